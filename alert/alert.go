@@ -0,0 +1,154 @@
+// Package alert fires webhooks when the node runs into a condition that needs the attention of its operator, such as
+// an unreachable orchestrator or a low ETH balance.
+package alert
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Type is the condition of an alert
+type Type string
+
+const (
+	// OrchestratorUnreachable is fired by broadcasters when the segments submitted to an orchestrator keep failing to reach it
+	OrchestratorUnreachable Type = "orchestratorUnreachable"
+	// RedemptionFailed is fired by redeemers when winning tickets could not be redeemed
+	RedemptionFailed Type = "redemptionFailed"
+	// TranscoderEvicted is fired by orchestrators when a remote transcoder, such as a GPU, is evicted after a fatal error
+	TranscoderEvicted Type = "transcoderEvicted"
+	// EthBalanceLow is fired when the ETH balance of the node falls below -ethBalanceLow or -ethBalanceCritical
+	EthBalanceLow Type = "ethBalanceLow"
+	// BlockWatcherStalled is fired when the node hasn't seen a new block for -alertBlockStallTimeout
+	BlockWatcherStalled Type = "blockWatcherStalled"
+)
+
+// Types are all the alert types
+var Types = []Type{OrchestratorUnreachable, RedemptionFailed, TranscoderEvicted, EthBalanceLow, BlockWatcherStalled}
+
+// Severity is how urgent an alert is
+type Severity string
+
+const (
+	Warning  Severity = "warning"
+	Critical Severity = "critical"
+)
+
+// Alert is a condition of the node that needs the attention of its operator
+type Alert struct {
+	Type     Type     `json:"type"`
+	Severity Severity `json:"severity"`
+	// Subject is what the alert is about, such as the URI of an orchestrator. The alerts with the same type and
+	// subject are only fired once per cooldown.
+	Subject string    `json:"subject,omitempty"`
+	Message string    `json:"message"`
+	Node    string    `json:"node"`
+	Time    time.Time `json:"time"`
+}
+
+// Config configures the alerts of the node
+type Config struct {
+	Webhooks []*Webhook
+	// Types are the types of the alerts fired, all of them if empty
+	Types []Type
+	// Cooldown is the time during which an alert with the same type and subject as a fired one isn't fired again
+	Cooldown time.Duration
+	// Node identifies the node in the alerts
+	Node string
+}
+
+// Alerter fires the alerts of the node to the webhooks
+type Alerter struct {
+	cfg   Config
+	types map[Type]bool
+
+	mu    sync.Mutex
+	fired map[string]time.Time
+}
+
+// NewAlerter creates an Alerter with the config
+func NewAlerter(cfg Config) *Alerter {
+	types := make(map[Type]bool)
+	for _, t := range cfg.Types {
+		types[t] = true
+	}
+	return &Alerter{cfg: cfg, types: types, fired: make(map[string]time.Time)}
+}
+
+// Fire posts the alert to the webhooks without blocking, unless its type isn't enabled or it was fired less than
+// the cooldown ago. It returns whether the alert was fired.
+func (a *Alerter) Fire(alert *Alert) bool {
+	if len(a.types) > 0 && !a.types[alert.Type] {
+		return false
+	}
+	now := time.Now()
+	key := string(alert.Type) + "/" + alert.Subject
+	a.mu.Lock()
+	if last, ok := a.fired[key]; ok && now.Sub(last) < a.cfg.Cooldown {
+		a.mu.Unlock()
+		return false
+	}
+	// Forget the alerts past their cooldown so that the subjects that come and go don't pile up
+	for k, last := range a.fired {
+		if now.Sub(last) >= a.cfg.Cooldown {
+			delete(a.fired, k)
+		}
+	}
+	a.fired[key] = now
+	a.mu.Unlock()
+
+	alert.Time = now
+	alert.Node = a.cfg.Node
+	glog.Warningf("Alert type=%s severity=%s subject=%q message=%q", alert.Type, alert.Severity, alert.Subject, alert.Message)
+	for _, w := range a.cfg.Webhooks {
+		go w.post(alert)
+	}
+	return true
+}
+
+var alerter *Alerter
+
+// Init enables the alerts of the node. It has to be called before the services that fire alerts are started.
+func Init(cfg Config) {
+	alerter = NewAlerter(cfg)
+}
+
+// Enabled returns whether the alerts of the node are enabled
+func Enabled() bool {
+	return alerter != nil
+}
+
+// Fire fires an alert about subject if the alerts are enabled
+func Fire(typ Type, severity Severity, subject, format string, args ...interface{}) {
+	if alerter == nil {
+		return
+	}
+	alerter.Fire(&Alert{Type: typ, Severity: severity, Subject: subject, Message: fmt.Sprintf(format, args...)})
+}
+
+// ParseTypes parses a comma separated list of alert types
+func ParseTypes(s string) ([]Type, error) {
+	var types []Type
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, t := range Types {
+			if string(t) == name {
+				types = append(types, t)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown alert type %q", name)
+		}
+	}
+	return types, nil
+}
@@ -0,0 +1,89 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlerter_Fire(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	received := make(chan *Alert, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		assert.Nil(json.NewDecoder(r.Body).Decode(&alert))
+		received <- &alert
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.Nil(err)
+	w, err := NewWebhook(u, JSON)
+	require.Nil(err)
+
+	a := NewAlerter(Config{
+		Webhooks: []*Webhook{w},
+		Types:    []Type{OrchestratorUnreachable, EthBalanceLow},
+		Cooldown: time.Hour,
+		Node:     "node-1",
+	})
+	assert.True(a.Fire(&Alert{Type: OrchestratorUnreachable, Severity: Warning, Subject: "https://o1:8935", Message: "3 segments failed"}))
+	select {
+	case alert := <-received:
+		assert.Equal(OrchestratorUnreachable, alert.Type)
+		assert.Equal(Warning, alert.Severity)
+		assert.Equal("https://o1:8935", alert.Subject)
+		assert.Equal("3 segments failed", alert.Message)
+		assert.Equal("node-1", alert.Node)
+		assert.False(alert.Time.IsZero())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for alert")
+	}
+
+	// The same alert isn't fired again during the cooldown, the alerts about other subjects are
+	assert.False(a.Fire(&Alert{Type: OrchestratorUnreachable, Subject: "https://o1:8935"}))
+	assert.True(a.Fire(&Alert{Type: OrchestratorUnreachable, Subject: "https://o2:8935"}))
+	// Alerts of the types that aren't enabled aren't fired
+	assert.False(a.Fire(&Alert{Type: BlockWatcherStalled}))
+
+	// Without cooldown, the alerts are always fired
+	a = NewAlerter(Config{})
+	assert.True(a.Fire(&Alert{Type: BlockWatcherStalled}))
+	assert.True(a.Fire(&Alert{Type: BlockWatcherStalled}))
+}
+
+func TestFire(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func() { alerter = nil }()
+	assert.False(Enabled())
+	// No-op without Init
+	Fire(EthBalanceLow, Critical, "", "balance=%d", 1)
+
+	Init(Config{Cooldown: time.Hour})
+	assert.True(Enabled())
+	Fire(EthBalanceLow, Critical, "", "balance=%d", 1)
+	assert.False(alerter.Fire(&Alert{Type: EthBalanceLow}))
+}
+
+func TestParseTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	types, err := ParseTypes("")
+	assert.Nil(err)
+	assert.Empty(types)
+
+	types, err = ParseTypes("redemptionFailed, blockWatcherStalled")
+	assert.Nil(err)
+	assert.Equal([]Type{RedemptionFailed, BlockWatcherStalled}, types)
+
+	_, err = ParseTypes("redemptionFailed,unknown")
+	assert.EqualError(err, `unknown alert type "unknown"`)
+}
@@ -0,0 +1,154 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Format is the payload format of a webhook
+type Format string
+
+const (
+	// JSON posts the Alert as is
+	JSON Format = "json"
+	// Slack posts a message to a Slack, or Slack compatible, incoming webhook
+	Slack Format = "slack"
+	// PagerDuty triggers a PagerDuty Events API v2 event, the routing key being the routing_key param of the URL
+	PagerDuty Format = "pagerduty"
+)
+
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// Webhook is a URL that alerts are posted to
+type Webhook struct {
+	url        *url.URL
+	format     Format
+	routingKey string
+}
+
+// NewWebhook creates a Webhook posting the alerts to u in the format
+func NewWebhook(u *url.URL, format Format) (*Webhook, error) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid alert webhook URL %q, the scheme must be http or https", u.Redacted())
+	}
+	w := &Webhook{url: u, format: format}
+	switch format {
+	case JSON, Slack:
+	case PagerDuty:
+		// The routing key is a secret, kept out of the URL that is logged
+		q := u.Query()
+		w.routingKey = q.Get("routing_key")
+		if w.routingKey == "" {
+			return nil, fmt.Errorf("missing routing_key param in PagerDuty alert webhook URL %q", u.Redacted())
+		}
+		q.Del("routing_key")
+		stripped := *u
+		stripped.RawQuery = q.Encode()
+		w.url = &stripped
+	default:
+		return nil, fmt.Errorf("unknown alert webhook format %q", format)
+	}
+	return w, nil
+}
+
+// ParseWebhooks parses a comma separated list of webhook URLs, each prefixed with its format and = if not JSON,
+// e.g. https://example.com/alerts,slack=https://hooks.slack.com/services/T0/B0/X
+func ParseWebhooks(s string) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		format := JSON
+		for _, f := range []Format{JSON, Slack, PagerDuty} {
+			if strings.HasPrefix(entry, string(f)+"=") {
+				format = f
+				entry = strings.TrimPrefix(entry, string(f)+"=")
+				break
+			}
+		}
+		u, err := url.ParseRequestURI(entry)
+		if err != nil {
+			return nil, err
+		}
+		w, err := NewWebhook(u, format)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+// String returns the URL of the webhook without its credentials
+func (w *Webhook) String() string {
+	return string(w.format) + "=" + w.url.Redacted()
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+type pagerDutyPayload struct {
+	RoutingKey  string `json:"routing_key"`
+	EventAction string `json:"event_action"`
+	DedupKey    string `json:"dedup_key"`
+	Payload     struct {
+		Summary   string `json:"summary"`
+		Source    string `json:"source"`
+		Severity  string `json:"severity"`
+		Timestamp string `json:"timestamp"`
+		Class     string `json:"class"`
+		Component string `json:"component,omitempty"`
+	} `json:"payload"`
+}
+
+// payload returns the body of the request posting the alert
+func (w *Webhook) payload(alert *Alert) interface{} {
+	switch w.format {
+	case Slack:
+		text := fmt.Sprintf("*[%s] %s* on %s: %s", alert.Severity, alert.Type, alert.Node, alert.Message)
+		return &slackPayload{Text: text}
+	case PagerDuty:
+		p := &pagerDutyPayload{
+			RoutingKey:  w.routingKey,
+			EventAction: "trigger",
+			DedupKey:    alert.Node + "/" + string(alert.Type) + "/" + alert.Subject,
+		}
+		p.Payload.Summary = fmt.Sprintf("%s: %s", alert.Type, alert.Message)
+		p.Payload.Source = alert.Node
+		p.Payload.Severity = string(alert.Severity)
+		p.Payload.Timestamp = alert.Time.UTC().Format(time.RFC3339)
+		p.Payload.Class = string(alert.Type)
+		p.Payload.Component = alert.Subject
+		return p
+	}
+	return alert
+}
+
+func (w *Webhook) post(alert *Alert) {
+	body, err := json.Marshal(w.payload(alert))
+	if err != nil {
+		glog.Errorf("Unable to marshal alert type=%v err=%q", alert.Type, err)
+		return
+	}
+	resp, err := webhookClient.Post(w.url.String(), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		glog.Errorf("Unable to POST alert on webhook url=%v type=%v err=%q", w.url.Redacted(), alert.Type, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rbody, _ := ioutil.ReadAll(resp.Body)
+		glog.Errorf("Alert webhook returned error url=%v status=%v type=%v err=%q", w.url.Redacted(), resp.StatusCode, alert.Type, string(rbody))
+	}
+}
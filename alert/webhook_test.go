@@ -0,0 +1,70 @@
+package alert
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWebhooks(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	webhooks, err := ParseWebhooks("https://example.com/alerts?a=b, slack=https://hooks.slack.com/services/T0/B0/X,pagerduty=https://events.pagerduty.com/v2/enqueue?routing_key=secret")
+	require.Nil(err)
+	require.Len(webhooks, 3)
+	assert.Equal(JSON, webhooks[0].format)
+	assert.Equal("https://example.com/alerts?a=b", webhooks[0].url.String())
+	assert.Equal(Slack, webhooks[1].format)
+	assert.Equal("https://hooks.slack.com/services/T0/B0/X", webhooks[1].url.String())
+	assert.Equal(PagerDuty, webhooks[2].format)
+	assert.Equal("secret", webhooks[2].routingKey)
+	assert.Equal("https://events.pagerduty.com/v2/enqueue", webhooks[2].url.String())
+	assert.Equal("pagerduty=https://events.pagerduty.com/v2/enqueue", webhooks[2].String())
+
+	webhooks, err = ParseWebhooks("")
+	assert.Nil(err)
+	assert.Empty(webhooks)
+
+	_, err = ParseWebhooks("pagerduty=https://events.pagerduty.com/v2/enqueue")
+	assert.EqualError(err, `missing routing_key param in PagerDuty alert webhook URL "https://events.pagerduty.com/v2/enqueue"`)
+	_, err = ParseWebhooks("ftp://example.com")
+	assert.EqualError(err, `invalid alert webhook URL "ftp://example.com", the scheme must be http or https`)
+	_, err = ParseWebhooks("example.com")
+	assert.Error(err)
+}
+
+func TestWebhook_Payload(t *testing.T) {
+	assert := assert.New(t)
+
+	alert := &Alert{
+		Type:     TranscoderEvicted,
+		Severity: Critical,
+		Subject:  "10.0.0.2:47322",
+		Message:  "remote transcoder evicted",
+		Node:     "orch-1",
+		Time:     time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	u, _ := url.Parse("https://example.com")
+	w, _ := NewWebhook(u, JSON)
+	assert.Equal(alert, w.payload(alert))
+
+	w, _ = NewWebhook(u, Slack)
+	assert.Equal(&slackPayload{Text: "*[critical] transcoderEvicted* on orch-1: remote transcoder evicted"}, w.payload(alert))
+
+	u, _ = url.Parse("https://events.pagerduty.com/v2/enqueue?routing_key=secret")
+	w, _ = NewWebhook(u, PagerDuty)
+	p := w.payload(alert).(*pagerDutyPayload)
+	assert.Equal("secret", p.RoutingKey)
+	assert.Equal("trigger", p.EventAction)
+	assert.Equal("orch-1/transcoderEvicted/10.0.0.2:47322", p.DedupKey)
+	assert.Equal("transcoderEvicted: remote transcoder evicted", p.Payload.Summary)
+	assert.Equal("orch-1", p.Payload.Source)
+	assert.Equal("critical", p.Payload.Severity)
+	assert.Equal("2022-12-01T10:00:00Z", p.Payload.Timestamp)
+	assert.Equal("10.0.0.2:47322", p.Payload.Component)
+}
@@ -0,0 +1,207 @@
+// Package api serves the versioned JSON management API of the node, along with its OpenAPI document generated from
+// the types of the requests and responses of its routes.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// DocumentPath is the path of the OpenAPI document of an API, relative to its base path
+const DocumentPath = "/openapi.json"
+
+// Params are the values of the path parameters of a request, e.g. manifestId for /streams/{manifestId}
+type Params map[string]string
+
+// HandlerFunc handles a request to a route, returning the value of the JSON response, or nil for no content
+type HandlerFunc func(r *http.Request, params Params) (interface{}, error)
+
+// Route is an endpoint of an API
+type Route struct {
+	Method string
+	// Path is the path of the route relative to the base path of the API, with the parameters in braces, e.g.
+	// /streams/{manifestId}
+	Path    string
+	Summary string
+	// Tag groups the routes in the document
+	Tag string
+	// Request is a value of the type of the JSON body of the requests, nil if they have none
+	Request interface{}
+	// Response is a value of the type of the JSON responses, nil if they have no content
+	Response interface{}
+	// Auth requires the requests to be authorized by the Authorizer of the API
+	Auth    bool
+	Handler HandlerFunc
+}
+
+// Authorizer returns the error responded to a request to a route requiring auth, nil if the request is authorized
+type Authorizer func(r *http.Request) error
+
+// Error is an error with the status of the response
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errorf returns an error responded with status
+func Errorf(status int, format string, args ...interface{}) *Error {
+	return &Error{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// ErrorResponse is the body of the error responses
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// API routes the requests under its base path
+type API struct {
+	title    string
+	version  string
+	basePath string
+	routes   []Route
+	auth     Authorizer
+}
+
+// New returns an API served under basePath, e.g. /api/v2
+func New(title, version, basePath string) *API {
+	return &API{title: title, version: version, basePath: strings.TrimSuffix(basePath, "/")}
+}
+
+// Handle adds routes to the API
+func (a *API) Handle(routes ...Route) {
+	a.routes = append(a.routes, routes...)
+}
+
+// SetAuthorizer sets the authorizer of the requests to the routes requiring auth. The requests to these routes are
+// forbidden while it isn't set.
+func (a *API) SetAuthorizer(auth Authorizer) {
+	a.auth = auth
+}
+
+// Routes returns the routes of the API
+func (a *API) Routes() []Route {
+	return append([]Route(nil), a.routes...)
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, a.basePath)
+	if path == DocumentPath && r.Method == http.MethodGet {
+		respond(w, http.StatusOK, a.Document())
+		return
+	}
+	methodMismatch := false
+	for _, route := range a.routes {
+		params, ok := matchPath(route.Path, path)
+		if !ok {
+			continue
+		}
+		if route.Method != r.Method {
+			methodMismatch = true
+			continue
+		}
+		if route.Auth {
+			if err := a.authorize(r); err != nil {
+				respondError(w, r, err)
+				return
+			}
+		}
+		res, err := route.Handler(r, params)
+		if err != nil {
+			respondError(w, r, err)
+			return
+		}
+		if res == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		respond(w, http.StatusOK, res)
+		return
+	}
+	if methodMismatch {
+		respondError(w, r, Errorf(http.StatusMethodNotAllowed, "method %s not allowed", r.Method))
+		return
+	}
+	respondError(w, r, Errorf(http.StatusNotFound, "not found"))
+}
+
+func (a *API) authorize(r *http.Request) error {
+	if a.auth == nil {
+		return Errorf(http.StatusForbidden, "forbidden")
+	}
+	return a.auth(r)
+}
+
+// matchPath returns the values of the parameters of pattern if path matches it
+func matchPath(pattern, path string) (Params, bool) {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternParts) != len(pathParts) {
+		return nil, false
+	}
+	params := Params{}
+	for i, part := range patternParts {
+		if name, ok := pathParam(part); ok {
+			if pathParts[i] == "" {
+				return nil, false
+			}
+			params[name] = pathParts[i]
+			continue
+		}
+		if part != pathParts[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// pathParam returns the name of the parameter if part of a path is one
+func pathParam(part string) (string, bool) {
+	if len(part) > 2 && strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+		return part[1 : len(part)-1], true
+	}
+	return "", false
+}
+
+// DecodeBody decodes the JSON body of r into v, rejecting the unknown fields
+func DecodeBody(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return Errorf(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+func respond(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		glog.Errorf("Error marshaling API response err=%q", err)
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(ErrorResponse{Error: err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(data); err != nil {
+		glog.Errorf("Error writing API response err=%q", err)
+	}
+}
+
+// respondError responds with the status of err if it's an *Error, 500 otherwise
+func respondError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	if apiErr, ok := err.(*Error); ok {
+		status = apiErr.Status
+	}
+	if status >= http.StatusInternalServerError {
+		glog.Errorf("API error method=%s path=%s status=%d err=%q", r.Method, r.URL.Path, status, err)
+	}
+	respond(w, status, ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	Name string `json:"name"`
+}
+
+func newTestAPI() *API {
+	a := New("Test API", "1.0.0", "/api/v1/")
+	a.Handle(
+		Route{
+			Method: http.MethodGet, Path: "/items/{name}", Response: testItem{},
+			Handler: func(r *http.Request, params Params) (interface{}, error) {
+				if params["name"] == "missing" {
+					return nil, Errorf(http.StatusNotFound, "unknown item %q", params["name"])
+				}
+				return testItem{Name: params["name"]}, nil
+			},
+		},
+		Route{
+			Method: http.MethodPost, Path: "/items", Request: testItem{}, Response: testItem{},
+			Handler: func(r *http.Request, params Params) (interface{}, error) {
+				var item testItem
+				if err := DecodeBody(r, &item); err != nil {
+					return nil, err
+				}
+				return item, nil
+			},
+		},
+		Route{
+			Method: http.MethodDelete, Path: "/items/{name}",
+			Handler: func(r *http.Request, params Params) (interface{}, error) {
+				if params["name"] == "broken" {
+					return nil, errors.New("storage failure")
+				}
+				return nil, nil
+			},
+		},
+	)
+	return a
+}
+
+func serve(h http.Handler, method, path, body string) (int, string) {
+	req := httptest.NewRequest(method, "http://example.com"+path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestAPI_Routes(t *testing.T) {
+	assert := assert.New(t)
+	a := newTestAPI()
+
+	status, body := serve(a, http.MethodGet, "/api/v1/items/foo", "")
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`{"name":"foo"}`, body)
+
+	status, body = serve(a, http.MethodGet, "/api/v1/items/missing", "")
+	assert.Equal(http.StatusNotFound, status)
+	assert.JSONEq(`{"error":"unknown item \"missing\""}`, body)
+
+	status, body = serve(a, http.MethodPost, "/api/v1/items", `{"name":"bar"}`)
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`{"name":"bar"}`, body)
+
+	status, body = serve(a, http.MethodDelete, "/api/v1/items/foo", "")
+	assert.Equal(http.StatusNoContent, status)
+	assert.Empty(body)
+
+	// errors other than *Error are internal errors
+	status, body = serve(a, http.MethodDelete, "/api/v1/items/broken", "")
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.JSONEq(`{"error":"storage failure"}`, body)
+
+	status, body = serve(a, http.MethodPut, "/api/v1/items", "")
+	assert.Equal(http.StatusMethodNotAllowed, status)
+	assert.JSONEq(`{"error":"method PUT not allowed"}`, body)
+
+	for _, path := range []string{"/api/v1/items/foo/bar", "/api/v1", "/api/v1/other"} {
+		status, _ = serve(a, http.MethodGet, path, "")
+		assert.Equal(http.StatusNotFound, status, path)
+	}
+}
+
+func TestAPI_Auth(t *testing.T) {
+	assert := assert.New(t)
+	a := newTestAPI()
+	a.Handle(Route{
+		Method: http.MethodPut, Path: "/items/{name}", Auth: true,
+		Handler: func(r *http.Request, params Params) (interface{}, error) {
+			return nil, nil
+		},
+	})
+
+	// routes requiring auth are forbidden without an authorizer
+	status, body := serve(a, http.MethodPut, "/api/v1/items/foo", "")
+	assert.Equal(http.StatusForbidden, status)
+	assert.JSONEq(`{"error":"forbidden"}`, body)
+
+	a.SetAuthorizer(func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			return Errorf(http.StatusUnauthorized, "unauthorized")
+		}
+		return nil
+	})
+	status, body = serve(a, http.MethodPut, "/api/v1/items/foo", "")
+	assert.Equal(http.StatusUnauthorized, status)
+	assert.JSONEq(`{"error":"unauthorized"}`, body)
+
+	req := httptest.NewRequest(http.MethodPut, "http://example.com/api/v1/items/foo", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+	assert.Equal(http.StatusNoContent, w.Code)
+
+	// the other routes don't require auth
+	status, _ = serve(a, http.MethodGet, "/api/v1/items/foo", "")
+	assert.Equal(http.StatusOK, status)
+}
+
+func TestDecodeBody(t *testing.T) {
+	assert := assert.New(t)
+	a := newTestAPI()
+
+	for _, body := range []string{``, `{"name":1}`, `{"name":"foo","extra":true}`} {
+		status, resp := serve(a, http.MethodPost, "/api/v1/items", body)
+		assert.Equal(http.StatusBadRequest, status, body)
+		var errResp ErrorResponse
+		assert.Nil(json.Unmarshal([]byte(resp), &errResp))
+		assert.True(strings.HasPrefix(errResp.Error, "invalid request body: "), resp)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	assert := assert.New(t)
+
+	params, ok := matchPath("/streams/{manifestId}/sessions", "/streams/abc/sessions")
+	require.True(t, ok)
+	assert.Equal(Params{"manifestId": "abc"}, params)
+
+	params, ok = matchPath("/streams", "/streams/")
+	assert.True(ok)
+	assert.Empty(params)
+
+	_, ok = matchPath("/streams/{manifestId}", "/streams")
+	assert.False(ok)
+	_, ok = matchPath("/streams/{manifestId}", "/sessions/abc")
+	assert.False(ok)
+}
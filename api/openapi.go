@@ -0,0 +1,229 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bearerAuth is the security scheme of the routes requiring auth, which send a token in an
+// "Authorization: Bearer <token>" header
+const bearerAuth = "bearerAuth"
+
+// Schema is an OpenAPI schema object
+type Schema map[string]interface{}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Document returns the OpenAPI 3 document of the API, with the schemas of the requests and responses generated from
+// the types of their Go values: the fields are named after their json tag, required unless omitempty, and described
+// by their doc tag.
+func (a *API) Document() map[string]interface{} {
+	g := &schemaGenerator{components: make(map[string]Schema)}
+	errorSchema := g.schema(reflect.TypeOf(ErrorResponse{}))
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": errorSchema}},
+	}
+
+	paths := make(map[string]map[string]interface{})
+	for _, route := range a.routes {
+		op := map[string]interface{}{
+			"summary":     route.Summary,
+			"operationId": operationID(route),
+			"responses": map[string]interface{}{
+				"default": errorResponse,
+			},
+		}
+		if route.Tag != "" {
+			op["tags"] = []string{route.Tag}
+		}
+		if route.Auth {
+			op["security"] = []map[string][]string{{bearerAuth: {}}}
+		}
+		var params []map[string]interface{}
+		for _, part := range strings.Split(route.Path, "/") {
+			if name, ok := pathParam(part); ok {
+				params = append(params, map[string]interface{}{
+					"name": name, "in": "path", "required": true, "schema": Schema{"type": "string"},
+				})
+			}
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if route.Request != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": g.schema(reflect.TypeOf(route.Request))},
+				},
+			}
+		}
+		responses := op["responses"].(map[string]interface{})
+		if route.Response != nil {
+			responses["200"] = map[string]interface{}{
+				"description": "OK",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": g.schema(reflect.TypeOf(route.Response))},
+				},
+			}
+		} else {
+			responses["204"] = map[string]interface{}{"description": "No content"}
+		}
+		if paths[route.Path] == nil {
+			paths[route.Path] = make(map[string]interface{})
+		}
+		paths[route.Path][strings.ToLower(route.Method)] = op
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   a.title,
+			"version": a.version,
+		},
+		"servers": []map[string]interface{}{{"url": a.basePath}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas":         g.components,
+			"securitySchemes": map[string]interface{}{bearerAuth: map[string]string{"type": "http", "scheme": "bearer"}},
+		},
+	}
+}
+
+// operationID returns the ID of the operation of the route, e.g. getStreams or deleteStreamsManifestId
+func operationID(route Route) string {
+	id := strings.ToLower(route.Method)
+	for _, part := range strings.Split(route.Path, "/") {
+		if name, ok := pathParam(part); ok {
+			part = name
+		}
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+type schemaGenerator struct {
+	components map[string]Schema
+}
+
+// schema returns the schema of the values of t, referencing the schemas of the named structs in the components
+func (g *schemaGenerator) schema(t reflect.Type) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Schema{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return Schema{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "format": "byte"}
+		}
+		return Schema{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		// The unexported types are named like the exported ones in the document
+		name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		if _, ok := g.components[name]; !ok {
+			// Reserve the name first for the recursive types
+			g.components[name] = Schema{}
+			g.components[name] = g.structSchema(t)
+		}
+		return Schema{"$ref": "#/components/schemas/" + name}
+	}
+	return Schema{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) Schema {
+	properties := make(map[string]Schema)
+	var required []string
+	g.addFields(t, properties, &required)
+	s := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		s["required"] = required
+	}
+	return s
+}
+
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(ft, properties, required)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		var s Schema
+		if hasOption(opts, "string") {
+			s = Schema{"type": "string"}
+		} else {
+			s = g.schema(f.Type)
+		}
+		if doc := f.Tag.Get("doc"); doc != "" {
+			if _, ok := s["$ref"]; ok {
+				// The siblings of $ref are ignored in OpenAPI 3.0
+				s = Schema{"allOf": []Schema{s}}
+			}
+			s["description"] = doc
+		}
+		properties[name] = s
+		if !hasOption(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func hasOption(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testNode struct {
+	testBase
+	Name     string            `json:"name" doc:"Name of the node"`
+	Count    uint64            `json:"count,omitempty"`
+	Amount   int64             `json:"amount,string"`
+	Created  time.Time         `json:"created"`
+	Data     []byte            `json:"data,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Children []*testNode       `json:"children"`
+	Parent   *testNode         `json:"parent,omitempty" doc:"Parent of the node"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func TestDocument(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	a := newTestAPI()
+	a.Handle(Route{Method: http.MethodGet, Path: "/nodes/{id}", Summary: "Get a node", Tag: "nodes", Response: testNode{}})
+	a.Handle(Route{Method: http.MethodDelete, Path: "/nodes/{id}", Summary: "Delete a node", Tag: "nodes", Auth: true})
+
+	// the document is served under the base path
+	status, body := serve(a, http.MethodGet, "/api/v1"+DocumentPath, "")
+	require.Equal(http.StatusOK, status)
+	var doc map[string]interface{}
+	require.Nil(json.Unmarshal([]byte(body), &doc))
+	assert.Equal("3.0.3", doc["openapi"])
+	assert.Equal(map[string]interface{}{"title": "Test API", "version": "1.0.0"}, doc["info"])
+	assert.Equal([]interface{}{map[string]interface{}{"url": "/api/v1"}}, doc["servers"])
+
+	paths := doc["paths"].(map[string]interface{})
+	assert.Len(paths, 3)
+	item := paths["/items/{name}"].(map[string]interface{})
+	assert.Contains(item, "get")
+	assert.Contains(item, "delete")
+
+	del := item["delete"].(map[string]interface{})
+	assert.Equal("deleteItemsName", del["operationId"])
+	assert.Equal([]interface{}{map[string]interface{}{
+		"name": "name", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+	}}, del["parameters"])
+	responses := del["responses"].(map[string]interface{})
+	assert.Contains(responses, "204")
+	assert.Contains(responses, "default")
+
+	post := paths["/items"].(map[string]interface{})["post"].(map[string]interface{})
+	ref := map[string]interface{}{"$ref": "#/components/schemas/TestItem"}
+	assert.Equal(ref, post["requestBody"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"])
+	ok := post["responses"].(map[string]interface{})["200"].(map[string]interface{})
+	assert.Equal(ref, ok["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"])
+
+	get := paths["/nodes/{id}"].(map[string]interface{})["get"].(map[string]interface{})
+	assert.Equal("Get a node", get["summary"])
+	assert.Equal([]interface{}{"nodes"}, get["tags"])
+	assert.NotContains(get, "security")
+	delNode := paths["/nodes/{id}"].(map[string]interface{})["delete"].(map[string]interface{})
+	assert.Equal([]interface{}{map[string]interface{}{"bearerAuth": []interface{}{}}}, delNode["security"])
+
+	components := doc["components"].(map[string]interface{})
+	assert.Equal(map[string]interface{}{
+		"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+	}, components["securitySchemes"])
+	schemas := components["schemas"].(map[string]interface{})
+	assert.Contains(schemas, "ErrorResponse")
+	assert.Contains(schemas, "TestItem")
+	node := schemas["TestNode"].(map[string]interface{})
+	assert.Equal([]interface{}{"amount", "children", "created", "id", "name"}, node["required"])
+	nodeRef := map[string]interface{}{"$ref": "#/components/schemas/TestNode"}
+	assert.Equal(map[string]interface{}{
+		"id":      map[string]interface{}{"type": "string"},
+		"name":    map[string]interface{}{"type": "string", "description": "Name of the node"},
+		"count":   map[string]interface{}{"type": "integer", "format": "int64"},
+		"amount":  map[string]interface{}{"type": "string"},
+		"created": map[string]interface{}{"type": "string", "format": "date-time"},
+		"data":    map[string]interface{}{"type": "string", "format": "byte"},
+		"labels": map[string]interface{}{
+			"type": "object", "additionalProperties": map[string]interface{}{"type": "string"},
+		},
+		"children": map[string]interface{}{"type": "array", "items": nodeRef},
+		"parent":   map[string]interface{}{"allOf": []interface{}{nodeRef}, "description": "Parent of the node"},
+	}, node["properties"])
+}
+
+func TestOperationID(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("getStreams", operationID(Route{Method: http.MethodGet, Path: "/streams"}))
+	assert.Equal("deleteStreamsManifestId", operationID(Route{Method: http.MethodDelete, Path: "/streams/{manifestId}"}))
+	assert.Equal("postStakingWithdrawStake", operationID(Route{Method: http.MethodPost, Path: "/staking/withdraw-stake"}))
+}
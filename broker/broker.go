@@ -0,0 +1,29 @@
+// Package broker publishes the metadata events of the node to the message brokers other than AMQP, which is
+// supported by the event package of livepeer-data.
+package broker
+
+import "context"
+
+type partitionKeyCtxKey struct{}
+
+// WithPartitionKey returns ctx with the key of the events published with it, which the brokers use to keep the events
+// with the same key in order, such as the manifest ID of a stream
+func WithPartitionKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, partitionKeyCtxKey{}, key)
+}
+
+// partitionKey returns the partition key of ctx, or def if it has none
+func partitionKey(ctx context.Context, def string) string {
+	if key, ok := ctx.Value(partitionKeyCtxKey{}).(string); ok && key != "" {
+		return key
+	}
+	return def
+}
+
+// namespacedKey prefixes key with keyNs like the AMQP producer does
+func namespacedKey(keyNs, key string) string {
+	if keyNs == "" {
+		return key
+	}
+	return keyNs + "." + key
+}
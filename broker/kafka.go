@@ -0,0 +1,104 @@
+package broker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/livepeer-data/pkg/event"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+// kafkaBatchTimeout is how long the events are batched before being written, kept short as the events are published one by one
+const kafkaBatchTimeout = 10 * time.Millisecond
+
+// KeyHeader is the header of the Kafka messages with the key of the event, e.g. broadcaster.stream_health.transcode.a.abcdef
+const KeyHeader = "key"
+
+type kafkaProducer struct {
+	writer *kafka.Writer
+	keyNs  string
+}
+
+// NewKafkaProducer publishes the events as JSON to the Kafka topic of uri, kafka://[user:password@]host:port[,host:port...][/topic],
+// or kafkas:// over TLS. The credentials are sent with SASL/PLAIN and the topic defaults to defaultTopic. The messages
+// are keyed by the partition key of the context of the events, so that the events of a stream are kept in order in
+// the same partition, or the key of the events otherwise. The key of the events, prefixed with keyNs, is in the
+// KeyHeader header. The producer is closed once ctx is done.
+func NewKafkaProducer(ctx context.Context, uri, defaultTopic, keyNs string) (event.Producer, error) {
+	writer, err := newKafkaWriter(uri, defaultTopic)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		if err := writer.Close(); err != nil {
+			glog.Errorf("Error closing the Kafka producer err=%q", err)
+		}
+	}()
+	return &kafkaProducer{writer: writer, keyNs: keyNs}, nil
+}
+
+func newKafkaWriter(uri, defaultTopic string) (*kafka.Writer, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	transport := &kafka.Transport{}
+	switch u.Scheme {
+	case "kafka":
+	case "kafkas":
+		transport.TLS = &tls.Config{}
+	default:
+		return nil, fmt.Errorf("invalid Kafka scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing Kafka brokers in %q", uri)
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		transport.SASL = plain.Mechanism{Username: u.User.Username(), Password: password}
+	}
+	topic := strings.Trim(u.Path, "/")
+	if topic == "" {
+		topic = defaultTopic
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("missing Kafka topic in %q", uri)
+	}
+	return &kafka.Writer{
+		Addr:         kafka.TCP(strings.Split(u.Host, ",")...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: kafkaBatchTimeout,
+		RequiredAcks: kafka.RequireAll,
+		Transport:    transport,
+	}, nil
+}
+
+// Publish writes the event to Kafka. The events are always persisted by the brokers so persistent is ignored.
+func (p *kafkaProducer) Publish(ctx context.Context, key string, body interface{}, persistent bool) error {
+	msg, err := kafkaMessage(ctx, namespacedKey(p.keyNs, key), body)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, msg)
+}
+
+func kafkaMessage(ctx context.Context, key string, body interface{}) (kafka.Message, error) {
+	value, err := json.Marshal(body)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("error marshaling event: %w", err)
+	}
+	return kafka.Message{
+		Key:     []byte(partitionKey(ctx, key)),
+		Value:   value,
+		Headers: []kafka.Header{{Key: KeyHeader, Value: []byte(key)}},
+	}, nil
+}
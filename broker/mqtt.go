@@ -0,0 +1,139 @@
+package broker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/golang/glog"
+	"github.com/livepeer/livepeer-data/pkg/event"
+	"github.com/nats-io/nuid"
+)
+
+// mqttConnectTimeout is how long to wait for the connection to the MQTT broker on start
+var mqttConnectTimeout = 10 * time.Second
+
+// mqttDefaultQoS is the QoS of the events when it's not in the URI: delivered at least once
+const mqttDefaultQoS = 1
+
+type mqttProducer struct {
+	client mqtt.Client
+	prefix string
+	qos    byte
+	keyNs  string
+}
+
+// NewMQTTProducer publishes the events as JSON to an MQTT broker, on the topics <prefix>/<key of the event prefixed
+// with keyNs, with slashes instead of dots>. uri is mqtt://[user:password@]host:port[,host:port...][/prefix][?qos=N],
+// or mqtts:// over TLS, the prefix defaulting to defaultPrefix and the QoS to 1. The client reconnects on its own if
+// the connection is lost, which is closed once ctx is done.
+func NewMQTTProducer(ctx context.Context, uri, defaultPrefix, keyNs string) (event.Producer, error) {
+	opts, prefix, qos, err := mqttConfig(uri, defaultPrefix)
+	if err != nil {
+		return nil, err
+	}
+	opts.SetClientID(fmt.Sprintf("livepeer-%s-%s", keyNs, nuid.Next()))
+	opts.SetAutoReconnect(true)
+	opts.SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+		glog.Errorf("Lost the MQTT connection err=%q", err)
+	})
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(mqttConnectTimeout) {
+		client.Disconnect(0)
+		return nil, fmt.Errorf("timed out connecting to MQTT brokers")
+	}
+	if err := token.Error(); err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		client.Disconnect(250)
+	}()
+	return &mqttProducer{client: client, prefix: prefix, qos: qos, keyNs: keyNs}, nil
+}
+
+// mqttConfig returns the client options, the topic prefix and the QoS of uri
+func mqttConfig(uri, defaultPrefix string) (*mqtt.ClientOptions, string, byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	var scheme string
+	switch u.Scheme {
+	case "mqtt":
+		scheme = "tcp"
+	case "mqtts":
+		scheme = "ssl"
+	default:
+		return nil, "", 0, fmt.Errorf("invalid MQTT scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, "", 0, fmt.Errorf("missing MQTT brokers in %q", uri)
+	}
+	opts := mqtt.NewClientOptions()
+	for _, host := range strings.Split(u.Host, ",") {
+		opts.AddBroker(scheme + "://" + host)
+	}
+	if u.Scheme == "mqtts" {
+		opts.SetTLSConfig(&tls.Config{})
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		opts.SetUsername(u.User.Username())
+		opts.SetPassword(password)
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	if prefix == "" || strings.ContainsAny(prefix, "+#") {
+		return nil, "", 0, fmt.Errorf("invalid MQTT topic prefix %q", prefix)
+	}
+	qos := byte(mqttDefaultQoS)
+	if q := u.Query().Get("qos"); q != "" {
+		n, err := strconv.Atoi(q)
+		if err != nil || n < 0 || n > 2 {
+			return nil, "", 0, fmt.Errorf("invalid MQTT QoS %q", q)
+		}
+		qos = byte(n)
+	}
+	return opts, prefix, qos, nil
+}
+
+// mqttTopic returns the topic of the events with key, one level per part of the key, replacing the wildcards
+// that aren't valid in a topic
+func mqttTopic(prefix, key string) string {
+	key = strings.Map(func(r rune) rune {
+		switch r {
+		case '.':
+			return '/'
+		case '+', '#':
+			return '_'
+		}
+		return r
+	}, key)
+	return prefix + "/" + key
+}
+
+// Publish publishes the event with the QoS of the producer, waiting for the broker to acknowledge it for a QoS above 0.
+// The events are never retained by the broker so persistent is ignored.
+func (p *mqttProducer) Publish(ctx context.Context, key string, body interface{}, persistent bool) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error marshaling event: %w", err)
+	}
+	token := p.client.Publish(mqttTopic(p.prefix, namespacedKey(p.keyNs, key)), p.qos, false, data)
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
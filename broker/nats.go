@@ -0,0 +1,141 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/livepeer-data/pkg/event"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nuid"
+)
+
+// natsRetryInterval is how long to wait before publishing an event again after JetStream failed to acknowledge it
+var natsRetryInterval = 500 * time.Millisecond
+
+type natsProducer struct {
+	js      nats.JetStreamContext
+	subject string
+	keyNs   string
+}
+
+// NewNATSProducer publishes the events as JSON to NATS JetStream, on the subjects <prefix>.<key of the event prefixed
+// with keyNs>. uri is nats://[user:password@]host:port[,host:port...][/prefix], the prefix defaulting to
+// defaultPrefix. The events are stored by the stream with the subjects <prefix>.>, which is created if it doesn't
+// exist, named after the prefix. The events are published at least once: they're published again until JetStream
+// acknowledges them or the context of the event is done, with a message ID for JetStream to drop the duplicates.
+// The connection is closed once ctx is done.
+func NewNATSProducer(ctx context.Context, uri, defaultPrefix, keyNs string) (event.Producer, error) {
+	servers, opts, prefix, err := natsConfig(uri, defaultPrefix)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, nats.Name("livepeer-"+keyNs), nats.MaxReconnects(-1))
+	nc, err := nats.Connect(servers, opts...)
+	if err != nil {
+		return nil, err
+	}
+	js, err := nc.JetStream()
+	if err == nil {
+		err = ensureNATSStream(js, prefix)
+	}
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		if err := nc.Drain(); err != nil {
+			glog.Errorf("Error closing the NATS connection err=%q", err)
+		}
+	}()
+	return &natsProducer{js: js, subject: prefix, keyNs: keyNs}, nil
+}
+
+// natsConfig returns the servers, the connection options and the subject prefix of uri
+func natsConfig(uri, defaultPrefix string) (string, []nats.Option, string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", nil, "", err
+	}
+	if u.Scheme != "nats" {
+		return "", nil, "", fmt.Errorf("invalid NATS scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", nil, "", fmt.Errorf("missing NATS servers in %q", uri)
+	}
+	var servers []string
+	for _, host := range strings.Split(u.Host, ",") {
+		servers = append(servers, "nats://"+host)
+	}
+	var opts []nats.Option
+	if u.User != nil {
+		password, _ := u.User.Password()
+		opts = append(opts, nats.UserInfo(u.User.Username(), password))
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	if prefix == "" || strings.ContainsAny(prefix, " *>") {
+		return "", nil, "", fmt.Errorf("invalid NATS subject prefix %q", prefix)
+	}
+	return strings.Join(servers, ","), opts, prefix, nil
+}
+
+// natsStreamName returns the name of the stream of the subjects with prefix, which can't contain dots
+func natsStreamName(prefix string) string {
+	return strings.ReplaceAll(prefix, ".", "_")
+}
+
+func ensureNATSStream(js nats.JetStreamContext, prefix string) error {
+	name := natsStreamName(prefix)
+	_, err := js.StreamInfo(name)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		glog.Infof("Creating NATS JetStream stream=%s subjects=%s.>", name, prefix)
+		_, err = js.AddStream(&nats.StreamConfig{Name: name, Subjects: []string{prefix + ".>"}})
+	}
+	if err != nil {
+		return fmt.Errorf("error getting NATS JetStream stream=%s: %w", name, err)
+	}
+	return nil
+}
+
+// natsSubject returns the subject of the events with key, replacing the characters that aren't valid in a subject token
+func natsSubject(prefix, key string) string {
+	key = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n', '*', '>':
+			return '_'
+		}
+		return r
+	}, key)
+	return prefix + "." + key
+}
+
+// Publish publishes the event to JetStream. The events are always persisted by JetStream so persistent is ignored.
+func (p *natsProducer) Publish(ctx context.Context, key string, body interface{}, persistent bool) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error marshaling event: %w", err)
+	}
+	msg := nats.NewMsg(natsSubject(p.subject, namespacedKey(p.keyNs, key)))
+	msg.Data = data
+	id := nuid.Next()
+	for {
+		_, err = p.js.PublishMsg(msg, nats.Context(ctx), nats.MsgId(id))
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(natsRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/livepeer/livepeer-data/pkg/event"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaField is the field of the events with the ID of the schema of their payload, which consumers dispatch on
+const SchemaField = "schema"
+
+// Schema is a version of the JSON schema of the payload of a type of metadata events. The events are published with
+// the latest version of the schema of their type. Fields are only added within a version; any breaking change bumps
+// the version.
+type Schema struct {
+	// Type is the type of the events
+	Type string
+	// Version is the version of the schema of the events of Type
+	Version int
+
+	doc string
+}
+
+// ID returns the ID of the schema in the schema field of the events, e.g. transcode.v1
+func (s Schema) ID() string {
+	return fmt.Sprintf("%s.v%d", s.Type, s.Version)
+}
+
+var compiledSchemas = compileSchemas(Schemas...)
+
+func compileSchemas(schemas ...Schema) map[string]*jsonschema.Schema {
+	compiled := make(map[string]*jsonschema.Schema, len(schemas))
+	for _, s := range schemas {
+		url := s.ID() + ".json"
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(url, strings.NewReader(s.doc)); err != nil {
+			panic(fmt.Sprintf("invalid schema=%s: %v", s.ID(), err))
+		}
+		compiled[s.ID()] = compiler.MustCompile(url)
+	}
+	return compiled
+}
+
+// latestSchemas returns the latest version of the schema of each type of events
+func latestSchemas(schemas ...Schema) map[string]Schema {
+	latest := make(map[string]Schema)
+	for _, s := range schemas {
+		if l, ok := latest[s.Type]; !ok || s.Version > l.Version {
+			latest[s.Type] = s
+		}
+	}
+	return latest
+}
+
+var currentSchemas = latestSchemas(Schemas...)
+
+// ValidateEvent returns the JSON payload of evt with the schema field set to the latest version of the schema of its
+// type, if it's valid against it
+func ValidateEvent(evt interface{}) ([]byte, error) {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling event: %w", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("event is not a JSON object: %w", err)
+	}
+	typ, _ := payload["type"].(string)
+	schema, ok := currentSchemas[typ]
+	if !ok {
+		return nil, fmt.Errorf("no schema for event type=%q", typ)
+	}
+	payload[SchemaField] = schema.ID()
+	if err := compiledSchemas[schema.ID()].Validate(payload); err != nil {
+		return nil, fmt.Errorf("event doesn't match schema=%s: %w", schema.ID(), err)
+	}
+	return withSchemaField(schema.ID(), data), nil
+}
+
+// withSchemaField adds the schema field first to the JSON object data, keeping the order of its other fields
+func withSchemaField(schemaID string, data []byte) []byte {
+	data = bytes.TrimSpace(data)
+	var buf bytes.Buffer
+	buf.WriteString(`{"` + SchemaField + `":"` + schemaID + `"`)
+	if rest := bytes.TrimSpace(data[1:]); len(rest) > 0 && rest[0] != '}' {
+		buf.WriteByte(',')
+	}
+	buf.Write(data[1:])
+	return buf.Bytes()
+}
+
+type schemaValidator struct {
+	producer event.Producer
+}
+
+// NewSchemaValidator returns a producer that versions the events with the schema field and validates them against
+// their schema before publishing them to producer, rejecting the events that don't have one or don't match it so that
+// consumers never receive a payload they can't parse
+func NewSchemaValidator(producer event.Producer) event.Producer {
+	return &schemaValidator{producer: producer}
+}
+
+func (v *schemaValidator) Publish(ctx context.Context, key string, body interface{}, persistent bool) error {
+	data, err := ValidateEvent(body)
+	if err != nil {
+		return err
+	}
+	return v.producer.Publish(ctx, key, json.RawMessage(data), persistent)
+}
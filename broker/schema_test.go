@@ -0,0 +1,110 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/livepeer/livepeer-data/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publishedEvent struct {
+	key  string
+	body interface{}
+}
+
+type stubProducer struct {
+	published []publishedEvent
+}
+
+func (p *stubProducer) Publish(ctx context.Context, key string, body interface{}, persistent bool) error {
+	p.published = append(p.published, publishedEvent{key, body})
+	return nil
+}
+
+func TestWithSchemaField(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`{"schema":"stream_health.v1","type":"stream_health"}`, string(withSchemaField("stream_health.v1", []byte(`{"type":"stream_health"}`))))
+	assert.Equal(`{"schema":"transcode.v1"}`, string(withSchemaField("transcode.v1", []byte(` {} `))))
+}
+
+func TestLatestSchemas(t *testing.T) {
+	assert := assert.New(t)
+
+	v1 := Schema{Type: "transcode", Version: 1}
+	v2 := Schema{Type: "transcode", Version: 2}
+	other := Schema{Type: "other", Version: 1}
+	assert.Equal(map[string]Schema{"transcode": v2, "other": other}, latestSchemas(v2, other, v1))
+	assert.Equal(TranscodeSchemaV1, currentSchemas["transcode"])
+	assert.Equal(StreamHealthSchemaV1, currentSchemas["stream_health"])
+}
+
+func TestSchemaValidator(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	errMsg := "OrchestratorBusy"
+	evt := data.NewTranscodeEvent("node", "stream", data.SegmentMetadata{Name: "seg.ts", SeqNo: 3, Duration: 2, ByteSize: 100},
+		time.Now(), false, []data.TranscodeAttemptInfo{{Orchestrator: data.OrchestratorMetadata{Address: "0x1", TranscoderUri: "https://o1"}, Error: &errMsg}})
+
+	stub := &stubProducer{}
+	producer := NewSchemaValidator(stub)
+	require.Nil(producer.Publish(context.Background(), "key", evt, false))
+	require.Len(stub.published, 1)
+	assert.Equal("key", stub.published[0].key)
+	var payload map[string]interface{}
+	require.Nil(json.Unmarshal(stub.published[0].body.(json.RawMessage), &payload))
+	assert.Equal("transcode.v1", payload[SchemaField])
+	assert.Equal("transcode", payload["type"])
+	assert.Equal("OrchestratorBusy", payload["attempts"].([]interface{})[0].(map[string]interface{})["error"])
+
+	// the events without a schema and the ones not matching it aren't published
+	err := producer.Publish(context.Background(), "key", map[string]string{"type": "unknown"}, false)
+	assert.EqualError(err, `no schema for event type="unknown"`)
+	err = producer.Publish(context.Background(), "key", []int{1}, false)
+	assert.Contains(err.Error(), "event is not a JSON object")
+	evt.Type_ = "stream_health"
+	err = producer.Publish(context.Background(), "key", evt, false)
+	assert.Contains(err.Error(), "event doesn't match schema=stream_health.v1")
+	assert.Len(stub.published, 1)
+}
+
+// The payloads published with a version of a schema must stay valid against it, so that consumers of the version
+// never break
+func TestSchemas_Compatible(t *testing.T) {
+	assert := assert.New(t)
+
+	payloads := map[Schema]string{
+		TranscodeSchemaV1: `{"schema":"transcode.v1","type":"transcode","id":"2f8e4c1a-6f0d-4a4e-9a47-4a3b5d0c7e21",
+			"timestamp":1665000000000,"streamId":"movie","nodeId":"broadcaster-1",
+			"segment":{"name":"movie/source/3.ts","seqNo":3,"duration":2,"byteSize":1048576},"startTime":1664999999500,
+			"latencyMs":500,"success":true,"attempts":[{"orchestrator":{"address":"0x1","transcodeUri":"https://o1:8935"},
+			"latencyMs":480,"error":null}]}`,
+		StreamHealthSchemaV1: `{"schema":"stream_health.v1","type":"stream_health","id":"2f8e4c1a-6f0d-4a4e-9a47-4a3b5d0c7e21",
+			"timestamp":1665000000000,"streamId":"movie","nodeId":"broadcaster-1","ended":false,
+			"health":{"score":72.5,"state":"degraded","droppedRate":0.1,"realtimeRatio":0.8,"verificationFailureRate":0,
+			"swapRate":0.2}}`,
+	}
+	assert.Len(payloads, len(Schemas))
+	for _, s := range Schemas {
+		payload, ok := payloads[s]
+		if !assert.True(ok, "missing payload of schema=%s", s.ID()) {
+			continue
+		}
+		var v map[string]interface{}
+		assert.Nil(json.Unmarshal([]byte(payload), &v))
+		assert.Nil(compiledSchemas[s.ID()].Validate(v), "schema=%s", s.ID())
+
+		// new fields don't break the consumers
+		v["newField"] = "value"
+		assert.Nil(compiledSchemas[s.ID()].Validate(v), "schema=%s", s.ID())
+
+		// removed fields do
+		delete(v, "streamId")
+		assert.Error(compiledSchemas[s.ID()].Validate(v), "schema=%s", s.ID())
+	}
+}
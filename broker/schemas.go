@@ -0,0 +1,90 @@
+package broker
+
+// The schemas of the metadata events. A breaking change to the payload of a type of events is a new version of its
+// schema, appended here, keeping the previous versions so that the events they validated still do.
+var (
+	// TranscodeSchemaV1 is the schema of the events published by broadcasters for each segment transcoded
+	TranscodeSchemaV1 = Schema{Type: "transcode", Version: 1, doc: transcodeSchemaV1}
+	// StreamHealthSchemaV1 is the schema of the events published by broadcasters when the health of a stream changes
+	StreamHealthSchemaV1 = Schema{Type: "stream_health", Version: 1, doc: streamHealthSchemaV1}
+
+	// Schemas are all the versions of the schemas of the metadata events
+	Schemas = []Schema{TranscodeSchemaV1, StreamHealthSchemaV1}
+)
+
+const transcodeSchemaV1 = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "transcode.v1",
+  "description": "A segment transcoded by a broadcaster, with its attempts and their orchestrators",
+  "type": "object",
+  "required": ["schema", "type", "id", "timestamp", "streamId", "nodeId", "segment", "startTime", "latencyMs", "success", "attempts"],
+  "properties": {
+    "schema": {"const": "transcode.v1"},
+    "type": {"const": "transcode"},
+    "id": {"type": "string"},
+    "timestamp": {"type": "integer", "description": "Unix time in milliseconds"},
+    "streamId": {"type": "string"},
+    "nodeId": {"type": "string"},
+    "segment": {
+      "type": "object",
+      "required": ["name", "seqNo", "duration", "byteSize"],
+      "properties": {
+        "name": {"type": "string"},
+        "seqNo": {"type": "integer", "minimum": 0},
+        "duration": {"type": "number", "description": "Seconds"},
+        "byteSize": {"type": "integer", "minimum": 0}
+      }
+    },
+    "startTime": {"type": "integer", "description": "Unix time in milliseconds"},
+    "latencyMs": {"type": "integer"},
+    "success": {"type": "boolean"},
+    "attempts": {
+      "type": ["array", "null"],
+      "items": {
+        "type": "object",
+        "required": ["orchestrator", "latencyMs", "error"],
+        "properties": {
+          "orchestrator": {
+            "type": "object",
+            "required": ["address", "transcodeUri"],
+            "properties": {
+              "address": {"type": "string"},
+              "transcodeUri": {"type": "string"}
+            }
+          },
+          "latencyMs": {"type": "integer"},
+          "error": {"type": ["string", "null"]}
+        }
+      }
+    }
+  }
+}`
+
+const streamHealthSchemaV1 = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "stream_health.v1",
+  "description": "The health score of a stream, published when its state changes and when it ends",
+  "type": "object",
+  "required": ["schema", "type", "id", "timestamp", "streamId", "nodeId", "ended", "health"],
+  "properties": {
+    "schema": {"const": "stream_health.v1"},
+    "type": {"const": "stream_health"},
+    "id": {"type": "string"},
+    "timestamp": {"type": "integer", "description": "Unix time in milliseconds"},
+    "streamId": {"type": "string"},
+    "nodeId": {"type": "string"},
+    "ended": {"type": "boolean"},
+    "health": {
+      "type": "object",
+      "required": ["score", "state", "droppedRate", "realtimeRatio", "verificationFailureRate", "swapRate"],
+      "properties": {
+        "score": {"type": "number", "minimum": 0, "maximum": 100},
+        "state": {"enum": ["healthy", "degraded", "unhealthy"]},
+        "droppedRate": {"type": "number", "minimum": 0, "maximum": 1},
+        "realtimeRatio": {"type": "number", "minimum": 0},
+        "verificationFailureRate": {"type": "number", "minimum": 0, "maximum": 1},
+        "swapRate": {"type": "number", "minimum": 0, "maximum": 1}
+      }
+    }
+  }
+}`
@@ -0,0 +1,530 @@
+/*
+Package loadtest implements `livepeer loadtest`, which publishes concurrent synthetic streams to a broadcaster
+or an orchestrator and reports the success rate and latency of their segments, to capacity-test nodes before real events.
+*/
+package loadtest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/server"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
+	"github.com/livepeer/m3u8"
+	"github.com/olekukonko/tablewriter"
+)
+
+// segment is a source segment published by the load test
+type segment struct {
+	data     []byte
+	duration time.Duration
+}
+
+// target transcodes the segments published by the load test
+type target interface {
+	// transcode submits seg as segment seqNo of the stream mid and returns once all its renditions are received
+	transcode(ctx context.Context, mid string, seqNo uint64, seg *segment) error
+}
+
+// result is the outcome of publishing a segment
+type result struct {
+	latency time.Duration
+	err     error
+}
+
+// Run runs the load test configured by the command line args of `livepeer loadtest`
+func Run(args []string) error {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	targetURL := fs.String("target", "", "URL of the HTTP ingest of a broadcaster, e.g. http://127.0.0.1:8935, or of an orchestrator with -orchestrator, e.g. https://127.0.0.1:8935")
+	orchestrator := fs.Bool("orchestrator", false, "Submit segments directly to the -target orchestrator without payments, as an offchain broadcaster would")
+	in := fs.String("in", "", "Input m3u8 manifest of the source segments, published in a loop")
+	streams := fs.Int("streams", 1, "Number of concurrent streams")
+	segs := fs.Int("segs", 10, "Number of segments published per stream")
+	transcodingOptions := fs.String("transcodingOptions", "P240p30fps16x9,P360p30fps16x9", "Comma separated presets to transcode the streams into")
+	bitrate := fs.String("bitrate", "", "Re-encode the source segments at this bitrate, e.g. 3000k, before publishing them")
+	resolution := fs.String("resolution", "1280x720", "Resolution of the source segments re-encoded with -bitrate")
+	live := fs.Bool("live", true, "Publish one segment per segment duration in each stream, as a live stream would")
+	streamDelay := fs.Duration("streamDelay", 300*time.Millisecond, "Delay before starting each new stream")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout to transcode each segment")
+	secret := fs.String("broadcasterSecret", "", "Shared secret with the -target orchestrator, or path to a file containing it")
+	verbosity := fs.String("v", "", "Log verbosity.  {4|5|6}")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if vFlag := flag.Lookup("v"); vFlag != nil && *verbosity != "" {
+		vFlag.Value.Set(*verbosity)
+	}
+
+	if *targetURL == "" || *in == "" {
+		fs.Usage()
+		return errors.New("missing -target or -in")
+	}
+	if *streams < 1 || *segs < 1 {
+		return errors.New("-streams and -segs must be positive")
+	}
+	uri, err := url.ParseRequestURI(*targetURL)
+	if err != nil {
+		return fmt.Errorf("invalid -target: %w", err)
+	}
+	presets := strings.Split(*transcodingOptions, ",")
+	profiles, err := parsePresets(presets)
+	if err != nil {
+		return err
+	}
+	var source []*segment
+	if *bitrate == "" {
+		source, err = readSegments(*in)
+	} else {
+		var dir string
+		dir, err = ioutil.TempDir("", "loadtest")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		source, err = reencodeSegments(*in, dir, *bitrate, *resolution)
+	}
+	if err != nil {
+		return err
+	}
+	if len(source) == 0 {
+		return fmt.Errorf("no segments in %s", *in)
+	}
+
+	var t target
+	if *orchestrator {
+		if *secret != "" {
+			server.BroadcasterSecret, err = common.ReadFromFile(*secret)
+			if err != nil {
+				return fmt.Errorf("invalid -broadcasterSecret: %w", err)
+			}
+		}
+		t = newOrchestratorTarget(uri, profiles)
+	} else {
+		t = &broadcasterTarget{url: uri, presets: presets, renditions: len(profiles), client: &http.Client{}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	defer signal.Stop(c)
+	go func() {
+		select {
+		case <-c:
+			glog.Info("Stopping the load test")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	printConfig(os.Stdout, [][]string{
+		{"Target", uri.String()},
+		{"Orchestrator", strconv.FormatBool(*orchestrator)},
+		{"Source", *in},
+		{"Source Bitrate", *bitrate},
+		{"Transcoding Options", *transcodingOptions},
+		{"Streams", strconv.Itoa(*streams)},
+		{"Segments Per Stream", strconv.Itoa(*segs)},
+		{"Live Mode", strconv.FormatBool(*live)},
+	})
+	start := time.Now()
+	results := run(ctx, t, *streams, *segs, source, *live, *streamDelay, *timeout)
+	printReport(os.Stdout, summarize(results), time.Since(start))
+	return nil
+}
+
+func parsePresets(presets []string) ([]ffmpeg.VideoProfile, error) {
+	var profiles []ffmpeg.VideoProfile
+	for _, v := range presets {
+		p, ok := ffmpeg.VideoProfileLookup[strings.TrimSpace(v)]
+		if !ok {
+			return nil, fmt.Errorf("unknown transcoding preset %q", v)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// readPlaylist returns the paths and durations of the segments of an m3u8 manifest
+func readPlaylist(manifest string) ([]string, []time.Duration, error) {
+	f, err := os.Open(manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	p, _, err := m3u8.DecodeFrom(bufio.NewReader(f), true)
+	if err != nil {
+		return nil, nil, err
+	}
+	pl, ok := p.(*m3u8.MediaPlaylist)
+	if !ok {
+		return nil, nil, fmt.Errorf("expecting media playlist in %s", manifest)
+	}
+	var paths []string
+	var durations []time.Duration
+	for _, s := range pl.Segments {
+		if s == nil {
+			continue
+		}
+		paths = append(paths, filepath.Join(filepath.Dir(manifest), s.URI))
+		durations = append(durations, time.Duration(s.Duration*float64(time.Second)))
+	}
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no segments in %s", manifest)
+	}
+	return paths, durations, nil
+}
+
+func readSegments(manifest string) ([]*segment, error) {
+	paths, durations, err := readPlaylist(manifest)
+	if err != nil {
+		return nil, err
+	}
+	segs := make([]*segment, len(paths))
+	for i, p := range paths {
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		segs[i] = &segment{data: data, duration: durations[i]}
+	}
+	return segs, nil
+}
+
+// reencodeSegments re-encodes the segments of manifest at bitrate into dir
+func reencodeSegments(manifest, dir, bitrate, resolution string) ([]*segment, error) {
+	paths, durations, err := readPlaylist(manifest)
+	if err != nil {
+		return nil, err
+	}
+	ffmpeg.InitFFmpegWithLogLevel(ffmpeg.FFLogWarning)
+	profile := ffmpeg.VideoProfile{Name: "source", Bitrate: bitrate, Framerate: 30, Resolution: resolution, Format: ffmpeg.FormatMPEGTS}
+	segs := make([]*segment, len(paths))
+	for i, p := range paths {
+		out := filepath.Join(dir, fmt.Sprintf("%d.ts", i))
+		_, err := ffmpeg.Transcode3(&ffmpeg.TranscodeOptionsIn{Fname: p, Accel: ffmpeg.Software}, []ffmpeg.TranscodeOptions{{
+			Oname:        out,
+			Profile:      profile,
+			Accel:        ffmpeg.Software,
+			AudioEncoder: ffmpeg.ComponentOptions{Name: "copy"},
+		}})
+		if err != nil {
+			return nil, fmt.Errorf("could not re-encode %s: %w", p, err)
+		}
+		data, err := ioutil.ReadFile(out)
+		if err != nil {
+			return nil, err
+		}
+		segs[i] = &segment{data: data, duration: durations[i]}
+	}
+	return segs, nil
+}
+
+// run publishes streams concurrent streams of segs segments each, looping over the source segments
+func run(ctx context.Context, t target, streams, segs int, source []*segment, live bool, streamDelay, timeout time.Duration) [][]result {
+	runID := common.RandName()
+	results := make([][]result, streams)
+	var wg sync.WaitGroup
+	for i := 0; i < streams; i++ {
+		if i > 0 && !sleep(ctx, streamDelay) {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mid := fmt.Sprintf("loadtest_%s_%d", runID, i)
+			results[i] = publish(ctx, t, mid, segs, source, live, timeout)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+func publish(ctx context.Context, t target, mid string, segs int, source []*segment, live bool, timeout time.Duration) []result {
+	var results []result
+	for seqNo := 0; seqNo < segs && ctx.Err() == nil; seqNo++ {
+		seg := source[seqNo%len(source)]
+		start := time.Now()
+		sctx, cancel := context.WithTimeout(ctx, timeout)
+		err := t.transcode(sctx, mid, uint64(seqNo), seg)
+		cancel()
+		latency := time.Since(start)
+		if err != nil {
+			glog.Errorf("Error transcoding stream=%s seqNo=%d err=%q", mid, seqNo, err)
+		} else {
+			glog.V(common.DEBUG).Infof("Transcoded stream=%s seqNo=%d latency=%v", mid, seqNo, latency)
+		}
+		results = append(results, result{latency: latency, err: err})
+		if live && latency < seg.duration && !sleep(ctx, seg.duration-latency) {
+			break
+		}
+	}
+	return results
+}
+
+// sleep waits for d and returns false if ctx is done before
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func checkRenditions(expected, got int) error {
+	if expected != got {
+		return fmt.Errorf("expected renditions=%d got=%d", expected, got)
+	}
+	return nil
+}
+
+// broadcasterTarget pushes segments to the HTTP ingest of a broadcaster
+type broadcasterTarget struct {
+	url        *url.URL
+	presets    []string
+	renditions int
+	client     *http.Client
+}
+
+func (b *broadcasterTarget) transcode(ctx context.Context, mid string, seqNo uint64, seg *segment) error {
+	config, err := json.Marshal(map[string]interface{}{"manifestID": mid, "presets": b.presets})
+	if err != nil {
+		return err
+	}
+	u := *b.url
+	u.Path = path.Join(u.Path, "live", mid, fmt.Sprintf("%d.ts", seqNo))
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(seg.data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "multipart/mixed")
+	req.Header.Set("Content-Duration", strconv.FormatInt(seg.duration.Milliseconds(), 10))
+	req.Header.Set(server.LIVERPEER_TRANSCODE_CONFIG_HEADER, string(config))
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			// Drop the URL, which is different for every segment
+			err = uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		glog.V(common.DEBUG).Infof("Error response stream=%s seqNo=%d status=%d body=%q", mid, seqNo, resp.StatusCode, body)
+		return fmt.Errorf("status=%d", resp.StatusCode)
+	}
+	_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	renditions := 0
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(ioutil.Discard, p); err != nil {
+			return err
+		}
+		renditions++
+	}
+	return checkRenditions(b.renditions, renditions)
+}
+
+// orchestratorTarget submits segments to an orchestrator without payments
+type orchestratorTarget struct {
+	uri      *url.URL
+	bcast    common.Broadcaster
+	profiles []ffmpeg.VideoProfile
+
+	mu       sync.Mutex
+	sessions map[string]*server.BroadcastSession
+}
+
+func newOrchestratorTarget(uri *url.URL, profiles []ffmpeg.VideoProfile) *orchestratorTarget {
+	return &orchestratorTarget{
+		uri:      uri,
+		bcast:    core.NewBroadcaster(nil),
+		profiles: profiles,
+		sessions: make(map[string]*server.BroadcastSession),
+	}
+}
+
+// session returns the session of the stream mid, getting the orchestrator info for the first segment of the stream
+func (o *orchestratorTarget) session(ctx context.Context, mid string) (*server.BroadcastSession, error) {
+	o.mu.Lock()
+	sess, ok := o.sessions[mid]
+	o.mu.Unlock()
+	if ok {
+		return sess, nil
+	}
+
+	info, err := server.GetOrchestratorInfo(ctx, o.bcast, o.uri, server.GetOrchestratorInfoParams{})
+	if err != nil {
+		return nil, err
+	}
+	params := &core.StreamParameters{ManifestID: core.ManifestID(mid), Profiles: o.profiles}
+	if params.Capabilities, err = core.JobCapabilities(params, nil); err != nil {
+		return nil, err
+	}
+	sess = server.NewBroadcastSession(o.bcast, params, info)
+
+	o.mu.Lock()
+	o.sessions[mid] = sess
+	o.mu.Unlock()
+	return sess, nil
+}
+
+func (o *orchestratorTarget) transcode(ctx context.Context, mid string, seqNo uint64, seg *segment) error {
+	sess, err := o.session(ctx, mid)
+	if err != nil {
+		return err
+	}
+	hlsSeg := &stream.HLSSegment{
+		SeqNo:    seqNo,
+		Name:     fmt.Sprintf("%d.ts", seqNo),
+		Data:     seg.data,
+		Duration: seg.duration.Seconds(),
+	}
+	res, err := server.SubmitSegment(ctx, sess, hlsSeg, nil, 0, false, true)
+	if err != nil {
+		return err
+	}
+	return checkRenditions(len(o.profiles), len(res.Segments))
+}
+
+// summary aggregates the results of the load test
+type summary struct {
+	streams   int
+	segments  int
+	succeeded int
+	// latencies of the successful segments, sorted
+	latencies []time.Duration
+	errors    map[string]int
+}
+
+func summarize(results [][]result) *summary {
+	s := &summary{streams: len(results), errors: make(map[string]int)}
+	for _, stream := range results {
+		for _, r := range stream {
+			s.segments++
+			if r.err != nil {
+				s.errors[r.err.Error()]++
+				continue
+			}
+			s.succeeded++
+			s.latencies = append(s.latencies, r.latency)
+		}
+	}
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	return s
+}
+
+func (s *summary) successRate() float64 {
+	if s.segments == 0 {
+		return 0
+	}
+	return float64(s.succeeded) / float64(s.segments)
+}
+
+// percentile returns the latency below which the fraction p of the successful segments are
+func (s *summary) percentile(p float64) time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(s.latencies)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return s.latencies[i]
+}
+
+func (s *summary) average() time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, l := range s.latencies {
+		total += l
+	}
+	return total / time.Duration(len(s.latencies))
+}
+
+func newTable(w io.Writer) *tablewriter.Table {
+	table := tablewriter.NewWriter(w)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("*")
+	table.SetColumnSeparator("|")
+	return table
+}
+
+func printConfig(w io.Writer, data [][]string) {
+	table := newTable(w)
+	table.AppendBulk(data)
+	table.Render()
+}
+
+func printReport(w io.Writer, s *summary, took time.Duration) {
+	table := newTable(w)
+	table.AppendBulk([][]string{
+		{"Duration", took.Round(time.Millisecond).String()},
+		{"Streams", strconv.Itoa(s.streams)},
+		{"Segments", strconv.Itoa(s.segments)},
+		{"Succeeded", strconv.Itoa(s.succeeded)},
+		{"Success Rate", fmt.Sprintf("%.1f%%", 100*s.successRate())},
+		{"Latency Avg", s.average().Round(time.Millisecond).String()},
+		{"Latency P50", s.percentile(0.5).Round(time.Millisecond).String()},
+		{"Latency P95", s.percentile(0.95).Round(time.Millisecond).String()},
+		{"Latency P99", s.percentile(0.99).Round(time.Millisecond).String()},
+		{"Latency Max", s.percentile(1).Round(time.Millisecond).String()},
+	})
+	table.Render()
+
+	if len(s.errors) == 0 {
+		return
+	}
+	var errs []string
+	for err := range s.errors {
+		errs = append(errs, err)
+	}
+	sort.Slice(errs, func(i, j int) bool { return s.errors[errs[i]] > s.errors[errs[j]] })
+	table = newTable(w)
+	table.SetHeader([]string{"Error", "Segments"})
+	for _, err := range errs {
+		table.Append([]string{err, strconv.Itoa(s.errors[err])})
+	}
+	table.Render()
+}
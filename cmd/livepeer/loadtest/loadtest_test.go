@@ -0,0 +1,174 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_InvalidArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.EqualError(Run([]string{"-in", "foo.m3u8"}), "missing -target or -in")
+	assert.EqualError(Run([]string{"-target", "http://127.0.0.1:8935", "-in", "foo.m3u8", "-streams", "0"}), "-streams and -segs must be positive")
+	assert.EqualError(Run([]string{"-target", "http://127.0.0.1:8935", "-in", "foo.m3u8", "-transcodingOptions", "foo"}), `unknown transcoding preset "foo"`)
+
+	// the error to read the segments to re-encode isn't shadowed
+	dir := t.TempDir()
+	assert.EqualError(Run([]string{"-target", "http://127.0.0.1:8935", "-in", filepath.Join(dir, "missing.m3u8"), "-bitrate", "1000k"}), "open "+filepath.Join(dir, "missing.m3u8")+": no such file or directory")
+
+	manifest := filepath.Join(dir, "test.m3u8")
+	require.Nil(t, ioutil.WriteFile(manifest, []byte("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:2\n#EXTINF:2.000,\n0.ts\n#EXT-X-ENDLIST\n"), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "0.ts"), []byte("seg0"), 0644))
+	defer func() { server.BroadcasterSecret = "" }()
+	assert.EqualError(Run([]string{"-target", "https://127.0.0.1:8935", "-in", manifest, "-orchestrator", "-broadcasterSecret", dir}), "invalid -broadcasterSecret: supplied path is a directory")
+}
+
+func TestReadSegments(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "test.m3u8")
+	require.Nil(ioutil.WriteFile(manifest, []byte("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:2\n#EXTINF:2.000,\n0.ts\n#EXTINF:1.500,\n1.ts\n#EXT-X-ENDLIST\n"), 0644))
+	require.Nil(ioutil.WriteFile(filepath.Join(dir, "0.ts"), []byte("seg0"), 0644))
+	require.Nil(ioutil.WriteFile(filepath.Join(dir, "1.ts"), []byte("seg1"), 0644))
+
+	segs, err := readSegments(manifest)
+	require.Nil(err)
+	require.Len(segs, 2)
+	assert.Equal([]byte("seg0"), segs[0].data)
+	assert.Equal(2*time.Second, segs[0].duration)
+	assert.Equal([]byte("seg1"), segs[1].data)
+	assert.Equal(1500*time.Millisecond, segs[1].duration)
+
+	_, err = readSegments(filepath.Join(dir, "missing.m3u8"))
+	assert.NotNil(err)
+}
+
+type stubTarget struct {
+	mu    sync.Mutex
+	calls map[string][]uint64
+	err   error
+}
+
+func (s *stubTarget) transcode(ctx context.Context, mid string, seqNo uint64, seg *segment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[mid] = append(s.calls[mid], seqNo)
+	if seqNo == 1 {
+		return s.err
+	}
+	return nil
+}
+
+func TestRun_PublishesStreams(t *testing.T) {
+	assert := assert.New(t)
+
+	target := &stubTarget{calls: make(map[string][]uint64), err: errors.New("some error")}
+	source := []*segment{{data: []byte("seg0"), duration: 10 * time.Millisecond}, {data: []byte("seg1"), duration: 10 * time.Millisecond}}
+	results := run(context.Background(), target, 3, 4, source, true, time.Millisecond, time.Second)
+
+	assert.Len(results, 3)
+	assert.Len(target.calls, 3)
+	for _, seqNos := range target.calls {
+		assert.Equal([]uint64{0, 1, 2, 3}, seqNos)
+	}
+
+	s := summarize(results)
+	assert.Equal(3, s.streams)
+	assert.Equal(12, s.segments)
+	assert.Equal(9, s.succeeded)
+	assert.InDelta(0.75, s.successRate(), 1e-9)
+	assert.Equal(map[string]int{"some error": 3}, s.errors)
+
+	// stops publishing once canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	target.calls = make(map[string][]uint64)
+	results = run(ctx, target, 1, 4, source, true, time.Millisecond, time.Second)
+	assert.Len(results[0], 0)
+}
+
+func TestSummary_Latencies(t *testing.T) {
+	assert := assert.New(t)
+
+	var results []result
+	for i := 10; i >= 1; i-- {
+		results = append(results, result{latency: time.Duration(i) * time.Millisecond})
+	}
+	s := summarize([][]result{results})
+	assert.Equal(1.0, s.successRate())
+	assert.Equal(5*time.Millisecond, s.percentile(0.5))
+	assert.Equal(10*time.Millisecond, s.percentile(0.95))
+	assert.Equal(10*time.Millisecond, s.percentile(1))
+	assert.Equal(1*time.Millisecond, s.percentile(0))
+	assert.Equal(5500*time.Microsecond, s.average())
+
+	s = summarize(nil)
+	assert.Equal(0.0, s.successRate())
+	assert.Equal(time.Duration(0), s.percentile(0.5))
+	assert.Equal(time.Duration(0), s.average())
+}
+
+func TestBroadcasterTarget(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	renditions := 2
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("POST", r.Method)
+		assert.Equal("/live/foo/3.ts", r.URL.Path)
+		assert.Equal("multipart/mixed", r.Header.Get("Accept"))
+		assert.Equal("2000", r.Header.Get("Content-Duration"))
+		var config map[string]interface{}
+		require.Nil(json.Unmarshal([]byte(r.Header.Get(server.LIVERPEER_TRANSCODE_CONFIG_HEADER)), &config))
+		assert.Equal("foo", config["manifestID"])
+		assert.Equal([]interface{}{"P240p30fps16x9", "P360p30fps16x9"}, config["presets"])
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal([]byte("seg"), body)
+
+		if renditions < 0 {
+			http.Error(w, "some error", http.StatusInternalServerError)
+			return
+		}
+		mw := multipart.NewWriter(w)
+		w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < renditions; i++ {
+			fw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"video/MP2T"}})
+			require.Nil(err)
+			fw.Write([]byte(fmt.Sprintf("rendition%d", i)))
+		}
+		mw.Close()
+	}))
+	defer ts.Close()
+
+	uri, err := url.Parse(ts.URL)
+	require.Nil(err)
+	target := &broadcasterTarget{url: uri, presets: []string{"P240p30fps16x9", "P360p30fps16x9"}, renditions: 2, client: &http.Client{}}
+	seg := &segment{data: []byte("seg"), duration: 2 * time.Second}
+
+	assert.Nil(target.transcode(context.Background(), "foo", 3, seg))
+
+	renditions = 1
+	assert.EqualError(target.transcode(context.Background(), "foo", 3, seg), "expected renditions=2 got=1")
+
+	renditions = -1
+	assert.EqualError(target.transcode(context.Background(), "foo", 3, seg), "status=500")
+}
@@ -23,6 +23,8 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/alert"
+	"github.com/livepeer/go-livepeer/broker"
 	"github.com/livepeer/go-livepeer/build"
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/core"
@@ -33,6 +35,7 @@ import (
 	lpmon "github.com/livepeer/go-livepeer/monitor"
 	"github.com/livepeer/go-livepeer/pm"
 	"github.com/livepeer/go-livepeer/server"
+	"github.com/livepeer/go-livepeer/storage"
 	"github.com/livepeer/go-livepeer/verification"
 	"github.com/livepeer/go-tools/drivers"
 	"github.com/livepeer/livepeer-data/pkg/event"
@@ -42,8 +45,6 @@ import (
 var (
 	// The timeout for ETH RPC calls
 	ethRPCTimeout = 20 * time.Second
-	// The maximum blocks for the block watcher to retain
-	blockWatcherRetentionLimit = 20
 
 	// Estimate of the gas required to redeem a PM ticket on L1 Ethereum
 	redeemGasL1 = 350000
@@ -56,11 +57,15 @@ var (
 	cleanupInterval = 1 * time.Minute
 	// The time to live for cached max float values for PM senders (else they will be cleaned up) in seconds
 	smTTL = 60 // 1 minute
+
+	// The interval at which to record the size of the DB and the waits for its connection
+	dbMetricsInterval = 15 * time.Second
 )
 
 const RtmpPort = "1935"
 const RpcPort = "8935"
 const CliPort = "7935"
+const AdminPort = "7936"
 
 type LivepeerConfig struct {
 	Network                      *string
@@ -68,6 +73,9 @@ type LivepeerConfig struct {
 	CliAddr                      *string
 	HttpAddr                     *string
 	ServiceAddr                  *string
+	Region                       *string
+	Datacenter                   *string
+	KeepCert                     *bool
 	OrchAddr                     *string
 	VerifierURL                  *string
 	EthController                *string
@@ -78,10 +86,33 @@ type LivepeerConfig struct {
 	Transcoder                   *bool
 	Broadcaster                  *bool
 	OrchSecret                   *string
+	BroadcasterSecret            *string
+	DiscoveryRateLimitPerIP      *float64
+	DiscoveryRateLimitPerSender  *float64
+	DiscoveryRateLimitBurst      *int
+	DelegationAPIToken           *string
+	DebugAPIToken                *string
+	AdminAddr                    *string
+	AdminAPIToken                *string
 	TranscodingOptions           *string
 	MaxAttempts                  *int
+	RetryBudget                  *time.Duration
+	RetryBackoff                 *time.Duration
+	RetryMaxBackoff              *time.Duration
+	MaxTimeoutRetries            *int
+	MaxRefusalRetries            *int
+	MaxVerificationRetries       *int
 	SelectRandFreq               *float64
+	StakeWeightedSelection       *bool
+	OrchLatencyProbeInterval     *time.Duration
+	SessionRotationInterval      *time.Duration
 	MaxSessions                  *int
+	SessionQueueTimeout          *time.Duration
+	SessionQueueSize             *int
+	SessionRetryAfter            *time.Duration
+	MaxSessionsPerBroadcaster    *int
+	MaxSegmentDuration           *time.Duration
+	MaxSegmentSize               *int
 	CurrentManifest              *bool
 	Nvidia                       *string
 	Netint                       *string
@@ -92,6 +123,13 @@ type LivepeerConfig struct {
 	EthAcctAddr                  *string
 	EthPassword                  *string
 	EthKeystorePath              *string
+	EthSigner                    *string
+	EthLedgerPath                *string
+	EthSignerURL                 *string
+	EthKMSKey                    *string
+	ENSURL                       *string
+	ENSRefreshInterval           *time.Duration
+	SRVRefreshInterval           *time.Duration
 	EthOrchAddr                  *string
 	EthUrl                       *string
 	TxTimeout                    *time.Duration
@@ -100,32 +138,109 @@ type LivepeerConfig struct {
 	MinGasPrice                  *int64
 	MaxGasPrice                  *int
 	InitializeRound              *bool
+	InitializeRoundMaxDelay      *time.Duration
 	TicketEV                     *string
 	MaxFaceValue                 *string
+	TicketRedeemBatchSize        *int
+	TicketRedeemRelayURL         *string
+	PaymentWebhookURL            *string
+	PaymentWebhookEvents         *string
+	MinSenderReserve             *string
+	MaxUnredeemableTickets       *int
+	SenderCoolOff                *time.Duration
+	MaxSenderExposure            *string
+	TicketFaceValueStrategy      *string
+	TicketFaceValue              *string
+	TicketFaceValueSmoothing     *time.Duration
+	TicketSenderFaceValueScales  *string
 	MaxTicketEV                  *string
 	DepositMultiplier            *int
+	TopUpMinDeposit              *string
+	TopUpDepositAmount           *string
+	TopUpMinReserve              *string
+	TopUpReserveAmount           *string
+	FeeWithdrawAddr              *string
+	FeeWithdrawMinFees           *string
+	FeeWithdrawMaxGasPrice       *string
+	FeeWithdrawInterval          *time.Duration
+	ClaimEarningsRounds          *int
 	PricePerUnit                 *int
 	MaxPricePerUnit              *int
+	MaxPricePerCapability        *string
 	PixelsPerUnit                *int
 	AutoAdjustPrice              *bool
 	PricePerBroadcaster          *string
+	UtilizationPricing           *string
+	BroadcasterAllowlist         *string
+	BroadcasterBlocklist         *string
 	BlockPollingInterval         *int
+	BackfillBlockRange           *int
+	BackfillConcurrency          *int
+	MaxReorgDepth                *int
 	Redeemer                     *bool
 	RedeemerAddr                 *string
 	Reward                       *bool
+	RewardMaxAttempts            *int
+	RewardRetryInterval          *time.Duration
+	RewardPriceBump              *int
+	RewardAlertWebhookURL        *string
+	EthBalanceLow                *string
+	EthBalanceCritical           *string
+	EthBalanceSafeMode           *bool
+	EthBalanceAlertWebhookURL    *string
 	Monitor                      *bool
 	MetricsPerStream             *bool
 	MetricsExposeClientIP        *bool
+	MetricsMaxStreams            *int
+	MetricsStreamTTL             *time.Duration
+	MetricsAddr                  *string
+	MetricsPushURL               *string
+	MetricsPushFormat            *string
+	MetricsPushInterval          *time.Duration
+	TracingEndpoint              *string
+	TracingSampleRate            *float64
+	GPUMetricsInterval           *time.Duration
+	AlertWebhookURLs             *string
+	AlertTypes                   *string
+	AlertCooldown                *time.Duration
+	AlertBlockStallTimeout       *time.Duration
 	MetadataQueueUri             *string
 	MetadataAmqpExchange         *string
 	MetadataPublishTimeout       *time.Duration
 	Datadir                      *string
 	Objectstore                  *string
 	Recordstore                  *string
+	RecordArchiveStore           *string
+	RecordArchiveDir             *string
+	StoreHealthCheckInterval     *time.Duration
+	RecordPathTemplate           *string
 	FVfailGsBucket               *string
 	FVfailGsKey                  *string
 	AuthWebhookURL               *string
+	IngestJWTKeys                *string
 	OrchWebhookURL               *string
+	Gossip                       *bool
+	GossipPeers                  *string
+	GossipInterval               *time.Duration
+	OrchInfoCacheTTL             *time.Duration
+	MinDiscoveryParallelism      *int
+	MaxDiscoveryParallelism      *int
+	MinDiscoveryTimeout          *time.Duration
+	MaxDiscoveryTimeout          *time.Duration
+	OrchPoolRefreshInterval      *time.Duration
+	OrchAllowlist                *string
+	OrchBlocklist                *string
+	OrchRegions                  *string
+	RequireOrchRegion            *bool
+	OrchReputation               *bool
+	OrchReputationWeights        *string
+	OrchCertPins                 *string
+	LocalFallback                *bool
+	RedundantTranscoding         *bool
+	SplitRenditions              *bool
+	MaxSpendPerStream            *string
+	MaxSpendPerHour              *string
+	SpendLimitAlertOnly          *bool
 	DetectionWebhookURL          *string
 }
 
@@ -137,6 +252,9 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultCliAddr := "127.0.0.1:" + CliPort
 	defaultHttpAddr := ""
 	defaultServiceAddr := ""
+	defaultRegion := ""
+	defaultDatacenter := ""
+	defaultKeepCert := false
 	defaultOrchAddr := ""
 	defaultVerifierURL := ""
 	defaultVerifierPath := ""
@@ -146,10 +264,33 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultTranscoder := false
 	defaultBroadcaster := false
 	defaultOrchSecret := ""
+	defaultBroadcasterSecret := ""
+	defaultDiscoveryRateLimitPerIP := 0.0
+	defaultDiscoveryRateLimitPerSender := 0.0
+	defaultDiscoveryRateLimitBurst := 10
+	defaultDelegationAPIToken := ""
+	defaultDebugAPIToken := ""
+	defaultAdminAddr := ""
+	defaultAdminAPIToken := ""
 	defaultTranscodingOptions := "P240p30fps16x9,P360p30fps16x9"
 	defaultMaxAttempts := 3
+	defaultRetryBudget := time.Duration(0)
+	defaultRetryBackoff := time.Duration(0)
+	defaultRetryMaxBackoff := time.Duration(0)
+	defaultMaxTimeoutRetries := -1
+	defaultMaxRefusalRetries := -1
+	defaultMaxVerificationRetries := -1
 	defaultSelectRandFreq := 0.3
+	defaultStakeWeightedSelection := false
+	defaultOrchLatencyProbeInterval := time.Duration(0)
+	defaultSessionRotationInterval := time.Duration(0)
 	defaultMaxSessions := 10
+	defaultSessionQueueTimeout := time.Duration(0)
+	defaultSessionQueueSize := 10
+	defaultSessionRetryAfter := 5 * time.Second
+	defaultMaxSessionsPerBroadcaster := 0
+	defaultMaxSegmentDuration := time.Duration(0)
+	defaultMaxSegmentSize := 0
 	defaultCurrentManifest := false
 	defaultNvidia := ""
 	defaultNetint := ""
@@ -162,6 +303,13 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultEthAcctAddr := ""
 	defaultEthPassword := ""
 	defaultEthKeystorePath := ""
+	defaultEthSigner := "keystore"
+	defaultEthLedgerPath := eth.DefaultLedgerPath
+	defaultEthSignerURL := ""
+	defaultEthKMSKey := ""
+	defaultENSURL := ""
+	defaultENSRefreshInterval := time.Hour
+	defaultSRVRefreshInterval := 5 * time.Minute
 	defaultEthOrchAddr := ""
 	defaultEthUrl := ""
 	defaultTxTimeout := 5 * time.Minute
@@ -170,20 +318,70 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultMaxGasPrice := 0
 	defaultEthController := ""
 	defaultInitializeRound := false
+	defaultInitializeRoundMaxDelay := 30 * time.Second
 	defaultTicketEV := "1000000000000"
 	defaultMaxFaceValue := "0"
+	defaultTicketRedeemBatchSize := 1
+	defaultTicketRedeemRelayURL := ""
+	defaultPaymentWebhookURL := ""
+	defaultPaymentWebhookEvents := ""
+	defaultMinSenderReserve := ""
+	defaultMaxUnredeemableTickets := 0
+	defaultSenderCoolOff := time.Hour
+	defaultMaxSenderExposure := ""
+	defaultTicketFaceValueStrategy := "txCostMultiplier"
+	defaultTicketFaceValue := ""
+	defaultTicketFaceValueSmoothing := 30 * time.Minute
+	defaultTicketSenderFaceValueScales := ""
 	defaultMaxTicketEV := "3000000000000"
 	defaultDepositMultiplier := 1
+	defaultTopUpMinDeposit := ""
+	defaultTopUpDepositAmount := ""
+	defaultTopUpMinReserve := ""
+	defaultTopUpReserveAmount := ""
+	defaultFeeWithdrawAddr := ""
+	defaultFeeWithdrawMinFees := ""
+	defaultFeeWithdrawMaxGasPrice := ""
+	defaultFeeWithdrawInterval := time.Hour
+	defaultClaimEarningsRounds := 0
 	defaultMaxPricePerUnit := 0
+	defaultMaxPricePerCapability := ""
 	defaultPixelsPerUnit := 1
 	defaultAutoAdjustPrice := true
 	defaultpricePerBroadcaster := ""
+	defaultUtilizationPricing := ""
+	defaultBroadcasterAllowlist := ""
+	defaultBroadcasterBlocklist := ""
 	defaultBlockPollingInterval := 5
+	defaultBackfillBlockRange := 1000
+	defaultBackfillConcurrency := 3
+	defaultMaxReorgDepth := 20
 	defaultRedeemer := false
 	defaultRedeemerAddr := ""
+	defaultRewardMaxAttempts := 5
+	defaultRewardRetryInterval := 5 * time.Minute
+	defaultRewardPriceBump := 20
+	defaultRewardAlertWebhookURL := ""
+	defaultEthBalanceLow := ""
+	defaultEthBalanceCritical := ""
+	defaultEthBalanceSafeMode := false
+	defaultEthBalanceAlertWebhookURL := ""
 	defaultMonitor := false
 	defaultMetricsPerStream := false
 	defaultMetricsExposeClientIP := false
+	defaultMetricsMaxStreams := 0
+	defaultMetricsStreamTTL := time.Duration(0)
+	defaultMetricsAddr := ""
+	defaultMetricsPushURL := ""
+	defaultMetricsPushFormat := lpmon.PushFormatPushgateway
+	defaultMetricsPushInterval := 15 * time.Second
+	defaultTracingEndpoint := ""
+	defaultTracingSampleRate := 1.0
+	defaultGPUMetricsInterval := 15 * time.Second
+	defaultAlertWebhookURLs := ""
+	defaultAlertTypes := ""
+	defaultAlertCooldown := 15 * time.Minute
+	defaultAlertBlockStallTimeout := 10 * time.Minute
 	defaultMetadataQueueUri := ""
 	defaultMetadataAmqpExchange := "lp_golivepeer_metadata"
 	defaultMetadataPublishTimeout := 1 * time.Second
@@ -198,6 +396,10 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultDatadir := ""
 	defaultObjectstore := ""
 	defaultRecordstore := ""
+	defaultRecordArchiveStore := ""
+	defaultRecordArchiveDir := ""
+	defaultStoreHealthCheckInterval := 5 * time.Minute
+	defaultRecordPathTemplate := ""
 
 	// Fast Verification GS bucket:
 	defaultFVfailGsBucket := ""
@@ -205,7 +407,30 @@ func DefaultLivepeerConfig() LivepeerConfig {
 
 	// API
 	defaultAuthWebhookURL := ""
+	defaultIngestJWTKeys := ""
 	defaultOrchWebhookURL := ""
+	defaultGossip := false
+	defaultGossipPeers := ""
+	defaultGossipInterval := time.Minute
+	defaultOrchInfoCacheTTL := time.Duration(0)
+	defaultMinDiscoveryParallelism := 10
+	defaultMaxDiscoveryParallelism := 100
+	defaultMinDiscoveryTimeout := 500 * time.Millisecond
+	defaultMaxDiscoveryTimeout := 6 * time.Second
+	defaultOrchPoolRefreshInterval := time.Hour
+	defaultOrchAllowlist := ""
+	defaultOrchBlocklist := ""
+	defaultOrchRegions := ""
+	defaultRequireOrchRegion := false
+	defaultOrchReputation := false
+	defaultOrchReputationWeights := ""
+	defaultOrchCertPins := ""
+	defaultLocalFallback := false
+	defaultRedundantTranscoding := false
+	defaultSplitRenditions := false
+	defaultMaxSpendPerStream := ""
+	defaultMaxSpendPerHour := ""
+	defaultSpendLimitAlertOnly := false
 	defaultDetectionWebhookURL := ""
 
 	return LivepeerConfig{
@@ -215,6 +440,9 @@ func DefaultLivepeerConfig() LivepeerConfig {
 		CliAddr:      &defaultCliAddr,
 		HttpAddr:     &defaultHttpAddr,
 		ServiceAddr:  &defaultServiceAddr,
+		Region:       &defaultRegion,
+		Datacenter:   &defaultDatacenter,
+		KeepCert:     &defaultKeepCert,
 		OrchAddr:     &defaultOrchAddr,
 		VerifierURL:  &defaultVerifierURL,
 		VerifierPath: &defaultVerifierPath,
@@ -224,10 +452,33 @@ func DefaultLivepeerConfig() LivepeerConfig {
 		Transcoder:                   &defaultTranscoder,
 		Broadcaster:                  &defaultBroadcaster,
 		OrchSecret:                   &defaultOrchSecret,
+		BroadcasterSecret:            &defaultBroadcasterSecret,
+		DiscoveryRateLimitPerIP:      &defaultDiscoveryRateLimitPerIP,
+		DiscoveryRateLimitPerSender:  &defaultDiscoveryRateLimitPerSender,
+		DiscoveryRateLimitBurst:      &defaultDiscoveryRateLimitBurst,
+		DelegationAPIToken:           &defaultDelegationAPIToken,
+		DebugAPIToken:                &defaultDebugAPIToken,
+		AdminAddr:                    &defaultAdminAddr,
+		AdminAPIToken:                &defaultAdminAPIToken,
 		TranscodingOptions:           &defaultTranscodingOptions,
 		MaxAttempts:                  &defaultMaxAttempts,
+		RetryBudget:                  &defaultRetryBudget,
+		RetryBackoff:                 &defaultRetryBackoff,
+		RetryMaxBackoff:              &defaultRetryMaxBackoff,
+		MaxTimeoutRetries:            &defaultMaxTimeoutRetries,
+		MaxRefusalRetries:            &defaultMaxRefusalRetries,
+		MaxVerificationRetries:       &defaultMaxVerificationRetries,
 		SelectRandFreq:               &defaultSelectRandFreq,
+		StakeWeightedSelection:       &defaultStakeWeightedSelection,
+		OrchLatencyProbeInterval:     &defaultOrchLatencyProbeInterval,
+		SessionRotationInterval:      &defaultSessionRotationInterval,
 		MaxSessions:                  &defaultMaxSessions,
+		SessionQueueTimeout:          &defaultSessionQueueTimeout,
+		SessionQueueSize:             &defaultSessionQueueSize,
+		SessionRetryAfter:            &defaultSessionRetryAfter,
+		MaxSessionsPerBroadcaster:    &defaultMaxSessionsPerBroadcaster,
+		MaxSegmentDuration:           &defaultMaxSegmentDuration,
+		MaxSegmentSize:               &defaultMaxSegmentSize,
 		CurrentManifest:              &defaultCurrentManifest,
 		Nvidia:                       &defaultNvidia,
 		Netint:                       &defaultNetint,
@@ -237,34 +488,91 @@ func DefaultLivepeerConfig() LivepeerConfig {
 		DetectionSampleRate:          &defaultDetectionSampleRate,
 
 		// Onchain:
-		EthAcctAddr:            &defaultEthAcctAddr,
-		EthPassword:            &defaultEthPassword,
-		EthKeystorePath:        &defaultEthKeystorePath,
-		EthOrchAddr:            &defaultEthOrchAddr,
-		EthUrl:                 &defaultEthUrl,
-		TxTimeout:              &defaultTxTimeout,
-		MaxTxReplacements:      &defaultMaxTxReplacements,
-		GasLimit:               &defaultGasLimit,
-		MaxGasPrice:            &defaultMaxGasPrice,
-		EthController:          &defaultEthController,
-		InitializeRound:        &defaultInitializeRound,
-		TicketEV:               &defaultTicketEV,
-		MaxFaceValue:           &defaultMaxFaceValue,
-		MaxTicketEV:            &defaultMaxTicketEV,
-		DepositMultiplier:      &defaultDepositMultiplier,
-		MaxPricePerUnit:        &defaultMaxPricePerUnit,
-		PixelsPerUnit:          &defaultPixelsPerUnit,
-		AutoAdjustPrice:        &defaultAutoAdjustPrice,
-		PricePerBroadcaster:    &defaultpricePerBroadcaster,
-		BlockPollingInterval:   &defaultBlockPollingInterval,
-		Redeemer:               &defaultRedeemer,
-		RedeemerAddr:           &defaultRedeemerAddr,
-		Monitor:                &defaultMonitor,
-		MetricsPerStream:       &defaultMetricsPerStream,
-		MetricsExposeClientIP:  &defaultMetricsExposeClientIP,
-		MetadataQueueUri:       &defaultMetadataQueueUri,
-		MetadataAmqpExchange:   &defaultMetadataAmqpExchange,
-		MetadataPublishTimeout: &defaultMetadataPublishTimeout,
+		EthAcctAddr:                 &defaultEthAcctAddr,
+		EthPassword:                 &defaultEthPassword,
+		EthKeystorePath:             &defaultEthKeystorePath,
+		EthSigner:                   &defaultEthSigner,
+		EthLedgerPath:               &defaultEthLedgerPath,
+		EthSignerURL:                &defaultEthSignerURL,
+		EthKMSKey:                   &defaultEthKMSKey,
+		ENSURL:                      &defaultENSURL,
+		ENSRefreshInterval:          &defaultENSRefreshInterval,
+		SRVRefreshInterval:          &defaultSRVRefreshInterval,
+		EthOrchAddr:                 &defaultEthOrchAddr,
+		EthUrl:                      &defaultEthUrl,
+		TxTimeout:                   &defaultTxTimeout,
+		MaxTxReplacements:           &defaultMaxTxReplacements,
+		GasLimit:                    &defaultGasLimit,
+		MaxGasPrice:                 &defaultMaxGasPrice,
+		EthController:               &defaultEthController,
+		InitializeRound:             &defaultInitializeRound,
+		InitializeRoundMaxDelay:     &defaultInitializeRoundMaxDelay,
+		TicketEV:                    &defaultTicketEV,
+		MaxFaceValue:                &defaultMaxFaceValue,
+		TicketRedeemBatchSize:       &defaultTicketRedeemBatchSize,
+		TicketRedeemRelayURL:        &defaultTicketRedeemRelayURL,
+		PaymentWebhookURL:           &defaultPaymentWebhookURL,
+		PaymentWebhookEvents:        &defaultPaymentWebhookEvents,
+		MinSenderReserve:            &defaultMinSenderReserve,
+		MaxUnredeemableTickets:      &defaultMaxUnredeemableTickets,
+		SenderCoolOff:               &defaultSenderCoolOff,
+		MaxSenderExposure:           &defaultMaxSenderExposure,
+		TicketFaceValueStrategy:     &defaultTicketFaceValueStrategy,
+		TicketFaceValue:             &defaultTicketFaceValue,
+		TicketFaceValueSmoothing:    &defaultTicketFaceValueSmoothing,
+		TicketSenderFaceValueScales: &defaultTicketSenderFaceValueScales,
+		MaxTicketEV:                 &defaultMaxTicketEV,
+		DepositMultiplier:           &defaultDepositMultiplier,
+		TopUpMinDeposit:             &defaultTopUpMinDeposit,
+		TopUpDepositAmount:          &defaultTopUpDepositAmount,
+		TopUpMinReserve:             &defaultTopUpMinReserve,
+		TopUpReserveAmount:          &defaultTopUpReserveAmount,
+		FeeWithdrawAddr:             &defaultFeeWithdrawAddr,
+		FeeWithdrawMinFees:          &defaultFeeWithdrawMinFees,
+		FeeWithdrawMaxGasPrice:      &defaultFeeWithdrawMaxGasPrice,
+		FeeWithdrawInterval:         &defaultFeeWithdrawInterval,
+		ClaimEarningsRounds:         &defaultClaimEarningsRounds,
+		MaxPricePerUnit:             &defaultMaxPricePerUnit,
+		MaxPricePerCapability:       &defaultMaxPricePerCapability,
+		PixelsPerUnit:               &defaultPixelsPerUnit,
+		AutoAdjustPrice:             &defaultAutoAdjustPrice,
+		PricePerBroadcaster:         &defaultpricePerBroadcaster,
+		UtilizationPricing:          &defaultUtilizationPricing,
+		BroadcasterAllowlist:        &defaultBroadcasterAllowlist,
+		BroadcasterBlocklist:        &defaultBroadcasterBlocklist,
+		BlockPollingInterval:        &defaultBlockPollingInterval,
+		BackfillBlockRange:          &defaultBackfillBlockRange,
+		BackfillConcurrency:         &defaultBackfillConcurrency,
+		MaxReorgDepth:               &defaultMaxReorgDepth,
+		Redeemer:                    &defaultRedeemer,
+		RedeemerAddr:                &defaultRedeemerAddr,
+		RewardMaxAttempts:           &defaultRewardMaxAttempts,
+		RewardRetryInterval:         &defaultRewardRetryInterval,
+		RewardPriceBump:             &defaultRewardPriceBump,
+		RewardAlertWebhookURL:       &defaultRewardAlertWebhookURL,
+		EthBalanceLow:               &defaultEthBalanceLow,
+		EthBalanceCritical:          &defaultEthBalanceCritical,
+		EthBalanceSafeMode:          &defaultEthBalanceSafeMode,
+		EthBalanceAlertWebhookURL:   &defaultEthBalanceAlertWebhookURL,
+		Monitor:                     &defaultMonitor,
+		MetricsPerStream:            &defaultMetricsPerStream,
+		MetricsExposeClientIP:       &defaultMetricsExposeClientIP,
+		MetricsMaxStreams:           &defaultMetricsMaxStreams,
+		MetricsStreamTTL:            &defaultMetricsStreamTTL,
+		MetricsAddr:                 &defaultMetricsAddr,
+		MetricsPushURL:              &defaultMetricsPushURL,
+		MetricsPushFormat:           &defaultMetricsPushFormat,
+		MetricsPushInterval:         &defaultMetricsPushInterval,
+		TracingEndpoint:             &defaultTracingEndpoint,
+		TracingSampleRate:           &defaultTracingSampleRate,
+		GPUMetricsInterval:          &defaultGPUMetricsInterval,
+		AlertWebhookURLs:            &defaultAlertWebhookURLs,
+		AlertTypes:                  &defaultAlertTypes,
+		AlertCooldown:               &defaultAlertCooldown,
+		AlertBlockStallTimeout:      &defaultAlertBlockStallTimeout,
+		MetadataQueueUri:            &defaultMetadataQueueUri,
+		MetadataAmqpExchange:        &defaultMetadataAmqpExchange,
+		MetadataPublishTimeout:      &defaultMetadataPublishTimeout,
 
 		// Ingest:
 		HttpIngest: &defaultHttpIngest,
@@ -273,18 +581,46 @@ func DefaultLivepeerConfig() LivepeerConfig {
 		LocalVerify: &defaultLocalVerify,
 
 		// Storage:
-		Datadir:     &defaultDatadir,
-		Objectstore: &defaultObjectstore,
-		Recordstore: &defaultRecordstore,
+		Datadir:            &defaultDatadir,
+		Objectstore:        &defaultObjectstore,
+		Recordstore:        &defaultRecordstore,
+		RecordArchiveStore: &defaultRecordArchiveStore,
+		RecordArchiveDir:   &defaultRecordArchiveDir,
+
+		StoreHealthCheckInterval: &defaultStoreHealthCheckInterval,
+		RecordPathTemplate:       &defaultRecordPathTemplate,
 
 		// Fast Verification GS bucket:
 		FVfailGsBucket: &defaultFVfailGsBucket,
 		FVfailGsKey:    &defaultFVfailGsKey,
 
 		// API
-		AuthWebhookURL:      &defaultAuthWebhookURL,
-		OrchWebhookURL:      &defaultOrchWebhookURL,
-		DetectionWebhookURL: &defaultDetectionWebhookURL,
+		AuthWebhookURL:          &defaultAuthWebhookURL,
+		IngestJWTKeys:           &defaultIngestJWTKeys,
+		OrchWebhookURL:          &defaultOrchWebhookURL,
+		Gossip:                  &defaultGossip,
+		GossipPeers:             &defaultGossipPeers,
+		GossipInterval:          &defaultGossipInterval,
+		OrchInfoCacheTTL:        &defaultOrchInfoCacheTTL,
+		MinDiscoveryParallelism: &defaultMinDiscoveryParallelism,
+		MaxDiscoveryParallelism: &defaultMaxDiscoveryParallelism,
+		MinDiscoveryTimeout:     &defaultMinDiscoveryTimeout,
+		MaxDiscoveryTimeout:     &defaultMaxDiscoveryTimeout,
+		OrchPoolRefreshInterval: &defaultOrchPoolRefreshInterval,
+		OrchAllowlist:           &defaultOrchAllowlist,
+		OrchBlocklist:           &defaultOrchBlocklist,
+		OrchRegions:             &defaultOrchRegions,
+		RequireOrchRegion:       &defaultRequireOrchRegion,
+		OrchReputation:          &defaultOrchReputation,
+		OrchReputationWeights:   &defaultOrchReputationWeights,
+		OrchCertPins:            &defaultOrchCertPins,
+		LocalFallback:           &defaultLocalFallback,
+		RedundantTranscoding:    &defaultRedundantTranscoding,
+		SplitRenditions:         &defaultSplitRenditions,
+		MaxSpendPerStream:       &defaultMaxSpendPerStream,
+		MaxSpendPerHour:         &defaultMaxSpendPerHour,
+		SpendLimitAlertOnly:     &defaultSpendLimitAlertOnly,
+		DetectionWebhookURL:     &defaultDetectionWebhookURL,
 	}
 }
 
@@ -337,7 +673,7 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 	}
 
 	// If multiple orchAddr specified, ensure other necessary flags present and clean up list
-	orchURLs := parseOrchAddrs(*cfg.OrchAddr)
+	orchURLs, orchENSNames, orchSRVNames := parseOrchAddrs(*cfg.OrchAddr)
 
 	// Setting config options based on specified network
 	var redeemGas int
@@ -362,6 +698,61 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		glog.Infof("***Livepeer is running on the %v network***", *cfg.Network)
 	}
 
+	// Offchain orchestrators announce themselves through gossip, and broadcasters discover them from it
+	var gossip *discovery.Gossip
+	if *cfg.Gossip {
+		if *cfg.Network != "offchain" {
+			glog.Errorf("-gossip is only supported on the offchain network, but %v provided. Restart the node with -network=offchain or without -gossip", *cfg.Network)
+			return
+		}
+		if *cfg.GossipInterval <= 0 {
+			glog.Errorf("-gossipInterval must be greater than 0, but %v provided. Restart the node with a different valid value for -gossipInterval", *cfg.GossipInterval)
+			return
+		}
+		// Gossip messages are authenticated with the secret shared by the nodes of the network
+		if *cfg.BroadcasterSecret == "" {
+			glog.Errorf("-gossip requires -broadcasterSecret to be set")
+			return
+		}
+	} else if *cfg.GossipPeers != "" {
+		glog.Errorf("-gossipPeers requires -gossip to be set")
+		return
+	}
+
+	// ENS names of addresses are resolved at startup, orchestrator URIs are periodically re-resolved
+	var ensResolver *eth.ENSResolver
+	if len(orchENSNames) > 0 || eth.IsENSName(*cfg.EthController) || eth.IsENSName(*cfg.EthOrchAddr) || hasENSName(*cfg.RedeemerAddr) {
+		ensURL := *cfg.ENSURL
+		if ensURL == "" {
+			ensURL = *cfg.EthUrl
+		}
+		if ensURL == "" {
+			glog.Fatal("Need to specify an Ethereum L1 node JSON-RPC URL using -ensUrl to resolve ENS names")
+		}
+		ensRPCClient, err := eth.DialRPC(ctx, ensURL)
+		if err != nil {
+			glog.Errorf("Failed to connect to Ethereum client for ENS: %v", err)
+			return
+		}
+		ensResolver, err = eth.NewENSResolver(ethclient.NewClient(ensRPCClient), eth.ENSRegistryAddr)
+		if err != nil {
+			glog.Errorf("Failed to create ENS resolver: %v", err)
+			return
+		}
+		for _, addr := range []*string{cfg.EthController, cfg.EthOrchAddr} {
+			if !eth.IsENSName(*addr) {
+				continue
+			}
+			resolved, err := ensResolver.Addr(*addr)
+			if err != nil {
+				glog.Errorf("Error resolving ENS name: %v", err)
+				return
+			}
+			glog.Infof("Resolved ENS name=%v address=%v", *addr, resolved.Hex())
+			*addr = resolved.Hex()
+		}
+	}
+
 	if *cfg.Datadir == "" {
 		homedir := os.Getenv("HOME")
 		if homedir == "" {
@@ -404,7 +795,20 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		n.OrchSecret, _ = common.ReadFromFile(*cfg.OrchSecret)
 	}
 
+	if *cfg.BroadcasterSecret != "" {
+		server.BroadcasterSecret, _ = common.ReadFromFile(*cfg.BroadcasterSecret)
+	}
+
+	if *cfg.DelegationAPIToken != "" {
+		server.DelegationAPIToken, _ = common.ReadFromFile(*cfg.DelegationAPIToken)
+	}
+
+	if *cfg.DebugAPIToken != "" {
+		server.DebugAPIToken, _ = common.ReadFromFile(*cfg.DebugAPIToken)
+	}
+
 	var transcoderCaps []core.Capability
+	var nvidiaDevices []string
 	if *cfg.Transcoder {
 		core.WorkDir = *cfg.Datadir
 		accel := ffmpeg.Software
@@ -430,6 +834,9 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 				glog.Fatalf("Error while parsing '-%v %v' flag: %v", strings.ToLower(accelName), devices, err)
 			}
 			glog.Infof("Transcoding on these %v devices: %v", accelName, devices)
+			if accel == ffmpeg.Nvidia {
+				nvidiaDevices = devices
+			}
 			// Test transcoding with specified device
 			if *cfg.TestTranscoder {
 				transcoderCaps, err = core.TestTranscoderCapabilities(devices, tf)
@@ -478,6 +885,26 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			n.TranscoderManager = core.NewRemoteTranscoderManager()
 			n.Transcoder = n.TranscoderManager
 		}
+
+		allowlist, err := core.ParseBroadcasterAddresses(*cfg.BroadcasterAllowlist)
+		if err != nil {
+			glog.Errorf("Error parsing -broadcasterAllowlist: %v", err)
+			return
+		}
+		for _, addr := range allowlist {
+			n.BroadcasterAccess.Allow(addr)
+		}
+		blocklist, err := core.ParseBroadcasterAddresses(*cfg.BroadcasterBlocklist)
+		if err != nil {
+			glog.Errorf("Error parsing -broadcasterBlocklist: %v", err)
+			return
+		}
+		for _, addr := range blocklist {
+			n.BroadcasterAccess.Block(addr)
+		}
+		if len(allowlist) > 0 || len(blocklist) > 0 {
+			glog.Infof("Broadcaster access restricted allowlist=%v blocklist=%v", allowlist, blocklist)
+		}
 	} else if *cfg.Transcoder {
 		n.NodeType = core.TranscoderNode
 	} else if *cfg.Broadcaster {
@@ -493,6 +920,20 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 	hn, _ := os.Hostname()
 	lpmon.NodeID += hn
 
+	nodeType := lpmon.Default
+	switch n.NodeType {
+	case core.BroadcasterNode:
+		nodeType = lpmon.Broadcaster
+	case core.OrchestratorNode:
+		nodeType = lpmon.Orchestrator
+	case core.TranscoderNode:
+		nodeType = lpmon.Transcoder
+	case core.RedeemerNode:
+		nodeType = lpmon.Redeemer
+	}
+	if *cfg.MetricsAddr != "" || *cfg.MetricsPushURL != "" {
+		*cfg.Monitor = true
+	}
 	if *cfg.Monitor {
 		if *cfg.MetricsExposeClientIP {
 			*cfg.MetricsPerStream = true
@@ -500,18 +941,63 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		lpmon.Enabled = true
 		lpmon.PerStreamMetrics = *cfg.MetricsPerStream
 		lpmon.ExposeClientIP = *cfg.MetricsExposeClientIP
-		nodeType := lpmon.Default
-		switch n.NodeType {
-		case core.BroadcasterNode:
-			nodeType = lpmon.Broadcaster
-		case core.OrchestratorNode:
-			nodeType = lpmon.Orchestrator
-		case core.TranscoderNode:
-			nodeType = lpmon.Transcoder
-		case core.RedeemerNode:
-			nodeType = lpmon.Redeemer
-		}
+		lpmon.MaxStreamsPerStreamMetrics = *cfg.MetricsMaxStreams
+		lpmon.PerStreamMetricsTTL = *cfg.MetricsStreamTTL
 		lpmon.InitCensus(nodeType, core.LivepeerVersion)
+		if len(nvidiaDevices) > 0 && *cfg.GPUMetricsInterval > 0 {
+			if err := lpmon.StartGPUMetrics(ctx, nvidiaDevices, *cfg.GPUMetricsInterval); err != nil {
+				glog.Errorf("Error recording the GPU metrics err=%q", err)
+			}
+		}
+		dbh.StartMetrics(ctx, dbMetricsInterval)
+		if *cfg.MetricsAddr != "" {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", lpmon.NativeHandler())
+			go func() {
+				glog.Infof("Serving the Prometheus metrics on %s/metrics", *cfg.MetricsAddr)
+				if err := http.ListenAndServe(*cfg.MetricsAddr, mux); err != nil {
+					glog.Errorf("Error serving the Prometheus metrics addr=%s err=%q", *cfg.MetricsAddr, err)
+				}
+			}()
+		}
+		if *cfg.MetricsPushURL != "" {
+			if err := lpmon.StartMetricsPush(ctx, *cfg.MetricsPushURL, *cfg.MetricsPushFormat, *cfg.MetricsPushInterval); err != nil {
+				glog.Fatalf("Error pushing the metrics err=%q", err)
+			}
+		}
+	}
+	if *cfg.TracingEndpoint != "" {
+		if *cfg.TracingSampleRate < 0 || *cfg.TracingSampleRate > 1 {
+			glog.Errorf("-tracingSampleRate must be between 0 and 1, but %v provided", *cfg.TracingSampleRate)
+			return
+		}
+		shutdown, err := lpmon.InitTracing(*cfg.TracingEndpoint, *cfg.TracingSampleRate, nodeType, core.LivepeerVersion)
+		if err != nil {
+			glog.Errorf("Error initializing tracing err=%q", err)
+			return
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				glog.Errorf("Error flushing the spans err=%q", err)
+			}
+		}()
+		glog.Infof("Exporting the traces to %s", *cfg.TracingEndpoint)
+	}
+	if *cfg.AlertWebhookURLs != "" {
+		webhooks, err := alert.ParseWebhooks(*cfg.AlertWebhookURLs)
+		if err != nil {
+			glog.Errorf("Error parsing -alertWebhookUrls err=%q", err)
+			return
+		}
+		alertTypes, err := alert.ParseTypes(*cfg.AlertTypes)
+		if err != nil {
+			glog.Errorf("Error parsing -alertTypes err=%q", err)
+			return
+		}
+		alert.Init(alert.Config{Webhooks: webhooks, Types: alertTypes, Cooldown: *cfg.AlertCooldown, Node: lpmon.NodeID})
+		glog.Infof("Posting the alerts to webhooks=%v", webhooks)
 	}
 
 	watcherErr := make(chan error)
@@ -526,6 +1012,13 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		}
 
 	} else {
+		// In read-only mode the node has no account to sign with, so it can only read the chain
+		readOnly := *cfg.EthSigner == "none"
+		if readOnly && (n.NodeType != core.BroadcasterNode || (cfg.Reward != nil && *cfg.Reward) || *cfg.InitializeRound) {
+			glog.Errorf("-ethSigner=none is only supported by broadcasters that do not run the reward or round initialization services")
+			return
+		}
+
 		var keystoreDir string
 		if _, err := os.Stat(*cfg.EthKeystorePath); !os.IsNotExist(err) {
 			keystoreDir, _ = filepath.Split(*cfg.EthKeystorePath)
@@ -544,11 +1037,12 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		}
 
 		//Set up eth client
-		backend, err := ethclient.Dial(*cfg.EthUrl)
+		rpcClient, err := eth.DialRPC(ctx, *cfg.EthUrl)
 		if err != nil {
 			glog.Errorf("Failed to connect to Ethereum client: %v", err)
 			return
 		}
+		backend := ethclient.NewClient(rpcClient)
 
 		chainID, err := backend.ChainID(ctx)
 		if err != nil {
@@ -580,7 +1074,21 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		}
 		defer gpm.Stop()
 
-		am, err := eth.NewAccountManager(ethcommon.HexToAddress(*cfg.EthAcctAddr), keystoreDir, chainID, *cfg.EthPassword)
+		var am eth.AccountManager
+		switch *cfg.EthSigner {
+		case "keystore":
+			am, err = eth.NewAccountManager(ethcommon.HexToAddress(*cfg.EthAcctAddr), keystoreDir, chainID, *cfg.EthPassword)
+		case "ledger":
+			am, err = eth.NewLedgerAccountManager(ethcommon.HexToAddress(*cfg.EthAcctAddr), *cfg.EthLedgerPath, chainID)
+		case eth.RemoteSignerClef, eth.RemoteSignerWeb3Signer:
+			am, err = eth.NewRemoteAccountManager(ethcommon.HexToAddress(*cfg.EthAcctAddr), *cfg.EthSignerURL, *cfg.EthSigner, chainID)
+		case "kms":
+			am, err = eth.NewKMSAccountManager(ethcommon.HexToAddress(*cfg.EthAcctAddr), *cfg.EthKMSKey, chainID)
+		case "none":
+			am = eth.NewReadOnlyAccountManager(ethcommon.HexToAddress(*cfg.EthAcctAddr))
+		default:
+			err = fmt.Errorf("invalid -ethSigner=%v", *cfg.EthSigner)
+		}
 		if err != nil {
 			glog.Errorf("Error creating Ethereum account manager: %v", err)
 			return
@@ -592,6 +1100,7 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		}
 
 		tm := eth.NewTransactionManager(backend, gpm, am, *cfg.TxTimeout, *cfg.MaxTxReplacements)
+		tm.SetRecorder(dbh)
 		go tm.Start()
 		defer tm.Stop()
 
@@ -625,21 +1134,32 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		addrMap := n.Eth.ContractAddresses()
 
 		// Initialize block watcher that will emit logs used by event watchers
-		blockWatcherClient, err := blockwatch.NewRPCClient(*cfg.EthUrl, ethRPCTimeout)
-		if err != nil {
-			glog.Errorf("Failed to setup blockwatch client: %v", err)
+		blockWatcherClient := blockwatch.NewRPCClient(*cfg.EthUrl, rpcClient, ethRPCTimeout)
+		topics := watchers.FilterTopics()
+
+		if *cfg.BackfillBlockRange <= 0 {
+			glog.Errorf("-backfillBlockRange must be greater than 0, but %v provided. Restart the node with a different valid value for -backfillBlockRange", *cfg.BackfillBlockRange)
+			return
+		}
+		if *cfg.BackfillConcurrency <= 0 {
+			glog.Errorf("-backfillConcurrency must be greater than 0, but %v provided. Restart the node with a different valid value for -backfillConcurrency", *cfg.BackfillConcurrency)
+			return
+		}
+		if *cfg.MaxReorgDepth <= 0 {
+			glog.Errorf("-maxReorgDepth must be greater than 0, but %v provided. Restart the node with a different valid value for -maxReorgDepth", *cfg.MaxReorgDepth)
 			return
 		}
-		topics := watchers.FilterTopics()
 
 		blockWatcherCfg := blockwatch.Config{
 			Store:               n.Database,
 			PollingInterval:     blockPollingTime,
 			StartBlockDepth:     rpc.LatestBlockNumber,
-			BlockRetentionLimit: blockWatcherRetentionLimit,
+			BlockRetentionLimit: *cfg.MaxReorgDepth,
 			WithLogs:            true,
 			Topics:              topics,
 			Client:              blockWatcherClient,
+			BackfillBlockRange:  *cfg.BackfillBlockRange,
+			BackfillConcurrency: *cfg.BackfillConcurrency,
 		}
 		// Wait until all event watchers have been initialized before starting the block watcher
 		blockWatcher := blockwatch.New(blockWatcherCfg)
@@ -695,6 +1215,10 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		n.Balances = core.NewAddressBalances(cleanupInterval)
 		defer n.Balances.StopCleanup()
 
+		n.Earnings = core.NewEarningsRecorder(dbh)
+		go n.Earnings.StartFlush()
+		defer n.Earnings.StopFlush()
+
 		// By default the ticket recipient is the node's address
 		// If the address of an on-chain registered orchestrator is provided, then it should be specified as the ticket recipient
 		recipientAddr := n.Eth.Account().Address
@@ -702,6 +1226,105 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			recipientAddr = ethcommon.HexToAddress(*cfg.EthOrchAddr)
 		}
 
+		var paymentEvents pm.PaymentEventSink
+		if *cfg.PaymentWebhookURL != "" {
+			whurl, err := validateURL(*cfg.PaymentWebhookURL)
+			if err != nil {
+				glog.Fatal("Error setting payment webhook URL ", err)
+			}
+			eventTypes, err := server.ParsePaymentEventTypes(*cfg.PaymentWebhookEvents)
+			if err != nil {
+				glog.Fatal("Error parsing -paymentWebhookEvents ", err)
+			}
+			glog.Info("Using payment webhook URL ", whurl.Redacted())
+			pw := server.NewPaymentWebhook(whurl, eventTypes)
+			pw.Start()
+			defer pw.Stop()
+			paymentEvents = pw
+		}
+
+		var ticketBroker pm.Broker = n.Eth
+		if *cfg.TicketRedeemRelayURL != "" {
+			relayURL, err := validateURL(*cfg.TicketRedeemRelayURL)
+			if err != nil {
+				glog.Fatal("Error setting ticket redemption relay URL ", err)
+			}
+			rb, err := eth.NewRelayBroker(n.Eth, backend, relayURL, n.Eth.ContractAddresses()["TicketBroker"], ethCfg.CheckTxTimeout)
+			if err != nil {
+				glog.Errorf("Error setting up ticket redemption relay: %v", err)
+				return
+			}
+			glog.Info("Using ticket redemption relay URL ", relayURL.Redacted())
+			ticketBroker = rb
+		}
+
+		if *cfg.EthBalanceSafeMode && *cfg.EthBalanceCritical == "" {
+			glog.Errorf("-ethBalanceSafeMode requires -ethBalanceCritical. Restart the node with a valid value for -ethBalanceCritical")
+			return
+		}
+		// safeMode is nil unless the gas consuming services are paused while the ETH balance is critical
+		var safeMode func() bool
+		if !readOnly && (*cfg.EthBalanceLow != "" || *cfg.EthBalanceCritical != "") {
+			watermarks := eth.BalanceWatermarks{SafeMode: *cfg.EthBalanceSafeMode}
+			for _, v := range []struct {
+				name  string
+				value *string
+				dst   **big.Int
+			}{
+				{"ethBalanceLow", cfg.EthBalanceLow, &watermarks.Low},
+				{"ethBalanceCritical", cfg.EthBalanceCritical, &watermarks.Critical},
+			} {
+				if *v.value == "" {
+					continue
+				}
+				amount, ok := new(big.Int).SetString(*v.value, 10)
+				if !ok || amount.Sign() <= 0 {
+					glog.Errorf("-%v must be a valid integer greater than 0, but %v provided. Restart the node with a different valid value for -%v", v.name, *v.value, v.name)
+					return
+				}
+				*v.dst = amount
+			}
+
+			// Start balance watcher
+			bw := eth.NewBalanceWatcher(n.Eth.Account().Address, backend, timeWatcher, watermarks)
+			var balanceWebhook *server.AlertWebhook
+			if *cfg.EthBalanceAlertWebhookURL != "" {
+				whurl, err := validateURL(*cfg.EthBalanceAlertWebhookURL)
+				if err != nil {
+					glog.Fatal("Error setting ETH balance alert webhook URL ", err)
+				}
+				glog.Info("Using ETH balance alert webhook URL ", whurl.Redacted())
+				balanceWebhook = server.NewAlertWebhook(whurl)
+			}
+			bw.SetAlertHandler(func(a *eth.BalanceAlert) {
+				if balanceWebhook != nil {
+					balanceWebhook.NotifyBalance(a)
+				}
+				if a.Level != eth.BalanceOK {
+					severity := alert.Warning
+					if a.Level == eth.BalanceCritical {
+						severity = alert.Critical
+					}
+					alert.Fire(alert.EthBalanceLow, severity, a.Address, "ETH balance=%v of account=%v is %v safeMode=%v", a.Balance, a.Address, a.Level, a.SafeMode)
+				}
+			})
+			go func() {
+				if err := bw.Start(); err != nil {
+					serviceErr <- err
+				}
+			}()
+			defer bw.Stop()
+			if watermarks.SafeMode {
+				glog.Infof("Pausing gas consuming services while the ETH balance is below %v", eth.FormatUnits(watermarks.Critical, "ETH"))
+				safeMode = bw.SafeMode
+			}
+		}
+
+		// The relay pays for the gas of the redemptions, so they don't depend on the ETH balance of the node account
+		redeemSafeMode := safeMode
+		if *cfg.TicketRedeemRelayURL != "" {
+			redeemSafeMode = nil
+		}
 		smCfg := &pm.LocalSenderMonitorConfig{
 			Claimant:        recipientAddr,
 			CleanupInterval: cleanupInterval,
@@ -709,6 +1332,32 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			RedeemGas:       redeemGas,
 			SuggestGasPrice: client.Backend().SuggestGasPrice,
 			RPCTimeout:      ethRPCTimeout,
+			RedeemBatchSize: *cfg.TicketRedeemBatchSize,
+			Events:          paymentEvents,
+			SafeMode:        redeemSafeMode,
+
+			MaxUnredeemableTickets: *cfg.MaxUnredeemableTickets,
+			SenderCoolOff:          *cfg.SenderCoolOff,
+		}
+		if *cfg.MinSenderReserve != "" {
+			minReserve, ok := new(big.Int).SetString(*cfg.MinSenderReserve, 10)
+			if !ok || minReserve.Sign() < 0 {
+				glog.Errorf("-minSenderReserve must be a valid positive integer, but %v provided. Restart the node with a different valid value for -minSenderReserve", *cfg.MinSenderReserve)
+				return
+			}
+			smCfg.MinReserveAlloc = minReserve
+		}
+		if *cfg.MaxSenderExposure != "" {
+			maxExposure, ok := new(big.Int).SetString(*cfg.MaxSenderExposure, 10)
+			if !ok || maxExposure.Sign() <= 0 {
+				glog.Errorf("-maxSenderExposure must be a valid positive integer, but %v provided. Restart the node with a different valid value for -maxSenderExposure", *cfg.MaxSenderExposure)
+				return
+			}
+			smCfg.MaxSenderExposure = maxExposure
+		}
+		if (smCfg.MinReserveAlloc != nil || smCfg.MaxUnredeemableTickets > 0) && smCfg.SenderCoolOff <= 0 {
+			glog.Errorf("-senderCoolOff must be greater than 0, but %v provided. Restart the node with a different valid value for -senderCoolOff", *cfg.SenderCoolOff)
+			return
 		}
 
 		if *cfg.Orchestrator {
@@ -730,14 +1379,38 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			if *cfg.PricePerBroadcaster != "" {
 				ppb := getBroadcasterPrices(*cfg.PricePerBroadcaster)
 				for _, p := range ppb {
+					if !ethcommon.IsHexAddress(p.EthAddress) {
+						glog.Errorf("-pricePerBroadcaster contains an invalid ethaddress, provided %v", p.EthAddress)
+						return
+					}
+					if p.PixelsPerUnit <= 0 {
+						glog.Errorf("-pricePerBroadcaster pixelsperunit must be > 0 for broadcaster %v, provided %d", p.EthAddress, p.PixelsPerUnit)
+						return
+					}
+					if p.PricePerUnit < 0 {
+						glog.Errorf("-pricePerBroadcaster priceperunit must be >= 0 for broadcaster %v, provided %d", p.EthAddress, p.PricePerUnit)
+						return
+					}
 					price := big.NewRat(p.PricePerUnit, p.PixelsPerUnit)
-					n.SetBasePrice(p.EthAddress, price)
-					glog.Infof("Price: %v set for broadcaster %v", price.RatString(), p.EthAddress)
+					// Normalize so that addresses without the 0x prefix match the sender address of tickets
+					ethAddr := ethcommon.HexToAddress(p.EthAddress).Hex()
+					n.SetBasePrice(ethAddr, price)
+					glog.Infof("Price: %v set for broadcaster %v", price.RatString(), ethAddr)
 				}
 			}
 
 			n.AutoAdjustPrice = *cfg.AutoAdjustPrice
 
+			if *cfg.UtilizationPricing != "" {
+				up, err := core.ParseUtilizationPricing(*cfg.UtilizationPricing)
+				if err != nil {
+					glog.Errorf("Error parsing -utilizationPricing: %v", err)
+					return
+				}
+				n.UtilizationPricing = up
+				glog.Infof("Utilization based pricing enabled with steps %v", up)
+			}
+
 			ev, _ := new(big.Int).SetString(*cfg.TicketEV, 10)
 			if ev == nil {
 				glog.Errorf("-ticketEV must be a valid integer, but %v provided. Restart the node with a different valid value for -ticketEV", *cfg.TicketEV)
@@ -759,25 +1432,47 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 
 			var sm pm.SenderMonitor
 			if *cfg.RedeemerAddr != "" {
-				*cfg.RedeemerAddr = defaultAddr(*cfg.RedeemerAddr, "127.0.0.1", RpcPort)
-				rc, err := server.NewRedeemerClient(*cfg.RedeemerAddr, senderWatcher, timeWatcher)
+				var redeemerAddrs []string
+				for _, addr := range strings.Split(*cfg.RedeemerAddr, ",") {
+					addr = strings.TrimSpace(addr)
+					if eth.IsENSName(addr) {
+						uri, err := discovery.ResolveENSURL(ensResolver, addr)
+						if err != nil {
+							glog.Errorf("Error resolving redeemer URI: %v", err)
+							return
+						}
+						glog.Infof("Resolved redeemer URI name=%v uri=%v", addr, uri)
+						addr = uri.Host
+					}
+					redeemerAddrs = append(redeemerAddrs, defaultAddr(addr, "127.0.0.1", RpcPort))
+				}
+				rc, err := server.NewRedeemerClient(redeemerAddrs, senderWatcher, timeWatcher, n.Database)
 				if err != nil {
 					glog.Error("Unable to start redeemer client: ", err)
 					return
 				}
 				sm = rc
 			} else {
-				sm = pm.NewSenderMonitor(smCfg, n.Eth, senderWatcher, timeWatcher, n.Database)
+				localSm := pm.NewSenderMonitor(smCfg, ticketBroker, senderWatcher, timeWatcher, n.Database)
+				n.Redemptions = localSm
+				sm = localSm
 			}
 
 			// Start sender monitor
 			sm.Start()
 			defer sm.Stop()
 
+			faceValueStrategy, err := newFaceValueStrategy(cfg, ev)
+			if err != nil {
+				glog.Error(err)
+				return
+			}
 			tcfg := pm.TicketParamsConfig{
-				EV:               ev,
-				RedeemGas:        redeemGas,
-				TxCostMultiplier: txCostMultiplier,
+				EV:                ev,
+				RedeemGas:         redeemGas,
+				TxCostMultiplier:  txCostMultiplier,
+				FaceValueStrategy: faceValueStrategy,
+				Events:            paymentEvents,
 			}
 			n.Recipient, err = pm.NewRecipient(
 				recipientAddr,
@@ -800,6 +1495,49 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 				n.SetMaxFaceValue(mfv)
 			}
 
+			if *cfg.FeeWithdrawAddr != "" {
+				// The contracts only let the orchestrator account withdraw its fees
+				if recipientAddr != n.Eth.Account().Address {
+					glog.Errorf("-feeWithdrawAddr requires the node account to be the orchestrator, but -ethOrchAddr=%v provided. Withdraw the fees with the orchestrator account instead", recipientAddr.Hex())
+					return
+				}
+				if !ethcommon.IsHexAddress(*cfg.FeeWithdrawAddr) {
+					glog.Errorf("-feeWithdrawAddr must be a valid ETH address, but %v provided. Restart the node with a different valid value for -feeWithdrawAddr", *cfg.FeeWithdrawAddr)
+					return
+				}
+				minFees, ok := new(big.Int).SetString(*cfg.FeeWithdrawMinFees, 10)
+				if !ok || minFees.Sign() <= 0 {
+					glog.Errorf("-feeWithdrawMinFees must be a valid integer greater than 0, but %v provided. Restart the node with a different valid value for -feeWithdrawMinFees", *cfg.FeeWithdrawMinFees)
+					return
+				}
+				if *cfg.FeeWithdrawInterval <= 0 {
+					glog.Errorf("-feeWithdrawInterval must be greater than 0, but %v provided. Restart the node with a different valid value for -feeWithdrawInterval", *cfg.FeeWithdrawInterval)
+					return
+				}
+				feeWithdrawCfg := eth.FeeWithdrawConfig{
+					Recipient: ethcommon.HexToAddress(*cfg.FeeWithdrawAddr),
+					MinFees:   minFees,
+					Interval:  *cfg.FeeWithdrawInterval,
+				}
+				if *cfg.FeeWithdrawMaxGasPrice != "" {
+					maxGasPrice, ok := new(big.Int).SetString(*cfg.FeeWithdrawMaxGasPrice, 10)
+					if !ok || maxGasPrice.Sign() <= 0 {
+						glog.Errorf("-feeWithdrawMaxGasPrice must be a valid integer greater than 0, but %v provided. Restart the node with a different valid value for -feeWithdrawMaxGasPrice", *cfg.FeeWithdrawMaxGasPrice)
+						return
+					}
+					feeWithdrawCfg.MaxGasPrice = maxGasPrice
+				}
+
+				// Start fee withdraw service
+				glog.Infof("Automatically withdrawing fees to recipient=%v minFees=%v", feeWithdrawCfg.Recipient.Hex(), eth.FormatUnits(minFees, "ETH"))
+				feeWithdrawService := eth.NewFeeWithdrawService(n.Eth, gpm, feeWithdrawCfg)
+				go func() {
+					if err := feeWithdrawService.Start(); err != nil {
+						serviceErr <- err
+					}
+				}()
+				defer feeWithdrawService.Stop()
+			}
 		}
 
 		if n.NodeType == core.BroadcasterNode {
@@ -816,16 +1554,59 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 				panic(fmt.Errorf("-depositMultiplier must be greater than 0, but %v provided. Restart the node with a valid value for -depositMultiplier", *cfg.DepositMultiplier))
 			}
 
-			// Fetch and cache broadcaster on-chain info
-			info, err := senderWatcher.GetSenderInfo(n.Eth.Account().Address)
-			if err != nil {
-				glog.Error("Failed to get broadcaster on-chain info: ", err)
-				return
+			if readOnly {
+				glog.Infof("Broadcaster is in read-only mode, payments are not sent to orchestrators")
+			} else {
+				// Fetch and cache broadcaster on-chain info
+				info, err := senderWatcher.GetSenderInfo(n.Eth.Account().Address)
+				if err != nil {
+					glog.Error("Failed to get broadcaster on-chain info: ", err)
+					return
+				}
+				glog.Info("Broadcaster Deposit: ", eth.FormatUnits(info.Deposit, "ETH"))
+				glog.Info("Broadcaster Reserve: ", eth.FormatUnits(info.Reserve.FundsRemaining, "ETH"))
+
+				n.Sender = pm.NewSender(n.Eth, timeWatcher, senderWatcher, ev, *cfg.DepositMultiplier)
+
+				// Record the tickets sent and the winning tickets redeemed for the spending report
+				spending := server.NewSpendingRecorder(dbh)
+				server.Spending = spending
+				go spending.StartFlush()
+				go spending.WatchWinningTickets(n.Eth.Account().Address, senderWatcher)
+				defer spending.StopFlush()
+
+				topUpCfg := eth.TopUpConfig{}
+				for _, v := range []struct {
+					name  string
+					value *string
+					dst   **big.Int
+				}{
+					{"topUpMinDeposit", cfg.TopUpMinDeposit, &topUpCfg.MinDeposit},
+					{"topUpDepositAmount", cfg.TopUpDepositAmount, &topUpCfg.DepositAmount},
+					{"topUpMinReserve", cfg.TopUpMinReserve, &topUpCfg.MinReserve},
+					{"topUpReserveAmount", cfg.TopUpReserveAmount, &topUpCfg.ReserveAmount},
+				} {
+					if *v.value == "" {
+						continue
+					}
+					amount, ok := new(big.Int).SetString(*v.value, 10)
+					if !ok || amount.Sign() < 0 {
+						glog.Errorf("-%v must be a valid positive integer, but %v provided. Restart the node with a different valid value for -%v", v.name, *v.value, v.name)
+						return
+					}
+					*v.dst = amount
+				}
+				if topUpCfg.DepositAmount != nil || topUpCfg.ReserveAmount != nil {
+					// Start top-up service
+					topUpService := eth.NewTopUpService(n.Eth, senderWatcher, timeWatcher, topUpCfg)
+					go func() {
+						if err := topUpService.Start(); err != nil {
+							serviceErr <- err
+						}
+					}()
+					defer topUpService.Stop()
+				}
 			}
-			glog.Info("Broadcaster Deposit: ", eth.FormatUnits(info.Deposit, "ETH"))
-			glog.Info("Broadcaster Reserve: ", eth.FormatUnits(info.Reserve.FundsRemaining, "ETH"))
-
-			n.Sender = pm.NewSender(n.Eth, timeWatcher, senderWatcher, ev, *cfg.DepositMultiplier)
 
 			if *cfg.PixelsPerUnit <= 0 {
 				// Can't divide by 0
@@ -837,6 +1618,47 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 				glog.Infof("Maximum transcoding price per pixel is not greater than 0: %v, broadcaster is currently set to accept ANY price.\n", *cfg.MaxPricePerUnit)
 				glog.Infoln("To update the broadcaster's maximum acceptable transcoding price per pixel, use the CLI or restart the broadcaster with the appropriate 'maxPricePerUnit' and 'pixelsPerUnit' values")
 			}
+
+			if *cfg.MaxPricePerCapability != "" {
+				capabilityPrices, err := getCapabilityMaxPrices(*cfg.MaxPricePerCapability)
+				if err != nil {
+					glog.Errorf("-maxPricePerCapability could not be parsed, provided %v: %v", *cfg.MaxPricePerCapability, err)
+					return
+				}
+				for _, p := range capabilityPrices {
+					capability, err := core.CapabilityFromName(p.Capability)
+					if err != nil {
+						glog.Errorf("-maxPricePerCapability contains an unknown capability, provided %v", p.Capability)
+						return
+					}
+					if p.PixelsPerUnit <= 0 {
+						glog.Errorf("-maxPricePerCapability pixelsperunit must be > 0 for capability %v, provided %d", p.Capability, p.PixelsPerUnit)
+						return
+					}
+					if p.PricePerUnit <= 0 {
+						glog.Errorf("-maxPricePerCapability priceperunit must be > 0 for capability %v, provided %d", p.Capability, p.PricePerUnit)
+						return
+					}
+					price := big.NewRat(p.PricePerUnit, p.PixelsPerUnit)
+					server.BroadcastCfg.SetCapabilityMaxPrice(capability, price)
+					glog.Infof("Maximum transcoding price: %v set for capability %v", price.RatString(), p.Capability)
+				}
+			}
+
+			if *cfg.MaxSpendPerStream != "" || *cfg.MaxSpendPerHour != "" {
+				maxPerStream, err := parseSpendLimit("maxSpendPerStream", *cfg.MaxSpendPerStream)
+				if err != nil {
+					glog.Error(err)
+					return
+				}
+				maxPerHour, err := parseSpendLimit("maxSpendPerHour", *cfg.MaxSpendPerHour)
+				if err != nil {
+					glog.Error(err)
+					return
+				}
+				server.SpendLimits = server.NewSpendLimiter(maxPerStream, maxPerHour, *cfg.SpendLimitAlertOnly)
+				glog.Infof("Limiting spend maxSpendPerStream=%q maxSpendPerHour=%q alertOnly=%v", *cfg.MaxSpendPerStream, *cfg.MaxSpendPerHour, *cfg.SpendLimitAlertOnly)
+			}
 		}
 
 		if n.NodeType == core.RedeemerNode {
@@ -845,10 +1667,13 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 				return
 			}
 
+			redeemerSm := pm.NewSenderMonitor(smCfg, ticketBroker, senderWatcher, timeWatcher, n.Database)
+			n.Redemptions = redeemerSm
 			r, err := server.NewRedeemer(
 				recipientAddr,
 				n.Eth,
-				pm.NewSenderMonitor(smCfg, n.Eth, senderWatcher, timeWatcher, n.Database),
+				redeemerSm,
+				n.Database,
 			)
 			if err != nil {
 				glog.Errorf("Unable to create redeemer: %v", err)
@@ -873,7 +1698,10 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		}
 
 		var reward bool
-		if cfg.Reward == nil {
+		if readOnly {
+			// Reward cannot be called without an account
+			reward = false
+		} else if cfg.Reward == nil {
 			// If the node address is an on-chain registered address, start the reward service
 			t, err := n.Eth.GetTranscoder(n.Eth.Account().Address)
 			if err != nil {
@@ -892,7 +1720,24 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		if reward {
 			// Start reward service
 			// The node will only call reward if it is active in the current round
-			rs := eth.NewRewardService(n.Eth, timeWatcher)
+			if *cfg.RewardPriceBump < 0 {
+				glog.Errorf("-rewardPriceBump must be greater than or equal to 0, but %v provided. Restart the node with a different valid value for -rewardPriceBump", *cfg.RewardPriceBump)
+				return
+			}
+			rs := eth.NewRewardService(n.Eth, timeWatcher, eth.RewardRetryConfig{
+				MaxAttempts:   *cfg.RewardMaxAttempts,
+				RetryInterval: *cfg.RewardRetryInterval,
+				PriceBump:     uint64(*cfg.RewardPriceBump),
+			})
+			if *cfg.RewardAlertWebhookURL != "" {
+				whurl, err := validateURL(*cfg.RewardAlertWebhookURL)
+				if err != nil {
+					glog.Fatal("Error setting reward alert webhook URL ", err)
+				}
+				glog.Info("Using reward alert webhook URL ", whurl.Redacted())
+				rs.SetAlertHandler(server.NewAlertWebhook(whurl).NotifyReward)
+			}
+			rs.SetSafeMode(safeMode)
 			go func() {
 				if err := rs.Start(ctx); err != nil {
 					serviceErr <- err
@@ -905,7 +1750,8 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		if *cfg.InitializeRound {
 			// Start round initializer
 			// The node will only initialize rounds if it in the upcoming active set for the round
-			initializer := eth.NewRoundInitializer(n.Eth, timeWatcher)
+			initializer := eth.NewRoundInitializer(n.Eth, timeWatcher, *cfg.InitializeRoundMaxDelay)
+			initializer.SetSafeMode(safeMode)
 			go func() {
 				if err := initializer.Start(); err != nil {
 					serviceErr <- err
@@ -915,6 +1761,21 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			defer initializer.Stop()
 		}
 
+		if *cfg.ClaimEarningsRounds > 0 {
+			// Start claim earnings service
+			glog.Infof("Automatically claiming earnings once more than %v rounds are unclaimed", *cfg.ClaimEarningsRounds)
+			ces := eth.NewClaimEarningsService(n.Eth, timeWatcher, int64(*cfg.ClaimEarningsRounds))
+			go func() {
+				if err := ces.Start(ctx); err != nil {
+					serviceErr <- err
+				}
+			}()
+			defer ces.Stop()
+		} else if *cfg.ClaimEarningsRounds < 0 {
+			glog.Errorf("-claimEarningsRounds must be greater than or equal to 0, but %v provided. Restart the node with a different valid value for -claimEarningsRounds", *cfg.ClaimEarningsRounds)
+			return
+		}
+
 		blockWatchCtx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
@@ -933,6 +1794,11 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 				blockWatcherErr <- fmt.Errorf("block watcher error: %v", err)
 			}
 		}()
+		if alert.Enabled() && *cfg.AlertBlockStallTimeout > 0 {
+			go blockWatcher.WatchStall(blockWatchCtx, *cfg.AlertBlockStallTimeout, func(latest *big.Int, since time.Duration) {
+				alert.Fire(alert.BlockWatcherStalled, alert.Critical, "", "No new block processed for %v, latest block=%v", since.Round(time.Second), latest)
+			})
+		}
 
 		go func() {
 			var err error
@@ -945,30 +1811,96 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		}()
 	}
 
+	// The stores are checked without the retries
+	storeHealth := storage.NewHealthChecker(lpmon.NodeID)
 	if *cfg.Objectstore != "" {
-		prepared, err := drivers.PrepareOSURL(*cfg.Objectstore)
+		prepared, err := storage.PrepareOSURL(*cfg.Objectstore)
 		if err != nil {
 			glog.Error("Error creating object store driver: ", err)
 			return
 		}
-		drivers.NodeStorage, err = drivers.ParseOSURL(prepared, false)
+		drivers.NodeStorage, err = storage.ParseOSURL(prepared, false)
 		if err != nil {
 			glog.Error("Error creating object store driver: ", err)
 			return
 		}
+		if storage.IsRecordOnly(drivers.NodeStorage) {
+			glog.Errorf("The object store %s can only be used with -recordStore", drivers.NodeStorage.UriSchemes())
+			return
+		}
+		storeHealth.Add(storage.UsageObjectStore, drivers.NodeStorage)
+		drivers.NodeStorage = storage.NewRetryDriver(drivers.NodeStorage, storage.DefaultRetryPolicy)
 	}
 
 	if *cfg.Recordstore != "" {
-		prepared, err := drivers.PrepareOSURL(*cfg.Recordstore)
+		var recordStores []drivers.OSDriver
+		for _, recordstore := range storage.SplitOSURLs(*cfg.Recordstore) {
+			prepared, err := storage.PrepareOSURL(recordstore)
+			if err != nil {
+				glog.Error("Error creating recordings object store driver: ", err)
+				return
+			}
+			recordStore, err := storage.ParseOSURL(prepared, true)
+			if err != nil {
+				glog.Error("Error creating recordings object store driver: ", err)
+				return
+			}
+			if len(recordStores) == 0 {
+				storeHealth.Add(storage.UsageRecordStore, recordStore)
+			} else {
+				storeHealth.Add(fmt.Sprintf("%s_replica_%d", storage.UsageRecordStore, len(recordStores)), recordStore)
+			}
+			recordStores = append(recordStores, recordStore)
+		}
+		if len(recordStores) == 0 {
+			glog.Errorf("No recordings object store in -recordStore=%q", *cfg.Recordstore)
+			return
+		}
+		// The replicas have their own retry queues
+		drivers.RecordStorage = storage.NewRetryDriver(recordStores[0], storage.DefaultRetryPolicy)
+		if len(recordStores) > 1 {
+			// The first store is used for the playlists and the /recordings endpoint
+			drivers.RecordStorage = storage.NewReplicatedDriver(drivers.RecordStorage, recordStores[1:]...)
+			glog.Infof("Replicating recordings to %d object stores", len(recordStores)-1)
+		}
+	}
+
+	if *cfg.RecordPathTemplate != "" {
+		storage.RecordPathTemplate, err = storage.ParsePathTemplate(*cfg.RecordPathTemplate)
+		if err != nil {
+			glog.Error("Error parsing -recordPathTemplate: ", err)
+			return
+		}
+		glog.Infof("Recording the segments at %s", storage.RecordPathTemplate)
+	}
+
+	if storeHealth.Len() > 0 {
+		// A misconfigured store is reported at startup instead of when the first segments are lost
+		if storeHealth.CheckAll(ctx) {
+			glog.Infof("Checked the object stores")
+		}
+		if *cfg.StoreHealthCheckInterval > 0 {
+			go storeHealth.Start(ctx, *cfg.StoreHealthCheckInterval)
+		}
+		storage.StoreHealth = storeHealth
+	}
+
+	if *cfg.RecordArchiveStore != "" {
+		client, err := storage.ParseArchiveURL(*cfg.RecordArchiveStore)
 		if err != nil {
-			glog.Error("Error creating recordings object store driver: ", err)
+			glog.Error("Error creating recordings archive client: ", err)
 			return
 		}
-		drivers.RecordStorage, err = drivers.ParseOSURL(prepared, true)
+		if *cfg.RecordArchiveDir == "" {
+			*cfg.RecordArchiveDir = filepath.Join(*cfg.Datadir, "recordArchive")
+		}
+		// Wait for the segments and playlists that are still being saved when streams end
+		storage.RecordArchiver, err = storage.NewArchiver(client, *cfg.RecordArchiveDir, 2*core.JsonPlaylistQuitTimeout)
 		if err != nil {
-			glog.Error("Error creating recordings object store driver: ", err)
+			glog.Error("Error creating recordings archiver: ", err)
 			return
 		}
+		glog.Infof("Archiving recordings to %s, staging them in %s", client.Description(), *cfg.RecordArchiveDir)
 	}
 
 	core.MaxSessions = *cfg.MaxSessions
@@ -976,6 +1908,33 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		lpmon.MaxSessions(core.MaxSessions)
 	}
 
+	if *cfg.SessionQueueTimeout > 0 {
+		if *cfg.SessionQueueSize <= 0 {
+			glog.Errorf("-sessionQueueSize must be greater than 0, but %v provided. Restart the node with a different valid value for -sessionQueueSize", *cfg.SessionQueueSize)
+			return
+		}
+		n.SessionQueue = core.NewSessionQueue(*cfg.SessionQueueTimeout, *cfg.SessionQueueSize)
+		glog.Infof("Holding up to %d session requests for %v while at capacity", *cfg.SessionQueueSize, *cfg.SessionQueueTimeout)
+	}
+	if *cfg.SessionRetryAfter < 0 {
+		glog.Errorf("-sessionRetryAfter must be greater than or equal to 0, but %v provided. Restart the node with a different valid value for -sessionRetryAfter", *cfg.SessionRetryAfter)
+		return
+	}
+	core.SessionRetryAfter = *cfg.SessionRetryAfter
+
+	if *cfg.MaxSessionsPerBroadcaster < 0 {
+		glog.Errorf("-maxSessionsPerBroadcaster must be greater than or equal to 0, but %v provided. Restart the node with a different valid value for -maxSessionsPerBroadcaster", *cfg.MaxSessionsPerBroadcaster)
+		return
+	}
+	n.MaxSessionsPerBroadcaster = *cfg.MaxSessionsPerBroadcaster
+
+	if *cfg.MaxSegmentDuration < 0 || *cfg.MaxSegmentSize < 0 {
+		glog.Errorf("-maxSegmentDuration and -maxSegmentSize must be greater than or equal to 0, but %v and %v provided. Restart the node with different valid values for -maxSegmentDuration and -maxSegmentSize", *cfg.MaxSegmentDuration, *cfg.MaxSegmentSize)
+		return
+	}
+	server.MaxSegmentDuration = *cfg.MaxSegmentDuration
+	server.MaxSegmentSize = *cfg.MaxSegmentSize
+
 	if *cfg.AuthWebhookURL != "" {
 		parsedUrl, err := validateURL(*cfg.AuthWebhookURL)
 		if err != nil {
@@ -985,6 +1944,15 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		server.AuthWebhookURL = parsedUrl
 	}
 
+	if *cfg.IngestJWTKeys != "" {
+		keys, err := server.ParseIngestJWTKeys(strings.Split(*cfg.IngestJWTKeys, ","))
+		if err != nil {
+			glog.Fatalf("Error setting ingest JWT keys: %v", err)
+		}
+		server.IngestJWTKeys = keys
+		glog.Infof("Authenticating HTTP ingest with JWTs issuers=%d", len(keys))
+	}
+
 	if *cfg.DetectionWebhookURL != "" {
 		parsedUrl, err := validateURL(*cfg.DetectionWebhookURL)
 		if err != nil {
@@ -995,6 +1963,7 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 	}
 	httpIngest := true
 
+	var orchLatencies *server.OrchestratorLatencies
 	if n.NodeType == core.BroadcasterNode {
 		// default lpms listener for broadcaster; same as default rpc port
 		// TODO provide an option to disable this?
@@ -1003,16 +1972,43 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 
 		bcast := core.NewBroadcaster(n)
 
+		if *cfg.MinDiscoveryParallelism <= 0 || *cfg.MaxDiscoveryParallelism < *cfg.MinDiscoveryParallelism {
+			glog.Fatalf("-minDiscoveryParallelism must be positive and not greater than -maxDiscoveryParallelism")
+		}
+		if *cfg.MinDiscoveryTimeout <= 0 || *cfg.MaxDiscoveryTimeout < *cfg.MinDiscoveryTimeout {
+			glog.Fatalf("-minDiscoveryTimeout must be positive and not greater than -maxDiscoveryTimeout")
+		}
+		if *cfg.OrchInfoCacheTTL < 0 {
+			glog.Errorf("-orchInfoCacheTTL must be greater than or equal to 0, but %v provided. Restart the node with a different valid value for -orchInfoCacheTTL", *cfg.OrchInfoCacheTTL)
+			return
+		}
+		discovery.OrchInfoCacheTTL = *cfg.OrchInfoCacheTTL
+
+		discoveryLimits := discovery.DiscoveryLimits{
+			MinParallelism: *cfg.MinDiscoveryParallelism,
+			MaxParallelism: *cfg.MaxDiscoveryParallelism,
+			MinTimeout:     *cfg.MinDiscoveryTimeout,
+			MaxTimeout:     *cfg.MaxDiscoveryTimeout,
+		}
+
 		// When the node is on-chain mode always cache the on-chain orchestrators and poll for updates
 		// Right now we rely on the DBOrchestratorPoolCache constructor to do this. Consider separating the logic
 		// caching/polling from the logic for fetching orchestrators during discovery
 		if *cfg.Network != "offchain" {
+			if *cfg.OrchPoolRefreshInterval <= 0 {
+				glog.Errorf("-orchPoolRefreshInterval must be greater than 0, but %v provided. Restart the node with a different valid value for -orchPoolRefreshInterval", *cfg.OrchPoolRefreshInterval)
+				return
+			}
+			discovery.CacheRefreshInterval = *cfg.OrchPoolRefreshInterval
+
 			ctx, cancel := context.WithCancel(ctx)
 			defer cancel()
 			dbOrchPoolCache, err := discovery.NewDBOrchestratorPoolCache(ctx, n, timeWatcher)
 			if err != nil {
 				glog.Fatalf("Could not create orchestrator pool with DB cache: %v", err)
 			}
+			dbOrchPoolCache.SetDiscoveryLimits(discoveryLimits)
+			server.OrchPoolRefresher = dbOrchPoolCache
 
 			n.OrchestratorPool = dbOrchPoolCache
 		}
@@ -1024,9 +2020,75 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 				glog.Fatal("Error setting orch webhook URL ", err)
 			}
 			glog.Info("Using orchestrator webhook URL ", whurl)
-			n.OrchestratorPool = discovery.NewWebhookPool(bcast, whurl)
+			whPool := discovery.NewWebhookPool(bcast, whurl)
+			whPool.SetDiscoveryLimits(discoveryLimits)
+			n.OrchestratorPool = whPool
+		} else if *cfg.Gossip {
+			gossipPeers, _, _ := parseOrchAddrs(*cfg.GossipPeers)
+			gossip = discovery.NewGossip(nil, append(gossipPeers, orchURLs...), *cfg.GossipInterval)
+			pool := discovery.NewGossipOrchestratorPool(bcast, gossip, common.Score_Trusted)
+			pool.SetDiscoveryLimits(discoveryLimits)
+			n.OrchestratorPool = pool
+		} else if len(orchENSNames) > 0 && len(orchSRVNames) > 0 {
+			glog.Errorf("-orchAddr can't contain both ENS names and SRV names, but %v provided. Restart the node with a different valid value for -orchAddr", *cfg.OrchAddr)
+			return
+		} else if len(orchSRVNames) > 0 {
+			if *cfg.SRVRefreshInterval <= 0 {
+				glog.Errorf("-srvRefreshInterval must be greater than 0, but %v provided. Restart the node with a different valid value for -srvRefreshInterval", *cfg.SRVRefreshInterval)
+				return
+			}
+			pool := discovery.NewSRVOrchestratorPool(bcast, orchURLs, orchSRVNames, *cfg.SRVRefreshInterval, common.Score_Trusted)
+			pool.SetDiscoveryLimits(discoveryLimits)
+			n.OrchestratorPool = pool
+		} else if len(orchENSNames) > 0 {
+			pool := discovery.NewENSOrchestratorPool(bcast, orchURLs, orchENSNames, ensResolver, *cfg.ENSRefreshInterval, common.Score_Trusted)
+			pool.SetDiscoveryLimits(discoveryLimits)
+			n.OrchestratorPool = pool
 		} else if len(orchURLs) > 0 {
-			n.OrchestratorPool = discovery.NewOrchestratorPool(bcast, orchURLs, common.Score_Trusted)
+			pool := discovery.NewOrchestratorPool(bcast, orchURLs, common.Score_Trusted)
+			pool.SetDiscoveryLimits(discoveryLimits)
+			n.OrchestratorPool = pool
+		}
+
+		if *cfg.OrchAllowlist != "" || *cfg.OrchBlocklist != "" {
+			filter, err := server.NewOrchestratorFilter(strings.Split(*cfg.OrchAllowlist, ","), strings.Split(*cfg.OrchBlocklist, ","))
+			if err != nil {
+				glog.Fatalf("Error setting orchestrator allowlist/blocklist: %v", err)
+			}
+			server.OrchFilter = filter
+			glog.Infof("Restricting orchestrator selection allowlist=%q blocklist=%q", *cfg.OrchAllowlist, *cfg.OrchBlocklist)
+		}
+
+		if *cfg.OrchRegions != "" {
+			server.OrchRegions = server.NewRegionSelection(strings.Split(*cfg.OrchRegions, ","), *cfg.RequireOrchRegion)
+			glog.Infof("Favoring orchestrators in regions=%q required=%v", *cfg.OrchRegions, *cfg.RequireOrchRegion)
+		} else if *cfg.RequireOrchRegion {
+			glog.Errorf("-requireOrchRegion requires -orchRegions to be set. Restart the node with -orchRegions or without -requireOrchRegion")
+			return
+		}
+
+		if *cfg.OrchCertPins != "" {
+			pins, err := server.ParseCertPins(strings.Split(*cfg.OrchCertPins, ","))
+			if err != nil {
+				glog.Fatalf("Error setting orchestrator cert pins: %v", err)
+			}
+			server.OrchCertPins.Configure(pins)
+			glog.Infof("Pinning orchestrator TLS certs pins=%d", len(pins))
+		}
+
+		if *cfg.LocalFallback {
+			server.LocalFallback = server.NewLocalFallbackTranscoder(n.WorkDir)
+			glog.Info("Transcoding segments locally when no orchestrator is able to transcode them")
+		}
+
+		if *cfg.RedundantTranscoding {
+			server.RedundantTranscoding = true
+			glog.Info("Submitting every segment to two orchestrators in parallel")
+		}
+
+		if *cfg.SplitRenditions {
+			server.SplitRenditions = true
+			glog.Info("Splitting the renditions of every stream across two orchestrators")
 		}
 
 		if n.OrchestratorPool == nil {
@@ -1042,8 +2104,8 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		if cfg.HttpIngest != nil {
 			httpIngest = *cfg.HttpIngest
 		}
-		if cfg.HttpIngest == nil && !isLocalHTTP && server.AuthWebhookURL == nil {
-			glog.Warning("HTTP ingest is disabled because -httpAddr is publicly accessible. To enable, configure -authWebhookUrl or use the -httpIngest flag")
+		if cfg.HttpIngest == nil && !isLocalHTTP && server.AuthWebhookURL == nil && server.IngestJWTKeys == nil {
+			glog.Warning("HTTP ingest is disabled because -httpAddr is publicly accessible. To enable, configure -authWebhookUrl or -ingestJwtKeys or use the -httpIngest flag")
 			httpIngest = false
 		}
 
@@ -1078,7 +2140,54 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 
 		// Set max transcode attempts. <=0 is OK; it just means "don't transcode"
 		server.MaxAttempts = *cfg.MaxAttempts
+		server.RetryPolicy = core.RetryPolicy{
+			Budget:              *cfg.RetryBudget,
+			Backoff:             *cfg.RetryBackoff,
+			MaxBackoff:          *cfg.RetryMaxBackoff,
+			TimeoutRetries:      *cfg.MaxTimeoutRetries,
+			RefusalRetries:      *cfg.MaxRefusalRetries,
+			VerificationRetries: *cfg.MaxVerificationRetries,
+		}
 		server.SelectRandFreq = *cfg.SelectRandFreq
+		server.StakeWeightedSelection = *cfg.StakeWeightedSelection
+		server.SessionRotationInterval = *cfg.SessionRotationInterval
+
+		if *cfg.OrchLatencyProbeInterval > 0 {
+			orchLatencies = server.StartLatencyProbing(ctx, n.OrchestratorPool, *cfg.OrchLatencyProbeInterval)
+			glog.Infof("Probing orchestrator latency every %v", *cfg.OrchLatencyProbeInterval)
+		}
+
+		orchStats, err := server.NewOrchestratorStats(dbh)
+		if err != nil {
+			glog.Errorf("Error loading orchestrator stats, not using them for selection: %v", err)
+		} else {
+			server.OrchStats = orchStats
+			go orchStats.StartFlush()
+			defer orchStats.StopFlush()
+		}
+
+		if *cfg.OrchReputation {
+			weights := discovery.DefaultReputationWeights
+			if *cfg.OrchReputationWeights != "" {
+				weights, err = discovery.ParseReputationWeights(*cfg.OrchReputationWeights)
+				if err != nil {
+					glog.Errorf("Invalid -orchReputationWeights: %v", err)
+					return
+				}
+			}
+			reputation, err := discovery.NewReputation(dbh, server.OrchStats, weights)
+			if err != nil {
+				glog.Errorf("Error loading orchestrator reputations, not using them for selection: %v", err)
+			} else {
+				server.Reputation = reputation
+				go reputation.Start()
+				defer reputation.Stop()
+				glog.Infof("Scoring orchestrator reputation with weights successRate=%v latency=%v verification=%v price=%v",
+					weights.SuccessRate, weights.Latency, weights.Verification, weights.Price)
+			}
+		} else if *cfg.OrchReputationWeights != "" {
+			glog.Fatal("-orchReputationWeights requires -orchReputation to be set")
+		}
 
 	} else if n.NodeType == core.OrchestratorNode {
 		suri, err := getServiceURI(n, *cfg.ServiceAddr)
@@ -1086,9 +2195,32 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			glog.Fatal("Error getting service URI: ", err)
 		}
 		n.SetServiceURI(suri)
+		n.Region = *cfg.Region
+		n.Datacenter = *cfg.Datacenter
+		server.KeepCert = *cfg.KeepCert
 		// if http addr is not provided, listen to all ifaces
 		// take the port to listen to from the service URI
 		*cfg.HttpAddr = defaultAddr(*cfg.HttpAddr, "", n.GetServiceURI().Port())
+		if *cfg.Gossip {
+			gossipPeers, _, _ := parseOrchAddrs(*cfg.GossipPeers)
+			gossip = discovery.NewGossip(n.GetServiceURI(), gossipPeers, *cfg.GossipInterval)
+		}
+		if *cfg.DiscoveryRateLimitPerIP < 0 {
+			glog.Errorf("-discoveryRateLimitPerIP must be greater than or equal to 0, but %v provided. Restart the node with a different valid value for -discoveryRateLimitPerIP", *cfg.DiscoveryRateLimitPerIP)
+			return
+		}
+		if *cfg.DiscoveryRateLimitPerSender < 0 {
+			glog.Errorf("-discoveryRateLimitPerSender must be greater than or equal to 0, but %v provided. Restart the node with a different valid value for -discoveryRateLimitPerSender", *cfg.DiscoveryRateLimitPerSender)
+			return
+		}
+		if *cfg.DiscoveryRateLimitPerIP > 0 || *cfg.DiscoveryRateLimitPerSender > 0 {
+			if *cfg.DiscoveryRateLimitBurst <= 0 {
+				glog.Errorf("-discoveryRateLimitBurst must be greater than 0, but %v provided. Restart the node with a different valid value for -discoveryRateLimitBurst", *cfg.DiscoveryRateLimitBurst)
+				return
+			}
+			server.DiscoveryRateLimits = server.NewDiscoveryRateLimiter(*cfg.DiscoveryRateLimitPerIP, *cfg.DiscoveryRateLimitPerSender, *cfg.DiscoveryRateLimitBurst)
+			glog.Infof("Rate limiting discovery requests perIP=%v perSender=%v burst=%v", *cfg.DiscoveryRateLimitPerIP, *cfg.DiscoveryRateLimitPerSender, *cfg.DiscoveryRateLimitBurst)
+		}
 		if !*cfg.Transcoder && n.OrchSecret == "" {
 			glog.Fatal("Running an orchestrator requires an -orchSecret for standalone mode or -transcoder for orchestrator+transcoder mode")
 		}
@@ -1116,9 +2248,28 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			if err != nil {
 				glog.Fatalf("Error establishing AMQP connection: err=%q", err)
 			}
+		case "kafka", "kafkas":
+			uriStr, topic, keyNs := *cfg.MetadataQueueUri, *cfg.MetadataAmqpExchange, n.NodeType.String()
+			server.MetadataQueue, err = broker.NewKafkaProducer(context.Background(), uriStr, topic, keyNs)
+			if err != nil {
+				glog.Fatalf("Error creating Kafka producer: err=%q", err)
+			}
+		case "nats":
+			uriStr, prefix, keyNs := *cfg.MetadataQueueUri, *cfg.MetadataAmqpExchange, n.NodeType.String()
+			server.MetadataQueue, err = broker.NewNATSProducer(context.Background(), uriStr, prefix, keyNs)
+			if err != nil {
+				glog.Fatalf("Error establishing NATS connection: err=%q", err)
+			}
+		case "mqtt", "mqtts":
+			uriStr, prefix, keyNs := *cfg.MetadataQueueUri, *cfg.MetadataAmqpExchange, n.NodeType.String()
+			server.MetadataQueue, err = broker.NewMQTTProducer(context.Background(), uriStr, prefix, keyNs)
+			if err != nil {
+				glog.Fatalf("Error establishing MQTT connection: err=%q", err)
+			}
 		default:
 			glog.Fatalf("Unsupported scheme in -metadataUri: %s", uri.Scheme)
 		}
+		server.MetadataQueue = broker.NewSchemaValidator(server.MetadataQueue)
 	}
 
 	//Create Livepeer Node
@@ -1128,6 +2279,14 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 	if err != nil {
 		glog.Fatalf("Error creating Livepeer server: err=%q", err)
 	}
+	s.OrchLatencies = orchLatencies
+	if gossip != nil {
+		if n.NodeType == core.OrchestratorNode {
+			s.HTTPMux.Handle(discovery.GossipPath, gossip)
+		}
+		gossip.Start(ctx)
+		glog.Infof("Discovering orchestrators through gossip peers=%q interval=%v", *cfg.GossipPeers, *cfg.GossipInterval)
+	}
 
 	ec := make(chan error)
 	tc := make(chan struct{})
@@ -1145,6 +2304,24 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		s.StartCliWebserver(srv)
 		close(wc)
 	}()
+
+	if *cfg.AdminAddr != "" {
+		var token string
+		if *cfg.AdminAPIToken != "" {
+			token, _ = common.ReadFromFile(*cfg.AdminAPIToken)
+		}
+		admin, err := server.NewAdminServer(s, n.Eth, token)
+		if err != nil {
+			glog.Fatalf("Error creating the admin API, -adminAddr requires an -adminApiToken: err=%q", err)
+		}
+		adminAddr := defaultAddr(*cfg.AdminAddr, "127.0.0.1", AdminPort)
+		go func() {
+			if err := admin.Start(adminAddr); err != nil {
+				glog.Fatalf("Error starting the admin API: err=%q", err)
+			}
+		}()
+		defer admin.Stop()
+	}
 	if n.NodeType != core.RedeemerNode {
 		go func() {
 			ec <- s.StartMediaServer(msCtx, *cfg.HttpAddr)
@@ -1181,6 +2358,20 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		if n.OrchSecret == "" {
 			glog.Fatal("Missing -orchSecret")
 		}
+		if len(orchURLs) <= 0 && len(orchENSNames) > 0 {
+			uri, err := discovery.ResolveENSURL(ensResolver, orchENSNames[0])
+			if err != nil {
+				glog.Fatalf("Error resolving orchestrator URI: %v", err)
+			}
+			orchURLs = append(orchURLs, uri)
+		}
+		if len(orchURLs) <= 0 && len(orchSRVNames) > 0 {
+			uris, err := discovery.ResolveSRVURLs(orchSRVNames[0])
+			if err != nil {
+				glog.Fatalf("Error resolving orchestrator SRV records: %v", err)
+			}
+			orchURLs = append(orchURLs, uris[0])
+		}
 		if len(orchURLs) <= 0 {
 			glog.Fatal("Missing -orchAddr")
 		}
@@ -1227,11 +2418,22 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 	}
 }
 
-func parseOrchAddrs(addrs string) []*url.URL {
+// parseOrchAddrs returns the orchestrator URIs, the ENS names and the SRV names to resolve them from
+func parseOrchAddrs(addrs string) ([]*url.URL, []string, []string) {
 	var res []*url.URL
+	var names []string
+	var srvNames []string
 	if len(addrs) > 0 {
 		for _, addr := range strings.Split(addrs, ",") {
 			addr = strings.TrimSpace(addr)
+			if eth.IsENSName(addr) {
+				names = append(names, addr)
+				continue
+			}
+			if discovery.IsSRVName(addr) {
+				srvNames = append(srvNames, addr)
+				continue
+			}
 			addr = defaultAddr(addr, "127.0.0.1", RpcPort)
 			if !strings.HasPrefix(addr, "http") {
 				addr = "https://" + addr
@@ -1244,7 +2446,16 @@ func parseOrchAddrs(addrs string) []*url.URL {
 			res = append(res, uri)
 		}
 	}
-	return res
+	return res, names, srvNames
+}
+
+func hasENSName(addrs string) bool {
+	for _, addr := range strings.Split(addrs, ",") {
+		if eth.IsENSName(strings.TrimSpace(addr)) {
+			return true
+		}
+	}
+	return false
 }
 
 func validateURL(u string) (*url.URL, error) {
@@ -1412,3 +2623,71 @@ func getBroadcasterPrices(broadcasterPrices string) []BroadcasterPrice {
 
 	return pricesSet.Prices
 }
+
+// Format of capabilityMaxPrices json
+// {"capabilities":[{"capability":"HEVC encode","priceperunit":2000,"pixelsperunit":1}]}
+type CapabilityMaxPrices struct {
+	Prices []CapabilityMaxPrice `json:"capabilities"`
+}
+
+type CapabilityMaxPrice struct {
+	Capability    string `json:"capability"`
+	PricePerUnit  int64  `json:"priceperunit"`
+	PixelsPerUnit int64  `json:"pixelsperunit"`
+}
+
+// getCapabilityMaxPrices parses the JSON max prices per capability, or the file containing them
+func getCapabilityMaxPrices(capabilityPrices string) ([]CapabilityMaxPrice, error) {
+	var pricesSet CapabilityMaxPrices
+	prices, err := common.ReadFromFile(capabilityPrices)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(prices), &pricesSet); err != nil {
+		return nil, err
+	}
+	return pricesSet.Prices, nil
+}
+
+// parseSpendLimit parses the spend limit in wei set with the flag name, returning nil if no limit is set
+func parseSpendLimit(name, limit string) (*big.Rat, error) {
+	if limit == "" {
+		return nil, nil
+	}
+	l, ok := new(big.Rat).SetString(limit)
+	if !ok || l.Sign() <= 0 {
+		return nil, fmt.Errorf("-%v must be a positive number of wei, but %v provided. Restart the node with a valid value for -%v", name, limit, name)
+	}
+	return l, nil
+}
+
+// newFaceValueStrategy creates the ticket face value strategy selected with -ticketFaceValueStrategy
+func newFaceValueStrategy(cfg LivepeerConfig, ev *big.Int) (pm.FaceValueStrategy, error) {
+	var strategy pm.FaceValueStrategy
+	switch *cfg.TicketFaceValueStrategy {
+	case "", "txCostMultiplier":
+		strategy = &pm.TxCostMultiplierStrategy{EV: ev, Multiplier: txCostMultiplier}
+	case "smoothedTxCost":
+		if *cfg.TicketFaceValueSmoothing <= 0 {
+			return nil, fmt.Errorf("-ticketFaceValueSmoothing must be greater than 0, but %v provided. Restart the node with a valid value for -ticketFaceValueSmoothing", *cfg.TicketFaceValueSmoothing)
+		}
+		strategy = pm.NewSmoothedTxCostStrategy(ev, txCostMultiplier, *cfg.TicketFaceValueSmoothing)
+	case "fixed":
+		faceValue, ok := new(big.Int).SetString(*cfg.TicketFaceValue, 10)
+		if !ok || faceValue.Cmp(ev) < 0 {
+			return nil, fmt.Errorf("-ticketFaceValue must be an integer no less than -ticketEV, but %v provided. Restart the node with a valid value for -ticketFaceValue", *cfg.TicketFaceValue)
+		}
+		strategy = &pm.FixedFaceValueStrategy{Amount: faceValue}
+	default:
+		return nil, fmt.Errorf("-ticketFaceValueStrategy must be one of txCostMultiplier, smoothedTxCost or fixed, but %v provided. Restart the node with a valid value for -ticketFaceValueStrategy", *cfg.TicketFaceValueStrategy)
+	}
+
+	if *cfg.TicketSenderFaceValueScales != "" {
+		scales, err := pm.ParseSenderScales(*cfg.TicketSenderFaceValueScales)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing -ticketSenderFaceValueScales: %v", err)
+		}
+		strategy = &pm.SenderScaledStrategy{Strategy: strategy, Scales: scales}
+	}
+	return strategy, nil
+}
@@ -2,7 +2,9 @@ package starter
 
 import (
 	"errors"
+	"io/ioutil"
 	"math/big"
+	"path/filepath"
 	"testing"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -85,6 +87,28 @@ func TestIsLocalURL(t *testing.T) {
 	assert.False(isLocal)
 }
 
+func TestParseOrchAddrs(t *testing.T) {
+	assert := assert.New(t)
+
+	uris, names, srvNames := parseOrchAddrs("")
+	assert.Empty(uris)
+	assert.Empty(names)
+	assert.Empty(srvNames)
+
+	uris, names, srvNames = parseOrchAddrs("127.0.0.1:8936, orch.eth,https://orch.example.com:8935, Other.ETH, srv://_livepeer._tcp.example.com")
+	var res []string
+	for _, uri := range uris {
+		res = append(res, uri.String())
+	}
+	assert.Equal([]string{"https://127.0.0.1:8936", "https://orch.example.com:8935"}, res)
+	assert.Equal([]string{"orch.eth", "Other.ETH"}, names)
+	assert.Equal([]string{"srv://_livepeer._tcp.example.com"}, srvNames)
+
+	assert.True(hasENSName("127.0.0.1:8936, redeemer.eth"))
+	assert.False(hasENSName("127.0.0.1:8936,redeemer.example.com"))
+	assert.False(hasENSName(""))
+}
+
 func TestParseGetBroadcasterPrices(t *testing.T) {
 	assert := assert.New(t)
 
@@ -99,3 +123,96 @@ func TestParseGetBroadcasterPrices(t *testing.T) {
 	assert.Equal(big.NewRat(1000, 1), price1)
 	assert.Equal(big.NewRat(2000, 3), price2)
 }
+
+func TestParseGetCapabilityMaxPrices(t *testing.T) {
+	assert := assert.New(t)
+
+	j := `{"capabilities":[{"capability":"HEVC encode","priceperunit":2000,"pixelsperunit":1}, {"capability":"VP9 encode","priceperunit":1000,"pixelsperunit":3}]}`
+
+	prices, err := getCapabilityMaxPrices(j)
+	assert.Nil(err)
+	assert.Len(prices, 2)
+	assert.Equal("HEVC encode", prices[0].Capability)
+	assert.Equal(big.NewRat(2000, 1), big.NewRat(prices[0].PricePerUnit, prices[0].PixelsPerUnit))
+	assert.Equal("VP9 encode", prices[1].Capability)
+	assert.Equal(big.NewRat(1000, 3), big.NewRat(prices[1].PricePerUnit, prices[1].PixelsPerUnit))
+
+	prices, err = getCapabilityMaxPrices("not json")
+	assert.NotNil(err)
+	assert.Nil(prices)
+
+	// the prices can be read from a file
+	file := filepath.Join(t.TempDir(), "prices.json")
+	require.Nil(t, ioutil.WriteFile(file, []byte(j), 0644))
+	prices, err = getCapabilityMaxPrices(file)
+	assert.Nil(err)
+	assert.Len(prices, 2)
+
+	_, err = getCapabilityMaxPrices(t.TempDir())
+	assert.EqualError(err, "supplied path is a directory")
+}
+
+func TestParseSpendLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	l, err := parseSpendLimit("maxSpendPerStream", "")
+	assert.Nil(err)
+	assert.Nil(l)
+
+	l, err = parseSpendLimit("maxSpendPerStream", "1000000000000000")
+	assert.Nil(err)
+	assert.Equal(big.NewRat(1000000000000000, 1), l)
+
+	_, err = parseSpendLimit("maxSpendPerStream", "abc")
+	assert.EqualError(err, "-maxSpendPerStream must be a positive number of wei, but abc provided. Restart the node with a valid value for -maxSpendPerStream")
+	_, err = parseSpendLimit("maxSpendPerHour", "-5")
+	assert.NotNil(err)
+	_, err = parseSpendLimit("maxSpendPerHour", "0")
+	assert.NotNil(err)
+}
+
+func TestNewFaceValueStrategy(t *testing.T) {
+	assert := assert.New(t)
+	ev := big.NewInt(1000)
+	cfg := DefaultLivepeerConfig()
+
+	s, err := newFaceValueStrategy(cfg, ev)
+	assert.Nil(err)
+	assert.Equal(&pm.TxCostMultiplierStrategy{EV: ev, Multiplier: txCostMultiplier}, s)
+
+	*cfg.TicketFaceValueStrategy = "smoothedTxCost"
+	s, err = newFaceValueStrategy(cfg, ev)
+	assert.Nil(err)
+	assert.IsType(&pm.SmoothedTxCostStrategy{}, s)
+	*cfg.TicketFaceValueSmoothing = 0
+	_, err = newFaceValueStrategy(cfg, ev)
+	assert.NotNil(err)
+
+	*cfg.TicketFaceValueStrategy = "fixed"
+	*cfg.TicketFaceValue = "5000"
+	s, err = newFaceValueStrategy(cfg, ev)
+	assert.Nil(err)
+	assert.Equal(&pm.FixedFaceValueStrategy{Amount: big.NewInt(5000)}, s)
+	// below the EV
+	*cfg.TicketFaceValue = "500"
+	_, err = newFaceValueStrategy(cfg, ev)
+	assert.EqualError(err, "-ticketFaceValue must be an integer no less than -ticketEV, but 500 provided. Restart the node with a valid value for -ticketFaceValue")
+	*cfg.TicketFaceValue = ""
+	_, err = newFaceValueStrategy(cfg, ev)
+	assert.NotNil(err)
+
+	*cfg.TicketFaceValueStrategy = "foo"
+	_, err = newFaceValueStrategy(cfg, ev)
+	assert.NotNil(err)
+
+	// per sender scaling wraps the selected strategy
+	*cfg.TicketFaceValueStrategy = "fixed"
+	*cfg.TicketFaceValue = "5000"
+	*cfg.TicketSenderFaceValueScales = "0x0000000000000000000000000000000000000001:0.5"
+	s, err = newFaceValueStrategy(cfg, ev)
+	assert.Nil(err)
+	assert.Equal(big.NewInt(2500), s.FaceValue(ethcommon.HexToAddress("0x0000000000000000000000000000000000000001"), big.NewInt(0)))
+	*cfg.TicketSenderFaceValueScales = "foo"
+	_, err = newFaceValueStrategy(cfg, ev)
+	assert.NotNil(err)
+}
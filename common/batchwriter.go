@@ -0,0 +1,106 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchWriter accumulates records in memory, merging the records with the same key, and writes them
+// in batches so that recording them stays off hot paths. Records that fail to be written are kept and
+// retried on the next flush, merged with any record added for the same key in the meantime.
+type BatchWriter struct {
+	// merge adds delta to total and returns the result. total is nil for the first record of a key,
+	// in which case merge returns a new record so that delta is not retained.
+	merge func(total, delta interface{}) interface{}
+	write func(record interface{}) error
+
+	mu      sync.Mutex
+	pending map[interface{}]interface{}
+
+	// flushMu serializes flushes so that a caller returning from Flush sees everything added before it written
+	flushMu sync.Mutex
+	quit    chan struct{}
+}
+
+// NewBatchWriter creates a BatchWriter that merges records with merge and writes them with write
+func NewBatchWriter(merge func(total, delta interface{}) interface{}, write func(record interface{}) error) *BatchWriter {
+	return &BatchWriter{
+		merge:   merge,
+		write:   write,
+		pending: make(map[interface{}]interface{}),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Add merges delta into the record pending to be written for key
+func (w *BatchWriter) Add(key, delta interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending[key] = w.merge(w.pending[key], delta)
+}
+
+// Pending returns the number of records pending to be written
+func (w *BatchWriter) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// Flush writes all pending records, returning the last write error. Records that failed to be written
+// are kept pending.
+func (w *BatchWriter) Flush() error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = make(map[interface{}]interface{})
+	w.mu.Unlock()
+
+	failed := make(map[interface{}]interface{})
+	var lastErr error
+	for key, record := range pending {
+		if err := w.write(record); err != nil {
+			failed[key] = record
+			lastErr = err
+		}
+	}
+	if len(failed) == 0 {
+		return lastErr
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, record := range failed {
+		if added, ok := w.pending[key]; ok {
+			record = w.merge(record, added)
+		}
+		w.pending[key] = record
+	}
+	return lastErr
+}
+
+// Start flushes the pending records every interval until Stop is called
+func (w *BatchWriter) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.Flush()
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// Stop stops the flush loop and writes any pending records
+func (w *BatchWriter) Stop() error {
+	close(w.quit)
+	return w.Flush()
+}
+
+// Done returns a channel that is closed when Stop is called
+func (w *BatchWriter) Done() <-chan struct{} {
+	return w.quit
+}
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type batchCount struct {
+	key   string
+	count int
+}
+
+func newTestBatchWriter() (*BatchWriter, map[string]int, *error, *sync.Mutex) {
+	var mu sync.Mutex
+	written := make(map[string]int)
+	var writeErr error
+	merge := func(total, delta interface{}) interface{} {
+		d := delta.(*batchCount)
+		if total == nil {
+			return &batchCount{key: d.key, count: d.count}
+		}
+		t := total.(*batchCount)
+		t.count += d.count
+		return t
+	}
+	write := func(record interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if writeErr != nil {
+			return writeErr
+		}
+		c := record.(*batchCount)
+		written[c.key] += c.count
+		return nil
+	}
+	return NewBatchWriter(merge, write), written, &writeErr, &mu
+}
+
+func TestBatchWriter(t *testing.T) {
+	assert := assert.New(t)
+	w, written, _, _ := newTestBatchWriter()
+
+	// records are merged by key
+	w.Add("foo", &batchCount{key: "foo", count: 1})
+	w.Add("foo", &batchCount{key: "foo", count: 2})
+	w.Add("bar", &batchCount{key: "bar", count: 5})
+	assert.Equal(2, w.Pending())
+	assert.Empty(written)
+
+	assert.Nil(w.Flush())
+	assert.Zero(w.Pending())
+	assert.Equal(map[string]int{"foo": 3, "bar": 5}, written)
+
+	// deltas are not retained
+	delta := &batchCount{key: "foo", count: 1}
+	w.Add("foo", delta)
+	w.Add("foo", &batchCount{key: "foo", count: 1})
+	assert.Equal(1, delta.count)
+}
+
+func TestBatchWriter_FailedWritesAreRequeued(t *testing.T) {
+	assert := assert.New(t)
+	w, written, writeErr, mu := newTestBatchWriter()
+
+	w.Add("foo", &batchCount{key: "foo", count: 1})
+	w.Add("bar", &batchCount{key: "bar", count: 2})
+	mu.Lock()
+	*writeErr = errors.New("write error")
+	mu.Unlock()
+	assert.EqualError(w.Flush(), "write error")
+	assert.Equal(2, w.Pending())
+	assert.Empty(written)
+
+	// records added after a failed write are merged with it
+	w.Add("foo", &batchCount{key: "foo", count: 4})
+	mu.Lock()
+	*writeErr = nil
+	mu.Unlock()
+	assert.Nil(w.Flush())
+	assert.Zero(w.Pending())
+	assert.Equal(map[string]int{"foo": 5, "bar": 2}, written)
+}
+
+func TestBatchWriter_StartStop(t *testing.T) {
+	assert := assert.New(t)
+	w, written, _, mu := newTestBatchWriter()
+
+	done := make(chan struct{})
+	go func() {
+		w.Start(10 * time.Millisecond)
+		close(done)
+	}()
+	w.Add("foo", &batchCount{key: "foo", count: 1})
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	assert.Equal(1, written["foo"])
+	mu.Unlock()
+
+	// stopping writes the pending records
+	w.Add("foo", &batchCount{key: "foo", count: 1})
+	assert.Nil(w.Stop())
+	<-done
+	assert.Equal(2, written["foo"])
+	select {
+	case <-w.Done():
+	default:
+		t.Fatal("Done was not closed")
+	}
+}
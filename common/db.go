@@ -2,13 +2,16 @@ package common
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -21,7 +24,8 @@ import (
 
 // DB is an initialized DB driver with prepared statements
 type DB struct {
-	dbh *sql.DB
+	dbh  *sql.DB
+	path string
 
 	// prepared statements
 	updateOrch                       *sql.Stmt
@@ -34,13 +38,30 @@ type DB struct {
 	withdrawableUnbondingLocks       *sql.Stmt
 	insertWinningTicket              *sql.Stmt
 	selectEarliestWinningTicket      *sql.Stmt
+	selectEarliestWinningTickets     *sql.Stmt
 	winningTicketCount               *sql.Stmt
+	winningTicketFaceValues          *sql.Stmt
+	selectWinningTicketSenders       *sql.Stmt
 	markWinningTicketRedeemed        *sql.Stmt
 	removeWinningTicket              *sql.Stmt
 	insertMiniHeader                 *sql.Stmt
 	findLatestMiniHeader             *sql.Stmt
 	findAllMiniHeadersSortedByNumber *sql.Stmt
 	deleteMiniHeader                 *sql.Stmt
+	selectEarnings                   *sql.Stmt
+	updateEarnings                   *sql.Stmt
+	addOrchStats                     *sql.Stmt
+	updateOrchReputation             *sql.Stmt
+	selectSpending                   *sql.Stmt
+	updateSpending                   *sql.Stmt
+	acquireLease                     *sql.Stmt
+	releaseLease                     *sql.Stmt
+	insertTransaction                *sql.Stmt
+
+	// serializes read-modify-write updates of the earnings table
+	earningsMu sync.Mutex
+	// serializes read-modify-write updates of the spending table
+	spendingMu sync.Mutex
 }
 
 // DBOrch is the type binding for a row result from the orchestrators table
@@ -51,6 +72,8 @@ type DBOrch struct {
 	ActivationRound   int64
 	DeactivationRound int64
 	Stake             int64 // Stored as a fixed point number
+	Region            string
+	Datacenter        string
 }
 
 // DBOrch is the type binding for a row result from the unbondingLocks table
@@ -61,15 +84,108 @@ type DBUnbondingLock struct {
 	WithdrawRound int64
 }
 
+// DBEarnings is the type binding for a row result from the earnings table.
+// EV and Fees are kept as exact fractions of wei so that totals don't drift over many payments.
+type DBEarnings struct {
+	Sender           ethcommon.Address `json:"sender"`
+	ManifestID       string            `json:"manifestID"`
+	Round            int64             `json:"round"`
+	Tickets          int64             `json:"tickets"`
+	WinningTickets   int64             `json:"winningTickets"`
+	EV               *big.Rat          `json:"ev"`
+	WinningFaceValue *big.Int          `json:"winningFaceValue"`
+	Pixels           int64             `json:"pixels"`
+	Fees             *big.Rat          `json:"fees"`
+}
+
+// DBSpending is the type binding for a row result from the spending table.
+// The spending of a broadcaster is kept per ticket recipient, stream and hour. The recipient of a winning ticket
+// is known from its on-chain redemption, but not its stream, so winning tickets are kept with an empty ManifestID.
+type DBSpending struct {
+	Recipient        ethcommon.Address `json:"recipient"`
+	ManifestID       string            `json:"manifestID"`
+	Hour             time.Time         `json:"hour"`
+	Tickets          int64             `json:"tickets"`
+	EV               *big.Rat          `json:"ev"`
+	WinningTickets   int64             `json:"winningTickets"`
+	WinningFaceValue *big.Int          `json:"winningFaceValue"`
+}
+
+// DBSpendingFilter is an object used to attach a filter to a SelectSpending query
+type DBSpendingFilter struct {
+	// Spending in the hours starting at or after From and before To
+	From *time.Time
+	To   *time.Time
+}
+
+// DBTransaction is the type binding for a row result from the transactions table
+type DBTransaction struct {
+	TxHash ethcommon.Hash `json:"txHash"`
+	// Method is the name of the contract method called by the transaction
+	Method      string `json:"method"`
+	BlockNumber int64  `json:"blockNumber"`
+	GasUsed     uint64 `json:"gasUsed"`
+	// GasPrice is the effective gas price paid by the transaction
+	GasPrice *big.Int `json:"gasPrice"`
+	Cost     *big.Int `json:"cost"`
+	// Failed is true if the transaction was reverted
+	Failed bool      `json:"failed"`
+	Time   time.Time `json:"time"`
+}
+
+// DBTransactionFilter is an object used to attach a filter to a SelectTransactions query
+type DBTransactionFilter struct {
+	// Transactions mined at or after From and before To
+	From   *time.Time
+	To     *time.Time
+	Method string
+}
+
+// DBOrchStats is the type binding for a row result from the orchestratorStats table
+type DBOrchStats struct {
+	ServiceURI string
+	Segments   int64
+	Successes  int64
+	Timeouts   int64
+	// Total round trip time of the successfully transcoded segments
+	RoundTripMs int64
+}
+
+// DBOrchReputation is the type binding for a row result from the orchestratorReputation table
+type DBOrchReputation struct {
+	ServiceURI           string
+	Score                float64
+	VerificationFailures int64
+	// Last price per pixel advertised by the orchestrator, nil if unknown
+	PricePerPixel *big.Rat
+}
+
+// DBEarningsFilter is an object used to attach a filter to a SelectEarnings query
+type DBEarningsFilter struct {
+	Sender     *ethcommon.Address
+	ManifestID string
+	Round      *big.Int
+}
+
 // DBOrchFilter is an object used to attach a filter to a selectOrch query
 type DBOrchFilter struct {
 	MaxPrice       *big.Rat
 	CurrentRound   *big.Int
 	Addresses      []ethcommon.Address
 	UpdatedLastDay bool
+	// Regions, if set, only matches the orchestrators that advertised one of the regions, case insensitively
+	Regions []string
 }
 
-var LivepeerDBVersion = 1
+var LivepeerDBVersion = 2
+
+// migrations upgrade the schema of a DB from the version of their key to the next version
+var migrations = map[int]string{
+	1: `
+	ALTER TABLE orchestrators ADD COLUMN region STRING;
+	ALTER TABLE orchestrators ADD COLUMN datacenter STRING;
+	`,
+}
 
 var ErrDBTooNew = errors.New("DB Too New")
 
@@ -89,7 +205,9 @@ var schema = `
 		pricePerPixel int64,
 		activationRound int64,
 		deactivationRound int64,
-		stake int64
+		stake int64,
+		region STRING,
+		datacenter STRING
 	);
 
 	CREATE TABLE IF NOT EXISTS unbondingLocks (
@@ -131,6 +249,72 @@ var schema = `
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_blockheaders_number ON blockheaders(number);
+
+	CREATE TABLE IF NOT EXISTS earnings (
+		sender STRING,
+		manifestID STRING,
+		round int64,
+		tickets int64 DEFAULT 0,
+		winningTickets int64 DEFAULT 0,
+		ev TEXT,
+		winningFaceValue TEXT,
+		pixels int64 DEFAULT 0,
+		fees TEXT,
+		updatedAt STRING DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(sender, manifestID, round)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_earnings_round ON earnings(round);
+
+	CREATE TABLE IF NOT EXISTS orchestratorStats (
+		serviceURI STRING PRIMARY KEY,
+		segments int64 DEFAULT 0,
+		successes int64 DEFAULT 0,
+		timeouts int64 DEFAULT 0,
+		roundTripMs int64 DEFAULT 0,
+		updatedAt STRING DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS orchestratorReputation (
+		serviceURI STRING PRIMARY KEY,
+		score REAL DEFAULT 0,
+		verificationFailures int64 DEFAULT 0,
+		pricePerPixel STRING,
+		updatedAt STRING DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS leases (
+		name STRING PRIMARY KEY,
+		holder STRING,
+		expiresAt int64
+	);
+
+	CREATE TABLE IF NOT EXISTS spending (
+		recipient STRING,
+		manifestID STRING,
+		hour int64,
+		tickets int64 DEFAULT 0,
+		ev TEXT,
+		winningTickets int64 DEFAULT 0,
+		winningFaceValue TEXT,
+		updatedAt STRING DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(recipient, manifestID, hour)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_spending_hour ON spending(hour);
+
+	CREATE TABLE IF NOT EXISTS transactions (
+		txHash STRING PRIMARY KEY,
+		method STRING,
+		blockNumber int64,
+		gasUsed int64,
+		gasPrice TEXT,
+		cost TEXT,
+		failed int,
+		createdAt int64
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_transactions_createdat ON transactions(createdAt);
 `
 
 func NewDBOrch(ethereumAddr string, serviceURI string, pricePerPixel int64, activationRound int64, deactivationRound int64, stake int64) *DBOrch {
@@ -147,8 +331,8 @@ func NewDBOrch(ethereumAddr string, serviceURI string, pricePerPixel int64, acti
 func InitDB(dbPath string) (*DB, error) {
 	// XXX need a way to ensure (via unit tests?) that all DB{} fields are
 	// properly closed / cleaned up in the case of an error
-	d := DB{}
-	db, err := sql.Open("sqlite3", dbPath)
+	d := DB{path: dbPath}
+	db, err := sql.Open(sqliteDriver, dbPath)
 	if err != nil {
 		glog.Error("Unable to open DB ", dbPath, err)
 		return nil, err
@@ -158,6 +342,15 @@ func InitDB(dbPath string) (*DB, error) {
 	// we can encounter a `database is locked` error. To avoid concurrent writes, we limit SQLite to a single connection
 	db.SetMaxOpenConns(1)
 	d.dbh = db
+	// Use a write-ahead log so that a write is not lost or rolled back if the node crashes. With a write-ahead log
+	// synchronous=NORMAL can't corrupt the DB, but the last commits may be rolled back on power loss. That is
+	// acceptable for everything but received winning tickets, which StoreWinningTicket syncs to disk on insert.
+	// Wait for the locks held by other processes sharing the DB file, i.e. a standby redeemer, instead of failing right away
+	if _, err := db.Exec("PRAGMA journal_mode=WAL; PRAGMA synchronous=NORMAL; PRAGMA busy_timeout=5000;"); err != nil {
+		glog.Error("Unable to set DB pragmas ", err)
+		d.Close()
+		return nil, err
+	}
 	schemaBuf := new(bytes.Buffer)
 	tmpl := template.Must(template.New("schema").Parse(schema))
 	tmpl.Execute(schemaBuf, LivepeerDBVersion)
@@ -184,6 +377,13 @@ func InitDB(dbPath string) (*DB, error) {
 	} else if dbVersion < LivepeerDBVersion {
 		// Upgrade stepwise up to the correct version using the migration
 		// procedure for each version
+		for v := dbVersion; v < LivepeerDBVersion; v++ {
+			if err := migrate(db, v); err != nil {
+				glog.Errorf("Unable to upgrade DB from version %v err=%q", v, err)
+				d.Close()
+				return nil, err
+			}
+		}
 	} else if dbVersion == LivepeerDBVersion {
 		// all good; nothing to do
 	}
@@ -208,8 +408,8 @@ func InitDB(dbPath string) (*DB, error) {
 
 	// updateOrch prepared statement
 	stmt, err = db.Prepare(`
-	INSERT INTO orchestrators(updatedAt, ethereumAddr, serviceURI, pricePerPixel, activationRound, deactivationRound, stake, createdAt) 
-	VALUES(datetime(), :ethereumAddr, :serviceURI, :pricePerPixel, :activationRound, :deactivationRound, :stake, datetime()) 
+	INSERT INTO orchestrators(updatedAt, ethereumAddr, serviceURI, pricePerPixel, activationRound, deactivationRound, stake, region, datacenter, createdAt) 
+	VALUES(datetime(), :ethereumAddr, :serviceURI, :pricePerPixel, :activationRound, :deactivationRound, :stake, :region, :datacenter, datetime()) 
 	ON CONFLICT(ethereumAddr) DO UPDATE SET 
 	updatedAt = excluded.updatedAt,
 	serviceURI =
@@ -231,7 +431,15 @@ func InitDB(dbPath string) (*DB, error) {
 	stake = 
 		CASE WHEN excluded.stake == 0
 		THEN orchestrators.stake
-		ELSE excluded.stake END 
+		ELSE excluded.stake END,
+	region =
+		CASE WHEN trim(excluded.region) == ""
+		THEN orchestrators.region
+		ELSE trim(excluded.region) END,
+	datacenter =
+		CASE WHEN trim(excluded.datacenter) == ""
+		THEN orchestrators.datacenter
+		ELSE trim(excluded.datacenter) END
 	`)
 	if err != nil {
 		glog.Error("Unable to prepare updateOrch ", err)
@@ -298,6 +506,15 @@ func InitDB(dbPath string) (*DB, error) {
 	}
 	d.selectEarliestWinningTicket = stmt
 
+	// Select earliest tickets
+	stmt, err = db.Prepare("SELECT sender, recipient, faceValue, winProb, senderNonce, recipientRand, recipientRandHash, sig, creationRound, creationRoundBlockHash, paramsExpirationBlock FROM ticketQueue WHERE sender=? AND creationRound >= ? AND redeemedAt IS NULL AND txHash IS NULL ORDER BY createdAt ASC LIMIT ?")
+	if err != nil {
+		glog.Error("Unable to prepare selectEarliestWinningTickets ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectEarliestWinningTickets = stmt
+
 	stmt, err = db.Prepare("SELECT count(sig) FROM ticketQueue WHERE sender=? AND creationRound >= ? AND redeemedAt IS NULL AND txHash IS NULL")
 	if err != nil {
 		glog.Error("Unable to prepare winningTicketCount ", err)
@@ -306,6 +523,22 @@ func InitDB(dbPath string) (*DB, error) {
 	}
 	d.winningTicketCount = stmt
 
+	stmt, err = db.Prepare("SELECT faceValue FROM ticketQueue WHERE sender=? AND creationRound >= ? AND redeemedAt IS NULL AND txHash IS NULL")
+	if err != nil {
+		glog.Error("Unable to prepare winningTicketFaceValues ", err)
+		d.Close()
+		return nil, err
+	}
+	d.winningTicketFaceValues = stmt
+
+	stmt, err = db.Prepare("SELECT DISTINCT sender FROM ticketQueue WHERE creationRound >= ? AND redeemedAt IS NULL AND txHash IS NULL")
+	if err != nil {
+		glog.Error("Unable to prepare selectWinningTicketSenders ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectWinningTicketSenders = stmt
+
 	// Remove latest ticket
 	stmt, err = db.Prepare("DELETE FROM ticketQueue WHERE sig=?")
 	if err != nil {
@@ -360,10 +593,133 @@ func InitDB(dbPath string) (*DB, error) {
 	}
 	d.deleteMiniHeader = stmt
 
+	// Select earnings for a sender, stream and round
+	stmt, err = db.Prepare("SELECT tickets, winningTickets, ev, winningFaceValue, pixels, fees FROM earnings WHERE sender=? AND manifestID=? AND round=?")
+	if err != nil {
+		glog.Error("Unable to prepare selectEarnings ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectEarnings = stmt
+
+	// Update earnings for a sender, stream and round
+	stmt, err = db.Prepare(`
+	INSERT OR REPLACE INTO earnings(sender, manifestID, round, tickets, winningTickets, ev, winningFaceValue, pixels, fees, updatedAt)
+	VALUES(:sender, :manifestID, :round, :tickets, :winningTickets, :ev, :winningFaceValue, :pixels, :fees, datetime())
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare updateEarnings ", err)
+		d.Close()
+		return nil, err
+	}
+	d.updateEarnings = stmt
+
+	// Add to the performance stats of an orchestrator
+	stmt, err = db.Prepare(`
+	INSERT INTO orchestratorStats(serviceURI, segments, successes, timeouts, roundTripMs, updatedAt)
+	VALUES(:serviceURI, :segments, :successes, :timeouts, :roundTripMs, datetime())
+	ON CONFLICT(serviceURI) DO UPDATE SET
+		segments = segments + excluded.segments,
+		successes = successes + excluded.successes,
+		timeouts = timeouts + excluded.timeouts,
+		roundTripMs = roundTripMs + excluded.roundTripMs,
+		updatedAt = excluded.updatedAt
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare addOrchStats ", err)
+		d.Close()
+		return nil, err
+	}
+	d.addOrchStats = stmt
+
+	// Replace the reputation of an orchestrator
+	stmt, err = db.Prepare(`
+	INSERT OR REPLACE INTO orchestratorReputation(serviceURI, score, verificationFailures, pricePerPixel, updatedAt)
+	VALUES(:serviceURI, :score, :verificationFailures, :pricePerPixel, datetime())
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare updateOrchReputation ", err)
+		d.Close()
+		return nil, err
+	}
+	d.updateOrchReputation = stmt
+
+	// Select spending for a recipient, stream and hour
+	stmt, err = db.Prepare("SELECT tickets, ev, winningTickets, winningFaceValue FROM spending WHERE recipient=? AND manifestID=? AND hour=?")
+	if err != nil {
+		glog.Error("Unable to prepare selectSpending ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectSpending = stmt
+
+	// Update spending for a recipient, stream and hour
+	stmt, err = db.Prepare(`
+	INSERT OR REPLACE INTO spending(recipient, manifestID, hour, tickets, ev, winningTickets, winningFaceValue, updatedAt)
+	VALUES(:recipient, :manifestID, :hour, :tickets, :ev, :winningTickets, :winningFaceValue, datetime())
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare updateSpending ", err)
+		d.Close()
+		return nil, err
+	}
+	d.updateSpending = stmt
+
+	// Acquire or renew a lease unless it is held by someone else and not expired
+	stmt, err = db.Prepare(`
+	INSERT INTO leases(name, holder, expiresAt) VALUES(:name, :holder, :expiresAt)
+	ON CONFLICT(name) DO UPDATE SET holder=excluded.holder, expiresAt=excluded.expiresAt
+	WHERE leases.holder=excluded.holder OR leases.expiresAt <= :now
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare acquireLease ", err)
+		d.Close()
+		return nil, err
+	}
+	d.acquireLease = stmt
+
+	stmt, err = db.Prepare("DELETE FROM leases WHERE name=? AND holder=?")
+	if err != nil {
+		glog.Error("Unable to prepare releaseLease ", err)
+		d.Close()
+		return nil, err
+	}
+	d.releaseLease = stmt
+
+	// Insert transaction
+	stmt, err = db.Prepare(`
+	INSERT OR REPLACE INTO transactions(txHash, method, blockNumber, gasUsed, gasPrice, cost, failed, createdAt)
+	VALUES(:txHash, :method, :blockNumber, :gasUsed, :gasPrice, :cost, :failed, :createdAt)
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare insertTransaction ", err)
+		d.Close()
+		return nil, err
+	}
+	d.insertTransaction = stmt
+
 	glog.V(DEBUG).Info("Initialized DB node")
 	return &d, nil
 }
 
+// migrate upgrades the schema of a DB at version to the next version
+func migrate(db *sql.DB, version int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(migrations[version]); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec("UPDATE kv SET value = ?, updatedAt = datetime() WHERE key = 'dbVersion'", strconv.Itoa(version+1)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	glog.Infof("Upgraded DB to version %v", version+1)
+	return tx.Commit()
+}
+
 func (db *DB) Close() {
 	glog.V(DEBUG).Info("Closing DB")
 	if db.selectKV != nil {
@@ -396,9 +752,18 @@ func (db *DB) Close() {
 	if db.selectEarliestWinningTicket != nil {
 		db.selectEarliestWinningTicket.Close()
 	}
+	if db.selectEarliestWinningTickets != nil {
+		db.selectEarliestWinningTickets.Close()
+	}
 	if db.winningTicketCount != nil {
 		db.winningTicketCount.Close()
 	}
+	if db.winningTicketFaceValues != nil {
+		db.winningTicketFaceValues.Close()
+	}
+	if db.selectWinningTicketSenders != nil {
+		db.selectWinningTicketSenders.Close()
+	}
 	if db.markWinningTicketRedeemed != nil {
 		db.markWinningTicketRedeemed.Close()
 	}
@@ -417,6 +782,33 @@ func (db *DB) Close() {
 	if db.deleteMiniHeader != nil {
 		db.deleteMiniHeader.Close()
 	}
+	if db.selectEarnings != nil {
+		db.selectEarnings.Close()
+	}
+	if db.updateEarnings != nil {
+		db.updateEarnings.Close()
+	}
+	if db.addOrchStats != nil {
+		db.addOrchStats.Close()
+	}
+	if db.updateOrchReputation != nil {
+		db.updateOrchReputation.Close()
+	}
+	if db.selectSpending != nil {
+		db.selectSpending.Close()
+	}
+	if db.updateSpending != nil {
+		db.updateSpending.Close()
+	}
+	if db.acquireLease != nil {
+		db.acquireLease.Close()
+	}
+	if db.releaseLease != nil {
+		db.releaseLease.Close()
+	}
+	if db.insertTransaction != nil {
+		db.insertTransaction.Close()
+	}
 	if db.dbh != nil {
 		db.dbh.Close()
 	}
@@ -493,6 +885,8 @@ func (db *DB) UpdateOrch(orch *DBOrch) error {
 		sql.Named("activationRound", orch.ActivationRound),
 		sql.Named("deactivationRound", orch.DeactivationRound),
 		sql.Named("stake", orch.Stake),
+		sql.Named("region", orch.Region),
+		sql.Named("datacenter", orch.Datacenter),
 	)
 
 	if err != nil {
@@ -522,13 +916,18 @@ func (db *DB) SelectOrchs(filter *DBOrchFilter) ([]*DBOrch, error) {
 			activationRound   int64
 			deactivationRound int64
 			stake             int64
+			region            sql.NullString
+			datacenter        sql.NullString
 		)
-		if err := rows.Scan(&serviceURI, &ethereumAddr, &pricePerPixel, &activationRound, &deactivationRound, &stake); err != nil {
+		if err := rows.Scan(&serviceURI, &ethereumAddr, &pricePerPixel, &activationRound, &deactivationRound, &stake, &region, &datacenter); err != nil {
 			glog.Error("db: Unable to fetch orchestrator ", err)
 			continue
 		}
 
-		orchs = append(orchs, NewDBOrch(serviceURI, ethereumAddr, pricePerPixel, activationRound, deactivationRound, stake))
+		orch := NewDBOrch(serviceURI, ethereumAddr, pricePerPixel, activationRound, deactivationRound, stake)
+		orch.Region = region.String
+		orch.Datacenter = datacenter.String
+		orchs = append(orchs, orch)
 	}
 	return orchs, nil
 }
@@ -687,7 +1086,29 @@ func (db *DB) StoreWinningTicket(ticket *pm.SignedTicket) error {
 		return errors.New("cannot store nil recipientRand")
 	}
 
-	_, err := db.insertWinningTicket.Exec(
+	// A payment is acknowledged once its winning tickets are stored, so sync the insert to disk even though
+	// the DB otherwise uses synchronous=NORMAL. The DB has a single connection, so holding it keeps other
+	// writes from running with synchronous=FULL.
+	ctx := context.Background()
+	conn, err := db.dbh.Conn(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed inserting winning ticket sender=%v", ticket.Sender.Hex())
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "PRAGMA synchronous=FULL"); err != nil {
+		return errors.Wrapf(err, "failed inserting winning ticket sender=%v", ticket.Sender.Hex())
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "PRAGMA synchronous=NORMAL"); err != nil {
+			glog.Errorf("Unable to reset DB synchronous mode err=%q", err)
+		}
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed inserting winning ticket sender=%v", ticket.Sender.Hex())
+	}
+	_, err = tx.Stmt(db.insertWinningTicket).Exec(
 		sql.Named("sender", ticket.Sender.Hex()),
 		sql.Named("recipient", ticket.Recipient.Hex()),
 		sql.Named("faceValue", ticket.FaceValue.Bytes()),
@@ -700,7 +1121,11 @@ func (db *DB) StoreWinningTicket(ticket *pm.SignedTicket) error {
 		sql.Named("creationRoundBlockHash", ticket.CreationRoundBlockHash.Hex()),
 		sql.Named("paramsExpirationBlock", ticket.ParamsExpirationBlock.Int64()),
 	)
-
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		tx.Rollback()
+	}
 	if err != nil {
 		return errors.Wrapf(err, "failed inserting winning ticket sender=%v", ticket.Sender.Hex())
 	}
@@ -753,6 +1178,38 @@ func (db *DB) RemoveWinningTicket(ticket *pm.SignedTicket) error {
 func (db *DB) SelectEarliestWinningTicket(sender ethcommon.Address, minCreationRound int64) (*pm.SignedTicket, error) {
 
 	row := db.selectEarliestWinningTicket.QueryRow(sender.Hex(), minCreationRound)
+	ticket, err := scanWinningTicket(row)
+	if err != nil {
+		if err.Error() != "sql: no rows in result set" {
+			return nil, fmt.Errorf("could not retrieve earliest ticket err=%q", err)
+		}
+		// If there is no result return no error, just nil value
+		return nil, nil
+	}
+	return ticket, nil
+}
+
+// SelectEarliestWinningTickets selects up to 'limit' of the earliest stored winning tickets for a 'sender'
+// which are not yet redeemed
+func (db *DB) SelectEarliestWinningTickets(sender ethcommon.Address, minCreationRound int64, limit int) ([]*pm.SignedTicket, error) {
+	rows, err := db.selectEarliestWinningTickets.Query(sender.Hex(), minCreationRound, limit)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve earliest tickets err=%q", err)
+	}
+	defer rows.Close()
+
+	var tickets []*pm.SignedTicket
+	for rows.Next() {
+		ticket, err := scanWinningTicket(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve earliest tickets err=%q", err)
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, rows.Err()
+}
+
+func scanWinningTicket(row interface{ Scan(...interface{}) error }) (*pm.SignedTicket, error) {
 	var (
 		senderString           string
 		recipient              string
@@ -767,16 +1224,12 @@ func (db *DB) SelectEarliestWinningTicket(sender ethcommon.Address, minCreationR
 		paramsExpirationBlock  int64
 	)
 	if err := row.Scan(&senderString, &recipient, &faceValue, &winProb, &senderNonce, &recipientRand, &recipientRandHash, &sig, &creationRound, &creationRoundBlockHash, &paramsExpirationBlock); err != nil {
-		if err.Error() != "sql: no rows in result set" {
-			return nil, fmt.Errorf("could not retrieve earliest ticket err=%q", err)
-		}
-		// If there is no result return no error, just nil value
-		return nil, nil
+		return nil, err
 	}
 
 	return &pm.SignedTicket{
 		Ticket: &pm.Ticket{
-			Sender:                 sender,
+			Sender:                 ethcommon.HexToAddress(senderString),
 			Recipient:              ethcommon.HexToAddress(recipient),
 			FaceValue:              new(big.Int).SetBytes(faceValue),
 			WinProb:                new(big.Int).SetBytes(winProb),
@@ -806,8 +1259,533 @@ func (db *DB) WinningTicketCount(sender ethcommon.Address, minCreationRound int6
 	return int(count64), nil
 }
 
+// WinningTicketFaceValue returns the sum of the face values of the non-redeemed winning tickets for a 'sender'
+func (db *DB) WinningTicketFaceValue(sender ethcommon.Address, minCreationRound int64) (*big.Int, error) {
+	rows, err := db.winningTicketFaceValues.Query(sender.Hex(), minCreationRound)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed selecting winning ticket face values")
+	}
+	defer rows.Close()
+
+	total := big.NewInt(0)
+	for rows.Next() {
+		var faceValue []byte
+		if err := rows.Scan(&faceValue); err != nil {
+			return nil, errors.Wrap(err, "failed scanning winning ticket face value")
+		}
+		total.Add(total, new(big.Int).SetBytes(faceValue))
+	}
+	return total, rows.Err()
+}
+
+// SelectWinningTicketSenders returns the senders that have non-redeemed winning tickets
+func (db *DB) SelectWinningTicketSenders(minCreationRound int64) ([]ethcommon.Address, error) {
+	rows, err := db.selectWinningTicketSenders.Query(minCreationRound)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed selecting winning ticket senders")
+	}
+	defer rows.Close()
+
+	var senders []ethcommon.Address
+	for rows.Next() {
+		var sender string
+		if err := rows.Scan(&sender); err != nil {
+			return nil, errors.Wrap(err, "failed scanning winning ticket sender")
+		}
+		senders = append(senders, ethcommon.HexToAddress(sender))
+	}
+	return senders, rows.Err()
+}
+
+// AddEarnings adds the tickets, fees and pixels for a sender's stream in a round to the stored totals
+func (db *DB) AddEarnings(earnings *DBEarnings) error {
+	if db == nil {
+		return nil
+	}
+	if earnings == nil {
+		return errors.New("cannot add nil earnings")
+	}
+
+	db.earningsMu.Lock()
+	defer db.earningsMu.Unlock()
+
+	var (
+		evStr            string
+		winningFaceValue string
+		feesStr          string
+	)
+	total := &DBEarnings{EV: big.NewRat(0, 1), WinningFaceValue: big.NewInt(0), Fees: big.NewRat(0, 1)}
+	row := db.selectEarnings.QueryRow(earnings.Sender.Hex(), earnings.ManifestID, earnings.Round)
+	switch err := row.Scan(&total.Tickets, &total.WinningTickets, &evStr, &winningFaceValue, &total.Pixels, &feesStr); err {
+	case sql.ErrNoRows:
+	case nil:
+		if _, ok := total.EV.SetString(evStr); !ok {
+			return fmt.Errorf("invalid stored ev=%v", evStr)
+		}
+		if _, ok := total.WinningFaceValue.SetString(winningFaceValue, 10); !ok {
+			return fmt.Errorf("invalid stored winningFaceValue=%v", winningFaceValue)
+		}
+		if _, ok := total.Fees.SetString(feesStr); !ok {
+			return fmt.Errorf("invalid stored fees=%v", feesStr)
+		}
+	default:
+		return errors.Wrapf(err, "failed selecting earnings sender=%v manifestID=%v round=%v", earnings.Sender.Hex(), earnings.ManifestID, earnings.Round)
+	}
+	total.Add(earnings)
+
+	_, err := db.updateEarnings.Exec(
+		sql.Named("sender", earnings.Sender.Hex()),
+		sql.Named("manifestID", earnings.ManifestID),
+		sql.Named("round", earnings.Round),
+		sql.Named("tickets", total.Tickets),
+		sql.Named("winningTickets", total.WinningTickets),
+		sql.Named("ev", total.EV.RatString()),
+		sql.Named("winningFaceValue", total.WinningFaceValue.String()),
+		sql.Named("pixels", total.Pixels),
+		sql.Named("fees", total.Fees.RatString()),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed updating earnings sender=%v manifestID=%v round=%v", earnings.Sender.Hex(), earnings.ManifestID, earnings.Round)
+	}
+	return nil
+}
+
+// Add adds the counts and amounts of other to e. Nil amounts of e are initialized to zero.
+func (e *DBEarnings) Add(other *DBEarnings) {
+	if e.EV == nil {
+		e.EV = big.NewRat(0, 1)
+	}
+	if e.WinningFaceValue == nil {
+		e.WinningFaceValue = big.NewInt(0)
+	}
+	if e.Fees == nil {
+		e.Fees = big.NewRat(0, 1)
+	}
+	e.Tickets += other.Tickets
+	e.WinningTickets += other.WinningTickets
+	e.Pixels += other.Pixels
+	if other.EV != nil {
+		e.EV.Add(e.EV, other.EV)
+	}
+	if other.WinningFaceValue != nil {
+		e.WinningFaceValue.Add(e.WinningFaceValue, other.WinningFaceValue)
+	}
+	if other.Fees != nil {
+		e.Fees.Add(e.Fees, other.Fees)
+	}
+}
+
+// SelectEarnings returns the earnings matching the filter ordered by round, sender and stream
+func (db *DB) SelectEarnings(filter *DBEarningsFilter) ([]*DBEarnings, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	qry := "SELECT sender, manifestID, round, tickets, winningTickets, ev, winningFaceValue, pixels, fees FROM earnings"
+	var (
+		filters []string
+		args    []interface{}
+	)
+	if filter != nil {
+		if filter.Sender != nil {
+			filters = append(filters, "sender = ?")
+			args = append(args, filter.Sender.Hex())
+		}
+		if filter.ManifestID != "" {
+			filters = append(filters, "manifestID = ?")
+			args = append(args, filter.ManifestID)
+		}
+		if filter.Round != nil {
+			filters = append(filters, "round = ?")
+			args = append(args, filter.Round.Int64())
+		}
+	}
+	if len(filters) > 0 {
+		qry += " WHERE " + strings.Join(filters, " AND ")
+	}
+	qry += " ORDER BY round, sender, manifestID"
+
+	rows, err := db.dbh.Query(qry, args...)
+	if err != nil {
+		glog.Error("db: Unable to select earnings ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	earnings := []*DBEarnings{}
+	for rows.Next() {
+		var (
+			sender           string
+			evStr            string
+			winningFaceValue string
+			feesStr          string
+			e                DBEarnings
+		)
+		if err := rows.Scan(&sender, &e.ManifestID, &e.Round, &e.Tickets, &e.WinningTickets, &evStr, &winningFaceValue, &e.Pixels, &feesStr); err != nil {
+			glog.Error("db: Unable to fetch earnings ", err)
+			continue
+		}
+		e.Sender = ethcommon.HexToAddress(sender)
+		e.EV, _ = new(big.Rat).SetString(evStr)
+		e.WinningFaceValue, _ = new(big.Int).SetString(winningFaceValue, 10)
+		e.Fees, _ = new(big.Rat).SetString(feesStr)
+		earnings = append(earnings, &e)
+	}
+	return earnings, nil
+}
+
+// AcquireLease acquires the lease name for holder until ttl from now, or renews it if holder already holds it.
+// It returns false if the lease is held by another holder and has not expired. Leases allow nodes that share
+// the DB, i.e. an active/standby pair, to agree on which of them is active.
+func (db *DB) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	res, err := db.acquireLease.Exec(
+		sql.Named("name", name),
+		sql.Named("holder", holder),
+		sql.Named("expiresAt", now.Add(ttl).UnixNano()),
+		sql.Named("now", now.UnixNano()),
+	)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed acquiring lease name=%v holder=%v", name, holder)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// ReleaseLease releases the lease name if it is held by holder
+func (db *DB) ReleaseLease(name, holder string) error {
+	if _, err := db.releaseLease.Exec(name, holder); err != nil {
+		return errors.Wrapf(err, "failed releasing lease name=%v holder=%v", name, holder)
+	}
+	return nil
+}
+
+// InsertTransaction stores a mined transaction sent by the node
+func (db *DB) InsertTransaction(tx *DBTransaction) error {
+	if db == nil {
+		return nil
+	}
+	if tx == nil {
+		return errors.New("cannot insert nil transaction")
+	}
+	if tx.GasPrice == nil || tx.Cost == nil {
+		return errors.New("cannot insert transaction with nil gas price or cost")
+	}
+
+	failed := 0
+	if tx.Failed {
+		failed = 1
+	}
+	_, err := db.insertTransaction.Exec(
+		sql.Named("txHash", tx.TxHash.Hex()),
+		sql.Named("method", tx.Method),
+		sql.Named("blockNumber", tx.BlockNumber),
+		sql.Named("gasUsed", int64(tx.GasUsed)),
+		sql.Named("gasPrice", tx.GasPrice.String()),
+		sql.Named("cost", tx.Cost.String()),
+		sql.Named("failed", failed),
+		sql.Named("createdAt", tx.Time.Unix()),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed inserting transaction txHash=%v", tx.TxHash.Hex())
+	}
+	return nil
+}
+
+// SelectTransactions returns the transactions matching the filter ordered by time
+func (db *DB) SelectTransactions(filter *DBTransactionFilter) ([]*DBTransaction, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	qry := "SELECT txHash, method, blockNumber, gasUsed, gasPrice, cost, failed, createdAt FROM transactions"
+	var (
+		filters []string
+		args    []interface{}
+	)
+	if filter != nil {
+		if filter.From != nil {
+			filters = append(filters, "createdAt >= ?")
+			args = append(args, filter.From.Unix())
+		}
+		if filter.To != nil {
+			filters = append(filters, "createdAt < ?")
+			args = append(args, filter.To.Unix())
+		}
+		if filter.Method != "" {
+			filters = append(filters, "method = ?")
+			args = append(args, filter.Method)
+		}
+	}
+	if len(filters) > 0 {
+		qry += " WHERE " + strings.Join(filters, " AND ")
+	}
+	qry += " ORDER BY createdAt, txHash"
+
+	rows, err := db.dbh.Query(qry, args...)
+	if err != nil {
+		glog.Error("db: Unable to select transactions ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := []*DBTransaction{}
+	for rows.Next() {
+		var (
+			txHash    string
+			gasUsed   int64
+			gasPrice  string
+			cost      string
+			failed    int
+			createdAt int64
+			tx        DBTransaction
+		)
+		if err := rows.Scan(&txHash, &tx.Method, &tx.BlockNumber, &gasUsed, &gasPrice, &cost, &failed, &createdAt); err != nil {
+			glog.Error("db: Unable to fetch transaction ", err)
+			continue
+		}
+		tx.TxHash = ethcommon.HexToHash(txHash)
+		tx.GasUsed = uint64(gasUsed)
+		tx.GasPrice, _ = new(big.Int).SetString(gasPrice, 10)
+		tx.Cost, _ = new(big.Int).SetString(cost, 10)
+		tx.Failed = failed != 0
+		tx.Time = time.Unix(createdAt, 0).UTC()
+		txs = append(txs, &tx)
+	}
+	return txs, nil
+}
+
+// AddSpending adds the tickets sent to a recipient for a stream in an hour to the stored totals
+func (db *DB) AddSpending(spending *DBSpending) error {
+	if db == nil {
+		return nil
+	}
+	if spending == nil {
+		return errors.New("cannot add nil spending")
+	}
+
+	db.spendingMu.Lock()
+	defer db.spendingMu.Unlock()
+
+	var (
+		evStr            string
+		winningFaceValue string
+	)
+	hour := spending.Hour.Truncate(time.Hour).Unix()
+	total := &DBSpending{EV: big.NewRat(0, 1), WinningFaceValue: big.NewInt(0)}
+	row := db.selectSpending.QueryRow(spending.Recipient.Hex(), spending.ManifestID, hour)
+	switch err := row.Scan(&total.Tickets, &evStr, &total.WinningTickets, &winningFaceValue); err {
+	case sql.ErrNoRows:
+	case nil:
+		if _, ok := total.EV.SetString(evStr); !ok {
+			return fmt.Errorf("invalid stored ev=%v", evStr)
+		}
+		if _, ok := total.WinningFaceValue.SetString(winningFaceValue, 10); !ok {
+			return fmt.Errorf("invalid stored winningFaceValue=%v", winningFaceValue)
+		}
+	default:
+		return errors.Wrapf(err, "failed selecting spending recipient=%v manifestID=%v hour=%v", spending.Recipient.Hex(), spending.ManifestID, hour)
+	}
+	total.Add(spending)
+
+	_, err := db.updateSpending.Exec(
+		sql.Named("recipient", spending.Recipient.Hex()),
+		sql.Named("manifestID", spending.ManifestID),
+		sql.Named("hour", hour),
+		sql.Named("tickets", total.Tickets),
+		sql.Named("ev", total.EV.RatString()),
+		sql.Named("winningTickets", total.WinningTickets),
+		sql.Named("winningFaceValue", total.WinningFaceValue.String()),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed updating spending recipient=%v manifestID=%v hour=%v", spending.Recipient.Hex(), spending.ManifestID, hour)
+	}
+	return nil
+}
+
+// Add adds the counts and amounts of other to s. Nil amounts of s are initialized to zero.
+func (s *DBSpending) Add(other *DBSpending) {
+	if s.EV == nil {
+		s.EV = big.NewRat(0, 1)
+	}
+	if s.WinningFaceValue == nil {
+		s.WinningFaceValue = big.NewInt(0)
+	}
+	s.Tickets += other.Tickets
+	s.WinningTickets += other.WinningTickets
+	if other.EV != nil {
+		s.EV.Add(s.EV, other.EV)
+	}
+	if other.WinningFaceValue != nil {
+		s.WinningFaceValue.Add(s.WinningFaceValue, other.WinningFaceValue)
+	}
+}
+
+// SelectSpending returns the spending matching the filter ordered by hour, recipient and stream
+func (db *DB) SelectSpending(filter *DBSpendingFilter) ([]*DBSpending, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	qry := "SELECT recipient, manifestID, hour, tickets, ev, winningTickets, winningFaceValue FROM spending"
+	var (
+		filters []string
+		args    []interface{}
+	)
+	if filter != nil {
+		if filter.From != nil {
+			filters = append(filters, "hour >= ?")
+			args = append(args, filter.From.Truncate(time.Hour).Unix())
+		}
+		if filter.To != nil {
+			filters = append(filters, "hour < ?")
+			args = append(args, filter.To.Unix())
+		}
+	}
+	if len(filters) > 0 {
+		qry += " WHERE " + strings.Join(filters, " AND ")
+	}
+	qry += " ORDER BY hour, recipient, manifestID"
+
+	rows, err := db.dbh.Query(qry, args...)
+	if err != nil {
+		glog.Error("db: Unable to select spending ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	spending := []*DBSpending{}
+	for rows.Next() {
+		var (
+			recipient        string
+			hour             int64
+			evStr            string
+			winningFaceValue string
+			s                DBSpending
+		)
+		if err := rows.Scan(&recipient, &s.ManifestID, &hour, &s.Tickets, &evStr, &s.WinningTickets, &winningFaceValue); err != nil {
+			glog.Error("db: Unable to fetch spending ", err)
+			continue
+		}
+		s.Recipient = ethcommon.HexToAddress(recipient)
+		s.Hour = time.Unix(hour, 0).UTC()
+		s.EV, _ = new(big.Rat).SetString(evStr)
+		s.WinningFaceValue, _ = new(big.Int).SetString(winningFaceValue, 10)
+		spending = append(spending, &s)
+	}
+	return spending, nil
+}
+
+// AddOrchStats adds the segment counts and round trip time of an orchestrator to the stored totals
+func (db *DB) AddOrchStats(stats *DBOrchStats) error {
+	if db == nil {
+		return nil
+	}
+	if stats == nil {
+		return errors.New("cannot add nil orchestrator stats")
+	}
+
+	_, err := db.addOrchStats.Exec(
+		sql.Named("serviceURI", stats.ServiceURI),
+		sql.Named("segments", stats.Segments),
+		sql.Named("successes", stats.Successes),
+		sql.Named("timeouts", stats.Timeouts),
+		sql.Named("roundTripMs", stats.RoundTripMs),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed updating orchestrator stats serviceURI=%v", stats.ServiceURI)
+	}
+	return nil
+}
+
+// SelectOrchStats returns the stored performance stats of all orchestrators
+func (db *DB) SelectOrchStats() ([]*DBOrchStats, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.dbh.Query("SELECT serviceURI, segments, successes, timeouts, roundTripMs FROM orchestratorStats")
+	if err != nil {
+		glog.Error("db: Unable to select orchestrator stats ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*DBOrchStats{}
+	for rows.Next() {
+		var s DBOrchStats
+		if err := rows.Scan(&s.ServiceURI, &s.Segments, &s.Successes, &s.Timeouts, &s.RoundTripMs); err != nil {
+			glog.Error("db: Unable to fetch orchestrator stats ", err)
+			continue
+		}
+		stats = append(stats, &s)
+	}
+	return stats, nil
+}
+
+// UpdateOrchReputation stores the reputation of an orchestrator, replacing the stored one
+func (db *DB) UpdateOrchReputation(rep *DBOrchReputation) error {
+	if db == nil {
+		return nil
+	}
+	if rep == nil {
+		return errors.New("cannot update nil orchestrator reputation")
+	}
+
+	var price sql.NullString
+	if rep.PricePerPixel != nil {
+		price = sql.NullString{String: rep.PricePerPixel.RatString(), Valid: true}
+	}
+	_, err := db.updateOrchReputation.Exec(
+		sql.Named("serviceURI", rep.ServiceURI),
+		sql.Named("score", rep.Score),
+		sql.Named("verificationFailures", rep.VerificationFailures),
+		sql.Named("pricePerPixel", price),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed updating orchestrator reputation serviceURI=%v", rep.ServiceURI)
+	}
+	return nil
+}
+
+// SelectOrchReputations returns the stored reputation of all orchestrators
+func (db *DB) SelectOrchReputations() ([]*DBOrchReputation, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.dbh.Query("SELECT serviceURI, score, verificationFailures, pricePerPixel FROM orchestratorReputation")
+	if err != nil {
+		glog.Error("db: Unable to select orchestrator reputations ", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reps := []*DBOrchReputation{}
+	for rows.Next() {
+		var (
+			r     DBOrchReputation
+			price sql.NullString
+		)
+		if err := rows.Scan(&r.ServiceURI, &r.Score, &r.VerificationFailures, &price); err != nil {
+			glog.Error("db: Unable to fetch orchestrator reputation ", err)
+			continue
+		}
+		if price.Valid {
+			if p, ok := new(big.Rat).SetString(price.String); ok {
+				r.PricePerPixel = p
+			}
+		}
+		reps = append(reps, &r)
+	}
+	return reps, nil
+}
+
 func buildSelectOrchsQuery(filter *DBOrchFilter) (string, error) {
-	query := "SELECT ethereumAddr, serviceURI, pricePerPixel, activationRound, deactivationRound, stake FROM orchestrators "
+	query := "SELECT ethereumAddr, serviceURI, pricePerPixel, activationRound, deactivationRound, stake, region, datacenter FROM orchestrators "
 	fil, err := buildFilterOrchsQuery(filter)
 	if err != nil {
 		return "", err
@@ -853,6 +1831,14 @@ func buildFilterOrchsQuery(filter *DBOrchFilter) (string, error) {
 			}
 			filters = append(filters, fmt.Sprintf("ethereumAddr IN (%v)", strings.Join(hexAddrs, ", ")))
 		}
+
+		if len(filter.Regions) > 0 {
+			regions := make([]string, len(filter.Regions))
+			for i, region := range filter.Regions {
+				regions[i] = fmt.Sprintf("'%v'", strings.ReplaceAll(strings.ToLower(strings.TrimSpace(region)), "'", "''"))
+			}
+			filters = append(filters, fmt.Sprintf("lower(region) IN (%v)", strings.Join(regions, ", ")))
+		}
 	}
 
 	if len(filters) > 0 {
@@ -3,8 +3,11 @@ package common
 import (
 	"database/sql"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"math/big"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -183,6 +186,58 @@ func TestDBVersion(t *testing.T) {
 	}
 }
 
+func TestDBMigration(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// create a DB at version 1
+	dbraw, err := sql.Open("sqlite3", dbPath(t))
+	require.Nil(err)
+	defer dbraw.Close()
+	_, err = dbraw.Exec(`
+	CREATE TABLE kv (
+		key STRING PRIMARY KEY,
+		value STRING,
+		updatedAt STRING DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT INTO kv(key, value) VALUES('dbVersion', '1');
+	CREATE TABLE orchestrators (
+		ethereumAddr STRING PRIMARY KEY,
+		createdAt STRING DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		updatedAt STRING DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		serviceURI STRING,
+		pricePerPixel int64,
+		activationRound int64,
+		deactivationRound int64,
+		stake int64
+	);
+	INSERT INTO orchestrators(ethereumAddr, serviceURI, pricePerPixel, activationRound, deactivationRound, stake) VALUES('0x01', '127.0.0.1:8936', 1, 1, 2, 0);
+	`)
+	require.Nil(err)
+
+	dbh, err := InitDB(dbPath(t))
+	require.Nil(err)
+	defer dbh.Close()
+
+	var dbVersion int
+	require.Nil(dbraw.QueryRow("SELECT value FROM kv WHERE key = 'dbVersion'").Scan(&dbVersion))
+	assert.Equal(LivepeerDBVersion, dbVersion)
+
+	// existing orchestrators are kept and can be updated with the new columns
+	orchs, err := dbh.SelectOrchs(nil)
+	require.Nil(err)
+	require.Len(orchs, 1)
+	assert.Equal("127.0.0.1:8936", orchs[0].ServiceURI)
+	assert.Empty(orchs[0].Region)
+
+	require.Nil(dbh.UpdateOrch(&DBOrch{EthereumAddr: "0x01", Region: "us-east", Datacenter: "aws-us-east-1"}))
+	orchs, err = dbh.SelectOrchs(nil)
+	require.Nil(err)
+	require.Len(orchs, 1)
+	assert.Equal("us-east", orchs[0].Region)
+	assert.Equal("aws-us-east-1", orchs[0].Datacenter)
+}
+
 func profilesMatch(j1 []ffmpeg.VideoProfile, j2 []ffmpeg.VideoProfile) bool {
 	if len(j1) != len(j2) {
 		return false
@@ -336,6 +391,44 @@ func TestSelectUpdateOrchs_AddingUpdatingRow_NoError(t *testing.T) {
 	assert.Equal(updatedOrch[0].Stake, stakeUpdate.Stake)
 }
 
+func TestSelectUpdateOrchs_RegionAndDatacenter(t *testing.T) {
+	dbh, dbraw, err := TempDB(t)
+	require := require.New(t)
+	assert := assert.New(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	o1 := &DBOrch{EthereumAddr: pm.RandAddress().String(), ServiceURI: "127.0.0.1:8936", Region: "us-east", Datacenter: "aws-us-east-1"}
+	o2 := &DBOrch{EthereumAddr: pm.RandAddress().String(), ServiceURI: "127.0.0.1:8937", Region: "EU-West"}
+	o3 := &DBOrch{EthereumAddr: pm.RandAddress().String(), ServiceURI: "127.0.0.1:8938"}
+	for _, o := range []*DBOrch{o1, o2, o3} {
+		require.Nil(dbh.UpdateOrch(o))
+	}
+
+	// updates without a region or datacenter keep the stored ones
+	require.Nil(dbh.UpdateOrch(&DBOrch{EthereumAddr: o1.EthereumAddr, Stake: 10}))
+	orchs, err := dbh.SelectOrchs(&DBOrchFilter{Addresses: []ethcommon.Address{ethcommon.HexToAddress(o1.EthereumAddr)}})
+	require.Nil(err)
+	require.Len(orchs, 1)
+	assert.Equal("us-east", orchs[0].Region)
+	assert.Equal("aws-us-east-1", orchs[0].Datacenter)
+	assert.Equal(int64(10), orchs[0].Stake)
+
+	// regions are matched case insensitively
+	orchs, err = dbh.SelectOrchs(&DBOrchFilter{Regions: []string{"US-East", " eu-west "}})
+	require.Nil(err)
+	uris := make([]string, 0, len(orchs))
+	for _, o := range orchs {
+		uris = append(uris, o.ServiceURI)
+	}
+	assert.ElementsMatch([]string{o1.ServiceURI, o2.ServiceURI}, uris)
+
+	count, err := dbh.OrchCount(&DBOrchFilter{Regions: []string{"ap-south"}})
+	require.Nil(err)
+	assert.Equal(0, count)
+}
+
 func TestSelectUpdateOrchs_AddingMultipleRows_NoError(t *testing.T) {
 	dbh, dbraw, err := TempDB(t)
 	defer dbh.Close()
@@ -858,6 +951,47 @@ func TestWinningTicketCount(t *testing.T) {
 	assert.Equal(count, 0)
 }
 
+func TestWinningTicketFaceValue(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	dbh, dbraw, err := TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	sender := pm.RandAddress()
+	storeTicket := func(sender ethcommon.Address, faceValue int64) *pm.SignedTicket {
+		_, ticket, sig, recipientRand := defaultWinningTicket(t)
+		ticket.Sender = sender
+		ticket.FaceValue = big.NewInt(faceValue)
+		signedTicket := &pm.SignedTicket{Ticket: ticket, Sig: sig, RecipientRand: recipientRand}
+		require.Nil(dbh.StoreWinningTicket(signedTicket))
+		return signedTicket
+	}
+
+	faceValue, err := dbh.WinningTicketFaceValue(sender, 0)
+	assert.Nil(err)
+	assert.Equal(big.NewInt(0), faceValue)
+
+	ticket := storeTicket(sender, 100)
+	storeTicket(sender, 250)
+	storeTicket(pm.RandAddress(), 1000)
+	faceValue, err = dbh.WinningTicketFaceValue(sender, ticket.CreationRound)
+	assert.Nil(err)
+	assert.Equal(big.NewInt(350), faceValue)
+
+	// redeemed tickets are not counted
+	require.Nil(dbh.MarkWinningTicketRedeemed(ticket, pm.RandHash()))
+	faceValue, err = dbh.WinningTicketFaceValue(sender, ticket.CreationRound)
+	assert.Nil(err)
+	assert.Equal(big.NewInt(250), faceValue)
+
+	// expired tickets are not counted
+	faceValue, err = dbh.WinningTicketFaceValue(sender, ticket.CreationRound+100)
+	assert.Nil(err)
+	assert.Equal(big.NewInt(0), faceValue)
+}
+
 func TestInsertWinningTicket_GivenValidInputs_InsertsOneRowCorrectly(t *testing.T) {
 	dbh, dbraw, err := TempDB(t)
 	defer dbh.Close()
@@ -1074,6 +1208,123 @@ func TestSelectEarliestWinningTicket(t *testing.T) {
 
 }
 
+func TestSelectEarliestWinningTickets(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	dbh, dbraw, err := TempDB(t)
+	defer dbh.Close()
+	defer dbraw.Close()
+	require.Nil(err)
+
+	sender := ethcommon.HexToAddress("charizard")
+
+	// no tickets found
+	tickets, err := dbh.SelectEarliestWinningTickets(sender, 0, 2)
+	assert.Nil(err)
+	assert.Empty(tickets)
+
+	var stored []*pm.SignedTicket
+	for i := 0; i < 3; i++ {
+		_, ticket, sig, recipientRand := defaultWinningTicket(t)
+		ticket.Sender = sender
+		signedTicket := &pm.SignedTicket{
+			Ticket:        ticket,
+			Sig:           sig,
+			RecipientRand: recipientRand,
+		}
+		require.Nil(dbh.StoreWinningTicket(signedTicket))
+		stored = append(stored, signedTicket)
+	}
+	_, ticket, sig, recipientRand := defaultWinningTicket(t)
+	ticket.Sender = ethcommon.HexToAddress("pikachu")
+	require.Nil(dbh.StoreWinningTicket(&pm.SignedTicket{Ticket: ticket, Sig: sig, RecipientRand: recipientRand}))
+
+	// limited to the tickets of the sender
+	tickets, err = dbh.SelectEarliestWinningTickets(sender, stored[0].CreationRound, 2)
+	assert.Nil(err)
+	assert.Len(tickets, 2)
+
+	tickets, err = dbh.SelectEarliestWinningTickets(sender, stored[0].CreationRound, 5)
+	assert.Nil(err)
+	assert.ElementsMatch(stored, tickets)
+
+	// excluding expired tickets
+	tickets, err = dbh.SelectEarliestWinningTickets(sender, stored[0].CreationRound+100, 5)
+	assert.Nil(err)
+	assert.Empty(tickets)
+
+	// excluding submitted tickets
+	require.Nil(dbh.MarkWinningTicketRedeemed(stored[0], pm.RandHash()))
+	tickets, err = dbh.SelectEarliestWinningTickets(sender, stored[0].CreationRound, 5)
+	assert.Nil(err)
+	assert.ElementsMatch(stored[1:], tickets)
+}
+
+func TestSelectWinningTicketSenders(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	dbh, dbraw, err := TempDB(t)
+	defer dbh.Close()
+	defer dbraw.Close()
+	require.Nil(err)
+
+	// no tickets
+	senders, err := dbh.SelectWinningTicketSenders(0)
+	assert.Nil(err)
+	assert.Empty(senders)
+
+	var stored []*pm.SignedTicket
+	for _, sender := range []ethcommon.Address{{1}, {1}, {2}} {
+		_, ticket, sig, recipientRand := defaultWinningTicket(t)
+		ticket.Sender = sender
+		signedTicket := &pm.SignedTicket{Ticket: ticket, Sig: sig, RecipientRand: recipientRand}
+		require.Nil(dbh.StoreWinningTicket(signedTicket))
+		stored = append(stored, signedTicket)
+	}
+
+	senders, err = dbh.SelectWinningTicketSenders(stored[0].CreationRound)
+	assert.Nil(err)
+	assert.ElementsMatch([]ethcommon.Address{{1}, {2}}, senders)
+
+	// excluding senders with only expired tickets
+	senders, err = dbh.SelectWinningTicketSenders(stored[0].CreationRound + 100)
+	assert.Nil(err)
+	assert.Empty(senders)
+
+	// excluding senders with only submitted tickets
+	require.Nil(dbh.MarkWinningTicketRedeemed(stored[2], pm.RandHash()))
+	senders, err = dbh.SelectWinningTicketSenders(stored[0].CreationRound)
+	assert.Nil(err)
+	assert.Equal([]ethcommon.Address{{1}}, senders)
+}
+
+func TestInitDB_WriteAheadLog(t *testing.T) {
+	require := require.New(t)
+	dir, err := ioutil.TempDir("", "TestInitDB_WriteAheadLog")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	dbh, err := InitDB(filepath.Join(dir, "lpdb.sqlite3"))
+	require.Nil(err)
+	defer dbh.Close()
+
+	var mode string
+	require.Nil(dbh.dbh.QueryRow("PRAGMA journal_mode").Scan(&mode))
+	assert.Equal(t, "wal", mode)
+
+	// synchronous=NORMAL (1) is used for all writes, including after a winning ticket is stored with synchronous=FULL
+	var synchronous int
+	require.Nil(dbh.dbh.QueryRow("PRAGMA synchronous").Scan(&synchronous))
+	assert.Equal(t, 1, synchronous)
+	_, ticket, sig, recipientRand := defaultWinningTicket(t)
+	require.Nil(dbh.StoreWinningTicket(&pm.SignedTicket{Ticket: ticket, Sig: sig, RecipientRand: recipientRand}))
+	require.Nil(dbh.dbh.QueryRow("PRAGMA synchronous").Scan(&synchronous))
+	assert.Equal(t, 1, synchronous)
+	count, err := dbh.WinningTicketCount(ticket.Sender, ticket.CreationRound)
+	require.Nil(err)
+	assert.Equal(t, 1, count)
+}
+
 func TestMarkWinningTicketRedeemed_GivenNilTicket_ReturnsError(t *testing.T) {
 	dbh, dbraw, err := TempDB(t)
 	defer dbh.Close()
@@ -1351,3 +1602,282 @@ func defaultMiniHeader() *blockwatch.MiniHeader {
 	block.Logs = []types.Log{log}
 	return block
 }
+
+func TestEarnings(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	dbh, dbraw, err := TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	// nil earnings
+	assert.EqualError(dbh.AddEarnings(nil), "cannot add nil earnings")
+
+	// no earnings
+	earnings, err := dbh.SelectEarnings(nil)
+	assert.Nil(err)
+	assert.Empty(earnings)
+
+	sender1 := pm.RandAddress()
+	sender2 := pm.RandAddress()
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	require.Nil(dbh.AddEarnings(&DBEarnings{Sender: sender1, ManifestID: "foo", Round: 1, Tickets: 2, EV: big.NewRat(201, 2)}))
+	require.Nil(dbh.AddEarnings(&DBEarnings{Sender: sender1, ManifestID: "foo", Round: 1, Tickets: 1, WinningTickets: 1, EV: big.NewRat(99, 2), WinningFaceValue: maxUint256}))
+	require.Nil(dbh.AddEarnings(&DBEarnings{Sender: sender1, ManifestID: "foo", Round: 1, Pixels: 1000, Fees: big.NewRat(1000, 3)}))
+	require.Nil(dbh.AddEarnings(&DBEarnings{Sender: sender1, ManifestID: "bar", Round: 2, Tickets: 1, EV: big.NewRat(10, 1)}))
+	require.Nil(dbh.AddEarnings(&DBEarnings{Sender: sender2, ManifestID: "baz", Round: 2, Tickets: 1, EV: big.NewRat(20, 1)}))
+
+	earnings, err = dbh.SelectEarnings(nil)
+	require.Nil(err)
+	require.Len(earnings, 3)
+	assert.Equal(sender1, earnings[0].Sender)
+	assert.Equal("foo", earnings[0].ManifestID)
+	assert.Equal(int64(1), earnings[0].Round)
+	assert.Equal(int64(3), earnings[0].Tickets)
+	assert.Equal(int64(1), earnings[0].WinningTickets)
+	assert.Equal(int64(1000), earnings[0].Pixels)
+	// fractional EV and fees are not truncated
+	assert.Zero(earnings[0].EV.Cmp(big.NewRat(150, 1)))
+	assert.Zero(earnings[0].Fees.Cmp(big.NewRat(1000, 3)))
+	assert.Equal(maxUint256, earnings[0].WinningFaceValue)
+	assert.Equal(int64(2), earnings[1].Round)
+	assert.Equal(int64(2), earnings[2].Round)
+
+	// filter by sender
+	earnings, err = dbh.SelectEarnings(&DBEarningsFilter{Sender: &sender2})
+	require.Nil(err)
+	require.Len(earnings, 1)
+	assert.Equal("baz", earnings[0].ManifestID)
+	assert.Equal(big.NewInt(0), earnings[0].WinningFaceValue)
+	assert.Equal(int64(0), earnings[0].Pixels)
+	assert.Zero(earnings[0].Fees.Sign())
+
+	// filter by stream
+	earnings, err = dbh.SelectEarnings(&DBEarningsFilter{ManifestID: "bar"})
+	require.Nil(err)
+	require.Len(earnings, 1)
+	assert.Equal(sender1, earnings[0].Sender)
+
+	// filter by round and sender
+	earnings, err = dbh.SelectEarnings(&DBEarningsFilter{Sender: &sender1, Round: big.NewInt(2)})
+	require.Nil(err)
+	require.Len(earnings, 1)
+	assert.Equal("bar", earnings[0].ManifestID)
+
+	// no match
+	earnings, err = dbh.SelectEarnings(&DBEarningsFilter{Round: big.NewInt(3)})
+	require.Nil(err)
+	assert.Empty(earnings)
+}
+
+func TestOrchStats(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	dbh, dbraw, err := TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	// nil stats
+	assert.EqualError(dbh.AddOrchStats(nil), "cannot add nil orchestrator stats")
+
+	// no stats
+	stats, err := dbh.SelectOrchStats()
+	assert.Nil(err)
+	assert.Empty(stats)
+
+	require.Nil(dbh.AddOrchStats(&DBOrchStats{ServiceURI: "https://o1:8935", Segments: 3, Successes: 2, Timeouts: 1, RoundTripMs: 1500}))
+	require.Nil(dbh.AddOrchStats(&DBOrchStats{ServiceURI: "https://o1:8935", Segments: 2, Successes: 2, RoundTripMs: 1000}))
+	require.Nil(dbh.AddOrchStats(&DBOrchStats{ServiceURI: "https://o2:8935", Segments: 1, Timeouts: 1}))
+
+	stats, err = dbh.SelectOrchStats()
+	require.Nil(err)
+	require.Len(stats, 2)
+	byURI := make(map[string]*DBOrchStats)
+	for _, s := range stats {
+		byURI[s.ServiceURI] = s
+	}
+	assert.Equal(&DBOrchStats{ServiceURI: "https://o1:8935", Segments: 5, Successes: 4, Timeouts: 1, RoundTripMs: 2500}, byURI["https://o1:8935"])
+	assert.Equal(&DBOrchStats{ServiceURI: "https://o2:8935", Segments: 1, Timeouts: 1}, byURI["https://o2:8935"])
+}
+
+func TestOrchReputation(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	dbh, dbraw, err := TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	// nil reputation
+	assert.EqualError(dbh.UpdateOrchReputation(nil), "cannot update nil orchestrator reputation")
+
+	// no reputations
+	reps, err := dbh.SelectOrchReputations()
+	assert.Nil(err)
+	assert.Empty(reps)
+
+	require.Nil(dbh.UpdateOrchReputation(&DBOrchReputation{ServiceURI: "https://o1:8935", Score: 0.5, VerificationFailures: 1}))
+	require.Nil(dbh.UpdateOrchReputation(&DBOrchReputation{ServiceURI: "https://o1:8935", Score: 0.75, VerificationFailures: 2, PricePerPixel: big.NewRat(1, 3)}))
+	require.Nil(dbh.UpdateOrchReputation(&DBOrchReputation{ServiceURI: "https://o2:8935", Score: 0.25}))
+
+	reps, err = dbh.SelectOrchReputations()
+	require.Nil(err)
+	require.Len(reps, 2)
+	byURI := make(map[string]*DBOrchReputation)
+	for _, r := range reps {
+		byURI[r.ServiceURI] = r
+	}
+	o1 := byURI["https://o1:8935"]
+	assert.Equal(0.75, o1.Score)
+	assert.Equal(int64(2), o1.VerificationFailures)
+	assert.Zero(o1.PricePerPixel.Cmp(big.NewRat(1, 3)))
+	assert.Equal(&DBOrchReputation{ServiceURI: "https://o2:8935", Score: 0.25}, byURI["https://o2:8935"])
+}
+
+func TestSpending(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	dbh, dbraw, err := TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	// nil spending
+	assert.EqualError(dbh.AddSpending(nil), "cannot add nil spending")
+
+	// no spending
+	spending, err := dbh.SelectSpending(nil)
+	assert.Nil(err)
+	assert.Empty(spending)
+
+	o1 := ethcommon.Address{1}
+	o2 := ethcommon.Address{2}
+	hour := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	require.Nil(dbh.AddSpending(&DBSpending{Recipient: o1, ManifestID: "foo", Hour: hour.Add(5 * time.Minute), Tickets: 2, EV: big.NewRat(1, 2)}))
+	require.Nil(dbh.AddSpending(&DBSpending{Recipient: o1, ManifestID: "foo", Hour: hour.Add(50 * time.Minute), Tickets: 1, EV: big.NewRat(1, 3)}))
+	require.Nil(dbh.AddSpending(&DBSpending{Recipient: o1, Hour: hour, WinningTickets: 1, WinningFaceValue: big.NewInt(1000)}))
+	require.Nil(dbh.AddSpending(&DBSpending{Recipient: o2, ManifestID: "bar", Hour: hour.Add(time.Hour), Tickets: 1, EV: big.NewRat(2, 1)}))
+
+	spending, err = dbh.SelectSpending(nil)
+	require.Nil(err)
+	require.Len(spending, 3)
+	assert.Equal(&DBSpending{Recipient: o1, Hour: hour, EV: big.NewRat(0, 1), WinningTickets: 1, WinningFaceValue: big.NewInt(1000)}, spending[0])
+	assert.Equal(&DBSpending{Recipient: o1, ManifestID: "foo", Hour: hour, Tickets: 3, EV: big.NewRat(5, 6), WinningFaceValue: big.NewInt(0)}, spending[1])
+	assert.Equal(&DBSpending{Recipient: o2, ManifestID: "bar", Hour: hour.Add(time.Hour), Tickets: 1, EV: big.NewRat(2, 1), WinningFaceValue: big.NewInt(0)}, spending[2])
+
+	// filter by time range
+	from := hour.Add(30 * time.Minute)
+	spending, err = dbh.SelectSpending(&DBSpendingFilter{From: &from})
+	require.Nil(err)
+	require.Len(spending, 3)
+	to := hour.Add(time.Hour)
+	spending, err = dbh.SelectSpending(&DBSpendingFilter{From: &from, To: &to})
+	require.Nil(err)
+	require.Len(spending, 2)
+	from = hour.Add(time.Hour)
+	spending, err = dbh.SelectSpending(&DBSpendingFilter{From: &from})
+	require.Nil(err)
+	require.Len(spending, 1)
+	assert.Equal(o2, spending[0].Recipient)
+}
+
+func TestLeases(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	// first holder acquires the lease
+	ok, err := dbh.AcquireLease("redeemer", "a", time.Minute)
+	require.Nil(err)
+	assert.True(ok)
+
+	// holder renews its lease, others can't acquire it before it expires
+	ok, err = dbh.AcquireLease("redeemer", "a", time.Minute)
+	require.Nil(err)
+	assert.True(ok)
+	ok, err = dbh.AcquireLease("redeemer", "b", time.Minute)
+	require.Nil(err)
+	assert.False(ok)
+
+	// other leases are independent
+	ok, err = dbh.AcquireLease("foo", "b", time.Minute)
+	require.Nil(err)
+	assert.True(ok)
+
+	// expired lease is taken over
+	ok, err = dbh.AcquireLease("redeemer", "a", -time.Second)
+	require.Nil(err)
+	assert.True(ok)
+	ok, err = dbh.AcquireLease("redeemer", "b", time.Minute)
+	require.Nil(err)
+	assert.True(ok)
+	ok, err = dbh.AcquireLease("redeemer", "a", time.Minute)
+	require.Nil(err)
+	assert.False(ok)
+
+	// only the holder can release the lease
+	require.Nil(dbh.ReleaseLease("redeemer", "a"))
+	ok, err = dbh.AcquireLease("redeemer", "a", time.Minute)
+	require.Nil(err)
+	assert.False(ok)
+	require.Nil(dbh.ReleaseLease("redeemer", "b"))
+	ok, err = dbh.AcquireLease("redeemer", "a", time.Minute)
+	require.Nil(err)
+	assert.True(ok)
+}
+
+func TestTransactions(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	dbh, dbraw, err := TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	assert.EqualError(dbh.InsertTransaction(nil), "cannot insert nil transaction")
+	assert.EqualError(dbh.InsertTransaction(&DBTransaction{Method: "reward"}), "cannot insert transaction with nil gas price or cost")
+
+	txs, err := dbh.SelectTransactions(nil)
+	assert.Nil(err)
+	assert.Empty(txs)
+
+	now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	reward := &DBTransaction{TxHash: ethcommon.Hash{1}, Method: "reward", BlockNumber: 10, GasUsed: 300000, GasPrice: big.NewInt(2), Cost: big.NewInt(600000), Time: now}
+	redeem := &DBTransaction{TxHash: ethcommon.Hash{2}, Method: "redeemWinningTicket", BlockNumber: 11, GasUsed: 200000, GasPrice: big.NewInt(3), Cost: big.NewInt(600000), Failed: true, Time: now.Add(time.Hour)}
+	initRound := &DBTransaction{TxHash: ethcommon.Hash{3}, Method: "initializeRound", BlockNumber: 12, GasUsed: 100000, GasPrice: big.NewInt(1), Cost: big.NewInt(100000), Time: now.Add(2 * time.Hour)}
+	for _, tx := range []*DBTransaction{redeem, reward, initRound} {
+		require.Nil(dbh.InsertTransaction(tx))
+	}
+
+	// ordered by time
+	txs, err = dbh.SelectTransactions(nil)
+	require.Nil(err)
+	assert.Equal([]*DBTransaction{reward, redeem, initRound}, txs)
+
+	// filters
+	from, to := now.Add(time.Hour), now.Add(2*time.Hour)
+	txs, err = dbh.SelectTransactions(&DBTransactionFilter{From: &from, To: &to})
+	require.Nil(err)
+	assert.Equal([]*DBTransaction{redeem}, txs)
+	txs, err = dbh.SelectTransactions(&DBTransactionFilter{Method: "initializeRound"})
+	require.Nil(err)
+	assert.Equal([]*DBTransaction{initRound}, txs)
+
+	// same tx is stored once
+	require.Nil(dbh.InsertTransaction(reward))
+	txs, err = dbh.SelectTransactions(nil)
+	require.Nil(err)
+	assert.Len(txs, 3)
+}
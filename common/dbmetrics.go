@@ -0,0 +1,233 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/monitor"
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriver is the SQLite driver, wrapped to record the latency of the queries when monitoring is enabled
+const sqliteDriver = "sqlite3_monitored"
+
+const dbOperationOther = "other"
+
+// Operations of the queries the latency is recorded by. The other queries are recorded together.
+var dbOperations = map[string]bool{
+	"select": true,
+	"insert": true,
+	"update": true,
+	"delete": true,
+	"commit": true,
+}
+
+func init() {
+	sql.Register(sqliteDriver, monitoredDriver{&sqlite3.SQLiteDriver{}})
+}
+
+type monitoredDriver struct {
+	*sqlite3.SQLiteDriver
+}
+
+// sqliteConn is implemented by the connections of the SQLite driver
+type sqliteConn interface {
+	driver.Conn
+	driver.ConnPrepareContext
+	driver.ConnBeginTx
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+}
+
+// sqliteStmt is implemented by the prepared statements of the SQLite driver
+type sqliteStmt interface {
+	driver.Stmt
+	driver.StmtExecContext
+	driver.StmtQueryContext
+}
+
+func (d monitoredDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &monitoredConn{conn.(sqliteConn)}, nil
+}
+
+type monitoredConn struct {
+	sqliteConn
+}
+
+func (c *monitoredConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *monitoredConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.sqliteConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &monitoredStmt{stmt.(sqliteStmt), queryOperation(query)}, nil
+}
+
+func (c *monitoredConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *monitoredConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	tx, err := c.sqliteConn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return monitoredTx{tx}, nil
+}
+
+func (c *monitoredConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	res, err := c.sqliteConn.ExecContext(ctx, query, args)
+	observeQuery(queryOperation(query), start, err)
+	return res, err
+}
+
+func (c *monitoredConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := c.sqliteConn.QueryContext(ctx, query, args)
+	if err != nil {
+		observeQuery(queryOperation(query), start, err)
+		return nil, err
+	}
+	return &monitoredRows{Rows: rows, operation: queryOperation(query), start: start}, nil
+}
+
+type monitoredStmt struct {
+	sqliteStmt
+	operation string
+}
+
+func (s *monitoredStmt) Exec(args []driver.Value) (driver.Result, error) {
+	start := time.Now()
+	res, err := s.sqliteStmt.Exec(args)
+	observeQuery(s.operation, start, err)
+	return res, err
+}
+
+func (s *monitoredStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	res, err := s.sqliteStmt.ExecContext(ctx, args)
+	observeQuery(s.operation, start, err)
+	return res, err
+}
+
+func (s *monitoredStmt) Query(args []driver.Value) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.sqliteStmt.Query(args)
+	if err != nil {
+		observeQuery(s.operation, start, err)
+		return nil, err
+	}
+	return &monitoredRows{Rows: rows, operation: s.operation, start: start}, nil
+}
+
+func (s *monitoredStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.sqliteStmt.QueryContext(ctx, args)
+	if err != nil {
+		observeQuery(s.operation, start, err)
+		return nil, err
+	}
+	return &monitoredRows{Rows: rows, operation: s.operation, start: start}, nil
+}
+
+// monitoredRows records the latency of a query once its rows are closed, since SQLite steps through
+// the query as the rows are read
+type monitoredRows struct {
+	driver.Rows
+	operation string
+	start     time.Time
+	err       error
+}
+
+func (r *monitoredRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return err
+}
+
+func (r *monitoredRows) Close() error {
+	err := r.Rows.Close()
+	observeQuery(r.operation, r.start, r.err)
+	return err
+}
+
+type monitoredTx struct {
+	driver.Tx
+}
+
+func (tx monitoredTx) Commit() error {
+	start := time.Now()
+	err := tx.Tx.Commit()
+	observeQuery("commit", start, err)
+	return err
+}
+
+// queryOperation returns the operation of the query used to label its latency
+func queryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return dbOperationOther
+	}
+	op := strings.ToLower(fields[0])
+	if !dbOperations[op] {
+		return dbOperationOther
+	}
+	return op
+}
+
+func observeQuery(operation string, start time.Time, err error) {
+	if !monitor.Enabled {
+		return
+	}
+	monitor.DBQuery(operation, time.Since(start), isBusyError(err))
+}
+
+// StartMetrics records the size of the database file and of its write-ahead log, and how often and for how long
+// queries waited for the database connection, every interval until ctx is done
+func (db *DB) StartMetrics(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var last sql.DBStats
+		for {
+			monitor.DBStorage(fileSize(db.path), fileSize(db.path+"-wal"))
+			stats := db.dbh.Stats()
+			monitor.DBConnectionWaits(stats.WaitCount-last.WaitCount, stats.WaitDuration-last.WaitDuration)
+			last = stats
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// fileSize returns the size of the file at path, or 0 if it doesn't exist
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			glog.Errorf("Error reading the size of file=%s err=%q", path, err)
+		}
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryOperation(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("select", queryOperation("SELECT value FROM kv WHERE key=?"))
+	assert.Equal("insert", queryOperation("\n\tINSERT OR REPLACE INTO kv(key, value) VALUES(?1, ?2)"))
+	assert.Equal("update", queryOperation("update kv SET value = ?"))
+	assert.Equal("delete", queryOperation("DELETE FROM kv"))
+	assert.Equal(dbOperationOther, queryOperation("PRAGMA journal_mode=WAL;"))
+	assert.Equal(dbOperationOther, queryOperation(" "))
+}
+
+func TestMonitoredDriver_BusyError(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "dbmetrics")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "lpdb.sqlite3")
+
+	dbh, err := InitDB(path)
+	require.Nil(err)
+	defer dbh.Close()
+	assert.Greater(fileSize(path), int64(0))
+	assert.Equal(int64(0), fileSize(filepath.Join(dir, "missing")))
+
+	// queries go through the monitored driver
+	require.Nil(dbh.updateKVStore("foo", "bar"))
+	value, err := dbh.selectKVStore("foo")
+	require.Nil(err)
+	assert.Equal("bar", value)
+
+	// a write fails while another process holds the write lock of the DB
+	other, err := sql.Open(sqliteDriver, fmt.Sprintf("file:%s?_busy_timeout=0", path))
+	require.Nil(err)
+	defer other.Close()
+	tx, err := other.Begin()
+	require.Nil(err)
+	defer tx.Rollback()
+	_, err = tx.Exec("INSERT INTO kv(key, value) VALUES('lock', 'held')")
+	require.Nil(err)
+
+	_, err = dbh.dbh.Exec("PRAGMA busy_timeout=0")
+	require.Nil(err)
+	err = dbh.updateKVStore("foo", "baz")
+	require.NotNil(err)
+	assert.True(isBusyError(err))
+	assert.False(isBusyError(errors.New("some error")))
+	assert.False(isBusyError(nil))
+}
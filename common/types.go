@@ -48,6 +48,7 @@ type Broadcaster interface {
 type CapabilityComparator interface {
 	CompatibleWith(*net.Capabilities) bool
 	LegacyOnly() bool
+	ToNetCapabilities() *net.Capabilities
 }
 
 const (
@@ -58,6 +59,8 @@ const (
 type OrchestratorLocalInfo struct {
 	URL   *url.URL `json:"Url"`
 	Score float32
+	// Address is the on-chain address registered for URL, nil if discovery doesn't know it, e.g. for pools of URIs
+	Address *ethcommon.Address `json:",omitempty"`
 }
 
 // combines B's local metadata about O with info received from this O
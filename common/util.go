@@ -290,6 +290,26 @@ func ProfilesNames(profiles []ffmpeg.VideoProfile) string {
 	return strings.Join(names, ",")
 }
 
+// ProfilesCodecs returns the distinct codecs the profiles are encoded with, sorted and comma-separated
+func ProfilesCodecs(profiles []ffmpeg.VideoProfile) string {
+	codecs := make(sort.StringSlice, 0, len(profiles))
+	seen := make(map[string]bool)
+	for _, p := range profiles {
+		name, ok := ffmpeg.VideoCodecName[p.Encoder]
+		if !ok {
+			name = "unknown"
+		}
+		// H.264 is H264 in the labels of the metrics
+		name = strings.ReplaceAll(name, ".", "")
+		if !seen[name] {
+			seen[name] = true
+			codecs = append(codecs, name)
+		}
+	}
+	codecs.Sort()
+	return strings.Join(codecs, ",")
+}
+
 func ProfileExtensionFormat(ext string) ffmpeg.Format {
 	p, ok := ffmpeg.ExtensionFormats[ext]
 	if !ok {
@@ -430,6 +450,9 @@ func JoinURL(url, path string) string {
 	return url + path
 }
 
+// ErrMaxBufferExceeded is returned by ReadAtMost when the input has more than the allowed number of bytes
+var ErrMaxBufferExceeded = errors.New("input bigger than max buffer size")
+
 // Read at most n bytes from an io.Reader
 func ReadAtMost(r io.Reader, n int) ([]byte, error) {
 	// Reading one extra byte to check if input Reader
@@ -437,7 +460,7 @@ func ReadAtMost(r io.Reader, n int) ([]byte, error) {
 	limitedReader := io.LimitReader(r, int64(n)+1)
 	b, err := ioutil.ReadAll(limitedReader)
 	if err == nil && len(b) > n {
-		return nil, errors.New("input bigger than max buffer size")
+		return nil, ErrMaxBufferExceeded
 	}
 	return b, err
 }
@@ -177,6 +177,20 @@ func TestProfilesToHex(t *testing.T) {
 	compare([]ffmpeg.VideoProfile{ffmpeg.P360p30fps16x9, ffmpeg.P240p30fps16x9})
 }
 
+func TestProfilesCodecs(t *testing.T) {
+	assert := assert.New(t)
+
+	hevc := ffmpeg.P360p30fps16x9
+	hevc.Encoder = ffmpeg.H265
+	vp9 := ffmpeg.P144p30fps16x9
+	vp9.Encoder = ffmpeg.VP9
+
+	assert.Equal("", ProfilesCodecs(nil))
+	assert.Equal("H264", ProfilesCodecs([]ffmpeg.VideoProfile{ffmpeg.P240p30fps16x9, ffmpeg.P720p30fps16x9}))
+	assert.Equal("H264,HEVC,VP9", ProfilesCodecs([]ffmpeg.VideoProfile{vp9, hevc, ffmpeg.P240p30fps16x9, hevc}))
+	assert.Equal("unknown", ProfilesCodecs([]ffmpeg.VideoProfile{{Encoder: ffmpeg.VideoCodec(100)}}))
+}
+
 func TestVideoProfile_FormatMimeType(t *testing.T) {
 	inp := []ffmpeg.Format{ffmpeg.FormatNone, ffmpeg.FormatMPEGTS, ffmpeg.FormatMP4}
 	exp := []string{"video/mp2t", "video/mp2t", "video/mp4"}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+var ErrBroadcasterNotAllowed = errors.New("BroadcasterNotAllowed")
+
+// BroadcasterAccessList restricts which broadcaster (sender) addresses an orchestrator accepts work from.
+// A blocked address is always refused. Once an address has been allowed, only addresses on the allowlist
+// are accepted, even if the allowlist later becomes empty, until the allowlist is cleared.
+type BroadcasterAccessList struct {
+	mu        sync.RWMutex
+	allowlist map[ethcommon.Address]bool
+	blocklist map[ethcommon.Address]bool
+	// Whether the allowlist is enforced, tracked separately so that removing the last
+	// allowed address does not open access to every broadcaster
+	allowlistEnforced bool
+}
+
+// NewBroadcasterAccessList creates an empty BroadcasterAccessList that accepts all broadcasters
+func NewBroadcasterAccessList() *BroadcasterAccessList {
+	return &BroadcasterAccessList{
+		allowlist: make(map[ethcommon.Address]bool),
+		blocklist: make(map[ethcommon.Address]bool),
+	}
+}
+
+// ParseBroadcasterAddresses parses a comma-separated list of ETH addresses
+func ParseBroadcasterAddresses(s string) ([]ethcommon.Address, error) {
+	var addrs []ethcommon.Address
+	for _, str := range strings.Split(s, ",") {
+		str = strings.TrimSpace(str)
+		if str == "" {
+			continue
+		}
+		if !ethcommon.IsHexAddress(str) {
+			return nil, fmt.Errorf("invalid broadcaster address %v", str)
+		}
+		addrs = append(addrs, ethcommon.HexToAddress(str))
+	}
+	return addrs, nil
+}
+
+// Allow adds an address to the allowlist
+func (l *BroadcasterAccessList) Allow(addr ethcommon.Address) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.allowlist[addr] = true
+	l.allowlistEnforced = true
+}
+
+// Disallow removes an address from the allowlist. The allowlist stays enforced if it becomes empty.
+func (l *BroadcasterAccessList) Disallow(addr ethcommon.Address) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.allowlist, addr)
+}
+
+// ClearAllowlist removes all addresses from the allowlist and stops enforcing it
+func (l *BroadcasterAccessList) ClearAllowlist() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.allowlist = make(map[ethcommon.Address]bool)
+	l.allowlistEnforced = false
+}
+
+// AllowlistEnforced returns whether only addresses on the allowlist are accepted
+func (l *BroadcasterAccessList) AllowlistEnforced() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.allowlistEnforced
+}
+
+// Block adds an address to the blocklist
+func (l *BroadcasterAccessList) Block(addr ethcommon.Address) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.blocklist[addr] = true
+}
+
+// Unblock removes an address from the blocklist
+func (l *BroadcasterAccessList) Unblock(addr ethcommon.Address) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.blocklist, addr)
+}
+
+// Allowed returns whether work should be accepted from an address
+func (l *BroadcasterAccessList) Allowed(addr ethcommon.Address) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.blocklist[addr] {
+		return false
+	}
+	if l.allowlistEnforced {
+		return l.allowlist[addr]
+	}
+	return true
+}
+
+// Allowlist returns the addresses on the allowlist
+func (l *BroadcasterAccessList) Allowlist() []ethcommon.Address {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return sortedAddresses(l.allowlist)
+}
+
+// Blocklist returns the addresses on the blocklist
+func (l *BroadcasterAccessList) Blocklist() []ethcommon.Address {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return sortedAddresses(l.blocklist)
+}
+
+func sortedAddresses(set map[ethcommon.Address]bool) []ethcommon.Address {
+	addrs := make([]ethcommon.Address, 0, len(set))
+	for addr := range set {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Hex() < addrs[j].Hex() })
+	return addrs
+}
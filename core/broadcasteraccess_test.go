@@ -0,0 +1,74 @@
+package core
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcasterAccessList(t *testing.T) {
+	assert := assert.New(t)
+
+	addr1 := ethcommon.Address{1}
+	addr2 := ethcommon.Address{2}
+	addr3 := ethcommon.Address{3}
+
+	l := NewBroadcasterAccessList()
+	assert.True(l.Allowed(addr1))
+	assert.True(l.Allowed(addr2))
+	assert.Empty(l.Allowlist())
+	assert.Empty(l.Blocklist())
+
+	// blocklist only
+	l.Block(addr1)
+	assert.False(l.Allowed(addr1))
+	assert.True(l.Allowed(addr2))
+	assert.Equal([]ethcommon.Address{addr1}, l.Blocklist())
+
+	// allowlist restricts everyone else
+	l.Allow(addr2)
+	assert.False(l.Allowed(addr1))
+	assert.True(l.Allowed(addr2))
+	assert.False(l.Allowed(addr3))
+
+	// blocklist takes precedence over allowlist
+	l.Allow(addr1)
+	assert.False(l.Allowed(addr1))
+	assert.Equal([]ethcommon.Address{addr1, addr2}, l.Allowlist())
+
+	l.Unblock(addr1)
+	assert.True(l.Allowed(addr1))
+	assert.Empty(l.Blocklist())
+
+	// removing the last allowed address keeps the allowlist enforced
+	l.Disallow(addr1)
+	l.Disallow(addr2)
+	assert.Empty(l.Allowlist())
+	assert.True(l.AllowlistEnforced())
+	assert.False(l.Allowed(addr1))
+	assert.False(l.Allowed(addr3))
+
+	// clearing the allowlist stops enforcing it
+	l.Allow(addr1)
+	l.ClearAllowlist()
+	assert.Empty(l.Allowlist())
+	assert.False(l.AllowlistEnforced())
+	assert.True(l.Allowed(addr3))
+}
+
+func TestParseBroadcasterAddresses(t *testing.T) {
+	assert := assert.New(t)
+
+	addrs, err := ParseBroadcasterAddresses("")
+	assert.Nil(err)
+	assert.Empty(addrs)
+
+	addrs, err = ParseBroadcasterAddresses("0x0100000000000000000000000000000000000000, 0x0200000000000000000000000000000000000000,")
+	require.Nil(t, err)
+	assert.Equal([]ethcommon.Address{{1}, {2}}, addrs)
+
+	_, err = ParseBroadcasterAddresses("0x0100000000000000000000000000000000000000,foo")
+	assert.EqualError(err, "invalid broadcaster address foo")
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"errors"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+var ErrBroadcasterSessionCap = errors.New("BroadcasterSessionsCapped")
+
+// reserveBroadcasterSession associates a session with the broadcaster paying for it and returns whether a new
+// session was reserved. It returns ErrBroadcasterSessionCap if the session is new and the broadcaster already
+// occupies MaxSessionsPerBroadcaster sessions, releasing the slot reserved for the session so that the broadcaster
+// can't take capacity from others with sessions it isn't allowed to start.
+// The association only lasts as long as the session holds a slot, see sessionHoldsSlotLocked.
+func (n *LivepeerNode) reserveBroadcasterSession(sender ethcommon.Address, mid ManifestID) (bool, error) {
+	if n.MaxSessionsPerBroadcaster <= 0 {
+		return false, nil
+	}
+
+	n.segmentMutex.Lock()
+	defer n.segmentMutex.Unlock()
+	if _, ok := n.sessionSenders[mid]; ok && n.sessionHoldsSlotLocked(mid) {
+		return false, nil
+	}
+	if n.broadcasterSessionsLocked(sender) >= n.MaxSessionsPerBroadcaster {
+		if _, ok := n.reservedSessions[mid]; ok {
+			n.releaseSessionLocked(mid)
+		}
+		return false, ErrBroadcasterSessionCap
+	}
+	n.sessionSenders[mid] = sender
+	return true, nil
+}
+
+// releaseBroadcasterSession removes the association between a session and its broadcaster
+func (n *LivepeerNode) releaseBroadcasterSession(mid ManifestID) {
+	n.segmentMutex.Lock()
+	defer n.segmentMutex.Unlock()
+	delete(n.sessionSenders, mid)
+}
+
+// BroadcasterSessions returns the number of sessions currently associated with a broadcaster
+func (n *LivepeerNode) BroadcasterSessions(sender ethcommon.Address) int {
+	n.segmentMutex.Lock()
+	defer n.segmentMutex.Unlock()
+	return n.broadcasterSessionsLocked(sender)
+}
+
+// broadcasterSessionsLocked counts the sessions of a broadcaster, dropping associations of sessions that
+// no longer hold a slot, e.g. because their reservation expired before they started transcoding.
+// Requires segmentMutex.
+func (n *LivepeerNode) broadcasterSessionsLocked(sender ethcommon.Address) int {
+	n.expireSessionReservationsLocked()
+	count := 0
+	for mid, addr := range n.sessionSenders {
+		if !n.sessionHoldsSlotLocked(mid) {
+			delete(n.sessionSenders, mid)
+			continue
+		}
+		if addr == sender {
+			count++
+		}
+	}
+	return count
+}
+
+// sessionHoldsSlotLocked returns whether a session is transcoding or has a reserved slot. Requires segmentMutex.
+func (n *LivepeerNode) sessionHoldsSlotLocked(mid ManifestID) bool {
+	if _, ok := n.SegmentChans[mid]; ok {
+		return true
+	}
+	_, ok := n.reservedSessions[mid]
+	return ok
+}
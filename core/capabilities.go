@@ -3,6 +3,7 @@ package core
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"sync"
 
@@ -473,6 +474,31 @@ func CapabilityToName(capability Capability) (string, error) {
 	return capName, nil
 }
 
+// CapabilityFromName returns the capability with the given name, ignoring case
+func CapabilityFromName(name string) (Capability, error) {
+	for capability, capName := range CapabilityNameLookup {
+		if strings.EqualFold(capName, strings.TrimSpace(name)) {
+			return capability, nil
+		}
+	}
+	return Capability_Invalid, capUnknown
+}
+
+// HasCapability returns whether the capability is part of the set
+func (c *Capabilities) HasCapability(capability Capability) bool {
+	if c == nil || capability < 0 {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	arrIdx := int(capability) / 64
+	bitIdx := int(capability) % 64
+	if arrIdx >= len(c.bitstring) {
+		return false
+	}
+	return c.bitstring[arrIdx]&uint64(1<<bitIdx) > 0
+}
+
 func InArray(capability Capability, caps []Capability) bool {
 	for _, c := range caps {
 		if capability == c {
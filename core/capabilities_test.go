@@ -480,3 +480,33 @@ func TestCapabilities_LegacyCheck(t *testing.T) {
 
 	assert.Len(legacyCapabilities, legacyLen) // sanity check no modifications
 }
+
+func TestCapabilities_HasCapability(t *testing.T) {
+	assert := assert.New(t)
+
+	var nilCaps *Capabilities
+	assert.False(nilCaps.HasCapability(Capability_H264))
+
+	caps := NewCapabilities([]Capability{Capability_H264, Capability_HEVC_Encode}, nil)
+	assert.True(caps.HasCapability(Capability_H264))
+	assert.True(caps.HasCapability(Capability_HEVC_Encode))
+	assert.False(caps.HasCapability(Capability_HEVC_Decode))
+	assert.False(caps.HasCapability(Capability_Invalid))
+	assert.False(caps.HasCapability(Capability(200)))
+}
+
+func TestCapability_FromName(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := CapabilityFromName("HEVC encode")
+	assert.Nil(err)
+	assert.Equal(Capability_HEVC_Encode, c)
+
+	c, err = CapabilityFromName(" hevc ENCODE ")
+	assert.Nil(err)
+	assert.Equal(Capability_HEVC_Encode, c)
+
+	c, err = CapabilityFromName("foo")
+	assert.Equal(capUnknown, err)
+	assert.Equal(Capability_Invalid, c)
+}
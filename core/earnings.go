@@ -0,0 +1,65 @@
+package core
+
+import (
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+)
+
+// earningsFlushInterval is how often the earnings accumulated in memory are written to the DB
+var earningsFlushInterval = 10 * time.Second
+
+type earningsKey struct {
+	sender     ethcommon.Address
+	manifestID string
+	round      int64
+}
+
+// EarningsRecorder accumulates per stream earnings in memory and periodically
+// writes them to the DB so that recording them stays off the payment and transcode path
+type EarningsRecorder struct {
+	writes *common.BatchWriter
+}
+
+// NewEarningsRecorder creates an EarningsRecorder that writes to db
+func NewEarningsRecorder(db *common.DB) *EarningsRecorder {
+	merge := func(total, delta interface{}) interface{} {
+		earnings := delta.(*common.DBEarnings)
+		if total == nil {
+			total = &common.DBEarnings{Sender: earnings.Sender, ManifestID: earnings.ManifestID, Round: earnings.Round}
+		}
+		total.(*common.DBEarnings).Add(earnings)
+		return total
+	}
+	write := func(record interface{}) error {
+		earnings := record.(*common.DBEarnings)
+		if err := db.AddEarnings(earnings); err != nil {
+			glog.Errorf("Error recording earnings sender=%v manifestID=%v round=%v err=%q", earnings.Sender.Hex(), earnings.ManifestID, earnings.Round, err)
+			return err
+		}
+		return nil
+	}
+	return &EarningsRecorder{writes: common.NewBatchWriter(merge, write)}
+}
+
+// Add adds earnings to the totals pending to be written to the DB
+func (r *EarningsRecorder) Add(earnings *common.DBEarnings) {
+	r.writes.Add(earningsKey{sender: earnings.Sender, manifestID: earnings.ManifestID, round: earnings.Round}, earnings)
+}
+
+// Flush writes all pending earnings to the DB. Earnings that fail to be written are kept pending.
+func (r *EarningsRecorder) Flush() error {
+	return r.writes.Flush()
+}
+
+// StartFlush periodically writes pending earnings to the DB until StopFlush is called
+func (r *EarningsRecorder) StartFlush() {
+	r.writes.Start(earningsFlushInterval)
+}
+
+// StopFlush stops the flush loop and writes any pending earnings to the DB
+func (r *EarningsRecorder) StopFlush() {
+	r.writes.Stop()
+}
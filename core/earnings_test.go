@@ -0,0 +1,75 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEarningsRecorder(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	sender := ethcommon.Address{1}
+	r := NewEarningsRecorder(dbh)
+	go r.StartFlush()
+
+	r.Add(&common.DBEarnings{Sender: sender, ManifestID: "foo", Round: 1, Tickets: 1, EV: big.NewRat(1, 3)})
+	r.Add(&common.DBEarnings{Sender: sender, ManifestID: "foo", Round: 1, Tickets: 1, EV: big.NewRat(2, 3)})
+	r.Add(&common.DBEarnings{Sender: sender, ManifestID: "foo", Round: 1, Pixels: 100, Fees: big.NewRat(50, 1)})
+	r.Add(&common.DBEarnings{Sender: sender, ManifestID: "foo", Round: 2, Pixels: 10, Fees: big.NewRat(5, 1)})
+
+	// pending earnings are merged per stream and round in memory
+	assert.Equal(2, r.writes.Pending())
+	earnings, err := dbh.SelectEarnings(nil)
+	require.Nil(err)
+	assert.Empty(earnings)
+
+	// stopping writes the pending earnings
+	r.StopFlush()
+	assert.Zero(r.writes.Pending())
+	earnings, err = dbh.SelectEarnings(nil)
+	require.Nil(err)
+	require.Len(earnings, 2)
+	assert.Equal(int64(2), earnings[0].Tickets)
+	assert.Equal("1", earnings[0].EV.RatString())
+	assert.Equal(int64(100), earnings[0].Pixels)
+	assert.Equal("50", earnings[0].Fees.RatString())
+	assert.Equal(int64(2), earnings[1].Round)
+	assert.Equal(int64(10), earnings[1].Pixels)
+
+	// flushing adds to the stored totals
+	r.Add(&common.DBEarnings{Sender: sender, ManifestID: "foo", Round: 2, Pixels: 10, Fees: big.NewRat(5, 1)})
+	require.Nil(r.Flush())
+	earnings, err = dbh.SelectEarnings(&common.DBEarningsFilter{Round: big.NewInt(2)})
+	require.Nil(err)
+	require.Len(earnings, 1)
+	assert.Equal(int64(20), earnings[0].Pixels)
+	assert.Equal("10", earnings[0].Fees.RatString())
+
+	// earnings that fail to be written are kept and merged with earnings added since
+	_, err = dbraw.Exec("ALTER TABLE earnings RENAME TO earnings_tmp")
+	require.Nil(err)
+	r.Add(&common.DBEarnings{Sender: sender, ManifestID: "foo", Round: 2, Pixels: 10, Fees: big.NewRat(5, 1)})
+	assert.NotNil(r.Flush())
+	assert.Equal(1, r.writes.Pending())
+	r.Add(&common.DBEarnings{Sender: sender, ManifestID: "foo", Round: 2, Pixels: 5, Fees: big.NewRat(1, 1)})
+	_, err = dbraw.Exec("ALTER TABLE earnings_tmp RENAME TO earnings")
+	require.Nil(err)
+	require.Nil(r.Flush())
+	assert.Zero(r.writes.Pending())
+	earnings, err = dbh.SelectEarnings(&common.DBEarningsFilter{Round: big.NewInt(2)})
+	require.Nil(err)
+	require.Len(earnings, 1)
+	assert.Equal(int64(35), earnings[0].Pixels)
+	assert.Equal("16", earnings[0].Fees.RatString())
+}
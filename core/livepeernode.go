@@ -22,6 +22,8 @@ import (
 
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/eth"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
 )
 
 var ErrTranscoderAvail = errors.New("ErrTranscoderUnavailable")
@@ -80,6 +82,21 @@ type LivepeerNode struct {
 	Balances          *AddressBalances
 	Capabilities      *Capabilities
 	AutoAdjustPrice   bool
+	// UtilizationPricing, if set, scales the base price by session utilization
+	UtilizationPricing *UtilizationPricing
+	BroadcasterAccess  *BroadcasterAccessList
+	// SessionQueue, if set, holds new sessions briefly while at MaxSessions instead of rejecting them
+	SessionQueue *SessionQueue
+	// Earnings, if set, records per stream earnings to the DB
+	Earnings *EarningsRecorder
+	// MaxSessionsPerBroadcaster limits the number of concurrent sessions a single sender can occupy, 0 means no limit
+	MaxSessionsPerBroadcaster int
+	// Region is the operator configured region label of the orchestrator
+	Region string
+	// Datacenter is the operator configured datacenter or hosting provider label of the orchestrator
+	Datacenter string
+	// Redemptions, if set, reports the winning ticket redemptions of a node that redeems tickets itself
+	Redemptions pm.RedemptionReporter
 	// Broadcaster public fields
 	Sender pm.Sender
 
@@ -90,23 +107,34 @@ type LivepeerNode struct {
 	// Transcoder private fields
 	priceInfo    map[string]*big.Rat
 	serviceURI   url.URL
+	draining     bool
 	segmentMutex *sync.RWMutex
+	// capabilityPrices holds the base prices for jobs requiring a capability, keyed by broadcaster address
+	capabilityPrices map[string]map[Capability]*big.Rat
+	// sessionSenders maps sessions to the broadcaster paying for them, protected by segmentMutex
+	sessionSenders map[ManifestID]ethcommon.Address
+	// reservedSessions holds the time slots were reserved for sessions that have not started transcoding yet,
+	// protected by segmentMutex
+	reservedSessions map[ManifestID]time.Time
 }
 
 // NewLivepeerNode creates a new Livepeer Node. Eth can be nil.
 func NewLivepeerNode(e eth.LivepeerEthClient, wd string, dbh *common.DB) (*LivepeerNode, error) {
 	rand.Seed(time.Now().UnixNano())
 	return &LivepeerNode{
-		Eth:             e,
-		WorkDir:         wd,
-		Database:        dbh,
-		AutoAdjustPrice: true,
-		SegmentChans:    make(map[ManifestID]SegmentChan),
-		segmentMutex:    &sync.RWMutex{},
-		Capabilities:    &Capabilities{capacities: map[Capability]int{}},
-		priceInfo:       make(map[string]*big.Rat),
-		StorageConfigs:  make(map[string]*transcodeConfig),
-		storageMutex:    &sync.RWMutex{},
+		Eth:               e,
+		WorkDir:           wd,
+		Database:          dbh,
+		AutoAdjustPrice:   true,
+		SegmentChans:      make(map[ManifestID]SegmentChan),
+		segmentMutex:      &sync.RWMutex{},
+		sessionSenders:    make(map[ManifestID]ethcommon.Address),
+		reservedSessions:  make(map[ManifestID]time.Time),
+		Capabilities:      &Capabilities{capacities: map[Capability]int{}},
+		priceInfo:         make(map[string]*big.Rat),
+		BroadcasterAccess: NewBroadcasterAccessList(),
+		StorageConfigs:    make(map[string]*transcodeConfig),
+		storageMutex:      &sync.RWMutex{},
 	}, nil
 }
 
@@ -122,6 +150,20 @@ func (n *LivepeerNode) SetServiceURI(newUrl *url.URL) {
 	n.serviceURI = *newUrl
 }
 
+// SetDraining toggles drain mode. While draining the orchestrator finishes existing sessions but does not accept new ones
+func (n *LivepeerNode) SetDraining(draining bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.draining = draining
+}
+
+// Draining returns whether the node is in drain mode
+func (n *LivepeerNode) Draining() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.draining
+}
+
 // SetBasePrice sets the base price for an orchestrator on the node
 func (n *LivepeerNode) SetBasePrice(b_eth_addr string, price *big.Rat) {
 	addr := strings.ToLower(b_eth_addr)
@@ -140,11 +182,63 @@ func (n *LivepeerNode) GetBasePrice(b_eth_addr string) *big.Rat {
 	return n.priceInfo[addr]
 }
 
+// GetBasePrices returns a copy of the base prices keyed by broadcaster address,
+// including the "default" price
 func (n *LivepeerNode) GetBasePrices() map[string]*big.Rat {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 
-	return n.priceInfo
+	prices := make(map[string]*big.Rat, len(n.priceInfo))
+	for addr, price := range n.priceInfo {
+		prices[addr] = price
+	}
+	return prices
+}
+
+// SetCapabilityBasePrice sets the base price for the jobs of a broadcaster, or "default", requiring the capability.
+// A nil price removes the capability specific base price.
+func (n *LivepeerNode) SetCapabilityBasePrice(b_eth_addr string, capability Capability, price *big.Rat) {
+	addr := strings.ToLower(b_eth_addr)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if price == nil {
+		delete(n.capabilityPrices[addr], capability)
+		if len(n.capabilityPrices[addr]) == 0 {
+			delete(n.capabilityPrices, addr)
+		}
+		return
+	}
+	if n.capabilityPrices == nil {
+		n.capabilityPrices = make(map[string]map[Capability]*big.Rat)
+	}
+	if n.capabilityPrices[addr] == nil {
+		n.capabilityPrices[addr] = make(map[Capability]*big.Rat)
+	}
+	n.capabilityPrices[addr][capability] = price
+}
+
+// GetCapabilityBasePrices returns a copy of the capability specific base prices keyed by broadcaster address,
+// including the "default" prices
+func (n *LivepeerNode) GetCapabilityBasePrices() map[string]map[Capability]*big.Rat {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	prices := make(map[string]map[Capability]*big.Rat, len(n.capabilityPrices))
+	for addr, capPrices := range n.capabilityPrices {
+		prices[addr] = make(map[Capability]*big.Rat, len(capPrices))
+		for capability, price := range capPrices {
+			prices[addr][capability] = price
+		}
+	}
+	return prices
+}
+
+// ActiveSessions returns the number of sessions currently in use, including sessions admitted but not transcoding yet
+func (n *LivepeerNode) ActiveSessions() int {
+	n.segmentMutex.RLock()
+	defer n.segmentMutex.RUnlock()
+	return len(n.SegmentChans) + len(n.reservedSessions)
 }
 
 // SetMaxFaceValue sets the faceValue upper limit for tickets received
@@ -665,9 +665,11 @@ func TestOrchCheckCapacity(t *testing.T) {
 	// happy case
 	assert.Nil(o.CheckCapacity(mid))
 
-	// capped case
+	// capped case, mid already holds a reserved slot
 	MaxSessions = 0
-	assert.Equal(ErrOrchCap, o.CheckCapacity(mid))
+	assert.Nil(o.CheckCapacity(mid))
+	assert.Equal(ErrOrchCap, o.CheckCapacity(ManifestID("other")))
+	assert.Equal(ErrOrchCap, o.CheckCapacity(""))
 
 	// ensure existing segment chans pass while cap is active
 	MaxSessions = cap
@@ -675,6 +677,181 @@ func TestOrchCheckCapacity(t *testing.T) {
 	assert.Nil(err)
 	MaxSessions = 0
 	assert.Nil(o.CheckCapacity(mid))
+	MaxSessions = cap
+}
+
+func TestOrchCheckCapacity_Draining(t *testing.T) {
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	n, _ := NewLivepeerNode(nil, "", nil)
+	n.SessionQueue = NewSessionQueue(time.Second, 1)
+	n.Capabilities = NewCapabilities([]Capability{Capability_H264}, nil)
+	o := NewOrchestrator(n, nil)
+	md := StubSegTranscodingMetadata()
+	mid := ManifestID(md.AuthToken.SessionId)
+	assert := assert.New(t)
+
+	_, err := n.getSegmentChan(context.TODO(), md)
+	assert.Nil(err)
+
+	n.SetDraining(true)
+
+	// existing sessions continue
+	assert.Nil(o.CheckCapacity(mid))
+	_, err = n.getSegmentChan(context.TODO(), md)
+	assert.Nil(err)
+
+	// new sessions are rejected without being queued
+	start := time.Now()
+	assert.Equal(ErrOrchCap, o.CheckCapacity(ManifestID("other")))
+	assert.True(time.Since(start) < time.Second)
+	_, err = n.getSegmentChan(context.TODO(), &SegTranscodingMetadata{AuthToken: &net.AuthToken{SessionId: "other"}})
+	assert.Equal(ErrOrchCap, err)
+
+	// discovery requests are refused
+	assert.Equal(ErrOrchCap, o.CheckCapacity(""))
+
+	// no capacity is advertised
+	assert.Equal(uint32(0), o.Capabilities().Capacities[uint32(Capability_H264)])
+
+	n.SetDraining(false)
+	assert.Nil(o.CheckCapacity(ManifestID("other")))
+	assert.Equal(uint32(1), o.Capabilities().Capacities[uint32(Capability_H264)])
+}
+
+func TestOrchCheckCapacity_SessionQueue(t *testing.T) {
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	n, _ := NewLivepeerNode(nil, "", nil)
+	n.SessionQueue = NewSessionQueue(time.Second, 1)
+	o := NewOrchestrator(n, nil)
+	md := StubSegTranscodingMetadata()
+	assert := assert.New(t)
+
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 1
+
+	_, err := n.getSegmentChan(context.TODO(), md)
+	assert.Nil(err)
+
+	// a new session is held until the existing session ends
+	errCh := make(chan error)
+	go func() { errCh <- o.CheckCapacity(ManifestID("other")) }()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(1, n.SessionQueue.Waiting())
+
+	// the queue is full
+	assert.Equal(ErrOrchCap, o.CheckCapacity(ManifestID("another")))
+
+	n.endTranscodingSession(md.AuthToken.SessionId, context.TODO())
+	select {
+	case err := <-errCh:
+		assert.Nil(err)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("queued session was not admitted")
+	}
+
+	// the admitted session holds the freed slot without queueing again
+	assert.Equal(1, n.ActiveSessions())
+	assert.Equal(ErrOrchCap, o.CheckCapacity(""))
+	_, err = n.getSegmentChan(context.TODO(), &SegTranscodingMetadata{AuthToken: &net.AuthToken{SessionId: "other"}})
+	assert.Nil(err)
+	assert.Equal(0, n.SessionQueue.Waiting())
+
+	// a new session is rejected once the queue times out
+	n.SessionQueue = NewSessionQueue(10*time.Millisecond, 1)
+	assert.Equal(ErrOrchCap, o.CheckCapacity(ManifestID("another")))
+	assert.Equal(0, n.SessionQueue.Waiting())
+}
+
+func TestOrchCheckCapacity_SessionQueue_SingleAdmission(t *testing.T) {
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	n, _ := NewLivepeerNode(nil, "", nil)
+	n.SessionQueue = NewSessionQueue(200*time.Millisecond, 3)
+	o := NewOrchestrator(n, nil)
+	md := StubSegTranscodingMetadata()
+	assert := assert.New(t)
+
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 1
+
+	_, err := n.getSegmentChan(context.TODO(), md)
+	assert.Nil(err)
+
+	errCh := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) { errCh <- o.CheckCapacity(ManifestID(fmt.Sprintf("new%d", i))) }(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(3, n.SessionQueue.Waiting())
+
+	// a single freed session admits a single queued session
+	n.endTranscodingSession(md.AuthToken.SessionId, context.TODO())
+	admitted, refused := 0, 0
+	for i := 0; i < 3; i++ {
+		if err := <-errCh; err == nil {
+			admitted++
+		} else {
+			assert.Equal(ErrOrchCap, err)
+			refused++
+		}
+	}
+	assert.Equal(1, admitted)
+	assert.Equal(2, refused)
+	assert.Equal(1, n.ActiveSessions())
+}
+
+func TestOrchCheckCapacity_ReservationExpires(t *testing.T) {
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	n, _ := NewLivepeerNode(nil, "", nil)
+	o := NewOrchestrator(n, nil)
+	assert := assert.New(t)
+
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 1
+	defer func(timeout time.Duration) { sessionReservationTimeout = timeout }(sessionReservationTimeout)
+	sessionReservationTimeout = 10 * time.Millisecond
+
+	// a session that never starts transcoding only holds its slot temporarily
+	assert.Nil(o.CheckCapacity(ManifestID("foo")))
+	assert.Equal(ErrOrchCap, o.CheckCapacity(ManifestID("bar")))
+	time.Sleep(20 * time.Millisecond)
+	assert.Nil(o.CheckCapacity(ManifestID("bar")))
+	assert.Equal(1, n.ActiveSessions())
+}
+
+func TestOrchReleaseCapacity(t *testing.T) {
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	n, _ := NewLivepeerNode(nil, "", nil)
+	o := NewOrchestrator(n, nil)
+	assert := assert.New(t)
+
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 1
+
+	// a rejected session hands its slot to the next session right away
+	assert.Nil(o.CheckCapacity(ManifestID("foo")))
+	assert.Equal(ErrOrchCap, o.CheckCapacity(ManifestID("bar")))
+	o.ReleaseCapacity(ManifestID("foo"))
+	assert.Equal(0, n.ActiveSessions())
+	assert.Nil(o.CheckCapacity(ManifestID("bar")))
+
+	// releasing a session without a reservation doesn't free the slots of others
+	o.ReleaseCapacity(ManifestID("foo"))
+	assert.Equal(1, n.ActiveSessions())
+
+	// transcoding sessions keep their slot
+	md := StubSegTranscodingMetadata()
+	mid := ManifestID(md.AuthToken.SessionId)
+	o.ReleaseCapacity(ManifestID("bar"))
+	assert.Nil(o.CheckCapacity(mid))
+	_, err := n.getSegmentChan(context.TODO(), md)
+	assert.Nil(err)
+	o.ReleaseCapacity(mid)
+	assert.Equal(1, n.ActiveSessions())
+	assert.Equal(ErrOrchCap, o.CheckCapacity(ManifestID("baz")))
 }
 
 func TestProcessPayment_GivenRecipientError_ReturnsNil(t *testing.T) {
@@ -722,6 +899,176 @@ func TestProcessPayment_GivenNoSender_ReturnsError(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestProcessPayment_GivenBlockedSender_ReturnsError(t *testing.T) {
+	n, _ := NewLivepeerNode(nil, "", nil)
+	recipient := new(pm.MockRecipient)
+	n.Recipient = recipient
+	orch := NewOrchestrator(n, nil)
+
+	protoPayment := defaultPayment(t)
+	sender := ethcommon.BytesToAddress(protoPayment.Sender)
+	n.BroadcasterAccess.Block(sender)
+
+	err := orch.ProcessPayment(context.Background(), protoPayment, ManifestID("some manifest"))
+	assert.Equal(t, ErrBroadcasterNotAllowed, err)
+	recipient.AssertNotCalled(t, "ReceiveTicket", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessPayment_MaxSessionsPerBroadcaster(t *testing.T) {
+	assert := assert.New(t)
+	addr := defaultRecipient
+	dbh, dbraw := tempDBWithOrch(t, &common.DBOrch{
+		EthereumAddr:      addr.Hex(),
+		ActivationRound:   1,
+		DeactivationRound: 999,
+	})
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	n, _ := NewLivepeerNode(nil, "", dbh)
+	n.Balances = NewAddressBalances(5 * time.Second)
+	n.MaxSessionsPerBroadcaster = 1
+	recipient := new(pm.MockRecipient)
+	n.Recipient = recipient
+	orch := NewOrchestrator(n, &stubRoundsManager{round: big.NewInt(10)})
+	orch.address = addr
+	recipient.On("ReceiveTicket", mock.Anything, mock.Anything, mock.Anything).Return("some sessionID", false, nil)
+
+	// capacity is checked before payments are processed, as in ServeSegment
+	processPayment := func(pay net.Payment, mid ManifestID) error {
+		require.Nil(t, orch.CheckCapacity(mid))
+		return orch.ProcessPayment(context.Background(), pay, mid)
+	}
+
+	pay := defaultPayment(t)
+	sender := ethcommon.BytesToAddress(pay.Sender)
+
+	assert.Nil(processPayment(pay, ManifestID("foo")))
+	assert.Equal(1, n.BroadcasterSessions(sender))
+
+	// further payments for an existing session are accepted
+	assert.Nil(processPayment(pay, ManifestID("foo")))
+
+	// a new session from the same sender is refused
+	assert.Equal(ErrBroadcasterSessionCap, processPayment(pay, ManifestID("bar")))
+
+	// the limit is per sender
+	otherPay := defaultPayment(t)
+	otherPay.Sender = pm.RandAddress().Bytes()
+	assert.Nil(processPayment(otherPay, ManifestID("baz")))
+
+	// the slot is freed once the session ends
+	n.endTranscodingSession("foo", context.Background())
+	assert.Equal(0, n.BroadcasterSessions(sender))
+
+	// a failed payment does not hold a slot
+	pay.ExpectedPrice = nil
+	assert.Error(processPayment(pay, ManifestID("bar")))
+	assert.Equal(0, n.BroadcasterSessions(sender))
+}
+
+func TestProcessPayment_MaxSessionsPerBroadcaster_ReleasesCapacity(t *testing.T) {
+	assert := assert.New(t)
+	addr := defaultRecipient
+	dbh, dbraw := tempDBWithOrch(t, &common.DBOrch{
+		EthereumAddr:      addr.Hex(),
+		ActivationRound:   1,
+		DeactivationRound: 999,
+	})
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	n, _ := NewLivepeerNode(nil, "", dbh)
+	n.Balances = NewAddressBalances(5 * time.Second)
+	n.MaxSessionsPerBroadcaster = 1
+	recipient := new(pm.MockRecipient)
+	n.Recipient = recipient
+	orch := NewOrchestrator(n, &stubRoundsManager{round: big.NewInt(10)})
+	orch.address = addr
+	recipient.On("ReceiveTicket", mock.Anything, mock.Anything, mock.Anything).Return("some sessionID", false, nil)
+
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 3
+
+	pay := defaultPayment(t)
+	assert.Nil(orch.CheckCapacity(ManifestID("foo")))
+	assert.Nil(orch.ProcessPayment(context.Background(), pay, ManifestID("foo")))
+	assert.Equal(1, n.ActiveSessions())
+
+	// sessions refused by the cap don't keep the slots reserved for them
+	for i := 0; i < 5; i++ {
+		mid := ManifestID(fmt.Sprintf("capped%d", i))
+		assert.Nil(orch.CheckCapacity(mid))
+		assert.Equal(ErrBroadcasterSessionCap, orch.ProcessPayment(context.Background(), pay, mid))
+		assert.Equal(1, n.ActiveSessions())
+	}
+
+	// so the capacity left is available to other broadcasters
+	for _, mid := range []ManifestID{"bar", "baz"} {
+		otherPay := defaultPayment(t)
+		otherPay.Sender = pm.RandAddress().Bytes()
+		assert.Nil(orch.CheckCapacity(mid))
+		assert.Nil(orch.ProcessPayment(context.Background(), otherPay, mid))
+	}
+	assert.Equal(3, n.ActiveSessions())
+	assert.Equal(ErrOrchCap, orch.CheckCapacity(ManifestID("other")))
+}
+
+func TestProcessPayment_MaxSessionsPerBroadcaster_SessionNotStarted(t *testing.T) {
+	assert := assert.New(t)
+	addr := defaultRecipient
+	dbh, dbraw := tempDBWithOrch(t, &common.DBOrch{
+		EthereumAddr:      addr.Hex(),
+		ActivationRound:   1,
+		DeactivationRound: 999,
+	})
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	n, _ := NewLivepeerNode(nil, "", dbh)
+	n.Balances = NewAddressBalances(5 * time.Second)
+	n.MaxSessionsPerBroadcaster = 1
+	recipient := new(pm.MockRecipient)
+	n.Recipient = recipient
+	orch := NewOrchestrator(n, &stubRoundsManager{round: big.NewInt(10)})
+	orch.address = addr
+	recipient.On("ReceiveTicket", mock.Anything, mock.Anything, mock.Anything).Return("some sessionID", false, nil)
+
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	defer func(timeout time.Duration) { sessionReservationTimeout = timeout }(sessionReservationTimeout)
+
+	pay := defaultPayment(t)
+	sender := ethcommon.BytesToAddress(pay.Sender)
+
+	// payment succeeds but the reserved slot is lost and getSegmentChan fails at capacity
+	md := StubSegTranscodingMetadata()
+	mid := ManifestID(md.AuthToken.SessionId)
+	assert.Nil(orch.CheckCapacity(mid))
+	assert.Nil(orch.ProcessPayment(context.Background(), pay, mid))
+	assert.Equal(1, n.BroadcasterSessions(sender))
+	n.segmentMutex.Lock()
+	delete(n.reservedSessions, mid)
+	n.segmentMutex.Unlock()
+	MaxSessions = 0
+	_, err := n.getSegmentChan(context.TODO(), md)
+	assert.Equal(ErrOrchCap, err)
+	assert.Equal(0, n.BroadcasterSessions(sender))
+
+	// payment succeeds but the segment is never transcoded, the slot is freed once the reservation expires
+	MaxSessions = cap
+	sessionReservationTimeout = 10 * time.Millisecond
+	assert.Nil(orch.CheckCapacity(ManifestID("bar")))
+	assert.Nil(orch.ProcessPayment(context.Background(), pay, ManifestID("bar")))
+	assert.Equal(ErrBroadcasterSessionCap, orch.ProcessPayment(context.Background(), pay, ManifestID("baz")))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(0, n.BroadcasterSessions(sender))
+	assert.Nil(orch.CheckCapacity(ManifestID("baz")))
+	assert.Nil(orch.ProcessPayment(context.Background(), pay, ManifestID("baz")))
+}
+
 func TestProcessPayment_GivenNoTicketParams_ReturnsNil(t *testing.T) {
 	n, _ := NewLivepeerNode(nil, "", nil)
 	recipient := new(pm.MockRecipient)
@@ -891,13 +1238,15 @@ func TestProcessPayment_GivenWinningTicket_RedeemError(t *testing.T) {
 
 	errorLogsBefore := glog.Stats.Error.Lines()
 
-	err := orch.ProcessPayment(context.Background(), defaultPayment(t), manifestID)
+	payment := defaultPayment(t)
+	err := orch.ProcessPayment(context.Background(), payment, manifestID)
 
-	time.Sleep(time.Millisecond * 20)
+	// the payment is not acknowledged or credited if the winning ticket could not be queued
 	errorLogsAfter := glog.Stats.Error.Lines()
 	assert := assert.New(t)
-	assert.Nil(err)
+	assert.EqualError(err, `failed to queue winning ticket err="RedeemWinningTicket error"`)
 	assert.Equal(int64(1), errorLogsAfter-errorLogsBefore)
+	assert.Nil(orch.node.Balances.Balance(ethcommon.BytesToAddress(payment.Sender), manifestID))
 	recipient.AssertCalled(t, "RedeemWinningTicket", mock.Anything, mock.Anything, mock.Anything)
 }
 
@@ -941,6 +1290,73 @@ func TestProcessPayment_GivenWinningTicket_Redeems(t *testing.T) {
 	recipient.AssertCalled(t, "RedeemWinningTicket", mock.Anything, mock.Anything, mock.Anything)
 }
 
+func TestProcessPayment_RecordsEarnings(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	addr := defaultRecipient
+	dbh, dbraw := tempDBWithOrch(t, &common.DBOrch{
+		EthereumAddr:      addr.Hex(),
+		ActivationRound:   1,
+		DeactivationRound: 999,
+	})
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	n, _ := NewLivepeerNode(nil, "", dbh)
+	n.Balances = NewAddressBalances(5 * time.Second)
+	recipient := new(pm.MockRecipient)
+	n.Recipient = recipient
+	orch := NewOrchestrator(n, &stubRoundsManager{round: big.NewInt(10)})
+	orch.address = addr
+
+	recipient.On("ReceiveTicket", mock.Anything, mock.Anything, mock.Anything).Return("some sessionID", true, nil).Once()
+	recipient.On("ReceiveTicket", mock.Anything, mock.Anything, mock.Anything).Return("some sessionID", false, nil)
+	recipient.On("RedeemWinningTicket", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	payment := defaultPaymentWithTickets(t, []*net.TicketSenderParams{
+		{SenderNonce: 1, Sig: pm.RandBytes(123)},
+		{SenderNonce: 2, Sig: pm.RandBytes(123)},
+	})
+	// EV = faceValue * winProb / (2^256 - 1) = 1000 / 3 per ticket, which is recorded without truncation
+	maxWinProb := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	payment.TicketParams.FaceValue = big.NewInt(1000).Bytes()
+	payment.TicketParams.WinProb = new(big.Int).Div(maxWinProb, big.NewInt(3)).Bytes()
+	sender := ethcommon.BytesToAddress(payment.Sender)
+
+	n.Earnings = NewEarningsRecorder(dbh)
+
+	require.Nil(orch.ProcessPayment(context.Background(), *payment, ManifestID("foo")))
+	require.Nil(orch.ProcessPayment(context.Background(), *payment, ManifestID("foo")))
+
+	// earnings are only written to the DB when flushed
+	earnings, err := dbh.SelectEarnings(&common.DBEarningsFilter{Sender: &sender})
+	require.Nil(err)
+	assert.Empty(earnings)
+
+	require.Nil(n.Earnings.Flush())
+	earnings, err = dbh.SelectEarnings(&common.DBEarningsFilter{Sender: &sender})
+	require.Nil(err)
+	require.Len(earnings, 1)
+	assert.Equal(int64(4), earnings[0].Tickets)
+	assert.Equal(int64(1), earnings[0].WinningTickets)
+	// tickets are recorded in the current round rather than in the creation round of the tickets
+	assert.NotEqual(int64(10), payment.ExpirationParams.CreationRound)
+	assert.Equal(int64(10), earnings[0].Round)
+	assert.Equal("4000/3", earnings[0].EV.RatString())
+	assert.Equal(big.NewInt(1000), earnings[0].WinningFaceValue)
+
+	// debited fees and pixels are recorded in the current round, along with the tickets that paid for them
+	orch.DebitFees(sender, ManifestID("foo"), &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 3}, 1000)
+	require.Nil(n.Earnings.Flush())
+	earnings, err = dbh.SelectEarnings(&common.DBEarningsFilter{Sender: &sender})
+	require.Nil(err)
+	require.Len(earnings, 1)
+	assert.Equal(int64(10), earnings[0].Round)
+	assert.Equal(int64(4), earnings[0].Tickets)
+	assert.Equal(int64(1000), earnings[0].Pixels)
+	assert.Zero(earnings[0].Fees.Cmp(big.NewRat(1000, 3)))
+}
+
 func TestProcessPayment_GivenMultipleWinningTickets_RedeemsAll(t *testing.T) {
 	addr := defaultRecipient
 	dbh, dbraw := tempDBWithOrch(t, &common.DBOrch{
@@ -1400,7 +1816,7 @@ func TestPriceInfo(t *testing.T) {
 	recipient.On("TxCostMultiplier", mock.Anything).Return(txMultiplier, nil)
 	orch := NewOrchestrator(n, nil)
 
-	priceInfo, err := orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err := orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	assert.Zero(expPricePerPixel.Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
 	fixedPrice, err := common.PriceToFixed(expPricePerPixel)
@@ -1415,7 +1831,7 @@ func TestPriceInfo(t *testing.T) {
 	orch = NewOrchestrator(n, nil)
 	expPricePerPixel = big.NewRat(1010, 100)
 
-	priceInfo, err = orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	assert.Zero(expPricePerPixel.Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
 	fixedPrice, err = common.PriceToFixed(expPricePerPixel)
@@ -1430,7 +1846,7 @@ func TestPriceInfo(t *testing.T) {
 	orch = NewOrchestrator(n, nil)
 	expPricePerPixel = big.NewRat(101, 1000)
 
-	priceInfo, err = orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	assert.Zero(expPricePerPixel.Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
 	fixedPrice, err = common.PriceToFixed(expPricePerPixel)
@@ -1444,7 +1860,7 @@ func TestPriceInfo(t *testing.T) {
 	orch = NewOrchestrator(n, nil)
 	expPricePerPixel = big.NewRat(2525, 1000)
 
-	priceInfo, err = orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	assert.Zero(expPricePerPixel.Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
 	fixedPrice, err = common.PriceToFixed(expPricePerPixel)
@@ -1463,7 +1879,7 @@ func TestPriceInfo(t *testing.T) {
 	orch = NewOrchestrator(n, nil)
 	expPricePerPixel = big.NewRat(11, 1)
 
-	priceInfo, err = orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	assert.Zero(expPricePerPixel.Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
 	fixedPrice, err = common.PriceToFixed(expPricePerPixel)
@@ -1482,7 +1898,7 @@ func TestPriceInfo(t *testing.T) {
 	orch = NewOrchestrator(n, nil)
 	expPricePerPixel = big.NewRat(1100, 10)
 
-	priceInfo, err = orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	assert.Zero(expPricePerPixel.Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
 	fixedPrice, err = common.PriceToFixed(expPricePerPixel)
@@ -1501,7 +1917,7 @@ func TestPriceInfo(t *testing.T) {
 	orch = NewOrchestrator(n, nil)
 	expPricePerPixel = big.NewRat(20, 1)
 
-	priceInfo, err = orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	assert.Zero(expPricePerPixel.Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
 	fixedPrice, err = common.PriceToFixed(expPricePerPixel)
@@ -1514,7 +1930,7 @@ func TestPriceInfo(t *testing.T) {
 	n.SetBasePrice("default", big.NewRat(0, 1))
 	orch = NewOrchestrator(n, nil)
 
-	priceInfo, err = orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	assert.Zero(priceInfo.PricePerUnit)
 	assert.Equal(int64(1), priceInfo.PixelsPerUnit)
@@ -1532,7 +1948,7 @@ func TestPriceInfo(t *testing.T) {
 	overhead := new(big.Rat).Add(big.NewRat(1, 1), new(big.Rat).Inv(txMultiplier))
 	expPricePerPixel = new(big.Rat).Mul(basePrice, overhead) // 23953749205332825000/926899968213313
 	require.Equal(expPricePerPixel.Num().Cmp(big.NewInt(int64(math.MaxInt64))), 1)
-	priceInfo, err = orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	// for this case price will be rounded when converting to fixed
 	assert.NotEqual(expPricePerPixel.Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)), 0)
@@ -1548,17 +1964,53 @@ func TestPriceInfo(t *testing.T) {
 
 	// Now make sure when AutoAdjustPrice = false we are returning the base price
 	n.AutoAdjustPrice = false
-	priceInfo, err = orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(err)
 	assert.Equal(basePrice, big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit))
 }
 
+func TestPriceInfo_UtilizationPricing(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	defer func(max int) { MaxSessions = max }(MaxSessions)
+	MaxSessions = 2
+
+	n, _ := NewLivepeerNode(nil, "", nil)
+	n.SetBasePrice("default", big.NewRat(10, 1))
+	n.AutoAdjustPrice = false
+	n.Recipient = new(pm.MockRecipient)
+	up, err := ParseUtilizationPricing("0:0.5,0.5:1,1:2")
+	require.Nil(err)
+	n.UtilizationPricing = up
+	orch := NewOrchestrator(n, nil)
+
+	// idle
+	priceInfo, err := orch.PriceInfo(ethcommon.Address{}, nil)
+	require.Nil(err)
+	assert.Zero(big.NewRat(5, 1).Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
+
+	// half utilized
+	n.SegmentChans["foo"] = make(SegmentChan)
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
+	require.Nil(err)
+	assert.Zero(big.NewRat(10, 1).Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
+
+	// fully utilized
+	n.SegmentChans["bar"] = make(SegmentChan)
+	priceInfo, err = orch.PriceInfo(ethcommon.Address{}, nil)
+	require.Nil(err)
+	assert.Zero(big.NewRat(20, 1).Cmp(big.NewRat(priceInfo.PricePerUnit, priceInfo.PixelsPerUnit)))
+
+	// base price is left untouched
+	assert.Zero(big.NewRat(10, 1).Cmp(n.GetBasePrice("default")))
+}
+
 func TestPriceInfo_GivenNilNode_ReturnsNilError(t *testing.T) {
 	n, _ := NewLivepeerNode(nil, "", nil)
 	orch := NewOrchestrator(n, nil)
 	orch.node = nil
 
-	priceInfo, err := orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err := orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(t, err)
 	assert.Nil(t, priceInfo)
 }
@@ -1568,7 +2020,7 @@ func TestPriceInfo_GivenNilRecipient_ReturnsNilError(t *testing.T) {
 	orch := NewOrchestrator(n, nil)
 	n.Recipient = nil
 
-	priceInfo, err := orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err := orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(t, err)
 	assert.Nil(t, priceInfo)
 }
@@ -1583,7 +2035,7 @@ func TestPriceInfo_TxMultiplierError_ReturnsError(t *testing.T) {
 	recipient.On("TxCostMultiplier", mock.Anything).Return(nil, expError)
 	orch := NewOrchestrator(n, nil)
 
-	priceInfo, err := orch.PriceInfo(ethcommon.Address{})
+	priceInfo, err := orch.PriceInfo(ethcommon.Address{}, nil)
 	assert.Nil(t, priceInfo)
 	assert.EqualError(t, err, expError.Error())
 }
@@ -19,13 +19,16 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/livepeer/go-livepeer/alert"
 	"github.com/livepeer/go-livepeer/clog"
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/eth"
 	"github.com/livepeer/go-livepeer/monitor"
 	"github.com/livepeer/go-livepeer/net"
 	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/go-livepeer/storage"
 	"github.com/livepeer/go-tools/drivers"
 
 	lpcrypto "github.com/livepeer/go-livepeer/crypto"
@@ -79,14 +82,33 @@ func (orch *orchestrator) TranscoderSecret() string {
 	return orch.node.OrchSecret
 }
 
+// CheckCapacity returns ErrOrchCap if there is no capacity for the session. For a new session
+// a slot is reserved until it starts transcoding, queueing the request first if a SessionQueue is set.
+// An empty mid only checks whether there is capacity for a new session, which is never the case while draining
+// so that discovery requests are refused. Existing sessions get the refreshed orchestrator info with their segments.
 func (orch *orchestrator) CheckCapacity(mid ManifestID) error {
-	orch.node.segmentMutex.RLock()
-	defer orch.node.segmentMutex.RUnlock()
-	if _, ok := orch.node.SegmentChans[mid]; ok {
+	if mid == "" {
+		if !orch.node.hasSessionCapacity() {
+			return ErrOrchCap
+		}
 		return nil
 	}
-	if len(orch.node.SegmentChans) >= MaxSessions {
-		return ErrOrchCap
+	return orch.node.waitForSessionCapacity(mid)
+}
+
+// ReleaseCapacity releases the slot reserved by CheckCapacity for a session that has not started transcoding,
+// e.g. because its segment was rejected. Sessions that are transcoding keep their slot.
+func (orch *orchestrator) ReleaseCapacity(mid ManifestID) {
+	orch.node.releaseSessionReservation(mid)
+}
+
+// CheckBroadcasterAccess returns an error if work from the broadcaster address should be refused
+func (orch *orchestrator) CheckBroadcasterAccess(addr ethcommon.Address) error {
+	if orch.node == nil || orch.node.BroadcasterAccess == nil {
+		return nil
+	}
+	if !orch.node.BroadcasterAccess.Allowed(addr) {
+		return ErrBroadcasterNotAllowed
 	}
 	return nil
 }
@@ -103,7 +125,7 @@ func (orch *orchestrator) TranscoderResults(tcID int64, res *RemoteTranscoderRes
 	orch.node.TranscoderManager.transcoderResults(tcID, res)
 }
 
-func (orch *orchestrator) ProcessPayment(ctx context.Context, payment net.Payment, manifestID ManifestID) error {
+func (orch *orchestrator) ProcessPayment(ctx context.Context, payment net.Payment, manifestID ManifestID) (err error) {
 	if orch.node == nil || orch.node.Recipient == nil {
 		return nil
 	}
@@ -118,6 +140,21 @@ func (orch *orchestrator) ProcessPayment(ctx context.Context, payment net.Paymen
 
 	sender := ethcommon.BytesToAddress(payment.Sender)
 
+	if err := orch.CheckBroadcasterAccess(sender); err != nil {
+		return err
+	}
+
+	reserved, err := orch.node.reserveBroadcasterSession(sender, manifestID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Don't hold a slot for a session that was never paid for
+		if reserved && err != nil {
+			orch.node.releaseBroadcasterSession(manifestID)
+		}
+	}()
+
 	recipientAddr := ethcommon.BytesToAddress(payment.TicketParams.Recipient)
 	ok, err := orch.isPaymentEligible(recipientAddr)
 	if err != nil {
@@ -158,8 +195,9 @@ func (orch *orchestrator) ProcessPayment(ctx context.Context, payment net.Paymen
 	totalEV := big.NewRat(0, 1)
 	totalTickets := 0
 	totalWinningTickets := 0
+	totalWinningFaceValue := big.NewInt(0)
 
-	var receiveErr error
+	var receiveErr, queueErr error
 
 	for _, tsp := range payment.TicketSenderParams {
 
@@ -189,6 +227,20 @@ func (orch *orchestrator) ProcessPayment(ctx context.Context, payment net.Paymen
 			receiveErr = err
 		}
 
+		if won {
+			clog.V(common.DEBUG).Infof(ctx, "Received winning ticket sessionID=%v recipientRandHash=%x senderNonce=%v", manifestID, ticket.RecipientRandHash, ticket.SenderNonce)
+
+			// Persist the ticket before crediting and acknowledging the payment, so that it is redeemed even if the node crashes
+			if err := orch.node.Recipient.RedeemWinningTicket(ticket, tsp.Sig, seed); err != nil {
+				clog.Errorf(ctx, "error redeeming ticket sessionID=%v recipientRandHash=%x senderNonce=%v err=%q", manifestID, ticket.RecipientRandHash, ticket.SenderNonce, err)
+				queueErr = err
+				continue
+			}
+
+			totalWinningTickets++
+			totalWinningFaceValue.Add(totalWinningFaceValue, ticket.FaceValue)
+		}
+
 		if receiveErr == nil {
 			// Add ticket EV to credit
 			ev := ticket.EV()
@@ -196,29 +248,33 @@ func (orch *orchestrator) ProcessPayment(ctx context.Context, payment net.Paymen
 			totalEV.Add(totalEV, ev)
 			totalTickets++
 		}
-
-		if won {
-			clog.V(common.DEBUG).Infof(ctx, "Received winning ticket sessionID=%v recipientRandHash=%x senderNonce=%v", manifestID, ticket.RecipientRandHash, ticket.SenderNonce)
-
-			totalWinningTickets++
-
-			go func(ticket *pm.Ticket, sig []byte, seed *big.Int) {
-				if err := orch.node.Recipient.RedeemWinningTicket(ticket, sig, seed); err != nil {
-					clog.Errorf(ctx, "error redeeming ticket sessionID=%v recipientRandHash=%x senderNonce=%v err=%q", manifestID, ticket.RecipientRandHash, ticket.SenderNonce, err)
-				}
-			}(ticket, tsp.Sig, seed)
-		}
 	}
 
 	if monitor.Enabled {
 		monitor.TicketValueRecv(ctx, sender.Hex(), totalEV)
 		monitor.TicketsRecv(ctx, sender.Hex(), totalTickets)
 		monitor.WinningTicketsRecv(ctx, sender.Hex(), totalWinningTickets)
+		monitor.WinningTicketFaceValueRecv(ctx, sender.Hex(), totalWinningFaceValue)
+	}
+
+	if orch.node.Earnings != nil && totalTickets > 0 {
+		orch.node.Earnings.Add(&common.DBEarnings{
+			Sender:           sender,
+			ManifestID:       string(manifestID),
+			Round:            orch.earningsRound(),
+			Tickets:          int64(totalTickets),
+			WinningTickets:   int64(totalWinningTickets),
+			EV:               totalEV,
+			WinningFaceValue: totalWinningFaceValue,
+		})
 	}
 
 	if receiveErr != nil {
 		return receiveErr
 	}
+	if queueErr != nil {
+		return fmt.Errorf("failed to queue winning ticket err=%q", queueErr)
+	}
 
 	return nil
 }
@@ -252,12 +308,13 @@ func (orch *orchestrator) TicketParams(sender ethcommon.Address, priceInfo *net.
 	}, nil
 }
 
-func (orch *orchestrator) PriceInfo(sender ethcommon.Address) (*net.PriceInfo, error) {
+// PriceInfo returns the price of the orchestrator for a job of the sender requiring caps, if known
+func (orch *orchestrator) PriceInfo(sender ethcommon.Address, caps *Capabilities) (*net.PriceInfo, error) {
 	if orch.node == nil || orch.node.Recipient == nil {
 		return nil, nil
 	}
 
-	price, err := orch.priceInfo(sender)
+	price, err := orch.priceInfo(sender, caps)
 	if err != nil {
 		return nil, err
 	}
@@ -273,12 +330,8 @@ func (orch *orchestrator) PriceInfo(sender ethcommon.Address) (*net.PriceInfo, e
 }
 
 // priceInfo returns price per pixel as a fixed point number wrapped in a big.Rat
-func (orch *orchestrator) priceInfo(sender ethcommon.Address) (*big.Rat, error) {
-	basePrice := orch.node.GetBasePrice(sender.String())
-
-	if basePrice == nil {
-		basePrice = orch.node.GetBasePrice("default")
-	}
+func (orch *orchestrator) priceInfo(sender ethcommon.Address, caps *Capabilities) (*big.Rat, error) {
+	basePrice := orch.node.BasePriceForJob(sender.String(), caps)
 
 	if !orch.node.AutoAdjustPrice {
 		return basePrice, nil
@@ -327,14 +380,54 @@ func (orch *orchestrator) DebitFees(addr ethcommon.Address, manifestID ManifestI
 		return
 	}
 	priceRat := big.NewRat(price.GetPricePerUnit(), price.GetPixelsPerUnit())
-	orch.node.Balances.Debit(addr, manifestID, priceRat.Mul(priceRat, big.NewRat(pixels, 1)))
+	fees := priceRat.Mul(priceRat, big.NewRat(pixels, 1))
+	orch.node.Balances.Debit(addr, manifestID, fees)
+
+	if orch.node.Earnings != nil {
+		orch.node.Earnings.Add(&common.DBEarnings{
+			Sender:     addr,
+			ManifestID: string(manifestID),
+			Round:      orch.earningsRound(),
+			Pixels:     pixels,
+			Fees:       new(big.Rat).Set(fees),
+		})
+	}
+}
+
+// earningsRound returns the round in which earnings are recorded, i.e. the current round, so that the tickets received
+// for a segment and the fees debited for it are recorded in the same round
+func (orch *orchestrator) earningsRound() int64 {
+	if orch.rm == nil {
+		return 0
+	}
+	if r := orch.rm.LastInitializedRound(); r != nil {
+		return r.Int64()
+	}
+	return 0
 }
 
 func (orch *orchestrator) Capabilities() *net.Capabilities {
 	if orch.node == nil {
 		return nil
 	}
-	return orch.node.Capabilities.ToNetCapabilities()
+	caps := orch.node.Capabilities.ToNetCapabilities()
+	if caps != nil && orch.node.Draining() {
+		// advertise no capacity while draining
+		for c := range caps.Capacities {
+			caps.Capacities[c] = 0
+		}
+	}
+	return caps
+}
+
+// Region returns the operator configured region label of the orchestrator
+func (orch *orchestrator) Region() string {
+	return orch.node.Region
+}
+
+// Datacenter returns the operator configured datacenter or hosting provider label of the orchestrator
+func (orch *orchestrator) Datacenter() string {
+	return orch.node.Datacenter
 }
 
 func (orch *orchestrator) AuthToken(sessionID string, expiration int64) *net.AuthToken {
@@ -456,22 +549,150 @@ func (rtm *RemoteTranscoderManager) removeTaskChan(taskID int64) {
 	delete(rtm.taskChans, taskID)
 }
 
+// hasSessionCapacity returns whether a new session could be accepted without exceeding MaxSessions
+func (n *LivepeerNode) hasSessionCapacity() bool {
+	n.segmentMutex.Lock()
+	defer n.segmentMutex.Unlock()
+	n.expireSessionReservationsLocked()
+	return n.newSessionsAllowedLocked()
+}
+
+// newSessionsAllowedLocked returns whether a new session can take a slot without exceeding MaxSessions
+// or jumping ahead of queued sessions. Requires segmentMutex.
+func (n *LivepeerNode) newSessionsAllowedLocked() bool {
+	if n.Draining() || len(n.SegmentChans)+len(n.reservedSessions) >= MaxSessions {
+		return false
+	}
+	return n.SessionQueue == nil || n.SessionQueue.Waiting() == 0
+}
+
+// reserveSessionLocked returns whether the session holds a slot, reserving one for a new session
+// if there is capacity. Requires segmentMutex.
+func (n *LivepeerNode) reserveSessionLocked(mid ManifestID) bool {
+	if _, ok := n.SegmentChans[mid]; ok {
+		return true
+	}
+	if _, ok := n.reservedSessions[mid]; ok {
+		n.reservedSessions[mid] = time.Now()
+		return true
+	}
+	n.expireSessionReservationsLocked()
+	if !n.newSessionsAllowedLocked() {
+		return false
+	}
+	n.reservedSessions[mid] = time.Now()
+	return true
+}
+
+// releaseSessionLocked releases the slot reserved for a session that did not start transcoding
+// and hands it to the next queued session. Requires segmentMutex.
+func (n *LivepeerNode) releaseSessionLocked(mid ManifestID) {
+	delete(n.reservedSessions, mid)
+	if _, ok := n.SegmentChans[mid]; !ok {
+		delete(n.sessionSenders, mid)
+	}
+	n.admitQueuedSessionsLocked()
+}
+
+// releaseSessionReservation releases the slot reserved for a session, if any
+func (n *LivepeerNode) releaseSessionReservation(mid ManifestID) {
+	n.segmentMutex.Lock()
+	defer n.segmentMutex.Unlock()
+	if _, ok := n.reservedSessions[mid]; ok {
+		n.releaseSessionLocked(mid)
+	}
+}
+
+// expireSessionReservationsLocked releases reserved slots of sessions that never started transcoding.
+// Requires segmentMutex.
+func (n *LivepeerNode) expireSessionReservationsLocked() {
+	expired := false
+	for mid, reserved := range n.reservedSessions {
+		if time.Since(reserved) > sessionReservationTimeout {
+			delete(n.reservedSessions, mid)
+			delete(n.sessionSenders, mid)
+			expired = true
+		}
+	}
+	if expired {
+		n.admitQueuedSessionsLocked()
+	}
+}
+
+// admitQueuedSessionsLocked hands free slots to queued sessions, one slot per session in queue order.
+// Requires segmentMutex.
+func (n *LivepeerNode) admitQueuedSessionsLocked() {
+	if n.SessionQueue == nil || n.Draining() {
+		return
+	}
+	for len(n.SegmentChans)+len(n.reservedSessions) < MaxSessions {
+		w := n.SessionQueue.pop()
+		if w == nil {
+			return
+		}
+		n.reservedSessions[w.mid] = time.Now()
+		close(w.admitted)
+	}
+}
+
+// waitForSessionCapacity reserves a slot for the session, returning ErrOrchCap if there is no capacity or the
+// node is draining. If a SessionQueue is configured the request is held until a slot is handed to it or the
+// queue times out.
+func (n *LivepeerNode) waitForSessionCapacity(mid ManifestID) error {
+	n.segmentMutex.Lock()
+	if n.reserveSessionLocked(mid) {
+		n.segmentMutex.Unlock()
+		return nil
+	}
+	// no point queueing while draining, capacity won't free up for new sessions
+	if n.SessionQueue == nil || n.Draining() {
+		n.segmentMutex.Unlock()
+		return ErrOrchCap
+	}
+	w := n.SessionQueue.enqueue(mid)
+	n.segmentMutex.Unlock()
+	if w == nil {
+		return ErrOrchCap
+	}
+
+	timer := time.NewTimer(n.SessionQueue.timeout)
+	defer timer.Stop()
+	select {
+	case <-w.admitted:
+		return nil
+	case <-timer.C:
+	}
+
+	n.segmentMutex.Lock()
+	defer n.segmentMutex.Unlock()
+	if n.SessionQueue.remove(w) {
+		return ErrOrchCap
+	}
+	// admitted between the timeout and acquiring the lock
+	return nil
+}
+
 func (n *LivepeerNode) getSegmentChan(ctx context.Context, md *SegTranscodingMetadata) (SegmentChan, error) {
+	mid := ManifestID(md.AuthToken.SessionId)
 	// concurrency concerns here? what if a chan is added mid-call?
 	n.segmentMutex.Lock()
 	defer n.segmentMutex.Unlock()
-	if sc, ok := n.SegmentChans[ManifestID(md.AuthToken.SessionId)]; ok {
+	if sc, ok := n.SegmentChans[mid]; ok {
 		return sc, nil
 	}
-	if len(n.SegmentChans) >= MaxSessions {
+	// The slot is usually already reserved by CheckCapacity
+	if !n.reserveSessionLocked(mid) {
+		delete(n.sessionSenders, mid)
 		return nil, ErrOrchCap
 	}
 	sc := make(SegmentChan, maxSegmentChannels)
 	clog.V(common.DEBUG).Infof(ctx, "Creating new segment chan")
 	if err := n.transcodeSegmentLoop(clog.Clone(context.Background(), ctx), md, sc); err != nil {
+		n.releaseSessionLocked(mid)
 		return nil, err
 	}
-	n.SegmentChans[ManifestID(md.AuthToken.SessionId)] = sc
+	delete(n.reservedSessions, mid)
+	n.SegmentChans[mid] = sc
 	if lpmon.Enabled {
 		lpmon.CurrentSessions(len(n.SegmentChans))
 	}
@@ -557,7 +778,9 @@ func (n *LivepeerNode) transcodeSeg(ctx context.Context, config transcodeConfig,
 
 	//Do the transcoding
 	start := time.Now()
-	tData, err := transcoder.Transcode(ctx, md)
+	tctx, span := monitor.StartSegmentSpan(ctx, monitor.SpanTranscode, string(md.ManifestID), seg.SeqNo)
+	tData, err := transcoder.Transcode(tctx, md)
+	monitor.EndSpan(span, err)
 	if err != nil {
 		if _, ok := err.(UnrecoverableError); ok {
 			panic(err)
@@ -576,7 +799,11 @@ func (n *LivepeerNode) transcodeSeg(ctx context.Context, config transcodeConfig,
 	took := time.Since(start)
 	clog.V(common.DEBUG).Infof(ctx, "Transcoding of segment took=%v", took)
 	if monitor.Enabled {
-		monitor.SegmentTranscoded(ctx, 0, seg.SeqNo, md.Duration, took, common.ProfilesNames(md.Profiles), true, true)
+		monitor.SegmentTranscoded(ctx, 0, seg.SeqNo, md.Duration, took, common.ProfilesNames(md.Profiles),
+			monitor.JobType{Codecs: common.ProfilesCodecs(md.Profiles), Detection: md.DetectorEnabled}, true, true)
+		for _, p := range md.Profiles {
+			monitor.RenditionStageTime(monitor.RenditionStageTranscode, p.Name, p.Resolution, took)
+		}
 	}
 
 	// Prepare the result object
@@ -625,7 +852,7 @@ func (n *LivepeerNode) transcodeSegmentLoop(logCtx context.Context, md *SegTrans
 	los := drivers.NodeStorage.NewSession(md.AuthToken.SessionId)
 
 	// determine appropriate OS to use
-	os := drivers.NewSession(FromNetOsInfo(md.OS))
+	os := storage.NewRetrySession(drivers.NewSession(FromNetOsInfo(md.OS)), storage.DefaultRetryPolicy)
 	if os == nil {
 		// no preference (or unknown pref), so use our own
 		os = los
@@ -694,6 +921,8 @@ func (n *LivepeerNode) endTranscodingSession(sessionId string, logCtx context.Co
 			lpmon.CurrentSessions(len(n.SegmentChans))
 		}
 	}
+	delete(n.sessionSenders, mid)
+	n.releaseSessionLocked(mid)
 	n.segmentMutex.Unlock()
 }
 
@@ -752,10 +981,15 @@ func (rt *RemoteTranscoder) done() {
 func (rt *RemoteTranscoder) Transcode(logCtx context.Context, md *SegTranscodingMetadata) (*TranscodeData, error) {
 	taskID, taskChan := rt.manager.addTaskChan()
 	defer rt.manager.removeTaskChan(taskID)
+	logCtx, span := monitor.StartSegmentSpan(logCtx, monitor.SpanTranscodeRemote, string(md.ManifestID), uint64(md.Seq),
+		attribute.String(monitor.AttrTranscoder, rt.addr), attribute.Int64(monitor.AttrTaskID, taskID))
+	defer span.End()
 	fname := md.Fname
 	signalEOF := func(err error) (*TranscodeData, error) {
 		rt.done()
+		monitor.SetSpanError(logCtx, err)
 		clog.Errorf(logCtx, "Fatal error with remote transcoder=%s taskId=%d fname=%s err=%q", rt.addr, taskID, fname, err)
+		alert.Fire(alert.TranscoderEvicted, alert.Critical, rt.addr, "Remote transcoder=%s evicted after a fatal error err=%q", rt.addr, err)
 		return nil, RemoteTranscoderFatalError{err}
 	}
 
@@ -774,7 +1008,8 @@ func (rt *RemoteTranscoder) Transcode(logCtx context.Context, md *SegTranscoding
 		TaskId:  taskID,
 		SegData: segData,
 		// Triggers failure on Os that don't know how to use SegData
-		Profiles: []byte("invalid"),
+		Profiles:     []byte("invalid"),
+		TraceContext: monitor.TraceContext(logCtx),
 	}
 	err = rt.stream.Send(msg)
 
@@ -801,6 +1036,7 @@ func (rt *RemoteTranscoder) Transcode(logCtx context.Context, md *SegTranscoding
 		}
 		clog.InfofErr(logCtx, "Successfully received results from remote transcoder=%s segments=%d taskId=%d fname=%s dur=%v",
 			rt.addr, segmentLen, taskID, fname, time.Since(start), chanData.Err)
+		monitor.SetSpanError(logCtx, chanData.Err)
 		return chanData.TranscodeData, chanData.Err
 	}
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/net"
 	"github.com/livepeer/go-tools/drivers"
+	gonet "net"
 	"net/http"
 	"time"
 )
@@ -19,9 +20,25 @@ func GetSegmentData(ctx context.Context, uri string) ([]byte, error) {
 	return getSegmentDataHTTP(ctx, uri)
 }
 
+// SegmentTLSConfig returns the TLS config to download segments from the host:port addr. Orchestrators usually have
+// self-signed certificates, so certificates aren't verified unless it's replaced, e.g. by the certificate pins of
+// the broadcaster.
+var SegmentTLSConfig = func(addr string) *tls.Config {
+	return insecureTLSConfig
+}
+
+var insecureTLSConfig = &tls.Config{InsecureSkipVerify: true}
+
 var httpc = &http.Client{
-	Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
-	Timeout:   common.HTTPTimeout / 2,
+	Transport: &http.Transport{
+		TLSClientConfig: insecureTLSConfig,
+		DialTLSContext: func(ctx context.Context, network, addr string) (gonet.Conn, error) {
+			tlsDialer := &tls.Dialer{Config: SegmentTLSConfig(addr)}
+			return tlsDialer.DialContext(ctx, network, addr)
+		},
+		ForceAttemptHTTP2: true,
+	},
+	Timeout: common.HTTPTimeout / 2,
 }
 
 func FromNetOsInfo(os *net.OSInfo) *drivers.OSInfo {
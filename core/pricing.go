@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type utilizationStep struct {
+	threshold  float64
+	multiplier *big.Rat
+}
+
+// UtilizationPricing scales the orchestrator's base price according to how many
+// of its sessions are currently in use
+type UtilizationPricing struct {
+	steps []utilizationStep
+}
+
+// ParseUtilizationPricing parses a comma-separated list of <utilization>:<multiplier> pairs
+// i.e. "0:0.8,0.5:1,0.8:1.5". Utilization thresholds are fractions of MaxSessions in the range [0, 1].
+// The multiplier of the highest threshold that is less than or equal to the current utilization is applied,
+// utilization below the lowest threshold leaves the base price unchanged.
+func ParseUtilizationPricing(s string) (*UtilizationPricing, error) {
+	var steps []utilizationStep
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid utilization pricing step %q, expected <utilization>:<multiplier>", pair)
+		}
+		threshold, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			return nil, fmt.Errorf("invalid utilization threshold %q, must be between 0 and 1", parts[0])
+		}
+		multiplier, ok := new(big.Rat).SetString(parts[1])
+		if !ok || multiplier.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid price multiplier %q, must be greater than 0", parts[1])
+		}
+		steps = append(steps, utilizationStep{threshold: threshold, multiplier: multiplier})
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("no utilization pricing steps provided")
+	}
+
+	sort.Slice(steps, func(i, j int) bool { return steps[i].threshold < steps[j].threshold })
+	for i := 1; i < len(steps); i++ {
+		if steps[i].threshold == steps[i-1].threshold {
+			return nil, fmt.Errorf("duplicate utilization threshold %v", steps[i].threshold)
+		}
+	}
+
+	return &UtilizationPricing{steps: steps}, nil
+}
+
+// Multiplier returns the price multiplier for the given utilization
+func (p *UtilizationPricing) Multiplier(utilization float64) *big.Rat {
+	multiplier := big.NewRat(1, 1)
+	for _, step := range p.steps {
+		if utilization < step.threshold {
+			break
+		}
+		multiplier = step.multiplier
+	}
+	return new(big.Rat).Set(multiplier)
+}
+
+// String returns the pricing steps in the format accepted by ParseUtilizationPricing
+func (p *UtilizationPricing) String() string {
+	strs := make([]string, len(p.steps))
+	for i, step := range p.steps {
+		strs[i] = fmt.Sprintf("%v:%v", step.threshold, step.multiplier.FloatString(2))
+	}
+	return strings.Join(strs, ",")
+}
+
+// BasePriceForBroadcaster returns the base price for a broadcaster, falling back to the default base price,
+// scaled by utilization pricing if it is enabled
+func (n *LivepeerNode) BasePriceForBroadcaster(addr string) *big.Rat {
+	return n.BasePriceForJob(addr, nil)
+}
+
+// BasePriceForJob returns the base price for a job of a broadcaster requiring caps, scaled by utilization pricing
+// if it is enabled. If the job requires capabilities that have a specific base price, for the broadcaster or else
+// by default, the highest of those prices is used. Otherwise the base price of the broadcaster applies, falling back
+// to the default base price.
+func (n *LivepeerNode) BasePriceForJob(addr string, caps *Capabilities) *big.Rat {
+	basePrice := n.capabilityBasePrice(addr, caps)
+	if basePrice == nil {
+		basePrice = n.GetBasePrice(addr)
+	}
+	if basePrice == nil {
+		basePrice = n.GetBasePrice("default")
+	}
+
+	if basePrice != nil && n.UtilizationPricing != nil {
+		multiplier := n.UtilizationPricing.Multiplier(n.SessionUtilization())
+		basePrice = new(big.Rat).Mul(basePrice, multiplier)
+	}
+
+	return basePrice
+}
+
+func (n *LivepeerNode) capabilityBasePrice(addr string, caps *Capabilities) *big.Rat {
+	if caps == nil {
+		return nil
+	}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for _, key := range []string{strings.ToLower(addr), "default"} {
+		var maxPrice *big.Rat
+		for capability, price := range n.capabilityPrices[key] {
+			if !caps.HasCapability(capability) {
+				continue
+			}
+			if maxPrice == nil || price.Cmp(maxPrice) > 0 {
+				maxPrice = price
+			}
+		}
+		if maxPrice != nil {
+			return maxPrice
+		}
+	}
+	return nil
+}
+
+// SessionUtilization returns the fraction of MaxSessions currently in use
+func (n *LivepeerNode) SessionUtilization() float64 {
+	if MaxSessions <= 0 {
+		return 1
+	}
+	return float64(n.ActiveSessions()) / float64(MaxSessions)
+}
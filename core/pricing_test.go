@@ -0,0 +1,112 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUtilizationPricing(t *testing.T) {
+	assert := assert.New(t)
+
+	up, err := ParseUtilizationPricing("0.8:1.5, 0:0.8,0.5:1")
+	require.Nil(t, err)
+	assert.Equal("0:0.80,0.5:1.00,0.8:1.50", up.String())
+
+	_, err = ParseUtilizationPricing("")
+	assert.EqualError(err, `invalid utilization pricing step "", expected <utilization>:<multiplier>`)
+	_, err = ParseUtilizationPricing("0.5")
+	assert.EqualError(err, `invalid utilization pricing step "0.5", expected <utilization>:<multiplier>`)
+	_, err = ParseUtilizationPricing("1.5:2")
+	assert.EqualError(err, `invalid utilization threshold "1.5", must be between 0 and 1`)
+	_, err = ParseUtilizationPricing("foo:2")
+	assert.EqualError(err, `invalid utilization threshold "foo", must be between 0 and 1`)
+	_, err = ParseUtilizationPricing("0.5:0")
+	assert.EqualError(err, `invalid price multiplier "0", must be greater than 0`)
+	_, err = ParseUtilizationPricing("0.5:-1")
+	assert.EqualError(err, `invalid price multiplier "-1", must be greater than 0`)
+	_, err = ParseUtilizationPricing("0.5:1,0.5:2")
+	assert.EqualError(err, "duplicate utilization threshold 0.5")
+}
+
+func TestUtilizationPricing_Multiplier(t *testing.T) {
+	assert := assert.New(t)
+
+	up, err := ParseUtilizationPricing("0.25:0.8,0.5:1,0.8:3/2")
+	require.Nil(t, err)
+
+	assert.Zero(up.Multiplier(0).Cmp(big.NewRat(1, 1)))
+	assert.Zero(up.Multiplier(0.25).Cmp(big.NewRat(4, 5)))
+	assert.Zero(up.Multiplier(0.4).Cmp(big.NewRat(4, 5)))
+	assert.Zero(up.Multiplier(0.5).Cmp(big.NewRat(1, 1)))
+	assert.Zero(up.Multiplier(0.79).Cmp(big.NewRat(1, 1)))
+	assert.Zero(up.Multiplier(0.8).Cmp(big.NewRat(3, 2)))
+	assert.Zero(up.Multiplier(1).Cmp(big.NewRat(3, 2)))
+
+	// Returned multiplier must not alias internal state
+	up.Multiplier(1).SetInt64(100)
+	assert.Zero(up.Multiplier(1).Cmp(big.NewRat(3, 2)))
+}
+
+func TestSessionUtilization(t *testing.T) {
+	defer func(max int) { MaxSessions = max }(MaxSessions)
+	MaxSessions = 4
+
+	n, _ := NewLivepeerNode(nil, "", nil)
+	assert.Equal(t, 0.0, n.SessionUtilization())
+
+	n.SegmentChans["foo"] = make(SegmentChan)
+	assert.Equal(t, 0.25, n.SessionUtilization())
+
+	n.SegmentChans["bar"] = make(SegmentChan)
+	n.SegmentChans["baz"] = make(SegmentChan)
+	n.SegmentChans["qux"] = make(SegmentChan)
+	assert.Equal(t, 1.0, n.SessionUtilization())
+}
+
+func TestBasePriceForJob(t *testing.T) {
+	assert := assert.New(t)
+	n, _ := NewLivepeerNode(nil, "", nil)
+	bcast := "0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B"
+	h264 := NewCapabilities([]Capability{Capability_H264}, nil)
+	hevc := NewCapabilities([]Capability{Capability_H264, Capability_HEVC_Encode}, nil)
+
+	n.SetBasePrice("default", big.NewRat(1, 1))
+	assert.Equal(big.NewRat(1, 1), n.BasePriceForJob(bcast, nil))
+	assert.Equal(big.NewRat(1, 1), n.BasePriceForJob(bcast, hevc))
+
+	// capability prices apply to the jobs requiring the capability
+	n.SetCapabilityBasePrice("default", Capability_HEVC_Encode, big.NewRat(3, 1))
+	assert.Equal(big.NewRat(1, 1), n.BasePriceForJob(bcast, h264))
+	assert.Equal(big.NewRat(3, 1), n.BasePriceForJob(bcast, hevc))
+	assert.Equal(big.NewRat(1, 1), n.BasePriceForBroadcaster(bcast))
+
+	// the highest of the capability prices of the job applies
+	n.SetCapabilityBasePrice("default", Capability_H264, big.NewRat(2, 1))
+	assert.Equal(big.NewRat(2, 1), n.BasePriceForJob(bcast, h264))
+	assert.Equal(big.NewRat(3, 1), n.BasePriceForJob(bcast, hevc))
+
+	// the capability prices of the broadcaster take precedence over the default ones
+	n.SetBasePrice(bcast, big.NewRat(5, 1))
+	assert.Equal(big.NewRat(3, 1), n.BasePriceForJob(bcast, hevc))
+	n.SetCapabilityBasePrice(bcast, Capability_HEVC_Encode, big.NewRat(4, 1))
+	assert.Equal(big.NewRat(4, 1), n.BasePriceForJob(strings.ToLower(bcast), hevc))
+	assert.Equal(big.NewRat(2, 1), n.BasePriceForJob(bcast, h264))
+	assert.Equal(big.NewRat(3, 1), n.BasePriceForJob("0x1111111111111111111111111111111111111111", hevc))
+
+	prices := n.GetCapabilityBasePrices()
+	assert.Len(prices, 2)
+	assert.Equal(big.NewRat(4, 1), prices[strings.ToLower(bcast)][Capability_HEVC_Encode])
+
+	// removing a capability price restores the next price
+	n.SetCapabilityBasePrice(bcast, Capability_HEVC_Encode, nil)
+	assert.Equal(big.NewRat(3, 1), n.BasePriceForJob(bcast, hevc))
+	assert.NotContains(n.GetCapabilityBasePrices(), strings.ToLower(bcast))
+
+	// utilization pricing scales the capability prices
+	n.UtilizationPricing, _ = ParseUtilizationPricing("0:2")
+	assert.Equal(big.NewRat(6, 1), n.BasePriceForJob(bcast, hevc))
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures how the transcoding of a segment is retried after a failed attempt
+type RetryPolicy struct {
+	// Maximum number of transcode attempts per segment, 0 to use the node default
+	MaxAttempts int
+	// Maximum time spent on all the transcode attempts of a segment, 0 for no limit
+	Budget time.Duration
+	// Delay before the first retry, doubled with every further retry up to MaxBackoff (if set)
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Maximum number of retries after each kind of failure, negative for no limit other than MaxAttempts
+	TimeoutRetries      int
+	RefusalRetries      int
+	VerificationRetries int
+}
+
+// BackoffDelay returns the delay before the given retry, starting at 1. A random jitter of
+// up to half the delay is subtracted so that streams failing together don't retry in lockstep.
+func (p RetryPolicy) BackoffDelay(retry int) time.Duration {
+	if p.Backoff <= 0 || retry < 1 {
+		return 0
+	}
+	delay := p.Backoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay/2)+1))
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_BackoffDelay(t *testing.T) {
+	assert := assert.New(t)
+
+	// no backoff
+	assert.Zero(RetryPolicy{}.BackoffDelay(1))
+
+	p := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: 500 * time.Millisecond}
+	assert.Zero(p.BackoffDelay(0))
+	for i := 0; i < 100; i++ {
+		for retry, max := range map[int]time.Duration{1: 100, 2: 200, 3: 400, 4: 500, 10: 500} {
+			max *= time.Millisecond
+			delay := p.BackoffDelay(retry)
+			assert.LessOrEqual(int64(delay), int64(max))
+			assert.GreaterOrEqual(int64(delay), int64(max/2))
+		}
+	}
+
+	// no max backoff
+	p.MaxBackoff = 0
+	delay := p.BackoffDelay(5)
+	assert.LessOrEqual(int64(delay), int64(1600*time.Millisecond))
+	assert.GreaterOrEqual(int64(delay), int64(800*time.Millisecond))
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionRetryAfter is the hint returned to broadcasters for when a request
+// refused because the orchestrator is at capacity may be retried. Broadcasters
+// don't send new sessions to the orchestrator until then. No hint is returned if it is 0.
+var SessionRetryAfter = 5 * time.Second
+
+// sessionReservationTimeout is how long a slot admitted by CheckCapacity is held for
+// a session that has not started transcoding, e.g. because its payment failed
+var sessionReservationTimeout = 1 * time.Minute
+
+// SessionQueue holds session requests that arrive while the orchestrator is at
+// MaxSessions for a short period of time instead of rejecting them immediately.
+// Each freed session slot is handed to the request at the front of the queue.
+type SessionQueue struct {
+	timeout time.Duration
+	size    int
+
+	mu      sync.Mutex
+	waiters []*sessionWaiter
+}
+
+type sessionWaiter struct {
+	mid      ManifestID
+	admitted chan struct{}
+}
+
+// NewSessionQueue creates a SessionQueue that holds up to size requests for at most timeout each
+func NewSessionQueue(timeout time.Duration, size int) *SessionQueue {
+	return &SessionQueue{
+		timeout: timeout,
+		size:    size,
+	}
+}
+
+// enqueue adds a request for the session to the back of the queue. It returns nil if the queue is full.
+func (q *SessionQueue) enqueue(mid ManifestID) *sessionWaiter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.waiters) >= q.size {
+		return nil
+	}
+	w := &sessionWaiter{mid: mid, admitted: make(chan struct{})}
+	q.waiters = append(q.waiters, w)
+	return w
+}
+
+// pop removes the request at the front of the queue, nil if the queue is empty
+func (q *SessionQueue) pop() *sessionWaiter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.waiters) == 0 {
+		return nil
+	}
+	w := q.waiters[0]
+	q.waiters[0] = nil
+	q.waiters = q.waiters[1:]
+	return w
+}
+
+// remove removes a request from the queue and returns whether it was still queued
+func (q *SessionQueue) remove(w *sessionWaiter) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, waiter := range q.waiters {
+		if waiter == w {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Waiting returns the number of requests currently held in the queue
+func (q *SessionQueue) Waiting() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiters)
+}
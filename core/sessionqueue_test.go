@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionQueue(t *testing.T) {
+	assert := assert.New(t)
+
+	q := NewSessionQueue(time.Second, 2)
+	assert.Nil(q.pop())
+
+	w1 := q.enqueue("foo")
+	w2 := q.enqueue("bar")
+	assert.NotNil(w1)
+	assert.NotNil(w2)
+	assert.Equal(2, q.Waiting())
+
+	// queue is full
+	assert.Nil(q.enqueue("baz"))
+
+	// requests are admitted in order
+	assert.Equal(w1, q.pop())
+	assert.Equal(1, q.Waiting())
+	assert.False(q.remove(w1))
+	assert.True(q.remove(w2))
+	assert.Equal(0, q.Waiting())
+	assert.Nil(q.pop())
+}
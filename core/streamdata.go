@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/url"
 	"strings"
 	"time"
 
@@ -45,6 +46,14 @@ type StreamParameters struct {
 	Codec             ffmpeg.VideoCodec
 	PixelFormat       ffmpeg.PixelFormat
 	TimeoutMultiplier int // Used in the VOD workflow to allow us to be more lenient with timeouts
+	// Orchestrators to use for the stream, in order of preference, before falling back to normal selection
+	PreferredOrchestrators []*url.URL
+	// Submit each segment to two orchestrators in parallel and use the first valid result
+	RedundantTranscoding bool
+	// Transcode the highest rendition and the other renditions of each segment on different orchestrators
+	SplitRenditions bool
+	// Overrides the node's retry policy for the segments of the stream
+	RetryPolicy *RetryPolicy
 }
 
 func (s *StreamParameters) StreamID() string {
@@ -0,0 +1,129 @@
+package discovery
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Weight of the latest request in the moving averages of the discovery stats
+const discoveryStatsAlpha = 0.2
+
+// DiscoveryLimits are the floor and ceiling of the number of concurrent GetOrchestrator requests
+// and of the time to wait for orchestrators to respond during discovery
+type DiscoveryLimits struct {
+	MinParallelism int
+	MaxParallelism int
+	MinTimeout     time.Duration
+	MaxTimeout     time.Duration
+}
+
+// DefaultDiscoveryLimits returns the limits used by pools unless configured otherwise
+func DefaultDiscoveryLimits() DiscoveryLimits {
+	return DiscoveryLimits{
+		MinParallelism: 10,
+		MaxParallelism: 100,
+		MinTimeout:     getOrchestratorsCutoffTimeout,
+		MaxTimeout:     maxGetOrchestratorCutoffTimeout,
+	}
+}
+
+// discoveryStats keeps moving averages of the response time of GetOrchestrator requests and of the share of
+// requests that return a usable orchestrator, which are used to size the parallelism and timeouts of discovery
+// within its limits
+type discoveryStats struct {
+	mu           sync.Mutex
+	limits       DiscoveryLimits
+	responseTime time.Duration
+	successRate  float64
+}
+
+func newDiscoveryStats(limits DiscoveryLimits) *discoveryStats {
+	return &discoveryStats{limits: limits, successRate: 1}
+}
+
+func (s *discoveryStats) setLimits(limits DiscoveryLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+}
+
+func (s *discoveryStats) getLimits() DiscoveryLimits {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limits
+}
+
+// observe records the outcome of a GetOrchestrator request. The response time is only recorded if the
+// orchestrator responded or the request timed out, in which case elapsed is the request timeout.
+func (s *discoveryStats) observe(elapsed time.Duration, responded, timedOut, usable bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if responded || timedOut {
+		if s.responseTime == 0 {
+			s.responseTime = elapsed
+		} else {
+			s.responseTime = time.Duration((1-discoveryStatsAlpha)*float64(s.responseTime) + discoveryStatsAlpha*float64(elapsed))
+		}
+	}
+	success := 0.0
+	if usable {
+		success = 1
+	}
+	s.successRate = (1-discoveryStatsAlpha)*s.successRate + discoveryStatsAlpha*success
+}
+
+// parallelism returns the number of concurrent requests to use to find numOrchestrators in a pool of poolSize.
+// Enough requests are made to find twice the orchestrators needed given the past success rate, and at least
+// a quarter of the pool so that large pools are covered in a few rounds of requests.
+func (s *discoveryStats) parallelism(poolSize, numOrchestrators int) int {
+	s.mu.Lock()
+	successRate := s.successRate
+	limits := s.limits
+	s.mu.Unlock()
+
+	n := float64(2 * numOrchestrators)
+	if successRate > 0 {
+		n /= successRate
+	} else {
+		n = float64(poolSize)
+	}
+	n = math.Max(n, float64(poolSize/4))
+	n = math.Max(n, float64(limits.MinParallelism))
+	n = math.Min(n, float64(limits.MaxParallelism))
+	return int(math.Max(1, math.Min(math.Ceil(n), float64(poolSize))))
+}
+
+// cutoffTimeout returns how long to wait for the first orchestrators to respond, twice the average response time
+func (s *discoveryStats) cutoffTimeout() time.Duration {
+	return s.scaledResponseTime(2, false)
+}
+
+// requestTimeout returns how long to wait for a single orchestrator to respond, four times the average response time
+func (s *discoveryStats) requestTimeout() time.Duration {
+	return s.scaledResponseTime(4, true)
+}
+
+// scaledResponseTime returns factor times the average response time within the timeout limits, or the
+// max or min timeout, depending on unknownMax, if no response time has been recorded yet
+func (s *discoveryStats) scaledResponseTime(factor float64, unknownMax bool) time.Duration {
+	s.mu.Lock()
+	responseTime := s.responseTime
+	limits := s.limits
+	s.mu.Unlock()
+
+	if responseTime == 0 {
+		if unknownMax {
+			return limits.MaxTimeout
+		}
+		return limits.MinTimeout
+	}
+	timeout := time.Duration(factor * float64(responseTime))
+	if timeout < limits.MinTimeout {
+		return limits.MinTimeout
+	}
+	if timeout > limits.MaxTimeout {
+		return limits.MaxTimeout
+	}
+	return timeout
+}
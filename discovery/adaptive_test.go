@@ -0,0 +1,133 @@
+package discovery
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoveryStats_Parallelism(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newDiscoveryStats(DefaultDiscoveryLimits())
+	// at least the min parallelism, but not more than the pool size
+	assert.Equal(10, s.parallelism(20, 1))
+	assert.Equal(3, s.parallelism(3, 1))
+	assert.Equal(1, s.parallelism(0, 1))
+	// twice the orchestrators needed
+	assert.Equal(20, s.parallelism(40, 10))
+	// a quarter of large pools
+	assert.Equal(50, s.parallelism(200, 1))
+	// up to the max parallelism
+	assert.Equal(100, s.parallelism(1000, 1))
+
+	// more requests are made when fewer orchestrators are usable
+	for i := 0; i < 10; i++ {
+		s.observe(time.Second, true, false, false)
+	}
+	assert.Greater(s.parallelism(40, 10), 20)
+	for i := 0; i < 100; i++ {
+		s.observe(time.Second, false, false, false)
+	}
+	assert.Equal(40, s.parallelism(40, 10))
+
+	// the limits are configurable
+	s = newDiscoveryStats(DiscoveryLimits{MinParallelism: 3, MaxParallelism: 5})
+	assert.Equal(3, s.parallelism(8, 1))
+	assert.Equal(5, s.parallelism(1000, 1))
+}
+
+func TestDiscoveryStats_Timeouts(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newDiscoveryStats(DefaultDiscoveryLimits())
+	// no response times yet
+	assert.Equal(500*time.Millisecond, s.cutoffTimeout())
+	assert.Equal(6*time.Second, s.requestTimeout())
+
+	s.observe(time.Second, true, false, true)
+	assert.Equal(2*time.Second, s.cutoffTimeout())
+	assert.Equal(4*time.Second, s.requestTimeout())
+
+	// requests that failed without a response don't change the response time
+	s.observe(10*time.Millisecond, false, false, false)
+	assert.Equal(2*time.Second, s.cutoffTimeout())
+
+	// timed out requests increase it
+	s.observe(6*time.Second, false, true, false)
+	assert.Equal(4*time.Second, s.cutoffTimeout())
+	assert.Equal(6*time.Second, s.requestTimeout())
+
+	// fast responses are bounded by the min timeout
+	s = newDiscoveryStats(DefaultDiscoveryLimits())
+	s.observe(time.Millisecond, true, false, true)
+	assert.Equal(500*time.Millisecond, s.cutoffTimeout())
+	assert.Equal(500*time.Millisecond, s.requestTimeout())
+
+	// the limits are configurable
+	s.setLimits(DiscoveryLimits{MinTimeout: 100 * time.Millisecond, MaxTimeout: time.Second})
+	assert.Equal(100*time.Millisecond, s.cutoffTimeout())
+	s.observe(10*time.Second, false, true, false)
+	assert.Equal(time.Second, s.requestTimeout())
+}
+
+func TestOrchestratorPool_GetOrchestrators_Parallelism(t *testing.T) {
+	assert := assert.New(t)
+
+	oldOrchInfo := serverGetOrchInfo
+	var wg sync.WaitGroup
+	defer func() {
+		wg.Wait()
+		serverGetOrchInfo = oldOrchInfo
+	}()
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		defer wg.Done()
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return &net.OrchestratorInfo{Transcoder: orchestratorServer.String()}, nil
+	}
+
+	var addresses []string
+	for i := 0; i < 40; i++ {
+		addresses = append(addresses, "https://127.0.0.1:"+strconv.Itoa(8936+i))
+	}
+	pool := NewOrchestratorPool(nil, stringsToURIs(addresses), common.Score_Trusted)
+	limits := DefaultDiscoveryLimits()
+	limits.MinParallelism, limits.MaxParallelism = 2, 5
+	pool.SetDiscoveryLimits(limits)
+
+	wg.Add(len(addresses))
+	res, err := pool.GetOrchestrators(context.TODO(), 40, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+	assert.Nil(err)
+	assert.Len(res, 40)
+	assert.LessOrEqual(maxInFlight, 5)
+	assert.Greater(maxInFlight, 1)
+
+	// response times are recorded
+	pool.stats.mu.Lock()
+	assert.GreaterOrEqual(int64(pool.stats.responseTime), int64(5*time.Millisecond))
+	assert.Equal(1.0, pool.stats.successRate)
+	pool.stats.mu.Unlock()
+
+	// other pools keep the default limits
+	assert.Equal(DefaultDiscoveryLimits(), NewOrchestratorPool(nil, nil, common.Score_Trusted).stats.getLimits())
+}
@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -21,9 +22,10 @@ import (
 	"github.com/golang/glog"
 )
 
-var cacheRefreshInterval = 1 * time.Hour
+// CacheRefreshInterval is how often the DBOrchestratorPoolCache polls the info of the orchestrators in the DB
+var CacheRefreshInterval = 1 * time.Hour
 var getTicker = func() *time.Ticker {
-	return time.NewTicker(cacheRefreshInterval)
+	return time.NewTicker(CacheRefreshInterval)
 }
 
 type ticketParamsValidator interface {
@@ -36,6 +38,10 @@ type DBOrchestratorPoolCache struct {
 	ticketParamsValidator ticketParamsValidator
 	rm                    common.RoundsManager
 	bcast                 common.Broadcaster
+	// stats are shared by the pools created for every discovery
+	stats *discoveryStats
+	// refreshMu prevents on-demand refreshes from overlapping with each other and with the polls
+	refreshMu sync.Mutex
 }
 
 func NewDBOrchestratorPoolCache(ctx context.Context, node *core.LivepeerNode, rm common.RoundsManager) (*DBOrchestratorPoolCache, error) {
@@ -49,6 +55,7 @@ func NewDBOrchestratorPoolCache(ctx context.Context, node *core.LivepeerNode, rm
 		ticketParamsValidator: node.Sender,
 		rm:                    rm,
 		bcast:                 core.NewBroadcaster(node),
+		stats:                 newDiscoveryStats(DefaultDiscoveryLimits()),
 	}
 
 	if err := dbo.cacheTranscoderPool(); err != nil {
@@ -66,41 +73,42 @@ func NewDBOrchestratorPoolCache(ctx context.Context, node *core.LivepeerNode, rm
 	return dbo, nil
 }
 
-func (dbo *DBOrchestratorPoolCache) getURLs() ([]*url.URL, error) {
+// getInfos returns the orchestrators with a price up to maxPrice, with the addresses they are registered with
+func (dbo *DBOrchestratorPoolCache) getInfos(maxPrice *big.Rat) ([]common.OrchestratorLocalInfo, error) {
 	orchs, err := dbo.store.SelectOrchs(
 		&common.DBOrchFilter{
-			MaxPrice:       server.BroadcastCfg.MaxPrice(),
+			MaxPrice:       maxPrice,
 			CurrentRound:   dbo.nextRound(),
 			UpdatedLastDay: true,
+			Regions:        server.OrchRegions.RequiredRegions(),
 		},
 	)
 	if err != nil || len(orchs) <= 0 {
 		return nil, err
 	}
 
-	var uris []*url.URL
+	var infos []common.OrchestratorLocalInfo
 	for _, orch := range orchs {
 		if uri, err := url.Parse(orch.ServiceURI); err == nil {
-			uris = append(uris, uri)
+			addr := ethcommon.HexToAddress(orch.EthereumAddr)
+			infos = append(infos, common.OrchestratorLocalInfo{URL: uri, Score: common.Score_Untrusted, Address: &addr})
 		}
 	}
-	return uris, nil
+	return infos, nil
 }
 
 func (dbo *DBOrchestratorPoolCache) GetInfos() []common.OrchestratorLocalInfo {
-	uris, _ := dbo.getURLs()
-	infos := make([]common.OrchestratorLocalInfo, 0, len(uris))
-	for _, uri := range uris {
-		infos = append(infos, common.OrchestratorLocalInfo{URL: uri, Score: common.Score_Untrusted})
-	}
+	infos, _ := dbo.getInfos(server.BroadcastCfg.MaxPrice())
 	return infos
 }
 
 func (dbo *DBOrchestratorPoolCache) GetOrchestrators(ctx context.Context, numOrchestrators int, suspender common.Suspender, caps common.CapabilityComparator,
 	scorePred common.ScorePred) (common.OrchestratorDescriptors, error) {
 
-	uris, err := dbo.getURLs()
-	if err != nil || len(uris) <= 0 {
+	// check O's price against the max price for the capabilities required by the job
+	maxPrice := server.BroadcastCfg.MaxPriceForCapabilities(caps)
+	infos, err := dbo.getInfos(maxPrice)
+	if err != nil || len(infos) <= 0 {
 		return nil, err
 	}
 
@@ -110,16 +118,18 @@ func (dbo *DBOrchestratorPoolCache) GetOrchestrators(ctx context.Context, numOrc
 			return false
 		}
 
-		if err := dbo.ticketParamsValidator.ValidateTicketParams(pmTicketParams(info.TicketParams)); err != nil {
-			clog.V(common.DEBUG).Infof(ctx, "invalid ticket params orch=%v err=%q",
-				info.GetTranscoder(),
-				err,
-			)
-			return false
+		// Ticket params are not validated in read-only mode, in which the node does not send tickets
+		if dbo.ticketParamsValidator != nil {
+			if err := dbo.ticketParamsValidator.ValidateTicketParams(pmTicketParams(info.TicketParams)); err != nil {
+				clog.V(common.DEBUG).Infof(ctx, "invalid ticket params orch=%v err=%q",
+					info.GetTranscoder(),
+					err,
+				)
+				return false
+			}
 		}
 
 		// check if O's price is below B's max price
-		maxPrice := server.BroadcastCfg.MaxPrice()
 		price, err := common.RatPriceInfo(info.PriceInfo)
 		if err != nil {
 			clog.V(common.DEBUG).Infof(ctx, "invalid price info orch=%v err=%q", info.GetTranscoder(), err)
@@ -140,7 +150,7 @@ func (dbo *DBOrchestratorPoolCache) GetOrchestrators(ctx context.Context, numOrc
 		return true
 	}
 
-	orchPool := NewOrchestratorPoolWithPred(dbo.bcast, uris, pred, common.Score_Untrusted)
+	orchPool := &orchestratorPool{infos: infos, pred: pred, bcast: dbo.bcast, stats: dbo.stats}
 	orchInfos, err := orchPool.GetOrchestrators(ctx, numOrchestrators, suspender, caps, scorePred)
 	if err != nil || len(orchInfos) <= 0 {
 		return nil, err
@@ -149,12 +159,18 @@ func (dbo *DBOrchestratorPoolCache) GetOrchestrators(ctx context.Context, numOrc
 	return orchInfos, nil
 }
 
+// SetDiscoveryLimits sets the limits of the parallelism and timeouts of discovery
+func (dbo *DBOrchestratorPoolCache) SetDiscoveryLimits(limits DiscoveryLimits) {
+	dbo.stats.setLimits(limits)
+}
+
 func (dbo *DBOrchestratorPoolCache) Size() int {
 	count, _ := dbo.store.OrchCount(
 		&common.DBOrchFilter{
 			MaxPrice:       server.BroadcastCfg.MaxPrice(),
 			CurrentRound:   dbo.nextRound(),
 			UpdatedLastDay: true,
+			Regions:        server.OrchRegions.RequiredRegions(),
 		},
 	)
 	return count
@@ -248,9 +264,11 @@ func (dbo *DBOrchestratorPoolCache) pollOrchestratorInfo(ctx context.Context) er
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
+				dbo.refreshMu.Lock()
 				if err := dbo.cacheDBOrchs(); err != nil {
 					glog.Errorf("unable to poll orchestrator info: %v", err)
 				}
+				dbo.refreshMu.Unlock()
 			}
 		}
 	}()
@@ -258,6 +276,21 @@ func (dbo *DBOrchestratorPoolCache) pollOrchestratorInfo(ctx context.Context) er
 	return nil
 }
 
+// Refresh immediately caches the registered orchestrators, their stake and their info instead of waiting for the
+// next poll, so that newly registered orchestrators can be used right away
+func (dbo *DBOrchestratorPoolCache) Refresh() error {
+	dbo.refreshMu.Lock()
+	defer dbo.refreshMu.Unlock()
+
+	if err := dbo.cacheTranscoderPool(); err != nil {
+		return err
+	}
+	if err := dbo.cacheOrchestratorStake(); err != nil {
+		return err
+	}
+	return dbo.cacheDBOrchs()
+}
+
 func (dbo *DBOrchestratorPoolCache) cacheDBOrchs() error {
 	orchs, err := dbo.store.SelectOrchs(
 		&common.DBOrchFilter{
@@ -279,7 +312,7 @@ func (dbo *DBOrchestratorPoolCache) cacheDBOrchs() error {
 			return
 		}
 
-		info, err := serverGetOrchInfo(ctx, dbo.bcast, uri)
+		info, err := serverGetOrchInfo(ctx, dbo.bcast, uri, server.GetOrchestratorInfoParams{})
 		if err != nil {
 			errc <- err
 			return
@@ -309,6 +342,8 @@ func (dbo *DBOrchestratorPoolCache) cacheDBOrchs() error {
 			errc <- err
 			return
 		}
+		dbOrch.Region = info.GetRegion()
+		dbOrch.Datacenter = info.GetDatacenter()
 		resc <- dbOrch
 	}
 
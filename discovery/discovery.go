@@ -19,6 +19,8 @@ import (
 )
 
 var getOrchestratorsTimeoutLoop = 3 * time.Second
+
+// Default bounds of the time to wait for orchestrators to respond during discovery
 var getOrchestratorsCutoffTimeout = 500 * time.Millisecond
 var maxGetOrchestratorCutoffTimeout = 6 * time.Second
 
@@ -28,6 +30,7 @@ type orchestratorPool struct {
 	infos []common.OrchestratorLocalInfo
 	pred  func(info *net.OrchestratorInfo) bool
 	bcast common.Broadcaster
+	stats *discoveryStats
 }
 
 func NewOrchestratorPool(bcast common.Broadcaster, uris []*url.URL, score float32) *orchestratorPool {
@@ -39,7 +42,7 @@ func NewOrchestratorPool(bcast common.Broadcaster, uris []*url.URL, score float3
 	for _, uri := range uris {
 		infos = append(infos, common.OrchestratorLocalInfo{URL: uri, Score: score})
 	}
-	return &orchestratorPool{infos: infos, bcast: bcast}
+	return &orchestratorPool{infos: infos, bcast: bcast, stats: newDiscoveryStats(DefaultDiscoveryLimits())}
 }
 
 func NewOrchestratorPoolWithPred(bcast common.Broadcaster, addresses []*url.URL,
@@ -50,6 +53,11 @@ func NewOrchestratorPoolWithPred(bcast common.Broadcaster, addresses []*url.URL,
 	return pool
 }
 
+// SetDiscoveryLimits sets the limits of the parallelism and timeouts of discovery
+func (o *orchestratorPool) SetDiscoveryLimits(limits DiscoveryLimits) {
+	o.stats.setLimits(limits)
+}
+
 func (o *orchestratorPool) GetInfos() []common.OrchestratorLocalInfo {
 	return o.infos
 }
@@ -57,9 +65,11 @@ func (o *orchestratorPool) GetInfos() []common.OrchestratorLocalInfo {
 func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrators int, suspender common.Suspender, caps common.CapabilityComparator,
 	scorePred common.ScorePred) (common.OrchestratorDescriptors, error) {
 
+	// Requests may outlive discovery so they use the filters in place when it started
+	orchFilter, orchRegions := server.OrchFilter, server.OrchRegions
 	linfos := make([]*common.OrchestratorLocalInfo, 0, len(o.infos))
 	for i, _ := range o.infos {
-		if scorePred(o.infos[i].Score) {
+		if scorePred(o.infos[i].Score) && orchFilter.AllowsLocal(&o.infos[i]) {
 			linfos = append(linfos, &o.infos[i])
 		}
 	}
@@ -93,14 +103,61 @@ func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrator
 		}
 		return caps.CompatibleWith(info.Capabilities)
 	}
+	// Orchestrators that don't support the job's capabilities or charge more than the max price refuse
+	// the request early
+	params := server.DiscoveryParams(caps)
+	requestTimeout := o.stats.requestTimeout()
 	getOrchInfo := func(ctx context.Context, od common.OrchestratorDescriptor, infoCh chan common.OrchestratorDescriptor, errCh chan error) {
-		info, err := serverGetOrchInfo(ctx, o.bcast, od.LocalInfo.URL)
-		if err == nil && isCompatible(info) {
+		suits := func(info *net.OrchestratorInfo) bool {
+			return orchFilter.Allows(od.LocalInfo, info) && orchRegions.Allows(info) && isCompatible(info)
+		}
+		// Only the orchestrators known not to suit the job are cached and skipped, the others are always requested
+		// since each stream needs the credentials of its own session
+		var info *net.OrchestratorInfo
+		var err error
+		cacheKey := orchInfoCacheKey(od.LocalInfo.URL, params)
+		// Orchestrators that refused a request with a retry-after hint, e.g. because they are at capacity, are
+		// skipped for all jobs until then
+		retryKey := od.LocalInfo.URL.String()
+		skipped := false
+		if _, retryErr, ok := orchInfos.get(retryKey); ok {
+			err, skipped = retryErr, true
+		} else if OrchInfoCacheTTL > 0 {
+			if cachedInfo, cachedErr, ok := orchInfos.get(cacheKey); ok && (cachedErr != nil || !suits(cachedInfo)) {
+				info, err, skipped = cachedInfo, cachedErr, true
+			}
+		}
+		var elapsed time.Duration
+		timedOut := false
+		if !skipped {
+			start := time.Now()
+			reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+			info, err = serverGetOrchInfo(reqCtx, o.bcast, od.LocalInfo.URL, params)
+			elapsed = time.Since(start)
+			timedOut = reqCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+			cancel()
+			if retryAfter := server.RetryAfter(err); retryAfter > 0 {
+				orchInfos.set(retryKey, nil, err, retryAfter)
+			} else if OrchInfoCacheTTL > 0 && (server.IsDiscoveryMismatch(err) || err == nil && !suits(info)) {
+				orchInfos.set(cacheKey, info, err, OrchInfoCacheTTL)
+			}
+		}
+		mismatch := server.IsDiscoveryMismatch(err)
+		usable := err == nil && suits(info)
+		// Skipped orchestrators and requests interrupted because discovery is over say nothing about the orchestrator
+		if !skipped && (err == nil || ctx.Err() == nil) {
+			o.stats.observe(elapsed, err == nil || mismatch, timedOut, usable)
+		}
+		if usable {
 			od.RemoteInfo = info
 			infoCh <- od
 			return
 		}
-		if err != nil && !errors.Is(err, context.Canceled) {
+		if mismatch {
+			clog.V(common.DEBUG).Infof(ctx, "Orchestrator does not meet the job requirements orch=%v err=%q", od.LocalInfo.URL, err)
+		} else if skipped {
+			clog.V(common.DEBUG).Infof(ctx, "Skipping orchestrator until it can be retried orch=%v err=%q", od.LocalInfo.URL, err)
+		} else if err != nil && !errors.Is(err, context.Canceled) {
 			clog.Errorf(ctx, "err=%q", err)
 			if monitor.Enabled {
 				monitor.LogDiscoveryError(ctx, od.LocalInfo.URL.String(), err.Error())
@@ -116,15 +173,28 @@ func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrator
 	odCh := make(chan common.OrchestratorDescriptor, numAvailableOrchs)
 	errCh := make(chan error, numAvailableOrchs)
 
-	ctx, cancel := context.WithTimeout(clog.Clone(context.Background(), ctx), maxGetOrchestratorCutoffTimeout)
+	maxTimeout := o.stats.getLimits().MaxTimeout
+	ctx, cancel := context.WithTimeout(clog.Clone(context.Background(), ctx), maxTimeout)
 
 	// Shuffle and create O descriptor
+	reqCh := make(chan common.OrchestratorDescriptor, numAvailableOrchs)
 	for _, i := range rand.Perm(numAvailableOrchs) {
-		go getOrchInfo(ctx, common.OrchestratorDescriptor{linfos[i], nil}, odCh, errCh)
+		reqCh <- common.OrchestratorDescriptor{linfos[i], nil}
+	}
+	close(reqCh)
+	// Bound the number of concurrent requests. Requests still queued once discovery is over
+	// are made with the canceled context and return right away.
+	parallelism := o.stats.parallelism(numAvailableOrchs, numOrchestrators)
+	for i := 0; i < parallelism; i++ {
+		go func() {
+			for od := range reqCh {
+				getOrchInfo(ctx, od, odCh, errCh)
+			}
+		}()
 	}
 
 	// try to wait for orchestrators until at least 1 is found (with the exponential backoff timout)
-	timeout := getOrchestratorsCutoffTimeout
+	timeout := o.stats.cutoffTimeout()
 	timer := time.NewTimer(timeout)
 
 	for nbResp < numAvailableOrchs && len(ods) < numOrchestrators && !timedOut {
@@ -146,8 +216,8 @@ func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrator
 			// At this point we already waited timeout, so need to wait another timeout to make it the increased 2 * timeout
 			timer.Reset(timeout)
 			timeout *= 2
-			if timeout > maxGetOrchestratorCutoffTimeout {
-				timeout = maxGetOrchestratorCutoffTimeout
+			if timeout > maxTimeout {
+				timeout = maxTimeout
 			}
 			clog.V(common.DEBUG).Infof(ctx, "No orchestrators found, increasing discovery timeout to %s", timeout)
 		case <-ctx.Done():
@@ -164,8 +234,8 @@ func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrator
 		}
 	}
 
-	clog.Infof(ctx, "Done fetching orch info numOrch=%d responses=%d/%d timedOut=%t",
-		len(ods), nbResp, len(linfos), timedOut)
+	clog.Infof(ctx, "Done fetching orch info numOrch=%d responses=%d/%d timedOut=%t parallelism=%d requestTimeout=%s",
+		len(ods), nbResp, len(linfos), timedOut, parallelism, requestTimeout)
 	return ods, nil
 }
 
@@ -4,16 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
+	gonet "net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/golang/glog"
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/core"
@@ -55,7 +61,7 @@ func TestDeadLock(t *testing.T) {
 	first := true
 	oldOrchInfo := serverGetOrchInfo
 	defer func() { wg.Wait(); serverGetOrchInfo = oldOrchInfo }()
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		defer wg.Done()
 		if first {
@@ -87,7 +93,7 @@ func TestDeadLock_NewOrchestratorPoolWithPred(t *testing.T) {
 	first := true
 	oldOrchInfo := serverGetOrchInfo
 	defer func() { wg.Wait(); serverGetOrchInfo = oldOrchInfo }()
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		defer wg.Done()
 		if first {
@@ -179,6 +185,175 @@ func TestDBOrchestratorPoolCacheSize(t *testing.T) {
 	assert.Equal(len(addresses), nonEmptyPool.Size())
 }
 
+func TestDBOrchestratorPoolCache_Refresh(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	oldOrchInfo := serverGetOrchInfo
+	defer func() { serverGetOrchInfo = oldOrchInfo }()
+	var mu sync.Mutex
+	var polled []string
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		polled = append(polled, orchestratorServer.String())
+		return &net.OrchestratorInfo{
+			Address:    pm.RandBytes(20),
+			Transcoder: orchestratorServer.String(),
+			PriceInfo:  &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1},
+		}, nil
+	}
+
+	ethClient := &eth.StubClient{TotalStake: big.NewInt(0)}
+	node := &core.LivepeerNode{
+		Database: dbh,
+		Eth:      ethClient,
+		Sender:   &pm.MockSender{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool, err := NewDBOrchestratorPoolCache(ctx, node, &stubRoundsManager{})
+	require.NoError(err)
+	assert.Equal(0, pool.Size())
+
+	// Orchestrators registered after startup are cached and polled on refresh
+	addresses := []string{"https://127.0.0.1:8936", "https://127.0.0.1:8937"}
+	ethClient.Orchestrators = StubOrchestrators(addresses)
+	require.NoError(pool.Refresh())
+	assert.Equal(len(addresses), pool.Size())
+	mu.Lock()
+	assert.ElementsMatch(addresses, polled)
+	mu.Unlock()
+
+	// The error of the transcoder pool query is returned
+	ethClient.TranscoderPoolError = errors.New("TranscoderPool error")
+	assert.EqualError(pool.Refresh(), "Could not refresh DB list of orchestrators: TranscoderPool error")
+}
+
+func TestDBOrchestratorPoolCache_Regions(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	regions := map[string]string{"https://127.0.0.1:8936": "us-east", "https://127.0.0.1:8937": "eu-west"}
+	oldOrchInfo := serverGetOrchInfo
+	defer func() { serverGetOrchInfo = oldOrchInfo }()
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		return &net.OrchestratorInfo{
+			Address:    pm.RandBytes(20),
+			Transcoder: orchestratorServer.String(),
+			PriceInfo:  &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1},
+			Region:     regions[orchestratorServer.String()],
+			Datacenter: "dc-" + regions[orchestratorServer.String()],
+		}, nil
+	}
+
+	node := &core.LivepeerNode{
+		Database: dbh,
+		Eth:      &eth.StubClient{Orchestrators: StubOrchestrators([]string{"https://127.0.0.1:8936", "https://127.0.0.1:8937", "https://127.0.0.1:8938"})},
+		Sender:   &pm.MockSender{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool, err := NewDBOrchestratorPoolCache(ctx, node, &stubRoundsManager{})
+	require.NoError(err)
+
+	// the region and datacenter advertised by orchestrators are cached
+	orchs, err := dbh.SelectOrchs(nil)
+	require.Nil(err)
+	require.Len(orchs, 3)
+	for _, o := range orchs {
+		assert.Equal(regions[o.ServiceURI], o.Region)
+		if o.Region != "" {
+			assert.Equal("dc-"+o.Region, o.Datacenter)
+		}
+	}
+
+	// preferred regions don't exclude orchestrators from discovery
+	defer func() { server.OrchRegions = nil }()
+	server.OrchRegions = server.NewRegionSelection([]string{"US-East"}, false)
+	assert.Equal(3, pool.Size())
+
+	// orchestrators in other regions are not queried during discovery if a region is required
+	server.OrchRegions = server.NewRegionSelection([]string{"US-East"}, true)
+	assert.Equal(1, pool.Size())
+	infos := pool.GetInfos()
+	require.Len(infos, 1)
+	assert.Equal("https://127.0.0.1:8936", infos[0].URL.String())
+}
+
+func TestDBOrchestratorPoolCache_OrchFilter(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	uris := []string{"https://127.0.0.1:8936", "https://127.0.0.1:8937", "https://127.0.0.1:8938"}
+	registered := func(uri string) ethcommon.Address {
+		return ethcommon.BytesToAddress([]byte(uri))
+	}
+	// orchestrators report addresses other than the ones registered for their URIs
+	reported := map[string]ethcommon.Address{
+		uris[0]: pm.RandAddress(),
+		uris[1]: pm.RandAddress(),
+		uris[2]: registered(uris[0]),
+	}
+	oldOrchInfo := serverGetOrchInfo
+	defer func() { serverGetOrchInfo = oldOrchInfo }()
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		return &net.OrchestratorInfo{
+			Address:    reported[orchestratorServer.String()].Bytes(),
+			Transcoder: orchestratorServer.String(),
+			PriceInfo:  &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1},
+		}, nil
+	}
+
+	sender := &pm.MockSender{}
+	sender.On("ValidateTicketParams", mock.Anything).Return(nil)
+	node := &core.LivepeerNode{
+		Database: dbh,
+		Eth:      &eth.StubClient{Orchestrators: StubOrchestrators(uris)},
+		Sender:   sender,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool, err := NewDBOrchestratorPoolCache(ctx, node, &stubRoundsManager{})
+	require.NoError(err)
+	for _, info := range pool.GetInfos() {
+		require.NotNil(info.Address)
+		assert.Equal(registered(info.URL.String()), *info.Address)
+	}
+
+	defer func() { server.OrchFilter = nil }()
+	getOrchs := func() []string {
+		infos, err := pool.GetOrchestrators(context.TODO(), len(uris), newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+		require.Nil(err)
+		return transcoders(infos)
+	}
+
+	// blocked by the registered address, whatever the orchestrator reports
+	server.OrchFilter, err = server.NewOrchestratorFilter(nil, []string{registered(uris[1]).Hex()})
+	require.Nil(err)
+	assert.ElementsMatch([]string{uris[0], uris[2]}, getOrchs())
+
+	// reporting an allowed address doesn't get an orchestrator on the allowlist
+	server.OrchFilter, err = server.NewOrchestratorFilter([]string{registered(uris[0]).Hex()}, nil)
+	require.Nil(err)
+	assert.Equal([]string{uris[0]}, getOrchs())
+}
+
 func TestNewDBOrchestorPoolCache_NoEthAddress(t *testing.T) {
 	require := require.New(t)
 	assert := assert.New(t)
@@ -186,7 +361,7 @@ func TestNewDBOrchestorPoolCache_NoEthAddress(t *testing.T) {
 	oldServerGetOrchInfo := serverGetOrchInfo
 	defer func() { serverGetOrchInfo = oldServerGetOrchInfo }()
 	var mu sync.Mutex
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		defer mu.Unlock()
 
@@ -243,7 +418,7 @@ func TestNewDBOrchestratorPoolCache_InvalidPrices(t *testing.T) {
 	oldServerGetOrchInfo := serverGetOrchInfo
 	defer func() { serverGetOrchInfo = oldServerGetOrchInfo }()
 	var mu sync.Mutex
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		defer mu.Unlock()
 
@@ -293,7 +468,7 @@ func TestNewDBOrchestratorPoolCache_GivenListOfOrchs_CreatesPoolCacheCorrectly(t
 	expPricePerPixel, _ := common.PriceToFixed(big.NewRat(999, 1))
 	var mu sync.Mutex
 	first := true
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		if first {
 			time.Sleep(100 * time.Millisecond)
@@ -385,7 +560,7 @@ func TestNewDBOrchestratorPoolCache_TestURLs(t *testing.T) {
 
 	var mu sync.Mutex
 	first := true
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		if first {
 			time.Sleep(100 * time.Millisecond)
@@ -478,7 +653,7 @@ func TestNewDBOrchestorPoolCache_PollOrchestratorInfo(t *testing.T) {
 	wg := sync.WaitGroup{}
 	oldOrchInfo := serverGetOrchInfo
 	defer func() { wg.Wait(); serverGetOrchInfo = oldOrchInfo }()
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		defer mu.Unlock()
 		// slightly unsafe to be adding to the wg counter here
@@ -527,9 +702,9 @@ func TestNewDBOrchestorPoolCache_PollOrchestratorInfo(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	origCacheRefreshInterval := cacheRefreshInterval
-	cacheRefreshInterval = 200 * time.Millisecond
-	defer func() { cacheRefreshInterval = origCacheRefreshInterval }()
+	origCacheRefreshInterval := CacheRefreshInterval
+	CacheRefreshInterval = 200 * time.Millisecond
+	defer func() { CacheRefreshInterval = origCacheRefreshInterval }()
 	pool, err := NewDBOrchestratorPoolCache(ctx, node, &stubRoundsManager{})
 	require.NoError(err)
 
@@ -633,7 +808,7 @@ func TestCachedPool_AllOrchestratorsTooExpensive_ReturnsEmptyList(t *testing.T)
 	defer runtime.GOMAXPROCS(gmp)
 	var mu sync.Mutex
 	first := true
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		if first {
 			time.Sleep(100 * time.Millisecond)
@@ -721,7 +896,7 @@ func TestCachedPool_GetOrchestrators_MaxBroadcastPriceNotSet(t *testing.T) {
 	defer runtime.GOMAXPROCS(gmp)
 	var mu sync.Mutex
 	first := true
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		if first {
 			time.Sleep(100 * time.Millisecond)
@@ -803,6 +978,52 @@ func TestCachedPool_GetOrchestrators_MaxBroadcastPriceNotSet(t *testing.T) {
 	assert.Len(infos, 50)
 }
 
+func TestCachedPool_GetOrchestrators_ReadOnly(t *testing.T) {
+	expPriceInfo := &net.PriceInfo{
+		PricePerUnit:  1,
+		PixelsPerUnit: 1,
+	}
+	server.BroadcastCfg.SetMaxPrice(nil)
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		return &net.OrchestratorInfo{
+			Address:      pm.RandBytes(20),
+			Transcoder:   orchestratorServer.String(),
+			PriceInfo:    expPriceInfo,
+			TicketParams: &net.TicketParams{},
+		}, nil
+	}
+
+	addresses := []string{}
+	for i := 0; i < 5; i++ {
+		addresses = append(addresses, "https://127.0.0.1:"+strconv.Itoa(8936+i))
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	defer dbh.Close()
+	defer dbraw.Close()
+	require.Nil(err)
+
+	// A read-only broadcaster has no sender, so the ticket params of the orchestrators are not validated
+	node := &core.LivepeerNode{
+		Database: dbh,
+		Eth: &eth.StubClient{
+			Orchestrators: StubOrchestrators(addresses),
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool, err := NewDBOrchestratorPoolCache(ctx, node, &stubRoundsManager{})
+	require.NoError(err)
+
+	oinfos, err := pool.GetOrchestrators(context.TODO(), 5, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+	assert.Nil(err)
+	assert.Len(oinfos, 5)
+}
+
 func TestCachedPool_N_OrchestratorsGoodPricing_ReturnsNOrchestrators(t *testing.T) {
 	// Test setup
 	goodTranscoder := &net.OrchestratorInfo{
@@ -827,7 +1048,7 @@ func TestCachedPool_N_OrchestratorsGoodPricing_ReturnsNOrchestrators(t *testing.
 	defer runtime.GOMAXPROCS(gmp)
 	var mu sync.Mutex
 	first := true
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		if first {
 			time.Sleep(100 * time.Millisecond)
@@ -925,7 +1146,7 @@ func TestCachedPool_GetOrchestrators_TicketParamsValidation(t *testing.T) {
 
 	server.BroadcastCfg.SetMaxPrice(nil)
 
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		return &net.OrchestratorInfo{
 			Address:      pm.RandBytes(20),
 			Transcoder:   "transcoder",
@@ -999,7 +1220,7 @@ func TestCachedPool_GetOrchestrators_OnlyActiveOrchestrators(t *testing.T) {
 	defer runtime.GOMAXPROCS(gmp)
 	var mu sync.Mutex
 	first := true
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		if first {
 			time.Sleep(100 * time.Millisecond)
@@ -1096,18 +1317,21 @@ func TestNewWHOrchestratorPoolCache(t *testing.T) {
 	// mock webhook and orchestrator info request
 	addresses := []string{"https://127.0.0.1:8936", "https://127.0.0.1:8937", "https://127.0.0.1:8938"}
 
-	getURLsfromWebhook = func(cbUrl *url.URL) ([]byte, error) {
+	oldGetWebhookPage := getWebhookPage
+	defer func() { getWebhookPage = oldGetWebhookPage }()
+	getWebhookPage = func(pageURL *url.URL, etag string) (*webhookPage, error) {
 		var wh []webhookResponse
 		for _, addr := range addresses {
 			wh = append(wh, webhookResponse{Address: addr})
 		}
-		return json.Marshal(&wh)
+		body, err := json.Marshal(&wh)
+		return &webhookPage{body: body}, err
 	}
 
 	wg := sync.WaitGroup{}
 	oldOrchInfo := serverGetOrchInfo
 	defer func() { wg.Wait(); serverGetOrchInfo = oldOrchInfo }()
-	serverGetOrchInfo = func(c context.Context, b common.Broadcaster, s *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(c context.Context, b common.Broadcaster, s *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		defer wg.Done()
 		return &net.OrchestratorInfo{Transcoder: "transcoder"}, nil
 	}
@@ -1199,40 +1423,153 @@ func TestNewWHOrchestratorPoolCache(t *testing.T) {
 	}
 }
 
+func TestWebhookPool_PaginationAndConditionalRequests(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var mu sync.Mutex
+	pages := map[string][]string{
+		"":  {"https://127.0.0.1:8936", "https://127.0.0.1:8937"},
+		"2": {"https://127.0.0.1:8938"},
+	}
+	requests := make(map[string]int)
+	notModified := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		page := r.URL.Query().Get("page")
+		requests[page]++
+
+		var wh []webhookResponse
+		for _, addr := range pages[page] {
+			wh = append(wh, webhookResponse{Address: addr})
+		}
+		body, _ := json.Marshal(&wh)
+		etag := fmt.Sprintf(`"%x"`, crypto.Keccak256(body))
+		if r.Header.Get("If-None-Match") == etag {
+			notModified[page]++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if page == "" {
+			w.Header().Set("Link", `</api/orchestrator?page=2>; rel="next"`)
+		}
+		w.Header().Set("ETag", etag)
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	whURL, err := url.ParseRequestURI(ts.URL + "/api/orchestrator")
+	require.Nil(err)
+	whpool := &webhookPool{callback: whURL, mu: &sync.RWMutex{}, stats: newDiscoveryStats(DefaultDiscoveryLimits())}
+
+	urls := func(infos []common.OrchestratorLocalInfo) []string {
+		var urls []string
+		for _, info := range infos {
+			urls = append(urls, info.URL.String())
+		}
+		return urls
+	}
+
+	// the orchestrators of all pages are returned
+	infos, err := whpool.getInfos()
+	require.Nil(err)
+	assert.Equal([]string{"https://127.0.0.1:8936", "https://127.0.0.1:8937", "https://127.0.0.1:8938"}, urls(infos))
+	assert.Equal(map[string]int{"": 1, "2": 1}, requests)
+
+	// unchanged pages are not downloaded again
+	whpool.lastRequest = time.Time{}
+	infos, err = whpool.getInfos()
+	require.Nil(err)
+	assert.Len(infos, 3)
+	assert.Equal(map[string]int{"": 1, "2": 1}, notModified)
+
+	// only the changed pages are downloaded
+	mu.Lock()
+	pages["2"] = []string{"https://127.0.0.1:8939"}
+	mu.Unlock()
+	whpool.lastRequest = time.Time{}
+	infos, err = whpool.getInfos()
+	require.Nil(err)
+	assert.Equal([]string{"https://127.0.0.1:8936", "https://127.0.0.1:8937", "https://127.0.0.1:8939"}, urls(infos))
+	assert.Equal(map[string]int{"": 3, "2": 3}, requests)
+	assert.Equal(map[string]int{"": 2, "2": 1}, notModified)
+}
+
+func TestWebhookPool_PaginationLoop(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `<`+r.URL.Path+`>; rel="next"`)
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	whURL, _ := url.ParseRequestURI(ts.URL + "/api/orchestrator")
+	whpool := &webhookPool{callback: whURL, mu: &sync.RWMutex{}, stats: newDiscoveryStats(DefaultDiscoveryLimits())}
+	_, err := whpool.getInfos()
+	assert.EqualError(err, fmt.Sprintf("webhook page %v was already fetched", whURL))
+}
+
+func TestNextPageLink(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("", nextPageLink(nil))
+	assert.Equal("https://example.com/?page=2", nextPageLink([]string{`<https://example.com/?page=2>; rel="next"`}))
+	assert.Equal("/?page=3", nextPageLink([]string{`</?page=1>; rel="prev", </?page=3>; rel="next"`}))
+	assert.Equal("/?page=3", nextPageLink([]string{`</?page=1>; rel=first`, `</?page=3>; REL="last next"`}))
+	assert.Equal("", nextPageLink([]string{`</?page=1>; rel="prev"`, `/?page=3; rel="next"`}))
+}
+
 func TestDeserializeWebhookJSON(t *testing.T) {
 	assert := assert.New(t)
 
 	// assert input of webhookResponse address object returns correct address
 	resp, _ := json.Marshal(&[]webhookResponse{{Address: "https://127.0.0.1:8936"}})
-	urls, err := deserializeWebhookJSON(resp)
+	urls, _, err := deserializeWebhookJSON(resp)
 	assert.Nil(err)
 	assert.Equal("https://127.0.0.1:8936", urls[0].URL.String())
 
 	// assert input of empty byte array returns JSON error
-	urls, err = deserializeWebhookJSON([]byte{})
+	urls, _, err = deserializeWebhookJSON([]byte{})
 	assert.Contains(err.Error(), "unexpected end of JSON input")
 	assert.Nil(urls)
 
 	// assert input of empty byte array returns empty object
 	resp, _ = json.Marshal(&[]webhookResponse{{}})
-	urls, err = deserializeWebhookJSON(resp)
+	urls, _, err = deserializeWebhookJSON(resp)
 	assert.Nil(err)
 	assert.Empty(urls)
 
 	// assert input of invalid addresses returns invalid JSON error
-	urls, err = deserializeWebhookJSON(make([]byte, 64))
+	urls, _, err = deserializeWebhookJSON(make([]byte, 64))
 	assert.Contains(err.Error(), "invalid character")
 	assert.Empty(urls)
 
 	// assert input of invalid JSON returns JSON unmarshal object error
-	urls, err = deserializeWebhookJSON([]byte(`{"name":false}`))
+	urls, _, err = deserializeWebhookJSON([]byte(`{"name":false}`))
 	assert.Contains(err.Error(), "cannot unmarshal object")
 	assert.Empty(urls)
 
 	// assert input of invalid JSON returns JSON unmarshal number error
-	urls, err = deserializeWebhookJSON([]byte(`1112`))
+	urls, _, err = deserializeWebhookJSON([]byte(`1112`))
 	assert.Contains(err.Error(), "cannot unmarshal number")
 	assert.Empty(urls)
+
+	// assert cert fingerprints are returned as pins and addresses with invalid fingerprints are skipped
+	fp := "AB:CD:" + strings.Repeat("00:", 29) + "EF"
+	resp, _ = json.Marshal(&[]webhookResponse{
+		{Address: "https://127.0.0.1:8936", CertFingerprint: fp},
+		{Address: "https://127.0.0.1:8937"},
+		{Address: "https://127.0.0.1:8938", CertFingerprint: "foo"},
+	})
+	urls, pins, err := deserializeWebhookJSON(resp)
+	assert.Nil(err)
+	assert.Len(urls, 2)
+	assert.Equal("https://127.0.0.1:8937", urls[1].URL.String())
+	assert.Len(pins, 1)
+	assert.Equal("https://127.0.0.1:8936", pins[0].URI.String())
+	assert.Equal("abcd"+strings.Repeat("00", 29)+"ef", pins[0].Fingerprint.String())
 }
 
 func TestEthOrchToDBOrch(t *testing.T) {
@@ -1269,7 +1606,7 @@ func TestOrchestratorPool_GetOrchestrators(t *testing.T) {
 	orchCb := func() error { return nil }
 	oldOrchInfo := serverGetOrchInfo
 	defer func() { wg.Wait(); serverGetOrchInfo = oldOrchInfo }()
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		defer wg.Done()
 		err := orchCb()
 		return &net.OrchestratorInfo{
@@ -1334,7 +1671,7 @@ func TestOrchestratorPool_GetOrchestrators_SuspendedOrchs(t *testing.T) {
 	orchCb := func() error { return nil }
 	oldOrchInfo := serverGetOrchInfo
 	defer func() { wg.Wait(); serverGetOrchInfo = oldOrchInfo }()
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		defer wg.Done()
 		err := orchCb()
 		return &net.OrchestratorInfo{
@@ -1406,7 +1743,7 @@ func TestOrchestratorPool_ShuffleGetOrchestrators(t *testing.T) {
 
 	oldOrchInfo := serverGetOrchInfo
 	defer func() { serverGetOrchInfo = oldOrchInfo }()
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		ch <- server
 		return &net.OrchestratorInfo{Transcoder: server.String()}, nil
 	}
@@ -1469,7 +1806,7 @@ func TestOrchestratorPool_GetOrchestratorTimeout(t *testing.T) {
 	ch := make(chan struct{})
 	oldOrchInfo := serverGetOrchInfo
 	defer func() { serverGetOrchInfo = oldOrchInfo }()
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		ch <- struct{}{} // this will block if necessary to simulate a timeout
 		return &net.OrchestratorInfo{}, nil
 	}
@@ -1582,7 +1919,7 @@ func TestOrchestratorPool_Capabilities(t *testing.T) {
 	calls := 0
 	oldOrchInfo := serverGetOrchInfo
 	defer func() { serverGetOrchInfo = oldOrchInfo }()
-	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL) (*net.OrchestratorInfo, error) {
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, server *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		mu.Lock()
 		defer func() {
 			calls = (calls + 1) % len(responses)
@@ -1619,3 +1956,606 @@ func TestOrchestratorPool_Capabilities(t *testing.T) {
 	assert.Len(infos, 1)
 	assert.Equal(i4, infos[0].RemoteInfo)
 }
+
+func TestOrchestratorPool_JobConstraints(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer server.BroadcastCfg.SetMaxPrice(nil)
+	server.BroadcastCfg.SetMaxPrice(big.NewRat(3, 1))
+
+	info := &net.OrchestratorInfo{Transcoder: "https://o1.com", Capabilities: &net.Capabilities{Bitstring: capCompatString}}
+	addresses := stringsToURIs([]string{"https://o1.com", "https://o2.com", "https://o3.com"})
+	pool := NewOrchestratorPool(nil, addresses, common.Score_Trusted)
+
+	var mu sync.Mutex
+	var reqParams []server.GetOrchestratorInfoParams
+	oldOrchInfo := serverGetOrchInfo
+	defer func() { serverGetOrchInfo = oldOrchInfo }()
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchURL *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		mu.Lock()
+		reqParams = append(reqParams, params)
+		mu.Unlock()
+		switch orchURL.Host {
+		case "o2.com":
+			return nil, fmt.Errorf("Could not get orchestrator orch=%v: %w", orchURL, server.ErrDiscoveryCapabilities)
+		case "o3.com":
+			return nil, fmt.Errorf("Could not get orchestrator orch=%v: %w", orchURL, server.ErrDiscoveryPrice)
+		}
+		return info, nil
+	}
+
+	caps := newStubCapabilities()
+	caps.isLegacy = false
+	infos, err := pool.GetOrchestrators(context.TODO(), len(addresses), newStubSuspender(), caps, common.ScoreAtLeast(0))
+	require.Nil(err)
+	require.Len(infos, 1)
+	assert.Equal(info, infos[0].RemoteInfo)
+
+	// The job's capabilities and max price are sent to every orchestrator
+	require.Len(reqParams, len(addresses))
+	for _, params := range reqParams {
+		assert.Equal(caps.ToNetCapabilities(), params.Caps)
+		assert.Equal(big.NewRat(3, 1), params.MaxPrice)
+	}
+}
+
+func TestOrchestratorPool_OrchFilter(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	addresses := stringsToURIs([]string{"https://127.0.0.1:8936", "https://127.0.0.1:8937", "https://127.0.0.1:8938"})
+	blockedAddr := ethcommon.Address{1}
+
+	mu := &sync.Mutex{}
+	fetched := make(map[string]bool)
+	oldOrchInfo := serverGetOrchInfo
+	defer func() { serverGetOrchInfo = oldOrchInfo }()
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchURL *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		mu.Lock()
+		fetched[orchURL.String()] = true
+		mu.Unlock()
+		info := &net.OrchestratorInfo{Transcoder: orchURL.String()}
+		if orchURL.Port() == "8937" {
+			info.Address = blockedAddr.Bytes()
+		}
+		return info, nil
+	}
+	defer func() { server.OrchFilter = nil }()
+	pool := NewOrchestratorPool(nil, addresses, common.Score_Trusted)
+
+	// blocked by address
+	filter, err := server.NewOrchestratorFilter(nil, []string{blockedAddr.Hex()})
+	require.Nil(err)
+	server.OrchFilter = filter
+	infos, err := pool.GetOrchestrators(context.TODO(), len(addresses), newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+	assert.Nil(err)
+	assert.ElementsMatch([]string{"https://127.0.0.1:8936", "https://127.0.0.1:8938"}, transcoders(infos))
+
+	// allowed by URI, others are not even fetched
+	filter, err = server.NewOrchestratorFilter([]string{"127.0.0.1:8938"}, nil)
+	require.Nil(err)
+	server.OrchFilter = filter
+	fetched = make(map[string]bool)
+	infos, err = pool.GetOrchestrators(context.TODO(), len(addresses), newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+	assert.Nil(err)
+	assert.Equal([]string{"https://127.0.0.1:8938"}, transcoders(infos))
+	assert.Equal(map[string]bool{"https://127.0.0.1:8938": true}, fetched)
+}
+
+func transcoders(ods common.OrchestratorDescriptors) []string {
+	var res []string
+	for _, info := range ods.GetRemoteInfos() {
+		res = append(res, info.Transcoder)
+	}
+	return res
+}
+
+type stubENSResolver struct {
+	mu   sync.Mutex
+	urls map[string]string
+}
+
+func (r *stubENSResolver) Text(name, key string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key != eth.ENSURLKey {
+		return "", errors.New("unexpected key")
+	}
+	u, ok := r.urls[name]
+	if !ok {
+		return "", errors.New("no record")
+	}
+	return u, nil
+}
+
+func (r *stubENSResolver) set(name, u string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.urls[name] = u
+}
+
+func TestENSOrchestratorPool(t *testing.T) {
+	assert := assert.New(t)
+	resolver := &stubENSResolver{urls: map[string]string{"a.eth": "https://127.0.0.1:8936"}}
+	static := stringsToURIs([]string{"https://127.0.0.1:8935"})
+
+	pool := NewENSOrchestratorPool(nil, static, []string{"a.eth", "b.eth"}, resolver, time.Hour, common.Score_Trusted)
+	urls := func() []string {
+		var res []string
+		for _, info := range pool.GetInfos() {
+			res = append(res, info.URL.String())
+		}
+		return res
+	}
+	// Names that can't be resolved are skipped
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8936"}, urls())
+	assert.Equal(2, pool.Size())
+	assert.Equal(2, pool.SizeWith(common.ScoreAtLeast(common.Score_Trusted)))
+
+	// Names are not re-resolved before the refresh interval elapsed
+	resolver.set("b.eth", "https://127.0.0.1:8937")
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8936"}, urls())
+
+	pool.refreshInterval = 0
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8936", "https://127.0.0.1:8937"}, urls())
+
+	// The last URI of a name is kept when it can't be resolved anymore
+	resolver.set("a.eth", "https://127.0.0.1:9000")
+	delete(resolver.urls, "b.eth")
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://127.0.0.1:9000", "https://127.0.0.1:8937"}, urls())
+
+	defer func() { serverGetOrchInfo = server.GetOrchestratorInfo }()
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchURL *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		return &net.OrchestratorInfo{Transcoder: orchURL.String()}, nil
+	}
+	infos, err := pool.GetOrchestrators(context.TODO(), 3, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+	assert.Nil(err)
+	assert.ElementsMatch([]string{"https://127.0.0.1:8935", "https://127.0.0.1:9000", "https://127.0.0.1:8937"}, transcoders(infos))
+}
+
+func TestSRVOrchestratorPool(t *testing.T) {
+	assert := assert.New(t)
+	var mu sync.Mutex
+	records := map[string][]*gonet.SRV{
+		"_livepeer._tcp.example.com": {
+			{Target: "o1.example.com.", Port: 8935},
+			{Target: "o2.example.com.", Port: 8936},
+		},
+	}
+	origLookupSRV := lookupSRV
+	defer func() { lookupSRV = origLookupSRV }()
+	lookupSRV = func(name string) ([]*gonet.SRV, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		addrs, ok := records[name]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return addrs, nil
+	}
+	static := stringsToURIs([]string{"https://127.0.0.1:8935", "https://o1.example.com:8935"})
+
+	pool := NewSRVOrchestratorPool(nil, static, []string{"srv://_livepeer._tcp.example.com", "srv://_livepeer._tcp.other.com"}, time.Hour, common.Score_Trusted)
+	urls := func() []string {
+		var res []string
+		for _, info := range pool.GetInfos() {
+			res = append(res, info.URL.String())
+		}
+		return res
+	}
+	// Names that can't be resolved are skipped and duplicate URIs are removed
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://o1.example.com:8935", "https://o2.example.com:8936"}, urls())
+	assert.Equal(3, pool.Size())
+	assert.Equal(3, pool.SizeWith(common.ScoreAtLeast(common.Score_Trusted)))
+
+	// Names are not re-resolved before the refresh interval elapsed
+	mu.Lock()
+	records["_livepeer._tcp.other.com"] = []*gonet.SRV{{Target: "o3.other.com.", Port: 8935}}
+	mu.Unlock()
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://o1.example.com:8935", "https://o2.example.com:8936"}, urls())
+
+	pool.refreshInterval = 0
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://o1.example.com:8935", "https://o2.example.com:8936", "https://o3.other.com:8935"}, urls())
+
+	// Removed records are dropped, and the last targets of a name are kept when it can't be resolved anymore
+	mu.Lock()
+	records["_livepeer._tcp.example.com"] = []*gonet.SRV{{Target: "o2.example.com.", Port: 8936}}
+	delete(records, "_livepeer._tcp.other.com")
+	mu.Unlock()
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://o1.example.com:8935", "https://o2.example.com:8936", "https://o3.other.com:8935"}, urls())
+
+	defer func() { serverGetOrchInfo = server.GetOrchestratorInfo }()
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchURL *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		return &net.OrchestratorInfo{Transcoder: orchURL.String()}, nil
+	}
+	infos, err := pool.GetOrchestrators(context.TODO(), 4, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+	assert.Nil(err)
+	assert.Len(infos, 4)
+}
+
+func TestResolveSRVURLs(t *testing.T) {
+	assert := assert.New(t)
+	origLookupSRV := lookupSRV
+	defer func() { lookupSRV = origLookupSRV }()
+	var lookedUp string
+	lookupSRV = func(name string) ([]*gonet.SRV, error) {
+		lookedUp = name
+		return nil, nil
+	}
+	_, err := ResolveSRVURLs("srv://_livepeer._tcp.example.com")
+	assert.EqualError(err, "no SRV records for _livepeer._tcp.example.com")
+	assert.Equal("_livepeer._tcp.example.com", lookedUp)
+
+	assert.True(IsSRVName("srv://_livepeer._tcp.example.com"))
+	assert.True(IsSRVName("SRV://example.com"))
+	assert.False(IsSRVName("srv://"))
+	assert.False(IsSRVName("https://example.com"))
+	assert.False(IsSRVName("orch.eth"))
+}
+
+func TestGossip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func() { server.BroadcasterSecret = "" }()
+	server.BroadcasterSecret = "secret"
+
+	newOrch := func(seeds []*url.URL) (*Gossip, *httptest.Server) {
+		var g *Gossip
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(GossipPath, r.URL.Path)
+			g.ServeHTTP(w, r)
+		}))
+		self, err := url.Parse(ts.URL)
+		require.Nil(err)
+		g = NewGossip(self, seeds, time.Minute)
+		return g, ts
+	}
+	o1, ts1 := newOrch(nil)
+	defer ts1.Close()
+	o2, ts2 := newOrch([]*url.URL{o1.self})
+	defer ts2.Close()
+	b := NewGossip(nil, []*url.URL{o2.self}, time.Minute)
+
+	// Orchestrators announce themselves to their seeds, which add them once they gossiped with them
+	o2.gossip()
+	assert.Equal([]*url.URL{o1.self}, o2.Peers())
+	assert.Empty(o1.Peers())
+	o1.gossip()
+	assert.Equal([]*url.URL{o2.self}, o1.Peers())
+
+	// Broadcasters learn about the orchestrators known by the orchestrators they gossip with
+	b.gossip()
+	assert.Equal([]*url.URL{o2.self}, b.Peers())
+	b.gossip()
+	assert.ElementsMatch([]*url.URL{o1.self, o2.self}, b.Peers())
+
+	pool := NewGossipOrchestratorPool(nil, b, common.Score_Trusted)
+	assert.Equal(2, pool.Size())
+	assert.Equal(2, pool.SizeWith(common.ScoreAtLeast(common.Score_Trusted)))
+
+	// Unreachable orchestrators are skipped
+	ts2.Close()
+	b.gossip()
+	assert.Equal(2, pool.Size())
+
+	// Orchestrators that didn't announce themselves for a few rounds are forgotten
+	b.mu.Lock()
+	b.peers[o2.self.String()] = time.Now().Add(-gossipPeerTTLRounds * time.Minute).Unix()
+	b.mu.Unlock()
+	assert.Equal([]*url.URL{o1.self}, b.Peers())
+	assert.Len(b.message().Peers, 1)
+
+	// Orchestrators announced by others are not added if the node can't gossip with them
+	b.merge([]gossipPeer{{Address: "http://127.0.0.1:1", LastSeen: time.Now().Unix()}})
+	b.gossip()
+	assert.Equal([]*url.URL{o1.self}, b.Peers())
+	assert.NotContains(b.candidates, "http://127.0.0.1:1")
+
+	// Nor if they don't respond with messages authenticated with the secret
+	ts3 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"peers":[]}`))
+	}))
+	defer ts3.Close()
+	b.merge([]gossipPeer{{Address: ts3.URL, LastSeen: time.Now().Unix()}})
+	b.gossip()
+	assert.Equal([]*url.URL{o1.self}, b.Peers())
+}
+
+func TestGossip_Merge(t *testing.T) {
+	assert := assert.New(t)
+	self, _ := url.Parse("https://127.0.0.1:8935")
+	g := NewGossip(self, nil, time.Hour)
+	now := time.Now().Unix()
+
+	g.merge([]gossipPeer{
+		// The node itself, invalid addresses and expired orchestrators are ignored
+		{Address: "https://127.0.0.1:8935", LastSeen: now},
+		{Address: "not a url", LastSeen: now},
+		{Address: "ftp://127.0.0.1:8936", LastSeen: now},
+		{Address: "https://127.0.0.1:8937", LastSeen: now - int64(gossipPeerTTLRounds*3600)},
+		// Orchestrators can't be announced as seen in the future
+		{Address: "https://127.0.0.1:8938", LastSeen: now + 3600},
+		{Address: "https://127.0.0.1:8939", LastSeen: now - 30},
+	})
+	// Orchestrators announced by others need to be verified
+	assert.Empty(g.peers)
+	assert.Len(g.candidates, 2)
+	assert.LessOrEqual(g.candidates["https://127.0.0.1:8938"], time.Now().Unix())
+
+	for _, uri := range g.popCandidates(gossipFanout) {
+		g.verified(uri)
+	}
+	assert.Empty(g.candidates)
+	assert.Len(g.peers, 2)
+	g.peers["https://127.0.0.1:8939"] = now - 30
+
+	// Older announcements don't replace newer ones
+	g.merge([]gossipPeer{{Address: "https://127.0.0.1:8939", LastSeen: now - 40}})
+	assert.Equal(now-30, g.peers["https://127.0.0.1:8939"])
+	g.merge([]gossipPeer{{Address: "https://127.0.0.1:8939", LastSeen: now - 10}})
+	assert.Equal(now-10, g.peers["https://127.0.0.1:8939"])
+
+	// The node announces itself in its messages
+	var addrs []string
+	for _, peer := range g.message().Peers {
+		addrs = append(addrs, peer.Address)
+	}
+	assert.ElementsMatch([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8938", "https://127.0.0.1:8939"}, addrs)
+	assert.Len(g.Peers(), 2)
+
+	// Orchestrators that were not verified yet are limited, the ones seen the longest ago are evicted
+	for i := 0; i < maxGossipPeers+10; i++ {
+		g.merge([]gossipPeer{{Address: fmt.Sprintf("https://10.0.0.1:%d", 1000+i), LastSeen: now - int64(maxGossipPeers+10-i)}})
+	}
+	assert.Len(g.candidates, maxGossipPeers)
+	assert.NotContains(g.candidates, "https://10.0.0.1:1000")
+	assert.Contains(g.candidates, fmt.Sprintf("https://10.0.0.1:%d", 1000+maxGossipPeers+9))
+	// Older announcements don't evict more recent ones
+	g.merge([]gossipPeer{{Address: "https://10.0.0.2:1000", LastSeen: now - 2*maxGossipPeers}})
+	assert.NotContains(g.candidates, "https://10.0.0.2:1000")
+
+	// The number of known orchestrators is limited, the ones seen the longest ago are evicted
+	g.peers = make(map[string]int64)
+	for i := 0; i < maxGossipPeers; i++ {
+		g.peers[fmt.Sprintf("https://10.0.0.3:%d", 1000+i)] = now - int64(maxGossipPeers-i)
+	}
+	newPeer, _ := url.Parse("https://10.0.0.4:1000")
+	g.verified(newPeer)
+	assert.Len(g.peers, maxGossipPeers)
+	assert.Contains(g.peers, "https://10.0.0.4:1000")
+	assert.NotContains(g.peers, "https://10.0.0.3:1000")
+}
+
+func TestGossip_ServeHTTP(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGossip(nil, nil, time.Minute)
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("GET", GossipPath, nil))
+	assert.Equal(http.StatusMethodNotAllowed, w.Code)
+
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("POST", GossipPath, strings.NewReader("{")))
+	assert.Equal(http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	body := fmt.Sprintf(`{"peers":[{"address":"https://127.0.0.1:8935","lastSeen":%d}]}`, time.Now().Unix())
+	g.ServeHTTP(w, httptest.NewRequest("POST", GossipPath, strings.NewReader(body)))
+	assert.Equal(http.StatusOK, w.Code)
+	var res gossipMessage
+	assert.Nil(json.Unmarshal(w.Body.Bytes(), &res))
+	// Announced orchestrators are only added once the node gossiped with them
+	assert.Empty(res.Peers)
+	assert.Contains(g.candidates, "https://127.0.0.1:8935")
+
+	// Messages are authenticated with the secret
+	defer func() { server.BroadcasterSecret = "" }()
+	server.BroadcasterSecret = "secret"
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest("POST", GossipPath, strings.NewReader(body)))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest("POST", GossipPath, strings.NewReader(body))
+	server.SetBroadcasterAuth(req.Header, gossipRequestAuth, []byte(body))
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Nil(server.VerifyBroadcasterAuth(w.Header(), gossipResponseAuth, w.Body.Bytes()))
+}
+
+func TestOrchInfoCache(t *testing.T) {
+	assert := assert.New(t)
+	c := newOrchInfoCache()
+
+	info := &net.OrchestratorInfo{
+		Transcoder:   "https://127.0.0.1:8935",
+		Address:      []byte("addr"),
+		PriceInfo:    &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 2},
+		Capabilities: &net.Capabilities{Bitstring: capCompatString},
+		Region:       "us-east",
+		TicketParams: &net.TicketParams{Recipient: []byte("recipient")},
+		AuthToken:    &net.AuthToken{SessionId: "session"},
+		Storage:      []*net.OSInfo{{StorageType: net.OSInfo_S3}},
+	}
+	c.set("key", info, nil, time.Minute)
+	cached, err, ok := c.get("key")
+	assert.True(ok)
+	assert.Nil(err)
+	assert.Equal("https://127.0.0.1:8935", cached.Transcoder)
+	assert.Equal([]byte("addr"), cached.Address)
+	assert.Equal(int64(1), cached.PriceInfo.PricePerUnit)
+	assert.Equal(capCompatString, cached.Capabilities.Bitstring)
+	assert.Equal("us-east", cached.Region)
+	// The credentials of the session of the stream are not cached
+	assert.Nil(cached.TicketParams)
+	assert.Nil(cached.AuthToken)
+	assert.Nil(cached.Storage)
+
+	// Callers get copies of the cached info
+	cached.PriceInfo.PricePerUnit = 5
+	cached, _, _ = c.get("key")
+	assert.Equal(int64(1), cached.PriceInfo.PricePerUnit)
+	info.PriceInfo.PricePerUnit = 5
+	cached, _, _ = c.get("key")
+	assert.Equal(int64(1), cached.PriceInfo.PricePerUnit)
+
+	_, _, ok = c.get("other")
+	assert.False(ok)
+
+	// Orchestrators refusing the job are cached with their error
+	c.set("refused", nil, server.ErrDiscoveryPrice, time.Minute)
+	cached, err, ok = c.get("refused")
+	assert.True(ok)
+	assert.Nil(cached)
+	assert.Equal(server.ErrDiscoveryPrice, err)
+
+	// Expired entries are removed
+	c.set("expired", info, nil, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	_, _, ok = c.get("expired")
+	assert.False(ok)
+	assert.Len(c.entries, 2)
+}
+
+func TestOrchInfoCacheKey(t *testing.T) {
+	assert := assert.New(t)
+
+	uri, _ := url.Parse("https://127.0.0.1:8935")
+	key := orchInfoCacheKey(uri, server.GetOrchestratorInfoParams{})
+	assert.Equal(key, orchInfoCacheKey(uri, server.GetOrchestratorInfoParams{}))
+
+	// Jobs with other capabilities or max prices don't share responses
+	caps := &net.Capabilities{Bitstring: capCompatString}
+	capsKey := orchInfoCacheKey(uri, server.GetOrchestratorInfoParams{Caps: caps})
+	assert.NotEqual(key, capsKey)
+	priceKey := orchInfoCacheKey(uri, server.GetOrchestratorInfoParams{Caps: caps, MaxPrice: big.NewRat(1, 2)})
+	assert.NotEqual(capsKey, priceKey)
+	assert.NotEqual(priceKey, orchInfoCacheKey(uri, server.GetOrchestratorInfoParams{Caps: caps, MaxPrice: big.NewRat(1, 3)}))
+
+	other, _ := url.Parse("https://127.0.0.1:8936")
+	assert.NotEqual(key, orchInfoCacheKey(other, server.GetOrchestratorInfoParams{}))
+}
+
+func TestOrchestratorPool_OrchInfoCache(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func() {
+		serverGetOrchInfo = server.GetOrchestratorInfo
+		OrchInfoCacheTTL = 0
+		orchInfos = newOrchInfoCache()
+	}()
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchURL *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests[orchURL.String()]++
+		switch orchURL.String() {
+		case "https://127.0.0.1:8936":
+			return nil, fmt.Errorf("Could not get orchestrator orch=%v: %w", orchURL, server.ErrDiscoveryPrice)
+		case "https://127.0.0.1:8937":
+			return &net.OrchestratorInfo{Transcoder: orchURL.String(), PriceInfo: &net.PriceInfo{PricePerUnit: 10, PixelsPerUnit: 1}}, nil
+		}
+		return &net.OrchestratorInfo{
+			Transcoder:   orchURL.String(),
+			PriceInfo:    &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1},
+			TicketParams: &net.TicketParams{RecipientRandHash: []byte{byte(requests[orchURL.String()])}},
+			AuthToken:    &net.AuthToken{SessionId: fmt.Sprintf("session%d", requests[orchURL.String()])},
+		}, nil
+	}
+	pred := func(info *net.OrchestratorInfo) bool {
+		return info.PriceInfo.PricePerUnit < 5
+	}
+	uris := stringsToURIs([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8936", "https://127.0.0.1:8937"})
+	pool := NewOrchestratorPoolWithPred(nil, uris, pred, common.Score_Trusted)
+	getOrchs := func() common.OrchestratorDescriptors {
+		infos, err := pool.GetOrchestrators(context.TODO(), 3, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+		require.Nil(err)
+		require.Len(infos, 1)
+		assert.Equal("https://127.0.0.1:8935", infos[0].RemoteInfo.Transcoder)
+		return infos
+	}
+	numRequests := func(uri string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[uri]
+	}
+
+	// Nothing is cached by default
+	getOrchs()
+	getOrchs()
+	assert.Equal(2, numRequests("https://127.0.0.1:8935"))
+	assert.Equal(2, numRequests("https://127.0.0.1:8936"))
+	assert.Equal(2, numRequests("https://127.0.0.1:8937"))
+
+	// Orchestrators that refused the job or don't suit it are skipped within the TTL
+	OrchInfoCacheTTL = time.Minute
+	getOrchs()
+	infos := getOrchs()
+	assert.Equal(3, numRequests("https://127.0.0.1:8936"))
+	assert.Equal(3, numRequests("https://127.0.0.1:8937"))
+
+	// Orchestrators that suit the job are requested for every stream, which gets the credentials of its own session
+	assert.Equal(4, numRequests("https://127.0.0.1:8935"))
+	assert.Equal("session4", infos[0].RemoteInfo.AuthToken.SessionId)
+	assert.Equal([]byte{4}, infos[0].RemoteInfo.TicketParams.RecipientRandHash)
+	// and only the orchestrators that refused or don't suit the job are cached
+	assert.Len(orchInfos.entries, 2)
+	uri, _ := url.Parse("https://127.0.0.1:8935")
+	_, _, ok := orchInfos.get(orchInfoCacheKey(uri, server.DiscoveryParams(newStubCapabilities())))
+	assert.False(ok)
+
+	orchInfos = newOrchInfoCache()
+	getOrchs()
+	assert.Equal(4, numRequests("https://127.0.0.1:8936"))
+	assert.Equal(4, numRequests("https://127.0.0.1:8937"))
+}
+
+func TestOrchestratorPool_RetryAfter(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func() {
+		serverGetOrchInfo = server.GetOrchestratorInfo
+		orchInfos = newOrchInfoCache()
+	}()
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	capped := true
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchURL *url.URL, params server.GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests[orchURL.String()]++
+		if orchURL.String() == "https://127.0.0.1:8936" && capped {
+			return nil, server.WithRetryAfter(fmt.Errorf("Could not get orchestrator orch=%v: OrchestratorCapped", orchURL), "10")
+		}
+		return &net.OrchestratorInfo{Transcoder: orchURL.String(), PriceInfo: &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1}}, nil
+	}
+	numRequests := func(uri string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[uri]
+	}
+	uris := stringsToURIs([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8936"})
+	pool := NewOrchestratorPool(nil, uris, common.Score_Trusted)
+	getOrchs := func(caps common.CapabilityComparator) int {
+		infos, err := pool.GetOrchestrators(context.TODO(), 2, newStubSuspender(), caps, common.ScoreAtLeast(0))
+		require.Nil(err)
+		return len(infos)
+	}
+
+	// the orchestrator is not requested again for any job until the retry-after time, even without a cache TTL
+	assert.Equal(1, getOrchs(newStubCapabilities()))
+	capped = false
+	assert.Equal(1, getOrchs(newStubCapabilities()))
+	assert.Equal(1, getOrchs(newStubCapabilities()))
+	assert.Equal(1, numRequests("https://127.0.0.1:8936"))
+	assert.Equal(3, numRequests("https://127.0.0.1:8935"))
+
+	orchInfos.entries["https://127.0.0.1:8936"] = orchInfoCacheEntry{err: errors.New("capped"), expires: time.Now().Add(-time.Second)}
+	assert.Equal(2, getOrchs(newStubCapabilities()))
+	assert.Equal(2, numRequests("https://127.0.0.1:8936"))
+}
@@ -0,0 +1,140 @@
+package discovery
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/eth"
+)
+
+// ENSTextResolver resolves the text records of ENS names
+type ENSTextResolver interface {
+	Text(name, key string) (string, error)
+}
+
+// ensPool is an orchestrator pool of static URIs and of ENS names whose URL text record is periodically re-resolved,
+// so that an orchestrator can move to another URI without reconfiguring the broadcasters
+type ensPool struct {
+	uris            []*url.URL
+	names           []string
+	resolver        ENSTextResolver
+	refreshInterval time.Duration
+	score           float32
+	bcast           common.Broadcaster
+
+	mu          sync.RWMutex
+	pool        *orchestratorPool
+	resolved    map[string]*url.URL
+	lastResolve time.Time
+	// stats are shared by the pools created after each resolution
+	stats *discoveryStats
+}
+
+func NewENSOrchestratorPool(bcast common.Broadcaster, uris []*url.URL, names []string, resolver ENSTextResolver, refreshInterval time.Duration, score float32) *ensPool {
+	p := &ensPool{
+		uris:            uris,
+		names:           names,
+		resolver:        resolver,
+		refreshInterval: refreshInterval,
+		score:           score,
+		bcast:           bcast,
+		resolved:        make(map[string]*url.URL),
+		lastResolve:     time.Now(),
+		stats:           newDiscoveryStats(DefaultDiscoveryLimits()),
+	}
+	p.resolve()
+	return p
+}
+
+// ResolveENSURL returns the URI in the URL text record of an ENS name
+func ResolveENSURL(resolver ENSTextResolver, name string) (*url.URL, error) {
+	text, err := resolver.Text(name, eth.ENSURLKey)
+	if err != nil {
+		return nil, err
+	}
+	return url.ParseRequestURI(text)
+}
+
+func (e *ensPool) resolve() {
+	e.mu.RLock()
+	resolved := make(map[string]*url.URL, len(e.resolved))
+	for name, uri := range e.resolved {
+		resolved[name] = uri
+	}
+	e.mu.RUnlock()
+
+	for _, name := range e.names {
+		uri, err := ResolveENSURL(e.resolver, name)
+		if err != nil {
+			// Keep using the last URI of the name if it can't be resolved
+			glog.Errorf("Could not resolve orchestrator URI name=%v err=%q", name, err)
+			continue
+		}
+		if prev, ok := resolved[name]; !ok || prev.String() != uri.String() {
+			glog.Infof("Resolved orchestrator URI name=%v uri=%v", name, uri)
+		}
+		resolved[name] = uri
+	}
+
+	uris := append([]*url.URL{}, e.uris...)
+	for _, name := range e.names {
+		if uri, ok := resolved[name]; ok {
+			uris = append(uris, uri)
+		}
+	}
+	infos := make([]common.OrchestratorLocalInfo, 0, len(uris))
+	for _, uri := range uris {
+		infos = append(infos, common.OrchestratorLocalInfo{URL: uri, Score: e.score})
+	}
+	pool := &orchestratorPool{infos: infos, bcast: e.bcast, stats: e.stats}
+
+	e.mu.Lock()
+	e.resolved = resolved
+	e.pool = pool
+	e.mu.Unlock()
+}
+
+// getPool returns the current pool, re-resolving the names first if the refresh interval elapsed
+func (e *ensPool) getPool() *orchestratorPool {
+	// Only one caller re-resolves the names, the others use the current pool in the meantime
+	e.mu.Lock()
+	stale := time.Since(e.lastResolve) >= e.refreshInterval
+	if stale {
+		e.lastResolve = time.Now()
+	}
+	e.mu.Unlock()
+	if stale {
+		e.resolve()
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.pool
+}
+
+// SetDiscoveryLimits sets the limits of the parallelism and timeouts of discovery
+func (e *ensPool) SetDiscoveryLimits(limits DiscoveryLimits) {
+	e.stats.setLimits(limits)
+}
+
+func (e *ensPool) GetInfos() []common.OrchestratorLocalInfo {
+	return e.getPool().GetInfos()
+}
+
+func (e *ensPool) Size() int {
+	return len(e.GetInfos())
+}
+
+func (e *ensPool) SizeWith(scorePred common.ScorePred) int {
+	return e.getPool().SizeWith(scorePred)
+}
+
+func (e *ensPool) GetOrchestrators(ctx context.Context, numOrchestrators int, suspender common.Suspender, caps common.CapabilityComparator,
+	scorePred common.ScorePred) (common.OrchestratorDescriptors, error) {
+
+	return e.getPool().GetOrchestrators(ctx, numOrchestrators, suspender, caps, scorePred)
+}
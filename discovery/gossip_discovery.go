@@ -0,0 +1,400 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/server"
+)
+
+// GossipPath is the path of the orchestrator endpoint that exchanges the known orchestrators with other nodes
+const GossipPath = "/gossip"
+
+// maxGossipPeers limits the number of orchestrators, and of orchestrators that were not verified yet, that a node
+// keeps track of
+const maxGossipPeers = 1000
+
+// gossipFanout is the number of known orchestrators, besides the seeds, that a node gossips with each round
+const gossipFanout = 3
+
+// Auth scopes of the gossip messages exchanged by nodes, see server.SetBroadcasterAuth
+const gossipRequestAuth = "POST " + GossipPath
+const gossipResponseAuth = GossipPath + " response"
+
+// gossipPeerTTLRounds is the number of rounds after which an orchestrator that didn't announce itself is forgotten
+const gossipPeerTTLRounds = 5
+
+type gossipPeer struct {
+	Address string `json:"address"`
+	// LastSeen is the unix time at which the orchestrator last announced itself
+	LastSeen int64 `json:"lastSeen"`
+}
+
+type gossipMessage struct {
+	Peers []gossipPeer `json:"peers"`
+}
+
+// Gossip discovers the orchestrators of an offchain network without a central webhook. Each round, a node sends the
+// orchestrators it knows of to the seeds and to a few of the known orchestrators, which respond with the orchestrators
+// they know of. Orchestrators announce themselves in the messages they send and respond with, and are forgotten
+// once they didn't announce themselves for a few rounds.
+// Messages are authenticated with the server.BroadcasterSecret shared by the nodes of the network. Orchestrators that
+// a node learns about from others are only added once the node gossiped with them itself.
+type Gossip struct {
+	// self is the service URI of an orchestrator, nil for a broadcaster
+	self     *url.URL
+	seeds    []*url.URL
+	interval time.Duration
+
+	mu sync.RWMutex
+	// peers are the unix times at which the known orchestrators last announced themselves
+	peers map[string]int64
+	// candidates are the unix times at which the orchestrators that were not verified yet were announced
+	candidates map[string]int64
+}
+
+func NewGossip(self *url.URL, seeds []*url.URL, interval time.Duration) *Gossip {
+	return &Gossip{
+		self:       self,
+		seeds:      seeds,
+		interval:   interval,
+		peers:      make(map[string]int64),
+		candidates: make(map[string]int64),
+	}
+}
+
+// Start gossips right away and then every interval until the context is done
+func (g *Gossip) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+		for {
+			g.gossip()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (g *Gossip) gossip() {
+	msg := g.message()
+	for _, peer := range g.targets() {
+		res, err := postGossip(peer, msg)
+		if err != nil {
+			glog.V(common.DEBUG).Infof("Could not gossip with orchestrator uri=%v err=%q", peer, err)
+			continue
+		}
+		g.verified(peer)
+		g.merge(res.Peers)
+	}
+}
+
+// targets returns the seeds, a few random known orchestrators and a few orchestrators to verify to gossip with
+func (g *Gossip) targets() []*url.URL {
+	targets := append([]*url.URL{}, g.seeds...)
+	skip := make(map[string]bool)
+	for _, seed := range g.seeds {
+		skip[seed.String()] = true
+	}
+	if g.self != nil {
+		skip[g.self.String()] = true
+	}
+
+	var known []*url.URL
+	for _, peer := range g.Peers() {
+		if !skip[peer.String()] {
+			known = append(known, peer)
+		}
+	}
+	rand.Shuffle(len(known), func(i, j int) { known[i], known[j] = known[j], known[i] })
+	if len(known) > gossipFanout {
+		known = known[:gossipFanout]
+	}
+	return append(append(targets, known...), g.popCandidates(gossipFanout)...)
+}
+
+// popCandidates removes up to n random orchestrators that were not verified yet and returns them
+func (g *Gossip) popCandidates(n int) []*url.URL {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var uris []*url.URL
+	for addr := range g.candidates {
+		if len(uris) >= n {
+			break
+		}
+		delete(g.candidates, addr)
+		if uri, err := url.ParseRequestURI(addr); err == nil {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// verified adds an orchestrator that the node gossiped with, which announces itself by responding
+func (g *Gossip) verified(peer *url.URL) {
+	now := time.Now().Unix()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	addr := peer.String()
+	if g.self != nil && addr == g.self.String() {
+		return
+	}
+	delete(g.candidates, addr)
+	if _, ok := g.peers[addr]; !ok {
+		if !evictOldest(g.peers, now) {
+			return
+		}
+		glog.Infof("Discovered orchestrator through gossip uri=%v", addr)
+	}
+	g.peers[addr] = now
+}
+
+// evictOldest makes room for an entry seen at lastSeen in a map of the times entries were seen, evicting the entry
+// seen the longest ago if the map is full. It returns false if all the entries were seen more recently.
+func evictOldest(entries map[string]int64, lastSeen int64) bool {
+	if len(entries) < maxGossipPeers {
+		return true
+	}
+	oldest, oldestSeen := "", lastSeen
+	for addr, seen := range entries {
+		if seen < oldestSeen {
+			oldest, oldestSeen = addr, seen
+		}
+	}
+	if oldest == "" {
+		return false
+	}
+	delete(entries, oldest)
+	return true
+}
+
+// message returns the known orchestrators, with the node itself if it is an orchestrator
+func (g *Gossip) message() gossipMessage {
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.expire(now)
+	if g.self != nil {
+		g.peers[g.self.String()] = now.Unix()
+	}
+	msg := gossipMessage{Peers: make([]gossipPeer, 0, len(g.peers))}
+	for addr, lastSeen := range g.peers {
+		msg.Peers = append(msg.Peers, gossipPeer{Address: addr, LastSeen: lastSeen})
+	}
+	return msg
+}
+
+// merge updates the time at which the known orchestrators of a message were last seen. The other orchestrators of the
+// message are verified by gossiping with them before they are added.
+func (g *Gossip) merge(peers []gossipPeer) {
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, peer := range peers {
+		uri, err := url.ParseRequestURI(peer.Address)
+		if err != nil || (uri.Scheme != "https" && uri.Scheme != "http") || uri.Host == "" {
+			continue
+		}
+		addr := uri.String()
+		if g.self != nil && addr == g.self.String() {
+			continue
+		}
+		// An orchestrator can't be announced as seen in the future to keep it from expiring
+		lastSeen := peer.LastSeen
+		if lastSeen > now.Unix() {
+			lastSeen = now.Unix()
+		}
+		if now.Sub(time.Unix(lastSeen, 0)) >= g.ttl() {
+			continue
+		}
+		prev, ok := g.peers[addr]
+		if !ok {
+			if _, ok := g.candidates[addr]; !ok && evictOldest(g.candidates, lastSeen) {
+				g.candidates[addr] = lastSeen
+			}
+			continue
+		}
+		if lastSeen > prev {
+			g.peers[addr] = lastSeen
+		}
+	}
+}
+
+// expire forgets the orchestrators that didn't announce themselves for a few rounds. It is called with the lock held.
+func (g *Gossip) expire(now time.Time) {
+	for _, entries := range []map[string]int64{g.peers, g.candidates} {
+		for addr, lastSeen := range entries {
+			if now.Sub(time.Unix(lastSeen, 0)) >= g.ttl() {
+				delete(entries, addr)
+			}
+		}
+	}
+}
+
+func (g *Gossip) ttl() time.Duration {
+	return gossipPeerTTLRounds * g.interval
+}
+
+// Peers returns the URIs of the known orchestrators, excluding the node itself
+func (g *Gossip) Peers() []*url.URL {
+	now := time.Now()
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var uris []*url.URL
+	for addr, lastSeen := range g.peers {
+		if g.self != nil && addr == g.self.String() {
+			continue
+		}
+		if now.Sub(time.Unix(lastSeen, 0)) >= g.ttl() {
+			continue
+		}
+		uri, err := url.ParseRequestURI(addr)
+		if err != nil {
+			continue
+		}
+		uris = append(uris, uri)
+	}
+	sort.Slice(uris, func(i, j int) bool { return uris[i].String() < uris[j].String() })
+	return uris
+}
+
+// ServeHTTP merges the orchestrators sent by a node and responds with the known orchestrators
+func (g *Gossip) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read gossip message: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := server.VerifyBroadcasterAuth(r.Header, gossipRequestAuth, body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var msg gossipMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode gossip message: %v", err), http.StatusBadRequest)
+		return
+	}
+	g.merge(msg.Peers)
+
+	res, err := json.Marshal(g.message())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	server.SetBroadcasterAuth(w.Header(), gossipResponseAuth, res)
+	w.Write(res)
+}
+
+var postGossip = func(peer *url.URL, msg gossipMessage) (*gossipMessage, error) {
+	// Orchestrators use self-signed certificates
+	httpc := &http.Client{
+		Timeout:   3 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, peer.ResolveReference(&url.URL{Path: GossipPath}).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	server.SetBroadcasterAuth(req.Header, gossipRequestAuth, body)
+	resp, err := httpc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status=%d body=%q", resp.StatusCode, data)
+	}
+	// Only nodes of the network can announce themselves by responding
+	if err := server.VerifyBroadcasterAuth(resp.Header, gossipResponseAuth, data); err != nil {
+		return nil, err
+	}
+	var res gossipMessage
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// gossipPool is an orchestrator pool of the orchestrators discovered through gossip
+type gossipPool struct {
+	gossip *Gossip
+	score  float32
+	bcast  common.Broadcaster
+	// stats are shared by the pools created for the current orchestrators
+	stats *discoveryStats
+}
+
+func NewGossipOrchestratorPool(bcast common.Broadcaster, gossip *Gossip, score float32) *gossipPool {
+	return &gossipPool{
+		gossip: gossip,
+		score:  score,
+		bcast:  bcast,
+		stats:  newDiscoveryStats(DefaultDiscoveryLimits()),
+	}
+}
+
+func (p *gossipPool) getPool() *orchestratorPool {
+	peers := p.gossip.Peers()
+	infos := make([]common.OrchestratorLocalInfo, 0, len(peers))
+	for _, uri := range peers {
+		infos = append(infos, common.OrchestratorLocalInfo{URL: uri, Score: p.score})
+	}
+	return &orchestratorPool{infos: infos, bcast: p.bcast, stats: p.stats}
+}
+
+// SetDiscoveryLimits sets the limits of the parallelism and timeouts of discovery
+func (p *gossipPool) SetDiscoveryLimits(limits DiscoveryLimits) {
+	p.stats.setLimits(limits)
+}
+
+func (p *gossipPool) GetInfos() []common.OrchestratorLocalInfo {
+	return p.getPool().GetInfos()
+}
+
+func (p *gossipPool) Size() int {
+	return len(p.GetInfos())
+}
+
+func (p *gossipPool) SizeWith(scorePred common.ScorePred) int {
+	return p.getPool().SizeWith(scorePred)
+}
+
+func (p *gossipPool) GetOrchestrators(ctx context.Context, numOrchestrators int, suspender common.Suspender, caps common.CapabilityComparator,
+	scorePred common.ScorePred) (common.OrchestratorDescriptors, error) {
+
+	return p.getPool().GetOrchestrators(ctx, numOrchestrators, suspender, caps, scorePred)
+}
@@ -0,0 +1,105 @@
+package discovery
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/server"
+)
+
+// OrchInfoCacheTTL is how long discovery remembers the orchestrators that refused or don't suit a job, so that
+// streams started within a short time of each other don't request them again. Nothing is cached if it is 0.
+var OrchInfoCacheTTL time.Duration
+
+var orchInfos = newOrchInfoCache()
+
+type orchInfoCacheEntry struct {
+	// info only holds the fields of a response that didn't pass the filters of the job, which don't depend on the
+	// stream, so that they can be checked again by the jobs with other filters
+	info *net.OrchestratorInfo
+	// err is set if the orchestrator refused the job because it doesn't meet its requirements
+	err     error
+	expires time.Time
+}
+
+// orchInfoCache is a negative cache of discovery: it caches the responses of the orchestrators that refused or don't
+// suit a job by URI and job requirements, and the refusals of the orchestrators that asked to be retried later by
+// URI. The responses of the orchestrators that suit a job are not cached, since their auth token and ticket params
+// are only valid for the session of one stream.
+type orchInfoCache struct {
+	mu      sync.Mutex
+	entries map[string]orchInfoCacheEntry
+}
+
+func newOrchInfoCache() *orchInfoCache {
+	return &orchInfoCache{entries: make(map[string]orchInfoCacheEntry)}
+}
+
+// orchInfoCacheKey returns the key of the response of an orchestrator to a request with params, since orchestrators
+// advertise prices and refuse jobs depending on the capabilities and the max price of the job
+func orchInfoCacheKey(uri *url.URL, params server.GetOrchestratorInfoParams) string {
+	key := uri.String() + "|" + params.Caps.String() + "|"
+	if params.MaxPrice != nil {
+		key += params.MaxPrice.RatString()
+	}
+	return key
+}
+
+// streamIndependentInfo returns the fields of a response that can be reused by the streams of other sessions
+func streamIndependentInfo(info *net.OrchestratorInfo) *net.OrchestratorInfo {
+	res := &net.OrchestratorInfo{
+		Transcoder: info.Transcoder,
+		Address:    append([]byte(nil), info.Address...),
+		Region:     info.Region,
+		Datacenter: info.Datacenter,
+	}
+	if info.PriceInfo != nil {
+		res.PriceInfo = &net.PriceInfo{PricePerUnit: info.PriceInfo.PricePerUnit, PixelsPerUnit: info.PriceInfo.PixelsPerUnit}
+	}
+	if info.Capabilities != nil {
+		res.Capabilities = proto.Clone(info.Capabilities).(*net.Capabilities)
+	}
+	return res
+}
+
+// get returns a copy of the cached info of an orchestrator, or the error with which it refused the job, if any
+func (c *orchInfoCache) get(key string) (*net.OrchestratorInfo, error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+	if !time.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, nil, false
+	}
+	if entry.err != nil {
+		return nil, entry.err, true
+	}
+	return streamIndependentInfo(entry.info), nil, true
+}
+
+// set caches the stream independent info of the response of an orchestrator, or the error with which it refused
+// the job, for the TTL
+func (c *orchInfoCache) set(key string, info *net.OrchestratorInfo, err error, ttl time.Duration) {
+	now := time.Now()
+	entry := orchInfoCacheEntry{err: err, expires: now.Add(ttl)}
+	if err == nil {
+		entry.info = streamIndependentInfo(info)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry
+}
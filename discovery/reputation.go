@@ -0,0 +1,277 @@
+package discovery
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/server"
+)
+
+// Average round trip of a segment at which the latency score of an orchestrator is 0.5
+const reputationRoundTripRef = 2 * time.Second
+
+// Share of the segments submitted to an orchestrator failing verification at which its verification score drops to 0
+const reputationMaxVerificationFailureRate = 0.1
+
+// Score of the components for which there is no data yet
+const reputationUnknownScore = 0.5
+
+// reputationUpdateInterval is how often the reputation scores are recomputed and written to the DB
+var reputationUpdateInterval = 1 * time.Minute
+
+// ReputationWeights are the weights of the components of the reputation score
+type ReputationWeights struct {
+	SuccessRate  float64
+	Latency      float64
+	Verification float64
+	Price        float64
+}
+
+// DefaultReputationWeights favors orchestrators that reliably transcode segments over cheap ones
+var DefaultReputationWeights = ReputationWeights{SuccessRate: 0.4, Latency: 0.25, Verification: 0.25, Price: 0.1}
+
+// ParseReputationWeights parses weights formatted as comma separated component=weight pairs, e.g.
+// successRate=0.5,latency=0.2,verification=0.2,price=0.1. The weight of the omitted components is 0.
+func ParseReputationWeights(s string) (ReputationWeights, error) {
+	var w ReputationWeights
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 {
+			return w, fmt.Errorf("invalid reputation weight %q, expected component=weight", pair)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil || v < 0 {
+			return w, fmt.Errorf("invalid reputation weight %q, expected a non-negative number", pair)
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case "successRate":
+			w.SuccessRate = v
+		case "latency":
+			w.Latency = v
+		case "verification":
+			w.Verification = v
+		case "price":
+			w.Price = v
+		default:
+			return w, fmt.Errorf("unknown reputation component %q, expected one of successRate, latency, verification or price", kv[0])
+		}
+	}
+	if w.SuccessRate+w.Latency+w.Verification+w.Price <= 0 {
+		return w, fmt.Errorf("at least one reputation weight must be greater than 0")
+	}
+	return w, nil
+}
+
+type orchReputation struct {
+	score                float64
+	verificationFailures int64
+	price                *big.Rat
+}
+
+// Reputation scores orchestrators between 0 and 1 by combining their historical success rate and latency,
+// as recorded by the orchestrator stats, the share of their segments that failed verification and how their price
+// compares to the cheapest known orchestrator. The scores are recomputed periodically and persisted in the DB
+// along with the verification failures and last price of each orchestrator, so that they survive restarts.
+type Reputation struct {
+	db      *common.DB
+	stats   *server.OrchestratorStats
+	weights ReputationWeights
+
+	mu    sync.RWMutex
+	orchs map[string]*orchReputation
+
+	quit chan struct{}
+}
+
+// NewReputation creates a Reputation initialized with the reputations stored in db
+func NewReputation(db *common.DB, stats *server.OrchestratorStats, weights ReputationWeights) (*Reputation, error) {
+	r := &Reputation{
+		db:      db,
+		stats:   stats,
+		weights: weights,
+		orchs:   make(map[string]*orchReputation),
+		quit:    make(chan struct{}),
+	}
+	stored, err := db.SelectOrchReputations()
+	if err != nil {
+		return nil, err
+	}
+	for _, rep := range stored {
+		r.orchs[rep.ServiceURI] = &orchReputation{
+			score:                rep.Score,
+			verificationFailures: rep.VerificationFailures,
+			price:                rep.PricePerPixel,
+		}
+	}
+	return r, nil
+}
+
+// Score records the price advertised in info and returns the last computed reputation score of the orchestrator.
+// The score of an orchestrator seen for the first time is computed right away.
+func (r *Reputation) Score(info *net.OrchestratorInfo) float64 {
+	uri := info.GetTranscoder()
+	price := pricePerPixel(info.GetPriceInfo())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	orch, ok := r.orchs[uri]
+	if !ok {
+		orch = &orchReputation{price: price}
+		r.orchs[uri] = orch
+		orch.score = r.components(uri, orch, r.minPrice()).Score
+		return orch.score
+	}
+	if price != nil {
+		orch.price = price
+	}
+	return orch.score
+}
+
+// VerificationFailed records that a segment transcoded by the orchestrator at uri failed verification
+func (r *Reputation) VerificationFailed(uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	orch, ok := r.orchs[uri]
+	if !ok {
+		orch = &orchReputation{}
+		r.orchs[uri] = orch
+	}
+	orch.verificationFailures++
+}
+
+// Scores returns the current reputation scores of all known orchestrators sorted by descending score
+func (r *Reputation) Scores() []*server.ReputationScore {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	minPrice := r.minPrice()
+	scores := make([]*server.ReputationScore, 0, len(r.orchs))
+	for uri, orch := range r.orchs {
+		scores = append(scores, r.components(uri, orch, minPrice))
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].ServiceURI < scores[j].ServiceURI
+	})
+	return scores
+}
+
+// Update recomputes the reputation scores of all known orchestrators and writes them to the DB
+func (r *Reputation) Update() error {
+	r.mu.Lock()
+	minPrice := r.minPrice()
+	reps := make([]*common.DBOrchReputation, 0, len(r.orchs))
+	for uri, orch := range r.orchs {
+		orch.score = r.components(uri, orch, minPrice).Score
+		reps = append(reps, &common.DBOrchReputation{
+			ServiceURI:           uri,
+			Score:                orch.score,
+			VerificationFailures: orch.verificationFailures,
+			PricePerPixel:        orch.price,
+		})
+	}
+	r.mu.Unlock()
+
+	var err error
+	for _, rep := range reps {
+		if uerr := r.db.UpdateOrchReputation(rep); uerr != nil {
+			glog.Errorf("Error storing orchestrator reputation orch=%v err=%q", rep.ServiceURI, uerr)
+			err = uerr
+		}
+	}
+	return err
+}
+
+// Start periodically updates the reputation scores until Stop is called
+func (r *Reputation) Start() {
+	ticker := time.NewTicker(reputationUpdateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Update()
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// Stop stops the update loop and writes the current scores to the DB
+func (r *Reputation) Stop() {
+	close(r.quit)
+	r.Update()
+}
+
+// components computes the reputation score of the orchestrator at uri. The caller must hold the lock.
+func (r *Reputation) components(uri string, orch *orchReputation, minPrice *big.Rat) *server.ReputationScore {
+	stats, _ := r.stats.Stats(uri)
+
+	rs := &server.ReputationScore{
+		ServiceURI:           uri,
+		SuccessRate:          float64(stats.Successes+1) / float64(stats.Segments+stats.Timeouts+2),
+		Latency:              reputationUnknownScore,
+		Verification:         1,
+		Price:                reputationUnknownScore,
+		VerificationFailures: orch.verificationFailures,
+	}
+	if stats.Successes > 0 {
+		roundTrip := time.Duration(stats.RoundTripMs/stats.Successes) * time.Millisecond
+		rs.Latency = float64(reputationRoundTripRef) / float64(reputationRoundTripRef+roundTrip)
+	}
+	if orch.verificationFailures > 0 {
+		failureRate := float64(orch.verificationFailures) / float64(stats.Segments+1)
+		rs.Verification = 1 - failureRate/reputationMaxVerificationFailureRate
+		if rs.Verification < 0 {
+			rs.Verification = 0
+		}
+	}
+	if orch.price != nil {
+		rs.PricePerPixel = orch.price.FloatString(3)
+		rs.Price = priceScore(orch.price, minPrice)
+	}
+
+	w := r.weights
+	total := w.SuccessRate + w.Latency + w.Verification + w.Price
+	if total > 0 {
+		rs.Score = (w.SuccessRate*rs.SuccessRate + w.Latency*rs.Latency + w.Verification*rs.Verification + w.Price*rs.Price) / total
+	}
+	return rs
+}
+
+// minPrice returns the lowest known price per pixel. The caller must hold the lock.
+func (r *Reputation) minPrice() *big.Rat {
+	var min *big.Rat
+	for _, orch := range r.orchs {
+		if orch.price != nil && (min == nil || orch.price.Cmp(min) < 0) {
+			min = orch.price
+		}
+	}
+	return min
+}
+
+// priceScore scores a price by how close it is to the lowest known price: 1 for the lowest price, 0.5 for twice
+// the lowest price and so on
+func priceScore(price, minPrice *big.Rat) float64 {
+	if price.Sign() <= 0 || minPrice == nil {
+		return 1
+	}
+	score, _ := new(big.Rat).Quo(minPrice, price).Float64()
+	return score
+}
+
+func pricePerPixel(info *net.PriceInfo) *big.Rat {
+	if info == nil || info.PixelsPerUnit <= 0 {
+		return nil
+	}
+	return big.NewRat(info.PricePerUnit, info.PixelsPerUnit)
+}
@@ -0,0 +1,132 @@
+package discovery
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func orchInfoWithPrice(uri string, pricePerUnit, pixelsPerUnit int64) *net.OrchestratorInfo {
+	return &net.OrchestratorInfo{
+		Transcoder: uri,
+		PriceInfo:  &net.PriceInfo{PricePerUnit: pricePerUnit, PixelsPerUnit: pixelsPerUnit},
+	}
+}
+
+func TestReputation_Score(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	stats, err := server.NewOrchestratorStats(nil)
+	require.Nil(err)
+	// o1 transcodes all segments in 2s, o2 fails half of them
+	for i := 0; i < 8; i++ {
+		stats.Observe("https://o1:8935", true, false, 2*time.Second)
+		stats.Observe("https://o2:8935", i%2 == 0, false, 2*time.Second)
+	}
+
+	rep, err := NewReputation(nil, stats, ReputationWeights{SuccessRate: 1, Latency: 1, Verification: 1, Price: 1})
+	require.Nil(err)
+
+	s1 := rep.Score(orchInfoWithPrice("https://o1:8935", 1, 1))
+	s2 := rep.Score(orchInfoWithPrice("https://o2:8935", 1, 1))
+	// success rate 0.9, latency 0.5, verification 1, price 1
+	assert.InDelta(0.85, s1, 0.0001)
+	// success rate 0.5, latency 0.5, verification 1, price 1
+	assert.InDelta(0.75, s2, 0.0001)
+
+	// Scores are only recomputed on update
+	rep.VerificationFailed("https://o1:8935")
+	assert.InDelta(0.85, rep.Score(orchInfoWithPrice("https://o1:8935", 1, 1)), 0.0001)
+
+	// 1 failure out of 8 segments zeroes the verification score
+	require.Nil(rep.Update())
+	assert.InDelta(0.6, rep.Score(orchInfoWithPrice("https://o1:8935", 1, 1)), 0.0001)
+
+	// o3 is twice as expensive and unknown
+	s3 := rep.Score(orchInfoWithPrice("https://o3:8935", 2, 1))
+	// success rate 0.5, latency 0.5, verification 1, price 0.5
+	assert.InDelta(0.625, s3, 0.0001)
+}
+
+func TestReputation_Scores(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rep, err := NewReputation(nil, nil, DefaultReputationWeights)
+	require.Nil(err)
+	assert.Empty(rep.Scores())
+
+	rep.Score(orchInfoWithPrice("https://o1:8935", 3, 1))
+	rep.Score(orchInfoWithPrice("https://o2:8935", 1, 1))
+	rep.VerificationFailed("https://o3:8935")
+
+	scores := rep.Scores()
+	require.Len(scores, 3)
+	assert.Equal("https://o2:8935", scores[0].ServiceURI)
+	assert.Equal(1.0, scores[0].Price)
+	assert.Equal("1.000", scores[0].PricePerPixel)
+	assert.Equal("https://o1:8935", scores[1].ServiceURI)
+	assert.InDelta(1.0/3, scores[1].Price, 0.0001)
+	assert.Equal("https://o3:8935", scores[2].ServiceURI)
+	assert.Equal(int64(1), scores[2].VerificationFailures)
+	assert.Equal(0.0, scores[2].Verification)
+	assert.Equal(reputationUnknownScore, scores[2].Price)
+}
+
+func TestReputation_Persisted(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	rep, err := NewReputation(dbh, nil, DefaultReputationWeights)
+	require.Nil(err)
+	score := rep.Score(orchInfoWithPrice("https://o1:8935", 2, 1))
+	rep.VerificationFailed("https://o1:8935")
+	require.Nil(rep.Update())
+
+	// The scores, verification failures and prices are loaded on restart
+	rep, err = NewReputation(dbh, nil, DefaultReputationWeights)
+	require.Nil(err)
+	scores := rep.Scores()
+	require.Len(scores, 1)
+	assert.Equal(int64(1), scores[0].VerificationFailures)
+	assert.Equal("2.000", scores[0].PricePerPixel)
+	assert.Less(rep.Score(&net.OrchestratorInfo{Transcoder: "https://o1:8935"}), score)
+
+	stored, err := dbh.SelectOrchReputations()
+	require.Nil(err)
+	require.Len(stored, 1)
+	assert.Zero(stored[0].PricePerPixel.Cmp(big.NewRat(2, 1)))
+}
+
+func TestParseReputationWeights(t *testing.T) {
+	assert := assert.New(t)
+
+	w, err := ParseReputationWeights("successRate=0.5, latency=0.2,verification=0.2,price=0.1")
+	assert.Nil(err)
+	assert.Equal(ReputationWeights{SuccessRate: 0.5, Latency: 0.2, Verification: 0.2, Price: 0.1}, w)
+
+	w, err = ParseReputationWeights("price=1")
+	assert.Nil(err)
+	assert.Equal(ReputationWeights{Price: 1}, w)
+
+	_, err = ParseReputationWeights("price")
+	assert.EqualError(err, `invalid reputation weight "price", expected component=weight`)
+	_, err = ParseReputationWeights("price=-1")
+	assert.EqualError(err, `invalid reputation weight "price=-1", expected a non-negative number`)
+	_, err = ParseReputationWeights("stake=1")
+	assert.EqualError(err, `unknown reputation component "stake", expected one of successRate, latency, verification or price`)
+	_, err = ParseReputationWeights("price=0")
+	assert.EqualError(err, "at least one reputation weight must be greater than 0")
+}
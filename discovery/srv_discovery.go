@@ -0,0 +1,166 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+)
+
+// SRVScheme is the scheme of the -orchAddr entries whose SRV records list the orchestrators
+const SRVScheme = "srv://"
+
+var lookupSRV = func(name string) ([]*net.SRV, error) {
+	_, addrs, err := net.LookupSRV("", "", name)
+	return addrs, err
+}
+
+// IsSRVName returns whether an orchestrator address is a DNS name to resolve the SRV records of
+func IsSRVName(addr string) bool {
+	return len(addr) > len(SRVScheme) && strings.HasPrefix(strings.ToLower(addr), SRVScheme)
+}
+
+// ResolveSRVURLs returns the orchestrator URIs of the targets in the SRV records of a name
+func ResolveSRVURLs(name string) ([]*url.URL, error) {
+	if IsSRVName(name) {
+		name = name[len(SRVScheme):]
+	}
+	addrs, err := lookupSRV(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no SRV records for %v", name)
+	}
+	uris := make([]*url.URL, 0, len(addrs))
+	for _, addr := range addrs {
+		host := strings.TrimSuffix(addr.Target, ".")
+		uris = append(uris, &url.URL{Scheme: "https", Host: net.JoinHostPort(host, fmt.Sprint(addr.Port))})
+	}
+	return uris, nil
+}
+
+// srvPool is an orchestrator pool of static URIs and of the targets of the SRV records of DNS names, which are
+// periodically re-resolved so that operators can add or remove orchestrators by updating their DNS records
+type srvPool struct {
+	uris            []*url.URL
+	names           []string
+	refreshInterval time.Duration
+	score           float32
+	bcast           common.Broadcaster
+
+	mu          sync.RWMutex
+	pool        *orchestratorPool
+	resolved    map[string][]*url.URL
+	lastResolve time.Time
+	// stats are shared by the pools created after each resolution
+	stats *discoveryStats
+}
+
+func NewSRVOrchestratorPool(bcast common.Broadcaster, uris []*url.URL, names []string, refreshInterval time.Duration, score float32) *srvPool {
+	p := &srvPool{
+		uris:            uris,
+		names:           names,
+		refreshInterval: refreshInterval,
+		score:           score,
+		bcast:           bcast,
+		resolved:        make(map[string][]*url.URL),
+		lastResolve:     time.Now(),
+		stats:           newDiscoveryStats(DefaultDiscoveryLimits()),
+	}
+	p.resolve()
+	return p
+}
+
+func (s *srvPool) resolve() {
+	s.mu.RLock()
+	resolved := make(map[string][]*url.URL, len(s.resolved))
+	for name, uris := range s.resolved {
+		resolved[name] = uris
+	}
+	s.mu.RUnlock()
+
+	for _, name := range s.names {
+		uris, err := ResolveSRVURLs(name)
+		if err != nil {
+			// Keep using the last targets of the name if it can't be resolved
+			glog.Errorf("Could not resolve orchestrator SRV records name=%v err=%q", name, err)
+			continue
+		}
+		if prev, ok := resolved[name]; !ok || fmt.Sprint(prev) != fmt.Sprint(uris) {
+			glog.Infof("Resolved orchestrator SRV records name=%v uris=%v", name, uris)
+		}
+		resolved[name] = uris
+	}
+
+	seen := make(map[string]bool)
+	var infos []common.OrchestratorLocalInfo
+	add := func(uri *url.URL) {
+		if seen[uri.String()] {
+			return
+		}
+		seen[uri.String()] = true
+		infos = append(infos, common.OrchestratorLocalInfo{URL: uri, Score: s.score})
+	}
+	for _, uri := range s.uris {
+		add(uri)
+	}
+	for _, name := range s.names {
+		for _, uri := range resolved[name] {
+			add(uri)
+		}
+	}
+	pool := &orchestratorPool{infos: infos, bcast: s.bcast, stats: s.stats}
+
+	s.mu.Lock()
+	s.resolved = resolved
+	s.pool = pool
+	s.mu.Unlock()
+}
+
+// getPool returns the current pool, re-resolving the names first if the refresh interval elapsed
+func (s *srvPool) getPool() *orchestratorPool {
+	// Only one caller re-resolves the names, the others use the current pool in the meantime
+	s.mu.Lock()
+	stale := time.Since(s.lastResolve) >= s.refreshInterval
+	if stale {
+		s.lastResolve = time.Now()
+	}
+	s.mu.Unlock()
+	if stale {
+		s.resolve()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pool
+}
+
+// SetDiscoveryLimits sets the limits of the parallelism and timeouts of discovery
+func (s *srvPool) SetDiscoveryLimits(limits DiscoveryLimits) {
+	s.stats.setLimits(limits)
+}
+
+func (s *srvPool) GetInfos() []common.OrchestratorLocalInfo {
+	return s.getPool().GetInfos()
+}
+
+func (s *srvPool) Size() int {
+	return len(s.GetInfos())
+}
+
+func (s *srvPool) SizeWith(scorePred common.ScorePred) int {
+	return s.getPool().SizeWith(scorePred)
+}
+
+func (s *srvPool) GetOrchestrators(ctx context.Context, numOrchestrators int, suspender common.Suspender, caps common.CapabilityComparator,
+	scorePred common.ScorePred) (common.OrchestratorDescriptors, error) {
+
+	return s.getPool().GetOrchestrators(ctx, numOrchestrators, suspender, caps, scorePred)
+}
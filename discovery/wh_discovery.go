@@ -3,9 +3,11 @@ package discovery
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,11 +15,24 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/golang/glog"
 	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/server"
 )
 
+// Maximum number of pages followed when fetching a paginated webhook response
+const maxWebhookPages = 1000
+
 type webhookResponse struct {
-	Address string  `json:"address,omitempty"`
-	Score   float32 `json:"score,omitempty"`
+	Address         string  `json:"address,omitempty"`
+	Score           float32 `json:"score,omitempty"`
+	CertFingerprint string  `json:"certFingerprint,omitempty"`
+}
+
+// webhookPage is a page of the webhook response
+type webhookPage struct {
+	body []byte
+	etag string
+	// next is the URL of the next page, if any
+	next *url.URL
 }
 
 type webhookPool struct {
@@ -27,6 +42,10 @@ type webhookPool struct {
 	lastRequest  time.Time
 	mu           *sync.RWMutex
 	bcast        common.Broadcaster
+	// stats are shared by the pools created from the webhook responses
+	stats *discoveryStats
+	// pages are the last fetched pages of the webhook response by URL, to make conditional requests for them
+	pages map[string]*webhookPage
 }
 
 func NewWebhookPool(bcast common.Broadcaster, callback *url.URL) *webhookPool {
@@ -34,6 +53,7 @@ func NewWebhookPool(bcast common.Broadcaster, callback *url.URL) *webhookPool {
 		callback: callback,
 		mu:       &sync.RWMutex{},
 		bcast:    bcast,
+		stats:    newDiscoveryStats(DefaultDiscoveryLimits()),
 	}
 	go p.getInfos()
 	return p
@@ -51,12 +71,12 @@ func (w *webhookPool) getInfos() ([]common.OrchestratorLocalInfo, error) {
 	}
 
 	// retrive addrs from webhook if time since lastRequest is more than the refresh interval
-	body, err := getURLsfromWebhook(w.callback)
+	bodies, err := w.fetchPages()
 	if err != nil {
 		return nil, err
 	}
 
-	hash := ethcommon.BytesToHash(crypto.Keccak256(body))
+	hash := ethcommon.BytesToHash(crypto.Keccak256(bodies...))
 	if hash == w.responseHash {
 		w.mu.Lock()
 		w.lastRequest = time.Now()
@@ -65,13 +85,20 @@ func (w *webhookPool) getInfos() ([]common.OrchestratorLocalInfo, error) {
 		return pool.GetInfos(), nil
 	}
 
-	infos, err := deserializeWebhookJSON(body)
-	if err != nil {
-		return nil, err
+	var infos []common.OrchestratorLocalInfo
+	var pins []server.CertPin
+	for _, body := range bodies {
+		pageInfos, pagePins, err := deserializeWebhookJSON(body)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, pageInfos...)
+		pins = append(pins, pagePins...)
 	}
+	server.OrchCertPins.SetDiscovered(pins)
 
 	// pool = NewOrchestratorPool(w.bcast, addrs)
-	pool = &orchestratorPool{infos: infos, bcast: w.bcast}
+	pool = &orchestratorPool{infos: infos, bcast: w.bcast, stats: w.stats}
 
 	w.mu.Lock()
 	w.responseHash = hash
@@ -82,6 +109,59 @@ func (w *webhookPool) getInfos() ([]common.OrchestratorLocalInfo, error) {
 	return infos, nil
 }
 
+// fetchPages returns the bodies of all the pages of the webhook response, following the next page links.
+// Pages that were fetched before are requested conditionally with their ETag, and their last body is reused
+// if they were not modified.
+func (w *webhookPool) fetchPages() ([][]byte, error) {
+	w.mu.RLock()
+	cached := w.pages
+	w.mu.RUnlock()
+
+	pages := make(map[string]*webhookPage)
+	var bodies [][]byte
+	for pageURL := w.callback; pageURL != nil; {
+		key := pageURL.String()
+		if _, ok := pages[key]; ok {
+			return nil, fmt.Errorf("webhook page %v was already fetched", key)
+		}
+		if len(pages) >= maxWebhookPages {
+			return nil, fmt.Errorf("webhook response has more than %v pages", maxWebhookPages)
+		}
+
+		var etag string
+		prev := cached[key]
+		if prev != nil {
+			etag = prev.etag
+		}
+		page, err := getWebhookPage(pageURL, etag)
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			// not modified
+			if prev == nil {
+				return nil, fmt.Errorf("webhook page %v was not modified but was never fetched", key)
+			}
+			page = prev
+		}
+
+		pages[key] = page
+		bodies = append(bodies, page.body)
+		pageURL = page.next
+	}
+
+	w.mu.Lock()
+	w.pages = pages
+	w.mu.Unlock()
+
+	return bodies, nil
+}
+
+// SetDiscoveryLimits sets the limits of the parallelism and timeouts of discovery
+func (w *webhookPool) SetDiscoveryLimits(limits DiscoveryLimits) {
+	w.stats.setLimits(limits)
+}
+
 func (w *webhookPool) GetInfos() []common.OrchestratorLocalInfo {
 	infos, _ := w.getInfos()
 	return infos
@@ -118,32 +198,78 @@ func (w *webhookPool) GetOrchestrators(ctx context.Context, numOrchestrators int
 	return w.pool.GetOrchestrators(ctx, numOrchestrators, suspender, caps, scorePred)
 }
 
-var getURLsfromWebhook = func(cbUrl *url.URL) ([]byte, error) {
+// getWebhookPage fetches a page of the webhook response. If etag is set, the page is only returned if it changed,
+// otherwise nil is returned.
+var getWebhookPage = func(pageURL *url.URL, etag string) (*webhookPage, error) {
 	var httpc = &http.Client{
 		Timeout: 3 * time.Second,
 	}
-	resp, err := httpc.Get(cbUrl.String())
+	req, err := http.NewRequest("GET", pageURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := httpc.Do(req)
 	if err != nil {
 		glog.Error("Unable to make webhook request ", err)
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		return nil, nil
+	}
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		glog.Error("Unable to read response body ", err)
 		return nil, err
 	}
 
-	return body, nil
+	page := &webhookPage{body: body, etag: resp.Header.Get("ETag")}
+	if next := nextPageLink(resp.Header.Values("Link")); next != "" {
+		page.next, err = pageURL.Parse(next)
+		if err != nil {
+			glog.Errorf("Unable to parse next page link %q : %s", next, err)
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// nextPageLink returns the target of the rel="next" link in the Link headers of a response, if any
+func nextPageLink(headers []string) string {
+	for _, header := range headers {
+		for _, link := range strings.Split(header, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(kv) != 2 || !strings.EqualFold(kv[0], "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(kv[1], `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+					}
+				}
+			}
+		}
+	}
+	return ""
 }
 
-func deserializeWebhookJSON(body []byte) ([]common.OrchestratorLocalInfo, error) {
+func deserializeWebhookJSON(body []byte) ([]common.OrchestratorLocalInfo, []server.CertPin, error) {
 	var addrs []webhookResponse
 	if err := json.Unmarshal(body, &addrs); err != nil {
 		glog.Error("Unable to unmarshal JSON ", err)
-		return nil, err
+		return nil, nil, err
 	}
 	var infos []common.OrchestratorLocalInfo
+	var pins []server.CertPin
 	for _, addr := range addrs {
 		if addr.Address == "" {
 			continue
@@ -153,8 +279,16 @@ func deserializeWebhookJSON(body []byte) ([]common.OrchestratorLocalInfo, error)
 			glog.Errorf("Unable to parse address  %q : %s", addr.Address, err)
 			continue
 		}
+		if addr.CertFingerprint != "" {
+			fp, err := server.ParseCertFingerprint(addr.CertFingerprint)
+			if err != nil {
+				glog.Errorf("Unable to parse cert fingerprint of address %q : %s", addr.Address, err)
+				continue
+			}
+			pins = append(pins, server.CertPin{URI: uri, Fingerprint: fp})
+		}
 		infos = append(infos, common.OrchestratorLocalInfo{URL: uri, Score: addr.Score})
 	}
 
-	return infos, nil
+	return infos, pins, nil
 }
@@ -0,0 +1,191 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/monitor"
+)
+
+// ErrSafeMode is returned instead of sending a transaction while the node is in safe mode
+var ErrSafeMode = errors.New("node is in safe mode because of a low ETH balance")
+
+type balanceReader interface {
+	BalanceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// BalanceWatermarks defines the ETH balances of the node account below which the BalanceWatcher raises alerts.
+// A nil watermark disables the corresponding alert.
+type BalanceWatermarks struct {
+	// Low is the balance below which a low balance alert is emitted
+	Low *big.Int
+	// Critical is the balance below which a critical balance alert is emitted
+	Critical *big.Int
+	// SafeMode pauses the gas consuming services while the balance is below Critical
+	SafeMode bool
+}
+
+// BalanceLevel is the level of the ETH balance relative to the watermarks
+type BalanceLevel string
+
+const (
+	BalanceOK       BalanceLevel = "ok"
+	BalanceLow      BalanceLevel = "low"
+	BalanceCritical BalanceLevel = "critical"
+)
+
+// BalanceAlert is emitted by the BalanceWatcher when the ETH balance crosses a watermark
+type BalanceAlert struct {
+	Level    BalanceLevel `json:"level"`
+	Address  string       `json:"address"`
+	Balance  *big.Int     `json:"balance"`
+	SafeMode bool         `json:"safeMode"`
+	Time     time.Time    `json:"time"`
+}
+
+// BalanceWatcher is a service that checks the ETH balance of the node account on every L1 block. It raises alerts
+// when the balance crosses the watermarks and, if enabled, enters a safe mode while the balance is critical so that
+// the gas consuming services are paused instead of failing their transactions repeatedly.
+type BalanceWatcher struct {
+	addr    ethcommon.Address
+	backend balanceReader
+	tw      timeWatcher
+	cfg     BalanceWatermarks
+	alert   func(*BalanceAlert)
+	quit    chan struct{}
+
+	level    BalanceLevel
+	safeMode bool
+	mu       sync.RWMutex
+}
+
+// NewBalanceWatcher creates a BalanceWatcher instance for the account addr
+func NewBalanceWatcher(addr ethcommon.Address, backend balanceReader, tw timeWatcher, cfg BalanceWatermarks) *BalanceWatcher {
+	return &BalanceWatcher{
+		addr:    addr,
+		backend: backend,
+		tw:      tw,
+		cfg:     cfg,
+		quit:    make(chan struct{}),
+		level:   BalanceOK,
+	}
+}
+
+// SetAlertHandler sets the function that the balance alerts are passed to.
+// It should be called before Start.
+func (w *BalanceWatcher) SetAlertHandler(alert func(*BalanceAlert)) {
+	w.alert = alert
+}
+
+// Start checks the balance and kicks off a loop that checks it again on every L1 block
+func (w *BalanceWatcher) Start() error {
+	l1BlockSink := make(chan *big.Int, 10)
+	l1BlockSub := w.tw.SubscribeL1Blocks(l1BlockSink)
+	defer l1BlockSub.Unsubscribe()
+
+	if err := w.checkBalance(); err != nil {
+		glog.Errorf("Error checking ETH balance err=%q", err)
+	}
+
+	for {
+		select {
+		case <-w.quit:
+			glog.Infof("Stopping balance watcher")
+			return nil
+		case err := <-l1BlockSub.Err():
+			if err != nil {
+				glog.Errorf("L1 Block subscription error err=%q", err)
+			}
+		case <-l1BlockSink:
+			if err := w.checkBalance(); err != nil {
+				glog.Errorf("Error checking ETH balance err=%q", err)
+			}
+		}
+	}
+}
+
+// Stop signals the loop to exit gracefully
+func (w *BalanceWatcher) Stop() {
+	close(w.quit)
+}
+
+// SafeMode returns true if the gas consuming services should not send transactions
+func (w *BalanceWatcher) SafeMode() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.safeMode
+}
+
+// Level returns the level of the last checked balance
+func (w *BalanceWatcher) Level() BalanceLevel {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.level
+}
+
+func (w *BalanceWatcher) checkBalance() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	balance, err := w.backend.BalanceAt(ctx, w.addr, nil)
+	if err != nil {
+		return err
+	}
+
+	if monitor.Enabled {
+		monitor.EthBalance(balance)
+	}
+
+	level := balanceLevel(balance, w.cfg)
+	safeMode := w.cfg.SafeMode && level == BalanceCritical
+
+	w.mu.Lock()
+	changed := level != w.level
+	w.level = level
+	w.safeMode = safeMode
+	w.mu.Unlock()
+
+	if monitor.Enabled {
+		monitor.SafeMode(safeMode)
+	}
+
+	if !changed {
+		return nil
+	}
+
+	switch level {
+	case BalanceCritical:
+		glog.Errorf("ETH balance is critical addr=%v balance=%v safeMode=%v", w.addr.Hex(), FormatUnits(balance, "ETH"), safeMode)
+	case BalanceLow:
+		glog.Warningf("ETH balance is low addr=%v balance=%v", w.addr.Hex(), FormatUnits(balance, "ETH"))
+	default:
+		glog.Infof("ETH balance is back above the watermarks addr=%v balance=%v", w.addr.Hex(), FormatUnits(balance, "ETH"))
+	}
+
+	if w.alert != nil {
+		w.alert(&BalanceAlert{
+			Level:    level,
+			Address:  w.addr.Hex(),
+			Balance:  balance,
+			SafeMode: safeMode,
+			Time:     time.Now(),
+		})
+	}
+
+	return nil
+}
+
+func balanceLevel(balance *big.Int, cfg BalanceWatermarks) BalanceLevel {
+	if cfg.Critical != nil && balance.Cmp(cfg.Critical) < 0 {
+		return BalanceCritical
+	}
+	if cfg.Low != nil && balance.Cmp(cfg.Low) < 0 {
+		return BalanceLow
+	}
+	return BalanceOK
+}
@@ -0,0 +1,135 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubBalanceReader struct {
+	balance *big.Int
+	err     error
+	mu      sync.Mutex
+}
+
+func (r *stubBalanceReader) BalanceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (*big.Int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.balance, r.err
+}
+
+func (r *stubBalanceReader) setBalance(balance *big.Int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.balance = balance
+}
+
+func TestBalanceWatcher_CheckBalance(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := ethcommon.HexToAddress("0x1")
+	backend := &stubBalanceReader{balance: big.NewInt(1000)}
+	cfg := BalanceWatermarks{Low: big.NewInt(100), Critical: big.NewInt(10), SafeMode: true}
+	w := NewBalanceWatcher(addr, backend, &stubTimeWatcher{}, cfg)
+	var alerts []*BalanceAlert
+	w.SetAlertHandler(func(alert *BalanceAlert) { alerts = append(alerts, alert) })
+
+	// No alert above the watermarks
+	assert.Nil(w.checkBalance())
+	assert.Equal(BalanceOK, w.Level())
+	assert.False(w.SafeMode())
+	assert.Len(alerts, 0)
+
+	// Low balance
+	backend.setBalance(big.NewInt(50))
+	assert.Nil(w.checkBalance())
+	assert.Equal(BalanceLow, w.Level())
+	assert.False(w.SafeMode())
+	assert.Len(alerts, 1)
+	assert.Equal(BalanceLow, alerts[0].Level)
+	assert.Equal(addr.Hex(), alerts[0].Address)
+	assert.Equal(big.NewInt(50), alerts[0].Balance)
+
+	// Alerts are only emitted when the level changes
+	assert.Nil(w.checkBalance())
+	assert.Len(alerts, 1)
+
+	// Critical balance enters safe mode
+	backend.setBalance(big.NewInt(5))
+	assert.Nil(w.checkBalance())
+	assert.Equal(BalanceCritical, w.Level())
+	assert.True(w.SafeMode())
+	assert.Len(alerts, 2)
+	assert.Equal(BalanceCritical, alerts[1].Level)
+	assert.True(alerts[1].SafeMode)
+
+	// Recovery leaves safe mode
+	backend.setBalance(big.NewInt(1000))
+	assert.Nil(w.checkBalance())
+	assert.Equal(BalanceOK, w.Level())
+	assert.False(w.SafeMode())
+	assert.Len(alerts, 3)
+	assert.Equal(BalanceOK, alerts[2].Level)
+
+	// The level is kept on error
+	backend.err = errors.New("BalanceAt error")
+	assert.EqualError(w.checkBalance(), "BalanceAt error")
+	assert.Equal(BalanceOK, w.Level())
+	assert.Len(alerts, 3)
+}
+
+func TestBalanceWatcher_CheckBalance_SafeModeDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	backend := &stubBalanceReader{balance: big.NewInt(5)}
+	cfg := BalanceWatermarks{Low: big.NewInt(100), Critical: big.NewInt(10)}
+	w := NewBalanceWatcher(ethcommon.Address{}, backend, &stubTimeWatcher{}, cfg)
+
+	assert.Nil(w.checkBalance())
+	assert.Equal(BalanceCritical, w.Level())
+	assert.False(w.SafeMode())
+}
+
+func TestBalanceLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := BalanceWatermarks{Low: big.NewInt(100), Critical: big.NewInt(10)}
+	assert.Equal(BalanceOK, balanceLevel(big.NewInt(100), cfg))
+	assert.Equal(BalanceLow, balanceLevel(big.NewInt(99), cfg))
+	assert.Equal(BalanceLow, balanceLevel(big.NewInt(10), cfg))
+	assert.Equal(BalanceCritical, balanceLevel(big.NewInt(9), cfg))
+
+	// Disabled watermarks
+	assert.Equal(BalanceOK, balanceLevel(big.NewInt(0), BalanceWatermarks{}))
+	assert.Equal(BalanceCritical, balanceLevel(big.NewInt(0), BalanceWatermarks{Critical: big.NewInt(1)}))
+}
+
+func TestBalanceWatcher_Start_Stop(t *testing.T) {
+	assert := assert.New(t)
+
+	backend := &stubBalanceReader{balance: big.NewInt(1000)}
+	tw := &stubTimeWatcher{}
+	cfg := BalanceWatermarks{Critical: big.NewInt(10), SafeMode: true}
+	w := NewBalanceWatcher(ethcommon.Address{}, backend, tw, cfg)
+
+	errCh := make(chan error)
+	go func() { errCh <- w.Start() }()
+	time.Sleep(20 * time.Millisecond)
+	assert.False(w.SafeMode())
+
+	// The balance is checked on every L1 block
+	backend.setBalance(big.NewInt(5))
+	tw.blockSink <- big.NewInt(1)
+	time.Sleep(20 * time.Millisecond)
+	assert.True(w.SafeMode())
+
+	w.Stop()
+	assert.Nil(<-errCh)
+	assert.True(tw.blockSub.(*stubSubscription).unsubscribed)
+}
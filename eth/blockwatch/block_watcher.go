@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,12 +18,28 @@ import (
 	"github.com/golang/glog"
 )
 
-// maxBlocksInGetLogsQuery is the max number of blocks to fetch logs for in a single query. There is
+// maxBlocksInGetLogsQuery is the default max number of blocks to fetch logs for in a single query. There is
 // a hard limit of 10,000 logs returned by a single `eth_getLogs` query by Infura's Ethereum nodes so
 // we need to try and stay below it. Parity, Geth and Alchemy all have much higher limits (if any) on
 // the number of logs returned so Infura is by far the limiting factor.
 var maxBlocksInGetLogsQuery = 60
 
+// backfillRetries is the number of times a failed batch of `eth_getLogs` queries is retried during a backfill
+var backfillRetries = 3
+
+// backfillRetryInterval is the time to wait before retrying a failed batch of `eth_getLogs` queries
+var backfillRetryInterval = 5 * time.Second
+
+// backfillProgressInterval is the min interval at which the progress of a backfill is logged
+var backfillProgressInterval = 10 * time.Second
+
+// subscriptionPollingInterval is the interval at which the Watcher still polls for new blocks while it is notified of
+// them by a subscription, in case a notification is missed
+var subscriptionPollingInterval = time.Minute
+
+// resubscribeInterval is the interval at which the Watcher tries to subscribe to new blocks again after a subscription fails
+var resubscribeInterval = time.Minute
+
 // EventType describes the types of events emitted by blockwatch.Watcher. A block can be discovered
 // and added to our representation of the chain. During a block re-org, a block previously stored
 // can be removed from the list.
@@ -49,6 +67,12 @@ type Config struct {
 	WithLogs            bool
 	Topics              []common.Hash
 	Client              Client
+	// BackfillBlockRange is the max number of blocks to fetch logs for in a single `eth_getLogs` query during a
+	// backfill. Defaults to maxBlocksInGetLogsQuery
+	BackfillBlockRange int
+	// BackfillConcurrency is the number of `eth_getLogs` queries sent concurrently during a backfill. Defaults to
+	// getLogsRequestChunkSize
+	BackfillConcurrency int
 }
 
 // Watcher maintains a consistent representation of the latest `blockRetentionLimit` blocks,
@@ -64,8 +88,13 @@ type Watcher struct {
 	wasStartedOnce      bool                    // Whether the block watcher has previously been started
 	pollingInterval     time.Duration
 	ticker              *time.Ticker
+	noSubscriptions     bool // Whether the client doesn't support subscriptions to new blocks
 	withLogs            bool
 	topics              []common.Hash
+	backfillBlockRange  int
+	backfillConcurrency int
+	reconnectFeed       event.Feed
+	syncFailing         bool // Whether the last sync failed
 	sync.RWMutex
 }
 
@@ -73,6 +102,15 @@ type Watcher struct {
 func New(config Config) *Watcher {
 	stack := NewStack(config.Store, config.BlockRetentionLimit)
 
+	backfillBlockRange := config.BackfillBlockRange
+	if backfillBlockRange <= 0 {
+		backfillBlockRange = maxBlocksInGetLogsQuery
+	}
+	backfillConcurrency := config.BackfillConcurrency
+	if backfillConcurrency <= 0 {
+		backfillConcurrency = getLogsRequestChunkSize
+	}
+
 	bs := &Watcher{
 		pollingInterval:     config.PollingInterval,
 		blockRetentionLimit: config.BlockRetentionLimit,
@@ -81,28 +119,30 @@ func New(config Config) *Watcher {
 		client:              config.Client,
 		withLogs:            config.WithLogs,
 		topics:              config.Topics,
+		backfillBlockRange:  backfillBlockRange,
+		backfillConcurrency: backfillConcurrency,
 	}
 	return bs
 }
 
 // BackfillEvents finds missed events and sends them to event subscribers.
 // It blocks until it is done backfilling or the given context is canceled.
+// The missed blocks are backfilled in batches of ranged `eth_getLogs` queries. The events of each batch are sent
+// as soon as the batch is fetched, and the last block of the batch is then stored as a checkpoint, so that a
+// backfill that is interrupted resumes from the last checkpoint instead of starting over.
 // Note that the latest block is never backfilled here from logs. It will be polled separately in syncToLatestBlock().
 // The reason for that is that we always need to propagate events from the latest block even if it does not contain
 // events which are filtered out during the backfilling process.
 func (w *Watcher) BackfillEvents(ctx context.Context) error {
-	events, err := w.getMissedEventsToBackfill(ctx)
-	if err != nil {
-		return err
-	}
-	if len(events) > 0 {
+	return w.backfillMissedEvents(ctx, func(events []*Event) {
 		w.blockFeed.Send(w.enrichWithL1(events))
-	}
-	return nil
+	})
 }
 
 // Watch starts the Watcher. It will continuously look for new blocks and blocks
 // until the given context is canceled. Typically, you want to call Watch inside a goroutine.
+// If the client supports it, the Watcher syncs as soon as it is notified of a new block and only
+// polls every subscriptionPollingInterval. Otherwise, it polls every pollingInterval.
 func (w *Watcher) Watch(ctx context.Context) error {
 	w.Lock()
 	if w.wasStartedOnce {
@@ -113,17 +153,84 @@ func (w *Watcher) Watch(ctx context.Context) error {
 	w.Unlock()
 
 	ticker := time.NewTicker(w.pollingInterval)
+	defer ticker.Stop()
+
+	headers := make(chan *types.Header, 10)
+	sub := w.subscribeNewHeads(ctx, headers)
+	lastSubscribe := time.Now()
+	var lastSync time.Time
+	syncBlocks := func() {
+		lastSync = time.Now()
+		w.syncBlocks(ctx)
+	}
+
 	for {
+		// A nil channel blocks, so the subscription is ignored while polling
+		var subErr <-chan error
+		if sub != nil {
+			subErr = sub.Err()
+		}
+
 		select {
 		case <-ctx.Done():
-			ticker.Stop()
+			if sub != nil {
+				sub.Unsubscribe()
+			}
 			return nil
+		case err := <-subErr:
+			glog.Errorf("blockwatch.Watcher new block subscription failed - falling back to polling err=%q", err)
+			sub.Unsubscribe()
+			sub = nil
+			lastSubscribe = time.Now()
+		case <-headers:
+			syncBlocks()
 		case <-ticker.C:
-			if err := w.syncToLatestBlock(ctx); err != nil {
-				glog.Errorf("blockwatch.Watcher error encountered - trying again on next polling interval err=%q", err)
+			if sub == nil && time.Since(lastSubscribe) >= resubscribeInterval {
+				sub = w.subscribeNewHeads(ctx, headers)
+				lastSubscribe = time.Now()
+			}
+			if sub != nil && time.Since(lastSync) < subscriptionPollingInterval {
+				continue
 			}
+			syncBlocks()
+		}
+	}
+}
+
+// syncBlocks syncs to the latest block and notifies the reconnection subscribers when it succeeds after failing,
+// since the events of the blocks synced while the RPC node was unreachable may not have been processed
+func (w *Watcher) syncBlocks(ctx context.Context) {
+	if err := w.syncToLatestBlock(ctx); err != nil {
+		glog.Errorf("blockwatch.Watcher error encountered - trying again on next polling interval err=%q", err)
+		w.syncFailing = true
+		return
+	}
+	if w.syncFailing {
+		w.syncFailing = false
+		glog.Infof("blockwatch.Watcher reconnected - notifying watchers to reconcile their state")
+		w.reconnectFeed.Send(struct{}{})
+	}
+}
+
+// subscribeNewHeads subscribes to notifications about new blocks if the client supports it. It returns nil if the
+// Watcher needs to poll for new blocks instead.
+func (w *Watcher) subscribeNewHeads(ctx context.Context, headers chan<- *types.Header) ethereum.Subscription {
+	subscriber, ok := w.client.(HeaderSubscriber)
+	if !ok || w.noSubscriptions {
+		return nil
+	}
+	sub, err := subscriber.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+			w.noSubscriptions = true
+			glog.Infof("blockwatch.Watcher polling for new blocks every %v", w.pollingInterval)
+			return nil
 		}
+		glog.Errorf("blockwatch.Watcher unable to subscribe to new blocks - falling back to polling err=%q", err)
+		return nil
 	}
+	glog.Infof("blockwatch.Watcher subscribed to new blocks")
+	return sub
 }
 
 // Subscribe allows one to subscribe to the block events emitted by the Watcher.
@@ -134,6 +241,13 @@ func (w *Watcher) Subscribe(sink chan<- []*Event) event.Subscription {
 	return w.blockScope.Track(w.blockFeed.Subscribe(sink))
 }
 
+// SubscribeReconnects allows one to be notified when the Watcher syncs again after failing to sync, so that
+// the state derived from block events can be reconciled with the chain.
+// To unsubscribe, simply call `Unsubscribe` on the returned subscription.
+func (w *Watcher) SubscribeReconnects(sink chan<- struct{}) event.Subscription {
+	return w.blockScope.Track(w.reconnectFeed.Subscribe(sink))
+}
+
 // GetLatestBlock returns the latest block processed
 func (w *Watcher) GetLatestBlock() (*MiniHeader, error) {
 	h, err := w.stack.Peek()
@@ -310,102 +424,164 @@ func (w *Watcher) addLogs(header *MiniHeader) (*MiniHeader, error) {
 	return header, nil
 }
 
-// getMissedEventsToBackfill finds missed events that might have occurred since the last block polling.
+// backfillMissedEvents finds missed events that might have occurred since the last block polling.
 // It does this by comparing the last block stored with the latest block discoverable via RPC.
-// If the stored block is older than the latest block, it batch-fetches the events for missing blocks,
-// re-sets the stored blocks and returns the block events found.
+// If the stored block is older than the latest block, it batch-fetches the events for missing blocks and
+// passes the block events found in each batch to sink, before storing the last block of the batch.
 // Note that the latest block is never backfilled, and will be polled separately during in syncToLatestBlock().
-func (w *Watcher) getMissedEventsToBackfill(ctx context.Context) ([]*Event, error) {
-	events := []*Event{}
-
-	var (
-		startBlockNum          int
-		latestRetainedBlockNum int
-		blocksElapsed          int
-	)
-
+func (w *Watcher) backfillMissedEvents(ctx context.Context, sink func([]*Event)) error {
 	latestRetainedBlock, err := w.stack.Peek()
 	if err != nil {
-		return events, err
+		return err
+	}
+	if latestRetainedBlock == nil {
+		return nil
 	}
 
 	latestBlock, err := w.client.HeaderByNumber(nil)
 	if err != nil {
-		return events, err
+		return err
 	}
 
+	// Events for latestRetainedBlock already processed, start at latestRetainedBlock + 1
+	startBlockNum := int(latestRetainedBlock.Number.Int64()) + 1
 	// Latest block will be polled separately in syncToLatestBlock(), so it's not backfilled.
 	preLatestBlockNum := int(latestBlock.Number.Int64()) - 1
 
-	if latestRetainedBlock != nil {
-		latestRetainedBlockNum = int(latestRetainedBlock.Number.Int64())
-		// Events for latestRetainedBlock already processed, start at latestRetainedBlock + 1
-		startBlockNum = latestRetainedBlockNum + 1
-	} else {
-		return events, nil
-	}
-
-	if blocksElapsed = preLatestBlockNum - startBlockNum; blocksElapsed <= 0 {
-		return events, nil
+	if preLatestBlockNum-startBlockNum <= 0 {
+		return nil
 	}
 
-	logs, furthestBlockProcessed := w.getLogsInBlockRange(ctx, startBlockNum, preLatestBlockNum)
-	if furthestBlockProcessed > latestRetainedBlockNum {
-		// If we have processed blocks further then the latestRetainedBlock in the DB, we
-		// want to remove all blocks from the DB and insert the furthestBlockProcessed
-		// Doing so will cause the BlockWatcher to start from that furthestBlockProcessed.
-		headers, err := w.InspectRetainedBlocks()
-		if err != nil {
-			return events, err
+	progress := newBackfillProgress(startBlockNum, preLatestBlockNum)
+	batchSize := w.backfillBlockRange * w.backfillConcurrency
+	for from := startBlockNum; from <= preLatestBlockNum; {
+		to := from + batchSize - 1
+		if to > preLatestBlockNum {
+			to = preLatestBlockNum
 		}
-		for i := 0; i < len(headers); i++ {
-			_, err := w.stack.Pop()
-			if err != nil {
-				return events, err
+
+		logs, furthestBlockProcessed, err := w.getLogsInBlockRangeWithRetries(ctx, from, to)
+		if furthestBlockProcessed >= from {
+			if events := eventsFromLogs(logs); len(events) > 0 {
+				sink(events)
+			}
+			if err := w.checkpoint(furthestBlockProcessed); err != nil {
+				return err
 			}
+			progress.update(furthestBlockProcessed)
 		}
-		// Add furthest block processed into the DB
-		latestHeader, err := w.client.HeaderByNumber(big.NewInt(int64(furthestBlockProcessed)))
 		if err != nil {
-			return events, err
+			return err
 		}
-		err = w.stack.Push(latestHeader)
-		if err != nil {
-			return events, err
+
+		from = to + 1
+	}
+	progress.done()
+
+	return nil
+}
+
+// getLogsInBlockRangeWithRetries fetches the logs in the block range, retrying from the furthest block processed
+// when a query fails. It returns an error if the range could not be fetched after backfillRetries retries.
+func (w *Watcher) getLogsInBlockRangeWithRetries(ctx context.Context, from, to int) ([]types.Log, int, error) {
+	allLogs := []types.Log{}
+	furthestBlockProcessed := from - 1
+	for attempt := 0; ; attempt++ {
+		logs, furthest := w.getLogsInBlockRange(ctx, furthestBlockProcessed+1, to)
+		allLogs = append(allLogs, logs...)
+		furthestBlockProcessed = furthest
+		if furthestBlockProcessed >= to {
+			return allLogs, furthestBlockProcessed, nil
+		}
+		if attempt >= backfillRetries {
+			return allLogs, furthestBlockProcessed, fmt.Errorf("unable to fetch the logs of blocks %d-%d", furthestBlockProcessed+1, to)
 		}
 
-		// If no logs found, noop
-		if len(logs) == 0 {
-			return events, nil
+		select {
+		case <-time.After(backfillRetryInterval):
+		case <-ctx.Done():
+			return allLogs, furthestBlockProcessed, ctx.Err()
 		}
+	}
+}
 
-		// Create the block events from all the logs found by grouping
-		// them into blockHeaders
-		hashToBlockHeader := map[common.Hash]*MiniHeader{}
-		for _, log := range logs {
-			blockHeader, ok := hashToBlockHeader[log.BlockHash]
-			if !ok {
-				// TODO: Find a way to include the parent hash for the block as well.
-				// It's currently not an issue to omit it since we don't use the parent hash
-				// when processing block events in event watcher services
-				blockHeader = &MiniHeader{
-					Hash:   log.BlockHash,
-					Number: big.NewInt(0).SetUint64(log.BlockNumber),
-					Logs:   []types.Log{},
-				}
-				hashToBlockHeader[log.BlockHash] = blockHeader
-			}
-			blockHeader.Logs = append(blockHeader.Logs, log)
+// checkpoint replaces the retained blocks with the header of blockNum, so that the Watcher resumes from that block
+func (w *Watcher) checkpoint(blockNum int) error {
+	header, err := w.client.HeaderByNumber(big.NewInt(int64(blockNum)))
+	if err != nil {
+		return err
+	}
+
+	headers, err := w.InspectRetainedBlocks()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(headers); i++ {
+		if _, err := w.stack.Pop(); err != nil {
+			return err
 		}
-		for _, blockHeader := range hashToBlockHeader {
+	}
+
+	return w.stack.Push(header)
+}
+
+// eventsFromLogs creates the block events from the logs by grouping them into block headers sorted by number
+func eventsFromLogs(logs []types.Log) []*Event {
+	events := []*Event{}
+	hashToBlockHeader := map[common.Hash]*MiniHeader{}
+	for _, log := range logs {
+		blockHeader, ok := hashToBlockHeader[log.BlockHash]
+		if !ok {
+			// TODO: Find a way to include the parent hash for the block as well.
+			// It's currently not an issue to omit it since we don't use the parent hash
+			// when processing block events in event watcher services
+			blockHeader = &MiniHeader{
+				Hash:   log.BlockHash,
+				Number: big.NewInt(0).SetUint64(log.BlockNumber),
+				Logs:   []types.Log{},
+			}
+			hashToBlockHeader[log.BlockHash] = blockHeader
 			events = append(events, &Event{
 				Type:        Added,
 				BlockHeader: blockHeader,
 			})
 		}
-		return events, nil
+		blockHeader.Logs = append(blockHeader.Logs, log)
 	}
-	return events, nil
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].BlockHeader.Number.Cmp(events[j].BlockHeader.Number) < 0
+	})
+	return events
+}
+
+// backfillProgress logs the progress of a backfill at most every backfillProgressInterval
+type backfillProgress struct {
+	from, to int
+	start    time.Time
+	lastLog  time.Time
+}
+
+func newBackfillProgress(from, to int) *backfillProgress {
+	glog.Infof("Backfilling block events fromBlock=%v toBlock=%v", from, to)
+	now := time.Now()
+	return &backfillProgress{from: from, to: to, start: now, lastLog: now}
+}
+
+func (p *backfillProgress) update(block int) {
+	if time.Since(p.lastLog) < backfillProgressInterval {
+		return
+	}
+	p.lastLog = time.Now()
+
+	done := block - p.from + 1
+	total := p.to - p.from + 1
+	elapsed := time.Since(p.start)
+	remaining := time.Duration(float64(elapsed) / float64(done) * float64(total-done))
+	glog.Infof("Backfilling block events block=%v/%v progress=%.1f%% remaining=%v", block, p.to, 100*float64(done)/float64(total), remaining.Round(time.Second))
+}
+
+func (p *backfillProgress) done() {
+	glog.Infof("Backfilled block events fromBlock=%v toBlock=%v took=%v", p.from, p.to, time.Since(p.start).Round(time.Millisecond))
 }
 
 type logRequestResult struct {
@@ -415,7 +591,7 @@ type logRequestResult struct {
 	Err  error
 }
 
-// getLogsRequestChunkSize is the number of `eth_getLogs` JSON RPC to send concurrently in each batch fetch
+// getLogsRequestChunkSize is the default number of `eth_getLogs` JSON RPC to send concurrently in each batch fetch
 const getLogsRequestChunkSize = 3
 
 // getLogsInBlockRange attempts to fetch all logs in the block range supplied. It implements a
@@ -424,16 +600,16 @@ const getLogsRequestChunkSize = 3
 // batch requests are not sent. Instead, it returns all the logs it found up until the error was
 // encountered, along with the block number after which no further logs were retrieved.
 func (w *Watcher) getLogsInBlockRange(ctx context.Context, from, to int) ([]types.Log, int) {
-	blockRanges := w.getSubBlockRanges(from, to, maxBlocksInGetLogsQuery)
+	blockRanges := w.getSubBlockRanges(from, to, w.backfillBlockRange)
 
 	numChunks := 0
 	chunkChan := make(chan []*blockRange, 1000000)
 	for len(blockRanges) != 0 {
 		var chunk []*blockRange
-		if len(blockRanges) < getLogsRequestChunkSize {
+		if len(blockRanges) < w.backfillConcurrency {
 			chunk = blockRanges[:len(blockRanges)]
 		} else {
-			chunk = blockRanges[:getLogsRequestChunkSize]
+			chunk = blockRanges[:w.backfillConcurrency]
 		}
 		chunkChan <- chunk
 		blockRanges = blockRanges[len(chunk):]
@@ -563,6 +739,25 @@ func (w *Watcher) getSubBlockRanges(from, to, rangeSize int) []*blockRange {
 
 const infuraTooManyResultsErrMsg = "query returned more than 10000 results"
 
+// tooManyResultsErrMsgs are parts of the errors returned by providers when an `eth_getLogs` query returns too many
+// logs or spans too many blocks, in which case the query is split in two
+var tooManyResultsErrMsgs = []string{
+	infuraTooManyResultsErrMsg,
+	// Alchemy
+	"response size exceeded",
+	// Providers limiting the block range of a query
+	"block range",
+}
+
+func isTooManyResultsErr(err error) bool {
+	for _, msg := range tooManyResultsErrMsgs {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
 func (w *Watcher) filterLogsRecursively(from, to int, allLogs []types.Log) ([]types.Log, error) {
 	glog.V(6).Infof("Polling blocks from=%v to=%v", from, to)
 	numBlocks := to - from
@@ -577,9 +772,9 @@ func (w *Watcher) filterLogsRecursively(from, to int, allLogs []types.Log) ([]ty
 	})
 	if err != nil {
 		// Infura caps the logs returned to 10,000 per request, if our request exceeds this limit, split it
-		// into two requests. Parity, Geth and Alchemy all have much higher limits (if any at all), so no need
-		// to expect any similar errors of this nature from them.
-		if err.Error() == infuraTooManyResultsErrMsg {
+		// into two requests. Other providers cap the response size or the block range of a request, which
+		// is handled the same way.
+		if isTooManyResultsErr(err) {
 			// HACK(fabio): Infura limits the returned results to 10,000 logs, BUT some single
 			// blocks contain more then 10,000 logs. This has supposedly been fixed but we keep
 			// this logic here just in case. It helps us avoid infinite recursion.
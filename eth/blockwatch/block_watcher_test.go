@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -99,6 +102,188 @@ func TestWatcherStartStop(t *testing.T) {
 	}
 }
 
+// fakeSubscribeClient is a fakeClient that notifies the Watcher of new blocks through the channels passed to SubscribeNewHead
+type fakeSubscribeClient struct {
+	*fakeClient
+	err        error
+	subErr     chan error
+	subscribed chan chan<- *types.Header
+
+	headerCalls    int64
+	subscribeCalls int64
+}
+
+func (c *fakeSubscribeClient) HeaderByNumber(number *big.Int) (*MiniHeader, error) {
+	atomic.AddInt64(&c.headerCalls, 1)
+	return c.fakeClient.HeaderByNumber(number)
+}
+
+func (c *fakeSubscribeClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	atomic.AddInt64(&c.subscribeCalls, 1)
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.subscribed <- ch
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case err := <-c.subErr:
+			return err
+		case <-quit:
+			return nil
+		}
+	}), nil
+}
+
+func newFakeSubscribeClient(t *testing.T) *fakeSubscribeClient {
+	fakeClient, err := newFakeClient(basicFakeClientFixture)
+	require.NoError(t, err)
+	return &fakeSubscribeClient{
+		fakeClient: fakeClient,
+		subErr:     make(chan error),
+		subscribed: make(chan chan<- *types.Header, 1),
+	}
+}
+
+func TestWatcherSubscription(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func(d time.Duration) { subscriptionPollingInterval = d }(subscriptionPollingInterval)
+	defer func(d time.Duration) { resubscribeInterval = d }(resubscribeInterval)
+	subscriptionPollingInterval = time.Hour
+	resubscribeInterval = 300 * time.Millisecond
+
+	client := newFakeSubscribeClient(t)
+	cfg := config
+	cfg.PollingInterval = 10 * time.Millisecond
+	cfg.Store = &stubMiniHeaderStore{}
+	cfg.Client = client
+	watcher := New(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watcher.Watch(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	var headers chan<- *types.Header
+	select {
+	case headers = <-client.subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for subscription")
+	}
+
+	// The watcher syncs once when it starts, then stops polling while it is subscribed
+	require.Eventually(func() bool { return atomic.LoadInt64(&client.headerCalls) > 0 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	calls := atomic.LoadInt64(&client.headerCalls)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(calls, atomic.LoadInt64(&client.headerCalls))
+
+	// A new block notification triggers a sync
+	headers <- &types.Header{Number: big.NewInt(6)}
+	assert.Eventually(func() bool { return atomic.LoadInt64(&client.headerCalls) > calls }, time.Second, 10*time.Millisecond)
+
+	// The watcher falls back to polling when the subscription fails
+	client.subErr <- errors.New("connection lost")
+	calls = atomic.LoadInt64(&client.headerCalls)
+	assert.Eventually(func() bool { return atomic.LoadInt64(&client.headerCalls) > calls+5 }, time.Second, 10*time.Millisecond)
+	assert.Equal(int64(1), atomic.LoadInt64(&client.subscribeCalls))
+
+	// and subscribes again after resubscribeInterval
+	select {
+	case <-client.subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for resubscription")
+	}
+}
+
+func TestWatcherSubscription_Unsupported(t *testing.T) {
+	defer func(d time.Duration) { resubscribeInterval = d }(resubscribeInterval)
+	resubscribeInterval = 0
+
+	client := newFakeSubscribeClient(t)
+	client.err = rpc.ErrNotificationsUnsupported
+	cfg := config
+	cfg.PollingInterval = 10 * time.Millisecond
+	cfg.Store = &stubMiniHeaderStore{}
+	cfg.Client = client
+	watcher := New(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watcher.Watch(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// The watcher polls without trying to subscribe again
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&client.headerCalls) > 5 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&client.subscribeCalls))
+}
+
+// failingClient is a Client whose HeaderByNumber calls fail while fail is true
+type failingClient struct {
+	Client
+	fail bool
+}
+
+func (c *failingClient) HeaderByNumber(number *big.Int) (*MiniHeader, error) {
+	if c.fail {
+		return nil, errUnexpected
+	}
+	return c.Client.HeaderByNumber(number)
+}
+
+func TestWatcherSyncBlocks_Reconnect(t *testing.T) {
+	fakeClient, err := newFakeClient(basicFakeClientFixture)
+	require.NoError(t, err)
+	client := &failingClient{Client: fakeClient}
+	cfg := config
+	cfg.Store = &stubMiniHeaderStore{}
+	cfg.Client = client
+	watcher := New(cfg)
+
+	sink := make(chan struct{}, 1)
+	sub := watcher.SubscribeReconnects(sink)
+	defer sub.Unsubscribe()
+
+	notified := func() bool {
+		select {
+		case <-sink:
+			return true
+		default:
+			return false
+		}
+	}
+
+	ctx := context.Background()
+	watcher.syncBlocks(ctx)
+	assert.False(t, notified())
+
+	// Subscribers are notified on the first successful sync after a failure
+	client.fail = true
+	watcher.syncBlocks(ctx)
+	watcher.syncBlocks(ctx)
+	assert.False(t, notified())
+
+	client.fail = false
+	watcher.syncBlocks(ctx)
+	assert.True(t, notified())
+
+	watcher.syncBlocks(ctx)
+	assert.False(t, notified())
+}
+
 type blockRangeChunksTestCase struct {
 	from                int
 	to                  int
@@ -182,7 +367,7 @@ func TestGetSubBlockRanges(t *testing.T) {
 	}
 }
 
-func TestGetMissedEventsToBackfillSomeMissed(t *testing.T) {
+func TestBackfillMissedEventsSomeMissed(t *testing.T) {
 	// Fixture will return block 30 as the tip of the chain
 	fakeClient, err := newFakeClient("testdata/fake_client_fast_sync_fixture.json")
 	require.NoError(t, err)
@@ -204,7 +389,7 @@ func TestGetMissedEventsToBackfillSomeMissed(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	events, err := watcher.getMissedEventsToBackfill(ctx)
+	events, err := collectBackfilledEvents(ctx, watcher)
 	require.NoError(t, err)
 	assert.Len(t, events, 1)
 
@@ -215,7 +400,7 @@ func TestGetMissedEventsToBackfillSomeMissed(t *testing.T) {
 	assert.Equal(t, big.NewInt(29), headers[0].Number)
 }
 
-func TestGetMissedEventsToBackfillNoneMissed(t *testing.T) {
+func TestBackfillMissedEventsNoneMissed(t *testing.T) {
 	// Fixture will return block 5 as the tip of the chain
 	fakeClient, err := newFakeClient("testdata/fake_client_basic_fixture.json")
 	require.NoError(t, err)
@@ -236,7 +421,7 @@ func TestGetMissedEventsToBackfillNoneMissed(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	events, err := watcher.getMissedEventsToBackfill(ctx)
+	events, err := collectBackfilledEvents(ctx, watcher)
 	require.NoError(t, err)
 	assert.Len(t, events, 0)
 
@@ -247,7 +432,7 @@ func TestGetMissedEventsToBackfillNoneMissed(t *testing.T) {
 	assert.Equal(t, big.NewInt(5), headers[0].Number)
 }
 
-func TestGetMissedEventsToBackfill_NOOP(t *testing.T) {
+func TestBackfillMissedEvents_NOOP(t *testing.T) {
 	// No last retained block
 	// No config.BackfillStartBlock
 
@@ -262,7 +447,7 @@ func TestGetMissedEventsToBackfill_NOOP(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	events, err := watcher.getMissedEventsToBackfill(ctx)
+	events, err := collectBackfilledEvents(ctx, watcher)
 	require.NoError(t, err)
 	assert.Len(t, events, 0)
 
@@ -271,6 +456,138 @@ func TestGetMissedEventsToBackfill_NOOP(t *testing.T) {
 	require.Len(t, headers, 0)
 }
 
+// backfillLogClient is a fakeLogClient that also returns the headers of the blocks up to latest
+type backfillLogClient struct {
+	*fakeLogClient
+	latest int64
+}
+
+func (c *backfillLogClient) HeaderByNumber(number *big.Int) (*MiniHeader, error) {
+	if number == nil {
+		number = big.NewInt(c.latest)
+	}
+	return &MiniHeader{Number: number, Hash: common.BigToHash(number)}, nil
+}
+
+func backfillLog(blockNum int64) types.Log {
+	return types.Log{BlockNumber: uint64(blockNum), BlockHash: common.BigToHash(big.NewInt(blockNum))}
+}
+
+func collectBackfilledEvents(ctx context.Context, w *Watcher) ([]*Event, error) {
+	var events []*Event
+	err := w.backfillMissedEvents(ctx, func(batch []*Event) {
+		events = append(events, batch...)
+	})
+	return events, err
+}
+
+func TestBackfillMissedEvents_Batches(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fakeLogClient, err := newFakeLogClient(map[string]filterLogsResponse{
+		aRange(10, 14): {Logs: []types.Log{backfillLog(14), backfillLog(12), backfillLog(14)}},
+		aRange(15, 19): {},
+		aRange(20, 24): {Logs: []types.Log{backfillLog(20)}},
+		aRange(25, 29): {},
+		aRange(30, 30): {Logs: []types.Log{backfillLog(30)}},
+	})
+	require.NoError(err)
+
+	store := &stubMiniHeaderStore{}
+	require.NoError(store.InsertMiniHeader(&MiniHeader{Number: big.NewInt(9), Hash: common.BigToHash(big.NewInt(9))}))
+
+	cfg := config
+	cfg.Store = store
+	cfg.Client = &backfillLogClient{fakeLogClient: fakeLogClient, latest: 31}
+	cfg.BackfillBlockRange = 5
+	cfg.BackfillConcurrency = 2
+	watcher := New(cfg)
+
+	var batches [][]*Event
+	var checkpoints []int64
+	err = watcher.backfillMissedEvents(context.Background(), func(events []*Event) {
+		batches = append(batches, events)
+		header, err := watcher.stack.Peek()
+		require.NoError(err)
+		checkpoints = append(checkpoints, header.Number.Int64())
+	})
+	require.NoError(err)
+	assert.Equal(5, fakeLogClient.Count())
+
+	// The events of each batch are sent, sorted by block number, before the batch is checkpointed
+	require.Len(batches, 3)
+	require.Len(batches[0], 2)
+	assert.Equal(big.NewInt(12), batches[0][0].BlockHeader.Number)
+	assert.Equal(big.NewInt(14), batches[0][1].BlockHeader.Number)
+	assert.Len(batches[0][1].BlockHeader.Logs, 2)
+	require.Len(batches[1], 1)
+	assert.Equal(big.NewInt(20), batches[1][0].BlockHeader.Number)
+	require.Len(batches[2], 1)
+	assert.Equal(big.NewInt(30), batches[2][0].BlockHeader.Number)
+	assert.Equal([]int64{9, 19, 29}, checkpoints)
+
+	headers, err := store.FindAllMiniHeadersSortedByNumber()
+	require.NoError(err)
+	require.Len(headers, 1)
+	assert.Equal(big.NewInt(30), headers[0].Number)
+}
+
+func TestBackfillMissedEvents_ResumeFromCheckpoint(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func(retries int, interval time.Duration) {
+		backfillRetries = retries
+		backfillRetryInterval = interval
+	}(backfillRetries, backfillRetryInterval)
+	backfillRetries = 1
+	backfillRetryInterval = time.Millisecond
+
+	fakeLogClient, err := newFakeLogClient(map[string]filterLogsResponse{
+		aRange(10, 14): {Logs: []types.Log{backfillLog(12)}},
+		aRange(15, 19): {},
+		aRange(20, 24): {Err: errUnexpected},
+		aRange(25, 29): {},
+	})
+	require.NoError(err)
+
+	store := &stubMiniHeaderStore{}
+	require.NoError(store.InsertMiniHeader(&MiniHeader{Number: big.NewInt(9), Hash: common.BigToHash(big.NewInt(9))}))
+
+	cfg := config
+	cfg.Store = store
+	cfg.Client = &backfillLogClient{fakeLogClient: fakeLogClient, latest: 31}
+	cfg.BackfillBlockRange = 5
+	cfg.BackfillConcurrency = 2
+	watcher := New(cfg)
+
+	events, err := collectBackfilledEvents(context.Background(), watcher)
+	assert.EqualError(err, "unable to fetch the logs of blocks 20-29")
+	require.Len(events, 1)
+	assert.Equal(big.NewInt(12), events[0].BlockHeader.Number)
+	// The failed range is retried once
+	assert.Equal(6, fakeLogClient.Count())
+
+	// The backfill resumes from the last checkpoint
+	headers, err := store.FindAllMiniHeadersSortedByNumber()
+	require.NoError(err)
+	require.Len(headers, 1)
+	assert.Equal(big.NewInt(19), headers[0].Number)
+
+	fakeLogClient.rangeToResponse[aRange(20, 24)] = filterLogsResponse{Logs: []types.Log{backfillLog(21)}}
+	fakeLogClient.rangeToResponse[aRange(30, 30)] = filterLogsResponse{}
+	events, err = collectBackfilledEvents(context.Background(), watcher)
+	require.NoError(err)
+	require.Len(events, 1)
+	assert.Equal(big.NewInt(21), events[0].BlockHeader.Number)
+
+	headers, err = store.FindAllMiniHeadersSortedByNumber()
+	require.NoError(err)
+	require.Len(headers, 1)
+	assert.Equal(big.NewInt(30), headers[0].Number)
+}
+
 var logStub = types.Log{
 	Address: common.HexToAddress("0x21ab6c9fac80c59d401b37cb43f81ea9dde7fe34"),
 	Topics: []common.Hash{
@@ -374,6 +691,29 @@ func TestFilterLogsRecursively(t *testing.T) {
 			},
 			Err: errUnexpected,
 		},
+		filterLogsRecursivelyTestCase{
+			Label: "BLOCK_RANGE_LIMIT_ERROR",
+			rangeToFilterLogsResponse: map[string]filterLogsResponse{
+				"10-20": filterLogsResponse{
+					Err: errors.New("exceed maximum block range: 5"),
+				},
+				"10-15": filterLogsResponse{
+					Err: errors.New("exceed maximum block range: 5"),
+				},
+				"10-12": filterLogsResponse{
+					Logs: []types.Log{
+						logStub,
+					},
+				},
+				"13-15": filterLogsResponse{},
+				"16-20": filterLogsResponse{
+					Logs: []types.Log{
+						logStub,
+					},
+				},
+			},
+			Logs: []types.Log{logStub, logStub},
+		},
 		filterLogsRecursivelyTestCase{
 			Label: "UNEXPECTED_ERROR",
 			rangeToFilterLogsResponse: map[string]filterLogsResponse{
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -24,25 +25,26 @@ type Client interface {
 	FilterLogs(q ethereum.FilterQuery) ([]types.Log, error)
 }
 
+// HeaderSubscriber is implemented by the clients that can notify the Watcher of new block headers,
+// so that it doesn't need to poll for them
+type HeaderSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
 // RPCClient is a Client for fetching Ethereum blocks from a specific JSON-RPC endpoint.
 type RPCClient struct {
 	rpcClient      *rpc.Client
 	client         *ethclient.Client
 	requestTimeout time.Duration
+	// Whether the endpoint is a WebSocket endpoint which supports subscriptions
+	subscriptions bool
 }
 
-// NewRPCClient returns a new Client for fetching Ethereum blocks using the given
-// ethclient.Client.
-func NewRPCClient(rpcURL string, requestTimeout time.Duration) (*RPCClient, error) {
-	ethClient, err := ethclient.Dial(rpcURL)
-	if err != nil {
-		return nil, err
-	}
-	rpcClient, err := rpc.Dial(rpcURL)
-	if err != nil {
-		return nil, err
-	}
-	return &RPCClient{rpcClient: rpcClient, client: ethClient, requestTimeout: requestTimeout}, nil
+// NewRPCClient returns a new Client for fetching Ethereum blocks from the JSON-RPC endpoint rpcURL
+// using the given rpc.Client connected to it.
+func NewRPCClient(rpcURL string, rpcClient *rpc.Client, requestTimeout time.Duration) *RPCClient {
+	subscriptions := strings.HasPrefix(rpcURL, "ws://") || strings.HasPrefix(rpcURL, "wss://")
+	return &RPCClient{rpcClient: rpcClient, client: ethclient.NewClient(rpcClient), requestTimeout: requestTimeout, subscriptions: subscriptions}
 }
 
 type getHeaderResponse struct {
@@ -118,3 +120,14 @@ func (rc *RPCClient) FilterLogs(q ethereum.FilterQuery) ([]types.Log, error) {
 	}
 	return logs, nil
 }
+
+// SubscribeNewHead subscribes to notifications about new block headers. It returns rpc.ErrNotificationsUnsupported
+// if the endpoint is not a WebSocket endpoint.
+func (rc *RPCClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	if !rc.subscriptions {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	ctx, cancel := context.WithTimeout(ctx, rc.requestTimeout)
+	defer cancel()
+	return rc.client.SubscribeNewHead(ctx, ch)
+}
@@ -0,0 +1,44 @@
+package blockwatch
+
+import (
+	"context"
+	"math/big"
+	"time"
+)
+
+// WatchStall calls onStall when the latest block processed hasn't changed for timeout, until ctx is done. onStall is
+// called once per stall, with the number of the latest block if any, and again if the stall lasts for another timeout.
+func (w *Watcher) WatchStall(ctx context.Context, timeout time.Duration, onStall func(latest *big.Int, since time.Duration)) {
+	latest := func() *big.Int {
+		h, err := w.stack.Peek()
+		if err != nil || h == nil {
+			return nil
+		}
+		return h.Number
+	}
+	watchStall(ctx, timeout/4, timeout, latest, onStall)
+}
+
+func watchStall(ctx context.Context, interval, timeout time.Duration, latest func() *big.Int, onStall func(*big.Int, time.Duration)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := latest()
+	lastChange := time.Now()
+	lastAlert := lastChange
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		if n := latest(); n != nil && (last == nil || n.Cmp(last) != 0) {
+			last, lastChange, lastAlert = n, now, now
+			continue
+		}
+		if now.Sub(lastAlert) >= timeout {
+			lastAlert = now
+			onStall(last, now.Sub(lastChange))
+		}
+	}
+}
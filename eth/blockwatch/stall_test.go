@@ -0,0 +1,68 @@
+package blockwatch
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchStall(t *testing.T) {
+	assert := assert.New(t)
+
+	var mu sync.Mutex
+	var block *big.Int
+	latest := func() *big.Int {
+		mu.Lock()
+		defer mu.Unlock()
+		return block
+	}
+	setBlock := func(n int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		block = big.NewInt(n)
+	}
+	stalls := make(chan *big.Int, 10)
+	onStall := func(latest *big.Int, since time.Duration) {
+		assert.GreaterOrEqual(since, 40*time.Millisecond)
+		stalls <- latest
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setBlock(1)
+	go watchStall(ctx, 5*time.Millisecond, 40*time.Millisecond, latest, onStall)
+
+	// No stall while new blocks are processed
+	for i := int64(2); i < 10; i++ {
+		time.Sleep(10 * time.Millisecond)
+		setBlock(i)
+	}
+	assert.Len(stalls, 0)
+
+	select {
+	case n := <-stalls:
+		assert.Equal(big.NewInt(9), n)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for stall")
+	}
+	// The stall is reported again after another timeout
+	select {
+	case n := <-stalls:
+		assert.Equal(big.NewInt(9), n)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for stall")
+	}
+
+	// No more stalls once ctx is done
+	cancel()
+	time.Sleep(60 * time.Millisecond)
+	for len(stalls) > 0 {
+		<-stalls
+	}
+	time.Sleep(60 * time.Millisecond)
+	assert.Len(stalls, 0)
+}
@@ -0,0 +1,107 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/glog"
+)
+
+// ClaimEarningsService is a service that claims the earnings of the node's account once more than a number of rounds
+// are unclaimed, so that the cost of a claim, which grows with the number of rounds it covers, stays bounded
+type ClaimEarningsService struct {
+	client LivepeerEthClient
+	tw     timeWatcher
+	// maxRounds is the number of unclaimed rounds above which the earnings are claimed
+	maxRounds *big.Int
+	quit      chan struct{}
+
+	mu sync.Mutex
+}
+
+// NewClaimEarningsService creates a ClaimEarningsService instance
+func NewClaimEarningsService(client LivepeerEthClient, tw timeWatcher, maxRounds int64) *ClaimEarningsService {
+	return &ClaimEarningsService{
+		client:    client,
+		tw:        tw,
+		maxRounds: big.NewInt(maxRounds),
+		quit:      make(chan struct{}),
+	}
+}
+
+// Start kicks off a loop that checks the unclaimed rounds at the start of every round
+func (s *ClaimEarningsService) Start(ctx context.Context) error {
+	roundSink := make(chan types.Log, 10)
+	sub := s.tw.SubscribeRounds(roundSink)
+	defer sub.Unsubscribe()
+
+	// The current round is checked right away in case the node was offline for a while
+	go s.check()
+
+	for {
+		select {
+		case err := <-sub.Err():
+			if err != nil {
+				glog.Errorf("Round subscription error err=%q", err)
+			}
+		case <-roundSink:
+			go s.check()
+		case <-s.quit:
+			glog.Infof("Stopping claim earnings service")
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Stop signals the loop to exit gracefully
+func (s *ClaimEarningsService) Stop() {
+	close(s.quit)
+}
+
+func (s *ClaimEarningsService) check() {
+	if err := s.tryClaimEarnings(); err != nil {
+		glog.Errorf("Error claiming earnings err=%q", err)
+	}
+}
+
+func (s *ClaimEarningsService) tryClaimEarnings() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	currentRound := s.tw.LastInitializedRound()
+	if currentRound == nil {
+		return nil
+	}
+
+	d, err := s.client.GetDelegator(s.client.Account().Address)
+	if err != nil {
+		return err
+	}
+
+	// Only bonded accounts have earnings to claim
+	if d.Status != "Bonded" || d.LastClaimRound == nil {
+		return nil
+	}
+
+	unclaimed := new(big.Int).Sub(currentRound, d.LastClaimRound)
+	if unclaimed.Cmp(s.maxRounds) <= 0 {
+		return nil
+	}
+
+	glog.Infof("Claiming earnings unclaimedRounds=%v lastClaimRound=%v endRound=%v", unclaimed, d.LastClaimRound, currentRound)
+	tx, err := s.client.ClaimEarnings(currentRound)
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.CheckTx(tx); err != nil {
+		return err
+	}
+
+	glog.Infof("Claimed earnings through round %v", currentRound)
+	return nil
+}
@@ -0,0 +1,83 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	lpTypes "github.com/livepeer/go-livepeer/eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimEarningsService_TryClaimEarnings(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := ethcommon.BytesToAddress([]byte("foo"))
+	client := &MockClient{}
+	client.On("Account").Return(accounts.Account{Address: addr})
+	tw := &stubTimeWatcher{lastInitializedRound: big.NewInt(100)}
+	s := NewClaimEarningsService(client, tw, 10)
+
+	// Error getting the delegator
+	client.On("GetDelegator", addr).Return(nil, errors.New("GetDelegator error")).Once()
+	assert.EqualError(s.tryClaimEarnings(), "GetDelegator error")
+
+	// Not bonded
+	client.On("GetDelegator", addr).Return(&lpTypes.Delegator{Status: "Unbonded", LastClaimRound: big.NewInt(50)}, nil).Once()
+	assert.Nil(s.tryClaimEarnings())
+
+	// Unclaimed rounds don't exceed the max
+	client.On("GetDelegator", addr).Return(&lpTypes.Delegator{Status: "Bonded", LastClaimRound: big.NewInt(90)}, nil).Once()
+	assert.Nil(s.tryClaimEarnings())
+	client.AssertNotCalled(t, "ClaimEarnings", mock.Anything)
+
+	// Claim through the current round
+	client.On("GetDelegator", addr).Return(&lpTypes.Delegator{Status: "Bonded", LastClaimRound: big.NewInt(89)}, nil)
+	tx := &types.Transaction{}
+	client.On("ClaimEarnings", big.NewInt(100)).Return(tx, nil).Once()
+	client.On("CheckTx").Return(nil).Once()
+	assert.Nil(s.tryClaimEarnings())
+	client.AssertCalled(t, "ClaimEarnings", big.NewInt(100))
+
+	// Failed tx
+	client.On("ClaimEarnings", big.NewInt(100)).Return(tx, nil).Once()
+	client.On("CheckTx").Return(errors.New("tx failed")).Once()
+	assert.EqualError(s.tryClaimEarnings(), "tx failed")
+
+	// Error sending the tx
+	client.On("ClaimEarnings", big.NewInt(100)).Return(nil, errors.New("ClaimEarnings error")).Once()
+	assert.EqualError(s.tryClaimEarnings(), "ClaimEarnings error")
+
+	// Unknown current round
+	tw.lastInitializedRound = nil
+	assert.Nil(s.tryClaimEarnings())
+}
+
+func TestClaimEarningsService_StartStop(t *testing.T) {
+	addr := ethcommon.BytesToAddress([]byte("foo"))
+	client := &MockClient{}
+	client.On("Account").Return(accounts.Account{Address: addr})
+	client.On("GetDelegator", addr).Return(&lpTypes.Delegator{Status: "Bonded", LastClaimRound: big.NewInt(100)}, nil)
+	tw := &stubTimeWatcher{lastInitializedRound: big.NewInt(100)}
+	s := NewClaimEarningsService(client, tw, 10)
+
+	errC := make(chan error)
+	go func() { errC <- s.Start(context.Background()) }()
+	time.Sleep(100 * time.Millisecond)
+	require.NotNil(t, tw.roundSink)
+
+	// Checked on start and on a new round
+	tw.roundSink <- types.Log{}
+	time.Sleep(100 * time.Millisecond)
+	client.AssertNumberOfCalls(t, "GetDelegator", 2)
+
+	s.Stop()
+	assert.Nil(t, <-errC)
+}
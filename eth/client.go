@@ -69,6 +69,7 @@ type LivepeerEthClient interface {
 	// Staking
 	Transcoder(blockRewardCut, feeShare *big.Int) (*types.Transaction, error)
 	Reward() (*types.Transaction, error)
+	RewardWithPriceBump(priceBump uint64) (*types.Transaction, error)
 	Bond(amount *big.Int, toAddr ethcommon.Address) (*types.Transaction, error)
 	Rebond(unbondingLockID *big.Int) (*types.Transaction, error)
 	RebondFromUnbonded(toAddr ethcommon.Address, unbondingLockID *big.Int) (*types.Transaction, error)
@@ -95,6 +96,7 @@ type LivepeerEthClient interface {
 	CancelUnlock() (*types.Transaction, error)
 	Withdraw() (*types.Transaction, error)
 	RedeemWinningTicket(ticket *pm.Ticket, sig []byte, recipientRand *big.Int) (*types.Transaction, error)
+	BatchRedeemWinningTickets(tickets []*pm.Ticket, sigs [][]byte, recipientRands []*big.Int) (*types.Transaction, error)
 	IsUsedTicket(ticket *pm.Ticket) (bool, error)
 	GetSenderInfo(addr ethcommon.Address) (*pm.SenderInfo, error)
 	UnlockPeriod() (*big.Int, error)
@@ -378,12 +380,12 @@ func (c *client) transactOpts() *bind.TransactOpts {
 	}
 
 	// If GasFeeCap is non-nil ensure that we adjust it to be min(gasPriceEstimate, current GasFeeCap).
+	// The estimate includes the priority fee because a GasFeeCap below the GasTipCap set by BoundContract is invalid.
 	gasPriceEstimate := opts.GasFeeCap
-	head, err := c.backend.HeaderByNumber(context.Background(), nil)
-	if err != nil {
+	if baseFee, tip := c.dynamicFees(); baseFee == nil {
 		glog.Errorf("failed to calculate gas price estimate - defaulting to using GasFeeCap = maxGasPrice")
 	} else {
-		gasPriceEstimate = new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+		gasPriceEstimate = new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
 	}
 	// Setting GasFeeCap > gasPriceEstimate is detrimental to the user because the user account will need at least gas * GasFeeCap in their balance
 	// to pay for the tx when they only need gas * gasPriceEstimate. GasFeeCap is initially going to be set to the maxGasPrice specified by the user which
@@ -397,6 +399,61 @@ func (c *client) transactOpts() *bind.TransactOpts {
 	return &opts
 }
 
+// bumpedTransactOpts returns transaction options with gas fees that are priceBump % above the current estimate.
+// The fees never exceed the max gas price.
+func (c *client) bumpedTransactOpts(priceBump uint64) *bind.TransactOpts {
+	c.transOptsMu.RLock()
+	maxGasPrice := c.transOpts.GasFeeCap
+	if maxGasPrice == nil {
+		maxGasPrice = c.transOpts.GasPrice
+	}
+	c.transOptsMu.RUnlock()
+
+	opts := c.transactOpts()
+	if priceBump == 0 {
+		return opts
+	}
+
+	baseFee, tip := c.dynamicFees()
+	if baseFee == nil {
+		// legacy tx, bump the gas price suggested by the gas price monitor
+		gpm := c.backend.GasPriceMonitor()
+		if gpm == nil || gpm.GasPrice() == nil {
+			return opts
+		}
+		opts.GasPrice = applyPriceBump(gpm.GasPrice(), priceBump)
+		if maxGasPrice != nil {
+			opts.GasPrice = bigMin(opts.GasPrice, maxGasPrice)
+		}
+		return opts
+	}
+
+	tip = applyPriceBump(tip, priceBump)
+	feeCap := applyPriceBump(new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip), priceBump)
+	if maxGasPrice != nil {
+		feeCap = bigMin(feeCap, maxGasPrice)
+		tip = bigMin(tip, feeCap)
+	}
+	opts.GasTipCap = tip
+	opts.GasFeeCap = feeCap
+	return opts
+}
+
+// dynamicFees returns the base fee and priority fee last polled by the gas price monitor, falling back
+// to the base fee of the latest block with no priority fee if the monitor does not know the fees
+func (c *client) dynamicFees() (*big.Int, *big.Int) {
+	if gpm := c.backend.GasPriceMonitor(); gpm != nil {
+		if baseFee, tip := gpm.BaseFee(), gpm.GasTipCap(); baseFee != nil && tip != nil {
+			return baseFee, tip
+		}
+	}
+	head, err := c.backend.HeaderByNumber(context.Background(), nil)
+	if err != nil || head.BaseFee == nil {
+		return nil, nil
+	}
+	return head.BaseFee, big.NewInt(0)
+}
+
 func (c *client) callOpts() *bind.CallOpts {
 	return &bind.CallOpts{
 		Context: newEthRpcContext(),
@@ -987,6 +1044,12 @@ func (c *client) Vote(pollAddr ethcommon.Address, choiceID *big.Int) (*types.Tra
 }
 
 func (c *client) Reward() (*types.Transaction, error) {
+	return c.RewardWithPriceBump(0)
+}
+
+// RewardWithPriceBump calls reward with gas fees that are priceBump % above the current estimate, capped at the max gas price,
+// so that a reward call that failed or got stuck can be retried with a higher fee
+func (c *client) RewardWithPriceBump(priceBump uint64) (*types.Transaction, error) {
 	addr := c.accountManager.Account().Address
 
 	tr, err := c.GetTranscoder(addr)
@@ -1031,7 +1094,7 @@ func (c *client) Reward() (*types.Transaction, error) {
 
 	hints := simulateTranscoderPoolUpdate(addr, reward.Add(reward, tr.DelegatedStake), transcoders, len(transcoders) == int(maxSize.Int64()))
 
-	return c.bondingManager.RewardWithHint(c.transactOpts(), hints.PosPrev, hints.PosNext)
+	return c.bondingManager.RewardWithHint(c.bumpedTransactOpts(priceBump), hints.PosPrev, hints.PosNext)
 }
 
 func (c *client) WithdrawFees(addr ethcommon.Address, amount *big.Int) (*types.Transaction, error) {
@@ -0,0 +1,151 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistryAddr is the address of the ENS registry on Ethereum mainnet and its testnets
+var ENSRegistryAddr = ethcommon.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// ENSURLKey is the key of the text record that holds the URL of a service
+const ENSURLKey = "url"
+
+// Time to wait for the ENS contracts calls of a name resolution
+var ensTimeout = 10 * time.Second
+
+const ensABI = `[
+	{"constant":true,"inputs":[{"name":"node","type":"bytes32"}],"name":"resolver","outputs":[{"name":"","type":"address"}],"type":"function"},
+	{"constant":true,"inputs":[{"name":"node","type":"bytes32"}],"name":"addr","outputs":[{"name":"","type":"address"}],"type":"function"},
+	{"constant":true,"inputs":[{"name":"node","type":"bytes32"},{"name":"key","type":"string"}],"name":"text","outputs":[{"name":"","type":"string"}],"type":"function"}
+]`
+
+// ENSResolver resolves ENS names with the registry and resolver contracts of the chain of caller.
+// ENS is deployed on Ethereum L1, so the caller should be connected to L1 even if the node runs on an L2.
+type ENSResolver struct {
+	caller   ethereum.ContractCaller
+	registry ethcommon.Address
+	abi      abi.ABI
+}
+
+func NewENSResolver(caller ethereum.ContractCaller, registry ethcommon.Address) (*ENSResolver, error) {
+	parsed, err := abi.JSON(strings.NewReader(ensABI))
+	if err != nil {
+		return nil, err
+	}
+	return &ENSResolver{caller: caller, registry: registry, abi: parsed}, nil
+}
+
+// IsENSName returns whether s is an ENS name, i.e. a name under the .eth top level domain
+func IsENSName(s string) bool {
+	s = strings.ToLower(s)
+	return len(s) > len(".eth") && strings.HasSuffix(s, ".eth") && !strings.Contains(s, "/")
+}
+
+// ENSNamehash returns the node of an ENS name as defined by EIP-137
+func ENSNamehash(name string) ethcommon.Hash {
+	var node ethcommon.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// ResolveAddress returns the address of s, which is either a hex encoded address or an ENS name
+func (r *ENSResolver) ResolveAddress(s string) (ethcommon.Address, error) {
+	if ethcommon.IsHexAddress(s) {
+		return ethcommon.HexToAddress(s), nil
+	}
+	if !IsENSName(s) {
+		return ethcommon.Address{}, fmt.Errorf("invalid address or ENS name=%v", s)
+	}
+	return r.Addr(s)
+}
+
+// Addr returns the address record of an ENS name
+func (r *ENSResolver) Addr(name string) (ethcommon.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ensTimeout)
+	defer cancel()
+
+	node := ENSNamehash(name)
+	resolver, err := r.resolver(ctx, name, node)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	var addr ethcommon.Address
+	if err := r.call(ctx, resolver, &addr, "addr", [32]byte(node)); err != nil {
+		return ethcommon.Address{}, fmt.Errorf("could not resolve ENS name=%v err=%q", name, err)
+	}
+	if (addr == ethcommon.Address{}) {
+		return ethcommon.Address{}, fmt.Errorf("ENS name=%v has no address", name)
+	}
+	return addr, nil
+}
+
+// Text returns the text record with the given key of an ENS name
+func (r *ENSResolver) Text(name, key string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ensTimeout)
+	defer cancel()
+
+	node := ENSNamehash(name)
+	resolver, err := r.resolver(ctx, name, node)
+	if err != nil {
+		return "", err
+	}
+	var text string
+	if err := r.call(ctx, resolver, &text, "text", [32]byte(node), key); err != nil {
+		return "", fmt.Errorf("could not resolve ENS name=%v key=%v err=%q", name, key, err)
+	}
+	if text == "" {
+		return "", fmt.Errorf("ENS name=%v has no %v record", name, key)
+	}
+	return text, nil
+}
+
+func (r *ENSResolver) resolver(ctx context.Context, name string, node ethcommon.Hash) (ethcommon.Address, error) {
+	var resolver ethcommon.Address
+	if err := r.call(ctx, r.registry, &resolver, "resolver", [32]byte(node)); err != nil {
+		return ethcommon.Address{}, fmt.Errorf("could not get ENS resolver name=%v err=%q", name, err)
+	}
+	if (resolver == ethcommon.Address{}) {
+		return ethcommon.Address{}, fmt.Errorf("ENS name=%v has no resolver", name)
+	}
+	return resolver, nil
+}
+
+func (r *ENSResolver) call(ctx context.Context, to ethcommon.Address, out interface{}, method string, args ...interface{}) error {
+	data, err := r.abi.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	res, err := r.caller.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		return errors.New("empty response")
+	}
+	values, err := r.abi.Unpack(method, res)
+	if err != nil {
+		return err
+	}
+	switch out := out.(type) {
+	case *ethcommon.Address:
+		*out = values[0].(ethcommon.Address)
+	case *string:
+		*out = values[0].(string)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeENS answers the calls to the ENS registry and to a single resolver
+type fakeENS struct {
+	t        *testing.T
+	abi      abi.ABI
+	registry ethcommon.Address
+	resolver ethcommon.Address
+	addrs    map[ethcommon.Hash]ethcommon.Address
+	texts    map[ethcommon.Hash]map[string]string
+	err      error
+}
+
+func newFakeENS(t *testing.T) *fakeENS {
+	parsed, err := abi.JSON(strings.NewReader(ensABI))
+	require.Nil(t, err)
+	return &fakeENS{
+		t:        t,
+		abi:      parsed,
+		registry: ENSRegistryAddr,
+		resolver: ethcommon.HexToAddress("0x4976fb03C32e5B8cfe2b6cCB31c09Ba78EBaBa41"),
+		addrs:    make(map[ethcommon.Hash]ethcommon.Address),
+		texts:    make(map[ethcommon.Hash]map[string]string),
+	}
+}
+
+func (f *fakeENS) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	method, err := f.abi.MethodById(msg.Data[:4])
+	require.Nil(f.t, err)
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	require.Nil(f.t, err)
+	node := ethcommon.Hash(args[0].([32]byte))
+
+	switch {
+	case *msg.To == f.registry && method.Name == "resolver":
+		if _, ok := f.addrs[node]; !ok {
+			if _, ok := f.texts[node]; !ok {
+				return method.Outputs.Pack(ethcommon.Address{})
+			}
+		}
+		return method.Outputs.Pack(f.resolver)
+	case *msg.To == f.resolver && method.Name == "addr":
+		return method.Outputs.Pack(f.addrs[node])
+	case *msg.To == f.resolver && method.Name == "text":
+		return method.Outputs.Pack(f.texts[node][args[1].(string)])
+	}
+	return nil, nil
+}
+
+func TestENSNamehash(t *testing.T) {
+	assert.Equal(t, ethcommon.Hash{}, ENSNamehash(""))
+	assert.Equal(t, ethcommon.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"), ENSNamehash("eth"))
+	assert.Equal(t, ethcommon.HexToHash("0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"), ENSNamehash("foo.eth"))
+	assert.Equal(t, ENSNamehash("foo.eth"), ENSNamehash("Foo.ETH"))
+}
+
+func TestIsENSName(t *testing.T) {
+	assert.True(t, IsENSName("foo.eth"))
+	assert.True(t, IsENSName("orch.Foo.ETH"))
+	assert.False(t, IsENSName(".eth"))
+	assert.False(t, IsENSName("eth"))
+	assert.False(t, IsENSName("foo.com"))
+	assert.False(t, IsENSName("https://foo.eth"))
+	assert.False(t, IsENSName("0xD8E8328501E9645d16Cf49539efC04f734606ee4"))
+}
+
+func TestENSResolver_Addr(t *testing.T) {
+	ens := newFakeENS(t)
+	r, err := NewENSResolver(ens, ENSRegistryAddr)
+	require.Nil(t, err)
+
+	addr := ethcommon.HexToAddress("0xD8E8328501E9645d16Cf49539efC04f734606ee4")
+	ens.addrs[ENSNamehash("controller.livepeer.eth")] = addr
+	ens.texts[ENSNamehash("norecord.eth")] = map[string]string{}
+
+	res, err := r.Addr("controller.livepeer.eth")
+	require.Nil(t, err)
+	assert.Equal(t, addr, res)
+
+	res, err = r.ResolveAddress("Controller.Livepeer.eth")
+	require.Nil(t, err)
+	assert.Equal(t, addr, res)
+
+	// Hex addresses are not resolved
+	res, err = r.ResolveAddress("0x1234567890123456789012345678901234567890")
+	require.Nil(t, err)
+	assert.Equal(t, ethcommon.HexToAddress("0x1234567890123456789012345678901234567890"), res)
+
+	_, err = r.ResolveAddress("foo")
+	assert.EqualError(t, err, "invalid address or ENS name=foo")
+
+	_, err = r.Addr("missing.eth")
+	assert.EqualError(t, err, "ENS name=missing.eth has no resolver")
+
+	_, err = r.Addr("norecord.eth")
+	assert.EqualError(t, err, "ENS name=norecord.eth has no address")
+
+	ens.err = errors.New("connection refused")
+	_, err = r.Addr("controller.livepeer.eth")
+	assert.EqualError(t, err, `could not get ENS resolver name=controller.livepeer.eth err="connection refused"`)
+}
+
+func TestENSResolver_Text(t *testing.T) {
+	ens := newFakeENS(t)
+	r, err := NewENSResolver(ens, ENSRegistryAddr)
+	require.Nil(t, err)
+
+	ens.texts[ENSNamehash("orch.eth")] = map[string]string{ENSURLKey: "https://orch.example.com:8935"}
+
+	text, err := r.Text("orch.eth", ENSURLKey)
+	require.Nil(t, err)
+	assert.Equal(t, "https://orch.example.com:8935", text)
+
+	_, err = r.Text("orch.eth", "avatar")
+	assert.EqualError(t, err, "ENS name=orch.eth has no avatar record")
+}
@@ -0,0 +1,102 @@
+package eth
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/glog"
+)
+
+type gasPriceGetter interface {
+	GasPrice() *big.Int
+}
+
+// FeeWithdrawConfig defines when the FeeWithdrawService withdraws the fees of the node's account
+type FeeWithdrawConfig struct {
+	// Recipient is the address that the fees are withdrawn to
+	Recipient ethcommon.Address
+	// MinFees is the amount of pending fees at which they are withdrawn
+	MinFees *big.Int
+	// MaxGasPrice is the gas price above which withdrawals are postponed. A nil value disables the check
+	MaxGasPrice *big.Int
+	// Interval is the time between checks of the pending fees
+	Interval time.Duration
+}
+
+// FeeWithdrawService is a service that periodically withdraws the pending fees of the node's account to a configured
+// address once they reach a threshold, so that an orchestrator doesn't have to withdraw them manually
+type FeeWithdrawService struct {
+	client LivepeerEthClient
+	gpm    gasPriceGetter
+	cfg    FeeWithdrawConfig
+	quit   chan struct{}
+
+	mu sync.Mutex
+}
+
+// NewFeeWithdrawService creates a FeeWithdrawService instance
+func NewFeeWithdrawService(client LivepeerEthClient, gpm gasPriceGetter, cfg FeeWithdrawConfig) *FeeWithdrawService {
+	return &FeeWithdrawService{
+		client: client,
+		gpm:    gpm,
+		cfg:    cfg,
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start kicks off a loop that checks the pending fees every cfg.Interval
+func (s *FeeWithdrawService) Start() error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			glog.Infof("Stopping fee withdraw service")
+			return nil
+		case <-ticker.C:
+			go func() {
+				if err := s.tryWithdrawFees(); err != nil {
+					glog.Errorf("Error withdrawing fees err=%q", err)
+				}
+			}()
+		}
+	}
+}
+
+// Stop signals the loop to exit gracefully
+func (s *FeeWithdrawService) Stop() {
+	close(s.quit)
+}
+
+func (s *FeeWithdrawService) tryWithdrawFees() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, err := s.client.GetDelegator(s.client.Account().Address)
+	if err != nil {
+		return err
+	}
+
+	// Pending fees are -1 if they could not be computed
+	if d.PendingFees.Sign() <= 0 || d.PendingFees.Cmp(s.cfg.MinFees) < 0 {
+		return nil
+	}
+
+	if s.cfg.MaxGasPrice != nil {
+		if gasPrice := s.gpm.GasPrice(); gasPrice == nil || gasPrice.Cmp(s.cfg.MaxGasPrice) > 0 {
+			glog.Infof("Postponing fee withdrawal because of the gas price fees=%v gasPrice=%v wei maxGasPrice=%v wei", FormatUnits(d.PendingFees, "ETH"), gasPrice, s.cfg.MaxGasPrice)
+			return nil
+		}
+	}
+
+	glog.Infof("Withdrawing fees=%v to recipient=%v", FormatUnits(d.PendingFees, "ETH"), s.cfg.Recipient.Hex())
+	tx, err := s.client.WithdrawFees(s.cfg.Recipient, d.PendingFees)
+	if err != nil {
+		return err
+	}
+
+	return s.client.CheckTx(tx)
+}
@@ -0,0 +1,107 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	lpTypes "github.com/livepeer/go-livepeer/eth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type stubGasPriceGetter struct {
+	gasPrice *big.Int
+}
+
+func (s *stubGasPriceGetter) GasPrice() *big.Int {
+	return s.gasPrice
+}
+
+func TestFeeWithdrawService_TryWithdrawFees(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := ethcommon.BytesToAddress([]byte("foo"))
+	recipient := ethcommon.BytesToAddress([]byte("cold"))
+	client := &MockClient{}
+	client.On("Account").Return(accounts.Account{Address: addr})
+	gpm := &stubGasPriceGetter{gasPrice: big.NewInt(10)}
+	cfg := FeeWithdrawConfig{
+		Recipient:   recipient,
+		MinFees:     big.NewInt(1000),
+		MaxGasPrice: big.NewInt(20),
+		Interval:    time.Hour,
+	}
+	s := NewFeeWithdrawService(client, gpm, cfg)
+
+	// Error getting the delegator
+	client.On("GetDelegator", addr).Return(nil, errors.New("GetDelegator error")).Once()
+	assert.EqualError(s.tryWithdrawFees(), "GetDelegator error")
+
+	// Pending fees below the threshold
+	client.On("GetDelegator", addr).Return(&lpTypes.Delegator{PendingFees: big.NewInt(999)}, nil).Once()
+	assert.Nil(s.tryWithdrawFees())
+
+	// Pending fees could not be computed
+	client.On("GetDelegator", addr).Return(&lpTypes.Delegator{PendingFees: big.NewInt(-1)}, nil).Once()
+	assert.Nil(s.tryWithdrawFees())
+	client.AssertNotCalled(t, "WithdrawFees", mock.Anything, mock.Anything)
+
+	// Gas price above the max
+	client.On("GetDelegator", addr).Return(&lpTypes.Delegator{PendingFees: big.NewInt(1000)}, nil)
+	gpm.gasPrice = big.NewInt(21)
+	assert.Nil(s.tryWithdrawFees())
+	client.AssertNotCalled(t, "WithdrawFees", mock.Anything, mock.Anything)
+
+	// Unknown gas price
+	gpm.gasPrice = nil
+	assert.Nil(s.tryWithdrawFees())
+	client.AssertNotCalled(t, "WithdrawFees", mock.Anything, mock.Anything)
+
+	// Withdraw to the recipient
+	gpm.gasPrice = big.NewInt(20)
+	tx := &types.Transaction{}
+	client.On("WithdrawFees", recipient, big.NewInt(1000)).Return(tx, nil).Once()
+	client.On("CheckTx").Return(nil).Once()
+	assert.Nil(s.tryWithdrawFees())
+	client.AssertCalled(t, "WithdrawFees", recipient, big.NewInt(1000))
+
+	// No gas price limit
+	s.cfg.MaxGasPrice = nil
+	gpm.gasPrice = big.NewInt(1000)
+	client.On("WithdrawFees", recipient, big.NewInt(1000)).Return(tx, nil).Once()
+	client.On("CheckTx").Return(errors.New("tx failed")).Once()
+	assert.EqualError(s.tryWithdrawFees(), "tx failed")
+
+	// Error sending the tx
+	client.On("WithdrawFees", recipient, big.NewInt(1000)).Return(nil, errors.New("WithdrawFees error")).Once()
+	assert.EqualError(s.tryWithdrawFees(), "WithdrawFees error")
+}
+
+func TestFeeWithdrawService_StartStop(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &MockClient{}
+	client.On("Account").Return(accounts.Account{Address: ethcommon.BytesToAddress([]byte("foo"))})
+	client.On("GetDelegator", mock.Anything).Return(&lpTypes.Delegator{PendingFees: big.NewInt(0)}, nil)
+	s := NewFeeWithdrawService(client, &stubGasPriceGetter{}, FeeWithdrawConfig{MinFees: big.NewInt(1), Interval: 10 * time.Millisecond})
+
+	errC := make(chan error)
+	go func() {
+		errC <- s.Start()
+	}()
+	time.Sleep(50 * time.Millisecond)
+	s.Stop()
+
+	select {
+	case err := <-errC:
+		assert.Nil(err)
+	case <-time.After(time.Second):
+		t.Fatal("fee withdraw service did not stop")
+	}
+	client.AssertCalled(t, "GetDelegator", ethcommon.BytesToAddress([]byte("foo")))
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/golang/glog"
 	"github.com/livepeer/go-livepeer/monitor"
 )
@@ -17,6 +18,13 @@ type GasPriceOracle interface {
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 }
 
+// DynamicFeeOracle defines methods for fetching the base fee and a suggested
+// priority fee for submitting EIP-1559 transactions
+type DynamicFeeOracle interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
 // GasPriceMonitor polls for gas price updates and updates its
 // own view of the current gas price that can be used by others
 type GasPriceMonitor struct {
@@ -36,6 +44,11 @@ type GasPriceMonitor struct {
 	minGasPrice *big.Int
 	// maxGasPrice is the max acceptable gas price defined by the user
 	maxGasPrice *big.Int
+	// baseFee is the base fee of the latest block, nil if the chain does not support EIP-1559
+	// or if the oracle does not implement DynamicFeeOracle
+	baseFee *big.Int
+	// gasTipCap is the current suggested priority fee, nil if baseFee is nil
+	gasTipCap *big.Int
 
 	// update is a channel used to send notifications to a listener
 	// when the gas price is updated
@@ -171,12 +184,32 @@ func (gpm *GasPriceMonitor) MaxGasPrice() *big.Int {
 	return gpm.maxGasPrice
 }
 
+// BaseFee returns the base fee of the latest block, or nil if it is unknown
+func (gpm *GasPriceMonitor) BaseFee() *big.Int {
+	gpm.gasPriceMu.RLock()
+	defer gpm.gasPriceMu.RUnlock()
+	return gpm.baseFee
+}
+
+// GasTipCap returns the current suggested priority fee, or nil if the base fee is unknown
+func (gpm *GasPriceMonitor) GasTipCap() *big.Int {
+	gpm.gasPriceMu.RLock()
+	defer gpm.gasPriceMu.RUnlock()
+	return gpm.gasTipCap
+}
+
 func (gpm *GasPriceMonitor) fetchAndUpdateGasPrice(ctx context.Context) error {
 	gasPrice, err := gpm.gpo.SuggestGasPrice(ctx)
 	if err != nil {
 		return err
 	}
 
+	if dfo, ok := gpm.gpo.(DynamicFeeOracle); ok {
+		if err := gpm.fetchAndUpdateDynamicFees(ctx, dfo, gasPrice); err != nil {
+			return err
+		}
+	}
+
 	if gasPrice.Cmp(gpm.minGasPrice) >= 0 {
 		gpm.updateGasPrice(gasPrice)
 
@@ -188,6 +221,35 @@ func (gpm *GasPriceMonitor) fetchAndUpdateGasPrice(ctx context.Context) error {
 	return nil
 }
 
+func (gpm *GasPriceMonitor) fetchAndUpdateDynamicFees(ctx context.Context, dfo DynamicFeeOracle, gasPrice *big.Int) error {
+	head, err := dfo.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Not London ready, legacy txs are used
+	if head.BaseFee == nil {
+		return nil
+	}
+
+	tip, err := dfo.SuggestGasTipCap(ctx)
+	if err != nil {
+		// eth_maxPriorityFeePerGas is not a part of the ETH JSON-RPC spec, so if the provider does not support it
+		// we calculate the priority fee as eth_gasPrice - baseFee
+		tip = new(big.Int).Sub(gasPrice, head.BaseFee)
+		if tip.Sign() < 0 {
+			tip = big.NewInt(0)
+		}
+	}
+
+	gpm.gasPriceMu.Lock()
+	defer gpm.gasPriceMu.Unlock()
+
+	gpm.baseFee = head.BaseFee
+	gpm.gasTipCap = tip
+
+	return nil
+}
+
 func (gpm *GasPriceMonitor) updateGasPrice(gasPrice *big.Int) {
 	gpm.gasPriceMu.Lock()
 	defer gpm.gasPriceMu.Unlock()
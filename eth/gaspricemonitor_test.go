@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -238,3 +239,68 @@ func TestSetMaxGasPrice(t *testing.T) {
 	gpm.SetMaxGasPrice(gp)
 	assert.Equal(t, gp, gpm.MaxGasPrice())
 }
+
+type stubDynamicFeeOracle struct {
+	*stubGasPriceOracle
+	baseFee *big.Int
+	tip     *big.Int
+	headErr error
+	tipErr  error
+}
+
+func (s *stubDynamicFeeOracle) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if s.headErr != nil {
+		return nil, s.headErr
+	}
+	return &types.Header{BaseFee: s.baseFee}, nil
+}
+
+func (s *stubDynamicFeeOracle) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if s.tipErr != nil {
+		return nil, s.tipErr
+	}
+	return s.tip, nil
+}
+
+func TestFetchAndUpdateGasPrice_DynamicFees(t *testing.T) {
+	assert := assert.New(t)
+
+	gpo := &stubDynamicFeeOracle{
+		stubGasPriceOracle: newStubGasPriceOracle(big.NewInt(110)),
+		baseFee:            big.NewInt(100),
+		tip:                big.NewInt(2),
+	}
+	gpm := NewGasPriceMonitor(gpo, 1*time.Hour, big.NewInt(0), nil)
+
+	// Oracle without dynamic fee support
+	legacy := NewGasPriceMonitor(gpo.stubGasPriceOracle, 1*time.Hour, big.NewInt(0), nil)
+	assert.Nil(legacy.fetchAndUpdateGasPrice(context.Background()))
+	assert.Nil(legacy.BaseFee())
+	assert.Nil(legacy.GasTipCap())
+
+	assert.Nil(gpm.fetchAndUpdateGasPrice(context.Background()))
+	assert.Equal(big.NewInt(110), gpm.GasPrice())
+	assert.Equal(big.NewInt(100), gpm.BaseFee())
+	assert.Equal(big.NewInt(2), gpm.GasTipCap())
+
+	// The priority fee is derived from the gas price if it cannot be fetched
+	gpo.tipErr = errors.New("method not found")
+	assert.Nil(gpm.fetchAndUpdateGasPrice(context.Background()))
+	assert.Equal(big.NewInt(10), gpm.GasTipCap())
+
+	gpo.baseFee = big.NewInt(200)
+	assert.Nil(gpm.fetchAndUpdateGasPrice(context.Background()))
+	assert.Equal(big.NewInt(0), gpm.GasTipCap())
+
+	// Error fetching the latest block
+	gpo.headErr = errors.New("HeaderByNumber error")
+	assert.EqualError(gpm.fetchAndUpdateGasPrice(context.Background()), "HeaderByNumber error")
+	assert.Equal(big.NewInt(200), gpm.BaseFee())
+
+	// Chain that is not London ready
+	gpo.headErr = nil
+	gpo.baseFee = nil
+	legacy = NewGasPriceMonitor(gpo, 1*time.Hour, big.NewInt(0), nil)
+	assert.Nil(legacy.fetchAndUpdateGasPrice(context.Background()))
+	assert.Nil(legacy.BaseFee())
+}
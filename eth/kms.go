@@ -0,0 +1,196 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/golang/glog"
+)
+
+// Time to wait for a key management service to return a public key or a signature
+var kmsTimeout = 10 * time.Second
+
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
+// digestSigner is a secp256k1 key held by a key management service that signs digests without exposing the key
+type digestSigner interface {
+	// PublicKey returns the DER encoded SubjectPublicKeyInfo of the key
+	PublicKey(ctx context.Context) ([]byte, error)
+	// SignDigest returns the DER encoded ECDSA signature of a 32 byte digest
+	SignDigest(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// kmsAccountManager signs with a key of a cloud key management service so that the key never exists on the node
+type kmsAccountManager struct {
+	signer  digestSigner
+	pubKey  []byte
+	account accounts.Account
+	chainID *big.Int
+}
+
+// NewKMSAccountManager returns an AccountManager that signs with the KMS key identified by keyURI:
+// awskms://<key ID or ARN> for AWS KMS or gcpkms://projects/<project>/locations/<location>/keyRings/<key ring>/cryptoKeys/<key>/cryptoKeyVersions/<version>
+// for GCP Cloud KMS. The key has to be an asymmetric secp256k1 signing key. If accountAddr is set, the address of the key has to match it.
+func NewKMSAccountManager(accountAddr ethcommon.Address, keyURI string, chainID *big.Int) (AccountManager, error) {
+	var signer digestSigner
+	var err error
+	switch {
+	case strings.HasPrefix(keyURI, "awskms://"):
+		signer, err = newAWSKMSSigner(strings.TrimPrefix(keyURI, "awskms://"))
+	case strings.HasPrefix(keyURI, "gcpkms://"):
+		signer, err = newGCPKMSSigner(strings.TrimPrefix(keyURI, "gcpkms://"))
+	default:
+		return nil, fmt.Errorf("invalid KMS key URI=%v", keyURI)
+	}
+	if err != nil {
+		return nil, err
+	}
+	am, err := newKMSAccountManager(signer, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if (accountAddr != ethcommon.Address{}) && am.account.Address != accountAddr {
+		return nil, fmt.Errorf("KMS key %v does not match %v", am.account.Address.Hex(), accountAddr.Hex())
+	}
+	glog.Infof("Using Ethereum account: %v with KMS key %v", am.account.Address.Hex(), keyURI)
+	return am, nil
+}
+
+func newKMSAccountManager(signer digestSigner, chainID *big.Int) (*kmsAccountManager, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+	defer cancel()
+	der, err := signer.PublicKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get KMS public key err=%q", err)
+	}
+	pubKey, err := parseKMSPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	return &kmsAccountManager{
+		signer:  signer,
+		pubKey:  crypto.FromECDSAPub(pubKey),
+		account: accounts.Account{Address: crypto.PubkeyToAddress(*pubKey)},
+		chainID: chainID,
+	}, nil
+}
+
+// Unlock is a no-op, access to the key is controlled by the KMS
+func (am *kmsAccountManager) Unlock(passphrase string) error {
+	return nil
+}
+
+// Lock is a no-op, access to the key is controlled by the KMS
+func (am *kmsAccountManager) Lock() error {
+	return nil
+}
+
+func (am *kmsAccountManager) CreateTransactOpts(gasLimit uint64) (*bind.TransactOpts, error) {
+	return &bind.TransactOpts{
+		From: am.account.Address,
+		Signer: func(addr ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if addr != am.account.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return am.SignTx(tx)
+		},
+		GasLimit: gasLimit,
+	}, nil
+}
+
+func (am *kmsAccountManager) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	signer := types.LatestSignerForChainID(am.chainID)
+	sig, err := am.signHash(signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// Sign byte array message
+func (am *kmsAccountManager) Sign(msg []byte) ([]byte, error) {
+	sig, err := am.signHash(accounts.TextHash(msg))
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func (am *kmsAccountManager) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	sighash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := am.signHash(sighash)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func (am *kmsAccountManager) Account() accounts.Account {
+	return am.account
+}
+
+// signHash signs hash with the KMS key and returns the signature in the [R || S || V] format where V is 0 or 1
+func (am *kmsAccountManager) signHash(hash []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+	defer cancel()
+	der, err := am.signer.SignDigest(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("KMS signing failed err=%q", err)
+	}
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &rs); err != nil {
+		return nil, fmt.Errorf("invalid KMS signature err=%q", err)
+	}
+	// KMS signatures are not normalized, but Ethereum only accepts signatures with a low S value
+	if rs.S.Cmp(secp256k1HalfN) > 0 {
+		rs.S = new(big.Int).Sub(crypto.S256().Params().N, rs.S)
+	}
+	sig := make([]byte, 65)
+	math.ReadBits(rs.R, sig[:32])
+	math.ReadBits(rs.S, sig[32:64])
+	// The recovery ID is the one that recovers the public key of the account
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		if pubKey, err := crypto.Ecrecover(hash, sig); err == nil && string(pubKey) == string(am.pubKey) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("KMS signature does not match the account")
+}
+
+// parseKMSPublicKey parses a DER encoded SubjectPublicKeyInfo of a secp256k1 key
+func parseKMSPublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var spki struct {
+		Algorithm struct {
+			Algorithm  asn1.ObjectIdentifier
+			Parameters asn1.ObjectIdentifier
+		}
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &spki); err != nil {
+		return nil, fmt.Errorf("invalid KMS public key err=%q", err)
+	}
+	// secp256k1 curve OID
+	if !spki.Algorithm.Parameters.Equal(asn1.ObjectIdentifier{1, 3, 132, 0, 10}) {
+		return nil, fmt.Errorf("KMS key is not a secp256k1 key curve=%v", spki.Algorithm.Parameters)
+	}
+	return crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+}
@@ -0,0 +1,52 @@
+package eth
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+)
+
+// awsKMSSigner signs with an ECC_SECG_P256K1 key of AWS KMS
+type awsKMSSigner struct {
+	client kmsiface.KMSAPI
+	keyID  string
+}
+
+// newAWSKMSSigner uses the default AWS credentials and region of the environment.
+// The region of a key ARN takes precedence over the default region.
+func newAWSKMSSigner(keyID string) (*awsKMSSigner, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, err
+	}
+	cfg := aws.NewConfig()
+	if keyARN, err := arn.Parse(keyID); err == nil {
+		cfg = cfg.WithRegion(keyARN.Region)
+	}
+	return &awsKMSSigner{client: kms.New(sess, cfg), keyID: keyID}, nil
+}
+
+func (s *awsKMSSigner) PublicKey(ctx context.Context) ([]byte, error) {
+	out, err := s.client.GetPublicKeyWithContext(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(s.keyID)})
+	if err != nil {
+		return nil, err
+	}
+	return out.PublicKey, nil
+}
+
+func (s *awsKMSSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	out, err := s.client.SignWithContext(ctx, &kms.SignInput{
+		KeyId:            aws.String(s.keyID),
+		Message:          digest,
+		MessageType:      aws.String(kms.MessageTypeDigest),
+		SigningAlgorithm: aws.String(kms.SigningAlgorithmSpecEcdsaSha256),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Signature, nil
+}
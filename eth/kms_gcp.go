@@ -0,0 +1,88 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/oauth2/google"
+)
+
+const gcpKMSEndpoint = "https://cloudkms.googleapis.com/v1/"
+
+// gcpKMSSigner signs with an EC_SIGN_SECP256K1_SHA256 key version of GCP Cloud KMS using its REST API
+type gcpKMSSigner struct {
+	client   *http.Client
+	endpoint string
+	name     string
+}
+
+// newGCPKMSSigner uses the application default credentials of the environment
+func newGCPKMSSigner(name string) (*gcpKMSSigner, error) {
+	client, err := google.DefaultClient(context.Background(), "https://www.googleapis.com/auth/cloudkms")
+	if err != nil {
+		return nil, err
+	}
+	return &gcpKMSSigner{client: client, endpoint: gcpKMSEndpoint, name: name}, nil
+}
+
+func (s *gcpKMSSigner) PublicKey(ctx context.Context) ([]byte, error) {
+	var res struct {
+		Pem string `json:"pem"`
+	}
+	if err := s.do(ctx, http.MethodGet, s.name+"/publicKey", nil, &res); err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(res.Pem))
+	if block == nil {
+		return nil, errors.New("invalid public key PEM")
+	}
+	return block.Bytes, nil
+}
+
+// SignDigest passes the digest as a SHA-256 digest, which Cloud KMS signs as is
+func (s *gcpKMSSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	req := map[string]interface{}{
+		"digest": map[string][]byte{"sha256": digest},
+	}
+	var res struct {
+		Signature []byte `json:"signature"`
+	}
+	if err := s.do(ctx, http.MethodPost, s.name+":asymmetricSign", req, &res); err != nil {
+		return nil, err
+	}
+	return res.Signature, nil
+}
+
+func (s *gcpKMSSigner) do(ctx context.Context, method, path string, reqBody, resBody interface{}) error {
+	var body []byte
+	if reqBody != nil {
+		var err error
+		if body, err = json.Marshal(reqBody); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status=%v body=%q", resp.StatusCode, data)
+	}
+	return json.Unmarshal(data, resBody)
+}
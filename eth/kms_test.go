@@ -0,0 +1,218 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	lpcrypto "github.com/livepeer/go-livepeer/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKMS signs digests with a local key and returns DER encoded keys and signatures like a KMS does
+type fakeKMS struct {
+	key    *ecdsa.PrivateKey
+	highS  bool
+	err    error
+	digest []byte
+}
+
+func newFakeKMS(t *testing.T) *fakeKMS {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	return &fakeKMS{key: key}
+}
+
+func (k *fakeKMS) addr() ethcommon.Address {
+	return crypto.PubkeyToAddress(k.key.PublicKey)
+}
+
+func (k *fakeKMS) PublicKey(ctx context.Context) ([]byte, error) {
+	if k.err != nil {
+		return nil, k.err
+	}
+	return marshalTestPublicKey(&k.key.PublicKey, asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+}
+
+func (k *fakeKMS) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	if k.err != nil {
+		return nil, k.err
+	}
+	k.digest = digest
+	sig, err := crypto.Sign(digest, k.key)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	if k.highS {
+		s.Sub(crypto.S256().Params().N, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+
+func marshalTestPublicKey(pub *ecdsa.PublicKey, curve asn1.ObjectIdentifier) ([]byte, error) {
+	type algorithm struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters asn1.ObjectIdentifier
+	}
+	return asn1.Marshal(struct {
+		Algorithm algorithm
+		PublicKey asn1.BitString
+	}{
+		Algorithm: algorithm{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}, Parameters: curve},
+		PublicKey: asn1.BitString{Bytes: crypto.FromECDSAPub(pub), BitLength: 65 * 8},
+	})
+}
+
+func TestKMSAccountManager_Account(t *testing.T) {
+	kms := newFakeKMS(t)
+	am, err := newKMSAccountManager(kms, big.NewInt(1))
+	require.Nil(t, err)
+
+	assert.Equal(t, kms.addr(), am.Account().Address)
+	assert.Nil(t, am.Unlock("foo"))
+	assert.Nil(t, am.Lock())
+
+	opts, err := am.CreateTransactOpts(1000)
+	require.Nil(t, err)
+	assert.Equal(t, kms.addr(), opts.From)
+	assert.Equal(t, uint64(1000), opts.GasLimit)
+
+	kms.err = errors.New("access denied")
+	_, err = newKMSAccountManager(kms, big.NewInt(1))
+	assert.EqualError(t, err, `could not get KMS public key err="access denied"`)
+
+	_, err = NewKMSAccountManager(ethcommon.Address{}, "foo://bar", big.NewInt(1))
+	assert.EqualError(t, err, "invalid KMS key URI=foo://bar")
+}
+
+func TestKMSAccountManager_SignTx(t *testing.T) {
+	kms := newFakeKMS(t)
+	am, err := newKMSAccountManager(kms, big.NewInt(5))
+	require.Nil(t, err)
+	signer := types.LatestSignerForChainID(am.chainID)
+	to := ethcommon.HexToAddress("0x1234")
+
+	txs := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(100), Gas: 21000, To: &to, Value: big.NewInt(5)}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: am.chainID, Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(200), Gas: 21000, To: &to}),
+	}
+	for _, highS := range []bool{false, true} {
+		kms.highS = highS
+		for _, tx := range txs {
+			signed, err := am.SignTx(tx)
+			require.Nil(t, err)
+			sender, err := types.Sender(signer, signed)
+			require.Nil(t, err)
+			assert.Equal(t, kms.addr(), sender)
+			_, _, s := signed.RawSignatureValues()
+			assert.True(t, s.Cmp(secp256k1HalfN) <= 0)
+		}
+	}
+
+	kms.err = errors.New("throttled")
+	_, err = am.SignTx(txs[0])
+	assert.EqualError(t, err, `KMS signing failed err="throttled"`)
+}
+
+func TestKMSAccountManager_Sign(t *testing.T) {
+	kms := newFakeKMS(t)
+	am, err := newKMSAccountManager(kms, big.NewInt(1))
+	require.Nil(t, err)
+
+	sig, err := am.Sign([]byte("foo"))
+	require.Nil(t, err)
+	assert.Len(t, sig, 65)
+	assert.True(t, lpcrypto.VerifySig(kms.addr(), []byte("foo"), sig))
+
+	var d apitypes.TypedData
+	require.Nil(t, json.Unmarshal([]byte(jsonTypedData), &d))
+	sig, err = am.SignTypedData(d)
+	require.Nil(t, err)
+	hash, _, err := apitypes.TypedDataAndHash(d)
+	require.Nil(t, err)
+	assert.Equal(t, hash, kms.digest)
+	pub, err := crypto.SigToPub(hash, append(sig[:64:64], sig[64]-27))
+	require.Nil(t, err)
+	assert.Equal(t, kms.addr(), crypto.PubkeyToAddress(*pub))
+
+	// A signature of another key does not match the account
+	other := newFakeKMS(t)
+	am.signer = other
+	_, err = am.Sign([]byte("foo"))
+	assert.EqualError(t, err, "KMS signature does not match the account")
+}
+
+func TestParseKMSPublicKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	der, err := marshalTestPublicKey(&key.PublicKey, asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+	require.Nil(t, err)
+	pub, err := parseKMSPublicKey(der)
+	require.Nil(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*pub))
+
+	// P-256 key
+	der, err = marshalTestPublicKey(&key.PublicKey, asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+	require.Nil(t, err)
+	_, err = parseKMSPublicKey(der)
+	assert.EqualError(t, err, "KMS key is not a secp256k1 key curve=1.2.840.10045.3.1.7")
+
+	_, err = parseKMSPublicKey([]byte("foo"))
+	assert.Error(t, err)
+}
+
+func TestGCPKMSSigner(t *testing.T) {
+	kms := newFakeKMS(t)
+	name := "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/"+name+"/publicKey":
+			der, err := kms.PublicKey(r.Context())
+			require.Nil(t, err)
+			json.NewEncoder(w).Encode(map[string]string{"pem": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/"+name+":asymmetricSign":
+			var req struct {
+				Digest struct {
+					Sha256 []byte `json:"sha256"`
+				} `json:"digest"`
+			}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+			sig, err := kms.SignDigest(r.Context(), req.Digest.Sha256)
+			require.Nil(t, err)
+			json.NewEncoder(w).Encode(map[string]string{"signature": base64.StdEncoding.EncodeToString(sig)})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := &gcpKMSSigner{client: server.Client(), endpoint: server.URL + "/v1/", name: name}
+	am, err := newKMSAccountManager(s, big.NewInt(1))
+	require.Nil(t, err)
+	assert.Equal(t, kms.addr(), am.Account().Address)
+
+	sig, err := am.Sign([]byte("foo"))
+	require.Nil(t, err)
+	assert.True(t, lpcrypto.VerifySig(kms.addr(), []byte("foo"), sig))
+
+	s.name = "projects/p/locations/global/keyRings/r/cryptoKeys/missing/cryptoKeyVersions/1"
+	_, err = s.PublicKey(context.Background())
+	assert.EqualError(t, err, `status=404 body="not found\n"`)
+}
@@ -0,0 +1,336 @@
+package eth
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/golang/glog"
+	"github.com/karalabe/usb"
+)
+
+// DefaultLedgerPath is the derivation path of the first account of the Ledger Ethereum app
+const DefaultLedgerPath = "m/44'/60'/0'/0/0"
+
+const ledgerVendorID = 0x2c97
+
+// Instructions of the Ledger Ethereum app
+const (
+	ledgerInsGetAddress      = 0x02
+	ledgerInsSignTx          = 0x04
+	ledgerInsSignPersonalMsg = 0x08
+	ledgerInsSignEIP712      = 0x0c
+)
+
+// The first chunk of data signed by the Ledger Ethereum app is sent with P1 = 0x00 and the following chunks with P1 = 0x80
+const (
+	ledgerP1First = 0x00
+	ledgerP1More  = 0x80
+)
+
+// Maximum size of the data of an APDU
+const ledgerMaxChunk = 255
+
+var errLedgerReplyInvalidHeader = errors.New("ledger: invalid reply header")
+
+// ledgerStatusError is returned when the Ledger Ethereum app replies with a status other than success
+type ledgerStatusError uint16
+
+func (e ledgerStatusError) Error() string {
+	switch e {
+	case 0x6985:
+		return "ledger: request denied by the user"
+	case 0x6d00, 0x6e00:
+		return "ledger: Ethereum app not open"
+	case 0x6a80:
+		return "ledger: invalid data, enable blind signing or contract data in the Ethereum app settings"
+	}
+	return fmt.Sprintf("ledger: request failed status=%#04x", uint16(e))
+}
+
+// ledgerAccountManager signs with an account of a Ledger hardware wallet so that its key never leaves the device.
+// Transactions and messages have to be approved on the device.
+type ledgerAccountManager struct {
+	mu      sync.Mutex
+	device  io.ReadWriter
+	path    accounts.DerivationPath
+	account accounts.Account
+	chainID *big.Int
+}
+
+// NewLedgerAccountManager returns an AccountManager that uses the account of the first Ledger device connected over USB
+// at the given derivation path. The Ethereum app has to be open on the device. If accountAddr is set, the account has to match it.
+func NewLedgerAccountManager(accountAddr ethcommon.Address, path string, chainID *big.Int) (AccountManager, error) {
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Ledger derivation path=%v err=%q", path, err)
+	}
+	if !usb.Supported() {
+		return nil, errors.New("USB devices are not supported on this platform")
+	}
+	infos, err := usb.Enumerate(ledgerVendorID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		// The Ethereum app is on the HID interface of the device
+		if info.UsagePage != 0xffa0 && info.Interface != 0 {
+			continue
+		}
+		device, err := info.Open()
+		if err != nil {
+			return nil, fmt.Errorf("could not open Ledger device err=%q", err)
+		}
+		am, err := newLedgerAccountManager(device, derivationPath, chainID)
+		if err != nil {
+			device.Close()
+			return nil, err
+		}
+		if (accountAddr != ethcommon.Address{}) && am.account.Address != accountAddr {
+			device.Close()
+			return nil, fmt.Errorf("Ledger account %v at path %v does not match %v", am.account.Address.Hex(), path, accountAddr.Hex())
+		}
+		glog.Infof("Using Ethereum account: %v on Ledger at path %v", am.account.Address.Hex(), path)
+		return am, nil
+	}
+	return nil, errors.New("no Ledger device found")
+}
+
+func newLedgerAccountManager(device io.ReadWriter, path accounts.DerivationPath, chainID *big.Int) (*ledgerAccountManager, error) {
+	am := &ledgerAccountManager{device: device, path: path, chainID: chainID}
+	addr, err := am.deriveAddress()
+	if err != nil {
+		return nil, err
+	}
+	am.account = accounts.Account{Address: addr, URL: accounts.URL{Scheme: "ledger", Path: path.String()}}
+	return am, nil
+}
+
+// Unlock is a no-op, the Ledger is unlocked on the device
+func (am *ledgerAccountManager) Unlock(passphrase string) error {
+	return nil
+}
+
+// Lock is a no-op, the Ledger is locked on the device
+func (am *ledgerAccountManager) Lock() error {
+	return nil
+}
+
+func (am *ledgerAccountManager) CreateTransactOpts(gasLimit uint64) (*bind.TransactOpts, error) {
+	return &bind.TransactOpts{
+		From: am.account.Address,
+		Signer: func(addr ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if addr != am.account.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return am.SignTx(tx)
+		},
+		GasLimit: gasLimit,
+	}, nil
+}
+
+// SignTx signs a legacy or EIP-1559 transaction on the Ledger
+func (am *ledgerAccountManager) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	var payload []byte
+	var err error
+	switch tx.Type() {
+	case types.LegacyTxType:
+		payload, err = rlp.EncodeToBytes([]interface{}{
+			tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), am.chainID, uint(0), uint(0),
+		})
+	case types.DynamicFeeTxType:
+		payload, err = rlp.EncodeToBytes([]interface{}{
+			am.chainID, tx.Nonce(), tx.GasTipCap(), tx.GasFeeCap(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), tx.AccessList(),
+		})
+		payload = append([]byte{types.DynamicFeeTxType}, payload...)
+	default:
+		return nil, fmt.Errorf("ledger: unsupported transaction type=%v", tx.Type())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := am.signChunked(ledgerInsSignTx, am.encodePath(), payload)
+	if err != nil {
+		return nil, err
+	}
+	// The reply is V || R || S. The V of legacy transactions is the low byte of the EIP-155 V.
+	sig := append(reply[1:65:65], reply[0])
+	if tx.Type() == types.LegacyTxType {
+		sig[64] -= byte(am.chainID.Uint64()*2 + 35)
+	}
+	signer := types.LatestSignerForChainID(am.chainID)
+	signed, err := tx.WithSignature(signer, sig)
+	if err != nil {
+		return nil, err
+	}
+	if sender, err := types.Sender(signer, signed); err != nil || sender != am.account.Address {
+		return nil, errors.New("ledger: transaction signed by another account")
+	}
+	return signed, nil
+}
+
+// Sign signs a message prefixed as in personal_sign on the Ledger
+func (am *ledgerAccountManager) Sign(msg []byte) ([]byte, error) {
+	data := am.encodePath()
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], uint32(len(msg)))
+	reply, err := am.signChunked(ledgerInsSignPersonalMsg, data, msg)
+	if err != nil {
+		return nil, err
+	}
+	return ledgerSignature(reply), nil
+}
+
+// SignTypedData signs the EIP-712 hash of typedData on the Ledger
+func (am *ledgerAccountManager) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return nil, err
+	}
+	typedDataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, err
+	}
+	data := append(am.encodePath(), domainSeparator...)
+	data = append(data, typedDataHash...)
+	reply, err := am.exchange(ledgerInsSignEIP712, ledgerP1First, 0, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < 65 {
+		return nil, errors.New("ledger: invalid signature")
+	}
+	return ledgerSignature(reply), nil
+}
+
+func (am *ledgerAccountManager) Account() accounts.Account {
+	return am.account
+}
+
+func (am *ledgerAccountManager) deriveAddress() (ethcommon.Address, error) {
+	reply, err := am.exchange(ledgerInsGetAddress, 0, 0, am.encodePath())
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	// The reply is the length prefixed public key followed by the length prefixed hex address
+	if len(reply) < 1 || len(reply) < 1+int(reply[0])+1 {
+		return ethcommon.Address{}, errors.New("ledger: invalid address reply")
+	}
+	reply = reply[1+int(reply[0]):]
+	if len(reply) < 1+int(reply[0]) {
+		return ethcommon.Address{}, errors.New("ledger: invalid address reply")
+	}
+	hexAddr := string(reply[1 : 1+int(reply[0])])
+	addr, err := hex.DecodeString(strings.TrimPrefix(hexAddr, "0x"))
+	if err != nil || len(addr) != ethcommon.AddressLength {
+		return ethcommon.Address{}, fmt.Errorf("ledger: invalid address=%v", hexAddr)
+	}
+	return ethcommon.BytesToAddress(addr), nil
+}
+
+func (am *ledgerAccountManager) encodePath() []byte {
+	data := make([]byte, 1+4*len(am.path))
+	data[0] = byte(len(am.path))
+	for i, component := range am.path {
+		binary.BigEndian.PutUint32(data[1+4*i:], component)
+	}
+	return data
+}
+
+// signChunked sends header followed by payload in as many APDUs as needed and returns the signature in the last reply
+func (am *ledgerAccountManager) signChunked(ins byte, header, payload []byte) ([]byte, error) {
+	data := append(header, payload...)
+	p1 := byte(ledgerP1First)
+	var reply []byte
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > ledgerMaxChunk {
+			chunk = chunk[:ledgerMaxChunk]
+		}
+		var err error
+		reply, err = am.exchange(ins, p1, 0, chunk)
+		if err != nil {
+			return nil, err
+		}
+		data = data[len(chunk):]
+		p1 = ledgerP1More
+	}
+	if len(reply) < 65 {
+		return nil, errors.New("ledger: invalid signature")
+	}
+	return reply, nil
+}
+
+// ledgerSignature converts a V || R || S signature with V = 27 or 28 to the R || S || V format used by the node
+func ledgerSignature(reply []byte) []byte {
+	v := reply[0]
+	if v < 27 {
+		v += 27
+	}
+	return append(append([]byte{}, reply[1:65]...), v)
+}
+
+// exchange sends an APDU to the Ledger over the HID transport and returns the reply without its status word.
+// APDUs are framed with a 2 byte length and split in 64 byte packets that start with the channel, a tag and a sequence number.
+func (am *ledgerAccountManager) exchange(ins, p1, p2 byte, data []byte) ([]byte, error) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	apdu := make([]byte, 2, 7+len(data))
+	binary.BigEndian.PutUint16(apdu, uint16(5+len(data)))
+	apdu = append(apdu, 0xe0, ins, p1, p2, byte(len(data)))
+	apdu = append(apdu, data...)
+
+	header := []byte{0x01, 0x01, 0x05, 0x00, 0x00}
+	for seq := 0; len(apdu) > 0; seq++ {
+		chunk := make([]byte, 64)
+		copy(chunk, header)
+		binary.BigEndian.PutUint16(chunk[3:], uint16(seq))
+		n := copy(chunk[len(header):], apdu)
+		apdu = apdu[n:]
+		if _, err := am.device.Write(chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	var reply []byte
+	chunk := make([]byte, 64)
+	for seq := 0; ; seq++ {
+		if _, err := io.ReadFull(am.device, chunk); err != nil {
+			return nil, err
+		}
+		if chunk[0] != 0x01 || chunk[1] != 0x01 || chunk[2] != 0x05 || binary.BigEndian.Uint16(chunk[3:]) != uint16(seq) {
+			return nil, errLedgerReplyInvalidHeader
+		}
+		payload := chunk[5:]
+		if seq == 0 {
+			reply = make([]byte, 0, binary.BigEndian.Uint16(chunk[5:7]))
+			payload = chunk[7:]
+		}
+		if left := cap(reply) - len(reply); left > len(payload) {
+			reply = append(reply, payload...)
+		} else {
+			reply = append(reply, payload[:left]...)
+			break
+		}
+	}
+	if len(reply) < 2 {
+		return nil, errLedgerReplyInvalidHeader
+	}
+	if status := binary.BigEndian.Uint16(reply[len(reply)-2:]); status != 0x9000 {
+		return nil, ledgerStatusError(status)
+	}
+	return reply[:len(reply)-2], nil
+}
@@ -0,0 +1,259 @@
+package eth
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	lpcrypto "github.com/livepeer/go-livepeer/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLedger emulates the Ethereum app of a Ledger over the HID transport, signing with a local key
+type fakeLedger struct {
+	key     *ecdsa.PrivateKey
+	chainID *big.Int
+	deny    bool
+
+	in      []byte
+	inLen   int
+	inSeq   int
+	out     bytes.Buffer
+	pending []byte
+	apdus   int
+}
+
+func newFakeLedger(t *testing.T, chainID *big.Int) *fakeLedger {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	return &fakeLedger{key: key, chainID: chainID}
+}
+
+func (l *fakeLedger) addr() ethcommon.Address {
+	return crypto.PubkeyToAddress(l.key.PublicKey)
+}
+
+func (l *fakeLedger) Write(p []byte) (int, error) {
+	if len(p) != 64 || p[0] != 0x01 || p[1] != 0x01 || p[2] != 0x05 || int(binary.BigEndian.Uint16(p[3:])) != l.inSeq {
+		panic("invalid packet")
+	}
+	data := p[5:]
+	if l.inSeq == 0 {
+		l.inLen = int(binary.BigEndian.Uint16(p[5:]))
+		data = p[7:]
+	}
+	l.inSeq++
+	l.in = append(l.in, data...)
+	if len(l.in) >= l.inLen {
+		apdu := l.in[:l.inLen]
+		l.in, l.inSeq = nil, 0
+		l.reply(l.handle(apdu))
+	}
+	return len(p), nil
+}
+
+func (l *fakeLedger) Read(p []byte) (int, error) {
+	return l.out.Read(p)
+}
+
+func (l *fakeLedger) reply(data []byte, status uint16) {
+	data = append(data, byte(status>>8), byte(status))
+	msg := append([]byte{byte(len(data) >> 8), byte(len(data))}, data...)
+	for seq := 0; len(msg) > 0; seq++ {
+		chunk := make([]byte, 64)
+		copy(chunk, []byte{0x01, 0x01, 0x05, byte(seq >> 8), byte(seq)})
+		msg = msg[copy(chunk[5:], msg):]
+		l.out.Write(chunk)
+	}
+}
+
+func (l *fakeLedger) sign(hash []byte) (byte, []byte) {
+	sig, err := crypto.Sign(hash, l.key)
+	if err != nil {
+		panic(err)
+	}
+	return sig[64], sig[:64]
+}
+
+func (l *fakeLedger) handle(apdu []byte) ([]byte, uint16) {
+	l.apdus++
+	if apdu[0] != 0xe0 || int(apdu[4]) != len(apdu)-5 {
+		return nil, 0x6700
+	}
+	ins, p1, data := apdu[1], apdu[2], apdu[5:]
+	if p1 == ledgerP1First {
+		l.pending = nil
+	}
+	switch ins {
+	case ledgerInsGetAddress:
+		pub := crypto.FromECDSAPub(&l.key.PublicKey)
+		addr := hex.EncodeToString(l.addr().Bytes())
+		reply := append([]byte{byte(len(pub))}, pub...)
+		reply = append(reply, byte(len(addr)))
+		return append(reply, addr...), 0x9000
+	case ledgerInsSignTx:
+		l.pending = append(l.pending, data...)
+		payload := l.pending[1+4*int(l.pending[0]):]
+		list := payload
+		if payload[0] == types.DynamicFeeTxType {
+			list = payload[1:]
+		}
+		if _, _, _, err := rlp.Split(list); err != nil {
+			// Wait for the rest of the transaction
+			return nil, 0x9000
+		}
+		if l.deny {
+			return nil, 0x6985
+		}
+		v, rs := l.sign(crypto.Keccak256(payload))
+		if payload[0] != types.DynamicFeeTxType {
+			v += byte(l.chainID.Uint64()*2 + 35)
+		}
+		return append([]byte{v}, rs...), 0x9000
+	case ledgerInsSignPersonalMsg:
+		l.pending = append(l.pending, data...)
+		msg := l.pending[1+4*int(l.pending[0]):]
+		if len(msg)-4 < int(binary.BigEndian.Uint32(msg)) {
+			return nil, 0x9000
+		}
+		if l.deny {
+			return nil, 0x6985
+		}
+		v, rs := l.sign(accounts.TextHash(msg[4:]))
+		return append([]byte{v + 27}, rs...), 0x9000
+	case ledgerInsSignEIP712:
+		if l.deny {
+			return nil, 0x6985
+		}
+		hashes := data[1+4*int(data[0]):]
+		v, rs := l.sign(crypto.Keccak256([]byte{0x19, 0x01}, hashes))
+		return append([]byte{v + 27}, rs...), 0x9000
+	}
+	return nil, 0x6d00
+}
+
+func newTestLedgerAccountManager(t *testing.T) (*ledgerAccountManager, *fakeLedger) {
+	chainID := big.NewInt(421613)
+	device := newFakeLedger(t, chainID)
+	path, err := accounts.ParseDerivationPath(DefaultLedgerPath)
+	require.Nil(t, err)
+	am, err := newLedgerAccountManager(device, path, chainID)
+	require.Nil(t, err)
+	return am, device
+}
+
+func TestLedger_Account(t *testing.T) {
+	am, device := newTestLedgerAccountManager(t)
+
+	assert.Equal(t, device.addr(), am.Account().Address)
+	assert.Equal(t, "ledger://m/44'/60'/0'/0/0", am.Account().URL.String())
+	assert.Equal(t, []byte{5, 0x80, 0, 0, 44, 0x80, 0, 0, 60, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, am.encodePath())
+	assert.Nil(t, am.Unlock("foo"))
+	assert.Nil(t, am.Lock())
+
+	opts, err := am.CreateTransactOpts(1000)
+	require.Nil(t, err)
+	assert.Equal(t, device.addr(), opts.From)
+	assert.Equal(t, uint64(1000), opts.GasLimit)
+	to := ethcommon.HexToAddress("0x1234")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: am.chainID, Gas: 21000, To: &to})
+	_, err = opts.Signer(to, tx)
+	assert.Equal(t, bind.ErrNotAuthorized, err)
+	signed, err := opts.Signer(device.addr(), tx)
+	require.Nil(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(am.chainID), signed)
+	require.Nil(t, err)
+	assert.Equal(t, device.addr(), sender)
+}
+
+func TestLedger_SignTx(t *testing.T) {
+	am, device := newTestLedgerAccountManager(t)
+	to := ethcommon.HexToAddress("0x1234")
+	signer := types.LatestSignerForChainID(am.chainID)
+
+	// Large calldata is sent to the device in several APDUs
+	data := bytes.Repeat([]byte{0xab}, 600)
+
+	txs := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(100), Gas: 21000, To: &to, Value: big.NewInt(5)}),
+		types.NewTx(&types.LegacyTx{Nonce: 2, GasPrice: big.NewInt(100), Gas: 100000, To: &to, Data: data}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: am.chainID, Nonce: 3, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(200), Gas: 21000, To: &to, Value: big.NewInt(5)}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: am.chainID, Nonce: 4, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(200), Gas: 100000, To: &to, Data: data}),
+	}
+	apdus := []int{1, 3, 1, 3}
+	for i, tx := range txs {
+		device.apdus = 0
+		signed, err := am.SignTx(tx)
+		require.Nil(t, err)
+		sender, err := types.Sender(signer, signed)
+		require.Nil(t, err)
+		assert.Equal(t, device.addr(), sender)
+		assert.Equal(t, tx.Type(), signed.Type())
+		assert.Equal(t, apdus[i], device.apdus)
+	}
+
+	_, err := am.SignTx(types.NewTx(&types.AccessListTx{ChainID: am.chainID, To: &to}))
+	assert.EqualError(t, err, "ledger: unsupported transaction type=1")
+
+	device.deny = true
+	_, err = am.SignTx(txs[0])
+	assert.EqualError(t, err, "ledger: request denied by the user")
+}
+
+func TestLedger_Sign(t *testing.T) {
+	am, device := newTestLedgerAccountManager(t)
+
+	sig, err := am.Sign([]byte("foo"))
+	require.Nil(t, err)
+	assert.Len(t, sig, 65)
+	assert.True(t, lpcrypto.VerifySig(device.addr(), []byte("foo"), sig))
+
+	// Messages larger than an APDU
+	msg := bytes.Repeat([]byte("bar"), 200)
+	sig, err = am.Sign(msg)
+	require.Nil(t, err)
+	assert.True(t, lpcrypto.VerifySig(device.addr(), msg, sig))
+
+	device.deny = true
+	_, err = am.Sign([]byte("foo"))
+	assert.EqualError(t, err, "ledger: request denied by the user")
+}
+
+func TestLedger_SignTypedData(t *testing.T) {
+	am, device := newTestLedgerAccountManager(t)
+
+	var d apitypes.TypedData
+	require.Nil(t, json.Unmarshal([]byte(jsonTypedData), &d))
+
+	sig, err := am.SignTypedData(d)
+	require.Nil(t, err)
+	require.Len(t, sig, 65)
+	assert.Contains(t, []byte{27, 28}, sig[64])
+
+	hash, _, err := apitypes.TypedDataAndHash(d)
+	require.Nil(t, err)
+	pub, err := crypto.SigToPub(hash, append(sig[:64:64], sig[64]-27))
+	require.Nil(t, err)
+	assert.Equal(t, device.addr(), crypto.PubkeyToAddress(*pub))
+}
+
+func TestLedger_AppNotOpen(t *testing.T) {
+	path, err := accounts.ParseDerivationPath(DefaultLedgerPath)
+	require.Nil(t, err)
+	device := newFakeLedger(t, big.NewInt(1))
+	am := &ledgerAccountManager{device: device, path: path, chainID: big.NewInt(1)}
+	_, err = am.exchange(0x42, 0, 0, nil)
+	assert.EqualError(t, err, "ledger: Ethereum app not open")
+}
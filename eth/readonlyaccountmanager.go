@@ -0,0 +1,64 @@
+package eth
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/golang/glog"
+)
+
+// ErrReadOnly is returned when signing in read-only mode
+var ErrReadOnly = errors.New("cannot sign in read-only mode")
+
+// readOnlyAccountManager has no key, so that a node can read the chain without an unlocked account
+type readOnlyAccountManager struct {
+	account accounts.Account
+}
+
+// NewReadOnlyAccountManager returns an AccountManager that refuses to sign. The account address, which can be empty,
+// is only used to read the on-chain state of the account.
+func NewReadOnlyAccountManager(accountAddr ethcommon.Address) AccountManager {
+	glog.Infof("Using Ethereum account: %v in read-only mode", accountAddr.Hex())
+	return &readOnlyAccountManager{account: accounts.Account{Address: accountAddr}}
+}
+
+// Unlock is a no-op, there is no key to unlock
+func (am *readOnlyAccountManager) Unlock(passphrase string) error {
+	return nil
+}
+
+// Lock is a no-op, there is no key to lock
+func (am *readOnlyAccountManager) Lock() error {
+	return nil
+}
+
+// CreateTransactOpts returns transact opts that are used to bind the contracts, but that fail to sign transactions
+func (am *readOnlyAccountManager) CreateTransactOpts(gasLimit uint64) (*bind.TransactOpts, error) {
+	return &bind.TransactOpts{
+		From: am.account.Address,
+		Signer: func(addr ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return nil, ErrReadOnly
+		},
+		GasLimit: gasLimit,
+	}, nil
+}
+
+func (am *readOnlyAccountManager) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	return nil, ErrReadOnly
+}
+
+func (am *readOnlyAccountManager) Sign(msg []byte) ([]byte, error) {
+	return nil, ErrReadOnly
+}
+
+func (am *readOnlyAccountManager) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	return nil, ErrReadOnly
+}
+
+func (am *readOnlyAccountManager) Account() accounts.Account {
+	return am.account
+}
@@ -0,0 +1,39 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyAccountManager(t *testing.T) {
+	assert := assert.New(t)
+	addr := ethcommon.HexToAddress("0xD8E8328501E9645d16Cf49539efC04f734606ee4")
+	am := NewReadOnlyAccountManager(addr)
+
+	assert.Equal(addr, am.Account().Address)
+	assert.Nil(am.Unlock("foo"))
+	assert.Nil(am.Lock())
+
+	_, err := am.Sign([]byte("foo"))
+	assert.Equal(ErrReadOnly, err)
+	_, err = am.SignTypedData(apitypes.TypedData{})
+	assert.Equal(ErrReadOnly, err)
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1)})
+	_, err = am.SignTx(tx)
+	assert.Equal(ErrReadOnly, err)
+
+	// The transact opts can bind the contracts but cannot sign
+	opts, err := am.CreateTransactOpts(100)
+	require.Nil(t, err)
+	assert.Equal(addr, opts.From)
+	assert.Equal(uint64(100), opts.GasLimit)
+	_, err = opts.Signer(addr, tx)
+	assert.Equal(ErrReadOnly, err)
+}
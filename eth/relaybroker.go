@@ -0,0 +1,178 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/eth/contracts"
+	"github.com/livepeer/go-livepeer/pm"
+)
+
+var relayReceiptPollInterval = 2 * time.Second
+
+var relayClient = &http.Client{Timeout: 30 * time.Second}
+
+type receiptReader interface {
+	TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*types.Receipt, error)
+}
+
+// relayRequest is the body POSTed to the relay to submit a transaction
+type relayRequest struct {
+	To     ethcommon.Address `json:"to"`
+	Data   hexutil.Bytes     `json:"data"`
+	Method string            `json:"method"`
+}
+
+// relayResponse is the body returned by the relay with the signed transaction that it submitted
+type relayResponse struct {
+	Tx hexutil.Bytes `json:"tx"`
+}
+
+// RelayBroker is a pm.Broker that submits the ticket redemption transactions to a relay which sends them from its own
+// account, so that the node's account doesn't need ETH to pay for their gas. The other calls go to the wrapped broker.
+// The TicketBroker pays the face value of a winning ticket to its recipient regardless of the account that redeems it.
+type RelayBroker struct {
+	pm.Broker
+
+	url            *url.URL
+	ticketBroker   ethcommon.Address
+	abi            abi.ABI
+	backend        receiptReader
+	checkTxTimeout time.Duration
+
+	mu      sync.Mutex
+	relayed map[ethcommon.Hash]bool
+}
+
+// NewRelayBroker creates a RelayBroker that submits the redemption transactions for the TicketBroker contract at
+// ticketBroker to the relay at u, and waits up to checkTxTimeout for them to confirm
+func NewRelayBroker(broker pm.Broker, backend receiptReader, u *url.URL, ticketBroker ethcommon.Address, checkTxTimeout time.Duration) (*RelayBroker, error) {
+	brokerABI, err := abi.JSON(strings.NewReader(contracts.TicketBrokerABI))
+	if err != nil {
+		return nil, err
+	}
+	return &RelayBroker{
+		Broker:         broker,
+		url:            u,
+		ticketBroker:   ticketBroker,
+		abi:            brokerABI,
+		backend:        backend,
+		checkTxTimeout: checkTxTimeout,
+		relayed:        make(map[ethcommon.Hash]bool),
+	}, nil
+}
+
+// RedeemWinningTicket submits a ticket redemption transaction to the relay
+func (rb *RelayBroker) RedeemWinningTicket(ticket *pm.Ticket, sig []byte, recipientRand *big.Int) (*types.Transaction, error) {
+	data, err := rb.abi.Pack("redeemWinningTicket", brokerTicket(ticket), sig, recipientRand)
+	if err != nil {
+		return nil, err
+	}
+	return rb.relay("redeemWinningTicket", data)
+}
+
+// BatchRedeemWinningTickets submits a transaction redeeming multiple tickets to the relay
+func (rb *RelayBroker) BatchRedeemWinningTickets(tickets []*pm.Ticket, sigs [][]byte, recipientRands []*big.Int) (*types.Transaction, error) {
+	brokerTickets := make([]contracts.MTicketBrokerCoreTicket, len(tickets))
+	for i, ticket := range tickets {
+		brokerTickets[i] = brokerTicket(ticket)
+	}
+	data, err := rb.abi.Pack("batchRedeemWinningTickets", brokerTickets, sigs, recipientRands)
+	if err != nil {
+		return nil, err
+	}
+	return rb.relay("batchRedeemWinningTickets", data)
+}
+
+// CheckTx waits for a transaction to confirm on-chain and returns an error if the transaction failed.
+// The transactions that were not submitted to the relay are checked by the wrapped broker
+func (rb *RelayBroker) CheckTx(tx *types.Transaction) error {
+	rb.mu.Lock()
+	relayed := rb.relayed[tx.Hash()]
+	delete(rb.relayed, tx.Hash())
+	rb.mu.Unlock()
+	if !relayed {
+		return rb.Broker.CheckTx(tx)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rb.checkTxTimeout)
+	defer cancel()
+	ticker := time.NewTicker(relayReceiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := rb.backend.TransactionReceipt(ctx, tx.Hash())
+		if err == nil {
+			// Depends on the error in isNonRetryableTicketErr()
+			if receipt.Status == types.ReceiptStatusFailed {
+				return fmt.Errorf("transaction failed txHash=%v", tx.Hash().Hex())
+			}
+			return nil
+		}
+		if !errors.Is(err, ethereum.NotFound) && ctx.Err() == nil {
+			glog.V(5).Infof("Error getting receipt of relayed transaction txHash=%v err=%q", tx.Hash().Hex(), err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for transaction receipt txHash=%v", tx.Hash().Hex())
+		}
+	}
+}
+
+// relay POSTs a transaction to the relay and returns the signed transaction that the relay submitted
+func (rb *RelayBroker) relay(method string, data []byte) (*types.Transaction, error) {
+	body, err := json.Marshal(&relayRequest{To: rb.ticketBroker, Data: data, Method: method})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := relayClient.Post(rb.url.String(), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not submit transaction to relay url=%v err=%q", rb.url.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	rbody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("relay returned error status=%v err=%q", resp.StatusCode, strings.TrimSpace(string(rbody)))
+	}
+
+	var res relayResponse
+	if err := json.Unmarshal(rbody, &res); err != nil {
+		return nil, fmt.Errorf("could not parse relay response err=%q", err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(res.Tx); err != nil {
+		return nil, fmt.Errorf("could not decode transaction returned by relay err=%q", err)
+	}
+	// Only wait for the transaction that was requested, so that a misbehaving relay can't have tickets marked as redeemed
+	if tx.To() == nil || *tx.To() != rb.ticketBroker || !bytes.Equal(tx.Data(), data) {
+		return nil, fmt.Errorf("transaction returned by relay does not match the request txHash=%v", tx.Hash().Hex())
+	}
+
+	rb.mu.Lock()
+	rb.relayed[tx.Hash()] = true
+	rb.mu.Unlock()
+
+	glog.Infof("Submitted transaction to relay method=%v txHash=%v", method, tx.Hash().Hex())
+	return tx, nil
+}
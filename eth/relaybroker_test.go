@@ -0,0 +1,140 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubReceiptReader struct {
+	receipts map[ethcommon.Hash]*types.Receipt
+	err      error
+}
+
+func (r *stubReceiptReader) TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*types.Receipt, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	receipt, ok := r.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func relayTicket() *pm.Ticket {
+	return &pm.Ticket{
+		Recipient:         pm.RandAddress(),
+		Sender:            pm.RandAddress(),
+		FaceValue:         big.NewInt(1000),
+		WinProb:           big.NewInt(500),
+		SenderNonce:       1,
+		RecipientRandHash: pm.RandHash(),
+		CreationRound:     10,
+	}
+}
+
+func TestRelayBroker_RedeemWinningTicket(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ticketBroker := ethcommon.BytesToAddress([]byte("ticketBroker"))
+	var req relayRequest
+	var tx *types.Transaction
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Nil(json.NewDecoder(r.Body).Decode(&req))
+		if status != http.StatusOK {
+			http.Error(w, "relay error", status)
+			return
+		}
+		if tx == nil {
+			tx = types.NewTx(&types.LegacyTx{Nonce: 1, To: &req.To, Data: req.Data, Gas: 500000, GasPrice: big.NewInt(10)})
+		}
+		b, err := tx.MarshalBinary()
+		require.Nil(err)
+		json.NewEncoder(w).Encode(&relayResponse{Tx: b})
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.Nil(err)
+
+	rb, err := NewRelayBroker(&StubClient{}, &stubReceiptReader{}, u, ticketBroker, time.Second)
+	require.Nil(err)
+
+	ticket := relayTicket()
+	sig := []byte("sig")
+	recipientRand := big.NewInt(7)
+	res, err := rb.RedeemWinningTicket(ticket, sig, recipientRand)
+	require.Nil(err)
+	assert.Equal(tx.Hash(), res.Hash())
+	assert.Equal(ticketBroker, req.To)
+	assert.Equal("redeemWinningTicket", req.Method)
+	expData, err := rb.abi.Pack("redeemWinningTicket", brokerTicket(ticket), sig, recipientRand)
+	require.Nil(err)
+	assert.Equal(hexutil.Bytes(expData), req.Data)
+	assert.True(rb.relayed[res.Hash()])
+
+	res, err = rb.BatchRedeemWinningTickets([]*pm.Ticket{ticket, relayTicket()}, [][]byte{sig, sig}, []*big.Int{recipientRand, recipientRand})
+	assert.Nil(res)
+	assert.EqualError(err, "transaction returned by relay does not match the request txHash="+tx.Hash().Hex())
+	assert.Equal("batchRedeemWinningTickets", req.Method)
+
+	tx = nil
+	res, err = rb.BatchRedeemWinningTickets([]*pm.Ticket{ticket, relayTicket()}, [][]byte{sig, sig}, []*big.Int{recipientRand, recipientRand})
+	assert.Nil(err)
+	assert.Equal(tx.Hash(), res.Hash())
+
+	// relay errors
+	status = http.StatusServiceUnavailable
+	_, err = rb.RedeemWinningTicket(ticket, sig, recipientRand)
+	assert.EqualError(err, `relay returned error status=503 err="relay error"`)
+}
+
+func TestRelayBroker_CheckTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func(d time.Duration) { relayReceiptPollInterval = d }(relayReceiptPollInterval)
+	relayReceiptPollInterval = 10 * time.Millisecond
+
+	client := &StubClient{CheckTxErr: errors.New("CheckTx error")}
+	receipts := &stubReceiptReader{receipts: make(map[ethcommon.Hash]*types.Receipt)}
+	rb, err := NewRelayBroker(client, receipts, &url.URL{}, ethcommon.Address{}, 100*time.Millisecond)
+	require.Nil(err)
+
+	// Transactions not sent through the relay are checked by the wrapped broker
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	assert.EqualError(rb.CheckTx(tx), "CheckTx error")
+
+	rb.relayed[tx.Hash()] = true
+	assert.EqualError(rb.CheckTx(tx), "timed out waiting for transaction receipt txHash="+tx.Hash().Hex())
+	assert.Empty(rb.relayed)
+
+	rb.relayed[tx.Hash()] = true
+	receipts.err = errors.New("RPC error")
+	assert.EqualError(rb.CheckTx(tx), "timed out waiting for transaction receipt txHash="+tx.Hash().Hex())
+	receipts.err = nil
+
+	rb.relayed[tx.Hash()] = true
+	receipts.receipts[tx.Hash()] = &types.Receipt{Status: types.ReceiptStatusFailed}
+	assert.EqualError(rb.CheckTx(tx), "transaction failed txHash="+tx.Hash().Hex())
+
+	rb.relayed[tx.Hash()] = true
+	receipts.receipts[tx.Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful}
+	assert.Nil(rb.CheckTx(tx))
+}
@@ -0,0 +1,216 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/golang/glog"
+)
+
+// Remote signer APIs
+const (
+	// RemoteSignerClef is the account_* API of clef
+	RemoteSignerClef = "clef"
+	// RemoteSignerWeb3Signer is the eth_* signing API of web3signer and Ethereum nodes
+	RemoteSignerWeb3Signer = "web3signer"
+)
+
+// Time to wait for the remote signer, which includes the time it takes to approve a request if the signer requires manual approval
+var remoteSignerTimeout = 2 * time.Minute
+
+// remoteAccountManager delegates signing to a remote signer so that the node never holds the key of the account.
+// The remote signer enforces its own policy on which requests it signs.
+type remoteAccountManager struct {
+	client  *rpc.Client
+	api     string
+	account accounts.Account
+	chainID *big.Int
+}
+
+// NewRemoteAccountManager returns an AccountManager that signs with the remote signer at url using the given API.
+// If accountAddr is not set, the first account of the remote signer is used.
+func NewRemoteAccountManager(accountAddr ethcommon.Address, url, api string, chainID *big.Int) (AccountManager, error) {
+	if api != RemoteSignerClef && api != RemoteSignerWeb3Signer {
+		return nil, fmt.Errorf("invalid remote signer API=%v", api)
+	}
+	if url == "" {
+		return nil, errors.New("missing remote signer URL")
+	}
+	client, err := rpc.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to remote signer url=%v err=%q", url, err)
+	}
+	am, err := newRemoteAccountManager(client, api, accountAddr, chainID)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	glog.Infof("Using Ethereum account: %v on %v remote signer", am.account.Address.Hex(), api)
+	return am, nil
+}
+
+func newRemoteAccountManager(client *rpc.Client, api string, accountAddr ethcommon.Address, chainID *big.Int) (*remoteAccountManager, error) {
+	am := &remoteAccountManager{client: client, api: api, chainID: chainID}
+
+	var addrs []ethcommon.Address
+	if err := am.call(&addrs, "list", "accounts"); err != nil {
+		return nil, fmt.Errorf("could not list remote signer accounts err=%q", err)
+	}
+	for _, addr := range addrs {
+		if (accountAddr == ethcommon.Address{}) || addr == accountAddr {
+			am.account = accounts.Account{Address: addr}
+			return am, nil
+		}
+	}
+	if (accountAddr == ethcommon.Address{}) {
+		return nil, errors.New("remote signer has no accounts")
+	}
+	return nil, fmt.Errorf("account %v not found on remote signer", accountAddr.Hex())
+}
+
+// Unlock is a no-op, the account is managed by the remote signer
+func (am *remoteAccountManager) Unlock(passphrase string) error {
+	return nil
+}
+
+// Lock is a no-op, the account is managed by the remote signer
+func (am *remoteAccountManager) Lock() error {
+	return nil
+}
+
+func (am *remoteAccountManager) CreateTransactOpts(gasLimit uint64) (*bind.TransactOpts, error) {
+	return &bind.TransactOpts{
+		From: am.account.Address,
+		Signer: func(addr ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if addr != am.account.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return am.SignTx(tx)
+		},
+		GasLimit: gasLimit,
+	}, nil
+}
+
+// SignTx asks the remote signer to sign tx and checks that the signed transaction is the one that was requested
+func (am *remoteAccountManager) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:    ethcommon.NewMixedcaseAddress(am.account.Address),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    &data,
+		ChainID: (*hexutil.Big)(am.chainID),
+	}
+	if tx.To() != nil {
+		to := ethcommon.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+	switch tx.Type() {
+	case types.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	default:
+		return nil, fmt.Errorf("remote signer: unsupported transaction type=%v", tx.Type())
+	}
+
+	// clef returns the raw transaction in an object and web3signer returns it directly
+	var res json.RawMessage
+	if err := am.call(&res, "signTransaction", "signTransaction", &args); err != nil {
+		return nil, err
+	}
+	var raw hexutil.Bytes
+	if am.api == RemoteSignerClef {
+		var clefRes struct {
+			Raw hexutil.Bytes `json:"raw"`
+		}
+		if err := json.Unmarshal(res, &clefRes); err != nil {
+			return nil, err
+		}
+		raw = clefRes.Raw
+	} else if err := json.Unmarshal(res, &raw); err != nil {
+		return nil, err
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid transaction err=%q", err)
+	}
+	signer := types.LatestSignerForChainID(am.chainID)
+	// The remote signer could have changed the transaction, for example if its policy sets the fees
+	if signer.Hash(signed) != signer.Hash(tx) {
+		return nil, errors.New("remote signer modified the transaction")
+	}
+	if sender, err := types.Sender(signer, signed); err != nil || sender != am.account.Address {
+		return nil, errors.New("remote signer signed the transaction with another account")
+	}
+	return signed, nil
+}
+
+// Sign asks the remote signer to sign a message prefixed as in personal_sign
+func (am *remoteAccountManager) Sign(msg []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	var err error
+	if am.api == RemoteSignerClef {
+		err = am.call(&sig, "signData", "", accounts.MimetypeTextPlain, am.account.Address, hexutil.Bytes(msg))
+	} else {
+		err = am.call(&sig, "", "sign", am.account.Address, hexutil.Bytes(msg))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return remoteSignature(sig)
+}
+
+// SignTypedData asks the remote signer to sign typedData as in eth_signTypedData
+func (am *remoteAccountManager) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	var sig hexutil.Bytes
+	if err := am.call(&sig, "signTypedData", "signTypedData", am.account.Address, typedData); err != nil {
+		return nil, err
+	}
+	return remoteSignature(sig)
+}
+
+func (am *remoteAccountManager) Account() accounts.Account {
+	return am.account
+}
+
+// call calls the clef account_<clefMethod> or the web3signer eth_<web3SignerMethod> method
+func (am *remoteAccountManager) call(result interface{}, clefMethod, web3SignerMethod string, args ...interface{}) error {
+	method := "eth_" + web3SignerMethod
+	if am.api == RemoteSignerClef {
+		method = "account_" + clefMethod
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteSignerTimeout)
+	defer cancel()
+	if err := am.client.CallContext(ctx, result, method, args...); err != nil {
+		return fmt.Errorf("remote signer: %v", err)
+	}
+	return nil
+}
+
+// remoteSignature checks a [R || S || V] signature and converts the V param to 27 or 28
+func remoteSignature(sig []byte) ([]byte, error) {
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("remote signer returned an invalid signature length=%v", len(sig))
+	}
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
@@ -0,0 +1,236 @@
+package eth
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	lpcrypto "github.com/livepeer/go-livepeer/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSigner signs with a local key like a remote signer would
+type fakeSigner struct {
+	key     *ecdsa.PrivateKey
+	chainID *big.Int
+	deny    bool
+	gas     uint64
+}
+
+func (s *fakeSigner) addr() ethcommon.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *fakeSigner) signTx(args apitypes.SendTxArgs) (hexutil.Bytes, error) {
+	if s.deny {
+		return nil, errors.New("Request denied")
+	}
+	if s.gas > 0 {
+		args.Gas = hexutil.Uint64(s.gas)
+	}
+	tx, err := types.SignTx(args.ToTransaction(), types.LatestSignerForChainID(s.chainID), s.key)
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+func (s *fakeSigner) signHash(hash []byte) (hexutil.Bytes, error) {
+	if s.deny {
+		return nil, errors.New("Request denied")
+	}
+	sig, err := crypto.Sign(hash, s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func (s *fakeSigner) signTypedData(typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	return s.signHash(hash)
+}
+
+// fakeClef implements the account_* API of clef
+type fakeClef struct{ *fakeSigner }
+
+func (s *fakeClef) List() []ethcommon.Address {
+	return []ethcommon.Address{ethcommon.HexToAddress("0x1"), s.addr()}
+}
+
+func (s *fakeClef) SignTransaction(args apitypes.SendTxArgs, methodSelector *string) (interface{}, error) {
+	raw, err := s.signTx(args)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": raw}, nil
+}
+
+func (s *fakeClef) SignData(contentType string, addr ethcommon.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if contentType != accounts.MimetypeTextPlain || addr.Address() != s.addr() {
+		return nil, errors.New("invalid request")
+	}
+	return s.signHash(accounts.TextHash(data))
+}
+
+func (s *fakeClef) SignTypedData(addr ethcommon.MixedcaseAddress, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	if addr.Address() != s.addr() {
+		return nil, errors.New("invalid request")
+	}
+	return s.signTypedData(typedData)
+}
+
+// fakeWeb3Signer implements the eth_* signing API of web3signer
+type fakeWeb3Signer struct{ *fakeSigner }
+
+func (s *fakeWeb3Signer) Accounts() []ethcommon.Address {
+	return []ethcommon.Address{s.addr()}
+}
+
+func (s *fakeWeb3Signer) SignTransaction(args apitypes.SendTxArgs) (hexutil.Bytes, error) {
+	return s.signTx(args)
+}
+
+func (s *fakeWeb3Signer) Sign(addr ethcommon.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if addr != s.addr() {
+		return nil, errors.New("invalid request")
+	}
+	return s.signHash(accounts.TextHash(data))
+}
+
+func (s *fakeWeb3Signer) SignTypedData(addr ethcommon.Address, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	if addr != s.addr() {
+		return nil, errors.New("invalid request")
+	}
+	return s.signTypedData(typedData)
+}
+
+func newTestRemoteSigner(t *testing.T) (*rpc.Server, *fakeSigner) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	signer := &fakeSigner{key: key, chainID: big.NewInt(421613)}
+	server := rpc.NewServer()
+	require.Nil(t, server.RegisterName("account", &fakeClef{signer}))
+	require.Nil(t, server.RegisterName("eth", &fakeWeb3Signer{signer}))
+	t.Cleanup(server.Stop)
+	return server, signer
+}
+
+func TestRemoteAccountManager_Account(t *testing.T) {
+	server, signer := newTestRemoteSigner(t)
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	for _, api := range []string{RemoteSignerClef, RemoteSignerWeb3Signer} {
+		// Defaults to the first account
+		am, err := newRemoteAccountManager(client, api, ethcommon.Address{}, signer.chainID)
+		require.Nil(t, err)
+		if api == RemoteSignerClef {
+			assert.Equal(t, ethcommon.HexToAddress("0x1"), am.Account().Address)
+		} else {
+			assert.Equal(t, signer.addr(), am.Account().Address)
+		}
+
+		am, err = newRemoteAccountManager(client, api, signer.addr(), signer.chainID)
+		require.Nil(t, err)
+		assert.Equal(t, signer.addr(), am.Account().Address)
+		assert.Nil(t, am.Unlock(""))
+		assert.Nil(t, am.Lock())
+
+		_, err = newRemoteAccountManager(client, api, ethcommon.HexToAddress("0x2"), signer.chainID)
+		assert.EqualError(t, err, "account 0x0000000000000000000000000000000000000002 not found on remote signer")
+	}
+
+	_, err := NewRemoteAccountManager(signer.addr(), "http://localhost:8550", "foo", signer.chainID)
+	assert.EqualError(t, err, "invalid remote signer API=foo")
+	_, err = NewRemoteAccountManager(signer.addr(), "", RemoteSignerClef, signer.chainID)
+	assert.EqualError(t, err, "missing remote signer URL")
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	am, err := NewRemoteAccountManager(signer.addr(), ts.URL, RemoteSignerClef, signer.chainID)
+	require.Nil(t, err)
+	assert.Equal(t, signer.addr(), am.Account().Address)
+}
+
+func TestRemoteAccountManager_Sign(t *testing.T) {
+	server, signer := newTestRemoteSigner(t)
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var d apitypes.TypedData
+	require.Nil(t, json.Unmarshal([]byte(jsonTypedData), &d))
+	typedDataHash, _, err := apitypes.TypedDataAndHash(d)
+	require.Nil(t, err)
+
+	to := ethcommon.HexToAddress("0x1234")
+	txs := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(100), Gas: 21000, To: &to, Value: big.NewInt(5)}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: signer.chainID, Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(200), Gas: 100000, To: &to, Data: []byte("foo")}),
+	}
+
+	for _, api := range []string{RemoteSignerClef, RemoteSignerWeb3Signer} {
+		signer.deny = false
+		am, err := newRemoteAccountManager(client, api, signer.addr(), signer.chainID)
+		require.Nil(t, err)
+
+		sig, err := am.Sign([]byte("foo"))
+		require.Nil(t, err)
+		assert.True(t, lpcrypto.VerifySig(signer.addr(), []byte("foo"), sig))
+
+		sig, err = am.SignTypedData(d)
+		require.Nil(t, err)
+		pub, err := crypto.SigToPub(typedDataHash, append(sig[:64:64], sig[64]-27))
+		require.Nil(t, err)
+		assert.Equal(t, signer.addr(), crypto.PubkeyToAddress(*pub))
+
+		opts, err := am.CreateTransactOpts(1000)
+		require.Nil(t, err)
+		assert.Equal(t, signer.addr(), opts.From)
+		for _, tx := range txs {
+			signed, err := opts.Signer(signer.addr(), tx)
+			require.Nil(t, err)
+			assert.Equal(t, tx.Type(), signed.Type())
+			sender, err := types.Sender(types.LatestSignerForChainID(signer.chainID), signed)
+			require.Nil(t, err)
+			assert.Equal(t, signer.addr(), sender)
+		}
+
+		// The signed transaction has to be the requested one
+		signer.gas = 50000
+		_, err = am.SignTx(txs[0])
+		assert.EqualError(t, err, "remote signer modified the transaction")
+		signer.gas = 0
+
+		signer.deny = true
+		_, err = am.SignTx(txs[0])
+		assert.EqualError(t, err, "remote signer: Request denied")
+		_, err = am.Sign([]byte("foo"))
+		assert.EqualError(t, err, "remote signer: Request denied")
+	}
+}
+
+func TestRemoteSignature(t *testing.T) {
+	_, err := remoteSignature([]byte{1, 2})
+	assert.EqualError(t, err, "remote signer returned an invalid signature length=2")
+
+	sig := make([]byte, 65)
+	sig, err = remoteSignature(sig)
+	require.Nil(t, err)
+	assert.Equal(t, byte(27), sig[64])
+}
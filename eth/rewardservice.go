@@ -3,10 +3,13 @@ package eth
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/monitor"
 )
 
 var (
@@ -14,21 +17,66 @@ var (
 	ErrRewardServiceStopped = fmt.Errorf("reward service already stopped")
 )
 
+// RewardRetryConfig defines how the RewardService retries the reward calls that failed or timed out within a round
+type RewardRetryConfig struct {
+	// MaxAttempts is the max number of reward calls in a round. Values below 1 mean a single call
+	MaxAttempts int
+	// RetryInterval is the time to wait before retrying a reward call
+	RetryInterval time.Duration
+	// PriceBump is the % by which the gas fees of each retry are raised above those of the previous attempt
+	PriceBump uint64
+}
+
+// RewardAlertType is the type of a RewardAlert
+type RewardAlertType string
+
+const (
+	// RewardCallFailed is emitted when a reward call fails and is going to be retried
+	RewardCallFailed RewardAlertType = "rewardCallFailed"
+	// RewardMissed is emitted when reward could not be called in a round
+	RewardMissed RewardAlertType = "rewardMissed"
+)
+
+// RewardAlert is emitted by the RewardService when the reward of a round risks being missed
+type RewardAlert struct {
+	Type    RewardAlertType `json:"type"`
+	Round   *big.Int        `json:"round"`
+	Attempt int             `json:"attempt"`
+	Error   string          `json:"error"`
+	Time    time.Time       `json:"time"`
+}
+
 type RewardService struct {
 	client       LivepeerEthClient
 	working      bool
 	cancelWorker context.CancelFunc
 	tw           timeWatcher
+	retry        RewardRetryConfig
+	alert        func(*RewardAlert)
+	safeMode     func() bool
 	mu           sync.Mutex
 }
 
-func NewRewardService(client LivepeerEthClient, tw timeWatcher) *RewardService {
+func NewRewardService(client LivepeerEthClient, tw timeWatcher, retry RewardRetryConfig) *RewardService {
 	return &RewardService{
 		client: client,
 		tw:     tw,
+		retry:  retry,
 	}
 }
 
+// SetAlertHandler sets the function that the alerts of missed or failing reward calls are passed to.
+// It should be called before Start.
+func (s *RewardService) SetAlertHandler(alert func(*RewardAlert)) {
+	s.alert = alert
+}
+
+// SetSafeMode sets the function that reports whether reward calls are paused because of a low ETH balance.
+// It should be called before Start.
+func (s *RewardService) SetSafeMode(safeMode func() bool) {
+	s.safeMode = safeMode
+}
+
 func (s *RewardService) Start(ctx context.Context) error {
 	if s.working {
 		return ErrRewardServiceStarted
@@ -54,7 +102,7 @@ func (s *RewardService) Start(ctx context.Context) error {
 			}
 		case <-roundSink:
 			go func() {
-				err := s.tryReward()
+				err := s.tryReward(cancelCtx)
 				if err != nil {
 					glog.Errorf("Error trying to call reward err=%q", err)
 				}
@@ -81,31 +129,114 @@ func (s *RewardService) IsWorking() bool {
 	return s.working
 }
 
-func (s *RewardService) tryReward() error {
+func (s *RewardService) tryReward(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	currentRound := s.tw.LastInitializedRound()
 
-	t, err := s.client.GetTranscoder(s.client.Account().Address)
-	if err != nil {
-		return err
+	maxAttempts := s.retry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
 	}
 
-	if t.LastRewardRound.Cmp(currentRound) == -1 && t.Active {
-		tx, err := s.client.Reward()
-		if err != nil {
-			return err
+	var err error
+	attempt := 0
+	for ; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(s.retry.RetryInterval):
+			case <-ctx.Done():
+				return err
+			}
+			// Reward can only be called during the round
+			if s.tw.LastInitializedRound().Cmp(currentRound) != 0 {
+				break
+			}
+		}
+
+		// Retry later in the round in case the balance is topped up in the meantime
+		if s.safeMode != nil && s.safeMode() {
+			err = ErrSafeMode
+			glog.Warningf("Not calling reward for round %v in safe mode attempt=%d", currentRound, attempt+1)
+			continue
+		}
+
+		if err = s.callReward(currentRound, attempt); err == nil {
+			return nil
 		}
 
-		if err := s.client.CheckTx(tx); err != nil {
-			return err
+		if monitor.Enabled {
+			monitor.RewardCallError()
+		}
+		if attempt+1 < maxAttempts {
+			glog.Errorf("Error calling reward for round %v, retrying in %v attempt=%d err=%q", currentRound, s.retry.RetryInterval, attempt+1, err)
+			s.sendAlert(RewardCallFailed, currentRound, attempt+1, err)
 		}
+	}
 
-		glog.Infof("Called reward for round %v", currentRound)
+	glog.Warningf("Missed reward for round %v", currentRound)
+	if monitor.Enabled {
+		monitor.RewardMissed()
+	}
+	s.sendAlert(RewardMissed, currentRound, attempt, err)
 
+	return err
+}
+
+// callReward calls reward if it was not called yet for the round. Retries raise the gas fees above those of the previous attempt
+// so that they are not stuck behind a gas price spike.
+func (s *RewardService) callReward(currentRound *big.Int, attempt int) error {
+	t, err := s.client.GetTranscoder(s.client.Account().Address)
+	if err != nil {
+		return err
+	}
+
+	// A transaction of a previous attempt could have been mined in the meantime
+	if t.LastRewardRound.Cmp(currentRound) >= 0 || !t.Active {
 		return nil
 	}
 
+	var tx *types.Transaction
+	if attempt == 0 {
+		tx, err = s.client.Reward()
+	} else {
+		tx, err = s.client.RewardWithPriceBump(cumulativePriceBump(s.retry.PriceBump, attempt))
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.CheckTx(tx); err != nil {
+		return err
+	}
+
+	glog.Infof("Called reward for round %v", currentRound)
+
 	return nil
 }
+
+func (s *RewardService) sendAlert(typ RewardAlertType, round *big.Int, attempt int, err error) {
+	if s.alert == nil {
+		return
+	}
+	alert := &RewardAlert{
+		Type:    typ,
+		Round:   new(big.Int).Set(round),
+		Attempt: attempt,
+		Time:    time.Now(),
+	}
+	if err != nil {
+		alert.Error = err.Error()
+	}
+	s.alert(alert)
+}
+
+// cumulativePriceBump returns the % by which a price is raised by applying priceBump n times
+func cumulativePriceBump(priceBump uint64, n int) uint64 {
+	val := big.NewInt(100)
+	for i := 0; i < n; i++ {
+		val = applyPriceBump(val, priceBump)
+	}
+	return val.Uint64() - 100
+}
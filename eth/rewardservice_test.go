@@ -2,6 +2,7 @@ package eth
 
 import (
 	"context"
+	"errors"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"math/big"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/golang/glog"
 	lpTypes "github.com/livepeer/go-livepeer/eth/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -127,3 +129,145 @@ func TestRewardService_ReceiveRoundEvent_TryReward(t *testing.T) {
 	assert.Equal(int64(1), errorLogsAfter-errorLogsBefore)
 	assert.Equal(int64(0), infoLogsAfter-infoLogsBefore)
 }
+
+func TestRewardService_TryReward_Retry(t *testing.T) {
+	assert := assert.New(t)
+	eth := &MockClient{}
+	tw := &stubTimeWatcher{
+		lastInitializedRound: big.NewInt(100),
+	}
+	var alerts []*RewardAlert
+	rs := NewRewardService(eth, tw, RewardRetryConfig{MaxAttempts: 3, RetryInterval: time.Millisecond, PriceBump: 20})
+	rs.SetAlertHandler(func(alert *RewardAlert) { alerts = append(alerts, alert) })
+
+	addr := ethcommon.Address{}
+	eth.On("Account").Return(accounts.Account{Address: addr})
+	eth.On("GetTranscoder", addr).Return(&lpTypes.Transcoder{
+		LastRewardRound: big.NewInt(1),
+		Active:          true,
+	}, nil)
+
+	// Retries raise the gas fees until the reward call succeeds
+	eth.On("Reward").Return(&types.Transaction{}, nil).Once()
+	eth.On("CheckTx").Return(context.DeadlineExceeded).Once()
+	eth.On("RewardWithPriceBump", uint64(20)).Return(&types.Transaction{}, nil).Once()
+	eth.On("CheckTx").Return(nil).Once()
+
+	assert.Nil(rs.tryReward(context.Background()))
+	eth.AssertNumberOfCalls(t, "Reward", 1)
+	eth.AssertNumberOfCalls(t, "RewardWithPriceBump", 1)
+	assert.Len(alerts, 1)
+	assert.Equal(RewardCallFailed, alerts[0].Type)
+	assert.Equal(big.NewInt(100), alerts[0].Round)
+	assert.Equal(1, alerts[0].Attempt)
+	assert.Equal(context.DeadlineExceeded.Error(), alerts[0].Error)
+
+	// Reward is missed once all the attempts failed
+	alerts = nil
+	eth.On("Reward").Return(nil, errors.New("reward error")).Once()
+	eth.On("RewardWithPriceBump", uint64(20)).Return(nil, errors.New("reward error")).Once()
+	eth.On("RewardWithPriceBump", uint64(44)).Return(nil, errors.New("reward error")).Once()
+
+	assert.EqualError(rs.tryReward(context.Background()), "reward error")
+	assert.Len(alerts, 3)
+	assert.Equal(RewardMissed, alerts[2].Type)
+	assert.Equal(3, alerts[2].Attempt)
+}
+
+func TestRewardService_TryReward_RoundEnded(t *testing.T) {
+	assert := assert.New(t)
+	eth := &MockClient{}
+	tw := &stubTimeWatcher{
+		lastInitializedRound: big.NewInt(100),
+	}
+	var alerts []*RewardAlert
+	rs := NewRewardService(eth, tw, RewardRetryConfig{MaxAttempts: 3, RetryInterval: time.Millisecond, PriceBump: 20})
+	rs.SetAlertHandler(func(alert *RewardAlert) {
+		alerts = append(alerts, alert)
+		// The next round starts before the retry
+		tw.lastInitializedRound = big.NewInt(101)
+	})
+
+	addr := ethcommon.Address{}
+	eth.On("Account").Return(accounts.Account{Address: addr})
+	eth.On("GetTranscoder", addr).Return(&lpTypes.Transcoder{
+		LastRewardRound: big.NewInt(1),
+		Active:          true,
+	}, nil)
+	eth.On("Reward").Return(nil, errors.New("reward error")).Once()
+
+	assert.EqualError(rs.tryReward(context.Background()), "reward error")
+	eth.AssertNotCalled(t, "RewardWithPriceBump", mock.Anything)
+	assert.Len(alerts, 2)
+	assert.Equal(RewardMissed, alerts[1].Type)
+	assert.Equal(big.NewInt(100), alerts[1].Round)
+	assert.Equal(1, alerts[1].Attempt)
+}
+
+func TestRewardService_TryReward_MinedAfterTimeout(t *testing.T) {
+	assert := assert.New(t)
+	eth := &MockClient{}
+	tw := &stubTimeWatcher{
+		lastInitializedRound: big.NewInt(100),
+	}
+	rs := NewRewardService(eth, tw, RewardRetryConfig{MaxAttempts: 3, RetryInterval: time.Millisecond, PriceBump: 20})
+
+	addr := ethcommon.Address{}
+	eth.On("Account").Return(accounts.Account{Address: addr})
+	eth.On("GetTranscoder", addr).Return(&lpTypes.Transcoder{
+		LastRewardRound: big.NewInt(1),
+		Active:          true,
+	}, nil).Once()
+	eth.On("Reward").Return(&types.Transaction{}, nil).Once()
+	eth.On("CheckTx").Return(context.DeadlineExceeded).Once()
+	// The timed out transaction was mined before the retry
+	eth.On("GetTranscoder", addr).Return(&lpTypes.Transcoder{
+		LastRewardRound: big.NewInt(100),
+		Active:          true,
+	}, nil)
+
+	assert.Nil(rs.tryReward(context.Background()))
+	eth.AssertNotCalled(t, "RewardWithPriceBump", mock.Anything)
+}
+
+func TestRewardService_TryReward_SafeMode(t *testing.T) {
+	assert := assert.New(t)
+	eth := &MockClient{}
+	tw := &stubTimeWatcher{
+		lastInitializedRound: big.NewInt(100),
+	}
+	var alerts []*RewardAlert
+	rs := NewRewardService(eth, tw, RewardRetryConfig{MaxAttempts: 2, RetryInterval: time.Millisecond})
+	rs.SetAlertHandler(func(alert *RewardAlert) { alerts = append(alerts, alert) })
+	safeMode := true
+	rs.SetSafeMode(func() bool { return safeMode })
+
+	// Reward is not called in safe mode
+	assert.Equal(ErrSafeMode, rs.tryReward(context.Background()))
+	eth.AssertNotCalled(t, "Reward")
+	assert.Len(alerts, 1)
+	assert.Equal(RewardMissed, alerts[0].Type)
+	assert.Equal(ErrSafeMode.Error(), alerts[0].Error)
+
+	// Reward is called once the node leaves safe mode
+	addr := ethcommon.Address{}
+	eth.On("Account").Return(accounts.Account{Address: addr})
+	eth.On("GetTranscoder", addr).Return(&lpTypes.Transcoder{
+		LastRewardRound: big.NewInt(1),
+		Active:          true,
+	}, nil)
+	eth.On("Reward").Return(&types.Transaction{}, nil).Once()
+	eth.On("CheckTx").Return(nil).Once()
+	safeMode = false
+
+	assert.Nil(rs.tryReward(context.Background()))
+	eth.AssertNumberOfCalls(t, "Reward", 1)
+}
+
+func TestCumulativePriceBump(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(uint64(0), cumulativePriceBump(20, 0))
+	assert.Equal(uint64(20), cumulativePriceBump(20, 1))
+	assert.Equal(uint64(44), cumulativePriceBump(20, 2))
+	assert.Equal(uint64(0), cumulativePriceBump(0, 3))
+}
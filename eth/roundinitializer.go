@@ -2,18 +2,24 @@ package eth
 
 import (
 	"math/big"
+	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/golang/glog"
+	lpTypes "github.com/livepeer/go-livepeer/eth/types"
 )
 
 // Number of L1 blocks in an epoch which is the time period during which the caller should
 // initialize the round if it is selected and if the round is not initialized
 var epochL1Blocks = big.NewInt(5)
 
+// Source of the random delay before initializing the round
+var roundInitializerRand = rand.Float64
+
 type timeWatcher interface {
 	LastSeenL1Block() *big.Int
 	LastInitializedRound() *big.Int
@@ -27,25 +33,36 @@ type timeWatcher interface {
 // RoundInitializer is a service that automatically initializes the current round. Each round is split into epochs with a length of
 // epochL1Blocks. During each epoch a member of the upcoming active set is selected to initialize the round
 // This selection process is purely a client side implementation that attempts to minimize on-chain transaction collisions, but
-// collisions are still possible if initialization transactions are submitted by parties that are not using this selection process
+// collisions are still possible if initialization transactions are submitted by parties that are not using this selection process.
+// To further reduce collisions, the selected caller waits for a random delay that is shorter for callers with more stake, and only
+// initializes the round if no other party initialized it in the meantime
 type RoundInitializer struct {
-	client LivepeerEthClient
-	tw     timeWatcher
-	quit   chan struct{}
+	client   LivepeerEthClient
+	tw       timeWatcher
+	quit     chan struct{}
+	maxDelay time.Duration
+	safeMode func() bool
 
 	nextRoundStartL1Block *big.Int
 	mu                    sync.Mutex
 }
 
-// NewRoundInitializer creates a RoundInitializer instance
-func NewRoundInitializer(client LivepeerEthClient, tw timeWatcher) *RoundInitializer {
+// NewRoundInitializer creates a RoundInitializer instance that waits up to maxDelay before initializing the round
+func NewRoundInitializer(client LivepeerEthClient, tw timeWatcher, maxDelay time.Duration) *RoundInitializer {
 	return &RoundInitializer{
-		client: client,
-		tw:     tw,
-		quit:   make(chan struct{}),
+		client:   client,
+		tw:       tw,
+		quit:     make(chan struct{}),
+		maxDelay: maxDelay,
 	}
 }
 
+// SetSafeMode sets the function that reports whether round initialization is paused because of a low ETH balance.
+// It should be called before Start.
+func (r *RoundInitializer) SetSafeMode(safeMode func() bool) {
+	r.safeMode = safeMode
+}
+
 // Start kicks off a loop that checks if the round should be initialized
 func (r *RoundInitializer) Start() error {
 	l1BlockSink := make(chan *big.Int, 10)
@@ -109,7 +126,7 @@ func (r *RoundInitializer) tryInitialize() error {
 
 	epochSeed := r.currentEpochSeed(currentL1Blk, r.nextRoundStartL1Block, lastInitializedL1BlkHash)
 
-	ok, err := r.shouldInitialize(epochSeed)
+	ok, stakeShare, err := r.shouldInitialize(epochSeed)
 	if err != nil {
 		return err
 	}
@@ -121,6 +138,31 @@ func (r *RoundInitializer) tryInitialize() error {
 
 	currentRound := new(big.Int).Add(r.tw.LastInitializedRound(), big.NewInt(1))
 
+	if delay := r.delay(stakeShare); delay > 0 {
+		glog.V(6).Infof("Waiting %v before initializing round %d", delay, currentRound)
+		select {
+		case <-time.After(delay):
+		case <-r.quit:
+			return nil
+		}
+	}
+
+	// Noop if another party already initialized the round
+	initialized, err := r.client.CurrentRoundInitialized()
+	if err != nil {
+		return err
+	}
+	if initialized {
+		glog.Infof("Round %d already initialized by another party", currentRound)
+		return nil
+	}
+
+	// Another party of the active set initializes the round once the delays elapse
+	if r.safeMode != nil && r.safeMode() {
+		glog.Warningf("Not initializing round %d in safe mode", currentRound)
+		return nil
+	}
+
 	glog.Infof("New round - preparing to initialize round to join active set, current round is %d", currentRound)
 
 	tx, err := r.client.InitializeRound()
@@ -137,17 +179,18 @@ func (r *RoundInitializer) tryInitialize() error {
 	return nil
 }
 
-func (r *RoundInitializer) shouldInitialize(epochSeed *big.Int) (bool, error) {
+// shouldInitialize returns whether the caller is selected to initialize the round, and its share of the stake of the upcoming active set
+func (r *RoundInitializer) shouldInitialize(epochSeed *big.Int) (bool, float64, error) {
 	transcoders, err := r.client.TranscoderPool()
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 
 	numActive := big.NewInt(int64(len(transcoders)))
 
 	// Should not initialize if the upcoming active set is empty
 	if numActive.Cmp(big.NewInt(0)) == 0 {
-		return false, nil
+		return false, 0, nil
 	}
 
 	// Find the caller's rank in the upcoming active set
@@ -163,18 +206,38 @@ func (r *RoundInitializer) shouldInitialize(epochSeed *big.Int) (bool, error) {
 
 	// Should not initialize if the caller is not in the upcoming active set
 	if rank == -1 {
-		return false, nil
+		return false, 0, nil
 	}
 
 	// Use the seed to select a position within the active set
 	selection := new(big.Int).Mod(epochSeed, numActive)
 	// Should not initialize if the selection does not match the caller's rank in the active set
 	if selection.Int64() != int64(rank) {
-		return false, nil
+		return false, 0, nil
 	}
 
 	// If the selection matches the caller's rank the caller should initialize the round
-	return true, nil
+	return true, activeStakeShare(transcoders, transcoders[rank]), nil
+}
+
+// delay returns a random delay up to maxDelay that is scaled down by the caller's share of the stake of the active set,
+// so that if several parties are selected, e.g. because they saw different L1 blocks, the one with the most stake likely goes first
+func (r *RoundInitializer) delay(stakeShare float64) time.Duration {
+	return time.Duration(float64(r.maxDelay) * (1 - stakeShare) * roundInitializerRand())
+}
+
+func activeStakeShare(transcoders []*lpTypes.Transcoder, t *lpTypes.Transcoder) float64 {
+	total := big.NewInt(0)
+	for _, tr := range transcoders {
+		if tr.DelegatedStake != nil {
+			total.Add(total, tr.DelegatedStake)
+		}
+	}
+	if total.Sign() == 0 || t.DelegatedStake == nil {
+		return 0
+	}
+	share, _ := new(big.Rat).SetFrac(t.DelegatedStake, total).Float64()
+	return share
 }
 
 // Returns the seed used to select a round initializer in the current epoch for the current round
@@ -3,6 +3,7 @@ package eth
 import (
 	"errors"
 	"math/big"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -17,7 +18,7 @@ import (
 )
 
 func TestRoundInitializer_CurrentEpochSeed(t *testing.T) {
-	initializer := NewRoundInitializer(nil, nil)
+	initializer := NewRoundInitializer(nil, nil, 0)
 
 	assert := assert.New(t)
 
@@ -46,7 +47,7 @@ func TestRoundInitializer_CurrentEpochSeed(t *testing.T) {
 func TestRoundInitializer_ShouldInitialize(t *testing.T) {
 	client := &MockClient{}
 	tw := &stubTimeWatcher{}
-	initializer := NewRoundInitializer(client, tw)
+	initializer := NewRoundInitializer(client, tw, 0)
 
 	assert := assert.New(t)
 
@@ -54,13 +55,13 @@ func TestRoundInitializer_ShouldInitialize(t *testing.T) {
 	expErr := errors.New("TranscoderPool error")
 	client.On("TranscoderPool").Return(nil, expErr).Once()
 
-	ok, err := initializer.shouldInitialize(nil)
+	ok, _, err := initializer.shouldInitialize(nil)
 	assert.EqualError(err, expErr.Error())
 	assert.False(ok)
 
 	// Test active set is empty because no registered transcoders
 	client.On("TranscoderPool").Return([]*lpTypes.Transcoder{}, nil).Once()
-	ok, err = initializer.shouldInitialize(nil)
+	ok, _, err = initializer.shouldInitialize(nil)
 	assert.Nil(err)
 	assert.False(ok)
 
@@ -74,7 +75,7 @@ func TestRoundInitializer_ShouldInitialize(t *testing.T) {
 	}
 	client.On("TranscoderPool").Return(registered, nil).Once()
 
-	ok, err = initializer.shouldInitialize(nil)
+	ok, _, err = initializer.shouldInitialize(nil)
 	assert.Nil(err)
 	assert.False(ok)
 
@@ -83,13 +84,13 @@ func TestRoundInitializer_ShouldInitialize(t *testing.T) {
 	client.On("TranscoderPool").Return(registered, nil)
 
 	seed := big.NewInt(3)
-	ok, err = initializer.shouldInitialize(seed)
+	ok, _, err = initializer.shouldInitialize(seed)
 	assert.Nil(err)
 	assert.False(ok)
 
 	// Test caller selected
 	seed = big.NewInt(5)
-	ok, err = initializer.shouldInitialize(seed)
+	ok, _, err = initializer.shouldInitialize(seed)
 	assert.Nil(err)
 	assert.True(ok)
 }
@@ -101,7 +102,7 @@ func TestRoundInitializer_TryInitialize(t *testing.T) {
 		lastInitializedRound:     big.NewInt(100),
 		lastInitializedBlockHash: [32]byte{123},
 	}
-	initializer := NewRoundInitializer(client, tw)
+	initializer := NewRoundInitializer(client, tw, 0)
 	initializer.nextRoundStartL1Block = big.NewInt(5)
 	assert := assert.New(t)
 
@@ -124,9 +125,24 @@ func TestRoundInitializer_TryInitialize(t *testing.T) {
 	err = initializer.tryInitialize()
 	assert.Nil(err)
 
-	// Test error when submitting initialization tx
+	// Test error checking whether the round is initialized
 	registered = []*lpTypes.Transcoder{{Address: caller}}
 	client.On("TranscoderPool").Return(registered, nil)
+	expErr = errors.New("CurrentRoundInitialized error")
+	client.On("CurrentRoundInitialized").Return(false, expErr).Once()
+
+	err = initializer.tryInitialize()
+	assert.EqualError(err, expErr.Error())
+
+	// Test round already initialized by another party
+	client.On("CurrentRoundInitialized").Return(true, nil).Once()
+
+	err = initializer.tryInitialize()
+	assert.Nil(err)
+	client.AssertNotCalled(t, "InitializeRound")
+
+	// Test error when submitting initialization tx
+	client.On("CurrentRoundInitialized").Return(false, nil)
 	expErr = errors.New("InitializeRound error")
 	client.On("InitializeRound").Return(nil, expErr).Once()
 
@@ -149,6 +165,53 @@ func TestRoundInitializer_TryInitialize(t *testing.T) {
 	assert.Nil(err)
 }
 
+func TestRoundInitializer_Delay(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { roundInitializerRand = rand.Float64 }()
+	roundInitializerRand = func() float64 { return 0.5 }
+
+	caller := ethcommon.BytesToAddress([]byte("foo"))
+	transcoders := []*lpTypes.Transcoder{
+		{Address: ethcommon.BytesToAddress([]byte("jar")), DelegatedStake: big.NewInt(750)},
+		{Address: caller, DelegatedStake: big.NewInt(250)},
+	}
+	assert.Equal(0.25, activeStakeShare(transcoders, transcoders[1]))
+	assert.Equal(0.75, activeStakeShare(transcoders, transcoders[0]))
+	assert.Equal(0.0, activeStakeShare([]*lpTypes.Transcoder{{Address: caller}}, &lpTypes.Transcoder{Address: caller}))
+
+	// The delay is shorter for callers with more stake
+	initializer := NewRoundInitializer(nil, nil, 40*time.Second)
+	assert.Equal(15*time.Second, initializer.delay(0.25))
+	assert.Equal(5*time.Second, initializer.delay(0.75))
+	assert.Equal(time.Duration(0), initializer.delay(1))
+	assert.Equal(time.Duration(0), NewRoundInitializer(nil, nil, 0).delay(0.25))
+
+	// The selected caller waits before initializing the round
+	client := &MockClient{}
+	tw := &stubTimeWatcher{
+		lastBlock:                big.NewInt(5),
+		lastInitializedRound:     big.NewInt(100),
+		lastInitializedBlockHash: [32]byte{123},
+	}
+	client.On("Account").Return(accounts.Account{Address: caller})
+	client.On("TranscoderPool").Return([]*lpTypes.Transcoder{{Address: caller, DelegatedStake: big.NewInt(1)}, {Address: ethcommon.BytesToAddress([]byte("jar")), DelegatedStake: big.NewInt(1)}}, nil)
+	client.On("CurrentRoundInitialized").Return(true, nil)
+	initializer = NewRoundInitializer(client, tw, 400*time.Millisecond)
+	initializer.nextRoundStartL1Block = big.NewInt(5)
+	// Find an epoch in which the caller is selected
+	for i := int64(0); ; i++ {
+		tw.lastBlock = big.NewInt(5 + i*epochL1Blocks.Int64())
+		seed := initializer.currentEpochSeed(tw.lastBlock, initializer.nextRoundStartL1Block, tw.lastInitializedBlockHash)
+		if ok, _, _ := initializer.shouldInitialize(seed); ok {
+			break
+		}
+	}
+	start := time.Now()
+	assert.Nil(initializer.tryInitialize())
+	assert.GreaterOrEqual(time.Since(start), 100*time.Millisecond)
+	client.AssertNotCalled(t, "InitializeRound")
+}
+
 func TestRoundInitializer_Start_Stop(t *testing.T) {
 	assert := assert.New(t)
 	tw := &stubTimeWatcher{}
@@ -262,6 +325,7 @@ func TestRoundInitializer_BlockSubscription(t *testing.T) {
 	registered := []*lpTypes.Transcoder{{Address: caller}}
 	client.On("Account").Return(accounts.Account{Address: caller})
 	client.On("TranscoderPool").Return(registered, nil)
+	client.On("CurrentRoundInitialized").Return(false, nil)
 	client.On("InitializeRound").Return(nil, errors.New("some error")).Once()
 	errLinesBefore := glog.Stats.Error.Lines()
 	// tryInitialize error
@@ -0,0 +1,177 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/livepeer/go-livepeer/monitor"
+)
+
+// Error codes recorded for the RPC requests that failed before a JSON-RPC response was received
+const (
+	rpcErrTimeout = "timeout"
+	rpcErrNetwork = "network"
+)
+
+// DialRPC connects to the Ethereum JSON-RPC endpoint rpcURL. The requests to HTTP endpoints are instrumented, so
+// that the method, latency and error code of every call is recorded by the monitoring subsystem with the host of
+// the endpoint as the provider.
+func DialRPC(ctx context.Context, rpcURL string) (*rpc.Client, error) {
+	u, err := url.Parse(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return rpc.DialContext(ctx, rpcURL)
+	}
+
+	client := &http.Client{
+		Transport: &rpcMetricsTransport{
+			provider: u.Host,
+			base:     http.DefaultTransport,
+			record:   recordRPCCall,
+		},
+	}
+	return rpc.DialHTTPWithClient(rpcURL, client)
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+type rpcResponse struct {
+	ID    json.RawMessage `json:"id"`
+	Error *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+// rpcMetricsTransport is a http.RoundTripper that records the JSON-RPC calls sent through it
+type rpcMetricsTransport struct {
+	provider string
+	base     http.RoundTripper
+	// record is called for every JSON-RPC call with an empty code if the call succeeded
+	record func(provider, method, code string, latency time.Duration)
+}
+
+func (t *rpcMetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqs []rpcRequest
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		reqs = decodeRPCRequests(body)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.recordAll(reqs, transportErrorCode(err), time.Since(start))
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.recordAll(reqs, fmt.Sprintf("http_%d", resp.StatusCode), time.Since(start))
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	latency := time.Since(start)
+	if err != nil {
+		t.recordAll(reqs, transportErrorCode(err), latency)
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	codes := decodeRPCErrorCodes(body)
+	for _, r := range reqs {
+		t.record(t.provider, r.Method, codes[string(r.ID)], latency)
+	}
+
+	return resp, nil
+}
+
+func (t *rpcMetricsTransport) recordAll(reqs []rpcRequest, code string, latency time.Duration) {
+	for _, r := range reqs {
+		t.record(t.provider, r.Method, code, latency)
+	}
+}
+
+// decodeRPCRequests returns the calls of a single or batch JSON-RPC request
+func decodeRPCRequests(body []byte) []rpcRequest {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var reqs []rpcRequest
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			return nil
+		}
+		return reqs
+	}
+
+	var req rpcRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil
+	}
+	return []rpcRequest{req}
+}
+
+// decodeRPCErrorCodes returns the JSON-RPC error codes of a single or batch response by request ID
+func decodeRPCErrorCodes(body []byte) map[string]string {
+	var resps []rpcResponse
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &resps); err != nil {
+			return nil
+		}
+	} else {
+		var resp rpcResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil
+		}
+		resps = []rpcResponse{resp}
+	}
+
+	codes := make(map[string]string)
+	for _, r := range resps {
+		if r.Error != nil {
+			codes[string(r.ID)] = strconv.Itoa(r.Error.Code)
+		}
+	}
+	return codes
+}
+
+func transportErrorCode(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return rpcErrTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return rpcErrTimeout
+	}
+	return rpcErrNetwork
+}
+
+func recordRPCCall(provider, method, code string, latency time.Duration) {
+	if !monitor.Enabled {
+		return
+	}
+	monitor.EthRPCCall(provider, method, latency)
+	if code != "" {
+		monitor.EthRPCError(provider, method, code)
+	}
+}
@@ -0,0 +1,142 @@
+package eth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRPCCall struct {
+	provider string
+	method   string
+	code     string
+}
+
+type rpcCallRecorder struct {
+	calls []recordedRPCCall
+	mu    sync.Mutex
+}
+
+func (r *rpcCallRecorder) record(provider, method, code string, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, recordedRPCCall{provider, method, code})
+}
+
+func newInstrumentedRPCClient(t *testing.T, handler http.HandlerFunc) (*rpc.Client, *rpcCallRecorder) {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	recorder := &rpcCallRecorder{}
+	client, err := rpc.DialHTTPWithClient(ts.URL, &http.Client{
+		Transport: &rpcMetricsTransport{
+			provider: "provider",
+			base:     http.DefaultTransport,
+			record:   recorder.record,
+		},
+	})
+	require.Nil(t, err)
+	t.Cleanup(client.Close)
+
+	return client, recorder
+}
+
+func TestRPCMetricsTransport_Call(t *testing.T) {
+	assert := assert.New(t)
+
+	client, recorder := newInstrumentedRPCClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	})
+
+	var res string
+	err := client.CallContext(context.Background(), &res, "eth_chainId")
+	assert.Nil(err)
+	assert.Equal("0x1", res)
+	assert.Equal([]recordedRPCCall{{"provider", "eth_chainId", ""}}, recorder.calls)
+}
+
+func TestRPCMetricsTransport_RPCError(t *testing.T) {
+	assert := assert.New(t)
+
+	client, recorder := newInstrumentedRPCClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"query returned more than 10000 results"}}`))
+	})
+
+	var res interface{}
+	err := client.CallContext(context.Background(), &res, "eth_getLogs")
+	assert.EqualError(err, "query returned more than 10000 results")
+	assert.Equal([]recordedRPCCall{{"provider", "eth_getLogs", "-32005"}}, recorder.calls)
+}
+
+func TestRPCMetricsTransport_HTTPError(t *testing.T) {
+	assert := assert.New(t)
+
+	client, recorder := newInstrumentedRPCClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	})
+
+	var res interface{}
+	err := client.CallContext(context.Background(), &res, "eth_blockNumber")
+	assert.NotNil(err)
+	assert.Equal([]recordedRPCCall{{"provider", "eth_blockNumber", "http_429"}}, recorder.calls)
+}
+
+func TestRPCMetricsTransport_Timeout(t *testing.T) {
+	assert := assert.New(t)
+
+	client, recorder := newInstrumentedRPCClient(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var res interface{}
+	err := client.CallContext(ctx, &res, "eth_blockNumber")
+	assert.NotNil(err)
+	assert.Equal([]recordedRPCCall{{"provider", "eth_blockNumber", rpcErrTimeout}}, recorder.calls)
+}
+
+func TestRPCMetricsTransport_Batch(t *testing.T) {
+	assert := assert.New(t)
+
+	client, recorder := newInstrumentedRPCClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"0x1"},{"jsonrpc":"2.0","id":2,"error":{"code":-32000,"message":"header not found"}}]`))
+	})
+
+	var chainID, block string
+	err := client.BatchCallContext(context.Background(), []rpc.BatchElem{
+		{Method: "eth_chainId", Result: &chainID},
+		{Method: "eth_getBlockByNumber", Args: []interface{}{"0x1", false}, Result: &block},
+	})
+	assert.Nil(err)
+	assert.Equal([]recordedRPCCall{
+		{"provider", "eth_chainId", ""},
+		{"provider", "eth_getBlockByNumber", "-32000"},
+	}, recorder.calls)
+}
+
+func TestDecodeRPCRequests(t *testing.T) {
+	assert := assert.New(t)
+
+	reqs := decodeRPCRequests([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`))
+	assert.Len(reqs, 1)
+	assert.Equal("eth_call", reqs[0].Method)
+	assert.Equal("1", string(reqs[0].ID))
+
+	reqs = decodeRPCRequests([]byte(` [{"id":1,"method":"eth_call"},{"id":2,"method":"eth_getLogs"}]`))
+	assert.Len(reqs, 2)
+	assert.Equal("eth_getLogs", reqs[1].Method)
+
+	assert.Nil(decodeRPCRequests([]byte(`not json`)))
+}
@@ -100,6 +100,12 @@ func (m *MockClient) Reward() (*types.Transaction, error) {
 	return mockTransaction(args, 0), args.Error(1)
 }
 
+// RewardWithPriceBump calls reward with gas fees that are priceBump % above the current estimate
+func (m *MockClient) RewardWithPriceBump(priceBump uint64) (*types.Transaction, error) {
+	args := m.Called(priceBump)
+	return mockTransaction(args, 0), args.Error(1)
+}
+
 func (m *MockClient) GetTranscoderEarningsPoolForRound(address common.Address, round *big.Int) (*lpTypes.TokenPools, error) {
 	args := m.Called()
 	return args.Get(0).(*lpTypes.TokenPools), args.Error(1)
@@ -154,6 +160,11 @@ func (m *MockClient) FundDeposit(amount *big.Int) (*types.Transaction, error) {
 	return mockTransaction(args, 0), args.Error(1)
 }
 
+func (m *MockClient) FundReserve(amount *big.Int) (*types.Transaction, error) {
+	args := m.Called(amount)
+	return mockTransaction(args, 0), args.Error(1)
+}
+
 func (m *MockClient) Unlock() (*types.Transaction, error) {
 	args := m.Called()
 	return mockTransaction(args, 0), args.Error(1)
@@ -164,6 +175,11 @@ func (m *MockClient) CancelUnlock() (*types.Transaction, error) {
 	return mockTransaction(args, 0), args.Error(1)
 }
 
+func (m *MockClient) ClaimEarnings(endRound *big.Int) (*types.Transaction, error) {
+	args := m.Called(endRound)
+	return mockTransaction(args, 0), args.Error(1)
+}
+
 func (m *MockClient) Withdraw() (*types.Transaction, error) {
 	args := m.Called()
 	return mockTransaction(args, 0), args.Error(1)
@@ -316,6 +332,9 @@ func (e *StubClient) Transcoder(blockRewardCut, feeShare *big.Int) (*types.Trans
 	return nil, nil
 }
 func (e *StubClient) Reward() (*types.Transaction, error) { return nil, nil }
+func (e *StubClient) RewardWithPriceBump(priceBump uint64) (*types.Transaction, error) {
+	return nil, nil
+}
 func (e *StubClient) Bond(amount *big.Int, toAddr common.Address) (*types.Transaction, error) {
 	return nil, nil
 }
@@ -390,6 +409,9 @@ func (e *StubClient) Withdraw() (*types.Transaction, error) {
 func (e *StubClient) RedeemWinningTicket(ticket *pm.Ticket, sig []byte, recipientRand *big.Int) (*types.Transaction, error) {
 	return nil, nil
 }
+func (e *StubClient) BatchRedeemWinningTickets(tickets []*pm.Ticket, sigs [][]byte, recipientRands []*big.Int) (*types.Transaction, error) {
+	return nil, nil
+}
 func (e *StubClient) IsUsedTicket(ticket *pm.Ticket) (bool, error) {
 	return true, nil
 }
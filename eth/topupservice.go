@@ -0,0 +1,163 @@
+package eth
+
+import (
+	"math/big"
+	"sync"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/pm"
+)
+
+// Number of L1 blocks to wait after a top-up before checking the balances again, which gives the
+// sender watcher time to process the funding events
+const topUpCooldownL1Blocks = 5
+
+type senderInfoGetter interface {
+	GetSenderInfo(addr ethcommon.Address) (*pm.SenderInfo, error)
+}
+
+// TopUpConfig defines the thresholds below which the TopUpService funds the deposit and reserve
+// of the node's account. A nil or zero amount disables the top-up of the corresponding balance.
+type TopUpConfig struct {
+	// DepositAmount is added to the deposit when it falls below MinDeposit
+	MinDeposit    *big.Int
+	DepositAmount *big.Int
+	// ReserveAmount is added to the reserve when it falls below MinReserve
+	MinReserve    *big.Int
+	ReserveAmount *big.Int
+}
+
+// TopUpService is a service that funds the deposit and reserve of the node's account when they fall below
+// the configured thresholds, so that a broadcaster doesn't run out of funds for its tickets mid-stream
+type TopUpService struct {
+	client  LivepeerEthClient
+	senders senderInfoGetter
+	tw      timeWatcher
+	cfg     TopUpConfig
+	quit    chan struct{}
+
+	// pendingTx is the funding tx waiting to be confirmed, during which no other top-up is made
+	pendingTx *types.Transaction
+	// nextL1Block is the first L1 block at which the balances are checked after the last top-up was confirmed
+	nextL1Block *big.Int
+	mu          sync.Mutex
+}
+
+// NewTopUpService creates a TopUpService instance
+func NewTopUpService(client LivepeerEthClient, senders senderInfoGetter, tw timeWatcher, cfg TopUpConfig) *TopUpService {
+	return &TopUpService{
+		client:  client,
+		senders: senders,
+		tw:      tw,
+		cfg:     cfg,
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start kicks off a loop that checks the deposit and reserve on every L1 block
+func (s *TopUpService) Start() error {
+	l1BlockSink := make(chan *big.Int, 10)
+	l1BlockSub := s.tw.SubscribeL1Blocks(l1BlockSink)
+	defer l1BlockSub.Unsubscribe()
+
+	for {
+		select {
+		case <-s.quit:
+			glog.Infof("Stopping top-up service")
+			return nil
+		case err := <-l1BlockSub.Err():
+			if err != nil {
+				glog.Errorf("L1 Block subscription error err=%q", err)
+			}
+		case l1Block := <-l1BlockSink:
+			go func() {
+				if err := s.tryTopUp(l1Block); err != nil {
+					glog.Errorf("Error topping up deposit and reserve err=%q", err)
+				}
+			}()
+		}
+	}
+}
+
+// Stop signals the loop to exit gracefully
+func (s *TopUpService) Stop() {
+	close(s.quit)
+}
+
+func (s *TopUpService) tryTopUp(l1Block *big.Int) error {
+	tx, err := s.submitTopUp(l1Block)
+	if tx == nil || err != nil {
+		return err
+	}
+
+	err = s.client.CheckTx(tx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pendingTx = nil
+	// Wait before the next top-up whether or not the tx succeeds, so that a failing tx is not resubmitted on every block.
+	// The cooldown starts once the tx is confirmed, which can take longer than the cooldown itself.
+	confirmedL1Block := s.tw.LastSeenL1Block()
+	if confirmedL1Block == nil || confirmedL1Block.Cmp(l1Block) < 0 {
+		confirmedL1Block = l1Block
+	}
+	s.nextL1Block = new(big.Int).Add(confirmedL1Block, big.NewInt(topUpCooldownL1Blocks))
+
+	return err
+}
+
+// submitTopUp submits a funding tx if the deposit or reserve are below their thresholds, and records it as pending.
+// It returns a nil tx if no top-up is needed, or if a top-up is pending or cooling down.
+func (s *TopUpService) submitTopUp(l1Block *big.Int) (*types.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pendingTx != nil || s.nextL1Block != nil && l1Block.Cmp(s.nextL1Block) < 0 {
+		return nil, nil
+	}
+
+	info, err := s.senders.GetSenderInfo(s.client.Account().Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Funding the deposit or reserve cancels an unlock, so don't interfere with a withdrawal in progress
+	if info.WithdrawRound != nil && info.WithdrawRound.Sign() > 0 {
+		return nil, nil
+	}
+
+	depositAmount := topUpAmount(info.Deposit, s.cfg.MinDeposit, s.cfg.DepositAmount)
+	reserveAmount := topUpAmount(info.Reserve.FundsRemaining, s.cfg.MinReserve, s.cfg.ReserveAmount)
+
+	var tx *types.Transaction
+	switch {
+	case depositAmount != nil && reserveAmount != nil:
+		glog.Infof("Topping up deposit=%v reserve=%v amounts deposit=%v reserve=%v", FormatUnits(info.Deposit, "ETH"), FormatUnits(info.Reserve.FundsRemaining, "ETH"), FormatUnits(depositAmount, "ETH"), FormatUnits(reserveAmount, "ETH"))
+		tx, err = s.client.FundDepositAndReserve(depositAmount, reserveAmount)
+	case depositAmount != nil:
+		glog.Infof("Topping up deposit=%v amount=%v", FormatUnits(info.Deposit, "ETH"), FormatUnits(depositAmount, "ETH"))
+		tx, err = s.client.FundDeposit(depositAmount)
+	case reserveAmount != nil:
+		glog.Infof("Topping up reserve=%v amount=%v", FormatUnits(info.Reserve.FundsRemaining, "ETH"), FormatUnits(reserveAmount, "ETH"))
+		tx, err = s.client.FundReserve(reserveAmount)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.pendingTx = tx
+	return tx, nil
+}
+
+// topUpAmount returns amount if balance is below min, otherwise nil
+func topUpAmount(balance, min, amount *big.Int) *big.Int {
+	if min == nil || amount == nil || amount.Sign() <= 0 || balance.Cmp(min) >= 0 {
+		return nil
+	}
+	return amount
+}
@@ -0,0 +1,163 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type stubSenderInfoGetter struct {
+	info *pm.SenderInfo
+	err  error
+}
+
+func (s *stubSenderInfoGetter) GetSenderInfo(addr ethcommon.Address) (*pm.SenderInfo, error) {
+	return s.info, s.err
+}
+
+func TestTopUpService_TryTopUp(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &MockClient{}
+	client.On("Account").Return(accounts.Account{Address: ethcommon.BytesToAddress([]byte("foo"))})
+	senders := &stubSenderInfoGetter{
+		info: &pm.SenderInfo{
+			Deposit:       big.NewInt(100),
+			WithdrawRound: big.NewInt(0),
+			Reserve:       &pm.ReserveInfo{FundsRemaining: big.NewInt(100), ClaimedInCurrentRound: big.NewInt(0)},
+		},
+	}
+	cfg := TopUpConfig{
+		MinDeposit:    big.NewInt(50),
+		DepositAmount: big.NewInt(1000),
+		MinReserve:    big.NewInt(50),
+		ReserveAmount: big.NewInt(2000),
+	}
+	s := NewTopUpService(client, senders, &stubTimeWatcher{}, cfg)
+
+	// Error getting the sender info
+	senders.err = errors.New("GetSenderInfo error")
+	assert.EqualError(s.tryTopUp(big.NewInt(1)), "GetSenderInfo error")
+	senders.err = nil
+
+	// Balances above the thresholds
+	assert.Nil(s.tryTopUp(big.NewInt(1)))
+	client.AssertNotCalled(t, "FundDeposit", mock.Anything)
+	client.AssertNotCalled(t, "FundReserve", mock.Anything)
+
+	// Deposit below the threshold
+	tx := &types.Transaction{}
+	senders.info.Deposit = big.NewInt(10)
+	client.On("FundDeposit", big.NewInt(1000)).Return(tx, nil).Once()
+	client.On("CheckTx").Return(nil)
+	assert.Nil(s.tryTopUp(big.NewInt(1)))
+
+	// Balances are not checked again until the cooldown has passed
+	senders.info.Reserve.FundsRemaining = big.NewInt(10)
+	assert.Nil(s.tryTopUp(big.NewInt(1 + topUpCooldownL1Blocks - 1)))
+
+	// Deposit and reserve below the thresholds
+	client.On("FundDepositAndReserve", big.NewInt(1000), big.NewInt(2000)).Return(tx, nil).Once()
+	assert.Nil(s.tryTopUp(big.NewInt(1 + topUpCooldownL1Blocks)))
+
+	// Reserve below the threshold
+	senders.info.Deposit = big.NewInt(100)
+	client.On("FundReserve", big.NewInt(2000)).Return(tx, nil).Once()
+	assert.Nil(s.tryTopUp(big.NewInt(1 + 2*topUpCooldownL1Blocks)))
+
+	// Error submitting the funding tx doesn't start the cooldown
+	expErr := errors.New("FundReserve error")
+	client.On("FundReserve", big.NewInt(2000)).Return(nil, expErr).Once()
+	assert.EqualError(s.tryTopUp(big.NewInt(1+3*topUpCooldownL1Blocks)), expErr.Error())
+	client.On("FundReserve", big.NewInt(2000)).Return(tx, nil).Once()
+	assert.Nil(s.tryTopUp(big.NewInt(1 + 3*topUpCooldownL1Blocks)))
+
+	// No top-up while an unlock is in progress
+	senders.info.WithdrawRound = big.NewInt(5)
+	assert.Nil(s.tryTopUp(big.NewInt(1 + 4*topUpCooldownL1Blocks)))
+
+	client.AssertExpectations(t)
+}
+
+func TestTopUpService_TryTopUp_PendingTx(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &MockClient{}
+	client.On("Account").Return(accounts.Account{Address: ethcommon.BytesToAddress([]byte("foo"))})
+	senders := &stubSenderInfoGetter{
+		info: &pm.SenderInfo{
+			Deposit:       big.NewInt(10),
+			WithdrawRound: big.NewInt(0),
+			Reserve:       &pm.ReserveInfo{FundsRemaining: big.NewInt(100), ClaimedInCurrentRound: big.NewInt(0)},
+		},
+	}
+	tw := &stubTimeWatcher{}
+	s := NewTopUpService(client, senders, tw, TopUpConfig{MinDeposit: big.NewInt(50), DepositAmount: big.NewInt(1000)})
+
+	tx := &types.Transaction{}
+	confirm := make(chan struct{})
+	client.On("FundDeposit", big.NewInt(1000)).Return(tx, nil).Once()
+	client.On("CheckTx").Run(func(mock.Arguments) { <-confirm }).Return(nil).Once()
+	errCh := make(chan error)
+	go func() { errCh <- s.tryTopUp(big.NewInt(1)) }()
+	time.Sleep(20 * time.Millisecond)
+
+	// No other top-up while the tx is pending, even after the cooldown
+	s.mu.Lock()
+	assert.Equal(tx, s.pendingTx)
+	s.mu.Unlock()
+	assert.Nil(s.tryTopUp(big.NewInt(1 + 2*topUpCooldownL1Blocks)))
+
+	// The cooldown starts from the L1 block at which the tx was confirmed
+	tw.lastBlock = big.NewInt(1 + 2*topUpCooldownL1Blocks)
+	close(confirm)
+	assert.Nil(<-errCh)
+	assert.Nil(s.pendingTx)
+	assert.Nil(s.tryTopUp(big.NewInt(1 + 3*topUpCooldownL1Blocks - 1)))
+
+	client.On("FundDeposit", big.NewInt(1000)).Return(tx, nil).Once()
+	client.On("CheckTx").Return(nil).Once()
+	assert.Nil(s.tryTopUp(big.NewInt(1 + 3*topUpCooldownL1Blocks)))
+
+	client.AssertExpectations(t)
+}
+
+func TestTopUpAmount(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(big.NewInt(10), topUpAmount(big.NewInt(4), big.NewInt(5), big.NewInt(10)))
+	assert.Nil(topUpAmount(big.NewInt(5), big.NewInt(5), big.NewInt(10)))
+	// Disabled
+	assert.Nil(topUpAmount(big.NewInt(4), nil, big.NewInt(10)))
+	assert.Nil(topUpAmount(big.NewInt(4), big.NewInt(5), nil))
+	assert.Nil(topUpAmount(big.NewInt(4), big.NewInt(5), big.NewInt(0)))
+}
+
+func TestTopUpService_Start_Stop(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &MockClient{}
+	client.On("Account").Return(accounts.Account{})
+	senders := &stubSenderInfoGetter{err: errors.New("GetSenderInfo error")}
+	tw := &stubTimeWatcher{}
+	s := NewTopUpService(client, senders, tw, TopUpConfig{})
+
+	errCh := make(chan error)
+	go func() { errCh <- s.Start() }()
+	time.Sleep(20 * time.Millisecond)
+
+	tw.blockSink <- big.NewInt(1)
+	time.Sleep(20 * time.Millisecond)
+
+	s.Stop()
+	assert.Nil(<-errCh)
+	assert.True(tw.blockSub.(*stubSubscription).unsubscribed)
+}
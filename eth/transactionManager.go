@@ -33,6 +33,15 @@ type transactionSigner interface {
 	SignTx(tx *types.Transaction) (*types.Transaction, error)
 }
 
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// TransactionRecorder stores the transactions mined by the TransactionManager
+type TransactionRecorder interface {
+	InsertTransaction(tx *common.DBTransaction) error
+}
+
 type TransactionManager struct {
 	txTimeout       time.Duration
 	maxReplacements int
@@ -47,6 +56,8 @@ type TransactionManager struct {
 	gpm *GasPriceMonitor
 	sig transactionSigner
 
+	recorder TransactionRecorder
+
 	cond *sync.Cond
 
 	quit chan struct{}
@@ -97,6 +108,12 @@ func NewTransactionManager(eth transactionSenderReader, gpm *GasPriceMonitor, si
 	}
 }
 
+// SetRecorder sets the recorder that the gas costs of the mined transactions are stored with.
+// It should be called before Start.
+func (tm *TransactionManager) SetRecorder(recorder TransactionRecorder) {
+	tm.recorder = recorder
+}
+
 func (tm *TransactionManager) SendTransaction(ctx context.Context, tx *types.Transaction) error {
 	sendErr := tm.eth.SendTransaction(ctx, tx)
 
@@ -155,7 +172,7 @@ func (tm *TransactionManager) replace(tx *types.Transaction) (*types.Transaction
 		return nil, ErrReplacingMinedTx
 	}
 
-	newRawTx := newReplacementTx(tx)
+	newRawTx := newReplacementTx(tx, tm.gpm)
 
 	// Bump gas price exceeds max gas price, return early
 	max := tm.gpm.MaxGasPrice()
@@ -225,6 +242,10 @@ func (tm *TransactionManager) checkTxLoop() {
 			txReceipt = *(receipt)
 		}
 
+		if err == nil && receipt != nil && tm.recorder != nil {
+			tm.record(tx, receipt)
+		}
+
 		tm.feed.Send(&transactionReceipt{
 			originTxHash: originHash,
 			Receipt:      txReceipt,
@@ -234,6 +255,51 @@ func (tm *TransactionManager) checkTxLoop() {
 	}
 }
 
+// record stores the gas cost of a mined transaction
+func (tm *TransactionManager) record(tx *types.Transaction, receipt *types.Receipt) {
+	method := "unknown"
+	if txLog, err := newTxLog(tx); err == nil {
+		method = txLog.method
+	}
+	gasPrice := tm.effectiveGasPrice(tx, receipt)
+	var blockNumber int64
+	if receipt.BlockNumber != nil {
+		blockNumber = receipt.BlockNumber.Int64()
+	}
+
+	err := tm.recorder.InsertTransaction(&common.DBTransaction{
+		TxHash:      tx.Hash(),
+		Method:      method,
+		BlockNumber: blockNumber,
+		GasUsed:     receipt.GasUsed,
+		GasPrice:    gasPrice,
+		Cost:        new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed)),
+		Failed:      receipt.Status == types.ReceiptStatusFailed,
+		Time:        time.Now(),
+	})
+	if err != nil {
+		glog.Errorf("Error recording transaction txHash=%v err=%q", tx.Hash().Hex(), err)
+	}
+}
+
+// effectiveGasPrice returns the gas price paid by a mined transaction. Receipts don't include it, so the gas price of
+// a dynamic fee tx is computed from the base fee of its block, or estimated like for replacements if the block is unknown.
+func (tm *TransactionManager) effectiveGasPrice(tx *types.Transaction, receipt *types.Receipt) *big.Int {
+	if tx.Type() == types.LegacyTxType {
+		return tx.GasPrice()
+	}
+
+	if hr, ok := tm.eth.(headerReader); ok && receipt.BlockNumber != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tm.txTimeout)
+		defer cancel()
+		header, err := hr.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err == nil && header.BaseFee != nil {
+			return bigMin(tx.GasFeeCap(), new(big.Int).Add(header.BaseFee, tx.GasTipCap()))
+		}
+	}
+	return calcGasPrice(tx)
+}
+
 func applyPriceBump(val *big.Int, priceBump uint64) *big.Int {
 	a := big.NewInt(100 + int64(priceBump))
 	b := new(big.Int).Mul(a, val)
@@ -243,7 +309,7 @@ func applyPriceBump(val *big.Int, priceBump uint64) *big.Int {
 
 // Calculate the gas price as gas tip cap + base fee
 func calcGasPrice(tx *types.Transaction) *big.Int {
-	if tx.GasFeeCap() == nil {
+	if tx.Type() == types.LegacyTxType {
 		// legacy tx, not London ready
 		return tx.GasPrice()
 	} else {
@@ -253,30 +319,62 @@ func calcGasPrice(tx *types.Transaction) *big.Int {
 	}
 }
 
-func newReplacementTx(tx *types.Transaction) *types.Transaction {
+// newReplacementTx returns a copy of tx with the price bump required to replace it applied to its fees.
+// If the current fees of the gas price monitor are higher than the bumped fees, e.g. during a fee spike,
+// then the current fees are used instead so that the replacement tx does not get stuck as well.
+func newReplacementTx(tx *types.Transaction, gpm *GasPriceMonitor) *types.Transaction {
 	var baseTx types.TxData
-	if tx.GasFeeCap() == nil {
+	if tx.Type() == types.LegacyTxType {
 		// legacy tx, not London ready
+		gasPrice := applyPriceBump(tx.GasPrice(), priceBump)
+		if gpm != nil {
+			gasPrice = bigMax(gasPrice, gpm.GasPrice())
+		}
 		baseTx = &types.LegacyTx{
 			Nonce:    tx.Nonce(),
-			GasPrice: applyPriceBump(tx.GasPrice(), priceBump),
+			GasPrice: gasPrice,
 			Gas:      tx.Gas(),
 			To:       tx.To(),
 			Value:    tx.Value(),
 			Data:     tx.Data(),
 		}
 	} else {
+		// geth requires the price bump to be applied to both the gas tip cap and gas fee cap
+		gasTipCap := applyPriceBump(tx.GasTipCap(), priceBump)
+		gasFeeCap := applyPriceBump(tx.GasFeeCap(), priceBump)
+		if gpm != nil {
+			if baseFee, tip := gpm.BaseFee(), gpm.GasTipCap(); baseFee != nil && tip != nil {
+				gasTipCap = bigMax(gasTipCap, tip)
+				// Same as the gas fee cap of new txs, which allows the base fee to double before the tx becomes unexecutable
+				gasFeeCap = bigMax(gasFeeCap, new(big.Int).Add(gasTipCap, new(big.Int).Mul(baseFee, big.NewInt(2))))
+			}
+		}
 		baseTx = &types.DynamicFeeTx{
-			Nonce: tx.Nonce(),
-			// geth requires the price bump to be applied to both the gas tip cap and gas fee cap
-			GasFeeCap: applyPriceBump(tx.GasFeeCap(), priceBump),
-			GasTipCap: applyPriceBump(tx.GasTipCap(), priceBump),
-			Gas:       tx.Gas(),
-			Value:     tx.Value(),
-			Data:      tx.Data(),
-			To:        tx.To(),
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasFeeCap:  gasFeeCap,
+			GasTipCap:  gasTipCap,
+			Gas:        tx.Gas(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			To:         tx.To(),
+			AccessList: tx.AccessList(),
 		}
 	}
 
 	return types.NewTx(baseTx)
 }
+
+func bigMax(a, b *big.Int) *big.Int {
+	if b != nil && b.Cmp(a) > 0 {
+		return b
+	}
+	return a
+}
+
+func bigMin(a, b *big.Int) *big.Int {
+	if b.Cmp(a) < 0 {
+		return b
+	}
+	return a
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/golang/glog"
+	lpcommon "github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/pm"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,6 +46,35 @@ func (stm *stubTransactionSenderReader) CodeAt(ctx context.Context, account comm
 	return []byte{}, stm.err["CodeAt"]
 }
 
+type stubHeaderTransactionSenderReader struct {
+	stubTransactionSenderReader
+	header *types.Header
+	err    error
+}
+
+func (stm *stubHeaderTransactionSenderReader) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return stm.header, stm.err
+}
+
+type stubTransactionRecorder struct {
+	mu  sync.Mutex
+	txs []*lpcommon.DBTransaction
+	err error
+}
+
+func (r *stubTransactionRecorder) InsertTransaction(tx *lpcommon.DBTransaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txs = append(r.txs, tx)
+	return r.err
+}
+
+func (r *stubTransactionRecorder) Transactions() []*lpcommon.DBTransaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*lpcommon.DBTransaction(nil), r.txs...)
+}
+
 type stubTransactionSigner struct {
 	err error
 }
@@ -245,7 +275,7 @@ func TestTransactionManager_Replace(t *testing.T) {
 	tx, err = tm.replace(stubTx)
 	logsAfter = glog.Stats.Info.Lines()
 	assert.Nil(err)
-	expTx := newReplacementTx(stubTx)
+	expTx := newReplacementTx(stubTx, gpm)
 	assert.Equal(tx.Hash(), expTx.Hash())
 	assert.Equal(logsAfter-logsBefore, int64(1))
 }
@@ -359,6 +389,87 @@ func TestTransactionManager_CheckTxLoop(t *testing.T) {
 	sub.Unsubscribe()
 }
 
+func TestTransactionManager_Record(t *testing.T) {
+	assert := assert.New(t)
+
+	eth := &stubTransactionSenderReader{
+		err: make(map[string]error),
+	}
+	recorder := &stubTransactionRecorder{}
+	tm := NewTransactionManager(eth, &GasPriceMonitor{gasPrice: big.NewInt(1)}, &stubTransactionSigner{}, 2*time.Second, 0)
+	tm.SetRecorder(recorder)
+
+	go tm.Start()
+	defer tm.Stop()
+
+	receipt := types.NewReceipt(pm.RandHash().Bytes(), false, 100000)
+	receipt.GasUsed = 50000
+	receipt.BlockNumber = big.NewInt(7)
+	eth.receipt = receipt
+
+	sink := make(chan *transactionReceipt, 10)
+	sub := tm.Subscribe(sink)
+	defer sub.Unsubscribe()
+
+	// Mined tx is recorded with its gas cost
+	tx := types.NewTransaction(1, pm.RandAddress(), big.NewInt(100), 100000, big.NewInt(10), pm.RandBytes(68))
+	assert.Nil(tm.SendTransaction(context.Background(), tx))
+	event := <-sink
+	assert.Nil(event.err)
+	txs := recorder.Transactions()
+	assert.Len(txs, 1)
+	assert.Equal(tx.Hash(), txs[0].TxHash)
+	assert.Equal("unknown", txs[0].Method)
+	assert.Equal(int64(7), txs[0].BlockNumber)
+	assert.Equal(uint64(50000), txs[0].GasUsed)
+	assert.Equal(big.NewInt(10), txs[0].GasPrice)
+	assert.Equal(big.NewInt(500000), txs[0].Cost)
+	assert.False(txs[0].Failed)
+
+	// Reverted tx is recorded as failed
+	receipt.Status = types.ReceiptStatusFailed
+	assert.Nil(tm.SendTransaction(context.Background(), tx))
+	<-sink
+	txs = recorder.Transactions()
+	assert.Len(txs, 2)
+	assert.True(txs[1].Failed)
+
+	// Tx that is not mined is not recorded
+	eth.receipt = nil
+	eth.err["TransactionReceipt"] = context.DeadlineExceeded
+	assert.Nil(tm.SendTransaction(context.Background(), tx))
+	event = <-sink
+	assert.NotNil(event.err)
+	assert.Len(recorder.Transactions(), 2)
+}
+
+func TestTransactionManager_EffectiveGasPrice(t *testing.T) {
+	assert := assert.New(t)
+
+	receipt := &types.Receipt{BlockNumber: big.NewInt(1)}
+	tm := &TransactionManager{eth: &stubTransactionSenderReader{}, txTimeout: time.Second}
+
+	// legacy tx
+	assert.Equal(big.NewInt(300), tm.effectiveGasPrice(newStubLegacyTx(big.NewInt(300)), receipt))
+
+	// dynamic fee tx without block header is estimated
+	tx := newStubDynamicFeeTx(big.NewInt(2100), big.NewInt(100))
+	assert.Equal(big.NewInt(1100), tm.effectiveGasPrice(tx, receipt))
+
+	// dynamic fee tx pays the base fee of its block and the tip
+	eth := &stubHeaderTransactionSenderReader{header: &types.Header{BaseFee: big.NewInt(500)}}
+	tm.eth = eth
+	assert.Equal(big.NewInt(600), tm.effectiveGasPrice(tx, receipt))
+
+	// capped by the fee cap
+	eth.header.BaseFee = big.NewInt(2050)
+	assert.Equal(big.NewInt(2100), tm.effectiveGasPrice(tx, receipt))
+
+	// header error
+	eth.err = errors.New("HeaderByNumber error")
+	assert.Equal(big.NewInt(1100), tm.effectiveGasPrice(tx, receipt))
+}
+
 func TestApplyPriceBump(t *testing.T) {
 	assert := assert.New(t)
 
@@ -418,7 +529,7 @@ func TestNewReplacementTx_LegacyTx(t *testing.T) {
 	gasPrice := big.NewInt(100)
 
 	tx1 := newStubLegacyTx(gasPrice)
-	tx2 := newReplacementTx(tx1)
+	tx2 := newReplacementTx(tx1, nil)
 	assert.NotEqual(tx1.Hash(), tx2.Hash())
 	assert.Equal(applyPriceBump(tx1.GasPrice(), priceBump), tx2.GasPrice())
 	assert.Equal(tx1.Nonce(), tx2.Nonce())
@@ -434,7 +545,7 @@ func TestNewReplacementTx_DynamicFeeTx(t *testing.T) {
 	gasFeeCap := big.NewInt(1000)
 
 	tx1 := newStubDynamicFeeTx(gasFeeCap, gasTipCap)
-	tx2 := newReplacementTx(tx1)
+	tx2 := newReplacementTx(tx1, nil)
 	assert.NotEqual(tx1.Hash(), tx2.Hash())
 	assert.Equal(applyPriceBump(tx1.GasTipCap(), priceBump), tx2.GasTipCap())
 	assert.Equal(applyPriceBump(tx1.GasFeeCap(), priceBump), tx2.GasFeeCap())
@@ -444,6 +555,49 @@ func TestNewReplacementTx_DynamicFeeTx(t *testing.T) {
 	assert.Equal(tx1.To(), tx2.To())
 }
 
+func TestNewReplacementTx_CurrentFees(t *testing.T) {
+	assert := assert.New(t)
+
+	gpm := &GasPriceMonitor{
+		minGasPrice: big.NewInt(0),
+		gasPrice:    big.NewInt(500),
+		baseFee:     big.NewInt(1000),
+		gasTipCap:   big.NewInt(200),
+	}
+
+	// The current gas price is used if it is higher than the bumped gas price
+	tx := newReplacementTx(newStubLegacyTx(big.NewInt(100)), gpm)
+	assert.Equal(big.NewInt(500), tx.GasPrice())
+	tx = newReplacementTx(newStubLegacyTx(big.NewInt(1000)), gpm)
+	assert.Equal(applyPriceBump(big.NewInt(1000), priceBump), tx.GasPrice())
+
+	// The current fees are used if they are higher than the bumped fees
+	tx1 := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(42),
+		Nonce:     1,
+		GasFeeCap: big.NewInt(1000),
+		GasTipCap: big.NewInt(100),
+		Gas:       1000000,
+	})
+	tx2 := newReplacementTx(tx1, gpm)
+	assert.Equal(big.NewInt(200), tx2.GasTipCap())
+	assert.Equal(big.NewInt(2200), tx2.GasFeeCap())
+	assert.Equal(big.NewInt(42), tx2.ChainId())
+
+	// The bumped fees are used if they are higher than the current fees
+	gpm.baseFee = big.NewInt(1)
+	gpm.gasTipCap = big.NewInt(1)
+	tx2 = newReplacementTx(tx1, gpm)
+	assert.Equal(applyPriceBump(tx1.GasTipCap(), priceBump), tx2.GasTipCap())
+	assert.Equal(applyPriceBump(tx1.GasFeeCap(), priceBump), tx2.GasFeeCap())
+
+	// Only the price bump is applied if the current fees are unknown
+	gpm.baseFee = nil
+	tx2 = newReplacementTx(tx1, gpm)
+	assert.Equal(applyPriceBump(tx1.GasTipCap(), priceBump), tx2.GasTipCap())
+	assert.Equal(applyPriceBump(tx1.GasFeeCap(), priceBump), tx2.GasFeeCap())
+}
+
 func newStubLegacyTx(gasPrice *big.Int) *types.Transaction {
 	addr := pm.RandAddress()
 	return types.NewTx(&types.LegacyTx{
@@ -51,16 +51,26 @@ func (ow *OrchestratorWatcher) Watch() {
 
 	blockSink := make(chan []*blockwatch.Event, 10)
 	sub := ow.watcher.Subscribe(blockSink)
-	defer sub.Unsubscribe()
+	defer func() { sub.Unsubscribe() }()
+
+	reconnectSink := make(chan struct{}, 1)
+	reconnectSub := ow.watcher.SubscribeReconnects(reconnectSink)
+	defer reconnectSub.Unsubscribe()
 
 	for {
 		select {
 		case <-ow.quit:
 			return
 		case err := <-sub.Err():
-			glog.Error(err)
+			sub = resubscribe(ow.watcher, sub, blockSink, err)
 		case block := <-blockSink:
 			go ow.handleBlockEvents(block)
+		case <-reconnectSink:
+			go func() {
+				if err := ow.reconcile(); err != nil {
+					glog.Errorf("error reconciling orchestrators: %v", err)
+				}
+			}()
 		case _ = <-roundSink:
 			go func() {
 				if err := ow.handleRoundEvent(); err != nil {
@@ -171,6 +181,36 @@ func (ow *OrchestratorWatcher) handleTranscoderDeactivated(log types.Log) error
 	)
 }
 
+// reconcile refetches the activation rounds and stake of the orchestrators in the store after the block watcher
+// reconnected, in case an event could not be handled while the ethereum node was unreachable
+func (ow *OrchestratorWatcher) reconcile() error {
+	orchs, err := ow.store.SelectOrchs(nil)
+	if err != nil {
+		return err
+	}
+
+	ow.blockMu.Lock()
+	for _, o := range orchs {
+		t, err := ow.lpEth.GetTranscoder(ethcommon.HexToAddress(o.EthereumAddr))
+		if err != nil {
+			glog.Errorf("could not reconcile orchestrator %v: %v", o.EthereumAddr, err)
+			continue
+		}
+		if err := ow.store.UpdateOrch(
+			&common.DBOrch{
+				EthereumAddr:      o.EthereumAddr,
+				ActivationRound:   common.ToInt64(t.ActivationRound),
+				DeactivationRound: common.ToInt64(t.DeactivationRound),
+			},
+		); err != nil {
+			glog.Errorf("could not reconcile orchestrator %v: %v", o.EthereumAddr, err)
+		}
+	}
+	ow.blockMu.Unlock()
+
+	return ow.handleRoundEvent()
+}
+
 func (ow *OrchestratorWatcher) handleRoundEvent() error {
 	round, err := ow.lpEth.CurrentRound()
 	if err != nil {
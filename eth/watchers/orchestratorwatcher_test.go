@@ -215,3 +215,43 @@ func TestOrchWatcher_HandleRoundEvent_CacheOrchestratorStake(t *testing.T) {
 	assert.Equal(int64(1), errorLogsAfter-errorLogsBefore)
 	lpEth.TotalStake = expStake
 }
+
+func TestOrchWatcher_Reconcile(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	watcher := &stubBlockWatcher{}
+	stubStore := &stubOrchestratorStore{
+		ethereumAddr:    pm.RandAddress().Hex(),
+		activationRound: 1,
+	}
+	lpEth := &eth.StubClient{
+		Orch: &lpTypes.Transcoder{
+			ActivationRound:   big.NewInt(5),
+			DeactivationRound: big.NewInt(10),
+		},
+		TotalStake: big.NewInt(5000000000000000000),
+		Errors:     map[string]error{"CurrentRound": errors.New("CurrentRound error")},
+	}
+	tw := &stubTimeWatcher{}
+
+	ow, err := NewOrchestratorWatcher(stubBondingManagerAddr, watcher, stubStore, lpEth, tw)
+	require.Nil(err)
+
+	// Activation rounds are refetched even if the stake can't be cached
+	err = ow.reconcile()
+	assert.EqualError(err, "CurrentRound error")
+	assert.Equal(int64(5), stubStore.activationRound)
+	assert.Equal(int64(10), stubStore.deactivationRound)
+
+	// Stake is cached
+	delete(lpEth.Errors, "CurrentRound")
+	err = ow.reconcile()
+	assert.Nil(err)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(int64(500000), stubStore.stake)
+
+	// Store error
+	stubStore.selectErr = errors.New("SelectOrchs error")
+	assert.EqualError(ow.reconcile(), "SelectOrchs error")
+}
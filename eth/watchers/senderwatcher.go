@@ -30,6 +30,8 @@ type SenderWatcher struct {
 	// subscriptions
 	reserveChangeFeed  event.Feed
 	reserveChangeScope event.SubscriptionScope
+	winningTicketFeed  event.Feed
+	winningTicketScope event.SubscriptionScope
 }
 
 // NewSenderWatcher initiates a new SenderWatcher
@@ -104,16 +106,22 @@ func (sw *SenderWatcher) Watch() {
 
 	events := make(chan []*blockwatch.Event, 10)
 	sub := sw.watcher.Subscribe(events)
-	defer sub.Unsubscribe()
+	defer func() { sub.Unsubscribe() }()
+
+	reconnectSink := make(chan struct{}, 1)
+	reconnectSub := sw.watcher.SubscribeReconnects(reconnectSink)
+	defer reconnectSub.Unsubscribe()
 
 	for {
 		select {
 		case <-sw.quit:
 			return
 		case err := <-sub.Err():
-			glog.Error(err)
+			sub = resubscribe(sw.watcher, sub, events, err)
 		case events := <-events:
 			go sw.handleBlockEvents(events)
+		case <-reconnectSink:
+			go sw.reconcile()
 		case round := <-roundSink:
 			go func() {
 				if err := sw.handleRoundEvent(round); err != nil {
@@ -128,6 +136,7 @@ func (sw *SenderWatcher) Watch() {
 func (sw *SenderWatcher) Stop() {
 	close(sw.quit)
 	sw.reserveChangeScope.Close()
+	sw.winningTicketScope.Close()
 }
 
 // Clear removes a key-value pair from the map
@@ -142,11 +151,38 @@ func (sw *SenderWatcher) Clear(addr ethcommon.Address) {
 	}
 }
 
+// reconcile clears the cached sender info after the block watcher reconnected, in case an event could not be handled
+// while the ethereum node was unreachable. The info is fetched again when it is next requested, and the reserve change
+// subscribers are notified so that they request it.
+func (sw *SenderWatcher) reconcile() {
+	sw.mu.Lock()
+	senders := make(map[ethcommon.Address]bool)
+	for addr := range sw.senders {
+		senders[addr] = true
+	}
+	for addr := range sw.claimedReserve {
+		senders[addr] = true
+	}
+	sw.senders = make(map[ethcommon.Address]*pm.SenderInfo)
+	sw.claimedReserve = make(map[ethcommon.Address]*big.Int)
+	sw.mu.Unlock()
+
+	glog.Infof("Reconciling sender info senders=%v", len(senders))
+	for addr := range senders {
+		sw.reserveChangeFeed.Send(addr)
+	}
+}
+
 // SubscribeReserveChange notifies subscribers when the sender info for a particular sender changes
 func (sw *SenderWatcher) SubscribeReserveChange(sink chan<- ethcommon.Address) event.Subscription {
 	return sw.reserveChangeScope.Track(sw.reserveChangeFeed.Subscribe(sink))
 }
 
+// SubscribeWinningTicketTransfers notifies subscribers of the winning tickets redeemed on-chain
+func (sw *SenderWatcher) SubscribeWinningTicketTransfers(sink chan<- *contracts.TicketBrokerWinningTicketTransfer) event.Subscription {
+	return sw.winningTicketScope.Track(sw.winningTicketFeed.Subscribe(sink))
+}
+
 func (sw *SenderWatcher) handleBlockEvents(events []*blockwatch.Event) {
 	for _, event := range events {
 		for _, log := range event.BlockHeader.Logs {
@@ -210,6 +246,9 @@ func (sw *SenderWatcher) handleLog(log types.Log) error {
 		}
 		amount := winningTicketTransfer.Amount
 		sender = winningTicketTransfer.Sender
+		if !log.Removed {
+			sw.winningTicketFeed.Send(&winningTicketTransfer)
+		}
 
 		if info, ok := sw.senders[sender]; ok && !log.Removed {
 			// See if amount > deposit
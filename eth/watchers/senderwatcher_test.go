@@ -10,6 +10,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/livepeer/go-livepeer/eth"
 	"github.com/livepeer/go-livepeer/eth/blockwatch"
+	"github.com/livepeer/go-livepeer/eth/contracts"
 	"github.com/livepeer/go-livepeer/pm"
 
 	"github.com/stretchr/testify/assert"
@@ -780,3 +781,86 @@ func TestSubscribeReserveChange(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSubscribeWinningTicketTransfers(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	lpEth := &eth.StubClient{TranscoderAddress: stubClaimant}
+
+	sw, err := NewSenderWatcher(stubTicketBrokerAddr, &stubBlockWatcher{}, lpEth, &stubTimeWatcher{})
+	require.Nil(err)
+
+	sink := make(chan *contracts.TicketBrokerWinningTicketTransfer, 10)
+	sub := sw.SubscribeWinningTicketTransfers(sink)
+	defer sub.Unsubscribe()
+
+	header := defaultMiniHeader()
+	header.Logs = append(header.Logs, newStubWinningTicketLog())
+	blockEvent := &blockwatch.Event{
+		Type:        blockwatch.Added,
+		BlockHeader: header,
+	}
+	sw.handleBlockEvents([]*blockwatch.Event{blockEvent})
+	transfer := <-sink
+	assert.Equal(stubSender, transfer.Sender)
+	assert.Equal(stubClaimant, transfer.Recipient)
+	assert.Equal(big.NewInt(200000000000), transfer.Amount)
+
+	// Removed logs are not sent
+	blockEvent.Type = blockwatch.Removed
+	sw.handleBlockEvents([]*blockwatch.Event{blockEvent})
+	assert.Len(sink, 0)
+}
+
+func TestSenderWatcher_Reconcile(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	watcher := &stubBlockWatcher{}
+	lpEth := &eth.StubClient{
+		SenderInfo: &pm.SenderInfo{
+			Deposit: big.NewInt(10),
+			Reserve: &pm.ReserveInfo{
+				FundsRemaining:        big.NewInt(100),
+				ClaimedInCurrentRound: big.NewInt(0),
+			},
+		},
+		ClaimedAmount: big.NewInt(5),
+	}
+
+	sw, err := NewSenderWatcher(stubTicketBrokerAddr, watcher, lpEth, &stubTimeWatcher{})
+	require.Nil(err)
+
+	sink := make(chan ethcommon.Address, 10)
+	sub := sw.SubscribeReserveChange(sink)
+	defer sub.Unsubscribe()
+
+	go sw.Watch()
+	defer sw.Stop()
+	time.Sleep(2 * time.Millisecond)
+
+	_, err = sw.GetSenderInfo(stubSender)
+	require.Nil(err)
+	_, err = sw.ClaimedReserve(stubSender, stubClaimant)
+	require.Nil(err)
+
+	// The deposit changed while the ethereum node was unreachable
+	lpEth.SenderInfo = &pm.SenderInfo{
+		Deposit: big.NewInt(20),
+		Reserve: &pm.ReserveInfo{
+			FundsRemaining:        big.NewInt(100),
+			ClaimedInCurrentRound: big.NewInt(0),
+		},
+	}
+	watcher.reconnectSink <- struct{}{}
+
+	select {
+	case sender := <-sink:
+		assert.Equal(stubSender, sender)
+	case <-time.After(time.Second):
+		t.Fatal("expected reserve change subscribers to be notified")
+	}
+
+	info, err := sw.GetSenderInfo(stubSender)
+	require.Nil(err)
+	assert.Equal(big.NewInt(20), info.Deposit)
+}
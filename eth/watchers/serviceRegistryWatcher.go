@@ -37,16 +37,24 @@ func NewServiceRegistryWatcher(serviceRegistryAddr ethcommon.Address, watcher Bl
 func (srw *ServiceRegistryWatcher) Watch() {
 	events := make(chan []*blockwatch.Event, 10)
 	sub := srw.watcher.Subscribe(events)
-	defer sub.Unsubscribe()
+	defer func() { sub.Unsubscribe() }()
+
+	reconnectSink := make(chan struct{}, 1)
+	reconnectSub := srw.watcher.SubscribeReconnects(reconnectSink)
+	defer reconnectSub.Unsubscribe()
 
 	for {
 		select {
 		case <-srw.quit:
 			return
 		case err := <-sub.Err():
-			glog.Error(err)
+			sub = resubscribe(srw.watcher, sub, events, err)
 		case events := <-events:
 			srw.handleBlockEvents(events)
+		case <-reconnectSink:
+			if err := srw.reconcile(); err != nil {
+				glog.Errorf("error reconciling service URIs: %v", err)
+			}
 		}
 	}
 }
@@ -56,6 +64,33 @@ func (srw *ServiceRegistryWatcher) Stop() {
 	close(srw.quit)
 }
 
+// reconcile refetches the service URIs of the orchestrators in the store after the block watcher reconnected,
+// in case an event could not be handled while the ethereum node was unreachable
+func (srw *ServiceRegistryWatcher) reconcile() error {
+	orchs, err := srw.store.SelectOrchs(nil)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range orchs {
+		uri, err := srw.lpEth.GetServiceURI(ethcommon.HexToAddress(o.EthereumAddr))
+		if err != nil {
+			glog.Errorf("could not reconcile service URI of orchestrator %v: %v", o.EthereumAddr, err)
+			continue
+		}
+		if err := srw.store.UpdateOrch(
+			&common.DBOrch{
+				EthereumAddr: o.EthereumAddr,
+				ServiceURI:   uri,
+			},
+		); err != nil {
+			glog.Errorf("could not reconcile service URI of orchestrator %v: %v", o.EthereumAddr, err)
+		}
+	}
+
+	return nil
+}
+
 func (srw *ServiceRegistryWatcher) handleBlockEvents(events []*blockwatch.Event) {
 	for _, event := range events {
 		for _, log := range event.BlockHeader.Logs {
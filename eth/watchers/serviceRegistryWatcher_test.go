@@ -64,3 +64,30 @@ func TestServiceRegistryWatcher_HandleLog_HandleServiceURIUpdate(t *testing.T) {
 	assert.Equal(lpEth.Orch.ServiceURI, stubStore.serviceURI)
 	assert.Equal(lpEth.Orch.Address.String(), stubStore.ethereumAddr)
 }
+
+func TestServiceRegistryWatcher_Reconcile(t *testing.T) {
+	assert := assert.New(t)
+	watcher := &stubBlockWatcher{}
+	stubStore := &stubOrchestratorStore{
+		ethereumAddr: stubTranscoder.Hex(),
+		serviceURI:   "http://127.0.0.1:8935",
+	}
+	lpEth := &eth.StubClient{
+		Orch: &lpTypes.Transcoder{
+			Address:    stubTranscoder,
+			ServiceURI: "http://127.0.0.1:1337",
+		},
+	}
+	srw, err := NewServiceRegistryWatcher(stubServiceRegistryAddr, watcher, stubStore, lpEth)
+	assert.Nil(err)
+
+	go srw.Watch()
+	defer srw.Stop()
+	time.Sleep(2 * time.Millisecond)
+
+	// The service URI was updated while the ethereum node was unreachable
+	watcher.reconnectSink <- struct{}{}
+	time.Sleep(2 * time.Millisecond)
+	assert.Equal("http://127.0.0.1:1337", stubStore.serviceURI)
+	assert.Equal(stubTranscoder.Hex(), stubStore.ethereumAddr)
+}
@@ -266,18 +266,28 @@ func (s *stubSubscription) Err() <-chan error {
 }
 
 type stubBlockWatcher struct {
-	sink         chan<- []*blockwatch.Event
-	sub          *stubSubscription
-	latestHeader *blockwatch.MiniHeader
-	err          error
+	sink          chan<- []*blockwatch.Event
+	sub           *stubSubscription
+	errCh         chan error
+	subscribes    int
+	reconnectSink chan<- struct{}
+	latestHeader  *blockwatch.MiniHeader
+	err           error
 }
 
 func (bw *stubBlockWatcher) Subscribe(sink chan<- []*blockwatch.Event) event.Subscription {
 	bw.sink = sink
-	bw.sub = &stubSubscription{errCh: make(<-chan error)}
+	bw.errCh = make(chan error)
+	bw.sub = &stubSubscription{errCh: bw.errCh}
+	bw.subscribes++
 	return bw.sub
 }
 
+func (bw *stubBlockWatcher) SubscribeReconnects(sink chan<- struct{}) event.Subscription {
+	bw.reconnectSink = sink
+	return &stubSubscription{errCh: make(<-chan error)}
+}
+
 func (bw *stubBlockWatcher) GetLatestBlock() (*blockwatch.MiniHeader, error) {
 	return bw.latestHeader, bw.err
 }
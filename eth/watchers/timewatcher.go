@@ -63,41 +63,70 @@ func NewTimeWatcher(roundsManagerAddr ethcommon.Address, watcher BlockWatcher, l
 		dec:     dec,
 	}
 
+	if err := tw.fetchRoundInfo(); err != nil {
+		return nil, err
+	}
+
+	lastSeenBlock, err := tw.watcher.GetLatestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching last seen block err=%q", err)
+	}
+	l1BlockNum := big.NewInt(0)
+	if lastSeenBlock != nil {
+		l1BlockNum = lastSeenBlock.L1BlockNumber
+	}
+	tw.setLastSeenL1Block(l1BlockNum)
+
+	return tw, nil
+}
+
+// fetchRoundInfo sets the round info and the transcoder pool size cached by the TimeWatcher from RPC calls to an ethereum node
+func (tw *TimeWatcher) fetchRoundInfo() error {
 	lr, err := tw.lpEth.LastInitializedRound()
 	if err != nil {
-		return nil, fmt.Errorf("error fetching initial lastInitializedRound value err=%q", err)
+		return fmt.Errorf("error fetching initial lastInitializedRound value err=%q", err)
 	}
 	bh, err := tw.lpEth.BlockHashForRound(lr)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching initial lastInitializedL1BlockHash value err=%q", err)
+		return fmt.Errorf("error fetching initial lastInitializedL1BlockHash value err=%q", err)
 	}
 	pbh, err := tw.lpEth.BlockHashForRound(prevRound(lr))
 	if err != nil {
-		return nil, fmt.Errorf("error fetching initial preLastInitializedL1BlockHash value err=%q", err)
+		return fmt.Errorf("error fetching initial preLastInitializedL1BlockHash value err=%q", err)
 	}
 	num, err := tw.lpEth.CurrentRoundStartBlock()
 	if err != nil {
-		return nil, fmt.Errorf("error fetching current round start block err=%q", err)
+		return fmt.Errorf("error fetching current round start block err=%q", err)
 	}
-	tw.setLastInitializedRound(lr, bh, pbh, num)
-
-	lastSeenBlock, err := tw.watcher.GetLatestBlock()
+	size, err := tw.lpEth.GetTranscoderPoolSize()
 	if err != nil {
-		return nil, fmt.Errorf("error fetching last seen block err=%q", err)
+		return fmt.Errorf("error fetching initial transcoderPoolSize err=%q", err)
 	}
-	l1BlockNum := big.NewInt(0)
-	if lastSeenBlock != nil {
-		l1BlockNum = lastSeenBlock.L1BlockNumber
+	tw.setLastInitializedRound(lr, bh, pbh, num)
+	tw.setTranscoderPoolSize(size)
+
+	return nil
+}
+
+// reconcile refetches the round info after the block watcher reconnected, in case a NewRound event could not be handled
+// while the ethereum node was unreachable. The round subscribers are notified if the round changed.
+func (tw *TimeWatcher) reconcile() error {
+	tw.feedMu.Lock()
+	defer tw.feedMu.Unlock()
+
+	prev := tw.LastInitializedRound()
+	if err := tw.fetchRoundInfo(); err != nil {
+		return err
 	}
-	tw.setLastSeenL1Block(l1BlockNum)
 
-	size, err := tw.lpEth.GetTranscoderPoolSize()
-	if err != nil {
-		return nil, fmt.Errorf("error fetching initial transcoderPoolSize err=%q", err)
+	lr := tw.LastInitializedRound()
+	if prev == nil || prev.Cmp(lr) != 0 {
+		glog.Infof("Reconciled last initialized round prev=%v round=%v", prev, lr)
+		// The subscribers only use the log to know whether a NewRound event was removed
+		tw.roundSubFeed.Send(types.Log{})
 	}
-	tw.setTranscoderPoolSize(size)
 
-	return tw, nil
+	return nil
 }
 
 // LastInitializedRound gets the last initialized round from cache
@@ -167,15 +196,26 @@ func (tw *TimeWatcher) setLastSeenL1Block(blockNum *big.Int) {
 func (tw *TimeWatcher) Watch() error {
 	blockSink := make(chan []*blockwatch.Event, 10)
 	sub := tw.watcher.Subscribe(blockSink)
-	defer sub.Unsubscribe()
+	defer func() { sub.Unsubscribe() }()
+
+	reconnectSink := make(chan struct{}, 1)
+	reconnectSub := tw.watcher.SubscribeReconnects(reconnectSink)
+	defer reconnectSub.Unsubscribe()
+
 	for {
 		select {
 		case <-tw.quit:
 			return nil
 		case err := <-sub.Err():
-			glog.Error(err)
+			sub = resubscribe(tw.watcher, sub, blockSink, err)
 		case block := <-blockSink:
 			go tw.handleBlockEvents(block)
+		case <-reconnectSink:
+			go func() {
+				if err := tw.reconcile(); err != nil {
+					glog.Errorf("Error reconciling round info err=%q", err)
+				}
+			}()
 		}
 	}
 }
@@ -384,3 +384,78 @@ func TestSubscribeRounds(t *testing.T) {
 	update := <-events
 	assert.Equal(newRoundEvent, update)
 }
+
+func TestTimeWatcher_Resubscribe(t *testing.T) {
+	assert := assert.New(t)
+	lpEth := &eth.StubClient{
+		PoolSize: big.NewInt(50),
+		Round:    big.NewInt(1),
+		Errors:   make(map[string]error),
+	}
+	watcher := &stubBlockWatcher{
+		latestHeader: &blockwatch.MiniHeader{L1BlockNumber: big.NewInt(10)},
+	}
+	tw, err := NewTimeWatcher(stubRoundsManagerAddr, watcher, lpEth)
+	require.Nil(t, err)
+
+	go tw.Watch()
+	defer tw.Stop()
+	time.Sleep(2 * time.Millisecond)
+
+	oldSub := watcher.sub
+	watcher.errCh <- fmt.Errorf("connection lost")
+	time.Sleep(2 * time.Millisecond)
+	assert.True(oldSub.unsubscribed)
+	assert.Equal(2, watcher.subscribes)
+
+	// Events are received on the new subscription
+	header := defaultMiniHeader()
+	watcher.sink <- []*blockwatch.Event{{Type: blockwatch.Added, BlockHeader: header}}
+	time.Sleep(2 * time.Millisecond)
+	assert.Equal(header.L1BlockNumber, tw.LastSeenL1Block())
+}
+
+func TestTimeWatcher_Reconcile(t *testing.T) {
+	assert := assert.New(t)
+	hash := ethcommon.HexToHash("foo")
+	lpEth := &eth.StubClient{
+		PoolSize:          big.NewInt(50),
+		BlockNum:          big.NewInt(10),
+		BlockHashToReturn: hash,
+		Round:             big.NewInt(1),
+		Errors:            make(map[string]error),
+	}
+	watcher := &stubBlockWatcher{
+		latestHeader: &blockwatch.MiniHeader{L1BlockNumber: big.NewInt(10)},
+	}
+	tw, err := NewTimeWatcher(stubRoundsManagerAddr, watcher, lpEth)
+	require.Nil(t, err)
+
+	roundSink := make(chan types.Log, 10)
+	roundSub := tw.SubscribeRounds(roundSink)
+	defer roundSub.Unsubscribe()
+
+	go tw.Watch()
+	defer tw.Stop()
+	time.Sleep(2 * time.Millisecond)
+
+	// A round was initialized while the ethereum node was unreachable
+	lpEth.Round = big.NewInt(2)
+	lpEth.PoolSize = big.NewInt(60)
+	watcher.reconnectSink <- struct{}{}
+	time.Sleep(2 * time.Millisecond)
+
+	assert.Equal(big.NewInt(2), tw.LastInitializedRound())
+	assert.Equal(big.NewInt(60), tw.GetTranscoderPoolSize())
+	select {
+	case <-roundSink:
+	default:
+		t.Error("expected round subscribers to be notified")
+	}
+
+	// Round did not change, subscribers are not notified
+	watcher.reconnectSink <- struct{}{}
+	time.Sleep(2 * time.Millisecond)
+	assert.Equal(big.NewInt(2), tw.LastInitializedRound())
+	assert.Len(roundSink, 0)
+}
@@ -3,14 +3,24 @@ package watchers
 import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/golang/glog"
 	"github.com/livepeer/go-livepeer/eth/blockwatch"
 )
 
 type BlockWatcher interface {
 	Subscribe(sink chan<- []*blockwatch.Event) event.Subscription
+	SubscribeReconnects(sink chan<- struct{}) event.Subscription
 	GetLatestBlock() (*blockwatch.MiniHeader, error)
 }
 
 type timeWatcher interface {
 	SubscribeRounds(sink chan<- types.Log) event.Subscription
 }
+
+// resubscribe replaces a failed subscription to the block events of watcher, so that the caller doesn't silently
+// stop receiving events
+func resubscribe(watcher BlockWatcher, sub event.Subscription, sink chan<- []*blockwatch.Event, err error) event.Subscription {
+	glog.Errorf("Block event subscription failed - resubscribing err=%v", err)
+	sub.Unsubscribe()
+	return watcher.Subscribe(sink)
+}
@@ -50,14 +50,14 @@ func NewUnbondingWatcher(addr ethcommon.Address, bondingManagerAddr ethcommon.Ad
 func (w *UnbondingWatcher) Watch() {
 	blockSink := make(chan []*blockwatch.Event, 10)
 	sub := w.bw.Subscribe(blockSink)
-	defer sub.Unsubscribe()
+	defer func() { sub.Unsubscribe() }()
 
 	for {
 		select {
 		case <-w.quit:
 			return
 		case err := <-sub.Err():
-			glog.Errorf("error with block subscription: %v", err)
+			sub = resubscribe(w.bw, sub, blockSink, err)
 		case block := <-blockSink:
 			go w.handleBlockEvents(block)
 		}
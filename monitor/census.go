@@ -27,6 +27,33 @@ type (
 	SegmentTranscodeError string
 )
 
+// JobType describes the transcoding job of a segment, used to label the transcoding metrics
+type JobType struct {
+	// Codecs are the comma-separated codecs of the renditions, e.g. "H264,HEVC"
+	Codecs string
+	// Detection is whether scene classification ran on the segment
+	Detection bool
+}
+
+func (j JobType) tags() []tag.Mutator {
+	codecs := j.Codecs
+	if codecs == "" {
+		codecs = "unknown"
+	}
+	detection := "off"
+	if j.Detection {
+		detection = "on"
+	}
+	return []tag.Mutator{tag.Insert(census.kCodec, codecs), tag.Insert(census.kDetection, detection)}
+}
+
+// Stages of the processing of the renditions of a segment
+const (
+	RenditionStageUpload    = "upload"
+	RenditionStageTranscode = "transcode"
+	RenditionStageDownload  = "download"
+)
+
 const (
 	SegmentUploadErrorUnknown               SegmentUploadError    = "Unknown"
 	SegmentUploadErrorGenCreds              SegmentUploadError    = "GenCreds"
@@ -94,10 +121,16 @@ type (
 		nodeID                        string
 		ctx                           context.Context
 		kGPU                          tag.Key
+		kGPUName                      tag.Key
+		kDBOperation                  tag.Key
 		kNodeType                     tag.Key
 		kNodeID                       tag.Key
 		kProfile                      tag.Key
 		kProfiles                     tag.Key
+		kCodec                        tag.Key
+		kDetection                    tag.Key
+		kResolution                   tag.Key
+		kStage                        tag.Key
 		kErrorCode                    tag.Key
 		kTry                          tag.Key
 		kSender                       tag.Key
@@ -132,10 +165,27 @@ type (
 		mTranscodersLoad              *stats.Int64Measure
 		mSuccessRate                  *stats.Float64Measure
 		mSuccessRatePerStream         *stats.Float64Measure
+		mStreamHealthScore            *stats.Float64Measure
+		mPerStreamMetricsStreams      *stats.Int64Measure
+		mStreamHealthScoreMin         *stats.Float64Measure
 		mTranscodeTime                *stats.Float64Measure
 		mTranscodeOverallLatency      *stats.Float64Measure
 		mUploadTime                   *stats.Float64Measure
 		mDownloadTime                 *stats.Float64Measure
+		mRenditionStageTime           *stats.Float64Measure
+		mGPUUtilization               *stats.Int64Measure
+		mGPUMemoryUtilization         *stats.Int64Measure
+		mGPUEncoderUtilization        *stats.Int64Measure
+		mGPUDecoderUtilization        *stats.Int64Measure
+		mGPUMemoryUsed                *stats.Int64Measure
+		mGPUMemoryTotal               *stats.Int64Measure
+		mGPUTemperature               *stats.Int64Measure
+		mDBFileSize                   *stats.Int64Measure
+		mDBWALSize                    *stats.Int64Measure
+		mDBQueryLatency               *stats.Float64Measure
+		mDBBusyErrors                 *stats.Int64Measure
+		mDBConnectionWaits            *stats.Int64Measure
+		mDBConnectionWaitTime         *stats.Float64Measure
 		mAuthWebhookTime              *stats.Float64Measure
 		mSourceSegmentDuration        *stats.Float64Measure
 		mHTTPClientTimeout1           *stats.Int64Measure
@@ -163,12 +213,22 @@ type (
 		mTicketsRecv           *stats.Int64Measure
 		mPaymentRecvErr        *stats.Int64Measure
 		mWinningTicketsRecv    *stats.Int64Measure
+		mWinningFaceValueRecv  *stats.Float64Measure
+		mTicketsRedeemed       *stats.Int64Measure
 		mValueRedeemed         *stats.Float64Measure
 		mTicketRedemptionError *stats.Int64Measure
+		mWinningTicketsPending *stats.Int64Measure
+		mRedemptionsSubmitted  *stats.Int64Measure
+		mRedemptionGasCost     *stats.Float64Measure
+		mSenderMaxFloat        *stats.Float64Measure
 		mSuggestedGasPrice     *stats.Float64Measure
 		mMinGasPrice           *stats.Float64Measure
 		mMaxGasPrice           *stats.Float64Measure
 		mTranscodingPrice      *stats.Float64Measure
+		mRewardCallErrors      *stats.Int64Measure
+		mRewardMissed          *stats.Int64Measure
+		mEthBalance            *stats.Float64Measure
+		mSafeMode              *stats.Int64Measure
 
 		// Metrics for pixel accounting
 		mMilPixelsProcessed *stats.Float64Measure
@@ -184,9 +244,38 @@ type (
 		mSegmentClassProb    *stats.Float64Measure
 		mSceneClassification *stats.Int64Measure
 
+		// Metrics for Ethereum RPC calls
+		kRPCProvider   tag.Key
+		kRPCMethod     tag.Key
+		mEthRPCCalls   *stats.Int64Measure
+		mEthRPCLatency *stats.Float64Measure
+		mEthRPCErrors  *stats.Int64Measure
+
+		// Metrics for discovery rate limits
+		kRateLimit                    tag.Key
+		mDiscoveryRequestsRateLimited *stats.Int64Measure
+
+		// Metrics for object store saves
+		kObjectStore             tag.Key
+		mObjectStoreSaveRetries  *stats.Int64Measure
+		mObjectStoreSaveFailures *stats.Int64Measure
+		mObjectStoreCircuitOpen  *stats.Int64Measure
+		kStorage                 tag.Key
+		mStreamStorageBytes      *stats.Int64Measure
+		mObjectStoreHealthy      *stats.Int64Measure
+		mObjectStoreCheckFailed  *stats.Int64Measure
+
+		// Metrics of the orchestrators on the broadcaster
+		mOrchSegmentsSent    *stats.Int64Measure
+		mOrchSegmentFailures *stats.Int64Measure
+		mOrchRoundTrip       *stats.Float64Measure
+		mOrchTicketValueSent *stats.Float64Measure
+
 		lock        sync.Mutex
 		emergeTimes map[uint64]map[uint64]time.Time // nonce:seqNo
 		success     map[uint64]*segmentsAverager
+		health      map[uint64]float64 // nonce:score
+		streams     *streamLabels
 	}
 
 	segmentCount struct {
@@ -227,14 +316,24 @@ func InitCensus(nodeType NodeType, version string) {
 		nodeID:      NodeID,
 		nodeType:    nodeType,
 		success:     make(map[uint64]*segmentsAverager),
+		health:      make(map[uint64]float64),
+	}
+	if PerStreamMetrics && (MaxStreamsPerStreamMetrics > 0 || PerStreamMetricsTTL > 0) {
+		census.streams = newStreamLabels(MaxStreamsPerStreamMetrics, PerStreamMetricsTTL)
 	}
 	var err error
 	ctx := context.Background()
 	census.kGPU = tag.MustNewKey("gpu")
+	census.kGPUName = tag.MustNewKey("gpu_name")
+	census.kDBOperation = tag.MustNewKey("operation")
 	census.kNodeType = tag.MustNewKey("node_type")
 	census.kNodeID = tag.MustNewKey("node_id")
 	census.kProfile = tag.MustNewKey("profile")
 	census.kProfiles = tag.MustNewKey("profiles")
+	census.kCodec = tag.MustNewKey("codec")
+	census.kDetection = tag.MustNewKey("detection")
+	census.kResolution = tag.MustNewKey("resolution")
+	census.kStage = tag.MustNewKey("stage")
 	census.kErrorCode = tag.MustNewKey("error_code")
 	census.kTry = tag.MustNewKey("try")
 	census.kSender = tag.MustNewKey("sender")
@@ -248,6 +347,11 @@ func InitCensus(nodeType NodeType, version string) {
 	census.kOrchestratorAddress = tag.MustNewKey("orchestrator_address")
 	census.kFVErrorType = tag.MustNewKey("fverror_type")
 	census.kSegClassName = tag.MustNewKey("seg_class_name")
+	census.kRPCProvider = tag.MustNewKey("provider")
+	census.kRPCMethod = tag.MustNewKey("method")
+	census.kRateLimit = tag.MustNewKey("limit")
+	census.kObjectStore = tag.MustNewKey("object_store")
+	census.kStorage = tag.MustNewKey("storage")
 	census.ctx, err = tag.New(ctx, tag.Insert(census.kNodeType, string(nodeType)), tag.Insert(census.kNodeID, NodeID))
 	if err != nil {
 		glog.Fatal("Error creating context", err)
@@ -282,11 +386,28 @@ func InitCensus(nodeType NodeType, version string) {
 	census.mTranscodersLoad = stats.Int64("transcoders_load", "Total load of transcoders currently connected to orchestrator", "tot")
 	census.mSuccessRate = stats.Float64("success_rate", "Success rate", "per")
 	census.mSuccessRatePerStream = stats.Float64("success_rate_per_stream", "Success rate, per stream", "per")
+	census.mStreamHealthScore = stats.Float64("stream_health_score", "Health score of the stream", "score")
+	census.mStreamHealthScoreMin = stats.Float64("stream_health_score_min", "Lowest health score of the active streams", "score")
+	census.mPerStreamMetricsStreams = stats.Int64("per_stream_metrics_streams", "Number of streams labeled with their manifest ID in the per-stream metrics", "tot")
 	census.mTranscodeTime = stats.Float64("transcode_time_seconds", "Transcoding time", "sec")
 	census.mTranscodeOverallLatency = stats.Float64("transcode_overall_latency_seconds",
 		"Transcoding latency, from source segment emerged from segmenter till all transcoded segment apeeared in manifest", "sec")
 	census.mUploadTime = stats.Float64("upload_time_seconds", "Upload (to Orchestrator) time", "sec")
 	census.mDownloadTime = stats.Float64("download_time_seconds", "Download (from orchestrator) time", "sec")
+	census.mRenditionStageTime = stats.Float64("rendition_stage_time_seconds", "Upload, transcode or download time of a rendition", "sec")
+	census.mGPUUtilization = stats.Int64("gpu_utilization_percent", "Percent of time a kernel was running on the GPU", "%")
+	census.mGPUMemoryUtilization = stats.Int64("gpu_memory_utilization_percent", "Percent of time the GPU memory was read or written", "%")
+	census.mGPUEncoderUtilization = stats.Int64("gpu_encoder_utilization_percent", "Utilization of the video encoder of the GPU", "%")
+	census.mGPUDecoderUtilization = stats.Int64("gpu_decoder_utilization_percent", "Utilization of the video decoder of the GPU", "%")
+	census.mGPUMemoryUsed = stats.Int64("gpu_memory_used_bytes", "GPU memory used", "By")
+	census.mGPUMemoryTotal = stats.Int64("gpu_memory_total_bytes", "GPU memory installed", "By")
+	census.mGPUTemperature = stats.Int64("gpu_temperature_celsius", "Temperature of the GPU", "C")
+	census.mDBFileSize = stats.Int64("db_file_size_bytes", "Size of the database file", "By")
+	census.mDBWALSize = stats.Int64("db_wal_size_bytes", "Size of the write-ahead log of the database", "By")
+	census.mDBQueryLatency = stats.Float64("db_query_latency_seconds", "Latency of the database queries", "sec")
+	census.mDBBusyErrors = stats.Int64("db_busy_errors", "Number of database queries that failed because the database was locked", "tot")
+	census.mDBConnectionWaits = stats.Int64("db_connection_waits", "Number of times a query waited for the database connection", "tot")
+	census.mDBConnectionWaitTime = stats.Float64("db_connection_wait_seconds", "Time queries waited for the database connection", "sec")
 	census.mAuthWebhookTime = stats.Float64("auth_webhook_time_milliseconds", "Authentication webhook execution time", "ms")
 	census.mSourceSegmentDuration = stats.Float64("source_segment_duration_seconds", "Source segment's duration", "sec")
 	census.mTranscodeScore = stats.Float64("transcode_score", "Ratio of source segment duration vs. transcode time", "rat")
@@ -308,12 +429,22 @@ func InitCensus(nodeType NodeType, version string) {
 	census.mTicketsRecv = stats.Int64("tickets_recv", "TicketsRecv", "tot")
 	census.mPaymentRecvErr = stats.Int64("payment_recv_errors", "PaymentRecvErr", "tot")
 	census.mWinningTicketsRecv = stats.Int64("winning_tickets_recv", "WinningTicketsRecv", "tot")
+	census.mWinningFaceValueRecv = stats.Float64("winning_ticket_face_value_recv", "WinningTicketFaceValueRecv", "gwei")
+	census.mTicketsRedeemed = stats.Int64("tickets_redeemed", "TicketsRedeemed", "tot")
 	census.mValueRedeemed = stats.Float64("value_redeemed", "ValueRedeemed", "gwei")
 	census.mTicketRedemptionError = stats.Int64("ticket_redemption_errors", "TicketRedemptionError", "tot")
+	census.mWinningTicketsPending = stats.Int64("winning_tickets_pending", "WinningTicketsPending", "tot")
+	census.mRedemptionsSubmitted = stats.Int64("ticket_redemptions_submitted", "TicketRedemptionsSubmitted", "tot")
+	census.mRedemptionGasCost = stats.Float64("ticket_redemption_gas_cost", "TicketRedemptionGasCost", "gwei")
+	census.mSenderMaxFloat = stats.Float64("sender_max_float", "SenderMaxFloat", "gwei")
 	census.mSuggestedGasPrice = stats.Float64("suggested_gas_price", "SuggestedGasPrice", "gwei")
 	census.mMinGasPrice = stats.Float64("min_gas_price", "MinGasPrice", "gwei")
 	census.mMaxGasPrice = stats.Float64("max_gas_price", "MaxGasPrice", "gwei")
 	census.mTranscodingPrice = stats.Float64("transcoding_price", "TranscodingPrice", "wei")
+	census.mRewardCallErrors = stats.Int64("reward_call_errors", "RewardCallErrors", "tot")
+	census.mRewardMissed = stats.Int64("reward_missed_rounds", "RewardMissedRounds", "tot")
+	census.mEthBalance = stats.Float64("eth_balance", "EthBalance", "gwei")
+	census.mSafeMode = stats.Int64("safe_mode", "SafeMode", "tot")
 
 	// Metrics for pixel accounting
 	census.mMilPixelsProcessed = stats.Float64("mil_pixels_processed", "MilPixelsProcessed", "mil pixels")
@@ -330,6 +461,26 @@ func InitCensus(nodeType NodeType, version string) {
 	census.mSegmentClassProb = stats.Float64("segment_class_prob", "SegmentClassProb", "tot")
 	census.mSceneClassification = stats.Int64("scene_classification_done", "SceneClassificationDone", "tot")
 
+	// Metrics for Ethereum RPC calls
+	census.mEthRPCCalls = stats.Int64("eth_rpc_calls", "EthRPCCalls", "tot")
+	census.mEthRPCLatency = stats.Float64("eth_rpc_latency_seconds", "EthRPCLatency", "sec")
+	census.mEthRPCErrors = stats.Int64("eth_rpc_errors", "EthRPCErrors", "tot")
+	census.mDiscoveryRequestsRateLimited = stats.Int64("discovery_requests_rate_limited", "DiscoveryRequestsRateLimited", "tot")
+
+	// Metrics for object store saves
+	census.mObjectStoreSaveRetries = stats.Int64("object_store_save_retries", "ObjectStoreSaveRetries", "tot")
+	census.mObjectStoreSaveFailures = stats.Int64("object_store_save_failures", "ObjectStoreSaveFailures", "tot")
+	census.mObjectStoreCircuitOpen = stats.Int64("object_store_circuit_open", "ObjectStoreCircuitOpen", "tot")
+	census.mStreamStorageBytes = stats.Int64("stream_storage_bytes", "StreamStorageBytes", "By")
+	census.mObjectStoreHealthy = stats.Int64("object_store_healthy", "ObjectStoreHealthy", "tot")
+	census.mObjectStoreCheckFailed = stats.Int64("object_store_health_check_failures", "ObjectStoreHealthCheckFailures", "tot")
+
+	// Metrics of the orchestrators on the broadcaster
+	census.mOrchSegmentsSent = stats.Int64("orchestrator_segments_sent", "OrchestratorSegmentsSent", "tot")
+	census.mOrchSegmentFailures = stats.Int64("orchestrator_segment_failures", "OrchestratorSegmentFailures", "tot")
+	census.mOrchRoundTrip = stats.Float64("orchestrator_round_trip_seconds", "OrchestratorRoundTrip, seconds", "sec")
+	census.mOrchTicketValueSent = stats.Float64("orchestrator_ticket_value_sent", "OrchestratorTicketValueSent", "gwei")
+
 	glog.Infof("Compiler: %s Arch %s OS %s Go version %s", runtime.Compiler, runtime.GOARCH, runtime.GOOS, runtime.Version())
 	glog.Infof("Livepeer version: %s", version)
 	glog.Infof("Node type %s node ID %s", nodeType, NodeID)
@@ -494,14 +645,14 @@ func InitCensus(nodeType NodeType, version string) {
 			Name:        "segment_transcoded_total",
 			Measure:     census.mSegmentTranscoded,
 			Description: "SegmentTranscoded",
-			TagKeys:     append([]tag.Key{census.kProfiles, census.kTrusted, census.kVerified}, baseTags...),
+			TagKeys:     append([]tag.Key{census.kProfiles, census.kCodec, census.kDetection, census.kTrusted, census.kVerified}, baseTags...),
 			Aggregation: view.Count(),
 		},
 		{
 			Name:        "segment_transcoded_unprocessed_total",
 			Measure:     census.mSegmentTranscodedUnprocessed,
 			Description: "Raw number of segments successfully transcoded.",
-			TagKeys:     append([]tag.Key{census.kProfiles}, baseTagsWithManifestIDAndOrchInfo...),
+			TagKeys:     append([]tag.Key{census.kProfiles, census.kCodec, census.kDetection}, baseTagsWithManifestIDAndOrchInfo...),
 			Aggregation: view.Count(),
 		},
 		{
@@ -515,7 +666,7 @@ func InitCensus(nodeType NodeType, version string) {
 			Name:        "segment_transcoded_all_appeared_total",
 			Measure:     census.mSegmentTranscodedAllAppeared,
 			Description: "SegmentTranscodedAllAppeared",
-			TagKeys:     append([]tag.Key{census.kProfiles}, baseTagsWithManifestIDAndOrchInfo...),
+			TagKeys:     append([]tag.Key{census.kProfiles, census.kCodec, census.kDetection}, baseTagsWithManifestIDAndOrchInfo...),
 			Aggregation: view.Count(),
 		},
 		{
@@ -532,25 +683,46 @@ func InitCensus(nodeType NodeType, version string) {
 			TagKeys:     baseTagsWithManifestID,
 			Aggregation: view.LastValue(),
 		},
+		{
+			Name:        "stream_health_score",
+			Measure:     census.mStreamHealthScore,
+			Description: "Health score of the stream from 0 to 100, from its dropped segments, transcode latency, verification failures and orchestrator swaps",
+			TagKeys:     baseTagsWithManifestID,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "stream_health_score_min",
+			Measure:     census.mStreamHealthScoreMin,
+			Description: "Lowest health score of the active streams",
+			TagKeys:     baseTags,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "per_stream_metrics_streams",
+			Measure:     census.mPerStreamMetricsStreams,
+			Description: "Number of streams labeled with their manifest ID in the per-stream metrics",
+			TagKeys:     baseTags,
+			Aggregation: view.LastValue(),
+		},
 		{
 			Name:        "transcode_time_seconds",
 			Measure:     census.mTranscodeTime,
 			Description: "TranscodeTime, seconds",
-			TagKeys:     append([]tag.Key{census.kProfiles, census.kTrusted, census.kVerified}, baseTags...),
+			TagKeys:     append([]tag.Key{census.kProfiles, census.kCodec, census.kDetection, census.kTrusted, census.kVerified}, baseTags...),
 			Aggregation: view.Distribution(0, .250, .500, .750, 1.000, 1.250, 1.500, 2.000, 2.500, 3.000, 3.500, 4.000, 4.500, 5.000, 10.000),
 		},
 		{
 			Name:        "transcode_overall_latency_seconds",
 			Measure:     census.mTranscodeOverallLatency,
 			Description: "Transcoding latency, from source segment emerged from segmenter till all transcoded segment apeeared in manifest",
-			TagKeys:     append([]tag.Key{census.kProfiles}, baseTagsWithOrchInfo...),
+			TagKeys:     append([]tag.Key{census.kProfiles, census.kCodec, census.kDetection}, baseTagsWithOrchInfo...),
 			Aggregation: view.Distribution(0, .500, .75, 1.000, 1.500, 2.000, 2.500, 3.000, 3.500, 4.000, 4.500, 5.000, 10.000),
 		},
 		{
 			Name:        "transcode_score",
 			Measure:     census.mTranscodeScore,
 			Description: "Ratio of source segment duration vs. transcode time",
-			TagKeys:     append([]tag.Key{census.kProfiles, census.kTrusted, census.kVerified}, baseTags...),
+			TagKeys:     append([]tag.Key{census.kProfiles, census.kCodec, census.kDetection, census.kTrusted, census.kVerified}, baseTags...),
 			Aggregation: view.Distribution(0, .5, 1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5, 5, 10, 15, 20, 40),
 		},
 		{
@@ -588,6 +760,104 @@ func InitCensus(nodeType NodeType, version string) {
 			TagKeys:     baseTagsWithManifestID,
 			Aggregation: view.Distribution(0, .10, .20, .50, .100, .150, .200, .500, .1000, .5000, 10.000),
 		},
+		{
+			Name:        "rendition_stage_time_seconds",
+			Measure:     census.mRenditionStageTime,
+			Description: "Upload, transcode or download time of the renditions of the segments, seconds",
+			TagKeys:     append([]tag.Key{census.kStage, census.kProfile, census.kResolution}, baseTags...),
+			Aggregation: view.Distribution(0, .05, .1, .25, .5, .75, 1, 1.5, 2, 2.5, 3, 4, 5, 7.5, 10, 15, 20),
+		},
+		{
+			Name:        "gpu_utilization_percent",
+			Measure:     census.mGPUUtilization,
+			Description: "Percent of time a kernel was running on the GPU during the last sample period",
+			TagKeys:     append([]tag.Key{census.kGPU, census.kGPUName}, baseTags...),
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "gpu_memory_utilization_percent",
+			Measure:     census.mGPUMemoryUtilization,
+			Description: "Percent of time the GPU memory was read or written during the last sample period",
+			TagKeys:     append([]tag.Key{census.kGPU, census.kGPUName}, baseTags...),
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "gpu_encoder_utilization_percent",
+			Measure:     census.mGPUEncoderUtilization,
+			Description: "Utilization of the video encoder of the GPU, percent",
+			TagKeys:     append([]tag.Key{census.kGPU, census.kGPUName}, baseTags...),
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "gpu_decoder_utilization_percent",
+			Measure:     census.mGPUDecoderUtilization,
+			Description: "Utilization of the video decoder of the GPU, percent",
+			TagKeys:     append([]tag.Key{census.kGPU, census.kGPUName}, baseTags...),
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "gpu_memory_used_bytes",
+			Measure:     census.mGPUMemoryUsed,
+			Description: "GPU memory used, bytes",
+			TagKeys:     append([]tag.Key{census.kGPU, census.kGPUName}, baseTags...),
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "gpu_memory_total_bytes",
+			Measure:     census.mGPUMemoryTotal,
+			Description: "GPU memory installed, bytes",
+			TagKeys:     append([]tag.Key{census.kGPU, census.kGPUName}, baseTags...),
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "gpu_temperature_celsius",
+			Measure:     census.mGPUTemperature,
+			Description: "Temperature of the GPU, degrees Celsius",
+			TagKeys:     append([]tag.Key{census.kGPU, census.kGPUName}, baseTags...),
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "db_file_size_bytes",
+			Measure:     census.mDBFileSize,
+			Description: "Size of the database file, bytes",
+			TagKeys:     baseTags,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "db_wal_size_bytes",
+			Measure:     census.mDBWALSize,
+			Description: "Size of the write-ahead log of the database, bytes",
+			TagKeys:     baseTags,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "db_query_latency_seconds",
+			Measure:     census.mDBQueryLatency,
+			Description: "Latency of the database queries, seconds",
+			TagKeys:     append([]tag.Key{census.kDBOperation}, baseTags...),
+			Aggregation: view.Distribution(0, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5),
+		},
+		{
+			Name:        "db_busy_errors",
+			Measure:     census.mDBBusyErrors,
+			Description: "Number of database queries that failed because the database was locked",
+			TagKeys:     append([]tag.Key{census.kDBOperation}, baseTags...),
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "db_connection_waits",
+			Measure:     census.mDBConnectionWaits,
+			Description: "Number of times a query waited for the database connection held by another query",
+			TagKeys:     baseTags,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "db_connection_wait_seconds",
+			Measure:     census.mDBConnectionWaitTime,
+			Description: "Time queries waited for the database connection held by another query, seconds",
+			TagKeys:     baseTags,
+			Aggregation: view.Sum(),
+		},
 		{
 			Name:        "auth_webhook_time_milliseconds",
 			Measure:     census.mAuthWebhookTime,
@@ -725,6 +995,13 @@ func InitCensus(nodeType NodeType, version string) {
 			TagKeys:     baseTagsWithManifestIDAndEthAddr,
 			Aggregation: view.Sum(),
 		},
+		{
+			Name:        "winning_ticket_face_value_recv",
+			Measure:     census.mWinningFaceValueRecv,
+			Description: "Face value of the winning tickets received",
+			TagKeys:     baseTagsWithManifestIDAndEthAddr,
+			Aggregation: view.Sum(),
+		},
 		{
 			Name:        "value_redeemed",
 			Measure:     census.mValueRedeemed,
@@ -732,13 +1009,48 @@ func InitCensus(nodeType NodeType, version string) {
 			TagKeys:     baseTagsWithEthAddr,
 			Aggregation: view.Sum(),
 		},
+		{
+			Name:        "tickets_redeemed",
+			Measure:     census.mTicketsRedeemed,
+			Description: "Winning tickets redeemed",
+			TagKeys:     baseTagsWithEthAddr,
+			Aggregation: view.Sum(),
+		},
 		{
 			Name:        "ticket_redemption_errors",
 			Measure:     census.mTicketRedemptionError,
 			Description: "Errors when redeeming tickets",
+			TagKeys:     baseTagsWithEthAddr,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "winning_tickets_pending",
+			Measure:     census.mWinningTicketsPending,
+			Description: "Winning tickets waiting to be redeemed, per sender",
+			TagKeys:     []tag.Key{census.kNodeID, census.kNodeType, census.kSender},
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "ticket_redemptions_submitted",
+			Measure:     census.mRedemptionsSubmitted,
+			Description: "Ticket redemption transactions waiting to confirm",
 			TagKeys:     baseTags,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "ticket_redemption_gas_cost",
+			Measure:     census.mRedemptionGasCost,
+			Description: "Estimated gas cost of ticket redemption transactions",
+			TagKeys:     baseTagsWithEthAddr,
 			Aggregation: view.Sum(),
 		},
+		{
+			Name:        "sender_max_float",
+			Measure:     census.mSenderMaxFloat,
+			Description: "Max float of a sender, i.e. the value of the tickets from the sender that are guaranteed to be redeemable",
+			TagKeys:     []tag.Key{census.kNodeID, census.kNodeType, census.kSender},
+			Aggregation: view.LastValue(),
+		},
 		{
 			Name:        "min_gas_price",
 			Measure:     census.mMinGasPrice,
@@ -753,13 +1065,148 @@ func InitCensus(nodeType NodeType, version string) {
 			TagKeys:     baseTags,
 			Aggregation: view.LastValue(),
 		},
+		{
+			Name:        "reward_call_errors",
+			Measure:     census.mRewardCallErrors,
+			Description: "Errors when calling reward",
+			TagKeys:     baseTags,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "reward_missed_rounds",
+			Measure:     census.mRewardMissed,
+			Description: "Rounds in which reward could not be called",
+			TagKeys:     baseTags,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "eth_balance",
+			Measure:     census.mEthBalance,
+			Description: "ETH balance of the node account",
+			TagKeys:     baseTags,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "safe_mode",
+			Measure:     census.mSafeMode,
+			Description: "Whether the gas consuming services are paused because of a low ETH balance",
+			TagKeys:     baseTags,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "eth_rpc_calls",
+			Measure:     census.mEthRPCCalls,
+			Description: "Number of Ethereum JSON-RPC calls",
+			TagKeys:     append([]tag.Key{census.kRPCProvider, census.kRPCMethod}, baseTags...),
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "eth_rpc_latency_seconds",
+			Measure:     census.mEthRPCLatency,
+			Description: "Latency of Ethereum JSON-RPC calls",
+			TagKeys:     append([]tag.Key{census.kRPCProvider, census.kRPCMethod}, baseTags...),
+			Aggregation: view.Distribution(0, .05, .1, .25, .5, 1, 2, 5, 10, 20),
+		},
+		{
+			Name:        "eth_rpc_errors",
+			Measure:     census.mEthRPCErrors,
+			Description: "Number of failed Ethereum JSON-RPC calls by error code",
+			TagKeys:     append([]tag.Key{census.kRPCProvider, census.kRPCMethod, census.kErrorCode}, baseTags...),
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "discovery_requests_rate_limited",
+			Measure:     census.mDiscoveryRequestsRateLimited,
+			Description: "Number of GetOrchestrator requests refused by the per IP or per sender rate limits",
+			TagKeys:     append([]tag.Key{census.kRateLimit}, baseTags...),
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "object_store_save_retries",
+			Measure:     census.mObjectStoreSaveRetries,
+			Description: "Number of retried saves to the object stores",
+			TagKeys:     append([]tag.Key{census.kObjectStore}, baseTags...),
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "object_store_save_failures",
+			Measure:     census.mObjectStoreSaveFailures,
+			Description: "Number of saves to the object stores given up, by reason: error, timeout or circuit_open",
+			TagKeys:     append([]tag.Key{census.kObjectStore, census.kErrorCode}, baseTags...),
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "object_store_circuit_open",
+			Measure:     census.mObjectStoreCircuitOpen,
+			Description: "Whether the saves to the object store fail fast because of its previous failures",
+			TagKeys:     append([]tag.Key{census.kObjectStore}, baseTags...),
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "stream_storage_bytes",
+			Measure:     census.mStreamStorageBytes,
+			Description: "Bytes saved to the object stores by the streams, by storage: object or record",
+			TagKeys:     append([]tag.Key{census.kStorage}, baseTagsWithManifestID...),
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "stream_storage_saves",
+			Measure:     census.mStreamStorageBytes,
+			Description: "Number of saves to the object stores by the streams, by storage: object or record",
+			TagKeys:     append([]tag.Key{census.kStorage}, baseTagsWithManifestID...),
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "object_store_healthy",
+			Measure:     census.mObjectStoreHealthy,
+			Description: "Whether the last health check of the object store succeeded",
+			TagKeys:     append([]tag.Key{census.kStorage}, baseTags...),
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "object_store_health_check_failures",
+			Measure:     census.mObjectStoreCheckFailed,
+			Description: "Number of failed health checks of the object stores, by failed step: write, read or delete",
+			TagKeys:     append([]tag.Key{census.kStorage, census.kErrorCode}, baseTags...),
+			Aggregation: view.Count(),
+		},
+
+		// Metrics of the orchestrators on the broadcaster
+		{
+			Name:        "orchestrator_segments_sent",
+			Measure:     census.mOrchSegmentsSent,
+			Description: "Number of segments submitted to the orchestrator",
+			TagKeys:     baseTagsWithOrchInfo,
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "orchestrator_segment_failures",
+			Measure:     census.mOrchSegmentFailures,
+			Description: "Number of segments submitted to the orchestrator that failed, by reason: timeout, refusal or other",
+			TagKeys:     append([]tag.Key{census.kErrorCode}, baseTagsWithOrchInfo...),
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "orchestrator_round_trip_seconds",
+			Measure:     census.mOrchRoundTrip,
+			Description: "Round trip of the segments transcoded by the orchestrator, from their submission to the response, seconds",
+			TagKeys:     baseTagsWithOrchInfo,
+			Aggregation: view.Distribution(0, .25, .5, .75, 1, 1.5, 2, 2.5, 3, 4, 5, 7.5, 10, 15, 20, 30),
+		},
+		{
+			Name:        "orchestrator_ticket_value_sent",
+			Measure:     census.mOrchTicketValueSent,
+			Description: "Ticket value sent to the orchestrator",
+			TagKeys:     baseTagsWithOrchInfo,
+			Aggregation: view.Sum(),
+		},
 
 		// Metrics for pixel accounting
 		{
 			Name:        "mil_pixels_processed",
 			Measure:     census.mMilPixelsProcessed,
 			Description: "Million pixels processed",
-			TagKeys:     baseTagsWithManifestIDAndIP,
+			TagKeys:     append([]tag.Key{census.kCodec, census.kDetection}, baseTagsWithManifestIDAndIP...),
 			Aggregation: view.Sum(),
 		},
 		{
@@ -828,6 +1275,7 @@ func InitCensus(nodeType NodeType, version string) {
 	if err := view.Register(views...); err != nil {
 		glog.Fatalf("Failed to register views: %v", err)
 	}
+	initNativeRegistry(views)
 	registry := rprom.NewRegistry()
 	registry.MustRegister(rprom.NewProcessCollector(rprom.ProcessCollectorOpts{}))
 	registry.MustRegister(rprom.NewGoCollector())
@@ -848,6 +1296,9 @@ func InitCensus(nodeType NodeType, version string) {
 	}
 	if !unitTestMode {
 		go census.timeoutWatcher(ctx)
+		if census.streams != nil {
+			go census.sweepPerStreamMetrics(context.Background(), perStreamViews(views))
+		}
 	}
 	Exporter = pe
 	// init metrics values
@@ -865,33 +1316,25 @@ func addManifestID(ctx context.Context, manifestID string) (context.Context, err
 
 func manifestIDTag(ctx context.Context, others ...tag.Mutator) []tag.Mutator {
 	if PerStreamMetrics {
-		others = append(others, tag.Insert(census.kManifestID, clog.GetManifestID(ctx)))
+		others = append(others, tag.Insert(census.kManifestID, streamManifestID(clog.GetManifestID(ctx))))
 	}
 	return others
 }
 
 func manifestIDTagAndOrchInfo(orchInfo *lpnet.OrchestratorInfo, ctx context.Context, others ...tag.Mutator) []tag.Mutator {
-	others = manifestIDTag(ctx, others...)
-
-	others = append(
-		others,
-		tag.Insert(census.kOrchestratorURI, orchInfo.GetTranscoder()),
-		tag.Insert(census.kOrchestratorAddress, common.BytesToAddress(orchInfo.GetAddress()).String()),
-	)
-
-	return others
+	return append(manifestIDTag(ctx, others...), orchInfoTags(orchInfo)...)
 }
 
 func manifestIDTagStr(manifestID string, others ...tag.Mutator) []tag.Mutator {
 	if PerStreamMetrics {
-		others = append(others, tag.Insert(census.kManifestID, manifestID))
+		others = append(others, tag.Insert(census.kManifestID, streamManifestID(manifestID)))
 	}
 	return others
 }
 
 func manifestIDTagAndIP(ctx context.Context, others ...tag.Mutator) []tag.Mutator {
 	if PerStreamMetrics {
-		others = append(others, tag.Insert(census.kManifestID, clog.GetManifestID(ctx)))
+		others = append(others, tag.Insert(census.kManifestID, streamManifestID(clog.GetManifestID(ctx))))
 	}
 	if ExposeClientIP {
 		ip := clog.GetVal(ctx, clog.ClientIP)
@@ -934,7 +1377,7 @@ func (cen *censusMetricsCounter) successRate() float64 {
 		if r, has := avg.successRate(); has {
 			if PerStreamMetrics {
 				if err := stats.RecordWithTags(census.ctx,
-					[]tag.Mutator{tag.Insert(census.kManifestID, avg.manifestID)}, census.mSuccessRatePerStream.M(r)); err != nil {
+					[]tag.Mutator{tag.Insert(census.kManifestID, streamManifestID(avg.manifestID))}, census.mSuccessRatePerStream.M(r)); err != nil {
 
 					glog.Errorf("Error recording metrics manifestID=%s err=%q", avg.manifestID, err)
 				}
@@ -1213,6 +1656,60 @@ func SegmentDownloaded(ctx context.Context, nonce, seqNo uint64, downloadDur tim
 	}
 }
 
+// RenditionStageTime records the time of a stage of the processing of a rendition of a segment. On the broadcaster,
+// upload is the submission of the source segment to the orchestrator, transcode the time the orchestrator took to
+// respond after that and download the download of the rendition. On the orchestrator, download is the download of the
+// source segment, transcode its transcoding and upload the save of the rendition to the object store. The time of the
+// stages shared by the renditions of a segment is recorded for each of them.
+func RenditionStageTime(stage, profile, resolution string, dur time.Duration) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kStage, stage), tag.Insert(census.kProfile, profile), tag.Insert(census.kResolution, resolution)},
+		census.mRenditionStageTime.M(dur.Seconds())); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// GPUStats records a sample of the usage of a GPU
+func GPUStats(s GPUDeviceStats) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kGPU, s.Device), tag.Insert(census.kGPUName, s.Name)},
+		census.mGPUUtilization.M(int64(s.Utilization)),
+		census.mGPUMemoryUtilization.M(int64(s.MemoryUtilization)),
+		census.mGPUEncoderUtilization.M(int64(s.EncoderUtilization)),
+		census.mGPUDecoderUtilization.M(int64(s.DecoderUtilization)),
+		census.mGPUMemoryUsed.M(int64(s.MemoryUsed)),
+		census.mGPUMemoryTotal.M(int64(s.MemoryTotal)),
+		census.mGPUTemperature.M(int64(s.Temperature))); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// DBQuery records the latency of a database query by operation, e.g. select or insert,
+// and whether it failed because the database was locked
+func DBQuery(operation string, latency time.Duration, busy bool) {
+	ms := []stats.Measurement{census.mDBQueryLatency.M(latency.Seconds())}
+	if busy {
+		ms = append(ms, census.mDBBusyErrors.M(1))
+	}
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kDBOperation, operation)}, ms...); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// DBStorage records the size of the database file and of its write-ahead log
+func DBStorage(fileSize, walSize int64) {
+	stats.Record(census.ctx, census.mDBFileSize.M(fileSize), census.mDBWALSize.M(walSize))
+}
+
+// DBConnectionWaits records the number of queries that waited for the database connection since the last call, and for how long
+func DBConnectionWaits(waits int64, wait time.Duration) {
+	stats.Record(census.ctx, census.mDBConnectionWaits.M(waits), census.mDBConnectionWaitTime.M(wait.Seconds()))
+}
+
 func SegSceneClassificationResult(ctx context.Context, seqNo uint64, class string, prob float64) {
 	clog.V(logLevel).Infof(ctx, "Logging SegSceneClassificationResult... class=%s prob=%v", class, prob)
 	if err := stats.RecordWithTags(census.ctx,
@@ -1324,14 +1821,14 @@ func (cen *censusMetricsCounter) segmentUploadFailed(ctx context.Context, nonce,
 }
 
 func SegmentTranscoded(ctx context.Context, nonce, seqNo uint64, sourceDur time.Duration, transcodeDur time.Duration, profiles string,
-	trusted, verified bool) {
+	job JobType, trusted, verified bool) {
 
 	clog.V(logLevel).Infof(ctx, "Logging SegmentTranscode nonce=%d seqNo=%d dur=%s trusted=%v verified=%v", nonce, seqNo, transcodeDur, trusted, verified)
-	census.segmentTranscoded(nonce, seqNo, sourceDur, transcodeDur, profiles, trusted, verified)
+	census.segmentTranscoded(nonce, seqNo, sourceDur, transcodeDur, profiles, job, trusted, verified)
 }
 
 func (cen *censusMetricsCounter) segmentTranscoded(nonce, seqNo uint64, sourceDur time.Duration, transcodeDur time.Duration,
-	profiles string, trusted, verified bool) {
+	profiles string, job JobType, trusted, verified bool) {
 
 	cen.lock.Lock()
 	defer cen.lock.Unlock()
@@ -1343,7 +1840,7 @@ func (cen *censusMetricsCounter) segmentTranscoded(nonce, seqNo uint64, sourceDu
 	if !trusted {
 		trustedStr = "untrusted"
 	}
-	ctx, err := tag.New(cen.ctx, tag.Insert(cen.kProfiles, profiles), tag.Insert(cen.kVerified, verifiedStr), tag.Insert(cen.kTrusted, trustedStr))
+	ctx, err := tag.New(cen.ctx, append(job.tags(), tag.Insert(cen.kProfiles, profiles), tag.Insert(cen.kVerified, verifiedStr), tag.Insert(cen.kTrusted, trustedStr))...)
 	if err != nil {
 		glog.Error("Error creating context", err)
 		return
@@ -1391,10 +1888,10 @@ func (cen *censusMetricsCounter) sendSuccess() {
 	stats.Record(cen.ctx, cen.mSuccessRate.M(cen.successRate()))
 }
 
-func SegmentFullyTranscoded(ctx context.Context, nonce, seqNo uint64, profiles string, errCode SegmentTranscodeError, orchInfo *lpnet.OrchestratorInfo) {
+func SegmentFullyTranscoded(ctx context.Context, nonce, seqNo uint64, profiles string, job JobType, errCode SegmentTranscodeError, orchInfo *lpnet.OrchestratorInfo) {
 	census.lock.Lock()
 	defer census.lock.Unlock()
-	rctx, err := tag.New(census.ctx, tag.Insert(census.kProfiles, profiles))
+	rctx, err := tag.New(census.ctx, append(job.tags(), tag.Insert(census.kProfiles, profiles))...)
 	if err != nil {
 		glog.Error("Error creating context", err)
 		return
@@ -1483,6 +1980,8 @@ func (cen *censusMetricsCounter) streamEnded(nonce uint64) {
 	defer cen.lock.Unlock()
 	stats.Record(cen.ctx, cen.mStreamEnded.M(1))
 	delete(cen.emergeTimes, nonce)
+	delete(cen.health, nonce)
+	cen.sendHealthScoreMin()
 	if avg, has := cen.success[nonce]; has {
 		if avg.canBeRemoved() {
 			delete(cen.success, nonce)
@@ -1494,6 +1993,34 @@ func (cen *censusMetricsCounter) streamEnded(nonce uint64) {
 	census.sendSuccess()
 }
 
+// StreamHealthScore records the health score of a stream
+func StreamHealthScore(ctx context.Context, nonce uint64, score float64) {
+	census.streamHealthScore(ctx, nonce, score)
+}
+
+func (cen *censusMetricsCounter) streamHealthScore(ctx context.Context, nonce uint64, score float64) {
+	cen.lock.Lock()
+	defer cen.lock.Unlock()
+	cen.health[nonce] = score
+	if PerStreamMetrics {
+		if err := stats.RecordWithTags(cen.ctx, manifestIDTag(ctx), cen.mStreamHealthScore.M(score)); err != nil {
+			clog.Errorf(ctx, "Error recording metrics err=%q", err)
+		}
+	}
+	cen.sendHealthScoreMin()
+}
+
+// sendHealthScoreMin records the lowest health score of the active streams, or 100 if there are none
+func (cen *censusMetricsCounter) sendHealthScoreMin() {
+	min := 100.0
+	for _, score := range cen.health {
+		if score < min {
+			min = score
+		}
+	}
+	stats.Record(cen.ctx, cen.mStreamHealthScoreMin.M(min))
+}
+
 // TicketValueSent records the ticket value sent
 func TicketValueSent(ctx context.Context, value *big.Rat) {
 	if value.Cmp(big.NewRat(0, 1)) <= 0 {
@@ -1619,6 +2146,30 @@ func WinningTicketsRecv(ctx context.Context, sender string, numTickets int) {
 	}
 }
 
+// WinningTicketFaceValueRecv records the face value of the winning tickets received from a sender for a manifestID
+func WinningTicketFaceValueRecv(ctx context.Context, sender string, value *big.Int) {
+	if value.Cmp(big.NewInt(0)) <= 0 {
+		return
+	}
+
+	if err := stats.RecordWithTags(census.ctx,
+		manifestIDTag(ctx, tag.Insert(census.kSender, sender)),
+		census.mWinningFaceValueRecv.M(wei2gwei(value))); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// TicketsRedeemed records the number of winning tickets from a sender that were redeemed
+func TicketsRedeemed(sender string, numTickets int) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kSender, sender)},
+		census.mTicketsRedeemed.M(int64(numTickets))); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
 // ValueRedeemed records the value from redeeming winning tickets
 func ValueRedeemed(sender string, value *big.Int) {
 	if value.Cmp(big.NewInt(0)) <= 0 {
@@ -1643,9 +2194,46 @@ func TicketRedemptionError(sender string) {
 	}
 }
 
-func MilPixelsProcessed(ctx context.Context, milPixels float64) {
+// WinningTicketsPending records the number of winning tickets from a sender that are waiting to be redeemed
+func WinningTicketsPending(sender string, numTickets int) {
 	if err := stats.RecordWithTags(census.ctx,
-		manifestIDTagAndIP(ctx), census.mMilPixelsProcessed.M(milPixels)); err != nil {
+		[]tag.Mutator{tag.Insert(census.kSender, sender)},
+		census.mWinningTicketsPending.M(int64(numTickets))); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// TicketRedemptionsSubmitted records the number of ticket redemption transactions waiting to confirm
+func TicketRedemptionsSubmitted(numTxs int) {
+	if err := stats.RecordWithTags(census.ctx, nil, census.mRedemptionsSubmitted.M(int64(numTxs))); err != nil {
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// TicketRedemptionGasCost records the estimated gas cost of a ticket redemption transaction
+func TicketRedemptionGasCost(sender string, cost *big.Int) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kSender, sender)},
+		census.mRedemptionGasCost.M(wei2gwei(cost))); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// SenderMaxFloat records the max float of a sender
+func SenderMaxFloat(sender string, maxFloat *big.Int) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kSender, sender)},
+		census.mSenderMaxFloat.M(wei2gwei(maxFloat))); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+func MilPixelsProcessed(ctx context.Context, milPixels float64, job JobType) {
+	if err := stats.RecordWithTags(census.ctx,
+		manifestIDTagAndIP(ctx, job.tags()...), census.mMilPixelsProcessed.M(milPixels)); err != nil {
 		clog.Errorf(ctx, "Error recording metrics err=%q", err)
 	}
 }
@@ -1663,6 +2251,173 @@ func MaxGasPrice(maxGasPrice *big.Int) {
 	stats.Record(census.ctx, census.mMaxGasPrice.M(wei2gwei(maxGasPrice)))
 }
 
+// RewardCallError records an error from calling reward
+func RewardCallError() {
+	stats.Record(census.ctx, census.mRewardCallErrors.M(1))
+}
+
+// RewardMissed records a round in which reward could not be called
+func RewardMissed() {
+	stats.Record(census.ctx, census.mRewardMissed.M(1))
+}
+
+// EthBalance records the ETH balance of the node account
+func EthBalance(balance *big.Int) {
+	stats.Record(census.ctx, census.mEthBalance.M(wei2gwei(balance)))
+}
+
+// SafeMode records whether the node is in safe mode
+func SafeMode(enabled bool) {
+	var v int64
+	if enabled {
+		v = 1
+	}
+	stats.Record(census.ctx, census.mSafeMode.M(v))
+}
+
+// EthRPCCall records an Ethereum JSON-RPC call and its latency
+func EthRPCCall(provider, method string, latency time.Duration) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kRPCProvider, provider), tag.Insert(census.kRPCMethod, method)},
+		census.mEthRPCCalls.M(1), census.mEthRPCLatency.M(latency.Seconds())); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// EthRPCError records a failed Ethereum JSON-RPC call. The code is the JSON-RPC error code, the HTTP status of
+// the response or the reason why no response was received
+func EthRPCError(provider, method, code string) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kRPCProvider, provider), tag.Insert(census.kRPCMethod, method), tag.Insert(census.kErrorCode, code)},
+		census.mEthRPCErrors.M(1)); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// DiscoveryRateLimited records a GetOrchestrator request refused by the rate limit, "ip" or "sender"
+func DiscoveryRateLimited(limit string) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kRateLimit, limit)},
+		census.mDiscoveryRequestsRateLimited.M(1)); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// ObjectStoreSaveRetried records a failed save to an object store that is retried
+func ObjectStoreSaveRetried(store string) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kObjectStore, store)},
+		census.mObjectStoreSaveRetries.M(1)); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// ObjectStoreSaveFailed records a save to an object store that is given up. The reason is "error", "timeout" or
+// "circuit_open" when the save isn't tried.
+func ObjectStoreSaveFailed(store, reason string) {
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kObjectStore, store), tag.Insert(census.kErrorCode, reason)},
+		census.mObjectStoreSaveFailures.M(1)); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// ObjectStoreCircuitBreaker records whether the circuit breaker of an object store is open
+func ObjectStoreCircuitBreaker(store string, open bool) {
+	var v int64
+	if open {
+		v = 1
+	}
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kObjectStore, store)},
+		census.mObjectStoreCircuitOpen.M(v)); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// StreamStorageSaved records the bytes of a save to the object store of a stream. The storage is "object" or
+// "record".
+func StreamStorageSaved(manifestID, storage string, bytes int64) {
+	if err := stats.RecordWithTags(census.ctx,
+		manifestIDTagStr(manifestID, tag.Insert(census.kStorage, storage)),
+		census.mStreamStorageBytes.M(bytes)); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// ObjectStoreHealthCheck records the result of the health check of an object store. The storage is "object",
+// "record" or "record_replica_<n>", and the step is the step of the check that failed.
+func ObjectStoreHealthCheck(storage, step string, healthy bool) {
+	var v int64
+	if healthy {
+		v = 1
+	}
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kStorage, storage)},
+		census.mObjectStoreHealthy.M(v)); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+	if healthy {
+		return
+	}
+	if err := stats.RecordWithTags(census.ctx,
+		[]tag.Mutator{tag.Insert(census.kStorage, storage), tag.Insert(census.kErrorCode, step)},
+		census.mObjectStoreCheckFailed.M(1)); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// OrchestratorSegmentSubmitted records a segment submitted to an orchestrator by the broadcaster. The reason of the
+// failure is empty if the segment was transcoded, in which case its round trip is recorded.
+func OrchestratorSegmentSubmitted(orchInfo *lpnet.OrchestratorInfo, roundTrip time.Duration, reason string) {
+	tags := orchInfoTags(orchInfo)
+	ms := []stats.Measurement{census.mOrchSegmentsSent.M(1)}
+	if reason == "" {
+		ms = append(ms, census.mOrchRoundTrip.M(roundTrip.Seconds()))
+	}
+	if err := stats.RecordWithTags(census.ctx, tags, ms...); err != nil {
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+	if reason == "" {
+		return
+	}
+	if err := stats.RecordWithTags(census.ctx,
+		append(tags, tag.Insert(census.kErrorCode, reason)),
+		census.mOrchSegmentFailures.M(1)); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+// OrchestratorTicketValueSent records the value of the tickets sent to an orchestrator by the broadcaster
+func OrchestratorTicketValueSent(orchInfo *lpnet.OrchestratorInfo, value *big.Rat) {
+	if value.Cmp(big.NewRat(0, 1)) <= 0 {
+		return
+	}
+	if err := stats.RecordWithTags(census.ctx,
+		orchInfoTags(orchInfo),
+		census.mOrchTicketValueSent.M(fracwei2gwei(value))); err != nil {
+
+		glog.Errorf("Error recording metrics err=%q", err)
+	}
+}
+
+func orchInfoTags(orchInfo *lpnet.OrchestratorInfo) []tag.Mutator {
+	return []tag.Mutator{
+		tag.Insert(census.kOrchestratorURI, orchInfo.GetTranscoder()),
+		tag.Insert(census.kOrchestratorAddress, common.BytesToAddress(orchInfo.GetAddress()).String()),
+	}
+}
+
 // TranscodingPrice records the last transcoding price
 func TranscodingPrice(sender string, price *big.Rat) {
 	floatWei, ok := price.Float64()
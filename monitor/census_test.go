@@ -9,6 +9,7 @@ import (
 
 	lpnet "github.com/livepeer/go-livepeer/net"
 	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/tag"
 )
 
 func TestAveragerCanBeRemoved(t *testing.T) {
@@ -62,7 +63,7 @@ func TestLastSegmentTimeout(t *testing.T) {
 	if sr := census.successRate(); sr != 1 {
 		t.Fatalf("Success rate should be 1, not %f", sr)
 	}
-	SegmentFullyTranscoded(context.Background(), 1, 1, "ps", "", &lpnet.OrchestratorInfo{})
+	SegmentFullyTranscoded(context.Background(), 1, 1, "ps", JobType{}, "", &lpnet.OrchestratorInfo{})
 	if sr := census.successRate(); sr != 1 {
 		t.Fatalf("Success rate should be 1, not %f", sr)
 	}
@@ -74,7 +75,7 @@ func TestLastSegmentTimeout(t *testing.T) {
 	SegmentEmerged(context.TODO(), 1, 3, 3, 1)
 	SegmentTranscodeFailed(context.TODO(), SegmentTranscodeErrorSessionEnded, 1, 3, fmt.Errorf("some"), true)
 	SegmentEmerged(context.TODO(), 1, 4, 3, 1)
-	SegmentFullyTranscoded(context.Background(), 1, 4, "ps", "", &lpnet.OrchestratorInfo{})
+	SegmentFullyTranscoded(context.Background(), 1, 4, "ps", JobType{}, "", &lpnet.OrchestratorInfo{})
 	if sr := census.successRate(); sr != 0.75 {
 		t.Fatalf("Success rate should be 0.75, not %f", sr)
 	}
@@ -85,7 +86,7 @@ func TestLastSegmentTimeout(t *testing.T) {
 
 	StreamCreated("h1", 2)
 	SegmentEmerged(context.TODO(), 2, 1, 3, 1)
-	SegmentFullyTranscoded(context.Background(), 2, 1, "ps", "", &lpnet.OrchestratorInfo{})
+	SegmentFullyTranscoded(context.Background(), 2, 1, "ps", JobType{}, "", &lpnet.OrchestratorInfo{})
 	SegmentEmerged(context.TODO(), 2, 2, 3, 1)
 	StreamEnded(context.TODO(), 2)
 	if len(census.success) != 1 {
@@ -111,7 +112,7 @@ func TestLastSegmentTimeout(t *testing.T) {
 
 	StreamCreated("h3", 3)
 	SegmentEmerged(context.TODO(), 3, 1, 3, 1)
-	SegmentFullyTranscoded(context.Background(), 3, 1, "ps", "", &lpnet.OrchestratorInfo{})
+	SegmentFullyTranscoded(context.Background(), 3, 1, "ps", JobType{}, "", &lpnet.OrchestratorInfo{})
 	SegmentEmerged(context.TODO(), 3, 2, 3, 1)
 	StreamEnded(context.TODO(), 3)
 	if len(census.success) != 1 {
@@ -124,6 +125,38 @@ func TestLastSegmentTimeout(t *testing.T) {
 	if sr := census.successRate(); sr != 0.5 {
 		t.Fatalf("Success rate should be 0.5, not %f", sr)
 	}
+
+	StreamHealthScore(context.TODO(), 3, 60)
+	StreamHealthScore(context.TODO(), 4, 90)
+	if len(census.health) != 2 {
+		t.Fatalf("Should be two stream health scores, instead have %d", len(census.health))
+	}
+	StreamEnded(context.TODO(), 4)
+	if score, ok := census.health[3]; len(census.health) != 1 || !ok || score != 60 {
+		t.Fatalf("Should only have the health score of stream 3, instead have %v", census.health)
+	}
+}
+
+func TestJobTypeTags(t *testing.T) {
+	assert := assert.New(t)
+	census.kCodec = tag.MustNewKey("codec")
+	census.kDetection = tag.MustNewKey("detection")
+
+	tagValues := func(job JobType) (string, string) {
+		ctx, err := tag.New(context.Background(), job.tags()...)
+		assert.Nil(err)
+		m := tag.FromContext(ctx)
+		codec, _ := m.Value(census.kCodec)
+		detection, _ := m.Value(census.kDetection)
+		return codec, detection
+	}
+
+	codec, detection := tagValues(JobType{Codecs: "H264,HEVC", Detection: true})
+	assert.Equal("H264,HEVC", codec)
+	assert.Equal("on", detection)
+	codec, detection = tagValues(JobType{})
+	assert.Equal("unknown", codec)
+	assert.Equal("off", detection)
 }
 
 func TestWei2Gwei(t *testing.T) {
@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// GPUDeviceStats is a sample of the usage of a GPU
+type GPUDeviceStats struct {
+	// Device is the ID of the device, as in the -nvidia flag
+	Device string
+	Name   string
+	// Utilization, MemoryUtilization, EncoderUtilization and DecoderUtilization are percents over the last sample period of the driver
+	Utilization        uint32
+	MemoryUtilization  uint32
+	EncoderUtilization uint32
+	DecoderUtilization uint32
+	MemoryUsed         uint64
+	MemoryTotal        uint64
+	// Temperature is in degrees Celsius
+	Temperature uint32
+}
+
+// gpuReader reads the usage of the GPUs from their driver
+type gpuReader interface {
+	Read(device string) (GPUDeviceStats, error)
+	Close()
+}
+
+var newGPUReader = newNVMLReader
+
+// StartGPUMetrics records the usage of the NVIDIA GPUs with the device IDs every interval until ctx is done.
+// It returns an error if the NVIDIA Management Library isn't available.
+func StartGPUMetrics(ctx context.Context, devices []string, interval time.Duration) error {
+	r, err := newGPUReader()
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer r.Close()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			for _, s := range readGPUStats(r, devices) {
+				GPUStats(s)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// readGPUStats reads the usage of the devices, skipping the ones that can't be read
+func readGPUStats(r gpuReader, devices []string) []GPUDeviceStats {
+	var res []GPUDeviceStats
+	for _, d := range devices {
+		s, err := r.Read(d)
+		if err != nil {
+			glog.V(logLevel).Infof("Error reading the GPU stats device=%s err=%q", d, err)
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+//go:build linux && cgo
+// +build linux,cgo
+
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+type nvmlReader struct{}
+
+func newNVMLReader() (_ gpuReader, err error) {
+	// NVML panics if libnvidia-ml.so can't be loaded
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("error loading NVML: %v", r)
+		}
+	}()
+	if ret := nvml.Init(); ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("error initializing NVML: %s", nvml.ErrorString(ret))
+	}
+	return nvmlReader{}, nil
+}
+
+func (nvmlReader) Read(device string) (GPUDeviceStats, error) {
+	idx, err := strconv.Atoi(device)
+	if err != nil {
+		return GPUDeviceStats{}, fmt.Errorf("invalid device %q", device)
+	}
+	d, ret := nvml.DeviceGetHandleByIndex(idx)
+	if ret != nvml.SUCCESS {
+		return GPUDeviceStats{}, fmt.Errorf("error getting device: %s", nvml.ErrorString(ret))
+	}
+	s := GPUDeviceStats{Device: device}
+	if s.Name, ret = d.GetName(); ret != nvml.SUCCESS {
+		return s, fmt.Errorf("error getting name: %s", nvml.ErrorString(ret))
+	}
+	util, ret := d.GetUtilizationRates()
+	if ret != nvml.SUCCESS {
+		return s, fmt.Errorf("error getting utilization: %s", nvml.ErrorString(ret))
+	}
+	s.Utilization, s.MemoryUtilization = util.Gpu, util.Memory
+	if s.EncoderUtilization, _, ret = d.GetEncoderUtilization(); ret != nvml.SUCCESS {
+		return s, fmt.Errorf("error getting encoder utilization: %s", nvml.ErrorString(ret))
+	}
+	if s.DecoderUtilization, _, ret = d.GetDecoderUtilization(); ret != nvml.SUCCESS {
+		return s, fmt.Errorf("error getting decoder utilization: %s", nvml.ErrorString(ret))
+	}
+	mem, ret := d.GetMemoryInfo()
+	if ret != nvml.SUCCESS {
+		return s, fmt.Errorf("error getting memory: %s", nvml.ErrorString(ret))
+	}
+	s.MemoryUsed, s.MemoryTotal = mem.Used, mem.Total
+	if s.Temperature, ret = d.GetTemperature(nvml.TEMPERATURE_GPU); ret != nvml.SUCCESS {
+		return s, fmt.Errorf("error getting temperature: %s", nvml.ErrorString(ret))
+	}
+	return s, nil
+}
+
+func (nvmlReader) Close() {
+	nvml.Shutdown()
+}
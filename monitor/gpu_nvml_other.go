@@ -0,0 +1,10 @@
+//go:build !linux || !cgo
+// +build !linux !cgo
+
+package monitor
+
+import "errors"
+
+func newNVMLReader() (gpuReader, error) {
+	return nil, errors.New("the GPU metrics are only supported on Linux")
+}
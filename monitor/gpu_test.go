@@ -0,0 +1,34 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubGPUReader map[string]GPUDeviceStats
+
+func (r stubGPUReader) Read(device string) (GPUDeviceStats, error) {
+	s, ok := r[device]
+	if !ok {
+		return GPUDeviceStats{}, errors.New("no device")
+	}
+	return s, nil
+}
+
+func (r stubGPUReader) Close() {}
+
+func TestReadGPUStats(t *testing.T) {
+	assert := assert.New(t)
+
+	r := stubGPUReader{
+		"0": {Device: "0", Name: "Tesla T4", Utilization: 40, EncoderUtilization: 70, MemoryUsed: 1 << 30, Temperature: 60},
+		"2": {Device: "2", Name: "Tesla T4", Utilization: 10},
+	}
+	// Devices that can't be read are skipped
+	s := readGPUStats(r, []string{"0", "1", "2"})
+	assert.Equal([]GPUDeviceStats{r["0"], r["2"]}, s)
+
+	assert.Empty(readGPUStats(r, []string{"1"}))
+}
@@ -0,0 +1,173 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	rprom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opencensus.io/stats/view"
+)
+
+// nativeNamespace is the prefix of the names of the native Prometheus metrics, as the ones of the OpenCensus exporter
+const nativeNamespace = "livepeer"
+
+var (
+	nativeRegistry   *rprom.Registry
+	nativeRegistryMu sync.Mutex
+)
+
+// NativeHandler returns the handler of the native Prometheus /metrics endpoint. The metrics are the same as the ones
+// of the OpenCensus exporter, with the naming conventions of Prometheus: counters end with _total, gauges and
+// histograms don't, and the durations are in seconds. It returns nil if the metrics aren't initialized.
+func NativeHandler() http.Handler {
+	nativeRegistryMu.Lock()
+	defer nativeRegistryMu.Unlock()
+	if nativeRegistry == nil {
+		return nil
+	}
+	return promhttp.HandlerFor(nativeRegistry, promhttp.HandlerOpts{ErrorLog: nativeErrorLog{}})
+}
+
+// initNativeRegistry creates the registry of the native metrics of the views
+func initNativeRegistry(views []*view.View) {
+	registry := rprom.NewRegistry()
+	registry.MustRegister(rprom.NewProcessCollector(rprom.ProcessCollectorOpts{}))
+	registry.MustRegister(rprom.NewGoCollector())
+	registry.MustRegister(newViewCollector(views))
+	nativeRegistryMu.Lock()
+	nativeRegistry = registry
+	nativeRegistryMu.Unlock()
+}
+
+type nativeErrorLog struct{}
+
+func (nativeErrorLog) Println(v ...interface{}) {
+	glog.Errorln(v...)
+}
+
+// nativeMetric is the Prometheus metric of a view
+type nativeMetric struct {
+	view      *view.View
+	desc      *rprom.Desc
+	valueType rprom.ValueType
+	// scale converts the values to the base unit of the metric
+	scale float64
+}
+
+// viewCollector collects the data of the OpenCensus views as native Prometheus metrics. It's an unchecked collector,
+// the descriptions of the metrics being known once the views are registered.
+type viewCollector struct {
+	metrics []*nativeMetric
+}
+
+func newViewCollector(views []*view.View) *viewCollector {
+	c := &viewCollector{}
+	names := make(map[string]bool)
+	for _, v := range views {
+		name, scale := nativeName(v)
+		if names[name] {
+			glog.Errorf("Skipping the native metric of the view name=%s, another view has the same native name=%s", v.Name, name)
+			continue
+		}
+		names[name] = true
+		labels := make([]string, len(v.TagKeys))
+		for i, k := range v.TagKeys {
+			labels[i] = k.Name()
+		}
+		m := &nativeMetric{view: v, desc: rprom.NewDesc(name, v.Description, labels, nil), scale: scale}
+		switch v.Aggregation.Type {
+		case view.AggTypeCount, view.AggTypeSum:
+			m.valueType = rprom.CounterValue
+		default:
+			m.valueType = rprom.GaugeValue
+		}
+		c.metrics = append(c.metrics, m)
+	}
+	return c
+}
+
+func (c *viewCollector) Describe(ch chan<- *rprom.Desc) {
+}
+
+func (c *viewCollector) Collect(ch chan<- rprom.Metric) {
+	for _, m := range c.metrics {
+		rows, err := view.RetrieveData(m.view.Name)
+		if err != nil {
+			continue
+		}
+		for _, row := range rows {
+			values := make([]string, len(m.view.TagKeys))
+			for i, k := range m.view.TagKeys {
+				for _, t := range row.Tags {
+					if t.Key == k {
+						values[i] = t.Value
+						break
+					}
+				}
+			}
+			metric, err := m.metric(row.Data, values)
+			if err != nil {
+				glog.Errorf("Error collecting native metric name=%s err=%q", m.view.Name, err)
+				continue
+			}
+			ch <- metric
+		}
+	}
+}
+
+func (m *nativeMetric) metric(data view.AggregationData, values []string) (rprom.Metric, error) {
+	switch d := data.(type) {
+	case *view.CountData:
+		return rprom.NewConstMetric(m.desc, m.valueType, float64(d.Value), values...)
+	case *view.SumData:
+		return rprom.NewConstMetric(m.desc, m.valueType, d.Value*m.scale, values...)
+	case *view.LastValueData:
+		return rprom.NewConstMetric(m.desc, m.valueType, d.Value*m.scale, values...)
+	case *view.DistributionData:
+		buckets := make(map[float64]uint64, len(m.view.Aggregation.Buckets))
+		var count uint64
+		for i, bound := range m.view.Aggregation.Buckets {
+			if i < len(d.CountPerBucket) {
+				count += uint64(d.CountPerBucket[i])
+			}
+			buckets[bound*m.scale] = count
+		}
+		return rprom.NewConstHistogram(m.desc, uint64(d.Count), d.Sum()*m.scale, buckets, values...)
+	}
+	return nil, fmt.Errorf("unknown aggregation %T", data)
+}
+
+// nativeName returns the Prometheus name of the metric of a view, and the scale converting its values to the base
+// unit of the name
+func nativeName(v *view.View) (string, float64) {
+	name := strings.TrimSuffix(v.Name, "_total")
+	scale := 1.0
+	if v.Aggregation.Type != view.AggTypeCount {
+		// The unit of the values, not of the counts of the values
+		switch v.Measure.Unit() {
+		case "ms":
+			name = strings.TrimSuffix(strings.TrimSuffix(name, "_milliseconds"), "_ms")
+			name = withSuffix(name, "_seconds")
+			scale = 0.001
+		case "sec", "s":
+			name = withSuffix(name, "_seconds")
+		case "By":
+			name = withSuffix(name, "_bytes")
+		}
+	}
+	if v.Aggregation.Type == view.AggTypeCount || v.Aggregation.Type == view.AggTypeSum {
+		name += "_total"
+	}
+	return nativeNamespace + "_" + name, scale
+}
+
+func withSuffix(name, suffix string) string {
+	if strings.HasSuffix(name, suffix) {
+		return name
+	}
+	return name + suffix
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	rprom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestNativeName(t *testing.T) {
+	assert := assert.New(t)
+
+	name := func(viewName, unit string, agg *view.Aggregation) (string, float64) {
+		return nativeName(&view.View{Name: viewName, Measure: stats.Int64(viewName, "", unit), Aggregation: agg})
+	}
+	// Counters end with _total
+	n, _ := name("segment_source_appeared_total", "tot", view.Count())
+	assert.Equal("livepeer_segment_source_appeared_total", n)
+	n, _ = name("orchestrator_swaps", "tot", view.Count())
+	assert.Equal("livepeer_orchestrator_swaps_total", n)
+	n, _ = name("stream_storage_bytes", "By", view.Sum())
+	assert.Equal("livepeer_stream_storage_bytes_total", n)
+	n, _ = name("stream_storage_saves", "By", view.Count())
+	assert.Equal("livepeer_stream_storage_saves_total", n)
+	// Gauges don't
+	n, _ = name("current_sessions_total", "tot", view.LastValue())
+	assert.Equal("livepeer_current_sessions", n)
+	// Durations are in seconds
+	n, scale := name("auth_webhook_time_milliseconds", "ms", view.Distribution(0, 100))
+	assert.Equal("livepeer_auth_webhook_time_seconds", n)
+	assert.Equal(0.001, scale)
+	n, scale = name("recording_save_latency", "sec", view.Distribution(0, 1))
+	assert.Equal("livepeer_recording_save_latency_seconds", n)
+	assert.Equal(1.0, scale)
+}
+
+func TestViewCollector(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	kStore := tag.MustNewKey("test_store")
+	mBytes := stats.Int64("test_native_bytes", "", "By")
+	mSessions := stats.Int64("test_native_sessions", "", "tot")
+	mLatency := stats.Float64("test_native_latency_ms", "", "ms")
+	views := []*view.View{
+		{Name: "test_native_bytes", Measure: mBytes, TagKeys: []tag.Key{kStore}, Aggregation: view.Sum()},
+		{Name: "test_native_saves", Measure: mBytes, TagKeys: []tag.Key{kStore}, Aggregation: view.Count()},
+		{Name: "test_native_sessions_total", Measure: mSessions, Aggregation: view.LastValue()},
+		{Name: "test_native_latency_ms", Measure: mLatency, Aggregation: view.Distribution(0, 100, 500)},
+		// Same native name as the first one
+		{Name: "test_native_bytes_total", Measure: mBytes, Aggregation: view.Sum()},
+	}
+	require.Nil(view.Register(views...))
+	defer view.Unregister(views...)
+	registry := rprom.NewRegistry()
+	c := newViewCollector(views)
+	assert.Len(c.metrics, 4)
+	registry.MustRegister(c)
+
+	ctx := context.Background()
+	require.Nil(stats.RecordWithTags(ctx, []tag.Mutator{tag.Insert(kStore, "record")}, mBytes.M(100)))
+	require.Nil(stats.RecordWithTags(ctx, []tag.Mutator{tag.Insert(kStore, "record")}, mBytes.M(50)))
+	stats.Record(ctx, mSessions.M(10))
+	stats.Record(ctx, mLatency.M(250))
+
+	mfs, err := registry.Gather()
+	require.Nil(err)
+	families := make(map[string]*dto.MetricFamily)
+	for _, mf := range mfs {
+		families[mf.GetName()] = mf
+	}
+	mf := families["livepeer_test_native_bytes_total"]
+	require.NotNil(mf)
+	assert.Equal(dto.MetricType_COUNTER, mf.GetType())
+	assert.Equal(150.0, mf.Metric[0].GetCounter().GetValue())
+	assert.Equal("test_store", mf.Metric[0].Label[0].GetName())
+	assert.Equal("record", mf.Metric[0].Label[0].GetValue())
+	assert.Equal(2.0, families["livepeer_test_native_saves_total"].Metric[0].GetCounter().GetValue())
+
+	mf = families["livepeer_test_native_sessions"]
+	require.NotNil(mf)
+	assert.Equal(dto.MetricType_GAUGE, mf.GetType())
+	assert.Equal(10.0, mf.Metric[0].GetGauge().GetValue())
+
+	mf = families["livepeer_test_native_latency_seconds"]
+	require.NotNil(mf)
+	assert.Equal(dto.MetricType_HISTOGRAM, mf.GetType())
+	h := mf.Metric[0].GetHistogram()
+	assert.Equal(uint64(1), h.GetSampleCount())
+	assert.InDelta(0.25, h.GetSampleSum(), 1e-9)
+	buckets := make(map[float64]uint64)
+	for _, b := range h.Bucket {
+		buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+	}
+	// OpenCensus drops the bounds that aren't positive
+	assert.Equal(map[float64]uint64{0.1: 0, 0.5: 1}, buckets)
+}
@@ -0,0 +1,250 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/snappy"
+	rprom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Formats the metrics are pushed in
+const (
+	PushFormatPushgateway = "pushgateway"
+	PushFormatRemoteWrite = "remote_write"
+)
+
+// pushJob is the job label of the pushed metrics, the instance label being the node ID
+const pushJob = "livepeer"
+
+var pushTimeout = 10 * time.Second
+
+// metricsPusher pushes the gathered metrics to an endpoint
+type metricsPusher interface {
+	push(ctx context.Context) error
+}
+
+// StartMetricsPush pushes the native Prometheus metrics to uri every interval until ctx is done, for the nodes that
+// can't be scraped, e.g. behind a NAT. format is either a Prometheus Pushgateway, which the metrics are deleted from
+// once ctx is done, or a remote-write endpoint. The credentials of uri, if any, are sent with basic auth.
+func StartMetricsPush(ctx context.Context, uri, format string, interval time.Duration) error {
+	nativeRegistryMu.Lock()
+	gatherer := nativeRegistry
+	nativeRegistryMu.Unlock()
+	if gatherer == nil {
+		return errors.New("metrics not initialized")
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid metrics push URL %q", uri)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("invalid metrics push interval %s", interval)
+	}
+	var pusher metricsPusher
+	switch format {
+	case PushFormatPushgateway:
+		pusher = newPushgatewayPusher(u, gatherer)
+	case PushFormatRemoteWrite:
+		pusher = newRemoteWritePusher(u, gatherer)
+	default:
+		return fmt.Errorf("invalid metrics push format %q", format)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				if p, ok := pusher.(*pushgatewayPusher); ok {
+					if err := p.delete(); err != nil {
+						glog.Errorf("Error deleting the metrics from the Pushgateway url=%s err=%q", u.Redacted(), err)
+					}
+				}
+				return
+			case <-ticker.C:
+			}
+			pushCtx, cancel := context.WithTimeout(ctx, pushTimeout)
+			if err := pusher.push(pushCtx); err != nil {
+				glog.Errorf("Error pushing the metrics url=%s format=%s err=%q", u.Redacted(), format, err)
+			}
+			cancel()
+		}
+	}()
+	return nil
+}
+
+// pushInstance returns the instance label of the pushed metrics
+func pushInstance() string {
+	if NodeID != "" {
+		return NodeID
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+type pushgatewayPusher struct {
+	pusher *push.Pusher
+}
+
+func newPushgatewayPusher(u *url.URL, gatherer rprom.Gatherer) *pushgatewayPusher {
+	endpoint := *u
+	endpoint.User = nil
+	p := push.New(endpoint.String(), pushJob).Gatherer(gatherer).Grouping("instance", pushInstance())
+	if u.User != nil {
+		password, _ := u.User.Password()
+		p = p.BasicAuth(u.User.Username(), password)
+	}
+	return &pushgatewayPusher{pusher: p}
+}
+
+// push replaces the metrics of the node on the Pushgateway
+func (p *pushgatewayPusher) push(ctx context.Context) error {
+	return p.pusher.PushContext(ctx)
+}
+
+func (p *pushgatewayPusher) delete() error {
+	return p.pusher.Delete()
+}
+
+type remoteWritePusher struct {
+	url      *url.URL
+	gatherer rprom.Gatherer
+	client   *http.Client
+}
+
+func newRemoteWritePusher(u *url.URL, gatherer rprom.Gatherer) *remoteWritePusher {
+	return &remoteWritePusher{url: u, gatherer: gatherer, client: &http.Client{}}
+}
+
+// push sends the samples of the metrics with the version 1 of the remote-write protocol
+func (p *remoteWritePusher) push(ctx context.Context) error {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	body := snappy.Encode(nil, remoteWriteRequest(families, time.Now(), pushInstance()))
+	endpoint := *p.url
+	endpoint.User = nil
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if p.url.User != nil {
+		password, _ := p.url.User.Password()
+		req.SetBasicAuth(p.url.User.Username(), password)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status=%d body=%q", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+type remoteWriteLabel struct {
+	name, value string
+}
+
+// remoteWriteRequest returns the WriteRequest protobuf of the samples of the metric families at ts, labeled with the
+// job and the instance like a scrape would
+func remoteWriteRequest(families []*dto.MetricFamily, ts time.Time, instance string) []byte {
+	millis := ts.UnixNano() / int64(time.Millisecond)
+	var req []byte
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			labels := []remoteWriteLabel{{"job", pushJob}, {"instance", instance}}
+			for _, l := range m.GetLabel() {
+				labels = append(labels, remoteWriteLabel{l.GetName(), l.GetValue()})
+			}
+			series := func(name string, value float64, extra ...remoteWriteLabel) {
+				ls := append(append([]remoteWriteLabel{{"__name__", name}}, labels...), extra...)
+				req = protowire.AppendTag(req, 1, protowire.BytesType)
+				req = protowire.AppendBytes(req, remoteWriteTimeSeries(ls, value, millis))
+			}
+			name := f.GetName()
+			switch f.GetType() {
+			case dto.MetricType_COUNTER:
+				series(name, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				series(name, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				series(name, m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				inf := false
+				for _, b := range h.GetBucket() {
+					inf = math.IsInf(b.GetUpperBound(), 1)
+					series(name+"_bucket", float64(b.GetCumulativeCount()), remoteWriteLabel{"le", formatFloat(b.GetUpperBound())})
+				}
+				if !inf {
+					series(name+"_bucket", float64(h.GetSampleCount()), remoteWriteLabel{"le", "+Inf"})
+				}
+				series(name+"_sum", h.GetSampleSum())
+				series(name+"_count", float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					series(name, q.GetValue(), remoteWriteLabel{"quantile", formatFloat(q.GetQuantile())})
+				}
+				series(name+"_sum", s.GetSampleSum())
+				series(name+"_count", float64(s.GetSampleCount()))
+			}
+		}
+	}
+	return req
+}
+
+// remoteWriteTimeSeries returns the TimeSeries protobuf of a sample, with its labels sorted by name as required
+func remoteWriteTimeSeries(labels []remoteWriteLabel, value float64, millis int64) []byte {
+	sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+	var ts []byte
+	for _, l := range labels {
+		var label []byte
+		label = protowire.AppendTag(label, 1, protowire.BytesType)
+		label = protowire.AppendString(label, l.name)
+		label = protowire.AppendTag(label, 2, protowire.BytesType)
+		label = protowire.AppendString(label, l.value)
+		ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, label)
+	}
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, uint64(millis))
+	ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+	return protowire.AppendBytes(ts, sample)
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
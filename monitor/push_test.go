@@ -0,0 +1,168 @@
+package monitor
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	rprom "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func newPushTestRegistry() *rprom.Registry {
+	registry := rprom.NewRegistry()
+	gauge := rprom.NewGaugeVec(rprom.GaugeOpts{Name: "livepeer_current_sessions", Help: "sessions"}, []string{"node_type"})
+	gauge.WithLabelValues("orch").Set(3)
+	hist := rprom.NewHistogram(rprom.HistogramOpts{Name: "livepeer_latency_seconds", Help: "latency", Buckets: []float64{0.5, 1}})
+	hist.Observe(0.2)
+	hist.Observe(2)
+	registry.MustRegister(gauge, hist)
+	return registry
+}
+
+// consumeMessage returns the fields of the protobuf message b by number
+func consumeMessage(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	fields := make(map[protowire.Number][][]byte)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.True(t, n > 0)
+		b = b[n:]
+		var v []byte
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			v = b[:n]
+		case protowire.VarintType:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			v = b[:n]
+		}
+		require.True(t, n > 0)
+		b = b[n:]
+		fields[num] = append(fields[num], v)
+	}
+	return fields
+}
+
+func TestRemoteWritePusher(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var req *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(strings.Replace(server.URL, "http://", "http://user:pass@", 1) + "/api/v1/write")
+	require.Nil(err)
+	NodeID = "node-1"
+	defer func() { NodeID = "" }()
+	require.Nil(newRemoteWritePusher(u, newPushTestRegistry()).push(context.Background()))
+
+	assert.Equal("/api/v1/write", req.URL.Path)
+	assert.Equal("snappy", req.Header.Get("Content-Encoding"))
+	assert.Equal("application/x-protobuf", req.Header.Get("Content-Type"))
+	user, pass, ok := req.BasicAuth()
+	assert.True(ok)
+	assert.Equal("user", user)
+	assert.Equal("pass", pass)
+
+	decoded, err := snappy.Decode(nil, body)
+	require.Nil(err)
+	series := make(map[string]float64)
+	for _, ts := range consumeMessage(t, decoded)[1] {
+		fields := consumeMessage(t, ts)
+		var labels []string
+		for _, l := range fields[1] {
+			label := consumeMessage(t, l)
+			labels = append(labels, string(label[1][0])+"="+string(label[2][0]))
+		}
+		assert.True(sort.StringsAreSorted(labels))
+		require.Len(fields[2], 1)
+		sample := consumeMessage(t, fields[2][0])
+		bits, _ := protowire.ConsumeFixed64(sample[1][0])
+		millis, _ := protowire.ConsumeVarint(sample[2][0])
+		assert.InDelta(time.Now().UnixNano()/int64(time.Millisecond), int64(millis), 60000)
+		series[strings.Join(labels, ",")] = math.Float64frombits(bits)
+	}
+	labels := "instance=node-1,job=livepeer"
+	assert.Equal(map[string]float64{
+		"__name__=livepeer_current_sessions," + labels + ",node_type=orch": 3,
+		"__name__=livepeer_latency_seconds_bucket," + labels + ",le=0.5":   1,
+		"__name__=livepeer_latency_seconds_bucket," + labels + ",le=1":     1,
+		"__name__=livepeer_latency_seconds_bucket," + labels + ",le=+Inf":  2,
+		"__name__=livepeer_latency_seconds_sum," + labels:                  2.2,
+		"__name__=livepeer_latency_seconds_count," + labels:                2,
+	}, series)
+
+	// the errors of the endpoint are returned
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "out of order sample", http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	u, err = url.Parse(failing.URL)
+	require.Nil(err)
+	err = newRemoteWritePusher(u, newPushTestRegistry()).push(context.Background())
+	assert.EqualError(err, `unexpected status=400 body="out of order sample\n"`)
+}
+
+func TestPushgatewayPusher(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var methods, paths, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		paths = append(paths, r.URL.Path)
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.Nil(err)
+	NodeID = "node-1"
+	defer func() { NodeID = "" }()
+	p := newPushgatewayPusher(u, newPushTestRegistry())
+	require.Nil(p.push(context.Background()))
+	require.Nil(p.delete())
+	assert.Equal([]string{http.MethodPut, http.MethodDelete}, methods)
+	assert.Equal([]string{"/metrics/job/livepeer/instance/node-1", "/metrics/job/livepeer/instance/node-1"}, paths)
+	assert.Contains(bodies[0], "livepeer_current_sessions")
+	assert.Empty(bodies[1])
+}
+
+func TestStartMetricsPush_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	nativeRegistryMu.Lock()
+	old := nativeRegistry
+	nativeRegistry = newPushTestRegistry()
+	nativeRegistryMu.Unlock()
+	defer func() {
+		nativeRegistryMu.Lock()
+		nativeRegistry = old
+		nativeRegistryMu.Unlock()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.EqualError(StartMetricsPush(ctx, "ftp://host", PushFormatPushgateway, time.Second), `invalid metrics push URL "ftp://host"`)
+	assert.EqualError(StartMetricsPush(ctx, "http://host", "graphite", time.Second), `invalid metrics push format "graphite"`)
+	assert.EqualError(StartMetricsPush(ctx, "http://host", PushFormatRemoteWrite, 0), "invalid metrics push interval 0s")
+}
@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+// MaxStreamsPerStreamMetrics is the max number of streams labeled with their manifest ID in the per-stream metrics,
+// the metrics of the other streams being labeled with otherManifestID. 0 for no limit.
+var MaxStreamsPerStreamMetrics int
+
+// PerStreamMetricsTTL is how long the per-stream metrics of a stream are kept after it was last recorded. 0 to keep
+// them forever.
+var PerStreamMetricsTTL time.Duration
+
+// otherManifestID is the manifest ID label of the streams over MaxStreamsPerStreamMetrics
+const otherManifestID = "other"
+
+var perStreamMetricsSweepInterval = time.Minute
+
+// streamLabels limits the manifest IDs labeling the per-stream metrics and expires the ones not recorded anymore
+type streamLabels struct {
+	max int
+	ttl time.Duration
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+	overflow bool
+}
+
+func newStreamLabels(max int, ttl time.Duration) *streamLabels {
+	return &streamLabels{max: max, ttl: ttl, lastSeen: make(map[string]time.Time)}
+}
+
+// label returns the manifest ID label of the metrics of the stream recorded at now
+func (s *streamLabels) label(manifestID string, now time.Time) string {
+	if s == nil {
+		return manifestID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.lastSeen[manifestID]; !ok && s.max > 0 && len(s.lastSeen) >= s.max {
+		if !s.overflow {
+			glog.Warningf("Reached the max number of streams in the per-stream metrics max=%d, labeling the other streams with manifest_id=%s", s.max, otherManifestID)
+			s.overflow = true
+		}
+		return otherManifestID
+	}
+	s.lastSeen[manifestID] = now
+	return manifestID
+}
+
+// expire forgets the streams not recorded for the TTL at now, returning how many were. Forgetting streams resets the
+// per-stream metrics of all the streams, so the streams are only forgotten when the label set has to shrink: when
+// streams are labeled with otherManifestID, or when at least half of the labeled streams are not recorded anymore.
+func (s *streamLabels) expire(now time.Time) int {
+	if s.ttl <= 0 {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var stale []string
+	for mid, seen := range s.lastSeen {
+		if now.Sub(seen) >= s.ttl {
+			stale = append(stale, mid)
+		}
+	}
+	if len(stale) == 0 || !s.overflow && 2*len(stale) < len(s.lastSeen) {
+		return 0
+	}
+	for _, mid := range stale {
+		delete(s.lastSeen, mid)
+	}
+	s.overflow = false
+	return len(stale)
+}
+
+func (s *streamLabels) tracked() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.lastSeen)
+}
+
+// streamManifestID returns the manifest ID label of the per-stream metrics of the stream
+func streamManifestID(manifestID string) string {
+	return census.streams.label(manifestID, time.Now())
+}
+
+// sweepPerStreamMetrics expires the per-stream metrics of the streams not recorded for PerStreamMetricsTTL, until
+// ctx is done. OpenCensus can't delete the series of a single stream, so the per-stream views of all the streams,
+// active or not, are reset when streams expire: their counters restart from zero, which rate() and increase()
+// handle, and the gauges of the active streams are back on their next record.
+func (cen *censusMetricsCounter) sweepPerStreamMetrics(ctx context.Context, views []*view.View) {
+	interval := perStreamMetricsSweepInterval
+	if cen.streams.ttl > 0 && cen.streams.ttl < interval {
+		interval = cen.streams.ttl
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if expired := cen.streams.expire(time.Now()); expired > 0 {
+			glog.V(logLevel).Infof("Expiring the per-stream metrics of streams=%d", expired)
+			view.Unregister(views...)
+			if err := view.Register(views...); err != nil {
+				glog.Errorf("Error registering the per-stream views err=%q", err)
+			}
+		}
+		if err := stats.RecordWithTags(cen.ctx, nil, cen.mPerStreamMetricsStreams.M(int64(cen.streams.tracked()))); err != nil {
+			glog.Errorf("Error recording metrics err=%q", err)
+		}
+	}
+}
+
+// perStreamViews returns the views labeled with the manifest ID
+func perStreamViews(views []*view.View) []*view.View {
+	var res []*view.View
+	for _, v := range views {
+		for _, k := range v.TagKeys {
+			if k == census.kManifestID {
+				res = append(res, v)
+				break
+			}
+		}
+	}
+	return res
+}
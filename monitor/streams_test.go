@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestStreamLabels(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Now()
+	s := newStreamLabels(2, time.Minute)
+	assert.Equal("a", s.label("a", start))
+	assert.Equal("b", s.label("b", start.Add(30*time.Second)))
+	// the streams over the limit are labeled together
+	assert.Equal(otherManifestID, s.label("c", start.Add(30*time.Second)))
+	assert.Equal("a", s.label("a", start.Add(40*time.Second)))
+	assert.Equal(2, s.tracked())
+
+	// the streams not recorded for the TTL expire, freeing their slot
+	assert.Equal(0, s.expire(start.Add(time.Minute)))
+	assert.Equal(1, s.expire(start.Add(90*time.Second)))
+	assert.Equal("c", s.label("c", start.Add(90*time.Second)))
+	assert.Equal(2, s.tracked())
+
+	// without limit, the streams only expire once at least half of them are not recorded anymore
+	s = newStreamLabels(0, time.Minute)
+	s.label("a", start)
+	s.label("b", start.Add(30*time.Second))
+	s.label("c", start.Add(45*time.Second))
+	assert.Equal(0, s.expire(start.Add(70*time.Second)))
+	assert.Equal(3, s.tracked())
+	assert.Equal(2, s.expire(start.Add(90*time.Second)))
+	assert.Equal(1, s.tracked())
+
+	// no expiry without TTL
+	s = newStreamLabels(0, 0)
+	assert.Equal("a", s.label("a", start))
+	assert.Equal(0, s.expire(start.Add(time.Hour)))
+
+	// no limit nor expiry without streamLabels
+	var nilLabels *streamLabels
+	assert.Equal("a", nilLabels.label("a", start))
+}
+
+func TestPerStreamViews(t *testing.T) {
+	assert := assert.New(t)
+
+	census.kManifestID = tag.MustNewKey("manifest_id")
+	kOther := tag.MustNewKey("other")
+	m := stats.Int64("test_per_stream_views", "test", "tot")
+	perStream := &view.View{Name: "per_stream", Measure: m, TagKeys: []tag.Key{kOther, census.kManifestID}, Aggregation: view.Count()}
+	global := &view.View{Name: "global", Measure: m, TagKeys: []tag.Key{kOther}, Aggregation: view.Count()}
+	assert.Equal([]*view.View{perStream}, perStreamViews([]*view.View{global, perStream}))
+}
@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Names of the spans of a segment, from its submission by the broadcaster to its transcoding by the transcoder
+const (
+	SpanTranscodeSegment  = "broadcaster.transcodeSegment"
+	SpanSubmitSegment     = "broadcaster.submitSegment"
+	SpanServeSegment      = "orchestrator.serveSegment"
+	SpanTranscode         = "orchestrator.transcode"
+	SpanTranscodeRemote   = "orchestrator.remoteTranscode"
+	SpanTranscoderSegment = "transcoder.transcode"
+	SpanTranscoderResults = "transcoder.sendResults"
+)
+
+// Attributes of the spans
+const (
+	AttrManifestID   = "manifest_id"
+	AttrSeqNo        = "seq_no"
+	AttrOrchestrator = "orchestrator"
+	AttrTranscoder   = "transcoder"
+	AttrTaskID       = "task_id"
+)
+
+var (
+	tracer = trace.NewNoopTracerProvider().Tracer("")
+	// propagator is the W3C trace context, which is relayed by the nodes that don't trace themselves
+	propagator = propagation.TraceContext{}
+)
+
+// InitTracing exports the spans of the node to the OTLP/HTTP endpoint, such as http://localhost:4318. The traces
+// started by the node are sampled at the ratio, the ones started by other nodes as sampled by them. It returns the
+// function flushing the spans on shutdown.
+func InitTracing(endpoint string, sampleRatio float64, nodeType NodeType, version string) (func(context.Context) error, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid tracing endpoint %q", endpoint)
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	switch u.Scheme {
+	case "http":
+		opts = append(opts, otlptracehttp.WithInsecure())
+	case "https":
+	default:
+		return nil, fmt.Errorf("invalid tracing endpoint %q, the scheme must be http or https", endpoint)
+	}
+	if p := strings.TrimSuffix(u.Path, "/"); p != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(p))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	res := resource.NewSchemaless(
+		attribute.String("service.name", "livepeer"),
+		attribute.String("service.version", version),
+		attribute.String("service.instance.id", NodeID),
+		attribute.String("livepeer.node_type", string(nodeType)),
+	)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	tracer = provider.Tracer("github.com/livepeer/go-livepeer")
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span, a child of the span of ctx if any. The span has to be ended with EndSpan.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartSegmentSpan starts a span of the processing of a segment of a stream
+func StartSegmentSpan(ctx context.Context, name, manifestID string, seqNo uint64, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String(AttrManifestID, manifestID), attribute.Int64(AttrSeqNo, int64(seqNo)))
+	return StartSpan(ctx, name, attrs...)
+}
+
+// EndSpan ends a span, with an error status if err isn't nil
+func EndSpan(span trace.Span, err error) {
+	setSpanError(span, err)
+	span.End()
+}
+
+// SetSpanError sets the error status of the span of ctx if err isn't nil
+func SetSpanError(ctx context.Context, err error) {
+	setSpanError(trace.SpanFromContext(ctx), err)
+}
+
+func setSpanError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// WithSpan returns ctx with the span of spanCtx, for the contexts cloned from others that don't keep their values
+func WithSpan(ctx, spanCtx context.Context) context.Context {
+	return trace.ContextWithSpan(ctx, trace.SpanFromContext(spanCtx))
+}
+
+// InjectTraceHeaders sets the headers of the trace context of ctx in a request to another node
+func InjectTraceHeaders(ctx context.Context, h http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// ExtractTraceHeaders returns ctx with the trace context of the headers of a request from another node
+func ExtractTraceHeaders(ctx context.Context, h http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(h))
+}
+
+// TraceContext returns the trace context of ctx to send to another node in a message, nil if there isn't any
+func TraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// ExtractTraceContext returns ctx with the trace context of a message from another node
+func ExtractTraceContext(ctx context.Context, traceCtx map[string]string) context.Context {
+	return propagator.Extract(ctx, propagation.MapCarrier(traceCtx))
+}
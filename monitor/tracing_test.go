@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInitTracing_InvalidEndpoint(t *testing.T) {
+	assert := assert.New(t)
+	for _, endpoint := range []string{"localhost:4318", "grpc://localhost:4317", "http://", "%"} {
+		_, err := InitTracing(endpoint, 1, Broadcaster, "test")
+		assert.Error(err, endpoint)
+	}
+}
+
+func TestTracing_Propagation(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer func(t trace.Tracer) { tracer = t }(tracer)
+	tracer = provider.Tracer("test")
+
+	// Broadcaster
+	bctx, bspan := StartSegmentSpan(context.Background(), SpanSubmitSegment, "mid", 3,
+		attribute.String(AttrOrchestrator, "https://o:8935"))
+	h := http.Header{}
+	InjectTraceHeaders(WithSpan(context.Background(), bctx), h)
+	require.NotEmpty(h.Get("traceparent"))
+
+	// Orchestrator
+	octx, ospan := StartSegmentSpan(ExtractTraceHeaders(context.Background(), h), SpanTranscodeRemote, "mid", 3)
+	traceCtx := TraceContext(octx)
+	require.NotEmpty(traceCtx["traceparent"])
+
+	// Transcoder
+	tctx, tspan := StartSegmentSpan(ExtractTraceContext(context.Background(), traceCtx), SpanTranscoderSegment, "mid", 3)
+	SetSpanError(tctx, errors.New("transcode failed"))
+	tspan.End()
+	EndSpan(ospan, errors.New("transcode failed"))
+	EndSpan(bspan, nil)
+
+	spans := recorder.Ended()
+	require.Len(spans, 3)
+	tsp, osp, bsp := spans[0], spans[1], spans[2]
+	assert.Equal(SpanTranscoderSegment, tsp.Name())
+	assert.Equal(SpanTranscodeRemote, osp.Name())
+	assert.Equal(SpanSubmitSegment, bsp.Name())
+
+	// The spans of the three nodes are in the same trace
+	assert.Equal(bsp.SpanContext().TraceID(), osp.SpanContext().TraceID())
+	assert.Equal(bsp.SpanContext().TraceID(), tsp.SpanContext().TraceID())
+	assert.Equal(bsp.SpanContext().SpanID(), osp.Parent().SpanID())
+	assert.True(osp.Parent().IsRemote())
+	assert.Equal(osp.SpanContext().SpanID(), tsp.Parent().SpanID())
+
+	assert.Contains(bsp.Attributes(), attribute.String(AttrManifestID, "mid"))
+	assert.Contains(bsp.Attributes(), attribute.Int64(AttrSeqNo, 3))
+	assert.Contains(bsp.Attributes(), attribute.String(AttrOrchestrator, "https://o:8935"))
+	assert.Equal(codes.Unset, bsp.Status().Code)
+	assert.Equal(codes.Error, osp.Status().Code)
+	assert.Equal(codes.Error, tsp.Status().Code)
+	assert.Equal("transcode failed", tsp.Status().Description)
+}
+
+func TestTracing_NotTracing(t *testing.T) {
+	assert := assert.New(t)
+
+	// Without a trace there's no context to send
+	assert.Nil(TraceContext(context.Background()))
+	h := http.Header{}
+	InjectTraceHeaders(context.Background(), h)
+	assert.Empty(h)
+
+	// A node that doesn't trace relays the trace context it receives
+	traceCtx := map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+	ctx, span := StartSegmentSpan(ExtractTraceContext(context.Background(), traceCtx), SpanServeSegment, "mid", 1)
+	defer EndSpan(span, nil)
+	assert.False(span.IsRecording())
+	assert.Equal(traceCtx, TraceContext(ctx))
+}
@@ -0,0 +1,2692 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: net/admin.proto
+
+package net
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type SessionEvent_Type int32
+
+const (
+	// A session with an orchestrator was added to the sessions of the stream
+	SessionEvent_SESSION_ADDED SessionEvent_Type = 0
+	// A session was removed from the sessions of the stream, e.g. after an error
+	SessionEvent_SESSION_REMOVED    SessionEvent_Type = 1
+	SessionEvent_SEGMENT_TRANSCODED SessionEvent_Type = 2
+	SessionEvent_SEGMENT_FAILED     SessionEvent_Type = 3
+)
+
+var SessionEvent_Type_name = map[int32]string{
+	0: "SESSION_ADDED",
+	1: "SESSION_REMOVED",
+	2: "SEGMENT_TRANSCODED",
+	3: "SEGMENT_FAILED",
+}
+
+var SessionEvent_Type_value = map[string]int32{
+	"SESSION_ADDED":      0,
+	"SESSION_REMOVED":    1,
+	"SEGMENT_TRANSCODED": 2,
+	"SEGMENT_FAILED":     3,
+}
+
+func (x SessionEvent_Type) String() string {
+	return proto.EnumName(SessionEvent_Type_name, int32(x))
+}
+
+func (SessionEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{31, 0}
+}
+
+type StatusReq struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatusReq) Reset()         { *m = StatusReq{} }
+func (m *StatusReq) String() string { return proto.CompactTextString(m) }
+func (*StatusReq) ProtoMessage()    {}
+func (*StatusReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{0}
+}
+
+func (m *StatusReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusReq.Unmarshal(m, b)
+}
+func (m *StatusReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusReq.Marshal(b, m, deterministic)
+}
+func (m *StatusReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusReq.Merge(m, src)
+}
+func (m *StatusReq) XXX_Size() int {
+	return xxx_messageInfo_StatusReq.Size(m)
+}
+func (m *StatusReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusReq proto.InternalMessageInfo
+
+type NodeStatus struct {
+	NodeType              string   `protobuf:"bytes,1,opt,name=node_type,json=nodeType,proto3" json:"node_type,omitempty"`
+	Version               string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	GoVersion             string   `protobuf:"bytes,3,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	Goos                  string   `protobuf:"bytes,4,opt,name=goos,proto3" json:"goos,omitempty"`
+	Goarch                string   `protobuf:"bytes,5,opt,name=goarch,proto3" json:"goarch,omitempty"`
+	EthAddress            string   `protobuf:"bytes,6,opt,name=eth_address,json=ethAddress,proto3" json:"eth_address,omitempty"`
+	ActiveStreams         int64    `protobuf:"varint,7,opt,name=active_streams,json=activeStreams,proto3" json:"active_streams,omitempty"`
+	OrchestratorPool      []string `protobuf:"bytes,8,rep,name=orchestrator_pool,json=orchestratorPool,proto3" json:"orchestrator_pool,omitempty"`
+	RegisteredTranscoders int64    `protobuf:"varint,9,opt,name=registered_transcoders,json=registeredTranscoders,proto3" json:"registered_transcoders,omitempty"`
+	LocalTranscoding      bool     `protobuf:"varint,10,opt,name=local_transcoding,json=localTranscoding,proto3" json:"local_transcoding,omitempty"`
+	XXX_NoUnkeyedLiteral  struct{} `json:"-"`
+	XXX_unrecognized      []byte   `json:"-"`
+	XXX_sizecache         int32    `json:"-"`
+}
+
+func (m *NodeStatus) Reset()         { *m = NodeStatus{} }
+func (m *NodeStatus) String() string { return proto.CompactTextString(m) }
+func (*NodeStatus) ProtoMessage()    {}
+func (*NodeStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{1}
+}
+
+func (m *NodeStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NodeStatus.Unmarshal(m, b)
+}
+func (m *NodeStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NodeStatus.Marshal(b, m, deterministic)
+}
+func (m *NodeStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeStatus.Merge(m, src)
+}
+func (m *NodeStatus) XXX_Size() int {
+	return xxx_messageInfo_NodeStatus.Size(m)
+}
+func (m *NodeStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeStatus proto.InternalMessageInfo
+
+func (m *NodeStatus) GetNodeType() string {
+	if m != nil {
+		return m.NodeType
+	}
+	return ""
+}
+
+func (m *NodeStatus) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *NodeStatus) GetGoVersion() string {
+	if m != nil {
+		return m.GoVersion
+	}
+	return ""
+}
+
+func (m *NodeStatus) GetGoos() string {
+	if m != nil {
+		return m.Goos
+	}
+	return ""
+}
+
+func (m *NodeStatus) GetGoarch() string {
+	if m != nil {
+		return m.Goarch
+	}
+	return ""
+}
+
+func (m *NodeStatus) GetEthAddress() string {
+	if m != nil {
+		return m.EthAddress
+	}
+	return ""
+}
+
+func (m *NodeStatus) GetActiveStreams() int64 {
+	if m != nil {
+		return m.ActiveStreams
+	}
+	return 0
+}
+
+func (m *NodeStatus) GetOrchestratorPool() []string {
+	if m != nil {
+		return m.OrchestratorPool
+	}
+	return nil
+}
+
+func (m *NodeStatus) GetRegisteredTranscoders() int64 {
+	if m != nil {
+		return m.RegisteredTranscoders
+	}
+	return 0
+}
+
+func (m *NodeStatus) GetLocalTranscoding() bool {
+	if m != nil {
+		return m.LocalTranscoding
+	}
+	return false
+}
+
+type ListStreamsReq struct {
+	// Only lists the stream with the manifest ID if set
+	ManifestId           string   `protobuf:"bytes,1,opt,name=manifest_id,json=manifestId,proto3" json:"manifest_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListStreamsReq) Reset()         { *m = ListStreamsReq{} }
+func (m *ListStreamsReq) String() string { return proto.CompactTextString(m) }
+func (*ListStreamsReq) ProtoMessage()    {}
+func (*ListStreamsReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{2}
+}
+
+func (m *ListStreamsReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListStreamsReq.Unmarshal(m, b)
+}
+func (m *ListStreamsReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListStreamsReq.Marshal(b, m, deterministic)
+}
+func (m *ListStreamsReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStreamsReq.Merge(m, src)
+}
+func (m *ListStreamsReq) XXX_Size() int {
+	return xxx_messageInfo_ListStreamsReq.Size(m)
+}
+func (m *ListStreamsReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStreamsReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStreamsReq proto.InternalMessageInfo
+
+func (m *ListStreamsReq) GetManifestId() string {
+	if m != nil {
+		return m.ManifestId
+	}
+	return ""
+}
+
+type ListStreamsRes struct {
+	Streams              []*StreamStatus `protobuf:"bytes,1,rep,name=streams,proto3" json:"streams,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ListStreamsRes) Reset()         { *m = ListStreamsRes{} }
+func (m *ListStreamsRes) String() string { return proto.CompactTextString(m) }
+func (*ListStreamsRes) ProtoMessage()    {}
+func (*ListStreamsRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{3}
+}
+
+func (m *ListStreamsRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListStreamsRes.Unmarshal(m, b)
+}
+func (m *ListStreamsRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListStreamsRes.Marshal(b, m, deterministic)
+}
+func (m *ListStreamsRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStreamsRes.Merge(m, src)
+}
+func (m *ListStreamsRes) XXX_Size() int {
+	return xxx_messageInfo_ListStreamsRes.Size(m)
+}
+func (m *ListStreamsRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStreamsRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStreamsRes proto.InternalMessageInfo
+
+func (m *ListStreamsRes) GetStreams() []*StreamStatus {
+	if m != nil {
+		return m.Streams
+	}
+	return nil
+}
+
+type StreamStatus struct {
+	ManifestId       string           `protobuf:"bytes,1,opt,name=manifest_id,json=manifestId,proto3" json:"manifest_id,omitempty"`
+	ExternalStreamId string           `protobuf:"bytes,2,opt,name=external_stream_id,json=externalStreamId,proto3" json:"external_stream_id,omitempty"`
+	Profiles         []string         `protobuf:"bytes,3,rep,name=profiles,proto3" json:"profiles,omitempty"`
+	Sessions         []*SessionStatus `protobuf:"bytes,4,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	Succeeded        int64            `protobuf:"varint,5,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed           int64            `protobuf:"varint,6,opt,name=failed,proto3" json:"failed,omitempty"`
+	Errors           map[string]int64 `protobuf:"bytes,7,rep,name=errors,proto3" json:"errors,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	RecentSegments   []*SegmentStatus `protobuf:"bytes,8,rep,name=recent_segments,json=recentSegments,proto3" json:"recent_segments,omitempty"`
+	Health           *StreamHealth    `protobuf:"bytes,9,opt,name=health,proto3" json:"health,omitempty"`
+	// Unix time in milliseconds
+	StartedAt            int64    `protobuf:"varint,10,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	SourceBytes          uint64   `protobuf:"varint,11,opt,name=source_bytes,json=sourceBytes,proto3" json:"source_bytes,omitempty"`
+	TranscodedBytes      uint64   `protobuf:"varint,12,opt,name=transcoded_bytes,json=transcodedBytes,proto3" json:"transcoded_bytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamStatus) Reset()         { *m = StreamStatus{} }
+func (m *StreamStatus) String() string { return proto.CompactTextString(m) }
+func (*StreamStatus) ProtoMessage()    {}
+func (*StreamStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{4}
+}
+
+func (m *StreamStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamStatus.Unmarshal(m, b)
+}
+func (m *StreamStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamStatus.Marshal(b, m, deterministic)
+}
+func (m *StreamStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamStatus.Merge(m, src)
+}
+func (m *StreamStatus) XXX_Size() int {
+	return xxx_messageInfo_StreamStatus.Size(m)
+}
+func (m *StreamStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamStatus proto.InternalMessageInfo
+
+func (m *StreamStatus) GetManifestId() string {
+	if m != nil {
+		return m.ManifestId
+	}
+	return ""
+}
+
+func (m *StreamStatus) GetExternalStreamId() string {
+	if m != nil {
+		return m.ExternalStreamId
+	}
+	return ""
+}
+
+func (m *StreamStatus) GetProfiles() []string {
+	if m != nil {
+		return m.Profiles
+	}
+	return nil
+}
+
+func (m *StreamStatus) GetSessions() []*SessionStatus {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+func (m *StreamStatus) GetSucceeded() int64 {
+	if m != nil {
+		return m.Succeeded
+	}
+	return 0
+}
+
+func (m *StreamStatus) GetFailed() int64 {
+	if m != nil {
+		return m.Failed
+	}
+	return 0
+}
+
+func (m *StreamStatus) GetErrors() map[string]int64 {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+func (m *StreamStatus) GetRecentSegments() []*SegmentStatus {
+	if m != nil {
+		return m.RecentSegments
+	}
+	return nil
+}
+
+func (m *StreamStatus) GetHealth() *StreamHealth {
+	if m != nil {
+		return m.Health
+	}
+	return nil
+}
+
+func (m *StreamStatus) GetStartedAt() int64 {
+	if m != nil {
+		return m.StartedAt
+	}
+	return 0
+}
+
+func (m *StreamStatus) GetSourceBytes() uint64 {
+	if m != nil {
+		return m.SourceBytes
+	}
+	return 0
+}
+
+func (m *StreamStatus) GetTranscodedBytes() uint64 {
+	if m != nil {
+		return m.TranscodedBytes
+	}
+	return 0
+}
+
+type SegmentStatus struct {
+	SeqNo                uint64   `protobuf:"varint,1,opt,name=seq_no,json=seqNo,proto3" json:"seq_no,omitempty"`
+	Orchestrator         string   `protobuf:"bytes,2,opt,name=orchestrator,proto3" json:"orchestrator,omitempty"`
+	Attempts             int64    `protobuf:"varint,3,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LatencyMs            int64    `protobuf:"varint,4,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	Error                string   `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SegmentStatus) Reset()         { *m = SegmentStatus{} }
+func (m *SegmentStatus) String() string { return proto.CompactTextString(m) }
+func (*SegmentStatus) ProtoMessage()    {}
+func (*SegmentStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{5}
+}
+
+func (m *SegmentStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SegmentStatus.Unmarshal(m, b)
+}
+func (m *SegmentStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SegmentStatus.Marshal(b, m, deterministic)
+}
+func (m *SegmentStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SegmentStatus.Merge(m, src)
+}
+func (m *SegmentStatus) XXX_Size() int {
+	return xxx_messageInfo_SegmentStatus.Size(m)
+}
+func (m *SegmentStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_SegmentStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SegmentStatus proto.InternalMessageInfo
+
+func (m *SegmentStatus) GetSeqNo() uint64 {
+	if m != nil {
+		return m.SeqNo
+	}
+	return 0
+}
+
+func (m *SegmentStatus) GetOrchestrator() string {
+	if m != nil {
+		return m.Orchestrator
+	}
+	return ""
+}
+
+func (m *SegmentStatus) GetAttempts() int64 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *SegmentStatus) GetLatencyMs() int64 {
+	if m != nil {
+		return m.LatencyMs
+	}
+	return 0
+}
+
+func (m *SegmentStatus) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type StreamHealth struct {
+	Score                   float64  `protobuf:"fixed64,1,opt,name=score,proto3" json:"score,omitempty"`
+	State                   string   `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	DroppedRate             float64  `protobuf:"fixed64,3,opt,name=dropped_rate,json=droppedRate,proto3" json:"dropped_rate,omitempty"`
+	RealtimeRatio           float64  `protobuf:"fixed64,4,opt,name=realtime_ratio,json=realtimeRatio,proto3" json:"realtime_ratio,omitempty"`
+	VerificationFailureRate float64  `protobuf:"fixed64,5,opt,name=verification_failure_rate,json=verificationFailureRate,proto3" json:"verification_failure_rate,omitempty"`
+	SwapRate                float64  `protobuf:"fixed64,6,opt,name=swap_rate,json=swapRate,proto3" json:"swap_rate,omitempty"`
+	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
+	XXX_unrecognized        []byte   `json:"-"`
+	XXX_sizecache           int32    `json:"-"`
+}
+
+func (m *StreamHealth) Reset()         { *m = StreamHealth{} }
+func (m *StreamHealth) String() string { return proto.CompactTextString(m) }
+func (*StreamHealth) ProtoMessage()    {}
+func (*StreamHealth) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{6}
+}
+
+func (m *StreamHealth) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamHealth.Unmarshal(m, b)
+}
+func (m *StreamHealth) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamHealth.Marshal(b, m, deterministic)
+}
+func (m *StreamHealth) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamHealth.Merge(m, src)
+}
+func (m *StreamHealth) XXX_Size() int {
+	return xxx_messageInfo_StreamHealth.Size(m)
+}
+func (m *StreamHealth) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamHealth.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamHealth proto.InternalMessageInfo
+
+func (m *StreamHealth) GetScore() float64 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+func (m *StreamHealth) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *StreamHealth) GetDroppedRate() float64 {
+	if m != nil {
+		return m.DroppedRate
+	}
+	return 0
+}
+
+func (m *StreamHealth) GetRealtimeRatio() float64 {
+	if m != nil {
+		return m.RealtimeRatio
+	}
+	return 0
+}
+
+func (m *StreamHealth) GetVerificationFailureRate() float64 {
+	if m != nil {
+		return m.VerificationFailureRate
+	}
+	return 0
+}
+
+func (m *StreamHealth) GetSwapRate() float64 {
+	if m != nil {
+		return m.SwapRate
+	}
+	return 0
+}
+
+type TerminateStreamReq struct {
+	ManifestId string `protobuf:"bytes,1,opt,name=manifest_id,json=manifestId,proto3" json:"manifest_id,omitempty"`
+	// Milliseconds the publisher is rejected for before it can restart the stream, one minute if unset
+	BlockForMs           int64    `protobuf:"varint,2,opt,name=block_for_ms,json=blockForMs,proto3" json:"block_for_ms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TerminateStreamReq) Reset()         { *m = TerminateStreamReq{} }
+func (m *TerminateStreamReq) String() string { return proto.CompactTextString(m) }
+func (*TerminateStreamReq) ProtoMessage()    {}
+func (*TerminateStreamReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{7}
+}
+
+func (m *TerminateStreamReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TerminateStreamReq.Unmarshal(m, b)
+}
+func (m *TerminateStreamReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TerminateStreamReq.Marshal(b, m, deterministic)
+}
+func (m *TerminateStreamReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TerminateStreamReq.Merge(m, src)
+}
+func (m *TerminateStreamReq) XXX_Size() int {
+	return xxx_messageInfo_TerminateStreamReq.Size(m)
+}
+func (m *TerminateStreamReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_TerminateStreamReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TerminateStreamReq proto.InternalMessageInfo
+
+func (m *TerminateStreamReq) GetManifestId() string {
+	if m != nil {
+		return m.ManifestId
+	}
+	return ""
+}
+
+func (m *TerminateStreamReq) GetBlockForMs() int64 {
+	if m != nil {
+		return m.BlockForMs
+	}
+	return 0
+}
+
+type TerminateStreamRes struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TerminateStreamRes) Reset()         { *m = TerminateStreamRes{} }
+func (m *TerminateStreamRes) String() string { return proto.CompactTextString(m) }
+func (*TerminateStreamRes) ProtoMessage()    {}
+func (*TerminateStreamRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{8}
+}
+
+func (m *TerminateStreamRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TerminateStreamRes.Unmarshal(m, b)
+}
+func (m *TerminateStreamRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TerminateStreamRes.Marshal(b, m, deterministic)
+}
+func (m *TerminateStreamRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TerminateStreamRes.Merge(m, src)
+}
+func (m *TerminateStreamRes) XXX_Size() int {
+	return xxx_messageInfo_TerminateStreamRes.Size(m)
+}
+func (m *TerminateStreamRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_TerminateStreamRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TerminateStreamRes proto.InternalMessageInfo
+
+type ListSessionsReq struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSessionsReq) Reset()         { *m = ListSessionsReq{} }
+func (m *ListSessionsReq) String() string { return proto.CompactTextString(m) }
+func (*ListSessionsReq) ProtoMessage()    {}
+func (*ListSessionsReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{9}
+}
+
+func (m *ListSessionsReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSessionsReq.Unmarshal(m, b)
+}
+func (m *ListSessionsReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSessionsReq.Marshal(b, m, deterministic)
+}
+func (m *ListSessionsReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSessionsReq.Merge(m, src)
+}
+func (m *ListSessionsReq) XXX_Size() int {
+	return xxx_messageInfo_ListSessionsReq.Size(m)
+}
+func (m *ListSessionsReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSessionsReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSessionsReq proto.InternalMessageInfo
+
+type ListSessionsRes struct {
+	Sessions             []*SessionStatus `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ListSessionsRes) Reset()         { *m = ListSessionsRes{} }
+func (m *ListSessionsRes) String() string { return proto.CompactTextString(m) }
+func (*ListSessionsRes) ProtoMessage()    {}
+func (*ListSessionsRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{10}
+}
+
+func (m *ListSessionsRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSessionsRes.Unmarshal(m, b)
+}
+func (m *ListSessionsRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSessionsRes.Marshal(b, m, deterministic)
+}
+func (m *ListSessionsRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSessionsRes.Merge(m, src)
+}
+func (m *ListSessionsRes) XXX_Size() int {
+	return xxx_messageInfo_ListSessionsRes.Size(m)
+}
+func (m *ListSessionsRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSessionsRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSessionsRes proto.InternalMessageInfo
+
+func (m *ListSessionsRes) GetSessions() []*SessionStatus {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+type SessionStatus struct {
+	ManifestId           string               `protobuf:"bytes,1,opt,name=manifest_id,json=manifestId,proto3" json:"manifest_id,omitempty"`
+	Orchestrator         string               `protobuf:"bytes,2,opt,name=orchestrator,proto3" json:"orchestrator,omitempty"`
+	Address              string               `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Trusted              bool                 `protobuf:"varint,4,opt,name=trusted,proto3" json:"trusted,omitempty"`
+	PricePerUnit         int64                `protobuf:"varint,5,opt,name=price_per_unit,json=pricePerUnit,proto3" json:"price_per_unit,omitempty"`
+	PixelsPerUnit        int64                `protobuf:"varint,6,opt,name=pixels_per_unit,json=pixelsPerUnit,proto3" json:"pixels_per_unit,omitempty"`
+	TicketParams         *SessionTicketParams `protobuf:"bytes,7,opt,name=ticket_params,json=ticketParams,proto3" json:"ticket_params,omitempty"`
+	SegsInFlight         int64                `protobuf:"varint,8,opt,name=segs_in_flight,json=segsInFlight,proto3" json:"segs_in_flight,omitempty"`
+	LatencyScore         float64              `protobuf:"fixed64,9,opt,name=latency_score,json=latencyScore,proto3" json:"latency_score,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *SessionStatus) Reset()         { *m = SessionStatus{} }
+func (m *SessionStatus) String() string { return proto.CompactTextString(m) }
+func (*SessionStatus) ProtoMessage()    {}
+func (*SessionStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{11}
+}
+
+func (m *SessionStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionStatus.Unmarshal(m, b)
+}
+func (m *SessionStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionStatus.Marshal(b, m, deterministic)
+}
+func (m *SessionStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionStatus.Merge(m, src)
+}
+func (m *SessionStatus) XXX_Size() int {
+	return xxx_messageInfo_SessionStatus.Size(m)
+}
+func (m *SessionStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionStatus proto.InternalMessageInfo
+
+func (m *SessionStatus) GetManifestId() string {
+	if m != nil {
+		return m.ManifestId
+	}
+	return ""
+}
+
+func (m *SessionStatus) GetOrchestrator() string {
+	if m != nil {
+		return m.Orchestrator
+	}
+	return ""
+}
+
+func (m *SessionStatus) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *SessionStatus) GetTrusted() bool {
+	if m != nil {
+		return m.Trusted
+	}
+	return false
+}
+
+func (m *SessionStatus) GetPricePerUnit() int64 {
+	if m != nil {
+		return m.PricePerUnit
+	}
+	return 0
+}
+
+func (m *SessionStatus) GetPixelsPerUnit() int64 {
+	if m != nil {
+		return m.PixelsPerUnit
+	}
+	return 0
+}
+
+func (m *SessionStatus) GetTicketParams() *SessionTicketParams {
+	if m != nil {
+		return m.TicketParams
+	}
+	return nil
+}
+
+func (m *SessionStatus) GetSegsInFlight() int64 {
+	if m != nil {
+		return m.SegsInFlight
+	}
+	return 0
+}
+
+func (m *SessionStatus) GetLatencyScore() float64 {
+	if m != nil {
+		return m.LatencyScore
+	}
+	return 0
+}
+
+type SessionTicketParams struct {
+	Recipient            string   `protobuf:"bytes,1,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	FaceValue            string   `protobuf:"bytes,2,opt,name=face_value,json=faceValue,proto3" json:"face_value,omitempty"`
+	WinProb              string   `protobuf:"bytes,3,opt,name=win_prob,json=winProb,proto3" json:"win_prob,omitempty"`
+	ExpirationBlock      string   `protobuf:"bytes,4,opt,name=expiration_block,json=expirationBlock,proto3" json:"expiration_block,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SessionTicketParams) Reset()         { *m = SessionTicketParams{} }
+func (m *SessionTicketParams) String() string { return proto.CompactTextString(m) }
+func (*SessionTicketParams) ProtoMessage()    {}
+func (*SessionTicketParams) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{12}
+}
+
+func (m *SessionTicketParams) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionTicketParams.Unmarshal(m, b)
+}
+func (m *SessionTicketParams) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionTicketParams.Marshal(b, m, deterministic)
+}
+func (m *SessionTicketParams) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionTicketParams.Merge(m, src)
+}
+func (m *SessionTicketParams) XXX_Size() int {
+	return xxx_messageInfo_SessionTicketParams.Size(m)
+}
+func (m *SessionTicketParams) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionTicketParams.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionTicketParams proto.InternalMessageInfo
+
+func (m *SessionTicketParams) GetRecipient() string {
+	if m != nil {
+		return m.Recipient
+	}
+	return ""
+}
+
+func (m *SessionTicketParams) GetFaceValue() string {
+	if m != nil {
+		return m.FaceValue
+	}
+	return ""
+}
+
+func (m *SessionTicketParams) GetWinProb() string {
+	if m != nil {
+		return m.WinProb
+	}
+	return ""
+}
+
+func (m *SessionTicketParams) GetExpirationBlock() string {
+	if m != nil {
+		return m.ExpirationBlock
+	}
+	return ""
+}
+
+type GetPricingReq struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPricingReq) Reset()         { *m = GetPricingReq{} }
+func (m *GetPricingReq) String() string { return proto.CompactTextString(m) }
+func (*GetPricingReq) ProtoMessage()    {}
+func (*GetPricingReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{13}
+}
+
+func (m *GetPricingReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPricingReq.Unmarshal(m, b)
+}
+func (m *GetPricingReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPricingReq.Marshal(b, m, deterministic)
+}
+func (m *GetPricingReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPricingReq.Merge(m, src)
+}
+func (m *GetPricingReq) XXX_Size() int {
+	return xxx_messageInfo_GetPricingReq.Size(m)
+}
+func (m *GetPricingReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPricingReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPricingReq proto.InternalMessageInfo
+
+type Pricing struct {
+	BasePrices           []*Price `protobuf:"bytes,1,rep,name=base_prices,json=basePrices,proto3" json:"base_prices,omitempty"`
+	MaxPrices            []*Price `protobuf:"bytes,2,rep,name=max_prices,json=maxPrices,proto3" json:"max_prices,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Pricing) Reset()         { *m = Pricing{} }
+func (m *Pricing) String() string { return proto.CompactTextString(m) }
+func (*Pricing) ProtoMessage()    {}
+func (*Pricing) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{14}
+}
+
+func (m *Pricing) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Pricing.Unmarshal(m, b)
+}
+func (m *Pricing) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Pricing.Marshal(b, m, deterministic)
+}
+func (m *Pricing) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Pricing.Merge(m, src)
+}
+func (m *Pricing) XXX_Size() int {
+	return xxx_messageInfo_Pricing.Size(m)
+}
+func (m *Pricing) XXX_DiscardUnknown() {
+	xxx_messageInfo_Pricing.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Pricing proto.InternalMessageInfo
+
+func (m *Pricing) GetBasePrices() []*Price {
+	if m != nil {
+		return m.BasePrices
+	}
+	return nil
+}
+
+func (m *Pricing) GetMaxPrices() []*Price {
+	if m != nil {
+		return m.MaxPrices
+	}
+	return nil
+}
+
+type Price struct {
+	Broadcaster          string   `protobuf:"bytes,1,opt,name=broadcaster,proto3" json:"broadcaster,omitempty"`
+	Capability           string   `protobuf:"bytes,2,opt,name=capability,proto3" json:"capability,omitempty"`
+	PricePerUnit         string   `protobuf:"bytes,3,opt,name=price_per_unit,json=pricePerUnit,proto3" json:"price_per_unit,omitempty"`
+	PixelsPerUnit        string   `protobuf:"bytes,4,opt,name=pixels_per_unit,json=pixelsPerUnit,proto3" json:"pixels_per_unit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Price) Reset()         { *m = Price{} }
+func (m *Price) String() string { return proto.CompactTextString(m) }
+func (*Price) ProtoMessage()    {}
+func (*Price) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{15}
+}
+
+func (m *Price) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Price.Unmarshal(m, b)
+}
+func (m *Price) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Price.Marshal(b, m, deterministic)
+}
+func (m *Price) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Price.Merge(m, src)
+}
+func (m *Price) XXX_Size() int {
+	return xxx_messageInfo_Price.Size(m)
+}
+func (m *Price) XXX_DiscardUnknown() {
+	xxx_messageInfo_Price.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Price proto.InternalMessageInfo
+
+func (m *Price) GetBroadcaster() string {
+	if m != nil {
+		return m.Broadcaster
+	}
+	return ""
+}
+
+func (m *Price) GetCapability() string {
+	if m != nil {
+		return m.Capability
+	}
+	return ""
+}
+
+func (m *Price) GetPricePerUnit() string {
+	if m != nil {
+		return m.PricePerUnit
+	}
+	return ""
+}
+
+func (m *Price) GetPixelsPerUnit() string {
+	if m != nil {
+		return m.PixelsPerUnit
+	}
+	return ""
+}
+
+type SetPriceReq struct {
+	// ETH address of the broadcaster, or default for all the others
+	Broadcaster   string `protobuf:"bytes,1,opt,name=broadcaster,proto3" json:"broadcaster,omitempty"`
+	PricePerUnit  string `protobuf:"bytes,2,opt,name=price_per_unit,json=pricePerUnit,proto3" json:"price_per_unit,omitempty"`
+	PixelsPerUnit string `protobuf:"bytes,3,opt,name=pixels_per_unit,json=pixelsPerUnit,proto3" json:"pixels_per_unit,omitempty"`
+	// Name of the capability the price is for the jobs requiring, unset for all the jobs
+	Capability           string   `protobuf:"bytes,4,opt,name=capability,proto3" json:"capability,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetPriceReq) Reset()         { *m = SetPriceReq{} }
+func (m *SetPriceReq) String() string { return proto.CompactTextString(m) }
+func (*SetPriceReq) ProtoMessage()    {}
+func (*SetPriceReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{16}
+}
+
+func (m *SetPriceReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetPriceReq.Unmarshal(m, b)
+}
+func (m *SetPriceReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetPriceReq.Marshal(b, m, deterministic)
+}
+func (m *SetPriceReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetPriceReq.Merge(m, src)
+}
+func (m *SetPriceReq) XXX_Size() int {
+	return xxx_messageInfo_SetPriceReq.Size(m)
+}
+func (m *SetPriceReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetPriceReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetPriceReq proto.InternalMessageInfo
+
+func (m *SetPriceReq) GetBroadcaster() string {
+	if m != nil {
+		return m.Broadcaster
+	}
+	return ""
+}
+
+func (m *SetPriceReq) GetPricePerUnit() string {
+	if m != nil {
+		return m.PricePerUnit
+	}
+	return ""
+}
+
+func (m *SetPriceReq) GetPixelsPerUnit() string {
+	if m != nil {
+		return m.PixelsPerUnit
+	}
+	return ""
+}
+
+func (m *SetPriceReq) GetCapability() string {
+	if m != nil {
+		return m.Capability
+	}
+	return ""
+}
+
+type RemovePriceReq struct {
+	// ETH address of the broadcaster, or default with a capability
+	Broadcaster          string   `protobuf:"bytes,1,opt,name=broadcaster,proto3" json:"broadcaster,omitempty"`
+	Capability           string   `protobuf:"bytes,2,opt,name=capability,proto3" json:"capability,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemovePriceReq) Reset()         { *m = RemovePriceReq{} }
+func (m *RemovePriceReq) String() string { return proto.CompactTextString(m) }
+func (*RemovePriceReq) ProtoMessage()    {}
+func (*RemovePriceReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{17}
+}
+
+func (m *RemovePriceReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemovePriceReq.Unmarshal(m, b)
+}
+func (m *RemovePriceReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemovePriceReq.Marshal(b, m, deterministic)
+}
+func (m *RemovePriceReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemovePriceReq.Merge(m, src)
+}
+func (m *RemovePriceReq) XXX_Size() int {
+	return xxx_messageInfo_RemovePriceReq.Size(m)
+}
+func (m *RemovePriceReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemovePriceReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemovePriceReq proto.InternalMessageInfo
+
+func (m *RemovePriceReq) GetBroadcaster() string {
+	if m != nil {
+		return m.Broadcaster
+	}
+	return ""
+}
+
+func (m *RemovePriceReq) GetCapability() string {
+	if m != nil {
+		return m.Capability
+	}
+	return ""
+}
+
+type GetStakingReq struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStakingReq) Reset()         { *m = GetStakingReq{} }
+func (m *GetStakingReq) String() string { return proto.CompactTextString(m) }
+func (*GetStakingReq) ProtoMessage()    {}
+func (*GetStakingReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{18}
+}
+
+func (m *GetStakingReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStakingReq.Unmarshal(m, b)
+}
+func (m *GetStakingReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStakingReq.Marshal(b, m, deterministic)
+}
+func (m *GetStakingReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStakingReq.Merge(m, src)
+}
+func (m *GetStakingReq) XXX_Size() int {
+	return xxx_messageInfo_GetStakingReq.Size(m)
+}
+func (m *GetStakingReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStakingReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStakingReq proto.InternalMessageInfo
+
+type Staking struct {
+	Delegator *Delegator `protobuf:"bytes,1,opt,name=delegator,proto3" json:"delegator,omitempty"`
+	// Unset for the nodes other than orchestrators
+	Transcoder           *TranscoderStatus `protobuf:"bytes,2,opt,name=transcoder,proto3" json:"transcoder,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Staking) Reset()         { *m = Staking{} }
+func (m *Staking) String() string { return proto.CompactTextString(m) }
+func (*Staking) ProtoMessage()    {}
+func (*Staking) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{19}
+}
+
+func (m *Staking) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Staking.Unmarshal(m, b)
+}
+func (m *Staking) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Staking.Marshal(b, m, deterministic)
+}
+func (m *Staking) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Staking.Merge(m, src)
+}
+func (m *Staking) XXX_Size() int {
+	return xxx_messageInfo_Staking.Size(m)
+}
+func (m *Staking) XXX_DiscardUnknown() {
+	xxx_messageInfo_Staking.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Staking proto.InternalMessageInfo
+
+func (m *Staking) GetDelegator() *Delegator {
+	if m != nil {
+		return m.Delegator
+	}
+	return nil
+}
+
+func (m *Staking) GetTranscoder() *TranscoderStatus {
+	if m != nil {
+		return m.Transcoder
+	}
+	return nil
+}
+
+type Delegator struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Status               string   `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	BondedAmount         string   `protobuf:"bytes,3,opt,name=bonded_amount,json=bondedAmount,proto3" json:"bonded_amount,omitempty"`
+	PendingStake         string   `protobuf:"bytes,4,opt,name=pending_stake,json=pendingStake,proto3" json:"pending_stake,omitempty"`
+	Fees                 string   `protobuf:"bytes,5,opt,name=fees,proto3" json:"fees,omitempty"`
+	PendingFees          string   `protobuf:"bytes,6,opt,name=pending_fees,json=pendingFees,proto3" json:"pending_fees,omitempty"`
+	DelegateAddress      string   `protobuf:"bytes,7,opt,name=delegate_address,json=delegateAddress,proto3" json:"delegate_address,omitempty"`
+	DelegatedAmount      string   `protobuf:"bytes,8,opt,name=delegated_amount,json=delegatedAmount,proto3" json:"delegated_amount,omitempty"`
+	StartRound           string   `protobuf:"bytes,9,opt,name=start_round,json=startRound,proto3" json:"start_round,omitempty"`
+	LastClaimRound       string   `protobuf:"bytes,10,opt,name=last_claim_round,json=lastClaimRound,proto3" json:"last_claim_round,omitempty"`
+	NextUnbondingLockId  string   `protobuf:"bytes,11,opt,name=next_unbonding_lock_id,json=nextUnbondingLockId,proto3" json:"next_unbonding_lock_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Delegator) Reset()         { *m = Delegator{} }
+func (m *Delegator) String() string { return proto.CompactTextString(m) }
+func (*Delegator) ProtoMessage()    {}
+func (*Delegator) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{20}
+}
+
+func (m *Delegator) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Delegator.Unmarshal(m, b)
+}
+func (m *Delegator) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Delegator.Marshal(b, m, deterministic)
+}
+func (m *Delegator) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Delegator.Merge(m, src)
+}
+func (m *Delegator) XXX_Size() int {
+	return xxx_messageInfo_Delegator.Size(m)
+}
+func (m *Delegator) XXX_DiscardUnknown() {
+	xxx_messageInfo_Delegator.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Delegator proto.InternalMessageInfo
+
+func (m *Delegator) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Delegator) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Delegator) GetBondedAmount() string {
+	if m != nil {
+		return m.BondedAmount
+	}
+	return ""
+}
+
+func (m *Delegator) GetPendingStake() string {
+	if m != nil {
+		return m.PendingStake
+	}
+	return ""
+}
+
+func (m *Delegator) GetFees() string {
+	if m != nil {
+		return m.Fees
+	}
+	return ""
+}
+
+func (m *Delegator) GetPendingFees() string {
+	if m != nil {
+		return m.PendingFees
+	}
+	return ""
+}
+
+func (m *Delegator) GetDelegateAddress() string {
+	if m != nil {
+		return m.DelegateAddress
+	}
+	return ""
+}
+
+func (m *Delegator) GetDelegatedAmount() string {
+	if m != nil {
+		return m.DelegatedAmount
+	}
+	return ""
+}
+
+func (m *Delegator) GetStartRound() string {
+	if m != nil {
+		return m.StartRound
+	}
+	return ""
+}
+
+func (m *Delegator) GetLastClaimRound() string {
+	if m != nil {
+		return m.LastClaimRound
+	}
+	return ""
+}
+
+func (m *Delegator) GetNextUnbondingLockId() string {
+	if m != nil {
+		return m.NextUnbondingLockId
+	}
+	return ""
+}
+
+type TranscoderStatus struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Status               string   `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Active               bool     `protobuf:"varint,3,opt,name=active,proto3" json:"active,omitempty"`
+	ServiceUri           string   `protobuf:"bytes,4,opt,name=service_uri,json=serviceUri,proto3" json:"service_uri,omitempty"`
+	RewardCut            string   `protobuf:"bytes,5,opt,name=reward_cut,json=rewardCut,proto3" json:"reward_cut,omitempty"`
+	FeeShare             string   `protobuf:"bytes,6,opt,name=fee_share,json=feeShare,proto3" json:"fee_share,omitempty"`
+	DelegatedStake       string   `protobuf:"bytes,7,opt,name=delegated_stake,json=delegatedStake,proto3" json:"delegated_stake,omitempty"`
+	LastRewardRound      string   `protobuf:"bytes,8,opt,name=last_reward_round,json=lastRewardRound,proto3" json:"last_reward_round,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TranscoderStatus) Reset()         { *m = TranscoderStatus{} }
+func (m *TranscoderStatus) String() string { return proto.CompactTextString(m) }
+func (*TranscoderStatus) ProtoMessage()    {}
+func (*TranscoderStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{21}
+}
+
+func (m *TranscoderStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TranscoderStatus.Unmarshal(m, b)
+}
+func (m *TranscoderStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TranscoderStatus.Marshal(b, m, deterministic)
+}
+func (m *TranscoderStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TranscoderStatus.Merge(m, src)
+}
+func (m *TranscoderStatus) XXX_Size() int {
+	return xxx_messageInfo_TranscoderStatus.Size(m)
+}
+func (m *TranscoderStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_TranscoderStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TranscoderStatus proto.InternalMessageInfo
+
+func (m *TranscoderStatus) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *TranscoderStatus) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *TranscoderStatus) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *TranscoderStatus) GetServiceUri() string {
+	if m != nil {
+		return m.ServiceUri
+	}
+	return ""
+}
+
+func (m *TranscoderStatus) GetRewardCut() string {
+	if m != nil {
+		return m.RewardCut
+	}
+	return ""
+}
+
+func (m *TranscoderStatus) GetFeeShare() string {
+	if m != nil {
+		return m.FeeShare
+	}
+	return ""
+}
+
+func (m *TranscoderStatus) GetDelegatedStake() string {
+	if m != nil {
+		return m.DelegatedStake
+	}
+	return ""
+}
+
+func (m *TranscoderStatus) GetLastRewardRound() string {
+	if m != nil {
+		return m.LastRewardRound
+	}
+	return ""
+}
+
+type BondReq struct {
+	Amount               string   `protobuf:"bytes,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	To                   string   `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BondReq) Reset()         { *m = BondReq{} }
+func (m *BondReq) String() string { return proto.CompactTextString(m) }
+func (*BondReq) ProtoMessage()    {}
+func (*BondReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{22}
+}
+
+func (m *BondReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BondReq.Unmarshal(m, b)
+}
+func (m *BondReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BondReq.Marshal(b, m, deterministic)
+}
+func (m *BondReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BondReq.Merge(m, src)
+}
+func (m *BondReq) XXX_Size() int {
+	return xxx_messageInfo_BondReq.Size(m)
+}
+func (m *BondReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_BondReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BondReq proto.InternalMessageInfo
+
+func (m *BondReq) GetAmount() string {
+	if m != nil {
+		return m.Amount
+	}
+	return ""
+}
+
+func (m *BondReq) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+type UnbondReq struct {
+	Amount               string   `protobuf:"bytes,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnbondReq) Reset()         { *m = UnbondReq{} }
+func (m *UnbondReq) String() string { return proto.CompactTextString(m) }
+func (*UnbondReq) ProtoMessage()    {}
+func (*UnbondReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{23}
+}
+
+func (m *UnbondReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnbondReq.Unmarshal(m, b)
+}
+func (m *UnbondReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnbondReq.Marshal(b, m, deterministic)
+}
+func (m *UnbondReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnbondReq.Merge(m, src)
+}
+func (m *UnbondReq) XXX_Size() int {
+	return xxx_messageInfo_UnbondReq.Size(m)
+}
+func (m *UnbondReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnbondReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnbondReq proto.InternalMessageInfo
+
+func (m *UnbondReq) GetAmount() string {
+	if m != nil {
+		return m.Amount
+	}
+	return ""
+}
+
+type RebondReq struct {
+	UnbondingLockId string `protobuf:"bytes,1,opt,name=unbonding_lock_id,json=unbondingLockId,proto3" json:"unbonding_lock_id,omitempty"`
+	// Orchestrator to rebond to, for an unbonded delegator
+	To                   string   `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RebondReq) Reset()         { *m = RebondReq{} }
+func (m *RebondReq) String() string { return proto.CompactTextString(m) }
+func (*RebondReq) ProtoMessage()    {}
+func (*RebondReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{24}
+}
+
+func (m *RebondReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RebondReq.Unmarshal(m, b)
+}
+func (m *RebondReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RebondReq.Marshal(b, m, deterministic)
+}
+func (m *RebondReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RebondReq.Merge(m, src)
+}
+func (m *RebondReq) XXX_Size() int {
+	return xxx_messageInfo_RebondReq.Size(m)
+}
+func (m *RebondReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_RebondReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RebondReq proto.InternalMessageInfo
+
+func (m *RebondReq) GetUnbondingLockId() string {
+	if m != nil {
+		return m.UnbondingLockId
+	}
+	return ""
+}
+
+func (m *RebondReq) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+type WithdrawStakeReq struct {
+	UnbondingLockId      string   `protobuf:"bytes,1,opt,name=unbonding_lock_id,json=unbondingLockId,proto3" json:"unbonding_lock_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WithdrawStakeReq) Reset()         { *m = WithdrawStakeReq{} }
+func (m *WithdrawStakeReq) String() string { return proto.CompactTextString(m) }
+func (*WithdrawStakeReq) ProtoMessage()    {}
+func (*WithdrawStakeReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{25}
+}
+
+func (m *WithdrawStakeReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WithdrawStakeReq.Unmarshal(m, b)
+}
+func (m *WithdrawStakeReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WithdrawStakeReq.Marshal(b, m, deterministic)
+}
+func (m *WithdrawStakeReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WithdrawStakeReq.Merge(m, src)
+}
+func (m *WithdrawStakeReq) XXX_Size() int {
+	return xxx_messageInfo_WithdrawStakeReq.Size(m)
+}
+func (m *WithdrawStakeReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_WithdrawStakeReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WithdrawStakeReq proto.InternalMessageInfo
+
+func (m *WithdrawStakeReq) GetUnbondingLockId() string {
+	if m != nil {
+		return m.UnbondingLockId
+	}
+	return ""
+}
+
+type TxRes struct {
+	TxHash               string   `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TxRes) Reset()         { *m = TxRes{} }
+func (m *TxRes) String() string { return proto.CompactTextString(m) }
+func (*TxRes) ProtoMessage()    {}
+func (*TxRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{26}
+}
+
+func (m *TxRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxRes.Unmarshal(m, b)
+}
+func (m *TxRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxRes.Marshal(b, m, deterministic)
+}
+func (m *TxRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxRes.Merge(m, src)
+}
+func (m *TxRes) XXX_Size() int {
+	return xxx_messageInfo_TxRes.Size(m)
+}
+func (m *TxRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxRes proto.InternalMessageInfo
+
+func (m *TxRes) GetTxHash() string {
+	if m != nil {
+		return m.TxHash
+	}
+	return ""
+}
+
+type GetPaymentsReq struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPaymentsReq) Reset()         { *m = GetPaymentsReq{} }
+func (m *GetPaymentsReq) String() string { return proto.CompactTextString(m) }
+func (*GetPaymentsReq) ProtoMessage()    {}
+func (*GetPaymentsReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{27}
+}
+
+func (m *GetPaymentsReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPaymentsReq.Unmarshal(m, b)
+}
+func (m *GetPaymentsReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPaymentsReq.Marshal(b, m, deterministic)
+}
+func (m *GetPaymentsReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPaymentsReq.Merge(m, src)
+}
+func (m *GetPaymentsReq) XXX_Size() int {
+	return xxx_messageInfo_GetPaymentsReq.Size(m)
+}
+func (m *GetPaymentsReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPaymentsReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPaymentsReq proto.InternalMessageInfo
+
+type Payments struct {
+	Deposit               string   `protobuf:"bytes,1,opt,name=deposit,proto3" json:"deposit,omitempty"`
+	Reserve               string   `protobuf:"bytes,2,opt,name=reserve,proto3" json:"reserve,omitempty"`
+	ClaimedInCurrentRound string   `protobuf:"bytes,3,opt,name=claimed_in_current_round,json=claimedInCurrentRound,proto3" json:"claimed_in_current_round,omitempty"`
+	WithdrawRound         string   `protobuf:"bytes,4,opt,name=withdraw_round,json=withdrawRound,proto3" json:"withdraw_round,omitempty"`
+	XXX_NoUnkeyedLiteral  struct{} `json:"-"`
+	XXX_unrecognized      []byte   `json:"-"`
+	XXX_sizecache         int32    `json:"-"`
+}
+
+func (m *Payments) Reset()         { *m = Payments{} }
+func (m *Payments) String() string { return proto.CompactTextString(m) }
+func (*Payments) ProtoMessage()    {}
+func (*Payments) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{28}
+}
+
+func (m *Payments) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Payments.Unmarshal(m, b)
+}
+func (m *Payments) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Payments.Marshal(b, m, deterministic)
+}
+func (m *Payments) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Payments.Merge(m, src)
+}
+func (m *Payments) XXX_Size() int {
+	return xxx_messageInfo_Payments.Size(m)
+}
+func (m *Payments) XXX_DiscardUnknown() {
+	xxx_messageInfo_Payments.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Payments proto.InternalMessageInfo
+
+func (m *Payments) GetDeposit() string {
+	if m != nil {
+		return m.Deposit
+	}
+	return ""
+}
+
+func (m *Payments) GetReserve() string {
+	if m != nil {
+		return m.Reserve
+	}
+	return ""
+}
+
+func (m *Payments) GetClaimedInCurrentRound() string {
+	if m != nil {
+		return m.ClaimedInCurrentRound
+	}
+	return ""
+}
+
+func (m *Payments) GetWithdrawRound() string {
+	if m != nil {
+		return m.WithdrawRound
+	}
+	return ""
+}
+
+type DepositReq struct {
+	DepositAmount        string   `protobuf:"bytes,1,opt,name=deposit_amount,json=depositAmount,proto3" json:"deposit_amount,omitempty"`
+	ReserveAmount        string   `protobuf:"bytes,2,opt,name=reserve_amount,json=reserveAmount,proto3" json:"reserve_amount,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DepositReq) Reset()         { *m = DepositReq{} }
+func (m *DepositReq) String() string { return proto.CompactTextString(m) }
+func (*DepositReq) ProtoMessage()    {}
+func (*DepositReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{29}
+}
+
+func (m *DepositReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DepositReq.Unmarshal(m, b)
+}
+func (m *DepositReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DepositReq.Marshal(b, m, deterministic)
+}
+func (m *DepositReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DepositReq.Merge(m, src)
+}
+func (m *DepositReq) XXX_Size() int {
+	return xxx_messageInfo_DepositReq.Size(m)
+}
+func (m *DepositReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_DepositReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DepositReq proto.InternalMessageInfo
+
+func (m *DepositReq) GetDepositAmount() string {
+	if m != nil {
+		return m.DepositAmount
+	}
+	return ""
+}
+
+func (m *DepositReq) GetReserveAmount() string {
+	if m != nil {
+		return m.ReserveAmount
+	}
+	return ""
+}
+
+type WatchSessionsReq struct {
+	// Only streams the events of the stream with the manifest ID if set
+	ManifestId           string   `protobuf:"bytes,1,opt,name=manifest_id,json=manifestId,proto3" json:"manifest_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchSessionsReq) Reset()         { *m = WatchSessionsReq{} }
+func (m *WatchSessionsReq) String() string { return proto.CompactTextString(m) }
+func (*WatchSessionsReq) ProtoMessage()    {}
+func (*WatchSessionsReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{30}
+}
+
+func (m *WatchSessionsReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchSessionsReq.Unmarshal(m, b)
+}
+func (m *WatchSessionsReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchSessionsReq.Marshal(b, m, deterministic)
+}
+func (m *WatchSessionsReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchSessionsReq.Merge(m, src)
+}
+func (m *WatchSessionsReq) XXX_Size() int {
+	return xxx_messageInfo_WatchSessionsReq.Size(m)
+}
+func (m *WatchSessionsReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchSessionsReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchSessionsReq proto.InternalMessageInfo
+
+func (m *WatchSessionsReq) GetManifestId() string {
+	if m != nil {
+		return m.ManifestId
+	}
+	return ""
+}
+
+type SessionEvent struct {
+	Type         SessionEvent_Type `protobuf:"varint,1,opt,name=type,proto3,enum=net.SessionEvent_Type" json:"type,omitempty"`
+	ManifestId   string            `protobuf:"bytes,2,opt,name=manifest_id,json=manifestId,proto3" json:"manifest_id,omitempty"`
+	Orchestrator string            `protobuf:"bytes,3,opt,name=orchestrator,proto3" json:"orchestrator,omitempty"`
+	// Unix time in milliseconds
+	Timestamp int64 `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Set for the segment events
+	SeqNo                uint64   `protobuf:"varint,5,opt,name=seq_no,json=seqNo,proto3" json:"seq_no,omitempty"`
+	LatencyMs            int64    `protobuf:"varint,6,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	Error                string   `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SessionEvent) Reset()         { *m = SessionEvent{} }
+func (m *SessionEvent) String() string { return proto.CompactTextString(m) }
+func (*SessionEvent) ProtoMessage()    {}
+func (*SessionEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9afda08a26d393b8, []int{31}
+}
+
+func (m *SessionEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionEvent.Unmarshal(m, b)
+}
+func (m *SessionEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionEvent.Marshal(b, m, deterministic)
+}
+func (m *SessionEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionEvent.Merge(m, src)
+}
+func (m *SessionEvent) XXX_Size() int {
+	return xxx_messageInfo_SessionEvent.Size(m)
+}
+func (m *SessionEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionEvent proto.InternalMessageInfo
+
+func (m *SessionEvent) GetType() SessionEvent_Type {
+	if m != nil {
+		return m.Type
+	}
+	return SessionEvent_SESSION_ADDED
+}
+
+func (m *SessionEvent) GetManifestId() string {
+	if m != nil {
+		return m.ManifestId
+	}
+	return ""
+}
+
+func (m *SessionEvent) GetOrchestrator() string {
+	if m != nil {
+		return m.Orchestrator
+	}
+	return ""
+}
+
+func (m *SessionEvent) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *SessionEvent) GetSeqNo() uint64 {
+	if m != nil {
+		return m.SeqNo
+	}
+	return 0
+}
+
+func (m *SessionEvent) GetLatencyMs() int64 {
+	if m != nil {
+		return m.LatencyMs
+	}
+	return 0
+}
+
+func (m *SessionEvent) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterEnum("net.SessionEvent_Type", SessionEvent_Type_name, SessionEvent_Type_value)
+	proto.RegisterType((*StatusReq)(nil), "net.StatusReq")
+	proto.RegisterType((*NodeStatus)(nil), "net.NodeStatus")
+	proto.RegisterType((*ListStreamsReq)(nil), "net.ListStreamsReq")
+	proto.RegisterType((*ListStreamsRes)(nil), "net.ListStreamsRes")
+	proto.RegisterType((*StreamStatus)(nil), "net.StreamStatus")
+	proto.RegisterMapType((map[string]int64)(nil), "net.StreamStatus.ErrorsEntry")
+	proto.RegisterType((*SegmentStatus)(nil), "net.SegmentStatus")
+	proto.RegisterType((*StreamHealth)(nil), "net.StreamHealth")
+	proto.RegisterType((*TerminateStreamReq)(nil), "net.TerminateStreamReq")
+	proto.RegisterType((*TerminateStreamRes)(nil), "net.TerminateStreamRes")
+	proto.RegisterType((*ListSessionsReq)(nil), "net.ListSessionsReq")
+	proto.RegisterType((*ListSessionsRes)(nil), "net.ListSessionsRes")
+	proto.RegisterType((*SessionStatus)(nil), "net.SessionStatus")
+	proto.RegisterType((*SessionTicketParams)(nil), "net.SessionTicketParams")
+	proto.RegisterType((*GetPricingReq)(nil), "net.GetPricingReq")
+	proto.RegisterType((*Pricing)(nil), "net.Pricing")
+	proto.RegisterType((*Price)(nil), "net.Price")
+	proto.RegisterType((*SetPriceReq)(nil), "net.SetPriceReq")
+	proto.RegisterType((*RemovePriceReq)(nil), "net.RemovePriceReq")
+	proto.RegisterType((*GetStakingReq)(nil), "net.GetStakingReq")
+	proto.RegisterType((*Staking)(nil), "net.Staking")
+	proto.RegisterType((*Delegator)(nil), "net.Delegator")
+	proto.RegisterType((*TranscoderStatus)(nil), "net.TranscoderStatus")
+	proto.RegisterType((*BondReq)(nil), "net.BondReq")
+	proto.RegisterType((*UnbondReq)(nil), "net.UnbondReq")
+	proto.RegisterType((*RebondReq)(nil), "net.RebondReq")
+	proto.RegisterType((*WithdrawStakeReq)(nil), "net.WithdrawStakeReq")
+	proto.RegisterType((*TxRes)(nil), "net.TxRes")
+	proto.RegisterType((*GetPaymentsReq)(nil), "net.GetPaymentsReq")
+	proto.RegisterType((*Payments)(nil), "net.Payments")
+	proto.RegisterType((*DepositReq)(nil), "net.DepositReq")
+	proto.RegisterType((*WatchSessionsReq)(nil), "net.WatchSessionsReq")
+	proto.RegisterType((*SessionEvent)(nil), "net.SessionEvent")
+}
+
+func init() {
+	proto.RegisterFile("net/admin.proto", fileDescriptor_9afda08a26d393b8)
+}
+
+var fileDescriptor_9afda08a26d393b8 = []byte{
+	// 2067 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x58, 0x5f, 0x6f, 0x1b, 0xc7,
+	0x11, 0x17, 0x49, 0x89, 0xe4, 0x0d, 0xff, 0x6a, 0x6d, 0xcb, 0x8c, 0x92, 0x34, 0xea, 0xa5, 0x4e,
+	0x15, 0xdb, 0x50, 0x13, 0xb9, 0x46, 0x9b, 0xb4, 0x2e, 0x20, 0x5b, 0xb4, 0x23, 0xc0, 0xff, 0x70,
+	0x94, 0x6d, 0x20, 0x2f, 0x87, 0xd5, 0xdd, 0x88, 0x3c, 0x88, 0xbc, 0x3d, 0xef, 0x2e, 0xf5, 0xe7,
+	0xb5, 0x1f, 0xa0, 0x4f, 0x05, 0x5a, 0xa0, 0x68, 0x1f, 0xfb, 0x4d, 0xfa, 0x31, 0x0a, 0xf4, 0xad,
+	0x5f, 0xa3, 0x98, 0xdd, 0x3d, 0xf2, 0x48, 0x29, 0x95, 0x93, 0xb7, 0x9b, 0xdf, 0xfc, 0x76, 0x77,
+	0x76, 0x66, 0x76, 0x76, 0xf6, 0xa0, 0x93, 0xa2, 0xfe, 0x15, 0x8f, 0x27, 0x49, 0xba, 0x93, 0x49,
+	0xa1, 0x05, 0xab, 0xa4, 0xa8, 0xfd, 0x06, 0x78, 0x03, 0xcd, 0xf5, 0x54, 0x05, 0xf8, 0xde, 0xff,
+	0x6f, 0x19, 0xe0, 0xa5, 0x88, 0xd1, 0x22, 0xec, 0x63, 0xf0, 0x52, 0x11, 0x63, 0xa8, 0x2f, 0x32,
+	0xec, 0x95, 0xb6, 0x4a, 0xdb, 0x5e, 0x50, 0x27, 0xe0, 0xf0, 0x22, 0x43, 0xd6, 0x83, 0xda, 0x29,
+	0x4a, 0x95, 0x88, 0xb4, 0x57, 0x36, 0xaa, 0x5c, 0x64, 0x9f, 0x02, 0x0c, 0x45, 0x98, 0x2b, 0x2b,
+	0x46, 0xe9, 0x0d, 0xc5, 0x5b, 0xa7, 0x66, 0xb0, 0x3a, 0x14, 0x42, 0xf5, 0x56, 0x8d, 0xc2, 0x7c,
+	0xb3, 0x0d, 0xa8, 0x0e, 0x05, 0x97, 0xd1, 0xa8, 0xb7, 0x66, 0x50, 0x27, 0xb1, 0xcf, 0xa0, 0x81,
+	0x7a, 0x14, 0xf2, 0x38, 0x96, 0xa8, 0x54, 0xaf, 0x6a, 0x94, 0x80, 0x7a, 0xb4, 0x67, 0x11, 0x76,
+	0x07, 0xda, 0x3c, 0xd2, 0xc9, 0x29, 0x86, 0x4a, 0x4b, 0xe4, 0x13, 0xd5, 0xab, 0x6d, 0x95, 0xb6,
+	0x2b, 0x41, 0xcb, 0xa2, 0x03, 0x0b, 0xb2, 0x7b, 0xb0, 0x2e, 0x64, 0x34, 0x42, 0xa5, 0x25, 0xd7,
+	0x42, 0x86, 0x99, 0x10, 0xe3, 0x5e, 0x7d, 0xab, 0xb2, 0xed, 0x05, 0xdd, 0xa2, 0xe2, 0xb5, 0x10,
+	0x63, 0xf6, 0x10, 0x36, 0x24, 0x0e, 0x13, 0xa5, 0x51, 0x62, 0x1c, 0x6a, 0xc9, 0x53, 0x15, 0x89,
+	0x18, 0xa5, 0xea, 0x79, 0x66, 0xee, 0x5b, 0x73, 0xed, 0xe1, 0x5c, 0x49, 0x6b, 0x8c, 0x45, 0xc4,
+	0xc7, 0xb3, 0x11, 0x49, 0x3a, 0xec, 0xc1, 0x56, 0x69, 0xbb, 0x1e, 0x74, 0x8d, 0xe2, 0x70, 0x8e,
+	0xfb, 0x5f, 0x43, 0xfb, 0x79, 0xa2, 0xb4, 0xb3, 0x2f, 0xc0, 0xf7, 0xb4, 0xd5, 0x09, 0x4f, 0x93,
+	0x63, 0x54, 0x3a, 0x4c, 0x62, 0xe7, 0x6e, 0xc8, 0xa1, 0x83, 0xd8, 0x7f, 0xb4, 0x34, 0x84, 0x56,
+	0xac, 0xe5, 0xbb, 0x2e, 0x6d, 0x55, 0xb6, 0x1b, 0xbb, 0xeb, 0x3b, 0x29, 0xea, 0x1d, 0xcb, 0x70,
+	0x51, 0xcd, 0x19, 0xfe, 0x3f, 0x57, 0xa1, 0x59, 0xd4, 0x5c, 0xbb, 0x20, 0xbb, 0x0f, 0x0c, 0xcf,
+	0x35, 0xca, 0x94, 0x8f, 0x9d, 0x77, 0x89, 0x67, 0x83, 0xdd, 0xcd, 0x35, 0x76, 0xca, 0x83, 0x98,
+	0x6d, 0x42, 0x3d, 0x93, 0xe2, 0x38, 0x19, 0xa3, 0xea, 0x55, 0x8c, 0x67, 0x67, 0x32, 0xdb, 0x81,
+	0xba, 0x42, 0x45, 0xd1, 0xa7, 0xb0, 0x93, 0xa5, 0xcc, 0x5a, 0x6a, 0x41, 0x67, 0xea, 0x8c, 0xc3,
+	0x3e, 0x01, 0x4f, 0x4d, 0xa3, 0x08, 0x31, 0xc6, 0xd8, 0x64, 0x44, 0x25, 0x98, 0x03, 0x94, 0x2c,
+	0xc7, 0x3c, 0x19, 0x63, 0x6c, 0xf2, 0xa1, 0x12, 0x38, 0x89, 0x3d, 0x84, 0x2a, 0x4a, 0x29, 0x24,
+	0xe5, 0x00, 0xad, 0xf1, 0xe9, 0x25, 0x6f, 0xec, 0xf4, 0x8d, 0xbe, 0x9f, 0x6a, 0x79, 0x11, 0x38,
+	0x32, 0xfb, 0x1d, 0x74, 0x24, 0x46, 0x98, 0xea, 0x50, 0xe1, 0x70, 0x82, 0xa9, 0x56, 0xbd, 0xfa,
+	0x82, 0x8d, 0x06, 0x74, 0x36, 0xb6, 0x2d, 0xd5, 0x81, 0x8a, 0x7d, 0x09, 0xd5, 0x11, 0xf2, 0xb1,
+	0x1e, 0x99, 0xdc, 0x58, 0x8c, 0xc0, 0x77, 0x46, 0x11, 0x38, 0x02, 0x1d, 0x0b, 0xa5, 0xb9, 0xd4,
+	0x18, 0x87, 0x5c, 0xf7, 0xc0, 0xed, 0xca, 0x22, 0x7b, 0x9a, 0xfd, 0x1c, 0x9a, 0x4a, 0x4c, 0x65,
+	0x84, 0xe1, 0xd1, 0x85, 0x46, 0xd5, 0x6b, 0x6c, 0x95, 0xb6, 0x57, 0x83, 0x86, 0xc5, 0x1e, 0x13,
+	0xc4, 0xbe, 0x84, 0xee, 0x2c, 0x1b, 0x63, 0x47, 0x6b, 0x1a, 0x5a, 0x67, 0x8e, 0x1b, 0xea, 0xe6,
+	0x37, 0xd0, 0x28, 0xec, 0x95, 0x75, 0xa1, 0x72, 0x82, 0x17, 0x2e, 0xc6, 0xf4, 0xc9, 0x6e, 0xc2,
+	0xda, 0x29, 0x1f, 0x4f, 0xd1, 0xc4, 0xb3, 0x12, 0x58, 0xe1, 0xdb, 0xf2, 0x6f, 0x4b, 0xfe, 0xdf,
+	0x4a, 0xd0, 0x5a, 0xd8, 0x34, 0xbb, 0x05, 0x55, 0x85, 0xef, 0xc3, 0x54, 0x98, 0x09, 0x56, 0x83,
+	0x35, 0x85, 0xef, 0x5f, 0x0a, 0xe6, 0x43, 0xb3, 0x78, 0x76, 0x5c, 0x66, 0x2c, 0x60, 0x94, 0x15,
+	0x5c, 0x6b, 0x9c, 0x64, 0x5a, 0x99, 0x4a, 0x50, 0x09, 0x66, 0x32, 0x39, 0x64, 0xcc, 0x35, 0xa6,
+	0xd1, 0x45, 0x38, 0xb1, 0xe5, 0xa0, 0x12, 0x78, 0x0e, 0x79, 0xa1, 0xc8, 0x42, 0x13, 0x21, 0x57,
+	0x12, 0xac, 0xe0, 0xff, 0xbb, 0x04, 0xcd, 0xa2, 0x7b, 0x89, 0xa6, 0x22, 0x21, 0x6d, 0x81, 0x2a,
+	0x05, 0x56, 0x30, 0xa8, 0xe6, 0x1a, 0x9d, 0x51, 0x56, 0x20, 0x1f, 0xc7, 0x52, 0x64, 0x19, 0xc6,
+	0xa1, 0x24, 0x65, 0xc5, 0x0c, 0x69, 0x38, 0x2c, 0x20, 0xca, 0x1d, 0x68, 0x4b, 0x9a, 0x38, 0x99,
+	0x20, 0x71, 0x12, 0x61, 0x0c, 0x2b, 0x05, 0xad, 0x1c, 0x0d, 0x08, 0x64, 0xdf, 0xc2, 0x47, 0xa7,
+	0x28, 0x93, 0xe3, 0x24, 0x22, 0x31, 0x0d, 0x29, 0x05, 0xa7, 0x12, 0xed, 0xb4, 0x6b, 0x66, 0xc4,
+	0xed, 0x22, 0xe1, 0xa9, 0xd5, 0x9b, 0x25, 0x3e, 0x06, 0x4f, 0x9d, 0xf1, 0xcc, 0x72, 0xab, 0x86,
+	0x5b, 0x27, 0x80, 0x94, 0xfe, 0x3b, 0x60, 0x87, 0x28, 0x27, 0x49, 0xca, 0xb5, 0xab, 0x5e, 0x1f,
+	0x52, 0x1c, 0xd8, 0x16, 0x34, 0x8f, 0xc6, 0x22, 0x3a, 0x09, 0x8f, 0x85, 0x24, 0x6f, 0xda, 0xa8,
+	0x82, 0xc1, 0x9e, 0x0a, 0xf9, 0x42, 0xf9, 0x37, 0xaf, 0x98, 0x58, 0xf9, 0xeb, 0xd0, 0x31, 0x45,
+	0xc5, 0x9d, 0x3c, 0xba, 0x04, 0xf6, 0x96, 0xa1, 0xc5, 0xf3, 0x5b, 0xba, 0xfe, 0xfc, 0xfa, 0xff,
+	0x29, 0x53, 0x0a, 0x15, 0x74, 0xd7, 0x6f, 0xe0, 0x43, 0x92, 0xa9, 0x07, 0xb5, 0xfc, 0x26, 0xb0,
+	0xb7, 0x4a, 0x2e, 0x92, 0x46, 0xcb, 0xa9, 0xd2, 0x18, 0x9b, 0x70, 0xd5, 0x83, 0x5c, 0x64, 0xbf,
+	0x80, 0x76, 0x26, 0x93, 0x08, 0xc3, 0x0c, 0x65, 0x38, 0x4d, 0x13, 0xed, 0xea, 0x49, 0xd3, 0xa0,
+	0xaf, 0x51, 0xbe, 0x49, 0x13, 0xcd, 0xbe, 0x80, 0x4e, 0x96, 0x9c, 0xe3, 0x58, 0xcd, 0x69, 0xb6,
+	0xb6, 0xb4, 0x2c, 0x9c, 0xf3, 0x1e, 0x41, 0x4b, 0x27, 0xd1, 0x09, 0xea, 0x30, 0xe3, 0x32, 0xbf,
+	0x6d, 0x1a, 0xbb, 0xbd, 0xa2, 0x37, 0x0e, 0x0d, 0xe1, 0xb5, 0xd1, 0x07, 0x4d, 0x5d, 0x90, 0xc8,
+	0x18, 0x85, 0x43, 0x15, 0x26, 0x69, 0x78, 0x3c, 0x4e, 0x86, 0x23, 0xdd, 0xab, 0x5b, 0x63, 0x08,
+	0x3d, 0x48, 0x9f, 0x1a, 0x8c, 0x7d, 0x0e, 0xad, 0xfc, 0x5c, 0xd8, 0xcc, 0xf6, 0x4c, 0x8e, 0x34,
+	0x1d, 0x38, 0x20, 0xcc, 0xff, 0x6b, 0x09, 0x6e, 0x5c, 0xb1, 0x20, 0x95, 0x4e, 0x89, 0x51, 0x92,
+	0x25, 0x98, 0x6a, 0xe7, 0xe6, 0x39, 0x40, 0x47, 0xee, 0x98, 0x47, 0x18, 0xce, 0x8f, 0xbe, 0x17,
+	0x78, 0x84, 0xbc, 0x25, 0x80, 0x7d, 0x04, 0xf5, 0xb3, 0x24, 0x0d, 0x33, 0x29, 0x8e, 0x72, 0x0f,
+	0x9f, 0x25, 0xe9, 0x6b, 0x29, 0x8e, 0xa8, 0xf6, 0xe0, 0x79, 0x96, 0x48, 0x9b, 0xee, 0x26, 0xaf,
+	0xdc, 0x0d, 0xde, 0x99, 0xe3, 0x8f, 0x09, 0xf6, 0x3b, 0xd0, 0x7a, 0x86, 0xfa, 0xb5, 0x4c, 0xa2,
+	0x24, 0x1d, 0x52, 0x46, 0x71, 0xa8, 0x39, 0x89, 0xdd, 0x83, 0xc6, 0x11, 0x57, 0x18, 0x1a, 0xef,
+	0xe7, 0xc9, 0x04, 0xc6, 0x7d, 0x44, 0xc1, 0x00, 0x48, 0x6d, 0x3e, 0xa9, 0xde, 0xc1, 0x84, 0x9f,
+	0xe7, 0xdc, 0xf2, 0x25, 0xae, 0x37, 0xe1, 0xe7, 0x96, 0xea, 0xff, 0xa5, 0x04, 0x6b, 0xe6, 0x93,
+	0x6d, 0x41, 0xe3, 0x48, 0x0a, 0x1e, 0x47, 0x5c, 0x69, 0x94, 0xce, 0x05, 0x45, 0x88, 0xfd, 0x0c,
+	0x20, 0xe2, 0x19, 0x3f, 0x4a, 0xc6, 0x89, 0xbe, 0x70, 0x4e, 0x28, 0x20, 0x57, 0xa4, 0x8c, 0xf5,
+	0xc5, 0xb5, 0x29, 0x63, 0xfd, 0xb1, 0x98, 0x32, 0xfe, 0xdf, 0x4b, 0xd0, 0x18, 0x58, 0x77, 0x20,
+	0x1d, 0xe5, 0xeb, 0xed, 0xbb, 0xbc, 0x7e, 0xf9, 0xc3, 0xd6, 0xaf, 0x5c, 0xb1, 0xfe, 0xd2, 0x6e,
+	0x57, 0x97, 0x77, 0xeb, 0x07, 0xd0, 0x0e, 0x70, 0x22, 0x4e, 0xf1, 0x47, 0x58, 0x78, 0x8d, 0x07,
+	0x5d, 0x06, 0x0c, 0x34, 0x3f, 0x71, 0x19, 0x90, 0x42, 0xcd, 0x49, 0xec, 0x3e, 0x78, 0x31, 0x8e,
+	0x71, 0x68, 0x4e, 0x79, 0xc9, 0x1c, 0x9f, 0xb6, 0x89, 0xe9, 0x7e, 0x8e, 0x06, 0x73, 0x02, 0x7b,
+	0x08, 0x30, 0x6f, 0xc0, 0xcc, 0x4a, 0x8d, 0xdd, 0x5b, 0x86, 0x3e, 0x6f, 0xbd, 0x5c, 0xf9, 0x29,
+	0x10, 0xfd, 0x3f, 0x57, 0xc0, 0x9b, 0xcd, 0x57, 0xac, 0x1b, 0xa5, 0xc5, 0xba, 0xb1, 0x01, 0x55,
+	0x65, 0x46, 0xbb, 0x4d, 0x38, 0x89, 0x8e, 0xe0, 0x91, 0x48, 0xe9, 0x96, 0xe5, 0x13, 0x31, 0x4d,
+	0x67, 0x19, 0x60, 0xc1, 0x3d, 0x83, 0x11, 0x29, 0xc3, 0x94, 0xda, 0xb9, 0x50, 0x69, 0x7e, 0x82,
+	0xce, 0xb9, 0x4d, 0x07, 0xd2, 0x86, 0x91, 0xba, 0xdd, 0x63, 0x44, 0xe5, 0x2e, 0x31, 0xf3, 0x4d,
+	0xd7, 0x50, 0x3e, 0xd0, 0xe8, 0x6c, 0x5b, 0xdb, 0x70, 0xd8, 0x53, 0xb4, 0x57, 0xbd, 0x73, 0x02,
+	0xce, 0xba, 0xdf, 0x9a, 0x3d, 0x6e, 0x39, 0x9e, 0xb7, 0xc0, 0x05, 0xea, 0xcc, 0xdc, 0xfa, 0x22,
+	0x35, 0xb7, 0xf8, 0x33, 0x68, 0x98, 0x86, 0x23, 0x94, 0x62, 0x9a, 0xc6, 0xa6, 0xae, 0x78, 0x81,
+	0xed, 0x4a, 0x02, 0x42, 0xd8, 0x36, 0x74, 0xc7, 0x5c, 0xe9, 0x30, 0x1a, 0xf3, 0x64, 0xe2, 0x58,
+	0x60, 0x58, 0x6d, 0xc2, 0x9f, 0x10, 0x6c, 0x99, 0x0f, 0x60, 0x23, 0xc5, 0x73, 0x1d, 0x4e, 0xd3,
+	0x23, 0x61, 0xb7, 0x62, 0xae, 0x9f, 0x24, 0x36, 0x8d, 0x8b, 0x17, 0xdc, 0x20, 0xed, 0x9b, 0x5c,
+	0xf9, 0x5c, 0x44, 0x27, 0x07, 0xb1, 0xff, 0xa7, 0x32, 0x74, 0x97, 0xe3, 0xf6, 0x13, 0xa2, 0xb3,
+	0x01, 0x55, 0xdb, 0xde, 0x9b, 0xb0, 0xd4, 0x03, 0x27, 0x99, 0xed, 0xa1, 0x3c, 0xa5, 0xa3, 0x33,
+	0x95, 0x49, 0x9e, 0xeb, 0x0e, 0x7a, 0x23, 0x13, 0x2a, 0x7f, 0x12, 0xcf, 0xb8, 0x8c, 0xc3, 0x68,
+	0xaa, 0x5d, 0x48, 0x3c, 0x8b, 0x3c, 0x99, 0x6a, 0xba, 0x98, 0x8f, 0x11, 0x43, 0x35, 0xe2, 0x12,
+	0x5d, 0x50, 0xea, 0xc7, 0x88, 0x03, 0x92, 0xd9, 0x2f, 0x61, 0xee, 0x4e, 0x17, 0x6f, 0x1b, 0x90,
+	0xf6, 0x0c, 0xb6, 0x11, 0xbf, 0x0b, 0xeb, 0xc6, 0x87, 0x6e, 0x25, 0xeb, 0x44, 0x17, 0x10, 0x52,
+	0x04, 0x06, 0x37, 0x5e, 0xf4, 0xbf, 0x86, 0xda, 0x63, 0x91, 0xc6, 0x74, 0xea, 0x68, 0x53, 0x36,
+	0x78, 0xd6, 0x0b, 0x4e, 0x62, 0x6d, 0x28, 0x6b, 0xe1, 0x1c, 0x50, 0xd6, 0xc2, 0xff, 0x1c, 0x3c,
+	0xeb, 0xd6, 0xff, 0x33, 0xc8, 0x7f, 0x06, 0x5e, 0x80, 0x39, 0xe9, 0x2e, 0xac, 0x5f, 0x8e, 0x92,
+	0xe5, 0x77, 0xa6, 0x8b, 0x11, 0xba, 0xb4, 0xda, 0x1f, 0xa0, 0xfb, 0x2e, 0xd1, 0xa3, 0x58, 0xf2,
+	0x33, 0xb3, 0xbb, 0x1f, 0x39, 0x9f, 0xbf, 0x05, 0x6b, 0x87, 0xe7, 0xd4, 0x42, 0xdc, 0x86, 0x9a,
+	0x3e, 0x0f, 0x47, 0x5c, 0x8d, 0x72, 0x53, 0xf5, 0xf9, 0x77, 0x5c, 0x8d, 0xfc, 0x2e, 0xb4, 0xe9,
+	0xb6, 0xe0, 0x17, 0xa6, 0xa1, 0xa6, 0x62, 0xf1, 0x8f, 0x12, 0xd4, 0x73, 0x99, 0xb2, 0x23, 0xc6,
+	0x4c, 0xa8, 0x24, 0xdf, 0x62, 0x2e, 0x92, 0x46, 0x22, 0x05, 0x37, 0xbf, 0xc8, 0x72, 0x91, 0xfd,
+	0x06, 0x7a, 0x26, 0x81, 0x31, 0xa6, 0x9b, 0x36, 0x9a, 0x4a, 0x49, 0xdd, 0xbd, 0x0d, 0x84, 0x3d,
+	0xc8, 0xb7, 0x9c, 0xfe, 0x20, 0x7d, 0x62, 0xb5, 0x36, 0xa9, 0xef, 0x40, 0xfb, 0xcc, 0xed, 0xd6,
+	0xd1, 0x5d, 0x49, 0xcf, 0x51, 0x1b, 0xb5, 0xef, 0x01, 0xf6, 0xad, 0x11, 0xe4, 0x8e, 0x3b, 0xd0,
+	0x76, 0x26, 0x85, 0x0b, 0xb1, 0x68, 0x39, 0xd4, 0x9d, 0x3d, 0xd3, 0x58, 0x1a, 0xfb, 0x72, 0x9a,
+	0xb5, 0xba, 0xe5, 0x50, 0x4b, 0xf3, 0x1f, 0x40, 0xf7, 0x1d, 0xd7, 0xd1, 0xa8, 0xd0, 0x91, 0x5d,
+	0xff, 0x34, 0xfc, 0x57, 0x19, 0x9a, 0x6e, 0x40, 0xff, 0x94, 0xee, 0xf9, 0xbb, 0xb0, 0x3a, 0x7b,
+	0xb4, 0xb7, 0x77, 0x37, 0x8a, 0xed, 0x89, 0x21, 0xec, 0xd0, 0x13, 0x3e, 0x30, 0x9c, 0xe5, 0xd9,
+	0xcb, 0xd7, 0xb6, 0x66, 0x95, 0x2b, 0x5a, 0xb3, 0x4f, 0xc0, 0xa3, 0xe6, 0x58, 0x69, 0x3e, 0xc9,
+	0xf2, 0x56, 0x7e, 0x06, 0x14, 0x1e, 0x10, 0x6b, 0xc5, 0x07, 0xc4, 0xe2, 0x03, 0xa0, 0xfa, 0x83,
+	0x0f, 0x80, 0x5a, 0xf1, 0x01, 0xf0, 0x3d, 0xac, 0x92, 0xf1, 0x6c, 0x1d, 0x5a, 0x83, 0xfe, 0x60,
+	0x70, 0xf0, 0xea, 0x65, 0xb8, 0xb7, 0xbf, 0xdf, 0xdf, 0xef, 0xae, 0xb0, 0x1b, 0xd0, 0xc9, 0xa1,
+	0xa0, 0xff, 0xe2, 0xd5, 0xdb, 0xfe, 0x7e, 0xb7, 0xc4, 0x36, 0x80, 0x0d, 0xfa, 0xcf, 0x5e, 0xf4,
+	0x5f, 0x1e, 0x86, 0x87, 0xc1, 0xde, 0xcb, 0xc1, 0x93, 0x57, 0x44, 0x2e, 0x33, 0x06, 0xed, 0x1c,
+	0x7f, 0xba, 0x77, 0xf0, 0xbc, 0xbf, 0xdf, 0xad, 0xec, 0xfe, 0xb1, 0x0a, 0x6b, 0x7b, 0xf4, 0x87,
+	0x84, 0xdd, 0x83, 0xaa, 0x2b, 0x4f, 0x6d, 0xf7, 0xa2, 0x73, 0xff, 0x48, 0x36, 0x3b, 0x46, 0x9e,
+	0xff, 0x25, 0xf1, 0x57, 0xd8, 0x37, 0xd0, 0x28, 0xbc, 0xcc, 0xd9, 0x0d, 0xc3, 0x58, 0x7c, 0xde,
+	0x6f, 0x5e, 0x01, 0xd2, 0xd0, 0x3e, 0x74, 0x96, 0xba, 0x72, 0x76, 0xdb, 0x5e, 0x6f, 0x97, 0x1e,
+	0x01, 0x9b, 0x3f, 0xa0, 0xa0, 0x69, 0x7e, 0x0f, 0xcd, 0x62, 0xcf, 0xce, 0x6e, 0xce, 0x57, 0x9b,
+	0xe7, 0xd1, 0xe6, 0x55, 0x28, 0x8d, 0xfe, 0x0a, 0x60, 0xde, 0xb0, 0x31, 0xdb, 0xda, 0x2f, 0x74,
+	0x70, 0x9b, 0xcd, 0x59, 0xd7, 0x45, 0x3f, 0x2f, 0x56, 0xd8, 0x7d, 0xa8, 0xe7, 0x3d, 0x0d, 0xeb,
+	0xba, 0xec, 0x9a, 0xb5, 0x38, 0x97, 0xd8, 0xbb, 0xd0, 0x28, 0xb4, 0x18, 0xce, 0x3f, 0x8b, 0x4d,
+	0xc7, 0xa5, 0x31, 0xd6, 0xa6, 0xbc, 0x69, 0x98, 0xd9, 0x34, 0xef, 0x29, 0xdc, 0x08, 0x07, 0xf8,
+	0x2b, 0xcc, 0x87, 0x55, 0xaa, 0xa5, 0xcc, 0xe2, 0xae, 0xac, 0x6e, 0xda, 0x7e, 0xd1, 0xd4, 0x20,
+	0x7f, 0x85, 0x7d, 0x01, 0x55, 0x5b, 0x3c, 0x5d, 0x58, 0x67, 0x95, 0xf4, 0x32, 0x2f, 0xc0, 0x02,
+	0x2f, 0xc0, 0xab, 0x79, 0xbf, 0x86, 0xd6, 0x42, 0x79, 0x64, 0xb6, 0x37, 0x59, 0x2e, 0x99, 0x4b,
+	0xa3, 0x1e, 0x40, 0xa3, 0x50, 0xf2, 0x9c, 0x3f, 0x16, 0x8b, 0xe0, 0x66, 0xcb, 0xfa, 0xc3, 0x21,
+	0xfe, 0x0a, 0xdb, 0x86, 0x9a, 0x2b, 0x3a, 0xac, 0xe3, 0xfa, 0xa5, 0xbc, 0x04, 0x2d, 0x4d, 0xff,
+	0x08, 0x5a, 0x0b, 0x25, 0x24, 0x37, 0x6a, 0xa9, 0xac, 0x6c, 0xae, 0x5f, 0x2a, 0x0b, 0xfe, 0xca,
+	0x57, 0xa5, 0xa3, 0xaa, 0xf9, 0x3b, 0xf8, 0xe0, 0x7f, 0x03, 0x00, 0xd6, 0x43, 0xe4, 0x2c, 0x30,
+	0x14, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	Status(ctx context.Context, in *StatusReq, opts ...grpc.CallOption) (*NodeStatus, error)
+	ListStreams(ctx context.Context, in *ListStreamsReq, opts ...grpc.CallOption) (*ListStreamsRes, error)
+	// TerminateStream ends a stream, disconnecting its publisher
+	TerminateStream(ctx context.Context, in *TerminateStreamReq, opts ...grpc.CallOption) (*TerminateStreamRes, error)
+	ListSessions(ctx context.Context, in *ListSessionsReq, opts ...grpc.CallOption) (*ListSessionsRes, error)
+	GetPricing(ctx context.Context, in *GetPricingReq, opts ...grpc.CallOption) (*Pricing, error)
+	SetPrice(ctx context.Context, in *SetPriceReq, opts ...grpc.CallOption) (*Pricing, error)
+	RemovePrice(ctx context.Context, in *RemovePriceReq, opts ...grpc.CallOption) (*Pricing, error)
+	GetStaking(ctx context.Context, in *GetStakingReq, opts ...grpc.CallOption) (*Staking, error)
+	Bond(ctx context.Context, in *BondReq, opts ...grpc.CallOption) (*TxRes, error)
+	Unbond(ctx context.Context, in *UnbondReq, opts ...grpc.CallOption) (*TxRes, error)
+	Rebond(ctx context.Context, in *RebondReq, opts ...grpc.CallOption) (*TxRes, error)
+	WithdrawStake(ctx context.Context, in *WithdrawStakeReq, opts ...grpc.CallOption) (*TxRes, error)
+	GetPayments(ctx context.Context, in *GetPaymentsReq, opts ...grpc.CallOption) (*Payments, error)
+	Deposit(ctx context.Context, in *DepositReq, opts ...grpc.CallOption) (*TxRes, error)
+	// WatchSessions streams the events of the sessions of the broadcaster as they happen
+	WatchSessions(ctx context.Context, in *WatchSessionsReq, opts ...grpc.CallOption) (Admin_WatchSessionsClient, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) Status(ctx context.Context, in *StatusReq, opts ...grpc.CallOption) (*NodeStatus, error) {
+	out := new(NodeStatus)
+	err := c.cc.Invoke(ctx, "/net.Admin/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListStreams(ctx context.Context, in *ListStreamsReq, opts ...grpc.CallOption) (*ListStreamsRes, error) {
+	out := new(ListStreamsRes)
+	err := c.cc.Invoke(ctx, "/net.Admin/ListStreams", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) TerminateStream(ctx context.Context, in *TerminateStreamReq, opts ...grpc.CallOption) (*TerminateStreamRes, error) {
+	out := new(TerminateStreamRes)
+	err := c.cc.Invoke(ctx, "/net.Admin/TerminateStream", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListSessions(ctx context.Context, in *ListSessionsReq, opts ...grpc.CallOption) (*ListSessionsRes, error) {
+	out := new(ListSessionsRes)
+	err := c.cc.Invoke(ctx, "/net.Admin/ListSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetPricing(ctx context.Context, in *GetPricingReq, opts ...grpc.CallOption) (*Pricing, error) {
+	out := new(Pricing)
+	err := c.cc.Invoke(ctx, "/net.Admin/GetPricing", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetPrice(ctx context.Context, in *SetPriceReq, opts ...grpc.CallOption) (*Pricing, error) {
+	out := new(Pricing)
+	err := c.cc.Invoke(ctx, "/net.Admin/SetPrice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RemovePrice(ctx context.Context, in *RemovePriceReq, opts ...grpc.CallOption) (*Pricing, error) {
+	out := new(Pricing)
+	err := c.cc.Invoke(ctx, "/net.Admin/RemovePrice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetStaking(ctx context.Context, in *GetStakingReq, opts ...grpc.CallOption) (*Staking, error) {
+	out := new(Staking)
+	err := c.cc.Invoke(ctx, "/net.Admin/GetStaking", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Bond(ctx context.Context, in *BondReq, opts ...grpc.CallOption) (*TxRes, error) {
+	out := new(TxRes)
+	err := c.cc.Invoke(ctx, "/net.Admin/Bond", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Unbond(ctx context.Context, in *UnbondReq, opts ...grpc.CallOption) (*TxRes, error) {
+	out := new(TxRes)
+	err := c.cc.Invoke(ctx, "/net.Admin/Unbond", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Rebond(ctx context.Context, in *RebondReq, opts ...grpc.CallOption) (*TxRes, error) {
+	out := new(TxRes)
+	err := c.cc.Invoke(ctx, "/net.Admin/Rebond", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) WithdrawStake(ctx context.Context, in *WithdrawStakeReq, opts ...grpc.CallOption) (*TxRes, error) {
+	out := new(TxRes)
+	err := c.cc.Invoke(ctx, "/net.Admin/WithdrawStake", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetPayments(ctx context.Context, in *GetPaymentsReq, opts ...grpc.CallOption) (*Payments, error) {
+	out := new(Payments)
+	err := c.cc.Invoke(ctx, "/net.Admin/GetPayments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Deposit(ctx context.Context, in *DepositReq, opts ...grpc.CallOption) (*TxRes, error) {
+	out := new(TxRes)
+	err := c.cc.Invoke(ctx, "/net.Admin/Deposit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) WatchSessions(ctx context.Context, in *WatchSessionsReq, opts ...grpc.CallOption) (Admin_WatchSessionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Admin_serviceDesc.Streams[0], "/net.Admin/WatchSessions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminWatchSessionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_WatchSessionsClient interface {
+	Recv() (*SessionEvent, error)
+	grpc.ClientStream
+}
+
+type adminWatchSessionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminWatchSessionsClient) Recv() (*SessionEvent, error) {
+	m := new(SessionEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	Status(context.Context, *StatusReq) (*NodeStatus, error)
+	ListStreams(context.Context, *ListStreamsReq) (*ListStreamsRes, error)
+	// TerminateStream ends a stream, disconnecting its publisher
+	TerminateStream(context.Context, *TerminateStreamReq) (*TerminateStreamRes, error)
+	ListSessions(context.Context, *ListSessionsReq) (*ListSessionsRes, error)
+	GetPricing(context.Context, *GetPricingReq) (*Pricing, error)
+	SetPrice(context.Context, *SetPriceReq) (*Pricing, error)
+	RemovePrice(context.Context, *RemovePriceReq) (*Pricing, error)
+	GetStaking(context.Context, *GetStakingReq) (*Staking, error)
+	Bond(context.Context, *BondReq) (*TxRes, error)
+	Unbond(context.Context, *UnbondReq) (*TxRes, error)
+	Rebond(context.Context, *RebondReq) (*TxRes, error)
+	WithdrawStake(context.Context, *WithdrawStakeReq) (*TxRes, error)
+	GetPayments(context.Context, *GetPaymentsReq) (*Payments, error)
+	Deposit(context.Context, *DepositReq) (*TxRes, error)
+	// WatchSessions streams the events of the sessions of the broadcaster as they happen
+	WatchSessions(*WatchSessionsReq, Admin_WatchSessionsServer) error
+}
+
+// UnimplementedAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (*UnimplementedAdminServer) Status(ctx context.Context, req *StatusReq) (*NodeStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (*UnimplementedAdminServer) ListStreams(ctx context.Context, req *ListStreamsReq) (*ListStreamsRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStreams not implemented")
+}
+func (*UnimplementedAdminServer) TerminateStream(ctx context.Context, req *TerminateStreamReq) (*TerminateStreamRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TerminateStream not implemented")
+}
+func (*UnimplementedAdminServer) ListSessions(ctx context.Context, req *ListSessionsReq) (*ListSessionsRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (*UnimplementedAdminServer) GetPricing(ctx context.Context, req *GetPricingReq) (*Pricing, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPricing not implemented")
+}
+func (*UnimplementedAdminServer) SetPrice(ctx context.Context, req *SetPriceReq) (*Pricing, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPrice not implemented")
+}
+func (*UnimplementedAdminServer) RemovePrice(ctx context.Context, req *RemovePriceReq) (*Pricing, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemovePrice not implemented")
+}
+func (*UnimplementedAdminServer) GetStaking(ctx context.Context, req *GetStakingReq) (*Staking, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStaking not implemented")
+}
+func (*UnimplementedAdminServer) Bond(ctx context.Context, req *BondReq) (*TxRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Bond not implemented")
+}
+func (*UnimplementedAdminServer) Unbond(ctx context.Context, req *UnbondReq) (*TxRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unbond not implemented")
+}
+func (*UnimplementedAdminServer) Rebond(ctx context.Context, req *RebondReq) (*TxRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rebond not implemented")
+}
+func (*UnimplementedAdminServer) WithdrawStake(ctx context.Context, req *WithdrawStakeReq) (*TxRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WithdrawStake not implemented")
+}
+func (*UnimplementedAdminServer) GetPayments(ctx context.Context, req *GetPaymentsReq) (*Payments, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPayments not implemented")
+}
+func (*UnimplementedAdminServer) Deposit(ctx context.Context, req *DepositReq) (*TxRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deposit not implemented")
+}
+func (*UnimplementedAdminServer) WatchSessions(req *WatchSessionsReq, srv Admin_WatchSessionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSessions not implemented")
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Status(ctx, req.(*StatusReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListStreams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStreamsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListStreams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/ListStreams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListStreams(ctx, req.(*ListStreamsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_TerminateStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TerminateStreamReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).TerminateStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/TerminateStream",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).TerminateStream(ctx, req.(*TerminateStreamReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListSessions(ctx, req.(*ListSessionsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetPricing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPricingReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetPricing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/GetPricing",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetPricing(ctx, req.(*GetPricingReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPriceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/SetPrice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetPrice(ctx, req.(*SetPriceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RemovePrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemovePriceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RemovePrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/RemovePrice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RemovePrice(ctx, req.(*RemovePriceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetStaking_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStakingReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetStaking(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/GetStaking",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetStaking(ctx, req.(*GetStakingReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Bond_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BondReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Bond(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/Bond",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Bond(ctx, req.(*BondReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Unbond_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnbondReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Unbond(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/Unbond",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Unbond(ctx, req.(*UnbondReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Rebond_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebondReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Rebond(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/Rebond",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Rebond(ctx, req.(*RebondReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_WithdrawStake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WithdrawStakeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).WithdrawStake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/WithdrawStake",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).WithdrawStake(ctx, req.(*WithdrawStakeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetPayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetPayments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/GetPayments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetPayments(ctx, req.(*GetPaymentsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Deposit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepositReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Deposit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Admin/Deposit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Deposit(ctx, req.(*DepositReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_WatchSessions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSessionsReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).WatchSessions(m, &adminWatchSessionsServer{stream})
+}
+
+type Admin_WatchSessionsServer interface {
+	Send(*SessionEvent) error
+	grpc.ServerStream
+}
+
+type adminWatchSessionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminWatchSessionsServer) Send(m *SessionEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "net.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Status",
+			Handler:    _Admin_Status_Handler,
+		},
+		{
+			MethodName: "ListStreams",
+			Handler:    _Admin_ListStreams_Handler,
+		},
+		{
+			MethodName: "TerminateStream",
+			Handler:    _Admin_TerminateStream_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _Admin_ListSessions_Handler,
+		},
+		{
+			MethodName: "GetPricing",
+			Handler:    _Admin_GetPricing_Handler,
+		},
+		{
+			MethodName: "SetPrice",
+			Handler:    _Admin_SetPrice_Handler,
+		},
+		{
+			MethodName: "RemovePrice",
+			Handler:    _Admin_RemovePrice_Handler,
+		},
+		{
+			MethodName: "GetStaking",
+			Handler:    _Admin_GetStaking_Handler,
+		},
+		{
+			MethodName: "Bond",
+			Handler:    _Admin_Bond_Handler,
+		},
+		{
+			MethodName: "Unbond",
+			Handler:    _Admin_Unbond_Handler,
+		},
+		{
+			MethodName: "Rebond",
+			Handler:    _Admin_Rebond_Handler,
+		},
+		{
+			MethodName: "WithdrawStake",
+			Handler:    _Admin_WithdrawStake_Handler,
+		},
+		{
+			MethodName: "GetPayments",
+			Handler:    _Admin_GetPayments_Handler,
+		},
+		{
+			MethodName: "Deposit",
+			Handler:    _Admin_Deposit_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSessions",
+			Handler:       _Admin_WatchSessions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "net/admin.proto",
+}
@@ -289,10 +289,16 @@ type OrchestratorRequest struct {
 	// Ethereum address of the broadcaster
 	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
 	// Broadcaster's signature over its address
-	Sig                  []byte   `protobuf:"bytes,2,opt,name=sig,proto3" json:"sig,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Sig []byte `protobuf:"bytes,2,opt,name=sig,proto3" json:"sig,omitempty"`
+	// Capabilities required by the broadcaster's job. Orchestrators that don't
+	// support them reject the request before generating ticket params.
+	Capabilities *Capabilities `protobuf:"bytes,3,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
+	// Maximum price the broadcaster pays for the job. Orchestrators with a
+	// higher price reject the request before generating ticket params.
+	MaxPrice             *PriceInfo `protobuf:"bytes,4,opt,name=max_price,json=maxPrice,proto3" json:"max_price,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
 }
 
 func (m *OrchestratorRequest) Reset()         { *m = OrchestratorRequest{} }
@@ -334,6 +340,20 @@ func (m *OrchestratorRequest) GetSig() []byte {
 	return nil
 }
 
+func (m *OrchestratorRequest) GetCapabilities() *Capabilities {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
+func (m *OrchestratorRequest) GetMaxPrice() *PriceInfo {
+	if m != nil {
+		return m.MaxPrice
+	}
+	return nil
+}
+
 //
 //OSInfo needed to negotiate storages that will be used.
 //It carries info needed to write to the storage.
@@ -626,6 +646,10 @@ type OrchestratorInfo struct {
 	Capabilities *Capabilities `protobuf:"bytes,5,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
 	// Data for transcoding authentication
 	AuthToken *AuthToken `protobuf:"bytes,6,opt,name=auth_token,json=authToken,proto3" json:"auth_token,omitempty"`
+	// Operator configured region label of the orchestrator, e.g. us-east
+	Region string `protobuf:"bytes,7,opt,name=region,proto3" json:"region,omitempty"`
+	// Operator configured datacenter or hosting provider label of the orchestrator, e.g. aws-us-east-1
+	Datacenter string `protobuf:"bytes,8,opt,name=datacenter,proto3" json:"datacenter,omitempty"`
 	// Orchestrator returns info about own input object storage, if it wants it to be used.
 	Storage              []*OSInfo `protobuf:"bytes,32,rep,name=storage,proto3" json:"storage,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
@@ -700,6 +724,20 @@ func (m *OrchestratorInfo) GetAuthToken() *AuthToken {
 	return nil
 }
 
+func (m *OrchestratorInfo) GetRegion() string {
+	if m != nil {
+		return m.Region
+	}
+	return ""
+}
+
+func (m *OrchestratorInfo) GetDatacenter() string {
+	if m != nil {
+		return m.Datacenter
+	}
+	return ""
+}
+
 func (m *OrchestratorInfo) GetStorage() []*OSInfo {
 	if m != nil {
 		return m.Storage
@@ -1701,6 +1739,8 @@ type NotifySegment struct {
 	SegData *SegData `protobuf:"bytes,3,opt,name=segData,proto3" json:"segData,omitempty"`
 	// ID for this particular transcoding task.
 	TaskId int64 `protobuf:"varint,16,opt,name=taskId,proto3" json:"taskId,omitempty"`
+	// W3C trace context of the segment, e.g. traceparent, to continue the trace of the orchestrator.
+	TraceContext map[string]string `protobuf:"bytes,4,rep,name=traceContext,proto3" json:"traceContext,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	// Deprecated by fullProfiles. Set of presets to transcode into.
 	// Should be set to an invalid value to induce failures
 	Profiles             []byte   `protobuf:"bytes,17,opt,name=profiles,proto3" json:"profiles,omitempty"`
@@ -1755,6 +1795,13 @@ func (m *NotifySegment) GetTaskId() int64 {
 	return 0
 }
 
+func (m *NotifySegment) GetTraceContext() map[string]string {
+	if m != nil {
+		return m.TraceContext
+	}
+	return nil
+}
+
 func (m *NotifySegment) GetProfiles() []byte {
 	if m != nil {
 		return m.Profiles
@@ -2071,6 +2118,7 @@ func init() {
 	proto.RegisterType((*TranscodeResult)(nil), "net.TranscodeResult")
 	proto.RegisterType((*RegisterRequest)(nil), "net.RegisterRequest")
 	proto.RegisterType((*NotifySegment)(nil), "net.NotifySegment")
+	proto.RegisterMapType((map[string]string)(nil), "net.NotifySegment.TraceContextEntry")
 	proto.RegisterType((*TicketParams)(nil), "net.TicketParams")
 	proto.RegisterType((*TicketSenderParams)(nil), "net.TicketSenderParams")
 	proto.RegisterType((*TicketExpirationParams)(nil), "net.TicketExpirationParams")
@@ -2082,137 +2130,142 @@ func init() {
 }
 
 var fileDescriptor_034e29c79f9ba827 = []byte{
-	// 2068 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x58, 0xef, 0x6e, 0xdb, 0xc8,
-	0x11, 0x37, 0x25, 0x59, 0x7f, 0x46, 0x92, 0x4d, 0x6f, 0x1c, 0x87, 0xd1, 0x25, 0x17, 0x87, 0x97,
-	0x14, 0x39, 0xe0, 0xce, 0x17, 0xc8, 0x49, 0x7a, 0x29, 0x50, 0xa0, 0xb2, 0xac, 0xb3, 0x75, 0x88,
-	0x6d, 0x75, 0xe5, 0xe4, 0x63, 0x55, 0x9a, 0x5c, 0x49, 0xac, 0x25, 0x92, 0xe1, 0xae, 0x9a, 0xf8,
-	0xd0, 0x17, 0x68, 0xdf, 0xa0, 0xfd, 0x52, 0xa0, 0x40, 0xd1, 0xef, 0x7d, 0x82, 0x3e, 0x46, 0xbf,
-	0xf5, 0x55, 0x8a, 0x9d, 0x5d, 0x52, 0xa4, 0xe5, 0xfc, 0xc1, 0x7d, 0xdb, 0xf9, 0xb3, 0x3b, 0xb3,
-	0xb3, 0x33, 0xb3, 0xbf, 0x5d, 0x30, 0x03, 0x26, 0xbe, 0x9b, 0x45, 0xa3, 0x38, 0x72, 0xf7, 0xa2,
-	0x38, 0x14, 0x21, 0x29, 0x06, 0x4c, 0xd8, 0xbb, 0x50, 0x1d, 0xf8, 0xc1, 0x64, 0x10, 0x06, 0x13,
-	0xb2, 0x0d, 0xeb, 0x7f, 0x74, 0x66, 0x0b, 0x66, 0x19, 0xbb, 0xc6, 0x93, 0x06, 0x55, 0x84, 0x7d,
-	0x02, 0xf7, 0x7a, 0x81, 0x77, 0x1e, 0x3b, 0x01, 0x77, 0x43, 0xcf, 0x0f, 0x26, 0x43, 0xc6, 0xb9,
-	0x1f, 0x06, 0x94, 0xbd, 0x5d, 0x30, 0x2e, 0xc8, 0xb7, 0x00, 0xce, 0x42, 0x4c, 0x47, 0x22, 0xbc,
-	0x64, 0x01, 0x4e, 0xad, 0xb7, 0x37, 0xf6, 0x02, 0x26, 0xf6, 0x3a, 0x0b, 0x31, 0x3d, 0x97, 0x5c,
-	0x5a, 0x73, 0x92, 0xa1, 0xfd, 0x00, 0xee, 0x7f, 0x60, 0x39, 0x1e, 0x85, 0x01, 0x67, 0x76, 0x07,
-	0x6e, 0x9d, 0xc5, 0xee, 0x94, 0x71, 0x11, 0x3b, 0x22, 0x8c, 0x13, 0x33, 0x16, 0x54, 0x1c, 0xcf,
-	0x8b, 0x19, 0xe7, 0xda, 0xbd, 0x84, 0x24, 0x26, 0x14, 0xb9, 0x3f, 0xb1, 0x0a, 0xc8, 0x95, 0x43,
-	0xfb, 0xaf, 0x06, 0x94, 0xcf, 0x86, 0xfd, 0x60, 0x1c, 0x92, 0x97, 0x50, 0xe7, 0x22, 0x8c, 0x9d,
-	0x09, 0x3b, 0xbf, 0x8a, 0xd4, 0xce, 0x36, 0xda, 0x77, 0xd0, 0x3d, 0xa5, 0xb1, 0x37, 0x5c, 0x8a,
-	0x69, 0x56, 0x97, 0x3c, 0x86, 0x32, 0xdf, 0xf7, 0x83, 0x71, 0x68, 0x99, 0xb8, 0xa9, 0x26, 0xce,
-	0x1a, 0xee, 0xab, 0x79, 0x54, 0x0b, 0xed, 0x6f, 0xa1, 0x9e, 0x59, 0x82, 0x00, 0x94, 0x0f, 0xfb,
-	0xb4, 0xd7, 0x3d, 0x37, 0xd7, 0x48, 0x19, 0x0a, 0xc3, 0x7d, 0xd3, 0x90, 0xbc, 0xa3, 0xb3, 0xb3,
-	0xa3, 0x57, 0x3d, 0xb3, 0x60, 0xff, 0xc3, 0x80, 0x6a, 0xb2, 0x06, 0x21, 0x50, 0x9a, 0x86, 0x5c,
-	0xa0, 0x5b, 0x35, 0x8a, 0x63, 0xb9, 0x9d, 0x4b, 0x76, 0x85, 0xdb, 0xa9, 0x51, 0x39, 0x24, 0x3b,
-	0x50, 0x8e, 0xc2, 0x99, 0xef, 0x5e, 0x59, 0x45, 0x64, 0x6a, 0x8a, 0xdc, 0x83, 0x1a, 0xf7, 0x27,
-	0x81, 0x23, 0x16, 0x31, 0xb3, 0x4a, 0x28, 0x5a, 0x32, 0xc8, 0x97, 0x00, 0x6e, 0xcc, 0x3c, 0x16,
-	0x08, 0xdf, 0x99, 0x59, 0xeb, 0x28, 0xce, 0x70, 0x48, 0x0b, 0xaa, 0xef, 0x3b, 0xf3, 0x9f, 0x0e,
-	0x1d, 0xc1, 0xac, 0x32, 0x4a, 0x53, 0xda, 0x7e, 0x0d, 0xb5, 0x41, 0xec, 0xbb, 0x0c, 0x9d, 0xb4,
-	0xa1, 0x11, 0x49, 0x62, 0xc0, 0xe2, 0xd7, 0x81, 0xaf, 0x9c, 0x2d, 0xd2, 0x1c, 0x8f, 0x3c, 0x82,
-	0x66, 0xe4, 0xbf, 0x67, 0x33, 0x9e, 0x28, 0x15, 0x50, 0x29, 0xcf, 0xb4, 0xff, 0x67, 0x40, 0xa3,
-	0xeb, 0x44, 0xce, 0x85, 0x3f, 0xf3, 0x85, 0xcf, 0xb8, 0xdc, 0xc1, 0x85, 0x2f, 0xb8, 0x88, 0xfd,
-	0x60, 0x62, 0x19, 0xbb, 0xc5, 0x27, 0x25, 0xba, 0x64, 0x90, 0x5d, 0xa8, 0xcf, 0x9d, 0xc0, 0x93,
-	0x59, 0xe0, 0x33, 0x6e, 0x15, 0x50, 0x9e, 0x65, 0x91, 0x0e, 0x80, 0xeb, 0x44, 0x8e, 0x8b, 0xab,
-	0x59, 0xc5, 0xdd, 0xe2, 0x93, 0x7a, 0xfb, 0x21, 0x1e, 0x53, 0xd6, 0x0c, 0x12, 0x4a, 0xa7, 0x17,
-	0x88, 0xf8, 0x8a, 0x66, 0x26, 0xb5, 0x7e, 0x0d, 0x9b, 0xd7, 0xc4, 0xc9, 0x09, 0xc8, 0x7d, 0x36,
-	0xd5, 0x09, 0xa4, 0x95, 0x51, 0x40, 0x9e, 0x22, 0x7e, 0x55, 0xf8, 0xde, 0x68, 0x35, 0xa1, 0xde,
-	0x0d, 0x03, 0x99, 0xab, 0x7e, 0x20, 0xb8, 0xfd, 0x9f, 0x02, 0x98, 0xd9, 0xec, 0xc5, 0x00, 0x7e,
-	0x09, 0x20, 0x74, 0xbe, 0xb3, 0x58, 0x9f, 0x75, 0x86, 0x43, 0x5e, 0x40, 0x53, 0xf8, 0xee, 0x25,
-	0x13, 0xa3, 0xc8, 0x89, 0x9d, 0x39, 0x47, 0x2b, 0xf5, 0xf6, 0x16, 0x6e, 0xe4, 0x1c, 0x25, 0x03,
-	0x14, 0xd0, 0x86, 0xc8, 0x50, 0xb2, 0xf2, 0xf0, 0x10, 0x46, 0x98, 0xa4, 0xc5, 0x4c, 0xe5, 0xa5,
-	0x87, 0x47, 0x6b, 0x51, 0x7a, 0x8e, 0x99, 0x0a, 0x2a, 0xe5, 0x2b, 0xe8, 0x39, 0x34, 0xdc, 0x4c,
-	0xbc, 0x30, 0x59, 0x12, 0xfb, 0xd9, 0x40, 0xd2, 0x9c, 0xda, 0xb5, 0xca, 0x2f, 0x7f, 0xa2, 0xf2,
-	0xc9, 0x63, 0xa8, 0xe8, 0xf2, 0xb2, 0x76, 0xf1, 0xa4, 0xea, 0x99, 0x32, 0xa4, 0x89, 0xcc, 0xfe,
-	0x3d, 0xd4, 0xd2, 0xe9, 0x32, 0xf0, 0xcb, 0xbe, 0xd2, 0xa0, 0x8a, 0x20, 0xf7, 0x01, 0xb8, 0xea,
-	0x1a, 0x23, 0xdf, 0xd3, 0x95, 0x52, 0xd3, 0x9c, 0xbe, 0x27, 0xe3, 0xcd, 0xde, 0x47, 0x7e, 0xec,
-	0x08, 0x3f, 0x0c, 0x30, 0x2e, 0x45, 0x9a, 0xe1, 0xd8, 0x7d, 0x68, 0x1e, 0x32, 0xc1, 0x5c, 0x11,
-	0xc6, 0xdd, 0x99, 0xc3, 0x39, 0xb9, 0x0b, 0x55, 0x57, 0x0e, 0xe4, 0x6a, 0xea, 0xd4, 0x2b, 0x48,
-	0xf7, 0x3d, 0x69, 0x4a, 0x89, 0x02, 0x67, 0xce, 0x12, 0x53, 0xc8, 0x39, 0x75, 0xe6, 0xcc, 0xbe,
-	0x84, 0xd6, 0xd0, 0x65, 0x01, 0xc3, 0x75, 0xfc, 0xb1, 0xef, 0xa2, 0x85, 0x41, 0x1c, 0x8e, 0xfd,
-	0x19, 0x23, 0x0f, 0xa0, 0xce, 0x9d, 0x79, 0x34, 0x63, 0xa3, 0x58, 0x56, 0x99, 0x5a, 0x1a, 0x14,
-	0x8b, 0x3a, 0x82, 0x91, 0x6f, 0x40, 0x19, 0xd2, 0xd9, 0x5d, 0x6f, 0x13, 0x0c, 0x49, 0xce, 0x3b,
-	0x9a, 0xa8, 0xd8, 0x11, 0x6c, 0x26, 0x92, 0xc4, 0xc2, 0x39, 0x6c, 0x73, 0x69, 0x7f, 0xe4, 0xe6,
-	0x1c, 0xd0, 0x6d, 0xf8, 0x81, 0xea, 0x58, 0x1f, 0x74, 0xf0, 0x78, 0x8d, 0xde, 0xe2, 0xab, 0xd2,
-	0x83, 0x8a, 0x4e, 0x77, 0xfb, 0xdf, 0xeb, 0x50, 0x19, 0xb2, 0xc9, 0xa1, 0x23, 0x1c, 0x19, 0xd5,
-	0xb9, 0x13, 0xf8, 0x63, 0xc6, 0x45, 0xdf, 0xd3, 0xe7, 0x91, 0xe1, 0x60, 0x1b, 0x66, 0x6f, 0x75,
-	0xe1, 0xcb, 0x21, 0x76, 0x37, 0x87, 0x4f, 0xf1, 0x04, 0x1a, 0x14, 0xc7, 0xb2, 0xeb, 0x44, 0xca,
-	0x78, 0x92, 0x85, 0x29, 0x9d, 0x34, 0xf2, 0xf5, 0xb4, 0x91, 0x4b, 0x6d, 0x6f, 0xa1, 0xcf, 0x51,
-	0xe6, 0xd7, 0x3a, 0x4d, 0xe9, 0x95, 0xa4, 0xad, 0xfc, 0x9c, 0xa4, 0xad, 0x7e, 0x2a, 0x69, 0xbf,
-	0x06, 0xd3, 0xd3, 0x31, 0x1f, 0xb1, 0xc0, 0xb9, 0x98, 0x31, 0xcf, 0xaa, 0xed, 0x1a, 0x4f, 0xaa,
-	0x74, 0x33, 0xe1, 0xf7, 0x14, 0x9b, 0x3c, 0x85, 0x6d, 0xd7, 0x99, 0xb9, 0xa3, 0x88, 0xc5, 0x2e,
-	0x8b, 0xc4, 0xc2, 0x99, 0x8d, 0x70, 0xfb, 0x80, 0xea, 0x44, 0xca, 0x06, 0xa9, 0xe8, 0x58, 0x06,
-	0xe3, 0xf3, 0x2a, 0x42, 0xee, 0x74, 0xbc, 0x98, 0xcd, 0x06, 0x49, 0xdc, 0x1e, 0xa2, 0xae, 0xda,
-	0xe9, 0x1b, 0xdf, 0x63, 0xa1, 0x96, 0xd0, 0x9c, 0x1a, 0xf9, 0x25, 0x34, 0xb3, 0x74, 0xdb, 0xb2,
-	0x3f, 0x34, 0x2f, 0xaf, 0x77, 0x7d, 0xe2, 0xbe, 0xf5, 0xd5, 0x67, 0x4d, 0xdc, 0x27, 0x1d, 0x20,
-	0x9c, 0x4d, 0xe6, 0x2c, 0xd0, 0x9d, 0x8c, 0x09, 0x16, 0x73, 0xeb, 0x31, 0xc6, 0x58, 0x65, 0xf6,
-	0x90, 0x4d, 0x06, 0xa9, 0x84, 0x6e, 0x69, 0xed, 0x25, 0x8b, 0x74, 0x60, 0x2b, 0x8d, 0x77, 0x9a,
-	0x28, 0x8f, 0xd0, 0xfe, 0x76, 0xae, 0x36, 0x12, 0x17, 0xd2, 0xe3, 0x49, 0xdc, 0xb0, 0xf7, 0xa1,
-	0x99, 0x33, 0x23, 0xf3, 0x70, 0x1c, 0x87, 0x73, 0xcc, 0xd9, 0x12, 0xc5, 0x31, 0xd9, 0x80, 0x82,
-	0x08, 0x31, 0x59, 0x4b, 0xb4, 0x20, 0x42, 0x99, 0xe9, 0x8d, 0xec, 0xd6, 0xe4, 0x24, 0x2c, 0x79,
-	0x53, 0x5d, 0xcd, 0x72, 0x2c, 0xbb, 0xd1, 0x3b, 0xdf, 0x13, 0x53, 0x6b, 0x0b, 0x73, 0x51, 0x11,
-	0xf2, 0x7a, 0x9e, 0x32, 0x7f, 0x32, 0x15, 0x16, 0x41, 0xb6, 0xa6, 0x64, 0xbf, 0xbd, 0xf0, 0x05,
-	0x56, 0xfe, 0x2d, 0x14, 0x24, 0xa4, 0x4c, 0xf4, 0x71, 0xc4, 0xad, 0x6d, 0x75, 0xc1, 0x8c, 0x23,
-	0x4e, 0x9e, 0x42, 0x79, 0x1c, 0xc6, 0x73, 0x47, 0x58, 0xb7, 0x11, 0xa1, 0x58, 0x2b, 0xb1, 0xde,
-	0xfb, 0x01, 0xe5, 0x54, 0xeb, 0x49, 0xab, 0xe3, 0x88, 0x1f, 0xb2, 0xc0, 0xda, 0xc1, 0x65, 0x34,
-	0x45, 0xf6, 0xa1, 0xa2, 0xe3, 0x66, 0xdd, 0xc1, 0xa5, 0xee, 0xae, 0x2e, 0x95, 0xc4, 0x2e, 0xd1,
-	0x94, 0x0e, 0x4d, 0xc2, 0xc8, 0xb2, 0xd0, 0x4d, 0x39, 0x24, 0x2f, 0xa0, 0xc2, 0x02, 0x75, 0x61,
-	0xdd, 0xc5, 0x65, 0xee, 0xad, 0x2e, 0x83, 0x44, 0x37, 0xf4, 0x98, 0x4b, 0x13, 0x65, 0x44, 0x1d,
-	0xe1, 0x2c, 0x8c, 0x0f, 0x59, 0x24, 0xa6, 0x56, 0x0b, 0x17, 0xcc, 0x70, 0xc8, 0x11, 0x34, 0xdc,
-	0x69, 0x1c, 0xce, 0x1d, 0xb5, 0x1d, 0xeb, 0x0b, 0x5c, 0xfc, 0xab, 0xd5, 0xc5, 0xbb, 0xa8, 0x35,
-	0x5c, 0x5c, 0x60, 0xbb, 0xf4, 0x83, 0x09, 0xcd, 0x4d, 0xb4, 0xef, 0x43, 0x59, 0x8d, 0x24, 0xba,
-	0x3a, 0x19, 0xf4, 0x8e, 0xce, 0x87, 0xe6, 0x1a, 0xa9, 0x40, 0xf1, 0x64, 0xf0, 0xcc, 0x34, 0xec,
-	0x3f, 0x40, 0x25, 0x39, 0xc9, 0x5b, 0xb0, 0xd9, 0x3b, 0xed, 0x9e, 0x1d, 0xf6, 0xe8, 0xe8, 0xb0,
-	0xf7, 0x43, 0xe7, 0xf5, 0x2b, 0x09, 0xcd, 0xb6, 0xa0, 0x79, 0xdc, 0x7e, 0xf1, 0x6c, 0x74, 0xd0,
-	0x19, 0xf6, 0x5e, 0xf5, 0x4f, 0x7b, 0xa6, 0x41, 0x9a, 0x50, 0x43, 0xd6, 0x49, 0xa7, 0x7f, 0x6a,
-	0x16, 0x52, 0xf2, 0xb8, 0x7f, 0x74, 0x6c, 0x16, 0xc9, 0x5d, 0xb8, 0x8d, 0x64, 0xf7, 0xec, 0x74,
-	0x78, 0x4e, 0x3b, 0xfd, 0xd3, 0xde, 0xa1, 0x12, 0x95, 0xec, 0x36, 0xc0, 0x32, 0x14, 0xa4, 0x0a,
-	0x25, 0xa9, 0x68, 0xae, 0xe9, 0xd1, 0x73, 0xd3, 0x90, 0x6e, 0xbd, 0x19, 0x7c, 0x6f, 0x16, 0xd4,
-	0xe0, 0xa5, 0x59, 0xb4, 0xbb, 0xb0, 0xb5, 0xb2, 0x43, 0xb2, 0x01, 0xd0, 0x3d, 0xa6, 0x67, 0x27,
-	0x9d, 0xd1, 0xb3, 0xf6, 0x53, 0x73, 0x2d, 0x47, 0xb7, 0x4d, 0x23, 0x4b, 0x3f, 0x7b, 0x66, 0x16,
-	0xec, 0xb7, 0x70, 0x3b, 0x01, 0xd2, 0xcc, 0x1b, 0xaa, 0x5a, 0xc2, 0x5e, 0x6d, 0x42, 0x71, 0x11,
-	0xcf, 0x34, 0xd4, 0x90, 0x43, 0xc4, 0x90, 0x88, 0xc5, 0x74, 0x83, 0xd6, 0x14, 0xd9, 0x83, 0x5b,
-	0xd7, 0xfa, 0xd5, 0x48, 0xce, 0x54, 0x40, 0x73, 0x2b, 0xca, 0xf5, 0xab, 0xd7, 0xf1, 0xcc, 0xfe,
-	0x97, 0x01, 0x77, 0x6e, 0xb8, 0x50, 0xd0, 0xea, 0x09, 0xd4, 0xd5, 0x5d, 0x19, 0xc5, 0xe1, 0x05,
-	0x47, 0x3c, 0x57, 0x6f, 0x7f, 0xf3, 0xa1, 0x3b, 0x48, 0x4e, 0xd9, 0x43, 0xd6, 0x40, 0xaa, 0x27,
-	0xc8, 0x2c, 0x65, 0x20, 0x32, 0xcb, 0x8b, 0x3f, 0x85, 0xcc, 0x8c, 0x0c, 0x32, 0xb3, 0xa7, 0x00,
-	0xaa, 0x57, 0xa0, 0x6f, 0xbf, 0xfd, 0xe8, 0x45, 0x79, 0xef, 0x63, 0x4e, 0x7e, 0xf2, 0x96, 0xfc,
-	0x8b, 0x01, 0xcd, 0xf4, 0x1c, 0xd0, 0xda, 0x0b, 0xa8, 0xea, 0xd6, 0x96, 0x84, 0xa1, 0xa5, 0xc0,
-	0xdc, 0x4d, 0xa7, 0x45, 0x53, 0xdd, 0xd5, 0xa7, 0x0c, 0xf9, 0x0e, 0x40, 0x35, 0x38, 0x3f, 0x0c,
-	0x12, 0x84, 0xbb, 0x99, 0x69, 0x84, 0xb8, 0x40, 0x46, 0xc5, 0xfe, 0x9b, 0x01, 0x9b, 0xa9, 0x19,
-	0xca, 0xf8, 0x62, 0x26, 0x92, 0xab, 0xd9, 0x58, 0x5e, 0xcd, 0x3b, 0xb0, 0xce, 0xe2, 0x38, 0x8c,
-	0x15, 0xa2, 0x39, 0x5e, 0xa3, 0x8a, 0x24, 0x4f, 0xa0, 0xe4, 0x39, 0xc2, 0xd1, 0x60, 0x92, 0xe4,
-	0x9d, 0xd6, 0xc1, 0x40, 0x0d, 0xf2, 0x35, 0x94, 0x32, 0x6f, 0xa3, 0xdb, 0xea, 0xe2, 0xba, 0x86,
-	0x7c, 0x29, 0xaa, 0x1c, 0x54, 0xa1, 0x1c, 0xa3, 0x23, 0xf6, 0x9f, 0x60, 0x93, 0xb2, 0x89, 0xcf,
-	0x05, 0x4b, 0xdf, 0x75, 0x3b, 0x50, 0xe6, 0xcc, 0x8d, 0x59, 0xf2, 0x08, 0xd2, 0x94, 0xbc, 0xfa,
-	0x35, 0x4a, 0xbf, 0xd2, 0x29, 0x9b, 0xd2, 0x2b, 0x57, 0x7f, 0xf1, 0xb3, 0xae, 0x7e, 0xfb, 0xcf,
-	0x06, 0x34, 0x4f, 0x43, 0xe1, 0x8f, 0xaf, 0x74, 0xf4, 0x6f, 0xa8, 0x93, 0x5f, 0x40, 0x85, 0x2b,
-	0xc0, 0xa3, 0x57, 0x6d, 0x24, 0xf7, 0x16, 0x46, 0x3a, 0x11, 0x4a, 0xb7, 0x85, 0xc3, 0x2f, 0xfb,
-	0x1e, 0x06, 0xa0, 0x48, 0x35, 0x95, 0xc3, 0x37, 0x5b, 0x79, 0x7c, 0xf3, 0x63, 0xa9, 0x5a, 0x30,
-	0x8b, 0x3f, 0x96, 0xaa, 0x0f, 0x4d, 0xdb, 0xfe, 0x7b, 0x01, 0x1a, 0x59, 0x68, 0x2f, 0x9f, 0x42,
-	0x31, 0x73, 0xfd, 0xc8, 0x67, 0x81, 0xd0, 0xe8, 0x6a, 0xc9, 0x90, 0x30, 0x74, 0xec, 0xb8, 0x6c,
-	0xb4, 0xcc, 0xf5, 0x06, 0xad, 0x49, 0xce, 0x1b, 0xc9, 0x90, 0x00, 0xf6, 0x9d, 0x1f, 0x60, 0xdd,
-	0x69, 0xb4, 0x55, 0x79, 0xe7, 0x4b, 0x94, 0x77, 0x21, 0x0b, 0x3c, 0x5d, 0x66, 0x14, 0x3b, 0x81,
-	0xa7, 0x40, 0x89, 0xc2, 0x5e, 0x5b, 0xa9, 0x88, 0x3a, 0x81, 0x87, 0x98, 0x84, 0x40, 0x89, 0x33,
-	0xe6, 0x69, 0x14, 0x86, 0x63, 0x09, 0x82, 0x96, 0xf0, 0x79, 0x74, 0x31, 0x0b, 0xdd, 0x4b, 0x84,
-	0x63, 0x0d, 0xba, 0xb9, 0xe4, 0x1f, 0x48, 0x36, 0x39, 0x86, 0xad, 0x8c, 0xaa, 0x7e, 0xcf, 0x28,
-	0x68, 0xf6, 0x45, 0xe6, 0x3d, 0xd3, 0x4b, 0x75, 0xf4, 0xcb, 0x26, 0x63, 0x40, 0x71, 0xec, 0x3e,
-	0x10, 0xa5, 0x3b, 0x64, 0x81, 0xc7, 0x62, 0x1d, 0xa6, 0x87, 0xd0, 0xe0, 0x48, 0x8f, 0x82, 0x30,
-	0x70, 0x13, 0x4c, 0x5d, 0x57, 0xbc, 0x53, 0xc9, 0xba, 0xe1, 0x3f, 0xe0, 0x27, 0xd8, 0xb9, 0xd9,
-	0x2c, 0x79, 0x0c, 0x1b, 0x6e, 0xcc, 0x94, 0xb3, 0x71, 0xb8, 0x08, 0x3c, 0x5d, 0x24, 0xcd, 0x84,
-	0x4b, 0x25, 0x93, 0xbc, 0x84, 0xbb, 0x79, 0x35, 0x15, 0x04, 0x15, 0x4a, 0x65, 0x68, 0x27, 0x37,
-	0x03, 0x83, 0x21, 0xe3, 0x69, 0xff, 0xb3, 0x00, 0x95, 0x81, 0x73, 0x85, 0xe9, 0xb6, 0xf2, 0xd0,
-	0x33, 0x3e, 0xef, 0xa1, 0x87, 0x35, 0x22, 0x37, 0xa8, 0x6d, 0x69, 0xea, 0xe6, 0x60, 0x17, 0x7f,
-	0x46, 0xb0, 0x49, 0x1f, 0xb6, 0xb5, 0x67, 0x3a, 0xba, 0x7a, 0xb1, 0x12, 0x36, 0x9c, 0x3b, 0x99,
-	0xc5, 0xb2, 0xa7, 0x41, 0x89, 0x58, 0x3d, 0xa1, 0xe7, 0xb0, 0xc1, 0xde, 0x47, 0xcc, 0x15, 0xcc,
-	0x1b, 0xe1, 0xe3, 0x53, 0x3f, 0x27, 0xaf, 0xbf, 0x4c, 0x9b, 0x89, 0x16, 0xb2, 0xda, 0xff, 0x35,
-	0xa0, 0x91, 0xed, 0x1f, 0xe4, 0x00, 0x36, 0x8f, 0x98, 0xc8, 0xb1, 0xac, 0x95, 0x2e, 0xa3, 0xbb,
-	0x48, 0xeb, 0xe6, 0xfe, 0x43, 0x7e, 0x07, 0xb7, 0x6f, 0xfc, 0x6c, 0x22, 0xea, 0x93, 0xe0, 0x63,
-	0xff, 0x5a, 0x2d, 0xfb, 0x63, 0x2a, 0xea, 0xaf, 0x8a, 0x3c, 0x82, 0xd2, 0x40, 0x5e, 0xdc, 0xea,
-	0x6b, 0x28, 0xf9, 0x48, 0x6b, 0xe5, 0xc9, 0xf6, 0x29, 0xc0, 0xf9, 0xf2, 0xb5, 0xff, 0x1b, 0x20,
-	0x49, 0x0f, 0xcc, 0x70, 0x15, 0xb8, 0xbd, 0xd6, 0x1c, 0x5b, 0xaa, 0x01, 0xe7, 0x7a, 0xd6, 0x53,
-	0xe3, 0xa2, 0x8c, 0xff, 0x77, 0xfb, 0xff, 0x0f, 0x00, 0x00, 0xff, 0xff, 0x49, 0xb5, 0x66, 0xd9,
-	0xd3, 0x13, 0x00, 0x00,
+	// 2153 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x58, 0xcd, 0x72, 0xdb, 0xc8,
+	0xf1, 0x17, 0x48, 0x8a, 0x1f, 0x4d, 0x52, 0x02, 0xc7, 0xb2, 0x0c, 0x73, 0xed, 0xb5, 0x8c, 0xb5,
+	0xff, 0xe5, 0xad, 0xff, 0xae, 0xd6, 0x45, 0xd9, 0xce, 0x3a, 0x55, 0xa9, 0x44, 0xa2, 0xb8, 0x22,
+	0xb7, 0x2c, 0x89, 0x19, 0xd2, 0x3e, 0x86, 0x81, 0x80, 0x21, 0x89, 0x88, 0x04, 0x60, 0x60, 0x18,
+	0x4b, 0x5b, 0x79, 0x82, 0x9c, 0x73, 0x49, 0x2e, 0xa9, 0x4a, 0xd5, 0x56, 0xee, 0x79, 0x9a, 0xdc,
+	0xf2, 0x1a, 0x39, 0xa6, 0xa6, 0x67, 0x00, 0x02, 0x22, 0xfd, 0x51, 0x3e, 0x61, 0xfa, 0xd7, 0x3d,
+	0x3d, 0x3d, 0x3d, 0xdd, 0x3d, 0x3d, 0x00, 0xdd, 0x63, 0xfc, 0xbb, 0x59, 0x30, 0x0a, 0x03, 0x7b,
+	0x3f, 0x08, 0x7d, 0xee, 0x93, 0xbc, 0xc7, 0xb8, 0xb9, 0x07, 0xe5, 0xbe, 0xeb, 0x4d, 0xfa, 0xbe,
+	0x37, 0x21, 0x3b, 0xb0, 0xf9, 0x47, 0x6b, 0xb6, 0x60, 0x86, 0xb6, 0xa7, 0x3d, 0xa9, 0x51, 0x49,
+	0x98, 0xa7, 0x70, 0xaf, 0xe3, 0x39, 0xc3, 0xd0, 0xf2, 0x22, 0xdb, 0x77, 0x5c, 0x6f, 0x32, 0x60,
+	0x51, 0xe4, 0xfa, 0x1e, 0x65, 0x6f, 0x17, 0x2c, 0xe2, 0xe4, 0x5b, 0x00, 0x6b, 0xc1, 0xa7, 0x23,
+	0xee, 0x5f, 0x32, 0x0f, 0xa7, 0x56, 0x5b, 0x5b, 0xfb, 0x1e, 0xe3, 0xfb, 0x87, 0x0b, 0x3e, 0x1d,
+	0x0a, 0x94, 0x56, 0xac, 0x78, 0x68, 0x3e, 0x80, 0xfb, 0xef, 0x51, 0x17, 0x05, 0xbe, 0x17, 0x31,
+	0xf3, 0x67, 0x0d, 0x6e, 0x9d, 0x87, 0xf6, 0x94, 0x45, 0x3c, 0xb4, 0xb8, 0x1f, 0xc6, 0xeb, 0x18,
+	0x50, 0xb2, 0x1c, 0x27, 0x64, 0x51, 0xa4, 0xec, 0x8b, 0x49, 0xa2, 0x43, 0x3e, 0x72, 0x27, 0x46,
+	0x0e, 0x51, 0x31, 0x24, 0xcf, 0xa1, 0x66, 0x5b, 0x81, 0x75, 0xe1, 0xce, 0x5c, 0xee, 0xb2, 0xc8,
+	0xc8, 0xa3, 0x55, 0x0d, 0xb4, 0xaa, 0x9d, 0x62, 0xd0, 0x8c, 0x18, 0xf9, 0x7f, 0xa8, 0xcc, 0xad,
+	0xab, 0x51, 0x10, 0xba, 0x36, 0x33, 0x0a, 0xa9, 0x9d, 0xf4, 0x05, 0xd2, 0xf3, 0xc6, 0x3e, 0x2d,
+	0xcf, 0xad, 0x2b, 0xa4, 0xcc, 0xbf, 0x6a, 0x50, 0x3c, 0x1f, 0x08, 0x90, 0xbc, 0x84, 0x6a, 0xc4,
+	0xfd, 0xd0, 0x9a, 0xb0, 0xe1, 0x75, 0x20, 0xdd, 0xb7, 0xd5, 0xba, 0x83, 0x33, 0xa5, 0xc4, 0xfe,
+	0x60, 0xc9, 0xa6, 0x69, 0x59, 0xf2, 0x18, 0x8a, 0xd1, 0x81, 0xeb, 0x8d, 0x7d, 0x43, 0xc7, 0xf5,
+	0xea, 0x38, 0x6b, 0x70, 0x20, 0xe7, 0x51, 0xc5, 0x34, 0xbf, 0x85, 0x6a, 0x4a, 0x05, 0x01, 0x28,
+	0x1e, 0xf7, 0x68, 0xa7, 0x3d, 0xd4, 0x37, 0x48, 0x11, 0x72, 0x83, 0x03, 0x5d, 0x13, 0xd8, 0xc9,
+	0xf9, 0xf9, 0xc9, 0xab, 0x8e, 0x9e, 0x33, 0xff, 0xa1, 0x41, 0x39, 0xd6, 0x41, 0x08, 0x14, 0xa6,
+	0x7e, 0xc4, 0xd1, 0xac, 0x0a, 0xc5, 0xb1, 0x70, 0xd9, 0x25, 0xbb, 0x46, 0x97, 0x55, 0xa8, 0x18,
+	0x92, 0x5d, 0x28, 0x06, 0xfe, 0xcc, 0xb5, 0xaf, 0xd1, 0x59, 0x15, 0xaa, 0x28, 0x72, 0x0f, 0x2a,
+	0x91, 0x3b, 0xf1, 0x2c, 0xbe, 0x08, 0xa5, 0x4f, 0x2a, 0x74, 0x09, 0x90, 0x2f, 0x01, 0xec, 0x90,
+	0x39, 0xcc, 0xe3, 0xae, 0x35, 0x33, 0x36, 0x91, 0x9d, 0x42, 0x48, 0x13, 0xca, 0x57, 0x87, 0xf3,
+	0x9f, 0x8e, 0x2d, 0xce, 0x8c, 0x22, 0x72, 0x13, 0xda, 0x7c, 0x0d, 0x95, 0xc4, 0xaf, 0xc4, 0x84,
+	0x1a, 0xba, 0xbd, 0xcf, 0xc2, 0xd7, 0x9e, 0x2b, 0x8d, 0xcd, 0xd3, 0x0c, 0x46, 0x1e, 0x41, 0x3d,
+	0x70, 0xaf, 0xd8, 0x2c, 0x8a, 0x85, 0x72, 0x28, 0x94, 0x05, 0xcd, 0xff, 0x68, 0x50, 0x4b, 0x9f,
+	0xb1, 0xd8, 0xc1, 0x85, 0xcb, 0x23, 0x1e, 0xba, 0xde, 0xc4, 0xd0, 0xf6, 0xf2, 0x4f, 0x0a, 0x74,
+	0x09, 0x90, 0x3d, 0xa8, 0xce, 0x2d, 0xcf, 0x11, 0x91, 0x26, 0x22, 0x25, 0x87, 0xfc, 0x34, 0x44,
+	0x0e, 0x01, 0x44, 0x94, 0xd8, 0x71, 0x28, 0xe5, 0x9f, 0x54, 0x5b, 0x0f, 0x57, 0x42, 0x69, 0xbf,
+	0x9d, 0xc8, 0x74, 0x3c, 0x1e, 0x5e, 0xd3, 0xd4, 0xa4, 0xe6, 0xaf, 0x60, 0xfb, 0x06, 0x3b, 0x3e,
+	0x01, 0xb1, 0xcf, 0xba, 0x3c, 0x81, 0x24, 0xfd, 0x72, 0x88, 0x49, 0xe2, 0x97, 0xb9, 0xef, 0xb5,
+	0x66, 0x1d, 0xaa, 0x6d, 0xdf, 0x13, 0xf9, 0xe0, 0x7a, 0x3c, 0x32, 0xff, 0x9b, 0x03, 0x3d, 0x9d,
+	0x21, 0xe8, 0xc0, 0x2f, 0x01, 0xb8, 0x4a, 0x2a, 0x16, 0xaa, 0xb3, 0x4e, 0x21, 0xe4, 0x05, 0xd4,
+	0xb9, 0x6b, 0x5f, 0x32, 0x3e, 0x0a, 0xac, 0xd0, 0x9a, 0x47, 0x46, 0x2e, 0x95, 0x13, 0x43, 0xe4,
+	0xf4, 0x91, 0x41, 0x6b, 0x3c, 0x45, 0x89, 0xf4, 0xc6, 0x43, 0x18, 0x61, 0x90, 0xe6, 0xd7, 0x26,
+	0x45, 0x25, 0x88, 0x87, 0xe9, 0x2c, 0x2d, 0x64, 0xb3, 0xf4, 0x66, 0x4e, 0x6e, 0x7e, 0x5a, 0x4e,
+	0x66, 0xcb, 0x4b, 0xf1, 0x23, 0xe5, 0x45, 0x84, 0x71, 0xc8, 0x26, 0xae, 0xef, 0x19, 0x25, 0x19,
+	0xc6, 0x92, 0x12, 0xee, 0x71, 0x2c, 0x6e, 0xd9, 0xcc, 0xe3, 0x2c, 0x34, 0xca, 0xd2, 0x3d, 0x4b,
+	0x84, 0x3c, 0x86, 0x92, 0x4a, 0x4b, 0x63, 0x0f, 0x4f, 0xb8, 0x9a, 0x4a, 0x5f, 0x1a, 0xf3, 0xcc,
+	0xdf, 0x43, 0x25, 0x59, 0x56, 0x1c, 0xd8, 0xb2, 0xe8, 0xd5, 0xa8, 0x24, 0xc8, 0x7d, 0x80, 0x48,
+	0x96, 0xb4, 0x91, 0xeb, 0xa8, 0x0c, 0xab, 0x28, 0xa4, 0xe7, 0x08, 0x43, 0xd8, 0x55, 0xe0, 0x86,
+	0x16, 0x17, 0x46, 0xe6, 0x31, 0x82, 0x53, 0x88, 0xd9, 0x83, 0xfa, 0x31, 0xe3, 0xcc, 0xe6, 0x7e,
+	0xd8, 0x9e, 0x59, 0x51, 0x44, 0xee, 0x42, 0xd9, 0x16, 0x03, 0xa1, 0x4d, 0x46, 0x4b, 0x09, 0xe9,
+	0x9e, 0x23, 0x96, 0x92, 0x2c, 0xcf, 0x9a, 0xb3, 0x78, 0x29, 0x44, 0xce, 0xac, 0x39, 0x33, 0x2f,
+	0xa1, 0x39, 0xb0, 0x99, 0xc7, 0x50, 0x8f, 0x3b, 0x76, 0x6d, 0x5c, 0xa1, 0x1f, 0xfa, 0x63, 0x77,
+	0xc6, 0xc8, 0x03, 0xa8, 0x46, 0xd6, 0x3c, 0x98, 0xb1, 0x51, 0x28, 0xb2, 0x53, 0xaa, 0x06, 0x09,
+	0x51, 0x8b, 0x33, 0xf2, 0x0d, 0xc8, 0x85, 0x54, 0x56, 0x54, 0x5b, 0x04, 0x5d, 0x92, 0xb1, 0x8e,
+	0xc6, 0x22, 0x66, 0x00, 0xdb, 0x31, 0x27, 0x5e, 0x61, 0x08, 0x3b, 0x91, 0x58, 0x7f, 0x64, 0x67,
+	0x0c, 0x50, 0x77, 0xc4, 0x03, 0x59, 0xe9, 0xde, 0x6b, 0x60, 0x77, 0x83, 0xde, 0x8a, 0x56, 0xb9,
+	0x47, 0x25, 0x95, 0x26, 0xe6, 0xbf, 0x36, 0xa1, 0x34, 0x60, 0x93, 0x63, 0x8b, 0x5b, 0xc2, 0xab,
+	0x73, 0xcb, 0x73, 0xc7, 0x2c, 0xe2, 0x3d, 0x47, 0x9d, 0x47, 0x0a, 0xc1, 0x2b, 0x82, 0xbd, 0x55,
+	0x05, 0x43, 0x0c, 0xb1, 0x2a, 0x5a, 0xd1, 0x14, 0x4f, 0xa0, 0x46, 0x71, 0x2c, 0xaa, 0x55, 0x20,
+	0x17, 0x8f, 0xa3, 0x37, 0xa1, 0xe3, 0x4b, 0x66, 0x73, 0x79, 0xc9, 0x34, 0xa1, 0xec, 0x2c, 0xd4,
+	0x39, 0x8a, 0xb8, 0xdc, 0xa4, 0x09, 0xbd, 0x12, 0xec, 0xa5, 0xcf, 0x09, 0xf6, 0xf2, 0xc7, 0x82,
+	0xfd, 0x6b, 0xd0, 0x1d, 0xe5, 0xf3, 0x11, 0xf3, 0xac, 0x8b, 0x19, 0x73, 0x8c, 0xca, 0x9e, 0xf6,
+	0xa4, 0x4c, 0xb7, 0x63, 0xbc, 0x23, 0x61, 0xf2, 0x14, 0x76, 0x6c, 0x6b, 0x66, 0x8f, 0x02, 0x16,
+	0xda, 0x2c, 0xe0, 0x0b, 0x6b, 0x36, 0xc2, 0xed, 0x03, 0x8a, 0x13, 0xc1, 0xeb, 0x27, 0xac, 0xae,
+	0x70, 0xc6, 0xa7, 0x65, 0x84, 0xd8, 0xe9, 0x78, 0x31, 0x9b, 0xf5, 0x63, 0xbf, 0x3d, 0xdc, 0xcb,
+	0x27, 0x3b, 0x7d, 0xe3, 0x3a, 0xcc, 0x57, 0x1c, 0x9a, 0x11, 0x23, 0xbf, 0x80, 0x7a, 0x9a, 0x6e,
+	0x19, 0xe6, 0xfb, 0xe6, 0x65, 0xe5, 0x6e, 0x4e, 0x3c, 0x30, 0xbe, 0xfa, 0xa4, 0x89, 0x07, 0xe4,
+	0x10, 0x48, 0xc4, 0x26, 0x73, 0xe6, 0xa9, 0x0a, 0xc8, 0x38, 0x0b, 0x23, 0xe3, 0xf1, 0x9e, 0x96,
+	0x44, 0xf6, 0x80, 0x4d, 0xfa, 0x09, 0x87, 0x36, 0x94, 0xf4, 0x12, 0x22, 0x87, 0xd0, 0x48, 0xfc,
+	0x9d, 0x04, 0xca, 0x23, 0x5c, 0x7f, 0x27, 0x93, 0x1b, 0xb1, 0x09, 0xba, 0x93, 0x05, 0x22, 0xf3,
+	0x00, 0xea, 0x99, 0x65, 0x44, 0x1c, 0x8e, 0x43, 0x7f, 0x8e, 0x31, 0x5b, 0xa0, 0x38, 0x26, 0x5b,
+	0x90, 0xe3, 0x3e, 0x06, 0x6b, 0x81, 0xe6, 0xb8, 0x2f, 0x22, 0xbd, 0x96, 0xde, 0x9a, 0x98, 0x84,
+	0x29, 0xaf, 0xcb, 0x2b, 0x5d, 0x8c, 0x45, 0x35, 0x7a, 0xe7, 0x3a, 0x7c, 0x6a, 0x34, 0x30, 0x16,
+	0x25, 0x21, 0xea, 0xe1, 0x94, 0xb9, 0x93, 0x29, 0x37, 0x08, 0xc2, 0x8a, 0x12, 0x75, 0xfa, 0xc2,
+	0xe5, 0x98, 0xf9, 0xb7, 0x90, 0x11, 0x93, 0x22, 0xd0, 0xc7, 0x41, 0x64, 0xec, 0xc8, 0x8b, 0x69,
+	0x1c, 0x44, 0xe4, 0x29, 0x14, 0xc7, 0x7e, 0x38, 0xb7, 0xb8, 0x71, 0x1b, 0x3b, 0x1b, 0x63, 0xc5,
+	0xd7, 0xfb, 0x3f, 0x20, 0x9f, 0x2a, 0x39, 0xb1, 0xea, 0x38, 0x88, 0x8e, 0x99, 0x67, 0xec, 0xa2,
+	0x1a, 0x45, 0x91, 0x03, 0x28, 0x29, 0xbf, 0x19, 0x77, 0x50, 0xd5, 0xdd, 0x55, 0x55, 0xea, 0x4b,
+	0x63, 0x49, 0x61, 0xd0, 0xc4, 0x0f, 0x0c, 0x03, 0xcd, 0x14, 0x43, 0xf2, 0x02, 0x4a, 0xcc, 0x93,
+	0x17, 0xdd, 0x5d, 0x54, 0x73, 0x6f, 0x55, 0x0d, 0x12, 0x6d, 0xdf, 0x61, 0x36, 0x8d, 0x85, 0xb1,
+	0x5b, 0xf1, 0x67, 0x7e, 0x78, 0xcc, 0x02, 0x3e, 0x35, 0x9a, 0xa8, 0x30, 0x85, 0x90, 0x13, 0xa8,
+	0xd9, 0xd3, 0xd0, 0x9f, 0x5b, 0x72, 0x3b, 0xc6, 0x17, 0xa8, 0xfc, 0xab, 0x55, 0xe5, 0x6d, 0x94,
+	0x1a, 0x2c, 0x2e, 0xb0, 0x5c, 0xba, 0xde, 0x84, 0x66, 0x26, 0x9a, 0xf7, 0xa1, 0x28, 0x47, 0xa2,
+	0x2b, 0x3b, 0xed, 0x77, 0x4e, 0x86, 0x03, 0x7d, 0x83, 0x94, 0x20, 0x7f, 0xda, 0x7f, 0xa6, 0x6b,
+	0xe6, 0x1f, 0xa0, 0x14, 0x9f, 0xe4, 0x2d, 0xd8, 0xee, 0x9c, 0xb5, 0xcf, 0x8f, 0x3b, 0x74, 0x74,
+	0xdc, 0xf9, 0xe1, 0xf0, 0xf5, 0x2b, 0xd1, 0xd2, 0x35, 0xa0, 0xde, 0x6d, 0xbd, 0x78, 0x36, 0x3a,
+	0x3a, 0x1c, 0x74, 0x5e, 0xf5, 0xce, 0x3a, 0xba, 0x46, 0xea, 0x50, 0x41, 0xe8, 0xf4, 0xb0, 0x77,
+	0xa6, 0xe7, 0x12, 0xb2, 0xdb, 0x3b, 0xe9, 0xea, 0x79, 0x72, 0x17, 0x6e, 0x23, 0xd9, 0x3e, 0x3f,
+	0x1b, 0x0c, 0xe9, 0x61, 0xef, 0xac, 0x73, 0x2c, 0x59, 0x05, 0xb3, 0x05, 0xb0, 0x74, 0x05, 0x29,
+	0x43, 0x41, 0x08, 0xea, 0x1b, 0x6a, 0xf4, 0x5c, 0xd7, 0x84, 0x59, 0x6f, 0xfa, 0xdf, 0xeb, 0x39,
+	0x39, 0x78, 0xa9, 0xe7, 0xcd, 0x36, 0x34, 0x56, 0x76, 0x48, 0xb6, 0x00, 0xda, 0x5d, 0x7a, 0x7e,
+	0x7a, 0x38, 0x7a, 0xd6, 0x7a, 0xaa, 0x6f, 0x64, 0xe8, 0x96, 0xae, 0xa5, 0xe9, 0x67, 0xcf, 0xf4,
+	0x9c, 0xf9, 0x16, 0x6e, 0xc7, 0x5d, 0x3e, 0x73, 0x06, 0x32, 0x97, 0xb0, 0x56, 0xeb, 0x90, 0x5f,
+	0x84, 0x33, 0xd5, 0xa2, 0x88, 0x21, 0xf6, 0x9e, 0xd8, 0xc3, 0xa9, 0x02, 0xad, 0x28, 0xb2, 0x0f,
+	0xb7, 0x6e, 0xd4, 0xab, 0x91, 0x98, 0x29, 0x1b, 0xd4, 0x46, 0x90, 0xa9, 0x57, 0xaf, 0xc3, 0x99,
+	0xf9, 0x4f, 0x0d, 0xee, 0xac, 0xb9, 0x50, 0x70, 0xd5, 0x53, 0xa8, 0xca, 0xbb, 0x32, 0x08, 0xfd,
+	0x8b, 0x08, 0xfb, 0xc0, 0x6a, 0xeb, 0x9b, 0xf7, 0xdd, 0x41, 0x62, 0xca, 0x3e, 0x42, 0x7d, 0x21,
+	0x1e, 0x77, 0x74, 0x09, 0x80, 0x1d, 0x5d, 0x96, 0xfd, 0xb1, 0x8e, 0x4e, 0x4b, 0x75, 0x74, 0xe6,
+	0x14, 0x40, 0xd6, 0x0a, 0xb4, 0xed, 0xb7, 0x1f, 0xbc, 0x28, 0xef, 0x7d, 0xc8, 0xc8, 0x8f, 0xde,
+	0x92, 0x7f, 0xd6, 0xa0, 0x9e, 0x9c, 0x03, 0xae, 0xf6, 0x02, 0xca, 0xaa, 0xb4, 0xc5, 0x6e, 0x68,
+	0xca, 0x26, 0x70, 0xdd, 0x69, 0xd1, 0x44, 0x76, 0xcd, 0x33, 0xeb, 0x3b, 0x00, 0x59, 0xe0, 0x5c,
+	0xdf, 0x8b, 0x3b, 0xe3, 0xed, 0x54, 0x21, 0x44, 0x05, 0x29, 0x11, 0xf3, 0x6f, 0x1a, 0x6c, 0x27,
+	0xcb, 0x50, 0x16, 0x2d, 0x66, 0x3c, 0xbe, 0x9a, 0xb5, 0xe5, 0xd5, 0xbc, 0x0b, 0x9b, 0x2c, 0x0c,
+	0xfd, 0x50, 0x76, 0x34, 0xdd, 0x0d, 0x2a, 0x49, 0xf2, 0x04, 0x0a, 0xa2, 0x63, 0x33, 0xf2, 0xa9,
+	0x9a, 0x9d, 0xd9, 0x5a, 0x77, 0x83, 0xa2, 0x04, 0xf9, 0x1a, 0x0a, 0xa9, 0x37, 0xd5, 0x6d, 0x79,
+	0x71, 0xdd, 0xe8, 0x98, 0x29, 0x8a, 0x1c, 0x95, 0x45, 0xc3, 0x28, 0x0c, 0x31, 0xff, 0x04, 0xdb,
+	0x94, 0x4d, 0xdc, 0x88, 0xb3, 0xe4, 0xcd, 0xb9, 0x0b, 0xc5, 0x88, 0xd9, 0x21, 0x8b, 0x1f, 0x4f,
+	0x8a, 0x12, 0x57, 0xbf, 0xea, 0xee, 0xaf, 0x55, 0xc8, 0x26, 0xf4, 0x67, 0xbe, 0x3d, 0xcd, 0xbf,
+	0xe4, 0xa0, 0x7e, 0xe6, 0x73, 0x77, 0x7c, 0xad, 0xbc, 0xbf, 0x26, 0x4f, 0xfe, 0x0f, 0x4a, 0x91,
+	0x6c, 0x78, 0x94, 0xd6, 0x5a, 0x7c, 0x6f, 0xa1, 0xa7, 0x63, 0xa6, 0x30, 0x9b, 0x5b, 0xd1, 0x65,
+	0xcf, 0x41, 0x07, 0xe4, 0xa9, 0xa2, 0x48, 0x17, 0x6a, 0x3c, 0xb4, 0x6c, 0xd6, 0xf6, 0x3d, 0xce,
+	0xae, 0xb8, 0x51, 0xc0, 0x13, 0x7b, 0x84, 0x4a, 0x32, 0x6b, 0xef, 0x0f, 0x53, 0x62, 0x32, 0xf8,
+	0x33, 0x33, 0x33, 0x9d, 0x52, 0x23, 0xdb, 0x29, 0x35, 0x7f, 0x0d, 0x8d, 0x95, 0xe9, 0xe9, 0xe4,
+	0xa8, 0xac, 0x49, 0x8e, 0x4a, 0x2a, 0x39, 0x7e, 0x2c, 0x94, 0x73, 0x7a, 0xfe, 0xc7, 0x42, 0xf9,
+	0xa1, 0x6e, 0x9a, 0x7f, 0xcf, 0x41, 0x2d, 0xfd, 0x3a, 0x11, 0xaf, 0xb9, 0x90, 0xd9, 0x6e, 0xe0,
+	0x32, 0x8f, 0xab, 0x46, 0x6f, 0x09, 0x88, 0x8e, 0x78, 0x6c, 0xd9, 0x6c, 0xb4, 0xd4, 0x5c, 0xa3,
+	0x15, 0x81, 0xbc, 0x11, 0x80, 0xe8, 0xa5, 0xdf, 0xb9, 0x1e, 0x96, 0x00, 0xd5, 0xf8, 0x95, 0xde,
+	0xb9, 0xa2, 0xe1, 0xbc, 0x10, 0xb5, 0x26, 0x51, 0x33, 0x0a, 0x2d, 0xcf, 0x91, 0xfd, 0x91, 0x6c,
+	0x03, 0x1b, 0x09, 0x8b, 0x5a, 0x9e, 0x83, 0xed, 0x11, 0x81, 0x42, 0xc4, 0x98, 0xa3, 0x1a, 0x42,
+	0x1c, 0x8b, 0x7e, 0x6c, 0xd9, 0xc9, 0x8f, 0x2e, 0x66, 0xbe, 0x7d, 0x89, 0x9d, 0x61, 0x8d, 0x6e,
+	0x2f, 0xf1, 0x23, 0x01, 0x93, 0x2e, 0x34, 0x52, 0xa2, 0xea, 0x49, 0x26, 0xbb, 0xc4, 0x2f, 0x52,
+	0x4f, 0xb2, 0x4e, 0x22, 0xa3, 0x1e, 0x67, 0x3a, 0xbb, 0x81, 0x98, 0x3d, 0x20, 0x52, 0x76, 0xc0,
+	0x3c, 0x87, 0x85, 0xca, 0x4d, 0x0f, 0xa1, 0x16, 0x21, 0x3d, 0xf2, 0x7c, 0xcf, 0x8e, 0xdb, 0xfb,
+	0xaa, 0xc4, 0xce, 0x04, 0xb4, 0x9a, 0xcf, 0xe6, 0x4f, 0xb0, 0xbb, 0x7e, 0x59, 0xf2, 0x18, 0xb6,
+	0xec, 0x90, 0x49, 0x63, 0x43, 0x7f, 0xe1, 0x39, 0x2a, 0x5f, 0xeb, 0x31, 0x4a, 0x05, 0x48, 0x5e,
+	0xc2, 0xdd, 0xac, 0x98, 0x74, 0x82, 0x74, 0xa5, 0x5c, 0x68, 0x37, 0x33, 0x03, 0x9d, 0x21, 0xfc,
+	0x69, 0xfe, 0x9c, 0x83, 0x52, 0xdf, 0xba, 0xc6, 0xc8, 0x5f, 0x79, 0xab, 0x6a, 0x9f, 0xf6, 0x56,
+	0xc5, 0x74, 0x15, 0x1b, 0x54, 0x6b, 0x29, 0x6a, 0xbd, 0xb3, 0xf3, 0x9f, 0xe1, 0x6c, 0xd2, 0x83,
+	0x1d, 0x65, 0x99, 0xf2, 0xae, 0x52, 0x26, 0x33, 0xe9, 0x4e, 0x4a, 0x59, 0xfa, 0x34, 0x28, 0xe1,
+	0xab, 0x27, 0xf4, 0x1c, 0xb6, 0xd8, 0x55, 0xc0, 0x6c, 0xce, 0x1c, 0xf5, 0xc7, 0x69, 0x73, 0xed,
+	0xe3, 0xba, 0x1e, 0x4b, 0x21, 0xd4, 0xfa, 0xb7, 0x06, 0xb5, 0x74, 0x29, 0x23, 0x47, 0xb0, 0x7d,
+	0xc2, 0x78, 0x06, 0x32, 0x56, 0x0a, 0x9e, 0x2a, 0x68, 0xcd, 0xf5, 0xa5, 0x90, 0xfc, 0x0e, 0x6e,
+	0xaf, 0xfd, 0x29, 0x47, 0xe4, 0x7f, 0x8e, 0x0f, 0xfd, 0xff, 0x6b, 0x9a, 0x1f, 0x12, 0x91, 0xff,
+	0xf4, 0xc8, 0x23, 0x28, 0x88, 0xbf, 0x8c, 0x44, 0xfe, 0xdd, 0x8a, 0x7f, 0x38, 0x36, 0xb3, 0x64,
+	0xeb, 0x0c, 0x60, 0xb8, 0xfc, 0x61, 0xf1, 0x1b, 0x20, 0x71, 0x39, 0x4e, 0xa1, 0xb2, 0xcf, 0xbe,
+	0x51, 0xa7, 0x9b, 0x64, 0xb5, 0x84, 0x3d, 0xd5, 0x2e, 0x8a, 0xf8, 0x9f, 0xf3, 0xe0, 0x7f, 0x03,
+	0x00, 0xa7, 0xc9, 0xe4, 0x9b, 0xfb, 0x14, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -0,0 +1,105 @@
+package pm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/glog"
+)
+
+// BlockedSender is a sender whose tickets are rejected until the end of a cool-off period
+type BlockedSender struct {
+	Sender ethcommon.Address `json:"sender"`
+	Reason string            `json:"reason"`
+	Until  time.Time         `json:"until"`
+}
+
+func (b *BlockedSender) err() error {
+	return fmt.Errorf("tickets from sender %v are rejected until %v: %v", b.Sender.Hex(), b.Until.Format(time.RFC3339), b.Reason)
+}
+
+// checkBlocked returns an error if the tickets from a sender are rejected
+func (sm *LocalSenderMonitor) checkBlocked(addr ethcommon.Address) error {
+	sm.blockedMu.Lock()
+	defer sm.blockedMu.Unlock()
+
+	b, ok := sm.blocked[addr]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(b.Until) {
+		glog.Infof("Accepting tickets again after cool-off sender=%v", addr.Hex())
+		delete(sm.blocked, addr)
+		return nil
+	}
+	return b.err()
+}
+
+// block rejects the tickets from a sender for the cool-off period
+func (sm *LocalSenderMonitor) block(addr ethcommon.Address, reason string) error {
+	sm.blockedMu.Lock()
+	defer sm.blockedMu.Unlock()
+
+	b := &BlockedSender{
+		Sender: addr,
+		Reason: reason,
+		Until:  time.Now().Add(sm.cfg.SenderCoolOff),
+	}
+	sm.blocked[addr] = b
+	delete(sm.unredeemable, addr)
+	glog.Warningf("Rejecting tickets sender=%v until=%v reason=%q", addr.Hex(), b.Until.Format(time.RFC3339), reason)
+	return b.err()
+}
+
+// recordRedemptionResult counts the winning tickets from a sender that could not be redeemed and blocks the sender
+// once cfg.MaxUnredeemableTickets is reached. The count is reset when tickets from the sender are redeemed, i.e.
+// redeemed is greater than 0, which isn't the case of a batch whose tickets were all skipped by the broker.
+func (sm *LocalSenderMonitor) recordRedemptionResult(tickets []*SignedTicket, redeemed int, err error) {
+	if sm.cfg.MaxUnredeemableTickets <= 0 || len(tickets) == 0 {
+		return
+	}
+	sender := tickets[0].Sender
+
+	if err == nil {
+		if redeemed > 0 {
+			sm.blockedMu.Lock()
+			delete(sm.unredeemable, sender)
+			sm.blockedMu.Unlock()
+		}
+		return
+	}
+	if !isNonRetryableTicketErr(err) {
+		return
+	}
+
+	sm.blockedMu.Lock()
+	sm.unredeemable[sender] += len(tickets)
+	n := sm.unredeemable[sender]
+	sm.blockedMu.Unlock()
+
+	if n >= sm.cfg.MaxUnredeemableTickets {
+		sm.block(sender, fmt.Sprintf("%v unredeemable winning tickets", n))
+	}
+}
+
+// BlockedSenders returns the senders whose tickets are currently rejected
+func (sm *LocalSenderMonitor) BlockedSenders() []*BlockedSender {
+	sm.blockedMu.Lock()
+	defer sm.blockedMu.Unlock()
+
+	now := time.Now()
+	blocked := make([]*BlockedSender, 0, len(sm.blocked))
+	for _, b := range sm.blocked {
+		if now.After(b.Until) {
+			continue
+		}
+		blocked = append(blocked, b)
+	}
+	sort.Slice(blocked, func(i, j int) bool {
+		return bytes.Compare(blocked[i].Sender.Bytes(), blocked[j].Sender.Bytes()) < 0
+	})
+	return blocked
+}
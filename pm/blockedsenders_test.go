@@ -0,0 +1,104 @@
+package pm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSender_MinReserveAlloc(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	cfg.MinReserveAlloc = big.NewInt(100)
+	cfg.SenderCoolOff = time.Hour
+	addr := RandAddress()
+	// reserve alloc = 1000 / 5 = 200
+	smgr.info[addr] = &SenderInfo{
+		Deposit:       big.NewInt(1000),
+		WithdrawRound: big.NewInt(0),
+		Reserve: &ReserveInfo{
+			FundsRemaining:        big.NewInt(1000),
+			ClaimedInCurrentRound: big.NewInt(0),
+		},
+	}
+	smgr.claimedReserve[addr] = big.NewInt(0)
+	sm := NewSenderMonitor(cfg, b, smgr, tm, newStubTicketStore())
+
+	assert.Nil(sm.ValidateSender(addr))
+	assert.Empty(sm.BlockedSenders())
+
+	// reserve alloc = 1000 / 5 - 150 = 50
+	smgr.claimedReserve[addr] = big.NewInt(150)
+	err := sm.ValidateSender(addr)
+	require.NotNil(err)
+	assert.Contains(err.Error(), "reserve allocation 50 below minimum 100")
+	blocked := sm.BlockedSenders()
+	require.Len(blocked, 1)
+	assert.Equal(addr, blocked[0].Sender)
+	assert.Equal("reserve allocation 50 below minimum 100", blocked[0].Reason)
+	assert.WithinDuration(time.Now().Add(time.Hour), blocked[0].Until, time.Minute)
+
+	// The sender stays blocked during the cool-off even if its reserve is replenished
+	smgr.claimedReserve[addr] = big.NewInt(0)
+	assert.EqualError(sm.ValidateSender(addr), blocked[0].err().Error())
+
+	// and is accepted again once the cool-off ends
+	sm.blocked[addr].Until = time.Now().Add(-time.Second)
+	assert.Empty(sm.BlockedSenders())
+	assert.Nil(sm.ValidateSender(addr))
+	assert.Empty(sm.blocked)
+
+	// Errors getting the reserve allocation are returned
+	smgr.claimedReserveErr = errors.New("ClaimedReserve error")
+	assert.EqualError(sm.ValidateSender(addr), fmt.Sprintf("could not get reserve allocation for %v: ClaimedReserve error", addr.Hex()))
+	assert.Empty(sm.BlockedSenders())
+}
+
+func TestRecordRedemptionResult(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	cfg.SenderCoolOff = time.Hour
+	sm := NewSenderMonitor(cfg, b, smgr, tm, newStubTicketStore())
+	addr := RandAddress()
+	tickets := []*SignedTicket{defaultSignedTicket(addr, 0), defaultSignedTicket(addr, 1)}
+
+	// Disabled by default
+	sm.recordRedemptionResult(tickets, 0, errIsUsedTicket)
+	assert.Empty(sm.unredeemable)
+
+	cfg.MaxUnredeemableTickets = 3
+	sm.recordRedemptionResult(tickets, 0, errIsUsedTicket)
+	assert.Equal(2, sm.unredeemable[addr])
+
+	// Retryable errors are not counted
+	sm.recordRedemptionResult(tickets[:1], 0, errors.New("insufficient sender funds for redeem tx cost"))
+	assert.Equal(2, sm.unredeemable[addr])
+
+	// The count is reset by a successful redemption, but not if no ticket was redeemed, e.g. if all the tickets of
+	// a batch were skipped
+	sm.recordRedemptionResult(tickets, 0, nil)
+	assert.Equal(2, sm.unredeemable[addr])
+	sm.recordRedemptionResult(tickets, 1, nil)
+	assert.Equal(0, sm.unredeemable[addr])
+
+	// The sender is blocked once the max is reached
+	sm.recordRedemptionResult(tickets, 0, errors.New("transaction failed txHash=0x0"))
+	assert.Empty(sm.BlockedSenders())
+	sm.recordRedemptionResult(tickets[:1], 0, errIsUsedTicket)
+	blocked := sm.BlockedSenders()
+	assert.Len(blocked, 1)
+	assert.Equal(addr, blocked[0].Sender)
+	assert.Equal("3 unredeemable winning tickets", blocked[0].Reason)
+	assert.Equal(0, sm.unredeemable[addr])
+
+	err := sm.ValidateSender(addr)
+	assert.EqualError(err, fmt.Sprintf("tickets from sender %v are rejected until %v: 3 unredeemable winning tickets", addr.Hex(), blocked[0].Until.Format(time.RFC3339)))
+}
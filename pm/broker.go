@@ -57,6 +57,10 @@ type Broker interface {
 	// the broker pays the ticket's face value to the ticket's recipient
 	RedeemWinningTicket(ticket *Ticket, sig []byte, recipientRand *big.Int) (*types.Transaction, error)
 
+	// BatchRedeemWinningTickets submits multiple tickets to be redeemed by the broker in a single transaction.
+	// The broker skips the tickets that fail to be redeemed instead of failing the transaction
+	BatchRedeemWinningTickets(tickets []*Ticket, sigs [][]byte, recipientRands []*big.Int) (*types.Transaction, error)
+
 	// IsUsedTicket checks if a ticket has been used
 	IsUsedTicket(ticket *Ticket) (bool, error)
 
@@ -0,0 +1,55 @@
+package pm
+
+import (
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// PaymentEventType identifies a payment event
+type PaymentEventType string
+
+const (
+	// WinningTicketReceived is emitted when a winning ticket is queued for redemption
+	WinningTicketReceived PaymentEventType = "winningTicketReceived"
+	// TicketRedeemed is emitted when a redemption transaction confirms
+	TicketRedeemed PaymentEventType = "ticketRedeemed"
+	// RedemptionFailed is emitted when winning tickets could not be redeemed
+	RedemptionFailed PaymentEventType = "redemptionFailed"
+	// MaxFloatReached is emitted when the face value of tickets for a sender is capped by the sender's max float
+	MaxFloatReached PaymentEventType = "maxFloatReached"
+)
+
+// PaymentEventTypes are all the payment event types
+var PaymentEventTypes = []PaymentEventType{WinningTicketReceived, TicketRedeemed, RedemptionFailed, MaxFloatReached}
+
+// PaymentEvent is an event that affects the revenue of a ticket recipient
+type PaymentEvent struct {
+	Type   PaymentEventType  `json:"type"`
+	Time   time.Time         `json:"time"`
+	Sender ethcommon.Address `json:"sender"`
+	// Tickets is the number of winning tickets of the event
+	Tickets   int      `json:"tickets,omitempty"`
+	FaceValue *big.Int `json:"faceValue,omitempty"`
+	// TxHash is set for the events of submitted redemption transactions
+	TxHash *ethcommon.Hash `json:"txHash,omitempty"`
+	// Reason is the error of a RedemptionFailed event
+	Reason string `json:"reason,omitempty"`
+	// MaxFloat is the sender's max float for a MaxFloatReached event
+	MaxFloat *big.Int `json:"maxFloat,omitempty"`
+}
+
+// PaymentEventSink receives payment events
+type PaymentEventSink interface {
+	// Notify handles a payment event. It is called inline with ticket processing so it should not block.
+	Notify(ev *PaymentEvent)
+}
+
+func notifyPaymentEvent(sink PaymentEventSink, ev *PaymentEvent) {
+	if sink == nil {
+		return
+	}
+	ev.Time = time.Now()
+	sink.Notify(ev)
+}
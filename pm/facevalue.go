@@ -0,0 +1,134 @@
+package pm
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// FaceValueStrategy determines the desired face value of the tickets that a recipient asks a sender for.
+// The recipient caps the face value at the sender's max float and the configured max face value, and derives
+// the win probability from it so that the tickets keep the configured EV.
+type FaceValueStrategy interface {
+	// FaceValue returns the desired face value of tickets from sender given the current cost of redeeming a ticket
+	FaceValue(sender ethcommon.Address, txCost *big.Int) *big.Int
+}
+
+// TxCostMultiplierStrategy sets the face value to a multiple of the current redemption tx cost, so that
+// the tx cost is a fixed fraction of the value of a winning ticket. The face value is at least evMultiplier times the EV.
+type TxCostMultiplierStrategy struct {
+	EV         *big.Int
+	Multiplier int
+}
+
+// FaceValue implements FaceValueStrategy
+func (s *TxCostMultiplierStrategy) FaceValue(sender ethcommon.Address, txCost *big.Int) *big.Int {
+	return txCostMultiplierFaceValue(s.EV, txCost, s.Multiplier)
+}
+
+func txCostMultiplierFaceValue(ev, txCost *big.Int, multiplier int) *big.Int {
+	// faceValue = txCost * txCostMultiplier
+	faceValue := new(big.Int).Mul(txCost, big.NewInt(int64(multiplier)))
+	if faceValue.Cmp(ev) < 0 {
+		faceValue = new(big.Int).Mul(ev, evMultiplier)
+	}
+	return faceValue
+}
+
+// FixedFaceValueStrategy sets the face value to a fixed amount regardless of the tx cost
+type FixedFaceValueStrategy struct {
+	Amount *big.Int
+}
+
+// FaceValue implements FaceValueStrategy
+func (s *FixedFaceValueStrategy) FaceValue(sender ethcommon.Address, txCost *big.Int) *big.Int {
+	return new(big.Int).Set(s.Amount)
+}
+
+// SmoothedTxCostStrategy sets the face value to a multiple of the exponential moving average of the redemption
+// tx cost instead of the current tx cost, so that the ticket params don't follow short lived gas price spikes
+type SmoothedTxCostStrategy struct {
+	ev         *big.Int
+	multiplier int
+	// window is the time constant of the moving average
+	window time.Duration
+
+	mu   sync.Mutex
+	avg  *big.Int
+	last time.Time
+	now  func() time.Time
+}
+
+// NewSmoothedTxCostStrategy creates a SmoothedTxCostStrategy that averages the tx cost over window
+func NewSmoothedTxCostStrategy(ev *big.Int, multiplier int, window time.Duration) *SmoothedTxCostStrategy {
+	return &SmoothedTxCostStrategy{
+		ev:         ev,
+		multiplier: multiplier,
+		window:     window,
+		now:        time.Now,
+	}
+}
+
+// FaceValue implements FaceValueStrategy
+func (s *SmoothedTxCostStrategy) FaceValue(sender ethcommon.Address, txCost *big.Int) *big.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.avg == nil || s.window <= 0 {
+		s.avg = new(big.Int).Set(txCost)
+	} else {
+		// Weigh the current tx cost by the time elapsed since the last observation
+		weight := 1 - math.Exp(-float64(now.Sub(s.last))/float64(s.window))
+		delta := new(big.Float).SetInt(new(big.Int).Sub(txCost, s.avg))
+		step, _ := delta.Mul(delta, big.NewFloat(weight)).Int(nil)
+		s.avg.Add(s.avg, step)
+	}
+	s.last = now
+
+	return txCostMultiplierFaceValue(s.ev, s.avg, s.multiplier)
+}
+
+// SenderScaledStrategy scales the face value of another strategy per sender, i.e. to limit the value at risk
+// in a single ticket from senders that are less trusted
+type SenderScaledStrategy struct {
+	Strategy FaceValueStrategy
+	Scales   map[ethcommon.Address]*big.Rat
+}
+
+// FaceValue implements FaceValueStrategy
+func (s *SenderScaledStrategy) FaceValue(sender ethcommon.Address, txCost *big.Int) *big.Int {
+	faceValue := s.Strategy.FaceValue(sender, txCost)
+	scale, ok := s.Scales[sender]
+	if !ok {
+		return faceValue
+	}
+	scaled := new(big.Int).Mul(faceValue, scale.Num())
+	return scaled.Quo(scaled, scale.Denom())
+}
+
+// ParseSenderScales parses a comma-separated list of <address>:<multiplier> pairs
+// i.e. "0x0000000000000000000000000000000000000001:0.5,0x0000000000000000000000000000000000000002:2"
+func ParseSenderScales(s string) (map[ethcommon.Address]*big.Rat, error) {
+	scales := make(map[ethcommon.Address]*big.Rat)
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid sender scale %q, expected <address>:<multiplier>", pair)
+		}
+		if !ethcommon.IsHexAddress(parts[0]) {
+			return nil, fmt.Errorf("invalid sender address %q", parts[0])
+		}
+		scale, ok := new(big.Rat).SetString(parts[1])
+		if !ok || scale.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid face value multiplier %q, must be greater than 0", parts[1])
+		}
+		scales[ethcommon.HexToAddress(parts[0])] = scale
+	}
+	return scales, nil
+}
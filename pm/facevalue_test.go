@@ -0,0 +1,84 @@
+package pm
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCostMultiplierStrategy(t *testing.T) {
+	assert := assert.New(t)
+	s := &TxCostMultiplierStrategy{EV: big.NewInt(5), Multiplier: 100}
+
+	assert.Equal(big.NewInt(1000), s.FaceValue(RandAddress(), big.NewInt(10)))
+	// at least evMultiplier times the EV
+	assert.Equal(big.NewInt(500), s.FaceValue(RandAddress(), big.NewInt(0)))
+}
+
+func TestFixedFaceValueStrategy(t *testing.T) {
+	assert := assert.New(t)
+	s := &FixedFaceValueStrategy{Amount: big.NewInt(1000)}
+
+	assert.Equal(big.NewInt(1000), s.FaceValue(RandAddress(), big.NewInt(10)))
+	assert.Equal(big.NewInt(1000), s.FaceValue(RandAddress(), big.NewInt(100000)))
+
+	// the returned face value can be modified without changing the strategy
+	s.FaceValue(RandAddress(), big.NewInt(10)).SetInt64(1)
+	assert.Equal(big.NewInt(1000), s.Amount)
+}
+
+func TestSmoothedTxCostStrategy(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Unix(0, 0)
+	s := NewSmoothedTxCostStrategy(big.NewInt(5), 100, time.Minute)
+	s.now = func() time.Time { return now }
+
+	// the first observation is used as is
+	assert.Equal(big.NewInt(1000), s.FaceValue(RandAddress(), big.NewInt(10)))
+
+	// a spike right after the last observation barely moves the average
+	assert.Equal(big.NewInt(1000), s.FaceValue(RandAddress(), big.NewInt(1000)))
+
+	// the average moves by 1 - e^-1 of the difference after one window
+	now = now.Add(time.Minute)
+	assert.Equal(big.NewInt(63500), s.FaceValue(RandAddress(), big.NewInt(1000)))
+
+	// and converges after many windows
+	now = now.Add(time.Hour)
+	assert.Equal(big.NewInt(100000), s.FaceValue(RandAddress(), big.NewInt(1000)))
+}
+
+func TestSenderScaledStrategy(t *testing.T) {
+	assert := assert.New(t)
+	sender := RandAddress()
+	s := &SenderScaledStrategy{
+		Strategy: &FixedFaceValueStrategy{Amount: big.NewInt(1000)},
+		Scales:   map[ethcommon.Address]*big.Rat{sender: big.NewRat(1, 4)},
+	}
+
+	assert.Equal(big.NewInt(250), s.FaceValue(sender, big.NewInt(10)))
+	assert.Equal(big.NewInt(1000), s.FaceValue(RandAddress(), big.NewInt(10)))
+}
+
+func TestParseSenderScales(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	scales, err := ParseSenderScales("0x0000000000000000000000000000000000000001:0.5, 0x0000000000000000000000000000000000000002:2")
+	require.Nil(err)
+	assert.Equal(map[ethcommon.Address]*big.Rat{
+		ethcommon.HexToAddress("0x0000000000000000000000000000000000000001"): big.NewRat(1, 2),
+		ethcommon.HexToAddress("0x0000000000000000000000000000000000000002"): big.NewRat(2, 1),
+	}, scales)
+
+	_, err = ParseSenderScales("0x0000000000000000000000000000000000000001")
+	assert.EqualError(err, `invalid sender scale "0x0000000000000000000000000000000000000001", expected <address>:<multiplier>`)
+	_, err = ParseSenderScales("foo:1")
+	assert.EqualError(err, `invalid sender address "foo"`)
+	_, err = ParseSenderScales("0x0000000000000000000000000000000000000001:0")
+	assert.EqualError(err, `invalid face value multiplier "0", must be greater than 0`)
+}
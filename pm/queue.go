@@ -20,8 +20,9 @@ type RedeemableEmitter interface {
 }
 
 type redemption struct {
-	SignedTicket *SignedTicket
-	resCh        chan struct {
+	// SignedTickets are redeemed in a single transaction
+	SignedTickets []*SignedTicket
+	resCh         chan struct {
 		txHash ethcommon.Hash
 		err    error
 	}
@@ -44,17 +45,25 @@ type ticketQueue struct {
 	sender ethcommon.Address
 	store  TicketStore
 
+	// batchSize is the max number of tickets redeemed in a single transaction
+	batchSize int
+
 	quit chan struct{}
 
 	mu sync.Mutex
 }
 
 func newTicketQueue(sender ethcommon.Address, sm *LocalSenderMonitor) *ticketQueue {
+	batchSize := 1
+	if sm.cfg != nil && sm.cfg.RedeemBatchSize > 1 {
+		batchSize = sm.cfg.RedeemBatchSize
+	}
 	return &ticketQueue{
 		tm:         sm.tm,
 		redeemable: make(chan *redemption),
 		store:      sm.ticketStore,
 		sender:     sender,
+		batchSize:  batchSize,
 		quit:       make(chan struct{}),
 	}
 }
@@ -116,6 +125,11 @@ func (q *ticketQueue) handleBlockEvent(latestL1Block *big.Int) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	if q.batchSize > 1 {
+		q.redeemBatches(latestL1Block)
+		return
+	}
+
 	numTickets, err := q.Length()
 	if err != nil {
 		glog.Errorf("Error getting queue length err=%q", err)
@@ -140,7 +154,7 @@ func (q *ticketQueue) handleBlockEvent(latestL1Block *big.Int) {
 				err    error
 			})
 
-			q.redeemable <- &redemption{nextTicket, resCh}
+			q.redeemable <- &redemption{[]*SignedTicket{nextTicket}, resCh}
 			select {
 			case res := <-resCh:
 				// after receiving the response we can close the channel so it can be GC'd
@@ -163,6 +177,65 @@ func (q *ticketQueue) handleBlockEvent(latestL1Block *big.Int) {
 	}
 }
 
+// redeemBatches pops the redeemable tickets from the queue in batches of up to q.batchSize tickets that are
+// each redeemed in a single transaction. It stops at the first batch that fails with a retryable error, so
+// that the tickets are retried on the next block.
+// The broker skips the tickets of a batch that fail to redeem, e.g. because the reserve of the sender can't
+// cover them, instead of reverting the transaction. All the tickets of a confirmed batch are still marked
+// redeemed with its hash, since they would fail again, and the skipped ones are only reported by the sender
+// monitor, which checks which tickets were used once the transaction confirmed.
+func (q *ticketQueue) redeemBatches(latestL1Block *big.Int) {
+	for {
+		tickets, err := q.store.SelectEarliestWinningTickets(q.sender, new(big.Int).Sub(q.tm.LastInitializedRound(), big.NewInt(ticketValidityPeriod)).Int64(), q.batchSize)
+		if err != nil {
+			glog.Errorf("Unable to select earliest winning tickets err=%q", err)
+			return
+		}
+
+		var batch []*SignedTicket
+		for _, ticket := range tickets {
+			if !q.isRecipientActive(ticket.Recipient) {
+				glog.V(5).Infof("Ticket recipient is not active in this round, cannot redeem ticket recipient=%v", ticket.Recipient.Hex())
+				continue
+			}
+			if ticket.ParamsExpirationBlock.Cmp(latestL1Block) <= 0 {
+				batch = append(batch, ticket)
+			}
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		resCh := make(chan struct {
+			txHash ethcommon.Hash
+			err    error
+		})
+		q.redeemable <- &redemption{batch, resCh}
+		select {
+		case res := <-resCh:
+			close(resCh)
+			if res.err != nil {
+				glog.Errorf("Error redeeming tickets=%d err=%q", len(batch), res.err)
+				if !isNonRetryableTicketErr(res.err) {
+					return
+				}
+			}
+			for _, ticket := range batch {
+				if err := q.store.MarkWinningTicketRedeemed(ticket, res.txHash); err != nil {
+					glog.Error(err)
+					return
+				}
+			}
+		case <-q.quit:
+			return
+		}
+
+		if len(tickets) < q.batchSize {
+			return
+		}
+	}
+}
+
 func isNonRetryableTicketErr(err error) bool {
 	return err == errIsUsedTicket ||
 		// Depends on logic in eth.client.CheckTx()
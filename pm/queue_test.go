@@ -59,7 +59,7 @@ func (qc *queueConsumer) Wait(num int, e RedeemableEmitter, done chan struct{})
 			ticket.resCh <- struct {
 				txHash ethcommon.Hash
 				err    error
-			}{ticket.SignedTicket.Hash(), qc.redemptionErr}
+			}{ticket.SignedTickets[0].Hash(), qc.redemptionErr}
 		}
 	}
 	done <- struct{}{}
@@ -123,8 +123,8 @@ func TestTicketQueueLoop(t *testing.T) {
 	// in order
 	redeemable := qc.Redeemable()
 	for i := 0; i < numTickets; i++ {
-		assert.Equal(uint32(i), redeemable[i].SignedTicket.SenderNonce)
-		assert.True(ts.submitted[fmt.Sprintf("%x", redeemable[i].SignedTicket.Sig)])
+		assert.Equal(uint32(i), redeemable[i].SignedTickets[0].SenderNonce)
+		assert.True(ts.submitted[fmt.Sprintf("%x", redeemable[i].SignedTickets[0].Sig)])
 	}
 }
 
@@ -200,6 +200,74 @@ func TestTicketQueueLoop_IsNonRetryableTicketErr_MarkAsRedeemed(t *testing.T) {
 	assert.False(ts.submitted[fmt.Sprintf("%x", ticket.Sig)])
 }
 
+func TestTicketQueueLoop_Batched(t *testing.T) {
+	assert := assert.New(t)
+
+	sender := RandAddress()
+	ts := newStubTicketStore()
+	tm := &stubTimeManager{round: big.NewInt(100)}
+	sm := &LocalSenderMonitor{
+		cfg:         &LocalSenderMonitorConfig{RedeemBatchSize: 3},
+		ticketStore: ts,
+		tm:          tm,
+	}
+
+	q := newTicketQueue(sender, sm)
+	q.Start()
+	defer q.Stop()
+
+	numTickets := 7
+	for i := 0; i < numTickets; i++ {
+		q.Add(defaultSignedTicket(sender, uint32(i)))
+	}
+	// Add ticket with non-expired params
+	nonExpTicket := defaultSignedTicket(sender, uint32(numTickets))
+	nonExpTicket.ParamsExpirationBlock = big.NewInt(100)
+	q.Add(nonExpTicket)
+
+	qc := &queueConsumer{}
+	done := make(chan struct{})
+	// The tickets are received in batches of 3, 3 and 1
+	go qc.Wait(3, q, done)
+	time.Sleep(time.Millisecond * 20)
+
+	tm.blockNumSink <- big.NewInt(1)
+	<-done
+	time.Sleep(20 * time.Millisecond)
+
+	qlen, err := q.Length()
+	assert.Nil(err)
+	assert.Equal(1, qlen)
+
+	redeemable := qc.Redeemable()
+	var sizes []int
+	var nonce uint32
+	for _, red := range redeemable {
+		sizes = append(sizes, len(red.SignedTickets))
+		for _, ticket := range red.SignedTickets {
+			assert.Equal(nonce, ticket.SenderNonce)
+			assert.True(ts.submitted[fmt.Sprintf("%x", ticket.Sig)])
+			nonce++
+		}
+	}
+	assert.Equal([]int{3, 3, 1}, sizes)
+	assert.False(ts.submitted[fmt.Sprintf("%x", nonExpTicket.Sig)])
+
+	// Tickets are not marked as redeemed after a retryable error
+	ticket := defaultSignedTicket(sender, uint32(numTickets+1))
+	q.Add(ticket)
+	qc = &queueConsumer{redemptionErr: errors.New("some other error")}
+	go qc.Wait(1, q, done)
+	time.Sleep(time.Millisecond * 20)
+
+	tm.blockNumSink <- big.NewInt(1)
+	<-done
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Len(qc.Redeemable()[0].SignedTickets, 1)
+	assert.False(ts.submitted[fmt.Sprintf("%x", ticket.Sig)])
+}
+
 func TestTicketQueueLoopConcurrent(t *testing.T) {
 	assert := assert.New(t)
 
@@ -36,7 +36,7 @@ type Recipient interface {
 	// ReceiveTicket validates and processes a received ticket
 	ReceiveTicket(ticket *Ticket, sig []byte, seed *big.Int) (sessionID string, won bool, err error)
 
-	// RedeemWinningTicket redeems a single winning ticket
+	// RedeemWinningTicket queues a single winning ticket for redemption. The ticket is persisted when it returns without an error.
 	RedeemWinningTicket(ticket *Ticket, sig []byte, seed *big.Int) error
 
 	// TicketParams returns the recipient's currently accepted ticket parameters
@@ -65,6 +65,13 @@ type TicketParamsConfig struct {
 	// TxCostMultiplier is the desired multiplier of the transaction
 	// cost for redemption
 	TxCostMultiplier int
+
+	// FaceValueStrategy determines the desired face value of tickets.
+	// Defaults to a TxCostMultiplierStrategy using EV and TxCostMultiplier
+	FaceValueStrategy FaceValueStrategy
+
+	// Events receives the winning ticket and max float events of the recipient. Optional
+	Events PaymentEventSink
 }
 
 // GasPriceMonitor defines methods for monitoring gas prices
@@ -114,7 +121,7 @@ func NewRecipient(addr ethcommon.Address, broker Broker, val Validator, gpm GasP
 // secret. In most cases, NewRecipient should be used instead which will
 // automatically generate a random secret
 func NewRecipientWithSecret(addr ethcommon.Address, broker Broker, val Validator, gpm GasPriceMonitor, sm SenderMonitor, tm TimeManager, secret [32]byte, cfg TicketParamsConfig) Recipient {
-	return &recipient{
+	r := &recipient{
 		broker:       broker,
 		val:          val,
 		gpm:          gpm,
@@ -130,6 +137,10 @@ func NewRecipientWithSecret(addr ethcommon.Address, broker Broker, val Validator
 		cfg:  cfg,
 		quit: make(chan struct{}),
 	}
+	if r.cfg.FaceValueStrategy == nil {
+		r.cfg.FaceValueStrategy = &TxCostMultiplierStrategy{EV: cfg.EV, Multiplier: cfg.TxCostMultiplier}
+	}
+	return r
 }
 
 // Start initiates the helper goroutines for the recipient
@@ -182,7 +193,17 @@ func (r *recipient) ReceiveTicket(ticket *Ticket, sig []byte, seed *big.Int) (st
 // RedeemWinningTicket redeems a single winning ticket
 func (r *recipient) RedeemWinningTicket(ticket *Ticket, sig []byte, seed *big.Int) error {
 	recipientRand := r.rand(seed, ticket.Sender, ticket.FaceValue, ticket.WinProb, ticket.ParamsExpirationBlock, ticket.PricePerPixel, ticket.expirationParams())
-	return r.sm.QueueTicket(&SignedTicket{ticket, sig, recipientRand})
+	if err := r.sm.QueueTicket(&SignedTicket{ticket, sig, recipientRand}); err != nil {
+		return err
+	}
+
+	notifyPaymentEvent(r.cfg.Events, &PaymentEvent{
+		Type:      WinningTicketReceived,
+		Sender:    ticket.Sender,
+		Tickets:   1,
+		FaceValue: ticket.FaceValue,
+	})
+	return nil
 }
 
 // TicketParams returns the recipient's currently accepted ticket parameters
@@ -260,12 +281,7 @@ func (r *recipient) txCostWithGasPrice(gasPrice *big.Int) *big.Int {
 
 func (r *recipient) faceValue(sender ethcommon.Address) (*big.Int, error) {
 	txCost := r.txCost()
-	// faceValue = txCost * txCostMultiplier
-	faceValue := new(big.Int).Mul(txCost, big.NewInt(int64(r.cfg.TxCostMultiplier)))
-
-	if faceValue.Cmp(r.cfg.EV) < 0 {
-		faceValue = new(big.Int).Mul(r.cfg.EV, evMultiplier)
-	}
+	faceValue := r.cfg.FaceValueStrategy.FaceValue(sender, txCost)
 
 	// Fetch current max float for sender
 	maxFloat, err := r.sm.MaxFloat(sender)
@@ -276,6 +292,12 @@ func (r *recipient) faceValue(sender ethcommon.Address) (*big.Int, error) {
 	if faceValue.Cmp(maxFloat) > 0 {
 		// If faceValue > maxFloat
 		// Set faceValue = maxFloat
+		notifyPaymentEvent(r.cfg.Events, &PaymentEvent{
+			Type:      MaxFloatReached,
+			Sender:    sender,
+			FaceValue: faceValue,
+			MaxFloat:  maxFloat,
+		})
 		faceValue = maxFloat
 	}
 
@@ -383,6 +383,48 @@ func TestRedeemWinningTicket(t *testing.T) {
 	assert.Equal(sm.queued[0].Ticket, ticket)
 }
 
+func TestRecipient_PaymentEvents(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	sender, b, v, gm, sm, tm, cfg, sig := newRecipientFixtureOrFatal(t)
+	events := &stubPaymentEventSink{}
+	cfg.Events = events
+	secret := [32]byte{3}
+	r := NewRecipientWithSecret(RandAddress(), b, v, gm, sm, tm, secret, cfg)
+
+	// No event when the face value is below the max float
+	params := ticketParamsOrFatal(t, r, sender)
+	assert.Empty(events.Events())
+
+	// Queued winning ticket
+	ticket := newTicket(sender, params, 1)
+	require.Nil(r.RedeemWinningTicket(ticket, sig, genRecipientRand(sender, secret, params)))
+	require.Len(events.Events(), 1)
+	ev := events.Events()[0]
+	assert.Equal(WinningTicketReceived, ev.Type)
+	assert.Equal(sender, ev.Sender)
+	assert.Equal(1, ev.Tickets)
+	assert.Equal(params.FaceValue, ev.FaceValue)
+	assert.False(ev.Time.IsZero())
+
+	// No event when the ticket could not be queued
+	sm.shouldFail = errors.New("QueueTicket error")
+	assert.EqualError(r.RedeemWinningTicket(ticket, sig, genRecipientRand(sender, secret, params)), "QueueTicket error")
+	assert.Len(events.Events(), 1)
+
+	// Face value capped by the max float
+	sm.maxFloat = new(big.Int).Sub(params.FaceValue, big.NewInt(1))
+	params2 := ticketParamsOrFatal(t, r, sender)
+	require.Len(events.Events(), 2)
+	ev = events.Events()[1]
+	assert.Equal(MaxFloatReached, ev.Type)
+	assert.Equal(sender, ev.Sender)
+	assert.Equal(params.FaceValue, ev.FaceValue)
+	assert.Equal(sm.maxFloat, ev.MaxFloat)
+	assert.Equal(sm.maxFloat, params2.FaceValue)
+}
+
 func TestTicketParams(t *testing.T) {
 	sender, b, v, gm, sm, tm, cfg, _ := newRecipientFixtureOrFatal(t)
 	recipient := RandAddress()
@@ -731,3 +773,31 @@ func calcWinProb(faceValue, EV *big.Int) *big.Int {
 	// Compute winProb as the numerator of a fraction over maxWinProb
 	return new(big.Int).Mul(EV, x)
 }
+
+func TestTicketParams_FaceValueStrategy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	sender, b, v, gm, sm, tm, cfg, _ := newRecipientFixtureOrFatal(t)
+	txCost := new(big.Int).Mul(gm.gasPrice, big.NewInt(int64(cfg.RedeemGas)))
+	cfg.FaceValueStrategy = &FixedFaceValueStrategy{Amount: new(big.Int).Mul(txCost, big.NewInt(2))}
+	r := NewRecipientWithSecret(RandAddress(), b, v, gm, sm, tm, [32]byte{3}, cfg)
+
+	params, err := r.TicketParams(sender, big.NewRat(1, 1))
+	require.Nil(err)
+	assert.Equal(new(big.Int).Mul(txCost, big.NewInt(2)), params.FaceValue)
+	mul, err := r.TxCostMultiplier(sender)
+	require.Nil(err)
+	assert.Equal(big.NewRat(2, 1), mul)
+
+	// the face value is still capped by the sender's max float
+	sm.maxFloat = new(big.Int).Add(txCost, big.NewInt(1))
+	params, err = r.TicketParams(sender, big.NewRat(1, 1))
+	require.Nil(err)
+	assert.Equal(sm.maxFloat, params.FaceValue)
+
+	// and must cover the redemption cost
+	cfg.FaceValueStrategy = &FixedFaceValueStrategy{Amount: big.NewInt(10)}
+	r = NewRecipientWithSecret(RandAddress(), b, v, gm, sm, tm, [32]byte{3}, cfg)
+	_, err = r.TicketParams(sender, big.NewRat(1, 1))
+	assert.Equal(errInsufficientSenderReserve, err)
+}
@@ -0,0 +1,253 @@
+package pm
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/alert"
+	"github.com/livepeer/go-livepeer/monitor"
+)
+
+// maxRedemptionFailures is the number of most recent failed redemptions that are kept for reporting
+const maxRedemptionFailures = 100
+
+// SubmittedRedemption is a ticket redemption transaction that is waiting to confirm
+type SubmittedRedemption struct {
+	TxHash      ethcommon.Hash    `json:"txHash"`
+	Sender      ethcommon.Address `json:"sender"`
+	Tickets     int               `json:"tickets"`
+	FaceValue   *big.Int          `json:"faceValue"`
+	GasCost     *big.Int          `json:"gasCost"`
+	SubmittedAt time.Time         `json:"submittedAt"`
+}
+
+// RedemptionFailure is a failed attempt to redeem winning tickets
+type RedemptionFailure struct {
+	Time      time.Time         `json:"time"`
+	Sender    ethcommon.Address `json:"sender"`
+	Tickets   int               `json:"tickets"`
+	FaceValue *big.Int          `json:"faceValue"`
+	// TxHash is nil if the redemption failed before a transaction was submitted
+	TxHash *ethcommon.Hash `json:"txHash,omitempty"`
+	Reason string          `json:"reason"`
+	// Retryable is true if the tickets are redeemed again on a later block
+	Retryable bool `json:"retryable"`
+}
+
+// RedemptionStats summarizes the winning ticket redemptions since the node started
+type RedemptionStats struct {
+	RedeemedTickets int64    `json:"redeemedTickets"`
+	ValueRedeemed   *big.Int `json:"valueRedeemed"`
+	// GasSpent is the estimated gas cost of all submitted redemption transactions, including the failed ones
+	GasSpent  *big.Int               `json:"gasSpent"`
+	Submitted []*SubmittedRedemption `json:"submitted"`
+	// Failed are the most recent failed redemptions, latest first
+	Failed []*RedemptionFailure `json:"failed"`
+}
+
+// SenderRedemptionStatus is the redemption state of the winning tickets from a sender
+type SenderRedemptionStatus struct {
+	Sender   ethcommon.Address `json:"sender"`
+	MaxFloat *big.Int          `json:"maxFloat"`
+	// PendingTickets is the number of winning tickets that are stored, but not yet redeemed
+	PendingTickets int `json:"pendingTickets"`
+	// PendingAmount is the face value of the winning tickets in submitted redemption transactions
+	PendingAmount *big.Int `json:"pendingAmount"`
+}
+
+// RedemptionReporter reports the state of the winning ticket redemptions of a node
+type RedemptionReporter interface {
+	RedemptionStats() *RedemptionStats
+	SenderStatuses() ([]*SenderRedemptionStatus, error)
+	BlockedSenders() []*BlockedSender
+}
+
+// redemptionTracker keeps track of the redemption transactions submitted by a LocalSenderMonitor
+type redemptionTracker struct {
+	mu              sync.Mutex
+	submitted       map[ethcommon.Hash]*SubmittedRedemption
+	failures        []*RedemptionFailure
+	redeemedTickets int64
+	valueRedeemed   *big.Int
+	gasSpent        *big.Int
+
+	events PaymentEventSink
+}
+
+func newRedemptionTracker(events PaymentEventSink) *redemptionTracker {
+	return &redemptionTracker{
+		events:        events,
+		submitted:     make(map[ethcommon.Hash]*SubmittedRedemption),
+		valueRedeemed: big.NewInt(0),
+		gasSpent:      big.NewInt(0),
+	}
+}
+
+// submit records a redemption tx that was sent with an estimated gas cost of gasCost
+func (rt *redemptionTracker) submit(tx *types.Transaction, sender ethcommon.Address, tickets int, faceValue, gasCost *big.Int) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.submitted[tx.Hash()] = &SubmittedRedemption{
+		TxHash:      tx.Hash(),
+		Sender:      sender,
+		Tickets:     tickets,
+		FaceValue:   faceValue,
+		GasCost:     gasCost,
+		SubmittedAt: time.Now(),
+	}
+	rt.gasSpent.Add(rt.gasSpent, gasCost)
+
+	if monitor.Enabled {
+		monitor.TicketRedemptionGasCost(sender.Hex(), gasCost)
+		monitor.TicketRedemptionsSubmitted(len(rt.submitted))
+	}
+}
+
+// confirm records the outcome of a submitted redemption tx, which redeemed tickets with a total faceValue if it
+// confirmed. A batch redemption may redeem fewer tickets than were submitted.
+func (rt *redemptionTracker) confirm(tx *types.Transaction, tickets int, faceValue *big.Int, err error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	red, ok := rt.submitted[tx.Hash()]
+	if !ok {
+		return
+	}
+	delete(rt.submitted, tx.Hash())
+	if err == nil && tickets > 0 {
+		rt.redeemedTickets += int64(tickets)
+		rt.valueRedeemed.Add(rt.valueRedeemed, faceValue)
+
+		txHash := red.TxHash
+		notifyPaymentEvent(rt.events, &PaymentEvent{
+			Type:      TicketRedeemed,
+			Sender:    red.Sender,
+			Tickets:   tickets,
+			FaceValue: faceValue,
+			TxHash:    &txHash,
+		})
+	}
+
+	if monitor.Enabled {
+		monitor.TicketRedemptionsSubmitted(len(rt.submitted))
+	}
+}
+
+// fail records a failed attempt to redeem tickets. tx is nil if no redemption tx was sent.
+func (rt *redemptionTracker) fail(tickets []*SignedTicket, tx *types.Transaction, err error) {
+	faceValue := big.NewInt(0)
+	for _, ticket := range tickets {
+		faceValue.Add(faceValue, ticket.FaceValue)
+	}
+	failure := &RedemptionFailure{
+		Time:      time.Now(),
+		Sender:    tickets[0].Sender,
+		Tickets:   len(tickets),
+		FaceValue: faceValue,
+		Reason:    err.Error(),
+		Retryable: !isNonRetryableTicketErr(err),
+	}
+	if tx != nil {
+		txHash := tx.Hash()
+		failure.TxHash = &txHash
+	}
+
+	notifyPaymentEvent(rt.events, &PaymentEvent{
+		Type:      RedemptionFailed,
+		Sender:    failure.Sender,
+		Tickets:   failure.Tickets,
+		FaceValue: failure.FaceValue,
+		TxHash:    failure.TxHash,
+		Reason:    failure.Reason,
+	})
+
+	severity := alert.Warning
+	if !failure.Retryable {
+		severity = alert.Critical
+	}
+	alert.Fire(alert.RedemptionFailed, severity, failure.Sender.Hex(), "Redeeming tickets=%d faceValue=%v from sender=%v failed retryable=%v err=%q",
+		failure.Tickets, failure.FaceValue, failure.Sender.Hex(), failure.Retryable, failure.Reason)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.failures = append(rt.failures, failure)
+	if len(rt.failures) > maxRedemptionFailures {
+		rt.failures = rt.failures[len(rt.failures)-maxRedemptionFailures:]
+	}
+}
+
+func (rt *redemptionTracker) stats() *RedemptionStats {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	stats := &RedemptionStats{
+		RedeemedTickets: rt.redeemedTickets,
+		ValueRedeemed:   new(big.Int).Set(rt.valueRedeemed),
+		GasSpent:        new(big.Int).Set(rt.gasSpent),
+		Submitted:       make([]*SubmittedRedemption, 0, len(rt.submitted)),
+		Failed:          make([]*RedemptionFailure, 0, len(rt.failures)),
+	}
+	for _, red := range rt.submitted {
+		stats.Submitted = append(stats.Submitted, red)
+	}
+	sort.Slice(stats.Submitted, func(i, j int) bool {
+		return stats.Submitted[i].SubmittedAt.Before(stats.Submitted[j].SubmittedAt)
+	})
+	for i := len(rt.failures) - 1; i >= 0; i-- {
+		stats.Failed = append(stats.Failed, rt.failures[i])
+	}
+	return stats
+}
+
+// RedemptionStats returns the winning ticket redemptions submitted and failed since the monitor started
+func (sm *LocalSenderMonitor) RedemptionStats() *RedemptionStats {
+	return sm.redemptions.stats()
+}
+
+// SenderStatuses returns the redemption state of the winning tickets of the senders tracked by the monitor
+func (sm *LocalSenderMonitor) SenderStatuses() ([]*SenderRedemptionStatus, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	statuses := make([]*SenderRedemptionStatus, 0, len(sm.senders))
+	for addr, sender := range sm.senders {
+		maxFloat, err := sm.maxFloat(addr)
+		if err != nil {
+			return nil, err
+		}
+		pending, err := sender.queue.Length()
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, &SenderRedemptionStatus{
+			Sender:         addr,
+			MaxFloat:       maxFloat,
+			PendingTickets: pending,
+			PendingAmount:  new(big.Int).Set(sender.pendingAmount),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return bytes.Compare(statuses[i].Sender.Bytes(), statuses[j].Sender.Bytes()) < 0
+	})
+	return statuses, nil
+}
+
+// recordSenderMetrics records the max float and the number of pending winning tickets of the tracked senders
+func (sm *LocalSenderMonitor) recordSenderMetrics() {
+	statuses, err := sm.SenderStatuses()
+	if err != nil {
+		glog.Errorf("Error getting sender statuses err=%q", err)
+		return
+	}
+	for _, status := range statuses {
+		monitor.SenderMaxFloat(status.Sender.Hex(), status.MaxFloat)
+		monitor.WinningTicketsPending(status.Sender.Hex(), status.PendingTickets)
+	}
+}
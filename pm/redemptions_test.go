@@ -0,0 +1,189 @@
+package pm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedemptionStats(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	cfg.RedeemGas = 1000
+	cfg.SuggestGasPrice = func(ctx context.Context) (*big.Int, error) { return big.NewInt(2), nil }
+	events := &stubPaymentEventSink{}
+	cfg.Events = events
+	addr := RandAddress()
+	smgr.info[addr] = &SenderInfo{
+		Deposit:       big.NewInt(100000),
+		WithdrawRound: big.NewInt(0),
+		Reserve: &ReserveInfo{
+			FundsRemaining:        big.NewInt(100000),
+			ClaimedInCurrentRound: big.NewInt(0),
+		},
+	}
+	smgr.claimedReserve[addr] = big.NewInt(0)
+	sm := NewSenderMonitor(cfg, b, smgr, tm, newStubTicketStore())
+
+	stats := sm.RedemptionStats()
+	assert.Equal(int64(0), stats.RedeemedTickets)
+	assert.Equal(big.NewInt(0), stats.GasSpent)
+	assert.Empty(stats.Submitted)
+	assert.Empty(stats.Failed)
+
+	// Confirmed redemption
+	ticket := defaultSignedTicket(addr, 0)
+	ticket.FaceValue = big.NewInt(5000)
+	_, err := sm.redeemWinningTicket(ticket)
+	require.Nil(err)
+	stats = sm.RedemptionStats()
+	assert.Equal(int64(1), stats.RedeemedTickets)
+	assert.Equal(big.NewInt(5000), stats.ValueRedeemed)
+	assert.Equal(big.NewInt(2000), stats.GasSpent)
+	assert.Empty(stats.Submitted)
+	require.Len(events.Events(), 1)
+	assert.Equal(TicketRedeemed, events.Events()[0].Type)
+	assert.Equal(addr, events.Events()[0].Sender)
+	assert.Equal(1, events.Events()[0].Tickets)
+	assert.Equal(big.NewInt(5000), events.Events()[0].FaceValue)
+	assert.NotNil(events.Events()[0].TxHash)
+
+	// Failed redemption tx still spends gas
+	b.checkTxErr = errors.New("transaction failed txHash=0x0")
+	ticket = defaultSignedTicket(addr, 1)
+	ticket.FaceValue = big.NewInt(5000)
+	_, err = sm.redeemWinningTicket(ticket)
+	assert.Equal(b.checkTxErr, err)
+	stats = sm.RedemptionStats()
+	assert.Equal(int64(1), stats.RedeemedTickets)
+	assert.Equal(big.NewInt(5000), stats.ValueRedeemed)
+	assert.Equal(big.NewInt(4000), stats.GasSpent)
+	assert.Empty(stats.Submitted)
+	// The failure is reported by the ticket queue consumer
+	assert.Len(events.Events(), 1)
+
+	// Only the tickets of a batch that were redeemed are counted
+	b.checkTxErr = nil
+	tickets := []*SignedTicket{defaultSignedTicket(addr, 2), defaultSignedTicket(addr, 3), defaultSignedTicket(addr, 4)}
+	for i, ticket := range tickets {
+		ticket.FaceValue = big.NewInt(int64(10000 * (i + 1)))
+	}
+	b.batchSkipped = map[ethcommon.Hash]bool{tickets[1].Hash(): true}
+	_, _, err = sm.redeemWinningTickets(tickets)
+	require.Nil(err)
+	stats = sm.RedemptionStats()
+	assert.Equal(int64(3), stats.RedeemedTickets)
+	assert.Equal(big.NewInt(45000), stats.ValueRedeemed)
+	require.Len(events.Events(), 2)
+	assert.Equal(TicketRedeemed, events.Events()[1].Type)
+	assert.Equal(2, events.Events()[1].Tickets)
+	assert.Equal(big.NewInt(40000), events.Events()[1].FaceValue)
+
+	// No tickets are redeemed if the whole batch is skipped
+	tickets = []*SignedTicket{defaultSignedTicket(addr, 5), defaultSignedTicket(addr, 6)}
+	for _, ticket := range tickets {
+		ticket.FaceValue = big.NewInt(5000)
+		b.batchSkipped[ticket.Hash()] = true
+	}
+	_, _, err = sm.redeemWinningTickets(tickets)
+	require.Nil(err)
+	stats = sm.RedemptionStats()
+	assert.Equal(int64(3), stats.RedeemedTickets)
+	assert.Equal(big.NewInt(45000), stats.ValueRedeemed)
+	assert.Len(events.Events(), 2)
+}
+
+func TestRedemptionTracker_Fail(t *testing.T) {
+	assert := assert.New(t)
+
+	events := &stubPaymentEventSink{}
+	rt := newRedemptionTracker(events)
+	sender := RandAddress()
+	tickets := []*SignedTicket{defaultSignedTicket(sender, 0), defaultSignedTicket(sender, 1)}
+	tickets[0].FaceValue = big.NewInt(100)
+	tickets[1].FaceValue = big.NewInt(200)
+
+	rt.fail(tickets, nil, errors.New("insufficient sender funds for redeem tx cost"))
+	rt.fail(tickets[:1], nil, errIsUsedTicket)
+
+	failed := rt.stats().Failed
+	assert.Len(failed, 2)
+	// latest first
+	assert.Equal(errIsUsedTicket.Error(), failed[0].Reason)
+	assert.False(failed[0].Retryable)
+	assert.Equal(1, failed[0].Tickets)
+	assert.Equal("insufficient sender funds for redeem tx cost", failed[1].Reason)
+	assert.True(failed[1].Retryable)
+	assert.Equal(sender, failed[1].Sender)
+	assert.Equal(2, failed[1].Tickets)
+	assert.Equal(big.NewInt(300), failed[1].FaceValue)
+	assert.Nil(failed[1].TxHash)
+
+	evs := events.Events()
+	require.Len(t, evs, 2)
+	assert.Equal(RedemptionFailed, evs[0].Type)
+	assert.Equal(sender, evs[0].Sender)
+	assert.Equal(2, evs[0].Tickets)
+	assert.Equal(big.NewInt(300), evs[0].FaceValue)
+	assert.Equal("insufficient sender funds for redeem tx cost", evs[0].Reason)
+	assert.Nil(evs[0].TxHash)
+	assert.Equal(errIsUsedTicket.Error(), evs[1].Reason)
+
+	// Only the most recent failures are kept
+	for i := 0; i < maxRedemptionFailures; i++ {
+		rt.fail(tickets[:1], nil, fmt.Errorf("error %v", i))
+	}
+	failed = rt.stats().Failed
+	assert.Len(failed, maxRedemptionFailures)
+	assert.Equal(fmt.Sprintf("error %v", maxRedemptionFailures-1), failed[0].Reason)
+	assert.Equal("error 0", failed[maxRedemptionFailures-1].Reason)
+}
+
+func TestSenderStatuses(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	addr := RandAddress()
+	smgr.info[addr] = &SenderInfo{
+		Deposit:       big.NewInt(500),
+		WithdrawRound: big.NewInt(0),
+		Reserve: &ReserveInfo{
+			FundsRemaining:        big.NewInt(500),
+			ClaimedInCurrentRound: big.NewInt(0),
+		},
+	}
+	smgr.claimedReserve[addr] = big.NewInt(0)
+	ts := newStubTicketStore()
+	sm := NewSenderMonitor(cfg, b, smgr, tm, ts)
+	sm.Start()
+	defer sm.Stop()
+
+	statuses, err := sm.SenderStatuses()
+	require.Nil(err)
+	assert.Empty(statuses)
+
+	require.Nil(sm.QueueTicket(defaultSignedTicket(addr, 0)))
+	maxFloat, err := sm.MaxFloat(addr)
+	require.Nil(err)
+
+	statuses, err = sm.SenderStatuses()
+	require.Nil(err)
+	require.Len(statuses, 1)
+	assert.Equal(addr, statuses[0].Sender)
+	assert.Equal(maxFloat, statuses[0].MaxFloat)
+	assert.Equal(1, statuses[0].PendingTickets)
+	assert.Equal(big.NewInt(0), statuses[0].PendingAmount)
+
+	ts.loadShouldFail = true
+	_, err = sm.SenderStatuses()
+	assert.EqualError(err, "stub TicketStore load error")
+}
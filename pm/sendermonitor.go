@@ -19,6 +19,9 @@ import (
 // pending amount to be ignored when calculating the sender's max float
 const minDepositPendingRatio = 3.0
 
+// errSafeMode is a retryable redemption error returned while the redemptions are paused
+var errSafeMode = errors.New("redemptions paused in safe mode")
+
 // unixNow returns the current unix time
 // This is a wrapper function that can be stubbed in tests
 var unixNow = func() int64 {
@@ -60,6 +63,9 @@ type remoteSender struct {
 	lastAccess int64
 }
 
+// Gas paid by every transaction regardless of the contract calls it makes
+const txIntrinsicGas = 21000
+
 type LocalSenderMonitorConfig struct {
 	// The address that will be claiming from senders' reserves
 	Claimant ethcommon.Address
@@ -72,6 +78,27 @@ type LocalSenderMonitorConfig struct {
 	RedeemGas       int
 	SuggestGasPrice func(context.Context) (*big.Int, error)
 	RPCTimeout      time.Duration
+
+	// Max number of winning tickets from the same sender redeemed in a single transaction
+	RedeemBatchSize int
+
+	// Events receives the redemption events of the monitor. Optional
+	Events PaymentEventSink
+
+	// MinReserveAlloc is the reserve allocation below which the tickets from a sender are rejected. A nil value disables the check
+	MinReserveAlloc *big.Int
+	// MaxUnredeemableTickets is the number of winning tickets from a sender that fail to redeem with a non-retryable
+	// error after which the tickets from the sender are rejected. 0 disables the check
+	MaxUnredeemableTickets int
+	// SenderCoolOff is the time during which the tickets from a sender are rejected once it is blocked
+	SenderCoolOff time.Duration
+	// MaxSenderExposure is the face value of the unredeemed winning tickets from a sender at which its tickets are
+	// rejected until some of them are redeemed. A nil value disables the check
+	MaxSenderExposure *big.Int
+
+	// SafeMode returns true while the redemptions are paused because of a low ETH balance. The tickets stay queued
+	// and are redeemed once it returns false. Optional
+	SafeMode func() bool
 }
 
 type LocalSenderMonitor struct {
@@ -91,25 +118,36 @@ type LocalSenderMonitor struct {
 
 	ticketStore TicketStore
 
+	redemptions *redemptionTracker
+
+	// Senders whose tickets are rejected and the number of unredeemable winning tickets of each sender
+	blockedMu    sync.Mutex
+	blocked      map[ethcommon.Address]*BlockedSender
+	unredeemable map[ethcommon.Address]int
+
 	quit chan struct{}
 }
 
 // NewSenderMonitor returns a new SenderMonitor
 func NewSenderMonitor(cfg *LocalSenderMonitorConfig, broker Broker, smgr SenderManager, tm TimeManager, store TicketStore) *LocalSenderMonitor {
 	return &LocalSenderMonitor{
-		cfg:         cfg,
-		broker:      broker,
-		smgr:        smgr,
-		tm:          tm,
-		senders:     make(map[ethcommon.Address]*remoteSender),
-		redeemable:  make(chan *redemption),
-		ticketStore: store,
-		quit:        make(chan struct{}),
+		cfg:          cfg,
+		broker:       broker,
+		smgr:         smgr,
+		tm:           tm,
+		senders:      make(map[ethcommon.Address]*remoteSender),
+		redeemable:   make(chan *redemption),
+		ticketStore:  store,
+		redemptions:  newRedemptionTracker(cfg.Events),
+		blocked:      make(map[ethcommon.Address]*BlockedSender),
+		unredeemable: make(map[ethcommon.Address]int),
+		quit:         make(chan struct{}),
 	}
 }
 
 // Start initiates the helper goroutines for the monitor
 func (sm *LocalSenderMonitor) Start() {
+	sm.resumeTicketQueues()
 	go sm.startCleanupLoop()
 	go sm.watchReserveChange()
 	go sm.watchPoolSizeChange()
@@ -164,15 +202,21 @@ func (sm *LocalSenderMonitor) MaxFloat(addr ethcommon.Address) (*big.Int, error)
 // QueueTicket adds a ticket to the queue for a remote sender
 func (sm *LocalSenderMonitor) QueueTicket(ticket *SignedTicket) error {
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
 	sm.ensureCache(ticket.Sender)
+	queue := sm.senders[ticket.Sender].queue
+	sm.mu.Unlock()
 
-	return sm.senders[ticket.Sender].queue.Add(ticket)
+	// Store the ticket without holding the lock so that a slow DB write doesn't stall other senders
+	return queue.Add(ticket)
 }
 
-// ValidateSender checks whether a sender's unlock period ends the round after the next round
+// ValidateSender checks whether a sender's unlock period ends the round after the next round,
+// that the sender is not blocked, that its reserve allocation is not below cfg.MinReserveAlloc
+// and that the face value of its unredeemed winning tickets is below cfg.MaxSenderExposure
 func (sm *LocalSenderMonitor) ValidateSender(addr ethcommon.Address) error {
+	if err := sm.checkBlocked(addr); err != nil {
+		return err
+	}
 	info, err := sm.smgr.GetSenderInfo(addr)
 	if err != nil {
 		return fmt.Errorf("could not get sender info for %v: %v", addr.Hex(), err)
@@ -181,6 +225,26 @@ func (sm *LocalSenderMonitor) ValidateSender(addr ethcommon.Address) error {
 	if info.WithdrawRound.Int64() != 0 && info.WithdrawRound.Cmp(maxWithdrawRound) != 1 {
 		return fmt.Errorf("deposit and reserve for sender %v is set to unlock soon", addr.Hex())
 	}
+	if sm.cfg.MinReserveAlloc != nil {
+		reserveAlloc, err := sm.reserveAlloc(addr)
+		if err != nil {
+			return fmt.Errorf("could not get reserve allocation for %v: %v", addr.Hex(), err)
+		}
+		if reserveAlloc.Cmp(sm.cfg.MinReserveAlloc) < 0 {
+			return sm.block(addr, fmt.Sprintf("reserve allocation %v below minimum %v", reserveAlloc, sm.cfg.MinReserveAlloc))
+		}
+	}
+	if sm.cfg.MaxSenderExposure != nil {
+		minCreationRound := new(big.Int).Sub(sm.tm.LastInitializedRound(), big.NewInt(ticketValidityPeriod)).Int64()
+		exposure, err := sm.ticketStore.WinningTicketFaceValue(addr, minCreationRound)
+		if err != nil {
+			return fmt.Errorf("could not get unredeemed winning tickets for %v: %v", addr.Hex(), err)
+		}
+		// Unlike the other checks, the sender isn't blocked since its tickets are accepted again as soon as some are redeemed
+		if exposure.Cmp(sm.cfg.MaxSenderExposure) >= 0 {
+			return fmt.Errorf("unredeemed winning tickets from sender %v with face value %v reached max exposure %v", addr.Hex(), exposure, sm.cfg.MaxSenderExposure)
+		}
+	}
 	return nil
 }
 
@@ -281,6 +345,24 @@ func (sm *LocalSenderMonitor) cache(addr ethcommon.Address) {
 	}
 }
 
+// resumeTicketQueues starts the ticket queues of the senders with winning tickets that were stored,
+// but not redeemed before the node was last stopped, so that they are redeemed even if the senders don't send any more tickets
+func (sm *LocalSenderMonitor) resumeTicketQueues() {
+	minCreationRound := new(big.Int).Sub(sm.tm.LastInitializedRound(), big.NewInt(ticketValidityPeriod)).Int64()
+	senders, err := sm.ticketStore.SelectWinningTicketSenders(minCreationRound)
+	if err != nil {
+		glog.Errorf("Error loading senders with pending winning tickets err=%q", err)
+		return
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	for _, sender := range senders {
+		glog.Infof("Resuming redemption of pending winning tickets sender=%v", sender.Hex())
+		sm.ensureCache(sender)
+	}
+}
+
 // startTicketQueueConsumerLoop initiates a loop that runs a consumer
 // that receives redeemable tickets from a ticketQueue and feeds them into
 // a single output channel in a fan-in manner
@@ -288,7 +370,29 @@ func (sm *LocalSenderMonitor) startTicketQueueConsumerLoop(queue *ticketQueue, d
 	for {
 		select {
 		case red := <-queue.Redeemable():
-			tx, err := sm.redeemWinningTicket(red.SignedTicket)
+			if sm.cfg.SafeMode != nil && sm.cfg.SafeMode() {
+				// Not a redemption failure, so the tickets are retried on a later block without being recorded
+				red.resCh <- struct {
+					txHash ethcommon.Hash
+					err    error
+				}{
+					ethcommon.Hash{},
+					errSafeMode,
+				}
+				continue
+			}
+
+			var tx *types.Transaction
+			var redeemed int
+			var err error
+			if len(red.SignedTickets) == 1 {
+				tx, err = sm.redeemWinningTicket(red.SignedTickets[0])
+				if err == nil {
+					redeemed = 1
+				}
+			} else {
+				tx, redeemed, err = sm.redeemWinningTickets(red.SignedTickets)
+			}
 			res := struct {
 				txHash ethcommon.Hash
 				err    error
@@ -301,6 +405,10 @@ func (sm *LocalSenderMonitor) startTicketQueueConsumerLoop(queue *ticketQueue, d
 			if tx != nil {
 				res.txHash = tx.Hash()
 			}
+			if err != nil {
+				sm.redemptions.fail(red.SignedTickets, tx, err)
+			}
+			sm.recordRedemptionResult(red.SignedTickets, redeemed, err)
 
 			red.resCh <- res
 		case <-done:
@@ -328,6 +436,9 @@ func (sm *LocalSenderMonitor) startCleanupLoop() {
 		select {
 		case <-ticker.C:
 			sm.cleanup()
+			if monitor.Enabled {
+				sm.recordSenderMetrics()
+			}
 		case <-sm.quit:
 			return
 		}
@@ -342,6 +453,10 @@ func (sm *LocalSenderMonitor) cleanup() {
 
 	for k, v := range sm.senders {
 		if unixNow()-v.lastAccess > int64(sm.cfg.TTL) && v.subScope.Count() == 0 {
+			// Keep the ticket queue running until the sender's winning tickets are redeemed
+			if n, err := v.queue.Length(); err != nil || n > 0 {
+				continue
+			}
 			// Signal the ticket queue consumer to exit gracefully
 			v.done <- struct{}{}
 			v.subScope.Close() // close the maxfloat subscriptions
@@ -420,9 +535,12 @@ func (sm *LocalSenderMonitor) redeemWinningTicket(ticket *SignedTicket) (*types.
 		}
 		return nil, err
 	}
+	sm.redemptions.submit(tx, ticket.Sender, 1, ticket.FaceValue, txCost)
 
 	// Wait for transaction to confirm
-	if err := sm.broker.CheckTx(tx); err != nil {
+	err = sm.broker.CheckTx(tx)
+	sm.redemptions.confirm(tx, 1, ticket.FaceValue, err)
+	if err != nil {
 		if monitor.Enabled {
 			monitor.TicketRedemptionError(ticket.Sender.Hex())
 		}
@@ -434,11 +552,144 @@ func (sm *LocalSenderMonitor) redeemWinningTicket(ticket *SignedTicket) (*types.
 		// TODO(yondonfu): Handle case where < ticket.FaceValue is actually
 		// redeemed i.e. if sender reserve cannot cover the full ticket.FaceValue
 		monitor.ValueRedeemed(ticket.Sender.Hex(), ticket.Ticket.FaceValue)
+		monitor.TicketsRedeemed(ticket.Sender.Hex(), 1)
 	}
 
 	return tx, nil
 }
 
+// redeemWinningTickets redeems winning tickets from the same sender in a single transaction. The tx cost is
+// covered by the total face value of the tickets instead of by the face value of each ticket.
+// Returns a non-nil tx if one is sent. Otherwise, returns a nil tx. Also returns the number of tickets that the
+// confirmed tx redeemed, since the broker skips the tickets of a batch that fail to redeem.
+func (sm *LocalSenderMonitor) redeemWinningTickets(tickets []*SignedTicket) (*types.Transaction, int, error) {
+	sender := tickets[0].Sender
+	availableFunds, err := sm.availableFunds(sender)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Skip used tickets so that we don't pay for their redemption
+	var (
+		unused         []*Ticket
+		sigs           [][]byte
+		recipientRands []*big.Int
+	)
+	totalFaceValue := big.NewInt(0)
+	for _, ticket := range tickets {
+		used, err := sm.broker.IsUsedTicket(ticket.Ticket)
+		if err != nil {
+			if monitor.Enabled {
+				monitor.TicketRedemptionError(sender.Hex())
+			}
+			return nil, 0, err
+		}
+		if used {
+			if monitor.Enabled {
+				monitor.TicketRedemptionError(sender.Hex())
+			}
+			continue
+		}
+		unused = append(unused, ticket.Ticket)
+		sigs = append(sigs, ticket.Sig)
+		recipientRands = append(recipientRands, ticket.RecipientRand)
+		totalFaceValue.Add(totalFaceValue, ticket.FaceValue)
+	}
+	if len(unused) == 0 {
+		return nil, 0, errIsUsedTicket
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sm.cfg.RPCTimeout)
+	gasPrice, err := sm.cfg.SuggestGasPrice(ctx)
+	if err != nil {
+		cancel()
+		return nil, 0, err
+	}
+	cancel()
+
+	// The base tx cost is only paid once for the whole batch
+	txCost := new(big.Int).Mul(big.NewInt(int64(batchRedeemGas(sm.cfg.RedeemGas, len(unused)))), gasPrice)
+	if availableFunds.Cmp(txCost) <= 0 {
+		return nil, 0, errors.New("insufficient sender funds for redeem tx cost")
+	}
+	if totalFaceValue.Cmp(txCost) <= 0 {
+		return nil, 0, errors.New("insufficient ticket face value for redeem tx cost")
+	}
+
+	// The total face value is considered pending until the redemption transaction confirms on-chain
+	sm.subFloat(sender, totalFaceValue)
+
+	defer func() {
+		if err := sm.addFloat(sender, totalFaceValue); err != nil {
+			glog.Error(err)
+		}
+	}()
+
+	tx, err := sm.broker.BatchRedeemWinningTickets(unused, sigs, recipientRands)
+	if err != nil {
+		if monitor.Enabled {
+			monitor.TicketRedemptionError(sender.Hex())
+		}
+		return nil, 0, err
+	}
+	sm.redemptions.submit(tx, sender, len(unused), totalFaceValue, txCost)
+
+	err = sm.broker.CheckTx(tx)
+	if err != nil {
+		sm.redemptions.confirm(tx, 0, nil, err)
+		if monitor.Enabled {
+			monitor.TicketRedemptionError(sender.Hex())
+		}
+		return tx, 0, err
+	}
+
+	redeemed, redeemedFaceValue := sm.redeemedTickets(unused)
+	sm.redemptions.confirm(tx, redeemed, redeemedFaceValue, nil)
+	if redeemed < len(unused) {
+		glog.Warningf("Tickets skipped by batch redemption sender=%v tx=%v skipped=%d", sender.Hex(), tx.Hash().Hex(), len(unused)-redeemed)
+		if monitor.Enabled {
+			monitor.TicketRedemptionError(sender.Hex())
+		}
+	}
+
+	if monitor.Enabled {
+		monitor.ValueRedeemed(sender.Hex(), redeemedFaceValue)
+		monitor.TicketsRedeemed(sender.Hex(), redeemed)
+	}
+
+	return tx, redeemed, nil
+}
+
+// redeemedTickets returns the number and the total face value of the tickets of a confirmed batch redemption that
+// were redeemed. The broker skips the tickets of a batch that fail to redeem instead of reverting the transaction,
+// so the tickets that are still unused after it confirmed were not redeemed.
+func (sm *LocalSenderMonitor) redeemedTickets(tickets []*Ticket) (int, *big.Int) {
+	redeemed := 0
+	faceValue := big.NewInt(0)
+	for _, ticket := range tickets {
+		used, err := sm.broker.IsUsedTicket(ticket)
+		if err != nil {
+			// The ticket is assumed to be redeemed since the transaction confirmed
+			glog.Errorf("Error checking redeemed ticket err=%q", err)
+			used = true
+		}
+		if used {
+			redeemed++
+			faceValue.Add(faceValue, ticket.FaceValue)
+		}
+	}
+	return redeemed, faceValue
+}
+
+// batchRedeemGas estimates the gas used to redeem n tickets in a single transaction given
+// the gas used to redeem a single ticket, of which the intrinsic tx gas is only paid once
+func batchRedeemGas(redeemGas int, n int) int {
+	if redeemGas <= txIntrinsicGas {
+		return redeemGas * n
+	}
+	return txIntrinsicGas + n*(redeemGas-txIntrinsicGas)
+}
+
 // SubscribeMaxFloatChange notifies subcribers when the max float for a sender has changed
 // and that it should call LocalSenderMonitor.MaxFloat() to get the latest value
 func (sm *LocalSenderMonitor) SubscribeMaxFloatChange(sender ethcommon.Address, sink chan<- struct{}) event.Subscription {
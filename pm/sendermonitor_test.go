@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 	"time"
 
@@ -310,6 +311,129 @@ func TestQueueTicketAndSignalNewBlock(t *testing.T) {
 	assert.True(b.IsUsedTicket(signedT3.Ticket))
 }
 
+func TestQueueTicketAndSignalNewBlock_SafeMode(t *testing.T) {
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	addr := RandAddress()
+	smgr.info[addr] = &SenderInfo{
+		Deposit:       big.NewInt(500),
+		WithdrawRound: big.NewInt(0),
+		Reserve: &ReserveInfo{
+			FundsRemaining:        big.NewInt(5000),
+			ClaimedInCurrentRound: big.NewInt(0),
+		},
+	}
+
+	var mu sync.Mutex
+	safeMode := true
+	cfg.SafeMode = func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return safeMode
+	}
+
+	ts := newStubTicketStore()
+	smgr.claimedReserve[addr] = big.NewInt(100)
+	sm := NewSenderMonitor(cfg, b, smgr, tm, ts)
+	sm.Start()
+	defer sm.Stop()
+
+	assert := assert.New(t)
+
+	signedT := defaultSignedTicket(addr, uint32(0))
+	assert.Nil(sm.QueueTicket(signedT))
+	time.Sleep(20 * time.Millisecond)
+
+	// The ticket stays queued in safe mode
+	tm.blockNumSink <- big.NewInt(5)
+	time.Sleep(20 * time.Millisecond)
+	qlen, err := sm.senders[addr].queue.Length()
+	assert.Nil(err)
+	assert.Equal(1, qlen)
+	assert.False(b.IsUsedTicket(signedT.Ticket))
+
+	// The ticket is redeemed once the node leaves safe mode
+	mu.Lock()
+	safeMode = false
+	mu.Unlock()
+	tm.blockNumSink <- big.NewInt(6)
+	time.Sleep(20 * time.Millisecond)
+	qlen, err = sm.senders[addr].queue.Length()
+	assert.Nil(err)
+	assert.Equal(0, qlen)
+	assert.True(b.IsUsedTicket(signedT.Ticket))
+}
+
+func TestStart_ResumesPendingTicketQueues(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	addr := RandAddress()
+	smgr.info[addr] = &SenderInfo{
+		Deposit:       big.NewInt(500),
+		WithdrawRound: big.NewInt(0),
+		Reserve: &ReserveInfo{
+			FundsRemaining:        big.NewInt(5000),
+			ClaimedInCurrentRound: big.NewInt(0),
+		},
+	}
+	smgr.claimedReserve[addr] = big.NewInt(100)
+
+	// a ticket stored before the node was restarted
+	ts := newStubTicketStore()
+	signedT := defaultSignedTicket(addr, uint32(0))
+	require.Nil(ts.StoreWinningTicket(signedT))
+
+	sm := NewSenderMonitor(cfg, b, smgr, tm, ts)
+	sm.Start()
+	defer sm.Stop()
+
+	// the sender's queue is started without receiving a new ticket
+	require.NotNil(sm.senders[addr])
+	time.Sleep(20 * time.Millisecond)
+	tm.blockNumSink <- big.NewInt(5)
+	time.Sleep(20 * time.Millisecond)
+	assert.True(b.IsUsedTicket(signedT.Ticket))
+
+	// error loading the senders
+	ts.loadShouldFail = true
+	sm2 := NewSenderMonitor(cfg, b, smgr, tm, ts)
+	sm2.Start()
+	defer sm2.Stop()
+	assert.Empty(sm2.senders)
+}
+
+func TestCleanup_KeepsSendersWithPendingTickets(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	cfg.TTL = 5
+	addr := RandAddress()
+	smgr.info[addr] = &SenderInfo{
+		Deposit:       big.NewInt(500),
+		WithdrawRound: big.NewInt(0),
+		Reserve: &ReserveInfo{
+			FundsRemaining:        big.NewInt(5000),
+			ClaimedInCurrentRound: big.NewInt(0),
+		},
+	}
+	ts := newStubTicketStore()
+	sm := NewSenderMonitor(cfg, b, smgr, tm, ts)
+
+	setTime(0)
+	require.Nil(sm.QueueTicket(defaultSignedTicket(addr, uint32(0))))
+
+	increaseTime(10)
+	sm.cleanup()
+	assert.NotNil(sm.senders[addr])
+
+	// cleaned up once the ticket is redeemed
+	for _, ticket := range ts.tickets[addr] {
+		require.Nil(ts.MarkWinningTicketRedeemed(ticket, RandHash()))
+	}
+	sm.cleanup()
+	assert.Nil(sm.senders[addr])
+}
+
 func TestCleanup(t *testing.T) {
 	cfg, b, smgr, tm := localSenderMonitorFixture()
 	cfg.TTL = 5
@@ -563,6 +687,43 @@ func TestSenderMonitor_ValidateSender(t *testing.T) {
 	assert.EqualError(err, expErr)
 }
 
+func TestSenderMonitor_ValidateSender_MaxSenderExposure(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	cfg.MaxSenderExposure = big.NewInt(100)
+	addr := RandAddress()
+	smgr.info[addr] = &SenderInfo{WithdrawRound: big.NewInt(0)}
+	ts := newStubTicketStore()
+	sm := NewSenderMonitor(cfg, b, smgr, tm, ts)
+
+	assert.Nil(sm.ValidateSender(addr))
+
+	// face value = 50
+	ticket := defaultSignedTicket(addr, 0)
+	require.Nil(ts.StoreWinningTicket(ticket))
+	assert.Nil(sm.ValidateSender(addr))
+
+	// face value = 100
+	require.Nil(ts.StoreWinningTicket(defaultSignedTicket(addr, 1)))
+	err := sm.ValidateSender(addr)
+	assert.EqualError(err, fmt.Sprintf("unredeemed winning tickets from sender %v with face value 100 reached max exposure 100", addr.Hex()))
+	// other senders are not affected
+	other := RandAddress()
+	smgr.info[other] = &SenderInfo{WithdrawRound: big.NewInt(0)}
+	assert.Nil(sm.ValidateSender(other))
+
+	// tickets are accepted again once some are redeemed, without a cool-off
+	require.Nil(ts.MarkWinningTicketRedeemed(ticket, RandHash()))
+	assert.Nil(sm.ValidateSender(addr))
+	assert.Empty(sm.BlockedSenders())
+
+	// errors getting the unredeemed tickets are returned
+	ts.loadShouldFail = true
+	assert.EqualError(sm.ValidateSender(addr), fmt.Sprintf("could not get unredeemed winning tickets for %v: stub TicketStore load error", addr.Hex()))
+}
+
 func TestAvailableFunds(t *testing.T) {
 	assert := assert.New(t)
 
@@ -828,6 +989,131 @@ func TestRedeemWinningTicket_SingleTicket(t *testing.T) {
 	assert.True(ok)
 }
 
+func TestRedeemWinningTickets(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	addr := RandAddress()
+	smgr.info[addr] = &SenderInfo{
+		Deposit:       big.NewInt(100000),
+		WithdrawRound: big.NewInt(0),
+		Reserve: &ReserveInfo{
+			FundsRemaining:        big.NewInt(100000),
+			ClaimedInCurrentRound: big.NewInt(0),
+		},
+	}
+	smgr.claimedReserve[addr] = big.NewInt(0)
+
+	ts := newStubTicketStore()
+	sm := NewSenderMonitor(cfg, b, smgr, tm, ts)
+
+	ticket := func(nonce uint32) *SignedTicket {
+		t := defaultSignedTicket(addr, nonce)
+		t.FaceValue = big.NewInt(12000)
+		return t
+	}
+	tickets := []*SignedTicket{ticket(0), ticket(1), ticket(2)}
+
+	// A single ticket's face value doesn't cover the tx cost, but the total face value of a batch does
+	cfg.RedeemGas = txIntrinsicGas + 1000
+	cfg.SuggestGasPrice = func(ctx context.Context) (*big.Int, error) { return big.NewInt(1), nil }
+	_, err := sm.redeemWinningTicket(tickets[0])
+	assert.Contains(err.Error(), "insufficient ticket face value")
+
+	// Redeem error
+	b.redeemShouldFail = true
+	tx, _, err := sm.redeemWinningTickets(tickets)
+	assert.EqualError(err, "stub broker redeem error")
+	assert.Nil(tx)
+	b.redeemShouldFail = false
+
+	// Used tickets are skipped
+	b.usedTickets[tickets[1].Hash()] = true
+	tx, redeemed, err := sm.redeemWinningTickets(tickets)
+	assert.Nil(err)
+	assert.NotNil(tx)
+	assert.Equal(2, redeemed)
+	assert.Equal([]int{2}, b.batches)
+	for _, ticket := range tickets {
+		used, err := b.IsUsedTicket(ticket.Ticket)
+		assert.Nil(err)
+		assert.True(used)
+	}
+
+	// All tickets used
+	tx, _, err = sm.redeemWinningTickets(tickets)
+	assert.Nil(tx)
+	assert.Equal(errIsUsedTicket, err)
+
+	// The total face value must cover the tx cost
+	tickets = []*SignedTicket{ticket(3), ticket(4)}
+	cfg.SuggestGasPrice = func(ctx context.Context) (*big.Int, error) { return big.NewInt(2), nil }
+	_, _, err = sm.redeemWinningTickets(tickets)
+	assert.Contains(err.Error(), "insufficient ticket face value")
+
+	// CheckTx error
+	cfg.SuggestGasPrice = func(ctx context.Context) (*big.Int, error) { return big.NewInt(1), nil }
+	b.checkTxErr = errors.New("checktx error")
+	tx, _, err = sm.redeemWinningTickets(tickets)
+	assert.NotNil(tx)
+	assert.Equal(b.checkTxErr, err)
+
+	// The max float is restored after the redemption
+	mf, err := sm.MaxFloat(addr)
+	assert.Nil(err)
+	b.checkTxErr = nil
+	tickets = []*SignedTicket{ticket(5), ticket(6)}
+	_, _, err = sm.redeemWinningTickets(tickets)
+	assert.Nil(err)
+
+	mf2, err := sm.MaxFloat(addr)
+	assert.Nil(err)
+	assert.Equal(mf, mf2)
+	// The tickets skipped by the broker are not redeemed
+	tickets = []*SignedTicket{ticket(7), ticket(8)}
+	b.batchSkipped = map[ethcommon.Hash]bool{tickets[0].Hash(): true, tickets[1].Hash(): true}
+	tx, redeemed, err = sm.redeemWinningTickets(tickets)
+	assert.Nil(err)
+	assert.NotNil(tx)
+	assert.Equal(0, redeemed)
+}
+
+func TestRedeemedTickets(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg, b, smgr, tm := localSenderMonitorFixture()
+	sm := NewSenderMonitor(cfg, b, smgr, tm, newStubTicketStore())
+	addr := RandAddress()
+	var tickets []*Ticket
+	for i := 0; i < 3; i++ {
+		ticket := defaultSignedTicket(addr, uint32(i)).Ticket
+		ticket.FaceValue = big.NewInt(int64(100 * (i + 1)))
+		tickets = append(tickets, ticket)
+	}
+
+	// The tickets skipped by the broker are still unused
+	b.usedTickets[tickets[0].Hash()] = true
+	b.usedTickets[tickets[2].Hash()] = true
+	redeemed, faceValue := sm.redeemedTickets(tickets)
+	assert.Equal(2, redeemed)
+	assert.Equal(big.NewInt(400), faceValue)
+
+	// The tickets of a confirmed transaction are assumed redeemed if they can't be checked
+	b.isUsedErr = errors.New("IsUsedTicket error")
+	redeemed, faceValue = sm.redeemedTickets(tickets)
+	assert.Equal(3, redeemed)
+	assert.Equal(big.NewInt(600), faceValue)
+}
+
+func TestBatchRedeemGas(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(txIntrinsicGas+100, batchRedeemGas(txIntrinsicGas+100, 1))
+	assert.Equal(txIntrinsicGas+300, batchRedeemGas(txIntrinsicGas+100, 3))
+	// Estimates that don't account for the intrinsic gas are scaled
+	assert.Equal(300, batchRedeemGas(100, 3))
+}
+
 func TestRedeemWinningTicket_addFloatError(t *testing.T) {
 	cfg, b, smgr, tm := localSenderMonitorFixture()
 	addr := RandAddress()
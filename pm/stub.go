@@ -72,6 +72,24 @@ func (ts *stubTicketStore) SelectEarliestWinningTicket(sender ethcommon.Address,
 	return nil, nil
 }
 
+func (ts *stubTicketStore) SelectEarliestWinningTickets(sender ethcommon.Address, minCreationRound int64, limit int) ([]*SignedTicket, error) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	if ts.loadShouldFail {
+		return nil, fmt.Errorf("stub TicketStore load error")
+	}
+	var tickets []*SignedTicket
+	for _, t := range ts.tickets[sender] {
+		if len(tickets) >= limit {
+			break
+		}
+		if !ts.submitted[fmt.Sprintf("%x", t.Sig)] {
+			tickets = append(tickets, t)
+		}
+	}
+	return tickets, nil
+}
+
 func (ts *stubTicketStore) MarkWinningTicketRedeemed(ticket *SignedTicket, txHash ethcommon.Hash) error {
 	ts.lock.Lock()
 	defer ts.lock.Unlock()
@@ -114,6 +132,39 @@ func (ts *stubTicketStore) WinningTicketCount(sender ethcommon.Address, minCreat
 	return count, nil
 }
 
+func (ts *stubTicketStore) WinningTicketFaceValue(sender ethcommon.Address, minCreationRound int64) (*big.Int, error) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	if ts.loadShouldFail {
+		return nil, fmt.Errorf("stub TicketStore load error")
+	}
+	total := big.NewInt(0)
+	for _, t := range ts.tickets[sender] {
+		if !ts.submitted[fmt.Sprintf("%x", t.Sig)] {
+			total.Add(total, t.FaceValue)
+		}
+	}
+	return total, nil
+}
+
+func (ts *stubTicketStore) SelectWinningTicketSenders(minCreationRound int64) ([]ethcommon.Address, error) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	if ts.loadShouldFail {
+		return nil, fmt.Errorf("stub TicketStore load error")
+	}
+	var senders []ethcommon.Address
+	for sender, tickets := range ts.tickets {
+		for _, t := range tickets {
+			if !ts.submitted[fmt.Sprintf("%x", t.Sig)] {
+				senders = append(senders, sender)
+				break
+			}
+		}
+	}
+	return senders, nil
+}
+
 func (ts *stubTicketStore) IsOrchActive(addr ethcommon.Address, round *big.Int) (bool, error) {
 	return ts.isActive, ts.err
 }
@@ -147,6 +198,11 @@ type stubBroker struct {
 
 	checkTxErr error
 	isUsedErr  error
+
+	// sizes of the batches of tickets redeemed
+	batches []int
+	// tickets that are skipped instead of redeemed in batches
+	batchSkipped map[ethcommon.Hash]bool
 }
 
 func newStubBroker() *stubBroker {
@@ -193,6 +249,24 @@ func (b *stubBroker) RedeemWinningTicket(ticket *Ticket, sig []byte, recipientRa
 	return types.NewTx(&types.DynamicFeeTx{}), nil
 }
 
+func (b *stubBroker) BatchRedeemWinningTickets(tickets []*Ticket, sigs [][]byte, recipientRands []*big.Int) (*types.Transaction, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.redeemShouldFail {
+		return nil, fmt.Errorf("stub broker redeem error")
+	}
+
+	for _, ticket := range tickets {
+		if !b.batchSkipped[ticket.Hash()] {
+			b.usedTickets[ticket.Hash()] = true
+		}
+	}
+	b.batches = append(b.batches, len(tickets))
+
+	return types.NewTx(&types.DynamicFeeTx{}), nil
+}
+
 func (b *stubBroker) IsUsedTicket(ticket *Ticket) (bool, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -435,6 +509,23 @@ func (s *stubSenderMonitor) MaxFloat(addr ethcommon.Address) (*big.Int, error) {
 
 func (s *stubSenderMonitor) ValidateSender(addr ethcommon.Address) error { return s.validateSenderErr }
 
+type stubPaymentEventSink struct {
+	mu     sync.Mutex
+	events []*PaymentEvent
+}
+
+func (s *stubPaymentEventSink) Notify(ev *PaymentEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+}
+
+func (s *stubPaymentEventSink) Events() []*PaymentEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*PaymentEvent(nil), s.events...)
+}
+
 // MockRecipient is useful for testing components that depend on pm.Recipient
 type MockRecipient struct {
 	mock.Mock
@@ -12,6 +12,10 @@ type TicketStore interface {
 	// which is not yet redeemed
 	SelectEarliestWinningTicket(sender ethcommon.Address, minCreationRound int64) (*SignedTicket, error)
 
+	// SelectEarliestWinningTickets selects up to 'limit' of the earliest stored winning tickets for a 'sender'
+	// which are not yet redeemed
+	SelectEarliestWinningTickets(sender ethcommon.Address, minCreationRound int64, limit int) ([]*SignedTicket, error)
+
 	// RemoveWinningTicket removes a ticket
 	RemoveWinningTicket(ticket *SignedTicket) error
 
@@ -25,6 +29,12 @@ type TicketStore interface {
 	// WinningTicketCount returns the amount of non-redeemed winning tickets for a sender in the TicketStore
 	WinningTicketCount(sender ethcommon.Address, minCreationRound int64) (int, error)
 
+	// WinningTicketFaceValue returns the sum of the face values of the non-redeemed winning tickets for a sender in the TicketStore
+	WinningTicketFaceValue(sender ethcommon.Address, minCreationRound int64) (*big.Int, error)
+
+	// SelectWinningTicketSenders returns the senders that have non-redeemed winning tickets in the TicketStore
+	SelectWinningTicketSenders(minCreationRound int64) ([]ethcommon.Address, error)
+
 	// IsOrchActive returns true if the given orchestrator addr is active in the given round
 	IsOrchActive(addr ethcommon.Address, round *big.Int) (bool, error)
 }
@@ -0,0 +1,364 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	gonet "net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/api"
+	"github.com/livepeer/go-livepeer/eth"
+	"github.com/livepeer/go-livepeer/net"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var errAdminUnauthenticated = status.Error(codes.Unauthenticated, "unauthorized")
+
+// AdminServer serves the management API v2 over gRPC, along with the subscriptions to the session events.
+// Requests have to authenticate with the token in an "authorization: Bearer <token>" metadata.
+type AdminServer struct {
+	m     *managementService
+	token string
+
+	mu     sync.Mutex
+	server *grpc.Server
+}
+
+// NewAdminServer returns the gRPC admin API of the node, authenticating the requests with token
+func NewAdminServer(s *LivepeerServer, client eth.LivepeerEthClient, token string) (*AdminServer, error) {
+	if token == "" {
+		return nil, errors.New("must provide a token")
+	}
+	return &AdminServer{m: &managementService{server: s, client: client}, token: token}, nil
+}
+
+// Start serves the admin API on addr
+// This method will block
+func (a *AdminServer) Start(addr string) error {
+	listener, err := gonet.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	return a.serve(listener)
+}
+
+func (a *AdminServer) serve(listener gonet.Listener) error {
+	s := grpc.NewServer(grpc.UnaryInterceptor(a.authUnary), grpc.StreamInterceptor(a.authStream))
+	net.RegisterAdminServer(s, a)
+	a.mu.Lock()
+	a.server = s
+	a.mu.Unlock()
+
+	glog.Infof("Admin API listening on %v", listener.Addr())
+	return s.Serve(listener)
+}
+
+// Stop stops the admin API, ending the subscriptions
+func (a *AdminServer) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.server != nil {
+		a.server.Stop()
+	}
+}
+
+func (a *AdminServer) authenticate(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, auth := range md.Get("authorization") {
+		provided := strings.TrimPrefix(auth, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(a.token)) == 1 {
+			return nil
+		}
+	}
+	return errAdminUnauthenticated
+}
+
+func (a *AdminServer) authUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authenticate(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *AdminServer) authStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authenticate(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// adminError returns the gRPC status of an error of the management service
+func adminError(err error) error {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Unknown
+	switch apiErr.Status {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusInternalServerError:
+		code = codes.Internal
+	}
+	return status.Error(code, apiErr.Message)
+}
+
+func (a *AdminServer) Status(ctx context.Context, req *net.StatusReq) (*net.NodeStatus, error) {
+	info := a.m.status()
+	return &net.NodeStatus{
+		NodeType:              info.NodeType,
+		Version:               info.Version,
+		GoVersion:             info.GoVersion,
+		Goos:                  info.GOOS,
+		Goarch:                info.GOArch,
+		EthAddress:            info.EthAddress,
+		ActiveStreams:         int64(info.ActiveStreams),
+		OrchestratorPool:      info.OrchestratorPool,
+		RegisteredTranscoders: int64(info.RegisteredTranscoders),
+		LocalTranscoding:      info.LocalTranscoding,
+	}, nil
+}
+
+func (a *AdminServer) ListStreams(ctx context.Context, req *net.ListStreamsReq) (*net.ListStreamsRes, error) {
+	var streams []streamDebugInfo
+	if req.ManifestId != "" {
+		stream, err := a.m.stream(req.ManifestId)
+		if err != nil {
+			return nil, adminError(err)
+		}
+		streams = append(streams, *stream)
+	} else {
+		streams = a.m.streams()
+	}
+	res := &net.ListStreamsRes{}
+	for _, stream := range streams {
+		res.Streams = append(res.Streams, streamStatus(stream))
+	}
+	return res, nil
+}
+
+func streamStatus(info streamDebugInfo) *net.StreamStatus {
+	stream := &net.StreamStatus{
+		ManifestId:       info.ManifestID,
+		ExternalStreamId: info.ExternalStreamID,
+		Profiles:         info.Profiles,
+		StartedAt:        info.StartedAt.UnixNano() / int64(time.Millisecond),
+		SourceBytes:      info.SourceBytes,
+		TranscodedBytes:  info.TranscodedBytes,
+		Succeeded:        int64(info.Succeeded),
+		Failed:           int64(info.Failed),
+		Errors:           make(map[string]int64, len(info.Errors)),
+		Health: &net.StreamHealth{
+			Score:                   info.Health.Score,
+			State:                   string(info.Health.State),
+			DroppedRate:             info.Health.DroppedRate,
+			RealtimeRatio:           info.Health.RealtimeRatio,
+			VerificationFailureRate: info.Health.VerificationFailureRate,
+			SwapRate:                info.Health.SwapRate,
+		},
+	}
+	for _, sess := range info.Sessions {
+		stream.Sessions = append(stream.Sessions, sessionStatus(info.ManifestID, sess))
+	}
+	for err, count := range info.Errors {
+		stream.Errors[err] = int64(count)
+	}
+	for _, seg := range info.RecentSegments {
+		stream.RecentSegments = append(stream.RecentSegments, &net.SegmentStatus{
+			SeqNo:        seg.SeqNo,
+			Orchestrator: seg.Orchestrator,
+			Attempts:     int64(seg.Attempts),
+			LatencyMs:    seg.LatencyMs,
+			Error:        seg.Error,
+		})
+	}
+	return stream
+}
+
+func sessionStatus(manifestID string, info sessionDebugInfo) *net.SessionStatus {
+	sess := &net.SessionStatus{
+		ManifestId:    manifestID,
+		Orchestrator:  info.Orchestrator,
+		Address:       info.Address,
+		Trusted:       info.Trusted,
+		PricePerUnit:  info.PricePerUnit,
+		PixelsPerUnit: info.PixelsPerUnit,
+		SegsInFlight:  int64(info.SegsInFlight),
+		LatencyScore:  info.LatencyScore,
+	}
+	if params := info.TicketParams; params != nil {
+		sess.TicketParams = &net.SessionTicketParams{
+			Recipient:       params.Recipient,
+			FaceValue:       params.FaceValue,
+			WinProb:         params.WinProb,
+			ExpirationBlock: params.ExpirationBlock,
+		}
+	}
+	return sess
+}
+
+func (a *AdminServer) TerminateStream(ctx context.Context, req *net.TerminateStreamReq) (*net.TerminateStreamRes, error) {
+	blockFor := terminatedStreamBlock
+	if req.BlockForMs != 0 {
+		blockFor = time.Duration(req.BlockForMs) * time.Millisecond
+	}
+	if err := a.m.terminateStream(req.ManifestId, blockFor); err != nil {
+		return nil, adminError(err)
+	}
+	return &net.TerminateStreamRes{}, nil
+}
+
+func (a *AdminServer) ListSessions(ctx context.Context, req *net.ListSessionsReq) (*net.ListSessionsRes, error) {
+	res := &net.ListSessionsRes{}
+	for _, sess := range a.m.sessions() {
+		res.Sessions = append(res.Sessions, sessionStatus(sess.ManifestID, sess.sessionDebugInfo))
+	}
+	return res, nil
+}
+
+func (a *AdminServer) GetPricing(ctx context.Context, req *net.GetPricingReq) (*net.Pricing, error) {
+	return pricing(a.m.pricing()), nil
+}
+
+func pricing(info *pricingInfo) *net.Pricing {
+	res := &net.Pricing{}
+	price := func(p priceInfo) *net.Price {
+		return &net.Price{
+			Broadcaster:   p.Broadcaster,
+			Capability:    p.Capability,
+			PricePerUnit:  p.PricePerUnit,
+			PixelsPerUnit: p.PixelsPerUnit,
+		}
+	}
+	for _, p := range info.BasePrices {
+		res.BasePrices = append(res.BasePrices, price(p))
+	}
+	for _, p := range info.MaxPrices {
+		res.MaxPrices = append(res.MaxPrices, price(p))
+	}
+	return res
+}
+
+func (a *AdminServer) SetPrice(ctx context.Context, req *net.SetPriceReq) (*net.Pricing, error) {
+	err := a.m.setPrice(&priceRequest{
+		Broadcaster:   req.Broadcaster,
+		PricePerUnit:  req.PricePerUnit,
+		PixelsPerUnit: req.PixelsPerUnit,
+		Capability:    req.Capability,
+	})
+	if err != nil {
+		return nil, adminError(err)
+	}
+	return pricing(a.m.pricing()), nil
+}
+
+func (a *AdminServer) RemovePrice(ctx context.Context, req *net.RemovePriceReq) (*net.Pricing, error) {
+	if err := a.m.removePrice(req.Broadcaster, req.Capability); err != nil {
+		return nil, adminError(err)
+	}
+	return pricing(a.m.pricing()), nil
+}
+
+func (a *AdminServer) GetStaking(ctx context.Context, req *net.GetStakingReq) (*net.Staking, error) {
+	info, err := a.m.staking()
+	if err != nil {
+		return nil, adminError(err)
+	}
+	d := info.Delegator
+	res := &net.Staking{Delegator: &net.Delegator{
+		Address:             d.Address,
+		Status:              d.Status,
+		BondedAmount:        d.BondedAmount,
+		PendingStake:        d.PendingStake,
+		Fees:                d.Fees,
+		PendingFees:         d.PendingFees,
+		DelegateAddress:     d.DelegateAddress,
+		DelegatedAmount:     d.DelegatedAmount,
+		StartRound:          d.StartRound,
+		LastClaimRound:      d.LastClaimRound,
+		NextUnbondingLockId: d.NextUnbondingLockID,
+	}}
+	if t := info.Transcoder; t != nil {
+		res.Transcoder = &net.TranscoderStatus{
+			Address:         t.Address,
+			Status:          t.Status,
+			Active:          t.Active,
+			ServiceUri:      t.ServiceURI,
+			RewardCut:       t.RewardCut,
+			FeeShare:        t.FeeShare,
+			DelegatedStake:  t.DelegatedStake,
+			LastRewardRound: t.LastRewardRound,
+		}
+	}
+	return res, nil
+}
+
+func txRes(res *txResponse, err error) (*net.TxRes, error) {
+	if err != nil {
+		return nil, adminError(err)
+	}
+	return &net.TxRes{TxHash: res.TxHash}, nil
+}
+
+func (a *AdminServer) Bond(ctx context.Context, req *net.BondReq) (*net.TxRes, error) {
+	return txRes(a.m.bond(&bondRequest{Amount: req.Amount, To: req.To}))
+}
+
+func (a *AdminServer) Unbond(ctx context.Context, req *net.UnbondReq) (*net.TxRes, error) {
+	return txRes(a.m.unbond(&unbondRequest{Amount: req.Amount}))
+}
+
+func (a *AdminServer) Rebond(ctx context.Context, req *net.RebondReq) (*net.TxRes, error) {
+	return txRes(a.m.rebond(&rebondRequest{UnbondingLockID: req.UnbondingLockId, To: req.To}))
+}
+
+func (a *AdminServer) WithdrawStake(ctx context.Context, req *net.WithdrawStakeReq) (*net.TxRes, error) {
+	return txRes(a.m.withdrawStake(&withdrawStakeRequest{UnbondingLockID: req.UnbondingLockId}))
+}
+
+func (a *AdminServer) GetPayments(ctx context.Context, req *net.GetPaymentsReq) (*net.Payments, error) {
+	info, err := a.m.payments()
+	if err != nil {
+		return nil, adminError(err)
+	}
+	return &net.Payments{
+		Deposit:               info.Deposit,
+		Reserve:               info.Reserve,
+		ClaimedInCurrentRound: info.ClaimedInCurrentRound,
+		WithdrawRound:         info.WithdrawRound,
+	}, nil
+}
+
+func (a *AdminServer) Deposit(ctx context.Context, req *net.DepositReq) (*net.TxRes, error) {
+	return txRes(a.m.deposit(&depositRequest{DepositAmount: req.DepositAmount, ReserveAmount: req.ReserveAmount}))
+}
+
+// WatchSessions sends the session events, of the stream of the request if set, until the client is gone
+func (a *AdminServer) WatchSessions(req *net.WatchSessionsReq, stream net.Admin_WatchSessionsServer) error {
+	events, unsubscribe := sessionEvents.subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case evt := <-events:
+			if req.ManifestId != "" && evt.ManifestId != req.ManifestId {
+				continue
+			}
+			if err := stream.Send(evt); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	gonet "net"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func startAdminServer(t *testing.T, s *LivepeerServer) (net.AdminClient, func()) {
+	admin, err := NewAdminServer(s, nil, "secret")
+	require.Nil(t, err)
+	listener, err := gonet.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	go admin.serve(listener)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.Nil(t, err)
+	return net.NewAdminClient(conn), func() {
+		conn.Close()
+		admin.Stop()
+	}
+}
+
+func adminContext(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestNewAdminServer_RequiresToken(t *testing.T) {
+	_, err := NewAdminServer(stubServer(), nil, "")
+	assert.EqualError(t, err, "must provide a token")
+}
+
+func TestAdminServer_Auth(t *testing.T) {
+	assert := assert.New(t)
+	client, stop := startAdminServer(t, stubServer())
+	defer stop()
+
+	_, err := client.Status(context.Background(), &net.StatusReq{})
+	assert.Equal(codes.Unauthenticated, status.Code(err))
+	_, err = client.Status(adminContext("wrong"), &net.StatusReq{})
+	assert.Equal(codes.Unauthenticated, status.Code(err))
+
+	stream, err := client.WatchSessions(adminContext("wrong"), &net.WatchSessionsReq{})
+	require.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Equal(codes.Unauthenticated, status.Code(err))
+
+	res, err := client.Status(adminContext("secret"), &net.StatusReq{})
+	assert.Nil(err)
+	assert.Equal(core.LivepeerVersion, res.Version)
+}
+
+func TestAdminServer_Requests(t *testing.T) {
+	assert := assert.New(t)
+	s := stubServer()
+	client, stop := startAdminServer(t, s)
+	defer stop()
+	ctx := adminContext("secret")
+
+	_, err := client.ListStreams(ctx, &net.ListStreamsReq{ManifestId: "foo"})
+	assert.Equal(codes.NotFound, status.Code(err))
+	streams, err := client.ListStreams(ctx, &net.ListStreamsReq{})
+	assert.Nil(err)
+	assert.Empty(streams.Streams)
+	_, err = client.TerminateStream(ctx, &net.TerminateStreamReq{ManifestId: "foo"})
+	assert.Equal(codes.NotFound, status.Code(err))
+	_, err = client.TerminateStream(ctx, &net.TerminateStreamReq{ManifestId: "foo", BlockForMs: -1})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	// errors of the management service are mapped to gRPC codes
+	req := &net.SetPriceReq{Broadcaster: "default", PricePerUnit: "10", PixelsPerUnit: "4"}
+	_, err = client.SetPrice(ctx, req)
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+	_, err = client.GetStaking(ctx, &net.GetStakingReq{})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+	assert.Equal("requires an on-chain node", status.Convert(err).Message())
+
+	s.LivepeerNode.NodeType = core.OrchestratorNode
+	pricing, err := client.SetPrice(ctx, req)
+	require.Nil(t, err)
+	require.Len(t, pricing.BasePrices, 1)
+	assert.Equal("default", pricing.BasePrices[0].Broadcaster)
+	assert.Equal("5", pricing.BasePrices[0].PricePerUnit)
+	assert.Equal("2", pricing.BasePrices[0].PixelsPerUnit)
+
+	pricing, err = client.SetPrice(ctx, &net.SetPriceReq{Broadcaster: "default", Capability: "HEVC encode", PricePerUnit: "3", PixelsPerUnit: "1"})
+	require.Nil(t, err)
+	require.Len(t, pricing.BasePrices, 2)
+	assert.Equal("HEVC encode", pricing.BasePrices[1].Capability)
+	pricing, err = client.RemovePrice(ctx, &net.RemovePriceReq{Broadcaster: "default", Capability: "HEVC encode"})
+	require.Nil(t, err)
+	assert.Len(pricing.BasePrices, 1)
+	_, err = client.RemovePrice(ctx, &net.RemovePriceReq{Broadcaster: "default", Capability: "HEVC encode"})
+	assert.Equal(codes.NotFound, status.Code(err))
+}
+
+func TestAdminServer_WatchSessions(t *testing.T) {
+	assert := assert.New(t)
+	client, stop := startAdminServer(t, stubServer())
+	defer stop()
+
+	ctx, cancel := context.WithCancel(adminContext("secret"))
+	defer cancel()
+	stream, err := client.WatchSessions(ctx, &net.WatchSessionsReq{ManifestId: "foo"})
+	require.Nil(t, err)
+	assert.Eventually(sessionEvents.active, time.Second, 10*time.Millisecond)
+
+	// the events of the other streams are filtered out
+	publishSessionEvent(net.SessionEvent_SESSION_ADDED, "bar", "https://orch1:8935")
+	publishSessionEvent(net.SessionEvent_SESSION_ADDED, "foo", "https://orch2:8935")
+	evt, err := stream.Recv()
+	require.Nil(t, err)
+	assert.Equal(net.SessionEvent_SESSION_ADDED, evt.Type)
+	assert.Equal("foo", evt.ManifestId)
+	assert.Equal("https://orch2:8935", evt.Orchestrator)
+	assert.InDelta(time.Now().UnixNano()/int64(time.Millisecond), evt.Timestamp, 1000)
+
+	// the subscription ends with the request
+	cancel()
+	assert.Eventually(func() bool { return !sessionEvents.active() }, time.Second, 10*time.Millisecond)
+}
+
+func TestSessionEventHub(t *testing.T) {
+	assert := assert.New(t)
+	hub := newSessionEventHub()
+	assert.False(hub.active())
+
+	events, unsubscribe := hub.subscribe()
+	assert.True(hub.active())
+	// the events of a slow subscriber are dropped instead of blocking the publisher
+	for i := 0; i < sessionEventsBuffer+1; i++ {
+		hub.publish(&net.SessionEvent{SeqNo: uint64(i)})
+	}
+	assert.Len(events, sessionEventsBuffer)
+	assert.Equal(uint64(0), (<-events).SeqNo)
+
+	unsubscribe()
+	assert.False(hub.active())
+}
+
+func TestSessionPool_SessionEvents(t *testing.T) {
+	assert := assert.New(t)
+	events, unsubscribe := sessionEvents.subscribe()
+	defer unsubscribe()
+
+	sess := StubBroadcastSession("https://orch:8935")
+	// the events of the sessions of other tests are ignored
+	streamEvents := func() []*net.SessionEvent {
+		var res []*net.SessionEvent
+		for len(events) > 0 {
+			if evt := <-events; evt.ManifestId == "session-events" {
+				res = append(res, evt)
+			}
+		}
+		return res
+	}
+	sp := &SessionPool{mid: "session-events", sessMap: map[string]*BroadcastSession{sess.Transcoder(): sess}}
+	sp.removeSession(sess)
+	evts := streamEvents()
+	require.Len(t, evts, 1)
+	assert.Equal(net.SessionEvent_SESSION_REMOVED, evts[0].Type)
+	assert.Equal("https://orch:8935", evts[0].Orchestrator)
+
+	// removing a session twice publishes a single event
+	sp.removeSession(sess)
+	assert.Empty(streamEvents())
+}
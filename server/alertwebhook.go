@@ -0,0 +1,50 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/eth"
+)
+
+// AlertWebhook posts the alerts of the on-chain services as JSON to a webhook URL
+type AlertWebhook struct {
+	url *url.URL
+}
+
+// NewAlertWebhook creates an AlertWebhook that posts the alerts to u
+func NewAlertWebhook(u *url.URL) *AlertWebhook {
+	return &AlertWebhook{url: u}
+}
+
+// NotifyReward posts a reward alert without blocking the reward service
+func (w *AlertWebhook) NotifyReward(alert *eth.RewardAlert) {
+	go w.post("reward", string(alert.Type), alert)
+}
+
+// NotifyBalance posts a balance alert without blocking the balance watcher
+func (w *AlertWebhook) NotifyBalance(alert *eth.BalanceAlert) {
+	go w.post("balance", string(alert.Level), alert)
+}
+
+func (w *AlertWebhook) post(kind, typ string, alert interface{}) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		glog.Errorf("Unable to marshal %v alert type=%v err=%q", kind, typ, err)
+		return
+	}
+	// Alerts are rare, so they share the client of the payment webhook
+	resp, err := paymentWebhookClient.Post(w.url.String(), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		glog.Errorf("Unable to POST %v alert on webhook url=%v type=%v err=%q", kind, w.url.Redacted(), typ, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rbody, _ := ioutil.ReadAll(resp.Body)
+		glog.Errorf("Alert webhook returned error kind=%v status=%v type=%v err=%q", kind, resp.StatusCode, typ, string(rbody))
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/eth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertWebhook_NotifyReward(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	received := make(chan *eth.RewardAlert, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert eth.RewardAlert
+		assert.Nil(json.NewDecoder(r.Body).Decode(&alert))
+		received <- &alert
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.Nil(err)
+
+	w := NewAlertWebhook(u)
+	w.NotifyReward(&eth.RewardAlert{
+		Type:    eth.RewardMissed,
+		Round:   big.NewInt(100),
+		Attempt: 3,
+		Error:   "transaction failed",
+		Time:    time.Now(),
+	})
+
+	select {
+	case alert := <-received:
+		assert.Equal(eth.RewardMissed, alert.Type)
+		assert.Equal(big.NewInt(100), alert.Round)
+		assert.Equal(3, alert.Attempt)
+		assert.Equal("transaction failed", alert.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reward alert")
+	}
+}
+
+func TestAlertWebhook_NotifyBalance(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	received := make(chan *eth.BalanceAlert, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert eth.BalanceAlert
+		assert.Nil(json.NewDecoder(r.Body).Decode(&alert))
+		received <- &alert
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.Nil(err)
+
+	w := NewAlertWebhook(u)
+	w.NotifyBalance(&eth.BalanceAlert{
+		Level:    eth.BalanceCritical,
+		Address:  "0x0000000000000000000000000000000000000001",
+		Balance:  big.NewInt(5),
+		SafeMode: true,
+		Time:     time.Now(),
+	})
+
+	select {
+	case alert := <-received:
+		assert.Equal(eth.BalanceCritical, alert.Level)
+		assert.Equal("0x0000000000000000000000000000000000000001", alert.Address)
+		assert.Equal(big.NewInt(5), alert.Balance)
+		assert.True(alert.SafeMode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for balance alert")
+	}
+}
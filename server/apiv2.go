@@ -0,0 +1,613 @@
+package server
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/api"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/eth"
+)
+
+// APIv2BasePath is the base path of the management API v2 on the CLI server
+const APIv2BasePath = "/api/v2"
+
+// terminatedStreamBlock is how long a terminated stream is rejected by default, before its publisher can restart it
+const terminatedStreamBlock = time.Minute
+
+type nodeStatusInfo struct {
+	NodeType              string   `json:"nodeType"`
+	Version               string   `json:"version"`
+	GoVersion             string   `json:"goVersion"`
+	GOOS                  string   `json:"goos"`
+	GOArch                string   `json:"goarch"`
+	EthAddress            string   `json:"ethAddress,omitempty" doc:"Account of the node, unset off-chain"`
+	ActiveStreams         int      `json:"activeStreams"`
+	OrchestratorPool      []string `json:"orchestratorPool" doc:"URIs of the orchestrators the broadcaster transcodes with"`
+	RegisteredTranscoders int      `json:"registeredTranscoders" doc:"Number of the remote transcoders of the orchestrator"`
+	LocalTranscoding      bool     `json:"localTranscoding"`
+}
+
+// streamSessionInfo is a session transcoding the segments of a stream
+type streamSessionInfo struct {
+	ManifestID string `json:"manifestID"`
+	sessionDebugInfo
+}
+
+// priceInfo is a price of pricePerUnit wei for pixelsPerUnit pixels
+type priceInfo struct {
+	Broadcaster   string `json:"broadcaster,omitempty" doc:"ETH address of the broadcaster the price is for, or default"`
+	Capability    string `json:"capability,omitempty" doc:"Capability the price is for, unset for all"`
+	PricePerUnit  string `json:"pricePerUnit" doc:"Price in wei"`
+	PixelsPerUnit string `json:"pixelsPerUnit"`
+}
+
+type pricingInfo struct {
+	BasePrices []priceInfo `json:"basePrices" doc:"Prices of the orchestrator"`
+	MaxPrices  []priceInfo `json:"maxPrices" doc:"Max prices the broadcaster pays"`
+}
+
+type priceRequest struct {
+	Broadcaster   string `json:"broadcaster" doc:"ETH address of the broadcaster, or default for all the others"`
+	Capability    string `json:"capability,omitempty" doc:"Capability the price is for the jobs requiring, unset for all the jobs"`
+	PricePerUnit  string `json:"pricePerUnit" doc:"Price in wei"`
+	PixelsPerUnit string `json:"pixelsPerUnit"`
+}
+
+type delegatorInfo struct {
+	Address             string `json:"address"`
+	Status              string `json:"status"`
+	BondedAmount        string `json:"bondedAmount"`
+	PendingStake        string `json:"pendingStake"`
+	Fees                string `json:"fees"`
+	PendingFees         string `json:"pendingFees"`
+	DelegateAddress     string `json:"delegateAddress"`
+	DelegatedAmount     string `json:"delegatedAmount"`
+	StartRound          string `json:"startRound"`
+	LastClaimRound      string `json:"lastClaimRound"`
+	NextUnbondingLockID string `json:"nextUnbondingLockId"`
+}
+
+type transcoderInfo struct {
+	Address         string `json:"address"`
+	Status          string `json:"status"`
+	Active          bool   `json:"active"`
+	ServiceURI      string `json:"serviceURI"`
+	RewardCut       string `json:"rewardCut"`
+	FeeShare        string `json:"feeShare"`
+	DelegatedStake  string `json:"delegatedStake"`
+	LastRewardRound string `json:"lastRewardRound"`
+}
+
+type stakingInfo struct {
+	Delegator  delegatorInfo   `json:"delegator"`
+	Transcoder *transcoderInfo `json:"transcoder,omitempty" doc:"Registration of the orchestrator, unset for the other nodes"`
+}
+
+type paymentsInfo struct {
+	Deposit               string `json:"deposit" doc:"Deposit of the broadcaster in wei"`
+	Reserve               string `json:"reserve" doc:"Remaining reserve of the broadcaster in wei"`
+	ClaimedInCurrentRound string `json:"claimedInCurrentRound"`
+	WithdrawRound         string `json:"withdrawRound" doc:"Round the deposit and reserve can be withdrawn from, 0 if not unlocked"`
+}
+
+type bondRequest struct {
+	Amount string `json:"amount" doc:"Amount in LPT wei"`
+	To     string `json:"to" doc:"ETH address of the orchestrator"`
+}
+
+type unbondRequest struct {
+	Amount string `json:"amount" doc:"Amount in LPT wei"`
+}
+
+type rebondRequest struct {
+	UnbondingLockID string `json:"unbondingLockId"`
+	To              string `json:"to,omitempty" doc:"ETH address of the orchestrator to rebond to, for an unbonded delegator"`
+}
+
+type withdrawStakeRequest struct {
+	UnbondingLockID string `json:"unbondingLockId"`
+}
+
+type depositRequest struct {
+	DepositAmount string `json:"depositAmount" doc:"Amount in wei"`
+	ReserveAmount string `json:"reserveAmount" doc:"Amount in wei"`
+}
+
+// txResponse is returned once the transaction of a request is confirmed
+type txResponse struct {
+	TxHash string `json:"txHash"`
+}
+
+// managementService implements the operations of the management API v2, independently of how they're served
+type managementService struct {
+	server *LivepeerServer
+	client eth.LivepeerEthClient
+}
+
+func (m *managementService) status() *nodeStatusInfo {
+	node := m.server.LivepeerNode
+	info := &nodeStatusInfo{
+		NodeType:         node.NodeType.String(),
+		Version:          core.LivepeerVersion,
+		GoVersion:        runtime.Version(),
+		GOOS:             runtime.GOOS,
+		GOArch:           runtime.GOARCH,
+		OrchestratorPool: []string{},
+		LocalTranscoding: node.TranscoderManager == nil,
+	}
+	if m.client != nil {
+		info.EthAddress = m.client.Account().Address.Hex()
+	}
+	m.server.connectionLock.RLock()
+	info.ActiveStreams = len(m.server.rtmpConnections)
+	m.server.connectionLock.RUnlock()
+	if node.OrchestratorPool != nil {
+		for _, oInfo := range node.OrchestratorPool.GetInfos() {
+			info.OrchestratorPool = append(info.OrchestratorPool, oInfo.URL.String())
+		}
+	}
+	if node.TranscoderManager != nil {
+		info.RegisteredTranscoders = node.TranscoderManager.RegisteredTranscodersCount()
+	}
+	return info
+}
+
+func (m *managementService) streams() []streamDebugInfo {
+	return m.server.streamsDebug("")
+}
+
+func (m *managementService) stream(manifestID string) (*streamDebugInfo, error) {
+	streams := m.server.streamsDebug(core.ManifestID(manifestID))
+	if len(streams) == 0 {
+		return nil, api.Errorf(http.StatusNotFound, "unknown manifestID %q", manifestID)
+	}
+	return &streams[0], nil
+}
+
+// terminateStream ends the stream, disconnecting its publisher, and rejects it for blockFor
+func (m *managementService) terminateStream(manifestID string, blockFor time.Duration) error {
+	if blockFor < 0 {
+		return api.Errorf(http.StatusBadRequest, "invalid blockFor %s", blockFor)
+	}
+	err := m.server.terminateStream(context.Background(), core.ManifestID(manifestID), blockFor)
+	if err == errUnknownStream {
+		return api.Errorf(http.StatusNotFound, "unknown manifestID %q", manifestID)
+	}
+	return err
+}
+
+func (m *managementService) sessions() []streamSessionInfo {
+	sessions := []streamSessionInfo{}
+	for _, stream := range m.server.streamsDebug("") {
+		for _, sess := range stream.Sessions {
+			sessions = append(sessions, streamSessionInfo{ManifestID: stream.ManifestID, sessionDebugInfo: sess})
+		}
+	}
+	return sessions
+}
+
+func (m *managementService) pricing() *pricingInfo {
+	info := &pricingInfo{BasePrices: []priceInfo{}, MaxPrices: []priceInfo{}}
+	node := m.server.LivepeerNode
+	for broadcaster, price := range node.GetBasePrices() {
+		if price != nil {
+			info.BasePrices = append(info.BasePrices, newPriceInfo(price, broadcaster, ""))
+		}
+	}
+	for broadcaster, capPrices := range node.GetCapabilityBasePrices() {
+		for capability, price := range capPrices {
+			info.BasePrices = append(info.BasePrices, newPriceInfo(price, broadcaster, capabilityName(capability)))
+		}
+	}
+	sort.Slice(info.BasePrices, func(i, j int) bool {
+		a, b := info.BasePrices[i], info.BasePrices[j]
+		if a.Broadcaster != b.Broadcaster {
+			return a.Broadcaster < b.Broadcaster
+		}
+		return a.Capability < b.Capability
+	})
+	if price := BroadcastCfg.MaxPrice(); price != nil {
+		info.MaxPrices = append(info.MaxPrices, newPriceInfo(price, "", ""))
+	}
+	var capPrices []priceInfo
+	for capability, price := range BroadcastCfg.CapabilityMaxPrices() {
+		capPrices = append(capPrices, newPriceInfo(price, "", capabilityName(capability)))
+	}
+	sort.Slice(capPrices, func(i, j int) bool { return capPrices[i].Capability < capPrices[j].Capability })
+	info.MaxPrices = append(info.MaxPrices, capPrices...)
+	return info
+}
+
+func capabilityName(capability core.Capability) string {
+	name, err := core.CapabilityToName(capability)
+	if err != nil {
+		return strconv.Itoa(int(capability))
+	}
+	return name
+}
+
+func newPriceInfo(price *big.Rat, broadcaster, capability string) priceInfo {
+	return priceInfo{
+		Broadcaster:   broadcaster,
+		Capability:    capability,
+		PricePerUnit:  price.Num().String(),
+		PixelsPerUnit: price.Denom().String(),
+	}
+}
+
+func (m *managementService) setPrice(req *priceRequest) error {
+	if m.server.LivepeerNode.NodeType != core.OrchestratorNode {
+		return api.Errorf(http.StatusBadRequest, "node must be orchestrator node to set prices")
+	}
+	if err := m.server.setOrchestratorPriceInfo(req.Broadcaster, req.PricePerUnit, req.PixelsPerUnit, req.Capability); err != nil {
+		return api.Errorf(http.StatusBadRequest, "%v", err)
+	}
+	return nil
+}
+
+// removePrice removes the price of the orchestrator for a broadcaster, or the default price, for the jobs requiring
+// the capability if set, or else for all the jobs, for the next price to apply
+func (m *managementService) removePrice(broadcaster, capName string) error {
+	node := m.server.LivepeerNode
+	if node.NodeType != core.OrchestratorNode {
+		return api.Errorf(http.StatusBadRequest, "node must be orchestrator node to remove prices")
+	}
+	broadcaster = strings.ToLower(broadcaster)
+	if capName == "" {
+		if broadcaster == "default" {
+			return api.Errorf(http.StatusBadRequest, "the default price can't be removed")
+		}
+		if node.GetBasePrice(broadcaster) == nil {
+			return api.Errorf(http.StatusNotFound, "no price for broadcaster %q", broadcaster)
+		}
+		node.SetBasePrice(broadcaster, nil)
+		glog.Infof("Removed the price per pixel for broadcaster %s", broadcaster)
+		return nil
+	}
+	capability, err := core.CapabilityFromName(capName)
+	if err != nil {
+		return api.Errorf(http.StatusBadRequest, "unknown capability: %v", capName)
+	}
+	if node.GetCapabilityBasePrices()[broadcaster][capability] == nil {
+		return api.Errorf(http.StatusNotFound, "no price for capability %q for broadcaster %q", capName, broadcaster)
+	}
+	node.SetCapabilityBasePrice(broadcaster, capability, nil)
+	glog.Infof("Removed the price per pixel for capability %v for broadcaster %s", capName, broadcaster)
+	return nil
+}
+
+func (m *managementService) staking() (*stakingInfo, error) {
+	if err := m.requireClient(); err != nil {
+		return nil, err
+	}
+	addr := m.client.Account().Address
+	d, err := m.client.GetDelegator(addr)
+	if err != nil {
+		return nil, api.Errorf(http.StatusInternalServerError, "could not get delegator: %v", err)
+	}
+	info := &stakingInfo{Delegator: delegatorInfo{
+		Address:             d.Address.Hex(),
+		Status:              d.Status,
+		BondedAmount:        bigString(d.BondedAmount),
+		PendingStake:        bigString(d.PendingStake),
+		Fees:                bigString(d.Fees),
+		PendingFees:         bigString(d.PendingFees),
+		DelegateAddress:     d.DelegateAddress.Hex(),
+		DelegatedAmount:     bigString(d.DelegatedAmount),
+		StartRound:          bigString(d.StartRound),
+		LastClaimRound:      bigString(d.LastClaimRound),
+		NextUnbondingLockID: bigString(d.NextUnbondingLockId),
+	}}
+	if m.server.LivepeerNode.NodeType == core.OrchestratorNode {
+		t, err := m.client.GetTranscoder(addr)
+		if err != nil {
+			return nil, api.Errorf(http.StatusInternalServerError, "could not get transcoder: %v", err)
+		}
+		info.Transcoder = &transcoderInfo{
+			Address:         t.Address.Hex(),
+			Status:          t.Status,
+			Active:          t.Active,
+			ServiceURI:      t.ServiceURI,
+			RewardCut:       bigString(t.RewardCut),
+			FeeShare:        bigString(t.FeeShare),
+			DelegatedStake:  bigString(t.DelegatedStake),
+			LastRewardRound: bigString(t.LastRewardRound),
+		}
+	}
+	return info, nil
+}
+
+func (m *managementService) bond(req *bondRequest) (*txResponse, error) {
+	return m.sendTx(func() (*ethtypes.Transaction, error) {
+		amount, err := parseDelegationAmount(req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseDelegationAddr(req.To, "to")
+		if err != nil {
+			return nil, err
+		}
+		return m.client.Bond(amount, to)
+	})
+}
+
+func (m *managementService) unbond(req *unbondRequest) (*txResponse, error) {
+	return m.sendTx(func() (*ethtypes.Transaction, error) {
+		amount, err := parseDelegationAmount(req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		return m.client.Unbond(amount)
+	})
+}
+
+func (m *managementService) rebond(req *rebondRequest) (*txResponse, error) {
+	return m.sendTx(func() (*ethtypes.Transaction, error) {
+		lockID, err := parseUnbondingLockID(req.UnbondingLockID)
+		if err != nil {
+			return nil, err
+		}
+		if req.To != "" {
+			to, err := parseDelegationAddr(req.To, "to")
+			if err != nil {
+				return nil, err
+			}
+			return m.client.RebondFromUnbonded(to, lockID)
+		}
+		return m.client.Rebond(lockID)
+	})
+}
+
+func (m *managementService) withdrawStake(req *withdrawStakeRequest) (*txResponse, error) {
+	return m.sendTx(func() (*ethtypes.Transaction, error) {
+		lockID, err := parseUnbondingLockID(req.UnbondingLockID)
+		if err != nil {
+			return nil, err
+		}
+		return m.client.WithdrawStake(lockID)
+	})
+}
+
+func (m *managementService) payments() (*paymentsInfo, error) {
+	if err := m.requireClient(); err != nil {
+		return nil, err
+	}
+	info, err := m.client.GetSenderInfo(m.client.Account().Address)
+	if err != nil && err.Error() != "ErrNoResult" {
+		return nil, api.Errorf(http.StatusInternalServerError, "could not query sender info: %v", err)
+	}
+	res := &paymentsInfo{Deposit: "0", Reserve: "0", ClaimedInCurrentRound: "0", WithdrawRound: "0"}
+	if err == nil && info != nil {
+		res.Deposit = bigString(info.Deposit)
+		res.WithdrawRound = bigString(info.WithdrawRound)
+		if reserve := info.Reserve; reserve != nil {
+			res.Reserve = bigString(reserve.FundsRemaining)
+			res.ClaimedInCurrentRound = bigString(reserve.ClaimedInCurrentRound)
+		}
+	}
+	return res, nil
+}
+
+func (m *managementService) deposit(req *depositRequest) (*txResponse, error) {
+	return m.sendTx(func() (*ethtypes.Transaction, error) {
+		deposit, ok := new(big.Int).SetString(req.DepositAmount, 10)
+		if !ok || deposit.Sign() < 0 {
+			return nil, errBadDelegationRequest("missing or invalid depositAmount")
+		}
+		reserve, ok := new(big.Int).SetString(req.ReserveAmount, 10)
+		if !ok || reserve.Sign() < 0 {
+			return nil, errBadDelegationRequest("missing or invalid reserveAmount")
+		}
+		if deposit.Sign() == 0 && reserve.Sign() == 0 {
+			return nil, errBadDelegationRequest("depositAmount or reserveAmount must be greater than 0")
+		}
+		return m.client.FundDepositAndReserve(deposit, reserve)
+	})
+}
+
+func (m *managementService) requireClient() error {
+	if m.client == nil {
+		return api.Errorf(http.StatusBadRequest, "requires an on-chain node")
+	}
+	return nil
+}
+
+// sendTx sends the transaction of a request and waits for it to confirm
+func (m *managementService) sendTx(send func() (*ethtypes.Transaction, error)) (*txResponse, error) {
+	if err := m.requireClient(); err != nil {
+		return nil, err
+	}
+	tx, err := send()
+	if _, ok := err.(errBadDelegationRequest); ok {
+		return nil, api.Errorf(http.StatusBadRequest, "%v", err)
+	}
+	if err != nil {
+		return nil, api.Errorf(http.StatusInternalServerError, "could not send transaction: %v", err)
+	}
+	if err := m.client.CheckTx(tx); err != nil {
+		return nil, api.Errorf(http.StatusInternalServerError, "transaction failed: %v", err)
+	}
+	return &txResponse{TxHash: tx.Hash().Hex()}, nil
+}
+
+func bigString(i *big.Int) string {
+	if i == nil {
+		return "0"
+	}
+	return i.String()
+}
+
+// authorizeAPIv2 authorizes the requests to the routes of the API v2 that change the state of the node or send
+// transactions, which authenticate with the delegation API token
+func authorizeAPIv2(r *http.Request) error {
+	switch bearerTokenStatus(DelegationAPIToken, r) {
+	case http.StatusForbidden:
+		return api.Errorf(http.StatusForbidden, "route is disabled, start the node with -delegationApiToken to enable it")
+	case http.StatusUnauthorized:
+		return api.Errorf(http.StatusUnauthorized, "unauthorized")
+	}
+	return nil
+}
+
+// newAPIv2 returns the management API v2, served under APIv2BasePath with its OpenAPI document
+func (s *LivepeerServer) newAPIv2(client eth.LivepeerEthClient) *api.API {
+	m := &managementService{server: s, client: client}
+	a := api.New("Livepeer node management API", "2.0.0", APIv2BasePath)
+	a.SetAuthorizer(authorizeAPIv2)
+	a.Handle(
+		api.Route{
+			Method: http.MethodGet, Path: "/status", Summary: "Get the status of the node", Tag: "status",
+			Response: nodeStatusInfo{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				return m.status(), nil
+			},
+		},
+		api.Route{
+			Method: http.MethodGet, Path: "/streams", Summary: "List the active streams", Tag: "streams",
+			Response: []streamDebugInfo{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				return m.streams(), nil
+			},
+		},
+		api.Route{
+			Method: http.MethodGet, Path: "/streams/{manifestId}", Summary: "Get an active stream", Tag: "streams",
+			Response: streamDebugInfo{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				return m.stream(params["manifestId"])
+			},
+		},
+		api.Route{
+			Method: http.MethodDelete, Path: "/streams/{manifestId}", Auth: true, Tag: "streams",
+			Summary: "Terminate an active stream, rejecting its publisher for the blockFor query duration (default 1m)",
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				blockFor := terminatedStreamBlock
+				if v := r.URL.Query().Get("blockFor"); v != "" {
+					var err error
+					if blockFor, err = time.ParseDuration(v); err != nil {
+						return nil, api.Errorf(http.StatusBadRequest, "invalid blockFor %q", v)
+					}
+				}
+				return nil, m.terminateStream(params["manifestId"], blockFor)
+			},
+		},
+		api.Route{
+			Method: http.MethodGet, Path: "/sessions", Summary: "List the sessions transcoding the active streams", Tag: "streams",
+			Response: []streamSessionInfo{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				return m.sessions(), nil
+			},
+		},
+		api.Route{
+			Method: http.MethodGet, Path: "/pricing", Summary: "Get the prices of the node", Tag: "pricing",
+			Response: pricingInfo{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				return m.pricing(), nil
+			},
+		},
+		api.Route{
+			Method: http.MethodPut, Path: "/pricing", Auth: true, Summary: "Set the price of the orchestrator for a broadcaster, for the jobs requiring a capability if set", Tag: "pricing",
+			Request: priceRequest{}, Response: pricingInfo{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				var req priceRequest
+				if err := api.DecodeBody(r, &req); err != nil {
+					return nil, err
+				}
+				if err := m.setPrice(&req); err != nil {
+					return nil, err
+				}
+				return m.pricing(), nil
+			},
+		},
+		api.Route{
+			Method: http.MethodDelete, Path: "/pricing", Auth: true, Tag: "pricing",
+			Summary:  "Remove the price of the orchestrator for the broadcaster query param, for the capability query param if set",
+			Response: pricingInfo{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				query := r.URL.Query()
+				if err := m.removePrice(query.Get("broadcaster"), query.Get("capability")); err != nil {
+					return nil, err
+				}
+				return m.pricing(), nil
+			},
+		},
+		api.Route{
+			Method: http.MethodGet, Path: "/staking", Summary: "Get the stake of the node", Tag: "staking",
+			Response: stakingInfo{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				return m.staking()
+			},
+		},
+		api.Route{
+			Method: http.MethodPost, Path: "/staking/bond", Auth: true, Summary: "Bond LPT to an orchestrator", Tag: "staking",
+			Request: bondRequest{}, Response: txResponse{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				var req bondRequest
+				if err := api.DecodeBody(r, &req); err != nil {
+					return nil, err
+				}
+				return m.bond(&req)
+			},
+		},
+		api.Route{
+			Method: http.MethodPost, Path: "/staking/unbond", Auth: true, Summary: "Unbond LPT", Tag: "staking",
+			Request: unbondRequest{}, Response: txResponse{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				var req unbondRequest
+				if err := api.DecodeBody(r, &req); err != nil {
+					return nil, err
+				}
+				return m.unbond(&req)
+			},
+		},
+		api.Route{
+			Method: http.MethodPost, Path: "/staking/rebond", Auth: true, Summary: "Rebond the LPT of an unbonding lock", Tag: "staking",
+			Request: rebondRequest{}, Response: txResponse{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				var req rebondRequest
+				if err := api.DecodeBody(r, &req); err != nil {
+					return nil, err
+				}
+				return m.rebond(&req)
+			},
+		},
+		api.Route{
+			Method: http.MethodPost, Path: "/staking/withdraw", Auth: true, Summary: "Withdraw the LPT of an unbonding lock", Tag: "staking",
+			Request: withdrawStakeRequest{}, Response: txResponse{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				var req withdrawStakeRequest
+				if err := api.DecodeBody(r, &req); err != nil {
+					return nil, err
+				}
+				return m.withdrawStake(&req)
+			},
+		},
+		api.Route{
+			Method: http.MethodGet, Path: "/payments", Summary: "Get the deposit and reserve of the broadcaster", Tag: "payments",
+			Response: paymentsInfo{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				return m.payments()
+			},
+		},
+		api.Route{
+			Method: http.MethodPost, Path: "/payments/deposit", Auth: true, Summary: "Fund the deposit and reserve of the broadcaster", Tag: "payments",
+			Request: depositRequest{}, Response: txResponse{},
+			Handler: func(r *http.Request, params api.Params) (interface{}, error) {
+				var req depositRequest
+				if err := api.DecodeBody(r, &req); err != nil {
+					return nil, err
+				}
+				return m.deposit(&req)
+			},
+		},
+	)
+	return a
+}
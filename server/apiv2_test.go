@@ -0,0 +1,342 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/eth"
+	lpTypes "github.com/livepeer/go-livepeer/eth/types"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveAPIv2(h http.Handler, method, path, body string) (int, string) {
+	req := httptest.NewRequest(method, "http://example.com"+APIv2BasePath+path, strings.NewReader(body))
+	if DelegationAPIToken != "" {
+		req.Header.Set("Authorization", "Bearer "+DelegationAPIToken)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, trim(data)
+}
+
+func TestAPIv2_Status(t *testing.T) {
+	assert := assert.New(t)
+	s := stubServer()
+	s.LivepeerNode.NodeType = core.BroadcasterNode
+
+	status, body := serveAPIv2(s.newAPIv2(nil), http.MethodGet, "/status", "")
+	assert.Equal(http.StatusOK, status)
+	var info nodeStatusInfo
+	require.Nil(t, json.Unmarshal([]byte(body), &info))
+	assert.Equal("broadcaster", info.NodeType)
+	assert.Equal(core.LivepeerVersion, info.Version)
+	assert.Empty(info.EthAddress)
+	assert.Zero(info.ActiveStreams)
+	assert.Equal([]string{}, info.OrchestratorPool)
+
+	status, body = serveAPIv2(s.newAPIv2(nil), http.MethodGet, "/streams", "")
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("[]", body)
+	status, body = serveAPIv2(s.newAPIv2(nil), http.MethodGet, "/streams/foo", "")
+	assert.Equal(http.StatusNotFound, status)
+	assert.JSONEq(`{"error":"unknown manifestID \"foo\""}`, body)
+	status, body = serveAPIv2(s.newAPIv2(nil), http.MethodGet, "/sessions", "")
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("[]", body)
+}
+
+func TestAPIv2_TerminateStream(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { DelegationAPIToken = "" }()
+	DelegationAPIToken = "secret"
+	a := stubServer().newAPIv2(nil)
+
+	code, body := serveAPIv2(a, "DELETE", "/streams/unknown", "")
+	assert.Equal(http.StatusNotFound, code)
+	assert.JSONEq(`{"error":"unknown manifestID \"unknown\""}`, body)
+
+	code, body = serveAPIv2(a, "DELETE", "/streams/unknown?blockFor=soon", "")
+	assert.Equal(http.StatusBadRequest, code)
+	assert.Contains(body, `invalid blockFor \"soon\"`)
+
+	code, _ = serveAPIv2(a, "DELETE", "/streams/unknown?blockFor=-1s", "")
+	assert.Equal(http.StatusBadRequest, code)
+}
+
+func TestAPIv2_Pricing(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { DelegationAPIToken = "" }()
+	DelegationAPIToken = "secret"
+	s := stubServer()
+	a := s.newAPIv2(nil)
+
+	// only orchestrators set prices
+	req := `{"broadcaster":"default","pricePerUnit":"10","pixelsPerUnit":"4"}`
+	status, _ := serveAPIv2(a, http.MethodPut, "/pricing", req)
+	assert.Equal(http.StatusBadRequest, status)
+
+	s.LivepeerNode.NodeType = core.OrchestratorNode
+	status, body := serveAPIv2(a, http.MethodPut, "/pricing", req)
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`{"basePrices":[{"broadcaster":"default","pricePerUnit":"5","pixelsPerUnit":"2"}],"maxPrices":[]}`, body)
+
+	bcast := "0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B"
+	status, _ = serveAPIv2(a, http.MethodPut, "/pricing", fmt.Sprintf(`{"broadcaster":"%v","pricePerUnit":"1","pixelsPerUnit":"1"}`, bcast))
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(big.NewRat(1, 1), s.LivepeerNode.GetBasePrice(bcast))
+
+	for _, req := range []string{
+		`{"broadcaster":"foo","pricePerUnit":"1","pixelsPerUnit":"1"}`,
+		`{"broadcaster":"default","pricePerUnit":"-1","pixelsPerUnit":"1"}`,
+		`{"broadcaster":"default","pricePerUnit":"1","pixelsPerUnit":"0"}`,
+		`{"broadcaster":"default","pricePerUnit":1,"pixelsPerUnit":"1"}`,
+	} {
+		status, _ = serveAPIv2(a, http.MethodPut, "/pricing", req)
+		assert.Equal(http.StatusBadRequest, status, req)
+	}
+
+	// capability prices
+	status, body = serveAPIv2(a, http.MethodPut, "/pricing", `{"broadcaster":"default","capability":"HEVC encode","pricePerUnit":"3","pixelsPerUnit":"1"}`)
+	assert.Equal(http.StatusOK, status)
+	assert.Contains(body, `{"broadcaster":"default","capability":"HEVC encode","pricePerUnit":"3","pixelsPerUnit":"1"}`)
+	status, body = serveAPIv2(a, http.MethodPut, "/pricing", `{"broadcaster":"default","capability":"foo","pricePerUnit":"3","pixelsPerUnit":"1"}`)
+	assert.Equal(http.StatusBadRequest, status)
+	assert.JSONEq(`{"error":"unknown capability: foo"}`, body)
+
+	// removed prices
+	for _, query := range []string{"?broadcaster=default", "?broadcaster=default&capability=foo", "?broadcaster=0x1111111111111111111111111111111111111111"} {
+		status, _ = serveAPIv2(a, http.MethodDelete, "/pricing"+query, "")
+		assert.NotEqual(http.StatusOK, status, query)
+	}
+	status, body = serveAPIv2(a, http.MethodDelete, "/pricing?broadcaster=default&capability=HEVC%20encode", "")
+	assert.Equal(http.StatusOK, status)
+	assert.NotContains(body, "HEVC encode")
+	assert.Empty(s.LivepeerNode.GetCapabilityBasePrices())
+	status, body = serveAPIv2(a, http.MethodDelete, "/pricing?broadcaster="+bcast, "")
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`{"basePrices":[{"broadcaster":"default","pricePerUnit":"5","pixelsPerUnit":"2"}],"maxPrices":[]}`, body)
+	assert.Nil(s.LivepeerNode.GetBasePrice(bcast))
+	status, _ = serveAPIv2(a, http.MethodPut, "/pricing", fmt.Sprintf(`{"broadcaster":"%v","pricePerUnit":"1","pixelsPerUnit":"1"}`, bcast))
+	assert.Equal(http.StatusOK, status)
+
+	// max prices of the broadcaster
+	defer BroadcastCfg.SetMaxPrice(nil)
+	BroadcastCfg.SetMaxPrice(big.NewRat(3, 1))
+	status, body = serveAPIv2(a, http.MethodGet, "/pricing", "")
+	assert.Equal(http.StatusOK, status)
+	var info pricingInfo
+	require.Nil(t, json.Unmarshal([]byte(body), &info))
+	assert.Len(info.BasePrices, 2)
+	assert.Equal([]priceInfo{{PricePerUnit: "3", PixelsPerUnit: "1"}}, info.MaxPrices)
+}
+
+func TestAPIv2_OffChain(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { DelegationAPIToken = "" }()
+	DelegationAPIToken = "secret"
+	a := stubServer().newAPIv2(nil)
+	for path, method := range map[string]string{
+		"/staking":          http.MethodGet,
+		"/staking/unbond":   http.MethodPost,
+		"/payments":         http.MethodGet,
+		"/payments/deposit": http.MethodPost,
+	} {
+		status, body := serveAPIv2(a, method, path, `{}`)
+		assert.Equal(http.StatusBadRequest, status, path)
+		assert.JSONEq(`{"error":"requires an on-chain node"}`, body, path)
+	}
+}
+
+func TestAPIv2_Staking(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { DelegationAPIToken = "" }()
+	DelegationAPIToken = "secret"
+	addr := ethcommon.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	account := ethcommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	client := &delegationClient{MockClient: &eth.MockClient{}}
+	client.On("Account").Return(accounts.Account{Address: account})
+	client.On("CheckTx").Return(nil)
+	s := stubServer()
+	a := s.newAPIv2(client)
+
+	client.On("GetDelegator", account).Return(&lpTypes.Delegator{
+		Address:         account,
+		Status:          "Bonded",
+		BondedAmount:    big.NewInt(1000),
+		DelegateAddress: addr,
+	}, nil)
+	status, body := serveAPIv2(a, http.MethodGet, "/staking", "")
+	assert.Equal(http.StatusOK, status)
+	var info stakingInfo
+	require.Nil(t, json.Unmarshal([]byte(body), &info))
+	assert.Equal("Bonded", info.Delegator.Status)
+	assert.Equal("1000", info.Delegator.BondedAmount)
+	assert.Equal("0", info.Delegator.PendingStake)
+	assert.Equal(addr.Hex(), info.Delegator.DelegateAddress)
+	assert.Nil(info.Transcoder)
+
+	s.LivepeerNode.NodeType = core.OrchestratorNode
+	client.On("GetTranscoder", account).Return(&lpTypes.Transcoder{Address: account, Active: true, ServiceURI: "https://orch:8935"}, nil)
+	status, body = serveAPIv2(a, http.MethodGet, "/staking", "")
+	assert.Equal(http.StatusOK, status)
+	info = stakingInfo{}
+	require.Nil(t, json.Unmarshal([]byte(body), &info))
+	require.NotNil(t, info.Transcoder)
+	assert.True(info.Transcoder.Active)
+	assert.Equal("https://orch:8935", info.Transcoder.ServiceURI)
+
+	tx := ethtypes.NewTx(&ethtypes.LegacyTx{Nonce: 3})
+	ok := fmt.Sprintf(`{"txHash":"%v"}`, tx.Hash().Hex())
+	client.On("Bond", big.NewInt(1000), addr).Return(tx, nil).Once()
+	status, body = serveAPIv2(a, http.MethodPost, "/staking/bond", fmt.Sprintf(`{"amount":"1000","to":"%v"}`, addr.Hex()))
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(ok, body)
+
+	client.On("Unbond", big.NewInt(5)).Return(tx, nil).Once()
+	status, _ = serveAPIv2(a, http.MethodPost, "/staking/unbond", `{"amount":"5"}`)
+	assert.Equal(http.StatusOK, status)
+
+	client.On("RebondFromUnbonded", addr, big.NewInt(1)).Return(tx, nil).Once()
+	status, _ = serveAPIv2(a, http.MethodPost, "/staking/rebond", fmt.Sprintf(`{"unbondingLockId":"1","to":"%v"}`, addr.Hex()))
+	assert.Equal(http.StatusOK, status)
+
+	client.On("WithdrawStake", big.NewInt(1)).Return(tx, nil).Once()
+	status, _ = serveAPIv2(a, http.MethodPost, "/staking/withdraw", `{"unbondingLockId":"1"}`)
+	assert.Equal(http.StatusOK, status)
+
+	status, body = serveAPIv2(a, http.MethodPost, "/staking/bond", `{"amount":"1000","to":"foo"}`)
+	assert.Equal(http.StatusBadRequest, status)
+	assert.JSONEq(`{"error":"missing or invalid to"}`, body)
+
+	client.On("Unbond", big.NewInt(1)).Return(nil, errors.New("insufficient stake")).Once()
+	status, body = serveAPIv2(a, http.MethodPost, "/staking/unbond", `{"amount":"1"}`)
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.JSONEq(`{"error":"could not send transaction: insufficient stake"}`, body)
+}
+
+func TestAPIv2_Payments(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { DelegationAPIToken = "" }()
+	DelegationAPIToken = "secret"
+	account := ethcommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	client := &eth.MockClient{}
+	client.On("Account").Return(accounts.Account{Address: account})
+	client.On("CheckTx").Return(nil)
+	a := stubServer().newAPIv2(client)
+
+	// a sender without a deposit
+	client.On("GetSenderInfo", account).Return(nil, errors.New("ErrNoResult")).Once()
+	status, body := serveAPIv2(a, http.MethodGet, "/payments", "")
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`{"deposit":"0","reserve":"0","claimedInCurrentRound":"0","withdrawRound":"0"}`, body)
+
+	client.On("GetSenderInfo", account).Return(&pm.SenderInfo{
+		Deposit:       big.NewInt(100),
+		WithdrawRound: big.NewInt(0),
+		Reserve:       &pm.ReserveInfo{FundsRemaining: big.NewInt(50), ClaimedInCurrentRound: big.NewInt(5)},
+	}, nil).Once()
+	status, body = serveAPIv2(a, http.MethodGet, "/payments", "")
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`{"deposit":"100","reserve":"50","claimedInCurrentRound":"5","withdrawRound":"0"}`, body)
+
+	tx := ethtypes.NewTx(&ethtypes.LegacyTx{Nonce: 4})
+	client.On("FundDepositAndReserve", big.NewInt(100), big.NewInt(0)).Return(tx, nil).Once()
+	status, body = serveAPIv2(a, http.MethodPost, "/payments/deposit", `{"depositAmount":"100","reserveAmount":"0"}`)
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(fmt.Sprintf(`{"txHash":"%v"}`, tx.Hash().Hex()), body)
+
+	for _, req := range []string{
+		`{"depositAmount":"0","reserveAmount":"0"}`,
+		`{"depositAmount":"-1","reserveAmount":"1"}`,
+		`{"depositAmount":"1"}`,
+	} {
+		status, _ = serveAPIv2(a, http.MethodPost, "/payments/deposit", req)
+		assert.Equal(http.StatusBadRequest, status, req)
+	}
+}
+
+func TestAPIv2_Auth(t *testing.T) {
+	assert := assert.New(t)
+	client := &delegationClient{MockClient: &eth.MockClient{}}
+	a := stubServer().newAPIv2(client)
+	state := map[string]string{
+		"/streams/foo":      http.MethodDelete,
+		"/pricing":          http.MethodPut,
+		"/staking/bond":     http.MethodPost,
+		"/staking/unbond":   http.MethodPost,
+		"/staking/rebond":   http.MethodPost,
+		"/staking/withdraw": http.MethodPost,
+		"/payments/deposit": http.MethodPost,
+	}
+
+	// the state changing routes are disabled without a token
+	defer func() { DelegationAPIToken = "" }()
+	DelegationAPIToken = ""
+	for path, method := range state {
+		status, body := serveAPIv2(a, method, path, `{}`)
+		assert.Equal(http.StatusForbidden, status, path)
+		assert.JSONEq(`{"error":"route is disabled, start the node with -delegationApiToken to enable it"}`, body, path)
+	}
+	status, _ := serveAPIv2(a, http.MethodDelete, "/pricing?broadcaster=default", "")
+	assert.Equal(http.StatusForbidden, status)
+	status, _ = serveAPIv2(a, http.MethodGet, "/status", "")
+	assert.Equal(http.StatusOK, status)
+
+	DelegationAPIToken = "secret"
+	for path, method := range state {
+		req := httptest.NewRequest(method, "http://example.com"+APIv2BasePath+path, strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer wrong")
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+		assert.Equal(http.StatusUnauthorized, w.Code, path)
+		assert.JSONEq(`{"error":"unauthorized"}`, trim(w.Body.Bytes()), path)
+	}
+
+	// authenticated requests reach the handlers
+	status, _ = serveAPIv2(a, http.MethodPost, "/staking/unbond", `{}`)
+	assert.Equal(http.StatusBadRequest, status)
+}
+
+func TestAPIv2_Document(t *testing.T) {
+	assert := assert.New(t)
+	a := stubServer().newAPIv2(nil)
+
+	status, body := serveAPIv2(a, http.MethodGet, "/openapi.json", "")
+	assert.Equal(http.StatusOK, status)
+	var doc struct {
+		Paths      map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.Nil(t, json.Unmarshal([]byte(body), &doc))
+	for _, route := range a.Routes() {
+		assert.Contains(doc.Paths[route.Path], strings.ToLower(route.Method), route.Path)
+		op := doc.Paths[route.Path][strings.ToLower(route.Method)].(map[string]interface{})
+		// the routes changing the state of the node are documented as requiring the token
+		if route.Method == http.MethodGet {
+			assert.NotContains(op, "security", route.Path)
+		} else {
+			assert.Contains(op, "security", route.Path)
+		}
+	}
+	for _, schema := range []string{"NodeStatusInfo", "StreamDebugInfo", "StreamSessionInfo", "PricingInfo", "PriceRequest", "StakingInfo", "TxResponse"} {
+		assert.Contains(doc.Components.Schemas, schema)
+	}
+}
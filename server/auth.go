@@ -60,6 +60,34 @@ func authenticateStream(authURL *url.URL, incomingRequestURL string) (*authWebho
 	return &authResp, nil
 }
 
+// authenticateTranscode authenticates a request to the /transcode/ endpoint with the auth webhook, unless
+// config was verified from a JWT, and returns the configuration of its stream. The transcode config header,
+// if any, must have the same profiles as the webhook response and overrides it.
+func authenticateTranscode(r *http.Request, config *authWebhookResponse) (*authWebhookResponse, error) {
+	if config != nil && config.authenticated {
+		return config, nil
+	}
+	if AuthWebhookURL == nil {
+		return config, nil
+	}
+	reqURL := &url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+	resp, err := authenticateStream(AuthWebhookURL, reqURL.String())
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		resp = &authWebhookResponse{}
+	} else if config != nil && !resp.areProfilesEqual(*config) {
+		return nil, errors.New("transcode config header profiles don't match the auth webhook response")
+	}
+	if config != nil {
+		resp = config
+	}
+	// The stream isn't authenticated again with its random manifest ID
+	resp.authenticated = true
+	return resp, nil
+}
+
 func getTranscodeConfiguration(r *http.Request) (*authWebhookResponse, error) {
 	transcodeConfigurationHeader := r.Header.Get(LIVERPEER_TRANSCODE_CONFIG_HEADER)
 	if transcodeConfigurationHeader == "" {
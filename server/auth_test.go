@@ -1,6 +1,7 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -102,6 +103,64 @@ func TestTranscodeAuthHeaderParsing(t *testing.T) {
 	require.Equal(t, "id-456", config.SessionID)
 }
 
+func TestAuthenticateTranscode(t *testing.T) {
+	var webhookURLs []string
+	respStatus, respBody := http.StatusOK, ""
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		webhookURLs = append(webhookURLs, req["url"])
+		w.WriteHeader(respStatus)
+		fmt.Fprint(w, respBody)
+	}))
+	defer s.Close()
+	defer func() { AuthWebhookURL = nil }()
+	r := httptest.NewRequest(http.MethodPost, "http://broadcaster/transcode/1.ts", nil)
+
+	// without a webhook, the transcode config header is returned as is
+	config := &authWebhookResponse{ManifestID: "header"}
+	resp, err := authenticateTranscode(r, config)
+	require.NoError(t, err)
+	require.Equal(t, config, resp)
+	require.False(t, resp.authenticated)
+
+	// the webhook is called with the /transcode/ URL
+	AuthWebhookURL, _ = url.Parse(s.URL)
+	resp, err = authenticateTranscode(r, nil)
+	require.NoError(t, err)
+	require.True(t, resp.authenticated)
+	require.Equal(t, []string{"http://broadcaster/transcode/1.ts"}, webhookURLs)
+
+	respBody = `{"manifestID": "webhook"}`
+	resp, err = authenticateTranscode(r, nil)
+	require.NoError(t, err)
+	require.Equal(t, "webhook", resp.ManifestID)
+	require.True(t, resp.authenticated)
+
+	// the header overrides the webhook response if their profiles match
+	resp, err = authenticateTranscode(r, &authWebhookResponse{ManifestID: "header"})
+	require.NoError(t, err)
+	require.Equal(t, "header", resp.ManifestID)
+	require.True(t, resp.authenticated)
+
+	config = &authWebhookResponse{Profiles: []ffmpeg.JsonProfile{{Name: "240p"}}}
+	_, err = authenticateTranscode(r, config)
+	require.Error(t, err)
+
+	// a verified JWT replaces the webhook
+	webhookURLs = nil
+	config = &authWebhookResponse{ManifestID: "jwt", authenticated: true}
+	resp, err = authenticateTranscode(r, config)
+	require.NoError(t, err)
+	require.Equal(t, config, resp)
+	require.Empty(t, webhookURLs)
+
+	// the webhook denies the request
+	respStatus = http.StatusForbidden
+	_, err = authenticateTranscode(r, nil)
+	require.Error(t, err)
+}
+
 func TestProfileEqualityWithNoProfiles(t *testing.T) {
 	a := authWebhookResponse{}
 	b := authWebhookResponse{}
@@ -19,12 +19,14 @@ import (
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 
+	"github.com/livepeer/go-livepeer/broker"
 	"github.com/livepeer/go-livepeer/clog"
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/core"
 	"github.com/livepeer/go-livepeer/monitor"
 	"github.com/livepeer/go-livepeer/net"
 	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/go-livepeer/storage"
 	"github.com/livepeer/go-livepeer/verification"
 	"github.com/livepeer/go-tools/drivers"
 	"github.com/livepeer/livepeer-data/pkg/data"
@@ -46,6 +48,9 @@ var Policy *verification.Policy
 var BroadcastCfg = &BroadcastConfig{}
 var MaxAttempts = 3
 
+// SessionRotationInterval is how often the sessions of a stream are replaced by newly selected ones. Disabled if 0.
+var SessionRotationInterval time.Duration
+
 var MetadataQueue event.Producer
 var MetadataPublishTimeout = 1 * time.Second
 
@@ -58,7 +63,9 @@ var submitMultiSession = func(ctx context.Context, sess *BroadcastSession, seg *
 
 type BroadcastConfig struct {
 	maxPrice *big.Rat
-	mu       sync.RWMutex
+	// Max prices for jobs requiring specific capabilities, overriding maxPrice
+	capabilityMaxPrices map[core.Capability]*big.Rat
+	mu                  sync.RWMutex
 }
 
 type SegFlightMetadata struct {
@@ -82,6 +89,57 @@ func (cfg *BroadcastConfig) SetMaxPrice(price *big.Rat) {
 	}
 }
 
+// SetCapabilityMaxPrice sets the max price for jobs that require the capability.
+// A nil price removes the capability specific max price.
+func (cfg *BroadcastConfig) SetCapabilityMaxPrice(capability core.Capability, price *big.Rat) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	if price == nil {
+		delete(cfg.capabilityMaxPrices, capability)
+		return
+	}
+	if cfg.capabilityMaxPrices == nil {
+		cfg.capabilityMaxPrices = make(map[core.Capability]*big.Rat)
+	}
+	cfg.capabilityMaxPrices[capability] = price
+}
+
+// CapabilityMaxPrices returns a copy of the capability specific max prices
+func (cfg *BroadcastConfig) CapabilityMaxPrices() map[core.Capability]*big.Rat {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	prices := make(map[core.Capability]*big.Rat, len(cfg.capabilityMaxPrices))
+	for capability, price := range cfg.capabilityMaxPrices {
+		prices[capability] = price
+	}
+	return prices
+}
+
+// MaxPriceForCapabilities returns the max price for a job requiring caps.
+// If the job requires capabilities that have a specific max price, the highest of those
+// prices is used. Otherwise the global max price applies.
+func (cfg *BroadcastConfig) MaxPriceForCapabilities(caps common.CapabilityComparator) *big.Rat {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	jobCaps, ok := caps.(*core.Capabilities)
+	if !ok || jobCaps == nil {
+		return cfg.maxPrice
+	}
+	var maxPrice *big.Rat
+	for capability, price := range cfg.capabilityMaxPrices {
+		if !jobCaps.HasCapability(capability) {
+			continue
+		}
+		if maxPrice == nil || price.Cmp(maxPrice) > 0 {
+			maxPrice = price
+		}
+	}
+	if maxPrice == nil {
+		return cfg.maxPrice
+	}
+	return maxPrice
+}
+
 type sessionsCreator func() ([]*BroadcastSession, error)
 type SessionPool struct {
 	mid core.ManifestID
@@ -98,6 +156,8 @@ type SessionPool struct {
 	refreshing bool // only allow one refresh in-flight
 	finished   bool // set at stream end
 
+	lastRotation time.Time
+
 	createSessions sessionsCreator
 	sus            *suspender
 }
@@ -113,6 +173,7 @@ func NewSessionPool(mid core.ManifestID, poolSize, numOrchs int, sus *suspender,
 		sel:            sel,
 		createSessions: createSession,
 		sus:            sus,
+		lastRotation:   time.Now(),
 	}
 }
 
@@ -123,12 +184,27 @@ func (sp *SessionPool) suspend(orch string) {
 	sp.sus.suspend(orch, penalty)
 }
 
+// suspendUntil suspends an orchestrator until t, whatever the number of refreshes
+func (sp *SessionPool) suspendUntil(orch string, t time.Time) {
+	sp.sus.suspendUntil(orch, t)
+}
+
 func (sp *SessionPool) refreshSessions(ctx context.Context) {
+	sp.updateSessions(ctx, false)
+}
+
+// rotateSessions re-runs orchestrator selection and replaces the current sessions with the selected ones,
+// so that long-lived streams move to orchestrators that score better than the ones initially selected
+func (sp *SessionPool) rotateSessions(ctx context.Context) {
+	sp.updateSessions(ctx, true)
+}
+
+func (sp *SessionPool) updateSessions(ctx context.Context, replace bool) {
 	started := time.Now()
-	clog.V(common.DEBUG).Infof(ctx, "Starting session refresh")
+	clog.V(common.DEBUG).Infof(ctx, "Starting session refresh replace=%v", replace)
 	defer func() {
 		sp.lock.Lock()
-		clog.V(common.DEBUG).Infof(ctx, "Ending session refresh dur=%s orchs=%d", time.Since(started),
+		clog.V(common.DEBUG).Infof(ctx, "Ending session refresh replace=%v dur=%s orchs=%d", replace, time.Since(started),
 			sp.sel.Size())
 		sp.lock.Unlock()
 	}()
@@ -167,12 +243,22 @@ func (sp *SessionPool) refreshSessions(ctx context.Context) {
 		return
 	}
 
+	if replace {
+		// Segments in flight with the replaced sessions still complete, but the sessions are not re-used
+		for orch := range sp.sessMap {
+			publishSessionEvent(net.SessionEvent_SESSION_REMOVED, sp.mid, orch)
+		}
+		sp.sessMap = make(map[string]*BroadcastSession)
+		sp.sel.Clear()
+		sp.lastSess = nil
+	}
 	for _, sess := range newBroadcastSessions {
 		if _, ok := sp.sessMap[sess.OrchestratorInfo.Transcoder]; ok {
 			continue
 		}
 		uniqueSessions = append(uniqueSessions, sess)
 		sp.sessMap[sess.OrchestratorInfo.Transcoder] = sess
+		publishSessionEvent(net.SessionEvent_SESSION_ADDED, sp.mid, sess.OrchestratorInfo.Transcoder)
 	}
 
 	sp.sel.Add(uniqueSessions)
@@ -251,6 +337,11 @@ func (sp *SessionPool) selectSessions(ctx context.Context, sessionsNum int) []*B
 		}
 		return (numSess > 0 || len(sp.lastSess) > 0)
 	}
+	if SessionRotationInterval > 0 && time.Since(sp.lastRotation) >= SessionRotationInterval {
+		sp.lastRotation = time.Now()
+		go sp.rotateSessions(ctx)
+	}
+
 	var selectedSessions []*BroadcastSession
 
 	for checkSessions(sp) {
@@ -329,6 +420,9 @@ func (sp *SessionPool) removeSession(session *BroadcastSession) {
 	sp.lock.Lock()
 	defer sp.lock.Unlock()
 
+	if _, ok := sp.sessMap[session.Transcoder()]; ok {
+		publishSessionEvent(net.SessionEvent_SESSION_REMOVED, sp.mid, session.Transcoder())
+	}
 	delete(sp.sessMap, session.Transcoder())
 }
 
@@ -338,6 +432,9 @@ func (sp *SessionPool) cleanup() {
 	sp.finished = true
 	sp.lastSess = nil
 	sp.sel.Clear()
+	for orch := range sp.sessMap {
+		publishSessionEvent(net.SessionEvent_SESSION_REMOVED, sp.mid, orch)
+	}
 	sp.sessMap = make(map[string]*BroadcastSession) // prevent segfaults
 }
 
@@ -374,6 +471,10 @@ func (sp *SessionPool) completeSession(sess *BroadcastSession) {
 type BroadcastSessionsManager struct {
 	mid              core.ManifestID
 	VerificationFreq uint
+	// Submit each segment to two orchestrators in parallel and use the first valid result
+	redundant bool
+	// Transcode the highest rendition and the other renditions of each segment on different orchestrators
+	split bool
 
 	// Accessing or changing any of the below requires ownership of this mutex
 	sessLock sync.Mutex
@@ -384,12 +485,27 @@ type BroadcastSessionsManager struct {
 	untrustedPool *SessionPool
 
 	verifiedSession *BroadcastSession
+
+	// health of the stream, counting the failed verifications
+	health *streamHealth
 }
 
 func (bsm *BroadcastSessionsManager) isVerificationEnabled() bool {
 	return bsm.VerificationFreq > 0
 }
 
+// isRedundant returns whether segments are submitted to two orchestrators with the first valid result used.
+// Verification takes precedence since it already submits segments to several orchestrators.
+func (bsm *BroadcastSessionsManager) isRedundant() bool {
+	return bsm.redundant && !bsm.isVerificationEnabled()
+}
+
+// isSplit returns whether the renditions of each segment are split across two orchestrators.
+// Verification and redundant transcoding need all the renditions from each orchestrator so they take precedence.
+func (bsm *BroadcastSessionsManager) isSplit() bool {
+	return bsm.split && !bsm.redundant && !bsm.isVerificationEnabled() && Policy == nil
+}
+
 func (bsm *BroadcastSessionsManager) shouldSkipVerification(sessions []*BroadcastSession) bool {
 	if bsm.verifiedSession == nil {
 		return false
@@ -406,6 +522,8 @@ func NewSessionManager(ctx context.Context, node *core.LivepeerNode, params *cor
 		trustedPoolSize = float64(node.OrchestratorPool.SizeWith(common.ScoreAtLeast(common.Score_Trusted)))
 		untrustedPoolSize = float64(node.OrchestratorPool.SizeWith(common.ScoreEqualTo(common.Score_Untrusted)))
 	}
+	// Orchestrators preferred for the stream are trusted and selected through the trusted pool
+	trustedPoolSize += float64(len(params.PreferredOrchestrators))
 	maxInflight := common.HTTPTimeout.Seconds() / SegLen.Seconds()
 	trustedNumOrchs := int(math.Min(trustedPoolSize, maxInflight*2))
 	untrustedNumOrchs := int(untrustedPoolSize)
@@ -417,15 +535,13 @@ func NewSessionManager(ctx context.Context, node *core.LivepeerNode, params *cor
 	createSessionsUntrusted := func() ([]*BroadcastSession, error) {
 		return selectOrchestrator(ctx, node, params, untrustedNumOrchs, susUntrusted, common.ScoreEqualTo(common.Score_Untrusted))
 	}
-	var stakeRdr stakeReader
-	if node.Eth != nil {
-		stakeRdr = &storeStakeReader{store: node.Database}
-	}
 	bsm := &BroadcastSessionsManager{
 		mid:              params.ManifestID,
 		VerificationFreq: params.VerificationFreq,
-		trustedPool:      NewSessionPool(params.ManifestID, int(trustedPoolSize), trustedNumOrchs, susTrusted, createSessionsTrusted, NewMinLSSelector(stakeRdr, 1.0)),
-		untrustedPool:    NewSessionPool(params.ManifestID, int(untrustedPoolSize), untrustedNumOrchs, susUntrusted, createSessionsUntrusted, NewMinLSSelectorWithRandFreq(stakeRdr, 1.0, SelectRandFreq)),
+		redundant:        params.RedundantTranscoding,
+		split:            params.SplitRenditions && len(params.Profiles) > 1,
+		trustedPool:      NewSessionPool(params.ManifestID, int(trustedPoolSize), trustedNumOrchs, susTrusted, createSessionsTrusted, sel(0)),
+		untrustedPool:    NewSessionPool(params.ManifestID, int(untrustedPoolSize), untrustedNumOrchs, susUntrusted, createSessionsUntrusted, sel(SelectRandFreq)),
 	}
 	bsm.trustedPool.refreshSessions(ctx)
 	bsm.untrustedPool.refreshSessions(ctx)
@@ -442,6 +558,20 @@ func (bsm *BroadcastSessionsManager) suspendAndRemoveOrch(sess *BroadcastSession
 	}
 }
 
+// suspendOrchUntilRetryAfter suspends the orchestrator that refused a segment with a retry-after hint until then
+func (bsm *BroadcastSessionsManager) suspendOrchUntilRetryAfter(sess *BroadcastSession, err error) {
+	retryAfter := RetryAfter(err)
+	if retryAfter <= 0 {
+		return
+	}
+	t := time.Now().Add(retryAfter)
+	if sess.OrchestratorScore == common.Score_Untrusted {
+		bsm.untrustedPool.suspendUntil(sess.OrchestratorInfo.GetTranscoder(), t)
+	} else {
+		bsm.trustedPool.suspendUntil(sess.OrchestratorInfo.GetTranscoder(), t)
+	}
+}
+
 func (bsm *BroadcastSessionsManager) removeSession(session *BroadcastSession) {
 	bsm.sessLock.Lock()
 	defer bsm.sessLock.Unlock()
@@ -495,10 +625,14 @@ func (bsm *BroadcastSessionsManager) selectSessions(ctx context.Context) (bs []*
 		return sessions, true, verified
 	}
 
+	sessionsNum := 1
+	if bsm.isRedundant() || bsm.isSplit() {
+		sessionsNum = 2
+	}
 	// Default to selecting from untrusted pool
-	sessions := bsm.untrustedPool.selectSessions(ctx, 1)
-	if len(sessions) == 0 {
-		sessions = bsm.trustedPool.selectSessions(ctx, 1)
+	sessions := bsm.untrustedPool.selectSessions(ctx, sessionsNum)
+	if len(sessions) < sessionsNum {
+		sessions = append(sessions, bsm.trustedPool.selectSessions(ctx, sessionsNum-len(sessions))...)
 	}
 
 	return sessions, false, verified
@@ -524,6 +658,10 @@ func (bsm *BroadcastSessionsManager) cleanup(ctx context.Context) {
 func (bsm *BroadcastSessionsManager) chooseResults(ctx context.Context, seg *stream.HLSSegment, submitResultsCh chan *SubmitResult,
 	submittedCount int) (*BroadcastSession, *ReceivedTranscodeResult, error) {
 
+	if bsm.isRedundant() {
+		return bsm.chooseFirstResult(ctx, submitResultsCh, submittedCount)
+	}
+
 	trustedResult, untrustedResults, err := bsm.collectResults(submitResultsCh, submittedCount)
 
 	if trustedResult == nil {
@@ -635,6 +773,8 @@ func (bsm *BroadcastSessionsManager) chooseResults(ctx context.Context, seg *str
 			}
 			return untrustedResult.Session, untrustedResult.TranscodeResult, untrustedResult.Err
 		} else {
+			observeVerificationFailure(untrustedResult.Session)
+			bsm.health.verificationFailed()
 			sessionsToSuspend = append(sessionsToSuspend, untrustedResult.Session)
 		}
 	}
@@ -668,17 +808,77 @@ func (bsm *BroadcastSessionsManager) collectResults(submitResultsCh chan *Submit
 		}
 		if res.Err != nil {
 			err = res.Err
-			if isNonRetryableError(err) {
-				bsm.completeSession(context.TODO(), res.Session, false)
-			} else {
-				bsm.suspendAndRemoveOrch(res.Session)
-			}
+			bsm.resultFailed(res)
 		}
 	}
 
 	return trustedResults, untrustedResults, err
 }
 
+// chooseFirstResult returns the first valid result. The results received after it are compared against it
+// in the background and their sessions returned to the pools.
+func (bsm *BroadcastSessionsManager) chooseFirstResult(ctx context.Context, submitResultsCh chan *SubmitResult,
+	submittedCount int) (*BroadcastSession, *ReceivedTranscodeResult, error) {
+
+	var first *SubmitResult
+	var err error
+	received := 0
+	for first == nil && received < submittedCount {
+		res := <-submitResultsCh
+		received++
+		if res.Err == nil && res.TranscodeResult != nil {
+			first = res
+			continue
+		}
+		err = res.Err
+		bsm.resultFailed(res)
+	}
+	if first == nil {
+		return nil, nil, fmt.Errorf("error transcoding: no results at all err=%w", err)
+	}
+
+	go func(remaining int) {
+		for i := 0; i < remaining; i++ {
+			res := <-submitResultsCh
+			if res.Err != nil || res.TranscodeResult == nil {
+				bsm.resultFailed(res)
+				continue
+			}
+			compareRedundantResults(ctx, first, res)
+			updateSession(res.Session, res.TranscodeResult)
+			bsm.completeSession(ctx, res.Session, false)
+		}
+	}(submittedCount - received)
+
+	return first.Session, first.TranscodeResult, nil
+}
+
+// resultFailed suspends the orchestrator that failed to return a result unless the error was not its fault.
+// An orchestrator that refused the segment with a retry-after hint stays suspended until then.
+func (bsm *BroadcastSessionsManager) resultFailed(res *SubmitResult) {
+	if res.Err == nil || isNonRetryableError(res.Err) {
+		bsm.completeSession(context.TODO(), res.Session, false)
+	} else {
+		bsm.suspendOrchUntilRetryAfter(res.Session, res.Err)
+		bsm.suspendAndRemoveOrch(res.Session)
+	}
+}
+
+// compareRedundantResults logs whether the renditions returned by two orchestrators for the same segment differ
+func compareRedundantResults(ctx context.Context, first, other *SubmitResult) bool {
+	firstSegs, otherSegs := first.TranscodeResult.Segments, other.TranscodeResult.Segments
+	equal := len(firstSegs) == len(otherSegs)
+	for i := 0; equal && i < len(firstSegs); i++ {
+		equal = firstSegs[i].Pixels == otherSegs[i].Pixels
+	}
+	if !equal {
+		clog.Warningf(ctx, "Redundant transcode results differ orch=%v orch=%v", first.Session.Transcoder(), other.Session.Transcoder())
+	} else {
+		clog.V(common.DEBUG).Infof(ctx, "Redundant transcode results match orch=%v orch=%v", first.Session.Transcoder(), other.Session.Transcoder())
+	}
+	return equal
+}
+
 // the caller needs to ensure bsm.sessLock is acquired before calling this.
 func (bsm *BroadcastSessionsManager) completeSessionUnsafe(ctx context.Context, sess *BroadcastSession, tearDown bool) {
 	if tearDown {
@@ -716,12 +916,35 @@ func (bsm *BroadcastSessionsManager) usingVerified() bool {
 func selectOrchestrator(ctx context.Context, n *core.LivepeerNode, params *core.StreamParameters, count int, sus *suspender,
 	scorePred common.ScorePred) ([]*BroadcastSession, error) {
 
-	if n.OrchestratorPool == nil {
+	var ods common.OrchestratorDescriptors
+	if len(params.PreferredOrchestrators) > 0 && scorePred(common.Score_Trusted) {
+		ods = getPreferredOrchestrators(ctx, n, params, count, sus)
+	}
+	numPreferred := len(ods)
+
+	if n.OrchestratorPool == nil && numPreferred == 0 {
 		clog.Infof(ctx, "No orchestrators specified; not transcoding")
 		return nil, errDiscovery
 	}
 
-	ods, err := n.OrchestratorPool.GetOrchestrators(ctx, count, sus, params.Capabilities, scorePred)
+	var err error
+	if n.OrchestratorPool != nil && len(ods) < count {
+		// The pool may return the preferred orchestrators too, so ask for enough of them to replace the duplicates
+		var poolOds common.OrchestratorDescriptors
+		poolOds, err = n.OrchestratorPool.GetOrchestrators(ctx, count, sus, params.Capabilities, scorePred)
+		for _, od := range poolOds {
+			if len(ods) >= count {
+				break
+			}
+			if !includesTranscoder(ods, od.RemoteInfo.GetTranscoder()) {
+				ods = append(ods, od)
+			}
+		}
+		if numPreferred > 0 && err != nil {
+			clog.Errorf(ctx, "Error getting orchestrators from pool, using preferred orchestrators only err=%q", err)
+			err = nil
+		}
+	}
 
 	if len(ods) <= 0 {
 		clog.InfofErr(ctx, "No orchestrators found; not transcoding", err)
@@ -733,7 +956,7 @@ func selectOrchestrator(ctx context.Context, n *core.LivepeerNode, params *core.
 
 	var sessions []*BroadcastSession
 
-	for _, od := range ods {
+	for i, od := range ods {
 		var (
 			sessionID    string
 			balance      Balance
@@ -761,7 +984,7 @@ func selectOrchestrator(ctx context.Context, n *core.LivepeerNode, params *core.
 
 		var orchOS drivers.OSSession
 		if len(od.RemoteInfo.Storage) > 0 {
-			orchOS = drivers.NewSession(core.FromNetOsInfo(od.RemoteInfo.Storage[0]))
+			orchOS = storage.NewRetrySession(drivers.NewSession(core.FromNetOsInfo(od.RemoteInfo.Storage[0])), storage.DefaultRetryPolicy)
 		}
 
 		bcastOS := params.OS
@@ -788,12 +1011,72 @@ func selectOrchestrator(ctx context.Context, n *core.LivepeerNode, params *core.
 			lock:              &sync.RWMutex{},
 			OrchestratorScore: oScore,
 		}
+		if i < numPreferred {
+			session.PreferenceRank = i + 1
+		}
 
 		sessions = append(sessions, session)
 	}
 	return sessions, nil
 }
 
+// getPreferredOrchestrators returns up to count of the orchestrators preferred for the stream, in order of preference,
+// skipping those that can't be reached, are suspended, filtered out or don't support the stream's capabilities
+// includesTranscoder returns whether one of ods has the transcoder URL
+func includesTranscoder(ods common.OrchestratorDescriptors, transcoder string) bool {
+	for _, od := range ods {
+		if od.RemoteInfo.GetTranscoder() == transcoder {
+			return true
+		}
+	}
+	return false
+}
+
+func getPreferredOrchestrators(ctx context.Context, n *core.LivepeerNode, params *core.StreamParameters, count int,
+	sus *suspender) common.OrchestratorDescriptors {
+
+	uris := params.PreferredOrchestrators
+	infos := make([]*net.OrchestratorInfo, len(uris))
+	var wg sync.WaitGroup
+	for i, uri := range uris {
+		wg.Add(1)
+		go func(i int, uri *url.URL) {
+			defer wg.Done()
+			info, err := getOrchestratorInfoRPC(ctx, core.NewBroadcaster(n), uri, DiscoveryParams(params.Capabilities))
+			if err != nil {
+				clog.Errorf(ctx, "Error getting info from preferred orchestrator orch=%v err=%q", uri, err)
+				return
+			}
+			infos[i] = info
+		}(i, uri)
+	}
+	wg.Wait()
+
+	var ods common.OrchestratorDescriptors
+	for i, info := range infos {
+		if len(ods) >= count {
+			break
+		}
+		localInfo := &common.OrchestratorLocalInfo{URL: uris[i], Score: common.Score_Trusted}
+		if info == nil || !OrchFilter.Allows(localInfo, info) || !OrchRegions.Allows(info) {
+			continue
+		}
+		if info.Capabilities == nil && !params.Capabilities.LegacyOnly() ||
+			info.Capabilities != nil && !params.Capabilities.CompatibleWith(info.Capabilities) {
+			clog.V(common.DEBUG).Infof(ctx, "Preferred orchestrator does not support the stream's capabilities orch=%v", uris[i])
+			continue
+		}
+		if sus.Suspended(info.Transcoder) > 0 || includesTranscoder(ods, info.Transcoder) {
+			continue
+		}
+		ods = append(ods, common.OrchestratorDescriptor{
+			LocalInfo:  localInfo,
+			RemoteInfo: info,
+		})
+	}
+	return ods
+}
+
 func processSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, segPar *core.SegmentParameters) ([]string, error) {
 
 	rtmpStrm := cxn.stream
@@ -829,7 +1112,7 @@ func processSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSeg
 			ctx, cancel := clog.WithTimeout(context.Background(), ctx, recordSegmentsMaxTimeout)
 			defer cancel()
 			now := time.Now()
-			uri, err := drivers.SaveRetried(ctx, ros, name, seg.Data, map[string]string{"duration": segDurMs}, 3)
+			uri, err := ros.SaveData(ctx, name, bytes.NewReader(seg.Data), map[string]string{"duration": segDurMs}, 0)
 			took := time.Since(now)
 			if err != nil {
 				clog.Errorf(ctx, "Error saving name=%s bytes=%d to record store err=%q",
@@ -853,6 +1136,8 @@ func processSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSeg
 		}
 		return nil, err
 	}
+	// The URIs handed out to orchestrators and players are pre-signed if the object store signs URLs
+	uri = storage.SignURL(cpl.GetOSSession(), uri)
 	if cpl.GetOSSession().IsExternal() {
 		seg.Name = uri // hijack seg.Name to convey the uploaded URI
 	}
@@ -885,6 +1170,7 @@ func processSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSeg
 				}
 				return nil, err
 			}
+			uri = storage.SignURL(cpl.GetOSSession(), uri)
 			urls = append(urls, uri)
 			err = cpl.InsertHLSSegment(&profile, seg.SeqNo, uri, seg.Duration)
 			if err != nil {
@@ -903,14 +1189,23 @@ func processSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSeg
 	}
 
 	var (
-		startTime = time.Now()
-		attempts  []data.TranscodeAttemptInfo
-		urls      []string
+		startTime   = time.Now()
+		attempts    []data.TranscodeAttemptInfo
+		urls        []string
+		policy      = RetryPolicy
+		maxAttempts = MaxAttempts
 	)
-	for len(attempts) < MaxAttempts {
-		// if transcodeSegment fails, retry; rudimentary
+	if cxn.params != nil && cxn.params.RetryPolicy != nil {
+		policy = *cxn.params.RetryPolicy
+		if policy.MaxAttempts > 0 {
+			maxAttempts = policy.MaxAttempts
+		}
+	}
+	retries := newSegmentRetries(policy, startTime, time.Duration(seg.Duration*float64(time.Second)))
+	split := &splitResult{}
+	for len(attempts) < maxAttempts {
 		var info *data.TranscodeAttemptInfo
-		urls, info, err = transcodeSegment(ctx, cxn, seg, name, sv, segPar)
+		urls, info, err = transcodeSegment(ctx, cxn, seg, name, sv, segPar, split)
 		attempts = append(attempts, *info)
 		if err == nil {
 			break
@@ -930,43 +1225,74 @@ func processSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSeg
 			clog.Warningf(ctx, "Not retrying current segment due to context cancellation err=%q", err)
 			break
 		}
-		// recoverable error, retry
+		if len(attempts) >= maxAttempts {
+			break
+		}
+		// recoverable error, retry according to the retry policy
+		if retryErr := retries.retry(ctx, err); retryErr != nil {
+			err = retryErr
+			clog.Warningf(ctx, "Not retrying current segment err=%q", err)
+			break
+		}
 	}
-	if MetadataQueue != nil {
-		success := err == nil && len(urls) > 0
-		streamID := string(mid)
-		if cxn.params != nil && cxn.params.ExternalStreamID != "" {
-			streamID = cxn.params.ExternalStreamID
+	if (err != nil || len(urls) == 0) && LocalFallback != nil && cxn.params != nil && len(cxn.params.Profiles) > 0 &&
+		!shouldStopStream(err) && ctx.Err() == nil {
+		clog.Warningf(ctx, "Falling back to local transcoding err=%q", err)
+		if fallbackUrls, fallbackErr := LocalFallback.transcode(ctx, cxn, seg); fallbackErr != nil {
+			clog.Errorf(ctx, "Local fallback transcoding failed err=%q", fallbackErr)
+		} else {
+			urls, err = fallbackUrls, nil
 		}
+	}
+	latency := time.Since(startTime)
+	success := err == nil && len(urls) > 0
+	cxn.debugStats.observe(seg.SeqNo, latency, attempts, err)
+	var orch string
+	if len(attempts) > 0 {
+		orch = attempts[len(attempts)-1].Orchestrator.TranscoderUri
+	}
+	publishSegmentEvent(mid, orch, seg.SeqNo, latency, success, err)
+	health, healthChanged := cxn.health.observe(seg.Duration, latency, attempts, !success)
+	if monitor.Enabled {
+		monitor.StreamHealthScore(ctx, nonce, health.Score)
+	}
+	if healthChanged {
+		clog.Warningf(ctx, "Stream health changed state=%s score=%v", health.State, health.Score)
+		publishStreamHealth(cxn, health, false)
+	}
+	if MetadataQueue != nil {
+		streamID := cxnStreamID(cxn)
 		key := newTranscodeEventKey(mid, streamID)
 		evt := newTranscodeEvent(streamID, seg, startTime, success, attempts)
 		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), MetadataPublishTimeout)
+			ctx, cancel := context.WithTimeout(broker.WithPartitionKey(context.Background(), string(mid)), MetadataPublishTimeout)
 			defer cancel()
 			if err := MetadataQueue.Publish(ctx, key, evt, false); err != nil {
 				clog.Errorf(ctx, "Error publishing stream transcode event: err=%q key=%q event=%+v", err, key, evt)
 			}
 		}()
 	}
-	if len(attempts) == MaxAttempts && err != nil {
+	if len(attempts) == maxAttempts && err != nil {
 		err = fmt.Errorf("Hit max transcode attempts: %w", err)
 	}
 	return urls, err
 }
 
 func transcodeSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, name string,
-	verifier *verification.SegmentVerifier, segPar *core.SegmentParameters) ([]string, *data.TranscodeAttemptInfo, error) {
+	verifier *verification.SegmentVerifier, segPar *core.SegmentParameters, split *splitResult) ([]string, *data.TranscodeAttemptInfo, error) {
 
 	var urls []string
 	info := &data.TranscodeAttemptInfo{}
 	var err error
 
+	ctx, span := monitor.StartSegmentSpan(ctx, monitor.SpanTranscodeSegment, string(cxn.mid), seg.SeqNo)
 	defer func(startTime time.Time) {
 		info.LatencyMs = time.Since(startTime).Milliseconds()
 		if err != nil {
 			errStr := err.Error()
 			info.Error = &errStr
 		}
+		monitor.EndSpan(span, err)
 	}(time.Now())
 
 	nonce := cxn.nonce
@@ -992,6 +1318,10 @@ func transcodeSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSS
 	if monitor.Enabled {
 		monitor.TranscodeTry(ctx, nonce, seg.SeqNo)
 	}
+	if cxn.sessManager.isSplit() && (len(sessions) == 2 || split != nil && split.urls != nil) {
+		urls, err = transcodeSplit(ctx, cxn, seg, name, sessions, segPar, nonce, split)
+		return urls, info, err
+	}
 	if len(sessions) == 1 {
 		// shortcut for most common path
 		sess := sessions[0]
@@ -1001,12 +1331,15 @@ func transcodeSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSS
 		// cxn.sessManager.pushSegInFlight(sess, seg)
 		sess.pushSegInFlight(seg)
 		var res *ReceivedTranscodeResult
+		submitStart := time.Now()
 		res, err = SubmitSegment(ctx, sess.Clone(), seg, segPar, nonce, calcPerceptualHash, verified)
+		observeSubmission(sess, submitStart, res, err)
 		if err != nil || res == nil {
 			if isNonRetryableError(err) {
 				cxn.sessManager.completeSession(ctx, sess, false)
 				return nil, info, err
 			}
+			cxn.sessManager.suspendOrchUntilRetryAfter(sess, err)
 			cxn.sessManager.suspendAndRemoveOrch(sess)
 			if res == nil && err == nil {
 				err = errors.New("empty response")
@@ -1098,11 +1431,11 @@ func transcodeSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSS
 				return nil, info, err
 			}
 		}
-		urls, err = downloadResults(ctx, cxn, seg, sess, res, verifier)
+		urls, err = downloadResults(ctx, cxn, seg, sess, sess.Params.Profiles, res, verifier)
 		return urls, info, err
 	} else {
 		resc := make(chan *SubmitResult, len(sessions))
-		submittedCount := 0
+		var submitted []*BroadcastSession
 		for _, sess := range sessions {
 			// todo: run it in own goroutine (move to submitSegment?)
 			seg2, err := prepareForTranscoding(ctx, cxn, sess, seg, name)
@@ -1112,8 +1445,9 @@ func transcodeSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSS
 			// cxn.sessManager.pushSegInFlight(sess, seg)
 			sess.pushSegInFlight(seg2)
 			submitMultiSession(ctx, sess, seg2, segPar, nonce, calcPerceptualHash, resc)
-			submittedCount++
+			submitted = append(submitted, sess)
 		}
+		submittedCount := len(submitted)
 		if submittedCount == 0 {
 			return nil, info, fmt.Errorf("error: not submitted anything")
 		}
@@ -1124,13 +1458,13 @@ func transcodeSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSS
 			return nil, info, err
 		}
 		for _, usedSession := range sessions {
-			if usedSession != sess {
+			if usedSession != sess && !(cxn.sessManager.isRedundant() && includesSession(submitted, usedSession)) {
 				// return session that we're not using
 				cxn.sessManager.completeSession(ctx, usedSession, true)
 			}
 		}
 
-		urls, err = downloadResults(ctx, cxn, seg, sess, results, verifier)
+		urls, err = downloadResults(ctx, cxn, seg, sess, sess.Params.Profiles, results, verifier)
 		return urls, info, err
 	}
 }
@@ -1144,7 +1478,9 @@ type SubmitResult struct {
 func submitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSSegment, segPar *core.SegmentParameters,
 	nonce uint64, calcPerceptualHash bool, resc chan *SubmitResult) {
 
+	start := time.Now()
 	res, err := SubmitSegment(ctx, sess.Clone(), seg, segPar, nonce, calcPerceptualHash, false)
+	observeSubmission(sess, start, res, err)
 	resc <- &SubmitResult{
 		Session:         sess,
 		TranscodeResult: res,
@@ -1194,8 +1530,8 @@ func prepareForTranscoding(ctx context.Context, cxn *rtmpConnection, sess *Broad
 	return res, nil
 }
 
-func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, sess *BroadcastSession, res *ReceivedTranscodeResult,
-	verifier *verification.SegmentVerifier) ([]string, error) {
+func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, sess *BroadcastSession, profiles []ffmpeg.VideoProfile,
+	res *ReceivedTranscodeResult, verifier *verification.SegmentVerifier) ([]string, error) {
 
 	nonce := cxn.nonce
 	// download transcoded segments from the transcoder
@@ -1230,7 +1566,7 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 		}()
 
 		bos := sess.BroadcasterOS
-		profile := sess.Params.Profiles[i]
+		profile := profiles[i]
 
 		bros := cpl.GetRecordOSSession()
 		var data []byte
@@ -1238,6 +1574,7 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 		// - A verification policy is set. The segment data is needed for signature verification and/or pixel count verification
 		// - The segment data needs to be uploaded to the broadcaster's own OS
 		if verifier != nil || bros != nil || bos != nil && !bos.IsOwn(url) {
+			dlStart := time.Now()
 			d, err := downloadSeg(ctx, url)
 			if err != nil {
 				errFunc(monitor.SegmentTranscodeErrorDownload, url, err)
@@ -1250,6 +1587,9 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 
 			data = d
 			atomic.AddUint64(&cxn.transcodedBytes, uint64(len(data)))
+			if monitor.Enabled {
+				monitor.RenditionStageTime(monitor.RenditionStageDownload, profile.Name, profile.Resolution, time.Since(dlStart))
+			}
 		}
 
 		if bros != nil {
@@ -1260,7 +1600,7 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 				name := fmt.Sprintf("%s/%d%s", profile.Name, seg.SeqNo, ext)
 				segDurMs := getSegDurMsString(seg)
 				now := time.Now()
-				uri, err := drivers.SaveRetried(ctx, bros, name, data, map[string]string{"duration": segDurMs}, 3)
+				uri, err := bros.SaveData(ctx, name, bytes.NewReader(data), map[string]string{"duration": segDurMs}, 0)
 				took := time.Since(now)
 				if err != nil {
 					clog.Errorf(ctx, "Error saving nonce=%d manifestID=%s name=%s to record store err=%q", nonce, cxn.mid, name, err)
@@ -1347,7 +1687,9 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 	}
 
 	for i, url := range segURLs {
-		err := cpl.InsertHLSSegment(&sess.Params.Profiles[i], seg.SeqNo, url, seg.Duration)
+		url = storage.SignURL(sess.BroadcasterOS, url)
+		segURLs[i] = url
+		err := cpl.InsertHLSSegment(&profiles[i], seg.SeqNo, url, seg.Duration)
 		if err != nil {
 			// InsertHLSSegment only returns ErrSegmentAlreadyExists error
 			// Right now InsertHLSSegment call is atomic regarding transcoded segments - we either inserting
@@ -1361,14 +1703,15 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 	}
 
 	if monitor.Enabled {
-		monitor.SegmentFullyTranscoded(ctx, nonce, seg.SeqNo, common.ProfilesNames(sess.Params.Profiles), errCode, sess.OrchestratorInfo)
+		job := monitor.JobType{Codecs: common.ProfilesCodecs(profiles), Detection: segmentDetectionEnabled(sess.Params, seg.SeqNo)}
+		monitor.SegmentFullyTranscoded(ctx, nonce, seg.SeqNo, common.ProfilesNames(profiles), job, errCode, sess.OrchestratorInfo)
 	}
 
 	clog.V(common.DEBUG).Infof(ctx, "Successfully validated segment")
 	return segURLs, nil
 }
 
-var sessionErrStrings = []string{"dial tcp", "unexpected EOF", core.ErrOrchBusy.Error(), core.ErrOrchCap.Error()}
+var sessionErrStrings = []string{"dial tcp", "unexpected EOF", core.ErrOrchBusy.Error(), core.ErrOrchCap.Error(), core.ErrBroadcasterSessionCap.Error()}
 
 var sessionErrRegex = common.GenErrRegex(sessionErrStrings)
 
@@ -1407,6 +1750,8 @@ func verify(verifier *verification.SegmentVerifier, cxn *rtmpConnection,
 		// Remove the O from the working set for now
 		// Error falls through towards end if necessary
 		cxn.sessManager.removeSession(sess)
+		observeVerificationFailure(sess)
+		cxn.health.verificationFailed()
 	}
 	if accepted != nil {
 		// The returned set of results has been accepted by the verifier
@@ -1461,7 +1806,7 @@ func updateSession(sess *BroadcastSession, res *ReceivedTranscodeResult) {
 	sess.OrchestratorInfo = oInfo
 
 	if len(oInfo.Storage) > 0 {
-		sess.OrchestratorOS = drivers.NewSession(core.FromNetOsInfo(oInfo.Storage[0]))
+		sess.OrchestratorOS = storage.NewRetrySession(drivers.NewSession(core.FromNetOsInfo(oInfo.Storage[0])), storage.DefaultRetryPolicy)
 	}
 
 	if sess.Sender != nil && oInfo.TicketParams != nil {
@@ -1488,7 +1833,11 @@ func refreshSession(ctx context.Context, sess *BroadcastSession) error {
 	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
 	defer cancel()
 
-	oInfo, err := getOrchestratorInfoRPC(ctx, sess.Broadcaster, uri)
+	var caps common.CapabilityComparator
+	if sess.Params != nil {
+		caps = sess.Params.Capabilities
+	}
+	oInfo, err := getOrchestratorInfoRPC(ctx, sess.Broadcaster, uri, DiscoveryParams(caps))
 	if err != nil {
 		return err
 	}
@@ -1537,6 +1886,14 @@ func shouldRefreshSession(ctx context.Context, sess *BroadcastSession) (bool, er
 	return false, nil
 }
 
+// cxnStreamID returns the ID the events of the stream are published with
+func cxnStreamID(cxn *rtmpConnection) string {
+	if cxn.params != nil && cxn.params.ExternalStreamID != "" {
+		return cxn.params.ExternalStreamID
+	}
+	return string(cxn.mid)
+}
+
 func newTranscodeEventKey(mid core.ManifestID, streamID string) string {
 	shardKey := string(mid[0])
 	return fmt.Sprintf("stream_health.transcode.%s.%s", shardKey, streamID)
@@ -1561,6 +1918,9 @@ func nonRetryableErrMapInit() map[string]bool {
 	for _, v := range ffmpeg.NonRetryableErrs {
 		errs[v] = true
 	}
+	// The spend limits of the broadcaster are reached, which is not the fault of the orchestrator
+	errs[errStreamSpendLimitExceeded.Error()] = true
+	errs[errHourlySpendLimitExceeded.Error()] = true
 	return errs
 }
 
@@ -1572,7 +1932,8 @@ func isNonRetryableError(err error) bool {
 			return true
 		}
 	}
-	if strings.HasPrefix(err.Error(), "Hit max transcode attempts:") {
+	// The max attempts or the retry policy of the segment are exhausted
+	if strings.HasPrefix(err.Error(), "Hit max ") || strings.HasPrefix(err.Error(), "Hit retry budget:") {
 		return true
 	}
 	return false
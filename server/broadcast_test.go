@@ -15,6 +15,7 @@ import (
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/golang/protobuf/proto"
+	"github.com/livepeer/go-livepeer/broker"
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/core"
 	"github.com/livepeer/go-livepeer/net"
@@ -70,7 +71,7 @@ func StubBroadcastSessionsManager() *BroadcastSessionsManager {
 	return bsmWithSessList([]*BroadcastSession{sess1, sess2})
 }
 
-func selFactoryEmpty() BroadcastSessionsSelector {
+func selFactoryEmpty(randFreq float64) BroadcastSessionsSelector {
 	return &LIFOSelector{}
 }
 
@@ -260,6 +261,40 @@ func (s *stubSelector) Size() int                                { return s.size
 func (s *stubSelector) Clear()                                   {}
 func (s *stubSelector) Remove(session *BroadcastSession) bool    { return false }
 
+func TestBroadcastConfig_MaxPriceForCapabilities(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &BroadcastConfig{}
+	h264 := core.NewCapabilities([]core.Capability{core.Capability_H264}, nil)
+	hevc := core.NewCapabilities([]core.Capability{core.Capability_H264, core.Capability_HEVC_Encode, core.Capability_HEVC_Decode}, nil)
+	var nilCaps *core.Capabilities
+
+	// no max prices set
+	assert.Nil(cfg.MaxPriceForCapabilities(h264))
+	assert.Nil(cfg.MaxPriceForCapabilities(nil))
+
+	// only the global max price set
+	cfg.SetMaxPrice(big.NewRat(1, 1))
+	assert.Equal(big.NewRat(1, 1), cfg.MaxPriceForCapabilities(hevc))
+	assert.Equal(big.NewRat(1, 1), cfg.MaxPriceForCapabilities(nilCaps))
+
+	// capability specific max price only applies to jobs requiring the capability
+	cfg.SetCapabilityMaxPrice(core.Capability_HEVC_Encode, big.NewRat(3, 1))
+	assert.Equal(big.NewRat(1, 1), cfg.MaxPriceForCapabilities(h264))
+	assert.Equal(big.NewRat(3, 1), cfg.MaxPriceForCapabilities(hevc))
+	assert.Equal(big.NewRat(1, 1), cfg.MaxPriceForCapabilities(nil))
+
+	// highest matching capability max price is used
+	cfg.SetCapabilityMaxPrice(core.Capability_HEVC_Decode, big.NewRat(2, 1))
+	assert.Equal(big.NewRat(3, 1), cfg.MaxPriceForCapabilities(hevc))
+
+	// a capability max price can be lower than the global max price
+	cfg.SetCapabilityMaxPrice(core.Capability_HEVC_Encode, nil)
+	cfg.SetMaxPrice(big.NewRat(5, 1))
+	assert.Equal(big.NewRat(2, 1), cfg.MaxPriceForCapabilities(hevc))
+	assert.Len(cfg.CapabilityMaxPrices(), 1)
+}
+
 func TestStopSessionErrors(t *testing.T) {
 
 	// check error cases
@@ -268,6 +303,7 @@ func TestStopSessionErrors(t *testing.T) {
 		"Unable to submit segment 5 Post https://127.0.0.1:8936/segment: dial tcp 127.0.0.1:8936: getsockopt: connection refused",
 		core.ErrOrchBusy.Error(),
 		core.ErrOrchCap.Error(),
+		core.ErrBroadcasterSessionCap.Error(),
 	}
 
 	// Sanity check that we're checking each failure case
@@ -384,7 +420,7 @@ func TestSelectSession_MultipleInFlight2(t *testing.T) {
 	defer func() { getOrchestratorInfoRPC = oldGetOrchestratorInfoRPC }()
 
 	orchInfoCalled := 0
-	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		orchInfoCalled++
 		return successOrchInfoUpdate, nil
 	}
@@ -422,14 +458,14 @@ func TestSelectSession_MultipleInFlight2(t *testing.T) {
 	sess.OrchestratorInfo.AuthToken = &net.AuthToken{Token: []byte("foo"), SessionId: "bar", Expiration: time.Now().Add(-1 * time.Hour).Unix()}
 	errC := make(chan error)
 	go func() {
-		res, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Name: "s1", Duration: 900}, "dummy", nil, nil)
+		res, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Name: "s1", Duration: 900}, "dummy", nil, nil, nil)
 		assert.Len(res, 1)
 		errC <- err
 	}()
 	<-segStarted
 	assert.Len(cxn.sessManager.trustedPool.lastSess[0].SegsInFlight, 1)
 	go func() {
-		res, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Name: "s2", Duration: 900}, "dummy", nil, nil)
+		res, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Name: "s2", Duration: 900}, "dummy", nil, nil, nil)
 		assert.Nil(err)
 		assert.Len(res, 1)
 		errC <- err
@@ -501,7 +537,7 @@ func TestTranscodeSegment_UploadFailed_SuspendAndRemove(t *testing.T) {
 		sessManager: bsm,
 	}
 	seg := &stream.HLSSegment{}
-	_, _, err := transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil)
+	_, _, err := transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil, nil)
 	assert.EqualError(err, "some error")
 	_, ok := cxn.sessManager.trustedPool.sessMap[sess.OrchestratorInfo.GetTranscoder()]
 	assert.False(ok)
@@ -559,7 +595,7 @@ func TestTranscodeSegment_RefreshSession(t *testing.T) {
 
 	// Validate TicketParams error (not ErrTicketParamsExpired) -> Don't refresh, remove session & suspend orch
 	sender.On("ValidateTicketParams", mock.Anything).Return(errors.New("some error")).Once()
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil, nil)
 	assert.True(strings.Contains(err.Error(), "some error"))
 	_, ok := cxn.sessManager.trustedPool.sessMap[ts.URL]
 	assert.False(ok)
@@ -574,7 +610,7 @@ func TestTranscodeSegment_RefreshSession(t *testing.T) {
 	}
 	// Expired ticket params -> GetOrchestratorInfo error -> Error
 	sender.On("ValidateTicketParams", mock.Anything).Return(pm.ErrTicketParamsExpired)
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil, nil)
 	assert.True(strings.Contains(err.Error(), "Could not get orchestrator"))
 	_, ok = cxn.sessManager.trustedPool.sessMap[ts.URL]
 	assert.False(ok)
@@ -602,7 +638,7 @@ func TestTranscodeSegment_RefreshSession(t *testing.T) {
 	oldGetOrchestratorInfoRPC := getOrchestratorInfoRPC
 	defer func() { getOrchestratorInfoRPC = oldGetOrchestratorInfoRPC }()
 
-	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		return successOrchInfoUpdate, nil
 	}
 
@@ -611,7 +647,7 @@ func TestTranscodeSegment_RefreshSession(t *testing.T) {
 	balance.On("StageUpdate", mock.Anything, mock.Anything).Return(1, big.NewRat(100, 1), big.NewRat(100, 1))
 	sender.On("CreateTicketBatch", mock.Anything, mock.Anything).Return(nil, pm.ErrTicketParamsExpired).Once()
 	balance.On("Credit", mock.Anything)
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil, nil)
 	assert.EqualError(err, pm.ErrTicketParamsExpired.Error())
 	_, ok = cxn.sessManager.trustedPool.sessMap[ts.URL]
 	assert.False(ok)
@@ -628,7 +664,7 @@ func TestTranscodeSegment_RefreshSession(t *testing.T) {
 
 	sender.On("ValidateTicketParams", mock.Anything).Return(nil)
 	sender.On("CreateTicketBatch", mock.Anything, mock.Anything).Return(defaultTicketBatch(), nil)
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil, nil)
 	assert.Nil(err)
 
 	completedSess := cxn.sessManager.trustedPool.sessMap[ts.URL]
@@ -645,13 +681,13 @@ func TestTranscodeSegment_RefreshSession(t *testing.T) {
 	// Missing auth token
 	sess.OrchestratorInfo.AuthToken = nil
 	cxn.sessManager = bsmWithSessList([]*BroadcastSession{sess})
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{}, "dummy", nil, nil, nil)
 	assert.Equal("missing auth token", err.Error())
 
 	// Refresh session for expired auth token
 	sess.OrchestratorInfo.AuthToken = &net.AuthToken{Token: []byte("foo"), SessionId: "bar", Expiration: time.Now().Add(-1 * time.Hour).Unix()}
 	cxn.sessManager = bsmWithSessList([]*BroadcastSession{sess})
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{}, "dummy", nil, nil, nil)
 	assert.Nil(err)
 
 	completedSessInfo = cxn.sessManager.trustedPool.sessMap[tr.Info.Transcoder].OrchestratorInfo
@@ -661,7 +697,7 @@ func TestTranscodeSegment_RefreshSession(t *testing.T) {
 	// Refresh session for almost expired auth token
 	sess.OrchestratorInfo.AuthToken = &net.AuthToken{Token: []byte("foo"), SessionId: "bar", Expiration: time.Now().Add(30 * time.Second).Unix()}
 	cxn.sessManager = bsmWithSessList([]*BroadcastSession{sess})
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{}, "dummy", nil, nil, nil)
 	assert.Nil(err)
 
 	completedSessInfo = cxn.sessManager.trustedPool.sessMap[tr.Info.Transcoder].OrchestratorInfo
@@ -709,12 +745,45 @@ func TestTranscodeSegment_SuspendOrchestrator(t *testing.T) {
 		sessManager: bsm,
 	}
 
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil, nil)
 
 	assert.EqualError(err, "OrchestratorBusy")
 	assert.Equal(bsm.trustedPool.sus.Suspended(ts.URL), bsm.trustedPool.poolSize/bsm.trustedPool.numOrchs)
 }
 
+func TestTranscodeSegment_SuspendOrchestratorUntilRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	ts, mux := stubTLSServer()
+	defer ts.Close()
+	mux.HandleFunc("/segment", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(retryAfterKey, "30")
+		http.Error(w, core.ErrOrchCap.Error(), http.StatusForbidden)
+	})
+
+	sess := StubBroadcastSession(ts.URL)
+	sess.Params.Profiles = []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}
+	bsm := bsmWithSessList([]*BroadcastSession{sess})
+	cxn := &rtmpConnection{
+		mid:         core.ManifestID("foo"),
+		nonce:       7,
+		pl:          &stubPlaylistManager{manifestID: core.ManifestID("foo")},
+		profile:     &ffmpeg.P144p30fps16x9,
+		sessManager: bsm,
+	}
+
+	_, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil, nil)
+	assert.EqualError(err, core.ErrOrchCap.Error())
+
+	// the orchestrator stays suspended after the refreshes that would have ended its suspension
+	for i := 0; i < 10; i++ {
+		bsm.trustedPool.sus.signalRefresh()
+	}
+	assert.Greater(bsm.trustedPool.sus.Suspended(ts.URL), 0)
+	until := bsm.trustedPool.sus.until[ts.URL]
+	assert.WithinDuration(time.Now().Add(30*time.Second), until, 5*time.Second)
+}
+
 func TestTranscodeSegment_CompleteSession(t *testing.T) {
 	require := require.New(t)
 	assert := assert.New(t)
@@ -761,7 +830,7 @@ func TestTranscodeSegment_CompleteSession(t *testing.T) {
 	// The seg duration that is worse than the threshold (i.e "slow")
 	segDurSlowLatencyScore := segDurLatencyScoreThreshold / segDurMultiplier
 
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: segDurSlowLatencyScore}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: segDurSlowLatencyScore}, "dummy", nil, nil, nil)
 	assert.Nil(err)
 
 	completedSess := bsm.trustedPool.sessMap[ts.URL]
@@ -776,7 +845,7 @@ func TestTranscodeSegment_CompleteSession(t *testing.T) {
 	buf, err = proto.Marshal(tr)
 	require.Nil(err)
 
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: segDurFastLatencyScore}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: segDurFastLatencyScore}, "dummy", nil, nil, nil)
 	assert.Nil(err)
 
 	// Check that BroadcastSession.OrchestratorInfo was updated
@@ -788,7 +857,7 @@ func TestTranscodeSegment_CompleteSession(t *testing.T) {
 	assert.Zero(bsm.trustedPool.sel.Size())
 
 	// Check that we can re-use the last session that previously passed the latency score threshold check
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: segDurFastLatencyScore}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: segDurFastLatencyScore}, "dummy", nil, nil, nil)
 	assert.Nil(err)
 	completedSessInfo = bsm.trustedPool.sessMap[ts.URL].OrchestratorInfo
 	assert.Equal(sess.OrchestratorInfo.Transcoder, completedSessInfo.Transcoder)
@@ -860,6 +929,45 @@ func TestProcessSegment_MaxAttempts(t *testing.T) {
 	assert.Len(bsm.trustedPool.sessMap, 0)
 }
 
+func TestProcessSegment_RetryPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	var transcodeCalls int
+	var callTimes []time.Time
+	resp := func(w http.ResponseWriter, r *http.Request) {
+		transcodeCalls++
+		callTimes = append(callTimes, time.Now())
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("OrchestratorBusy"))
+	}
+	var sessions []*BroadcastSession
+	for i := 0; i < 4; i++ {
+		ts, mux := stubTLSServer()
+		defer ts.Close()
+		mux.HandleFunc("/segment", resp)
+		sessions = append(sessions, StubBroadcastSession(ts.URL))
+	}
+	bsm := bsmWithSessListExt(sessions, nil, true)
+	policy := RetryPolicy
+	policy.MaxAttempts = 10
+	policy.RefusalRetries = 2
+	policy.Backoff = 50 * time.Millisecond
+	cxn := &rtmpConnection{
+		profile:     &ffmpeg.VideoProfile{Name: "unused"},
+		sessManager: bsm,
+		pl:          &stubPlaylistManager{os: &stubOSSession{}},
+		params:      &core.StreamParameters{RetryPolicy: &policy},
+	}
+
+	// Refusals are retried after a backoff until the refusal retries are exhausted
+	_, err := processSegment(context.Background(), cxn, &stream.HLSSegment{}, nil)
+	assert.EqualError(err, "Hit max refusal retries: OrchestratorBusy")
+	assert.True(isNonRetryableError(err))
+	assert.Equal(3, transcodeCalls)
+	assert.GreaterOrEqual(int64(callTimes[1].Sub(callTimes[0])), int64(25*time.Millisecond))
+	assert.GreaterOrEqual(int64(callTimes[2].Sub(callTimes[1])), int64(50*time.Millisecond))
+}
+
 type queueEvent struct {
 	key  string
 	data interface{}
@@ -949,6 +1057,8 @@ func TestProcessSegment_MetadataQueueTranscodeEvent(t *testing.T) {
 	assert.Equal(seg.SeqNo, transEvt.Segment.SeqNo)
 	assert.Equal(1, len(transEvt.Attempts))
 	assert.Nil(transEvt.Attempts[0].Error)
+	_, err = broker.ValidateEvent(transEvt)
+	assert.Nil(err)
 
 	require.Equal(0, len(transcodeResps))
 	// One failed transcode attempt. Failed attempt should be in transcode event
@@ -1075,7 +1185,7 @@ func TestTranscodeSegment_VerifyPixels(t *testing.T) {
 		sessManager: bsm,
 	}
 
-	urls, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy")}, "dummy", nil, nil)
+	urls, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy")}, "dummy", nil, nil, nil)
 	assert.Nil(err)
 	assert.NotNil(urls)
 	assert.Len(urls, 1)
@@ -1096,7 +1206,7 @@ func TestTranscodeSegment_VerifyPixels(t *testing.T) {
 
 	sender.On("ValidateTicketParams", mock.Anything).Return(nil)
 
-	urls, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy")}, "dummy", nil, nil)
+	urls, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy")}, "dummy", nil, nil, nil)
 	assert.Nil(err)
 	assert.Equal("test.flv", urls[0])
 
@@ -1106,7 +1216,7 @@ func TestTranscodeSegment_VerifyPixels(t *testing.T) {
 	bsm = bsmWithSessList([]*BroadcastSession{sess})
 	cxn.sessManager = bsm
 
-	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy")}, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy")}, "dummy", nil, nil, nil)
 	assert.Nil(err)
 
 	// Wait for async pixels verification to finish
@@ -1210,7 +1320,7 @@ func TestHLSInsertion(t *testing.T) {
 	}
 
 	seg := &stream.HLSSegment{SeqNo: 93}
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil, nil)
 	assert.Nil(err)
 
 	// some sanity checks
@@ -1262,29 +1372,29 @@ func TestVerifier_Invocation(t *testing.T) {
 	}
 
 	seg := &stream.HLSSegment{}
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", segmentVerifier, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", segmentVerifier, nil, nil)
 	assert.Nil(err)
 	assert.Equal(1, verifier.calls)
 	require.NotNil(verifier.params)
 	assert.Equal(cxn.mid, verifier.params.ManifestID)
 	assert.Equal(seg, verifier.params.Source)
 	// Do it again for good measure
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", segmentVerifier, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", segmentVerifier, nil, nil)
 	assert.Nil(err)
 	assert.Equal(2, verifier.calls)
 
 	// now "disable" the verifier and ensure no calls
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil, nil)
 	assert.Nil(err)
 	assert.Equal(2, verifier.calls)
 
 	// Pass in a nil policy
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verification.NewSegmentVerifier(nil), nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verification.NewSegmentVerifier(nil), nil, nil)
 	assert.Nil(err)
 
 	// Pass in a policy but no verifier specified
 	policy = &verification.Policy{}
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verification.NewSegmentVerifier(policy), nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verification.NewSegmentVerifier(policy), nil, nil)
 	assert.Nil(err)
 }
 
@@ -1424,15 +1534,15 @@ func TestVerifier_HLSInsertion(t *testing.T) {
 	defer func() { downloadSeg = oldDownloadSeg }()
 	downloadSeg = func(ctx context.Context, url string) ([]byte, error) { return []byte("foo"), nil }
 
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil, nil)
 	assert.Equal(verification.ErrTampered, err)
 	assert.Empty(pl.uri) // sanity check that no insertion happened
 
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil, nil)
 	assert.Equal(verification.ErrTampered, err)
 	assert.Empty(pl.uri)
 
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil, nil)
 	assert.Nil(err)
 	assert.Equal(baseURL+"/resp2", pl.uri)
 }
@@ -1472,7 +1582,7 @@ func TestDownloadSegError_SuspendAndRemove(t *testing.T) {
 	oldDownloadSeg := downloadSeg
 	defer func() { downloadSeg = oldDownloadSeg }()
 	downloadSeg = func(ctx context.Context, url string) ([]byte, error) { return nil, errors.New("some error") }
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil, nil)
 	assert.EqualError(err, "some error")
 	_, ok := cxn.sessManager.trustedPool.sessMap[sess.OrchestratorInfo.GetTranscoder()]
 	assert.False(ok)
@@ -1500,7 +1610,7 @@ func TestRefreshSession(t *testing.T) {
 	assert.Contains(err.Error(), "invalid control character in URL")
 
 	// trigger getOrchestratorInfo error
-	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		return nil, errors.New("some error")
 	}
 	sess = StubBroadcastSession("foo")
@@ -1508,7 +1618,7 @@ func TestRefreshSession(t *testing.T) {
 	assert.EqualError(err, "some error")
 
 	// trigger update
-	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		return successOrchInfoUpdate, nil
 	}
 	err = refreshSession(context.TODO(), sess)
@@ -1519,7 +1629,7 @@ func TestRefreshSession(t *testing.T) {
 	oldRefreshTimeout := refreshTimeout
 	defer func() { refreshTimeout = oldRefreshTimeout }()
 	refreshTimeout = 10 * time.Millisecond
-	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, serv *url.URL) (*net.OrchestratorInfo, error) {
+	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, serv *url.URL, params GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 		// Wait until the refreshTimeout has elapsed
 		select {
 		case <-ctx.Done():
@@ -1570,21 +1680,21 @@ func TestVerifier_SegDownload(t *testing.T) {
 	// When there is no broadcaster OS, segments should not be downloaded
 	url := "somewhere1"
 	cxn.sessManager = bsmWithSessList([]*BroadcastSession{genBcastSess(ctx, t, url, nil, mid)})
-	_, _, err := transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil)
+	_, _, err := transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil, nil)
 	assert.Nil(err)
 	assert.False(downloaded[url])
 
 	// When segments are in the broadcaster's external OS, segments should not be downloaded
 	url = "https://livepeer.s3.amazonaws.com/resp1"
 	cxn.sessManager = bsmWithSessList([]*BroadcastSession{genBcastSess(ctx, t, url, externalOS, mid)})
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil, nil)
 	assert.Nil(err)
 	assert.False(downloaded[url])
 
 	// When segments are not in the broadcaster's external OS, segments should be downloaded
 	url = "somewhere2"
 	cxn.sessManager = bsmWithSessList([]*BroadcastSession{genBcastSess(ctx, t, url, externalOS, mid)})
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil, nil)
 	assert.Nil(err)
 	assert.True(downloaded[url])
 
@@ -1597,21 +1707,21 @@ func TestVerifier_SegDownload(t *testing.T) {
 	// When there is no broadcaster OS, segments should be downloaded
 	url = "somewhere3"
 	cxn.sessManager = bsmWithSessList([]*BroadcastSession{genBcastSess(ctx, t, url, nil, mid)})
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil, nil)
 	assert.Nil(err)
 	assert.True(downloaded[url])
 
 	// When segments are in the broadcaster's external OS, segments should be downloaded
 	url = "https://livepeer.s3.amazonaws.com/resp2"
 	cxn.sessManager = bsmWithSessList([]*BroadcastSession{genBcastSess(ctx, t, url, externalOS, mid)})
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil, nil)
 	assert.Nil(err)
 	assert.True(downloaded[url])
 
 	// When segments are not in the broadcaster's exernal OS, segments should be downloaded
 	url = "somewhere4"
 	cxn.sessManager = bsmWithSessList([]*BroadcastSession{genBcastSess(ctx, t, url, externalOS, mid)})
-	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil)
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verifier, nil, nil)
 	assert.Nil(err)
 	assert.True(downloaded[url])
 }
@@ -1818,3 +1928,221 @@ func TestVerifcationRunsBasedOnVerificationFrequency(t *testing.T) {
 	require.Greater(t, shouldRunCount, 1000)
 	require.Less(t, shouldRunCount, 3000)
 }
+
+func TestSelectSessions_Redundant(t *testing.T) {
+	assert := assert.New(t)
+
+	sess1 := StubBroadcastSession("transcoder1")
+	sess2 := StubBroadcastSession("transcoder2")
+	bsm := bsmWithSessListExt([]*BroadcastSession{sess1, sess2}, nil, true)
+
+	// One session when not redundant
+	sessions, calcPerceptualHash, verified := bsm.selectSessions(context.TODO())
+	assert.Len(sessions, 1)
+	assert.False(calcPerceptualHash)
+	assert.False(verified)
+	bsm.completeSession(context.TODO(), sessions[0], false)
+
+	// Two sessions when redundant
+	bsm.redundant = true
+	assert.True(bsm.isRedundant())
+	sessions, calcPerceptualHash, verified = bsm.selectSessions(context.TODO())
+	assert.Len(sessions, 2)
+	assert.ElementsMatch([]*BroadcastSession{sess1, sess2}, sessions)
+	assert.False(calcPerceptualHash)
+	assert.False(verified)
+
+	// Verification takes precedence
+	bsm.VerificationFreq = 1
+	assert.False(bsm.isRedundant())
+}
+
+func TestSelectSessions_Split(t *testing.T) {
+	assert := assert.New(t)
+
+	sess1 := StubBroadcastSession("transcoder1")
+	sess2 := StubBroadcastSession("transcoder2")
+	bsm := bsmWithSessListExt([]*BroadcastSession{sess1, sess2}, nil, true)
+
+	// Two sessions when splitting renditions
+	bsm.split = true
+	assert.True(bsm.isSplit())
+	sessions, calcPerceptualHash, verified := bsm.selectSessions(context.TODO())
+	assert.Len(sessions, 2)
+	assert.ElementsMatch([]*BroadcastSession{sess1, sess2}, sessions)
+	assert.False(calcPerceptualHash)
+	assert.False(verified)
+
+	// Redundant transcoding and verification take precedence
+	bsm.redundant = true
+	assert.False(bsm.isSplit())
+	bsm.redundant = false
+	bsm.VerificationFreq = 1
+	assert.False(bsm.isSplit())
+}
+
+func TestChooseFirstResult(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	sess1 := StubBroadcastSession("transcoder1")
+	sess2 := StubBroadcastSession("transcoder2")
+	sess3 := StubBroadcastSession("transcoder3")
+	bsm := bsmWithSessListExt([]*BroadcastSession{sess1, sess2, sess3}, nil, true)
+	bsm.redundant = true
+
+	res := func(pixels ...int64) *ReceivedTranscodeResult {
+		td := &net.TranscodeData{}
+		for _, p := range pixels {
+			td.Segments = append(td.Segments, &net.TranscodedSegmentData{Pixels: p})
+		}
+		return &ReceivedTranscodeResult{TranscodeData: td}
+	}
+
+	// Failed result is skipped and its orchestrator suspended
+	resChan := make(chan *SubmitResult, 2)
+	resChan <- &SubmitResult{Session: sess1, Err: errors.New("some error")}
+	resChan <- &SubmitResult{Session: sess2, TranscodeResult: res(100)}
+	sess, result, err := bsm.chooseResults(context.TODO(), nil, resChan, 2)
+	require.NoError(err)
+	assert.Equal(sess2, sess)
+	assert.Equal(int64(100), result.Segments[0].Pixels)
+	assert.NotContains(bsm.trustedPool.sessMap, "transcoder1")
+
+	// First valid result wins without waiting for the other one
+	resChan = make(chan *SubmitResult, 2)
+	resChan <- &SubmitResult{Session: sess3, TranscodeResult: res(200)}
+	sess, result, err = bsm.chooseResults(context.TODO(), nil, resChan, 2)
+	require.NoError(err)
+	assert.Equal(sess3, sess)
+	assert.Equal(int64(200), result.Segments[0].Pixels)
+
+	// The late result is consumed in the background
+	resChan <- &SubmitResult{Session: sess2, TranscodeResult: res(200)}
+	assert.Eventually(func() bool { return len(resChan) == 0 }, time.Second, 10*time.Millisecond)
+
+	// No valid results at all
+	resChan = make(chan *SubmitResult, 2)
+	resChan <- &SubmitResult{Session: sess2, Err: errors.New("some error")}
+	resChan <- &SubmitResult{Session: sess3, Err: errors.New("other error")}
+	_, _, err = bsm.chooseResults(context.TODO(), nil, resChan, 2)
+	assert.EqualError(err, "error transcoding: no results at all err=other error")
+	assert.Empty(bsm.trustedPool.sessMap)
+}
+
+func TestCompareRedundantResults(t *testing.T) {
+	assert := assert.New(t)
+
+	result := func(transcoder string, pixels ...int64) *SubmitResult {
+		td := &net.TranscodeData{}
+		for _, p := range pixels {
+			td.Segments = append(td.Segments, &net.TranscodedSegmentData{Pixels: p})
+		}
+		return &SubmitResult{Session: StubBroadcastSession(transcoder), TranscodeResult: &ReceivedTranscodeResult{TranscodeData: td}}
+	}
+
+	assert.True(compareRedundantResults(context.TODO(), result("a", 1, 2), result("b", 1, 2)))
+	assert.False(compareRedundantResults(context.TODO(), result("a", 1, 2), result("b", 1, 3)))
+	assert.False(compareRedundantResults(context.TODO(), result("a", 1, 2), result("b", 1)))
+}
+
+func TestSelectOrchestrator_PreferredOrchestrators(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	caps := core.NewCapabilities(core.DefaultCapabilities(), nil)
+	oldGetOrchestratorInfoRPC := getOrchestratorInfoRPC
+	defer func() { getOrchestratorInfoRPC = oldGetOrchestratorInfoRPC }()
+	getOrchestratorInfoRPC = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		if orchestratorServer.Host == "unreachable:8935" {
+			return nil, errors.New("some error")
+		}
+		return &net.OrchestratorInfo{
+			Transcoder:   orchestratorServer.String(),
+			AuthToken:    stubAuthToken,
+			Capabilities: caps.ToNetCapabilities(),
+		}, nil
+	}
+
+	uris, err := parsePreferredOrchestrators([]string{"unreachable:8935", "https://second:8935", "https://third:8935"})
+	require.Nil(err)
+	params := &core.StreamParameters{
+		ManifestID:             core.RandomManifestID(),
+		OS:                     drivers.NewMemoryDriver(nil).NewSession(""),
+		Capabilities:           caps,
+		PreferredOrchestrators: uris,
+	}
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	n.OrchestratorPool = &stubDiscovery{infos: []*net.OrchestratorInfo{{Transcoder: "https://pool:8935", AuthToken: stubAuthToken}}}
+
+	transcoders := func(sessions []*BroadcastSession) []string {
+		var res []string
+		for _, sess := range sessions {
+			res = append(res, fmt.Sprintf("%v/%v", sess.Transcoder(), sess.PreferenceRank))
+		}
+		return res
+	}
+
+	// preferred orchestrators come first, in order, followed by the pool
+	sessions, err := selectOrchestrator(context.TODO(), n, params, 3, newSuspender(), common.ScoreAtLeast(common.Score_Trusted))
+	require.Nil(err)
+	assert.Equal([]string{"https://second:8935/1", "https://third:8935/2", "https://pool:8935/0"}, transcoders(sessions))
+
+	// only as many as requested
+	sessions, err = selectOrchestrator(context.TODO(), n, params, 1, newSuspender(), common.ScoreAtLeast(common.Score_Trusted))
+	require.Nil(err)
+	assert.Equal([]string{"https://second:8935/1"}, transcoders(sessions))
+
+	// orchestrators that are both preferred and in the pool are only used once, as preferred orchestrators
+	pool := n.OrchestratorPool
+	n.OrchestratorPool = &stubDiscovery{infos: []*net.OrchestratorInfo{
+		{Transcoder: "https://third:8935", AuthToken: stubAuthToken},
+		{Transcoder: "https://pool:8935", AuthToken: stubAuthToken},
+		{Transcoder: "https://other:8935", AuthToken: stubAuthToken},
+	}}
+	sessions, err = selectOrchestrator(context.TODO(), n, params, 3, newSuspender(), common.ScoreAtLeast(common.Score_Trusted))
+	require.Nil(err)
+	assert.Equal([]string{"https://second:8935/1", "https://third:8935/2", "https://pool:8935/0"}, transcoders(sessions))
+	n.OrchestratorPool = pool
+
+	// preferred orchestrators with the same transcoder URL are only used once
+	dupURIs, err := parsePreferredOrchestrators([]string{"https://second:8935", "https://second:8935", "https://third:8935"})
+	require.Nil(err)
+	params.PreferredOrchestrators = dupURIs
+	sessions, err = selectOrchestrator(context.TODO(), n, params, 3, newSuspender(), common.ScoreAtLeast(common.Score_Trusted))
+	require.Nil(err)
+	assert.Equal([]string{"https://second:8935/1", "https://third:8935/2", "https://pool:8935/0"}, transcoders(sessions))
+	params.PreferredOrchestrators = uris
+
+	// suspended preferred orchestrators are skipped
+	sus := newSuspender()
+	sus.suspend("https://second:8935", 5)
+	sessions, err = selectOrchestrator(context.TODO(), n, params, 2, sus, common.ScoreAtLeast(common.Score_Trusted))
+	require.Nil(err)
+	assert.Equal([]string{"https://third:8935/1", "https://pool:8935/0"}, transcoders(sessions))
+
+	// preferred orchestrators are not used for the untrusted pool
+	sessions, err = selectOrchestrator(context.TODO(), n, params, 3, newSuspender(), common.ScoreEqualTo(common.Score_Untrusted))
+	require.Nil(err)
+	assert.Equal([]string{"https://pool:8935/0"}, transcoders(sessions))
+
+	// preferred orchestrators that are filtered out are skipped
+	OrchFilter, err = NewOrchestratorFilter(nil, []string{"second:8935"})
+	require.Nil(err)
+	defer func() { OrchFilter = nil }()
+	sessions, err = selectOrchestrator(context.TODO(), n, params, 3, newSuspender(), common.ScoreAtLeast(common.Score_Trusted))
+	require.Nil(err)
+	assert.Equal([]string{"https://third:8935/1", "https://pool:8935/0"}, transcoders(sessions))
+	OrchFilter = nil
+
+	// preferred orchestrators can be used without a pool
+	n.OrchestratorPool = nil
+	sessions, err = selectOrchestrator(context.TODO(), n, params, 3, newSuspender(), common.ScoreAtLeast(common.Score_Trusted))
+	require.Nil(err)
+	assert.Equal([]string{"https://second:8935/1", "https://third:8935/2"}, transcoders(sessions))
+
+	// incompatible preferred orchestrators are skipped
+	params.Capabilities = core.NewCapabilities(append(core.DefaultCapabilities(), core.Capability_H264_Decode_444_8bit), nil)
+	_, err = selectOrchestrator(context.TODO(), n, params, 3, newSuspender(), common.ScoreAtLeast(common.Score_Trusted))
+	assert.Equal(errDiscovery, err)
+}
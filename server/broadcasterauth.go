@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+const broadcasterAuthHeader = "Livepeer-Broadcaster-Auth"
+
+// gRPC metadata keys are lower case
+const broadcasterAuthKey = "livepeer-broadcaster-auth"
+
+// Maximum difference between the clocks of the broadcaster and the orchestrator for an auth token to be accepted
+const broadcasterAuthMaxSkew = 5 * time.Minute
+
+var errBroadcasterAuth = errors.New("broadcaster authentication failed")
+
+// BroadcasterSecret, if set, is a secret shared between broadcasters and orchestrators. Broadcasters
+// authenticate their requests with it and orchestrators reject requests that are not authenticated with it.
+var BroadcasterSecret string
+
+// genBroadcasterAuth returns a token of the form <unix time>.<HMAC-SHA256 of the time and scope keyed with secret>.
+// The scope identifies the request the token is generated for so that a captured token can't be replayed
+// against other requests: the full method name for gRPC requests and segmentAuthScope for segments.
+func genBroadcasterAuth(secret, scope string, now time.Time) string {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	return ts + "." + broadcasterAuthMAC(secret, ts, scope)
+}
+
+func broadcasterAuthMAC(secret, ts, scope string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(scope))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// segmentAuthScope returns the auth scope of a segment request. The segment credentials are signed by the
+// broadcaster and carry the manifest ID and sequence number, so a token is only valid for that segment.
+func segmentAuthScope(segCreds string) string {
+	return "/segment\n" + segCreds
+}
+
+// httpAuthScope returns the auth scope of an HTTP message, so that a token is only valid for that message and body
+func httpAuthScope(message string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return message + "\n" + hex.EncodeToString(sum[:])
+}
+
+// SetBroadcasterAuth adds an auth token for an HTTP message with body to header, if a BroadcasterSecret is set.
+// The message identifies what is sent, e.g. the method and path of a request.
+func SetBroadcasterAuth(header http.Header, message string, body []byte) {
+	if BroadcasterSecret == "" {
+		return
+	}
+	header.Set(broadcasterAuthHeader, genBroadcasterAuth(BroadcasterSecret, httpAuthScope(message, body), time.Now()))
+}
+
+// VerifyBroadcasterAuth returns an error unless no BroadcasterSecret is set or header has an auth token for an HTTP
+// message with body, as added by SetBroadcasterAuth
+func VerifyBroadcasterAuth(header http.Header, message string, body []byte) error {
+	return verifyBroadcasterAuth(BroadcasterSecret, httpAuthScope(message, body), header.Get(broadcasterAuthHeader), time.Now())
+}
+
+// verifyBroadcasterAuth returns an error unless secret is empty or token was generated with secret for scope recently
+func verifyBroadcasterAuth(secret, scope, token string, now time.Time) error {
+	if secret == "" {
+		return nil
+	}
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return errBroadcasterAuth
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return errBroadcasterAuth
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > broadcasterAuthMaxSkew || skew < -broadcasterAuthMaxSkew {
+		return errBroadcasterAuth
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(broadcasterAuthMAC(secret, parts[0], scope))) {
+		return errBroadcasterAuth
+	}
+	return nil
+}
+
+// verifyBroadcasterAuthRPC checks the auth token in the metadata of an incoming gRPC request
+func verifyBroadcasterAuthRPC(ctx context.Context) error {
+	if BroadcasterSecret == "" {
+		return nil
+	}
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(broadcasterAuthKey); len(vals) > 0 {
+			token = vals[0]
+		}
+	}
+	method, _ := grpc.Method(ctx)
+	return verifyBroadcasterAuth(BroadcasterSecret, method, token, time.Now())
+}
+
+// broadcasterAuthCreds adds an auth token to outgoing gRPC requests
+type broadcasterAuthCreds struct {
+	secret string
+}
+
+func (c broadcasterAuthCreds) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	var method string
+	if ri, ok := credentials.RequestInfoFromContext(ctx); ok {
+		method = ri.Method
+	}
+	return map[string]string{broadcasterAuthKey: genBroadcasterAuth(c.secret, method, time.Now())}, nil
+}
+
+func (c broadcasterAuthCreds) RequireTransportSecurity() bool {
+	return true
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/lpms/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestBroadcasterAuth_Verify(t *testing.T) {
+	assert := assert.New(t)
+
+	// tokens have a resolution of a second
+	now := time.Unix(time.Now().Unix(), 0)
+	token := genBroadcasterAuth("foo", "scope", now)
+	assert.Nil(verifyBroadcasterAuth("foo", "scope", token, now))
+	assert.Nil(verifyBroadcasterAuth("foo", "scope", token, now.Add(broadcasterAuthMaxSkew)))
+	assert.Nil(verifyBroadcasterAuth("foo", "scope", token, now.Add(-broadcasterAuthMaxSkew)))
+
+	// no secret, no auth
+	assert.Nil(verifyBroadcasterAuth("", "scope", "", now))
+
+	// wrong secret
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuth("bar", "scope", token, now))
+
+	// tokens can't be replayed for another scope
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuth("foo", "other", token, now))
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuth("foo", "", token, now))
+
+	// expired or from the future
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuth("foo", "scope", token, now.Add(broadcasterAuthMaxSkew+time.Second)))
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuth("foo", "scope", token, now.Add(-broadcasterAuthMaxSkew-time.Second)))
+
+	// malformed or tampered tokens
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuth("foo", "scope", "", now))
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuth("foo", "scope", "abc."+broadcasterAuthMAC("foo", "abc", "scope"), now))
+	ts := strconv.FormatInt(now.Unix(), 10)
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuth("foo", "scope", ts+"."+broadcasterAuthMAC("foo", ts+"1", "scope"), now))
+}
+
+func TestBroadcasterAuth_HTTP(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { BroadcasterSecret = "" }()
+
+	// no secret, no auth
+	h := http.Header{}
+	SetBroadcasterAuth(h, "POST /foo", []byte("body"))
+	assert.Empty(h.Get(broadcasterAuthHeader))
+	assert.Nil(VerifyBroadcasterAuth(h, "POST /foo", []byte("body")))
+
+	BroadcasterSecret = "secret"
+	assert.Equal(errBroadcasterAuth, VerifyBroadcasterAuth(h, "POST /foo", []byte("body")))
+	SetBroadcasterAuth(h, "POST /foo", []byte("body"))
+	assert.NotEmpty(h.Get(broadcasterAuthHeader))
+	assert.Nil(VerifyBroadcasterAuth(h, "POST /foo", []byte("body")))
+
+	// tokens are only valid for the message and body they were added for
+	assert.Equal(errBroadcasterAuth, VerifyBroadcasterAuth(h, "POST /bar", []byte("body")))
+	assert.Equal(errBroadcasterAuth, VerifyBroadcasterAuth(h, "POST /foo", []byte("other")))
+
+	BroadcasterSecret = "other"
+	assert.Equal(errBroadcasterAuth, VerifyBroadcasterAuth(h, "POST /foo", []byte("body")))
+}
+
+func TestBroadcasterAuth_RPC(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func() { BroadcasterSecret = "" }()
+	BroadcasterSecret = "foo"
+
+	// requests without a token are rejected before anything else is checked
+	lp := &lphttp{orchestrator: &mockOrchestrator{}}
+	_, err := lp.GetOrchestrator(context.Background(), &net.OrchestratorRequest{})
+	assert.Equal(errBroadcasterAuth, err)
+	_, err = lp.EndTranscodingSession(context.Background(), &net.EndTranscodingSessionRequest{})
+	assert.Equal(errBroadcasterAuth, err)
+
+	// tokens are only accepted for the method they were generated for
+	getOrch := "/net.Orchestrator/GetOrchestrator"
+	incoming := func(md map[string]string, method string) context.Context {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.New(md))
+		return grpc.NewContextWithServerTransportStream(ctx, &stubServerTransportStream{method: method})
+	}
+	md := map[string]string{broadcasterAuthKey: genBroadcasterAuth("foo", getOrch, time.Now())}
+	assert.Nil(verifyBroadcasterAuthRPC(incoming(md, getOrch)))
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuthRPC(incoming(md, "/net.Orchestrator/EndTranscodingSession")))
+
+	md = map[string]string{broadcasterAuthKey: genBroadcasterAuth("bar", getOrch, time.Now())}
+	assert.Equal(errBroadcasterAuth, verifyBroadcasterAuthRPC(incoming(md, getOrch)))
+
+	// no secret, no auth
+	BroadcasterSecret = ""
+	assert.Nil(verifyBroadcasterAuthRPC(context.Background()))
+}
+
+func TestBroadcasterAuth_ServeSegment(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func() { BroadcasterSecret = "" }()
+	BroadcasterSecret = "foo"
+	handler := serveSegmentHandler(&mockOrchestrator{})
+
+	resp := httpPostResp(handler, nil, map[string]string{paymentHeader: "foo"})
+	resp.Body.Close()
+	assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	scope := segmentAuthScope("creds")
+	resp = httpPostResp(handler, nil, map[string]string{paymentHeader: "foo", segmentHeader: "creds", broadcasterAuthHeader: genBroadcasterAuth("bar", scope, time.Now())})
+	resp.Body.Close()
+	assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	// tokens can't be replayed for another segment
+	resp = httpPostResp(handler, nil, map[string]string{paymentHeader: "foo", segmentHeader: "other", broadcasterAuthHeader: genBroadcasterAuth("foo", scope, time.Now())})
+	resp.Body.Close()
+	assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	// authenticated requests go on to the payment checks
+	resp = httpPostResp(handler, nil, map[string]string{paymentHeader: "foo", segmentHeader: "creds", broadcasterAuthHeader: genBroadcasterAuth("foo", scope, time.Now())})
+	resp.Body.Close()
+	assert.Equal(http.StatusPaymentRequired, resp.StatusCode)
+}
+
+func TestBroadcasterAuth_SubmitSegment(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func() { BroadcasterSecret = "" }()
+	ts, mux := stubTLSServer()
+	defer ts.Close()
+	var authErr error
+	mux.HandleFunc("/segment", func(w http.ResponseWriter, r *http.Request) {
+		authErr = verifyBroadcasterAuth("foo", segmentAuthScope(r.Header.Get(segmentHeader)), r.Header.Get(broadcasterAuthHeader), time.Now())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	BroadcasterSecret = "foo"
+	sess := StubBroadcastSession(ts.URL)
+	SubmitSegment(context.Background(), sess, &stream.HLSSegment{Data: []byte("dummy")}, nil, 0, false, true)
+	assert.Nil(authErr)
+
+	BroadcasterSecret = ""
+	SubmitSegment(context.Background(), sess, &stream.HLSSegment{Data: []byte("dummy")}, nil, 0, false, true)
+	assert.Equal(errBroadcasterAuth, authErr)
+}
+
+func TestBroadcasterAuth_Creds(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func() { BroadcasterSecret = "" }()
+	BroadcasterSecret = "foo"
+
+	// the orchestrator checks the token against the method the broadcaster called
+	var authErr, unscopedErr error
+	s := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		authErr = verifyBroadcasterAuthRPC(ctx)
+		md, _ := metadata.FromIncomingContext(ctx)
+		unscopedErr = verifyBroadcasterAuth("foo", "", md.Get(broadcasterAuthKey)[0], time.Now())
+		return &net.PingPong{}, nil
+	}))
+	net.RegisterOrchestratorServer(s, &lphttp{orchestrator: &mockOrchestrator{}})
+	ts := httptest.NewUnstartedServer(s)
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	uri, err := url.Parse(ts.URL)
+	require.Nil(err)
+	client, conn, err := startOrchestratorClient(context.Background(), uri)
+	require.Nil(err)
+	defer conn.Close()
+
+	_, err = client.Ping(context.Background(), &net.PingPong{})
+	assert.Nil(err)
+	assert.Nil(authErr)
+	assert.Equal(errBroadcasterAuth, unscopedErr)
+}
@@ -4,8 +4,10 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/pem"
+	"io/ioutil"
 	"math/big"
 	"net/url"
 	"os"
@@ -17,6 +19,10 @@ import (
 
 const certExpiry = 8765 * time.Hour // One year
 
+// KeepCert reuses the cert and private key in the data directory across restarts instead of
+// generating new ones, so that the cert fingerprint can be pinned by broadcasters
+var KeepCert bool
+
 func genCert(host string, priv *ecdsa.PrivateKey) ([]byte, error) {
 	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
 	glog.Info("Generating cert for ", host)
@@ -77,10 +83,9 @@ func getCert(uri *url.URL, workDir string) (string, string, error) {
 	keyFile := filepath.Join(workDir, "key.pem")
 	_, certErr := os.Stat(certFile)
 	_, keyErr := os.Stat(keyFile)
-	//if os.IsNotExist(certErr) || os.IsNotExist(keyErr) {
-	// XXX for now, just generate a new cert every time.
-	if true {
-		glog.Info("Private key and cert not found. Generating")
+	// Unless the cert is kept, generate a new cert every time
+	if !KeepCert || os.IsNotExist(certErr) || os.IsNotExist(keyErr) {
+		glog.Info("Generating private key and cert")
 		key, keyBytes, err := genKey()
 		if err != nil {
 			return "", "", err
@@ -105,5 +110,21 @@ func getCert(uri *url.URL, workDir string) (string, string, error) {
 		}
 		return "", "", err
 	}
+	logCertFingerprint(certFile)
 	return certFile, keyFile, nil
 }
+
+// logCertFingerprint logs the fingerprint of the cert so that it can be pinned by broadcasters
+func logCertFingerprint(certFile string) {
+	data, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		glog.Errorf("Unable to read cert %v: %v", certFile, err)
+		return
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		glog.Errorf("Unable to pem-decode %v", certFile)
+		return
+	}
+	glog.Infof("TLS cert fingerprint=%v", CertFingerprint(sha256.Sum256(block.Bytes)))
+}
@@ -67,8 +67,25 @@ func TestRPCCert(t *testing.T) {
 		return
 	}
 
+	// ensure that when invoking again, a new cert is returned unless certs are kept
+	cf, kf, err = getCert(url, wd)
+	if err != nil {
+		t.Error("Could not get cert/key ", err)
+		return
+	}
+	ch0, kh0, err := sha1sums(t, cf, kf)
+	if err != nil {
+		return
+	}
+	if bytes.Equal(kh, kh0) || bytes.Equal(ch, ch0) {
+		t.Error("Matched cert checksum")
+		return
+	}
+	KeepCert = true
+	defer func() { KeepCert = false }()
+	ch, kh = ch0, kh0
+
 	// ensure that when invoking again, the same cert is returned
-	/* XXX re-enable when we make certs persistent
 	cf, kf, err = getCert(url, wd)
 	if err != nil {
 		t.Error("Could not get cert/key ", err)
@@ -81,7 +98,7 @@ func TestRPCCert(t *testing.T) {
 	if !bytes.Equal(kh, kh1) || !bytes.Equal(ch, ch1) {
 		t.Error("Mismatched cert checksum")
 		return
-	}*/
+	}
 
 	// ensure that when a cert is missing, a new key/cert is generated
 	err = os.Remove(cf)
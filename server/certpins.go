@@ -0,0 +1,176 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	gonet "net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/livepeer/go-livepeer/core"
+)
+
+var errCertPinMismatch = errors.New("orchestrator certificate does not match pinned fingerprint")
+
+// OrchCertPins holds the expected TLS certificate fingerprints of orchestrators. Connections to a pinned
+// orchestrator fail unless it presents a matching certificate, other orchestrators may present any certificate.
+var OrchCertPins = NewCertPins()
+
+func init() {
+	// The renditions and the segments to verify are downloaded from the orchestrators, so check their pins too
+	core.SegmentTLSConfig = func(addr string) *tls.Config {
+		return OrchCertPins.tlsConfig(addr)
+	}
+}
+
+// CertFingerprint is the SHA-256 hash of a DER encoded certificate
+type CertFingerprint [sha256.Size]byte
+
+// CertPin is the fingerprint of a certificate that the orchestrator at URI is expected to present
+type CertPin struct {
+	URI         *url.URL
+	Fingerprint CertFingerprint
+}
+
+// CertPins maps orchestrators to the fingerprints of the certificates they may present.
+// Pins configured on the node take precedence over pins found through discovery.
+type CertPins struct {
+	mu         sync.RWMutex
+	configured map[string][]CertFingerprint
+	discovered map[string][]CertFingerprint
+}
+
+// NewCertPins creates an empty CertPins
+func NewCertPins() *CertPins {
+	return &CertPins{
+		configured: make(map[string][]CertFingerprint),
+		discovered: make(map[string][]CertFingerprint),
+	}
+}
+
+// ParseCertFingerprint parses a hex encoded SHA-256 fingerprint, optionally with colons as printed by
+// `openssl x509 -noout -fingerprint -sha256`
+func ParseCertFingerprint(s string) (CertFingerprint, error) {
+	var fp CertFingerprint
+	b, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(s), ":", ""))
+	if err != nil || len(b) != len(fp) {
+		return fp, fmt.Errorf("invalid SHA-256 certificate fingerprint: %v", s)
+	}
+	copy(fp[:], b)
+	return fp, nil
+}
+
+func (fp CertFingerprint) String() string {
+	return hex.EncodeToString(fp[:])
+}
+
+// ParseCertPins parses a list of pins of the form <service URI>=<fingerprint>.
+// If a service URI has no scheme, https is assumed.
+func ParseCertPins(entries []string) ([]CertPin, error) {
+	var pins []CertPin
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.LastIndex(entry, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid certificate pin, expected <service URI>=<fingerprint>: %v", entry)
+		}
+		addr := entry[:i]
+		if !strings.Contains(addr, "://") {
+			addr = "https://" + addr
+		}
+		uri, err := url.Parse(addr)
+		if err != nil || uri.Host == "" {
+			return nil, fmt.Errorf("invalid orchestrator service URI: %v", entry[:i])
+		}
+		fp, err := ParseCertFingerprint(entry[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		pins = append(pins, CertPin{URI: uri, Fingerprint: fp})
+	}
+	return pins, nil
+}
+
+// pinKey returns the host:port that the pins of an orchestrator apply to, the port defaulting to 443
+func pinKey(host string) string {
+	if _, _, err := gonet.SplitHostPort(host); err != nil {
+		return gonet.JoinHostPort(strings.Trim(host, "[]"), "443")
+	}
+	return host
+}
+
+func pinsByHost(pins []CertPin) map[string][]CertFingerprint {
+	m := make(map[string][]CertFingerprint)
+	for _, pin := range pins {
+		key := pinKey(pin.URI.Host)
+		m[key] = append(m[key], pin.Fingerprint)
+	}
+	return m
+}
+
+// Configure adds pins set by the node operator. An orchestrator may be pinned to several
+// fingerprints, e.g. while it rotates its certificate.
+func (p *CertPins) Configure(pins []CertPin) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, fps := range pinsByHost(pins) {
+		p.configured[key] = append(p.configured[key], fps...)
+	}
+}
+
+// SetDiscovered replaces the pins found through discovery
+func (p *CertPins) SetDiscovered(pins []CertPin) {
+	m := pinsByHost(pins)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.discovered = m
+}
+
+func (p *CertPins) pins(host string) []CertFingerprint {
+	key := pinKey(host)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if fps, ok := p.configured[key]; ok {
+		return fps
+	}
+	return p.discovered[key]
+}
+
+// verify checks that the certificate presented by the orchestrator at host matches one of its pins, if any
+func (p *CertPins) verify(host string, rawCerts [][]byte) error {
+	fps := p.pins(host)
+	if len(fps) == 0 {
+		return nil
+	}
+	if len(rawCerts) == 0 {
+		return errCertPinMismatch
+	}
+	leaf := CertFingerprint(sha256.Sum256(rawCerts[0]))
+	for _, fp := range fps {
+		if leaf == fp {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w host=%v fingerprint=%v", errCertPinMismatch, host, leaf)
+}
+
+// tlsConfig returns the TLS config to connect to the orchestrator at host. Orchestrators usually have
+// self-signed certificates, so certificates are only checked against the pins of the orchestrator.
+func (p *CertPins) tlsConfig(host string) *tls.Config {
+	if len(p.pins(host)) == 0 {
+		return tlsConfig
+	}
+	cfg := tlsConfig.Clone()
+	cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		return p.verify(host, rawCerts)
+	}
+	return cfg
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCertFingerprint(t *testing.T) {
+	assert := assert.New(t)
+
+	hexFp := strings.Repeat("ab", 32)
+	fp, err := ParseCertFingerprint(hexFp)
+	assert.Nil(err)
+	assert.Equal(hexFp, fp.String())
+
+	// openssl format
+	fp, err = ParseCertFingerprint(strings.TrimSuffix(strings.Repeat("AB:", 32), ":"))
+	assert.Nil(err)
+	assert.Equal(hexFp, fp.String())
+
+	_, err = ParseCertFingerprint("abcd")
+	assert.EqualError(err, "invalid SHA-256 certificate fingerprint: abcd")
+	_, err = ParseCertFingerprint(strings.Repeat("zz", 32))
+	assert.NotNil(err)
+}
+
+func TestParseCertPins(t *testing.T) {
+	assert := assert.New(t)
+
+	fp := strings.Repeat("ab", 32)
+	pins, err := ParseCertPins([]string{"https://127.0.0.1:8935=" + fp, " o.example.com=" + fp, ""})
+	assert.Nil(err)
+	assert.Len(pins, 2)
+	assert.Equal("https://127.0.0.1:8935", pins[0].URI.String())
+	assert.Equal("https://o.example.com", pins[1].URI.String())
+	assert.Equal(fp, pins[1].Fingerprint.String())
+
+	_, err = ParseCertPins([]string{"https://127.0.0.1:8935"})
+	assert.EqualError(err, "invalid certificate pin, expected <service URI>=<fingerprint>: https://127.0.0.1:8935")
+	_, err = ParseCertPins([]string{"https://=" + fp})
+	assert.EqualError(err, "invalid orchestrator service URI: https://")
+	_, err = ParseCertPins([]string{"https://127.0.0.1:8935=foo"})
+	assert.EqualError(err, "invalid SHA-256 certificate fingerprint: foo")
+}
+
+func TestCertPins_Verify(t *testing.T) {
+	assert := assert.New(t)
+
+	cert1, cert2, cert3 := []byte("cert1"), []byte("cert2"), []byte("cert3")
+	pin := func(uri string, cert []byte) CertPin {
+		u, _ := url.Parse(uri)
+		return CertPin{URI: u, Fingerprint: sha256.Sum256(cert)}
+	}
+	p := NewCertPins()
+	p.Configure([]CertPin{pin("https://o1.example.com:8935", cert1), pin("https://o1.example.com:8935", cert2), pin("https://o2.example.com", cert1)})
+
+	// unpinned orchestrators may present any cert
+	assert.Nil(p.verify("o3.example.com:8935", [][]byte{cert3}))
+	assert.Equal(tlsConfig, p.tlsConfig("o3.example.com:8935"))
+
+	// any of the pinned certs is accepted
+	assert.Nil(p.verify("o1.example.com:8935", [][]byte{cert1}))
+	assert.Nil(p.verify("o1.example.com:8935", [][]byte{cert2}))
+	assert.ErrorIs(p.verify("o1.example.com:8935", [][]byte{cert3}), errCertPinMismatch)
+	assert.ErrorIs(p.verify("o1.example.com:8935", nil), errCertPinMismatch)
+	assert.NotNil(p.tlsConfig("o1.example.com:8935").VerifyPeerCertificate)
+
+	// the default port applies to URIs without a port
+	assert.Nil(p.verify("o2.example.com:443", [][]byte{cert1}))
+	assert.ErrorIs(p.verify("o2.example.com", [][]byte{cert2}), errCertPinMismatch)
+	assert.Nil(p.verify("o2.example.com:8935", [][]byte{cert2}))
+
+	// configured pins take precedence over discovered ones
+	p.SetDiscovered([]CertPin{pin("https://o1.example.com:8935", cert3), pin("https://o3.example.com:8935", cert1)})
+	assert.ErrorIs(p.verify("o1.example.com:8935", [][]byte{cert3}), errCertPinMismatch)
+	assert.ErrorIs(p.verify("o3.example.com:8935", [][]byte{cert3}), errCertPinMismatch)
+	assert.Nil(p.verify("o3.example.com:8935", [][]byte{cert1}))
+
+	// discovered pins are replaced
+	p.SetDiscovered(nil)
+	assert.Nil(p.verify("o3.example.com:8935", [][]byte{cert3}))
+}
+
+func TestCertPins_HTTPClient(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ts, mux := stubTLSServer()
+	defer ts.Close()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	uri, err := url.Parse(ts.URL)
+	require.Nil(err)
+
+	oldPins := OrchCertPins
+	defer func() { OrchCertPins = oldPins }()
+
+	// the orchestrator presents the pinned cert
+	OrchCertPins = NewCertPins()
+	OrchCertPins.Configure([]CertPin{{URI: uri, Fingerprint: sha256.Sum256(ts.Certificate().Raw)}})
+	resp, err := httpClient.Get(ts.URL + "/status")
+	require.Nil(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusOK, resp.StatusCode)
+
+	// the orchestrator presents another cert
+	httpClient.CloseIdleConnections()
+	OrchCertPins = NewCertPins()
+	OrchCertPins.Configure([]CertPin{{URI: uri, Fingerprint: sha256.Sum256([]byte("other"))}})
+	_, err = httpClient.Get(ts.URL + "/status")
+	assert.ErrorIs(err, errCertPinMismatch)
+}
+
+func TestCertPins_SegmentDownload(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ts, mux := stubTLSServer()
+	defer ts.Close()
+	mux.HandleFunc("/stream/rendition.ts", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("rendition"))
+	})
+	uri, err := url.Parse(ts.URL)
+	require.Nil(err)
+
+	oldPins := OrchCertPins
+	defer func() { OrchCertPins = oldPins }()
+
+	// the orchestrator presents another cert
+	OrchCertPins = NewCertPins()
+	OrchCertPins.Configure([]CertPin{{URI: uri, Fingerprint: sha256.Sum256([]byte("other"))}})
+	_, err = downloadSeg(context.Background(), ts.URL+"/stream/rendition.ts")
+	assert.ErrorIs(err, errCertPinMismatch)
+	_, err = core.GetSegmentData(context.Background(), ts.URL+"/stream/rendition.ts")
+	assert.ErrorIs(err, errCertPinMismatch)
+
+	// the orchestrator presents the pinned cert
+	OrchCertPins = NewCertPins()
+	OrchCertPins.Configure([]CertPin{{URI: uri, Fingerprint: sha256.Sum256(ts.Certificate().Raw)}})
+	data, err := downloadSeg(context.Background(), ts.URL+"/stream/rendition.ts")
+	require.Nil(err)
+	assert.Equal([]byte("rendition"), data)
+}
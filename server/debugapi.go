@@ -0,0 +1,137 @@
+package server
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"time"
+
+	"github.com/livepeer/go-livepeer/core"
+)
+
+// DebugAPIToken, if set, enables the pprof profiles and the runtime diagnostics of the CLI server under /debug.
+// Requests have to authenticate with it in an "Authorization: Bearer <token>" header
+var DebugAPIToken string
+
+// processStart is when the node started, approximately
+var processStart = time.Now()
+
+// maxGCPauses is the number of most recent GC pauses reported
+const maxGCPauses = 16
+
+func init() {
+	// net/http/pprof registers its handlers on the default mux. Replace it so that they're only served by the CLI server
+	// behind authentication, and not accidentally by other listeners.
+	http.DefaultServeMux = http.NewServeMux()
+}
+
+type memoryStats struct {
+	HeapAlloc    uint64 `json:"heapAlloc"`
+	HeapInuse    uint64 `json:"heapInuse"`
+	HeapIdle     uint64 `json:"heapIdle"`
+	HeapReleased uint64 `json:"heapReleased"`
+	HeapObjects  uint64 `json:"heapObjects"`
+	StackInuse   uint64 `json:"stackInuse"`
+	Sys          uint64 `json:"sys"`
+	TotalAlloc   uint64 `json:"totalAlloc"`
+	Mallocs      uint64 `json:"mallocs"`
+	Frees        uint64 `json:"frees"`
+}
+
+type gcStats struct {
+	NumGC         int64     `json:"numGC"`
+	LastGC        time.Time `json:"lastGC"`
+	PauseTotalNs  int64     `json:"pauseTotalNs"`
+	RecentPauseNs []int64   `json:"recentPauseNs"`
+	NextGC        uint64    `json:"nextGC"`
+	CPUFraction   float64   `json:"cpuFraction"`
+}
+
+// runtimeStats are the runtime diagnostics of the node
+type runtimeStats struct {
+	Version       string      `json:"version"`
+	GoVersion     string      `json:"goVersion"`
+	UptimeSeconds int64       `json:"uptimeSeconds"`
+	NumCPU        int         `json:"numCPU"`
+	GOMAXPROCS    int         `json:"gomaxprocs"`
+	NumGoroutine  int         `json:"numGoroutine"`
+	NumCgoCall    int64       `json:"numCgoCall"`
+	Memory        memoryStats `json:"memory"`
+	GC            gcStats     `json:"gc"`
+}
+
+func registerDebugAPI(mux *http.ServeMux) {
+	handle := func(path string, h http.Handler) {
+		mux.Handle(path, mustHaveBearerToken("debug", func() string { return DebugAPIToken }, h))
+	}
+	// The index also serves the profiles by name, e.g. /debug/pprof/heap or /debug/pprof/goroutine?debug=2
+	handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
+	handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+	handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	handle("/debug/goroutines", goroutinesHandler())
+	handle("/debug/runtime", runtimeStatsHandler())
+}
+
+// goroutinesHandler dumps the stacks of all the goroutines
+func goroutinesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rpprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+}
+
+func runtimeStatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respondJson(w, readRuntimeStats())
+	})
+}
+
+func readRuntimeStats() *runtimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+
+	stats := &runtimeStats{
+		Version:       core.LivepeerVersion,
+		GoVersion:     runtime.Version(),
+		UptimeSeconds: int64(time.Since(processStart).Seconds()),
+		NumCPU:        runtime.NumCPU(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		NumGoroutine:  runtime.NumGoroutine(),
+		NumCgoCall:    runtime.NumCgoCall(),
+		Memory: memoryStats{
+			HeapAlloc:    mem.HeapAlloc,
+			HeapInuse:    mem.HeapInuse,
+			HeapIdle:     mem.HeapIdle,
+			HeapReleased: mem.HeapReleased,
+			HeapObjects:  mem.HeapObjects,
+			StackInuse:   mem.StackInuse,
+			Sys:          mem.Sys,
+			TotalAlloc:   mem.TotalAlloc,
+			Mallocs:      mem.Mallocs,
+			Frees:        mem.Frees,
+		},
+		GC: gcStats{
+			NumGC:        gc.NumGC,
+			LastGC:       gc.LastGC,
+			PauseTotalNs: int64(gc.PauseTotal),
+			NextGC:       mem.NextGC,
+			CPUFraction:  mem.GCCPUFraction,
+		},
+	}
+	// The pauses are the most recent first
+	pauses := gc.Pause
+	if len(pauses) > maxGCPauses {
+		pauses = pauses[:maxGCPauses]
+	}
+	stats.GC.RecentPauseNs = make([]int64, 0, len(pauses))
+	for _, p := range pauses {
+		stats.GC.RecentPauseNs = append(stats.GC.RecentPauseNs, int64(p))
+	}
+	return stats
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getDebug(mux *http.ServeMux, path, token string) (int, string) {
+	req := httptest.NewRequest("GET", "http://example.com"+path, nil)
+	if token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestDebugAPI_Auth(t *testing.T) {
+	assert := assert.New(t)
+	mux := http.NewServeMux()
+	registerDebugAPI(mux)
+
+	defer func() { DebugAPIToken = "" }()
+	DebugAPIToken = ""
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/goroutines", "/debug/runtime"} {
+		status, body := getDebug(mux, path, "")
+		assert.Equal(http.StatusForbidden, status, path)
+		assert.Equal("debug API is disabled", strings.TrimSpace(body), path)
+	}
+
+	DebugAPIToken = "secret"
+	status, _ := getDebug(mux, "/debug/pprof/heap", "")
+	assert.Equal(http.StatusUnauthorized, status)
+	status, _ = getDebug(mux, "/debug/pprof/heap", "wrong")
+	assert.Equal(http.StatusUnauthorized, status)
+
+	status, body := getDebug(mux, "/debug/pprof/", "secret")
+	assert.Equal(http.StatusOK, status)
+	assert.Contains(body, "goroutine")
+	status, _ = getDebug(mux, "/debug/pprof/heap", "secret")
+	assert.Equal(http.StatusOK, status)
+	status, body = getDebug(mux, "/debug/goroutines", "secret")
+	assert.Equal(http.StatusOK, status)
+	assert.Contains(body, "goroutine ")
+
+	// pprof isn't served by the default mux
+	w := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/debug/pprof/", nil))
+	assert.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestDebugAPI_RuntimeStats(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	mux := http.NewServeMux()
+	registerDebugAPI(mux)
+	defer func() { DebugAPIToken = "" }()
+	DebugAPIToken = "secret"
+
+	runtime.GC()
+	status, body := getDebug(mux, "/debug/runtime", "secret")
+	require.Equal(http.StatusOK, status)
+	var stats runtimeStats
+	require.Nil(json.Unmarshal([]byte(body), &stats))
+	assert.Equal(runtime.Version(), stats.GoVersion)
+	assert.Equal(runtime.NumCPU(), stats.NumCPU)
+	assert.Greater(stats.NumGoroutine, 0)
+	assert.Greater(stats.Memory.HeapAlloc, uint64(0))
+	assert.Greater(stats.GC.NumGC, int64(0))
+	assert.NotEmpty(stats.GC.RecentPauseNs)
+	assert.LessOrEqual(len(stats.GC.RecentPauseNs), maxGCPauses)
+}
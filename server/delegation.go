@@ -0,0 +1,188 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/livepeer/go-livepeer/eth"
+)
+
+// DelegationAPIToken, if set, enables the JSON delegation API of the CLI server and the state changing routes of the
+// API v2. Requests have to authenticate with it in an "Authorization: Bearer <token>" header
+var DelegationAPIToken string
+
+// delegationRequest is the JSON body of the delegation API requests. Amounts are decimal strings in LPT wei for bond
+// and unbond, and in ETH wei for withdrawFees
+type delegationRequest struct {
+	Amount          string `json:"amount"`
+	ToAddr          string `json:"toAddr"`
+	UnbondingLockID string `json:"unbondingLockId"`
+	Recipient       string `json:"recipient"`
+}
+
+// delegationResponse is returned once the transaction of a delegation API request is confirmed
+type delegationResponse struct {
+	TxHash string `json:"txHash"`
+}
+
+func registerDelegationAPI(mux *http.ServeMux, client eth.LivepeerEthClient, db ChainIdGetter) {
+	mux.Handle("/api/delegation/bond", delegationHandler(client, func(req *delegationRequest) (*ethtypes.Transaction, error) {
+		amount, err := parseDelegationAmount(req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		toAddr, err := parseDelegationAddr(req.ToAddr, "toAddr")
+		if err != nil {
+			return nil, err
+		}
+		return client.Bond(amount, toAddr)
+	}))
+	mux.Handle("/api/delegation/unbond", delegationHandler(client, func(req *delegationRequest) (*ethtypes.Transaction, error) {
+		amount, err := parseDelegationAmount(req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		return client.Unbond(amount)
+	}))
+	mux.Handle("/api/delegation/rebond", delegationHandler(client, func(req *delegationRequest) (*ethtypes.Transaction, error) {
+		lockID, err := parseUnbondingLockID(req.UnbondingLockID)
+		if err != nil {
+			return nil, err
+		}
+		// A lock of an unbonded delegator is rebonded to toAddr
+		if req.ToAddr != "" {
+			toAddr, err := parseDelegationAddr(req.ToAddr, "toAddr")
+			if err != nil {
+				return nil, err
+			}
+			return client.RebondFromUnbonded(toAddr, lockID)
+		}
+		return client.Rebond(lockID)
+	}))
+	mux.Handle("/api/delegation/withdrawStake", delegationHandler(client, func(req *delegationRequest) (*ethtypes.Transaction, error) {
+		lockID, err := parseUnbondingLockID(req.UnbondingLockID)
+		if err != nil {
+			return nil, err
+		}
+		return client.WithdrawStake(lockID)
+	}))
+	mux.Handle("/api/delegation/withdrawFees", mustHaveDb(db, delegationHandler(client, func(req *delegationRequest) (*ethtypes.Transaction, error) {
+		isL1Network, err := isL1Network(db)
+		if err != nil {
+			return nil, err
+		}
+		// The L1 contracts withdraw all the fees to the node's account
+		if isL1Network {
+			return client.L1WithdrawFees()
+		}
+		amount, err := parseDelegationAmount(req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		recipient := client.Account().Address
+		if req.Recipient != "" {
+			if recipient, err = parseDelegationAddr(req.Recipient, "recipient"); err != nil {
+				return nil, err
+			}
+		}
+		return client.WithdrawFees(recipient, amount)
+	})))
+}
+
+// errBadDelegationRequest is an invalid delegation API request
+type errBadDelegationRequest string
+
+func (e errBadDelegationRequest) Error() string {
+	return string(e)
+}
+
+func parseDelegationAmount(s string) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(s, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errBadDelegationRequest("missing or invalid amount")
+	}
+	return amount, nil
+}
+
+func parseUnbondingLockID(s string) (*big.Int, error) {
+	id, ok := new(big.Int).SetString(s, 10)
+	if !ok || id.Sign() < 0 {
+		return nil, errBadDelegationRequest("missing or invalid unbondingLockId")
+	}
+	return id, nil
+}
+
+func parseDelegationAddr(addr, name string) (ethcommon.Address, error) {
+	if !ethcommon.IsHexAddress(addr) {
+		return ethcommon.Address{}, errBadDelegationRequest(fmt.Sprintf("missing or invalid %v", name))
+	}
+	return ethcommon.HexToAddress(addr), nil
+}
+
+// delegationHandler authenticates a JSON delegation API request, sends its transaction and waits for it to confirm
+func delegationHandler(client eth.LivepeerEthClient, send func(req *delegationRequest) (*ethtypes.Transaction, error)) http.Handler {
+	return mustHaveDelegationAuth(mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			respondWithError(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req delegationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respond400(w, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+
+		tx, err := send(&req)
+		if _, ok := err.(errBadDelegationRequest); ok {
+			respond400(w, err.Error())
+			return
+		}
+		if err != nil {
+			respond500(w, fmt.Sprintf("could not send transaction: %v", err))
+			return
+		}
+		if err := client.CheckTx(tx); err != nil {
+			respond500(w, fmt.Sprintf("transaction failed: %v", err))
+			return
+		}
+		respondJson(w, delegationResponse{TxHash: tx.Hash().Hex()})
+	})))
+}
+
+func mustHaveDelegationAuth(h http.Handler) http.Handler {
+	return mustHaveBearerToken("delegation", func() string { return DelegationAPIToken }, h)
+}
+
+// mustHaveBearerToken serves the requests to the api that authenticate with the token in an
+// "Authorization: Bearer <token>" header. The api is disabled while the token is empty.
+func mustHaveBearerToken(api string, token func() string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch bearerTokenStatus(token(), r) {
+		case http.StatusForbidden:
+			respondWithError(w, api+" API is disabled", http.StatusForbidden)
+		case http.StatusUnauthorized:
+			respondWithError(w, "unauthorized", http.StatusUnauthorized)
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+}
+
+// bearerTokenStatus returns http.StatusOK if r authenticates with the token in an "Authorization: Bearer <token>"
+// header, http.StatusForbidden if the token is empty and http.StatusUnauthorized otherwise
+func bearerTokenStatus(token string, r *http.Request) int {
+	if token == "" {
+		return http.StatusForbidden
+	}
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		return http.StatusUnauthorized
+	}
+	return http.StatusOK
+}
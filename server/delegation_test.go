@@ -0,0 +1,164 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/livepeer/go-livepeer/eth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// delegationClient mocks the delegation transactions that eth.MockClient stubs
+type delegationClient struct {
+	*eth.MockClient
+}
+
+func delegationTx(args mock.Arguments) (*ethtypes.Transaction, error) {
+	tx, _ := args.Get(0).(*ethtypes.Transaction)
+	return tx, args.Error(1)
+}
+
+func (c *delegationClient) Bond(amount *big.Int, toAddr ethcommon.Address) (*ethtypes.Transaction, error) {
+	return delegationTx(c.Called(amount, toAddr))
+}
+
+func (c *delegationClient) Unbond(amount *big.Int) (*ethtypes.Transaction, error) {
+	return delegationTx(c.Called(amount))
+}
+
+func (c *delegationClient) Rebond(lockID *big.Int) (*ethtypes.Transaction, error) {
+	return delegationTx(c.Called(lockID))
+}
+
+func (c *delegationClient) RebondFromUnbonded(toAddr ethcommon.Address, lockID *big.Int) (*ethtypes.Transaction, error) {
+	return delegationTx(c.Called(toAddr, lockID))
+}
+
+func (c *delegationClient) WithdrawStake(lockID *big.Int) (*ethtypes.Transaction, error) {
+	return delegationTx(c.Called(lockID))
+}
+
+func postDelegation(mux *http.ServeMux, path, token, body string) (int, string) {
+	headers := map[string]string{"Content-Type": "application/json"}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	req := httptest.NewRequest("POST", "http://example.com"+path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Add(k, v)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, trim(data)
+}
+
+func newDelegationMux(client eth.LivepeerEthClient, db ChainIdGetter) *http.ServeMux {
+	mux := http.NewServeMux()
+	registerDelegationAPI(mux, client, db)
+	return mux
+}
+
+func TestDelegationAPI_Auth(t *testing.T) {
+	assert := assert.New(t)
+	client := &delegationClient{MockClient: &eth.MockClient{}}
+	mux := newDelegationMux(client, &mockChainIdGetter{})
+	req := func(token string) (int, string) {
+		return postDelegation(mux, "/api/delegation/unbond", token, `{"amount":"1"}`)
+	}
+
+	defer func() { DelegationAPIToken = "" }()
+	DelegationAPIToken = ""
+	status, body := req("")
+	assert.Equal(http.StatusForbidden, status)
+	assert.Equal("delegation API is disabled", body)
+
+	DelegationAPIToken = "secret"
+	status, _ = req("")
+	assert.Equal(http.StatusUnauthorized, status)
+	status, _ = req("wrong")
+	assert.Equal(http.StatusUnauthorized, status)
+
+	tx := ethtypes.NewTx(&ethtypes.LegacyTx{Nonce: 1})
+	client.On("Unbond", big.NewInt(1)).Return(tx, nil).Once()
+	client.On("CheckTx").Return(nil).Once()
+	status, body = req("secret")
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(fmt.Sprintf(`{"txHash":"%v"}`, tx.Hash().Hex()), body)
+}
+
+func TestDelegationAPI_Requests(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { DelegationAPIToken = "" }()
+	DelegationAPIToken = "secret"
+
+	addr := ethcommon.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	account := ethcommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	tx := ethtypes.NewTx(&ethtypes.LegacyTx{Nonce: 2})
+	client := &delegationClient{MockClient: &eth.MockClient{}}
+	client.On("Account").Return(accounts.Account{Address: account})
+	client.On("CheckTx").Return(nil)
+	db := &mockChainIdGetter{}
+	db.On("ChainID").Return(big.NewInt(42161), nil)
+	mux := newDelegationMux(client, db)
+	ok := fmt.Sprintf(`{"txHash":"%v"}`, tx.Hash().Hex())
+
+	client.On("Bond", big.NewInt(1000), addr).Return(tx, nil).Once()
+	status, body := postDelegation(mux, "/api/delegation/bond", "secret", fmt.Sprintf(`{"amount":"1000","toAddr":"%v"}`, addr.Hex()))
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(ok, body)
+
+	client.On("Rebond", big.NewInt(3)).Return(tx, nil).Once()
+	status, body = postDelegation(mux, "/api/delegation/rebond", "secret", `{"unbondingLockId":"3"}`)
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(ok, body)
+
+	client.On("RebondFromUnbonded", addr, big.NewInt(0)).Return(tx, nil).Once()
+	status, _ = postDelegation(mux, "/api/delegation/rebond", "secret", fmt.Sprintf(`{"unbondingLockId":"0","toAddr":"%v"}`, addr.Hex()))
+	assert.Equal(http.StatusOK, status)
+
+	client.On("WithdrawStake", big.NewInt(3)).Return(tx, nil).Once()
+	status, _ = postDelegation(mux, "/api/delegation/withdrawStake", "secret", `{"unbondingLockId":"3"}`)
+	assert.Equal(http.StatusOK, status)
+
+	// Fees are withdrawn to the node's account by default
+	client.On("WithdrawFees", account, big.NewInt(500)).Return(tx, nil).Once()
+	status, _ = postDelegation(mux, "/api/delegation/withdrawFees", "secret", `{"amount":"500"}`)
+	assert.Equal(http.StatusOK, status)
+	client.On("WithdrawFees", addr, big.NewInt(500)).Return(tx, nil).Once()
+	status, _ = postDelegation(mux, "/api/delegation/withdrawFees", "secret", fmt.Sprintf(`{"amount":"500","recipient":"%v"}`, addr.Hex()))
+	assert.Equal(http.StatusOK, status)
+
+	// Invalid requests
+	for path, body := range map[string]string{
+		"/api/delegation/bond":          `{"amount":"1000","toAddr":"foo"}`,
+		"/api/delegation/unbond":        `{"amount":"-1"}`,
+		"/api/delegation/rebond":        `{}`,
+		"/api/delegation/withdrawStake": `{"unbondingLockId":"x"}`,
+		"/api/delegation/withdrawFees":  `{"amount":"0"}`,
+	} {
+		status, _ := postDelegation(mux, path, "secret", body)
+		assert.Equal(http.StatusBadRequest, status, path)
+	}
+	status, body = postDelegation(mux, "/api/delegation/unbond", "secret", `{"amount":1}`)
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Contains(body, "invalid request body")
+
+	// Transaction errors
+	client.On("Unbond", big.NewInt(1)).Return(nil, errors.New("insufficient stake")).Once()
+	status, body = postDelegation(mux, "/api/delegation/unbond", "secret", `{"amount":"1"}`)
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.Equal("could not send transaction: insufficient stake", body)
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"math"
+	gonet "net"
+	"strconv"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/monitor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DiscoveryRateLimits, if set, limits the rate of the GetOrchestrator requests the orchestrator handles per IP and
+// per sender
+var DiscoveryRateLimits *DiscoveryRateLimiter
+
+// discoveryRateLimitCleanupInterval is how often the buckets that are full again are dropped
+var discoveryRateLimitCleanupInterval = time.Minute
+
+// DiscoveryRateLimiter limits the rate of GetOrchestrator requests with a token bucket per client IP and per sender
+// address. Requests from an IP are limited before verifying their signature, and requests of a sender after it, so
+// that a client can't use up the requests of a sender it doesn't control.
+type DiscoveryRateLimiter struct {
+	perIP     float64
+	perSender float64
+	burst     int
+
+	mu          sync.Mutex
+	ips         map[string]*tokenBucket
+	senders     map[ethcommon.Address]*tokenBucket
+	lastCleanup time.Time
+}
+
+// discoveryRateLimitError is returned for the requests refused by a DiscoveryRateLimiter
+type discoveryRateLimitError struct {
+	// wait is how long to wait before the next request is allowed
+	wait time.Duration
+}
+
+func (e discoveryRateLimitError) Error() string {
+	return "discovery rate limit exceeded"
+}
+
+// GRPCStatus returns the status of the gRPC responses to refused requests
+func (e discoveryRateLimitError) GRPCStatus() *status.Status {
+	return status.New(codes.ResourceExhausted, e.Error())
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewDiscoveryRateLimiter creates a DiscoveryRateLimiter allowing perIP and perSender requests per second, with
+// bursts of up to burst requests. A limit of 0 is not enforced.
+func NewDiscoveryRateLimiter(perIP, perSender float64, burst int) *DiscoveryRateLimiter {
+	return &DiscoveryRateLimiter{
+		perIP:       perIP,
+		perSender:   perSender,
+		burst:       burst,
+		ips:         make(map[string]*tokenBucket),
+		senders:     make(map[ethcommon.Address]*tokenBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// AllowIP returns a discoveryRateLimitError if a request from the IP is not allowed
+func (l *DiscoveryRateLimiter) AllowIP(ip string) error {
+	if l == nil || l.perIP <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.cleanupUnsafe(now)
+
+	b, ok := l.ips[ip]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.burst), last: now}
+		l.ips[ip] = b
+	}
+	err := l.takeUnsafe(b, l.perIP, now)
+	if err != nil && monitor.Enabled {
+		monitor.DiscoveryRateLimited("ip")
+	}
+	return err
+}
+
+// AllowSender returns a discoveryRateLimitError if a request of the sender is not allowed
+func (l *DiscoveryRateLimiter) AllowSender(sender ethcommon.Address) error {
+	if l == nil || l.perSender <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.cleanupUnsafe(now)
+
+	b, ok := l.senders[sender]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.burst), last: now}
+		l.senders[sender] = b
+	}
+	err := l.takeUnsafe(b, l.perSender, now)
+	if err != nil && monitor.Enabled {
+		monitor.DiscoveryRateLimited("sender")
+	}
+	return err
+}
+
+// takeUnsafe refills the bucket at rate tokens per second and takes a token from it if there is one
+func (l *DiscoveryRateLimiter) takeUnsafe(b *tokenBucket, rate float64, now time.Time) error {
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return discoveryRateLimitError{wait: time.Duration((1 - b.tokens) / rate * float64(time.Second))}
+	}
+	b.tokens--
+	return nil
+}
+
+// cleanupUnsafe drops the buckets that are full again, which behave the same as new ones
+func (l *DiscoveryRateLimiter) cleanupUnsafe(now time.Time) {
+	if now.Sub(l.lastCleanup) < discoveryRateLimitCleanupInterval {
+		return
+	}
+	l.lastCleanup = now
+	full := func(b *tokenBucket, rate float64) bool {
+		return b.tokens+now.Sub(b.last).Seconds()*rate >= float64(l.burst)
+	}
+	for ip, b := range l.ips {
+		if full(b, l.perIP) {
+			delete(l.ips, ip)
+		}
+	}
+	for sender, b := range l.senders {
+		if full(b, l.perSender) {
+			delete(l.senders, sender)
+		}
+	}
+}
+
+// setRateLimitTrailer tells the client of a gRPC request refused by the rate limit when to retry
+func setRateLimitTrailer(ctx context.Context, err discoveryRateLimitError) {
+	secs := int64(math.Ceil(err.wait.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	grpc.SetTrailer(ctx, metadata.Pairs(retryAfterKey, strconv.FormatInt(secs, 10)))
+}
+
+// peerIP returns the IP of the client of a gRPC request
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := gonet.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
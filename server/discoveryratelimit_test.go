@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	gonet "net"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestDiscoveryRateLimiter_PerIP(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewDiscoveryRateLimiter(10, 0, 2)
+	assert.Nil(l.AllowIP("10.0.0.1"))
+	assert.Nil(l.AllowIP("10.0.0.1"))
+	err := l.AllowIP("10.0.0.1")
+	require.IsType(t, discoveryRateLimitError{}, err)
+	assert.InDelta(100*time.Millisecond, err.(discoveryRateLimitError).wait, float64(10*time.Millisecond))
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+
+	// IPs are limited separately
+	assert.Nil(l.AllowIP("10.0.0.2"))
+
+	// Tokens are refilled over time
+	time.Sleep(110 * time.Millisecond)
+	assert.Nil(l.AllowIP("10.0.0.1"))
+	assert.NotNil(l.AllowIP("10.0.0.1"))
+
+	// Senders are not limited
+	sender := pm.RandAddress()
+	for i := 0; i < 5; i++ {
+		assert.Nil(l.AllowSender(sender))
+	}
+}
+
+func TestDiscoveryRateLimiter_PerSender(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewDiscoveryRateLimiter(0, 1, 1)
+	sender := pm.RandAddress()
+	assert.Nil(l.AllowSender(sender))
+	assert.NotNil(l.AllowSender(sender))
+	assert.Nil(l.AllowSender(pm.RandAddress()))
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(l.AllowIP("10.0.0.1"))
+	}
+
+	// A nil limiter doesn't limit
+	var nilLimiter *DiscoveryRateLimiter
+	assert.Nil(nilLimiter.AllowIP("10.0.0.1"))
+	assert.Nil(nilLimiter.AllowSender(sender))
+}
+
+func TestDiscoveryRateLimiter_Cleanup(t *testing.T) {
+	assert := assert.New(t)
+	defer func(d time.Duration) { discoveryRateLimitCleanupInterval = d }(discoveryRateLimitCleanupInterval)
+	discoveryRateLimitCleanupInterval = 0
+
+	l := NewDiscoveryRateLimiter(100, 100, 1)
+	assert.Nil(l.AllowIP("10.0.0.1"))
+	assert.Nil(l.AllowSender(ethcommon.Address{1}))
+	assert.Len(l.ips, 1)
+	assert.Len(l.senders, 1)
+
+	// Buckets that are full again are dropped
+	time.Sleep(20 * time.Millisecond)
+	assert.Nil(l.AllowIP("10.0.0.2"))
+	assert.Len(l.ips, 1)
+	assert.Len(l.senders, 0)
+}
+
+func TestGetOrchestrator_RateLimited(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	o := newStubOrchestrator()
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	lp := &lphttp{orchestrator: o}
+	req, err := genOrchestratorReq(stubBroadcaster2(), GetOrchestratorInfoParams{})
+	require.Nil(err)
+
+	defer func() { DiscoveryRateLimits = nil }()
+	getOrch := func(ip string) (*stubServerTransportStream, error) {
+		stream := &stubServerTransportStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &gonet.TCPAddr{IP: gonet.ParseIP(ip), Port: 1234}})
+		_, err := lp.GetOrchestrator(ctx, req)
+		return stream, err
+	}
+
+	// Requests are limited per IP
+	DiscoveryRateLimits = NewDiscoveryRateLimiter(0.5, 0, 1)
+	_, err = getOrch("10.0.0.1")
+	assert.Nil(err)
+	stream, err := getOrch("10.0.0.1")
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+	assert.Equal([]string{"2"}, stream.trailer.Get(retryAfterKey))
+	_, err = getOrch("10.0.0.2")
+	assert.Nil(err)
+
+	// Requests are limited per sender
+	DiscoveryRateLimits = NewDiscoveryRateLimiter(0, 0.5, 1)
+	_, err = getOrch("10.0.0.1")
+	assert.Nil(err)
+	stream, err = getOrch("10.0.0.2")
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+	assert.Equal([]string{"2"}, stream.trailer.Get(retryAfterKey))
+}
+
+func TestPeerIP(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("", peerIP(context.Background()))
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &gonet.TCPAddr{IP: gonet.ParseIP("10.0.0.1"), Port: 1234}})
+	assert.Equal("10.0.0.1", peerIP(ctx))
+}
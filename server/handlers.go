@@ -21,6 +21,7 @@ import (
 	"github.com/livepeer/go-livepeer/eth"
 	"github.com/livepeer/go-livepeer/eth/types"
 	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/go-livepeer/storage"
 	"github.com/livepeer/lpms/ffmpeg"
 	"github.com/pkg/errors"
 )
@@ -126,6 +127,7 @@ func setBroadcastConfigHandler() http.Handler {
 		pricePerUnit := r.FormValue("maxPricePerUnit")
 		pixelsPerUnit := r.FormValue("pixelsPerUnit")
 		transcodingOptions := r.FormValue("transcodingOptions")
+		capabilityName := r.FormValue("capability")
 
 		if (pricePerUnit == "" || pixelsPerUnit == "") && transcodingOptions == "" {
 			respond400(w, "missing form params (maxPricePerUnit AND pixelsPerUnit) or transcodingOptions")
@@ -154,8 +156,18 @@ func setBroadcastConfigHandler() http.Handler {
 				price = big.NewRat(pr, px)
 			}
 
-			BroadcastCfg.SetMaxPrice(price)
-			glog.Infof("Maximum transcoding price: %d per %q pixels\n", pr, px)
+			if capabilityName != "" {
+				capability, err := core.CapabilityFromName(capabilityName)
+				if err != nil {
+					respond400(w, fmt.Sprintf("unknown capability: %v", capabilityName))
+					return
+				}
+				BroadcastCfg.SetCapabilityMaxPrice(capability, price)
+				glog.Infof("Maximum transcoding price for capability %v: %d per %q pixels\n", capabilityName, pr, px)
+			} else {
+				BroadcastCfg.SetMaxPrice(price)
+				glog.Infof("Maximum transcoding price: %d per %q pixels\n", pr, px)
+			}
 		}
 
 		// set broadcast profiles
@@ -184,11 +196,21 @@ func getBroadcastConfigHandler() http.Handler {
 		for _, p := range BroadcastJobVideoProfiles {
 			pNames = append(pNames, p.Name)
 		}
+		capPrices := make(map[string]*big.Rat)
+		for capability, price := range BroadcastCfg.CapabilityMaxPrices() {
+			capName, err := core.CapabilityToName(capability)
+			if err != nil {
+				capName = strconv.Itoa(int(capability))
+			}
+			capPrices[capName] = price
+		}
 		config := struct {
-			MaxPrice           *big.Rat
-			TranscodingOptions string
+			MaxPrice              *big.Rat
+			MaxPricePerCapability map[string]*big.Rat `json:",omitempty"`
+			TranscodingOptions    string
 		}{
 			BroadcastCfg.MaxPrice(),
+			capPrices,
 			strings.Join(pNames, ","),
 		}
 
@@ -207,6 +229,29 @@ func getAvailableTranscodingOptionsHandler() http.Handler {
 	})
 }
 
+// PoolRefresher refreshes the orchestrators cached for discovery
+type PoolRefresher interface {
+	Refresh() error
+}
+
+// OrchPoolRefresher, if set, refreshes the on-chain orchestrators cached by the broadcaster
+var OrchPoolRefresher PoolRefresher
+
+func refreshOrchestratorPoolHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if OrchPoolRefresher == nil {
+			respond400(w, "orchestrator pool refresh is only available on on-chain broadcasters")
+			return
+		}
+		if err := OrchPoolRefresher.Refresh(); err != nil {
+			respond500(w, fmt.Sprintf("could not refresh orchestrator pool: %v", err))
+			return
+		}
+		glog.Info("Refreshed orchestrator pool")
+		respondOk(w, nil)
+	})
+}
+
 // Rounds
 func currentRoundHandler(client eth.LivepeerEthClient) http.Handler {
 	return mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -291,7 +336,7 @@ func (s *LivepeerServer) activateOrchestratorHandler(client eth.LivepeerEthClien
 			return
 		}
 
-		if err := s.setOrchestratorPriceInfo("default", r.FormValue("pricePerUnit"), r.FormValue("pixelsPerUnit")); err != nil {
+		if err := s.setOrchestratorPriceInfo("default", r.FormValue("pricePerUnit"), r.FormValue("pixelsPerUnit"), ""); err != nil {
 			respond400(w, err.Error())
 			return
 		}
@@ -386,7 +431,7 @@ func (s *LivepeerServer) setOrchestratorConfigHandler(client eth.LivepeerEthClie
 		pixels := r.FormValue("pixelsPerUnit")
 		price := r.FormValue("pricePerUnit")
 		if pixels != "" && price != "" {
-			if err := s.setOrchestratorPriceInfo("default", price, pixels); err != nil {
+			if err := s.setOrchestratorPriceInfo("default", price, pixels, ""); err != nil {
 				respond400(w, err.Error())
 				return
 			}
@@ -458,7 +503,9 @@ func (s *LivepeerServer) setOrchestratorConfigHandler(client eth.LivepeerEthClie
 	}))
 }
 
-func (s *LivepeerServer) setOrchestratorPriceInfo(broadcasterEthAddr, pricePerUnitStr, pixelsPerUnitStr string) error {
+// setOrchestratorPriceInfo sets the base price of the orchestrator for a broadcaster, or "default", for the jobs
+// requiring the capability if set, or else for all the jobs
+func (s *LivepeerServer) setOrchestratorPriceInfo(broadcasterEthAddr, pricePerUnitStr, pixelsPerUnitStr, capabilityName string) error {
 	ok, err := regexp.MatchString("^[0-9]+$", pricePerUnitStr)
 	if err != nil {
 		return err
@@ -475,7 +522,7 @@ func (s *LivepeerServer) setOrchestratorPriceInfo(broadcasterEthAddr, pricePerUn
 		return fmt.Errorf("pixelsPerUnit is not a valid integer, provided %v", pixelsPerUnitStr)
 	}
 
-	ok, err = regexp.MatchString("^0x[0-9a-fA-F]{40}|default$", broadcasterEthAddr)
+	ok, err = regexp.MatchString("^(0x[0-9a-fA-F]{40}|default)$", broadcasterEthAddr)
 	if err != nil {
 		return err
 	}
@@ -499,6 +546,16 @@ func (s *LivepeerServer) setOrchestratorPriceInfo(broadcasterEthAddr, pricePerUn
 		return fmt.Errorf("pixels per unit must be greater than 0, provided %d", pixelsPerUnit)
 	}
 
+	if capabilityName != "" {
+		capability, err := core.CapabilityFromName(capabilityName)
+		if err != nil {
+			return fmt.Errorf("unknown capability: %v", capabilityName)
+		}
+		s.LivepeerNode.SetCapabilityBasePrice(broadcasterEthAddr, capability, big.NewRat(pricePerUnit, pixelsPerUnit))
+		glog.Infof("Price per pixel set to %d wei for %d pixels for capability %v for broadcaster %s\n", pricePerUnit, pixelsPerUnit, capabilityName, broadcasterEthAddr)
+		return nil
+	}
+
 	s.LivepeerNode.SetBasePrice(broadcasterEthAddr, big.NewRat(pricePerUnit, pixelsPerUnit))
 	if broadcasterEthAddr == "default" {
 		glog.Infof("Price per pixel set to %d wei for %d pixels\n", pricePerUnit, pixelsPerUnit)
@@ -565,9 +622,12 @@ func (s *LivepeerServer) setPriceForBroadcaster() http.Handler {
 			pricePerUnitStr := r.FormValue("pricePerUnit")
 			pixelsPerUnitStr := r.FormValue("pixelsPerUnit")
 			broadcasterEthAddr := r.FormValue("broadcasterEthAddr")
+			capabilityName := r.FormValue("capability")
 
-			err := s.setOrchestratorPriceInfo(broadcasterEthAddr, pricePerUnitStr, pixelsPerUnitStr)
-			if err == nil {
+			err := s.setOrchestratorPriceInfo(broadcasterEthAddr, pricePerUnitStr, pixelsPerUnitStr, capabilityName)
+			if err == nil && capabilityName != "" {
+				respondOk(w, []byte(fmt.Sprintf("Price per pixel set to %s wei for %s pixels for capability %s for broadcaster %s\n", pricePerUnitStr, pixelsPerUnitStr, capabilityName, broadcasterEthAddr)))
+			} else if err == nil {
 				respondOk(w, []byte(fmt.Sprintf("Price per pixel set to %s wei for %s pixels for broadcaster %s\n", pricePerUnitStr, pixelsPerUnitStr, broadcasterEthAddr)))
 			} else {
 				respond400(w, err.Error())
@@ -578,6 +638,328 @@ func (s *LivepeerServer) setPriceForBroadcaster() http.Handler {
 	})
 }
 
+// Orchestrator config
+
+func (s *LivepeerServer) broadcasterAccessListHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.NodeType != core.OrchestratorNode {
+			respond400(w, "Node must be orchestrator node to get broadcaster access list")
+			return
+		}
+		access := s.LivepeerNode.BroadcasterAccess
+		respondJson(w, map[string]interface{}{
+			"allowlist":         access.Allowlist(),
+			"allowlistEnforced": access.AllowlistEnforced(),
+			"blocklist":         access.Blocklist(),
+		})
+	})
+}
+
+func (s *LivepeerServer) clearBroadcasterAllowlistHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.NodeType != core.OrchestratorNode {
+			respond400(w, "Node must be orchestrator node to update broadcaster access list")
+			return
+		}
+		s.LivepeerNode.BroadcasterAccess.ClearAllowlist()
+		glog.Infof("Broadcaster allowlist cleared, accepting work from all broadcasters that are not blocked")
+		respondOk(w, nil)
+	})
+}
+
+// updateBroadcasterAccessHandler applies update to the address provided in the broadcasterEthAddr form param
+func (s *LivepeerServer) updateBroadcasterAccessHandler(update func(*core.BroadcasterAccessList, ethcommon.Address)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.NodeType != core.OrchestratorNode {
+			respond400(w, "Node must be orchestrator node to update broadcaster access list")
+			return
+		}
+		broadcasterEthAddr := r.FormValue("broadcasterEthAddr")
+		if !ethcommon.IsHexAddress(broadcasterEthAddr) {
+			respond400(w, fmt.Sprintf("broadcasterEthAddr is not a valid eth address, provided %v", broadcasterEthAddr))
+			return
+		}
+		update(s.LivepeerNode.BroadcasterAccess, ethcommon.HexToAddress(broadcasterEthAddr))
+		respondOk(w, nil)
+	})
+}
+
+func (s *LivepeerServer) drainingHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.NodeType != core.OrchestratorNode {
+			respond400(w, "Node must be orchestrator node to get drain status")
+			return
+		}
+		respondJson(w, map[string]interface{}{
+			"draining":       s.LivepeerNode.Draining(),
+			"activeSessions": s.LivepeerNode.ActiveSessions(),
+		})
+	})
+}
+
+func (s *LivepeerServer) setDrainingHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.NodeType != core.OrchestratorNode {
+			respond400(w, "Node must be orchestrator node to set drain mode")
+			return
+		}
+		draining, err := strconv.ParseBool(r.FormValue("draining"))
+		if err != nil {
+			respond400(w, fmt.Sprintf("draining must be a boolean, provided %v", r.FormValue("draining")))
+			return
+		}
+		s.LivepeerNode.SetDraining(draining)
+		if draining {
+			glog.Infof("Drain mode enabled, not accepting new sessions activeSessions=%v", s.LivepeerNode.ActiveSessions())
+		} else {
+			glog.Info("Drain mode disabled, accepting new sessions")
+		}
+		respondOk(w, nil)
+	})
+}
+
+// earningsHandler returns the fees received per broadcaster, stream and round, optionally filtered
+// by the sender, manifestID and round query params
+func (s *LivepeerServer) earningsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.NodeType != core.OrchestratorNode {
+			respond400(w, "Node must be orchestrator node to get earnings")
+			return
+		}
+		if s.LivepeerNode.Database == nil {
+			respond500(w, "database not available")
+			return
+		}
+
+		filter := &common.DBEarningsFilter{ManifestID: r.FormValue("manifestID")}
+		if sender := r.FormValue("sender"); sender != "" {
+			if !ethcommon.IsHexAddress(sender) {
+				respond400(w, fmt.Sprintf("sender is not a valid eth address, provided %v", sender))
+				return
+			}
+			addr := ethcommon.HexToAddress(sender)
+			filter.Sender = &addr
+		}
+		if roundStr := r.FormValue("round"); roundStr != "" {
+			round, err := common.ParseBigInt(roundStr)
+			if err != nil {
+				respond400(w, fmt.Sprintf("round is not a valid integer, provided %v", roundStr))
+				return
+			}
+			filter.Round = round
+		}
+
+		if s.LivepeerNode.Earnings != nil {
+			// Include the earnings that are still pending to be written
+			s.LivepeerNode.Earnings.Flush()
+		}
+		earnings, err := s.LivepeerNode.Database.SelectEarnings(filter)
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		respondJson(w, earnings)
+	})
+}
+
+// spendingHandler returns the tickets sent by the broadcaster between the from and to query params, summed up
+// per the comma separated groupBy query param: orchestrator, stream, hour and/or day
+func (s *LivepeerServer) spendingHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.NodeType != core.BroadcasterNode {
+			respond400(w, "Node must be broadcaster node to get spending")
+			return
+		}
+		if s.LivepeerNode.Database == nil {
+			respond500(w, "database not available")
+			return
+		}
+
+		filter := &common.DBSpendingFilter{}
+		if fromStr := r.FormValue("from"); fromStr != "" {
+			from, err := parseReportTime(fromStr)
+			if err != nil {
+				respond400(w, fmt.Sprintf("from is not a valid unix or RFC3339 time, provided %v", fromStr))
+				return
+			}
+			filter.From = &from
+		}
+		if toStr := r.FormValue("to"); toStr != "" {
+			to, err := parseReportTime(toStr)
+			if err != nil {
+				respond400(w, fmt.Sprintf("to is not a valid unix or RFC3339 time, provided %v", toStr))
+				return
+			}
+			filter.To = &to
+		}
+
+		// Include the spending that is still pending to be written
+		if Spending != nil {
+			Spending.Flush()
+		}
+		spending, err := s.LivepeerNode.Database.SelectSpending(filter)
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		report, err := groupSpending(spending, parseGroupBy(r.FormValue("groupBy")))
+		if err != nil {
+			respond400(w, err.Error())
+			return
+		}
+		if report == nil {
+			report = []*SpendingReport{}
+		}
+		respondJson(w, report)
+	})
+}
+
+// txCostsHandler returns the gas costs of the transactions sent by the node between the from and to query params,
+// optionally filtered by contract method and summed up per the comma separated groupBy query param: method, hour and/or day
+func (s *LivepeerServer) txCostsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.Database == nil {
+			respond500(w, "database not available")
+			return
+		}
+
+		filter := &common.DBTransactionFilter{Method: r.FormValue("method")}
+		if fromStr := r.FormValue("from"); fromStr != "" {
+			from, err := parseReportTime(fromStr)
+			if err != nil {
+				respond400(w, fmt.Sprintf("from is not a valid unix or RFC3339 time, provided %v", fromStr))
+				return
+			}
+			filter.From = &from
+		}
+		if toStr := r.FormValue("to"); toStr != "" {
+			to, err := parseReportTime(toStr)
+			if err != nil {
+				respond400(w, fmt.Sprintf("to is not a valid unix or RFC3339 time, provided %v", toStr))
+				return
+			}
+			filter.To = &to
+		}
+
+		txs, err := s.LivepeerNode.Database.SelectTransactions(filter)
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		report, err := groupTxCosts(txs, parseGroupBy(r.FormValue("groupBy")))
+		if err != nil {
+			respond400(w, err.Error())
+			return
+		}
+		respondJson(w, report)
+	})
+}
+
+// recordingArchivesHandler reports the status of the archives of the recordings, or of the one at the name param with
+// its storage deals
+func recordingArchivesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if storage.RecordArchiver == nil {
+			respond400(w, "recordings archive not enabled, use -recordArchiveStore")
+			return
+		}
+		name := r.FormValue("name")
+		if name == "" {
+			respondJson(w, storage.RecordArchiver.Statuses())
+			return
+		}
+		status, ok := storage.RecordArchiver.Status(r.Context(), name)
+		if !ok {
+			respondWithError(w, fmt.Sprintf("no archive found for recording name=%s", name), http.StatusNotFound)
+			return
+		}
+		respondJson(w, status)
+	})
+}
+
+// healthzHandler reports whether the node is healthy, which is the case unless the last health check of one of its
+// object stores failed
+func healthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := struct {
+			Healthy      bool                  `json:"healthy"`
+			ObjectStores []storage.StoreStatus `json:"objectStores,omitempty"`
+		}{Healthy: true}
+		if storage.StoreHealth != nil {
+			res.Healthy = storage.StoreHealth.Healthy()
+			res.ObjectStores = storage.StoreHealth.Statuses()
+		}
+		data, err := json.Marshal(res)
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !res.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write(data)
+	})
+}
+
+// streamStorageUsageHandler reports the bytes saved to the object stores by the streams, or by the one at the
+// manifestID param
+func streamStorageUsageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mid := r.FormValue("manifestID")
+		if mid == "" {
+			respondJson(w, storage.StreamUsage.All())
+			return
+		}
+		usage, ok := storage.StreamUsage.Get(mid)
+		if !ok {
+			respondWithError(w, fmt.Sprintf("no storage usage found for manifestID=%s", mid), http.StatusNotFound)
+			return
+		}
+		respondJson(w, usage)
+	})
+}
+
+// Ticket redemption
+
+// redemptionsHandler returns the redemption transactions that are waiting to confirm, the most recent failed redemptions
+// with their reasons, and the value redeemed and the estimated gas spent since the node started
+func (s *LivepeerServer) redemptionsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.Redemptions == nil {
+			respond400(w, "Node must redeem winning tickets to get redemptions")
+			return
+		}
+		respondJson(w, s.LivepeerNode.Redemptions.RedemptionStats())
+	})
+}
+
+// redemptionSendersHandler returns the max float and the pending winning tickets of the senders that the node redeems tickets from
+func (s *LivepeerServer) redemptionSendersHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.Redemptions == nil {
+			respond400(w, "Node must redeem winning tickets to get redemption senders")
+			return
+		}
+		statuses, err := s.LivepeerNode.Redemptions.SenderStatuses()
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		respondJson(w, statuses)
+	})
+}
+
+func (s *LivepeerServer) blockedSendersHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.Redemptions == nil {
+			respond400(w, "Node must redeem winning tickets to get blocked senders")
+			return
+		}
+		respondJson(w, s.LivepeerNode.Redemptions.BlockedSenders())
+	})
+}
+
 // Bond, withdraw, reward
 func bondHandler(client eth.LivepeerEthClient) http.Handler {
 	return mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -10,11 +10,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/core"
 	"github.com/livepeer/go-livepeer/eth"
 	"github.com/livepeer/go-livepeer/eth/types"
@@ -22,6 +26,7 @@ import (
 	"github.com/livepeer/lpms/ffmpeg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Status
@@ -256,6 +261,43 @@ func TestSetBroadcastConfigHandler_Success(t *testing.T) {
 	assert.Equal(profiles, BroadcastJobVideoProfiles)
 }
 
+func TestSetBroadcastConfigHandler_CapabilityMaxPrice(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { BroadcastCfg.capabilityMaxPrices = nil }()
+
+	handler := setBroadcastConfigHandler()
+	status, body := postForm(handler, url.Values{
+		"maxPricePerUnit": {"1"},
+		"pixelsPerUnit":   {"2"},
+		"capability":      {"foo"},
+	})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("unknown capability: foo", body)
+
+	BroadcastCfg.SetMaxPrice(big.NewRat(1, 2))
+	status, _ = postForm(handler, url.Values{
+		"maxPricePerUnit": {"3"},
+		"pixelsPerUnit":   {"1"},
+		"capability":      {"HEVC encode"},
+	})
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(big.NewRat(1, 2), BroadcastCfg.MaxPrice())
+	assert.Equal(map[core.Capability]*big.Rat{core.Capability_HEVC_Encode: big.NewRat(3, 1)}, BroadcastCfg.CapabilityMaxPrices())
+
+	status, body = get(getBroadcastConfigHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.Contains(body, `"MaxPricePerCapability":{"HEVC encode":"3"}`)
+
+	// a zero price removes the capability max price
+	status, _ = postForm(handler, url.Values{
+		"maxPricePerUnit": {"0"},
+		"pixelsPerUnit":   {"1"},
+		"capability":      {"HEVC encode"},
+	})
+	assert.Equal(http.StatusOK, status)
+	assert.Empty(BroadcastCfg.CapabilityMaxPrices())
+}
+
 func TestGetBroadcastConfigHandler(t *testing.T) {
 	assert := assert.New(t)
 
@@ -284,6 +326,40 @@ func TestGetAvailableTranscodingOptionsHandler(t *testing.T) {
 	assert.NotEmpty(body)
 }
 
+type stubPoolRefresher struct {
+	refreshes int
+	err       error
+}
+
+func (r *stubPoolRefresher) Refresh() error {
+	r.refreshes++
+	return r.err
+}
+
+func TestRefreshOrchestratorPoolHandler(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { OrchPoolRefresher = nil }()
+
+	handler := refreshOrchestratorPoolHandler()
+
+	// no on-chain pool
+	status, body := get(handler)
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("orchestrator pool refresh is only available on on-chain broadcasters", body)
+
+	refresher := &stubPoolRefresher{}
+	OrchPoolRefresher = refresher
+	status, _ = get(handler)
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(1, refresher.refreshes)
+
+	refresher.err = errors.New("TranscoderPool error")
+	status, body = get(handler)
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.Equal("could not refresh orchestrator pool: TranscoderPool error", body)
+	assert.Equal(2, refresher.refreshes)
+}
+
 // Rounds
 func TestCurrentRoundHandler_Error(t *testing.T) {
 	assert := assert.New(t)
@@ -499,28 +575,48 @@ func TestSetOrchestratorPriceInfo(t *testing.T) {
 	s := stubServer()
 
 	// pricePerUnit is not an integer
-	err := s.setOrchestratorPriceInfo("default", "nil", "1")
+	err := s.setOrchestratorPriceInfo("default", "nil", "1", "")
 	assert.Error(t, err)
 	assert.True(t, strings.Contains(err.Error(), "pricePerUnit is not a valid integer"))
 
 	// pixelsPerUnit is not an integer
-	err = s.setOrchestratorPriceInfo("default", "1", "nil")
+	err = s.setOrchestratorPriceInfo("default", "1", "nil", "")
 	assert.Error(t, err)
 	assert.True(t, strings.Contains(err.Error(), "pixelsPerUnit is not a valid integer"))
 
-	err = s.setOrchestratorPriceInfo("default", "1", "1")
+	err = s.setOrchestratorPriceInfo("default", "1", "1", "")
 	assert.Nil(t, err)
 	assert.Zero(t, s.LivepeerNode.GetBasePrice("default").Cmp(big.NewRat(1, 1)))
 
-	err = s.setOrchestratorPriceInfo("default", "-5", "1")
+	err = s.setOrchestratorPriceInfo("default", "-5", "1", "")
 	assert.EqualErrorf(t, err, err.Error(), "price unit must be greater than or equal to 0, provided %d\n", -5)
 
 	// pixels per unit <= 0
-	err = s.setOrchestratorPriceInfo("default", "1", "0")
+	err = s.setOrchestratorPriceInfo("default", "1", "0", "")
 	assert.EqualErrorf(t, err, err.Error(), "pixels per unit must be greater than 0, provided %d\n", 0)
-	err = s.setOrchestratorPriceInfo("default", "1", "-5")
+	err = s.setOrchestratorPriceInfo("default", "1", "-5", "")
 	assert.EqualErrorf(t, err, err.Error(), "pixels per unit must be greater than 0, provided %d\n", -5)
 
+	// broadcaster address must match in full
+	err = s.setOrchestratorPriceInfo(ethcommon.Address{1}.Hex()+"ff", "1", "1", "")
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "broadcasterEthAddr is not a valid eth address"))
+	err = s.setOrchestratorPriceInfo("notdefault", "1", "1", "")
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "broadcasterEthAddr is not a valid eth address"))
+
+	err = s.setOrchestratorPriceInfo(ethcommon.Address{1}.Hex(), "3", "1", "")
+	assert.Nil(t, err)
+	assert.Zero(t, s.LivepeerNode.GetBasePrice(ethcommon.Address{1}.Hex()).Cmp(big.NewRat(3, 1)))
+
+	// capability prices
+	err = s.setOrchestratorPriceInfo("default", "1", "1", "foo")
+	assert.EqualError(t, err, "unknown capability: foo")
+	err = s.setOrchestratorPriceInfo(ethcommon.Address{1}.Hex(), "7", "2", "HEVC encode")
+	assert.Nil(t, err)
+	prices := s.LivepeerNode.GetCapabilityBasePrices()[strings.ToLower(ethcommon.Address{1}.Hex())]
+	assert.Zero(t, prices[core.Capability_HEVC_Encode].Cmp(big.NewRat(7, 2)))
+	assert.Zero(t, s.LivepeerNode.GetBasePrice(ethcommon.Address{1}.Hex()).Cmp(big.NewRat(3, 1)))
 }
 func TestSetPriceForBroadcasterHandler(t *testing.T) {
 	assert := assert.New(t)
@@ -607,6 +703,315 @@ func TestSetPriceForBroadcasterHandler_WrongInput(t *testing.T) {
 	assert.Equal(http.StatusBadRequest, status3)
 }
 
+func TestBroadcasterAccessHandlers(t *testing.T) {
+	assert := assert.New(t)
+	s := stubServer()
+	s.LivepeerNode.NodeType = core.OrchestratorNode
+	access := s.LivepeerNode.BroadcasterAccess
+	addr := ethcommon.Address{1}
+	form := url.Values{"broadcasterEthAddr": {addr.Hex()}}
+
+	status, _ := postForm(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Block), form)
+	assert.Equal(http.StatusOK, status)
+	assert.False(access.Allowed(addr))
+
+	status, body := get(s.broadcasterAccessListHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(fmt.Sprintf(`{"allowlist":[],"allowlistEnforced":false,"blocklist":["%v"]}`, addr.Hex()), body)
+
+	status, _ = postForm(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Unblock), form)
+	assert.Equal(http.StatusOK, status)
+	assert.True(access.Allowed(addr))
+
+	status, _ = postForm(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Allow), form)
+	assert.Equal(http.StatusOK, status)
+	assert.Equal([]ethcommon.Address{addr}, access.Allowlist())
+	assert.False(access.Allowed(ethcommon.Address{2}))
+
+	status, _ = postForm(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Disallow), form)
+	assert.Equal(http.StatusOK, status)
+	assert.False(access.Allowed(addr))
+	status, body = get(s.broadcasterAccessListHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`{"allowlist":[],"allowlistEnforced":true,"blocklist":[]}`, body)
+
+	status, _ = postForm(s.clearBroadcasterAllowlistHandler(), url.Values{})
+	assert.Equal(http.StatusOK, status)
+	assert.True(access.Allowed(addr))
+
+	status, _ = postForm(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Allow), form)
+	assert.Equal(http.StatusOK, status)
+
+	// invalid address
+	status, body = postForm(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Allow), url.Values{"broadcasterEthAddr": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("broadcasterEthAddr is not a valid eth address, provided foo", body)
+
+	// not an orchestrator
+	s.LivepeerNode.NodeType = core.BroadcasterNode
+	status, _ = postForm(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Disallow), form)
+	assert.Equal(http.StatusBadRequest, status)
+	status, _ = get(s.broadcasterAccessListHandler())
+	assert.Equal(http.StatusBadRequest, status)
+	status, _ = postForm(s.clearBroadcasterAllowlistHandler(), url.Values{})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal([]ethcommon.Address{addr}, access.Allowlist())
+}
+
+func TestDrainingHandlers(t *testing.T) {
+	assert := assert.New(t)
+	s := stubServer()
+	s.LivepeerNode.NodeType = core.OrchestratorNode
+
+	status, body := get(s.drainingHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`{"draining":false,"activeSessions":0}`, body)
+
+	status, _ = postForm(s.setDrainingHandler(), url.Values{"draining": {"true"}})
+	assert.Equal(http.StatusOK, status)
+	assert.True(s.LivepeerNode.Draining())
+
+	status, body = get(s.drainingHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`{"draining":true,"activeSessions":0}`, body)
+
+	status, body = postForm(s.setDrainingHandler(), url.Values{"draining": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("draining must be a boolean, provided foo", body)
+	assert.True(s.LivepeerNode.Draining())
+
+	status, _ = postForm(s.setDrainingHandler(), url.Values{"draining": {"false"}})
+	assert.Equal(http.StatusOK, status)
+	assert.False(s.LivepeerNode.Draining())
+
+	// not an orchestrator
+	s.LivepeerNode.NodeType = core.BroadcasterNode
+	status, _ = postForm(s.setDrainingHandler(), url.Values{"draining": {"true"}})
+	assert.Equal(http.StatusBadRequest, status)
+	status, _ = get(s.drainingHandler())
+	assert.Equal(http.StatusBadRequest, status)
+	assert.False(s.LivepeerNode.Draining())
+}
+
+func TestEarningsHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	s := stubServer()
+	s.LivepeerNode.NodeType = core.OrchestratorNode
+
+	// no database
+	status, body := get(s.earningsHandler())
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.Equal("database not available", body)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+	s.LivepeerNode.Database = dbh
+
+	sender := ethcommon.Address{1}
+	require.Nil(dbh.AddEarnings(&common.DBEarnings{Sender: sender, ManifestID: "foo", Round: 1, Tickets: 2, WinningTickets: 1, EV: big.NewRat(201, 2), WinningFaceValue: big.NewInt(1000), Pixels: 3000, Fees: big.NewRat(3000, 1)}))
+	require.Nil(dbh.AddEarnings(&common.DBEarnings{Sender: ethcommon.Address{2}, ManifestID: "bar", Round: 2, Tickets: 1, EV: big.NewRat(10, 1)}))
+
+	// earnings pending in the recorder are written before responding
+	s.LivepeerNode.Earnings = core.NewEarningsRecorder(dbh)
+	s.LivepeerNode.Earnings.Add(&common.DBEarnings{Sender: sender, ManifestID: "foo", Round: 1, Pixels: 1000, Fees: big.NewRat(1000, 1)})
+
+	status, body = get(s.earningsHandler())
+	assert.Equal(http.StatusOK, status)
+	var earnings []*common.DBEarnings
+	require.Nil(json.Unmarshal([]byte(body), &earnings))
+	assert.Len(earnings, 2)
+
+	status, body = postForm(s.earningsHandler(), url.Values{"sender": {sender.Hex()}, "round": {"1"}})
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(fmt.Sprintf(`[{"sender":"%v","manifestID":"foo","round":1,"tickets":2,"winningTickets":1,"ev":"201/2","winningFaceValue":1000,"pixels":4000,"fees":"4000"}]`, strings.ToLower(sender.Hex())), body)
+
+	// invalid filters
+	status, body = postForm(s.earningsHandler(), url.Values{"sender": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("sender is not a valid eth address, provided foo", body)
+	status, body = postForm(s.earningsHandler(), url.Values{"round": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("round is not a valid integer, provided foo", body)
+
+	// not an orchestrator
+	s.LivepeerNode.NodeType = core.BroadcasterNode
+	status, _ = get(s.earningsHandler())
+	assert.Equal(http.StatusBadRequest, status)
+}
+
+func TestTxCostsHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	s := stubServer()
+
+	// no database
+	status, body := get(s.txCostsHandler())
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.Equal("database not available", body)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+	s.LivepeerNode.Database = dbh
+
+	// no transactions
+	status, body = get(s.txCostsHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`[]`, body)
+
+	hour := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	require.Nil(dbh.InsertTransaction(&common.DBTransaction{TxHash: ethcommon.Hash{1}, Method: "reward", BlockNumber: 1, GasUsed: 100, GasPrice: big.NewInt(10), Cost: big.NewInt(1000), Time: hour}))
+	require.Nil(dbh.InsertTransaction(&common.DBTransaction{TxHash: ethcommon.Hash{2}, Method: "redeemWinningTicket", BlockNumber: 2, GasUsed: 50, GasPrice: big.NewInt(10), Cost: big.NewInt(500), Failed: true, Time: hour.Add(time.Hour)}))
+
+	status, body = postForm(s.txCostsHandler(), url.Values{"from": {"2021-06-01T10:00:00Z"}, "to": {strconv.FormatInt(hour.Add(time.Hour).Unix(), 10)}, "groupBy": {"method,hour"}})
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`[{"method":"reward","start":"2021-06-01T10:00:00Z","transactions":1,"failed":0,"gasUsed":100,"cost":1000}]`, body)
+
+	status, body = postForm(s.txCostsHandler(), url.Values{"method": {"redeemWinningTicket"}})
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`[{"transactions":1,"failed":1,"gasUsed":50,"cost":500}]`, body)
+
+	// invalid params
+	status, body = postForm(s.txCostsHandler(), url.Values{"from": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("from is not a valid unix or RFC3339 time, provided foo", body)
+	status, body = postForm(s.txCostsHandler(), url.Values{"to": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("to is not a valid unix or RFC3339 time, provided foo", body)
+	status, body = postForm(s.txCostsHandler(), url.Values{"groupBy": {"orchestrator"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("invalid groupBy=orchestrator", body)
+}
+
+func TestSpendingHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	s := stubServer()
+	s.LivepeerNode.NodeType = core.BroadcasterNode
+
+	// no database
+	status, body := get(s.spendingHandler())
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.Equal("database not available", body)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+	s.LivepeerNode.Database = dbh
+
+	// no spending
+	status, body = get(s.spendingHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`[]`, body)
+
+	orch := ethcommon.Address{1}
+	hour := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	require.Nil(dbh.AddSpending(&common.DBSpending{Recipient: orch, ManifestID: "foo", Hour: hour, Tickets: 2, EV: big.NewRat(201, 2)}))
+	require.Nil(dbh.AddSpending(&common.DBSpending{Recipient: ethcommon.Address{2}, ManifestID: "bar", Hour: hour.Add(time.Hour), Tickets: 1, EV: big.NewRat(10, 1)}))
+
+	// spending pending in the recorder is written before responding
+	defer func() { Spending = nil }()
+	Spending = NewSpendingRecorder(dbh)
+	Spending.RecordWinningTicket(orch, big.NewInt(1000))
+
+	status, body = postForm(s.spendingHandler(), url.Values{"from": {"2021-06-01T10:00:00Z"}, "to": {strconv.FormatInt(hour.Add(time.Hour).Unix(), 10)}, "groupBy": {"orchestrator,hour"}})
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(fmt.Sprintf(`[{"recipient":"%v","start":"2021-06-01T10:00:00Z","tickets":2,"ev":"201/2","winningTickets":0,"winningFaceValue":0}]`, strings.ToLower(orch.Hex())), body)
+
+	status, body = get(s.spendingHandler())
+	assert.Equal(http.StatusOK, status)
+	var report []*SpendingReport
+	require.Nil(json.Unmarshal([]byte(body), &report))
+	require.Len(report, 1)
+	assert.Equal(int64(3), report[0].Tickets)
+	assert.Equal(int64(1), report[0].WinningTickets)
+
+	// invalid params
+	status, body = postForm(s.spendingHandler(), url.Values{"from": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("from is not a valid unix or RFC3339 time, provided foo", body)
+	status, body = postForm(s.spendingHandler(), url.Values{"to": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("to is not a valid unix or RFC3339 time, provided foo", body)
+	status, body = postForm(s.spendingHandler(), url.Values{"groupBy": {"round"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("invalid groupBy=round", body)
+
+	// not a broadcaster
+	s.LivepeerNode.NodeType = core.OrchestratorNode
+	status, _ = get(s.spendingHandler())
+	assert.Equal(http.StatusBadRequest, status)
+}
+
+type stubRedemptionReporter struct {
+	stats    *pm.RedemptionStats
+	statuses []*pm.SenderRedemptionStatus
+	blocked  []*pm.BlockedSender
+	err      error
+}
+
+func (r *stubRedemptionReporter) RedemptionStats() *pm.RedemptionStats { return r.stats }
+
+func (r *stubRedemptionReporter) SenderStatuses() ([]*pm.SenderRedemptionStatus, error) {
+	return r.statuses, r.err
+}
+
+func (r *stubRedemptionReporter) BlockedSenders() []*pm.BlockedSender { return r.blocked }
+
+func TestRedemptionHandlers(t *testing.T) {
+	assert := assert.New(t)
+	s := stubServer()
+
+	// node doesn't redeem tickets
+	status, body := get(s.redemptionsHandler())
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("Node must redeem winning tickets to get redemptions", body)
+	status, body = get(s.redemptionSendersHandler())
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("Node must redeem winning tickets to get redemption senders", body)
+	status, body = get(s.blockedSendersHandler())
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("Node must redeem winning tickets to get blocked senders", body)
+
+	sender := ethcommon.Address{1}
+	txHash := ethcommon.Hash{2}
+	failedAt := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	reporter := &stubRedemptionReporter{
+		stats: &pm.RedemptionStats{
+			RedeemedTickets: 3,
+			ValueRedeemed:   big.NewInt(3000),
+			GasSpent:        big.NewInt(100),
+			Submitted:       []*pm.SubmittedRedemption{},
+			Failed:          []*pm.RedemptionFailure{{Time: failedAt, Sender: sender, Tickets: 1, FaceValue: big.NewInt(1000), TxHash: &txHash, Reason: "transaction failed", Retryable: false}},
+		},
+		statuses: []*pm.SenderRedemptionStatus{{Sender: sender, MaxFloat: big.NewInt(500), PendingTickets: 2, PendingAmount: big.NewInt(0)}},
+		blocked:  []*pm.BlockedSender{{Sender: sender, Reason: "3 unredeemable winning tickets", Until: failedAt}},
+	}
+	s.LivepeerNode.Redemptions = reporter
+
+	status, body = get(s.redemptionsHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(fmt.Sprintf(`{"redeemedTickets":3,"valueRedeemed":3000,"gasSpent":100,"submitted":[],"failed":[{"time":"2021-06-01T10:00:00Z","sender":"%v","tickets":1,"faceValue":1000,"txHash":"%v","reason":"transaction failed","retryable":false}]}`, strings.ToLower(sender.Hex()), txHash.Hex()), body)
+
+	status, body = get(s.redemptionSendersHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(fmt.Sprintf(`[{"sender":"%v","maxFloat":500,"pendingTickets":2,"pendingAmount":0}]`, strings.ToLower(sender.Hex())), body)
+
+	status, body = get(s.blockedSendersHandler())
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(fmt.Sprintf(`[{"sender":"%v","reason":"3 unredeemable winning tickets","until":"2021-06-01T10:00:00Z"}]`, strings.ToLower(sender.Hex())), body)
+
+	reporter.err = errors.New("some error")
+	status, body = get(s.redemptionSendersHandler())
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.Equal("some error", body)
+}
+
 // Bond, withdraw, reward
 func TestBondHandler(t *testing.T) {
 	assert := assert.New(t)
@@ -1574,7 +1979,10 @@ func dummyHandler() http.Handler {
 func stubServer() *LivepeerServer {
 	n, _ := core.NewLivepeerNode(nil, "", nil)
 	return &LivepeerServer{
-		LivepeerNode: n,
+		LivepeerNode:      n,
+		rtmpConnections:   make(map[core.ManifestID]*rtmpConnection),
+		internalManifests: make(map[core.ManifestID]core.ManifestID),
+		connectionLock:    &sync.RWMutex{},
 	}
 }
 
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var errIngestJWT = errors.New("invalid ingest JWT")
+
+// TranscodeJWTSubject is the subject of the JWTs only valid for the /transcode/ endpoint. It can't be the
+// name of a stream since stream names don't contain slashes.
+const TranscodeJWTSubject = "/transcode"
+
+// IngestJWTKeys, if set, maps the issuers of the JWTs accepted on HTTP ingest requests to the key
+// their signatures are verified with: a []byte HMAC secret, an *rsa.PublicKey or an *ecdsa.PublicKey
+var IngestJWTKeys map[string]interface{}
+
+// ParseIngestJWTKeys reads <issuer>=<key file> entries. Key files contain either a PEM encoded public key
+// for RS256 or ES256 tokens, or an HMAC secret for HS256 tokens.
+func ParseIngestJWTKeys(entries []string) (map[string]interface{}, error) {
+	keys := make(map[string]interface{})
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.Index(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid ingest JWT key, expected <issuer>=<key file>: %v", entry)
+		}
+		data, err := ioutil.ReadFile(entry[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseIngestJWTKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ingest JWT key for issuer=%v: %w", entry[:i], err)
+		}
+		keys[entry[:i]] = key
+	}
+	return keys, nil
+}
+
+func parseIngestJWTKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		secret := []byte(strings.TrimSpace(string(data)))
+		if len(secret) == 0 {
+			return nil, errors.New("empty HMAC secret")
+		}
+		return secret, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", key)
+}
+
+type ingestJWTHeader struct {
+	Alg string `json:"alg"`
+}
+
+type ingestJWTClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Expiry    int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+}
+
+// getIngestJWT returns the bearer token of an ingest request, if any
+func getIngestJWT(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+}
+
+// verifyIngestJWT checks the signature and the registered claims of token, and returns the remaining
+// claims, which have the same fields as the auth webhook response. A token with a subject is only
+// valid for the stream with that name.
+func verifyIngestJWT(keys map[string]interface{}, token, streamName string, now time.Time) (*authWebhookResponse, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errIngestJWT
+	}
+	var header ingestJWTHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, errIngestJWT
+	}
+	var claims ingestJWTClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, errIngestJWT
+	}
+	key, ok := keys[claims.Issuer]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown issuer=%v", errIngestJWT, claims.Issuer)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errIngestJWT
+	}
+	if err := verifyJWTSignature(key, header.Alg, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, fmt.Errorf("%w: %v", errIngestJWT, err)
+	}
+	if claims.Expiry == 0 || now.Unix() >= claims.Expiry {
+		return nil, fmt.Errorf("%w: expired", errIngestJWT)
+	}
+	if claims.NotBefore != 0 && now.Unix() < claims.NotBefore {
+		return nil, fmt.Errorf("%w: not valid yet", errIngestJWT)
+	}
+	if claims.Subject != "" && claims.Subject != streamName {
+		return nil, fmt.Errorf("%w: not valid for stream=%v", errIngestJWT, streamName)
+	}
+	var resp authWebhookResponse
+	if err := decodeJWTPart(parts[1], &resp); err != nil {
+		return nil, errIngestJWT
+	}
+	resp.authenticated = true
+	return &resp, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verifyJWTSignature checks that alg matches the type of key before checking the signature, so that
+// tokens can't pick a weaker algorithm than the one the issuer is configured with
+func verifyJWTSignature(key interface{}, alg, signed string, sig []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+	switch k := key.(type) {
+	case []byte:
+		if alg != "HS256" {
+			break
+		}
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			break
+		}
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig)
+	case *ecdsa.PublicKey:
+		if alg != "ES256" {
+			break
+		}
+		if len(sig) != 64 {
+			return errors.New("invalid signature")
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(k, digest[:], r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported alg=%v", alg)
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signIngestJWT(t *testing.T, alg string, key interface{}, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.Nil(t, err)
+	payload, err := json.Marshal(claims)
+	require.Nil(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.Nil(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.Nil(t, err)
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestIngestJWT_Verify(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(err)
+	keys := map[string]interface{}{
+		"hmac": []byte("secret"),
+		"rsa":  &rsaKey.PublicKey,
+		"ec":   &ecKey.PublicKey,
+	}
+	now := time.Now()
+	claims := func(iss string) map[string]interface{} {
+		return map[string]interface{}{"iss": iss, "exp": now.Add(time.Minute).Unix(), "manifestID": "foo", "presets": []string{"P240p30fps16x9"}}
+	}
+
+	// every supported algorithm, with the claims returned as a webhook response
+	for iss, token := range map[string]string{
+		"hmac": signIngestJWT(t, "HS256", []byte("secret"), claims("hmac")),
+		"rsa":  signIngestJWT(t, "RS256", rsaKey, claims("rsa")),
+		"ec":   signIngestJWT(t, "ES256", ecKey, claims("ec")),
+	} {
+		resp, err := verifyIngestJWT(keys, token, "movie", now)
+		require.Nil(err, iss)
+		assert.Equal("foo", resp.ManifestID)
+		assert.Equal([]string{"P240p30fps16x9"}, resp.Presets)
+		assert.True(resp.authenticated)
+	}
+
+	// wrong key, or an algorithm that doesn't match the key of the issuer
+	_, err = verifyIngestJWT(keys, signIngestJWT(t, "HS256", []byte("other"), claims("hmac")), "movie", now)
+	assert.ErrorIs(err, errIngestJWT)
+	_, err = verifyIngestJWT(keys, signIngestJWT(t, "HS256", []byte("secret"), claims("rsa")), "movie", now)
+	assert.ErrorIs(err, errIngestJWT)
+	_, err = verifyIngestJWT(keys, signIngestJWT(t, "none", nil, claims("hmac")), "movie", now)
+	assert.ErrorIs(err, errIngestJWT)
+
+	// unknown issuer
+	_, err = verifyIngestJWT(keys, signIngestJWT(t, "HS256", []byte("secret"), claims("foo")), "movie", now)
+	assert.ErrorIs(err, errIngestJWT)
+
+	// expired, missing expiry or not valid yet
+	token := signIngestJWT(t, "HS256", []byte("secret"), claims("hmac"))
+	_, err = verifyIngestJWT(keys, token, "movie", now.Add(time.Minute))
+	assert.ErrorIs(err, errIngestJWT)
+	_, err = verifyIngestJWT(keys, signIngestJWT(t, "HS256", []byte("secret"), map[string]interface{}{"iss": "hmac"}), "movie", now)
+	assert.ErrorIs(err, errIngestJWT)
+	c := claims("hmac")
+	c["nbf"] = now.Add(time.Minute).Unix()
+	_, err = verifyIngestJWT(keys, signIngestJWT(t, "HS256", []byte("secret"), c), "movie", now)
+	assert.ErrorIs(err, errIngestJWT)
+
+	// tokens with a subject are only valid for that stream
+	c = claims("hmac")
+	c["sub"] = "movie"
+	token = signIngestJWT(t, "HS256", []byte("secret"), c)
+	_, err = verifyIngestJWT(keys, token, "movie", now)
+	assert.Nil(err)
+	_, err = verifyIngestJWT(keys, token, "other", now)
+	assert.ErrorIs(err, errIngestJWT)
+
+	// malformed
+	_, err = verifyIngestJWT(keys, "foo.bar", "movie", now)
+	assert.ErrorIs(err, errIngestJWT)
+	_, err = verifyIngestJWT(keys, token+"x", "movie", now)
+	assert.ErrorIs(err, errIngestJWT)
+}
+
+func TestIngestJWT_ParseKeys(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(err)
+	der, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	require.Nil(err)
+	require.Nil(ioutil.WriteFile(filepath.Join(dir, "ec.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644))
+	require.Nil(ioutil.WriteFile(filepath.Join(dir, "secret"), []byte("secret\n"), 0644))
+	require.Nil(ioutil.WriteFile(filepath.Join(dir, "empty"), []byte("\n"), 0644))
+
+	keys, err := ParseIngestJWTKeys([]string{"ec=" + filepath.Join(dir, "ec.pem"), " hmac=" + filepath.Join(dir, "secret"), ""})
+	require.Nil(err)
+	assert.Len(keys, 2)
+	assert.Equal(&ecKey.PublicKey, keys["ec"])
+	assert.Equal([]byte("secret"), keys["hmac"])
+
+	_, err = ParseIngestJWTKeys([]string{filepath.Join(dir, "secret")})
+	assert.EqualError(err, "invalid ingest JWT key, expected <issuer>=<key file>: "+filepath.Join(dir, "secret"))
+	_, err = ParseIngestJWTKeys([]string{"hmac=" + filepath.Join(dir, "missing")})
+	assert.NotNil(err)
+	_, err = ParseIngestJWTKeys([]string{"hmac=" + filepath.Join(dir, "empty")})
+	assert.EqualError(err, "invalid ingest JWT key for issuer=hmac: empty HMAC secret")
+}
+
+func TestIngestJWT_HandlePush(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func() { IngestJWTKeys = nil }()
+	IngestJWTKeys = map[string]interface{}{"hmac": []byte("secret")}
+	s := &LivepeerServer{}
+
+	push := func(token string) int {
+		req := httptest.NewRequest("POST", "/live/movie/1.ts", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		s.HandlePush(w, req)
+		return w.Result().StatusCode
+	}
+
+	// a token is required without an auth webhook to fall back to
+	assert.Equal(http.StatusUnauthorized, push(""))
+	token := signIngestJWT(t, "HS256", []byte("other"), map[string]interface{}{"iss": "hmac", "exp": time.Now().Add(time.Minute).Unix()})
+	assert.Equal(http.StatusUnauthorized, push(token))
+}
+
+func TestIngestJWT_Transcode(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func() { IngestJWTKeys = nil }()
+	key := []byte("secret")
+	IngestJWTKeys = map[string]interface{}{"hmac": key}
+	s := &LivepeerServer{}
+	token := func(sub string) string {
+		return signIngestJWT(t, "HS256", key, map[string]interface{}{"iss": "hmac", "sub": sub, "exp": time.Now().Add(time.Minute).Unix()})
+	}
+	push := func(path, token string) int {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		if path == "/transcode/1.ts" {
+			s.HandleTranscode(w, req)
+		} else {
+			s.HandlePush(w, req)
+		}
+		return w.Result().StatusCode
+	}
+
+	// the subject of the transcode tokens is not a stream name, and stream tokens are not valid for transcode
+	assert.Equal(http.StatusUnauthorized, push("/transcode/1.ts", token("movie")))
+	assert.Equal(http.StatusUnauthorized, push("/live/movie/1.ts", token(TranscodeJWTSubject)))
+
+	_, err := verifyIngestJWT(IngestJWTKeys, token(TranscodeJWTSubject), TranscodeJWTSubject, time.Now())
+	assert.Nil(err)
+}
+
+func TestIngestJWT_SkipsAuthWebhook(t *testing.T) {
+	assert := assert.New(t)
+
+	var webhookCalled bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhookCalled = true
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+	defer func() { AuthWebhookURL = nil }()
+	AuthWebhookURL, _ = url.Parse(ts.URL)
+
+	s, cancel := setupServerWithCancel()
+	defer serverCleanup(s)
+	defer cancel()
+	u, _ := url.Parse("http://hot/live/movie/1.ts")
+
+	// the webhook denies the stream
+	assert.Nil(createRTMPStreamIDHandler(context.TODO(), s, nil)(u))
+	assert.True(webhookCalled)
+
+	// claims of a verified JWT are used instead of calling the webhook
+	webhookCalled = false
+	resp := &authWebhookResponse{ManifestID: "jwtmani", authenticated: true}
+	data := createRTMPStreamIDHandler(context.TODO(), s, resp)(u)
+	assert.False(webhookCalled)
+	if assert.NotNil(data) {
+		assert.Equal("jwtmani", string(streamParams(data).ManifestID))
+	}
+}
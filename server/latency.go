@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+)
+
+// Weight given to a new latency sample in the moving average
+const latencySampleWeight = 0.5
+
+// Latency at which an orchestrator's selection weight is halved
+const selectionLatencyRef = 100 * time.Millisecond
+
+// Maximum number of orchestrators probed concurrently
+const maxConcurrentLatencyProbes = 20
+
+// OrchestratorLatencies tracks a moving average of the round trip time to orchestrators, keyed by host
+type OrchestratorLatencies struct {
+	mu        sync.RWMutex
+	latencies map[string]time.Duration
+}
+
+// NewOrchestratorLatencies creates an empty OrchestratorLatencies
+func NewOrchestratorLatencies() *OrchestratorLatencies {
+	return &OrchestratorLatencies{latencies: make(map[string]time.Duration)}
+}
+
+// Observe records a latency sample for the orchestrator at uri
+func (l *OrchestratorLatencies) Observe(uri *url.URL, rtt time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if prev, ok := l.latencies[uri.Host]; ok {
+		rtt = time.Duration(latencySampleWeight*float64(rtt) + (1-latencySampleWeight)*float64(prev))
+	}
+	l.latencies[uri.Host] = rtt
+}
+
+// Latency returns the average latency of the orchestrator at uri and whether it has been probed
+func (l *OrchestratorLatencies) Latency(uri string) (time.Duration, bool) {
+	if l == nil {
+		return 0, false
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return 0, false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	rtt, ok := l.latencies[u.Host]
+	return rtt, ok
+}
+
+// retain drops the latencies of all hosts not in hosts
+func (l *OrchestratorLatencies) retain(hosts map[string]bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for host := range l.latencies {
+		if !hosts[host] {
+			delete(l.latencies, host)
+		}
+	}
+}
+
+// selectionWeight scales the selection weight of an orchestrator by its latency.
+// Orchestrators that have not been probed are weighted as if their latency was selectionLatencyRef.
+func (l *OrchestratorLatencies) selectionWeight(uri string) float64 {
+	rtt, ok := l.Latency(uri)
+	if !ok {
+		rtt = selectionLatencyRef
+	}
+	return float64(selectionLatencyRef) / float64(selectionLatencyRef+rtt)
+}
+
+// StartLatencyProbing periodically measures the latency to the orchestrators in the pool until ctx is done.
+// The returned latencies are meant to be passed to the session selectors to favor low latency orchestrators.
+func StartLatencyProbing(ctx context.Context, pool common.OrchestratorPool, interval time.Duration) *OrchestratorLatencies {
+	latencies := NewOrchestratorLatencies()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			probeOrchestrators(ctx, pool, latencies, probeOrchestratorLatency)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return latencies
+}
+
+// probeOrchestrators probes the orchestrators in the pool, at most maxConcurrentLatencyProbes at a time,
+// and drops the latencies of orchestrators that are no longer in the pool
+func probeOrchestrators(ctx context.Context, pool common.OrchestratorPool, latencies *OrchestratorLatencies,
+	probe func(context.Context, *url.URL) (time.Duration, error)) {
+
+	infos := pool.GetInfos()
+	hosts := make(map[string]bool, len(infos))
+	sem := make(chan struct{}, maxConcurrentLatencyProbes)
+	var wg sync.WaitGroup
+	for _, info := range infos {
+		hosts[info.URL.Host] = true
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(uri *url.URL) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			rtt, err := probe(ctx, uri)
+			if err != nil {
+				glog.V(common.DEBUG).Infof("Unable to probe orchestrator latency orch=%v err=%q", uri, err)
+				return
+			}
+			latencies.Observe(uri, rtt)
+		}(info.URL)
+	}
+	wg.Wait()
+	latencies.retain(hosts)
+}
+
+// probeOrchestratorLatency returns the round trip time of a Ping to the orchestrator
+func probeOrchestratorLatency(ctx context.Context, uri *url.URL) (time.Duration, error) {
+	c, conn, err := startOrchestratorClient(ctx, uri)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, GRPCTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.Ping(ctx, &net.PingPong{Value: []byte("latency")}); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrchestratorLatencies(t *testing.T) {
+	assert := assert.New(t)
+	l := NewOrchestratorLatencies()
+
+	uri, _ := url.Parse("https://127.0.0.1:8935")
+	_, ok := l.Latency(uri.String())
+	assert.False(ok)
+	assert.Equal(0.5, l.selectionWeight(uri.String()))
+
+	l.Observe(uri, 100*time.Millisecond)
+	rtt, ok := l.Latency(uri.String())
+	assert.True(ok)
+	assert.Equal(100*time.Millisecond, rtt)
+
+	// moving average of samples
+	l.Observe(uri, 300*time.Millisecond)
+	rtt, _ = l.Latency(uri.String())
+	assert.Equal(200*time.Millisecond, rtt)
+
+	// keyed by host
+	rtt, ok = l.Latency("https://127.0.0.1:8935/foo")
+	assert.True(ok)
+	assert.Equal(200*time.Millisecond, rtt)
+	assert.InDelta(1.0/3, l.selectionWeight(uri.String()), 0.0001)
+
+	// nil latencies
+	var nilLatencies *OrchestratorLatencies
+	_, ok = nilLatencies.Latency(uri.String())
+	assert.False(ok)
+}
+
+func TestMinLSSelector_SelectUnknownSession_Latency(t *testing.T) {
+	assert := assert.New(t)
+
+	latencies := NewOrchestratorLatencies()
+	newSession := func(uri string, addr ethcommon.Address) *BroadcastSession {
+		return &BroadcastSession{
+			OrchestratorInfo: &net.OrchestratorInfo{
+				Transcoder:   uri,
+				TicketParams: &net.TicketParams{Recipient: addr.Bytes()},
+			},
+		}
+	}
+	observe := func(uri string, rtt time.Duration) {
+		u, _ := url.Parse(uri)
+		latencies.Observe(u, rtt)
+	}
+	observe("https://slow.example.com:8935", 100*time.Second)
+	observe("https://fast.example.com:8935", time.Millisecond)
+
+	// off-chain, the session with the lowest latency is selected first, unprobed sessions last
+	sel := NewMinLSSelector(nil, 1.0)
+	sel.latencies = latencies
+	unprobed := newSession("https://unknown.example.com:8935", ethcommon.Address{3})
+	slow := newSession("https://slow.example.com:8935", ethcommon.Address{1})
+	fast := newSession("https://fast.example.com:8935", ethcommon.Address{2})
+	sel.Add([]*BroadcastSession{unprobed, slow, fast})
+	assert.Same(fast, sel.selectUnknownSession(context.TODO()))
+	assert.Same(slow, sel.selectUnknownSession(context.TODO()))
+	assert.Same(unprobed, sel.selectUnknownSession(context.TODO()))
+	assert.Nil(sel.selectUnknownSession(context.TODO()))
+
+	// on-chain, stake weights are scaled down by latency
+	stakeRdr := newStubStakeReader()
+	stakeRdr.SetStakes(map[ethcommon.Address]int64{{1}: 100, {2}: 100})
+	for i := 0; i < 10; i++ {
+		sel = NewMinLSSelector(stakeRdr, 1.0)
+		sel.latencies = latencies
+		sel.Add([]*BroadcastSession{slow, fast})
+		assert.Same(fast, sel.selectUnknownSession(context.TODO()))
+	}
+}
+
+type stubInfosPool struct {
+	common.OrchestratorPool
+	infos []common.OrchestratorLocalInfo
+}
+
+func (p *stubInfosPool) GetInfos() []common.OrchestratorLocalInfo {
+	return p.infos
+}
+
+func TestProbeOrchestrators(t *testing.T) {
+	assert := assert.New(t)
+
+	pool := &stubInfosPool{}
+	for i := 0; i < 3*maxConcurrentLatencyProbes; i++ {
+		u, _ := url.Parse(fmt.Sprintf("https://127.0.0.%v:8935", i))
+		pool.infos = append(pool.infos, common.OrchestratorLocalInfo{URL: u})
+	}
+	failing := pool.infos[0].URL.Host
+
+	var (
+		mu                    sync.Mutex
+		inflight, maxInflight int
+	)
+	probe := func(ctx context.Context, uri *url.URL) (time.Duration, error) {
+		mu.Lock()
+		inflight++
+		if inflight > maxInflight {
+			maxInflight = inflight
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		inflight--
+		mu.Unlock()
+		if uri.Host == failing {
+			return 0, errors.New("probe error")
+		}
+		return 10 * time.Millisecond, nil
+	}
+
+	latencies := NewOrchestratorLatencies()
+	probeOrchestrators(context.TODO(), pool, latencies, probe)
+	assert.LessOrEqual(maxInflight, maxConcurrentLatencyProbes)
+	assert.Len(latencies.latencies, len(pool.infos)-1)
+	_, ok := latencies.Latency(pool.infos[1].URL.String())
+	assert.True(ok)
+
+	// orchestrators that left the pool are evicted
+	removed := pool.infos[1].URL.String()
+	pool.infos = pool.infos[2:]
+	probeOrchestrators(context.TODO(), pool, latencies, probe)
+	assert.Len(latencies.latencies, len(pool.infos))
+	_, ok = latencies.Latency(removed)
+	assert.False(ok)
+}
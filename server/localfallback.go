@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/livepeer/go-livepeer/clog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/monitor"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
+)
+
+// Maximum frame rate of the rendition transcoded by the local fallback
+const localFallbackMaxFramerate = 30
+
+// LocalFallback, if set, transcodes segments on the broadcaster when no orchestrator could transcode them
+var LocalFallback *LocalFallbackTranscoder
+
+// LocalFallbackTranscoder transcodes segments with the software transcoder at a reduced profile to keep
+// streams playable while no orchestrator is available. The reduced profile is transcoded once per container
+// format of the stream's renditions, and each rendition's playlist gets the output in its own format.
+type LocalFallbackTranscoder struct {
+	workDir    string
+	transcoder core.Transcoder
+}
+
+// NewLocalFallbackTranscoder creates a LocalFallbackTranscoder that writes temporary files to workDir
+func NewLocalFallbackTranscoder(workDir string) *LocalFallbackTranscoder {
+	return &LocalFallbackTranscoder{
+		workDir:    workDir,
+		transcoder: core.NewLocalTranscoder(workDir),
+	}
+}
+
+// localFallbackProfile returns the lowest resolution profile with the frame rate capped at localFallbackMaxFramerate
+func localFallbackProfile(profiles []ffmpeg.VideoProfile) ffmpeg.VideoProfile {
+	lowest := profiles[0]
+	for _, p := range profiles[1:] {
+		w, h, err1 := ffmpeg.VideoProfileResolution(p)
+		lw, lh, err2 := ffmpeg.VideoProfileResolution(lowest)
+		if err1 == nil && err2 == nil && w*h < lw*lh {
+			lowest = p
+		}
+	}
+	den := lowest.FramerateDen
+	if den == 0 {
+		den = 1
+	}
+	if lowest.Framerate == 0 || lowest.Framerate/den > localFallbackMaxFramerate {
+		lowest.Framerate = localFallbackMaxFramerate
+		lowest.FramerateDen = 1
+	}
+	return lowest
+}
+
+func (lf *LocalFallbackTranscoder) transcode(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment) ([]string, error) {
+	profiles := cxn.params.Profiles
+	profile := localFallbackProfile(profiles)
+	// One output per container format, so that no rendition gets a segment in the format of another one
+	var outProfiles []ffmpeg.VideoProfile
+	outIdx := make(map[ffmpeg.Format]int)
+	for _, p := range profiles {
+		if _, ok := outIdx[p.Format]; ok {
+			continue
+		}
+		outIdx[p.Format] = len(outProfiles)
+		out := profile
+		out.Format = p.Format
+		outProfiles = append(outProfiles, out)
+	}
+	clog.Infof(ctx, "Transcoding segment locally as fallback profile=%s formats=%d", profile.Name, len(outProfiles))
+
+	if err := os.MkdirAll(lf.workDir, 0700); err != nil {
+		return nil, err
+	}
+	fname := path.Join(lf.workDir, common.RandName()+".tempfile")
+	if err := ioutil.WriteFile(fname, seg.Data, 0644); err != nil {
+		return nil, err
+	}
+	defer os.Remove(fname)
+
+	md := &core.SegTranscodingMetadata{
+		ManifestID: cxn.mid,
+		Fname:      fname,
+		Seq:        int64(seg.SeqNo),
+		Profiles:   outProfiles,
+	}
+	td, err := lf.transcoder.Transcode(ctx, md)
+	if err != nil {
+		return nil, fmt.Errorf("local fallback transcode failed: %w", err)
+	}
+	if len(td.Segments) < len(outProfiles) {
+		return nil, fmt.Errorf("local fallback transcode failed: expected segments=%d got=%d", len(outProfiles), len(td.Segments))
+	}
+	for _, s := range td.Segments[:len(outProfiles)] {
+		if len(s.Data) == 0 {
+			return nil, fmt.Errorf("local fallback transcode failed: empty transcoded segment")
+		}
+	}
+
+	cpl := cxn.pl
+	var urls []string
+	for i := range profiles {
+		data := td.Segments[outIdx[profiles[i].Format]].Data
+		ext, err := common.ProfileFormatExtension(profiles[i].Format)
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("%s/%d%s", profiles[i].Name, seg.SeqNo, ext)
+		uri, err := cpl.GetOSSession().SaveData(ctx, name, bytes.NewReader(data), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := cpl.InsertHLSSegment(&profiles[i], seg.SeqNo, uri, seg.Duration); err != nil {
+			clog.Errorf(ctx, "Playlist insertion error seqNo=%d err=%q", seg.SeqNo, err)
+			if monitor.Enabled {
+				monitor.SegmentTranscodeFailed(ctx, monitor.SegmentTranscodeErrorDuplicateSegment, cxn.nonce, seg.SeqNo, err, false)
+			}
+		}
+		urls = append(urls, uri)
+	}
+	return urls, nil
+}
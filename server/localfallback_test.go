@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalFallbackProfile(t *testing.T) {
+	assert := assert.New(t)
+
+	// Picks the lowest resolution and caps the frame rate
+	p := localFallbackProfile([]ffmpeg.VideoProfile{ffmpeg.P720p60fps16x9, ffmpeg.P360p30fps16x9, ffmpeg.P576p30fps16x9})
+	assert.Equal(ffmpeg.P360p30fps16x9.Name, p.Name)
+	assert.Equal(uint(30), p.Framerate)
+
+	p = localFallbackProfile([]ffmpeg.VideoProfile{ffmpeg.P720p60fps16x9})
+	assert.Equal(ffmpeg.P720p60fps16x9.Name, p.Name)
+	assert.Equal(uint(30), p.Framerate)
+	assert.Equal(uint(1), p.FramerateDen)
+
+	// Passthrough frame rate is capped too
+	passthrough := ffmpeg.P240p30fps16x9
+	passthrough.Framerate = 0
+	p = localFallbackProfile([]ffmpeg.VideoProfile{passthrough})
+	assert.Equal(uint(30), p.Framerate)
+
+	// Frame rates below the cap are kept
+	low := ffmpeg.P240p30fps16x9
+	low.Framerate = 24
+	p = localFallbackProfile([]ffmpeg.VideoProfile{low})
+	assert.Equal(uint(24), p.Framerate)
+}
+
+func TestProcessSegment_LocalFallback(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldFallback := LocalFallback
+	defer func() { LocalFallback = oldFallback }()
+
+	tr := &stubTranscoder{}
+	LocalFallback = &LocalFallbackTranscoder{workDir: t.TempDir(), transcoder: tr}
+
+	// No sessions available so the segment isn't transcoded by any orchestrator
+	bsm := bsmWithSessListExt([]*BroadcastSession{}, nil, true)
+	ostore := &stubOSSession{}
+	pl := &stubPlaylistManager{os: ostore}
+	profiles := []ffmpeg.VideoProfile{ffmpeg.P720p60fps16x9, ffmpeg.P144p30fps16x9}
+	cxn := &rtmpConnection{
+		mid:         "dummy1",
+		params:      &core.StreamParameters{ManifestID: "dummy1", Profiles: profiles},
+		profile:     &ffmpeg.VideoProfile{Name: "unused"},
+		sessManager: bsm,
+		pl:          pl,
+	}
+	seg := &stream.HLSSegment{Data: []byte("dummy"), SeqNo: 7, Duration: 2.0}
+
+	urls, err := processSegment(context.Background(), cxn, seg, nil)
+	require.Nil(err)
+	assert.Equal(1, tr.called)
+	require.Len(tr.profiles, 1)
+	assert.Equal(ffmpeg.P144p30fps16x9.Name, tr.profiles[0].Name)
+	// Output is inserted into the playlist of every rendition in its format
+	assert.Equal([]string{"saved_P720p60fps16x9/7.ts", "saved_P144p30fps16x9/7.ts"}, urls)
+	assert.Equal([]string{"unused/7.ts", "P720p60fps16x9/7.ts", "P144p30fps16x9/7.ts"}, ostore.saved)
+	assert.Equal(ffmpeg.P144p30fps16x9.Name, pl.profile.Name)
+	assert.Equal(uint64(7), pl.seq)
+
+	// Local transcode failure leaves the original result in place
+	tr.err = errors.New("transcode error")
+	ostore.saved = nil
+	urls, err = processSegment(context.Background(), cxn, seg, nil)
+	assert.Nil(err)
+	assert.Empty(urls)
+	assert.Equal(2, tr.called)
+	assert.Equal([]string{"unused/7.ts"}, ostore.saved)
+
+	// The reduced profile is transcoded once per container format of the renditions
+	tr.err = nil
+	ostore.saved = nil
+	mp4 := ffmpeg.P360p30fps16x9
+	mp4.Format = ffmpeg.FormatMP4
+	cxn.params.Profiles = []ffmpeg.VideoProfile{ffmpeg.P720p60fps16x9, mp4, ffmpeg.P144p30fps16x9}
+	urls, err = processSegment(context.Background(), cxn, seg, nil)
+	require.Nil(err)
+	assert.Equal(3, tr.called)
+	require.Len(tr.profiles, 2)
+	assert.Equal(ffmpeg.P144p30fps16x9.Name, tr.profiles[0].Name)
+	assert.Equal(ffmpeg.FormatNone, tr.profiles[0].Format)
+	assert.Equal(ffmpeg.P144p30fps16x9.Name, tr.profiles[1].Name)
+	assert.Equal(ffmpeg.FormatMP4, tr.profiles[1].Format)
+	assert.Equal([]string{"saved_P720p60fps16x9/7.ts", "saved_P360p30fps16x9/7.mp4", "saved_P144p30fps16x9/7.ts"}, urls)
+	cxn.params.Profiles = profiles
+
+	// Not used when disabled
+	LocalFallback = nil
+	_, err = processSegment(context.Background(), cxn, seg, nil)
+	assert.Nil(err)
+	assert.Equal(3, tr.called)
+}
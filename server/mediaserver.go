@@ -30,6 +30,7 @@ import (
 	"github.com/livepeer/go-livepeer/clog"
 	"github.com/livepeer/go-livepeer/monitor"
 	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/go-livepeer/storage"
 	"github.com/livepeer/go-tools/drivers"
 
 	"github.com/golang/glog"
@@ -67,6 +68,15 @@ var DetectionWhClient = &http.Client{Timeout: 2 * time.Second}
 
 var SelectRandFreq float64
 
+// StakeWeightedSelection enables selecting every session with a probability proportional to its orchestrator's stake
+var StakeWeightedSelection bool
+
+// RedundantTranscoding enables submitting the segments of every stream to two orchestrators in parallel
+var RedundantTranscoding bool
+
+// SplitRenditions enables transcoding the highest rendition and the other renditions of every stream on different orchestrators
+var SplitRenditions bool
+
 func PixelFormatNone() ffmpeg.PixelFormat {
 	return ffmpeg.PixelFormat{RawValue: ffmpeg.PixelFormatNone}
 }
@@ -87,9 +97,12 @@ type rtmpConnection struct {
 	profile         *ffmpeg.VideoProfile
 	params          *core.StreamParameters
 	sessManager     *BroadcastSessionsManager
+	startedAt       time.Time
 	lastUsed        time.Time
 	sourceBytes     uint64
 	transcodedBytes uint64
+	debugStats      *streamDebugStats
+	health          *streamHealth
 }
 
 func (s *LivepeerServer) getActiveRtmpConnectionUnsafe(mid core.ManifestID) (*rtmpConnection, bool) {
@@ -109,11 +122,15 @@ type LivepeerServer struct {
 	HTTPMux                 *http.ServeMux
 	ExposeCurrentManifest   bool
 	recordingsAuthResponses *cache.Cache
+	// OrchLatencies, if set, holds the probed orchestrator latencies used during session selection
+	OrchLatencies *OrchestratorLatencies
 
 	// Thread sensitive fields. All accesses to the
 	// following fields should be protected by `connectionLock`
 	rtmpConnections   map[core.ManifestID]*rtmpConnection
 	internalManifests map[core.ManifestID]core.ManifestID
+	// Manifest IDs of the terminated streams rejected until their expiry
+	terminatedStreams map[core.ManifestID]time.Time
 	lastHLSStreamID   core.StreamID
 	lastManifestID    core.ManifestID
 	context           context.Context
@@ -148,6 +165,18 @@ type authWebhookResponse struct {
 	} `json:"detection"`
 	VerificationFreq  uint `json:"verificationFreq"`
 	TimeoutMultiplier int  `json:"timeoutMultiplier"`
+	// Service URIs of the orchestrators to use for the stream, in order of preference
+	Orchestrators []string `json:"orchestrators"`
+	// Submit each segment to two orchestrators in parallel and use the first valid result
+	RedundantTranscoding bool `json:"redundantTranscoding"`
+	// Transcode the highest rendition and the other renditions on different orchestrators
+	SplitRenditions bool `json:"splitRenditions"`
+	// Overrides the retry policy for the segments of the stream
+	Retry *authWebhookRetryPolicy `json:"retry"`
+
+	// Set when the stream was already authenticated, either from the claims of a verified ingest JWT or
+	// with the /transcode/ URL of a stateless push, in which case the auth webhook is not called
+	authenticated bool
 }
 
 func NewLivepeerServer(rtmpAddr string, lpNode *core.LivepeerNode, httpIngest bool, transcodingOptions string) (*LivepeerServer, error) {
@@ -193,6 +222,7 @@ func NewLivepeerServer(rtmpAddr string, lpNode *core.LivepeerNode, httpIngest bo
 	}
 	if lpNode.NodeType == core.BroadcasterNode && httpIngest {
 		opts.HttpMux.HandleFunc("/live/", ls.HandlePush)
+		opts.HttpMux.HandleFunc("/transcode/", ls.HandleTranscode)
 	}
 	opts.HttpMux.HandleFunc("/recordings/", ls.HandleRecordings)
 	return ls, nil
@@ -265,9 +295,11 @@ func createRTMPStreamIDHandler(_ctx context.Context, s *LivepeerServer, webhookR
 
 		// do not replace captured _ctx variable
 		ctx := clog.AddNonce(_ctx, nonce)
-		if resp, err = authenticateStream(AuthWebhookURL, url.String()); err != nil {
-			clog.Errorf(ctx, "Authentication denied for streamID url=%s err=%q", url.String(), err)
-			return nil
+		if webhookResponseOverride == nil || !webhookResponseOverride.authenticated {
+			if resp, err = authenticateStream(AuthWebhookURL, url.String()); err != nil {
+				clog.Errorf(ctx, "Authentication denied for streamID url=%s err=%q", url.String(), err)
+				return nil
+			}
 		}
 
 		// If we've received auth in header AND callback URL forms then for now, we reject cases where they're
@@ -309,19 +341,25 @@ func createRTMPStreamIDHandler(_ctx context.Context, s *LivepeerServer, webhookR
 
 			// set OS if it was provided
 			if resp.ObjectStore != "" {
-				os, err = drivers.ParseOSURL(resp.ObjectStore, false)
+				os, err = storage.ParseOSURL(resp.ObjectStore, false)
 				if err != nil {
 					clog.Errorf(ctx, "Failed to parse object store url for streamID url=%s err=%q", url.String(), err)
 					return nil
 				}
+				if storage.IsRecordOnly(os) {
+					clog.Errorf(ctx, "Object store for streamID can only be used as record object store url=%s", url.String())
+					return nil
+				}
+				os = storage.NewRetryDriver(os, storage.DefaultRetryPolicy)
 			}
 			// set Recording OS if it was provided
 			if resp.RecordObjectStore != "" {
-				ros, err = drivers.ParseOSURL(resp.RecordObjectStore, true)
+				ros, err = storage.ParseOSURL(resp.RecordObjectStore, true)
 				if err != nil {
 					clog.Errorf(ctx, "Failed to parse recording object store url for streamID url=%s err=%q", url.String(), err)
 					return nil
 				}
+				ros = storage.NewRetryDriver(ros, storage.DefaultRetryPolicy)
 			}
 
 			// set Detection profile if provided
@@ -351,7 +389,7 @@ func createRTMPStreamIDHandler(_ctx context.Context, s *LivepeerServer, webhookR
 		ctx = clog.AddManifestID(ctx, string(mid))
 
 		if os != nil {
-			oss = os.NewSession(string(mid))
+			oss = storage.StreamUsage.NewSession(os.NewSession(string(mid)), string(mid), storage.UsageObjectStore)
 		}
 
 		recordPath := fmt.Sprintf("%s/%s", extmid, monitor.NodeID)
@@ -360,6 +398,22 @@ func createRTMPStreamIDHandler(_ctx context.Context, s *LivepeerServer, webhookR
 		} else if drivers.RecordStorage != nil {
 			ross = drivers.RecordStorage.NewSession(recordPath)
 		}
+		ross = storage.StreamUsage.NewSession(ross, string(mid), storage.UsageRecordStore)
+		if storage.RecordPathTemplate != nil {
+			ross = storage.RecordPathTemplate.NewSession(ross, storage.PathVars{
+				ManifestID: string(extmid),
+				SessionID:  sessionID,
+				NodeID:     monitor.NodeID,
+				Start:      time.Now(),
+			})
+		}
+		if ross != nil && storage.RecordArchiver != nil {
+			ross = storage.RecordArchiver.NewSession(ross, recordPath)
+		}
+		if s.isTerminated(mid) || s.isTerminated(extmid) {
+			clog.Errorf(ctx, "Rejecting terminated stream url=%s", url.String())
+			return nil
+		}
 		// Ensure there's no concurrent StreamID with the same name
 		s.connectionLock.RLock()
 		defer s.connectionLock.RUnlock()
@@ -367,7 +421,7 @@ func createRTMPStreamIDHandler(_ctx context.Context, s *LivepeerServer, webhookR
 			clog.Errorf(ctx, "Too many connections for streamID url=%s err=%q", url.String(), err)
 			return nil
 		}
-		return &core.StreamParameters{
+		params := &core.StreamParameters{
 			ManifestID:       mid,
 			ExternalStreamID: extStreamID,
 			SessionID:        sessionID,
@@ -380,9 +434,40 @@ func createRTMPStreamIDHandler(_ctx context.Context, s *LivepeerServer, webhookR
 			VerificationFreq: VerificationFreq,
 			Nonce:            nonce,
 		}
+		params.RedundantTranscoding = RedundantTranscoding
+		params.SplitRenditions = SplitRenditions
+		if resp != nil {
+			params.RedundantTranscoding = params.RedundantTranscoding || resp.RedundantTranscoding
+			params.SplitRenditions = params.SplitRenditions || resp.SplitRenditions
+			if resp.Retry != nil {
+				params.RetryPolicy = resp.Retry.apply(RetryPolicy)
+			}
+			if params.PreferredOrchestrators, err = parsePreferredOrchestrators(resp.Orchestrators); err != nil {
+				clog.Errorf(ctx, "Failed to parse preferred orchestrators for streamID url=%s err=%q", url.String(), err)
+				return nil
+			}
+		}
+		return params
 	}
 }
 
+// parsePreferredOrchestrators parses the orchestrator service URIs returned by the auth webhook.
+// The scheme defaults to https if omitted.
+func parsePreferredOrchestrators(orchs []string) ([]*url.URL, error) {
+	var uris []*url.URL
+	for _, orch := range orchs {
+		if !strings.Contains(orch, "://") {
+			orch = "https://" + orch
+		}
+		uri, err := url.ParseRequestURI(orch)
+		if err != nil || uri.Host == "" {
+			return nil, fmt.Errorf("invalid orchestrator service URI %q", orch)
+		}
+		uris = append(uris, uri)
+	}
+	return uris, nil
+}
+
 func jsonDetectionToDetectionConfig(ctx context.Context, resp *authWebhookResponse) (core.DetectionConfig, error) {
 	detection := core.DetectionConfig{
 		Freq:               resp.Detection.Freq,
@@ -506,6 +591,10 @@ func (s *LivepeerServer) registerConnection(ctx context.Context, rtmpStrm stream
 	}
 	if params.OS == nil {
 		params.OS = drivers.NodeStorage.NewSession(string(mid))
+		// The saves to the memory of the node aren't accounted for
+		if params.OS.IsExternal() {
+			params.OS = storage.StreamUsage.NewSession(params.OS, string(mid), storage.UsageObjectStore)
+		}
 	}
 	storage := params.OS
 
@@ -541,7 +630,10 @@ func (s *LivepeerServer) registerConnection(ctx context.Context, rtmpStrm stream
 		pl:           playlist,
 		profile:      &vProfile,
 		params:       params,
+		startedAt:    time.Now(),
 		lastUsed:     time.Now(),
+		debugStats:   newStreamDebugStats(),
+		health:       newStreamHealth(),
 	}
 	s.connectionLock.Lock()
 	oldCxn, exists := s.getActiveRtmpConnectionUnsafe(mid)
@@ -559,12 +651,13 @@ func (s *LivepeerServer) registerConnection(ctx context.Context, rtmpStrm stream
 	if s.LivepeerNode.Eth != nil {
 		stakeRdr = &storeStakeReader{store: s.LivepeerNode.Database}
 	}
-	selFactory := func() BroadcastSessionsSelector {
-		return NewMinLSSelectorWithRandFreq(stakeRdr, SELECTOR_LATENCY_SCORE_THRESHOLD, SelectRandFreq)
+	selFactory := func(randFreq float64) BroadcastSessionsSelector {
+		return newSessionsSelector(stakeRdr, randFreq, s.OrchLatencies, OrchStats, OrchRegions, Reputation)
 	}
 
 	// safe, because other goroutines should be waiting on initializing channel
 	cxn.sessManager = NewSessionManager(ctx, s.LivepeerNode, params, selFactory)
+	cxn.sessManager.health = cxn.health
 
 	// populate fields and signal initializing channel
 	s.serverLock.Lock()
@@ -619,10 +712,16 @@ func removeRTMPStream(ctx context.Context, s *LivepeerServer, extmid core.Manife
 	cxn.stream.Close()
 	cxn.sessManager.cleanup(ctx)
 	cxn.pl.Cleanup()
+	if archive, ok := cxn.pl.GetRecordOSSession().(*storage.ArchiveSession); ok {
+		archive.Complete()
+	}
+	SpendLimits.RemoveStream(intmid)
+	storage.StreamUsage.EndStream(string(intmid))
 	clog.Infof(ctx, "Ended stream with manifestID=%s external manifestID=%s", intmid, extmid)
 	delete(s.rtmpConnections, intmid)
 	delete(s.internalManifests, extmid)
 
+	publishStreamHealth(cxn, cxn.health.info(), true)
 	if monitor.Enabled {
 		monitor.StreamEnded(ctx, cxn.nonce)
 		monitor.CurrentSessions(len(s.rtmpConnections))
@@ -632,6 +731,43 @@ func removeRTMPStream(ctx context.Context, s *LivepeerServer, extmid core.Manife
 	return nil
 }
 
+// terminateStream ends the stream, disconnecting its publisher, and rejects the stream until blockFor elapses so
+// that the publisher can't restart it right away
+func (s *LivepeerServer) terminateStream(ctx context.Context, mid core.ManifestID, blockFor time.Duration) error {
+	now := time.Now()
+	s.connectionLock.Lock()
+	for terminated, expiry := range s.terminatedStreams {
+		if !now.Before(expiry) {
+			delete(s.terminatedStreams, terminated)
+		}
+	}
+	if blockFor > 0 {
+		if s.terminatedStreams == nil {
+			s.terminatedStreams = make(map[core.ManifestID]time.Time)
+		}
+		// Block the stream first, for a segment pushed meanwhile not to restart it
+		s.terminatedStreams[mid] = now.Add(blockFor)
+	}
+	s.connectionLock.Unlock()
+
+	if err := removeRTMPStream(ctx, s, mid); err != nil {
+		s.connectionLock.Lock()
+		delete(s.terminatedStreams, mid)
+		s.connectionLock.Unlock()
+		return err
+	}
+	clog.Infof(ctx, "Terminated stream with manifestID=%s blockFor=%s", mid, blockFor)
+	return nil
+}
+
+// isTerminated returns whether the stream was terminated and is still rejected
+func (s *LivepeerServer) isTerminated(mid core.ManifestID) bool {
+	s.connectionLock.RLock()
+	defer s.connectionLock.RUnlock()
+	expiry, ok := s.terminatedStreams[mid]
+	return ok && time.Now().Before(expiry)
+}
+
 //End RTMP Publish Handlers
 
 // HLS Play Handlers
@@ -740,6 +876,28 @@ type BreakOperation bool
 
 // HandlePush processes request for HTTP ingest
 func (s *LivepeerServer) HandlePush(w http.ResponseWriter, r *http.Request) {
+	s.handlePush(w, r, false)
+}
+
+// HandleTranscode processes a request to transcode a single segment outside of any stream.
+// The segment is pushed to a stream that only lives for the duration of the request and the
+// renditions are always returned in a multipart response.
+func (s *LivepeerServer) HandleTranscode(w http.ResponseWriter, r *http.Request) {
+	fname := path.Base(r.URL.Path)
+	if fname == "." || fname == "/" || fname == "transcode" {
+		httpErr := fmt.Sprintf(`Bad URL url=%s`, r.URL)
+		glog.Error(httpErr)
+		http.Error(w, httpErr, http.StatusBadRequest)
+		return
+	}
+	r.Header.Set("Accept", "multipart/mixed")
+	s.handlePush(w, r, true)
+}
+
+// handlePush transcodes a pushed segment. Stateless pushes are authenticated with their /transcode/ URL
+// and then pushed to a stream with a random manifest ID, which is not kept alive by a watchdog;
+// their stream is removed as soon as the segment has been processed.
+func (s *LivepeerServer) handlePush(w http.ResponseWriter, r *http.Request, stateless bool) {
 	errorOut := func(status int, s string, params ...interface{}) {
 		httpErr := fmt.Sprintf(s, params...)
 		glog.Error(httpErr)
@@ -760,6 +918,34 @@ func (s *LivepeerServer) HandlePush(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A verified JWT replaces both the auth webhook and the transcode config header
+	streamName := string(parseManifestID(r.URL.Path))
+	if stateless {
+		streamName = TranscodeJWTSubject
+	}
+	if IngestJWTKeys != nil {
+		if token := getIngestJWT(r); token != "" {
+			authHeaderConfig, err = verifyIngestJWT(IngestJWTKeys, token, streamName, time.Now())
+			if err != nil {
+				errorOut(http.StatusUnauthorized, `http push authentication failed url=%s err=%q`, r.URL, err)
+				return
+			}
+		} else if AuthWebhookURL == nil {
+			errorOut(http.StatusUnauthorized, `http push missing JWT url=%s`, r.URL)
+			return
+		}
+	}
+
+	if stateless {
+		// The auth webhook is called with the /transcode/ URL, since the random manifest ID of the stream
+		// can't be told apart from the name of a live stream
+		if authHeaderConfig, err = authenticateTranscode(r, authHeaderConfig); err != nil {
+			errorOut(http.StatusForbidden, `Authentication denied for transcode url=%s err=%q`, r.URL, err)
+			return
+		}
+		r.URL.Path = "/live/" + string(core.RandomManifestID()) + "/" + path.Base(r.URL.Path)
+	}
+
 	body, err := common.ReadAtMost(r.Body, common.MaxSegSize)
 	if err != nil {
 		errorOut(http.StatusInternalServerError, `Error reading http request body: %s`, err.Error())
@@ -819,6 +1005,10 @@ func (s *LivepeerServer) HandlePush(w http.ResponseWriter, r *http.Request) {
 		errorOut(http.StatusBadRequest, "Bad URL url=%s", r.URL)
 		return
 	}
+	if s.isTerminated(mid) {
+		errorOut(http.StatusForbidden, "Stream terminated manifestID=%s", mid)
+		return
+	}
 	s.connectionLock.RLock()
 	if intmid, exists := s.internalManifests[mid]; exists {
 		mid = intmid
@@ -858,6 +1048,10 @@ func (s *LivepeerServer) HandlePush(w http.ResponseWriter, r *http.Request) {
 
 	// Check for presence and register if a fresh cxn
 	if !exists {
+		if s.isTerminated(mid) {
+			errorOut(http.StatusForbidden, "Stream terminated manifestID=%s", mid)
+			return
+		}
 		appData := (createRTMPStreamIDHandler(ctx, s, authHeaderConfig))(r.URL)
 		if appData == nil {
 			errorOut(http.StatusInternalServerError, "Could not create stream ID: url=%s", r.URL)
@@ -902,10 +1096,13 @@ func (s *LivepeerServer) HandlePush(w http.ResponseWriter, r *http.Request) {
 		cxn, err = s.registerConnection(ctx, st, vcodec, mediaFormat.PixFormat, segPar)
 		if err != nil {
 			st.Close()
-			if err != errAlreadyExists {
+			// stateless pushes must not share the stream of another push
+			if err != errAlreadyExists || stateless {
 				errorOut(http.StatusInternalServerError, "http push error url=%s err=%q", r.URL, err)
 				return
 			} // else we continue with the old cxn
+		} else if stateless {
+			defer removeRTMPStream(context.TODO(), s, mid)
 		} else {
 			// Start a watchdog to remove session after a period of inactivity
 			ticker := time.NewTicker(httpPushTimeout)
@@ -1334,7 +1531,7 @@ func (s *LivepeerServer) HandleRecordings(w http.ResponseWriter, r *http.Request
 	ctx = clog.AddManifestID(ctx, manifestID)
 
 	if resp != nil && resp.RecordObjectStore != "" {
-		os, err := drivers.ParseOSURL(resp.RecordObjectStore, true)
+		os, err := storage.ParseOSURL(resp.RecordObjectStore, true)
 		if err != nil {
 			clog.Errorf(ctx, "Error parsing OS URL err=%q request url=%s", err, r.URL)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -1509,7 +1706,17 @@ func (s *LivepeerServer) HandleRecordings(w http.ResponseWriter, r *http.Request
 			if resp != nil {
 				osUrl = resp.RecordObjectStoreURL
 			}
-			mainJspl.AddSegmentsToMPL(manifests, track, mpl, osUrl)
+			if osUrl == "" && storage.SignsURLs(sess) {
+				// Reference the segments with pre-signed URLs of the record store instead of serving them
+				mainJspl.AddSegmentsToMPL(nil, track, mpl, "")
+				for _, mseg := range mpl.Segments {
+					if mseg != nil {
+						mseg.URI = storage.SignURL(sess, mseg.URI)
+					}
+				}
+			} else {
+				mainJspl.AddSegmentsToMPL(manifests, track, mpl, osUrl)
+			}
 			// check (debug code)
 			startSeq := mpl.Segments[0].SeqId
 			for _, seg := range mpl.Segments[1:] {
@@ -1649,7 +1856,7 @@ func (s *LivepeerServer) LatestPlaylist() core.PlaylistManager {
 
 func shouldStopStream(err error) bool {
 	_, ok := err.(pm.ErrSenderValidation)
-	return ok
+	return ok || err == errStreamSpendLimitExceeded
 }
 
 func getRemoteAddr(r *http.Request) string {
@@ -690,6 +690,53 @@ func TestCreateRTMPStreamHandlerWebhook(t *testing.T) {
 	defer ts19.Close()
 	sid = createSid(u)
 	assert.Nil(sid)
+
+	// set preferred orchestrators
+	ts20 := makeServer(`{"manifestID":"a", "orchestrators": ["https://o1.example.com:8935", "o2.example.com:8935"]}`)
+	defer ts20.Close()
+	params = createSid(u).(*core.StreamParameters)
+	require.Len(t, params.PreferredOrchestrators, 2)
+	assert.Equal("https://o1.example.com:8935", params.PreferredOrchestrators[0].String())
+	assert.Equal("https://o2.example.com:8935", params.PreferredOrchestrators[1].String())
+
+	// do not create stream if a preferred orchestrator is invalid
+	ts21 := makeServer(`{"manifestID":"a", "orchestrators": ["https://"]}`)
+	defer ts21.Close()
+	sid = createSid(u)
+	assert.Nil(sid)
+
+	// redundant transcoding per stream
+	ts22 := makeServer(`{"manifestID":"a"}`)
+	defer ts22.Close()
+	params = createSid(u).(*core.StreamParameters)
+	assert.False(params.RedundantTranscoding)
+	ts23 := makeServer(`{"manifestID":"a", "redundantTranscoding": true}`)
+	defer ts23.Close()
+	params = createSid(u).(*core.StreamParameters)
+	assert.True(params.RedundantTranscoding)
+
+	// redundant transcoding for all streams
+	RedundantTranscoding = true
+	defer func() { RedundantTranscoding = false }()
+	ts24 := makeServer(`{"manifestID":"a"}`)
+	defer ts24.Close()
+	params = createSid(u).(*core.StreamParameters)
+	assert.True(params.RedundantTranscoding)
+
+	// split renditions per stream
+	assert.False(params.SplitRenditions)
+	ts25 := makeServer(`{"manifestID":"a", "splitRenditions": true}`)
+	defer ts25.Close()
+	params = createSid(u).(*core.StreamParameters)
+	assert.True(params.SplitRenditions)
+
+	// split renditions for all streams
+	SplitRenditions = true
+	defer func() { SplitRenditions = false }()
+	ts26 := makeServer(`{"manifestID":"a"}`)
+	defer ts26.Close()
+	params = createSid(u).(*core.StreamParameters)
+	assert.True(params.SplitRenditions)
 }
 
 func TestCreateRTMPStreamHandler(t *testing.T) {
@@ -1322,6 +1369,11 @@ func TestShouldStopStream(t *testing.T) {
 	assert.False(ok)
 	ok = shouldStopStream(pm.ErrSenderValidation{})
 	assert.True(ok)
+	ok = shouldStopStream(errStreamSpendLimitExceeded)
+	assert.True(ok)
+	// the stream is only paused until the hourly spend is reset
+	ok = shouldStopStream(errHourlySpendLimitExceeded)
+	assert.False(ok)
 }
 
 func TestParseManifestID(t *testing.T) {
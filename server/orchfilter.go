@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+)
+
+// OrchFilter, if set, restricts which discovered orchestrators the broadcaster may select
+var OrchFilter *OrchestratorFilter
+
+// OrchestratorFilter restricts orchestrator selection to an allowlist and excludes a blocklist.
+// Orchestrators are matched by ETH address or by service URI.
+type OrchestratorFilter struct {
+	allow *orchMatcher
+	block *orchMatcher
+}
+
+type orchMatcher struct {
+	addrs map[ethcommon.Address]bool
+	// hosts holds the host:port of entries with a port and hostnames holds the host of entries without one
+	hosts     map[string]bool
+	hostnames map[string]bool
+}
+
+// NewOrchestratorFilter creates an OrchestratorFilter from lists of ETH addresses and service URIs.
+// If allowlist is empty, all orchestrators that are not in the blocklist are allowed.
+func NewOrchestratorFilter(allowlist, blocklist []string) (*OrchestratorFilter, error) {
+	allow, err := newOrchMatcher(allowlist)
+	if err != nil {
+		return nil, err
+	}
+	block, err := newOrchMatcher(blocklist)
+	if err != nil {
+		return nil, err
+	}
+	return &OrchestratorFilter{allow: allow, block: block}, nil
+}
+
+func newOrchMatcher(entries []string) (*orchMatcher, error) {
+	m := &orchMatcher{
+		addrs:     make(map[ethcommon.Address]bool),
+		hosts:     make(map[string]bool),
+		hostnames: make(map[string]bool),
+	}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if ethcommon.IsHexAddress(entry) {
+			m.addrs[ethcommon.HexToAddress(entry)] = true
+			continue
+		}
+		if !strings.Contains(entry, "://") {
+			entry = "https://" + entry
+		}
+		uri, err := url.Parse(entry)
+		if err != nil || uri.Host == "" {
+			return nil, fmt.Errorf("invalid orchestrator address or service URI: %v", entry)
+		}
+		if uri.Port() != "" {
+			m.hosts[uri.Host] = true
+		} else {
+			m.hostnames[uri.Hostname()] = true
+		}
+	}
+	return m, nil
+}
+
+func (m *orchMatcher) empty() bool {
+	return len(m.addrs) == 0 && len(m.hosts) == 0 && len(m.hostnames) == 0
+}
+
+func (m *orchMatcher) matchesURI(uri *url.URL) bool {
+	return uri != nil && (m.hosts[uri.Host] || m.hostnames[uri.Hostname()])
+}
+
+// matchesLocal returns whether the URI of an orchestrator, or the address registered for it, matches
+func (m *orchMatcher) matchesLocal(local *common.OrchestratorLocalInfo) bool {
+	if local == nil {
+		return false
+	}
+	return m.matchesURI(local.URL) || local.Address != nil && m.addrs[*local.Address]
+}
+
+// matches returns whether an orchestrator matches. Orchestrators are matched by the address registered for their URI
+// if discovery knows it, since an orchestrator could report any address. The addresses reported in the info are only
+// used for orchestrators whose address discovery doesn't know, e.g. those of offchain pools of URIs.
+func (m *orchMatcher) matches(local *common.OrchestratorLocalInfo, info *net.OrchestratorInfo) bool {
+	if m.matchesLocal(local) {
+		return true
+	}
+	if local != nil && local.Address != nil || info == nil {
+		return false
+	}
+	if len(info.Address) > 0 && m.addrs[ethcommon.BytesToAddress(info.Address)] {
+		return true
+	}
+	if recipient := info.GetTicketParams().GetRecipient(); len(recipient) > 0 && m.addrs[ethcommon.BytesToAddress(recipient)] {
+		return true
+	}
+	return false
+}
+
+// AllowsLocal returns whether an orchestrator may be selected, as far as can be told before fetching its info
+func (f *OrchestratorFilter) AllowsLocal(local *common.OrchestratorLocalInfo) bool {
+	if f == nil {
+		return true
+	}
+	if f.block.matchesLocal(local) {
+		return false
+	}
+	// Orchestrators can only be excluded up front if the allowlist can't match them by a reported address later
+	if !f.allow.empty() && (len(f.allow.addrs) == 0 || local != nil && local.Address != nil) {
+		return f.allow.matchesLocal(local)
+	}
+	return true
+}
+
+// Allows returns whether an orchestrator that returned info may be selected
+func (f *OrchestratorFilter) Allows(local *common.OrchestratorLocalInfo, info *net.OrchestratorInfo) bool {
+	if f == nil {
+		return true
+	}
+	if f.block.matches(local, info) {
+		return false
+	}
+	if f.allow.empty() {
+		return true
+	}
+	return f.allow.matches(local, info)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrchestratorFilter(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	uri := func(s string) *common.OrchestratorLocalInfo {
+		u, err := url.Parse(s)
+		require.Nil(err)
+		return &common.OrchestratorLocalInfo{URL: u}
+	}
+	addr1 := ethcommon.Address{1}
+	addr2 := ethcommon.Address{2}
+
+	// nil filter allows everything
+	var f *OrchestratorFilter
+	assert.True(f.AllowsLocal(uri("https://127.0.0.1:8935")))
+	assert.True(f.Allows(uri("https://127.0.0.1:8935"), &net.OrchestratorInfo{}))
+
+	// invalid entries
+	_, err := NewOrchestratorFilter([]string{"https://"}, nil)
+	assert.EqualError(err, "invalid orchestrator address or service URI: https://")
+	_, err = NewOrchestratorFilter(nil, []string{"%zz"})
+	assert.NotNil(err)
+
+	// blocklist by address and URI
+	f, err = NewOrchestratorFilter([]string{""}, []string{addr1.Hex(), " 10.0.0.1:8935", "blocked.example.com"})
+	require.Nil(err)
+	assert.False(f.AllowsLocal(uri("https://10.0.0.1:8935")))
+	assert.True(f.AllowsLocal(uri("https://10.0.0.1:8936")))
+	assert.False(f.AllowsLocal(uri("https://blocked.example.com:9000")))
+	assert.False(f.Allows(uri("https://127.0.0.1:8935"), &net.OrchestratorInfo{Address: addr1.Bytes()}))
+	assert.False(f.Allows(uri("https://127.0.0.1:8935"), &net.OrchestratorInfo{TicketParams: &net.TicketParams{Recipient: addr1.Bytes()}}))
+	assert.True(f.Allows(uri("https://127.0.0.1:8935"), &net.OrchestratorInfo{Address: addr2.Bytes()}))
+
+	// allowlist of URIs excludes other orchestrators before fetching their info
+	f, err = NewOrchestratorFilter([]string{"https://10.0.0.1:8935"}, nil)
+	require.Nil(err)
+	assert.True(f.AllowsLocal(uri("https://10.0.0.1:8935")))
+	assert.False(f.AllowsLocal(uri("https://10.0.0.2:8935")))
+	assert.True(f.Allows(uri("https://10.0.0.1:8935"), &net.OrchestratorInfo{}))
+	assert.False(f.Allows(uri("https://10.0.0.2:8935"), &net.OrchestratorInfo{}))
+
+	// allowlist with addresses can only be checked once the info is known
+	f, err = NewOrchestratorFilter([]string{addr2.Hex(), "10.0.0.1:8935"}, []string{"10.0.0.3"})
+	require.Nil(err)
+	assert.True(f.AllowsLocal(uri("https://10.0.0.2:8935")))
+	assert.False(f.AllowsLocal(uri("https://10.0.0.3:8935")))
+	assert.True(f.Allows(uri("https://10.0.0.2:8935"), &net.OrchestratorInfo{Address: addr2.Bytes()}))
+	assert.True(f.Allows(uri("https://10.0.0.1:8935"), &net.OrchestratorInfo{Address: addr1.Bytes()}))
+	assert.False(f.Allows(uri("https://10.0.0.2:8935"), &net.OrchestratorInfo{Address: addr1.Bytes()}))
+	assert.False(f.Allows(uri("https://10.0.0.3:8935"), &net.OrchestratorInfo{Address: addr2.Bytes()}))
+
+	// orchestrators are matched by the address registered for their URI if discovery knows it
+	registered := func(s string, addr ethcommon.Address) *common.OrchestratorLocalInfo {
+		local := uri(s)
+		local.Address = &addr
+		return local
+	}
+	f, err = NewOrchestratorFilter(nil, []string{addr1.Hex()})
+	require.Nil(err)
+	assert.False(f.AllowsLocal(registered("https://10.0.0.1:8935", addr1)))
+	assert.False(f.Allows(registered("https://10.0.0.1:8935", addr1), &net.OrchestratorInfo{Address: addr2.Bytes()}))
+	assert.True(f.AllowsLocal(registered("https://10.0.0.2:8935", addr2)))
+	assert.True(f.Allows(registered("https://10.0.0.2:8935", addr2), &net.OrchestratorInfo{Address: addr1.Bytes()}))
+	assert.True(f.Allows(registered("https://10.0.0.2:8935", addr2), &net.OrchestratorInfo{TicketParams: &net.TicketParams{Recipient: addr1.Bytes()}}))
+
+	// and can't get on an allowlist by reporting an allowed address, which excludes them before fetching their info
+	f, err = NewOrchestratorFilter([]string{addr2.Hex()}, nil)
+	require.Nil(err)
+	assert.False(f.AllowsLocal(registered("https://10.0.0.1:8935", addr1)))
+	assert.False(f.Allows(registered("https://10.0.0.1:8935", addr1), &net.OrchestratorInfo{Address: addr2.Bytes()}))
+	assert.True(f.AllowsLocal(registered("https://10.0.0.2:8935", addr2)))
+	assert.True(f.Allows(registered("https://10.0.0.2:8935", addr2), &net.OrchestratorInfo{}))
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"errors"
+	gonet "net"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/alert"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/monitor"
+)
+
+// Round trip of a segment at which an orchestrator's selection weight is halved
+const selectionRoundTripRef = 2 * time.Second
+
+// orchStatsFlushInterval is how often the orchestrator stats accumulated in memory are written to the DB
+var orchStatsFlushInterval = 1 * time.Minute
+
+// orchUnreachableFailures is the number of consecutive segments that fail to reach an orchestrator after which an alert is fired
+const orchUnreachableFailures = 3
+
+// OrchStats, if set, records the performance of orchestrators and is used to favor well performing orchestrators during selection
+var OrchStats *OrchestratorStats
+
+// OrchestratorStats tracks the success rate, timeouts and round trip time of segments submitted to orchestrators,
+// keyed by service URI. The stats are loaded from and periodically written to the DB so they survive restarts.
+type OrchestratorStats struct {
+	mu     sync.RWMutex
+	totals map[string]*common.DBOrchStats
+
+	writes *common.BatchWriter
+}
+
+// NewOrchestratorStats creates an OrchestratorStats initialized with the stats stored in db
+func NewOrchestratorStats(db *common.DB) (*OrchestratorStats, error) {
+	merge := func(total, delta interface{}) interface{} {
+		stats := delta.(*common.DBOrchStats)
+		if total == nil {
+			total = &common.DBOrchStats{ServiceURI: stats.ServiceURI}
+		}
+		addOrchStats(total.(*common.DBOrchStats), stats)
+		return total
+	}
+	write := func(record interface{}) error {
+		stats := record.(*common.DBOrchStats)
+		if err := db.AddOrchStats(stats); err != nil {
+			glog.Errorf("Error recording orchestrator stats orch=%v err=%q", stats.ServiceURI, err)
+			return err
+		}
+		return nil
+	}
+	s := &OrchestratorStats{
+		totals: make(map[string]*common.DBOrchStats),
+		writes: common.NewBatchWriter(merge, write),
+	}
+	stored, err := db.SelectOrchStats()
+	if err != nil {
+		return nil, err
+	}
+	for _, stats := range stored {
+		s.totals[stats.ServiceURI] = stats
+	}
+	return s, nil
+}
+
+// Observe records the outcome of a segment submitted to the orchestrator at uri
+func (s *OrchestratorStats) Observe(uri string, success, timeout bool, roundTrip time.Duration) {
+	if s == nil {
+		return
+	}
+	delta := &common.DBOrchStats{ServiceURI: uri, Segments: 1}
+	if success {
+		delta.Successes = 1
+		delta.RoundTripMs = roundTrip.Milliseconds()
+	}
+	if timeout {
+		delta.Timeouts = 1
+	}
+
+	s.mu.Lock()
+	stats, ok := s.totals[uri]
+	if !ok {
+		stats = &common.DBOrchStats{ServiceURI: uri}
+		s.totals[uri] = stats
+	}
+	addOrchStats(stats, delta)
+	s.mu.Unlock()
+	s.writes.Add(uri, delta)
+}
+
+// addOrchStats adds the counts of delta to stats
+func addOrchStats(stats, delta *common.DBOrchStats) {
+	stats.Segments += delta.Segments
+	stats.Successes += delta.Successes
+	stats.Timeouts += delta.Timeouts
+	stats.RoundTripMs += delta.RoundTripMs
+}
+
+// Stats returns the stats of the orchestrator at uri and whether any segment was submitted to it
+func (s *OrchestratorStats) Stats(uri string) (common.DBOrchStats, bool) {
+	if s == nil {
+		return common.DBOrchStats{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stats, ok := s.totals[uri]
+	if !ok {
+		return common.DBOrchStats{}, false
+	}
+	return *stats, true
+}
+
+// selectionWeight scores the orchestrator at uri by its success rate and average round trip.
+// Timeouts count as two failures since they also stall the stream for the length of the timeout.
+// Orchestrators without stats are weighted as if they had a 50% success rate and a round trip of selectionRoundTripRef.
+func (s *OrchestratorStats) selectionWeight(uri string) float64 {
+	stats, _ := s.Stats(uri)
+	successRate := float64(stats.Successes+1) / float64(stats.Segments+stats.Timeouts+2)
+	roundTrip := selectionRoundTripRef
+	if stats.Successes > 0 {
+		roundTrip = time.Duration(stats.RoundTripMs/stats.Successes) * time.Millisecond
+	}
+	return successRate * float64(selectionRoundTripRef) / float64(selectionRoundTripRef+roundTrip)
+}
+
+// Flush writes the stats observed since the last flush to the DB. Stats that fail to be written are kept pending.
+func (s *OrchestratorStats) Flush() error {
+	return s.writes.Flush()
+}
+
+// StartFlush periodically writes the observed stats to the DB until StopFlush is called
+func (s *OrchestratorStats) StartFlush() {
+	s.writes.Start(orchStatsFlushInterval)
+}
+
+// StopFlush stops the flush loop and writes any pending stats to the DB
+func (s *OrchestratorStats) StopFlush() {
+	s.writes.Stop()
+}
+
+// observeSubmission records the outcome of submitting a segment to the orchestrator of sess
+func observeSubmission(sess *BroadcastSession, start time.Time, res *ReceivedTranscodeResult, err error) {
+	roundTrip := time.Since(start)
+	success := err == nil && res != nil
+	if monitor.Enabled {
+		reason := ""
+		if !success {
+			reason = classifyFailure(err).String()
+		}
+		sess.lock.RLock()
+		orchInfo := sess.OrchestratorInfo
+		sess.lock.RUnlock()
+		monitor.OrchestratorSegmentSubmitted(orchInfo, roundTrip, reason)
+	}
+	if alert.Enabled() {
+		uri := sess.Transcoder()
+		if failures := unreachableOrchs.observe(uri, !success && isUnreachableError(err)); failures >= orchUnreachableFailures {
+			alert.Fire(alert.OrchestratorUnreachable, alert.Warning, uri, "Orchestrator=%s unreachable for segments=%d err=%q", uri, failures, err)
+		}
+	}
+	if OrchStats == nil {
+		return
+	}
+	OrchStats.Observe(sess.Transcoder(), success, isTimeoutError(err), roundTrip)
+}
+
+// unreachableOrchs counts the consecutive segments that failed to reach each orchestrator
+var unreachableOrchs = newOrchFailures()
+
+type orchFailures struct {
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+func newOrchFailures() *orchFailures {
+	return &orchFailures{failures: make(map[string]int)}
+}
+
+// observe records whether a segment failed to reach the orchestrator at uri and returns its consecutive failures
+func (f *orchFailures) observe(uri string, failed bool) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !failed {
+		delete(f.failures, uri)
+		return 0
+	}
+	f.failures[uri]++
+	return f.failures[uri]
+}
+
+// isUnreachableError returns whether err means the orchestrator couldn't be reached, as opposed to it responding with an error
+func isUnreachableError(err error) bool {
+	if isTimeoutError(err) {
+		return true
+	}
+	var opErr *gonet.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func isTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	// Cancellations of the segment, e.g. because the stream ended, say nothing about the orchestrator
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr gonet.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gonet "net"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrchestratorStats(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+	require.Nil(dbh.AddOrchStats(&common.DBOrchStats{ServiceURI: "https://o1:8935", Segments: 2, Successes: 2, RoundTripMs: 2000}))
+
+	// stored stats are loaded
+	s, err := NewOrchestratorStats(dbh)
+	require.Nil(err)
+	stats, ok := s.Stats("https://o1:8935")
+	assert.True(ok)
+	assert.Equal(int64(2), stats.Segments)
+
+	s.Observe("https://o1:8935", true, false, 500*time.Millisecond)
+	s.Observe("https://o2:8935", false, true, 5*time.Second)
+	stats, _ = s.Stats("https://o1:8935")
+	assert.Equal(common.DBOrchStats{ServiceURI: "https://o1:8935", Segments: 3, Successes: 3, RoundTripMs: 2500}, stats)
+	stats, _ = s.Stats("https://o2:8935")
+	assert.Equal(common.DBOrchStats{ServiceURI: "https://o2:8935", Segments: 1, Timeouts: 1}, stats)
+	_, ok = s.Stats("https://o3:8935")
+	assert.False(ok)
+
+	// only the observed stats are added to the DB so that the stored ones aren't counted twice
+	require.Nil(s.Flush())
+	stored, err := dbh.SelectOrchStats()
+	require.Nil(err)
+	assert.Len(stored, 2)
+	s2, err := NewOrchestratorStats(dbh)
+	require.Nil(err)
+	stats, _ = s2.Stats("https://o1:8935")
+	assert.Equal(int64(3), stats.Segments)
+	assert.Equal(int64(2500), stats.RoundTripMs)
+
+	// stats that fail to be written are kept and merged with stats observed since
+	_, err = dbraw.Exec("ALTER TABLE orchestratorStats RENAME TO orchestratorStats_tmp")
+	require.Nil(err)
+	s.Observe("https://o1:8935", true, false, time.Second)
+	assert.NotNil(s.Flush())
+	s.Observe("https://o1:8935", false, true, 0)
+	_, err = dbraw.Exec("ALTER TABLE orchestratorStats_tmp RENAME TO orchestratorStats")
+	require.Nil(err)
+	require.Nil(s.Flush())
+	s2, err = NewOrchestratorStats(dbh)
+	require.Nil(err)
+	stats, _ = s2.Stats("https://o1:8935")
+	assert.Equal(common.DBOrchStats{ServiceURI: "https://o1:8935", Segments: 5, Successes: 4, Timeouts: 1, RoundTripMs: 3500}, stats)
+
+	// nothing pending is a no-op
+	require.Nil(s.Flush())
+	s2, err = NewOrchestratorStats(dbh)
+	require.Nil(err)
+	stats, _ = s2.Stats("https://o1:8935")
+	assert.Equal(int64(5), stats.Segments)
+
+	// nil stats
+	var nilStats *OrchestratorStats
+	nilStats.Observe("https://o1:8935", true, false, time.Second)
+	_, ok = nilStats.Stats("https://o1:8935")
+	assert.False(ok)
+}
+
+func TestOrchestratorStats_SelectionWeight(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewOrchestratorStats(nil)
+	require.Nil(t, err)
+	for i := 0; i < 10; i++ {
+		s.Observe("fast", true, false, 500*time.Millisecond)
+		s.Observe("slow", true, false, 4*time.Second)
+		s.Observe("failing", i%2 == 0, false, 500*time.Millisecond)
+		s.Observe("timingout", i%2 == 0, i%2 != 0, 500*time.Millisecond)
+	}
+
+	// unknown orchestrators get a neutral weight
+	assert.InDelta(0.25, s.selectionWeight("unknown"), 1e-9)
+	assert.Greater(s.selectionWeight("fast"), s.selectionWeight("slow"))
+	assert.Greater(s.selectionWeight("fast"), s.selectionWeight("failing"))
+	assert.Greater(s.selectionWeight("failing"), s.selectionWeight("timingout"))
+	assert.Greater(s.selectionWeight("fast"), s.selectionWeight("unknown"))
+}
+
+func TestMinLSSelector_OrchestratorStats(t *testing.T) {
+	assert := assert.New(t)
+
+	stats, err := NewOrchestratorStats(nil)
+	require.Nil(t, err)
+	var sessions []*BroadcastSession
+	for i := 0; i < 3; i++ {
+		sessions = append(sessions, &BroadcastSession{OrchestratorInfo: &net.OrchestratorInfo{Transcoder: fmt.Sprintf("https://o%d:8935", i)}})
+	}
+	stats.Observe("https://o0:8935", false, true, 0)
+	stats.Observe("https://o2:8935", true, false, time.Second)
+
+	// off-chain, the best performing orchestrator is selected first, then unknown ones over poorly performing ones
+	sel := NewMinLSSelector(nil, 1.0)
+	sel.stats = stats
+	sel.Add(sessions)
+	assert.Same(sessions[2], sel.Select(context.TODO()))
+	assert.Same(sessions[1], sel.Select(context.TODO()))
+	assert.Same(sessions[0], sel.Select(context.TODO()))
+}
+
+func TestIsTimeoutError(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(isTimeoutError(nil))
+	assert.False(isTimeoutError(errors.New("some error")))
+	assert.True(isTimeoutError(fmt.Errorf("header timeout: %w", context.DeadlineExceeded)))
+	assert.False(isTimeoutError(fmt.Errorf("header timeout: %w", context.Canceled)))
+	assert.True(isTimeoutError(fmt.Errorf("header timeout: %w", errReqTimeout{context.Canceled})))
+	assert.True(isTimeoutError(&gonet.DNSError{IsTimeout: true}))
+}
+
+func TestIsUnreachableError(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(isUnreachableError(nil))
+	assert.False(isUnreachableError(errors.New("some error")))
+	assert.False(isUnreachableError(errOrchRefused{errors.New("OrchestratorBusy")}))
+	assert.True(isUnreachableError(fmt.Errorf("header timeout: %w", context.DeadlineExceeded)))
+	dialErr := &gonet.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	assert.True(isUnreachableError(fmt.Errorf("Post: %w", dialErr)))
+	assert.False(isUnreachableError(&gonet.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}))
+}
+
+func TestOrchFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newOrchFailures()
+	assert.Equal(1, f.observe("https://o1:8935", true))
+	assert.Equal(2, f.observe("https://o1:8935", true))
+	assert.Equal(1, f.observe("https://o2:8935", true))
+	// A segment that reaches the orchestrator resets its failures
+	assert.Equal(0, f.observe("https://o1:8935", false))
+	assert.Equal(1, f.observe("https://o1:8935", true))
+	assert.Equal(2, f.observe("https://o2:8935", true))
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/cenkalti/backoff"
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/net/http2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -164,6 +165,9 @@ func runTranscode(n *core.LivepeerNode, orchAddr string, httpc *http.Client, not
 	}
 	ctx = clog.AddSeqNo(ctx, uint64(md.Seq))
 	ctx = clog.AddVal(ctx, "taskId", strconv.FormatInt(notify.TaskId, 10))
+	ctx, span := monitor.StartSegmentSpan(monitor.ExtractTraceContext(ctx, notify.TraceContext), monitor.SpanTranscoderSegment,
+		string(md.ManifestID), uint64(md.Seq), attribute.Int64(monitor.AttrTaskID, notify.TaskId))
+	defer span.End()
 	if n.Capabilities != nil && !md.Caps.CompatibleWith(n.Capabilities.ToNetCapabilities()) {
 		clog.Errorf(ctx, "Requested capabilities for segment are not compatible with this node taskId=%d url=%s err=%q", notify.TaskId, notify.Url, errCapabilities)
 		sendTranscodeResult(ctx, n, orchAddr, httpc, notify, contentType, &body, tData, errCapabilities)
@@ -253,9 +257,12 @@ func sendTranscodeResult(ctx context.Context, n *core.LivepeerNode, orchAddr str
 ) {
 	if err != nil {
 		clog.Errorf(ctx, "Unable to transcode err=%q", err)
+		monitor.SetSpanError(ctx, err)
 		body.Write([]byte(err.Error()))
 		contentType = transcodingErrorMimeType
 	}
+	ctx, span := monitor.StartSpan(ctx, monitor.SpanTranscoderResults)
+	defer span.End()
 	req, err := http.NewRequest("POST", "https://"+orchAddr+"/transcodeResults", body)
 	if err != nil {
 		clog.Errorf(ctx, "Error posting results to orch=%s staskId=%d url=%s err=%q", orchAddr,
@@ -286,10 +293,12 @@ func sendTranscodeResult(ctx context.Context, n *core.LivepeerNode, orchAddr str
 	resp, err := httpc.Do(req)
 	if err != nil {
 		clog.Errorf(ctx, "Error submitting results err=%q", err)
+		monitor.SetSpanError(ctx, err)
 	} else {
 		rbody, rerr := ioutil.ReadAll(resp.Body)
 		resp.Body.Close()
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			monitor.SetSpanError(ctx, fmt.Errorf("orchestrator returned HTTP status %d", resp.StatusCode))
 			if rerr != nil {
 				clog.Errorf(ctx, "Orchestrator returned HTTP statusCode=%v with unreadable body err=%q", resp.StatusCode, rerr)
 			} else {
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/pm"
+)
+
+// paymentWebhookQueueSize is the number of payment events buffered for delivery. Events are dropped when the queue is full.
+const paymentWebhookQueueSize = 100
+
+// maxFloatEventInterval is the minimum interval between the MaxFloatReached events of a sender.
+// Ticket params are requested for every payment, so a capped sender would otherwise fire an event for every segment.
+var maxFloatEventInterval = 10 * time.Minute
+
+var paymentWebhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// PaymentWebhook posts payment events as JSON to a webhook URL
+type PaymentWebhook struct {
+	url    *url.URL
+	events map[pm.PaymentEventType]bool
+
+	queue chan *pm.PaymentEvent
+
+	mu           sync.Mutex
+	lastMaxFloat map[ethcommon.Address]time.Time
+
+	quit chan struct{}
+}
+
+// NewPaymentWebhook creates a PaymentWebhook that posts the events of the given types to u.
+// All events are posted if no types are given.
+func NewPaymentWebhook(u *url.URL, events []pm.PaymentEventType) *PaymentWebhook {
+	if len(events) == 0 {
+		events = pm.PaymentEventTypes
+	}
+	w := &PaymentWebhook{
+		url:          u,
+		events:       make(map[pm.PaymentEventType]bool),
+		queue:        make(chan *pm.PaymentEvent, paymentWebhookQueueSize),
+		lastMaxFloat: make(map[ethcommon.Address]time.Time),
+		quit:         make(chan struct{}),
+	}
+	for _, ev := range events {
+		w.events[ev] = true
+	}
+	return w
+}
+
+// ParsePaymentEventTypes parses a comma separated list of payment event types
+func ParsePaymentEventTypes(s string) ([]pm.PaymentEventType, error) {
+	var types []pm.PaymentEventType
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, t := range pm.PaymentEventTypes {
+			if string(t) == name {
+				types = append(types, t)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown payment event type %q", name)
+		}
+	}
+	return types, nil
+}
+
+// Start starts delivering the queued events
+func (w *PaymentWebhook) Start() {
+	go func() {
+		for {
+			select {
+			case ev := <-w.queue:
+				w.post(ev)
+			case <-w.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops delivering events
+func (w *PaymentWebhook) Stop() {
+	close(w.quit)
+}
+
+// Notify queues an event for delivery
+func (w *PaymentWebhook) Notify(ev *pm.PaymentEvent) {
+	if !w.events[ev.Type] {
+		return
+	}
+	if ev.Type == pm.MaxFloatReached && !w.allowMaxFloatEvent(ev.Sender, ev.Time) {
+		return
+	}
+
+	select {
+	case w.queue <- ev:
+	default:
+		glog.Warningf("Payment webhook queue is full, dropping event type=%v sender=%v", ev.Type, ev.Sender.Hex())
+	}
+}
+
+func (w *PaymentWebhook) allowMaxFloatEvent(sender ethcommon.Address, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if last, ok := w.lastMaxFloat[sender]; ok && now.Sub(last) < maxFloatEventInterval {
+		return false
+	}
+	w.lastMaxFloat[sender] = now
+	// Forget the senders that have not been capped recently
+	for addr, last := range w.lastMaxFloat {
+		if now.Sub(last) >= maxFloatEventInterval {
+			delete(w.lastMaxFloat, addr)
+		}
+	}
+	return true
+}
+
+func (w *PaymentWebhook) post(ev *pm.PaymentEvent) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		glog.Errorf("Unable to marshal payment event type=%v err=%q", ev.Type, err)
+		return
+	}
+	resp, err := paymentWebhookClient.Post(w.url.String(), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		glog.Errorf("Unable to POST payment event on webhook url=%v type=%v err=%q", w.url.Redacted(), ev.Type, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rbody, _ := ioutil.ReadAll(resp.Body)
+		glog.Errorf("Payment webhook returned error status=%v type=%v err=%q", resp.StatusCode, ev.Type, string(rbody))
+	}
+}
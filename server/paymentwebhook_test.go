@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePaymentEventTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	types, err := ParsePaymentEventTypes("")
+	assert.Nil(err)
+	assert.Empty(types)
+
+	types, err = ParsePaymentEventTypes("redemptionFailed, maxFloatReached")
+	assert.Nil(err)
+	assert.Equal([]pm.PaymentEventType{pm.RedemptionFailed, pm.MaxFloatReached}, types)
+
+	_, err = ParsePaymentEventTypes("redemptionFailed,foo")
+	assert.EqualError(err, `unknown payment event type "foo"`)
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	received := make(chan *pm.PaymentEvent, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev pm.PaymentEvent
+		assert.Nil(json.NewDecoder(r.Body).Decode(&ev))
+		received <- &ev
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.Nil(err)
+
+	w := NewPaymentWebhook(u, []pm.PaymentEventType{pm.TicketRedeemed, pm.MaxFloatReached})
+	w.Start()
+	defer w.Stop()
+
+	waitForEvent := func() *pm.PaymentEvent {
+		select {
+		case ev := <-received:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for payment event")
+		}
+		return nil
+	}
+
+	sender := pm.RandAddress()
+	now := time.Now()
+
+	// Event types that are not configured are not posted
+	w.Notify(&pm.PaymentEvent{Type: pm.WinningTicketReceived, Time: now, Sender: sender})
+
+	txHash := pm.RandHash()
+	w.Notify(&pm.PaymentEvent{Type: pm.TicketRedeemed, Time: now, Sender: sender, Tickets: 2, FaceValue: big.NewInt(100), TxHash: &txHash})
+	ev := waitForEvent()
+	assert.Equal(pm.TicketRedeemed, ev.Type)
+	assert.Equal(sender, ev.Sender)
+	assert.Equal(2, ev.Tickets)
+	assert.Equal(big.NewInt(100), ev.FaceValue)
+	assert.Equal(txHash, *ev.TxHash)
+
+	// Max float events are throttled per sender
+	w.Notify(&pm.PaymentEvent{Type: pm.MaxFloatReached, Time: now, Sender: sender, MaxFloat: big.NewInt(50)})
+	ev = waitForEvent()
+	assert.Equal(pm.MaxFloatReached, ev.Type)
+	assert.Equal(big.NewInt(50), ev.MaxFloat)
+	w.Notify(&pm.PaymentEvent{Type: pm.MaxFloatReached, Time: now.Add(time.Minute), Sender: sender})
+	other := pm.RandAddress()
+	w.Notify(&pm.PaymentEvent{Type: pm.MaxFloatReached, Time: now.Add(time.Minute), Sender: other})
+	assert.Equal(other, waitForEvent().Sender)
+	w.Notify(&pm.PaymentEvent{Type: pm.MaxFloatReached, Time: now.Add(maxFloatEventInterval), Sender: sender})
+	assert.Equal(sender, waitForEvent().Sender)
+
+	assert.Empty(received)
+}
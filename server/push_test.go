@@ -578,6 +578,76 @@ func TestPush_HTTPIngest(t *testing.T) {
 	cancel()
 }
 
+func TestPush_Transcode(t *testing.T) {
+	assert := assert.New(t)
+
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	n, _ := core.NewLivepeerNode(nil, "./tmp", nil)
+	n.NodeType = core.BroadcasterNode
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, _ := NewLivepeerServer("127.0.0.1:1938", n, true, "")
+	s.SetContextFromUnitTest(ctx)
+
+	req := httptest.NewRequest("POST", "/transcode/1.ts", strings.NewReader(""))
+	h, pattern := s.HTTPMux.Handler(req)
+	assert.Equal("/transcode/", pattern)
+
+	writer := httptest.NewRecorder()
+	h.ServeHTTP(writer, req)
+	resp := writer.Result()
+	defer resp.Body.Close()
+	assert.Equal(503, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	assert.Equal("No sessions available", strings.TrimSpace(string(body)))
+
+	// the stream only lives for the duration of the request
+	s.connectionLock.RLock()
+	assert.Len(s.rtmpConnections, 0)
+	assert.Len(s.internalManifests, 0)
+	s.connectionLock.RUnlock()
+
+	// segment name is required
+	req = httptest.NewRequest("POST", "/transcode/", strings.NewReader(""))
+	writer = httptest.NewRecorder()
+	h.ServeHTTP(writer, req)
+	resp = writer.Result()
+	defer resp.Body.Close()
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	// the auth webhook is called once, with the /transcode/ URL rather than the random manifest ID of the stream
+	var webhookURLs []string
+	webhookStatus := http.StatusForbidden
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		webhookURLs = append(webhookURLs, req["url"])
+		w.WriteHeader(webhookStatus)
+	}))
+	defer ts.Close()
+	defer func() { AuthWebhookURL = nil }()
+	AuthWebhookURL, _ = url.Parse(ts.URL)
+
+	req = httptest.NewRequest("POST", "http://broadcaster/transcode/2.ts", strings.NewReader(""))
+	writer = httptest.NewRecorder()
+	h.ServeHTTP(writer, req)
+	resp = writer.Result()
+	defer resp.Body.Close()
+	assert.Equal(http.StatusForbidden, resp.StatusCode)
+	assert.Equal([]string{"http://broadcaster/transcode/2.ts"}, webhookURLs)
+
+	webhookURLs = nil
+	webhookStatus = http.StatusOK
+	req = httptest.NewRequest("POST", "http://broadcaster/transcode/3.ts", strings.NewReader(""))
+	writer = httptest.NewRecorder()
+	h.ServeHTTP(writer, req)
+	resp = writer.Result()
+	defer resp.Body.Close()
+	assert.Equal(503, resp.StatusCode)
+	assert.Equal([]string{"http://broadcaster/transcode/3.ts"}, webhookURLs)
+}
+
 func TestPush_MP4(t *testing.T) {
 	oldProfs := BroadcastJobVideoProfiles
 	defer func() { BroadcastJobVideoProfiles = oldProfs }()
@@ -924,6 +994,51 @@ func TestPush_ShouldRemoveSessionAfterTimeout(t *testing.T) {
 	assert.False(exists)
 }
 
+func TestPush_TerminateStream(t *testing.T) {
+	assert := assert.New(t)
+
+	// wait for any earlier tests to complete
+	assert.True(wgWait(&pushResetWg), "timed out waiting for earlier tests")
+
+	s, cancel := setupServerWithCancel()
+	defer serverCleanup(s)
+	defer cancel()
+	push := func(name string) int {
+		w := httptest.NewRecorder()
+		s.HandlePush(w, httptest.NewRequest("POST", "/live/"+name+"/1.ts", nil))
+		resp := w.Result()
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	exists := func(mid core.ManifestID) bool {
+		s.connectionLock.Lock()
+		defer s.connectionLock.Unlock()
+		_, exists := s.rtmpConnections[mid]
+		return exists
+	}
+
+	// Unknown stream
+	assert.Equal(errUnknownStream, s.terminateStream(context.TODO(), "terminated", time.Minute))
+	assert.False(s.isTerminated("terminated"))
+
+	// Terminated stream is rejected until the block expires
+	push("terminated")
+	assert.True(exists("terminated"))
+	assert.Nil(s.terminateStream(context.TODO(), "terminated", 100*time.Millisecond))
+	assert.False(exists("terminated"))
+	assert.Equal(http.StatusForbidden, push("terminated"))
+	assert.False(exists("terminated"))
+	time.Sleep(150 * time.Millisecond)
+	push("terminated")
+	assert.True(exists("terminated"))
+
+	// Stream can be restarted right away without block
+	assert.Nil(s.terminateStream(context.TODO(), "terminated", 0))
+	assert.False(exists("terminated"))
+	push("terminated")
+	assert.True(exists("terminated"))
+}
+
 func TestPush_ShouldNotPanicIfSessionAlreadyRemoved(t *testing.T) {
 	oldRI := httpPushTimeout
 	httpPushTimeout = 100 * time.Millisecond
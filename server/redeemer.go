@@ -6,6 +6,8 @@ import (
 	"math/big"
 	gonet "net"
 	"net/url"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,21 +15,52 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/golang/glog"
 	"github.com/livepeer/go-livepeer/eth"
+	"github.com/livepeer/go-livepeer/monitor"
 	"github.com/livepeer/go-livepeer/net"
 	"github.com/livepeer/go-livepeer/pm"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 )
 
 var cleanupLoopTime = 5 * time.Minute
 
+// redeemerLeaseName is the name of the DB lease held by the active Redeemer of an active/standby pair
+const redeemerLeaseName = "redeemer"
+
+// redeemerLeaseTTL is how long the active Redeemer holds the lease without renewing it
+var redeemerLeaseTTL = 30 * time.Second
+
+// redeemerHealthCheckInterval is how often RedeemerClient checks the connection to the active Redeemer
+var redeemerHealthCheckInterval = 10 * time.Second
+
+var errRedeemerStandby = status.Error(codes.Unavailable, "redeemer is on standby")
+
+// redeemerForwardInterval is how often RedeemerClient retries sending the winning tickets stored on the node to the Redeemer
+var redeemerForwardInterval = 5 * time.Second
+
+// WinningTicketStore stores the winning tickets received by a node that uses a remote Redeemer
+// until they are sent to the Redeemer
+type WinningTicketStore interface {
+	StoreWinningTicket(ticket *pm.SignedTicket) error
+	SelectEarliestWinningTicket(sender ethcommon.Address, minCreationRound int64) (*pm.SignedTicket, error)
+	SelectWinningTicketSenders(minCreationRound int64) ([]ethcommon.Address, error)
+	RemoveWinningTicket(ticket *pm.SignedTicket) error
+}
+
 type localSenderMonitor interface {
 	pm.SenderMonitor
 	SubscribeMaxFloatChange(sender ethcommon.Address, sink chan<- struct{}) event.Subscription
 }
 
+// LeaseStore is used by Redeemers sharing a DB to agree on which of them is active
+type LeaseStore interface {
+	AcquireLease(name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(name, holder string) error
+}
+
 type Redeemer struct {
 	server    *grpc.Server
 	recipient ethcommon.Address
@@ -35,10 +68,18 @@ type Redeemer struct {
 	sm        localSenderMonitor
 	quit      chan struct{}
 	mu        sync.Mutex
+
+	// leases is nil if the Redeemer runs without a standby, in which case it is always active
+	leases   LeaseStore
+	holder   string
+	active   bool
+	leaseErr error
 }
 
-// NewRedeemer creates a new ticket redemption service instance
-func NewRedeemer(recipient ethcommon.Address, eth eth.LivepeerEthClient, sm *pm.LocalSenderMonitor) (*Redeemer, error) {
+// NewRedeemer creates a new ticket redemption service instance. If leases is not nil, the Redeemer
+// only serves requests and redeems tickets while it holds the redeemer lease, so that it can run in an
+// active/standby pair with another Redeemer sharing the same DB.
+func NewRedeemer(recipient ethcommon.Address, eth eth.LivepeerEthClient, sm *pm.LocalSenderMonitor, leases LeaseStore) (*Redeemer, error) {
 
 	if recipient == (ethcommon.Address{}) {
 		return nil, fmt.Errorf("must provide a recipient")
@@ -57,6 +98,7 @@ func NewRedeemer(recipient ethcommon.Address, eth eth.LivepeerEthClient, sm *pm.
 		eth:       eth,
 		sm:        sm,
 		quit:      make(chan struct{}),
+		leases:    leases,
 	}, nil
 }
 
@@ -80,21 +122,97 @@ func (r *Redeemer) Start(url *url.URL, workDir string) error {
 	}
 
 	s := grpc.NewServer(grpc.Creds(creds))
+	r.mu.Lock()
 	r.server = s
+	r.mu.Unlock()
 
 	net.RegisterTicketRedeemerServer(s, r)
 
-	return s.Serve(listener)
+	if r.leases != nil {
+		// The hostname and listen address distinguish the Redeemers of a pair, and let a restarted Redeemer take back its lease right away
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		r.holder = hostname + "/" + url.Host
+		go r.holdLease()
+	}
+
+	if err := s.Serve(listener); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.leaseErr
 }
 
 // Stop stops the Redeemer server
 func (r *Redeemer) Stop() {
 	close(r.quit)
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.server.Stop()
+	if r.leases != nil && r.active {
+		r.sm.Stop()
+		if err := r.leases.ReleaseLease(redeemerLeaseName, r.holder); err != nil {
+			glog.Error(err)
+		}
+	}
+}
+
+// holdLease acquires the redeemer lease and keeps renewing it. A standby Redeemer becomes active once it acquires
+// the lease. An active Redeemer that fails to renew the lease before it expires stops redeeming tickets and its server,
+// because the standby could take over and redeem the same tickets.
+func (r *Redeemer) holdLease() {
+	ticker := time.NewTicker(redeemerLeaseTTL / 3)
+	defer ticker.Stop()
+
+	var renewed time.Time
+	for {
+		ok, err := r.leases.AcquireLease(redeemerLeaseName, r.holder, redeemerLeaseTTL)
+		if err != nil {
+			glog.Errorf("Error acquiring redeemer lease err=%q", err)
+		}
+
+		r.mu.Lock()
+		switch {
+		case ok:
+			renewed = time.Now()
+			if !r.active {
+				glog.Infof("Redeemer is active holder=%v", r.holder)
+				r.active = true
+				r.sm.Start()
+			}
+		case r.active && (err == nil || time.Since(renewed) >= redeemerLeaseTTL):
+			r.leaseErr = fmt.Errorf("redeemer lost its lease holder=%v", r.holder)
+			r.active = false
+			r.sm.Stop()
+			r.server.Stop()
+			r.mu.Unlock()
+			return
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+func (r *Redeemer) isActive() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.leases == nil || r.active
 }
 
 // QueueTicket adds a ticket to the ticket queue
 func (r *Redeemer) QueueTicket(ctx context.Context, ticket *net.Ticket) (*net.QueueTicketRes, error) {
+	if !r.isActive() {
+		return nil, errRedeemerStandby
+	}
 	t := pmTicket(ticket)
 	if r.recipient != t.Recipient {
 		return nil, status.Error(codes.PermissionDenied, fmt.Sprintf("invalid ticket recipient 0x%x, expected %v", ticket.TicketParams.Recipient, r.recipient.Hex()))
@@ -109,6 +227,9 @@ func (r *Redeemer) QueueTicket(ctx context.Context, ticket *net.Ticket) (*net.Qu
 
 // MaxFloat is a unary RPC method to request the max float value for a sender
 func (r *Redeemer) MaxFloat(ctx context.Context, req *net.MaxFloatReq) (*net.MaxFloatUpdate, error) {
+	if !r.isActive() {
+		return nil, errRedeemerStandby
+	}
 	maxFloat, err := r.sm.MaxFloat(ethcommon.BytesToAddress(req.Sender))
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -119,6 +240,9 @@ func (r *Redeemer) MaxFloat(ctx context.Context, req *net.MaxFloatReq) (*net.Max
 
 // MonitorMaxFloat starts a server-side stream to the client to send max float updates for sender specified in the request
 func (r *Redeemer) MonitorMaxFloat(req *net.MaxFloatReq, stream net.TicketRedeemer_MonitorMaxFloatServer) error {
+	if !r.isActive() {
+		return errRedeemerStandby
+	}
 	sender := ethcommon.BytesToAddress(req.Sender)
 
 	errCh := make(chan error, 1)
@@ -162,11 +286,20 @@ type RedeemerClient struct {
 	conn *grpc.ClientConn
 	rpc  net.TicketRedeemerClient
 
+	// endpoints are the configured Redeemers, of which the one at index active is used
+	endpoints []*redeemerEndpoint
+	active    int
+	connMu    sync.Mutex
+
 	senders map[ethcommon.Address]*remoteSender
 	mu      sync.RWMutex
 	quit    chan struct{}
 	sm      pm.SenderManager
 	tm      pm.TimeManager
+
+	// store holds the winning tickets until they are sent to the Redeemer, forward signals that a ticket was stored
+	store   WinningTicketStore
+	forward chan struct{}
 }
 
 type remoteSender struct {
@@ -176,46 +309,265 @@ type remoteSender struct {
 	lastAccess time.Time
 }
 
+type redeemerEndpoint struct {
+	uri  string
+	conn *grpc.ClientConn
+	rpc  net.TicketRedeemerClient
+}
+
 // NewRedeemerClient instantiates a new client for the ticket redemption service
 // The client implements the pm.SenderMonitor interface
-func NewRedeemerClient(uri string, sm pm.SenderManager, tm pm.TimeManager) (*RedeemerClient, error) {
-	conn, err := grpc.Dial(
-		uri,
-		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
-		grpc.WithBlock(),
-		grpc.WithTimeout(GRPCConnectTimeout),
-	)
-
-	// TODO: PROVIDE KEEPALIVE SETTINGS
+// If multiple Redeemer URIs are provided, the client uses the first one that it can connect to and
+// fails over to another one when the Redeemer in use becomes unavailable
+// Winning tickets are written to store before they are sent to the Redeemer
+func NewRedeemerClient(uris []string, sm pm.SenderManager, tm pm.TimeManager, store WinningTicketStore) (*RedeemerClient, error) {
+	if len(uris) == 0 {
+		return nil, fmt.Errorf("must provide a redeemer address")
+	}
+
+	var endpoints []*redeemerEndpoint
+	closeAll := func() {
+		for _, e := range endpoints {
+			e.conn.Close()
+		}
+	}
+	for _, uri := range uris {
+		// TODO: PROVIDE KEEPALIVE SETTINGS
+		conn, err := grpc.Dial(
+			uri,
+			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("Did not connect to redeemer=%v err=%q", uri, err)
+		}
+		conn.Connect()
+		endpoints = append(endpoints, &redeemerEndpoint{uri: uri, conn: conn, rpc: net.NewTicketRedeemerClient(conn)})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), GRPCConnectTimeout)
+	defer cancel()
+	active, err := waitForReadyEndpoint(ctx, endpoints)
 	if err != nil {
-		return nil, fmt.Errorf("Did not connect to redeemer=%v err=%q", uri, err)
+		closeAll()
+		return nil, fmt.Errorf("Did not connect to redeemer=%v err=%q", strings.Join(uris, ","), err)
 	}
+
 	return &RedeemerClient{
-		conn:    conn,
-		rpc:     net.NewTicketRedeemerClient(conn),
-		sm:      sm,
-		tm:      tm,
-		senders: make(map[ethcommon.Address]*remoteSender),
-		quit:    make(chan struct{}),
+		conn:      endpoints[active].conn,
+		rpc:       endpoints[active].rpc,
+		endpoints: endpoints,
+		active:    active,
+		sm:        sm,
+		tm:        tm,
+		senders:   make(map[ethcommon.Address]*remoteSender),
+		quit:      make(chan struct{}),
+		store:     store,
+		forward:   make(chan struct{}, 1),
 	}, nil
 }
 
+// waitForReadyEndpoint returns the index of the first endpoint with a ready connection
+func waitForReadyEndpoint(ctx context.Context, endpoints []*redeemerEndpoint) (int, error) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		for i, e := range endpoints {
+			if e.conn.GetState() == connectivity.Ready {
+				return i, nil
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
 func (r *RedeemerClient) Start() {
 	go r.startCleanupLoop()
+	go r.startForwardLoop()
+	if len(r.endpoints) > 1 {
+		go r.startHealthCheckLoop()
+	}
 }
 
 // Stop stops the Redeemer client
 func (r *RedeemerClient) Stop() {
 	close(r.quit)
-	r.conn.Close()
+	if len(r.endpoints) == 0 {
+		r.conn.Close()
+		return
+	}
+	for _, e := range r.endpoints {
+		e.conn.Close()
+	}
+}
+
+// activeRPC returns the client of the Redeemer in use and its endpoint index
+func (r *RedeemerClient) activeRPC() (net.TicketRedeemerClient, int) {
+	r.connMu.Lock()
+	defer r.connMu.Unlock()
+	return r.rpc, r.active
+}
+
+// failover switches to the next endpoint with a ready connection if the endpoint at index from is still in use.
+// It returns true if the client uses a different endpoint afterwards.
+func (r *RedeemerClient) failover(from int) bool {
+	r.connMu.Lock()
+	defer r.connMu.Unlock()
+
+	if len(r.endpoints) < 2 {
+		return false
+	}
+	if r.active != from {
+		// Another request already failed over
+		return true
+	}
+	for i := 1; i < len(r.endpoints); i++ {
+		next := (from + i) % len(r.endpoints)
+		e := r.endpoints[next]
+		if e.conn.GetState() != connectivity.Ready {
+			e.conn.Connect()
+			continue
+		}
+		glog.Warningf("Failing over to redeemer=%v from redeemer=%v", e.uri, r.endpoints[from].uri)
+		r.active = next
+		r.conn = e.conn
+		r.rpc = e.rpc
+		return true
+	}
+	return false
+}
+
+// withFailover calls fn with the Redeemer in use and retries it once with another Redeemer if the one in use is unavailable
+func (r *RedeemerClient) withFailover(fn func(rpc net.TicketRedeemerClient) error) error {
+	rpc, active := r.activeRPC()
+	err := fn(rpc)
+	if !isRedeemerUnavailable(err) || !r.failover(active) {
+		return err
+	}
+	rpc, _ = r.activeRPC()
+	return fn(rpc)
+}
+
+func isRedeemerUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// startHealthCheckLoop fails over to another Redeemer when the connection to the Redeemer in use is lost
+func (r *RedeemerClient) startHealthCheckLoop() {
+	ticker := time.NewTicker(redeemerHealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			conn, active := r.activeConn()
+			switch conn.GetState() {
+			case connectivity.TransientFailure, connectivity.Shutdown:
+				r.failover(active)
+			}
+		case <-r.quit:
+			return
+		}
+	}
 }
 
-// QueueTicket sends a winning ticket to the Redeemer
+func (r *RedeemerClient) activeConn() (*grpc.ClientConn, int) {
+	r.connMu.Lock()
+	defer r.connMu.Unlock()
+	return r.conn, r.active
+}
+
+// QueueTicket stores a winning ticket on the node and sends it to the Redeemer asynchronously, so that
+// the ticket is not lost if the Redeemer is unavailable and receiving a payment doesn't wait for the Redeemer
 func (r *RedeemerClient) QueueTicket(ticket *pm.SignedTicket) error {
-	ctx, cancel := context.WithTimeout(context.Background(), GRPCTimeout)
-	defer cancel()
-	_, err := r.rpc.QueueTicket(ctx, protoTicket(ticket))
-	return err
+	if err := r.store.StoreWinningTicket(ticket); err != nil {
+		return err
+	}
+	select {
+	case r.forward <- struct{}{}:
+	default:
+		// a send is already pending
+	}
+	return nil
+}
+
+// startForwardLoop sends the stored winning tickets to the Redeemer whenever a ticket is stored,
+// and retries sending the tickets that could not be sent every redeemerForwardInterval
+func (r *RedeemerClient) startForwardLoop() {
+	ticker := time.NewTicker(redeemerForwardInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.forwardTickets(); err != nil {
+			glog.Errorf("Error sending winning tickets to redeemer err=%q", err)
+		}
+		select {
+		case <-r.forward:
+		case <-ticker.C:
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// forwardTickets sends the stored winning tickets to the Redeemer, removing each ticket from the store once the Redeemer
+// queued it. A ticket that the Redeemer rejects, e.g. because it isn't the ticket recipient, is dropped so that it
+// doesn't hold up the other tickets. Otherwise it stops at the first error, leaving the remaining tickets to be sent on
+// the next attempt. The Redeemer skips expired tickets, so all stored tickets are sent.
+func (r *RedeemerClient) forwardTickets() error {
+	senders, err := r.store.SelectWinningTicketSenders(0)
+	if err != nil {
+		return err
+	}
+	for _, sender := range senders {
+		for {
+			ticket, err := r.store.SelectEarliestWinningTicket(sender, 0)
+			if err != nil {
+				return err
+			}
+			if ticket == nil {
+				break
+			}
+			if err := r.sendTicket(ticket); err != nil {
+				if !isTicketRejected(err) {
+					return err
+				}
+				glog.Errorf("Dropping winning ticket rejected by redeemer sender=%v faceValue=%v err=%q", sender.Hex(), ticket.FaceValue, err)
+				if monitor.Enabled {
+					monitor.TicketRedemptionError(sender.Hex())
+				}
+			}
+			if err := r.store.RemoveWinningTicket(ticket); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isTicketRejected returns true if the Redeemer won't ever queue a ticket that it refused with err
+func isTicketRejected(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.PermissionDenied, codes.AlreadyExists, codes.FailedPrecondition:
+		return true
+	}
+	return false
+}
+
+// sendTicket sends a winning ticket to the Redeemer
+func (r *RedeemerClient) sendTicket(ticket *pm.SignedTicket) error {
+	return r.withFailover(func(rpc net.TicketRedeemerClient) error {
+		ctx, cancel := context.WithTimeout(context.Background(), GRPCTimeout)
+		defer cancel()
+		_, err := rpc.QueueTicket(ctx, protoTicket(ticket))
+		return err
+	})
 }
 
 // MaxFloat returns the max float for 'sender'
@@ -236,17 +588,23 @@ func (r *RedeemerClient) MaxFloat(sender ethcommon.Address) (*big.Int, error) {
 	r.mu.Unlock()
 
 	// Retrieve max float from Redeemer and cache it if no local cache exists
-	ctx, cancel := context.WithTimeout(context.Background(), GRPCTimeout)
-	mfu, err := r.rpc.MaxFloat(ctx, &net.MaxFloatReq{Sender: sender.Bytes()})
-	cancel()
+	var mfu *net.MaxFloatUpdate
+	err := r.withFailover(func(rpc net.TicketRedeemerClient) error {
+		ctx, cancel := context.WithTimeout(context.Background(), GRPCTimeout)
+		defer cancel()
+		var err error
+		mfu, err = rpc.MaxFloat(ctx, &net.MaxFloatReq{Sender: sender.Bytes()})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	mf := new(big.Int).SetBytes(mfu.MaxFloat)
 
 	// Request updates for sender from Redeemer
-	ctx, cancel = context.WithCancel(context.Background())
-	stream, err := r.rpc.MonitorMaxFloat(ctx, &net.MaxFloatReq{Sender: sender.Bytes()})
+	rpc, _ := r.activeRPC()
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := rpc.MonitorMaxFloat(ctx, &net.MaxFloatReq{Sender: sender.Bytes()})
 	if err != nil {
 		cancel()
 		// An error means we won't be receiving updates from the Redeemer for 'sender'
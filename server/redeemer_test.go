@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,11 +17,15 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/golang/glog"
 	"github.com/golang/mock/gomock"
+	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/eth"
 	"github.com/livepeer/go-livepeer/net"
 	"github.com/livepeer/go-livepeer/pm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var baseRPCErr = "rpc error: code = Internal desc = "
@@ -29,22 +34,22 @@ var baseRPCErr = "rpc error: code = Internal desc = "
 func TestRedeemerServer_NewRedeemer(t *testing.T) {
 	assert := assert.New(t)
 	// test no recipient
-	r, err := NewRedeemer(ethcommon.Address{}, nil, nil)
+	r, err := NewRedeemer(ethcommon.Address{}, nil, nil, nil)
 	assert.Nil(r)
 	assert.EqualError(err, "must provide a recipient")
 
 	// test no LivepeerEthClient
-	r, err = NewRedeemer(pm.RandAddress(), nil, nil)
+	r, err = NewRedeemer(pm.RandAddress(), nil, nil, nil)
 	assert.Nil(r)
 	assert.EqualError(err, "must provide a LivepeerEthClient")
 
 	// test no SenderMonitor
-	r, err = NewRedeemer(pm.RandAddress(), &eth.StubClient{}, nil)
+	r, err = NewRedeemer(pm.RandAddress(), &eth.StubClient{}, nil, nil)
 	assert.Nil(r)
 	assert.EqualError(err, "must provide a SenderMonitor")
 
 	recipient := pm.RandAddress()
-	r, err = NewRedeemer(recipient, &eth.StubClient{}, &pm.LocalSenderMonitor{})
+	r, err = NewRedeemer(recipient, &eth.StubClient{}, &pm.LocalSenderMonitor{}, nil)
 	assert.Nil(err)
 	assert.Equal(r.recipient, recipient)
 }
@@ -53,7 +58,7 @@ func TestRedeemerServer_Start(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
 
-	r, err := NewRedeemer(ethcommon.BytesToAddress([]byte("foo")), &eth.StubClient{}, &pm.LocalSenderMonitor{})
+	r, err := NewRedeemer(ethcommon.BytesToAddress([]byte("foo")), &eth.StubClient{}, &pm.LocalSenderMonitor{}, nil)
 	require.Nil(err)
 
 	url, err := url.ParseRequestURI("https://127.0.0.1:8935")
@@ -69,7 +74,7 @@ func TestRedeemerServer_Start(t *testing.T) {
 	time.Sleep(20 * time.Millisecond)
 
 	// Check that client can connect to server
-	_, err = NewRedeemerClient(url.Host, newStubSenderManager(), &stubTimeManager{})
+	_, err = NewRedeemerClient([]string{url.Host}, newStubSenderManager(), &stubTimeManager{}, nil)
 	assert.Nil(err)
 
 	r.Stop()
@@ -372,8 +377,14 @@ func TestRedeemerServer_MaxFloat(t *testing.T) {
 }
 
 // Test client
-func TestRedeemerClient_QueueTicket_RPCErr(t *testing.T) {
+func TestRedeemerClient_QueueTicket(t *testing.T) {
 	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
 
 	ctrl := gomock.NewController(t)
 	rpc := net.NewMockTicketRedeemerClient(ctrl)
@@ -381,34 +392,31 @@ func TestRedeemerClient_QueueTicket_RPCErr(t *testing.T) {
 	rc := &RedeemerClient{
 		rpc:     rpc,
 		senders: make(map[ethcommon.Address]*remoteSender), quit: make(chan struct{}),
+		store:   dbh,
+		forward: make(chan struct{}, 1),
 	}
 
-	ticket := &net.Ticket{
-		Sender:        pm.RandAddress().Bytes(),
-		RecipientRand: big.NewInt(1337).Bytes(),
-		TicketParams: &net.TicketParams{
-			Recipient:         pm.RandAddress().Bytes(),
-			FaceValue:         big.NewInt(100).Bytes(),
-			WinProb:           big.NewInt(100).Bytes(),
-			RecipientRandHash: pm.RandBytes(32),
-			ExpirationBlock:   big.NewInt(100).Bytes(),
-		},
-		SenderParams: &net.TicketSenderParams{
-			Sig:         pm.RandBytes(32),
-			SenderNonce: 1,
-		},
-		ExpirationParams: &net.TicketExpirationParams{
-			CreationRound:          100,
-			CreationRoundBlockHash: pm.RandHash().Bytes(),
-		},
-	}
+	// the ticket is stored without waiting for the Redeemer
+	ticket := pmTicket(redeemerTestTicket(pm.RandAddress()))
+	assert.Nil(rc.QueueTicket(ticket))
+	assert.Nil(rc.QueueTicket(pmTicket(redeemerTestTicket(pm.RandAddress()))))
+	assert.Len(rc.forward, 1)
+	count, err := dbh.WinningTicketCount(ticket.Sender, 0)
+	require.Nil(err)
+	assert.Equal(1, count)
 
-	rpc.EXPECT().QueueTicket(gomock.Any(), gomock.Any()).Return(nil, errors.New("QueueTicket error"))
-	assert.EqualError(rc.QueueTicket(pmTicket(ticket)), "QueueTicket error")
+	// the ticket is not acknowledged if it can't be stored
+	assert.NotNil(rc.QueueTicket(&pm.SignedTicket{Ticket: ticket.Ticket}))
 }
 
-func TestRedeemerClient_QueueTicket_Success(t *testing.T) {
+func TestRedeemerClient_ForwardTickets(t *testing.T) {
 	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
 
 	ctrl := gomock.NewController(t)
 	rpc := net.NewMockTicketRedeemerClient(ctrl)
@@ -416,31 +424,52 @@ func TestRedeemerClient_QueueTicket_Success(t *testing.T) {
 	rc := &RedeemerClient{
 		rpc:     rpc,
 		senders: make(map[ethcommon.Address]*remoteSender), quit: make(chan struct{}),
+		store:   dbh,
+		forward: make(chan struct{}, 1),
 	}
 
-	ticket := &net.Ticket{
-		Sender:        pm.RandAddress().Bytes(),
-		RecipientRand: big.NewInt(1337).Bytes(),
-		TicketParams: &net.TicketParams{
-			Recipient:         pm.RandAddress().Bytes(),
-			FaceValue:         big.NewInt(100).Bytes(),
-			WinProb:           big.NewInt(100).Bytes(),
-			RecipientRandHash: pm.RandBytes(32),
-			ExpirationBlock:   big.NewInt(100).Bytes(),
-		},
-		SenderParams: &net.TicketSenderParams{
-			Sig:         pm.RandBytes(32),
-			SenderNonce: 1,
-		},
-		ExpirationParams: &net.TicketExpirationParams{
-			CreationRound:          100,
-			CreationRoundBlockHash: pm.RandHash().Bytes(),
-		},
+	sender := pm.RandAddress()
+	for i := 0; i < 2; i++ {
+		ticket := redeemerTestTicket(pm.RandAddress())
+		ticket.Sender = sender.Bytes()
+		ticket.SenderParams.SenderNonce = uint32(i)
+		require.Nil(rc.QueueTicket(pmTicket(ticket)))
 	}
 
-	rpc.EXPECT().QueueTicket(gomock.Any(), gomock.Any()).Return(nil, nil)
-	err := rc.QueueTicket(pmTicket(ticket))
-	assert.Nil(err)
+	// tickets that could not be sent to the Redeemer stay stored
+	rpc.EXPECT().QueueTicket(gomock.Any(), gomock.Any()).Return(nil, errors.New("QueueTicket error"))
+	assert.EqualError(rc.forwardTickets(), "QueueTicket error")
+	count, err := dbh.WinningTicketCount(sender, 0)
+	require.Nil(err)
+	assert.Equal(2, count)
+
+	// tickets are removed once sent
+	rpc.EXPECT().QueueTicket(gomock.Any(), gomock.Any()).Return(&net.QueueTicketRes{}, nil).Times(2)
+	assert.Nil(rc.forwardTickets())
+	count, err = dbh.WinningTicketCount(sender, 0)
+	require.Nil(err)
+	assert.Equal(0, count)
+
+	// a ticket rejected by the Redeemer is dropped, and the other tickets are still sent
+	poison := redeemerTestTicket(pm.RandAddress())
+	poison.Sender = sender.Bytes()
+	poison.SenderParams.SenderNonce = 2
+	require.Nil(rc.QueueTicket(pmTicket(poison)))
+	sender2 := pm.RandAddress()
+	ticket := redeemerTestTicket(pm.RandAddress())
+	ticket.Sender = sender2.Bytes()
+	require.Nil(rc.QueueTicket(pmTicket(ticket)))
+	gomock.InOrder(
+		rpc.EXPECT().QueueTicket(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.PermissionDenied, "invalid ticket recipient")),
+		rpc.EXPECT().QueueTicket(gomock.Any(), gomock.Any()).Return(&net.QueueTicketRes{}, nil),
+	)
+	assert.Nil(rc.forwardTickets())
+	count, err = dbh.WinningTicketCount(sender, 0)
+	require.Nil(err)
+	assert.Equal(0, count)
+	count, err = dbh.WinningTicketCount(sender2, 0)
+	require.Nil(err)
+	assert.Equal(0, count)
 }
 
 func TestRedeemerClient_MaxFloat_LocalCacheExists(t *testing.T) {
@@ -517,7 +546,10 @@ func TestRedeemerClient_MaxFloat_NoLocalCache_MaxFloatSuccess_MonitorMaxFloatSuc
 	rc := &RedeemerClient{
 		senders: make(map[ethcommon.Address]*remoteSender),
 		rpc:     rpc,
+		quit:    make(chan struct{}),
 	}
+	// stop watching the stream, which returns updates indefinitely
+	defer close(rc.quit)
 
 	sender := ethcommon.HexToAddress("foo")
 	expFloat := big.NewInt(100)
@@ -709,6 +741,169 @@ func TestRedeemerClient_CleanupLoop(t *testing.T) {
 	close(r.quit)
 }
 
+func TestRedeemerServer_Standby(t *testing.T) {
+	assert := assert.New(t)
+
+	recipient := pm.RandAddress()
+	r := &Redeemer{
+		recipient: recipient,
+		eth:       &eth.StubClient{},
+		sm:        newStubSenderMonitor(),
+		quit:      make(chan struct{}),
+		leases:    &stubLeaseStore{},
+	}
+
+	_, err := r.QueueTicket(context.Background(), redeemerTestTicket(recipient))
+	assert.Equal(codes.Unavailable, status.Code(err))
+	_, err = r.MaxFloat(context.Background(), &net.MaxFloatReq{Sender: pm.RandAddress().Bytes()})
+	assert.Equal(codes.Unavailable, status.Code(err))
+	err = r.MonitorMaxFloat(&net.MaxFloatReq{Sender: pm.RandAddress().Bytes()}, nil)
+	assert.Equal(codes.Unavailable, status.Code(err))
+}
+
+func TestRedeemerServer_HoldLease(t *testing.T) {
+	assert := assert.New(t)
+
+	oldTTL := redeemerLeaseTTL
+	redeemerLeaseTTL = 30 * time.Millisecond
+	defer func() { redeemerLeaseTTL = oldTTL }()
+
+	recipient := pm.RandAddress()
+	leases := &stubLeaseStore{acquire: true}
+	sm := newStubSenderMonitor()
+	r := &Redeemer{
+		server:    grpc.NewServer(),
+		recipient: recipient,
+		eth:       &eth.StubClient{},
+		sm:        sm,
+		quit:      make(chan struct{}),
+		leases:    leases,
+		holder:    "foo",
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.holdLease()
+		close(done)
+	}()
+
+	// Redeemer becomes active once it acquires the lease
+	assert.Eventually(r.isActive, time.Second, time.Millisecond)
+	_, err := r.QueueTicket(context.Background(), redeemerTestTicket(recipient))
+	assert.Nil(err)
+
+	// Redeemer stops when it loses the lease
+	leases.setAcquire(false)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for redeemer to stop")
+	}
+	assert.EqualError(r.leaseErr, "redeemer lost its lease holder=foo")
+	// and stops redeeming tickets, which the new active Redeemer redeems
+	assert.False(r.isActive())
+	assert.Equal(1, sm.started)
+	assert.Equal(1, sm.stopped)
+
+	// the sender monitor isn't stopped twice
+	r.Stop()
+	assert.Equal(1, sm.stopped)
+}
+
+func TestRedeemer_ActiveStandbyFailover(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldTTL := redeemerLeaseTTL
+	redeemerLeaseTTL = 60 * time.Millisecond
+	defer func() { redeemerLeaseTTL = oldTTL }()
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	recipient := pm.RandAddress()
+	newRedeemer := func() (*Redeemer, *stubSenderMonitor) {
+		sm := newStubSenderMonitor()
+		return &Redeemer{
+			recipient: recipient,
+			eth:       &eth.StubClient{},
+			sm:        sm,
+			quit:      make(chan struct{}),
+			leases:    dbh,
+		}, sm
+	}
+	start := func(r *Redeemer, addr string) chan error {
+		u, err := url.ParseRequestURI("https://" + addr)
+		require.Nil(err)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- r.Start(u, t.TempDir())
+		}()
+		return errCh
+	}
+
+	r1, sm1 := newRedeemer()
+	errCh1 := start(r1, "127.0.0.1:8936")
+	require.Eventually(r1.isActive, time.Second, time.Millisecond)
+
+	r2, sm2 := newRedeemer()
+	errCh2 := start(r2, "127.0.0.1:8937")
+	defer r2.Stop()
+	time.Sleep(2 * redeemerLeaseTTL)
+	assert.False(r2.isActive())
+
+	// The client fails over from the standby Redeemer to the active one
+	rc, err := NewRedeemerClient([]string{"127.0.0.1:8937", "127.0.0.1:8936"}, newStubSenderManager(), &stubTimeManager{}, nil)
+	require.Nil(err)
+	defer rc.Stop()
+	require.Eventually(func() bool {
+		return rc.sendTicket(pmTicket(redeemerTestTicket(recipient))) == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(sm1.queued, 1)
+	assert.Len(sm2.queued, 0)
+
+	// The standby Redeemer takes over when the active one stops, and the client fails over to it
+	r1.Stop()
+	assert.Nil(<-errCh1)
+	require.Eventually(r2.isActive, time.Second, time.Millisecond)
+	assert.Nil(rc.sendTicket(pmTicket(redeemerTestTicket(recipient))))
+	assert.Len(sm1.queued, 1)
+	assert.Len(sm2.queued, 1)
+
+	select {
+	case err := <-errCh2:
+		t.Fatalf("redeemer stopped err=%v", err)
+	default:
+	}
+}
+
+func TestRedeemerClient_Failover(t *testing.T) {
+	assert := assert.New(t)
+
+	rc := &RedeemerClient{}
+	// no failover without other endpoints
+	assert.False(rc.failover(0))
+
+	conn, err := grpc.Dial("127.0.0.1:1", grpc.WithInsecure())
+	require.Nil(t, err)
+	defer conn.Close()
+	rc.endpoints = []*redeemerEndpoint{{uri: "foo", conn: conn}, {uri: "bar", conn: conn}}
+	// no failover without a ready endpoint
+	assert.False(rc.failover(0))
+	assert.Equal(0, rc.active)
+
+	// another request already failed over
+	rc.active = 1
+	assert.True(rc.failover(0))
+
+	assert.True(isRedeemerUnavailable(status.Error(codes.Unavailable, "")))
+	assert.True(isRedeemerUnavailable(status.Error(codes.DeadlineExceeded, "")))
+	assert.False(isRedeemerUnavailable(status.Error(codes.Internal, "")))
+	assert.False(isRedeemerUnavailable(nil))
+}
+
 func TestProtoTicket(t *testing.T) {
 	ogTicket := &pm.SignedTicket{
 		Ticket: &pm.Ticket{
@@ -738,6 +933,8 @@ type stubSenderMonitor struct {
 	sink       chan<- struct{}
 	sub        *stubSubscription
 	subscribed chan struct{}
+	started    int
+	stopped    int
 }
 
 func newStubSenderMonitor() *stubSenderMonitor {
@@ -747,9 +944,9 @@ func newStubSenderMonitor() *stubSenderMonitor {
 	}
 }
 
-func (s *stubSenderMonitor) Start() {}
+func (s *stubSenderMonitor) Start() { s.started++ }
 
-func (s *stubSenderMonitor) Stop() {}
+func (s *stubSenderMonitor) Stop() { s.stopped++ }
 
 func (s *stubSenderMonitor) QueueTicket(ticket *pm.SignedTicket) error {
 	if s.shouldFail != nil {
@@ -884,3 +1081,44 @@ func (m *stubTimeManager) SubscribeL1Blocks(sink chan<- *big.Int) event.Subscrip
 	m.blockNumSub = &stubSubscription{errCh: make(chan error)}
 	return m.blockNumSub
 }
+
+func redeemerTestTicket(recipient ethcommon.Address) *net.Ticket {
+	return &net.Ticket{
+		Sender:        pm.RandAddress().Bytes(),
+		RecipientRand: big.NewInt(1337).Bytes(),
+		TicketParams: &net.TicketParams{
+			Recipient:         recipient.Bytes(),
+			FaceValue:         big.NewInt(100).Bytes(),
+			WinProb:           big.NewInt(100).Bytes(),
+			RecipientRandHash: pm.RandBytes(32),
+			ExpirationBlock:   big.NewInt(100).Bytes(),
+		},
+		SenderParams: &net.TicketSenderParams{
+			Sig:         pm.RandBytes(32),
+			SenderNonce: 1,
+		},
+		ExpirationParams: &net.TicketExpirationParams{
+			CreationRound:          100,
+			CreationRoundBlockHash: pm.RandHash().Bytes(),
+		},
+	}
+}
+
+type stubLeaseStore struct {
+	mu      sync.Mutex
+	acquire bool
+}
+
+func (s *stubLeaseStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.acquire, nil
+}
+
+func (s *stubLeaseStore) ReleaseLease(name, holder string) error { return nil }
+
+func (s *stubLeaseStore) setAcquire(acquire bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acquire = acquire
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/livepeer/go-livepeer/net"
+)
+
+// Factor by which the selection weight of orchestrators outside of the preferred regions is scaled
+const otherRegionSelectionWeight = 0.1
+
+// OrchRegions, if set, favors or restricts selection to orchestrators advertising one of a set of regions
+var OrchRegions *RegionSelection
+
+// RegionSelection favors orchestrators in a set of regions during selection or, if required,
+// excludes orchestrators that are in other regions or don't advertise a region at all.
+// Regions are matched case insensitively.
+type RegionSelection struct {
+	regions  map[string]bool
+	required bool
+}
+
+// NewRegionSelection creates a RegionSelection for the given regions
+func NewRegionSelection(regions []string, required bool) *RegionSelection {
+	r := &RegionSelection{regions: make(map[string]bool), required: required}
+	for _, region := range regions {
+		region = strings.ToLower(strings.TrimSpace(region))
+		if region != "" {
+			r.regions[region] = true
+		}
+	}
+	return r
+}
+
+func (r *RegionSelection) inRegion(region string) bool {
+	return r.regions[strings.ToLower(strings.TrimSpace(region))]
+}
+
+// Allows returns whether the orchestrator that returned info may be selected
+func (r *RegionSelection) Allows(info *net.OrchestratorInfo) bool {
+	if r == nil || !r.required || len(r.regions) == 0 {
+		return true
+	}
+	return r.inRegion(info.GetRegion())
+}
+
+// RequiredRegions returns the regions that orchestrators must be in to be selected, or nil if any region is allowed
+func (r *RegionSelection) RequiredRegions() []string {
+	if r == nil || !r.required || len(r.regions) == 0 {
+		return nil
+	}
+	regions := make([]string, 0, len(r.regions))
+	for region := range r.regions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// selectionWeight scales down the selection weight of orchestrators outside of the preferred regions
+func (r *RegionSelection) selectionWeight(region string) float64 {
+	if r == nil || len(r.regions) == 0 || r.inRegion(region) {
+		return 1
+	}
+	return otherRegionSelectionWeight
+}
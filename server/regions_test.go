@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionSelection(t *testing.T) {
+	assert := assert.New(t)
+
+	// preferred regions don't exclude any orchestrator
+	r := NewRegionSelection([]string{"us-east", " EU-West ", ""}, false)
+	assert.Len(r.regions, 2)
+	assert.True(r.Allows(&net.OrchestratorInfo{Region: "us-east"}))
+	assert.True(r.Allows(&net.OrchestratorInfo{Region: "ap-south"}))
+	assert.True(r.Allows(&net.OrchestratorInfo{}))
+	assert.Equal(1.0, r.selectionWeight("us-east"))
+	assert.Equal(1.0, r.selectionWeight("eu-west"))
+	assert.Equal(1.0, r.selectionWeight("US-EAST"))
+	assert.Equal(otherRegionSelectionWeight, r.selectionWeight("ap-south"))
+	assert.Equal(otherRegionSelectionWeight, r.selectionWeight(""))
+	assert.Nil(r.RequiredRegions())
+
+	// required regions exclude orchestrators in other regions or without a region
+	r = NewRegionSelection([]string{"us-east", "eu-west"}, true)
+	assert.True(r.Allows(&net.OrchestratorInfo{Region: "us-east"}))
+	assert.True(r.Allows(&net.OrchestratorInfo{Region: "EU-West"}))
+	assert.False(r.Allows(&net.OrchestratorInfo{Region: "ap-south"}))
+	assert.False(r.Allows(&net.OrchestratorInfo{}))
+	assert.Equal([]string{"eu-west", "us-east"}, r.RequiredRegions())
+
+	// no regions
+	r = NewRegionSelection(nil, true)
+	assert.True(r.Allows(&net.OrchestratorInfo{Region: "ap-south"}))
+	assert.Equal(1.0, r.selectionWeight("ap-south"))
+
+	// nil selection
+	var nilRegions *RegionSelection
+	assert.True(nilRegions.Allows(&net.OrchestratorInfo{Region: "ap-south"}))
+	assert.Equal(1.0, nilRegions.selectionWeight("ap-south"))
+	assert.Nil(nilRegions.RequiredRegions())
+}
+
+func TestMinLSSelector_PreferredRegions(t *testing.T) {
+	assert := assert.New(t)
+
+	sessions := []*BroadcastSession{
+		{OrchestratorInfo: &net.OrchestratorInfo{Transcoder: "https://o0:8935", Region: "ap-south"}},
+		{OrchestratorInfo: &net.OrchestratorInfo{Transcoder: "https://o1:8935"}},
+		{OrchestratorInfo: &net.OrchestratorInfo{Transcoder: "https://o2:8935", Region: "us-east"}},
+	}
+
+	// off-chain, orchestrators in the preferred regions are selected first
+	sel := NewMinLSSelector(nil, 1.0)
+	sel.regions = NewRegionSelection([]string{"us-east"}, false)
+	sel.Add(sessions)
+	assert.Same(sessions[2], sel.Select(context.TODO()))
+	assert.Same(sessions[0], sel.Select(context.TODO()))
+	assert.Same(sessions[1], sel.Select(context.TODO()))
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/livepeer/go-livepeer/net"
+)
+
+// Reputation, if set, scores orchestrators by their past performance and price. The score replaces the performance
+// stats in the selection weight of orchestrators.
+var Reputation OrchReputation
+
+// OrchReputation scores the reputation of orchestrators
+type OrchReputation interface {
+	// Score returns the reputation score, between 0 and 1, of the orchestrator that returned info
+	Score(info *net.OrchestratorInfo) float64
+	// VerificationFailed records that a segment transcoded by the orchestrator at uri failed verification
+	VerificationFailed(uri string)
+	// Scores returns the reputation scores of all known orchestrators
+	Scores() []*ReputationScore
+}
+
+// ReputationScore is the reputation score of an orchestrator and the components it is made of, each between 0 and 1
+type ReputationScore struct {
+	ServiceURI           string  `json:"serviceURI"`
+	Score                float64 `json:"score"`
+	SuccessRate          float64 `json:"successRate"`
+	Latency              float64 `json:"latency"`
+	Verification         float64 `json:"verification"`
+	Price                float64 `json:"price"`
+	VerificationFailures int64   `json:"verificationFailures"`
+	PricePerPixel        string  `json:"pricePerPixel,omitempty"`
+}
+
+// observeVerificationFailure records that a segment transcoded by the orchestrator of sess failed verification
+func observeVerificationFailure(sess *BroadcastSession) {
+	if Reputation == nil {
+		return
+	}
+	Reputation.VerificationFailed(sess.Transcoder())
+}
+
+func orchestratorScoresHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Reputation == nil {
+			respond400(w, "orchestrator reputation scoring is not enabled")
+			return
+		}
+		respondJson(w, Reputation.Scores())
+	})
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubReputation struct {
+	scores   map[string]float64
+	failures map[string]int
+}
+
+func (r *stubReputation) Score(info *net.OrchestratorInfo) float64 {
+	return r.scores[info.GetTranscoder()]
+}
+
+func (r *stubReputation) VerificationFailed(uri string) {
+	r.failures[uri]++
+}
+
+func (r *stubReputation) Scores() []*ReputationScore {
+	var scores []*ReputationScore
+	for uri, score := range r.scores {
+		scores = append(scores, &ReputationScore{ServiceURI: uri, Score: score})
+	}
+	return scores
+}
+
+func TestMinLSSelector_Reputation(t *testing.T) {
+	assert := assert.New(t)
+
+	sessions := []*BroadcastSession{
+		{OrchestratorInfo: &net.OrchestratorInfo{Transcoder: "https://o0:8935"}},
+		{OrchestratorInfo: &net.OrchestratorInfo{Transcoder: "https://o1:8935"}},
+		{OrchestratorInfo: &net.OrchestratorInfo{Transcoder: "https://o2:8935"}},
+	}
+	rep := &stubReputation{scores: map[string]float64{"https://o0:8935": 0.2, "https://o1:8935": 0.9, "https://o2:8935": 0.5}}
+
+	// the reputation replaces the orchestrator stats, which would favor o0
+	stats := &OrchestratorStats{totals: make(map[string]*common.DBOrchStats)}
+	stats.totals["https://o0:8935"] = &common.DBOrchStats{ServiceURI: "https://o0:8935", Segments: 10, Successes: 10, RoundTripMs: 1000}
+
+	// off-chain, orchestrators are selected by descending reputation
+	sel := newSessionsSelector(nil, 0, nil, stats, nil, rep)
+	sel.Add(sessions)
+	assert.Same(sessions[1], sel.Select(context.TODO()))
+	assert.Same(sessions[2], sel.Select(context.TODO()))
+	assert.Same(sessions[0], sel.Select(context.TODO()))
+}
+
+func TestObserveVerificationFailure(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { Reputation = nil }()
+
+	sess := &BroadcastSession{OrchestratorInfo: &net.OrchestratorInfo{Transcoder: "https://o0:8935"}}
+
+	// no-op without reputation
+	observeVerificationFailure(sess)
+
+	rep := &stubReputation{failures: make(map[string]int)}
+	Reputation = rep
+	observeVerificationFailure(sess)
+	observeVerificationFailure(sess)
+	assert.Equal(2, rep.failures["https://o0:8935"])
+}
+
+func TestOrchestratorScoresHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	defer func() { Reputation = nil }()
+
+	get := func() *http.Response {
+		ts := httptest.NewServer(orchestratorScoresHandler())
+		defer ts.Close()
+		client := &http.Client{Timeout: 5 * time.Second}
+		res, err := client.Get(ts.URL)
+		require.Nil(err)
+		return res
+	}
+
+	// reputation scoring not enabled
+	res := get()
+	assert.Equal(http.StatusBadRequest, res.StatusCode)
+
+	Reputation = &stubReputation{scores: map[string]float64{"https://o0:8935": 0.5}}
+	res = get()
+	require.Equal(http.StatusOK, res.StatusCode)
+	body, err := ioutil.ReadAll(res.Body)
+	require.Nil(err)
+	var scores []*ReputationScore
+	require.Nil(json.Unmarshal(body, &scores))
+	assert.Equal([]*ReputationScore{{ServiceURI: "https://o0:8935", Score: 0.5}}, scores)
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/livepeer/go-livepeer/clog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/verification"
+)
+
+// RetryPolicy configures how segments that failed to transcode are retried.
+// Its MaxAttempts is ignored in favor of the MaxAttempts var.
+var RetryPolicy = core.RetryPolicy{TimeoutRetries: -1, RefusalRetries: -1, VerificationRetries: -1}
+
+// failureKind classifies a failed transcode attempt to decide how it is retried
+type failureKind int
+
+const (
+	failureOther failureKind = iota
+	// The orchestrator did not respond in time
+	failureTimeout
+	// The orchestrator declined the segment, e.g. because it is busy or at capacity
+	failureRefusal
+	// The transcoded results failed verification
+	failureVerification
+)
+
+func (k failureKind) String() string {
+	switch k {
+	case failureTimeout:
+		return "timeout"
+	case failureRefusal:
+		return "refusal"
+	case failureVerification:
+		return "verification"
+	}
+	return "other"
+}
+
+// errOrchRefused wraps the errors returned by orchestrators that declined a segment
+type errOrchRefused struct {
+	error
+}
+
+func (e errOrchRefused) Unwrap() error {
+	return e.error
+}
+
+func classifyFailure(err error) failureKind {
+	if isTimeoutError(err) {
+		return failureTimeout
+	}
+	if verification.IsRetryable(err) {
+		return failureVerification
+	}
+	var refused errOrchRefused
+	if errors.As(err, &refused) {
+		return failureRefusal
+	}
+	return failureOther
+}
+
+// retryLimit returns the maximum number of retries after failures of the given kind, negative for no limit
+func retryLimit(policy core.RetryPolicy, kind failureKind) int {
+	switch kind {
+	case failureTimeout:
+		return policy.TimeoutRetries
+	case failureRefusal:
+		return policy.RefusalRetries
+	case failureVerification:
+		return policy.VerificationRetries
+	}
+	return -1
+}
+
+// retryDelay returns how long to wait before retrying after a failure of the given kind.
+// Timeouts already held the segment back for the length of the timeout and verification failures
+// mean that the orchestrator was responsive, so the segment is retried with another orchestrator right away.
+func retryDelay(policy core.RetryPolicy, kind failureKind, retry int) time.Duration {
+	if kind == failureTimeout || kind == failureVerification {
+		return 0
+	}
+	return policy.BackoffDelay(retry)
+}
+
+// segmentRetries tracks the failed attempts of a segment against its retry policy
+type segmentRetries struct {
+	policy core.RetryPolicy
+	start  time.Time
+	// segDur caps the backoff delays and, without a budget, the time spent backing off, so that the
+	// segment isn't held back longer than it takes to play it
+	segDur   time.Duration
+	retries  int
+	failures map[failureKind]int
+}
+
+func newSegmentRetries(policy core.RetryPolicy, start time.Time, segDur time.Duration) *segmentRetries {
+	return &segmentRetries{policy: policy, start: start, segDur: segDur, failures: make(map[failureKind]int)}
+}
+
+// retry waits for the backoff delay after the failed attempt and returns nil if the segment should be retried
+func (r *segmentRetries) retry(ctx context.Context, err error) error {
+	kind := classifyFailure(err)
+	r.failures[kind]++
+	if limit := retryLimit(r.policy, kind); limit >= 0 && r.failures[kind] > limit {
+		return fmt.Errorf("Hit max %v retries: %w", kind, err)
+	}
+
+	r.retries++
+	delay := retryDelay(r.policy, kind, r.retries)
+	if r.segDur > 0 && delay > r.segDur {
+		delay = r.segDur
+	}
+	budget := r.policy.Budget
+	if budget <= 0 && delay > 0 {
+		budget = r.segDur
+	}
+	if budget > 0 && time.Since(r.start)+delay >= budget {
+		return fmt.Errorf("Hit retry budget: %w", err)
+	}
+	if delay <= 0 {
+		return nil
+	}
+	clog.V(common.DEBUG).Infof(ctx, "Retrying segment after backoff delay=%v failure=%v", delay, kind)
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// authWebhookRetryPolicy overrides the retry policy of a stream. Unset fields keep the node's defaults.
+type authWebhookRetryPolicy struct {
+	MaxAttempts            int   `json:"maxAttempts"`
+	BudgetMs               int64 `json:"budgetMs"`
+	BackoffMs              int64 `json:"backoffMs"`
+	MaxBackoffMs           int64 `json:"maxBackoffMs"`
+	MaxTimeoutRetries      *int  `json:"maxTimeoutRetries"`
+	MaxRefusalRetries      *int  `json:"maxRefusalRetries"`
+	MaxVerificationRetries *int  `json:"maxVerificationRetries"`
+}
+
+func (r *authWebhookRetryPolicy) apply(policy core.RetryPolicy) *core.RetryPolicy {
+	if r.MaxAttempts > 0 {
+		policy.MaxAttempts = r.MaxAttempts
+	}
+	if r.BudgetMs > 0 {
+		policy.Budget = time.Duration(r.BudgetMs) * time.Millisecond
+	}
+	if r.BackoffMs > 0 {
+		policy.Backoff = time.Duration(r.BackoffMs) * time.Millisecond
+	}
+	if r.MaxBackoffMs > 0 {
+		policy.MaxBackoff = time.Duration(r.MaxBackoffMs) * time.Millisecond
+	}
+	if r.MaxTimeoutRetries != nil {
+		policy.TimeoutRetries = *r.MaxTimeoutRetries
+	}
+	if r.MaxRefusalRetries != nil {
+		policy.RefusalRetries = *r.MaxRefusalRetries
+	}
+	if r.MaxVerificationRetries != nil {
+		policy.VerificationRetries = *r.MaxVerificationRetries
+	}
+	return &policy
+}
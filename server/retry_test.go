@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/verification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(failureTimeout, classifyFailure(fmt.Errorf("header timeout: %w", context.DeadlineExceeded)))
+	assert.Equal(failureRefusal, classifyFailure(errOrchRefused{errors.New("OrchestratorBusy")}))
+	assert.Equal(failureVerification, classifyFailure(verification.ErrPixelMismatch))
+	assert.Equal(failureVerification, classifyFailure(verification.ErrTampered))
+	assert.Equal(failureOther, classifyFailure(errors.New("UnknownResponse")))
+
+	// refusals keep the retry-after hint of the orchestrator
+	refused := errOrchRefused{WithRetryAfter(errors.New("OrchestratorCapped"), "5")}
+	assert.Equal(failureRefusal, classifyFailure(refused))
+	assert.Equal(5*time.Second, RetryAfter(fmt.Errorf("wrapped: %w", refused)))
+}
+
+func TestSegmentRetries(t *testing.T) {
+	assert := assert.New(t)
+
+	refused := errOrchRefused{errors.New("OrchestratorBusy")}
+	timeout := fmt.Errorf("header timeout: %w", context.DeadlineExceeded)
+
+	// no limits
+	r := newSegmentRetries(RetryPolicy, time.Now(), 0)
+	for i := 0; i < 5; i++ {
+		assert.Nil(r.retry(context.Background(), refused))
+		assert.Nil(r.retry(context.Background(), timeout))
+	}
+
+	// failures of each kind are limited separately
+	policy := RetryPolicy
+	policy.TimeoutRetries = 0
+	policy.RefusalRetries = 1
+	r = newSegmentRetries(policy, time.Now(), 0)
+	assert.Nil(r.retry(context.Background(), refused))
+	assert.Nil(r.retry(context.Background(), errors.New("UnknownResponse")))
+	assert.EqualError(r.retry(context.Background(), refused), "Hit max refusal retries: OrchestratorBusy")
+	assert.EqualError(r.retry(context.Background(), timeout), "Hit max timeout retries: header timeout: context deadline exceeded")
+
+	// refusals are retried after a backoff, timeouts right away
+	policy = RetryPolicy
+	policy.Backoff = 40 * time.Millisecond
+	r = newSegmentRetries(policy, time.Now(), 0)
+	start := time.Now()
+	assert.Nil(r.retry(context.Background(), timeout))
+	assert.Less(int64(time.Since(start)), int64(20*time.Millisecond))
+	assert.Nil(r.retry(context.Background(), refused))
+	assert.GreaterOrEqual(int64(time.Since(start)), int64(20*time.Millisecond))
+
+	// no retry if the backoff would exceed the budget
+	policy.Budget = 20 * time.Millisecond
+	r = newSegmentRetries(policy, time.Now(), 0)
+	assert.EqualError(r.retry(context.Background(), refused), "Hit retry budget: OrchestratorBusy")
+	r = newSegmentRetries(policy, time.Now().Add(-time.Second), 0)
+	assert.EqualError(r.retry(context.Background(), timeout), "Hit retry budget: header timeout: context deadline exceeded")
+
+	// the backoff is interrupted when the context is done
+	policy = RetryPolicy
+	policy.Backoff = time.Hour
+	r = newSegmentRetries(policy, time.Now(), 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(context.Canceled, r.retry(ctx, refused))
+
+	// the backoff is capped by the segment duration
+	policy.Budget = time.Hour
+	r = newSegmentRetries(policy, time.Now(), 20*time.Millisecond)
+	start = time.Now()
+	assert.Nil(r.retry(context.Background(), refused))
+	assert.Less(int64(time.Since(start)), int64(time.Second))
+
+	// without a budget, backing off past the segment duration is not retried but timeouts still are
+	policy.Budget = 0
+	r = newSegmentRetries(policy, time.Now().Add(-time.Second), 2*time.Second)
+	assert.EqualError(r.retry(context.Background(), refused), "Hit retry budget: OrchestratorBusy")
+	assert.Nil(r.retry(context.Background(), timeout))
+}
+
+func TestAuthWebhookRetryPolicy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defaults := core.RetryPolicy{Budget: time.Second, TimeoutRetries: -1, RefusalRetries: -1, VerificationRetries: -1}
+
+	var resp authWebhookResponse
+	require.Nil(json.Unmarshal([]byte(`{"retry": {"maxAttempts": 5, "backoffMs": 100, "maxBackoffMs": 1000, "maxRefusalRetries": 0}}`), &resp))
+	require.NotNil(resp.Retry)
+	assert.Equal(&core.RetryPolicy{
+		MaxAttempts:         5,
+		Budget:              time.Second,
+		Backoff:             100 * time.Millisecond,
+		MaxBackoff:          time.Second,
+		TimeoutRetries:      -1,
+		RefusalRetries:      0,
+		VerificationRetries: -1,
+	}, resp.Retry.apply(defaults))
+
+	resp = authWebhookResponse{}
+	require.Nil(json.Unmarshal([]byte(`{"retry": {"budgetMs": 3000, "maxTimeoutRetries": 1, "maxVerificationRetries": 2}}`), &resp))
+	assert.Equal(&core.RetryPolicy{Budget: 3 * time.Second, TimeoutRetries: 1, RefusalRetries: -1, VerificationRetries: 2}, resp.Retry.apply(defaults))
+}
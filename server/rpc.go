@@ -9,12 +9,14 @@ import (
 	"math/big"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/livepeer/go-livepeer/clog"
 	"github.com/livepeer/go-livepeer/common"
@@ -38,6 +40,16 @@ const GRPCConnectTimeout = 3 * time.Second
 const GRPCTimeout = 8 * time.Second
 const HTTPIdleTimeout = 10 * time.Minute
 
+// retryAfterKey is the gRPC trailer / HTTP header used to tell broadcasters when to retry
+// a request refused because the orchestrator is at capacity or rate limited. Broadcasters
+// don't send new sessions to the orchestrator until then.
+const retryAfterKey = "retry-after"
+
+// ErrDiscoveryCapabilities and ErrDiscoveryPrice are returned to discovery requests for jobs that the orchestrator
+// doesn't support or that pay less than its price
+var ErrDiscoveryCapabilities = errors.New("orchestrator does not support the required capabilities")
+var ErrDiscoveryPrice = errors.New("orchestrator price is higher than the max price")
+
 var authTokenValidPeriod = 30 * time.Minute
 var discoveryAuthWebhookCacheCleanup = 5 * time.Minute
 
@@ -50,16 +62,20 @@ type Orchestrator interface {
 	Sign([]byte) ([]byte, error)
 	VerifySig(ethcommon.Address, string, []byte) bool
 	CheckCapacity(core.ManifestID) error
+	ReleaseCapacity(core.ManifestID)
+	CheckBroadcasterAccess(ethcommon.Address) error
 	TranscodeSeg(context.Context, *core.SegTranscodingMetadata, *stream.HLSSegment) (*core.TranscodeResult, error)
 	ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, capacity int, capabilities *net.Capabilities)
 	TranscoderResults(job int64, res *core.RemoteTranscoderResult)
 	ProcessPayment(ctx context.Context, payment net.Payment, manifestID core.ManifestID) error
 	TicketParams(sender ethcommon.Address, priceInfo *net.PriceInfo) (*net.TicketParams, error)
-	PriceInfo(sender ethcommon.Address) (*net.PriceInfo, error)
+	PriceInfo(sender ethcommon.Address, caps *core.Capabilities) (*net.PriceInfo, error)
 	SufficientBalance(addr ethcommon.Address, manifestID core.ManifestID) bool
 	DebitFees(addr ethcommon.Address, manifestID core.ManifestID, price *net.PriceInfo, pixels int64)
 	Capabilities() *net.Capabilities
 	AuthToken(sessionID string, expiration int64) *net.AuthToken
+	Region() string
+	Datacenter() string
 }
 
 // Balance describes methods for a session's balance maintenance
@@ -110,6 +126,7 @@ type BroadcastSession struct {
 	Balances                 *core.AddressBalances
 	OrchestratorScore        float32
 	VerifiedByPerceptualHash bool
+	PreferenceRank           int // Rank among the orchestrators preferred for the stream starting at 1, 0 if not preferred
 	lock                     *sync.RWMutex
 	// access these fields under the lock
 	SegsInFlight     []SegFlightMetadata
@@ -120,6 +137,17 @@ type BroadcastSession struct {
 	Balance          Balance
 }
 
+// NewBroadcastSession creates a session to transcode the segments of the stream described by params with
+// the orchestrator that returned info, without payments. It is meant for tools that talk to orchestrators directly.
+func NewBroadcastSession(bcast common.Broadcaster, params *core.StreamParameters, info *net.OrchestratorInfo) *BroadcastSession {
+	return &BroadcastSession{
+		Broadcaster:      bcast,
+		Params:           params,
+		OrchestratorInfo: info,
+		lock:             &sync.RWMutex{},
+	}
+}
+
 func (bs *BroadcastSession) Transcoder() string {
 	bs.lock.RLock()
 	defer bs.lock.RUnlock()
@@ -159,6 +187,9 @@ type lphttp struct {
 }
 
 func (h *lphttp) EndTranscodingSession(ctx context.Context, request *net.EndTranscodingSessionRequest) (*net.EndTranscodingSessionResponse, error) {
+	if err := verifyBroadcasterAuthRPC(ctx); err != nil {
+		return nil, err
+	}
 	return endTranscodingSession(h.node, h.orchestrator, request)
 }
 
@@ -173,7 +204,21 @@ func (h *lphttp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *lphttp) GetOrchestrator(context context.Context, req *net.OrchestratorRequest) (*net.OrchestratorInfo, error) {
-	return getOrchestrator(h.orchestrator, req)
+	if err := verifyBroadcasterAuthRPC(context); err != nil {
+		return nil, err
+	}
+	if err := DiscoveryRateLimits.AllowIP(peerIP(context)); err != nil {
+		setRateLimitTrailer(context, err.(discoveryRateLimitError))
+		return nil, err
+	}
+	info, err := getOrchestrator(h.orchestrator, req)
+	if retryAfter := retryAfterSeconds(); retryAfter != "" && isOrchCapError(err) {
+		grpc.SetTrailer(context, metadata.Pairs(retryAfterKey, retryAfter))
+	}
+	if rlErr, ok := err.(discoveryRateLimitError); ok {
+		setRateLimitTrailer(context, rlErr)
+	}
+	return info, err
 }
 
 func (h *lphttp) Ping(context context.Context, req *net.PingPong) (*net.PingPong, error) {
@@ -191,6 +236,7 @@ func StartTranscodeServer(orch Orchestrator, bind string, mux *http.ServeMux, wo
 	}
 	net.RegisterOrchestratorServer(s, &lp)
 	lp.transRPC.HandleFunc("/segment", lp.ServeSegment)
+	lp.transRPC.HandleFunc("/status", lp.ServeStatus)
 	if acceptRemoteTranscoders {
 		net.RegisterTranscoderServer(s, &lp)
 		lp.transRPC.HandleFunc("/transcodeResults", lp.TranscodeResults)
@@ -239,7 +285,7 @@ func CheckOrchestratorAvailability(orch Orchestrator) bool {
 }
 
 func ping(context context.Context, req *net.PingPong, orch Orchestrator) (*net.PingPong, error) {
-	glog.Info("Received Ping request")
+	glog.V(common.DEBUG).Info("Received Ping request")
 	value, err := orch.Sign(req.Value)
 	if err != nil {
 		glog.Error("Unable to sign Ping request")
@@ -248,18 +294,107 @@ func ping(context context.Context, req *net.PingPong, orch Orchestrator) (*net.P
 	return &net.PingPong{Value: value}, nil
 }
 
+// OrchestratorStatus is the public status document served by the orchestrator
+type OrchestratorStatus struct {
+	Version           string         `json:"version"`
+	Address           string         `json:"address"`
+	ServiceURI        string         `json:"serviceURI"`
+	Region            string         `json:"region,omitempty"`
+	Datacenter        string         `json:"datacenter,omitempty"`
+	Capabilities      map[string]int `json:"capabilities"`
+	PriceInfo         *net.PriceInfo `json:"priceInfo,omitempty"`
+	MaxSessions       int            `json:"maxSessions"`
+	AvailableSessions int            `json:"availableSessions"`
+	Draining          bool           `json:"draining"`
+}
+
+// ServeStatus serves the orchestrator's public status without requiring the GetOrchestrator handshake
+func (h *lphttp) ServeStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	respondJson(w, orchestratorStatus(h.orchestrator, h.node))
+}
+
+// orchestratorStatus builds the status document. The price is the default base price, since the prices
+// quoted to specific broadcasters are only disclosed to them in GetOrchestrator.
+func orchestratorStatus(orch Orchestrator, node *core.LivepeerNode) *OrchestratorStatus {
+	status := &OrchestratorStatus{
+		Version:      core.LivepeerVersion,
+		Address:      orch.Address().Hex(),
+		ServiceURI:   orch.ServiceURI().String(),
+		Capabilities: make(map[string]int),
+		MaxSessions:  core.MaxSessions,
+	}
+
+	if caps := orch.Capabilities(); caps != nil {
+		for c, capacity := range caps.Capacities {
+			name, err := core.CapabilityToName(core.Capability(c))
+			if err != nil {
+				continue
+			}
+			status.Capabilities[name] = int(capacity)
+		}
+	}
+
+	if node == nil {
+		return status
+	}
+	status.Region = node.Region
+	status.Datacenter = node.Datacenter
+
+	// Only advertise a price when running with payments enabled
+	if node.Recipient != nil {
+		if price := node.BasePriceForBroadcaster("default"); price != nil {
+			status.PriceInfo = &net.PriceInfo{
+				PricePerUnit:  price.Num().Int64(),
+				PixelsPerUnit: price.Denom().Int64(),
+			}
+		}
+	}
+
+	status.Draining = node.Draining()
+	status.AvailableSessions = core.MaxSessions - node.ActiveSessions()
+	if status.AvailableSessions < 0 || status.Draining {
+		status.AvailableSessions = 0
+	}
+
+	return status
+}
+
+// GetOrchestratorInfoParams are the requirements of a job that the broadcaster sends to orchestrators during discovery
+type GetOrchestratorInfoParams struct {
+	// Caps are the capabilities required by the job, if any
+	Caps *net.Capabilities
+	// MaxPrice is the maximum price paid for the job, if any
+	MaxPrice *big.Rat
+}
+
+// DiscoveryParams returns the requirements sent to orchestrators for a job requiring caps
+func DiscoveryParams(caps common.CapabilityComparator) GetOrchestratorInfoParams {
+	var params GetOrchestratorInfoParams
+	if caps != nil {
+		params.Caps = caps.ToNetCapabilities()
+	}
+	params.MaxPrice = BroadcastCfg.MaxPriceForCapabilities(caps)
+	return params
+}
+
 // GetOrchestratorInfo - the broadcaster calls GetOrchestratorInfo which invokes GetOrchestrator on the orchestrator
-func GetOrchestratorInfo(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+func GetOrchestratorInfo(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL, params GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
 	c, conn, err := startOrchestratorClient(ctx, orchestratorServer)
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 
-	req, err := genOrchestratorReq(bcast)
-	r, err := c.GetOrchestrator(ctx, req)
+	req, err := genOrchestratorReq(bcast, params)
+	var trailer metadata.MD
+	r, err := c.GetOrchestrator(ctx, req, grpc.Trailer(&trailer))
 	if err != nil {
-		return nil, errors.Wrapf(err, "Could not get orchestrator orch=%v", orchestratorServer)
+		err = errors.Wrapf(err, "Could not get orchestrator orch=%v", orchestratorServer)
+		return nil, WithRetryAfter(err, strings.Join(trailer.Get(retryAfterKey), ""))
 	}
 
 	return r, nil
@@ -287,10 +422,15 @@ func EndTranscodingSession(ctx context.Context, sess *BroadcastSession) error {
 
 func startOrchestratorClient(ctx context.Context, uri *url.URL) (net.OrchestratorClient, *grpc.ClientConn, error) {
 	clog.V(common.DEBUG).Infof(ctx, "Connecting RPC to uri=%v", uri)
-	conn, err := grpc.Dial(uri.Host,
-		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(OrchCertPins.tlsConfig(uri.Host))),
 		grpc.WithBlock(),
-		grpc.WithTimeout(GRPCConnectTimeout))
+		grpc.WithTimeout(GRPCConnectTimeout),
+	}
+	if BroadcasterSecret != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(broadcasterAuthCreds{secret: BroadcasterSecret}))
+	}
+	conn, err := grpc.Dial(uri.Host, opts...)
 	if err != nil {
 		return nil, nil, errors.Wrapf(err, "Did not connect to orch=%v", uri)
 
@@ -300,12 +440,17 @@ func startOrchestratorClient(ctx context.Context, uri *url.URL) (net.Orchestrato
 	return c, conn, nil
 }
 
-func genOrchestratorReq(b common.Broadcaster) (*net.OrchestratorRequest, error) {
+func genOrchestratorReq(b common.Broadcaster, params GetOrchestratorInfoParams) (*net.OrchestratorRequest, error) {
 	sig, err := b.Sign([]byte(fmt.Sprintf("%v", b.Address().Hex())))
 	if err != nil {
 		return nil, err
 	}
-	return &net.OrchestratorRequest{Address: b.Address().Bytes(), Sig: sig}, nil
+	req := &net.OrchestratorRequest{Address: b.Address().Bytes(), Sig: sig, Capabilities: params.Caps}
+	// Prices that don't fit in the PriceInfo message are only checked by the broadcaster
+	if maxPrice := params.MaxPrice; maxPrice != nil && maxPrice.Num().IsInt64() && maxPrice.Denom().IsInt64() {
+		req.MaxPrice = &net.PriceInfo{PricePerUnit: maxPrice.Num().Int64(), PixelsPerUnit: maxPrice.Denom().Int64()}
+	}
+	return req, nil
 }
 
 func genEndSessionRequest(sess *BroadcastSession) (*net.EndTranscodingSessionRequest, error) {
@@ -318,12 +463,26 @@ func getOrchestrator(orch Orchestrator, req *net.OrchestratorRequest) (*net.Orch
 		return nil, fmt.Errorf("Invalid orchestrator request: %v", err)
 	}
 
+	// Senders are rate limited once their signature is verified, so that others can't use up their requests
+	if err := DiscoveryRateLimits.AllowSender(addr); err != nil {
+		return nil, err
+	}
+
+	if err := orch.CheckBroadcasterAccess(addr); err != nil {
+		return nil, fmt.Errorf("Invalid orchestrator request: %v", err)
+	}
+
 	if _, err := authenticateBroadcaster(addr.Hex()); err != nil {
 		return nil, fmt.Errorf("authentication failed: %v", err)
 	}
 
+	// Reject jobs the orchestrator can't take before generating ticket params for them
+	if err := checkOrchestratorReqConstraints(orch, addr, req); err != nil {
+		return nil, err
+	}
+
 	// currently, orchestrator == transcoder
-	return orchestratorInfo(orch, addr, orch.ServiceURI().String())
+	return orchestratorInfo(orch, addr, orch.ServiceURI().String(), core.CapabilitiesFromNetCapabilities(req.Capabilities))
 }
 
 func endTranscodingSession(node *core.LivepeerNode, orch Orchestrator, req *net.EndTranscodingSessionRequest) (*net.EndTranscodingSessionResponse, error) {
@@ -335,18 +494,19 @@ func endTranscodingSession(node *core.LivepeerNode, orch Orchestrator, req *net.
 	return &net.EndTranscodingSessionResponse{}, nil
 }
 
-func getPriceInfo(orch Orchestrator, addr ethcommon.Address) (*net.PriceInfo, error) {
+// getPriceInfo returns the price for a job of the broadcaster requiring caps, which may be nil
+func getPriceInfo(orch Orchestrator, addr ethcommon.Address, caps *core.Capabilities) (*net.PriceInfo, error) {
 	if AuthWebhookURL != nil {
 		webhookRes := getFromDiscoveryAuthWebhookCache(addr.Hex())
 		if webhookRes != nil && webhookRes.PriceInfo != nil {
 			return webhookRes.PriceInfo, nil
 		}
 	}
-	return orch.PriceInfo(addr)
+	return orch.PriceInfo(addr, caps)
 }
 
-func orchestratorInfo(orch Orchestrator, addr ethcommon.Address, serviceURI string) (*net.OrchestratorInfo, error) {
-	priceInfo, err := getPriceInfo(orch, addr)
+func orchestratorInfo(orch Orchestrator, addr ethcommon.Address, serviceURI string, caps *core.Capabilities) (*net.OrchestratorInfo, error) {
+	priceInfo, err := getPriceInfo(orch, addr, caps)
 	if err != nil {
 		return nil, err
 	}
@@ -368,6 +528,8 @@ func orchestratorInfo(orch Orchestrator, addr ethcommon.Address, serviceURI stri
 		Address:      orch.Address().Bytes(),
 		Capabilities: orch.Capabilities(),
 		AuthToken:    authToken,
+		Region:       orch.Region(),
+		Datacenter:   orch.Datacenter(),
 	}
 
 	os := drivers.NodeStorage.NewSession(authToken.SessionId)
@@ -383,6 +545,96 @@ func orchestratorInfo(orch Orchestrator, addr ethcommon.Address, serviceURI stri
 	return &tr, nil
 }
 
+// checkOrchestratorReqConstraints returns ErrDiscoveryCapabilities or ErrDiscoveryPrice if the orchestrator doesn't
+// meet the requirements of the broadcaster's job
+func checkOrchestratorReqConstraints(orch Orchestrator, addr ethcommon.Address, req *net.OrchestratorRequest) error {
+	if req.Capabilities != nil && !core.CapabilitiesFromNetCapabilities(req.Capabilities).CompatibleWith(orch.Capabilities()) {
+		return ErrDiscoveryCapabilities
+	}
+	if req.MaxPrice == nil {
+		return nil
+	}
+	maxPrice, err := common.RatPriceInfo(req.MaxPrice)
+	if err != nil {
+		return fmt.Errorf("Invalid orchestrator request: invalid max price: %v", err)
+	}
+	priceInfo, err := getPriceInfo(orch, addr, core.CapabilitiesFromNetCapabilities(req.Capabilities))
+	if err != nil {
+		return err
+	}
+	price, err := common.RatPriceInfo(priceInfo)
+	if err != nil {
+		return err
+	}
+	if price != nil && price.Cmp(maxPrice) > 0 {
+		return ErrDiscoveryPrice
+	}
+	return nil
+}
+
+// IsDiscoveryMismatch returns whether the orchestrator refused a discovery request because it doesn't meet the
+// requirements of the job, which says nothing about the availability of the orchestrator
+func IsDiscoveryMismatch(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), ErrDiscoveryCapabilities.Error()) ||
+		strings.Contains(err.Error(), ErrDiscoveryPrice.Error()))
+}
+
+func isOrchCapError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), core.ErrOrchCap.Error())
+}
+
+// retryAfterSeconds returns the retry-after hint of the requests refused because the orchestrator is at capacity,
+// empty if it is disabled
+// retryAfterSeconds returns the retry-after hint of the requests refused because the orchestrator is at capacity,
+// empty if it is disabled
+func retryAfterSeconds() string {
+	if core.SessionRetryAfter <= 0 {
+		return ""
+	}
+	secs := int(core.SessionRetryAfter.Seconds())
+	if secs < 1 {
+		secs = 1
+	}
+	return strconv.Itoa(secs)
+}
+
+// MaxOrchRetryAfter caps how long an orchestrator that refused a request because it is at capacity is skipped for,
+// whatever the retry-after hint it returned
+var MaxOrchRetryAfter = 1 * time.Minute
+
+// errRetryAfter wraps the errors of the requests refused by orchestrators that told when to retry them
+type errRetryAfter struct {
+	error
+	retryAfter time.Duration
+}
+
+func (e errRetryAfter) Unwrap() error {
+	return e.error
+}
+
+// WithRetryAfter wraps err with the retry-after hint of the response that refused the request, if any
+func WithRetryAfter(err error, hint string) error {
+	secs, parseErr := strconv.ParseInt(strings.TrimSpace(hint), 10, 64)
+	if parseErr != nil || secs <= 0 {
+		return err
+	}
+	retryAfter := time.Duration(secs) * time.Second
+	if retryAfter > MaxOrchRetryAfter {
+		retryAfter = MaxOrchRetryAfter
+	}
+	return errRetryAfter{err, retryAfter}
+}
+
+// RetryAfter returns how long the orchestrator that refused a request asked to wait before retrying it, 0 if it
+// didn't tell
+func RetryAfter(err error) time.Duration {
+	var retry errRetryAfter
+	if errors.As(err, &retry) {
+		return retry.retryAfter
+	}
+	return 0
+}
+
 func verifyOrchestratorReq(orch Orchestrator, addr ethcommon.Address, sig []byte) error {
 	if !orch.VerifySig(addr, addr.Hex(), sig) {
 		glog.Error("orchestrator req sig check failed")
@@ -33,6 +33,8 @@ import (
 	"github.com/livepeer/go-tools/drivers"
 	"github.com/livepeer/lpms/ffmpeg"
 	"github.com/livepeer/lpms/stream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 type mockBalance struct {
@@ -68,6 +70,7 @@ type stubOrchestrator struct {
 	block        *big.Int
 	signErr      error
 	sessCapErr   error
+	accessErr    error
 	ticketParams *net.TicketParams
 	priceInfo    *net.PriceInfo
 	serviceURI   string
@@ -75,6 +78,9 @@ type stubOrchestrator struct {
 	offchain     bool
 	caps         *core.Capabilities
 	authToken    *net.AuthToken
+	region       string
+	datacenter   string
+	released     []core.ManifestID
 }
 
 func (r *stubOrchestrator) ServiceURI() *url.URL {
@@ -137,7 +143,7 @@ func (r *stubOrchestrator) TicketParams(sender ethcommon.Address, priceInfo *net
 	return r.ticketParams, nil
 }
 
-func (r *stubOrchestrator) PriceInfo(sender ethcommon.Address) (*net.PriceInfo, error) {
+func (r *stubOrchestrator) PriceInfo(sender ethcommon.Address, caps *core.Capabilities) (*net.PriceInfo, error) {
 	return r.priceInfo, nil
 }
 
@@ -165,6 +171,14 @@ func (r *stubOrchestrator) AuthToken(sessionID string, expiration int64) *net.Au
 	return &net.AuthToken{Token: []byte("foo"), SessionId: sessionID, Expiration: expiration}
 }
 
+func (r *stubOrchestrator) Region() string {
+	return r.region
+}
+
+func (r *stubOrchestrator) Datacenter() string {
+	return r.datacenter
+}
+
 func newStubOrchestrator() *stubOrchestrator {
 	pk, err := ethcrypto.GenerateKey()
 	if err != nil {
@@ -176,6 +190,12 @@ func newStubOrchestrator() *stubOrchestrator {
 func (r *stubOrchestrator) CheckCapacity(mid core.ManifestID) error {
 	return r.sessCapErr
 }
+func (r *stubOrchestrator) ReleaseCapacity(mid core.ManifestID) {
+	r.released = append(r.released, mid)
+}
+func (r *stubOrchestrator) CheckBroadcasterAccess(addr ethcommon.Address) error {
+	return r.accessErr
+}
 func (r *stubOrchestrator) ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, capacity int, capabilities *net.Capabilities) {
 }
 func (r *stubOrchestrator) TranscoderResults(job int64, res *core.RemoteTranscoderResult) {
@@ -192,7 +212,7 @@ func TestRPCTranscoderReq(t *testing.T) {
 	o := newStubOrchestrator()
 	b := stubBroadcaster2()
 
-	req, err := genOrchestratorReq(b)
+	req, err := genOrchestratorReq(b, GetOrchestratorInfoParams{})
 	if err != nil {
 		t.Error("Unable to create orchestrator req ", req)
 	}
@@ -224,7 +244,7 @@ func TestRPCTranscoderReq(t *testing.T) {
 
 	// error signing
 	b.signErr = fmt.Errorf("Signing error")
-	_, err = genOrchestratorReq(b)
+	_, err = genOrchestratorReq(b, GetOrchestratorInfoParams{})
 	if err == nil {
 		t.Error("Did not expect to generate a orchestrator request with invalid address")
 	}
@@ -701,6 +721,15 @@ func TestValidatePrice(t *testing.T) {
 	err = validatePrice(s)
 	assert.EqualError(err, fmt.Sprintf("Orchestrator price higher than the set maximum price of %v wei per %v pixels", int64(1), int64(5)))
 
+	// Capability specific MaxPrice > O Price for a job requiring the capability
+	defer BroadcastCfg.SetCapabilityMaxPrice(core.Capability_HEVC_Encode, nil)
+	BroadcastCfg.SetCapabilityMaxPrice(core.Capability_HEVC_Encode, big.NewRat(1, 2))
+	err = validatePrice(s)
+	assert.EqualError(err, fmt.Sprintf("Orchestrator price higher than the set maximum price of %v wei per %v pixels", int64(1), int64(5)))
+	s.Params.Capabilities = core.NewCapabilities([]core.Capability{core.Capability_H264, core.Capability_HEVC_Encode}, nil)
+	err = validatePrice(s)
+	assert.Nil(err)
+
 	// O.PriceInfo is nil
 	s.OrchestratorInfo.PriceInfo = nil
 	err = validatePrice(s)
@@ -816,6 +845,50 @@ func TestGetOrchestrator_GivenValidSig_ReturnsTranscoderURI(t *testing.T) {
 	assert.Equal(uri, oInfo.Transcoder)
 }
 
+type stubServerTransportStream struct {
+	method  string
+	trailer metadata.MD
+}
+
+func (s *stubServerTransportStream) Method() string                  { return s.method }
+func (s *stubServerTransportStream) SetHeader(md metadata.MD) error  { return nil }
+func (s *stubServerTransportStream) SendHeader(md metadata.MD) error { return nil }
+func (s *stubServerTransportStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func TestGetOrchestrator_OrchCapError_SetsRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	o := newStubOrchestrator()
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	lp := &lphttp{orchestrator: o}
+	req, err := genOrchestratorReq(stubBroadcaster2(), GetOrchestratorInfoParams{})
+	require.Nil(err)
+
+	defer func(d time.Duration) { core.SessionRetryAfter = d }(core.SessionRetryAfter)
+	core.SessionRetryAfter = 500 * time.Millisecond
+
+	o.sessCapErr = core.ErrOrchCap
+	stream := &stubServerTransportStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	oInfo, err := lp.GetOrchestrator(ctx, req)
+	assert.Nil(oInfo)
+	assert.Contains(err.Error(), core.ErrOrchCap.Error())
+	// rounded up to at least a second
+	assert.Equal([]string{"1"}, stream.trailer.Get(retryAfterKey))
+
+	// no hint on success
+	o.sessCapErr = nil
+	stream = &stubServerTransportStream{}
+	ctx = grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	oInfo, err = lp.GetOrchestrator(ctx, req)
+	assert.Nil(err)
+	assert.NotNil(oInfo)
+	assert.Empty(stream.trailer.Get(retryAfterKey))
+}
+
 func TestGetOrchestrator_GivenInvalidSig_ReturnsError(t *testing.T) {
 	orch := &mockOrchestrator{}
 	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
@@ -828,6 +901,102 @@ func TestGetOrchestrator_GivenInvalidSig_ReturnsError(t *testing.T) {
 	assert.Nil(oInfo)
 }
 
+func TestGetOrchestrator_BroadcasterNotAllowed_ReturnsError(t *testing.T) {
+	o := newStubOrchestrator()
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	req, err := genOrchestratorReq(stubBroadcaster2(), GetOrchestratorInfoParams{})
+	require.Nil(t, err)
+
+	o.accessErr = core.ErrBroadcasterNotAllowed
+	oInfo, err := getOrchestrator(o, req)
+	assert.Nil(t, oInfo)
+	assert.EqualError(t, err, "Invalid orchestrator request: BroadcasterNotAllowed")
+
+	o.accessErr = nil
+	oInfo, err = getOrchestrator(o, req)
+	assert.Nil(t, err)
+	assert.NotNil(t, oInfo)
+}
+
+func TestGetOrchestrator_JobConstraints(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	o := newStubOrchestrator()
+	o.caps = core.NewCapabilities([]core.Capability{core.Capability_H264}, nil)
+	o.priceInfo = &net.PriceInfo{PricePerUnit: 2, PixelsPerUnit: 1}
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+
+	getOrch := func(params GetOrchestratorInfoParams) (*net.OrchestratorInfo, error) {
+		req, err := genOrchestratorReq(stubBroadcaster2(), params)
+		require.Nil(err)
+		return getOrchestrator(o, req)
+	}
+
+	// No constraints
+	oInfo, err := getOrch(GetOrchestratorInfoParams{})
+	assert.Nil(err)
+	assert.NotNil(oInfo)
+
+	// Supported capabilities and price within the max
+	h264 := core.NewCapabilities([]core.Capability{core.Capability_H264}, nil).ToNetCapabilities()
+	oInfo, err = getOrch(GetOrchestratorInfoParams{Caps: h264, MaxPrice: big.NewRat(2, 1)})
+	assert.Nil(err)
+	assert.NotNil(oInfo)
+
+	// Unsupported capabilities
+	hevc := core.NewCapabilities([]core.Capability{core.Capability_HEVC_Encode}, nil).ToNetCapabilities()
+	oInfo, err = getOrch(GetOrchestratorInfoParams{Caps: hevc})
+	assert.Nil(oInfo)
+	assert.Equal(ErrDiscoveryCapabilities, err)
+	assert.True(IsDiscoveryMismatch(err))
+
+	// Price higher than the max
+	oInfo, err = getOrch(GetOrchestratorInfoParams{Caps: h264, MaxPrice: big.NewRat(3, 2)})
+	assert.Nil(oInfo)
+	assert.Equal(ErrDiscoveryPrice, err)
+	assert.True(IsDiscoveryMismatch(err))
+
+	// Invalid max price
+	req, err := genOrchestratorReq(stubBroadcaster2(), GetOrchestratorInfoParams{})
+	require.Nil(err)
+	req.MaxPrice = &net.PriceInfo{PricePerUnit: 1}
+	oInfo, err = getOrchestrator(o, req)
+	assert.Nil(oInfo)
+	assert.EqualError(err, "Invalid orchestrator request: invalid max price: pixels per unit is 0")
+	assert.False(IsDiscoveryMismatch(err))
+}
+
+func TestGenOrchestratorReq_JobConstraints(t *testing.T) {
+	assert := assert.New(t)
+	caps := core.NewCapabilities([]core.Capability{core.Capability_H264}, nil).ToNetCapabilities()
+
+	req, err := genOrchestratorReq(stubBroadcaster2(), GetOrchestratorInfoParams{Caps: caps, MaxPrice: big.NewRat(3, 2)})
+	assert.Nil(err)
+	assert.Equal(caps, req.Capabilities)
+	assert.Equal(&net.PriceInfo{PricePerUnit: 3, PixelsPerUnit: 2}, req.MaxPrice)
+
+	// Max prices that don't fit in PriceInfo are not sent
+	huge, _ := new(big.Int).SetString("100000000000000000000", 10)
+	req, err = genOrchestratorReq(stubBroadcaster2(), GetOrchestratorInfoParams{MaxPrice: new(big.Rat).SetInt(huge)})
+	assert.Nil(err)
+	assert.Nil(req.Capabilities)
+	assert.Nil(req.MaxPrice)
+
+	// Discovery params use the max price of the job's capabilities
+	defer BroadcastCfg.SetMaxPrice(nil)
+	defer BroadcastCfg.SetCapabilityMaxPrice(core.Capability_H264, nil)
+	BroadcastCfg.SetMaxPrice(big.NewRat(1, 1))
+	params := DiscoveryParams(core.NewCapabilities([]core.Capability{core.Capability_H264}, nil))
+	assert.Equal(caps.Bitstring, params.Caps.Bitstring)
+	assert.Equal(big.NewRat(1, 1), params.MaxPrice)
+	BroadcastCfg.SetCapabilityMaxPrice(core.Capability_H264, big.NewRat(5, 1))
+	params = DiscoveryParams(core.NewCapabilities([]core.Capability{core.Capability_H264}, nil))
+	assert.Equal(big.NewRat(5, 1), params.MaxPrice)
+	params = DiscoveryParams(nil)
+	assert.Nil(params.Caps)
+	assert.Equal(big.NewRat(1, 1), params.MaxPrice)
+}
+
 func TestGetOrchestrator_GivenValidSig_ReturnsOrchTicketParams(t *testing.T) {
 	orch := &mockOrchestrator{}
 	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
@@ -1025,6 +1194,26 @@ func TestGetOrchestrator_StorageInit(t *testing.T) {
 	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
 }
 
+func TestGetOrchestrator_Region(t *testing.T) {
+	assert := assert.New(t)
+
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	orch := &stubOrchestrator{offchain: true}
+	orch.authToken = stubAuthToken
+
+	oInfo, err := getOrchestrator(orch, &net.OrchestratorRequest{})
+	assert.Nil(err)
+	assert.Equal("", oInfo.Region)
+	assert.Equal("", oInfo.Datacenter)
+
+	orch.region = "us-east"
+	orch.datacenter = "aws-us-east-1"
+	oInfo, err = getOrchestrator(orch, &net.OrchestratorRequest{})
+	assert.Nil(err)
+	assert.Equal("us-east", oInfo.Region)
+	assert.Equal("aws-us-east-1", oInfo.Datacenter)
+}
+
 func TestGetPriceInfo_NoWebhook_DefaultPriceError_ReturnsError(t *testing.T) {
 	assert := assert.New(t)
 	orch := &mockOrchestrator{}
@@ -1034,7 +1223,7 @@ func TestGetPriceInfo_NoWebhook_DefaultPriceError_ReturnsError(t *testing.T) {
 
 	orch.On("PriceInfo", mock.Anything).Return(nil, expErr)
 
-	p, err := getPriceInfo(orch, addr)
+	p, err := getPriceInfo(orch, addr, nil)
 	assert.Nil(p)
 	assert.EqualError(err, expErr.Error())
 }
@@ -1052,7 +1241,7 @@ func TestGetPriceInfo_NoWebhook_ReturnsDefaultPrice(t *testing.T) {
 
 	orch.On("PriceInfo", mock.Anything).Return(priceInfo, nil)
 
-	p, err := getPriceInfo(orch, addr)
+	p, err := getPriceInfo(orch, addr, nil)
 	assert.Equal(p.PricePerUnit, int64(100))
 	assert.Equal(p.PixelsPerUnit, int64(30))
 	assert.Nil(err)
@@ -1076,7 +1265,7 @@ func TestGetPriceInfo_Webhook_NoCache_ReturnsDefaultPrice(t *testing.T) {
 
 	orch.On("PriceInfo", mock.Anything).Return(priceInfo, nil)
 
-	p, err := getPriceInfo(orch, addr)
+	p, err := getPriceInfo(orch, addr, nil)
 	assert.Equal(p.PricePerUnit, int64(100))
 	assert.Equal(p.PixelsPerUnit, int64(30))
 	assert.Nil(err)
@@ -1102,7 +1291,7 @@ func TestGetPriceInfo_Webhook_Cache_WrongType_ReturnsDefaultPrice(t *testing.T)
 
 	orch.On("PriceInfo", mock.Anything).Return(priceInfo, nil)
 
-	p, err := getPriceInfo(orch, addr)
+	p, err := getPriceInfo(orch, addr, nil)
 	assert.Equal(p.PricePerUnit, int64(100))
 	assert.Equal(p.PixelsPerUnit, int64(30))
 	assert.Nil(err)
@@ -1133,7 +1322,7 @@ func TestGetPriceInfo_Webhook_Cache_ReturnsCachePrice(t *testing.T) {
 
 	orch.On("PriceInfo", mock.Anything).Return(priceInfo, nil)
 
-	p, err := getPriceInfo(orch, addr)
+	p, err := getPriceInfo(orch, addr, nil)
 	assert.Equal(p.PricePerUnit, int64(20))
 	assert.Equal(p.PixelsPerUnit, int64(19))
 	assert.Nil(err)
@@ -1253,6 +1442,7 @@ func TestCoreNetSegData_RoundTrip_Duration(t *testing.T) {
 
 type mockOrchestrator struct {
 	mock.Mock
+	released []core.ManifestID
 }
 
 func (o *mockOrchestrator) ServiceURI() *url.URL {
@@ -1307,7 +1497,7 @@ func (o *mockOrchestrator) TicketParams(sender ethcommon.Address, priceInfo *net
 	return nil, args.Error(1)
 }
 
-func (o *mockOrchestrator) PriceInfo(sender ethcommon.Address) (*net.PriceInfo, error) {
+func (o *mockOrchestrator) PriceInfo(sender ethcommon.Address, caps *core.Capabilities) (*net.PriceInfo, error) {
 	args := o.Called(sender)
 	if args.Get(0) != nil {
 		return args.Get(0).(*net.PriceInfo), args.Error(1)
@@ -1319,6 +1509,14 @@ func (o *mockOrchestrator) CheckCapacity(mid core.ManifestID) error {
 	return nil
 }
 
+func (o *mockOrchestrator) ReleaseCapacity(mid core.ManifestID) {
+	o.released = append(o.released, mid)
+}
+
+func (o *mockOrchestrator) CheckBroadcasterAccess(addr ethcommon.Address) error {
+	return nil
+}
+
 func (o *mockOrchestrator) SufficientBalance(addr ethcommon.Address, manifestID core.ManifestID) bool {
 	args := o.Called(addr, manifestID)
 	return args.Bool(0)
@@ -1335,6 +1533,14 @@ func (o *mockOrchestrator) LegacyOnly() bool {
 	return true
 }
 
+func (o *mockOrchestrator) Region() string {
+	return ""
+}
+
+func (o *mockOrchestrator) Datacenter() string {
+	return ""
+}
+
 func (o *mockOrchestrator) AuthToken(sessionID string, expiration int64) *net.AuthToken {
 	args := o.Called(sessionID, expiration)
 	if args.Get(0) != nil {
@@ -1378,3 +1584,99 @@ func defaultTicketSenderParams(t *testing.T) *net.TicketSenderParams {
 		Sig:         pm.RandBytes(123),
 	}
 }
+
+func TestServeStatus(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	defer func(max int) { core.MaxSessions = max }(core.MaxSessions)
+	core.MaxSessions = 3
+
+	orch := newStubOrchestrator()
+	orch.caps = core.NewCapabilities([]core.Capability{core.Capability_H264, core.Capability_HEVC_Encode}, nil)
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	n.SegmentChans["foo"] = make(core.SegmentChan)
+	lp := &lphttp{orchestrator: orch, node: n}
+
+	getStatus := func() (int, *OrchestratorStatus) {
+		w := httptest.NewRecorder()
+		lp.ServeStatus(w, httptest.NewRequest("GET", "/status", nil))
+		var status OrchestratorStatus
+		if w.Code == http.StatusOK {
+			require.Nil(json.Unmarshal(w.Body.Bytes(), &status))
+		}
+		return w.Code, &status
+	}
+
+	// offchain, no price
+	code, status := getStatus()
+	assert.Equal(http.StatusOK, code)
+	assert.Equal(core.LivepeerVersion, status.Version)
+	assert.Equal(orch.Address().Hex(), status.Address)
+	assert.Equal(orch.ServiceURI().String(), status.ServiceURI)
+	assert.Equal(map[string]int{"H.264": 1, "HEVC encode": 1}, status.Capabilities)
+	assert.Nil(status.PriceInfo)
+	assert.Equal(3, status.MaxSessions)
+	assert.Equal(2, status.AvailableSessions)
+	assert.Empty(status.Region)
+	assert.Empty(status.Datacenter)
+
+	n.Region = "us-east"
+	n.Datacenter = "aws-us-east-1"
+	_, status = getStatus()
+	assert.Equal("us-east", status.Region)
+	assert.Equal("aws-us-east-1", status.Datacenter)
+
+	// with payments enabled, the default base price is advertised
+	n.Recipient = new(pm.MockRecipient)
+	n.SetBasePrice("default", big.NewRat(5, 2))
+	n.SetBasePrice(ethcommon.Address{1}.Hex(), big.NewRat(1, 1))
+	_, status = getStatus()
+	require.NotNil(status.PriceInfo)
+	assert.Equal(int64(5), status.PriceInfo.PricePerUnit)
+	assert.Equal(int64(2), status.PriceInfo.PixelsPerUnit)
+
+	// the prices quoted to specific broadcasters are not disclosed
+	orch.priceInfo = &net.PriceInfo{PricePerUnit: 7, PixelsPerUnit: 3}
+	w := httptest.NewRecorder()
+	lp.ServeStatus(w, httptest.NewRequest("GET", "/status?broadcaster="+ethcommon.Address{1}.Hex(), nil))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Contains(w.Body.String(), `"priceInfo":{"pricePerUnit":5,"pixelsPerUnit":2}`)
+
+	// capacity never goes negative
+	n.SegmentChans["bar"] = make(core.SegmentChan)
+	n.SegmentChans["baz"] = make(core.SegmentChan)
+	n.SegmentChans["qux"] = make(core.SegmentChan)
+	_, status = getStatus()
+	assert.Equal(0, status.AvailableSessions)
+	assert.False(status.Draining)
+
+	// no capacity is advertised while draining
+	delete(n.SegmentChans, "qux")
+	delete(n.SegmentChans, "baz")
+	n.SetDraining(true)
+	_, status = getStatus()
+	assert.True(status.Draining)
+	assert.Equal(0, status.AvailableSessions)
+
+	w = httptest.NewRecorder()
+	lp.ServeStatus(w, httptest.NewRequest("POST", "/status", nil))
+	assert.Equal(http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+	err := errors.New("OrchestratorCapped")
+
+	assert.Equal(time.Duration(0), RetryAfter(err))
+	for _, hint := range []string{"", "0", "-1", "soon"} {
+		assert.Equal(err, WithRetryAfter(err, hint), hint)
+	}
+
+	retryErr := WithRetryAfter(err, "5")
+	assert.Equal(5*time.Second, RetryAfter(retryErr))
+	assert.Equal("OrchestratorCapped", retryErr.Error())
+	assert.True(errors.Is(retryErr, err))
+
+	// the hint is kept when the error is wrapped, and capped
+	assert.Equal(MaxOrchRetryAfter, RetryAfter(fmt.Errorf("wrapped: %w", WithRetryAfter(err, "3600"))))
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/livepeer/go-livepeer/eth"
 	"github.com/livepeer/go-livepeer/monitor"
 	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/storage"
 	"github.com/livepeer/lpms/ffmpeg"
 	"github.com/livepeer/lpms/stream"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const paymentHeader = "Livepeer-Payment"
@@ -43,6 +45,14 @@ var errProfile = errors.New("unrecognized encoder profile")
 var errEncoder = errors.New("unrecognized video codec")
 var errDuration = errors.New("invalid duration")
 var errCapCompat = errors.New("incompatible capabilities")
+var errSegTooLong = errors.New("SegmentTooLong")
+var errSegTooBig = errors.New("SegmentTooBig")
+
+// MaxSegmentDuration is the maximum duration of segments accepted by the orchestrator. No limit if 0
+var MaxSegmentDuration time.Duration
+
+// MaxSegmentSize is the maximum size in bytes of segments accepted by the orchestrator. No limit beyond common.MaxSegSize if 0
+var MaxSegmentSize int
 
 var tlsConfig = &tls.Config{InsecureSkipVerify: true}
 var httpClient = &http.Client{
@@ -52,7 +62,7 @@ var httpClient = &http.Client{
 			cctx, cancel := context.WithTimeout(ctx, common.HTTPDialTimeout)
 			defer cancel()
 
-			tlsDialer := &tls.Dialer{Config: tlsConfig}
+			tlsDialer := &tls.Dialer{Config: OrchCertPins.tlsConfig(addr)}
 			return tlsDialer.DialContext(cctx, network, addr)
 		},
 		// Required for the transport to try to upgrade to HTTP/2 if TLSClientConfig is non-nil or
@@ -68,7 +78,13 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 	orch := h.orchestrator
 
 	remoteAddr := getRemoteAddr(r)
-	ctx := clog.AddVal(r.Context(), clog.ClientIP, remoteAddr)
+	ctx := clog.AddVal(monitor.ExtractTraceHeaders(r.Context(), r.Header), clog.ClientIP, remoteAddr)
+
+	if err := verifyBroadcasterAuth(BroadcasterSecret, segmentAuthScope(r.Header.Get(segmentHeader)), r.Header.Get(broadcasterAuthHeader), time.Now()); err != nil {
+		clog.Errorf(ctx, "Could not authenticate broadcaster err=%q", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
 
 	payment, err := getPayment(r.Header.Get(paymentHeader))
 	if err != nil {
@@ -86,17 +102,71 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 	segData, ctx, err := verifySegCreds(ctx, orch, seg, sender)
 	if err != nil {
 		clog.Errorf(ctx, "Could not verify segment creds err=%q", err)
+		if retryAfter := retryAfterSeconds(); retryAfter != "" && isOrchCapError(err) {
+			w.Header().Set(retryAfterKey, retryAfter)
+		}
 		http.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
+	// Segments rejected before they are handed to the transcode loop don't hold the slot reserved for a new session
+	mid := core.ManifestID(segData.AuthToken.SessionId)
+	transcoding := false
+	defer func() {
+		if !transcoding {
+			orch.ReleaseCapacity(mid)
+		}
+	}()
 	ctx = clog.AddSeqNo(ctx, uint64(segData.Seq))
+	ctx, span := monitor.StartSegmentSpan(ctx, monitor.SpanServeSegment, string(segData.ManifestID), uint64(segData.Seq))
+	defer span.End()
 
 	clog.V(common.VERBOSE).Infof(ctx, "Received segment dur=%v", segData.Duration)
 
+	if MaxSegmentDuration > 0 && segData.Duration > MaxSegmentDuration {
+		err := fmt.Errorf("%v duration=%v max=%v", errSegTooLong, segData.Duration, MaxSegmentDuration)
+		clog.Errorf(ctx, "Rejecting segment err=%q", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if MaxSegmentSize > 0 && r.ContentLength > int64(MaxSegmentSize) {
+		err := fmt.Errorf("%v size=%v max=%v", errSegTooBig, r.ContentLength, MaxSegmentSize)
+		clog.Errorf(ctx, "Rejecting segment err=%q", err)
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
 	if monitor.Enabled {
 		monitor.SegmentEmerged(ctx, 0, uint64(segData.Seq), len(segData.Profiles), segData.Duration.Seconds())
 	}
 
+	// download the segment before processing the payment so that a body without a
+	// content length that turns out to be too big is rejected without crediting it
+	dlStart := time.Now()
+	maxSegSize := common.MaxSegSize
+	if MaxSegmentSize > 0 && MaxSegmentSize < maxSegSize {
+		maxSegSize = MaxSegmentSize
+	}
+	data, err := common.ReadAtMost(r.Body, maxSegSize)
+	if err == common.ErrMaxBufferExceeded {
+		err := fmt.Errorf("%v max=%v", errSegTooBig, maxSegSize)
+		clog.Errorf(ctx, "Rejecting segment err=%q", err)
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		clog.Errorf(ctx, "Could not read request body - err=%q", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	dlDur := time.Since(dlStart)
+	clog.V(common.VERBOSE).Infof(ctx, "Downloaded segment dur=%v", dlDur)
+
+	if monitor.Enabled {
+		monitor.SegmentDownloaded(ctx, 0, uint64(segData.Seq), dlDur)
+	}
+
 	if err := orch.ProcessPayment(ctx, payment, core.ManifestID(segData.AuthToken.SessionId)); err != nil {
 		clog.Errorf(ctx, "error processing payment: %v", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -112,7 +182,7 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	oInfo, err := orchestratorInfo(orch, sender, orch.ServiceURI().String())
+	oInfo, err := orchestratorInfo(orch, sender, orch.ServiceURI().String(), segData.Caps)
 	if err != nil {
 		clog.Errorf(ctx, "Error updating orchestrator info - err=%q", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -121,22 +191,6 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 	// Use existing auth token because new auth tokens should only be sent out in GetOrchestrator() RPC calls
 	oInfo.AuthToken = segData.AuthToken
 
-	// download the segment and check the hash
-	dlStart := time.Now()
-	data, err := common.ReadAtMost(r.Body, common.MaxSegSize)
-	if err != nil {
-		clog.Errorf(ctx, "Could not read request body - err=%q", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	dlDur := time.Since(dlStart)
-	clog.V(common.VERBOSE).Infof(ctx, "Downloaded segment dur=%v", dlDur)
-
-	if monitor.Enabled {
-		monitor.SegmentDownloaded(ctx, 0, uint64(segData.Seq), dlDur)
-	}
-
 	uri := ""
 	if r.Header.Get("Content-Type") == "application/vnd+livepeer.uri" {
 		uri = string(data)
@@ -157,6 +211,12 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "BadRequest", http.StatusBadRequest)
 			return
 		}
+		if MaxSegmentSize > 0 && len(data) > MaxSegmentSize {
+			err := fmt.Errorf("%v size=%v max=%v", errSegTooBig, len(data), MaxSegmentSize)
+			clog.Errorf(ctx, "Rejecting segment from url=%s err=%q", uri, err)
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
 	}
 
 	hash := crypto.Keccak256(data)
@@ -165,6 +225,9 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
+	if monitor.Enabled {
+		recordRenditionStage(monitor.RenditionStageDownload, segData.Profiles, time.Since(dlStart))
+	}
 
 	// Send down 200OK early as an indication that the upload completed
 	// Any further errors come through the response body
@@ -179,29 +242,35 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 		Name:  uri,
 	}
 
+	transcoding = true
 	res, err := orch.TranscodeSeg(ctx, segData, &hlsStream)
 
 	// Upload to OS and construct segment result set
 	var segments []*net.TranscodedSegmentData
 	var pixels int64
 	for i := 0; err == nil && i < len(res.TranscodeData.Segments); i++ {
+		profile := segData.Profiles[i]
 		var ext string
-		ext, err = common.ProfileFormatExtension(segData.Profiles[i].Format)
+		ext, err = common.ProfileFormatExtension(profile.Format)
 		if err != nil {
 			clog.Errorf(ctx, "Unknown format extension err=%s", err)
 			break
 		}
-		name := fmt.Sprintf("%s/%d%s", segData.Profiles[i].Name, segData.Seq, ext)
+		name := fmt.Sprintf("%s/%d%s", profile.Name, segData.Seq, ext)
 		// The use of := here is probably a bug?!?
 		segData := bytes.NewReader(res.TranscodeData.Segments[i].Data)
+		uploadStart := time.Now()
 		uri, err := res.OS.SaveData(ctx, name, segData, nil, 0)
 		if err != nil {
 			clog.Errorf(ctx, "Could not upload segment err=%q", err)
 			break
 		}
+		if monitor.Enabled {
+			monitor.RenditionStageTime(monitor.RenditionStageUpload, profile.Name, profile.Resolution, time.Since(uploadStart))
+		}
 		pixels += res.TranscodeData.Segments[i].Pixels
 		d := &net.TranscodedSegmentData{
-			Url:    uri,
+			Url:    storage.SignURL(res.OS, uri),
 			Pixels: res.TranscodeData.Segments[i].Pixels,
 		}
 		// Save perceptual hash if generated
@@ -213,7 +282,7 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 				clog.Errorf(ctx, "Could not upload segment perceptual hash err=%q", err)
 				break
 			}
-			d.PerceptualHashUrl = pHashUri
+			d.PerceptualHashUrl = storage.SignURL(res.OS, pHashUri)
 		}
 		segments = append(segments, d)
 	}
@@ -221,13 +290,15 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 	// Debit the fee for the total pixel count
 	orch.DebitFees(sender, core.ManifestID(segData.AuthToken.SessionId), payment.GetExpectedPrice(), pixels)
 	if monitor.Enabled {
-		monitor.MilPixelsProcessed(ctx, float64(pixels)/1000000.0)
+		monitor.MilPixelsProcessed(ctx, float64(pixels)/1000000.0,
+			monitor.JobType{Codecs: common.ProfilesCodecs(segData.Profiles), Detection: segData.DetectorEnabled})
 	}
 
 	// construct the response
 	var result net.TranscodeResult
 	if err != nil {
 		clog.Errorf(ctx, "Could not transcode err=%q", err)
+		monitor.SetSpanError(ctx, err)
 		result = net.TranscodeResult{Result: &net.TranscodeResult_Error{Error: err.Error()}}
 	} else {
 		result = net.TranscodeResult{Result: &net.TranscodeResult_Data{
@@ -382,6 +453,13 @@ func verifySegCreds(ctx context.Context, orch Orchestrator, segCreds string, bro
 		return nil, ctx, errSegSig
 	}
 
+	// Access is also checked here so that runtime access list changes apply to existing
+	// sessions, including offchain where no payments are processed
+	if err := orch.CheckBroadcasterAccess(broadcaster); err != nil {
+		clog.Errorf(ctx, "Broadcaster access check failed err=%q", err)
+		return nil, ctx, err
+	}
+
 	if !md.Caps.CompatibleWith(orch.Capabilities()) {
 		clog.Errorf(ctx, "Capability check failed")
 		return nil, ctx, errCapCompat
@@ -412,7 +490,7 @@ func verifySegCreds(ctx context.Context, orch Orchestrator, segCreds string, bro
 }
 
 func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSSegment, segPar *core.SegmentParameters,
-	nonce uint64, calcPerceptualHash, verified bool) (*ReceivedTranscodeResult, error) {
+	nonce uint64, calcPerceptualHash, verified bool) (_ *ReceivedTranscodeResult, err error) {
 
 	uploaded := seg.Name != "" // hijack seg.Name to convey the uploaded URI
 	if sess.OrchestratorInfo != nil {
@@ -421,6 +499,9 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 		}
 		ctx = clog.AddVal(ctx, "orchestrator", sess.OrchestratorInfo.Transcoder)
 	}
+	ctx, span := monitor.StartSegmentSpan(ctx, monitor.SpanSubmitSegment, string(sess.Params.ManifestID), seg.SeqNo,
+		attribute.String(monitor.AttrOrchestrator, sess.OrchestratorInfo.GetTranscoder()))
+	defer func() { monitor.EndSpan(span, err) }()
 
 	segCreds, err := genSegCreds(sess, seg, segPar, calcPerceptualHash)
 	if err != nil {
@@ -484,7 +565,7 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 		httpTimeout = time.Duration(params.TimeoutMultiplier) * httpTimeout
 	}
 
-	ctx, cancel := context.WithTimeout(clog.Clone(context.Background(), ctx), httpTimeout)
+	ctx, cancel := context.WithTimeout(clog.Clone(monitor.WithSpan(context.Background(), ctx), ctx), httpTimeout)
 	defer cancel()
 
 	ti := sess.OrchestratorInfo
@@ -500,6 +581,10 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 
 	req.Header.Set(segmentHeader, segCreds)
 	req.Header.Set(paymentHeader, payment)
+	monitor.InjectTraceHeaders(ctx, req.Header)
+	if BroadcasterSecret != "" {
+		req.Header.Set(broadcasterAuthHeader, genBroadcasterAuth(BroadcasterSecret, segmentAuthScope(segCreds), time.Now()))
+	}
 	if uploaded {
 		req.Header.Set("Content-Type", "application/vnd+livepeer.uri")
 	} else {
@@ -528,6 +613,7 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 	if monitor.Enabled {
 		monitor.TicketValueSent(ctx, balUpdate.NewCredit)
 		monitor.TicketsSent(ctx, balUpdate.NumTickets)
+		monitor.OrchestratorTicketValueSent(ti, balUpdate.NewCredit)
 	}
 
 	if resp.StatusCode != 200 {
@@ -542,11 +628,12 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 					fmt.Errorf("Code: %d Error: %s", resp.StatusCode, errorString), false, sess.OrchestratorInfo.Transcoder)
 			}
 		}
-		return nil, fmt.Errorf(errorString)
+		return nil, errOrchRefused{WithRetryAfter(fmt.Errorf(errorString), resp.Header.Get(retryAfterKey))}
 	}
 	clog.Infof(ctx, "Uploaded segment orch=%s dur=%s", ti.Transcoder, uploadDur)
 	if monitor.Enabled {
 		monitor.SegmentUploaded(ctx, nonce, seg.SeqNo, uploadDur, ti.Transcoder)
+		recordRenditionStage(monitor.RenditionStageUpload, params.Profiles, uploadDur)
 	}
 
 	data, err = ioutil.ReadAll(resp.Body)
@@ -589,6 +676,9 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 				monitor.SegmentTranscodeFailed(ctx, monitor.SegmentTranscodeErrorTranscode, nonce, seg.SeqNo, err, false)
 			}
 		}
+		if res.Error == "OrchestratorBusy" || res.Error == "OrchestratorCapped" {
+			return nil, errOrchRefused{err}
+		}
 		return nil, err
 	case *net.TranscodeResult_Data:
 		// fall through here for the normal case
@@ -615,14 +705,15 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 		balUpdate.Debit.Mul(new(big.Rat).SetInt64(pixelCount), priceInfo)
 
 		if monitor.Enabled {
-			monitor.MilPixelsProcessed(ctx, float64(pixelCount)/1000000.0)
+			monitor.MilPixelsProcessed(ctx, float64(pixelCount)/1000000.0, segmentJobType(params, seg.SeqNo))
 		}
 	}
 
 	// transcode succeeded; continue processing response
 	if monitor.Enabled {
 		monitor.SegmentTranscoded(ctx, nonce, seg.SeqNo, time.Duration(seg.Duration*float64(time.Second)), transcodeDur,
-			common.ProfilesNames(params.Profiles), sess.IsTrusted(), verified)
+			common.ProfilesNames(params.Profiles), segmentJobType(params, seg.SeqNo), sess.IsTrusted(), verified)
+		recordRenditionStage(monitor.RenditionStageTranscode, params.Profiles, transcodeDur)
 	}
 
 	clog.Infof(ctx, "Successfully transcoded segment segName=%s seqNo=%d orch=%s dur=%s",
@@ -635,6 +726,23 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 	}, nil
 }
 
+// segmentDetectionEnabled returns whether scene classification runs on the segment with seqNo
+func segmentDetectionEnabled(params *core.StreamParameters, seqNo uint64) bool {
+	return params != nil && params.Detection.Freq != 0 && seqNo%uint64(params.Detection.Freq) == 0
+}
+
+// segmentJobType returns the transcoding job of the segment with seqNo, used to label the transcoding metrics
+func segmentJobType(params *core.StreamParameters, seqNo uint64) monitor.JobType {
+	return monitor.JobType{Codecs: common.ProfilesCodecs(params.Profiles), Detection: segmentDetectionEnabled(params, seqNo)}
+}
+
+// recordRenditionStage records the time of a stage shared by the renditions of a segment for each of them
+func recordRenditionStage(stage string, profiles []ffmpeg.VideoProfile, dur time.Duration) {
+	for _, p := range profiles {
+		monitor.RenditionStageTime(stage, p.Name, p.Resolution, dur)
+	}
+}
+
 func genSegCreds(sess *BroadcastSession, seg *stream.HLSSegment, segPar *core.SegmentParameters, calcPerceptualHash bool) (string, error) {
 
 	// Send credentials for our own storage
@@ -644,13 +752,10 @@ func genSegCreds(sess *BroadcastSession, seg *stream.HLSSegment, segPar *core.Se
 	}
 
 	detectorProfiles := []ffmpeg.DetectorProfile{}
-	detectorEnabled := false
 	if sess.Params.Detection.Freq != 0 {
 		detectorProfiles = sess.Params.Detection.Profiles
-		if seg.SeqNo%uint64(sess.Params.Detection.Freq) == 0 {
-			detectorEnabled = true
-		}
 	}
+	detectorEnabled := segmentDetectionEnabled(sess.Params, seg.SeqNo)
 
 	// Generate signature for relevant parts of segment
 	params := sess.Params
@@ -792,6 +897,10 @@ func genPayment(ctx context.Context, sess *BroadcastSession, numTickets int) (st
 		return "", err
 	}
 
+	if err := SpendLimits.Check(ctx, sess.Params.ManifestID); err != nil {
+		return "", err
+	}
+
 	protoPayment := &net.Payment{
 		Sender:        sess.Broadcaster.Address().Bytes(),
 		ExpectedPrice: sess.OrchestratorInfo.PriceInfo,
@@ -827,6 +936,16 @@ func genPayment(ctx context.Context, sess *BroadcastSession, numTickets int) (st
 
 		protoPayment.TicketSenderParams = senderParams
 
+		if SpendLimits != nil || Spending != nil {
+			ev, err := sess.Sender.EV(sess.PMSessionID)
+			if err != nil {
+				return "", err
+			}
+			totalEV := new(big.Rat).Mul(ev, big.NewRat(int64(numTickets), 1))
+			SpendLimits.Record(sess.Params.ManifestID, totalEV)
+			Spending.Record(batch.Recipient, sess.Params.ManifestID, numTickets, totalEV)
+		}
+
 		ratPrice, _ := common.RatPriceInfo(protoPayment.ExpectedPrice)
 		clog.V(common.VERBOSE).Infof(ctx, "Created new payment - manifestID=%v sessionID=%v recipient=%v faceValue=%v winProb=%v price=%v numTickets=%v",
 			sess.Params.ManifestID,
@@ -856,7 +975,11 @@ func validatePrice(sess *BroadcastSession) error {
 		return errors.New("missing orchestrator price")
 	}
 
-	maxPrice := BroadcastCfg.MaxPrice()
+	var caps common.CapabilityComparator
+	if sess.Params != nil {
+		caps = sess.Params.Capabilities
+	}
+	maxPrice := BroadcastCfg.MaxPriceForCapabilities(caps)
 	if maxPrice != nil && oPrice.Cmp(maxPrice) == 1 {
 		return fmt.Errorf("Orchestrator price higher than the set maximum price of %v wei per %v pixels", maxPrice.Num().Int64(), maxPrice.Denom().Int64())
 	}
@@ -874,8 +997,22 @@ func sendReqWithTimeout(req *http.Request, timeout time.Duration) (*http.Respons
 	}
 	// timeout has already fired and cancelled the request
 	if err != nil {
-		return nil, err
+		return nil, errReqTimeout{err}
 	}
 	resp.Body.Close()
 	return nil, context.DeadlineExceeded
 }
+
+// errReqTimeout wraps the error of a request cancelled by sendReqWithTimeout, so that it is a timeout rather than a
+// cancellation of the request context
+type errReqTimeout struct {
+	error
+}
+
+func (e errReqTimeout) Unwrap() error {
+	return e.error
+}
+
+func (e errReqTimeout) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/big"
 	"net/http"
@@ -80,6 +81,42 @@ func TestServeSegment_VerifySegCredsError(t *testing.T) {
 	assert.Equal(errSegEncoding.Error(), strings.TrimSpace(string(body)))
 }
 
+func TestServeSegment_OrchCapError_SetsRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+	orch := &stubOrchestrator{offchain: true, sessCapErr: core.ErrOrchCap}
+	handler := serveSegmentHandler(orch)
+
+	data, err := proto.Marshal(&net.SegData{AuthToken: stubAuthToken})
+	require.Nil(t, err)
+	headers := map[string]string{
+		paymentHeader: "",
+		segmentHeader: base64.StdEncoding.EncodeToString(data),
+	}
+
+	defer func(d time.Duration) { core.SessionRetryAfter = d }(core.SessionRetryAfter)
+	core.SessionRetryAfter = 3 * time.Second
+
+	resp := httpPostResp(handler, nil, headers)
+	defer resp.Body.Close()
+	assert.Equal(http.StatusForbidden, resp.StatusCode)
+	assert.Equal("3", resp.Header.Get(retryAfterKey))
+
+	// no hint for other errors
+	orch.sessCapErr = errors.New("some error")
+	resp = httpPostResp(handler, nil, headers)
+	defer resp.Body.Close()
+	assert.Equal(http.StatusForbidden, resp.StatusCode)
+	assert.Empty(resp.Header.Get(retryAfterKey))
+
+	// nor when the hint is disabled
+	orch.sessCapErr = core.ErrOrchCap
+	core.SessionRetryAfter = 0
+	resp = httpPostResp(handler, nil, headers)
+	defer resp.Body.Close()
+	assert.Equal(http.StatusForbidden, resp.StatusCode)
+	assert.Empty(resp.Header.Get(retryAfterKey))
+}
+
 func TestServeSegment_MismatchHashError(t *testing.T) {
 	orch := &mockOrchestrator{}
 	handler := serveSegmentHandler(orch)
@@ -220,6 +257,23 @@ func TestVerifySegCreds_Duration(t *testing.T) {
 	assert.Nil(md)
 }
 
+func TestVerifySegCreds_BroadcasterAccess(t *testing.T) {
+	assert := assert.New(t)
+	orch := &stubOrchestrator{offchain: true}
+	data, err := proto.Marshal(&net.SegData{AuthToken: stubAuthToken})
+	assert.Nil(err)
+	creds := base64.StdEncoding.EncodeToString(data)
+
+	_, _, err = verifySegCreds(context.TODO(), orch, creds, ethcommon.Address{})
+	assert.Nil(err)
+
+	// access changes apply to existing sessions even when no payments are processed
+	orch.accessErr = core.ErrBroadcasterNotAllowed
+	md, _, err := verifySegCreds(context.TODO(), orch, creds, ethcommon.Address{})
+	assert.Equal(core.ErrBroadcasterNotAllowed, err)
+	assert.Nil(md)
+}
+
 func TestCoreSegMetadata_Profiles(t *testing.T) {
 	assert := assert.New(t)
 	// testing with the following profiles doesn't work: ffmpeg.P720p60fps16x9, ffmpeg.P144p25fps16x9
@@ -865,6 +919,8 @@ func TestServeSegment_ReturnSingleTranscodedSegmentData(t *testing.T) {
 	assert.True(ok)
 	assert.Equal([]byte("foo"), res.Data.Sig)
 	assert.Equal(1, len(res.Data.Segments))
+	// the slot is handed to the transcode loop
+	assert.Empty(orch.released)
 }
 
 func TestServeSegment_ReturnMultipleTranscodedSegmentData(t *testing.T) {
@@ -990,7 +1046,77 @@ func TestServeSegment_TooBigSegment(t *testing.T) {
 	defer resp.Body.Close()
 
 	assert := assert.New(t)
-	assert.Equal(http.StatusInternalServerError, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(err)
+	assert.Equal(http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal("SegmentTooBig max=1", strings.TrimSpace(string(body)))
+	orch.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestServeSegment_SegmentLimits(t *testing.T) {
+	orch := &mockOrchestrator{}
+	handler := serveSegmentHandler(orch)
+
+	require := require.New(t)
+	assert := assert.New(t)
+
+	orch.On("VerifySig", mock.Anything, mock.Anything, mock.Anything).Return(true)
+	orch.On("AuthToken", mock.Anything, mock.Anything).Return(stubAuthToken)
+
+	s := &BroadcastSession{
+		Broadcaster: stubBroadcaster2(),
+		Params: &core.StreamParameters{
+			ManifestID: core.RandomManifestID(),
+			Profiles:   []ffmpeg.VideoProfile{ffmpeg.P720p60fps16x9},
+		},
+		OrchestratorInfo: &net.OrchestratorInfo{AuthToken: stubAuthToken},
+	}
+	seg := &stream.HLSSegment{Data: []byte("foo"), Duration: 2}
+	creds, err := genSegCreds(s, seg, nil, false)
+	require.Nil(err)
+	headers := map[string]string{
+		paymentHeader: "",
+		segmentHeader: creds,
+	}
+
+	defer func() {
+		MaxSegmentDuration = 0
+		MaxSegmentSize = 0
+	}()
+
+	// segment longer than max duration
+	MaxSegmentDuration = time.Second
+	resp := httpPostResp(handler, bytes.NewReader(seg.Data), headers)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(err)
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+	assert.Equal("SegmentTooLong duration=2s max=1s", strings.TrimSpace(string(body)))
+	orch.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything, mock.Anything)
+
+	// segment bigger than max size
+	MaxSegmentDuration = 0
+	MaxSegmentSize = 2
+	resp = httpPostResp(handler, bytes.NewReader(seg.Data), headers)
+	defer resp.Body.Close()
+	body, err = ioutil.ReadAll(resp.Body)
+	require.Nil(err)
+	assert.Equal(http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal("SegmentTooBig size=3 max=2", strings.TrimSpace(string(body)))
+	orch.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything, mock.Anything)
+
+	// chunked segment bigger than max size, i.e. without a content length
+	resp = httpPostResp(handler, io.MultiReader(bytes.NewReader(seg.Data)), headers)
+	defer resp.Body.Close()
+	body, err = ioutil.ReadAll(resp.Body)
+	require.Nil(err)
+	assert.Equal(http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal("SegmentTooBig max=2", strings.TrimSpace(string(body)))
+	orch.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything, mock.Anything)
+
+	// rejected segments release the slot reserved for the session
+	mid := core.ManifestID(stubAuthToken.SessionId)
+	assert.Equal([]core.ManifestID{mid, mid, mid}, orch.released)
 }
 
 func TestServeSegment_ProcessPaymentError(t *testing.T) {
@@ -1045,6 +1171,10 @@ func TestServeSegment_ProcessPaymentError(t *testing.T) {
 
 	assert.Equal(http.StatusBadRequest, resp.StatusCode)
 	assert.Equal("some error", strings.TrimSpace(string(body)))
+
+	// sessions whose payments fail don't hold a slot
+	mid := core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId)
+	assert.Equal([]core.ManifestID{mid, mid}, orch.released)
 }
 
 func TestServeSegment_UpdateOrchestratorInfo(t *testing.T) {
@@ -1865,6 +1995,34 @@ func TestSubmitSegment_Non200StatusCode(t *testing.T) {
 	balance.AssertNotCalled(t, "Credit", mock.Anything)
 }
 
+func TestSubmitSegment_RetryAfter(t *testing.T) {
+	ts, mux := stubTLSServer()
+	defer ts.Close()
+	mux.HandleFunc("/segment", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(retryAfterKey, "7")
+		http.Error(w, core.ErrOrchCap.Error(), http.StatusForbidden)
+	})
+
+	s := &BroadcastSession{
+		Broadcaster: stubBroadcaster2(),
+		Params:      &core.StreamParameters{ManifestID: core.RandomManifestID()},
+		OrchestratorInfo: &net.OrchestratorInfo{
+			Transcoder: ts.URL,
+			PriceInfo: &net.PriceInfo{
+				PricePerUnit:  1,
+				PixelsPerUnit: 1,
+			},
+			AuthToken: stubAuthToken,
+		},
+	}
+
+	_, err := SubmitSegment(context.TODO(), s, &stream.HLSSegment{}, nil, 0, false, true)
+
+	assert.Equal(t, core.ErrOrchCap.Error(), err.Error())
+	assert.Equal(t, failureRefusal, classifyFailure(err))
+	assert.Equal(t, 7*time.Second, RetryAfter(err))
+}
+
 func TestSubmitSegment_ProtoUnmarshalError(t *testing.T) {
 	ts, mux := stubTLSServer()
 	defer ts.Close()
@@ -2296,6 +2454,7 @@ func TestSendReqWithTimeout(t *testing.T) {
 	wg.Done()
 	assert.Nil(resp)
 	assert.ErrorIs(err, context.Canceled)
+	assert.ErrorIs(err, context.DeadlineExceeded)
 }
 
 func stubTLSServer() (*httptest.Server, *http.ServeMux) {
@@ -4,6 +4,7 @@ import (
 	"container/heap"
 	"context"
 	"math/rand"
+	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/livepeer/go-livepeer/clog"
@@ -21,7 +22,7 @@ type BroadcastSessionsSelector interface {
 	Clear()
 }
 
-type BroadcastSessionsSelectorFactory func() BroadcastSessionsSelector
+type BroadcastSessionsSelectorFactory func(randFreq float64) BroadcastSessionsSelector
 
 type sessHeap []*BroadcastSession
 
@@ -97,6 +98,8 @@ type MinLSSelector struct {
 	knownSessions   *sessHeap
 
 	stakeRdr stakeReader
+	// selectionWeights are used to favor orchestrators when selecting unknown sessions
+	selectionWeights
 
 	minLS float64
 	// Frequency to randomly select unknown sessions
@@ -165,10 +168,24 @@ func (s *MinLSSelector) selectUnknownSession(ctx context.Context) *BroadcastSess
 		return nil
 	}
 
+	if i := preferredSessionIndex(s.unknownSessions); i >= 0 {
+		sess := s.unknownSessions[i]
+		s.unknownSessions = append(s.unknownSessions[:i:i], s.unknownSessions[i+1:]...)
+		return sess
+	}
+
 	if s.stakeRdr == nil {
 		// Sessions are selected based on the order of unknownSessions in off-chain mode
-		sess := s.unknownSessions[0]
-		s.unknownSessions = s.unknownSessions[1:]
+		// unless orchestrator stats are recorded or regions are preferred, in which case the highest weighted session is selected,
+		// or latency probing is enabled, in which case the lowest latency session is selected
+		i := 0
+		if s.stats != nil || s.regions != nil || s.reputation != nil {
+			i = s.highestWeightSession()
+		} else if s.latencies != nil {
+			i = s.lowestLatencySession()
+		}
+		sess := s.unknownSessions[i]
+		s.unknownSessions = append(s.unknownSessions[:i:i], s.unknownSessions[i+1:]...)
 		return sess
 	}
 
@@ -180,9 +197,66 @@ func (s *MinLSSelector) selectUnknownSession(ctx context.Context) *BroadcastSess
 		return sess
 	}
 
+	i, err := stakeWeightedIndex(s.unknownSessions, s.stakeRdr, s.weightFunc())
+	// If we fail to read stake weights of unknownSessions we should not continue with selection
+	if err != nil {
+		clog.Errorf(ctx, "failed to read stake weights for selection err=%q", err)
+		return nil
+	}
+	if i < 0 {
+		return nil
+	}
+
+	sess := s.unknownSessions[i]
+	s.removeUnknownSession(i)
+	return sess
+}
+
+// selectionWeights scale the stake weights of orchestrators during selection
+type selectionWeights struct {
+	// latencies, if set, is used to favor low latency orchestrators
+	latencies *OrchestratorLatencies
+	// stats, if set, is used to favor orchestrators that performed well
+	stats *OrchestratorStats
+	// regions, if set, is used to favor orchestrators in the preferred regions
+	regions *RegionSelection
+	// reputation, if set, is used instead of stats to favor orchestrators with a good reputation
+	reputation OrchReputation
+}
+
+// weightFunc returns the function scaling stake weights, nil if no weight is considered
+func (s *selectionWeights) weightFunc() func(sess *BroadcastSession) float64 {
+	if s.latencies == nil && s.stats == nil && s.regions == nil && s.reputation == nil {
+		return nil
+	}
+	return s.selectionWeight
+}
+
+// selectionWeight returns the factor by which the stake weight of the orchestrator of sess is scaled,
+// 0 to 1 depending on its probed latency, past performance or reputation and region, or 1 if none of them is considered
+func (s *selectionWeights) selectionWeight(sess *BroadcastSession) float64 {
+	uri := sess.OrchestratorInfo.GetTranscoder()
+	weight := 1.0
+	if s.latencies != nil {
+		weight *= s.latencies.selectionWeight(uri)
+	}
+	if s.reputation != nil {
+		weight *= s.reputation.Score(sess.OrchestratorInfo)
+	} else if s.stats != nil {
+		weight *= s.stats.selectionWeight(uri)
+	}
+	if s.regions != nil {
+		weight *= s.regions.selectionWeight(sess.OrchestratorInfo.GetRegion())
+	}
+	return weight
+}
+
+// stakeWeightedIndex runs a stake weighted random selection on sessions and returns the index of the selected session.
+// If weight is set, stake weights are scaled by the weight of each orchestrator. -1 is returned if no session is selected.
+func stakeWeightedIndex(sessions []*BroadcastSession, stakeRdr stakeReader, weight func(sess *BroadcastSession) float64) (int, error) {
 	var addrs []ethcommon.Address
 	addrCount := make(map[ethcommon.Address]int)
-	for _, sess := range s.unknownSessions {
+	for _, sess := range sessions {
 		if sess.OrchestratorInfo.GetTicketParams() == nil {
 			continue
 		}
@@ -194,12 +268,25 @@ func (s *MinLSSelector) selectUnknownSession(ctx context.Context) *BroadcastSess
 	}
 
 	// Fetch stake weights for all addresses
-	// We handle the possibility of missing stake weights for addresses when we run weighted random selection on unknownSessions
-	stakes, err := s.stakeRdr.Stakes(addrs)
-	// If we fail to read stake weights of unknownSessions we should not continue with selection
+	// We handle the possibility of missing stake weights for addresses when we run weighted random selection on sessions
+	stakes, err := stakeRdr.Stakes(addrs)
 	if err != nil {
-		clog.Errorf(ctx, "failed to read stake weights for selection err=%q", err)
-		return nil
+		return -1, err
+	}
+
+	if weight != nil {
+		// Scale stake weights down for orchestrators with a high latency, that performed poorly or outside of the preferred regions
+		scaled := make(map[ethcommon.Address]bool)
+		for _, sess := range sessions {
+			if sess.OrchestratorInfo.GetTicketParams() == nil {
+				continue
+			}
+			addr := ethcommon.BytesToAddress(sess.OrchestratorInfo.TicketParams.Recipient)
+			if stake, ok := stakes[addr]; ok && !scaled[addr] {
+				stakes[addr] = int64(float64(stake) * weight(sess))
+				scaled[addr] = true
+			}
+		}
 	}
 
 	totalStake := int64(0)
@@ -213,12 +300,12 @@ func (s *MinLSSelector) selectUnknownSession(ctx context.Context) *BroadcastSess
 		r = 1 + rand.Int63n(totalStake)
 	}
 
-	// Run a weighted random selection on unknownSessions
+	// Run a weighted random selection on sessions
 	// We iterate through each session and subtract the stake weight for the session's orchestrator from r (initialized to a random stake weight)
 	// If subtracting the stake weight for the current session from r results in a value <= 0, we select the current session
 	// The greater the stake weight of a session, the more likely that it will be selected because subtracting its stake weight from r
 	// will result in a value <= 0
-	for i, sess := range s.unknownSessions {
+	for i, sess := range sessions {
 		if sess.OrchestratorInfo.GetTicketParams() == nil {
 			continue
 		}
@@ -230,12 +317,50 @@ func (s *MinLSSelector) selectUnknownSession(ctx context.Context) *BroadcastSess
 		stakes[addr] = 0
 
 		if r <= 0 {
-			s.removeUnknownSession(i)
-			return sess
+			return i, nil
+		}
+	}
+
+	return -1, nil
+}
+
+// lowestLatencySession returns the index of the unknown session with the lowest probed latency.
+// Sessions that have not been probed are only selected if no session has been probed, in their original order.
+func (s *MinLSSelector) lowestLatencySession() int {
+	minIdx := 0
+	var minRTT time.Duration
+	found := false
+	for i, sess := range s.unknownSessions {
+		rtt, ok := s.latencies.Latency(sess.OrchestratorInfo.GetTranscoder())
+		if ok && (!found || rtt < minRTT) {
+			minIdx, minRTT, found = i, rtt, true
+		}
+	}
+	return minIdx
+}
+
+// highestWeightSession returns the index of the unknown session with the highest selection weight.
+// Sessions with the same weight are selected in their original order.
+func (s *MinLSSelector) highestWeightSession() int {
+	maxIdx := 0
+	maxWeight := -1.0
+	for i, sess := range s.unknownSessions {
+		if w := s.selectionWeight(sess); w > maxWeight {
+			maxIdx, maxWeight = i, w
 		}
 	}
+	return maxIdx
+}
 
-	return nil
+// preferredSessionIndex returns the index of the most preferred session, -1 if there is none
+func preferredSessionIndex(sessions []*BroadcastSession) int {
+	idx := -1
+	for i, sess := range sessions {
+		if sess.PreferenceRank > 0 && (idx < 0 || sess.PreferenceRank < sessions[idx].PreferenceRank) {
+			idx = i
+		}
+	}
+	return idx
 }
 
 func (s *MinLSSelector) removeUnknownSession(i int) {
@@ -244,6 +369,85 @@ func (s *MinLSSelector) removeUnknownSession(i int) {
 	s.unknownSessions = s.unknownSessions[:n-1]
 }
 
+// StakeWeightedSelector selects the next BroadcastSession randomly, with a probability proportional to the stake
+// of its orchestrator scaled by its selection weights, whether or not the session has been used before.
+// In off-chain mode sessions are selected in the order they were added.
+// StakeWeightedSelector is not concurrency safe so the caller is responsible for ensuring safety for concurrent method calls
+type StakeWeightedSelector struct {
+	sessions []*BroadcastSession
+	stakeRdr stakeReader
+	selectionWeights
+}
+
+// NewStakeWeightedSelector returns an instance of StakeWeightedSelector
+func NewStakeWeightedSelector(stakeRdr stakeReader) *StakeWeightedSelector {
+	return &StakeWeightedSelector{stakeRdr: stakeRdr}
+}
+
+// Add adds the sessions to the selector's list of sessions
+func (s *StakeWeightedSelector) Add(sessions []*BroadcastSession) {
+	s.sessions = append(s.sessions, sessions...)
+}
+
+// Complete returns the session to the selector's list of sessions
+func (s *StakeWeightedSelector) Complete(sess *BroadcastSession) {
+	s.sessions = append(s.sessions, sess)
+}
+
+// Select returns a session using stake weighted random selection
+func (s *StakeWeightedSelector) Select(ctx context.Context) *BroadcastSession {
+	if len(s.sessions) == 0 {
+		return nil
+	}
+
+	i := preferredSessionIndex(s.sessions)
+	if i < 0 {
+		i = 0
+		if s.stakeRdr != nil {
+			var err error
+			i, err = stakeWeightedIndex(s.sessions, s.stakeRdr, s.weightFunc())
+			if err != nil {
+				clog.Errorf(ctx, "failed to read stake weights for selection err=%q", err)
+				return nil
+			}
+			if i < 0 {
+				return nil
+			}
+		}
+	}
+
+	sess := s.sessions[i]
+	s.sessions = append(s.sessions[:i:i], s.sessions[i+1:]...)
+	return sess
+}
+
+// Size returns the number of sessions stored by the selector
+func (s *StakeWeightedSelector) Size() int {
+	return len(s.sessions)
+}
+
+// Clear resets the selector's state
+func (s *StakeWeightedSelector) Clear() {
+	s.sessions = nil
+	s.stakeRdr = nil
+}
+
+// newSessionsSelector returns the selector for the configured selection strategy.
+// latencies, stats, regions and reputation, if set, are used to favor low latency, well performing and
+// reputable orchestrators in the preferred regions.
+func newSessionsSelector(stakeRdr stakeReader, randFreq float64, latencies *OrchestratorLatencies, stats *OrchestratorStats,
+	regions *RegionSelection, reputation OrchReputation) BroadcastSessionsSelector {
+	weights := selectionWeights{latencies: latencies, stats: stats, regions: regions, reputation: reputation}
+	if StakeWeightedSelection {
+		sel := NewStakeWeightedSelector(stakeRdr)
+		sel.selectionWeights = weights
+		return sel
+	}
+	sel := NewMinLSSelectorWithRandFreq(stakeRdr, SELECTOR_LATENCY_SCORE_THRESHOLD, randFreq)
+	sel.selectionWeights = weights
+	return sel
+}
+
 // LIFOSelector selects the next BroadcastSession in LIFO order
 // now used only in tests
 type LIFOSelector []*BroadcastSession
@@ -516,6 +516,77 @@ func TestMinLSSelector_SelectUnknownSession_RandFreq(t *testing.T) {
 	assert.Equal(sess.OrchestratorInfo.TicketParams.Recipient, topAddr.Bytes())
 }
 
+func TestStakeWeightedSelector(t *testing.T) {
+	assert := assert.New(t)
+
+	newSession := func(addr ethcommon.Address, latencyScore float64) *BroadcastSession {
+		return &BroadcastSession{
+			OrchestratorInfo: &net.OrchestratorInfo{TicketParams: &net.TicketParams{Recipient: addr.Bytes()}},
+			LatencyScore:     latencyScore,
+		}
+	}
+	high := newSession(ethcommon.Address{1}, 10.0)
+	low := newSession(ethcommon.Address{2}, 0.1)
+
+	stakeRdr := newStubStakeReader()
+	stakeRdr.SetStakes(map[ethcommon.Address]int64{{1}: 1000000, {2}: 1})
+	sel := NewStakeWeightedSelector(stakeRdr)
+	assert.Nil(sel.Select(context.TODO()))
+
+	// the session with the most stake is selected even after it has been used
+	// and the other session has a better latency score
+	sel.Add([]*BroadcastSession{low, high})
+	assert.Equal(2, sel.Size())
+	for i := 0; i < 10; i++ {
+		sess := sel.Select(context.TODO())
+		assert.Same(high, sess)
+		assert.Equal(1, sel.Size())
+		sel.Complete(sess)
+	}
+
+	// stake weights are scaled by the selection weights
+	other := &BroadcastSession{OrchestratorInfo: &net.OrchestratorInfo{TicketParams: &net.TicketParams{Recipient: ethcommon.Address{3}.Bytes()}, Region: "ap-south"}}
+	preferred := &BroadcastSession{OrchestratorInfo: &net.OrchestratorInfo{TicketParams: &net.TicketParams{Recipient: ethcommon.Address{4}.Bytes()}, Region: "us-east"}}
+	stakeRdr.SetStakes(map[ethcommon.Address]int64{{3}: 9, {4}: 1})
+	weightedSel := NewStakeWeightedSelector(stakeRdr)
+	weightedSel.regions = NewRegionSelection([]string{"us-east"}, false)
+	weightedSel.Add([]*BroadcastSession{other, preferred})
+	for i := 0; i < 10; i++ {
+		sess := weightedSel.Select(context.TODO())
+		assert.Same(preferred, sess)
+		weightedSel.Complete(sess)
+	}
+	stakeRdr.SetStakes(map[ethcommon.Address]int64{{1}: 1000000, {2}: 1})
+
+	// nothing is selected if stake weights can't be read
+	stakeRdr.err = errors.New("Stakes error")
+	assert.Nil(sel.Select(context.TODO()))
+	assert.Equal(2, sel.Size())
+
+	// off-chain, sessions are selected in order
+	sel = NewStakeWeightedSelector(nil)
+	sel.Add([]*BroadcastSession{low, high})
+	assert.Same(low, sel.Select(context.TODO()))
+	assert.Same(high, sel.Select(context.TODO()))
+	assert.Nil(sel.Select(context.TODO()))
+
+	sel.Add([]*BroadcastSession{low})
+	sel.Clear()
+	assert.Zero(sel.Size())
+
+	// the selection strategy is configurable
+	defer func() { StakeWeightedSelection = false }()
+	latencies := NewOrchestratorLatencies()
+	minLSSel, ok := newSessionsSelector(stakeRdr, 0.5, latencies, nil, nil, nil).(*MinLSSelector)
+	assert.True(ok)
+	assert.Equal(0.5, minLSSel.randFreq)
+	assert.Same(latencies, minLSSel.latencies)
+	StakeWeightedSelection = true
+	stakeSel, ok := newSessionsSelector(stakeRdr, 0, latencies, nil, nil, nil).(*StakeWeightedSelector)
+	assert.True(ok)
+	assert.Same(latencies, stakeSel.latencies)
+}
+
 func TestMinLSSelector_RemoveUnknownSession(t *testing.T) {
 	assert := assert.New(t)
 
@@ -560,3 +631,28 @@ func TestMinLSSelector_RemoveUnknownSession(t *testing.T) {
 	sel.removeUnknownSession(0)
 	assert.Empty(sel.unknownSessions)
 }
+
+func TestSelectors_PreferredSessions(t *testing.T) {
+	assert := assert.New(t)
+
+	stakeRdr := newStubStakeReader()
+	stakeRdr.SetStakes(map[ethcommon.Address]int64{{1}: 1000, {2}: 1, {3}: 1})
+	newSession := func(addr ethcommon.Address, rank int) *BroadcastSession {
+		return &BroadcastSession{
+			PreferenceRank:   rank,
+			OrchestratorInfo: &net.OrchestratorInfo{TicketParams: &net.TicketParams{Recipient: addr.Bytes()}},
+		}
+	}
+
+	for _, sel := range []BroadcastSessionsSelector{NewMinLSSelector(stakeRdr, 1.0), NewStakeWeightedSelector(stakeRdr)} {
+		other := newSession(ethcommon.Address{1}, 0)
+		second := newSession(ethcommon.Address{2}, 2)
+		first := newSession(ethcommon.Address{3}, 1)
+		sel.Add([]*BroadcastSession{other, second, first})
+
+		// preferred sessions are selected in order of preference before the others
+		assert.Same(first, sel.Select(context.TODO()))
+		assert.Same(second, sel.Select(context.TODO()))
+		assert.Same(other, sel.Select(context.TODO()))
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/net"
+)
+
+// sessionEventsBuffer is the number of events buffered per subscriber, the events of a subscriber falling further
+// behind are dropped
+const sessionEventsBuffer = 256
+
+// sessionEvents publishes the events of the broadcast sessions to the WatchSessions subscribers of the admin API
+var sessionEvents = newSessionEventHub()
+
+type sessionEventHub struct {
+	mu   sync.RWMutex
+	subs map[chan *net.SessionEvent]struct{}
+}
+
+func newSessionEventHub() *sessionEventHub {
+	return &sessionEventHub{subs: make(map[chan *net.SessionEvent]struct{})}
+}
+
+// subscribe returns the channel of the events published until the returned func is called
+func (h *sessionEventHub) subscribe() (<-chan *net.SessionEvent, func()) {
+	ch := make(chan *net.SessionEvent, sessionEventsBuffer)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs, ch)
+		h.mu.Unlock()
+	}
+}
+
+func (h *sessionEventHub) publish(evt *net.SessionEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subs {
+		select {
+		case ch <- evt:
+		default:
+			glog.Warningf("Dropping session event of slow subscriber type=%s manifestID=%s", evt.Type, evt.ManifestId)
+		}
+	}
+}
+
+// active returns whether there are subscribers, so that the events are only built for them
+func (h *sessionEventHub) active() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs) > 0
+}
+
+func publishSessionEvent(typ net.SessionEvent_Type, mid core.ManifestID, orch string) {
+	if !sessionEvents.active() {
+		return
+	}
+	sessionEvents.publish(&net.SessionEvent{
+		Type:         typ,
+		ManifestId:   string(mid),
+		Orchestrator: orch,
+		Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
+	})
+}
+
+func publishSegmentEvent(mid core.ManifestID, orch string, seqNo uint64, latency time.Duration, success bool, err error) {
+	if !sessionEvents.active() {
+		return
+	}
+	evt := &net.SessionEvent{
+		Type:         net.SessionEvent_SEGMENT_TRANSCODED,
+		ManifestId:   string(mid),
+		Orchestrator: orch,
+		Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
+		SeqNo:        seqNo,
+		LatencyMs:    latency.Milliseconds(),
+	}
+	if !success {
+		evt.Type = net.SessionEvent_SEGMENT_FAILED
+	}
+	if err != nil {
+		evt.Error = err.Error()
+	}
+	sessionEvents.publish(evt)
+}
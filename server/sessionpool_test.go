@@ -280,6 +280,62 @@ func TestRefreshSessions(t *testing.T) {
 	assert.True(wgWait(&wg), "Session refresh timed out")
 }
 
+func TestRotateSessions(t *testing.T) {
+	assert := assert.New(t)
+
+	pool := stubPool()
+	sess1 := pool.sessList()[1]
+	sess3 := StubBroadcastSession("transcoder3")
+	pool.createSessions = func() ([]*BroadcastSession, error) {
+		return []*BroadcastSession{sess3, StubBroadcastSession("transcoder1")}, nil
+	}
+
+	// existing sessions are replaced, including those of orchestrators that were selected again
+	sessions := pool.selectSessions(context.TODO(), 1)
+	assert.Len(sessions, 1)
+	assert.NotNil(pool.lastSess)
+	pool.rotateSessions(context.TODO())
+	assert.Len(pool.sessList(), 2)
+	assert.Len(pool.sessMap, 2)
+	assert.Equal(sess3, pool.sessMap["transcoder3"])
+	assert.NotEqual(sess1, pool.sessMap["transcoder1"])
+	assert.NotContains(pool.sessMap, "transcoder2")
+	assert.Nil(pool.lastSess)
+
+	// the replaced session is not returned to the selector once its segment completes
+	pool.completeSession(sessions[0])
+	assert.Len(pool.sessList(), 2)
+
+	// existing sessions are kept if no new sessions are selected
+	pool.createSessions = func() ([]*BroadcastSession, error) {
+		return nil, nil
+	}
+	pool.rotateSessions(context.TODO())
+	assert.Len(pool.sessMap, 2)
+
+	// rotation is triggered by selection once the interval elapsed
+	oldInterval := SessionRotationInterval
+	defer func() { SessionRotationInterval = oldInterval }()
+	SessionRotationInterval = time.Hour
+	rotated := make(chan struct{}, 1)
+	pool.createSessions = func() ([]*BroadcastSession, error) {
+		rotated <- struct{}{}
+		return nil, nil
+	}
+	pool.selectSessions(context.TODO(), 1)
+	assert.Len(rotated, 0)
+
+	pool.lock.Lock()
+	pool.lastRotation = time.Now().Add(-time.Hour)
+	pool.lock.Unlock()
+	pool.selectSessions(context.TODO(), 1)
+	select {
+	case <-rotated:
+	case <-time.After(time.Second):
+		assert.Fail("sessions were not rotated")
+	}
+}
+
 func TestCleanupSessions(t *testing.T) {
 	pool := stubPool()
 
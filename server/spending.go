@@ -0,0 +1,245 @@
+package server
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/eth/contracts"
+)
+
+// spendingFlushInterval is how often the spending accumulated in memory is written to the DB
+var spendingFlushInterval = 1 * time.Minute
+
+// Spending, if set, records the tickets sent by the broadcaster per orchestrator and stream
+var Spending *SpendingRecorder
+
+type spendingKey struct {
+	recipient  ethcommon.Address
+	manifestID string
+	hour       time.Time
+}
+
+type winningTicketWatcher interface {
+	SubscribeWinningTicketTransfers(sink chan<- *contracts.TicketBrokerWinningTicketTransfer) event.Subscription
+}
+
+// SpendingRecorder tracks the expected value of the tickets sent to orchestrators and the face value of the
+// winning tickets they redeemed, per recipient, stream and hour. The spending is accumulated in memory and
+// periodically written to the DB.
+type SpendingRecorder struct {
+	writes *common.BatchWriter
+}
+
+// NewSpendingRecorder creates a SpendingRecorder that writes to db
+func NewSpendingRecorder(db *common.DB) *SpendingRecorder {
+	merge := func(total, delta interface{}) interface{} {
+		spending := delta.(*common.DBSpending)
+		if total == nil {
+			total = &common.DBSpending{Recipient: spending.Recipient, ManifestID: spending.ManifestID, Hour: spending.Hour}
+		}
+		total.(*common.DBSpending).Add(spending)
+		return total
+	}
+	write := func(record interface{}) error {
+		spending := record.(*common.DBSpending)
+		if err := db.AddSpending(spending); err != nil {
+			glog.Errorf("Error recording spending recipient=%v manifestID=%v err=%q", spending.Recipient.Hex(), spending.ManifestID, err)
+			return err
+		}
+		return nil
+	}
+	return &SpendingRecorder{writes: common.NewBatchWriter(merge, write)}
+}
+
+// Record records numTickets tickets with a total expected value of ev sent to recipient for the stream manifestID
+func (s *SpendingRecorder) Record(recipient ethcommon.Address, manifestID core.ManifestID, numTickets int, ev *big.Rat) {
+	if s == nil {
+		return
+	}
+	s.add(&common.DBSpending{
+		Recipient:  recipient,
+		ManifestID: string(manifestID),
+		Hour:       time.Now(),
+		Tickets:    int64(numTickets),
+		EV:         ev,
+	})
+}
+
+// RecordWinningTicket records a winning ticket with a face value of faceValue redeemed by recipient. The stream
+// a winning ticket was sent for is not known from its redemption, so it is recorded without a stream.
+func (s *SpendingRecorder) RecordWinningTicket(recipient ethcommon.Address, faceValue *big.Int) {
+	if s == nil {
+		return
+	}
+	s.add(&common.DBSpending{
+		Recipient:        recipient,
+		Hour:             time.Now(),
+		WinningTickets:   1,
+		WinningFaceValue: faceValue,
+	})
+}
+
+func (s *SpendingRecorder) add(delta *common.DBSpending) {
+	delta.Hour = delta.Hour.UTC().Truncate(time.Hour)
+	s.writes.Add(spendingKey{recipient: delta.Recipient, manifestID: delta.ManifestID, hour: delta.Hour}, delta)
+}
+
+// WatchWinningTickets records the winning tickets of sender redeemed on-chain until StopFlush is called
+func (s *SpendingRecorder) WatchWinningTickets(sender ethcommon.Address, watcher winningTicketWatcher) {
+	sink := make(chan *contracts.TicketBrokerWinningTicketTransfer, 10)
+	sub := watcher.SubscribeWinningTicketTransfers(sink)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case transfer := <-sink:
+			if transfer.Sender == sender {
+				s.RecordWinningTicket(transfer.Recipient, transfer.Amount)
+			}
+		case err := <-sub.Err():
+			if err != nil {
+				glog.Errorf("Winning ticket subscription error err=%q", err)
+			}
+			return
+		case <-s.writes.Done():
+			return
+		}
+	}
+}
+
+// Flush writes the spending recorded since the last flush to the DB. Spending that fails to be written is kept pending.
+func (s *SpendingRecorder) Flush() error {
+	return s.writes.Flush()
+}
+
+// StartFlush periodically writes the recorded spending to the DB until StopFlush is called
+func (s *SpendingRecorder) StartFlush() {
+	s.writes.Start(spendingFlushInterval)
+}
+
+// StopFlush stops the flush loop and writes any pending spending to the DB
+func (s *SpendingRecorder) StopFlush() {
+	s.writes.Stop()
+}
+
+// SpendingReport is the spending of the broadcaster for a group of the spending report. Fields that are not
+// part of the grouping are omitted.
+type SpendingReport struct {
+	Recipient        *ethcommon.Address `json:"recipient,omitempty"`
+	ManifestID       *string            `json:"manifestID,omitempty"`
+	Start            *time.Time         `json:"start,omitempty"`
+	Tickets          int64              `json:"tickets"`
+	EV               *big.Rat           `json:"ev"`
+	WinningTickets   int64              `json:"winningTickets"`
+	WinningFaceValue *big.Int           `json:"winningFaceValue"`
+}
+
+// Groupings supported by the spending report
+const (
+	groupByOrchestrator = "orchestrator"
+	groupByStream       = "stream"
+	groupByHour         = "hour"
+	groupByDay          = "day"
+)
+
+// groupSpending sums up the spending with the same values for the groupBy fields
+func groupSpending(spending []*common.DBSpending, groupBy []string) ([]*SpendingReport, error) {
+	var byOrch, byStream bool
+	var period time.Duration
+	for _, g := range groupBy {
+		switch g {
+		case groupByOrchestrator:
+			byOrch = true
+		case groupByStream:
+			byStream = true
+		case groupByHour:
+			period = time.Hour
+		case groupByDay:
+			period = 24 * time.Hour
+		default:
+			return nil, fmt.Errorf("invalid groupBy=%v", g)
+		}
+	}
+
+	var reports []*SpendingReport
+	index := make(map[spendingKey]*SpendingReport)
+	for _, sp := range spending {
+		var key spendingKey
+		report := &SpendingReport{}
+		if byOrch {
+			recipient := sp.Recipient
+			key.recipient = recipient
+			report.Recipient = &recipient
+		}
+		if byStream {
+			manifestID := sp.ManifestID
+			key.manifestID = manifestID
+			report.ManifestID = &manifestID
+		}
+		if period > 0 {
+			start := sp.Hour.UTC().Truncate(period)
+			key.hour = start
+			report.Start = &start
+		}
+		if r, ok := index[key]; ok {
+			report = r
+		} else {
+			report.EV = big.NewRat(0, 1)
+			report.WinningFaceValue = big.NewInt(0)
+			index[key] = report
+			reports = append(reports, report)
+		}
+		report.Tickets += sp.Tickets
+		report.WinningTickets += sp.WinningTickets
+		if sp.EV != nil {
+			report.EV.Add(report.EV, sp.EV)
+		}
+		if sp.WinningFaceValue != nil {
+			report.WinningFaceValue.Add(report.WinningFaceValue, sp.WinningFaceValue)
+		}
+	}
+
+	// The spending is ordered by hour, so only the order within a period is left to settle
+	sort.SliceStable(reports, func(i, j int) bool {
+		a, b := reports[i], reports[j]
+		if a.Start != nil && !a.Start.Equal(*b.Start) {
+			return a.Start.Before(*b.Start)
+		}
+		if a.Recipient != nil && *a.Recipient != *b.Recipient {
+			return a.Recipient.Hex() < b.Recipient.Hex()
+		}
+		if a.ManifestID != nil {
+			return *a.ManifestID < *b.ManifestID
+		}
+		return false
+	})
+	return reports, nil
+}
+
+// parseReportTime parses a time given either as unix seconds or in RFC3339 format
+func parseReportTime(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// parseGroupBy splits a comma separated list of groupings
+func parseGroupBy(s string) []string {
+	var groupBy []string
+	for _, g := range strings.Split(s, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groupBy = append(groupBy, g)
+		}
+	}
+	return groupBy
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/eth/contracts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubWinningTicketWatcher struct {
+	feed event.Feed
+}
+
+func (w *stubWinningTicketWatcher) SubscribeWinningTicketTransfers(sink chan<- *contracts.TicketBrokerWinningTicketTransfer) event.Subscription {
+	return w.feed.Subscribe(sink)
+}
+
+func TestSpendingRecorder(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	o1 := ethcommon.Address{1}
+	o2 := ethcommon.Address{2}
+	s := NewSpendingRecorder(dbh)
+	s.Record(o1, "foo", 2, big.NewRat(1, 2))
+	s.Record(o1, "foo", 1, big.NewRat(1, 4))
+	s.Record(o2, "bar", 1, big.NewRat(1, 1))
+	s.RecordWinningTicket(o1, big.NewInt(1000))
+
+	require.Nil(s.Flush())
+	spending, err := dbh.SelectSpending(nil)
+	require.Nil(err)
+	require.Len(spending, 3)
+	byKey := make(map[string]*common.DBSpending)
+	for _, sp := range spending {
+		byKey[sp.Recipient.Hex()+sp.ManifestID] = sp
+	}
+	assert.Equal(int64(3), byKey[o1.Hex()+"foo"].Tickets)
+	assert.Equal(big.NewRat(3, 4), byKey[o1.Hex()+"foo"].EV)
+	assert.Equal(int64(1), byKey[o1.Hex()].WinningTickets)
+	assert.Equal(big.NewInt(1000), byKey[o1.Hex()].WinningFaceValue)
+	assert.Equal(int64(1), byKey[o2.Hex()+"bar"].Tickets)
+
+	// flushed spending is not written again
+	require.Nil(s.Flush())
+	spending, err = dbh.SelectSpending(nil)
+	require.Nil(err)
+	require.Len(spending, 3)
+	for _, sp := range spending {
+		assert.LessOrEqual(sp.Tickets, int64(3))
+	}
+
+	// spending that fails to be written is kept and merged with spending recorded since
+	_, err = dbraw.Exec("ALTER TABLE spending RENAME TO spending_tmp")
+	require.Nil(err)
+	s.Record(o2, "bar", 1, big.NewRat(1, 1))
+	assert.NotNil(s.Flush())
+	s.Record(o2, "bar", 2, big.NewRat(1, 2))
+	_, err = dbraw.Exec("ALTER TABLE spending_tmp RENAME TO spending")
+	require.Nil(err)
+	require.Nil(s.Flush())
+	spending, err = dbh.SelectSpending(nil)
+	require.Nil(err)
+	require.Len(spending, 3)
+	for _, sp := range spending {
+		if sp.Recipient == o2 {
+			assert.Equal(int64(4), sp.Tickets)
+			assert.Equal(big.NewRat(5, 2), sp.EV)
+		}
+	}
+
+	// nil recorder is a no-op
+	var nilRecorder *SpendingRecorder
+	nilRecorder.Record(o1, "foo", 1, big.NewRat(1, 1))
+	nilRecorder.RecordWinningTicket(o1, big.NewInt(1))
+}
+
+func TestSpendingRecorder_WatchWinningTickets(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	sender := ethcommon.Address{9}
+	recipient := ethcommon.Address{1}
+	s := NewSpendingRecorder(dbh)
+	w := &stubWinningTicketWatcher{}
+
+	go s.WatchWinningTickets(sender, w)
+	defer s.StopFlush()
+	require.Eventually(func() bool { return w.feed.Send(&contracts.TicketBrokerWinningTicketTransfer{}) > 0 }, time.Second, time.Millisecond)
+
+	// only the winning tickets of sender are recorded
+	w.feed.Send(&contracts.TicketBrokerWinningTicketTransfer{Sender: ethcommon.Address{8}, Recipient: recipient, Amount: big.NewInt(500)})
+	w.feed.Send(&contracts.TicketBrokerWinningTicketTransfer{Sender: sender, Recipient: recipient, Amount: big.NewInt(1000)})
+
+	assert.Eventually(func() bool { return s.writes.Pending() == 1 }, time.Second, time.Millisecond)
+	require.Nil(s.Flush())
+	spending, err := dbh.SelectSpending(nil)
+	require.Nil(err)
+	require.Len(spending, 1)
+	assert.Equal(recipient, spending[0].Recipient)
+	assert.Equal(int64(1), spending[0].WinningTickets)
+	assert.Equal(big.NewInt(1000), spending[0].WinningFaceValue)
+}
+
+func TestGroupSpending(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	o1 := ethcommon.Address{1}
+	o2 := ethcommon.Address{2}
+	hour := time.Date(2021, 6, 1, 23, 0, 0, 0, time.UTC)
+	spending := []*common.DBSpending{
+		{Recipient: o2, ManifestID: "bar", Hour: hour, Tickets: 1, EV: big.NewRat(1, 1), WinningFaceValue: big.NewInt(0)},
+		{Recipient: o1, ManifestID: "foo", Hour: hour, Tickets: 2, EV: big.NewRat(1, 2), WinningFaceValue: big.NewInt(0)},
+		{Recipient: o1, Hour: hour.Add(time.Hour), EV: big.NewRat(0, 1), WinningTickets: 1, WinningFaceValue: big.NewInt(1000)},
+		{Recipient: o1, ManifestID: "foo", Hour: hour.Add(time.Hour), Tickets: 3, EV: big.NewRat(1, 4), WinningFaceValue: big.NewInt(0)},
+	}
+
+	// total
+	report, err := groupSpending(spending, nil)
+	require.Nil(err)
+	require.Len(report, 1)
+	assert.Equal(&SpendingReport{Tickets: 6, EV: big.NewRat(7, 4), WinningTickets: 1, WinningFaceValue: big.NewInt(1000)}, report[0])
+
+	// by orchestrator
+	report, err = groupSpending(spending, []string{"orchestrator"})
+	require.Nil(err)
+	require.Len(report, 2)
+	assert.Equal(o1, *report[0].Recipient)
+	assert.Nil(report[0].ManifestID)
+	assert.Equal(int64(5), report[0].Tickets)
+	assert.Equal(big.NewRat(3, 4), report[0].EV)
+	assert.Equal(big.NewInt(1000), report[0].WinningFaceValue)
+	assert.Equal(o2, *report[1].Recipient)
+
+	// by stream and day
+	report, err = groupSpending(spending, []string{"stream", "day"})
+	require.Nil(err)
+	require.Len(report, 4)
+	day := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(day, *report[0].Start)
+	assert.Equal("bar", *report[0].ManifestID)
+	assert.Equal(day, *report[1].Start)
+	assert.Equal("foo", *report[1].ManifestID)
+	assert.Equal(day.Add(24*time.Hour), *report[2].Start)
+	assert.Equal("", *report[2].ManifestID)
+	assert.Equal(int64(1), report[2].WinningTickets)
+	assert.Equal(int64(0), report[2].Tickets)
+	assert.Equal("foo", *report[3].ManifestID)
+
+	// by stream only, the winning tickets that are not attributed to a stream are grouped together
+	report, err = groupSpending(spending, []string{"stream"})
+	require.Nil(err)
+	require.Len(report, 3)
+	assert.Equal([]string{"", "bar", "foo"}, []string{*report[0].ManifestID, *report[1].ManifestID, *report[2].ManifestID})
+	assert.Equal(int64(5), report[2].Tickets)
+
+	// invalid grouping
+	_, err = groupSpending(spending, []string{"round"})
+	assert.EqualError(err, "invalid groupBy=round")
+}
+
+func TestParseReportTime(t *testing.T) {
+	assert := assert.New(t)
+
+	ts, err := parseReportTime("1622505600")
+	assert.Nil(err)
+	assert.Equal(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), ts)
+
+	ts, err = parseReportTime("2021-06-01T02:00:00+02:00")
+	assert.Nil(err)
+	assert.True(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC).Equal(ts))
+
+	_, err = parseReportTime("foo")
+	assert.NotNil(err)
+
+	assert.Equal([]string{"orchestrator", "day"}, parseGroupBy(" orchestrator,,day "))
+	assert.Nil(parseGroupBy(""))
+}
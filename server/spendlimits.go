@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/livepeer/go-livepeer/clog"
+	"github.com/livepeer/go-livepeer/core"
+)
+
+var (
+	errStreamSpendLimitExceeded = errors.New("stream spend limit exceeded")
+	errHourlySpendLimitExceeded = errors.New("hourly spend limit exceeded")
+)
+
+// SpendLimits, if set, caps the ticket EV the broadcaster spends per stream and per hour
+var SpendLimits *SpendLimiter
+
+// SpendLimiter tracks the cumulative EV of the tickets sent for each stream and across all streams in the
+// current hour. Once the limit of a stream is reached, its payments fail with errStreamSpendLimitExceeded, which
+// stops the stream. Once the hourly limit is reached, payments fail with errHourlySpendLimitExceeded, so that
+// segments are not transcoded until the hour rolls over. If alertOnly is set, the overspend is only logged.
+type SpendLimiter struct {
+	maxPerStream *big.Rat
+	maxPerHour   *big.Rat
+	alertOnly    bool
+
+	mu          sync.Mutex
+	streams     map[core.ManifestID]*big.Rat
+	alerted     map[core.ManifestID]bool
+	hourStart   time.Time
+	hourSpend   *big.Rat
+	hourAlerted bool
+}
+
+// NewSpendLimiter creates a SpendLimiter. A nil limit is not enforced.
+func NewSpendLimiter(maxPerStream, maxPerHour *big.Rat, alertOnly bool) *SpendLimiter {
+	return &SpendLimiter{
+		maxPerStream: maxPerStream,
+		maxPerHour:   maxPerHour,
+		alertOnly:    alertOnly,
+		streams:      make(map[core.ManifestID]*big.Rat),
+		alerted:      make(map[core.ManifestID]bool),
+		hourStart:    time.Now(),
+		hourSpend:    big.NewRat(0, 1),
+	}
+}
+
+// Check returns errStreamSpendLimitExceeded or errHourlySpendLimitExceeded if a limit has been reached for the stream
+// mid and limits are enforced
+func (l *SpendLimiter) Check(ctx context.Context, mid core.ManifestID) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resetHourUnsafe()
+
+	if l.maxPerStream != nil {
+		if spent, ok := l.streams[mid]; ok && spent.Cmp(l.maxPerStream) >= 0 {
+			if !l.alerted[mid] {
+				clog.Errorf(ctx, "Stream spend limit reached spent=%v wei limit=%v wei", spent.FloatString(2), l.maxPerStream.FloatString(2))
+				l.alerted[mid] = true
+			}
+			if !l.alertOnly {
+				return errStreamSpendLimitExceeded
+			}
+		}
+	}
+	if l.maxPerHour != nil && l.hourSpend.Cmp(l.maxPerHour) >= 0 {
+		if !l.hourAlerted {
+			clog.Errorf(ctx, "Hourly spend limit reached spent=%v wei limit=%v wei", l.hourSpend.FloatString(2), l.maxPerHour.FloatString(2))
+			l.hourAlerted = true
+		}
+		if !l.alertOnly {
+			return errHourlySpendLimitExceeded
+		}
+	}
+	return nil
+}
+
+// Record adds ev to the spend of the stream mid and of the current hour
+func (l *SpendLimiter) Record(mid core.ManifestID, ev *big.Rat) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resetHourUnsafe()
+
+	spent, ok := l.streams[mid]
+	if !ok {
+		spent = big.NewRat(0, 1)
+		l.streams[mid] = spent
+	}
+	spent.Add(spent, ev)
+	l.hourSpend.Add(l.hourSpend, ev)
+}
+
+// Spent returns the EV spent on the stream mid
+func (l *SpendLimiter) Spent(mid core.ManifestID) *big.Rat {
+	if l == nil {
+		return big.NewRat(0, 1)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if spent, ok := l.streams[mid]; ok {
+		return new(big.Rat).Set(spent)
+	}
+	return big.NewRat(0, 1)
+}
+
+// RemoveStream stops tracking the spend of the stream mid
+func (l *SpendLimiter) RemoveStream(mid core.ManifestID) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.streams, mid)
+	delete(l.alerted, mid)
+}
+
+func (l *SpendLimiter) resetHourUnsafe() {
+	if time.Since(l.hourStart) < time.Hour {
+		return
+	}
+	l.hourStart = time.Now()
+	l.hourSpend = big.NewRat(0, 1)
+	l.hourAlerted = false
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSpendLimiter_PerStream(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewSpendLimiter(big.NewRat(100, 1), nil, false)
+	assert.Nil(l.Check(context.TODO(), "foo"))
+
+	l.Record("foo", big.NewRat(60, 1))
+	l.Record("bar", big.NewRat(60, 1))
+	assert.Nil(l.Check(context.TODO(), "foo"))
+	assert.Zero(big.NewRat(60, 1).Cmp(l.Spent("foo")))
+
+	l.Record("foo", big.NewRat(40, 1))
+	assert.Equal(errStreamSpendLimitExceeded, l.Check(context.TODO(), "foo"))
+	assert.Equal(errStreamSpendLimitExceeded, l.Check(context.TODO(), "foo"))
+	assert.Nil(l.Check(context.TODO(), "bar"))
+
+	// ended streams are forgotten
+	l.RemoveStream("foo")
+	assert.Zero(big.NewRat(0, 1).Cmp(l.Spent("foo")))
+	assert.Nil(l.Check(context.TODO(), "foo"))
+}
+
+func TestSpendLimiter_PerHour(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewSpendLimiter(nil, big.NewRat(100, 1), false)
+	l.Record("foo", big.NewRat(60, 1))
+	assert.Nil(l.Check(context.TODO(), "bar"))
+	l.Record("bar", big.NewRat(40, 1))
+	assert.Equal(errHourlySpendLimitExceeded, l.Check(context.TODO(), "foo"))
+	assert.Equal(errHourlySpendLimitExceeded, l.Check(context.TODO(), "baz"))
+
+	// the hourly spend is reset once the hour has passed
+	l.hourStart = time.Now().Add(-time.Hour)
+	assert.Nil(l.Check(context.TODO(), "foo"))
+	assert.Zero(big.NewRat(60, 1).Cmp(l.Spent("foo")))
+}
+
+func TestSpendLimiter_AlertOnly(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewSpendLimiter(big.NewRat(100, 1), big.NewRat(100, 1), true)
+	l.Record("foo", big.NewRat(200, 1))
+	assert.Nil(l.Check(context.TODO(), "foo"))
+	assert.True(l.alerted["foo"])
+	assert.True(l.hourAlerted)
+
+	// nil limiter
+	var nilLimiter *SpendLimiter
+	nilLimiter.Record("foo", big.NewRat(200, 1))
+	assert.Nil(nilLimiter.Check(context.TODO(), "foo"))
+	assert.Zero(big.NewRat(0, 1).Cmp(nilLimiter.Spent("foo")))
+}
+
+func TestGenPayment_SpendLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func() { SpendLimits = nil }()
+	SpendLimits = NewSpendLimiter(big.NewRat(250, 1), nil, false)
+
+	sender := &pm.MockSender{}
+	s := &BroadcastSession{
+		Broadcaster:      stubBroadcaster2(),
+		Params:           &core.StreamParameters{ManifestID: core.RandomManifestID()},
+		OrchestratorInfo: &net.OrchestratorInfo{PriceInfo: &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1}, AuthToken: stubAuthToken},
+		PMSessionID:      "foo",
+		Sender:           sender,
+	}
+	batch := &pm.TicketBatch{
+		TicketParams: &pm.TicketParams{
+			Recipient:       pm.RandAddress(),
+			FaceValue:       big.NewInt(1000),
+			WinProb:         big.NewInt(1),
+			Seed:            big.NewInt(1),
+			ExpirationBlock: big.NewInt(1),
+		},
+		TicketExpirationParams: &pm.TicketExpirationParams{},
+		SenderParams:           []*pm.TicketSenderParams{{}, {}},
+	}
+	sender.On("CreateTicketBatch", s.PMSessionID, mock.Anything).Return(batch, nil)
+	sender.On("EV", s.PMSessionID).Return(big.NewRat(100, 1), nil)
+
+	// the EV of every ticket sent is recorded
+	_, err := genPayment(context.TODO(), s, 2)
+	assert.Nil(err)
+	assert.Zero(big.NewRat(200, 1).Cmp(SpendLimits.Spent(s.Params.ManifestID)))
+
+	_, err = genPayment(context.TODO(), s, 1)
+	assert.Nil(err)
+	assert.Zero(big.NewRat(300, 1).Cmp(SpendLimits.Spent(s.Params.ManifestID)))
+
+	// no payment is created once the limit is reached
+	_, err = genPayment(context.TODO(), s, 1)
+	assert.Equal(errStreamSpendLimitExceeded, err)
+	assert.True(shouldStopStream(err))
+	assert.True(isNonRetryableError(err))
+	sender.AssertNumberOfCalls(t, "CreateTicketBatch", 2)
+}
+
+func TestTranscodeSegment_HourlySpendLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func() { SpendLimits = nil }()
+	SpendLimits = NewSpendLimiter(nil, big.NewRat(100, 1), false)
+	SpendLimits.Record("other", big.NewRat(100, 1))
+
+	sess := StubBroadcastSession("transcoder1")
+	sess.Sender = &pm.MockSender{}
+	sess.PMSessionID = "foo"
+	bsm := bsmWithSessList([]*BroadcastSession{sess})
+	cxn := &rtmpConnection{
+		mid:         sess.Params.ManifestID,
+		pl:          &stubPlaylistManager{manifestID: sess.Params.ManifestID},
+		profile:     &ffmpeg.P240p30fps16x9,
+		sessManager: bsm,
+	}
+
+	// the segment is refused without suspending the orchestrator or stopping the stream
+	_, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{}, "dummy", nil, nil, nil)
+	assert.Equal(errHourlySpendLimitExceeded, err)
+	assert.True(isNonRetryableError(err))
+	assert.False(shouldStopStream(err))
+	assert.Equal(0, bsm.trustedPool.sus.Suspended("transcoder1"))
+	_, ok := bsm.trustedPool.sessMap["transcoder1"]
+	assert.True(ok)
+
+	// segments are transcoded again once the hour rolls over
+	SpendLimits.hourStart = time.Now().Add(-time.Hour)
+	assert.Nil(SpendLimits.Check(context.TODO(), sess.Params.ManifestID))
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/livepeer/go-livepeer/clog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
+)
+
+// splitProfiles returns the index of the highest resolution profile and the indices of the other profiles
+func splitProfiles(profiles []ffmpeg.VideoProfile) (int, []int) {
+	high := 0
+	for i := 1; i < len(profiles); i++ {
+		w, h, err1 := ffmpeg.VideoProfileResolution(profiles[i])
+		hw, hh, err2 := ffmpeg.VideoProfileResolution(profiles[high])
+		if err1 == nil && err2 == nil && w*h > hw*hh {
+			high = i
+		}
+	}
+	var low []int
+	for i := range profiles {
+		if i != high {
+			low = append(low, i)
+		}
+	}
+	return high, low
+}
+
+// splitSessions returns the session to transcode the highest rendition and the session to transcode the
+// other renditions, which is the cheaper one so that low renditions go to cheap orchestrators
+func splitSessions(a, b *BroadcastSession) (*BroadcastSession, *BroadcastSession) {
+	priceA, errA := common.RatPriceInfo(a.OrchestratorInfo.GetPriceInfo())
+	priceB, errB := common.RatPriceInfo(b.OrchestratorInfo.GetPriceInfo())
+	if errA == nil && errB == nil && priceA != nil && priceB != nil && priceA.Cmp(priceB) < 0 {
+		return b, a
+	}
+	return a, b
+}
+
+// splitResult holds the renditions of a segment that were already transcoded while its renditions are split across
+// two orchestrators, so that a retry of the segment only transcodes the missing renditions
+type splitResult struct {
+	urls []string
+}
+
+// missing returns those of indices whose renditions are not transcoded yet
+func (r *splitResult) missing(indices []int) []int {
+	var res []int
+	for _, idx := range indices {
+		if r.urls[idx] == "" {
+			res = append(res, idx)
+		}
+	}
+	return res
+}
+
+// transcodeSplit transcodes the highest rendition of seg with one session and the other renditions with the other
+// session, each with its own payment session. The URLs of the renditions are returned in the order of the profiles.
+// If either half fails, the renditions of the other half are kept in res and only the failed half is transcoded
+// when the segment is retried, with a single session if only one is needed.
+func transcodeSplit(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, name string, sessions []*BroadcastSession,
+	segPar *core.SegmentParameters, nonce uint64, res *splitResult) ([]string, error) {
+
+	profiles := sessions[0].Params.Profiles
+	if res == nil {
+		res = &splitResult{}
+	}
+	if len(res.urls) != len(profiles) {
+		res.urls = make([]string, len(profiles))
+	}
+	high, low := splitProfiles(profiles)
+	type part struct {
+		sess    *BroadcastSession
+		indices []int
+	}
+	var parts []part
+	highMissing, lowMissing := res.missing([]int{high}), res.missing(low)
+	switch {
+	case len(highMissing) > 0 && len(lowMissing) > 0 && len(sessions) > 1:
+		highSess, lowSess := splitSessions(sessions[0], sessions[1])
+		parts = []part{{highSess, highMissing}, {lowSess, lowMissing}}
+		clog.Infof(ctx, "Splitting renditions highOrch=%s lowOrch=%s", highSess.Transcoder(), lowSess.Transcoder())
+	default:
+		// Only one half is left, or a single session is available, so all the missing renditions go to one session
+		sess := sessions[0]
+		if len(sessions) > 1 {
+			highSess, lowSess := splitSessions(sessions[0], sessions[1])
+			sess = highSess
+			if len(highMissing) == 0 {
+				sess = lowSess
+			}
+		}
+		for _, s := range sessions {
+			if s != sess {
+				cxn.sessManager.completeSession(ctx, s, false)
+			}
+		}
+		parts = []part{{sess, append(highMissing, lowMissing...)}}
+		clog.Infof(ctx, "Transcoding missing split renditions orch=%s renditions=%d", sess.Transcoder(), len(parts[0].indices))
+	}
+
+	errs := make([]error, len(parts))
+	var wg sync.WaitGroup
+	for i, p := range parts {
+		wg.Add(1)
+		go func(i int, sess *BroadcastSession, indices []int) {
+			defer wg.Done()
+			partProfiles := make([]ffmpeg.VideoProfile, len(indices))
+			for j, idx := range indices {
+				partProfiles[j] = profiles[idx]
+			}
+			partURLs, err := transcodeRenditions(ctx, cxn, seg, name, sess, partProfiles, segPar, nonce)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for j, idx := range indices {
+				res.urls[idx] = partURLs[j]
+			}
+		}(i, p.sess, p.indices)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res.urls, nil
+}
+
+// transcodeRenditions transcodes seg into profiles with sess, which may be a subset of the stream's profiles
+func transcodeRenditions(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, name string, sess *BroadcastSession,
+	profiles []ffmpeg.VideoProfile, segPar *core.SegmentParameters, nonce uint64) ([]string, error) {
+
+	seg2, err := prepareForTranscoding(ctx, cxn, sess, seg, name)
+	if err != nil {
+		return nil, err
+	}
+	sess.pushSegInFlight(seg2)
+
+	// Only the submitted copy of the session is restricted to the profiles so that the session kept in the pool is unchanged
+	partSess := sess.Clone()
+	params := *partSess.Params
+	params.Profiles = profiles
+	partSess.Params = &params
+
+	start := time.Now()
+	res, err := SubmitSegment(ctx, partSess, seg2, segPar, nonce, false, false)
+	observeSubmission(sess, start, res, err)
+	if err == nil && res == nil {
+		err = errors.New("empty response")
+	}
+	if err == nil && len(res.Segments) != len(profiles) {
+		err = fmt.Errorf("expected renditions=%d got=%d", len(profiles), len(res.Segments))
+	}
+	if err != nil {
+		cxn.sessManager.resultFailed(&SubmitResult{Session: sess, TranscodeResult: res, Err: err})
+		return nil, err
+	}
+	return downloadResults(ctx, cxn, seg, sess, profiles, res, nil)
+}
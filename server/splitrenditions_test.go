@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/protobuf/proto"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitProfiles(t *testing.T) {
+	assert := assert.New(t)
+
+	high, low := splitProfiles([]ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9, ffmpeg.P720p30fps16x9, ffmpeg.P360p30fps16x9})
+	assert.Equal(1, high)
+	assert.Equal([]int{0, 2}, low)
+
+	high, low = splitProfiles([]ffmpeg.VideoProfile{ffmpeg.P720p30fps16x9, ffmpeg.P144p30fps16x9})
+	assert.Equal(0, high)
+	assert.Equal([]int{1}, low)
+
+	// invalid resolutions are never the highest
+	high, low = splitProfiles([]ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9, {Name: "bad", Resolution: "foo"}})
+	assert.Equal(0, high)
+	assert.Equal([]int{1}, low)
+}
+
+func TestSplitSessions(t *testing.T) {
+	assert := assert.New(t)
+
+	expensive := StubBroadcastSession("transcoder1")
+	expensive.OrchestratorInfo.PriceInfo = &net.PriceInfo{PricePerUnit: 10, PixelsPerUnit: 1}
+	cheap := StubBroadcastSession("transcoder2")
+	cheap.OrchestratorInfo.PriceInfo = &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1}
+
+	// the cheaper session transcodes the low renditions
+	highSess, lowSess := splitSessions(expensive, cheap)
+	assert.Equal(expensive, highSess)
+	assert.Equal(cheap, lowSess)
+	highSess, lowSess = splitSessions(cheap, expensive)
+	assert.Equal(expensive, highSess)
+	assert.Equal(cheap, lowSess)
+
+	// order is kept when prices are equal or unknown
+	other := StubBroadcastSession("transcoder3")
+	other.OrchestratorInfo.PriceInfo = nil
+	highSess, lowSess = splitSessions(cheap, other)
+	assert.Equal(cheap, highSess)
+	assert.Equal(other, lowSess)
+}
+
+func TestTranscodeSegment_SplitRenditions(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	orch := &mockOrchestrator{}
+	orch.On("VerifySig", mock.Anything, mock.Anything, mock.Anything).Return(true)
+	orch.On("AuthToken", mock.Anything, mock.Anything).Return(stubAuthToken)
+
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			md, _, err := verifySegCreds(context.TODO(), orch, r.Header.Get(segmentHeader), ethcommon.Address{})
+			require.NoError(err)
+			td := &net.TranscodeData{Sig: []byte("bar")}
+			var names []string
+			for _, p := range md.Profiles {
+				names = append(names, p.Name)
+				td.Segments = append(td.Segments, &net.TranscodedSegmentData{Url: name + "/" + p.Name + ".ts"})
+			}
+			mu.Lock()
+			received[name] = names
+			mu.Unlock()
+			buf, err := proto.Marshal(&net.TranscodeResult{Result: &net.TranscodeResult_Data{Data: td}})
+			require.NoError(err)
+			w.Write(buf)
+		}
+	}
+
+	ts1, mux1 := stubTLSServer()
+	defer ts1.Close()
+	mux1.HandleFunc("/segment", handler("expensive"))
+	ts2, mux2 := stubTLSServer()
+	defer ts2.Close()
+	mux2.HandleFunc("/segment", handler("cheap"))
+
+	profiles := []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9, ffmpeg.P720p30fps16x9, ffmpeg.P360p30fps16x9}
+	sess1 := StubBroadcastSession(ts1.URL)
+	sess1.OrchestratorInfo.PriceInfo = &net.PriceInfo{PricePerUnit: 10, PixelsPerUnit: 1}
+	sess1.Params.Profiles = profiles
+	sess2 := StubBroadcastSession(ts2.URL)
+	sess2.Params.Profiles = profiles
+	bsm := bsmWithSessListExt([]*BroadcastSession{sess1, sess2}, nil, true)
+	bsm.split = true
+	cxn := &rtmpConnection{
+		mid:         core.ManifestID("foo"),
+		pl:          &stubPlaylistManager{manifestID: core.ManifestID("foo")},
+		profile:     &ffmpeg.P144p30fps16x9,
+		sessManager: bsm,
+	}
+
+	urls, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil, nil)
+	require.NoError(err)
+
+	// the highest rendition goes to the expensive orchestrator and the others to the cheap one
+	assert.Equal([]string{ffmpeg.P720p30fps16x9.Name}, received["expensive"])
+	assert.Equal([]string{ffmpeg.P144p30fps16x9.Name, ffmpeg.P360p30fps16x9.Name}, received["cheap"])
+	assert.Equal([]string{"cheap/P144p30fps16x9.ts", "expensive/P720p30fps16x9.ts", "cheap/P360p30fps16x9.ts"}, urls)
+
+	// the sessions kept in the pool still have all the profiles
+	assert.Equal(profiles, sess1.Params.Profiles)
+	assert.Equal(profiles, sess2.Params.Profiles)
+	assert.Len(bsm.trustedPool.sessMap, 2)
+}
+
+func TestTranscodeSplit_RetriesFailedHalf(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	orch := &mockOrchestrator{}
+	orch.On("VerifySig", mock.Anything, mock.Anything, mock.Anything).Return(true)
+	orch.On("AuthToken", mock.Anything, mock.Anything).Return(stubAuthToken)
+
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	handler := func(name string, fail bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			md, _, err := verifySegCreds(context.TODO(), orch, r.Header.Get(segmentHeader), ethcommon.Address{})
+			require.NoError(err)
+			td := &net.TranscodeData{Sig: []byte("bar")}
+			var names []string
+			for _, p := range md.Profiles {
+				names = append(names, p.Name)
+				td.Segments = append(td.Segments, &net.TranscodedSegmentData{Url: name + "/" + p.Name + ".ts"})
+			}
+			mu.Lock()
+			received[name] = names
+			mu.Unlock()
+			if fail {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			buf, err := proto.Marshal(&net.TranscodeResult{Result: &net.TranscodeResult_Data{Data: td}})
+			require.NoError(err)
+			w.Write(buf)
+		}
+	}
+
+	profiles := []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9, ffmpeg.P720p30fps16x9, ffmpeg.P360p30fps16x9}
+	newSess := func(name string, price int64, fail bool) *BroadcastSession {
+		ts, mux := stubTLSServer()
+		t.Cleanup(ts.Close)
+		mux.HandleFunc("/segment", handler(name, fail))
+		sess := StubBroadcastSession(ts.URL)
+		sess.OrchestratorInfo.PriceInfo = &net.PriceInfo{PricePerUnit: price, PixelsPerUnit: 1}
+		sess.Params.Profiles = profiles
+		return sess
+	}
+	failing := newSess("failing", 10, true)
+	cheap := newSess("cheap", 1, false)
+	other := newSess("other", 5, false)
+	bsm := bsmWithSessListExt([]*BroadcastSession{failing, cheap, other}, nil, true)
+	bsm.split = true
+	cxn := &rtmpConnection{
+		mid:         core.ManifestID("foo"),
+		pl:          &stubPlaylistManager{manifestID: core.ManifestID("foo")},
+		profile:     &ffmpeg.P144p30fps16x9,
+		sessManager: bsm,
+	}
+	seg := &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}
+
+	// the low renditions are kept when the highest rendition fails
+	split := &splitResult{}
+	_, err := transcodeSplit(context.TODO(), cxn, seg, "dummy", []*BroadcastSession{failing, cheap}, nil, 0, split)
+	require.Error(err)
+	assert.Equal([]string{ffmpeg.P720p30fps16x9.Name}, received["failing"])
+	assert.Equal([]string{ffmpeg.P144p30fps16x9.Name, ffmpeg.P360p30fps16x9.Name}, received["cheap"])
+	assert.Equal([]string{"cheap/P144p30fps16x9.ts", "", "cheap/P360p30fps16x9.ts"}, split.urls)
+
+	// the retry only transcodes the highest rendition on a new session
+	received = make(map[string][]string)
+	urls, err := transcodeSplit(context.TODO(), cxn, seg, "dummy", []*BroadcastSession{other}, nil, 0, split)
+	require.NoError(err)
+	assert.Equal([]string{ffmpeg.P720p30fps16x9.Name}, received["other"])
+	assert.NotContains(received, "cheap")
+	assert.Equal([]string{"cheap/P144p30fps16x9.ts", "other/P720p30fps16x9.ts", "cheap/P360p30fps16x9.ts"}, urls)
+
+	// with two sessions for a single missing half, the unused session goes back to the pool
+	split = &splitResult{urls: []string{"", "done.ts", ""}}
+	received = make(map[string][]string)
+	urls, err = transcodeSplit(context.TODO(), cxn, seg, "dummy", []*BroadcastSession{other, cheap}, nil, 0, split)
+	require.NoError(err)
+	assert.Equal([]string{ffmpeg.P144p30fps16x9.Name, ffmpeg.P360p30fps16x9.Name}, received["cheap"])
+	assert.NotContains(received, "other")
+	assert.Equal([]string{"cheap/P144p30fps16x9.ts", "done.ts", "cheap/P360p30fps16x9.ts"}, urls)
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/livepeer-data/pkg/data"
+)
+
+// Number of recent segments kept per stream for debugging
+const streamDebugSegments = 20
+
+// Maximum number of distinct errors counted per stream, the remaining ones are counted together
+const streamDebugErrors = 20
+
+const streamDebugOtherErrors = "other"
+
+// streamDebugStats keeps the outcome of the recent segments of a stream
+type streamDebugStats struct {
+	mu        sync.Mutex
+	segments  []segmentDebugInfo
+	succeeded int
+	failed    int
+	errors    map[string]int
+}
+
+type segmentDebugInfo struct {
+	SeqNo        uint64 `json:"seqNo"`
+	Orchestrator string `json:"orchestrator,omitempty"`
+	Attempts     int    `json:"attempts"`
+	LatencyMs    int64  `json:"latencyMs"`
+	Error        string `json:"error,omitempty"`
+}
+
+func newStreamDebugStats() *streamDebugStats {
+	return &streamDebugStats{errors: make(map[string]int)}
+}
+
+// observe records the outcome of a segment, counting the errors of all its transcode attempts
+func (s *streamDebugStats) observe(seqNo uint64, latency time.Duration, attempts []data.TranscodeAttemptInfo, err error) {
+	if s == nil {
+		return
+	}
+	info := segmentDebugInfo{SeqNo: seqNo, Attempts: len(attempts), LatencyMs: latency.Milliseconds()}
+	if len(attempts) > 0 {
+		info.Orchestrator = attempts[len(attempts)-1].Orchestrator.TranscoderUri
+	}
+	if err != nil {
+		info.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failed++
+	} else {
+		s.succeeded++
+	}
+	for _, attempt := range attempts {
+		if attempt.Error != nil {
+			s.countError(*attempt.Error)
+		}
+	}
+	if len(s.segments) >= streamDebugSegments {
+		s.segments = s.segments[1:]
+	}
+	s.segments = append(s.segments, info)
+}
+
+func (s *streamDebugStats) countError(err string) {
+	if _, ok := s.errors[err]; !ok && len(s.errors) >= streamDebugErrors {
+		err = streamDebugOtherErrors
+	}
+	s.errors[err]++
+}
+
+type streamDebugInfo struct {
+	ManifestID       string             `json:"manifestID"`
+	ExternalStreamID string             `json:"externalStreamID,omitempty"`
+	Profiles         []string           `json:"profiles"`
+	StartedAt        time.Time          `json:"startedAt"`
+	SourceBytes      uint64             `json:"sourceBytes" doc:"Bytes of the segments received from the publisher"`
+	TranscodedBytes  uint64             `json:"transcodedBytes" doc:"Bytes of the transcoded renditions"`
+	Sessions         []sessionDebugInfo `json:"sessions"`
+	Succeeded        int                `json:"succeeded"`
+	Failed           int                `json:"failed"`
+	Errors           map[string]int     `json:"errors"`
+	RecentSegments   []segmentDebugInfo `json:"recentSegments"`
+	Health           streamHealthInfo   `json:"health"`
+}
+
+type sessionDebugInfo struct {
+	Orchestrator  string           `json:"orchestrator"`
+	Address       string           `json:"address"`
+	Trusted       bool             `json:"trusted"`
+	PricePerUnit  int64            `json:"pricePerUnit"`
+	PixelsPerUnit int64            `json:"pixelsPerUnit"`
+	TicketParams  *ticketDebugInfo `json:"ticketParams,omitempty"`
+	SegsInFlight  int              `json:"segsInFlight"`
+	LatencyScore  float64          `json:"latencyScore"`
+}
+
+type ticketDebugInfo struct {
+	Recipient       string `json:"recipient"`
+	FaceValue       string `json:"faceValue"`
+	WinProb         string `json:"winProb"`
+	ExpirationBlock string `json:"expirationBlock"`
+}
+
+func (s *streamDebugStats) debugInfo(info *streamDebugInfo) {
+	info.Errors = make(map[string]int)
+	info.RecentSegments = []segmentDebugInfo{}
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info.Succeeded, info.Failed = s.succeeded, s.failed
+	for err, count := range s.errors {
+		info.Errors[err] = count
+	}
+	info.RecentSegments = append(info.RecentSegments, s.segments...)
+}
+
+// debugSessions returns the sessions currently used to transcode the segments of the stream
+func (sp *SessionPool) debugSessions() []*BroadcastSession {
+	if sp == nil {
+		return nil
+	}
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	return append([]*BroadcastSession(nil), sp.lastSess...)
+}
+
+func sessionDebug(sess *BroadcastSession, trusted bool) sessionDebugInfo {
+	info := sessionDebugInfo{Trusted: trusted}
+	sess.lock.RLock()
+	defer sess.lock.RUnlock()
+	info.SegsInFlight = len(sess.SegsInFlight)
+	info.LatencyScore = sess.LatencyScore
+	if oInfo := sess.OrchestratorInfo; oInfo != nil {
+		info.Orchestrator = oInfo.Transcoder
+		info.Address = hexutil.Encode(oInfo.Address)
+		info.PricePerUnit = oInfo.GetPriceInfo().GetPricePerUnit()
+		info.PixelsPerUnit = oInfo.GetPriceInfo().GetPixelsPerUnit()
+		if params := pmTicketParams(oInfo.TicketParams); params != nil {
+			info.TicketParams = &ticketDebugInfo{
+				Recipient:       params.Recipient.Hex(),
+				FaceValue:       params.FaceValue.String(),
+				WinProb:         params.WinProbRat().FloatString(10),
+				ExpirationBlock: params.ExpirationBlock.String(),
+			}
+		}
+	}
+	return info
+}
+
+func cxnDebug(cxn *rtmpConnection) streamDebugInfo {
+	info := streamDebugInfo{
+		ManifestID:      string(cxn.mid),
+		Profiles:        []string{},
+		StartedAt:       cxn.startedAt,
+		SourceBytes:     atomic.LoadUint64(&cxn.sourceBytes),
+		TranscodedBytes: atomic.LoadUint64(&cxn.transcodedBytes),
+		Sessions:        []sessionDebugInfo{},
+	}
+	if cxn.params != nil {
+		info.ExternalStreamID = cxn.params.ExternalStreamID
+		for _, p := range cxn.params.Profiles {
+			info.Profiles = append(info.Profiles, p.Name)
+		}
+	}
+	if bsm := cxn.sessManager; bsm != nil {
+		for _, sess := range bsm.trustedPool.debugSessions() {
+			info.Sessions = append(info.Sessions, sessionDebug(sess, true))
+		}
+		for _, sess := range bsm.untrustedPool.debugSessions() {
+			info.Sessions = append(info.Sessions, sessionDebug(sess, false))
+		}
+	}
+	cxn.debugStats.debugInfo(&info)
+	info.Health = cxn.health.info()
+	return info
+}
+
+// streamsDebug returns the debug info of the active streams sorted by manifest ID, or only of the stream mid if set
+func (s *LivepeerServer) streamsDebug(mid core.ManifestID) []streamDebugInfo {
+	var cxns []*rtmpConnection
+	s.connectionLock.RLock()
+	for _, cxn := range s.rtmpConnections {
+		if mid == "" || cxn.mid == mid {
+			cxns = append(cxns, cxn)
+		}
+	}
+	s.connectionLock.RUnlock()
+
+	streams := []streamDebugInfo{}
+	for _, cxn := range cxns {
+		if cxn.initializing != nil {
+			<-cxn.initializing
+		}
+		streams = append(streams, cxnDebug(cxn))
+	}
+	sort.Slice(streams, func(i, j int) bool { return streams[i].ManifestID < streams[j].ManifestID })
+	return streams
+}
+
+// streamsDebugHandler shows the orchestrators, prices and recent segments of the active streams,
+// or only of the stream with the manifestID param if set
+func (s *LivepeerServer) streamsDebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mid := core.ManifestID(r.FormValue("manifestID"))
+		streams := s.streamsDebug(mid)
+		if mid != "" && len(streams) == 0 {
+			respondWithError(w, "unknown manifestID", http.StatusNotFound)
+			return
+		}
+		respondJson(w, streams)
+	})
+}
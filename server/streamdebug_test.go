@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/livepeer-data/pkg/data"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamDebugStats_Observe(t *testing.T) {
+	assert := assert.New(t)
+
+	errStr := "some error"
+	failed := data.TranscodeAttemptInfo{Orchestrator: data.OrchestratorMetadata{TranscoderUri: "https://o1"}, Error: &errStr}
+	succeeded := data.TranscodeAttemptInfo{Orchestrator: data.OrchestratorMetadata{TranscoderUri: "https://o2"}}
+
+	s := newStreamDebugStats()
+	s.observe(0, 2*time.Second, []data.TranscodeAttemptInfo{failed, succeeded}, nil)
+	s.observe(1, time.Second, []data.TranscodeAttemptInfo{failed}, errors.New("Hit max transcode attempts: some error"))
+
+	var info streamDebugInfo
+	s.debugInfo(&info)
+	assert.Equal(1, info.Succeeded)
+	assert.Equal(1, info.Failed)
+	assert.Equal(map[string]int{"some error": 2}, info.Errors)
+	assert.Equal([]segmentDebugInfo{
+		{SeqNo: 0, Orchestrator: "https://o2", Attempts: 2, LatencyMs: 2000},
+		{SeqNo: 1, Orchestrator: "https://o1", Attempts: 1, LatencyMs: 1000, Error: "Hit max transcode attempts: some error"},
+	}, info.RecentSegments)
+
+	// only the most recent segments are kept and distinct errors are capped
+	for i := 0; i < 2*streamDebugSegments; i++ {
+		e := fmt.Sprintf("error %d", i)
+		s.observe(uint64(i+2), time.Second, []data.TranscodeAttemptInfo{{Error: &e}}, nil)
+	}
+	s.debugInfo(&info)
+	assert.Len(info.RecentSegments, streamDebugSegments)
+	assert.Equal(uint64(2*streamDebugSegments+1), info.RecentSegments[streamDebugSegments-1].SeqNo)
+	assert.Len(info.Errors, streamDebugErrors+1)
+	assert.Equal(2*streamDebugSegments+1-streamDebugErrors, info.Errors[streamDebugOtherErrors])
+
+	// nil stats are a no-op
+	var nilStats *streamDebugStats
+	nilStats.observe(0, time.Second, nil, nil)
+	nilStats.debugInfo(&info)
+	assert.Empty(info.RecentSegments)
+}
+
+func TestStreamsDebugHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	sess := StubBroadcastSession("https://o1")
+	sess.OrchestratorInfo.Address = []byte{1, 2}
+	sess.OrchestratorInfo.TicketParams = &net.TicketParams{
+		Recipient:       []byte{3},
+		FaceValue:       big.NewInt(1000).Bytes(),
+		WinProb:         new(big.Int).Rsh(new(big.Int).Lsh(big.NewInt(1), 256), 1).Bytes(),
+		ExpirationBlock: big.NewInt(42).Bytes(),
+	}
+	bsm := bsmWithSessList([]*BroadcastSession{sess})
+	bsm.trustedPool.lastSess = []*BroadcastSession{sess}
+
+	stats := newStreamDebugStats()
+	stats.observe(7, time.Second, []data.TranscodeAttemptInfo{{Orchestrator: data.OrchestratorMetadata{TranscoderUri: "https://o1"}}}, nil)
+	s := &LivepeerServer{
+		connectionLock: &sync.RWMutex{},
+		rtmpConnections: map[core.ManifestID]*rtmpConnection{
+			"foo": {
+				mid:         "foo",
+				params:      &core.StreamParameters{ExternalStreamID: "ext", Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}},
+				sessManager: bsm,
+				debugStats:  stats,
+			},
+			"bar": {mid: "bar"},
+		},
+	}
+	handler := s.streamsDebugHandler()
+
+	get := func(query string) (int, []byte) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/debug/streams"+query, nil))
+		body, err := ioutil.ReadAll(w.Result().Body)
+		require.Nil(err)
+		return w.Result().StatusCode, body
+	}
+
+	status, body := get("")
+	assert.Equal(http.StatusOK, status)
+	var streams []streamDebugInfo
+	require.Nil(json.Unmarshal(body, &streams))
+	require.Len(streams, 2)
+	assert.Equal("bar", streams[0].ManifestID)
+	assert.Empty(streams[0].Sessions)
+
+	foo := streams[1]
+	assert.Equal("foo", foo.ManifestID)
+	assert.Equal("ext", foo.ExternalStreamID)
+	assert.Equal([]string{"P144p30fps16x9"}, foo.Profiles)
+	assert.Equal(1, foo.Succeeded)
+	assert.Equal([]segmentDebugInfo{{SeqNo: 7, Orchestrator: "https://o1", Attempts: 1, LatencyMs: 1000}}, foo.RecentSegments)
+	require.Len(foo.Sessions, 1)
+	assert.Equal(sessionDebugInfo{
+		Orchestrator:  "https://o1",
+		Address:       "0x0102",
+		Trusted:       true,
+		PricePerUnit:  1,
+		PixelsPerUnit: 1,
+		TicketParams: &ticketDebugInfo{
+			Recipient:       "0x0000000000000000000000000000000000000003",
+			FaceValue:       "1000",
+			WinProb:         "0.5000000000",
+			ExpirationBlock: "42",
+		},
+	}, foo.Sessions[0])
+
+	// filtered by manifestID
+	status, body = get("?manifestID=foo")
+	assert.Equal(http.StatusOK, status)
+	require.Nil(json.Unmarshal(body, &streams))
+	require.Len(streams, 1)
+	assert.Equal("foo", streams[0].ManifestID)
+
+	status, _ = get("?manifestID=baz")
+	assert.Equal(http.StatusNotFound, status)
+}
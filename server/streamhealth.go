@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/livepeer/go-livepeer/broker"
+	"github.com/livepeer/go-livepeer/clog"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/monitor"
+	"github.com/livepeer/livepeer-data/pkg/data"
+)
+
+// Number of recent segments the health score of a stream is computed from
+const streamHealthWindow = 30
+
+// Weights of the components of the health score, adding up to 1
+const (
+	healthWeightDropped      = 0.4
+	healthWeightLatency      = 0.3
+	healthWeightVerification = 0.15
+	healthWeightSwaps        = 0.15
+)
+
+// Transcode latency, relative to the segment duration, up to which a segment is fully healthy
+// and from which it doesn't count as healthy at all
+const (
+	healthRealtimeRatioGood = 0.5
+	healthRealtimeRatioBad  = 1.5
+)
+
+// Scores under which a stream is considered degraded and unhealthy
+const (
+	healthScoreDegraded  = 80
+	healthScoreUnhealthy = 50
+)
+
+const eventTypeStreamHealth data.EventType = "stream_health"
+
+type streamHealthState string
+
+const (
+	streamHealthy   streamHealthState = "healthy"
+	streamDegraded  streamHealthState = "degraded"
+	streamUnhealthy streamHealthState = "unhealthy"
+)
+
+type segmentHealth struct {
+	dropped              bool
+	realtimeRatio        float64
+	verificationFailures int
+	swaps                int
+}
+
+// streamHealth computes the health score of a stream from its recent segments
+type streamHealth struct {
+	mu       sync.Mutex
+	segments []segmentHealth
+	lastOrch string
+	state    streamHealthState
+	// verification failures of the segment being transcoded
+	verificationFailures int
+}
+
+type streamHealthInfo struct {
+	Score                   float64           `json:"score"`
+	State                   streamHealthState `json:"state"`
+	DroppedRate             float64           `json:"droppedRate"`
+	RealtimeRatio           float64           `json:"realtimeRatio"`
+	VerificationFailureRate float64           `json:"verificationFailureRate"`
+	SwapRate                float64           `json:"swapRate"`
+}
+
+// streamHealthEvent is published to the metadata queue when the health state of a stream changes and when it ends
+type streamHealthEvent struct {
+	data.Base
+	NodeID string           `json:"nodeId"`
+	Ended  bool             `json:"ended"`
+	Health streamHealthInfo `json:"health"`
+}
+
+func newStreamHealth() *streamHealth {
+	return &streamHealth{state: streamHealthy}
+}
+
+// verificationFailed counts a failed verification of the results of an orchestrator for the segment being transcoded
+func (h *streamHealth) verificationFailed() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.verificationFailures++
+}
+
+// observe records the outcome of a segment and returns the updated health of the stream,
+// along with whether its health state changed
+func (h *streamHealth) observe(duration float64, latency time.Duration, attempts []data.TranscodeAttemptInfo, dropped bool) (streamHealthInfo, bool) {
+	if h == nil {
+		return newStreamHealth().info(), false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seg := segmentHealth{dropped: dropped, verificationFailures: h.verificationFailures}
+	if duration > 0 {
+		seg.realtimeRatio = latency.Seconds() / duration
+	}
+	for _, attempt := range attempts {
+		orch := attempt.Orchestrator.TranscoderUri
+		if orch == "" {
+			continue
+		}
+		if h.lastOrch != "" && orch != h.lastOrch {
+			seg.swaps++
+		}
+		h.lastOrch = orch
+	}
+	h.verificationFailures = 0
+	if len(h.segments) >= streamHealthWindow {
+		h.segments = h.segments[1:]
+	}
+	h.segments = append(h.segments, seg)
+
+	info := h.infoLocked()
+	changed := info.State != h.state
+	h.state = info.State
+	return info, changed
+}
+
+func (h *streamHealth) info() streamHealthInfo {
+	if h == nil {
+		return newStreamHealth().info()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.infoLocked()
+}
+
+func (h *streamHealth) infoLocked() streamHealthInfo {
+	info := streamHealthInfo{Score: 100, State: streamHealthy}
+	if len(h.segments) == 0 {
+		return info
+	}
+	var dropped, verificationFailed, swaps, transcoded int
+	var realtimeRatio, latencyScore float64
+	for _, seg := range h.segments {
+		if seg.verificationFailures > 0 {
+			verificationFailed++
+		}
+		swaps += seg.swaps
+		if seg.dropped {
+			dropped++
+			continue
+		}
+		transcoded++
+		realtimeRatio += seg.realtimeRatio
+		latencyScore += clamp01((healthRealtimeRatioBad - seg.realtimeRatio) / (healthRealtimeRatioBad - healthRealtimeRatioGood))
+	}
+	n := float64(len(h.segments))
+	info.DroppedRate = float64(dropped) / n
+	info.VerificationFailureRate = float64(verificationFailed) / n
+	info.SwapRate = clamp01(float64(swaps) / n)
+	if transcoded > 0 {
+		info.RealtimeRatio = realtimeRatio / float64(transcoded)
+		latencyScore /= float64(transcoded)
+	}
+	score := healthWeightDropped*(1-info.DroppedRate) +
+		healthWeightLatency*latencyScore +
+		healthWeightVerification*(1-info.VerificationFailureRate) +
+		healthWeightSwaps*(1-info.SwapRate)
+	info.Score = math.Round(score*1000) / 10
+	switch {
+	case info.Score < healthScoreUnhealthy:
+		info.State = streamUnhealthy
+	case info.Score < healthScoreDegraded:
+		info.State = streamDegraded
+	}
+	return info
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func newStreamHealthEventKey(mid core.ManifestID, streamID string) string {
+	shardKey := string(mid[0])
+	return fmt.Sprintf("stream_health.score.%s.%s", shardKey, streamID)
+}
+
+func newStreamHealthEvent(streamID string, health streamHealthInfo, ended bool) *streamHealthEvent {
+	return &streamHealthEvent{
+		Base: data.Base{
+			Type_:      eventTypeStreamHealth,
+			ID_:        uuid.New(),
+			Timestamp_: data.UnixMillisTime{Time: time.Now().UTC()},
+			StreamID_:  streamID,
+		},
+		NodeID: monitor.NodeID,
+		Ended:  ended,
+		Health: health,
+	}
+}
+
+// publishStreamHealth publishes the health of the stream to the metadata queue, if any
+func publishStreamHealth(cxn *rtmpConnection, health streamHealthInfo, ended bool) {
+	if MetadataQueue == nil {
+		return
+	}
+	streamID := cxnStreamID(cxn)
+	key := newStreamHealthEventKey(cxn.mid, streamID)
+	evt := newStreamHealthEvent(streamID, health, ended)
+	go func() {
+		ctx, cancel := context.WithTimeout(broker.WithPartitionKey(context.Background(), string(cxn.mid)), MetadataPublishTimeout)
+		defer cancel()
+		if err := MetadataQueue.Publish(ctx, key, evt, false); err != nil {
+			clog.Errorf(ctx, "Error publishing stream health event: err=%q key=%q event=%+v", err, key, evt)
+		}
+	}()
+}
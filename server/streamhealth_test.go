@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/broker"
+	"github.com/livepeer/livepeer-data/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamHealth_Observe(t *testing.T) {
+	assert := assert.New(t)
+
+	o1 := data.TranscodeAttemptInfo{Orchestrator: data.OrchestratorMetadata{TranscoderUri: "https://o1"}}
+	o2 := data.TranscodeAttemptInfo{Orchestrator: data.OrchestratorMetadata{TranscoderUri: "https://o2"}}
+
+	h := newStreamHealth()
+	assert.Equal(streamHealthInfo{Score: 100, State: streamHealthy}, h.info())
+
+	// segments transcoded faster than realtime by the same orchestrator are fully healthy
+	for i := 0; i < 4; i++ {
+		info, changed := h.observe(2, time.Second, []data.TranscodeAttemptInfo{o1}, false)
+		assert.False(changed)
+		assert.Equal(100.0, info.Score)
+	}
+
+	// a dropped segment and a swap to another orchestrator with a failed verification
+	info, changed := h.observe(2, 3*time.Second, []data.TranscodeAttemptInfo{o1}, true)
+	assert.False(changed)
+	assert.Equal(0.2, info.DroppedRate)
+	assert.Equal(92.0, info.Score)
+	h.verificationFailed()
+	info, changed = h.observe(2, 2*time.Second, []data.TranscodeAttemptInfo{o1, o2}, false)
+	assert.False(changed)
+	assert.InDelta(1.0/6, info.DroppedRate, 1e-9)
+	assert.InDelta(1.0/6, info.VerificationFailureRate, 1e-9)
+	assert.InDelta(1.0/6, info.SwapRate, 1e-9)
+	assert.InDelta(0.6, info.RealtimeRatio, 1e-9)
+	assert.Equal(streamHealthy, info.State)
+
+	// the stream degrades as segments keep being dropped
+	for !changed {
+		info, changed = h.observe(2, 4*time.Second, []data.TranscodeAttemptInfo{o2}, true)
+	}
+	assert.Equal(streamDegraded, info.State)
+	assert.Less(info.Score, float64(healthScoreDegraded))
+	for !changed || info.State == streamDegraded {
+		info, changed = h.observe(2, 4*time.Second, []data.TranscodeAttemptInfo{o2}, true)
+	}
+	assert.Equal(streamUnhealthy, info.State)
+	assert.Less(info.Score, float64(healthScoreUnhealthy))
+
+	// only the most recent segments count
+	for i := 0; i < streamHealthWindow; i++ {
+		info, changed = h.observe(2, time.Second, []data.TranscodeAttemptInfo{o2}, false)
+	}
+	assert.Len(h.segments, streamHealthWindow)
+	assert.Equal(streamHealthInfo{Score: 100, State: streamHealthy, RealtimeRatio: 0.5}, info)
+
+	// nil health is a no-op
+	var nilHealth *streamHealth
+	nilHealth.verificationFailed()
+	info, changed = nilHealth.observe(2, time.Second, nil, true)
+	assert.False(changed)
+	assert.Equal(100.0, info.Score)
+}
+
+func TestStreamHealthEvent(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	assert.Equal("stream_health.score.f.ext", newStreamHealthEventKey("foo", "ext"))
+
+	evt := newStreamHealthEvent("ext", streamHealthInfo{Score: 42.5, State: streamUnhealthy}, true)
+	b, err := broker.ValidateEvent(evt)
+	require.Nil(err)
+	var decoded map[string]interface{}
+	require.Nil(json.Unmarshal(b, &decoded))
+	assert.Equal("stream_health.v1", decoded["schema"])
+	assert.Equal("stream_health", decoded["type"])
+	assert.Equal("ext", decoded["streamId"])
+	assert.Equal(true, decoded["ended"])
+	assert.Equal(map[string]interface{}{
+		"score":                   42.5,
+		"state":                   "unhealthy",
+		"droppedRate":             0.0,
+		"realtimeRatio":           0.0,
+		"verificationFailureRate": 0.0,
+		"swapRate":                0.0,
+	}, decoded["health"])
+}
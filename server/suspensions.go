@@ -2,6 +2,7 @@ package server
 
 import (
 	"sync"
+	"time"
 )
 
 // suspender is a list that keep track of suspender orchestrators
@@ -10,12 +11,15 @@ type suspender struct {
 	mu    sync.Mutex
 	list  map[string]int // list of orchestrator => refresh count at which the orchestrator is no longer suspended
 	count int
+	// orchestrator => time until which the orchestrator asked not to be sent new sessions
+	until map[string]time.Time
 }
 
 // newSuspender returns the pointer to a new Suspender instance
 func newSuspender() *suspender {
 	return &suspender{
-		list: make(map[string]int),
+		list:  make(map[string]int),
+		until: make(map[string]time.Time),
 	}
 }
 
@@ -26,6 +30,16 @@ func (s *suspender) suspend(orch string, penalty int) {
 	s.list[orch] += penalty
 }
 
+// suspendUntil suspends an orchestrator until t whatever the number of refreshes, e.g. when it asked to be
+// retried later
+func (s *suspender) suspendUntil(orch string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.After(s.until[orch]) {
+		s.until[orch] = t
+	}
+}
+
 // Suspended returns a non-zero value if the orchestrator is suspended
 // 'orch' is the service URI of the orchestrator
 // The value returned is the suspension penalty associated with the orchestrator whereby lower is better
@@ -35,7 +49,15 @@ func (s *suspender) Suspended(orch string) int {
 	if s.list[orch] < s.count {
 		delete(s.list, orch)
 	}
-	return s.list[orch]
+	penalty := s.list[orch]
+	if until, ok := s.until[orch]; ok {
+		if !time.Now().Before(until) {
+			delete(s.until, orch)
+		} else if penalty == 0 {
+			penalty = 1
+		}
+	}
+	return penalty
 }
 
 // signalRefresh increases Suspender.count
@@ -2,6 +2,7 @@ package server
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -22,3 +23,25 @@ func TestSuspender(t *testing.T) {
 	s.signalRefresh()
 	assert.Equal(s.count, 12)
 }
+
+func TestSuspender_SuspendUntil(t *testing.T) {
+	assert := assert.New(t)
+	s := newSuspender()
+
+	// the orchestrator is suspended until the time whatever the number of refreshes
+	s.suspendUntil("foo", time.Now().Add(time.Hour))
+	s.count = 10
+	assert.Equal(1, s.Suspended("foo"))
+	s.suspend("foo", 15)
+	assert.Equal(15, s.Suspended("foo"))
+
+	// an earlier time doesn't shorten the suspension
+	s.suspendUntil("bar", time.Now().Add(time.Hour))
+	s.suspendUntil("bar", time.Now().Add(-time.Hour))
+	assert.Equal(1, s.Suspended("bar"))
+
+	s.until["bar"] = time.Now().Add(-time.Second)
+	assert.Equal(0, s.Suspended("bar"))
+	_, ok := s.until["bar"]
+	assert.False(ok)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/livepeer/go-livepeer/common"
+)
+
+// TxCostReport is the gas cost of the transactions sent by the node, summed up per contract method and/or period
+type TxCostReport struct {
+	Method       *string    `json:"method,omitempty"`
+	Start        *time.Time `json:"start,omitempty"`
+	Transactions int64      `json:"transactions"`
+	Failed       int64      `json:"failed"`
+	GasUsed      uint64     `json:"gasUsed"`
+	Cost         *big.Int   `json:"cost"`
+}
+
+// Grouping by contract method supported by the transaction cost report, in addition to groupByHour and groupByDay
+const groupByMethod = "method"
+
+type txCostKey struct {
+	method string
+	start  time.Time
+}
+
+// groupTxCosts sums up the gas costs of the transactions with the same values for the groupBy fields
+func groupTxCosts(txs []*common.DBTransaction, groupBy []string) ([]*TxCostReport, error) {
+	var byMethod bool
+	var period time.Duration
+	for _, g := range groupBy {
+		switch g {
+		case groupByMethod:
+			byMethod = true
+		case groupByHour:
+			period = time.Hour
+		case groupByDay:
+			period = 24 * time.Hour
+		default:
+			return nil, fmt.Errorf("invalid groupBy=%v", g)
+		}
+	}
+
+	reports := []*TxCostReport{}
+	index := make(map[txCostKey]*TxCostReport)
+	for _, tx := range txs {
+		var key txCostKey
+		report := &TxCostReport{Cost: big.NewInt(0)}
+		if byMethod {
+			method := tx.Method
+			key.method = method
+			report.Method = &method
+		}
+		if period > 0 {
+			start := tx.Time.UTC().Truncate(period)
+			key.start = start
+			report.Start = &start
+		}
+		if r, ok := index[key]; ok {
+			report = r
+		} else {
+			index[key] = report
+			reports = append(reports, report)
+		}
+
+		report.Transactions++
+		if tx.Failed {
+			report.Failed++
+		}
+		report.GasUsed += tx.GasUsed
+		report.Cost.Add(report.Cost, tx.Cost)
+	}
+	return reports, nil
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupTxCosts(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	hour := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	txs := []*common.DBTransaction{
+		{Method: "redeemWinningTicket", GasUsed: 100, Cost: big.NewInt(1000), Time: hour.Add(time.Minute)},
+		{Method: "reward", GasUsed: 200, Cost: big.NewInt(2000), Time: hour.Add(2 * time.Minute)},
+		{Method: "redeemWinningTicket", GasUsed: 50, Cost: big.NewInt(500), Failed: true, Time: hour.Add(time.Hour)},
+	}
+
+	// no grouping sums up all transactions
+	report, err := groupTxCosts(txs, nil)
+	require.Nil(err)
+	require.Len(report, 1)
+	assert.Nil(report[0].Method)
+	assert.Nil(report[0].Start)
+	assert.Equal(int64(3), report[0].Transactions)
+	assert.Equal(int64(1), report[0].Failed)
+	assert.Equal(uint64(350), report[0].GasUsed)
+	assert.Equal(big.NewInt(3500), report[0].Cost)
+
+	report, err = groupTxCosts(txs, []string{"method"})
+	require.Nil(err)
+	require.Len(report, 2)
+	assert.Equal("redeemWinningTicket", *report[0].Method)
+	assert.Equal(int64(2), report[0].Transactions)
+	assert.Equal(big.NewInt(1500), report[0].Cost)
+	assert.Equal("reward", *report[1].Method)
+	assert.Equal(big.NewInt(2000), report[1].Cost)
+
+	report, err = groupTxCosts(txs, []string{"method", "hour"})
+	require.Nil(err)
+	require.Len(report, 3)
+	assert.Equal(hour, *report[0].Start)
+	assert.Equal(hour.Add(time.Hour), *report[2].Start)
+	assert.Equal(int64(1), report[2].Failed)
+
+	report, err = groupTxCosts(txs, []string{"day"})
+	require.Nil(err)
+	require.Len(report, 1)
+	assert.Equal(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), *report[0].Start)
+
+	// no transactions
+	report, err = groupTxCosts(nil, nil)
+	require.Nil(err)
+	assert.Empty(report)
+
+	_, err = groupTxCosts(txs, []string{"orchestrator"})
+	assert.EqualError(err, "invalid groupBy=orchestrator")
+}
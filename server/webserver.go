@@ -4,10 +4,8 @@ import (
 	"flag"
 	"net/http"
 
-	// pprof adds handlers to default mux via `init()`
-	_ "net/http/pprof"
-
 	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/core"
 	"github.com/livepeer/go-livepeer/monitor"
 )
 
@@ -25,17 +23,14 @@ func (s *LivepeerServer) StartCliWebserver(srv *http.Server) {
 func (s *LivepeerServer) setOnChainConfig() {}
 
 func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
-	// Override default mux because pprof only uses the default mux
-	// We really don't want to accidentally pull pprof into other listeners.
-	// Pprof, like the CLI, is a strictly private API!
-	mux := http.DefaultServeMux
-	http.DefaultServeMux = http.NewServeMux()
+	mux := http.NewServeMux()
 
 	client := s.LivepeerNode.Eth
 	db := s.LivepeerNode.Database
 
 	// Status
 	mux.Handle("/status", s.statusHandler())
+	mux.Handle("/healthz", healthzHandler())
 	mux.Handle("/streamID", s.streamIdHandler())
 	mux.Handle("/manifestID", s.manifestIdHandler())
 	mux.Handle("/localStreams", localStreamsHandler())
@@ -49,6 +44,7 @@ func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
 	mux.Handle("/setBroadcastConfig", mustHaveFormParams(setBroadcastConfigHandler()))
 	mux.Handle("/getBroadcastConfig", getBroadcastConfigHandler())
 	mux.Handle("/getAvailableTranscodingOptions", getAvailableTranscodingOptionsHandler())
+	mux.Handle("/refreshOrchestratorPool", refreshOrchestratorPoolHandler())
 
 	// Rounds
 	mux.Handle("/currentRound", currentRoundHandler(client))
@@ -61,6 +57,30 @@ func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
 	mux.Handle("/setMaxFaceValue", mustHaveFormParams(s.setMaxFaceValueHandler(), "maxfacevalue"))
 	mux.Handle("/setPriceForBroadcaster", mustHaveFormParams(s.setPriceForBroadcaster(), "pricePerUnit", "pixelsPerUnit", "broadcasterEthAddr"))
 
+	// Broadcaster access
+	mux.Handle("/broadcasterAccessList", s.broadcasterAccessListHandler())
+	mux.Handle("/allowBroadcaster", mustHaveFormParams(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Allow), "broadcasterEthAddr"))
+	mux.Handle("/disallowBroadcaster", mustHaveFormParams(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Disallow), "broadcasterEthAddr"))
+	mux.Handle("/blockBroadcaster", mustHaveFormParams(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Block), "broadcasterEthAddr"))
+	mux.Handle("/unblockBroadcaster", mustHaveFormParams(s.updateBroadcasterAccessHandler((*core.BroadcasterAccessList).Unblock), "broadcasterEthAddr"))
+	mux.Handle("/clearBroadcasterAllowlist", s.clearBroadcasterAllowlistHandler())
+
+	// Drain mode
+	mux.Handle("/draining", s.drainingHandler())
+	mux.Handle("/setDraining", mustHaveFormParams(s.setDrainingHandler(), "draining"))
+
+	// Earnings
+	mux.Handle("/earnings", s.earningsHandler())
+	mux.Handle("/spending", s.spendingHandler())
+	mux.Handle("/txCosts", s.txCostsHandler())
+
+	// Recordings
+	mux.Handle("/recordingArchives", recordingArchivesHandler())
+	mux.Handle("/streamStorageUsage", streamStorageUsageHandler())
+
+	// Orchestrator reputation
+	mux.Handle("/orchestratorScores", orchestratorScoresHandler())
+
 	// Bond, withdraw, reward
 	mux.Handle("/bond", mustHaveFormParams(bondHandler(client), "amount", "toAddr"))
 	mux.Handle("/rebond", mustHaveFormParams(rebondHandler(client), "unbondingLockId"))
@@ -74,6 +94,12 @@ func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
 	mux.Handle("/registeredOrchestrators", registeredOrchestratorsHandler(client, db))
 	mux.Handle("/reward", rewardHandler(client))
 
+	// Authenticated JSON API for bond, unbond, rebond and withdrawals
+	registerDelegationAPI(mux, client, db)
+
+	// Versioned JSON management API, and its OpenAPI document
+	mux.Handle(APIv2BasePath+"/", s.newAPIv2(client))
+
 	// Protocol parameters
 	mux.Handle("/protocolParameters", protocolParametersHandler(client, db))
 
@@ -101,11 +127,17 @@ func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
 	mux.Handle("/withdraw", withdrawHandler(client))
 	mux.Handle("/senderInfo", senderInfoHandler(client))
 	mux.Handle("/ticketBrokerParams", ticketBrokerParamsHandler(client))
+	mux.Handle("/redemptions", s.redemptionsHandler())
+	mux.Handle("/redemptionSenders", s.redemptionSendersHandler())
+	mux.Handle("/blockedSenders", s.blockedSendersHandler())
 
 	// Debug, Log Level
 	mux.Handle("/setLogLevel", mustHaveFormParams(setLogLevelHandler(), "loglevel"))
 	mux.Handle("/getLogLevel", getLogLevelHandler())
 	mux.Handle("/debug", s.debugHandler())
+	mux.Handle("/debug/streams", s.streamsDebugHandler())
+	// Authenticated pprof profiles and runtime diagnostics
+	registerDebugAPI(mux)
 
 	// Metrics
 	if monitor.Enabled {
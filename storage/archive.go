@@ -0,0 +1,373 @@
+package storage
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-tools/drivers"
+)
+
+// RecordArchiver archives the completed recordings of the node, nil when archiving is disabled
+var RecordArchiver *Archiver
+
+// States of the archive of a recording
+const (
+	ArchiveStaging   = "staging"
+	ArchiveQueued    = "queued"
+	ArchiveUploading = "uploading"
+	ArchiveUploaded  = "uploaded"
+	ArchiveFailed    = "failed"
+)
+
+const (
+	// archiveQueueSize is the number of completed recordings that can wait for their upload
+	archiveQueueSize = 1024
+	// archiveMaxAttempts is the number of times the upload of a recording is tried
+	archiveMaxAttempts = 3
+	// archiveStatusTTL is how long the status of a finished archive is kept
+	archiveStatusTTL = 7 * 24 * time.Hour
+	// archiveUploadTimeout is the timeout of the upload of a recording
+	archiveUploadTimeout = 30 * time.Minute
+	// archiveStatusTimeout is the timeout of the deal status requests
+	archiveStatusTimeout = 10 * time.Second
+	// stagingFilePrefix is the prefix of the temporary files written while staging
+	stagingFilePrefix = ".staging-"
+)
+
+// archiveRetryBackoff is the delay before the first retry of an upload, doubled after each attempt
+var archiveRetryBackoff = 30 * time.Second
+
+// ArchiveDeal is a Filecoin storage deal of an archive
+type ArchiveDeal struct {
+	ID       int64  `json:"id"`
+	Provider string `json:"provider"`
+	Status   string `json:"status"`
+}
+
+// ArchiveStatus is the status of the archive of a recording
+type ArchiveStatus struct {
+	// Name is the path of the recording in the record store, <manifestID>/<nodeID>
+	Name      string        `json:"name"`
+	State     string        `json:"state"`
+	Files     int           `json:"files"`
+	Bytes     int64         `json:"bytes"`
+	CID       string        `json:"cid,omitempty"`
+	Attempts  int           `json:"attempts"`
+	Error     string        `json:"error,omitempty"`
+	Deals     []ArchiveDeal `json:"deals,omitempty"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+
+	// ref is the reference of the upload used by the service to report its deals
+	ref string
+}
+
+// ArchiveClient uploads archives to a Filecoin storage service
+type ArchiveClient interface {
+	// Upload uploads the data and returns its CID and the reference of the upload in the service
+	Upload(ctx context.Context, name string, data io.Reader, size int64) (cid string, ref string, err error)
+	// Deals returns the storage deals of an upload
+	Deals(ctx context.Context, cid, ref string) ([]ArchiveDeal, error)
+	Description() string
+}
+
+// Archiver stages the recordings saved to the record store in a local directory and uploads them, once completed,
+// to a Filecoin storage service as a tar file
+type Archiver struct {
+	client ArchiveClient
+	dir    string
+	// delay is the time to wait after the end of a stream for the pending saves to its recording
+	delay time.Duration
+
+	mu       sync.Mutex
+	statuses map[string]*ArchiveStatus
+	queue    chan string
+}
+
+// NewArchiver creates an archiver staging recordings in dir and starts its upload worker
+func NewArchiver(client ArchiveClient, dir string, delay time.Duration) (*Archiver, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	a := &Archiver{
+		client:   client,
+		dir:      dir,
+		delay:    delay,
+		statuses: make(map[string]*ArchiveStatus),
+		queue:    make(chan string, archiveQueueSize),
+	}
+	go a.uploadLoop()
+	return a, nil
+}
+
+// NewSession returns a session saving to the record session and staging the saved files for the archive of the
+// recording at name
+func (a *Archiver) NewSession(record drivers.OSSession, name string) *ArchiveSession {
+	a.mu.Lock()
+	a.statuses[name] = &ArchiveStatus{Name: name, State: ArchiveStaging, UpdatedAt: time.Now()}
+	a.mu.Unlock()
+	return &ArchiveSession{OSSession: record, archiver: a, name: name}
+}
+
+// Complete queues the upload of the recording at name once the pending saves are done
+func (a *Archiver) Complete(name string) {
+	a.update(name, func(status *ArchiveStatus) {
+		status.State = ArchiveQueued
+	})
+	time.AfterFunc(a.delay, func() {
+		select {
+		case a.queue <- name:
+		default:
+			a.update(name, func(status *ArchiveStatus) {
+				status.State = ArchiveFailed
+				status.Error = "archive queue is full"
+			})
+			glog.Errorf("Error archiving recording name=%s err=%q", name, "archive queue is full")
+		}
+	})
+}
+
+// Status returns the status of the archive of a recording, with the current state of its storage deals
+func (a *Archiver) Status(ctx context.Context, name string) (ArchiveStatus, bool) {
+	a.mu.Lock()
+	s, ok := a.statuses[name]
+	var status ArchiveStatus
+	if ok {
+		status = *s
+	}
+	a.mu.Unlock()
+	if !ok || status.State != ArchiveUploaded {
+		return status, ok
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, archiveStatusTimeout)
+	defer cancel()
+	deals, err := a.client.Deals(ctx, status.CID, status.ref)
+	if err != nil {
+		glog.Errorf("Error getting archive deals name=%s cid=%s err=%q", name, status.CID, err)
+		return status, true
+	}
+	status.Deals = deals
+	return status, true
+}
+
+// Statuses returns the status of all the archives sorted by name, without the deals of the uploaded ones
+func (a *Archiver) Statuses() []ArchiveStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	statuses := make([]ArchiveStatus, 0, len(a.statuses))
+	for name, status := range a.statuses {
+		finished := status.State == ArchiveUploaded || status.State == ArchiveFailed
+		if finished && time.Since(status.UpdatedAt) > archiveStatusTTL {
+			delete(a.statuses, name)
+			continue
+		}
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (a *Archiver) update(name string, f func(status *ArchiveStatus)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	status, ok := a.statuses[name]
+	if !ok {
+		status = &ArchiveStatus{Name: name}
+		a.statuses[name] = status
+	}
+	f(status)
+	status.UpdatedAt = time.Now()
+}
+
+// stagingPath returns the path of a file of a recording in the staging directory
+func (a *Archiver) stagingPath(name, file string) (string, error) {
+	p := path.Join(name, file)
+	for _, elem := range strings.Split(name+"/"+file, "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("invalid archive file name=%s", p)
+		}
+	}
+	if p == "" || path.IsAbs(p) {
+		return "", fmt.Errorf("invalid archive file name=%s", p)
+	}
+	return filepath.Join(a.dir, filepath.FromSlash(p)), nil
+}
+
+func (a *Archiver) uploadLoop() {
+	for name := range a.queue {
+		a.upload(name)
+	}
+}
+
+// upload uploads a staged recording, trying again with a backoff on errors
+func (a *Archiver) upload(name string) {
+	backoff := archiveRetryBackoff
+	var err error
+	for attempt := 1; attempt <= archiveMaxAttempts; attempt++ {
+		a.update(name, func(status *ArchiveStatus) {
+			status.State = ArchiveUploading
+			status.Attempts = attempt
+		})
+		var cid, ref string
+		if cid, ref, err = a.uploadOnce(name); err == nil {
+			a.update(name, func(status *ArchiveStatus) {
+				status.State = ArchiveUploaded
+				status.CID = cid
+				status.ref = ref
+				status.Error = ""
+			})
+			glog.Infof("Archived recording name=%s cid=%s", name, cid)
+			stagingDir, _ := a.stagingPath(name, "")
+			if err := os.RemoveAll(stagingDir); err != nil {
+				glog.Errorf("Error removing staged recording name=%s err=%q", name, err)
+			}
+			return
+		}
+		glog.Errorf("Error archiving recording name=%s attempt=%d err=%q", name, attempt, err)
+		a.update(name, func(status *ArchiveStatus) {
+			status.Error = err.Error()
+		})
+		if attempt < archiveMaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	// The staged recording is kept to be archived manually
+	a.update(name, func(status *ArchiveStatus) {
+		status.State = ArchiveFailed
+	})
+}
+
+func (a *Archiver) uploadOnce(name string) (string, string, error) {
+	stagingDir, err := a.stagingPath(name, "")
+	if err != nil {
+		return "", "", err
+	}
+	file, err := ioutil.TempFile(a.dir, "archive-*.tar")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	files, bytes, err := writeTar(file, stagingDir)
+	if err != nil {
+		return "", "", err
+	}
+	if files == 0 {
+		return "", "", errors.New("no recorded files to archive")
+	}
+	a.update(name, func(status *ArchiveStatus) {
+		status.Files = files
+		status.Bytes = bytes
+	})
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), archiveUploadTimeout)
+	defer cancel()
+	archiveName := strings.ReplaceAll(name, "/", "_") + ".tar"
+	return a.client.Upload(ctx, archiveName, file, size)
+}
+
+// writeTar writes the files of dir in a tar archive and returns the number of files and their total size
+func writeTar(w io.Writer, dir string) (int, int64, error) {
+	tw := tar.NewWriter(w)
+	var files int
+	var bytes int64
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		// Skip the files that are being staged
+		if err != nil || info.IsDir() || strings.HasPrefix(info.Name(), stagingFilePrefix) {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		n, err := io.Copy(tw, f)
+		files++
+		bytes += n
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return files, bytes, tw.Close()
+}
+
+// ArchiveSession is a record session staging the saved files for the archive of the recording
+type ArchiveSession struct {
+	drivers.OSSession
+	archiver *Archiver
+	name     string
+}
+
+func (sess *ArchiveSession) unwrap() drivers.OSSession {
+	return sess.OSSession
+}
+
+// SaveData saves the data to the record session and stages it. Errors of the staging are only logged, since the
+// data was saved to the record store.
+func (sess *ArchiveSession) SaveData(ctx context.Context, name string, data io.Reader, meta map[string]string, timeout time.Duration) (string, error) {
+	stagingPath, err := sess.archiver.stagingPath(sess.name, name)
+	if err != nil {
+		return "", err
+	}
+	var staged *os.File
+	if err = os.MkdirAll(filepath.Dir(stagingPath), 0755); err == nil {
+		staged, err = ioutil.TempFile(filepath.Dir(stagingPath), stagingFilePrefix+"*")
+	}
+	if err != nil {
+		glog.Errorf("Error staging recorded file for archive name=%s err=%q", path.Join(sess.name, name), err)
+		return sess.OSSession.SaveData(ctx, name, data, meta, timeout)
+	}
+	defer os.Remove(staged.Name())
+	defer staged.Close()
+
+	uri, err := sess.OSSession.SaveData(ctx, name, io.TeeReader(data, staged), meta, timeout)
+	if err != nil {
+		return "", err
+	}
+	// Files that are saved again, such as the playlists, replace the staged ones
+	if err := staged.Close(); err == nil {
+		err = os.Rename(staged.Name(), stagingPath)
+	}
+	if err != nil {
+		glog.Errorf("Error staging recorded file for archive name=%s err=%q", path.Join(sess.name, name), err)
+	}
+	return uri, nil
+}
+
+// Complete queues the upload of the recording
+func (sess *ArchiveSession) Complete() {
+	sess.archiver.Complete(sess.name)
+}
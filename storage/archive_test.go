@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubArchiveClient struct {
+	mu      sync.Mutex
+	fails   int
+	uploads map[string][]byte
+}
+
+func (c *stubArchiveClient) Upload(ctx context.Context, name string, data io.Reader, size int64) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fails > 0 {
+		c.fails--
+		return "", "", errors.New("upload error")
+	}
+	b, err := ioutil.ReadAll(data)
+	if err != nil {
+		return "", "", err
+	}
+	if int64(len(b)) != size {
+		return "", "", errors.New("wrong size")
+	}
+	c.uploads[name] = b
+	return "bafy" + name, "ref" + name, nil
+}
+
+func (c *stubArchiveClient) Deals(ctx context.Context, cid, ref string) ([]ArchiveDeal, error) {
+	return []ArchiveDeal{{ID: 1, Provider: "f01000", Status: ref}}, nil
+}
+
+func (c *stubArchiveClient) Description() string {
+	return "stub"
+}
+
+func (c *stubArchiveClient) upload(name string) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.uploads[name]
+}
+
+func untar(t *testing.T, data []byte) map[string]string {
+	files := make(map[string]string)
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.Nil(t, err)
+		b, err := ioutil.ReadAll(tr)
+		require.Nil(t, err)
+		files[header.Name] = string(b)
+	}
+}
+
+func waitArchive(t *testing.T, a *Archiver, name string, state string) ArchiveStatus {
+	var status ArchiveStatus
+	assert.Eventually(t, func() bool {
+		status, _ = a.Status(context.Background(), name)
+		return status.State == state
+	}, 5*time.Second, 10*time.Millisecond)
+	return status
+}
+
+func TestArchiver_Archive(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func(b time.Duration) { archiveRetryBackoff = b }(archiveRetryBackoff)
+	archiveRetryBackoff = time.Millisecond
+
+	client := &stubArchiveClient{fails: 1, uploads: make(map[string][]byte)}
+	dir := t.TempDir()
+	a, err := NewArchiver(client, dir, 0)
+	require.Nil(err)
+
+	record := drivers.NewMemoryDriver(nil).NewSession("mid/node")
+	sess := a.NewSession(record, "mid/node")
+	ctx := context.Background()
+	_, err = sess.SaveData(ctx, "source/1.ts", bytes.NewReader([]byte("seg1")), nil, 0)
+	require.Nil(err)
+	_, err = sess.SaveData(ctx, "playlist_1.json", bytes.NewReader([]byte("v1")), nil, 0)
+	require.Nil(err)
+	_, err = sess.SaveData(ctx, "playlist_1.json", bytes.NewReader([]byte("v2")), nil, 0)
+	require.Nil(err)
+	_, err = sess.SaveData(ctx, "../escape.ts", bytes.NewReader([]byte("data")), nil, 0)
+	assert.EqualError(err, "invalid archive file name=mid/escape.ts")
+
+	// The data is saved to the record store and staged
+	res, err := record.ReadData(ctx, "mid/node/source/1.ts")
+	require.Nil(err)
+	data, _ := ioutil.ReadAll(res.Body)
+	assert.Equal("seg1", string(data))
+	staged, err := ioutil.ReadFile(filepath.Join(dir, "mid", "node", "playlist_1.json"))
+	require.Nil(err)
+	assert.Equal("v2", string(staged))
+	status, ok := a.Status(ctx, "mid/node")
+	assert.True(ok)
+	assert.Equal(ArchiveStaging, status.State)
+
+	// The recording is uploaded once completed, after a failed attempt
+	sess.Complete()
+	status = waitArchive(t, a, "mid/node", ArchiveUploaded)
+	assert.Equal("bafymid_node.tar", status.CID)
+	assert.Equal(2, status.Attempts)
+	assert.Equal(2, status.Files)
+	assert.Equal(int64(6), status.Bytes)
+	assert.Empty(status.Error)
+	assert.Equal([]ArchiveDeal{{ID: 1, Provider: "f01000", Status: "refmid_node.tar"}}, status.Deals)
+	assert.Equal(map[string]string{"source/1.ts": "seg1", "playlist_1.json": "v2"}, untar(t, client.upload("mid_node.tar")))
+	_, err = os.Stat(filepath.Join(dir, "mid", "node"))
+	assert.True(os.IsNotExist(err))
+
+	// Failed archives keep their staged files
+	client.fails = archiveMaxAttempts
+	sess = a.NewSession(record, "mid2/node")
+	_, err = sess.SaveData(ctx, "source/1.ts", bytes.NewReader([]byte("seg1")), nil, 0)
+	require.Nil(err)
+	sess.Complete()
+	status = waitArchive(t, a, "mid2/node", ArchiveFailed)
+	assert.Equal("upload error", status.Error)
+	assert.Equal(archiveMaxAttempts, status.Attempts)
+	_, err = os.Stat(filepath.Join(dir, "mid2", "node", "source", "1.ts"))
+	assert.Nil(err)
+
+	statuses := a.Statuses()
+	require.Len(statuses, 2)
+	assert.Equal("mid/node", statuses[0].Name)
+	assert.Nil(statuses[0].Deals)
+	assert.Equal("mid2/node", statuses[1].Name)
+	_, ok = a.Status(ctx, "unknown")
+	assert.False(ok)
+}
+
+func TestParseArchiveURL(t *testing.T) {
+	assert := assert.New(t)
+
+	client, err := ParseArchiveURL("web3storage+https://token@api.web3.storage")
+	assert.Nil(err)
+	assert.Equal(&web3StorageClient{endpoint: "https://api.web3.storage", token: "token", client: &http.Client{}}, client)
+	client, err = ParseArchiveURL("estuary://key@api.estuary.tech/")
+	assert.Nil(err)
+	assert.Equal(&estuaryClient{endpoint: "https://api.estuary.tech", token: "key", client: &http.Client{}}, client)
+
+	_, err = ParseArchiveURL("web3storage+https://api.web3.storage")
+	assert.EqualError(err, "API token is required in archive URL")
+	_, err = ParseArchiveURL("s3://key@us-east-1")
+	assert.EqualError(err, "unknown archive service=s3")
+	_, err = ParseArchiveURL("estuary+ftp://key@api.estuary.tech")
+	assert.EqualError(err, "invalid archive URL scheme=estuary+ftp")
+}
+
+func TestArchiveClients(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var uploaded []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"invalid token"}`))
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/upload":
+			body, _ := ioutil.ReadAll(r.Body)
+			uploaded = append(uploaded, r.Header.Get("X-Name")+":"+string(body))
+			w.Write([]byte(`{"cid":"bafyw3s"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/status/bafyw3s":
+			w.Write([]byte(`{"cid":"bafyw3s","deals":[{"dealId":10,"storageProvider":"f01001","status":"Active"},{"dealId":0,"storageProvider":"f01002","status":"Queued"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/content/add":
+			file, header, err := r.FormFile("data")
+			require.Nil(err)
+			body, _ := ioutil.ReadAll(file)
+			uploaded = append(uploaded, header.Filename+":"+string(body))
+			json.NewEncoder(w).Encode(map[string]interface{}{"cid": "bafyest", "estuaryId": 42})
+		case r.Method == http.MethodGet && r.URL.Path == "/content/status/42":
+			w.Write([]byte(`{"deals":[{"deal":{"dealId":11,"miner":"f01003"},"onChainState":{"sectorStartEpoch":100}},{"deal":{"dealId":12,"miner":"f01004"}},{"deal":{"miner":"f01005","failed":true}},{"deal":{"miner":"f01006"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "http://")
+	ctx := context.Background()
+
+	w3s, err := ParseArchiveURL("web3storage+http://token@" + host)
+	require.Nil(err)
+	cid, ref, err := w3s.Upload(ctx, "mid_node.tar", strings.NewReader("tar"), 3)
+	require.Nil(err)
+	assert.Equal("bafyw3s", cid)
+	assert.Equal("bafyw3s", ref)
+	deals, err := w3s.Deals(ctx, cid, ref)
+	require.Nil(err)
+	assert.Equal([]ArchiveDeal{{ID: 10, Provider: "f01001", Status: "active"}, {Provider: "f01002", Status: "queued"}}, deals)
+
+	est, err := ParseArchiveURL("estuary+http://token@" + host)
+	require.Nil(err)
+	cid, ref, err = est.Upload(ctx, "mid_node.tar", strings.NewReader("tar"), 3)
+	require.Nil(err)
+	assert.Equal("bafyest", cid)
+	assert.Equal("42", ref)
+	deals, err = est.Deals(ctx, cid, ref)
+	require.Nil(err)
+	sort.Slice(deals, func(i, j int) bool { return deals[i].Provider < deals[j].Provider })
+	assert.Equal([]ArchiveDeal{
+		{ID: 11, Provider: "f01003", Status: "active"},
+		{ID: 12, Provider: "f01004", Status: "published"},
+		{Provider: "f01005", Status: "failed"},
+		{Provider: "f01006", Status: "proposed"},
+	}, deals)
+	assert.Equal([]string{"mid_node.tar:tar", "mid_node.tar:tar"}, uploaded)
+
+	// Errors of the services are returned
+	bad, err := ParseArchiveURL("web3storage+http://bad@" + host)
+	require.Nil(err)
+	_, _, err = bad.Upload(ctx, "mid_node.tar", strings.NewReader("tar"), 3)
+	assert.EqualError(err, `web3.storage upload failed status=401 Unauthorized body="{\"message\":\"invalid token\"}"`)
+	bad, err = ParseArchiveURL("estuary+http://bad@" + host)
+	require.Nil(err)
+	_, err = bad.Deals(ctx, "bafyest", "42")
+	assert.EqualError(err, `Estuary status failed: status=401 Unauthorized body="{\"message\":\"invalid token\"}"`)
+}
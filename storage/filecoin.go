@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseArchiveURL returns the client of the Filecoin storage service of an archive URL:
+//
+//	web3storage+https://<API token>@api.web3.storage
+//	estuary+https://<API key>@api.estuary.tech
+func ParseArchiveURL(input string) (ArchiveClient, error) {
+	u, err := url.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	service, scheme := u.Scheme, "https"
+	if i := strings.Index(u.Scheme, "+"); i >= 0 {
+		service, scheme = u.Scheme[:i], u.Scheme[i+1:]
+	}
+	if scheme != "http" && scheme != "https" {
+		return nil, fmt.Errorf("invalid archive URL scheme=%s", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("archive API host not found in URL")
+	}
+	token := u.User.Username()
+	if token == "" {
+		return nil, errors.New("API token is required in archive URL")
+	}
+	endpoint := (&url.URL{Scheme: scheme, Host: u.Host, Path: strings.TrimSuffix(u.Path, "/")}).String()
+	switch service {
+	case "web3storage":
+		return &web3StorageClient{endpoint: endpoint, token: token, client: &http.Client{}}, nil
+	case "estuary":
+		return &estuaryClient{endpoint: endpoint, token: token, client: &http.Client{}}, nil
+	}
+	return nil, fmt.Errorf("unknown archive service=%s", service)
+}
+
+// doJSON sends an authenticated request and decodes the JSON response in res
+func doJSON(ctx context.Context, client *http.Client, method, uri, token string, body io.Reader, size int64, contentType string, res interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
+	if err != nil {
+		return err
+	}
+	if size > 0 {
+		req.ContentLength = size
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("status=%s body=%q", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+// web3StorageClient uploads archives with the web3.storage HTTP API
+type web3StorageClient struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+func (c *web3StorageClient) Description() string {
+	return "web3.storage"
+}
+
+func (c *web3StorageClient) Upload(ctx context.Context, name string, data io.Reader, size int64) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/upload", data)
+	if err != nil {
+		return "", "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/x-tar")
+	req.Header.Set("X-Name", url.QueryEscape(name))
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", "", fmt.Errorf("web3.storage upload failed status=%s body=%q", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var res struct {
+		CID string `json:"cid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", "", fmt.Errorf("invalid web3.storage upload response: %w", err)
+	}
+	if res.CID == "" {
+		return "", "", errors.New("no CID in web3.storage upload response")
+	}
+	return res.CID, res.CID, nil
+}
+
+func (c *web3StorageClient) Deals(ctx context.Context, cid, ref string) ([]ArchiveDeal, error) {
+	var res struct {
+		Deals []struct {
+			DealID          int64  `json:"dealId"`
+			StorageProvider string `json:"storageProvider"`
+			Status          string `json:"status"`
+		} `json:"deals"`
+	}
+	if err := doJSON(ctx, c.client, http.MethodGet, c.endpoint+"/status/"+url.PathEscape(cid), c.token, nil, 0, "", &res); err != nil {
+		return nil, fmt.Errorf("web3.storage status failed: %w", err)
+	}
+	deals := make([]ArchiveDeal, 0, len(res.Deals))
+	for _, d := range res.Deals {
+		deals = append(deals, ArchiveDeal{ID: d.DealID, Provider: d.StorageProvider, Status: strings.ToLower(d.Status)})
+	}
+	return deals, nil
+}
+
+// estuaryClient uploads archives with the Estuary HTTP API
+type estuaryClient struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+func (c *estuaryClient) Description() string {
+	return "Estuary"
+}
+
+func (c *estuaryClient) Upload(ctx context.Context, name string, data io.Reader, size int64) (string, string, error) {
+	// Stream the data in a multipart form, as expected by the content/add endpoint
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("data", name)
+		if err == nil {
+			_, err = io.Copy(part, data)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	var res struct {
+		CID       string `json:"cid"`
+		EstuaryID int64  `json:"estuaryId"`
+	}
+	err := doJSON(ctx, c.client, http.MethodPost, c.endpoint+"/content/add", c.token, pr, 0, mw.FormDataContentType(), &res)
+	// Unblock the writer if the request failed before reading all the data
+	pr.Close()
+	if err != nil {
+		return "", "", fmt.Errorf("Estuary upload failed: %w", err)
+	}
+	if res.CID == "" {
+		return "", "", errors.New("no CID in Estuary upload response")
+	}
+	return res.CID, strconv.FormatInt(res.EstuaryID, 10), nil
+}
+
+func (c *estuaryClient) Deals(ctx context.Context, cid, ref string) ([]ArchiveDeal, error) {
+	var res struct {
+		Deals []struct {
+			Deal struct {
+				DealID int64  `json:"dealId"`
+				Miner  string `json:"miner"`
+				Failed bool   `json:"failed"`
+			} `json:"deal"`
+			OnChainState *struct {
+				SectorStartEpoch int64 `json:"sectorStartEpoch"`
+			} `json:"onChainState"`
+		} `json:"deals"`
+	}
+	if err := doJSON(ctx, c.client, http.MethodGet, c.endpoint+"/content/status/"+url.PathEscape(ref), c.token, nil, 0, "", &res); err != nil {
+		return nil, fmt.Errorf("Estuary status failed: %w", err)
+	}
+	deals := make([]ArchiveDeal, 0, len(res.Deals))
+	for _, d := range res.Deals {
+		status := "proposed"
+		switch {
+		case d.Deal.Failed:
+			status = "failed"
+		case d.OnChainState != nil && d.OnChainState.SectorStartEpoch > 0:
+			status = "active"
+		case d.Deal.DealID > 0:
+			status = "published"
+		}
+		deals = append(deals, ArchiveDeal{ID: d.Deal.DealID, Provider: d.Deal.Miner, Status: status})
+	}
+	return deals, nil
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/livepeer/go-tools/drivers"
+	"google.golang.org/api/option"
+)
+
+const (
+	// gsChunkSizeParam is the size of the chunks of the resumable uploads
+	gsChunkSizeParam = "chunkSize"
+	// gsKMSKeyNameParam is the Cloud KMS key encrypting the saved objects (CMEK)
+	gsKMSKeyNameParam = "kmsKeyName"
+	// gsDefaultSaveTimeout is used on save ops when no custom timeout is provided, as by go-tools
+	gsDefaultSaveTimeout = 10 * time.Second
+)
+
+// GSOptions are the options of the Google Cloud Storage driver that the go-tools driver doesn't support
+type GSOptions struct {
+	// ChunkSize is the size of the chunks of the resumable uploads, 16MB by default. An upload buffers one chunk of
+	// its data.
+	ChunkSize int
+	// KMSKeyName is the resource name of the Cloud KMS key encrypting the objects saved by this node, as in
+	// projects/<project>/locations/<location>/keyRings/<key ring>/cryptoKeys/<key>. The bucket default encryption
+	// applies when empty.
+	KMSKeyName string
+}
+
+// GSOS is a Google Cloud Storage driver streaming the saves of the full API with resumable uploads, so that large
+// recordings aren't limited by the save timeout. The other operations and the saves shared with other nodes are the
+// ones of the go-tools driver.
+type GSOS struct {
+	drivers.OSDriver
+	bucket  string
+	keyData []byte
+	opts    GSOptions
+
+	mu     sync.Mutex
+	client *gcs.Client
+}
+
+var (
+	// gsDrivers are the drivers of the gs URLs, reused so that the streams recorded to the same bucket share their
+	// client
+	gsDrivers   = make(map[string]*GSOS)
+	gsDriversMu sync.Mutex
+)
+
+// parseGSURL returns the driver of a gs URL prepared by PrepareOSURL, gs://<key data>@bucket?chunkSize=8MB
+func parseGSURL(u *url.URL) (drivers.OSDriver, error) {
+	gsDriversMu.Lock()
+	defer gsDriversMu.Unlock()
+	if os, ok := gsDrivers[u.String()]; ok {
+		return os, nil
+	}
+	var opts GSOptions
+	q := u.Query()
+	if chunkSize := q.Get(gsChunkSizeParam); chunkSize != "" {
+		size, err := parseSize(chunkSize)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid %s=%q", gsChunkSizeParam, chunkSize)
+		}
+		opts.ChunkSize = int(size)
+	}
+	opts.KMSKeyName = q.Get(gsKMSKeyNameParam)
+	os, err := NewGSDriver(u.Host, u.User.Username(), opts)
+	if err != nil {
+		return nil, err
+	}
+	gsDrivers[u.String()] = os
+	return os, nil
+}
+
+// NewGSDriver creates a Google Cloud Storage driver using the full API with the JSON key of a service account
+func NewGSDriver(bucket, keyData string, opts GSOptions) (*GSOS, error) {
+	os, err := drivers.NewGoogleDriver(bucket, keyData, true)
+	if err != nil {
+		return nil, err
+	}
+	return &GSOS{OSDriver: os, bucket: bucket, keyData: []byte(keyData), opts: opts}, nil
+}
+
+func (os *GSOS) NewSession(path string) drivers.OSSession {
+	return &gsSession{OSSession: os.OSDriver.NewSession(path), os: os, key: path}
+}
+
+func (os *GSOS) Description() string {
+	return "Google Cloud Storage, with streaming uploads."
+}
+
+// getClient returns the client of the driver, shared by its sessions
+func (os *GSOS) getClient() (*gcs.Client, error) {
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	if os.client != nil {
+		return os.client, nil
+	}
+	client, err := gcs.NewClient(context.Background(), option.WithCredentialsJSON(os.keyData))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating GCP client err=%w", err)
+	}
+	os.client = client
+	return client, nil
+}
+
+type gsSession struct {
+	drivers.OSSession
+	os  *GSOS
+	key string
+}
+
+func (sess *gsSession) OS() drivers.OSDriver {
+	return sess.os
+}
+
+// SaveData streams the data to the object with a resumable upload, in chunks. The timeout applies to the upload of
+// each chunk.
+func (sess *gsSession) SaveData(ctx context.Context, name string, data io.Reader, meta map[string]string, timeout time.Duration) (string, error) {
+	client, err := sess.os.getClient()
+	if err != nil {
+		return "", err
+	}
+	// The same key and URI as the go-tools driver
+	key := sess.key + "/" + name
+	bufData := bufio.NewReaderSize(data, 4096)
+	firstBytes, err := bufData.Peek(512)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	contentType, err := drivers.TypeByExtension(path.Ext(name))
+	if err != nil {
+		contentType = http.DetectContentType(firstBytes)
+	}
+	if timeout == 0 {
+		timeout = gsDefaultSaveTimeout
+	}
+
+	ctx, st := newStreamTimeout(ctx, bufData, timeout)
+	w := client.Bucket(sess.os.bucket).Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	if len(meta) > 0 {
+		w.Metadata = meta
+	}
+	if sess.os.opts.ChunkSize > 0 {
+		w.ChunkSize = sess.os.opts.ChunkSize
+	}
+	if sess.os.opts.KMSKeyName != "" {
+		w.KMSKeyName = sess.os.opts.KMSKeyName
+	}
+	if _, err = io.Copy(w, st); err != nil {
+		// Canceling the upload instead of saving the data read so far
+		st.cancel()
+		w.Close()
+	} else {
+		err = w.Close()
+	}
+	if err = st.stop(err); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%s.storage.googleapis.com/%s", sess.os.bucket, key), nil
+}
+
+// DeleteFile deletes a file saved in the session
+func (sess *gsSession) DeleteFile(ctx context.Context, name string) error {
+	client, err := sess.os.getClient()
+	if err != nil {
+		return err
+	}
+	return client.Bucket(sess.os.bucket).Object(sess.key + "/" + name).Delete(ctx)
+}
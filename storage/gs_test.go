@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gsTestKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err)
+	keyData, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email": "test@project.iam.gserviceaccount.com",
+	})
+	require.Nil(t, err)
+	return string(keyData)
+}
+
+func TestParseOSURL_GS(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	input := (&url.URL{Scheme: "gs", User: url.User(gsTestKey(t)), Host: "bucket", RawQuery: "chunkSize=8MB"}).String()
+	os, err := ParseOSURL(input, true)
+	require.Nil(err)
+	gs := os.(*GSOS)
+	assert.Equal("bucket", gs.bucket)
+	assert.Equal(GSOptions{ChunkSize: 8 << 20}, gs.opts)
+	assert.Equal([]string{"gs"}, os.UriSchemes())
+
+	// The driver of a URL is reused
+	os2, err := ParseOSURL(input, true)
+	require.Nil(err)
+	assert.True(os == os2)
+
+	// The saves shared with other nodes are the ones of go-tools
+	os, err = ParseOSURL(input, false)
+	require.Nil(err)
+	assert.IsType(&drivers.GsOS{}, os)
+
+	_, err = ParseOSURL(strings.Replace(input, "chunkSize=8MB", "chunkSize=big", 1), true)
+	assert.EqualError(err, `invalid chunkSize="big"`)
+
+	// The KMS key is only supported by the saves of the full API
+	input += "&kmsKeyName=" + url.QueryEscape("projects/p/locations/l/keyRings/r/cryptoKeys/k")
+	os, err = ParseOSURL(input, true)
+	require.Nil(err)
+	assert.Equal("projects/p/locations/l/keyRings/r/cryptoKeys/k", os.(*GSOS).opts.KMSKeyName)
+	_, err = ParseOSURL(input, false)
+	assert.EqualError(err, "kmsKeyName is only supported in record stores")
+}
+
+func TestGSOS_SaveData(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var mu sync.Mutex
+	var uploads []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		uploads = append(uploads, r.Method+" "+r.URL.Path+" "+r.URL.Query().Get("uploadType")+" "+r.URL.Query().Get("kmsKeyName"))
+		if !bytes.Contains(body, []byte("recording")) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"bucket":"bucket","name":"mid/rec.mp4"}`))
+	}))
+	defer ts.Close()
+	t.Setenv("STORAGE_EMULATOR_HOST", strings.TrimPrefix(ts.URL, "http://"))
+
+	os, err := NewGSDriver("bucket", gsTestKey(t), GSOptions{KMSKeyName: "projects/p/locations/l/keyRings/r/cryptoKeys/k"})
+	require.Nil(err)
+	sess := os.NewSession("mid")
+	assert.Equal(os, sess.OS())
+	uri, err := sess.SaveData(context.Background(), "rec.mp4", io.MultiReader(strings.NewReader("recording")), nil, 0)
+	require.Nil(err)
+	assert.Equal("https://bucket.storage.googleapis.com/mid/rec.mp4", uri)
+	assert.Equal([]string{"POST /upload/storage/v1/b/bucket/o multipart projects/p/locations/l/keyRings/r/cryptoKeys/k"}, uploads)
+}
@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/monitor"
+	"github.com/livepeer/go-tools/drivers"
+)
+
+// StoreHealth checks the object stores of the node, nil when no object store is configured
+var StoreHealth *HealthChecker
+
+// Steps of the health check of an object store
+const (
+	HealthCheckWrite  = "write"
+	HealthCheckRead   = "read"
+	HealthCheckDelete = "delete"
+)
+
+const (
+	// healthCheckPath is the path of the sessions of the health checks, the file being named after the node so that
+	// the nodes sharing a store don't check each other's files
+	healthCheckPath = "livepeer-healthcheck"
+	// healthCheckTimeout is the timeout of each step of a health check
+	healthCheckTimeout = 10 * time.Second
+	// healthCheckMaxRead is the max size of the file read back, which is much smaller
+	healthCheckMaxRead = 1024
+)
+
+// fileDeleter is implemented by the sessions that can delete the files they save. The files of the health checks of
+// the other stores are overwritten by the next check instead.
+type fileDeleter interface {
+	DeleteFile(ctx context.Context, name string) error
+}
+
+// StoreStatus is the result of the last health check of an object store
+type StoreStatus struct {
+	// Store is object for -objectStore, record for the first store of -recordStore and record_replica_<n> for its
+	// replicas
+	Store       string `json:"store"`
+	Description string `json:"description"`
+	Healthy     bool   `json:"healthy"`
+	// Step is the step that failed, write, read or delete
+	Step          string     `json:"step,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	CheckedAt     time.Time  `json:"checkedAt"`
+	LastHealthyAt *time.Time `json:"lastHealthyAt,omitempty"`
+}
+
+// HealthChecker checks that files can be written to, read from and deleted from the object stores of the node
+type HealthChecker struct {
+	name   string
+	mu     sync.Mutex
+	stores []*storeCheck
+}
+
+type storeCheck struct {
+	os     drivers.OSDriver
+	status StoreStatus
+}
+
+// NewHealthChecker creates a checker writing the file of the health checks of the node ID
+func NewHealthChecker(nodeID string) *HealthChecker {
+	name := nodeID
+	if name == "" {
+		name = "node"
+	}
+	return &HealthChecker{name: name}
+}
+
+// Add adds an object store to check. The store should be the driver of the URL, without retries, so that the checks
+// report the current state of the store.
+func (c *HealthChecker) Add(store string, os drivers.OSDriver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stores = append(c.stores, &storeCheck{os: os, status: StoreStatus{Store: store, Description: os.Description()}})
+}
+
+// Len returns the number of object stores checked
+func (c *HealthChecker) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.stores)
+}
+
+// Start checks the stores at the interval until the context is done
+func (c *HealthChecker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.CheckAll(ctx)
+		}
+	}
+}
+
+// CheckAll checks the stores concurrently and returns whether they are all healthy
+func (c *HealthChecker) CheckAll(ctx context.Context) bool {
+	c.mu.Lock()
+	stores := append([]*storeCheck(nil), c.stores...)
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	results := make([]StoreStatus, len(stores))
+	for i, s := range stores {
+		wg.Add(1)
+		go func(i int, s *storeCheck) {
+			defer wg.Done()
+			results[i] = c.check(ctx, s)
+		}(i, s)
+	}
+	wg.Wait()
+
+	healthy := true
+	c.mu.Lock()
+	for i, s := range stores {
+		wasHealthy := s.status.Healthy || s.status.CheckedAt.IsZero()
+		res := results[i]
+		if !res.Healthy {
+			res.LastHealthyAt = s.status.LastHealthyAt
+			healthy = false
+			glog.Errorf("Object store health check failed store=%s step=%s err=%q", res.Store, res.Step, res.Error)
+		} else if !wasHealthy {
+			glog.Infof("Object store healthy again store=%s", res.Store)
+		}
+		s.status = res
+	}
+	c.mu.Unlock()
+	return healthy
+}
+
+// Statuses returns the results of the last health checks of the stores
+func (c *HealthChecker) Statuses() []StoreStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	statuses := make([]StoreStatus, len(c.stores))
+	for i, s := range c.stores {
+		statuses[i] = s.status
+	}
+	return statuses
+}
+
+// Healthy returns whether the last health checks of the stores succeeded
+func (c *HealthChecker) Healthy() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.stores {
+		if !s.status.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *HealthChecker) check(ctx context.Context, s *storeCheck) StoreStatus {
+	status := StoreStatus{Store: s.status.Store, Description: s.status.Description, CheckedAt: time.Now()}
+	step, err := c.probe(ctx, s.os)
+	if err != nil {
+		status.Step = step
+		status.Error = err.Error()
+	} else {
+		status.Healthy = true
+		checkedAt := status.CheckedAt
+		status.LastHealthyAt = &checkedAt
+	}
+	if monitor.Enabled {
+		monitor.ObjectStoreHealthCheck(status.Store, step, status.Healthy)
+	}
+	return status
+}
+
+// probe writes a random token to the store, reads it back and deletes it. It returns the step that failed.
+func (c *HealthChecker) probe(ctx context.Context, os drivers.OSDriver) (string, error) {
+	sess := os.NewSession(healthCheckPath)
+	defer sess.EndSession()
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return HealthCheckWrite, err
+	}
+	token := hex.EncodeToString(b)
+
+	uri, err := sess.SaveData(ctx, c.name, strings.NewReader(token), nil, healthCheckTimeout)
+	if err != nil {
+		return HealthCheckWrite, err
+	}
+
+	data, err := c.read(ctx, sess, uri)
+	if err != nil {
+		return HealthCheckRead, err
+	}
+	if data != nil && string(data) != token {
+		return HealthCheckRead, fmt.Errorf("read %q instead of the %q written", data, token)
+	}
+
+	if d := findDeleter(sess); d != nil {
+		ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		defer cancel()
+		if err := d.DeleteFile(ctx, c.name); err != nil {
+			return HealthCheckDelete, err
+		}
+	}
+	return "", nil
+}
+
+// read reads the file of the health check with the API of the store, or downloads it from its URI as other nodes
+// and players do when the store doesn't support reads. It returns nil data if the file can't be read either way.
+func (c *HealthChecker) read(ctx context.Context, sess drivers.OSSession, uri string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	var body io.ReadCloser
+	fi, err := sess.ReadData(ctx, healthCheckPath+"/"+c.name)
+	switch {
+	case err == nil:
+		body = fi.Body
+	case err.Error() != "Not implemented":
+		return nil, err
+	case strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, SignURL(sess, uri), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("download of %s failed with status %s", uri, resp.Status)
+		}
+		body = resp.Body
+	default:
+		return nil, nil
+	}
+	defer body.Close()
+	return ioutil.ReadAll(io.LimitReader(body, healthCheckMaxRead))
+}
+
+// findDeleter returns the deleter of a session or of the session it wraps, nil if files can't be deleted
+func findDeleter(sess drivers.OSSession) fileDeleter {
+	for sess != nil {
+		if d, ok := sess.(fileDeleter); ok {
+			return d
+		}
+		w, ok := sess.(wrappedSession)
+		if !ok {
+			return nil
+		}
+		sess = w.unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthChecker(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+	local, err := NewLocalDriver(dir, LocalOptions{})
+	require.Nil(err)
+	flaky := &flakyOS{MemoryOS: drivers.NewMemoryDriver(nil), fail: true}
+	c := NewHealthChecker("node1")
+	c.Add(UsageObjectStore, drivers.NewMemoryDriver(nil))
+	c.Add(UsageRecordStore, local)
+	c.Add("record_replica_1", flaky)
+	assert.Equal(3, c.Len())
+	ctx := context.Background()
+
+	assert.False(c.CheckAll(ctx))
+	assert.False(c.Healthy())
+	statuses := c.Statuses()
+	require.Len(statuses, 3)
+	assert.True(statuses[0].Healthy)
+	assert.True(statuses[1].Healthy)
+	assert.Equal(local.Description(), statuses[1].Description)
+	assert.NotNil(statuses[1].LastHealthyAt)
+	assert.Equal(StoreStatus{Store: "record_replica_1", Description: flaky.Description(), Step: HealthCheckWrite,
+		Error: "save error", CheckedAt: statuses[2].CheckedAt}, statuses[2])
+	// The file of the check is deleted when the store supports it
+	files, err := filepath.Glob(filepath.Join(dir, healthCheckPath, "*"))
+	require.Nil(err)
+	assert.Empty(files)
+
+	flaky.setFail(false)
+	assert.True(c.CheckAll(ctx))
+	assert.True(c.Healthy())
+	assert.True(c.Statuses()[2].Healthy)
+	assert.Equal("", c.Statuses()[2].Error)
+}
+
+func TestHealthChecker_Download(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	corrupt := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+			w.Header().Set("ETag", `"etag"`)
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if corrupt {
+				body = []byte("corrupt")
+			}
+			w.Write(body)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	// The object store of the node doesn't read with the API, the file is downloaded as by other nodes
+	os, err := ParseOSURL("s3+http://key:secret@"+strings.TrimPrefix(ts.URL, "http://")+"/bucket?region=us-east-1", false)
+	require.Nil(err)
+	c := NewHealthChecker("")
+	c.Add(UsageObjectStore, os)
+	assert.True(c.CheckAll(context.Background()))
+	mu.Lock()
+	assert.Empty(objects)
+	corrupt = true
+	mu.Unlock()
+	assert.False(c.CheckAll(context.Background()))
+	status := c.Statuses()[0]
+	assert.Equal(HealthCheckRead, status.Step)
+	assert.Contains(status.Error, `read "corrupt" instead of the`)
+	assert.NotNil(status.LastHealthyAt)
+}
@@ -0,0 +1,426 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-tools/drivers"
+)
+
+// URL parameters of the local object store
+const (
+	// localMaxAgeParam is the age after which the files are removed
+	localMaxAgeParam = "maxAge"
+	// localMaxSizeParam is the total size of the files above which the oldest ones are removed
+	localMaxSizeParam = "maxSize"
+	// localGCIntervalParam is the interval of the removal of the files exceeding the retention
+	localGCIntervalParam = "gcInterval"
+)
+
+const (
+	// localDefaultGCInterval is the interval of the garbage collection when not configured
+	localDefaultGCInterval = 10 * time.Minute
+	// localTempFilePrefix is the prefix of the files being written
+	localTempFilePrefix = ".tmp-"
+	// localTempFileMaxAge is the age after which the files that were being written are considered abandoned, and
+	// empty directories are removed
+	localTempFileMaxAge = time.Hour
+)
+
+// LocalOptions are the retention options of the local driver, zero values disable them
+type LocalOptions struct {
+	// MaxAge is the age after which the files are removed
+	MaxAge time.Duration
+	// MaxSize is the total size in bytes of the files above which the oldest ones are removed
+	MaxSize int64
+	// GCInterval is the interval of the removal of the files exceeding the retention
+	GCInterval time.Duration
+}
+
+// LocalOS is a driver saving data to a directory of the local disk, with a retention enforced in the background
+type LocalOS struct {
+	dir  string
+	opts LocalOptions
+}
+
+var (
+	// localDrivers are the drivers by directory, so that a single garbage collection runs for each directory when
+	// its URL is parsed again, e.g. from webhook responses
+	localDrivers = make(map[string]*LocalOS)
+	// localAllowedDirs are the directories of the URLs of the flags, the only ones that other URLs can use
+	localAllowedDirs = make(map[string]bool)
+	localDriversMu   sync.Mutex
+)
+
+// prepareLocalURL allows the directory of a file URL to be used
+func prepareLocalURL(u *url.URL) {
+	localDriversMu.Lock()
+	defer localDriversMu.Unlock()
+	localAllowedDirs[filepath.Clean(filepath.FromSlash(u.Path))] = true
+}
+
+// parseLocalURL returns the driver of a file URL with options:
+//
+//	file:///var/lib/livepeer/recordings?maxAge=168h&maxSize=50GB
+func parseLocalURL(u *url.URL) (drivers.OSDriver, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("file URL host must be empty or localhost, got host=%s", u.Host)
+	}
+	if u.Path == "" || !path.IsAbs(u.Path) {
+		return nil, errors.New("absolute directory path not found in file URL")
+	}
+	localDriversMu.Lock()
+	allowed := localAllowedDirs[filepath.Clean(filepath.FromSlash(u.Path))]
+	localDriversMu.Unlock()
+	if !allowed {
+		return nil, errors.New("file object stores are only supported with the directories of the object store flags")
+	}
+	q := u.Query()
+	var opts LocalOptions
+	var err error
+	if v := q.Get(localMaxAgeParam); v != "" {
+		if opts.MaxAge, err = time.ParseDuration(v); err != nil || opts.MaxAge < 0 {
+			return nil, fmt.Errorf("invalid %s=%q", localMaxAgeParam, v)
+		}
+	}
+	if v := q.Get(localMaxSizeParam); v != "" {
+		if opts.MaxSize, err = parseSize(v); err != nil {
+			return nil, fmt.Errorf("invalid %s=%q", localMaxSizeParam, v)
+		}
+	}
+	if v := q.Get(localGCIntervalParam); v != "" {
+		if opts.GCInterval, err = time.ParseDuration(v); err != nil || opts.GCInterval <= 0 {
+			return nil, fmt.Errorf("invalid %s=%q", localGCIntervalParam, v)
+		}
+	}
+	return NewLocalDriver(filepath.FromSlash(u.Path), opts)
+}
+
+// parseSize parses a size in bytes with an optional KB, MB, GB or TB unit, in powers of 1024
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		mult   int64
+	}{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+	s = strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, mult = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size=%q", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// NewLocalDriver returns the driver of a directory, creating it if needed, and starts the garbage collection of the
+// files exceeding the retention. The driver of a directory is created once, later calls return it as is.
+func NewLocalDriver(dir string, opts LocalOptions) (*LocalOS, error) {
+	dir = filepath.Clean(dir)
+	localDriversMu.Lock()
+	defer localDriversMu.Unlock()
+	if ostore, ok := localDrivers[dir]; ok {
+		return ostore, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if opts.GCInterval == 0 {
+		opts.GCInterval = localDefaultGCInterval
+	}
+	los := &LocalOS{dir: dir, opts: opts}
+	localDrivers[dir] = los
+	if opts.MaxAge > 0 || opts.MaxSize > 0 {
+		go los.gcLoop()
+	}
+	return los, nil
+}
+
+func (ostore *LocalOS) NewSession(path string) drivers.OSSession {
+	return &localSession{os: ostore, key: path}
+}
+
+func (ostore *LocalOS) UriSchemes() []string {
+	return []string{"file"}
+}
+
+func (ostore *LocalOS) Description() string {
+	return "Local file system directory with age and size retention."
+}
+
+// filePath returns the path of the file of a key, which has to stay in the directory of the driver
+func (ostore *LocalOS) filePath(key string) (string, error) {
+	for _, elem := range strings.Split(key, "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("invalid file name=%s", key)
+		}
+	}
+	return filepath.Join(ostore.dir, filepath.FromSlash(key)), nil
+}
+
+func (ostore *LocalOS) gcLoop() {
+	ticker := time.NewTicker(ostore.opts.GCInterval)
+	defer ticker.Stop()
+	for {
+		ostore.gc(time.Now())
+		<-ticker.C
+	}
+}
+
+type localFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// gc removes the files older than the max age, then the oldest files until their total size is within the max size,
+// and the directories left empty
+func (ostore *LocalOS) gc(now time.Time) {
+	var files []localFile
+	var dirs []string
+	var total int64
+	err := filepath.Walk(ostore.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			// Recent directories may be about to receive files
+			if p != ostore.dir && now.Sub(info.ModTime()) > localTempFileMaxAge {
+				dirs = append(dirs, p)
+			}
+			return nil
+		}
+		// Files being written are skipped, and removed if abandoned
+		if strings.HasPrefix(info.Name(), localTempFilePrefix) {
+			if now.Sub(info.ModTime()) > localTempFileMaxAge {
+				os.Remove(p)
+			}
+			return nil
+		}
+		files = append(files, localFile{path: p, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		glog.Errorf("Error listing local object store files dir=%s err=%q", ostore.dir, err)
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	var removed int
+	var removedBytes int64
+	for _, f := range files {
+		expired := ostore.opts.MaxAge > 0 && now.Sub(f.modTime) > ostore.opts.MaxAge
+		oversize := ostore.opts.MaxSize > 0 && total > ostore.opts.MaxSize
+		if !expired && !oversize {
+			// The files are sorted by age, so the next ones don't exceed the retention either
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			glog.Errorf("Error removing local object store file=%s err=%q", f.path, err)
+			continue
+		}
+		total -= f.size
+		removed++
+		removedBytes += f.size
+	}
+	// Remove the old empty directories, children first
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, dir := range dirs {
+		// Fails for the directories that are not empty
+		os.Remove(dir)
+	}
+	if removed > 0 {
+		glog.Infof("Removed files exceeding the retention of the local object store dir=%s files=%d bytes=%d remaining_bytes=%d",
+			ostore.dir, removed, removedBytes, total)
+	}
+}
+
+type localSession struct {
+	os  *LocalOS
+	key string
+}
+
+func (sess *localSession) OS() drivers.OSDriver {
+	return sess.os
+}
+
+func (sess *localSession) EndSession() {
+}
+
+func (sess *localSession) IsExternal() bool {
+	return false
+}
+
+func (sess *localSession) IsOwn(url string) bool {
+	return strings.HasPrefix(url, sess.os.dir)
+}
+
+// GetInfo returns nil since other nodes can't save data to the session
+func (sess *localSession) GetInfo() *drivers.OSInfo {
+	return nil
+}
+
+// SaveData writes the data to a temporary file renamed once complete, so that the files are never read partially
+func (sess *localSession) SaveData(ctx context.Context, name string, data io.Reader, meta map[string]string, timeout time.Duration) (string, error) {
+	filePath, err := sess.os.filePath(sess.key + "/" + name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return "", err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(filePath), localTempFilePrefix+"*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	if _, err := io.Copy(file, data); err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(file.Name(), filePath); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+func (sess *localSession) ReadData(ctx context.Context, name string) (*drivers.FileInfoReader, error) {
+	key := name
+	// if name is not specified, assume that this session already created with specific key
+	if key == "" {
+		key = sess.key
+	}
+	filePath, err := sess.os.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	size := info.Size()
+	res := &drivers.FileInfoReader{Body: file}
+	res.Name = name
+	res.LastModified = info.ModTime()
+	res.Size = &size
+	return res, nil
+}
+
+// DeleteFile deletes a file saved in the session
+func (sess *localSession) DeleteFile(ctx context.Context, name string) error {
+	filePath, err := sess.os.filePath(sess.key + "/" + name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(filePath)
+}
+
+// ListFiles lists the files with names starting with prefix, as the S3 driver. With the / delimiter, only the files
+// of the directory of the prefix are listed, along with its subdirectories.
+func (sess *localSession) ListFiles(ctx context.Context, prefix, delim string) (drivers.PageInfo, error) {
+	if delim != "" && delim != "/" {
+		return nil, fmt.Errorf("unsupported delimiter=%q", delim)
+	}
+	dir, namePrefix := path.Split(prefix)
+	dirPath, err := sess.os.filePath(dir)
+	if err != nil {
+		return nil, err
+	}
+	pi := &localPageInfo{}
+	err = filepath.Walk(dirPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == dirPath {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if p == dirPath {
+			return nil
+		}
+		rel, err := filepath.Rel(dirPath, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if info.IsDir() {
+			if !strings.HasPrefix(name, namePrefix) {
+				return filepath.SkipDir
+			}
+			if delim != "" {
+				if strings.HasPrefix(name, namePrefix) {
+					pi.directories = append(pi.directories, dir+name+"/")
+				}
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasPrefix(name, namePrefix) || strings.HasPrefix(info.Name(), localTempFilePrefix) {
+			return nil
+		}
+		size := info.Size()
+		pi.files = append(pi.files, drivers.FileInfo{
+			Name:         dir + name,
+			LastModified: info.ModTime(),
+			Size:         &size,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pi, nil
+}
+
+// localPageInfo is the single page of the listed files
+type localPageInfo struct {
+	files       []drivers.FileInfo
+	directories []string
+}
+
+func (pi *localPageInfo) Files() []drivers.FileInfo {
+	return pi.files
+}
+
+func (pi *localPageInfo) Directories() []string {
+	return pi.directories
+}
+
+func (pi *localPageInfo) HasNextPage() bool {
+	return false
+}
+
+func (pi *localPageInfo) NextPage() (drivers.PageInfo, error) {
+	return nil, drivers.ErrNoNextPage
+}
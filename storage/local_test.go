@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOSURL_Local(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := filepath.Join(t.TempDir(), "recordings")
+	input := "file://" + dir + "?maxAge=168h&maxSize=1.5GB&gcInterval=1h"
+
+	// Only the directories of the flags are allowed
+	_, err := ParseOSURL(input, true)
+	assert.EqualError(err, "file object stores are only supported with the directories of the object store flags")
+
+	prepared, err := PrepareOSURL(input)
+	require.Nil(err)
+	os1, err := ParseOSURL(prepared, true)
+	require.Nil(err)
+	local := os1.(*LocalOS)
+	assert.Equal(dir, local.dir)
+	assert.Equal(LocalOptions{MaxAge: 168 * time.Hour, MaxSize: 3 << 29, GCInterval: time.Hour}, local.opts)
+	assert.True(IsRecordOnly(os1))
+	assert.DirExists(dir)
+
+	// The driver of a directory is reused
+	os2, err := ParseOSURL("file://"+dir+"/", true)
+	require.Nil(err)
+	assert.True(os1 == os2)
+
+	_, err = ParseOSURL("file://host"+dir, true)
+	assert.EqualError(err, "file URL host must be empty or localhost, got host=host")
+	PrepareOSURL("file:///other")
+	_, err = ParseOSURL("file:///other?maxSize=lots", true)
+	assert.EqualError(err, `invalid maxSize="lots"`)
+	_, err = ParseOSURL("file:///other?gcInterval=0s", true)
+	assert.EqualError(err, `invalid gcInterval="0s"`)
+
+	size, err := parseSize("512")
+	assert.Nil(err)
+	assert.Equal(int64(512), size)
+	size, err = parseSize("10 mb")
+	assert.Nil(err)
+	assert.Equal(int64(10<<20), size)
+}
+
+func TestLocalOS_SaveReadList(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+	local, err := NewLocalDriver(dir, LocalOptions{})
+	require.Nil(err)
+	sess := local.NewSession("mid/node")
+	ctx := context.Background()
+
+	uri, err := sess.SaveData(ctx, "source/1.ts", bytes.NewReader([]byte("seg1")), nil, 0)
+	require.Nil(err)
+	assert.Equal(filepath.Join(dir, "mid", "node", "source", "1.ts"), uri)
+	assert.True(sess.IsOwn(uri))
+	_, err = sess.SaveData(ctx, "source/2.ts", bytes.NewReader([]byte("seg2")), nil, 0)
+	require.Nil(err)
+	_, err = sess.SaveData(ctx, "playlist_1.json", bytes.NewReader([]byte("v1")), nil, 0)
+	require.Nil(err)
+	_, err = sess.SaveData(ctx, "playlist_1.json", bytes.NewReader([]byte("v2")), nil, 0)
+	require.Nil(err)
+	_, err = sess.SaveData(ctx, "../../escape.ts", bytes.NewReader([]byte("data")), nil, 0)
+	assert.EqualError(err, "invalid file name=mid/node/../../escape.ts")
+
+	res, err := sess.ReadData(ctx, "mid/node/playlist_1.json")
+	require.Nil(err)
+	data, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Nil(err)
+	assert.Equal("v2", string(data))
+	assert.Equal(int64(2), *res.Size)
+	assert.False(res.LastModified.IsZero())
+	_, err = sess.ReadData(ctx, "mid/node/missing.json")
+	assert.True(os.IsNotExist(err))
+
+	// Listing as /recordings does
+	pi, err := sess.ListFiles(ctx, "mid/", "/")
+	require.Nil(err)
+	assert.Empty(pi.Files())
+	assert.Equal([]string{"mid/node/"}, pi.Directories())
+	pi, err = sess.ListFiles(ctx, "mid/node/playlist_", "")
+	require.Nil(err)
+	require.Len(pi.Files(), 1)
+	assert.Equal("mid/node/playlist_1.json", pi.Files()[0].Name)
+	assert.False(pi.HasNextPage())
+
+	// Without delimiter, the files of the subdirectories are listed
+	pi, err = sess.ListFiles(ctx, "mid/node/", "")
+	require.Nil(err)
+	var names []string
+	for _, f := range pi.Files() {
+		names = append(names, f.Name)
+	}
+	assert.Equal([]string{"mid/node/playlist_1.json", "mid/node/source/1.ts", "mid/node/source/2.ts"}, names)
+
+	pi, err = sess.ListFiles(ctx, "unknown/", "/")
+	require.Nil(err)
+	assert.Empty(pi.Files())
+	assert.Empty(pi.Directories())
+}
+
+func TestLocalOS_GC(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+	local, err := NewLocalDriver(dir, LocalOptions{MaxAge: 24 * time.Hour, MaxSize: 10, GCInterval: time.Hour})
+	require.Nil(err)
+	ctx := context.Background()
+	now := time.Now()
+	save := func(name string, size int, age time.Duration) string {
+		uri, err := local.NewSession("").SaveData(ctx, name, bytes.NewReader(bytes.Repeat([]byte("a"), size)), nil, 0)
+		require.Nil(err)
+		require.Nil(os.Chtimes(uri, now.Add(-age), now.Add(-age)))
+		return uri
+	}
+	exists := func(p string) bool {
+		_, err := os.Stat(p)
+		return err == nil
+	}
+
+	expired := save("old/node/1.ts", 1, 48*time.Hour)
+	oldest := save("mid/node/1.ts", 4, 3*time.Hour)
+	older := save("mid/node/2.ts", 4, 2*time.Hour)
+	recent := save("mid/node/3.ts", 4, time.Hour)
+	latest := save("mid/node/playlist_1.json", 2, 0)
+	// Abandoned and current temporary files
+	abandoned := filepath.Join(dir, "mid", localTempFilePrefix+"1")
+	require.Nil(ioutil.WriteFile(abandoned, []byte("a"), 0644))
+	require.Nil(os.Chtimes(abandoned, now.Add(-2*time.Hour), now.Add(-2*time.Hour)))
+	current := filepath.Join(dir, "mid", localTempFilePrefix+"2")
+	require.Nil(ioutil.WriteFile(current, []byte("aaaaaaaaaaaa"), 0644))
+	oldDir := filepath.Join(dir, "old", "node")
+	require.Nil(os.Chtimes(oldDir, now.Add(-2*time.Hour), now.Add(-2*time.Hour)))
+	require.Nil(os.Chtimes(filepath.Dir(oldDir), now.Add(-2*time.Hour), now.Add(-2*time.Hour)))
+
+	local.gc(now)
+	assert.False(exists(expired))
+	// The oldest files are removed until the total size is within 10 bytes
+	assert.False(exists(oldest))
+	assert.True(exists(older))
+	assert.True(exists(recent))
+	assert.True(exists(latest))
+	assert.False(exists(abandoned))
+	assert.True(exists(current))
+	// The old empty directories are removed
+	assert.False(exists(filepath.Join(dir, "old")))
+	assert.True(exists(filepath.Join(dir, "mid", "node")))
+	assert.True(exists(dir))
+
+	// Within the retention, nothing is removed
+	local.gc(now)
+	assert.True(exists(older))
+	assert.True(exists(recent))
+	assert.True(exists(latest))
+	entries, err := ioutil.ReadDir(filepath.Join(dir, "mid", "node"))
+	require.Nil(err)
+	assert.Len(entries, 3)
+	for _, entry := range entries {
+		assert.False(strings.HasPrefix(entry.Name(), localTempFilePrefix))
+	}
+}
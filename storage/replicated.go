@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-tools/drivers"
+)
+
+const (
+	// replicaQueueMaxItems is the number of saves that can wait in the queue of a replica
+	replicaQueueMaxItems = 1000
+	// replicaMaxAttempts is the number of times a save to a replica is tried
+	replicaMaxAttempts = 5
+	// replicaMaxBackoff is the max delay between the attempts of a save to a replica
+	replicaMaxBackoff = time.Minute
+	// replicaSaveTimeout is the timeout of each attempt of a save to a replica
+	replicaSaveTimeout = 30 * time.Second
+	// replicaSpoolFilePrefix is the prefix of the temporary files of the large saves
+	replicaSpoolFilePrefix = "livepeer-replica-"
+)
+
+var (
+	// replicaInitialBackoff is the delay before the first retry of a save to a replica, doubled after each attempt
+	replicaInitialBackoff = time.Second
+	// replicaMaxBufferSize is the max size of the data of a save held in memory for the replicas. Larger data, such
+	// as a recording, is spooled to a temporary file.
+	replicaMaxBufferSize int64 = 32 << 20
+)
+
+// SplitOSURLs returns the URLs of a comma separated list of object store URLs
+func SplitOSURLs(input string) []string {
+	var urls []string
+	for _, u := range strings.Split(input, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// ReplicatedOS is a driver saving data to a primary driver and replicating it to other drivers in the background.
+// The URIs of the saved data, the reads and the listings are the ones of the primary driver.
+type ReplicatedOS struct {
+	primary  drivers.OSDriver
+	replicas []*replicaQueue
+}
+
+// NewReplicatedDriver creates a driver replicating the data saved to primary to the replicas, and starts the queue
+// of each replica
+func NewReplicatedDriver(primary drivers.OSDriver, replicas ...drivers.OSDriver) *ReplicatedOS {
+	os := &ReplicatedOS{primary: primary}
+	for _, replica := range replicas {
+		q := newReplicaQueue(replica)
+		go q.loop()
+		os.replicas = append(os.replicas, q)
+	}
+	return os
+}
+
+func (os *ReplicatedOS) NewSession(path string) drivers.OSSession {
+	return &replicatedSession{OSSession: os.primary.NewSession(path), os: os, key: path}
+}
+
+func (os *ReplicatedOS) UriSchemes() []string {
+	return os.primary.UriSchemes()
+}
+
+func (os *ReplicatedOS) Description() string {
+	return "Replicated storage, with the primary driver: " + os.primary.Description()
+}
+
+// Pending returns the number of saves waiting in the queue of each replica
+func (os *ReplicatedOS) Pending() []int {
+	pending := make([]int, len(os.replicas))
+	for i, q := range os.replicas {
+		pending[i] = q.len()
+	}
+	return pending
+}
+
+type replicatedSession struct {
+	drivers.OSSession
+	os  *ReplicatedOS
+	key string
+}
+
+func (sess *replicatedSession) OS() drivers.OSDriver {
+	return sess.os
+}
+
+func (sess *replicatedSession) unwrap() drivers.OSSession {
+	return sess.OSSession
+}
+
+// SaveData saves the data to the primary driver and queues it for the replicas. The replicas are independent of the
+// primary driver, so the data is queued even if it can't be saved to the primary driver.
+func (sess *replicatedSession) SaveData(ctx context.Context, name string, data io.Reader, meta map[string]string, timeout time.Duration) (string, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(data, replicaMaxBufferSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(b)) <= replicaMaxBufferSize {
+		for _, q := range sess.os.replicas {
+			q.push(&replicaSave{key: sess.key, name: name, data: b, meta: meta})
+		}
+		return sess.OSSession.SaveData(ctx, name, bytes.NewReader(b), meta, timeout)
+	}
+
+	// The large data is spooled to a file read by the primary driver and the replicas
+	spool, err := newReplicaSpool(io.MultiReader(bytes.NewReader(b), data), len(sess.os.replicas)+1)
+	if err != nil {
+		return "", err
+	}
+	for _, q := range sess.os.replicas {
+		q.push(&replicaSave{key: sess.key, name: name, spool: spool, meta: meta})
+	}
+	defer spool.release()
+	f, err := os.Open(spool.path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return sess.OSSession.SaveData(ctx, name, f, meta, timeout)
+}
+
+// replicaSpool is a temporary file holding the data of a large save until it's saved to the primary driver and
+// queued saves to the replicas
+type replicaSpool struct {
+	path string
+	refs int32
+}
+
+func newReplicaSpool(data io.Reader, refs int) (*replicaSpool, error) {
+	f, err := ioutil.TempFile("", replicaSpoolFilePrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(f, data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &replicaSpool{path: f.Name(), refs: int32(refs)}, nil
+}
+
+// release removes the file once it's no longer used
+func (spool *replicaSpool) release() {
+	if atomic.AddInt32(&spool.refs, -1) == 0 {
+		os.Remove(spool.path)
+	}
+}
+
+// replicaSave is a save waiting in the queue of a replica, with its data in memory or in a spool file
+type replicaSave struct {
+	key   string
+	name  string
+	data  []byte
+	spool *replicaSpool
+	meta  map[string]string
+}
+
+func (s *replicaSave) path() string {
+	return path.Join(s.key, s.name)
+}
+
+func (s *replicaSave) open() (io.ReadCloser, error) {
+	if s.spool != nil {
+		return os.Open(s.spool.path)
+	}
+	return ioutil.NopCloser(bytes.NewReader(s.data)), nil
+}
+
+// done releases the spool file of the save once it's saved, given up or dropped
+func (s *replicaSave) done() {
+	if s.spool != nil {
+		s.spool.release()
+	}
+}
+
+// replicaQueue saves data to a replica in order, retrying with a backoff. A save replaces the pending one of the
+// same file, such as a playlist saved again.
+type replicaQueue struct {
+	os drivers.OSDriver
+
+	mu      sync.Mutex
+	items   *list.List
+	pending map[string]*list.Element
+	notify  chan struct{}
+}
+
+func newReplicaQueue(os drivers.OSDriver) *replicaQueue {
+	return &replicaQueue{
+		os:      os,
+		items:   list.New(),
+		pending: make(map[string]*list.Element),
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+func (q *replicaQueue) push(s *replicaSave) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if e, ok := q.pending[s.path()]; ok {
+		e.Value.(*replicaSave).done()
+		e.Value = s
+		return
+	}
+	if q.items.Len() >= replicaQueueMaxItems {
+		glog.Errorf("Dropping save to replica object store, queue is full desc=%q name=%s", q.os.Description(), s.path())
+		s.done()
+		return
+	}
+	q.pending[s.path()] = q.items.PushBack(s)
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *replicaQueue) pop() *replicaSave {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e := q.items.Front()
+	if e == nil {
+		return nil
+	}
+	q.items.Remove(e)
+	s := e.Value.(*replicaSave)
+	delete(q.pending, s.path())
+	return s
+}
+
+func (q *replicaQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Len()
+}
+
+func (q *replicaQueue) loop() {
+	for range q.notify {
+		for s := q.pop(); s != nil; s = q.pop() {
+			q.save(s)
+		}
+	}
+}
+
+func (q *replicaQueue) save(s *replicaSave) {
+	defer s.done()
+	sess := q.os.NewSession(s.key)
+	backoff := replicaInitialBackoff
+	for attempt := 1; ; attempt++ {
+		data, err := s.open()
+		if err == nil {
+			_, err = sess.SaveData(context.Background(), s.name, data, s.meta, replicaSaveTimeout)
+			data.Close()
+		}
+		if err == nil {
+			return
+		}
+		if attempt >= replicaMaxAttempts {
+			glog.Errorf("Error saving to replica object store, giving up desc=%q name=%s attempts=%d err=%q", q.os.Description(), s.path(), attempt, err)
+			return
+		}
+		glog.Warningf("Error saving to replica object store desc=%q name=%s attempt=%d err=%q", q.os.Description(), s.path(), attempt, err)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > replicaMaxBackoff {
+			backoff = replicaMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyOS is a memory driver failing the saves until unblocked, or the given number of next saves
+type flakyOS struct {
+	*drivers.MemoryOS
+	mu       sync.Mutex
+	fail     bool
+	failures int
+	attempts int
+}
+
+func (os *flakyOS) NewSession(path string) drivers.OSSession {
+	return &flakySession{OSSession: os.MemoryOS.NewSession(path), os: os}
+}
+
+func (os *flakyOS) setFail(fail bool) {
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	os.fail = fail
+}
+
+func (os *flakyOS) getAttempts() int {
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	return os.attempts
+}
+
+type flakySession struct {
+	drivers.OSSession
+	os *flakyOS
+}
+
+func (sess *flakySession) SaveData(ctx context.Context, name string, data io.Reader, meta map[string]string, timeout time.Duration) (string, error) {
+	sess.os.mu.Lock()
+	sess.os.attempts++
+	fail := sess.os.fail || sess.os.failures > 0
+	if sess.os.failures > 0 {
+		sess.os.failures--
+	}
+	sess.os.mu.Unlock()
+	if fail {
+		return "", errors.New("save error")
+	}
+	return sess.OSSession.SaveData(ctx, name, data, meta, timeout)
+}
+
+// readString reads a file saved to a memory driver, which reads from the session of the file
+func readString(t *testing.T, os drivers.OSDriver, key, name string) string {
+	res, err := os.NewSession(key).ReadData(context.Background(), key+"/"+name)
+	if err != nil {
+		return ""
+	}
+	data, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	return string(data)
+}
+
+func TestSplitOSURLs(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(SplitOSURLs(""))
+	assert.Equal([]string{"s3://a:b@us-east-1/hot"}, SplitOSURLs("s3://a:b@us-east-1/hot"))
+	assert.Equal([]string{"s3://a:b@us-east-1/hot", "gs://cold"}, SplitOSURLs(" s3://a:b@us-east-1/hot, gs://cold ,"))
+}
+
+func TestReplicatedOS(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func(b time.Duration) { replicaInitialBackoff = b }(replicaInitialBackoff)
+	replicaInitialBackoff = time.Millisecond
+
+	primary := drivers.NewMemoryDriver(nil)
+	hot := drivers.NewMemoryDriver(nil)
+	cold := &flakyOS{MemoryOS: drivers.NewMemoryDriver(nil), fail: true}
+	os := NewReplicatedDriver(primary, hot, cold)
+	sess := os.NewSession("mid")
+	assert.Equal(os, sess.OS())
+	ctx := context.Background()
+
+	uri, err := sess.SaveData(ctx, "1.ts", bytes.NewReader([]byte("seg1")), nil, 0)
+	require.Nil(err)
+	assert.Equal("/stream/mid/1.ts", uri)
+	assert.Equal("seg1", readString(t, primary, "mid", "1.ts"))
+	// Reads are from the primary driver
+	res, err := sess.ReadData(ctx, "mid/1.ts")
+	require.Nil(err)
+	data, _ := ioutil.ReadAll(res.Body)
+	assert.Equal("seg1", string(data))
+
+	// The replicas are independent, the saves to the failing one are retried
+	assert.Eventually(func() bool { return readString(t, hot, "mid", "1.ts") == "seg1" }, 5*time.Second, time.Millisecond)
+	assert.Eventually(func() bool { return cold.getAttempts() >= 2 }, 5*time.Second, time.Millisecond)
+	assert.Equal("", readString(t, cold, "mid", "1.ts"))
+	cold.setFail(false)
+	assert.Eventually(func() bool { return readString(t, cold, "mid", "1.ts") == "seg1" }, 5*time.Second, time.Millisecond)
+	assert.Eventually(func() bool { return os.Pending()[1] == 0 }, 5*time.Second, time.Millisecond)
+}
+
+func TestReplicaQueue(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func(b time.Duration) { replicaInitialBackoff = b }(replicaInitialBackoff)
+	replicaInitialBackoff = time.Millisecond
+
+	replica := &flakyOS{MemoryOS: drivers.NewMemoryDriver(nil)}
+	q := newReplicaQueue(replica)
+
+	// Pending saves of the same file are replaced
+	q.push(&replicaSave{key: "mid", name: "playlist.json", data: []byte("v1")})
+	q.push(&replicaSave{key: "mid", name: "1.ts", data: []byte("seg1")})
+	q.push(&replicaSave{key: "mid", name: "playlist.json", data: []byte("v2")})
+	assert.Equal(2, q.len())
+	s := q.pop()
+	require.NotNil(s)
+	assert.Equal("mid/playlist.json", s.path())
+	assert.Equal([]byte("v2"), s.data)
+	q.push(&replicaSave{key: "mid", name: "playlist.json", data: []byte("v3")})
+	assert.Equal(2, q.len())
+
+	go q.loop()
+	assert.Eventually(func() bool {
+		return readString(t, replica, "mid", "playlist.json") == "v3" && readString(t, replica, "mid", "1.ts") == "seg1"
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(2, replica.getAttempts())
+
+	// Saves are given up after the max attempts
+	replica.setFail(true)
+	q.push(&replicaSave{key: "mid", name: "2.ts", data: []byte("seg2")})
+	assert.Eventually(func() bool { return replica.getAttempts() == 2+replicaMaxAttempts }, 5*time.Second, time.Millisecond)
+	replica.setFail(false)
+	q.push(&replicaSave{key: "mid", name: "3.ts", data: []byte("seg3")})
+	assert.Eventually(func() bool { return readString(t, replica, "mid", "3.ts") == "seg3" }, 5*time.Second, time.Millisecond)
+	assert.Equal("", readString(t, replica, "mid", "2.ts"))
+}
+
+func TestReplicatedOS_LargeData(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func(size int64) { replicaMaxBufferSize = size }(replicaMaxBufferSize)
+	replicaMaxBufferSize = 4
+	defer func(b time.Duration) { replicaInitialBackoff = b }(replicaInitialBackoff)
+	replicaInitialBackoff = time.Millisecond
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	primary := drivers.NewMemoryDriver(nil)
+	replica := &flakyOS{MemoryOS: drivers.NewMemoryDriver(nil), failures: 1}
+	sess := NewReplicatedDriver(primary, replica).NewSession("mid")
+
+	// The large data is spooled to a file until it's saved to each store
+	_, err := sess.SaveData(context.Background(), "1.mp4", io.MultiReader(strings.NewReader("recording")), nil, 0)
+	require.Nil(err)
+	assert.Equal("recording", readString(t, primary, "mid", "1.mp4"))
+	assert.Eventually(func() bool { return readString(t, replica, "mid", "1.mp4") == "recording" }, 5*time.Second, time.Millisecond)
+	assert.Eventually(func() bool {
+		files, _ := filepath.Glob(filepath.Join(tmp, replicaSpoolFilePrefix+"*"))
+		return len(files) == 0
+	}, 5*time.Second, time.Millisecond)
+}
@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/monitor"
+	"github.com/livepeer/go-tools/drivers"
+)
+
+// ErrCircuitOpen is returned by the saves failing fast because of the previous failures of the object store
+var ErrCircuitOpen = errors.New("object store circuit breaker is open")
+
+// RetryPolicy is how the saves to an object store are retried, and when they fail fast
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a save is tried
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled after each attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout of each attempt, when the caller doesn't give one
+	Timeout time.Duration
+	// BreakerThreshold is the number of consecutive failed saves opening the circuit breaker, 0 disables it
+	BreakerThreshold int
+	// BreakerCooldown is how long the saves fail fast once the circuit breaker is open, before a save is tried
+	// again to close it
+	BreakerCooldown time.Duration
+}
+
+// DefaultRetryPolicy retries the saves for a few seconds only, since the segments are needed for live playback
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:      3,
+	InitialBackoff:   500 * time.Millisecond,
+	MaxBackoff:       2 * time.Second,
+	Timeout:          10 * time.Second,
+	BreakerThreshold: 10,
+	BreakerCooldown:  30 * time.Second,
+}
+
+// retryMaxBufferSize is the max size of the data of a save that is buffered to be saved again on retries. Larger data
+// that can't be seeked, such as a recording streamed from another store, is saved once to not hold it in memory.
+var retryMaxBufferSize int64 = 32 << 20
+
+// Reasons of the failed saves in the metrics
+const (
+	saveFailedError       = "error"
+	saveFailedTimeout     = "timeout"
+	saveFailedCircuitOpen = "circuit_open"
+)
+
+// RetryOS is a driver retrying the saves of the driver it wraps with a backoff, and failing them fast after
+// consecutive failures. Reads and listings aren't retried.
+type RetryOS struct {
+	drivers.OSDriver
+	retrier *retrier
+}
+
+// NewRetryDriver creates a driver retrying the saves to os with the policy
+func NewRetryDriver(os drivers.OSDriver, policy RetryPolicy) *RetryOS {
+	store := "unknown"
+	if schemes := os.UriSchemes(); len(schemes) > 0 {
+		store = schemes[0]
+	}
+	return &RetryOS{OSDriver: os, retrier: &retrier{policy: policy, store: store}}
+}
+
+func (os *RetryOS) NewSession(path string) drivers.OSSession {
+	return &retrySession{OSSession: os.OSDriver.NewSession(path), os: os, retrier: os.retrier}
+}
+
+// NewRetrySession wraps a session that isn't created by a driver of the node, such as the ones of the object stores
+// of other nodes, to retry its saves with the policy. It returns nil for a nil session.
+func NewRetrySession(sess drivers.OSSession, policy RetryPolicy) drivers.OSSession {
+	if sess == nil {
+		return nil
+	}
+	store := "unknown"
+	if info := sess.GetInfo(); info != nil {
+		switch info.StorageType {
+		case drivers.OSInfo_S3:
+			store = "s3"
+		case drivers.OSInfo_GOOGLE:
+			store = "gs"
+		}
+	}
+	return &retrySession{OSSession: sess, retrier: &retrier{policy: policy, store: store}}
+}
+
+type retrySession struct {
+	drivers.OSSession
+	// os is nil for the sessions of other nodes
+	os      *RetryOS
+	retrier *retrier
+}
+
+func (sess *retrySession) OS() drivers.OSDriver {
+	if sess.os == nil {
+		return sess.OSSession.OS()
+	}
+	return sess.os
+}
+
+func (sess *retrySession) unwrap() drivers.OSSession {
+	return sess.OSSession
+}
+
+func (sess *retrySession) SaveData(ctx context.Context, name string, data io.Reader, meta map[string]string, timeout time.Duration) (string, error) {
+	return sess.retrier.save(ctx, sess.OSSession, name, data, meta, timeout)
+}
+
+// retrier holds the policy and the circuit breaker state of an object store
+type retrier struct {
+	policy RetryPolicy
+	// store is the object store in the logs and metrics
+	store string
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (r *retrier) save(ctx context.Context, sess drivers.OSSession, name string, data io.Reader, meta map[string]string, timeout time.Duration) (string, error) {
+	if !r.allow(time.Now()) {
+		r.failed(name, 0, saveFailedCircuitOpen, ErrCircuitOpen)
+		return "", ErrCircuitOpen
+	}
+	maxAttempts := r.policy.MaxAttempts
+	data, rewind, err := rewindable(data)
+	if err != nil {
+		r.aborted()
+		return "", err
+	}
+	if rewind == nil {
+		maxAttempts = 1
+	}
+	if timeout == 0 {
+		timeout = r.policy.Timeout
+	}
+	backoff := r.policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if data, err = rewind(); err != nil {
+				r.failed(name, attempt-1, saveFailedError, err)
+				return "", err
+			}
+		}
+		uri, err := sess.SaveData(ctx, name, data, meta, timeout)
+		if err == nil {
+			r.succeeded()
+			return uri, nil
+		}
+		// Saves canceled by the caller aren't failures of the store
+		if errors.Is(ctx.Err(), context.Canceled) {
+			r.aborted()
+			return "", err
+		}
+		if attempt >= maxAttempts || ctx.Err() != nil {
+			r.failed(name, attempt, failedReason(err), err)
+			return "", err
+		}
+		glog.Warningf("Error saving to object store, retrying store=%s name=%s attempt=%d err=%q", r.store, name, attempt, err)
+		if monitor.Enabled {
+			monitor.ObjectStoreSaveRetried(r.store)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				r.aborted()
+			} else {
+				r.failed(name, attempt, saveFailedTimeout, err)
+			}
+			return "", err
+		}
+		if backoff *= 2; backoff > r.policy.MaxBackoff {
+			backoff = r.policy.MaxBackoff
+		}
+	}
+}
+
+// rewindable returns the data of a save and the function reading it again from the start for the retries. The data
+// is seeked back if it can be, otherwise it's buffered unless it's too large, in which case the function is nil.
+func rewindable(data io.Reader) (io.Reader, func() (io.Reader, error), error) {
+	if rs, ok := data.(io.ReadSeeker); ok {
+		offset, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, nil, err
+		}
+		return rs, func() (io.Reader, error) {
+			_, err := rs.Seek(offset, io.SeekStart)
+			return rs, err
+		}, nil
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(data, retryMaxBufferSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(b)) > retryMaxBufferSize {
+		return io.MultiReader(bytes.NewReader(b), data), nil, nil
+	}
+	return bytes.NewReader(b), func() (io.Reader, error) { return bytes.NewReader(b), nil }, nil
+}
+
+// allow returns whether a save can be tried, which is always the case unless the circuit breaker is open. Once the
+// cooldown is over, a single save is tried to close it.
+func (r *retrier) allow(now time.Time) bool {
+	if r.policy.BreakerThreshold <= 0 {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures < r.policy.BreakerThreshold {
+		return true
+	}
+	if r.probing || now.Sub(r.openedAt) < r.policy.BreakerCooldown {
+		return false
+	}
+	r.probing = true
+	return true
+}
+
+func (r *retrier) succeeded() {
+	r.mu.Lock()
+	wasOpen := r.policy.BreakerThreshold > 0 && r.failures >= r.policy.BreakerThreshold
+	r.failures = 0
+	r.probing = false
+	r.mu.Unlock()
+	if wasOpen {
+		glog.Infof("Object store circuit breaker closed store=%s", r.store)
+		if monitor.Enabled {
+			monitor.ObjectStoreCircuitBreaker(r.store, false)
+		}
+	}
+}
+
+// aborted lets another save be tried to close the circuit breaker when a save is neither successful nor failed
+func (r *retrier) aborted() {
+	r.mu.Lock()
+	r.probing = false
+	r.mu.Unlock()
+}
+
+func (r *retrier) failed(name string, attempts int, reason string, err error) {
+	if monitor.Enabled {
+		monitor.ObjectStoreSaveFailed(r.store, reason)
+	}
+	if reason == saveFailedCircuitOpen {
+		glog.Errorf("Not saving to object store store=%s name=%s err=%q", r.store, name, err)
+		return
+	}
+	glog.Errorf("Error saving to object store, giving up store=%s name=%s attempts=%d err=%q", r.store, name, attempts, err)
+
+	r.mu.Lock()
+	r.failures++
+	r.probing = false
+	failures := r.failures
+	opened := r.policy.BreakerThreshold > 0 && failures >= r.policy.BreakerThreshold
+	if opened {
+		r.openedAt = time.Now()
+	}
+	r.mu.Unlock()
+	if opened {
+		glog.Errorf("Object store circuit breaker open store=%s failures=%d cooldown=%s", r.store, failures, r.policy.BreakerCooldown)
+		if monitor.Enabled {
+			monitor.ObjectStoreCircuitBreaker(r.store, true)
+		}
+	}
+}
+
+func failedReason(err error) string {
+	var timeoutErr interface{ Timeout() bool }
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &timeoutErr) && timeoutErr.Timeout() {
+		return saveFailedTimeout
+	}
+	return saveFailedError
+}
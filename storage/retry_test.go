@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryOS_Save(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	flaky := &flakyOS{MemoryOS: drivers.NewMemoryDriver(nil), failures: 2}
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond}
+	os := NewRetryDriver(flaky, policy)
+	sess := os.NewSession("mid")
+	assert.Equal(os, sess.OS())
+	assert.Equal(os, sess.OS().NewSession("other").OS())
+	ctx := context.Background()
+
+	// The data is saved again on retries
+	uri, err := sess.SaveData(ctx, "1.ts", bytes.NewReader([]byte("seg1")), nil, 0)
+	require.Nil(err)
+	assert.Equal("/stream/mid/1.ts", uri)
+	assert.Equal(3, flaky.getAttempts())
+	assert.Equal("seg1", readString(t, flaky, "mid", "1.ts"))
+
+	// The circuit breaker opens after consecutive failed saves
+	flaky.setFail(true)
+	for i := 0; i < 2; i++ {
+		_, err = sess.SaveData(ctx, "2.ts", bytes.NewReader([]byte("seg2")), nil, 0)
+		assert.EqualError(err, "save error")
+	}
+	assert.Equal(9, flaky.getAttempts())
+	_, err = sess.SaveData(ctx, "2.ts", bytes.NewReader([]byte("seg2")), nil, 0)
+	assert.Equal(ErrCircuitOpen, err)
+	assert.Equal(9, flaky.getAttempts())
+
+	// A save is tried after the cooldown, closing the circuit breaker if it succeeds
+	flaky.setFail(false)
+	time.Sleep(60 * time.Millisecond)
+	_, err = sess.SaveData(ctx, "2.ts", bytes.NewReader([]byte("seg2")), nil, 0)
+	require.Nil(err)
+	assert.Equal("seg2", readString(t, flaky, "mid", "2.ts"))
+	_, err = sess.SaveData(ctx, "3.ts", bytes.NewReader([]byte("seg3")), nil, 0)
+	require.Nil(err)
+
+	// The saves canceled by the caller aren't retried nor failures of the store
+	flaky.setFail(true)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	for i := 0; i < 3; i++ {
+		_, err = sess.SaveData(canceled, "4.ts", bytes.NewReader([]byte("seg4")), nil, 0)
+		assert.EqualError(err, "save error")
+	}
+	assert.Equal(14, flaky.getAttempts())
+}
+
+func TestNewRetrySession(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(NewRetrySession(nil, DefaultRetryPolicy))
+	mem := drivers.NewMemoryDriver(nil).NewSession("mid")
+	sess := NewRetrySession(mem, DefaultRetryPolicy)
+	assert.Equal(mem.OS(), sess.OS())
+	assert.Equal("unknown", sess.(*retrySession).retrier.store)
+}
+
+func TestFailedReason(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("timeout", failedReason(context.DeadlineExceeded))
+	assert.Equal("timeout", failedReason(&net.DNSError{IsTimeout: true}))
+	assert.Equal("error", failedReason(errors.New("save error")))
+}
+
+func TestRetryOS_LargeData(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	defer func(size int64) { retryMaxBufferSize = size }(retryMaxBufferSize)
+	retryMaxBufferSize = 4
+
+	flaky := &flakyOS{MemoryOS: drivers.NewMemoryDriver(nil)}
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	sess := NewRetryDriver(flaky, policy).NewSession("mid")
+	ctx := context.Background()
+
+	// The data that can be seeked is read again from its start on retries
+	flaky.failures = 1
+	data := bytes.NewReader([]byte("..recording"))
+	data.Seek(2, io.SeekStart)
+	_, err := sess.SaveData(ctx, "1.mp4", data, nil, 0)
+	require.Nil(err)
+	assert.Equal(2, flaky.getAttempts())
+	assert.Equal("recording", readString(t, flaky, "mid", "1.mp4"))
+
+	// The small data is buffered
+	flaky.failures = 1
+	_, err = sess.SaveData(ctx, "2.ts", io.MultiReader(strings.NewReader("seg2")), nil, 0)
+	require.Nil(err)
+	assert.Equal(4, flaky.getAttempts())
+	assert.Equal("seg2", readString(t, flaky, "mid", "2.ts"))
+
+	// The larger data is streamed once
+	flaky.failures = 1
+	_, err = sess.SaveData(ctx, "3.mp4", io.MultiReader(strings.NewReader("recording")), nil, 0)
+	assert.EqualError(err, "save error")
+	assert.Equal(5, flaky.getAttempts())
+	_, err = sess.SaveData(ctx, "3.mp4", io.MultiReader(strings.NewReader("recording")), nil, 0)
+	require.Nil(err)
+	assert.Equal("recording", readString(t, flaky, "mid", "3.mp4"))
+}
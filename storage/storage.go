@@ -0,0 +1,112 @@
+// Package storage extends the object store drivers of go-tools with the options and drivers that they don't support
+package storage
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-tools/drivers"
+)
+
+// URLSigner is implemented by the sessions returning pre-signed URLs of the data they save, so that the data can be
+// downloaded without the store being public
+type URLSigner interface {
+	// SignURL returns a pre-signed URL of the data saved at uri, or uri itself if the data isn't saved by the session
+	SignURL(uri string) (string, error)
+}
+
+// wrappedSession is implemented by the sessions of this package wrapping the session of another driver
+type wrappedSession interface {
+	unwrap() drivers.OSSession
+}
+
+// PrepareOSURL resolves the files referenced by an object store URL and turns them into URL parameters, and allows
+// the directory of file URLs to be used. Don't use this when the URL comes from untrusted sources e.g. AuthWebhookUrl.
+func PrepareOSURL(input string) (string, error) {
+	u, err := url.Parse(input)
+	if err != nil {
+		return "", err
+	}
+	if isS3Scheme(u.Scheme) {
+		if err := prepareS3URL(u); err != nil {
+			return "", err
+		}
+		input = u.String()
+	}
+	if u.Scheme == "file" {
+		prepareLocalURL(u)
+	}
+	return drivers.PrepareOSURL(input)
+}
+
+// ParseOSURL returns the driver of an object store URL. URLs that don't use the options of this package are handled
+// by the go-tools drivers.
+func ParseOSURL(input string, useFullAPI bool) (drivers.OSDriver, error) {
+	u, err := url.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	if isS3Scheme(u.Scheme) && hasS3Options(u) {
+		return parseS3URL(u, useFullAPI)
+	}
+	if isIPFSScheme(u.Scheme) {
+		return parseIPFSURL(u)
+	}
+	if u.Scheme == "file" {
+		return parseLocalURL(u)
+	}
+	if u.Scheme == "gs" && useFullAPI {
+		return parseGSURL(u)
+	}
+	if u.Scheme == "gs" && u.Query().Get(gsKMSKeyNameParam) != "" {
+		// The segments shared with other nodes are saved with the POST policy of the go-tools driver
+		return nil, fmt.Errorf("%s is only supported in record stores", gsKMSKeyNameParam)
+	}
+	return drivers.ParseOSURL(input, useFullAPI)
+}
+
+// IsRecordOnly returns whether the driver can only be used to store recordings, because the URIs of the data it
+// saves can't be downloaded with HTTP by other nodes
+func IsRecordOnly(os drivers.OSDriver) bool {
+	switch os.(type) {
+	case *IPFSOS, *LocalOS:
+		return true
+	}
+	return false
+}
+
+// urlSigner returns the URL signer of a session or of the session it wraps, nil if URLs aren't signed
+func urlSigner(sess drivers.OSSession) URLSigner {
+	for sess != nil {
+		if signer, ok := sess.(URLSigner); ok {
+			return signer
+		}
+		w, ok := sess.(wrappedSession)
+		if !ok {
+			return nil
+		}
+		sess = w.unwrap()
+	}
+	return nil
+}
+
+// SignsURLs returns whether the session returns pre-signed URLs of the data it saves
+func SignsURLs(sess drivers.OSSession) bool {
+	return urlSigner(sess) != nil
+}
+
+// SignURL returns a pre-signed URL of the data saved at uri if the session signs URLs, otherwise uri itself. The
+// signed URLs are only meant to be handed out, e.g. in playlists and API responses, and not to be stored.
+func SignURL(sess drivers.OSSession, uri string) string {
+	signer := urlSigner(sess)
+	if signer == nil {
+		return uri
+	}
+	signed, err := signer.SignURL(uri)
+	if err != nil {
+		glog.Errorf("Error signing object store URL uri=%s err=%q", uri, err)
+		return uri
+	}
+	return signed
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// streamTimeout bounds an upload streaming its data from a reader: the upload is canceled once no data is read for
+// the timeout. The multipart uploads read the data one part at a time, so the timeout applies to the upload of each
+// part instead of the whole upload, which can take much longer for large recordings. For small uploads, it's the
+// same as a timeout of the whole upload.
+type streamTimeout struct {
+	data    io.Reader
+	timeout time.Duration
+	cancel  context.CancelFunc
+	done    chan struct{}
+	// lastRead is the time of the last read in unix nanoseconds
+	lastRead int64
+	timedOut int32
+}
+
+// newStreamTimeout returns the context of an upload and the reader of its data, canceling the context once no
+// data is read for the timeout. stop has to be called when the upload is done.
+func newStreamTimeout(ctx context.Context, data io.Reader, timeout time.Duration) (context.Context, *streamTimeout) {
+	ctx, cancel := context.WithCancel(ctx)
+	st := &streamTimeout{
+		data:     data,
+		timeout:  timeout,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		lastRead: time.Now().UnixNano(),
+	}
+	go st.watch()
+	return ctx, st
+}
+
+func (st *streamTimeout) Read(p []byte) (int, error) {
+	n, err := st.data.Read(p)
+	atomic.StoreInt64(&st.lastRead, time.Now().UnixNano())
+	return n, err
+}
+
+func (st *streamTimeout) watch() {
+	wait := st.timeout
+	for {
+		select {
+		case <-st.done:
+			return
+		case <-time.After(wait):
+		}
+		idle := time.Since(time.Unix(0, atomic.LoadInt64(&st.lastRead)))
+		if idle >= st.timeout {
+			atomic.StoreInt32(&st.timedOut, 1)
+			st.cancel()
+			return
+		}
+		wait = st.timeout - idle
+	}
+}
+
+// stop stops the watch of the upload and returns its error, which is a timeout error if the upload was canceled
+// because of the timeout
+func (st *streamTimeout) stop(err error) error {
+	close(st.done)
+	st.cancel()
+	if err != nil && atomic.LoadInt32(&st.timedOut) == 1 {
+		return fmt.Errorf("upload stalled for %s: %w", st.timeout, context.DeadlineExceeded)
+	}
+	return err
+}
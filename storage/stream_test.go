@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowReader returns one byte of its data at a time after the delay, and blocks once stalled
+type slowReader struct {
+	data  []byte
+	delay time.Duration
+	stall <-chan struct{}
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	if r.stall != nil {
+		<-r.stall
+	}
+	time.Sleep(r.delay)
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestStreamTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	// The uploads making progress aren't limited by the timeout
+	ctx, st := newStreamTimeout(context.Background(), &slowReader{data: []byte("0123456789"), delay: 10 * time.Millisecond}, 50*time.Millisecond)
+	data, err := ioutil.ReadAll(st)
+	assert.Nil(err)
+	assert.Equal("0123456789", string(data))
+	assert.Nil(ctx.Err())
+	assert.Nil(st.stop(nil))
+	assert.Equal(context.Canceled, ctx.Err())
+
+	// The stalled ones are canceled
+	stall := make(chan struct{})
+	defer close(stall)
+	ctx, st = newStreamTimeout(context.Background(), &slowReader{data: []byte("0123456789"), stall: stall}, 50*time.Millisecond)
+	go ioutil.ReadAll(st)
+	<-ctx.Done()
+	err = st.stop(ctx.Err())
+	assert.EqualError(err, "upload stalled for 50ms: context deadline exceeded")
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/livepeer/go-tools/drivers"
+)
+
+// RecordPathTemplate is the template of the keys of the recorded segments, nil for the default layout
+var RecordPathTemplate *PathTemplate
+
+// Variables of the path templates
+const (
+	pathVarManifestID = "manifestID"
+	pathVarSessionID  = "sessionID"
+	pathVarNodeID     = "nodeID"
+	pathVarProfile    = "profile"
+	pathVarSeqNo      = "seqNo"
+	pathVarDate       = "date"
+	pathVarYear       = "year"
+	pathVarMonth      = "month"
+	pathVarDay        = "day"
+)
+
+var (
+	pathVarRegexp = regexp.MustCompile(`\{([^{}]*)\}`)
+	// segmentNameRegexp matches the names of the recorded segments, <profile>/<seqNo><ext>
+	segmentNameRegexp = regexp.MustCompile(`^([^/]+)/(\d+)(\.[A-Za-z0-9]+)$`)
+)
+
+// PathVars are the variables of the path template of a stream
+type PathVars struct {
+	// ManifestID is the external manifest ID of the stream, as in the default layout
+	ManifestID string
+	// SessionID is the session ID returned by the webhook, empty without webhook
+	SessionID string
+	NodeID    string
+	// Start is the time the stream started, whose date is used for all its segments
+	Start time.Time
+}
+
+// PathTemplate is the template of the keys of the recorded segments, relative to the key prefix of the store, such as
+// recordings/{date}/{manifestID}/{profile}/{seqNo}. The extension of the segment is appended to the key.
+type PathTemplate struct {
+	tmpl string
+}
+
+// ParsePathTemplate parses a path template. It has to include the manifestID, profile and seqNo variables so that
+// the segments don't overwrite each other.
+func ParsePathTemplate(tmpl string) (*PathTemplate, error) {
+	tmpl = strings.Trim(tmpl, "/")
+	if tmpl == "" {
+		return nil, fmt.Errorf("empty path template")
+	}
+	used := make(map[string]bool)
+	for _, m := range pathVarRegexp.FindAllStringSubmatch(tmpl, -1) {
+		switch m[1] {
+		case pathVarManifestID, pathVarSessionID, pathVarNodeID, pathVarProfile, pathVarSeqNo, pathVarDate,
+			pathVarYear, pathVarMonth, pathVarDay:
+			used[m[1]] = true
+		default:
+			return nil, fmt.Errorf("unknown variable %s in path template %q", m[0], tmpl)
+		}
+	}
+	if !strings.Contains(tmpl, "/") {
+		return nil, fmt.Errorf("path template %q has to include a directory", tmpl)
+	}
+	for _, v := range []string{pathVarManifestID, pathVarProfile, pathVarSeqNo} {
+		if !used[v] {
+			return nil, fmt.Errorf("path template %q has to include {%s}", tmpl, v)
+		}
+	}
+	return &PathTemplate{tmpl: tmpl}, nil
+}
+
+func (t *PathTemplate) String() string {
+	return t.tmpl
+}
+
+// Key returns the key of a segment of the stream, without its extension. Empty variables are removed from the path.
+func (t *PathTemplate) Key(vars PathVars, profile string, seqNo uint64) string {
+	start := vars.Start.UTC()
+	key := pathVarRegexp.ReplaceAllStringFunc(t.tmpl, func(v string) string {
+		switch v[1 : len(v)-1] {
+		case pathVarManifestID:
+			return pathValue(vars.ManifestID)
+		case pathVarSessionID:
+			return pathValue(vars.SessionID)
+		case pathVarNodeID:
+			return pathValue(vars.NodeID)
+		case pathVarProfile:
+			return pathValue(profile)
+		case pathVarSeqNo:
+			return strconv.FormatUint(seqNo, 10)
+		case pathVarDate:
+			return start.Format("2006-01-02")
+		case pathVarYear:
+			return start.Format("2006")
+		case pathVarMonth:
+			return start.Format("01")
+		case pathVarDay:
+			return start.Format("02")
+		}
+		return v
+	})
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
+
+// pathValue keeps the value of a variable within its path element
+func pathValue(v string) string {
+	if v == "." || v == ".." {
+		return ""
+	}
+	return strings.ReplaceAll(v, "/", "_")
+}
+
+// NewSession wraps the record session of a stream to save its segments at the keys of the template. The other files,
+// such as the JSON playlists read by the /recordings endpoint, are saved in the session as before. It returns nil for
+// a nil session.
+func (t *PathTemplate) NewSession(sess drivers.OSSession, vars PathVars) drivers.OSSession {
+	if sess == nil {
+		return nil
+	}
+	return &templateSession{OSSession: sess, tmpl: t, vars: vars}
+}
+
+type templateSession struct {
+	drivers.OSSession
+	tmpl *PathTemplate
+	vars PathVars
+}
+
+func (sess *templateSession) unwrap() drivers.OSSession {
+	return sess.OSSession
+}
+
+func (sess *templateSession) SaveData(ctx context.Context, name string, data io.Reader, meta map[string]string, timeout time.Duration) (string, error) {
+	m := segmentNameRegexp.FindStringSubmatch(name)
+	if m == nil {
+		return sess.OSSession.SaveData(ctx, name, data, meta, timeout)
+	}
+	seqNo, err := strconv.ParseUint(m[2], 10, 64)
+	if err != nil {
+		return sess.OSSession.SaveData(ctx, name, data, meta, timeout)
+	}
+	key := sess.tmpl.Key(sess.vars, m[1], seqNo) + m[3]
+	dir := path.Dir(key)
+	if dir == "." {
+		// The directory of the template is made of empty variables
+		return sess.OSSession.SaveData(ctx, name, data, meta, timeout)
+	}
+	// The segment is saved in a session of its directory, so that its key is relative to the key prefix of the store
+	return sess.OS().NewSession(dir).SaveData(ctx, path.Base(key), data, meta, timeout)
+}
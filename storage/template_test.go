@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpl, err := ParsePathTemplate("/recordings/{date}/{manifestID}/{profile}/{seqNo}/")
+	assert.Nil(err)
+	assert.Equal("recordings/{date}/{manifestID}/{profile}/{seqNo}", tmpl.String())
+
+	_, err = ParsePathTemplate("")
+	assert.EqualError(err, "empty path template")
+	_, err = ParsePathTemplate("{manifestID}/{profile}/{seqNo}/{hour}")
+	assert.EqualError(err, `unknown variable {hour} in path template "{manifestID}/{profile}/{seqNo}/{hour}"`)
+	_, err = ParsePathTemplate("{manifestID}/{seqNo}")
+	assert.EqualError(err, `path template "{manifestID}/{seqNo}" has to include {profile}`)
+	_, err = ParsePathTemplate("{manifestID}_{profile}_{seqNo}")
+	assert.EqualError(err, `path template "{manifestID}_{profile}_{seqNo}" has to include a directory`)
+}
+
+func TestPathTemplate_Key(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tmpl, err := ParsePathTemplate("{year}/{month}/{day}/{date}/{sessionID}/{nodeID}/{manifestID}/{profile}/{seqNo}")
+	require.Nil(err)
+	start := time.Date(2022, 10, 17, 23, 30, 0, 0, time.FixedZone("", -2*3600))
+	vars := PathVars{ManifestID: "mid", SessionID: "sid", NodeID: "node", Start: start}
+	assert.Equal("2022/10/18/2022-10-18/sid/node/mid/source/3", tmpl.Key(vars, "source", 3))
+
+	// Empty variables are removed from the path, and the variables can't escape their path element
+	vars = PathVars{ManifestID: "../mid", NodeID: "..", Start: start}
+	assert.Equal("2022/10/18/2022-10-18/.._mid/P144p30fps16x9/3", tmpl.Key(vars, "P144p30fps16x9", 3))
+}
+
+func TestPathTemplate_NewSession(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tmpl, err := ParsePathTemplate("recordings/{date}/{manifestID}/{profile}/{seqNo}")
+	require.Nil(err)
+	assert.Nil(tmpl.NewSession(nil, PathVars{}))
+	os := drivers.NewMemoryDriver(nil)
+	vars := PathVars{ManifestID: "mid", Start: time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC)}
+	sess := tmpl.NewSession(os.NewSession("mid/node"), vars)
+	ctx := context.Background()
+
+	// The segments are saved at the key of the template
+	uri, err := sess.SaveData(ctx, "source/1.ts", bytes.NewReader([]byte("seg1")), nil, 0)
+	require.Nil(err)
+	assert.Equal("/stream/recordings/2022-10-17/mid/source/1.ts", uri)
+	assert.Equal("seg1", readString(t, os, "recordings/2022-10-17/mid/source", "1.ts"))
+
+	// The other files are saved in the session
+	uri, err = sess.SaveData(ctx, "playlist_1.json", bytes.NewReader([]byte("{}")), nil, 0)
+	require.Nil(err)
+	assert.Equal("/stream/mid/node/playlist_1.json", uri)
+}
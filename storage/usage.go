@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/livepeer/go-livepeer/monitor"
+	"github.com/livepeer/go-tools/drivers"
+)
+
+// StreamUsage tracks the bytes saved to the object stores by the streams of the node
+var StreamUsage = NewUsageTracker()
+
+// Object stores of the usage of a stream
+const (
+	// UsageObjectStore is the object store of the segments, -objectStore or the objectStore of the webhook
+	UsageObjectStore = "object"
+	// UsageRecordStore is the object store of the recordings, -recordStore or the recordObjectStore of the webhook
+	UsageRecordStore = "record"
+)
+
+// usageRetention is how long the usage of an ended stream is kept
+const usageRetention = 24 * time.Hour
+
+// Usage is the storage used by a stream
+type Usage struct {
+	ManifestID string `json:"manifestID"`
+	// ObjectStoreBytes and RecordStoreBytes are the bytes of the successful saves to each store. The saves to the
+	// replicas of a record store aren't counted separately.
+	ObjectStoreBytes int64      `json:"objectStoreBytes"`
+	ObjectStoreSaves int64      `json:"objectStoreSaves"`
+	RecordStoreBytes int64      `json:"recordStoreBytes"`
+	RecordStoreSaves int64      `json:"recordStoreSaves"`
+	StartedAt        time.Time  `json:"startedAt"`
+	EndedAt          *time.Time `json:"endedAt,omitempty"`
+}
+
+// UsageTracker accounts for the bytes saved to the object stores by each stream
+type UsageTracker struct {
+	mu      sync.Mutex
+	streams map[string]*Usage
+}
+
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{streams: make(map[string]*Usage)}
+}
+
+// NewSession wraps the session of a stream to account for the bytes it saves to the store, UsageObjectStore or
+// UsageRecordStore. The sessions it creates with its driver, such as the sessions of each orchestrator, are accounted
+// for too. It returns nil for a nil session.
+func (t *UsageTracker) NewSession(sess drivers.OSSession, manifestID, store string) drivers.OSSession {
+	if sess == nil {
+		return nil
+	}
+	t.mu.Lock()
+	if _, ok := t.streams[manifestID]; !ok {
+		t.streams[manifestID] = &Usage{ManifestID: manifestID, StartedAt: time.Now()}
+	}
+	t.mu.Unlock()
+	os := &usageOS{OSDriver: sess.OS(), tracker: t, manifestID: manifestID, store: store}
+	return &usageSession{OSSession: sess, os: os}
+}
+
+// EndStream marks the usage of a stream as ended. It's kept for usageRetention after.
+func (t *UsageTracker) EndStream(manifestID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if u, ok := t.streams[manifestID]; ok && u.EndedAt == nil {
+		now := time.Now()
+		u.EndedAt = &now
+	}
+	t.prune(time.Now())
+}
+
+// Get returns the usage of a stream
+func (t *UsageTracker) Get(manifestID string) (Usage, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(time.Now())
+	u, ok := t.streams[manifestID]
+	if !ok {
+		return Usage{}, false
+	}
+	return *u, true
+}
+
+// All returns the usage of the streams, the most recent first
+func (t *UsageTracker) All() []Usage {
+	t.mu.Lock()
+	t.prune(time.Now())
+	usages := make([]Usage, 0, len(t.streams))
+	for _, u := range t.streams {
+		usages = append(usages, *u)
+	}
+	t.mu.Unlock()
+	sort.Slice(usages, func(i, j int) bool { return usages[i].StartedAt.After(usages[j].StartedAt) })
+	return usages
+}
+
+// prune removes the usage of the streams ended for longer than usageRetention, with the lock held
+func (t *UsageTracker) prune(now time.Time) {
+	for mid, u := range t.streams {
+		if u.EndedAt != nil && now.Sub(*u.EndedAt) > usageRetention {
+			delete(t.streams, mid)
+		}
+	}
+}
+
+func (t *UsageTracker) add(manifestID, store string, bytes int64) {
+	t.mu.Lock()
+	u, ok := t.streams[manifestID]
+	if !ok {
+		// Saves finishing after the usage of the stream is pruned
+		u = &Usage{ManifestID: manifestID, StartedAt: time.Now()}
+		t.streams[manifestID] = u
+	}
+	if store == UsageRecordStore {
+		u.RecordStoreBytes += bytes
+		u.RecordStoreSaves++
+	} else {
+		u.ObjectStoreBytes += bytes
+		u.ObjectStoreSaves++
+	}
+	t.mu.Unlock()
+	if monitor.Enabled {
+		monitor.StreamStorageSaved(manifestID, store, bytes)
+	}
+}
+
+// usageOS is the driver of the sessions of a stream, accounting for the saves of the sessions it creates
+type usageOS struct {
+	drivers.OSDriver
+	tracker    *UsageTracker
+	manifestID string
+	store      string
+}
+
+func (os *usageOS) NewSession(path string) drivers.OSSession {
+	return &usageSession{OSSession: os.OSDriver.NewSession(path), os: os}
+}
+
+type usageSession struct {
+	drivers.OSSession
+	os *usageOS
+}
+
+func (sess *usageSession) OS() drivers.OSDriver {
+	return sess.os
+}
+
+func (sess *usageSession) unwrap() drivers.OSSession {
+	return sess.OSSession
+}
+
+func (sess *usageSession) SaveData(ctx context.Context, name string, data io.Reader, meta map[string]string, timeout time.Duration) (string, error) {
+	data, counter := newUsageReader(data)
+	uri, err := sess.OSSession.SaveData(ctx, name, data, meta, timeout)
+	if err == nil {
+		sess.os.tracker.add(sess.os.manifestID, sess.os.store, counter.size)
+	}
+	return uri, err
+}
+
+// usageReader counts the bytes of the data of a save. The size is the furthest offset read, so that the data read
+// again on retries isn't counted twice.
+type usageReader struct {
+	r      io.Reader
+	offset int64
+	size   int64
+}
+
+func (ur *usageReader) Read(p []byte) (int, error) {
+	n, err := ur.r.Read(p)
+	ur.offset += int64(n)
+	if ur.offset > ur.size {
+		ur.size = ur.offset
+	}
+	return n, err
+}
+
+// usageReadSeeker keeps the data seekable, so that it's read again from the start on retries instead of buffered
+type usageReadSeeker struct {
+	*usageReader
+	s     io.Seeker
+	start int64
+}
+
+func (urs *usageReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	pos, err := urs.s.Seek(offset, whence)
+	if err == nil {
+		urs.offset = pos - urs.start
+	}
+	return pos, err
+}
+
+// newUsageReader returns the reader of the data counting its bytes, seekable if the data is
+func newUsageReader(data io.Reader) (io.Reader, *usageReader) {
+	ur := &usageReader{r: data}
+	if rs, ok := data.(io.ReadSeeker); ok {
+		if start, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			return &usageReadSeeker{usageReader: ur, s: rs, start: start}, ur
+		}
+	}
+	return ur, ur
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTracker(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tracker := NewUsageTracker()
+	assert.Nil(tracker.NewSession(nil, "mid", UsageObjectStore))
+	ctx := context.Background()
+
+	// Retried saves are counted once, whether the data is seeked back or buffered
+	flaky := &flakyOS{MemoryOS: drivers.NewMemoryDriver(nil), failures: 2}
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	sess := tracker.NewSession(NewRetryDriver(flaky, policy).NewSession("mid"), "mid", UsageObjectStore)
+	_, err := sess.SaveData(ctx, "1.ts", bytes.NewReader([]byte("seg1")), nil, 0)
+	require.Nil(err)
+	assert.Equal(3, flaky.getAttempts())
+	flaky.failures = 1
+	_, err = sess.SaveData(ctx, "2.ts", io.MultiReader(strings.NewReader("segment2")), nil, 0)
+	require.Nil(err)
+
+	// Failed saves aren't counted
+	flaky.setFail(true)
+	_, err = sess.SaveData(ctx, "3.ts", bytes.NewReader([]byte("seg3")), nil, 0)
+	assert.NotNil(err)
+	flaky.setFail(false)
+
+	// The sessions created with the driver of the session are accounted for too
+	_, err = sess.OS().NewSession("mid/orch").SaveData(ctx, "1.ts", strings.NewReader("seg"), nil, 0)
+	require.Nil(err)
+
+	// Record sessions
+	rsess := tracker.NewSession(drivers.NewMemoryDriver(nil).NewSession("rec"), "mid", UsageRecordStore)
+	_, err = rsess.SaveData(ctx, "1.ts", strings.NewReader("recording"), nil, 0)
+	require.Nil(err)
+	tracker.NewSession(drivers.NewMemoryDriver(nil).NewSession("other"), "other", UsageObjectStore)
+
+	usage, ok := tracker.Get("mid")
+	require.True(ok)
+	assert.Equal(int64(4+8+3), usage.ObjectStoreBytes)
+	assert.Equal(int64(3), usage.ObjectStoreSaves)
+	assert.Equal(int64(9), usage.RecordStoreBytes)
+	assert.Equal(int64(1), usage.RecordStoreSaves)
+	assert.Nil(usage.EndedAt)
+	_, ok = tracker.Get("unknown")
+	assert.False(ok)
+
+	usages := tracker.All()
+	require.Len(usages, 2)
+	assert.Equal("other", usages[0].ManifestID)
+	assert.Equal("mid", usages[1].ManifestID)
+
+	// The usage of ended streams is kept for a while
+	tracker.EndStream("mid")
+	usage, ok = tracker.Get("mid")
+	require.True(ok)
+	assert.NotNil(usage.EndedAt)
+	tracker.prune(time.Now().Add(usageRetention + time.Second))
+	_, ok = tracker.Get("mid")
+	assert.False(ok)
+	assert.Len(tracker.All(), 1)
+}